@@ -0,0 +1,90 @@
+// Package cassette records the API interactions of a CLI run, including
+// streamed chat responses, and replays them without network access. A
+// Recorder is a proxy in front of the real API; a Player serves the recorded
+// responses in order.
+package cassette
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the cassette format written by this package
+const Version = 1
+
+// Cassette is a recording of API interactions
+type Cassette struct {
+	Version      int           `yaml:"version"`
+	RecordedAt   time.Time     `yaml:"recorded_at"`
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a request and the response it received
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Request is a recorded request. Headers are not recorded so credentials
+// never end up in a cassette.
+type Request struct {
+	Method string `yaml:"method"`
+	// Path includes the query string, e.g. /api/v1/agents?limit=10
+	Path string `yaml:"path"`
+	Body string `yaml:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	if c.Version > Version {
+		return nil, fmt.Errorf("cassette %s has version %d, this CLI supports up to %d", path, c.Version, Version)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path
+func (c *Cassette) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// recordedHeaders are the response headers kept in a cassette
+var recordedHeaders = []string{"Content-Type", "x-vercel-ai-data-stream"}
+
+// serve runs handler on addr (e.g. "127.0.0.1:0") in the background and
+// returns its base URL and a function that stops it
+func serve(addr string, handler http.Handler) (string, func() error, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start cassette server: %w", err)
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(listener)
+	return "http://" + listener.Addr().String(), srv.Close, nil
+}
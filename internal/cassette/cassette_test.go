@@ -0,0 +1,97 @@
+package cassette
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/mockserver"
+)
+
+func chatText(t *testing.T, client *kubiya.Client) string {
+	t.Helper()
+	messages, err := client.SendMessage(context.Background(), "mock-agent-1", "show me the pods", "session-1")
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	var b strings.Builder
+	for msg := range messages {
+		b.WriteString(msg.Type + ":" + msg.Content + "\n")
+	}
+	return b.String()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	apiURL, stopAPI, err := mockserver.New(mockserver.DefaultFixtures()).Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stopAPI()
+	upstream, _ := url.Parse(apiURL)
+	path := filepath.Join(t.TempDir(), "session.cassette")
+
+	recorder := NewRecorder(upstream, path)
+	recordURL, stopRecorder, err := recorder.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := kubiya.NewClient(&config.Config{APIKey: "secret-key", BaseURL: recordURL + "/api/v1"})
+	agents, err := client.GetAgents(context.Background())
+	if err != nil || len(agents) == 0 {
+		t.Fatalf("GetAgents() = %v, %v", agents, err)
+	}
+	recorded := chatText(t, client)
+	stopRecorder()
+
+	if recorder.Err() != nil || recorder.Len() != 2 {
+		t.Fatalf("recorded %d interactions, err = %v", recorder.Len(), recorder.Err())
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Interactions[1].Request.Path != "/api/v1/hb/v4/stream" {
+		t.Errorf("chat request path = %q", c.Interactions[1].Request.Path)
+	}
+
+	// Stop the API: replay must not need it
+	stopAPI()
+	player := NewPlayer(c)
+	replayURL, stopPlayer, err := player.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stopPlayer()
+
+	client = kubiya.NewClient(&config.Config{APIKey: "replay", BaseURL: replayURL + "/api/v1"})
+	replayedAgents, err := client.GetAgents(context.Background())
+	if err != nil || len(replayedAgents) != len(agents) || replayedAgents[0].UUID != agents[0].UUID {
+		t.Fatalf("replayed GetAgents() = %v, %v", replayedAgents, err)
+	}
+	if replayed := chatText(t, client); replayed != recorded {
+		t.Errorf("replayed chat:\n%s\nrecorded:\n%s", replayed, recorded)
+	}
+	if player.Unused() != 0 {
+		t.Errorf("Unused() = %d, want 0", player.Unused())
+	}
+
+	// Every recorded response was used
+	if _, err := client.GetAgents(context.Background()); err == nil {
+		t.Error("GetAgents() beyond the cassette succeeded")
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.cassette")
+	if err := (&Cassette{Version: Version + 1}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() accepted a newer cassette version")
+	}
+}
@@ -0,0 +1,84 @@
+package cassette
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Player serves the responses of a cassette. Requests are matched by method
+// and path; repeated requests get the recorded responses in order.
+type Player struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewPlayer returns a player for c
+func NewPlayer(c *Cassette) *Player {
+	return &Player{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+// Start serves the player on addr and returns its base URL and a function
+// that stops it
+func (p *Player) Start(addr string) (string, func() error, error) {
+	return serve(addr, p)
+}
+
+// ServeHTTP answers with the next unused recorded response for the request
+func (p *Player) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	interaction, ok := p.next(r.Method, r.URL.RequestURI())
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "{\"error\":%q}\n", fmt.Sprintf("cassette has no recorded response for %s %s", r.Method, r.URL.RequestURI()))
+		return
+	}
+
+	resp := interaction.Response
+	for name, value := range resp.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(resp.Status)
+
+	// Replay streams line by line so clients render them as they arrive
+	flusher, _ := w.(http.Flusher)
+	for _, line := range strings.SplitAfter(resp.Body, "\n") {
+		if line == "" {
+			continue
+		}
+		if _, err := w.Write([]byte(line)); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func (p *Player) next(method, path string) (Interaction, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, interaction := range p.cassette.Interactions {
+		if p.used[i] || interaction.Request.Method != method || interaction.Request.Path != path {
+			continue
+		}
+		p.used[i] = true
+		return interaction, true
+	}
+	return Interaction{}, false
+}
+
+// Unused returns the number of recorded interactions that were not replayed
+func (p *Player) Unused() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, used := range p.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,150 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+)
+
+// Recorder proxies requests to the real API and records every interaction.
+// The cassette is written after each completed response, so an interrupted
+// run keeps what was recorded so far.
+type Recorder struct {
+	path     string
+	proxy    *httputil.ReverseProxy
+	mu       sync.Mutex
+	cassette Cassette
+	err      error
+}
+
+// NewRecorder returns a recorder forwarding to the scheme and host of
+// upstream and writing the cassette to path
+func NewRecorder(upstream *url.URL, path string) *Recorder {
+	r := &Recorder{
+		path:     path,
+		cassette: Cassette{Version: Version, RecordedAt: time.Now().UTC()},
+	}
+	r.proxy = &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = upstream.Scheme
+			req.URL.Host = upstream.Host
+			req.Host = upstream.Host
+			// Record plain bodies rather than compressed ones
+			req.Header.Del("Accept-Encoding")
+		},
+		Transport: httpclient.Transport(),
+		// Pass streamed responses through as they arrive
+		FlushInterval:  -1,
+		ModifyResponse: r.record,
+	}
+	return r
+}
+
+// Start serves the recorder on addr and returns its base URL and a function
+// that stops it
+func (r *Recorder) Start(addr string) (string, func() error, error) {
+	return serve(addr, r)
+}
+
+// ServeHTTP forwards the request and records it
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "failed to read request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req = req.WithContext(withRecordedRequest(req.Context(), Request{
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Body:   string(body),
+	}))
+	r.proxy.ServeHTTP(w, req)
+}
+
+// record tees the response body and adds the interaction once it was read
+func (r *Recorder) record(resp *http.Response) error {
+	request, _ := recordedRequestFrom(resp.Request.Context())
+	response := Response{Status: resp.StatusCode}
+	for _, name := range recordedHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			if response.Headers == nil {
+				response.Headers = make(map[string]string)
+			}
+			response.Headers[name] = v
+		}
+	}
+
+	resp.Body = &teeBody{
+		ReadCloser: resp.Body,
+		done: func(body []byte) {
+			response.Body = string(body)
+			r.add(Interaction{Request: request, Response: response})
+		},
+	}
+	return nil
+}
+
+func (r *Recorder) add(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.cassette.Save(r.path); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// Len returns the number of recorded interactions
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cassette.Interactions)
+}
+
+// Err returns the first error writing the cassette
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// teeBody keeps a copy of everything read and hands it to done once, when
+// the body is closed
+type teeBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.buf.Write(p[:n])
+	return n, err
+}
+
+func (t *teeBody) Close() error {
+	err := t.ReadCloser.Close()
+	t.once.Do(func() { t.done(t.buf.Bytes()) })
+	return err
+}
+
+type recordedRequestKey struct{}
+
+// withRecordedRequest carries the request as received, before the proxy
+// rewrites it, to the response handler
+func withRecordedRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, recordedRequestKey{}, req)
+}
+
+func recordedRequestFrom(ctx context.Context) (Request, bool) {
+	req, ok := ctx.Value(recordedRequestKey{}).(Request)
+	return req, ok
+}
@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Outcomes of changing the owners of one agent
+const (
+	ownersChanged     = "changed"
+	ownersWouldChange = "would-change"
+	ownersUnchanged   = "unchanged"
+	ownersFailed      = "failed"
+)
+
+// agentOwnerUpdater is the part of kubiya.Client used to change owners
+type agentOwnerUpdater interface {
+	ListAgents(ctx context.Context) ([]kubiya.Agent, error)
+	GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error)
+	UpdateAgentRaw(ctx context.Context, uuid string, data map[string]interface{}) (*kubiya.Agent, error)
+	ListUsers(ctx context.Context) ([]kubiya.User, error)
+}
+
+// chownOptions selects the agents and their new owners
+type chownOptions struct {
+	AgentUUID string // a single agent, or those matching From and Selector
+	Owners    []string
+	From      string // owner to replace, keeping the others
+	Selector  map[string]string
+	DryRun    bool
+}
+
+// chownEntry records the owners of one agent before and after
+type chownEntry struct {
+	Agent  string   `json:"agent"`
+	UUID   string   `json:"uuid"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+	Status string   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// chownReport is printed at the end of an ownership transfer
+type chownReport struct {
+	Owners   []string     `json:"owners"`
+	From     string       `json:"from,omitempty"`
+	Selector string       `json:"selector,omitempty"`
+	DryRun   bool         `json:"dry_run"`
+	Agents   []chownEntry `json:"agents"`
+}
+
+func newAgentChownCommand(cfg *config.Config) *cobra.Command {
+	var (
+		owners       []string
+		from         string
+		selector     string
+		dryRun       bool
+		yes          bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "chown [agent-uuid]",
+		Short: "👑 Transfer the ownership of agents",
+		Long: `Change the owners of an agent, or of every agent matching --from and
+--selector.
+
+The new owners must be users of the organization. Without --from, the owners
+of the agents are replaced by --owner. With --from, only that owner is
+replaced and the other owners are kept, which is how agents are handed over
+when someone leaves: every agent they own moves to the new owners.
+
+--selector narrows the agents down to those having all the given tags, e.g.
+team=sre. The owners of each agent before and after the change are printed.`,
+		Example: `  # Make new-owner@corp.com the only owner of an agent
+  kubiya agent chown abc-123 --owner new-owner@corp.com
+
+  # Hand over every agent of a leaver, keeping their co-owners
+  kubiya agent chown --from leaver@corp.com --owner new-owner@corp.com --dry-run
+  kubiya agent chown --from leaver@corp.com --owner new-owner@corp.com --yes
+
+  # Give the SRE agents to two owners
+  kubiya agent chown --selector team=sre --owner alice@corp.com --owner bob@corp.com`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			sel, err := parseAgentSelector(selector)
+			if err != nil {
+				return err
+			}
+			opts := chownOptions{Owners: owners, From: from, Selector: sel, DryRun: dryRun}
+			if len(args) == 1 {
+				if selector != "" {
+					return fmt.Errorf("--selector cannot be combined with an agent UUID")
+				}
+				opts.AgentUUID = args[0]
+			} else if from == "" && selector == "" {
+				return fmt.Errorf("specify an agent UUID, --from or --selector")
+			}
+
+			client := kubiya.NewClient(cfg)
+			if err := checkAgentOwners(cmd.Context(), client, opts.Owners); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !dryRun && !yes {
+				matched, err := selectAgentsForChown(cmd.Context(), client, opts)
+				if err != nil {
+					return err
+				}
+				if len(matched) == 0 {
+					fmt.Fprintf(out, "%s No agent matches\n", style.InfoStyle.Render("ℹ️"))
+					return nil
+				}
+				if !confirmYesNo(fmt.Sprintf("Change the owners of %d agents to %s?", len(matched), strings.Join(opts.Owners, ", "))) {
+					return fmt.Errorf("ownership transfer cancelled")
+				}
+			}
+
+			report, err := chownAgents(cmd.Context(), client, opts)
+			if err != nil {
+				return err
+			}
+			report.Selector = selector
+
+			if outputFormat == "json" {
+				if err := output.EncodeJSON(out, report); err != nil {
+					return err
+				}
+			} else {
+				printAgentChown(out, report)
+			}
+
+			failed := 0
+			for _, e := range report.Agents {
+				if e.Status == ownersFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d agents failed to change owners", failed, len(report.Agents))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&owners, "owner", nil, "New owner, by email or user ID (can be specified multiple times)")
+	cmd.Flags().StringVar(&from, "from", "", "Only replace this owner, and only on the agents it owns")
+	cmd.Flags().StringVar(&selector, "selector", "", "Only change agents having these tags, e.g. team=sre,env=prod")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without changing anything")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("owner")
+
+	return cmd
+}
+
+// checkAgentOwners fails unless every owner is a user of the organization,
+// by email, UUID or ID
+func checkAgentOwners(ctx context.Context, client agentOwnerUpdater, owners []string) error {
+	users, err := client.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+	var unknown []string
+	for _, owner := range owners {
+		found := false
+		for _, u := range users {
+			if strings.EqualFold(u.Email, owner) || u.UUID != "" && u.UUID == owner || u.ID != "" && u.ID == owner {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, owner)
+		}
+	}
+	switch len(unknown) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("user %q not found in the organization", unknown[0])
+	default:
+		return fmt.Errorf("users not found in the organization: %s", strings.Join(unknown, ", "))
+	}
+}
+
+// hasOwner reports whether owner is in owners, ignoring case
+func hasOwner(owners []string, owner string) bool {
+	for _, o := range owners {
+		if strings.EqualFold(o, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAgentOwners returns the owners of an agent after the transfer: the
+// given owners, after those kept when only opts.From is replaced
+func newAgentOwners(before []string, opts chownOptions) []string {
+	after := []string{}
+	add := func(owner string) {
+		if owner != "" && !hasOwner(after, owner) {
+			after = append(after, owner)
+		}
+	}
+	if opts.From != "" {
+		for _, o := range before {
+			if !strings.EqualFold(o, opts.From) {
+				add(o)
+			}
+		}
+	}
+	for _, o := range opts.Owners {
+		add(o)
+	}
+	return after
+}
+
+func selectAgentsForChown(ctx context.Context, client agentOwnerUpdater, opts chownOptions) ([]kubiya.Agent, error) {
+	if opts.AgentUUID != "" {
+		agent, err := client.GetAgent(ctx, opts.AgentUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent: %w", err)
+		}
+		if opts.From != "" && !hasOwner(agent.Owners, opts.From) {
+			return nil, fmt.Errorf("agent %s is not owned by %s", agent.Name, opts.From)
+		}
+		return []kubiya.Agent{*agent}, nil
+	}
+
+	agents, err := client.ListAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	var matched []kubiya.Agent
+	for _, a := range agents {
+		if (opts.From == "" || hasOwner(a.Owners, opts.From)) && agentMatchesSelector(a, opts.Selector) {
+			matched = append(matched, a)
+		}
+	}
+	return matched, nil
+}
+
+// chownAgents changes the owners of the matching agents, one at a time.
+// Agents that fail are recorded and the transfer goes on.
+func chownAgents(ctx context.Context, client agentOwnerUpdater, opts chownOptions) (*chownReport, error) {
+	report := &chownReport{Owners: opts.Owners, From: opts.From, DryRun: opts.DryRun, Agents: []chownEntry{}}
+	matched, err := selectAgentsForChown(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, listed := range matched {
+		id := firstNonEmpty(listed.UUID, listed.ID)
+		entry := chownEntry{Agent: listed.Name, UUID: id, Before: listed.Owners, After: listed.Owners}
+
+		// The listing may leave fields out that the update would clear
+		agent := &listed
+		if opts.AgentUUID == "" {
+			if agent, err = client.GetAgent(ctx, id); err != nil {
+				entry.Status = ownersFailed
+				entry.Error = err.Error()
+				report.Agents = append(report.Agents, entry)
+				continue
+			}
+		}
+		if agent.Owners == nil {
+			agent.Owners = []string{}
+		}
+		entry.Before = agent.Owners
+		entry.After = newAgentOwners(agent.Owners, opts)
+
+		switch {
+		case strings.Join(entry.Before, "\n") == strings.Join(entry.After, "\n"):
+			entry.Status = ownersUnchanged
+		case opts.DryRun:
+			entry.Status = ownersWouldChange
+		default:
+			if _, err := client.UpdateAgentRaw(ctx, id, agentOwnersUpdateData(agent, entry.After)); err != nil {
+				entry.Status = ownersFailed
+				entry.Error = err.Error()
+				entry.After = entry.Before
+			} else {
+				entry.Status = ownersChanged
+			}
+		}
+		report.Agents = append(report.Agents, entry)
+	}
+	return report, nil
+}
+
+func agentOwnersUpdateData(agent *kubiya.Agent, owners []string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                  agent.Name,
+		"description":           agent.Description,
+		"instruction_type":      agent.InstructionType,
+		"llm_model":             agent.LLMModel,
+		"sources":               agent.Sources,
+		"environment_variables": agent.Environment,
+		"secrets":               agent.Secrets,
+		"allowed_groups":        agent.AllowedGroups,
+		"allowed_users":         agent.AllowedUsers,
+		"owners":                owners,
+		"runners":               agent.Runners,
+		"is_debug_mode":         agent.IsDebugMode,
+		"ai_instructions":       agent.AIInstructions,
+		"image":                 agent.Image,
+		"managed_by":            agent.ManagedBy,
+		"integrations":          agent.Integrations,
+		"links":                 agent.Links,
+		"tools":                 agent.Tools,
+		"tasks":                 agent.Tasks,
+		"starters":              agent.Starters,
+		"tags":                  agent.Tags,
+	}
+}
+
+func printAgentChown(w io.Writer, report *chownReport) {
+	if len(report.Agents) == 0 {
+		fmt.Fprintf(w, "%s No agent matches\n", style.InfoStyle.Render("ℹ️"))
+		return
+	}
+
+	owners := func(list []string) string {
+		if len(list) == 0 {
+			return "-"
+		}
+		return strings.Join(list, ", ")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tBEFORE\tAFTER\tSTATUS")
+	counts := map[string]int{}
+	for _, e := range report.Agents {
+		counts[e.Status]++
+		status := e.Status
+		if e.Error != "" {
+			status += ": " + e.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Agent, owners(e.Before), owners(e.After), status)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if report.DryRun {
+		fmt.Fprintf(w, "%s %d agents would change owners\n", style.InfoStyle.Render("👑"), counts[ownersWouldChange])
+	} else {
+		fmt.Fprintf(w, "%s %d agents changed owners\n", style.SuccessStyle.Render("✅"), counts[ownersChanged])
+	}
+	if n := counts[ownersFailed]; n > 0 {
+		fmt.Fprintf(w, "   %s\n", style.ErrorStyle.Render(fmt.Sprintf("%d failed", n)))
+	}
+}
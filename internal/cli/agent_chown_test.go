@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeOwnerUpdater keeps agents and users in memory
+type fakeOwnerUpdater struct {
+	agents  []*kubiya.Agent
+	users   []kubiya.User
+	failing string
+	updates map[string]map[string]interface{}
+}
+
+func (f *fakeOwnerUpdater) ListAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	var agents []kubiya.Agent
+	for _, a := range f.agents {
+		listed := *a
+		listed.AIInstructions = ""
+		agents = append(agents, listed)
+	}
+	return agents, nil
+}
+
+func (f *fakeOwnerUpdater) GetAgent(ctx context.Context, id string) (*kubiya.Agent, error) {
+	for _, a := range f.agents {
+		if a.UUID == id {
+			copied := *a
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("agent not found: %s", id)
+}
+
+func (f *fakeOwnerUpdater) UpdateAgentRaw(ctx context.Context, id string, data map[string]interface{}) (*kubiya.Agent, error) {
+	if id == f.failing {
+		return nil, fmt.Errorf("server error")
+	}
+	if f.updates == nil {
+		f.updates = map[string]map[string]interface{}{}
+	}
+	f.updates[id] = data
+	return &kubiya.Agent{UUID: id}, nil
+}
+
+func (f *fakeOwnerUpdater) ListUsers(ctx context.Context) ([]kubiya.User, error) {
+	return f.users, nil
+}
+
+func TestCheckAgentOwners(t *testing.T) {
+	client := &fakeOwnerUpdater{users: []kubiya.User{
+		{UUID: "u-1", Email: "alice@corp.com"},
+		{UUID: "u-2", Email: "bob@corp.com"},
+	}}
+	assert.NoError(t, checkAgentOwners(context.Background(), client, []string{"Alice@corp.com", "u-2"}))
+	assert.EqualError(t, checkAgentOwners(context.Background(), client, []string{"carol@corp.com"}),
+		`user "carol@corp.com" not found in the organization`)
+	assert.EqualError(t, checkAgentOwners(context.Background(), client, []string{"alice@corp.com", "carol@corp.com", "u-3"}),
+		"users not found in the organization: carol@corp.com, u-3")
+}
+
+func TestNewAgentOwners(t *testing.T) {
+	before := []string{"leaver@corp.com", "bob@corp.com"}
+	assert.Equal(t, []string{"alice@corp.com"}, newAgentOwners(before, chownOptions{Owners: []string{"alice@corp.com"}}))
+	assert.Equal(t, []string{"bob@corp.com", "alice@corp.com"},
+		newAgentOwners(before, chownOptions{From: "Leaver@corp.com", Owners: []string{"alice@corp.com"}}))
+	assert.Equal(t, []string{"bob@corp.com"},
+		newAgentOwners(before, chownOptions{From: "leaver@corp.com", Owners: []string{"BOB@corp.com"}}), "owners are not repeated")
+}
+
+func TestChownAgents(t *testing.T) {
+	client := &fakeOwnerUpdater{failing: "a-4", agents: []*kubiya.Agent{
+		{UUID: "a-1", Name: "oncall", Owners: []string{"leaver@corp.com", "bob@corp.com"}, Tags: []string{"team=sre"}, AIInstructions: "Be brief"},
+		{UUID: "a-2", Name: "runbook", Owners: []string{"bob@corp.com"}, Tags: []string{"team=sre"}},
+		{UUID: "a-3", Name: "billing", Owners: []string{"leaver@corp.com"}, Tags: []string{"team=finance"}},
+		{UUID: "a-4", Name: "pager", Owners: []string{"leaver@corp.com"}, Tags: []string{"team=sre"}},
+		{UUID: "a-5", Name: "handed", Owners: []string{"leaver@corp.com", "alice@corp.com"}, Tags: []string{"team=sre"}},
+	}}
+	sel, err := parseAgentSelector("team=sre")
+	require.NoError(t, err)
+	opts := chownOptions{From: "leaver@corp.com", Owners: []string{"alice@corp.com"}, Selector: sel}
+
+	report, err := chownAgents(context.Background(), client, opts)
+	require.NoError(t, err)
+	require.Len(t, report.Agents, 3)
+
+	assert.Equal(t, "oncall", report.Agents[0].Agent)
+	assert.Equal(t, ownersChanged, report.Agents[0].Status)
+	assert.Equal(t, []string{"leaver@corp.com", "bob@corp.com"}, report.Agents[0].Before)
+	assert.Equal(t, []string{"bob@corp.com", "alice@corp.com"}, report.Agents[0].After)
+	assert.Equal(t, []string{"bob@corp.com", "alice@corp.com"}, client.updates["a-1"]["owners"])
+	assert.Equal(t, "Be brief", client.updates["a-1"]["ai_instructions"], "the agent is updated from its full record")
+
+	assert.Equal(t, ownersFailed, report.Agents[1].Status)
+	assert.Equal(t, []string{"leaver@corp.com"}, report.Agents[1].After)
+	assert.Equal(t, ownersChanged, report.Agents[2].Status)
+	assert.Len(t, client.updates, 2)
+
+	var out bytes.Buffer
+	printAgentChown(&out, report)
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"AGENT", "BEFORE", "AFTER", "STATUS"}, strings.Fields(lines[0]))
+	assert.Contains(t, lines[1], "leaver@corp.com, bob@corp.com")
+	assert.Contains(t, lines[2], "failed: server error")
+	assert.Contains(t, out.String(), "2 agents changed owners")
+}
+
+func TestChownAgent(t *testing.T) {
+	client := &fakeOwnerUpdater{agents: []*kubiya.Agent{
+		{UUID: "a-1", Name: "oncall", Owners: []string{"bob@corp.com"}},
+		{UUID: "a-2", Name: "runbook", Owners: []string{"alice@corp.com"}},
+	}}
+
+	report, err := chownAgents(context.Background(), client, chownOptions{AgentUUID: "a-1", Owners: []string{"alice@corp.com"}, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, report.Agents, 1)
+	assert.Equal(t, ownersWouldChange, report.Agents[0].Status)
+	assert.Equal(t, []string{"alice@corp.com"}, report.Agents[0].After)
+	assert.Empty(t, client.updates)
+
+	report, err = chownAgents(context.Background(), client, chownOptions{AgentUUID: "a-2", Owners: []string{"alice@corp.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, ownersUnchanged, report.Agents[0].Status)
+	assert.Empty(t, client.updates)
+
+	_, err = chownAgents(context.Background(), client, chownOptions{AgentUUID: "a-2", From: "bob@corp.com", Owners: []string{"carol@corp.com"}})
+	assert.EqualError(t, err, "agent runbook is not owned by bob@corp.com")
+}
@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// agentYAMLFields are the fields of an agent that can be edited as YAML, in
+// the order they are written, with the comment written above each
+var agentYAMLFields = []struct {
+	key string
+	doc string
+}{
+	{"name", "Name of the agent, shown in chats and lists"},
+	{"description", "What the agent is for, used to route messages to it"},
+	{"llm_model", "LLM model the agent runs on, e.g. azure/gpt-4o"},
+	{"instruction_type", "How ai_instructions are interpreted, usually \"tools\""},
+	{"ai_instructions", "Instructions given to the model on every conversation"},
+	{"runners", "Runners the tools of the agent run on"},
+	{"sources", "UUIDs of the sources whose tools the agent can use"},
+	{"tools", "Tools of the agent on top of those of its sources"},
+	{"integrations", "Integrations the agent can use, e.g. kubernetes, aws"},
+	{"secrets", "Names of the secrets made available to the tools"},
+	{"environment_variables", "Environment variables of the tools, as NAME: value"},
+	{"starters", "Conversation starters suggested to users"},
+	{"owners", "Users who own the agent"},
+	{"allowed_users", "Users allowed to use the agent, all when empty"},
+	{"allowed_groups", "Groups allowed to use the agent, all when empty"},
+	{"tags", "Labels to organise agents"},
+	{"links", "Links shown with the agent"},
+	{"tasks", "Tasks of the agent"},
+	{"image", "Container image the tools of the agent run in by default"},
+	{"managed_by", "Tool managing the agent, e.g. terraform"},
+	{"is_debug_mode", "Send tool output and debug information along with replies"},
+}
+
+// agentReadOnlyFields are set by the platform and left out of the YAML
+var agentReadOnlyFields = map[string]bool{
+	"uuid":     true,
+	"id":       true,
+	"desc":     true,
+	"metadata": true,
+}
+
+const (
+	agentYAMLHeader = `# Editing agent %s (%s)
+# Lines starting with '#' are ignored. An empty file cancels the edit.
+# Fields at the end that this version of the CLI does not know about are
+# sent back as they are.
+`
+	agentYAMLErrorHeader = "# Please fix the errors below and save again. An empty file cancels the edit."
+	agentYAMLErrorLine   = "#  - "
+)
+
+// errEditCancelled is returned when the edited file is emptied
+var errEditCancelled = errors.New("edit cancelled")
+
+// renderAgentYAML writes an agent as returned by the API as commented YAML
+func renderAgentYAML(raw map[string]interface{}) ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value interface{}, comment string) error {
+		var v yaml.Node
+		if err := v.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		if s, ok := value.(string); ok && strings.Contains(s, "\n") {
+			v.Style = yaml.LiteralStyle
+		}
+		k := &yaml.Node{Kind: yaml.ScalarNode, Value: key, HeadComment: comment}
+		doc.Content = append(doc.Content, k, &v)
+		return nil
+	}
+
+	known := make(map[string]bool, len(agentYAMLFields))
+	for _, field := range agentYAMLFields {
+		known[field.key] = true
+		value, ok := raw[field.key]
+		if !ok || value == nil {
+			value = agentYAMLZero(field.key)
+		}
+		if err := add(field.key, value, field.doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var unknown []string
+	for key := range raw {
+		if !known[key] && !agentReadOnlyFields[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for i, key := range unknown {
+		comment := ""
+		if i == 0 {
+			comment = "Not known to this version of the CLI, kept as is"
+		}
+		if err := add(key, raw[key], comment); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, agentYAMLHeader, raw["name"], firstNonEmpty(stringField(raw, "uuid"), stringField(raw, "id")))
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode agent: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// agentYAMLZero is written for fields the API left out, so that every field
+// can be filled in
+func agentYAMLZero(key string) interface{} {
+	switch key {
+	case "environment_variables":
+		return map[string]string{}
+	case "is_debug_mode":
+		return false
+	case "name", "description", "llm_model", "instruction_type", "ai_instructions", "image", "managed_by":
+		return ""
+	default:
+		return []interface{}{}
+	}
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// parseAgentYAML validates an edited agent. It returns the agent and the
+// fields unknown to the CLI that were in the original agent, to be sent back
+// as they are. Fields that were not in the original are likely typos and are
+// rejected, as are fields of the wrong type.
+func parseAgentYAML(data []byte, original map[string]interface{}) (kubiya.Agent, map[string]interface{}, error) {
+	var edited map[string]interface{}
+	if err := yaml.Unmarshal(data, &edited); err != nil {
+		return kubiya.Agent{}, nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(edited) == 0 {
+		return kubiya.Agent{}, nil, errEditCancelled
+	}
+
+	known := make(map[string]bool, len(agentYAMLFields))
+	for _, field := range agentYAMLFields {
+		known[field.key] = true
+	}
+
+	var problems []string
+	extra := map[string]interface{}{}
+	for key, value := range edited {
+		switch {
+		case known[key]:
+		case agentReadOnlyFields[key]:
+			problems = append(problems, fmt.Sprintf("%s: is set by the platform and cannot be edited", key))
+		case hasKey(original, key):
+			extra[key] = value
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown field", key))
+		}
+	}
+
+	var agent kubiya.Agent
+	for _, field := range agentYAMLFields {
+		value, ok := edited[field.key]
+		if !ok || value == nil {
+			continue
+		}
+		// Decode field by field so that every type error is reported
+		data, err := json.Marshal(map[string]interface{}{field.key: value})
+		if err == nil {
+			err = json.Unmarshal(data, &agent)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", field.key, agentYAMLTypeError(err)))
+		}
+	}
+	if strings.TrimSpace(agent.Name) == "" {
+		problems = append(problems, "name: must not be empty")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return kubiya.Agent{}, nil, &agentYAMLError{problems: problems}
+	}
+
+	agent.UUID = firstNonEmpty(stringField(original, "uuid"), stringField(original, "id"))
+	return agent, extra, nil
+}
+
+func hasKey(m map[string]interface{}, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// agentYAMLTypeError rewords JSON type errors in terms of YAML
+func agentYAMLTypeError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		want := typeErr.Type.String()
+		switch typeErr.Type.Kind() {
+		case reflect.Slice:
+			want = "a list"
+		case reflect.Map:
+			want = "a mapping of names to strings"
+		case reflect.String:
+			want = "a string"
+		case reflect.Bool:
+			want = "true or false"
+		}
+		return fmt.Sprintf("must be %s, not %s", want, typeErr.Value)
+	}
+	return err.Error()
+}
+
+// agentYAMLError lists everything wrong with an edited agent
+type agentYAMLError struct {
+	problems []string
+}
+
+func (e *agentYAMLError) Error() string {
+	return "invalid agent: " + strings.Join(e.problems, "; ")
+}
+
+// withAgentYAMLErrors puts the errors of the last save at the top of the
+// file, in place of those of the save before
+func withAgentYAMLErrors(content []byte, err error) []byte {
+	problems := []string{err.Error()}
+	var yamlErr *agentYAMLError
+	if errors.As(err, &yamlErr) {
+		problems = yamlErr.problems
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(agentYAMLErrorHeader + "\n")
+	for _, problem := range problems {
+		buf.WriteString(agentYAMLErrorLine + strings.ReplaceAll(problem, "\n", " ") + "\n")
+	}
+	buf.WriteString("#\n")
+	buf.Write(stripAgentYAMLErrors(content))
+	return buf.Bytes()
+}
+
+func stripAgentYAMLErrors(content []byte) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r\n") != agentYAMLErrorHeader {
+		return content
+	}
+	i := 1
+	for i < len(lines) && strings.HasPrefix(lines[i], agentYAMLErrorLine) {
+		i++
+	}
+	if i < len(lines) && strings.TrimRight(lines[i], "\r\n") == "#" {
+		i++
+	}
+	return []byte(strings.Join(lines[i:], ""))
+}
+
+// editAgentYAML edits an agent as commented YAML, opening the file again
+// with the errors on top until it is valid, like kubectl edit. Saving the
+// file with its errors unchanged gives up.
+func editAgentYAML(raw map[string]interface{}, edit func([]byte) ([]byte, error)) (kubiya.Agent, map[string]interface{}, error) {
+	content, err := renderAgentYAML(raw)
+	if err != nil {
+		return kubiya.Agent{}, nil, err
+	}
+
+	var lastErr error
+	for {
+		edited, err := edit(content)
+		if err != nil {
+			return kubiya.Agent{}, nil, err
+		}
+		if lastErr != nil && bytes.Equal(stripAgentYAMLErrors(edited), stripAgentYAMLErrors(content)) {
+			return kubiya.Agent{}, nil, fmt.Errorf("edit cancelled, the errors were not fixed: %w", lastErr)
+		}
+
+		agent, extra, err := parseAgentYAML(stripAgentYAMLErrors(edited), raw)
+		if err == nil || errors.Is(err, errEditCancelled) {
+			return agent, extra, err
+		}
+		lastErr = err
+		content = withAgentYAMLErrors(edited, err)
+	}
+}
+
+// editInEditor opens content in $EDITOR and returns what was saved
+func editInEditor(content []byte, pattern string) ([]byte, error) {
+	tmpfile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(content); err != nil {
+		tmpfile.Close()
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	tmpfile.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+
+	cmd := exec.Command(editor, tmpfile.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(tmpfile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read updated file: %w", err)
+	}
+	return edited, nil
+}
+
+// agentExtraFieldChanges describes changes to fields unknown to the CLI
+func agentExtraFieldChanges(original, extra map[string]interface{}) []string {
+	var changes []string
+	for key, value := range extra {
+		if !reflect.DeepEqual(normalizeJSON(original[key]), normalizeJSON(value)) {
+			changes = append(changes, fmt.Sprintf("%s: changed", key))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// normalizeJSON makes values decoded from JSON and from YAML comparable
+func normalizeJSON(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRawAgent() map[string]interface{} {
+	return map[string]interface{}{
+		"uuid":            "abc-123",
+		"name":            "devops",
+		"description":     "Runs the clusters",
+		"llm_model":       "azure/gpt-4o",
+		"ai_instructions": "Be careful.\nNever delete namespaces.",
+		"sources":         []interface{}{"src-1"},
+		"environment_variables": map[string]interface{}{
+			"REGION": "us-east-1",
+		},
+		"metadata":       map[string]interface{}{"created_at": "2024-01-01"},
+		"team_dashboard": map[string]interface{}{"url": "https://dash.example.com"},
+	}
+}
+
+func TestRenderAgentYAML(t *testing.T) {
+	out, err := renderAgentYAML(testRawAgent())
+	require.NoError(t, err)
+	text := string(out)
+
+	assert.Contains(t, text, "# Editing agent devops (abc-123)")
+	assert.Contains(t, text, "# Name of the agent, shown in chats and lists\nname: devops")
+	assert.Contains(t, text, "ai_instructions: |-\n  Be careful.\n  Never delete namespaces.")
+	assert.Contains(t, text, "# Not known to this version of the CLI, kept as is\nteam_dashboard:")
+	assert.Contains(t, text, "secrets: []")
+	assert.NotContains(t, text, "created_at")
+	assert.NotContains(t, text, "uuid:")
+}
+
+func TestParseAgentYAMLRoundTrip(t *testing.T) {
+	raw := testRawAgent()
+	out, err := renderAgentYAML(raw)
+	require.NoError(t, err)
+
+	agent, extra, err := parseAgentYAML(out, raw)
+	require.NoError(t, err)
+	assert.Equal(t, "abc-123", agent.UUID)
+	assert.Equal(t, "devops", agent.Name)
+	assert.Equal(t, "Be careful.\nNever delete namespaces.", agent.AIInstructions)
+	assert.Equal(t, []string{"src-1"}, agent.Sources)
+	assert.Equal(t, map[string]string{"REGION": "us-east-1"}, agent.Environment)
+	assert.Equal(t, map[string]interface{}{"team_dashboard": map[string]interface{}{"url": "https://dash.example.com"}}, extra)
+	assert.Empty(t, agentExtraFieldChanges(raw, extra))
+
+	extra["team_dashboard"] = map[string]interface{}{"url": "https://other.example.com"}
+	assert.Equal(t, []string{"team_dashboard: changed"}, agentExtraFieldChanges(raw, extra))
+}
+
+func TestParseAgentYAMLErrors(t *testing.T) {
+	raw := testRawAgent()
+
+	_, _, err := parseAgentYAML([]byte("name: \"\"\nsources: src-1\nsecrtes: [a]\nuuid: other\n"), raw)
+	var yamlErr *agentYAMLError
+	require.True(t, errors.As(err, &yamlErr))
+	assert.Equal(t, []string{
+		"name: must not be empty",
+		"secrtes: unknown field",
+		"sources: must be a list, not string",
+		"uuid: is set by the platform and cannot be edited",
+	}, yamlErr.problems)
+
+	_, _, err = parseAgentYAML([]byte("# just comments\n"), raw)
+	assert.ErrorIs(t, err, errEditCancelled)
+
+	_, _, err = parseAgentYAML([]byte("name: [unclosed"), raw)
+	assert.ErrorContains(t, err, "invalid YAML")
+}
+
+func TestEditAgentYAMLReEditsOnErrors(t *testing.T) {
+	raw := testRawAgent()
+	var seen []string
+	edits := []func(string) string{
+		func(s string) string { return strings.Replace(s, "name: devops", "name: \"\"", 1) },
+		func(s string) string { return strings.Replace(s, "name: \"\"", "name: devops-2", 1) },
+	}
+
+	agent, _, err := editAgentYAML(raw, func(content []byte) ([]byte, error) {
+		seen = append(seen, string(content))
+		edit := edits[len(seen)-1]
+		return []byte(edit(string(content))), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "devops-2", agent.Name)
+	require.Len(t, seen, 2)
+	assert.True(t, strings.HasPrefix(seen[1], agentYAMLErrorHeader+"\n#  - name: must not be empty\n#\n# Editing agent"))
+}
+
+func TestEditAgentYAMLGivesUpOnUnchangedErrors(t *testing.T) {
+	raw := testRawAgent()
+	calls := 0
+	_, _, err := editAgentYAML(raw, func(content []byte) ([]byte, error) {
+		calls++
+		if calls == 1 {
+			return []byte(strings.Replace(string(content), "name: devops", "name: \"\"", 1)), nil
+		}
+		return content, nil
+	})
+	assert.ErrorContains(t, err, "errors were not fixed")
+	assert.Equal(t, 2, calls)
+}
+
+func TestStripAgentYAMLErrors(t *testing.T) {
+	body := []byte("name: devops\n")
+	withErrors := withAgentYAMLErrors(body, errors.New("invalid YAML: boom"))
+	assert.Contains(t, string(withErrors), "#  - invalid YAML: boom")
+	assert.Equal(t, body, stripAgentYAMLErrors(withErrors))
+	assert.Equal(t, body, stripAgentYAMLErrors(withAgentYAMLErrors(withErrors, errors.New("again"))))
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+)
+
+// newAgentEnvExportCommand prints agent environment variables in a reusable format
+func newAgentEnvExportCommand(cfg *config.Config) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export [uuid]",
+		Short: "Export the environment variables of an agent",
+		Long: `Print the environment variables of an agent. The dotenv output can be fed back
+with --env-file to 'agent create', 'agent edit' or an inline chat agent.`,
+		Example: `  kubiya agent env export abc-123 > .env
+  kubiya agent edit def-456 --env-file .env
+  kubiya agent env export abc-123 --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := getAgentEnvVars(cmd, cfg, args[0])
+			if err != nil {
+				return err
+			}
+			if env == nil {
+				env = map[string]string{}
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "dotenv", "env":
+				return writeDotenv(out, env)
+			case "json":
+				return output.EncodeJSON(out, env)
+			case "yaml":
+				return yaml.NewEncoder(out).Encode(env)
+			default:
+				return fmt.Errorf("unsupported format %q (must be dotenv, json or yaml)", format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "dotenv", "Output format (dotenv|json|yaml)")
+	return cmd
+}
+
+// getAgentEnvVars returns the environment variables configured on an agent
+func getAgentEnvVars(cmd *cobra.Command, cfg *config.Config, agentID string) (map[string]string, error) {
+	agent, err := loadAgentEnv(cmd, cfg, agentID)
+	if err != nil {
+		return nil, err
+	}
+	return agent.Environment, nil
+}
+
+// agentEnv is the environment of an agent, read with the V1 API or from the
+// V2 execution_environment.env_vars
+type agentEnv struct {
+	Name        string
+	Environment map[string]string
+	// save replaces the environment variables of the agent
+	save func(ctx context.Context, env map[string]string) error
+}
+
+// loadAgentEnv reads the environment variables of an agent with the API of
+// the current context
+func loadAgentEnv(cmd *cobra.Command, cfg *config.Config, agentID string) (*agentEnv, error) {
+	if cfg.UseV1API {
+		client := kubiya.NewClient(cfg)
+		agent, err := client.GetAgent(cmd.Context(), agentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent: %w", err)
+		}
+		return &agentEnv{
+			Name:        agent.Name,
+			Environment: agent.Environment,
+			save: func(ctx context.Context, env map[string]string) error {
+				_, err := client.UpdateAgentRaw(ctx, agentID, map[string]interface{}{
+					"name":                  agent.Name,
+					"description":           agent.Description,
+					"instruction_type":      agent.InstructionType,
+					"llm_model":             agent.LLMModel,
+					"sources":               agent.Sources,
+					"environment_variables": env,
+					"secrets":               agent.Secrets,
+					"allowed_groups":        agent.AllowedGroups,
+					"allowed_users":         agent.AllowedUsers,
+					"owners":                agent.Owners,
+					"runners":               agent.Runners,
+					"is_debug_mode":         agent.IsDebugMode,
+					"ai_instructions":       agent.AIInstructions,
+					"image":                 agent.Image,
+					"managed_by":            agent.ManagedBy,
+					"integrations":          agent.Integrations,
+					"links":                 agent.Links,
+					"tools":                 agent.Tools,
+					"tasks":                 agent.Tasks,
+					"tags":                  agent.Tags,
+				})
+				return err
+			},
+		}, nil
+	}
+
+	client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create control plane client: %w", err)
+	}
+	agent, err := client.GetAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	var execEnv entities.ExecutionEnvironment
+	if agent.ExecutionEnvironment != nil {
+		execEnv = *agent.ExecutionEnvironment
+	}
+	env := execEnv.EnvVars
+	if env == nil {
+		env = map[string]string{}
+	}
+	return &agentEnv{
+		Name:        agent.Name,
+		Environment: env,
+		save: func(ctx context.Context, env map[string]string) error {
+			// Secrets and integrations share execution_environment and are kept
+			execEnv.EnvVars = env
+			_, err := client.UpdateAgentExecutionEnvironment(agentID, execEnv)
+			return err
+		},
+	}, nil
+}
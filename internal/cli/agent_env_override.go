@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// defaultEnvOverrideTTL is how long stored overrides apply, so that testing
+// values do not linger
+const defaultEnvOverrideTTL = 24 * time.Hour
+
+// envOverrideKeyEnv holds a hex or raw 32-byte key used instead of the key
+// file, e.g. from a password manager
+const envOverrideKeyEnv = "KUBIYA_ENV_OVERRIDES_KEY"
+
+// envOverrides are the local environment overrides of one agent
+type envOverrides struct {
+	Env       map[string]string `json:"env"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// envOverrideStore keeps the overrides of each agent in an AES-GCM encrypted
+// file in ~/.kubiya/env-overrides. The key is generated on first use into a
+// file only the user can read, unless KUBIYA_ENV_OVERRIDES_KEY is set.
+type envOverrideStore struct {
+	dir string
+}
+
+func newEnvOverrideStore() (*envOverrideStore, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return &envOverrideStore{dir: filepath.Join(homeDir, config.KUBIYA_DIR, "env-overrides")}, nil
+}
+
+func (s *envOverrideStore) path(agent string) string {
+	return filepath.Join(s.dir, filepath.Base(agent)+".enc")
+}
+
+// key returns the encryption key, creating the key file when create is set
+func (s *envOverrideStore) key(create bool) ([]byte, error) {
+	if k := os.Getenv(envOverrideKeyEnv); k != "" {
+		if len(k) == 64 {
+			if key, err := hex.DecodeString(k); err == nil && len(key) == 32 {
+				return key, nil
+			}
+		}
+		if len(k) != 32 {
+			return nil, fmt.Errorf("%s must be 32 bytes or 64 hex characters", envOverrideKeyEnv)
+		}
+		return []byte(k), nil
+	}
+
+	keyFile := filepath.Join(s.dir, ".key")
+	key, err := os.ReadFile(keyFile)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid key file %s", keyFile)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !create {
+		return nil, fmt.Errorf("failed to read the env override key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create env override directory: %w", err)
+	}
+	if err := os.WriteFile(keyFile, key, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the env override key: %w", err)
+	}
+	return key, nil
+}
+
+// Get returns the unexpired overrides of an agent, nil when there are none
+func (s *envOverrideStore) Get(agent string) (*envOverrides, error) {
+	data, err := os.ReadFile(s.path(agent))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env overrides: %w", err)
+	}
+	key, err := s.key(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid env overrides %s", s.path(agent))
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(agent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt env overrides of %s (wrong key?)", agent)
+	}
+
+	var o envOverrides
+	if err := json.Unmarshal(plain, &o); err != nil {
+		return nil, fmt.Errorf("invalid env overrides %s: %w", s.path(agent), err)
+	}
+	if !o.ExpiresAt.IsZero() && time.Now().After(o.ExpiresAt) {
+		return nil, nil
+	}
+	return &o, nil
+}
+
+// Save encrypts and writes the overrides of an agent, removing the file
+// when there are none left
+func (s *envOverrideStore) Save(agent string, o *envOverrides) error {
+	if o == nil || len(o.Env) == 0 {
+		return s.Delete(agent)
+	}
+	plain, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// The agent is authenticated data, so a file cannot be moved to another agent
+	data := gcm.Seal(nonce, nonce, plain, []byte(agent))
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create env override directory: %w", err)
+	}
+	if err := os.WriteFile(s.path(agent), data, 0600); err != nil {
+		return fmt.Errorf("failed to write env overrides: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the overrides of an agent
+func (s *envOverrideStore) Delete(agent string) error {
+	if err := os.Remove(s.path(agent)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete env overrides: %w", err)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// parseEnvAssignments parses KEY=VALUE arguments
+func parseEnvAssignments(assignments []string) (map[string]string, error) {
+	env := make(map[string]string, len(assignments))
+	for _, a := range assignments {
+		key, value, ok := strings.Cut(a, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid environment override %q (expected KEY=VALUE)", a)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// chatEnvOverrides returns the overrides of a chat: the stored overrides of
+// the agent, looked up by each of its names, with the flag values on top
+func chatEnvOverrides(flags []string, agents ...string) (map[string]string, error) {
+	env := map[string]string{}
+	store, err := newEnvOverrideStore()
+	if err != nil {
+		return nil, err
+	}
+	for _, agent := range agents {
+		if agent == "" {
+			continue
+		}
+		o, err := store.Get(agent)
+		if err != nil {
+			return nil, err
+		}
+		if o != nil {
+			for k, v := range o.Env {
+				env[k] = v
+			}
+		}
+	}
+
+	fromFlags, err := parseEnvAssignments(flags)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range fromFlags {
+		env[k] = v
+	}
+	return env, nil
+}
+
+func newAgentEnvOverrideCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "override",
+		Aliases: []string{"overrides"},
+		Short:   "🧪 Manage local environment overrides for chat",
+		Long: `Manage environment variables that override those of an agent in your own
+chat sessions, for testing, without changing the agent for anyone else.
+
+Overrides are stored encrypted in ~/.kubiya/env-overrides and sent with every
+'kubiya chat' message to the agent until they expire. For a single run, use
+'kubiya chat --override-env KEY=VALUE' instead.`,
+	}
+
+	cmd.AddCommand(
+		newAgentEnvOverrideSetCommand(),
+		newAgentEnvOverrideListCommand(),
+		newAgentEnvOverrideUnsetCommand(),
+		newAgentEnvOverrideClearCommand(),
+	)
+	return cmd
+}
+
+func newAgentEnvOverrideSetCommand() *cobra.Command {
+	var ttl time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "set AGENT KEY=VALUE...",
+		Short: "➕ Override environment variables of an agent in your chats",
+		Example: `  kubiya agent env override set devbot LOG_LEVEL=trace FEATURE_X=on
+  kubiya agent env override set devbot LOG_LEVEL=trace --ttl 2h`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := parseEnvAssignments(args[1:])
+			if err != nil {
+				return err
+			}
+			if ttl <= 0 {
+				return fmt.Errorf("--ttl must be positive")
+			}
+			store, err := newEnvOverrideStore()
+			if err != nil {
+				return err
+			}
+			o, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if o == nil {
+				o = &envOverrides{Env: map[string]string{}}
+			}
+			for k, v := range env {
+				o.Env[k] = v
+			}
+			o.ExpiresAt = time.Now().Add(ttl).UTC()
+			if err := store.Save(args[0], o); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d override(s) for %s until %s\n", style.SuccessStyle.Render("✅"),
+				len(o.Env), style.HighlightStyle.Render(args[0]), o.ExpiresAt.Local().Format(time.RFC822))
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", defaultEnvOverrideTTL, "How long the overrides of the agent apply")
+	return cmd
+}
+
+func newAgentEnvOverrideListCommand() *cobra.Command {
+	var (
+		outputFormat string
+		showValues   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "list AGENT",
+		Aliases: []string{"ls"},
+		Short:   "📋 List the local overrides of an agent",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := newEnvOverrideStore()
+			if err != nil {
+				return err
+			}
+			o, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if o == nil {
+				o = &envOverrides{Env: map[string]string{}}
+			}
+			if !showValues {
+				masked := make(map[string]string, len(o.Env))
+				for k := range o.Env {
+					masked[k] = "********"
+				}
+				o = &envOverrides{Env: masked, ExpiresAt: o.ExpiresAt}
+			}
+
+			if outputFormat == "json" {
+				return printJSON(o)
+			}
+			return printEnvOverrides(cmd.OutOrStdout(), args[0], o)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "Show the values instead of masking them")
+	return cmd
+}
+
+func printEnvOverrides(w io.Writer, agent string, o *envOverrides) error {
+	if len(o.Env) == 0 {
+		fmt.Fprintf(w, "No local overrides for %s\n", agent)
+		return nil
+	}
+	keys := make([]string, 0, len(o.Env))
+	for k := range o.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE")
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", k, o.Env[k])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nExpires %s\n", o.ExpiresAt.Local().Format(time.RFC822))
+	return nil
+}
+
+func newAgentEnvOverrideUnsetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "unset AGENT KEY...",
+		Aliases: []string{"rm"},
+		Short:   "➖ Remove local overrides of an agent",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := newEnvOverrideStore()
+			if err != nil {
+				return err
+			}
+			o, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if o == nil {
+				return fmt.Errorf("no local overrides for %s", args[0])
+			}
+			for _, k := range args[1:] {
+				if _, ok := o.Env[k]; !ok {
+					return fmt.Errorf("%s has no local override of %s", args[0], k)
+				}
+				delete(o.Env, k)
+			}
+			if err := store.Save(args[0], o); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d override(s) left for %s\n", style.SuccessStyle.Render("✅"), len(o.Env), args[0])
+			return nil
+		},
+	}
+}
+
+func newAgentEnvOverrideClearCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear AGENT",
+		Short: "🗑️ Remove all local overrides of an agent",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := newEnvOverrideStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Delete(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Local overrides of %s cleared\n", style.SuccessStyle.Render("✅"), args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvOverrideStoreRoundTrip(t *testing.T) {
+	store := &envOverrideStore{dir: t.TempDir()}
+
+	o, err := store.Get("devbot")
+	require.NoError(t, err)
+	assert.Nil(t, o)
+
+	require.NoError(t, store.Save("devbot", &envOverrides{
+		Env:       map[string]string{"LOG_LEVEL": "trace", "FEATURE_X": "on"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	data, err := os.ReadFile(store.path("devbot"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "trace", "overrides must be stored encrypted")
+
+	o, err = store.Get("devbot")
+	require.NoError(t, err)
+	require.NotNil(t, o)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "trace", "FEATURE_X": "on"}, o.Env)
+
+	info, err := os.Stat(store.path("devbot"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestEnvOverrideStoreKeyFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	store := &envOverrideStore{dir: dir}
+	overrides := &envOverrides{
+		Env:       map[string]string{"LOG_LEVEL": "trace"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	for _, key := range []string{
+		strings.Repeat("0123456789abcdef", 4), // hex
+		strings.Repeat("k", 32),               // raw
+	} {
+		t.Setenv(envOverrideKeyEnv, key)
+		require.NoError(t, store.Save("devbot", overrides), key)
+		o, err := store.Get("devbot")
+		require.NoError(t, err, key)
+		require.NotNil(t, o)
+		assert.Equal(t, overrides.Env, o.Env)
+	}
+	_, err := os.Stat(filepath.Join(dir, ".key"))
+	assert.True(t, os.IsNotExist(err), "no key file is created when the key is set")
+
+	for _, key := range []string{"short", strings.Repeat("zz", 32)} {
+		t.Setenv(envOverrideKeyEnv, key)
+		err := store.Save("devbot", overrides)
+		require.Error(t, err, key)
+		assert.Contains(t, err.Error(), envOverrideKeyEnv)
+	}
+}
+
+func TestEnvOverrideStoreRejectsTampering(t *testing.T) {
+	store := &envOverrideStore{dir: t.TempDir()}
+	require.NoError(t, store.Save("devbot", &envOverrides{
+		Env:       map[string]string{"LOG_LEVEL": "trace"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	// A file copied to another agent does not decrypt
+	data, err := os.ReadFile(store.path("devbot"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(store.path("other"), data, 0600))
+	_, err = store.Get("other")
+	assert.Error(t, err)
+
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(store.path("devbot"), data, 0600))
+	_, err = store.Get("devbot")
+	assert.Error(t, err)
+}
+
+func TestEnvOverrideStoreExpiry(t *testing.T) {
+	store := &envOverrideStore{dir: t.TempDir()}
+	require.NoError(t, store.Save("devbot", &envOverrides{
+		Env:       map[string]string{"LOG_LEVEL": "trace"},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	o, err := store.Get("devbot")
+	require.NoError(t, err)
+	assert.Nil(t, o)
+}
+
+func TestEnvOverrideStoreSaveEmptyDeletes(t *testing.T) {
+	store := &envOverrideStore{dir: t.TempDir()}
+	require.NoError(t, store.Save("devbot", &envOverrides{
+		Env:       map[string]string{"LOG_LEVEL": "trace"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, store.Save("devbot", &envOverrides{Env: map[string]string{}}))
+
+	_, err := os.Stat(store.path("devbot"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseEnvAssignments(t *testing.T) {
+	env, err := parseEnvAssignments([]string{"LOG_LEVEL=trace", "URL=http://x?a=b", "EMPTY="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "trace", "URL": "http://x?a=b", "EMPTY": ""}, env)
+
+	for _, bad := range []string{"LOG_LEVEL", "=trace", "LOG LEVEL=trace"} {
+		_, err := parseEnvAssignments([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestChatEnvOverridesFlagsWin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	store, err := newEnvOverrideStore()
+	require.NoError(t, err)
+	require.NoError(t, store.Save("devbot", &envOverrides{
+		Env:       map[string]string{"LOG_LEVEL": "debug", "FEATURE_X": "on"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	env, err := chatEnvOverrides([]string{"LOG_LEVEL=trace"}, "devbot", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "trace", "FEATURE_X": "on"}, env)
+}
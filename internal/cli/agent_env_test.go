@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+)
+
+func TestAgentEnvV2(t *testing.T) {
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/agents/abc-123", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"id":"abc-123","name":"devbot","execution_environment":{"env_vars":{"LOG_LEVEL":"info"},"secrets":["GH_TOKEN"]}}`))
+		case http.MethodPatch:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patched))
+			w.Write([]byte(`{"id":"abc-123","name":"devbot"}`))
+		default:
+			t.Errorf("unexpected %s", r.Method)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", server.URL)
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	agent, err := loadAgentEnv(cmd, &config.Config{APIKey: "key"}, "abc-123")
+	require.NoError(t, err)
+	assert.Equal(t, "devbot", agent.Name)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "info"}, agent.Environment)
+
+	// Removing the last variable sends an empty map, and keeps the secrets
+	require.NoError(t, agent.save(context.Background(), map[string]string{}))
+	assert.Equal(t, map[string]interface{}{
+		"execution_environment": map[string]interface{}{
+			"env_vars":     map[string]interface{}{},
+			"secrets":      []interface{}{"GH_TOKEN"},
+			"integrations": []interface{}{},
+		},
+	}, patched)
+}
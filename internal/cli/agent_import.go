@@ -0,0 +1,451 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	"github.com/kubiyabot/cli/internal/httpclient"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+const terraformAgentType = "kubiya_agent"
+
+// terraformResource is a resource instance read from a Terraform state or plan
+type terraformResource struct {
+	Address string
+	Type    string
+	Values  map[string]interface{}
+}
+
+// terraformModule mirrors the module layout of `terraform show -json` output
+type terraformModule struct {
+	Resources []struct {
+		Address string                 `json:"address"`
+		Mode    string                 `json:"mode"`
+		Type    string                 `json:"type"`
+		Values  map[string]interface{} `json:"values"`
+	} `json:"resources"`
+	ChildModules []terraformModule `json:"child_modules"`
+}
+
+func (m terraformModule) collect(out []terraformResource) []terraformResource {
+	for _, r := range m.Resources {
+		if r.Mode == "managed" || r.Mode == "" {
+			out = append(out, terraformResource{Address: r.Address, Type: r.Type, Values: r.Values})
+		}
+	}
+	for _, child := range m.ChildModules {
+		out = child.collect(out)
+	}
+	return out
+}
+
+// loadTerraformResources reads managed resources from a raw state file
+// (terraform.tfstate), `terraform show -json` output or a JSON plan
+func loadTerraformResources(data []byte) ([]terraformResource, error) {
+	var doc struct {
+		Version   int `json:"version"`
+		Resources []struct {
+			Module    string `json:"module"`
+			Mode      string `json:"mode"`
+			Type      string `json:"type"`
+			Name      string `json:"name"`
+			Instances []struct {
+				IndexKey   interface{}            `json:"index_key"`
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+		Values *struct {
+			RootModule terraformModule `json:"root_module"`
+		} `json:"values"`
+		PlannedValues *struct {
+			RootModule terraformModule `json:"root_module"`
+		} `json:"planned_values"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Terraform JSON: %w", err)
+	}
+
+	var resources []terraformResource
+	switch {
+	case doc.PlannedValues != nil:
+		resources = doc.PlannedValues.RootModule.collect(nil)
+	case doc.Values != nil:
+		resources = doc.Values.RootModule.collect(nil)
+	case doc.Version > 0:
+		for _, r := range doc.Resources {
+			if r.Mode != "managed" {
+				continue
+			}
+			base := r.Type + "." + r.Name
+			if r.Module != "" {
+				base = r.Module + "." + base
+			}
+			for _, inst := range r.Instances {
+				address := base
+				switch key := inst.IndexKey.(type) {
+				case float64:
+					address = fmt.Sprintf("%s[%d]", base, int(key))
+				case string:
+					address = fmt.Sprintf("%s[%q]", base, key)
+				}
+				resources = append(resources, terraformResource{Address: address, Type: r.Type, Values: inst.Attributes})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized Terraform JSON: expected a state file, 'terraform show -json' output or a plan")
+	}
+
+	return resources, nil
+}
+
+// findTerraformAgent returns the kubiya_agent resource at address
+func findTerraformAgent(resources []terraformResource, address string) (*terraformResource, error) {
+	var available []string
+	for i, r := range resources {
+		if r.Type != terraformAgentType {
+			continue
+		}
+		if r.Address == address {
+			return &resources[i], nil
+		}
+		available = append(available, r.Address)
+	}
+
+	if len(available) == 0 {
+		return nil, fmt.Errorf("no %s resources found", terraformAgentType)
+	}
+	sort.Strings(available)
+	return nil, fmt.Errorf("resource %q not found (available: %s)", address, strings.Join(available, ", "))
+}
+
+// agentRequestFromTerraform maps kubiya_agent attributes to an agent create
+// request. The returned ID is the agent ID recorded in the state, if any.
+func agentRequestFromTerraform(values map[string]interface{}) (*entities.AgentCreateRequest, string) {
+	str := func(key string) *string {
+		if v, ok := values[key].(string); ok && v != "" {
+			return &v
+		}
+		return nil
+	}
+	list := func(key string) []string {
+		items, _ := values[key].([]interface{})
+		var out []string
+		for _, item := range items {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+
+	req := &entities.AgentCreateRequest{
+		Description:  str("description"),
+		SystemPrompt: str("instructions"),
+		Model:        str("model"),
+	}
+	if name := str("name"); name != nil {
+		req.Name = *name
+	}
+
+	env := &entities.ExecutionEnvironment{
+		Secrets:      list("secrets"),
+		Integrations: list("integrations"),
+	}
+	if vars, ok := values["environment_variables"].(map[string]interface{}); ok && len(vars) > 0 {
+		env.EnvVars = make(map[string]string, len(vars))
+		for k, v := range vars {
+			env.EnvVars[k] = fmt.Sprint(v)
+		}
+	}
+	if len(env.Secrets) > 0 || len(env.Integrations) > 0 || len(env.EnvVars) > 0 {
+		req.ExecutionEnvironment = env
+	}
+
+	id, _ := values["id"].(string)
+	return req, id
+}
+
+func newAgentImportCommand(cfg *config.Config) *cobra.Command {
+	var (
+		stateFile   string
+		address     string
+		openAPIFile string
+		openAPIOpts openAPIToolOptions
+		name        string
+		runner      string
+		dryRun      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "📥 Import an agent from Terraform or an OpenAPI spec",
+		Long: `Create or reconcile an agent from a kubiya_agent resource in a Terraform state
+or plan, or create an agent that calls an HTTP API from its OpenAPI spec.
+
+The Terraform file can be a raw state file (terraform.tfstate), the output of
+'terraform show -json' or a JSON plan ('terraform show -json plan.out').
+When the agent recorded in the state still exists it is updated in place,
+otherwise an agent with the same name is updated, or a new one is created.
+
+An OpenAPI 3 or Swagger 2 spec, as a file or URL in YAML or JSON, becomes a
+source with a tool per operation (or per tag with --group-by tag) and an agent
+that uses it. Path, query and header parameters and the properties of JSON
+request bodies become tool args. With --secret the tools send the named
+secret as the spec's security scheme: an API key header or query parameter,
+basic credentials (user:password) or a bearer token.`,
+		Example: `  # Import from a state file
+  kubiya agent import --from-terraform-state terraform.tfstate --address kubiya_agent.ops
+
+  # Preview what a plan would create
+  terraform show -json plan.out > plan.json
+  kubiya agent import --from-terraform-state plan.json --address module.agents.kubiya_agent.ops --dry-run
+
+  # Create an agent with a tool per operation of an API
+  kubiya agent import --from-openapi petstore.yaml --base-url https://petstore.example.com/v1 --secret PETSTORE_TOKEN
+
+  # Preview the tools, one per tag
+  kubiya agent import --from-openapi https://petstore3.swagger.io/api/v3/openapi.json --group-by tag --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if openAPIFile != "" {
+				return importOpenAPIAgent(cmd, cfg, openAPIFile, name, runner, openAPIOpts, dryRun)
+			}
+			if address == "" {
+				return fmt.Errorf("--address is required with --from-terraform-state")
+			}
+
+			data, err := os.ReadFile(stateFile)
+			if err != nil {
+				return fmt.Errorf("failed to read Terraform file: %w", err)
+			}
+
+			resources, err := loadTerraformResources(data)
+			if err != nil {
+				return err
+			}
+			resource, err := findTerraformAgent(resources, address)
+			if err != nil {
+				return err
+			}
+
+			req, stateID := agentRequestFromTerraform(resource.Values)
+			if req.Name == "" {
+				return fmt.Errorf("resource %s has no name", address)
+			}
+
+			client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+			if err != nil {
+				return fmt.Errorf("failed to create control plane client: %w", err)
+			}
+
+			existingID, err := findAgentToReconcile(client, stateID, req.Name)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				action := "create"
+				if existingID != "" {
+					action = "update " + existingID
+				}
+				fmt.Fprintf(out, "Would %s agent %s from %s:\n", action, req.Name, address)
+				return output.EncodeJSON(out, req)
+			}
+
+			var agent *entities.Agent
+			if existingID != "" {
+				agent, err = client.UpdateAgent(existingID, &entities.AgentUpdateRequest{
+					Name:                 &req.Name,
+					Description:          req.Description,
+					Model:                req.Model,
+					SystemPrompt:         req.SystemPrompt,
+					ExecutionEnvironment: req.ExecutionEnvironment,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to update agent: %w", err)
+				}
+				fmt.Fprintf(out, "%s Agent %s reconciled from %s (ID: %s)\n",
+					style.SuccessStyle.Render("✓"),
+					style.HighlightStyle.Render(agent.Name),
+					address, agent.ID)
+			} else {
+				agent, err = client.CreateAgent(req)
+				if err != nil {
+					return fmt.Errorf("failed to create agent: %w", err)
+				}
+				fmt.Fprintf(out, "%s Agent %s created from %s (ID: %s)\n",
+					style.SuccessStyle.Render("✓"),
+					style.HighlightStyle.Render(agent.Name),
+					address, agent.ID)
+			}
+
+			fmt.Fprintf(out, "  To manage it with the CLI only, run: terraform state rm %s\n", address)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&stateFile, "from-terraform-state", "", "Terraform state, 'terraform show -json' output or JSON plan")
+	cmd.Flags().StringVar(&address, "address", "", "Address of the kubiya_agent resource (e.g. kubiya_agent.ops)")
+	cmd.Flags().StringVar(&openAPIFile, "from-openapi", "", "OpenAPI 3 or Swagger 2 spec (file or URL) to generate tools from")
+	cmd.Flags().StringVar(&openAPIOpts.BaseURL, "base-url", "", "Base URL of the API (default: the first server of the spec)")
+	cmd.Flags().StringVar(&openAPIOpts.GroupBy, "group-by", "operation", "Generate a tool per operation or per tag (operation|tag)")
+	cmd.Flags().StringVar(&openAPIOpts.Secret, "secret", "", "Secret with the credentials of the API")
+	cmd.Flags().BoolVar(&openAPIOpts.IncludeDeprecated, "include-deprecated", false, "Generate tools for deprecated operations too")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the agent and source (default: the title of the spec)")
+	cmd.Flags().StringVar(&runner, "runner", "", "Runner for the tools (default: the first healthy runner)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the agent that would be created or updated")
+	cmd.MarkFlagsMutuallyExclusive("from-terraform-state", "from-openapi")
+	cmd.MarkFlagsOneRequired("from-terraform-state", "from-openapi")
+
+	return cmd
+}
+
+// findAgentToReconcile returns the ID of the agent an import should update:
+// the agent recorded in the state if it still exists, otherwise an agent with
+// the same name. An empty ID means a new agent is created.
+func findAgentToReconcile(client *controlplane.Client, stateID, name string) (string, error) {
+	if stateID != "" {
+		if agent, err := client.GetAgent(stateID); err == nil {
+			return agent.ID, nil
+		}
+	}
+
+	agents, err := client.ListAgents()
+	if err != nil {
+		return "", fmt.Errorf("failed to list agents: %w", err)
+	}
+	for _, agent := range agents {
+		if agent.Name == name {
+			return agent.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// readOpenAPISpec reads a spec from a file or an http(s) URL
+func readOpenAPISpec(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpclient.New(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", location, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// importOpenAPIAgent creates a source with the tools generated from an
+// OpenAPI spec and an agent that uses it
+func importOpenAPIAgent(cmd *cobra.Command, cfg *config.Config, location, name, runner string, opts openAPIToolOptions, dryRun bool) error {
+	if opts.GroupBy != "operation" && opts.GroupBy != "tag" {
+		return fmt.Errorf("invalid --group-by %q: use operation or tag", opts.GroupBy)
+	}
+
+	data, err := readOpenAPISpec(cmd.Context(), location)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+	spec, err := loadOpenAPISpec(data)
+	if err != nil {
+		return err
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = spec.baseURL(location)
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	tools, err := openAPITools(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = firstNonEmpty(spec.Info.Title, strings.TrimSuffix(filepath.Base(location), filepath.Ext(location)))
+	}
+	instructions := fmt.Sprintf("You operate the %s API at %s with your tools, one per API %s.", name, opts.BaseURL, opts.GroupBy)
+	if spec.Info.Description != "" {
+		instructions += "\n\n" + strings.TrimSpace(spec.Info.Description)
+	}
+
+	out := cmd.OutOrStdout()
+	if dryRun {
+		fmt.Fprintf(out, "Would create agent %s with a source of %d tools calling %s:\n", name, len(tools), opts.BaseURL)
+		return output.EncodeJSON(out, tools)
+	}
+
+	client := kubiya.NewClient(cfg)
+	if runner == "" {
+		if runner, err = getFirstHealthyRunner(cmd.Context(), client); err != nil {
+			runner = ""
+		}
+	}
+
+	sourceOpts := []kubiya.SourceOption{kubiya.WithInlineTools(tools), kubiya.WithName(name + " API")}
+	if runner != "" {
+		sourceOpts = append(sourceOpts, kubiya.WithRunner(runner))
+	}
+	source, err := client.CreateSource(cmd.Context(), "", sourceOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create source: %w", err)
+	}
+	fmt.Fprintf(out, "%s Source %s created with %d tools (UUID: %s)\n",
+		style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(source.Name), len(tools), source.UUID)
+
+	agent := kubiya.Agent{
+		Name:            name,
+		Description:     firstNonEmpty(firstLine(spec.Info.Description), fmt.Sprintf("Calls the %s API", name)),
+		InstructionType: "tools",
+		AIInstructions:  instructions,
+		Sources:         []string{source.UUID},
+		Secrets:         []string{},
+		Integrations:    []string{},
+		Owners:          []string{},
+		AllowedUsers:    []string{},
+		AllowedGroups:   []string{},
+		Runners:         []string{},
+		Image:           "ghcr.io/kubiyabot/kubiya-agent:stable",
+		Links:           []string{},
+		Tools:           []string{},
+		Tasks:           []string{},
+		Tags:            []string{"openapi"},
+	}
+	if opts.Secret != "" {
+		agent.Secrets = []string{opts.Secret}
+	}
+	if runner != "" {
+		agent.Runners = []string{runner}
+	}
+	created, err := client.CreateAgent(cmd.Context(), agent)
+	if err != nil {
+		return fmt.Errorf("failed to create agent (source %s was created): %w", source.UUID, err)
+	}
+	fmt.Fprintf(out, "%s Agent %s created from %s (UUID: %s)\n",
+		style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(created.Name), location, created.UUID)
+	if opts.Secret != "" {
+		fmt.Fprintf(out, "  The tools read the credentials from the secret %s; create it with: kubiya secret create %s --value ...\n", opts.Secret, opts.Secret)
+	}
+	return nil
+}
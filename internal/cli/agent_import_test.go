@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTerraformResources(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "raw state",
+			data: `{"version":4,"resources":[
+				{"mode":"managed","type":"kubiya_agent","name":"ops","instances":[{"attributes":{"name":"ops"}}]},
+				{"module":"module.team","mode":"managed","type":"kubiya_agent","name":"bot","instances":[{"index_key":0,"attributes":{}},{"index_key":"eu","attributes":{}}]},
+				{"mode":"data","type":"kubiya_runner","name":"r","instances":[{"attributes":{}}]}]}`,
+			want: []string{"kubiya_agent.ops", "module.team.kubiya_agent.bot[0]", `module.team.kubiya_agent.bot["eu"]`},
+		},
+		{
+			name: "show json",
+			data: `{"format_version":"1.0","values":{"root_module":{"resources":[{"address":"kubiya_agent.ops","mode":"managed","type":"kubiya_agent","values":{}}],
+				"child_modules":[{"resources":[{"address":"module.a.kubiya_agent.x","mode":"managed","type":"kubiya_agent","values":{}}]}]}}}`,
+			want: []string{"kubiya_agent.ops", "module.a.kubiya_agent.x"},
+		},
+		{
+			name: "plan",
+			data: `{"format_version":"1.2","planned_values":{"root_module":{"resources":[{"address":"kubiya_agent.new","mode":"managed","type":"kubiya_agent","values":{}}]}}}`,
+			want: []string{"kubiya_agent.new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, err := loadTerraformResources([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, r := range resources {
+				got = append(got, r.Address)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected addresses %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := loadTerraformResources([]byte(`{"foo":1}`)); err == nil {
+		t.Error("expected an error for unrecognized JSON")
+	}
+}
+
+func TestFindTerraformAgentListsAvailable(t *testing.T) {
+	resources := []terraformResource{
+		{Address: "kubiya_agent.ops", Type: "kubiya_agent"},
+		{Address: "kubiya_source.tools", Type: "kubiya_source"},
+	}
+
+	if _, err := findTerraformAgent(resources, "kubiya_agent.ops"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := findTerraformAgent(resources, "kubiya_agent.missing")
+	if err == nil || !strings.Contains(err.Error(), "available: kubiya_agent.ops") {
+		t.Errorf("expected available agents in error, got %v", err)
+	}
+}
+
+func TestAgentRequestFromTerraform(t *testing.T) {
+	req, id := agentRequestFromTerraform(map[string]interface{}{
+		"id":                    "agent-1",
+		"name":                  "ops",
+		"description":           "",
+		"instructions":          "You are an ops agent",
+		"model":                 "gpt-4o",
+		"secrets":               []interface{}{"GH_TOKEN"},
+		"integrations":          []interface{}{"github", ""},
+		"environment_variables": map[string]interface{}{"LOG_LEVEL": "debug"},
+	})
+
+	if id != "agent-1" || req.Name != "ops" {
+		t.Errorf("unexpected id %q / name %q", id, req.Name)
+	}
+	if req.Description != nil {
+		t.Errorf("expected empty description to be omitted, got %q", *req.Description)
+	}
+	if req.SystemPrompt == nil || *req.SystemPrompt != "You are an ops agent" {
+		t.Errorf("expected instructions to map to the system prompt, got %v", req.SystemPrompt)
+	}
+	env := req.ExecutionEnvironment
+	if env == nil || !reflect.DeepEqual(env.Integrations, []string{"github"}) || env.EnvVars["LOG_LEVEL"] != "debug" {
+		t.Errorf("unexpected execution environment %+v", env)
+	}
+}
@@ -0,0 +1,386 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// modelContextLimits are the context windows, in tokens, of known models.
+// Models are matched by the longest key their name contains.
+var modelContextLimits = map[string]int{
+	"gpt-4":         8192,
+	"gpt-4-32k":     32768,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4.1":       1047576,
+	"gpt-3.5-turbo": 16385,
+	"o1":            200000,
+	"o3":            200000,
+	"claude":        200000,
+	"gemini":        1048576,
+	"llama-3":       128000,
+	"mistral":       32768,
+}
+
+// Outcomes of migrating one agent
+const (
+	modelMigrated      = "migrated"
+	modelWouldMigrate  = "would-migrate"
+	modelSkippedLimits = "skipped"
+	modelMigrateFailed = "failed"
+)
+
+// agentModelMigrator is the part of kubiya.Client used to migrate models
+type agentModelMigrator interface {
+	ListAgents(ctx context.Context) ([]kubiya.Agent, error)
+	GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error)
+	UpdateAgent(ctx context.Context, uuid string, agent kubiya.Agent) (*kubiya.Agent, error)
+}
+
+// modelMigrationOptions selects the agents and the target model
+type modelMigrationOptions struct {
+	From         string
+	To           string
+	Selector     map[string]string
+	ContextLimit int // tokens, 0 to look the target model up
+	DryRun       bool
+	Force        bool // migrate agents whose instructions exceed the limit
+}
+
+// modelMigrationEntry records the migration of one agent
+type modelMigrationEntry struct {
+	Agent              string `json:"agent"`
+	UUID               string `json:"uuid"`
+	Before             string `json:"before"`
+	After              string `json:"after"`
+	InstructionsTokens int    `json:"instructions_tokens"`
+	Status             string `json:"status"`
+	Warning            string `json:"warning,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// modelMigrationReport is written at the end of a migration
+type modelMigrationReport struct {
+	From         string                `json:"from"`
+	To           string                `json:"to"`
+	Selector     string                `json:"selector,omitempty"`
+	ContextLimit int                   `json:"context_limit,omitempty"`
+	DryRun       bool                  `json:"dry_run"`
+	StartedAt    time.Time             `json:"started_at"`
+	Agents       []modelMigrationEntry `json:"agents"`
+}
+
+func newAgentModelMigrateCommand(cfg *config.Config) *cobra.Command {
+	var (
+		from         string
+		to           string
+		selector     string
+		contextLimit int
+		dryRun       bool
+		force        bool
+		yes          bool
+		reportFile   string
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "🔀 Move the agents using a model to another model",
+		Long: `Change the LLM model of every agent using --from to --to.
+
+--selector narrows the agents down to those having all the given tags, e.g.
+team=sre. Before an agent is changed, the size of its instructions is
+estimated and compared with the context window of the target model: agents
+whose instructions do not fit are skipped unless --force is set.
+
+The model of each agent before and after the migration is recorded in a JSON
+report, which can be used to roll the migration back.`,
+		Example: `  # Preview the migration of the SRE agents
+  kubiya agent model migrate --from azure/gpt-4 --to claude-sonnet-4 --selector team=sre --dry-run
+
+  # Migrate every agent using azure/gpt-4
+  kubiya agent model migrate --from azure/gpt-4 --to claude-sonnet-4 --yes --report migration.json
+
+  # Roll it back
+  kubiya agent model migrate --from claude-sonnet-4 --to azure/gpt-4 --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			if from == to {
+				return fmt.Errorf("--from and --to are the same model")
+			}
+			sel, err := parseAgentSelector(selector)
+			if err != nil {
+				return err
+			}
+			opts := modelMigrationOptions{From: from, To: to, Selector: sel, ContextLimit: contextLimit, DryRun: dryRun, Force: force}
+			if opts.ContextLimit == 0 {
+				opts.ContextLimit = modelContextLimit(to)
+			}
+
+			client := kubiya.NewClient(cfg)
+			out := cmd.OutOrStdout()
+			progress := out
+			if outputFormat == "json" {
+				progress = io.Discard
+			}
+
+			if !dryRun && !yes {
+				matched, err := selectAgentsForMigration(cmd.Context(), client, opts)
+				if err != nil {
+					return err
+				}
+				if len(matched) == 0 {
+					fmt.Fprintf(progress, "%s No agent uses %s\n", style.InfoStyle.Render("ℹ️"), from)
+					return nil
+				}
+				if !confirmYesNo(fmt.Sprintf("Change the model of %d agents from %s to %s?", len(matched), from, to)) {
+					return fmt.Errorf("migration cancelled")
+				}
+			}
+
+			report, err := migrateAgentModels(cmd.Context(), client, opts, progress)
+			if err != nil {
+				return err
+			}
+			report.Selector = selector
+
+			if reportFile == "" && !dryRun {
+				reportFile = fmt.Sprintf("model-migration-%s.json", report.StartedAt.Format("20060102-150405"))
+			}
+			if reportFile != "" {
+				var buf bytes.Buffer
+				if err := output.EncodeJSON(&buf, report); err != nil {
+					return err
+				}
+				if err := os.WriteFile(reportFile, buf.Bytes(), 0644); err != nil {
+					return fmt.Errorf("failed to write report: %w", err)
+				}
+			}
+
+			if outputFormat == "json" {
+				if err := output.EncodeJSON(out, report); err != nil {
+					return err
+				}
+			} else {
+				printModelMigration(out, report, reportFile)
+			}
+
+			failed := 0
+			for _, e := range report.Agents {
+				if e.Status == modelMigrateFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d agents failed to migrate", failed, len(report.Agents))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Model the agents use now")
+	cmd.Flags().StringVar(&to, "to", "", "Model to move the agents to")
+	cmd.Flags().StringVar(&selector, "selector", "", "Only migrate agents having these tags, e.g. team=sre,env=prod")
+	cmd.Flags().IntVar(&contextLimit, "context-limit", 0, "Context window of the target model in tokens (default: known limit of --to)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without changing anything")
+	cmd.Flags().BoolVar(&force, "force", false, "Also migrate agents whose instructions exceed the context window")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Report file (default: model-migration-<time>.json, none for --dry-run)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// parseAgentSelector parses "key=value,tag" selectors
+func parseAgentSelector(s string) (map[string]string, error) {
+	sel := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		if key = strings.TrimSpace(key); key == "" {
+			return nil, fmt.Errorf("invalid selector %q: expected key=value", part)
+		}
+		sel[key] = strings.TrimSpace(value)
+	}
+	return sel, nil
+}
+
+// agentMatchesSelector reports whether an agent has all the tags of the
+// selector, as "key=value" or "key:value" tags, or "key" for bare tags
+func agentMatchesSelector(agent kubiya.Agent, sel map[string]string) bool {
+	for key, value := range sel {
+		found := false
+		for _, tag := range agent.Tags {
+			if value == "" && tag == key || value != "" && (tag == key+"="+value || tag == key+":"+value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// modelContextLimit returns the context window of a model, 0 when unknown
+func modelContextLimit(model string) int {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	best, limit := "", 0
+	for key, l := range modelContextLimits {
+		if strings.Contains(name, key) && len(key) > len(best) {
+			best, limit = key, l
+		}
+	}
+	return limit
+}
+
+// estimateTokens approximates the tokens of a text, about 4 characters
+// per token
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+func selectAgentsForMigration(ctx context.Context, client agentModelMigrator, opts modelMigrationOptions) ([]kubiya.Agent, error) {
+	agents, err := client.ListAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	var matched []kubiya.Agent
+	for _, a := range agents {
+		if strings.EqualFold(a.LLMModel, opts.From) && agentMatchesSelector(a, opts.Selector) {
+			matched = append(matched, a)
+		}
+	}
+	return matched, nil
+}
+
+// migrateAgentModels moves the matching agents to the target model, one at
+// a time. Agents that fail are recorded and the migration goes on.
+func migrateAgentModels(ctx context.Context, client agentModelMigrator, opts modelMigrationOptions, progress io.Writer) (*modelMigrationReport, error) {
+	report := &modelMigrationReport{
+		From:         opts.From,
+		To:           opts.To,
+		ContextLimit: opts.ContextLimit,
+		DryRun:       opts.DryRun,
+		StartedAt:    time.Now(),
+		Agents:       []modelMigrationEntry{},
+	}
+	matched, err := selectAgentsForMigration(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, listed := range matched {
+		id := listed.UUID
+		if id == "" {
+			id = listed.ID
+		}
+		entry := modelMigrationEntry{Agent: listed.Name, UUID: id, Before: listed.LLMModel, After: listed.LLMModel}
+
+		// The listing may leave the instructions out
+		agent, err := client.GetAgent(ctx, id)
+		if err != nil {
+			entry.Status = modelMigrateFailed
+			entry.Error = err.Error()
+			fmt.Fprintf(progress, "%s %s: %v\n", style.ErrorStyle.Render("✗"), listed.Name, err)
+			report.Agents = append(report.Agents, entry)
+			continue
+		}
+
+		entry.InstructionsTokens = estimateTokens(agent.AIInstructions)
+		if opts.ContextLimit > 0 && entry.InstructionsTokens > opts.ContextLimit {
+			entry.Warning = fmt.Sprintf("instructions take about %d tokens, more than the %d of %s",
+				entry.InstructionsTokens, opts.ContextLimit, opts.To)
+			if !opts.Force {
+				entry.Status = modelSkippedLimits
+				fmt.Fprintf(progress, "%s %s: %s\n", style.WarningStyle.Render("⚠️  Skipped"), agent.Name, entry.Warning)
+				report.Agents = append(report.Agents, entry)
+				continue
+			}
+		}
+
+		if opts.DryRun {
+			entry.Status = modelWouldMigrate
+			entry.After = opts.To
+			report.Agents = append(report.Agents, entry)
+			continue
+		}
+
+		agent.LLMModel = opts.To
+		if _, err := client.UpdateAgent(ctx, id, *agent); err != nil {
+			entry.Status = modelMigrateFailed
+			entry.Error = err.Error()
+			fmt.Fprintf(progress, "%s %s: %v\n", style.ErrorStyle.Render("✗"), agent.Name, err)
+		} else {
+			entry.Status = modelMigrated
+			entry.After = opts.To
+			fmt.Fprintf(progress, "%s %s\n", style.SuccessStyle.Render("✓"), agent.Name)
+		}
+		report.Agents = append(report.Agents, entry)
+	}
+	return report, nil
+}
+
+func printModelMigration(w io.Writer, report *modelMigrationReport, reportFile string) {
+	if len(report.Agents) == 0 {
+		fmt.Fprintf(w, "%s No agent uses %s\n", style.InfoStyle.Render("ℹ️"), report.From)
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tBEFORE\tAFTER\tINSTRUCTIONS\tSTATUS")
+	counts := map[string]int{}
+	for _, e := range report.Agents {
+		counts[e.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t~%d tokens\t%s\n", e.Agent, e.Before, e.After, e.InstructionsTokens, e.Status)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if report.ContextLimit == 0 {
+		fmt.Fprintf(w, "%s\n", style.WarningStyle.Render(fmt.Sprintf(
+			"⚠️  The context window of %s is unknown, instructions were not checked (set --context-limit)", report.To)))
+	}
+	if report.DryRun {
+		fmt.Fprintf(w, "%s %d agents would move from %s to %s\n",
+			style.InfoStyle.Render("🔀"), counts[modelWouldMigrate], report.From, report.To)
+	} else {
+		fmt.Fprintf(w, "%s %d agents moved from %s to %s\n",
+			style.SuccessStyle.Render("✅"), counts[modelMigrated], report.From, report.To)
+	}
+	if n := counts[modelSkippedLimits]; n > 0 {
+		fmt.Fprintf(w, "   %s\n", style.WarningStyle.Render(fmt.Sprintf("%d skipped: instructions exceed the context window (use --force)", n)))
+	}
+	if n := counts[modelMigrateFailed]; n > 0 {
+		fmt.Fprintf(w, "   %s %d failed\n", style.ErrorStyle.Render("✗"), n)
+	}
+	if reportFile != "" {
+		fmt.Fprintf(w, "   %s\n", style.DimStyle.Render("Report: "+reportFile))
+	}
+}
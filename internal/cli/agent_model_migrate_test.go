@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeModelMigrator keeps agents in memory
+type fakeModelMigrator struct {
+	agents  []*kubiya.Agent
+	failing string
+	updates []string
+}
+
+func (f *fakeModelMigrator) ListAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	var agents []kubiya.Agent
+	for _, a := range f.agents {
+		listed := *a
+		listed.AIInstructions = ""
+		agents = append(agents, listed)
+	}
+	return agents, nil
+}
+
+func (f *fakeModelMigrator) GetAgent(ctx context.Context, id string) (*kubiya.Agent, error) {
+	for _, a := range f.agents {
+		if a.UUID == id {
+			copied := *a
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("agent not found: %s", id)
+}
+
+func (f *fakeModelMigrator) UpdateAgent(ctx context.Context, id string, agent kubiya.Agent) (*kubiya.Agent, error) {
+	if id == f.failing {
+		return nil, fmt.Errorf("server error")
+	}
+	f.updates = append(f.updates, id)
+	for i, a := range f.agents {
+		if a.UUID == id {
+			f.agents[i] = &agent
+		}
+	}
+	return &agent, nil
+}
+
+func TestMigrateAgentModels(t *testing.T) {
+	client := &fakeModelMigrator{failing: "a-4", agents: []*kubiya.Agent{
+		{UUID: "a-1", Name: "oncall", LLMModel: "azure/gpt-4", Tags: []string{"team=sre"}, AIInstructions: "Be brief"},
+		{UUID: "a-2", Name: "runbook", LLMModel: "azure/gpt-4", Tags: []string{"team:sre"}, AIInstructions: strings.Repeat("x", 400)},
+		{UUID: "a-3", Name: "billing", LLMModel: "azure/gpt-4", Tags: []string{"team=finance"}},
+		{UUID: "a-4", Name: "pager", LLMModel: "azure/gpt-4", Tags: []string{"team=sre"}},
+		{UUID: "a-5", Name: "new", LLMModel: "gpt-4o", Tags: []string{"team=sre"}},
+	}}
+	sel, err := parseAgentSelector("team=sre")
+	require.NoError(t, err)
+	opts := modelMigrationOptions{From: "azure/gpt-4", To: "claude-sonnet-4", Selector: sel, ContextLimit: 50}
+
+	var progress bytes.Buffer
+	report, err := migrateAgentModels(context.Background(), client, opts, &progress)
+	require.NoError(t, err)
+	require.Len(t, report.Agents, 3)
+
+	assert.Equal(t, modelMigrated, report.Agents[0].Status)
+	assert.Equal(t, "azure/gpt-4", report.Agents[0].Before)
+	assert.Equal(t, "claude-sonnet-4", report.Agents[0].After)
+	assert.Equal(t, "Be brief", client.agents[0].AIInstructions, "the agent is updated from its full record")
+
+	assert.Equal(t, modelSkippedLimits, report.Agents[1].Status)
+	assert.Equal(t, 100, report.Agents[1].InstructionsTokens)
+	assert.Contains(t, report.Agents[1].Warning, "more than the 50")
+	assert.Equal(t, "azure/gpt-4", report.Agents[1].After)
+
+	assert.Equal(t, modelMigrateFailed, report.Agents[2].Status)
+	assert.Equal(t, []string{"a-1"}, client.updates)
+}
+
+func TestMigrateAgentModelsDryRun(t *testing.T) {
+	client := &fakeModelMigrator{agents: []*kubiya.Agent{
+		{UUID: "a-1", Name: "oncall", LLMModel: "azure/gpt-4", AIInstructions: strings.Repeat("x", 400)},
+	}}
+	opts := modelMigrationOptions{From: "AZURE/gpt-4", To: "claude-sonnet-4", ContextLimit: 50, Force: true, DryRun: true}
+
+	report, err := migrateAgentModels(context.Background(), client, opts, &bytes.Buffer{})
+	require.NoError(t, err)
+	require.Len(t, report.Agents, 1)
+	assert.Equal(t, modelWouldMigrate, report.Agents[0].Status)
+	assert.NotEmpty(t, report.Agents[0].Warning)
+	assert.Empty(t, client.updates)
+}
+
+func TestModelContextLimit(t *testing.T) {
+	assert.Equal(t, 8192, modelContextLimit("azure/gpt-4"))
+	assert.Equal(t, 128000, modelContextLimit("openai/gpt-4o-mini"))
+	assert.Equal(t, 200000, modelContextLimit("claude-sonnet-4"))
+	assert.Equal(t, 0, modelContextLimit("acme-llm"))
+
+	_, err := parseAgentSelector("=sre")
+	assert.Error(t, err)
+	assert.True(t, agentMatchesSelector(kubiya.Agent{Tags: []string{"prod", "team:sre"}}, map[string]string{"team": "sre", "prod": ""}))
+}
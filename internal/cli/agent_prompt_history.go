@@ -0,0 +1,441 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// maxPromptSnapshots is how many snapshots are kept per agent
+const maxPromptSnapshots = 50
+
+// promptSnapshot is the AI instructions of an agent before a change
+type promptSnapshot struct {
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	Action       string    `json:"action"`
+	Instructions string    `json:"instructions"`
+}
+
+// promptHistory stores prompt snapshots as one JSON file per agent in
+// ~/.kubiya/prompt-history
+type promptHistory struct {
+	dir string
+}
+
+func newPromptHistory() (*promptHistory, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return &promptHistory{dir: filepath.Join(homeDir, config.KUBIYA_DIR, "prompt-history")}, nil
+}
+
+func (h *promptHistory) path(agentID string) string {
+	return filepath.Join(h.dir, filepath.Base(agentID)+".json")
+}
+
+// List returns the snapshots of an agent, oldest first
+func (h *promptHistory) List(agentID string) ([]promptSnapshot, error) {
+	data, err := os.ReadFile(h.path(agentID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt history: %w", err)
+	}
+
+	var snapshots []promptSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("invalid prompt history %s: %w", h.path(agentID), err)
+	}
+	return snapshots, nil
+}
+
+// Get returns a single snapshot of an agent
+func (h *promptHistory) Get(agentID string, version int) (*promptSnapshot, error) {
+	snapshots, err := h.List(agentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if snapshots[i].Version == version {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("agent %s has no prompt version %d (see 'kubiya agent prompt history %s')", agentID, version, agentID)
+}
+
+// Record saves instructions as a new snapshot unless they match the latest
+// one. The oldest snapshots are dropped beyond maxPromptSnapshots.
+func (h *promptHistory) Record(agentID, instructions, action string) error {
+	snapshots, err := h.List(agentID)
+	if err != nil {
+		return err
+	}
+
+	version := 1
+	if n := len(snapshots); n > 0 {
+		if snapshots[n-1].Instructions == instructions {
+			return nil
+		}
+		version = snapshots[n-1].Version + 1
+	}
+	snapshots = append(snapshots, promptSnapshot{
+		Version:      version,
+		CreatedAt:    time.Now().UTC(),
+		Action:       action,
+		Instructions: instructions,
+	})
+	if len(snapshots) > maxPromptSnapshots {
+		snapshots = snapshots[len(snapshots)-maxPromptSnapshots:]
+	}
+
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create prompt history directory: %w", err)
+	}
+	if err := os.WriteFile(h.path(agentID), data, 0600); err != nil {
+		return fmt.Errorf("failed to write prompt history: %w", err)
+	}
+	return nil
+}
+
+// agentPrompt is the instructions of an agent: its V2 system_prompt, or its
+// ai_instructions with the V1 API
+type agentPrompt struct {
+	Name           string
+	AIInstructions string
+	// save replaces the instructions of the agent
+	save func(ctx context.Context, instructions string) error
+}
+
+// loadAgentPrompt reads the instructions of an agent with the API of the
+// current context
+func loadAgentPrompt(ctx context.Context, cfg *config.Config, agentID string) (*agentPrompt, error) {
+	if cfg.UseV1API {
+		client := kubiya.NewClient(cfg)
+		agent, err := client.GetAgent(ctx, agentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent: %w", err)
+		}
+		return &agentPrompt{
+			Name:           agent.Name,
+			AIInstructions: agent.AIInstructions,
+			save: func(ctx context.Context, instructions string) error {
+				_, err := client.UpdateAgentRaw(ctx, agentID, agentPromptUpdateData(agent, instructions))
+				return err
+			},
+		}, nil
+	}
+
+	client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create control plane client: %w", err)
+	}
+	agent, err := client.GetAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	prompt := &agentPrompt{Name: agent.Name}
+	if agent.SystemPrompt != nil {
+		prompt.AIInstructions = *agent.SystemPrompt
+	}
+	prompt.save = func(ctx context.Context, instructions string) error {
+		_, err := client.UpdateAgent(agentID, &entities.AgentUpdateRequest{SystemPrompt: &instructions})
+		return err
+	}
+	return prompt, nil
+}
+
+// snapshotAgentPrompt records the instructions an agent had before a change,
+// once the change is saved. Failing to snapshot only warns since the change
+// is already made.
+func snapshotAgentPrompt(agentID, instructions, action string) {
+	history, err := newPromptHistory()
+	if err == nil {
+		err = history.Record(agentID, instructions, action)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s Could not save a snapshot of the current instructions: %v\n",
+			style.WarningStyle.Render("⚠️"), err)
+	}
+}
+
+// previousPromptSnapshot returns the newest snapshot that differs from current
+func previousPromptSnapshot(snapshots []promptSnapshot, current string) *promptSnapshot {
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Instructions != current {
+			return &snapshots[i]
+		}
+	}
+	return nil
+}
+
+func newAgentPromptHistoryCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "history [agent-uuid]",
+		Aliases: []string{"versions"},
+		Short:   "📜 List saved versions of agent AI instructions",
+		Long: `List the snapshots of an agent's AI instructions.
+
+A snapshot of the previous instructions is saved locally every time they are
+changed with 'kubiya agent prompt set', 'append', 'edit', 'clear' or 'restore'.
+The instructions are the system prompt of the agent, or its AI instructions
+with the V1 API.`,
+		Example: "  kubiya agent prompt history abc-123\n  kubiya agent prompt history abc-123 --output json",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			history, err := newPromptHistory()
+			if err != nil {
+				return err
+			}
+			snapshots, err := history.List(args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, snapshots)
+			}
+
+			if len(snapshots) == 0 {
+				fmt.Fprintln(out, "No saved versions. A version is saved each time the instructions are changed with 'kubiya agent prompt'.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tSAVED\tBEFORE\tLENGTH\tPREVIEW")
+			for i := len(snapshots) - 1; i >= 0; i-- {
+				s := snapshots[i]
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n",
+					s.Version,
+					s.CreatedAt.Local().Format("2006-01-02 15:04"),
+					s.Action,
+					len(s.Instructions),
+					promptPreview(s.Instructions))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+func newAgentPromptDiffCommand(cfg *config.Config) *cobra.Command {
+	var fromVersion, toVersion int
+
+	cmd := &cobra.Command{
+		Use:   "diff [agent-uuid]",
+		Short: "🔀 Show what changed in agent AI instructions",
+		Long: `Show a unified diff between saved versions of an agent's AI instructions.
+
+Without --to the version is compared with the current instructions. Without
+--from the newest saved version that differs from --to is used.`,
+		Example: `  # What changed in the latest edit
+  kubiya agent prompt diff abc-123
+
+  # Compare version 3 with the current instructions
+  kubiya agent prompt diff abc-123 --from 3
+
+  # Compare two saved versions
+  kubiya agent prompt diff abc-123 --from 2 --to 4`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentUUID := args[0]
+			history, err := newPromptHistory()
+			if err != nil {
+				return err
+			}
+
+			toName := agentUUID + "@current"
+			var to string
+			if toVersion != 0 {
+				snapshot, err := history.Get(agentUUID, toVersion)
+				if err != nil {
+					return err
+				}
+				to, toName = snapshot.Instructions, fmt.Sprintf("%s@v%d", agentUUID, snapshot.Version)
+			} else {
+				agent, err := loadAgentPrompt(cmd.Context(), cfg, agentUUID)
+				if err != nil {
+					return err
+				}
+				to = agent.AIInstructions
+			}
+
+			var from *promptSnapshot
+			if fromVersion != 0 {
+				if from, err = history.Get(agentUUID, fromVersion); err != nil {
+					return err
+				}
+			} else {
+				snapshots, err := history.List(agentUUID)
+				if err != nil {
+					return err
+				}
+				if toVersion != 0 {
+					snapshots = snapshotsBefore(snapshots, toVersion)
+				}
+				if from = previousPromptSnapshot(snapshots, to); from == nil {
+					return fmt.Errorf("no previous version of the instructions of agent %s is saved", agentUUID)
+				}
+			}
+
+			diff, err := unifiedDiff(from.Instructions, to, fmt.Sprintf("%s@v%d", agentUUID, from.Version), toName)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if diff == "" {
+				fmt.Fprintln(out, "The instructions are identical")
+				return nil
+			}
+			printColoredDiff(out, diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&fromVersion, "from", 0, "Version to compare from (default: the previous version)")
+	cmd.Flags().IntVar(&toVersion, "to", 0, "Version to compare to (default: current instructions)")
+	return cmd
+}
+
+func newAgentPromptRestoreCommand(cfg *config.Config) *cobra.Command {
+	var (
+		version int
+		yes     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "restore [agent-uuid]",
+		Aliases: []string{"rollback"},
+		Short:   "⏪ Restore a saved version of agent AI instructions",
+		Long: `Replace the AI instructions of an agent with a saved version.
+
+The instructions being replaced are saved first, so a restore can itself be
+undone.`,
+		Example: "  kubiya agent prompt restore abc-123 --version 3",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentUUID := args[0]
+			history, err := newPromptHistory()
+			if err != nil {
+				return err
+			}
+			snapshot, err := history.Get(agentUUID, version)
+			if err != nil {
+				return err
+			}
+
+			agent, err := loadAgentPrompt(cmd.Context(), cfg, agentUUID)
+			if err != nil {
+				return err
+			}
+
+			diff, err := unifiedDiff(agent.AIInstructions, snapshot.Instructions,
+				agentUUID+"@current", fmt.Sprintf("%s@v%d", agentUUID, snapshot.Version))
+			if err != nil {
+				return err
+			}
+			if diff == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Instructions already match version %d\n", snapshot.Version)
+				return nil
+			}
+
+			if !yes {
+				printColoredDiff(cmd.OutOrStdout(), diff)
+				fmt.Fprintln(cmd.OutOrStdout())
+				if !confirmYesNo(fmt.Sprintf("Restore the instructions of %s to version %d?", agent.Name, snapshot.Version)) {
+					return fmt.Errorf("restore cancelled")
+				}
+			}
+
+			if err := agent.save(cmd.Context(), snapshot.Instructions); err != nil {
+				return fmt.Errorf("failed to update agent: %w", err)
+			}
+			snapshotAgentPrompt(agentUUID, agent.AIInstructions, "restore")
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Instructions of %s restored to version %d\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(agent.Name),
+				snapshot.Version)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&version, "version", 0, "Version to restore (see 'kubiya agent prompt history')")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	_ = cmd.MarkFlagRequired("version")
+	return cmd
+}
+
+// snapshotsBefore returns the snapshots older than version
+func snapshotsBefore(snapshots []promptSnapshot, version int) []promptSnapshot {
+	var before []promptSnapshot
+	for _, s := range snapshots {
+		if s.Version < version {
+			before = append(before, s)
+		}
+	}
+	return before
+}
+
+// agentPromptUpdateData builds the V1 update payload of an agent with new
+// AI instructions, keeping every other field as is
+func agentPromptUpdateData(agent *kubiya.Agent, instructions string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                  agent.Name,
+		"description":           agent.Description,
+		"instruction_type":      agent.InstructionType,
+		"llm_model":             agent.LLMModel,
+		"sources":               agent.Sources,
+		"environment_variables": agent.Environment,
+		"secrets":               agent.Secrets,
+		"allowed_groups":        agent.AllowedGroups,
+		"allowed_users":         agent.AllowedUsers,
+		"owners":                agent.Owners,
+		"runners":               agent.Runners,
+		"is_debug_mode":         agent.IsDebugMode,
+		"ai_instructions":       instructions,
+		"image":                 agent.Image,
+		"managed_by":            agent.ManagedBy,
+		"integrations":          agent.Integrations,
+		"links":                 agent.Links,
+		"tools":                 agent.Tools,
+		"tasks":                 agent.Tasks,
+		"tags":                  agent.Tags,
+	}
+}
+
+// promptPreview returns the first line of instructions, shortened
+func promptPreview(instructions string) string {
+	line, _, _ := strings.Cut(instructions, "\n")
+	if len(line) > 50 {
+		line = line[:47] + "..."
+	}
+	return line
+}
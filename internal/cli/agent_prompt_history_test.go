@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/config"
+)
+
+func TestPromptHistoryRecord(t *testing.T) {
+	h := &promptHistory{dir: t.TempDir()}
+
+	for _, step := range []struct{ instructions, action string }{
+		{"v1", "set"},
+		{"v1", "append"}, // unchanged since the last snapshot, not recorded
+		{"v2", "edit"},
+		{"", "clear"},
+	} {
+		if err := h.Record("agent-1", step.instructions, step.action); err != nil {
+			t.Fatalf("Record(%q) error = %v", step.instructions, err)
+		}
+	}
+
+	snapshots, err := h.List("agent-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("expected 3 snapshots, got %+v", snapshots)
+	}
+	for i, want := range []string{"v1", "v2", ""} {
+		if snapshots[i].Version != i+1 || snapshots[i].Instructions != want {
+			t.Errorf("snapshot %d = %+v, want version %d with %q", i, snapshots[i], i+1, want)
+		}
+	}
+
+	got, err := h.Get("agent-1", 2)
+	if err != nil || got.Action != "edit" {
+		t.Errorf("Get(2) = %+v, %v", got, err)
+	}
+	if _, err := h.Get("agent-1", 9); err == nil {
+		t.Error("Get(9) expected an error")
+	}
+	if other, _ := h.List("agent-2"); len(other) != 0 {
+		t.Errorf("expected no snapshots for another agent, got %+v", other)
+	}
+
+	// The current instructions are "v2" again: the previous version is the
+	// newest snapshot that differs, skipping the identical one
+	prev := previousPromptSnapshot(snapshots[:2], "v2")
+	if prev == nil || prev.Version != 1 {
+		t.Errorf("previousPromptSnapshot() = %+v, want version 1", prev)
+	}
+}
+
+func TestPromptHistoryTrimsOldest(t *testing.T) {
+	h := &promptHistory{dir: t.TempDir()}
+	for i := 0; i < maxPromptSnapshots+5; i++ {
+		if err := h.Record("agent-1", fmt.Sprintf("v%d", i), "set"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, _ := h.List("agent-1")
+	if len(snapshots) != maxPromptSnapshots {
+		t.Fatalf("expected %d snapshots, got %d", maxPromptSnapshots, len(snapshots))
+	}
+	if snapshots[0].Version != 6 {
+		t.Errorf("oldest kept version = %d, want 6", snapshots[0].Version)
+	}
+}
+
+func TestAgentPromptSetV2SnapshotsAfterUpdate(t *testing.T) {
+	failUpdate := true
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"id":"agent-1","name":"devbot","system_prompt":"v1"}`))
+		case http.MethodPatch:
+			if failUpdate {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"detail":"boom"}`))
+				return
+			}
+			if err := json.NewDecoder(r.Body).Decode(&patched); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"id":"agent-1","name":"devbot","system_prompt":"v2"}`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", server.URL)
+	t.Setenv("HOME", t.TempDir())
+
+	set := func() error {
+		cmd := newAgentPromptSetCommand(&config.Config{APIKey: "key"})
+		cmd.SetArgs([]string{"agent-1", "--content", "v2", "-y"})
+		return cmd.ExecuteContext(context.Background())
+	}
+	history, err := newPromptHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set(); err == nil {
+		t.Fatal("expected the failed update to fail the command")
+	}
+	if snapshots, _ := history.List("agent-1"); len(snapshots) != 0 {
+		t.Fatalf("expected no snapshot of a failed update, got %+v", snapshots)
+	}
+
+	failUpdate = false
+	if err := set(); err != nil {
+		t.Fatal(err)
+	}
+	if patched["system_prompt"] != "v2" {
+		t.Errorf("expected the system prompt to be updated, got %v", patched)
+	}
+	snapshots, _ := history.List("agent-1")
+	if len(snapshots) != 1 || snapshots[0].Instructions != "v1" || snapshots[0].Action != "set" {
+		t.Errorf("expected a snapshot of the previous system prompt, got %+v", snapshots)
+	}
+}
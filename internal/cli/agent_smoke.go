@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	clierrors "github.com/kubiyabot/cli/internal/errors"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// hostedRunner is managed by Kubiya and not listed with the runners of the
+// organization
+const hostedRunner = "kubiya-hosted"
+
+// agentSmokeClient is the part of kubiya.Client used to smoke-test agents
+type agentSmokeClient interface {
+	chatSubmitter
+	GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error)
+	GetAgents(ctx context.Context) ([]kubiya.Agent, error)
+	ListRunners(ctx context.Context) ([]kubiya.Runner, error)
+}
+
+// agentSmokeOptions are the checks run against every agent
+type agentSmokeOptions struct {
+	Prompt     string
+	Expect     *regexp.Regexp
+	Timeout    time.Duration
+	MaxLatency time.Duration
+}
+
+// agentSmokeResult is the outcome of the smoke test of one agent
+type agentSmokeResult struct {
+	AgentUUID string `json:"agent_uuid"`
+	Agent     string `json:"agent"`
+	Passed    bool   `json:"passed"`
+	// Runner is the first configured runner found healthy
+	Runner    string   `json:"runner,omitempty"`
+	LatencyMS int64    `json:"latency_ms,omitempty"`
+	Reply     string   `json:"reply,omitempty"`
+	Failures  []string `json:"failures,omitempty"`
+}
+
+func newAgentSmokeTestCommand(cfg *config.Config) *cobra.Command {
+	var (
+		all          bool
+		prompt       string
+		expect       string
+		timeout      time.Duration
+		maxLatency   time.Duration
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "smoke-test [agent-uuid]",
+		Short: "🩺 Check that an agent answers a canary prompt",
+		Long: `Send a canary prompt to an agent in a new session and check that:
+
+  • at least one of its runners is healthy
+  • it replies, within --max-latency
+  • the reply matches --expect-regex
+
+With --all every agent of the organization is tested, one after the other,
+and a summary table is printed. The command exits with 1 when any agent
+failed, so it can run nightly to catch silently broken agents.`,
+		Example: `  # Smoke-test one agent
+  kubiya agent smoke-test abc-123
+
+  # With a custom canary
+  kubiya agent smoke-test abc-123 --prompt "Run 'echo ok' and show the output" --expect-regex '\bok\b'
+
+  # Test every agent nightly, as JSON
+  kubiya agent smoke-test --all --max-latency 90s -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return clierrors.ValidationError(fmt.Errorf("give either an agent UUID or --all"), "")
+			}
+			if outputFormat != "text" && outputFormat != "json" {
+				return clierrors.ValidationError(fmt.Errorf("unsupported output format: %s", outputFormat), "Use -o text or -o json")
+			}
+			re, err := regexp.Compile(expect)
+			if err != nil {
+				return clierrors.ValidationError(fmt.Errorf("invalid --expect-regex: %w", err), "")
+			}
+			opts := agentSmokeOptions{Prompt: prompt, Expect: re, Timeout: timeout, MaxLatency: maxLatency}
+
+			client := kubiya.NewClient(cfg)
+			var agents []kubiya.Agent
+			if all {
+				agents, err = client.GetAgents(cmd.Context())
+				if err != nil {
+					return clierrors.APIError(fmt.Errorf("failed to list agents: %w", err))
+				}
+			} else {
+				agent, err := client.GetAgent(cmd.Context(), args[0])
+				if err != nil {
+					return clierrors.APIError(fmt.Errorf("failed to get agent: %w", err))
+				}
+				agents = []kubiya.Agent{*agent}
+			}
+
+			progress := cmd.ErrOrStderr()
+			if outputFormat == "json" {
+				progress = io.Discard
+			}
+			results, err := smokeTestAgents(cmd.Context(), client, agents, opts, progress)
+			if err != nil {
+				return clierrors.APIError(err)
+			}
+
+			if outputFormat == "json" {
+				if err := output.EncodeJSON(cmd.OutOrStdout(), results); err != nil {
+					return err
+				}
+			} else {
+				printAgentSmokeResults(cmd.OutOrStdout(), results)
+			}
+
+			failed := 0
+			for _, r := range results {
+				if !r.Passed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return clierrors.RuntimeError(fmt.Errorf("%d of %d agent(s) failed the smoke test", failed, len(results)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Test every agent of the organization")
+	cmd.Flags().StringVar(&prompt, "prompt", "Reply with the single word pong.", "Canary prompt sent to the agent")
+	cmd.Flags().StringVar(&expect, "expect-regex", "(?i)pong", "Regular expression the reply must match")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "Time to wait for the reply of each agent")
+	cmd.Flags().DurationVar(&maxLatency, "max-latency", time.Minute, "Longest acceptable time to the complete reply (0 for no limit)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "output format (text|json)")
+	return cmd
+}
+
+// smokeTestAgents tests the agents one after the other. The error is only
+// set when the runners could not be listed.
+func smokeTestAgents(ctx context.Context, client agentSmokeClient, agents []kubiya.Agent, opts agentSmokeOptions, progress io.Writer) ([]agentSmokeResult, error) {
+	runners, err := client.ListRunners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runners: %w", err)
+	}
+	healthy := map[string]bool{hostedRunner: true}
+	for _, r := range runners {
+		healthy[r.Name] = isRunnerHealthy(r)
+	}
+
+	results := make([]agentSmokeResult, 0, len(agents))
+	for _, agent := range agents {
+		fmt.Fprintf(progress, "%s Testing %s...\n", style.DimStyle.Render("›"), agent.Name)
+		results = append(results, smokeTestAgent(ctx, client, agent, healthy, opts))
+	}
+	return results, nil
+}
+
+// smokeTestAgent checks the runners of the agent, then sends it the canary
+// prompt when one of them is healthy
+func smokeTestAgent(ctx context.Context, client chatSubmitter, agent kubiya.Agent, healthy map[string]bool, opts agentSmokeOptions) agentSmokeResult {
+	result := agentSmokeResult{AgentUUID: agent.UUID, Agent: agent.Name}
+	for _, name := range agent.Runners {
+		if healthy[name] {
+			result.Runner = name
+			break
+		}
+	}
+	if result.Runner == "" {
+		if len(agent.Runners) == 0 {
+			result.Failures = append(result.Failures, "no runner configured")
+		} else {
+			result.Failures = append(result.Failures, fmt.Sprintf("no healthy runner among %s", strings.Join(agent.Runners, ", ")))
+		}
+		return result
+	}
+
+	ctx, cancel := contextWithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	start := time.Now()
+	reply, err := collectAgentReply(ctx, client, agent.UUID, opts.Prompt)
+	latency := time.Since(start)
+	result.Reply = reply
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Failures = append(result.Failures, fmt.Sprintf("no reply within %s", opts.Timeout))
+	case err != nil:
+		result.Failures = append(result.Failures, err.Error())
+	default:
+		result.LatencyMS = latency.Milliseconds()
+		if strings.TrimSpace(reply) == "" {
+			result.Failures = append(result.Failures, "empty reply")
+		} else if !opts.Expect.MatchString(reply) {
+			result.Failures = append(result.Failures, fmt.Sprintf("reply does not match %s", opts.Expect))
+		}
+		if opts.MaxLatency > 0 && latency > opts.MaxLatency {
+			result.Failures = append(result.Failures, fmt.Sprintf("replied in %s, over %s", latency.Round(time.Millisecond), opts.MaxLatency))
+		}
+	}
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// collectAgentReply sends message in a new session and returns the text of
+// the reply once the agent is done
+func collectAgentReply(ctx context.Context, client chatSubmitter, agentID, message string) (string, error) {
+	msgChan, err := client.SendMessageWithContext(ctx, agentID, message, uuid.New().String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+
+	// Messages are streamed whole again with every update
+	contents := map[string]string{}
+	var order []string
+	for msg := range msgChan {
+		if msg.Type == "error" || msg.Error != "" {
+			return "", fmt.Errorf("agent error: %s", firstNonEmpty(msg.Error, msg.Content))
+		}
+		switch msg.Type {
+		case "tool", "tool_output", "system", "warning":
+		default:
+			if msg.SenderName == "You" || msg.MessageID == "" {
+				continue
+			}
+			if _, ok := contents[msg.MessageID]; !ok {
+				order = append(order, msg.MessageID)
+			}
+			if len(msg.Content) >= len(contents[msg.MessageID]) {
+				contents[msg.MessageID] = msg.Content
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, id := range order {
+		if content := strings.TrimSpace(contents[id]); content != "" {
+			parts = append(parts, content)
+		}
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func printAgentSmokeResults(w io.Writer, results []agentSmokeResult) {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tRESULT\tRUNNER\tLATENCY\tPROBLEM")
+	passed := 0
+	for _, r := range results {
+		status, latency := "FAIL", "-"
+		if r.Passed {
+			status = "PASS"
+			passed++
+		}
+		if r.LatencyMS > 0 {
+			latency = (time.Duration(r.LatencyMS) * time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Agent, status, firstNonEmpty(r.Runner, "-"), latency, strings.Join(r.Failures, "; "))
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if passed == len(results) {
+		fmt.Fprintf(w, "%s %d of %d agent(s) passed\n", style.SuccessStyle.Render("✅"), passed, len(results))
+	} else {
+		fmt.Fprintf(w, "%s %d of %d agent(s) passed\n", style.ErrorStyle.Render("✗"), passed, len(results))
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeSmokeClient struct {
+	scriptedChat
+	runners []kubiya.Runner
+}
+
+func (c *fakeSmokeClient) GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error) {
+	return &kubiya.Agent{UUID: agentID}, nil
+}
+
+func (c *fakeSmokeClient) GetAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	return nil, nil
+}
+
+func (c *fakeSmokeClient) ListRunners(ctx context.Context) ([]kubiya.Runner, error) {
+	return c.runners, nil
+}
+
+func smokeOptions() agentSmokeOptions {
+	return agentSmokeOptions{Prompt: "say pong", Expect: regexp.MustCompile("(?i)pong"), Timeout: time.Minute}
+}
+
+func TestSmokeTestAgents(t *testing.T) {
+	client := &fakeSmokeClient{
+		scriptedChat: scriptedChat{reply: []kubiya.ChatMessage{
+			{Type: "text", MessageID: "m1", Content: "Po"},
+			{Type: "text", MessageID: "m1", Content: "Pong", Final: true},
+		}},
+		runners: []kubiya.Runner{runnerWithStatus("gke-poc", "healthy"), runnerWithStatus("gke-old", "unhealthy")},
+	}
+	agents := []kubiya.Agent{
+		{UUID: "a1", Name: "devops", Runners: []string{"gke-old", "gke-poc"}},
+		{UUID: "a2", Name: "hosted", Runners: []string{hostedRunner}},
+		{UUID: "a3", Name: "stale", Runners: []string{"gke-old"}},
+		{UUID: "a4", Name: "bare"},
+	}
+
+	results, err := smokeTestAgents(context.Background(), client, agents, smokeOptions(), &bytes.Buffer{})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, "gke-poc", results[0].Runner)
+	assert.Equal(t, "Pong", results[0].Reply)
+	assert.True(t, results[1].Passed)
+	assert.Equal(t, []string{"no healthy runner among gke-old"}, results[2].Failures)
+	assert.Equal(t, []string{"no runner configured"}, results[3].Failures)
+	assert.Equal(t, []string{"say pong", "say pong"}, client.messages, "agents without a healthy runner are not prompted")
+	assert.NotEqual(t, client.sessions[0], client.sessions[1])
+
+	var out bytes.Buffer
+	printAgentSmokeResults(&out, results)
+	assert.Contains(t, out.String(), "2 of 4 agent(s) passed")
+}
+
+func TestSmokeTestAgentFailures(t *testing.T) {
+	healthy := map[string]bool{"gke-poc": true}
+	agent := kubiya.Agent{UUID: "a1", Name: "devops", Runners: []string{"gke-poc"}}
+
+	tests := []struct {
+		name  string
+		chat  *scriptedChat
+		opts  func(*agentSmokeOptions)
+		wants string
+	}{
+		{"unexpected reply", &scriptedChat{reply: []kubiya.ChatMessage{{Type: "text", MessageID: "m1", Content: "Hello!"}}},
+			nil, "reply does not match (?i)pong"},
+		{"empty reply", &scriptedChat{}, nil, "empty reply"},
+		{"agent error", &scriptedChat{reply: []kubiya.ChatMessage{{Type: "error", Error: "runner timed out"}}},
+			nil, "agent error: runner timed out"},
+		{"send error", &scriptedChat{err: errors.New("unauthorized")}, nil, "failed to send message: unauthorized"},
+		{"too slow", &scriptedChat{reply: []kubiya.ChatMessage{{Type: "text", MessageID: "m1", Content: "pong"}}},
+			func(o *agentSmokeOptions) { o.MaxLatency = time.Nanosecond }, "over 1ns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := smokeOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			result := smokeTestAgent(context.Background(), tt.chat, agent, healthy, opts)
+			assert.False(t, result.Passed)
+			require.Len(t, result.Failures, 1)
+			assert.Contains(t, result.Failures[0], tt.wants)
+		})
+	}
+}
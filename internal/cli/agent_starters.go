@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// newAgentStartersCommand creates the command to manage agent starters
+func newAgentStartersCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "starters",
+		Aliases: []string{"starter"},
+		Short:   "🚀 Manage agent conversation starters",
+		Long: `Manage the conversation starters of an agent.
+
+Starters are quick-actions offered when a conversation with the agent begins:
+the display text is shown to the user and the command is sent to the agent
+when it is picked.`,
+	}
+
+	cmd.AddCommand(
+		newAgentStartersListCommand(cfg),
+		newAgentStartersAddCommand(cfg),
+		newAgentStartersRemoveCommand(cfg),
+	)
+
+	return cmd
+}
+
+// newAgentStartersListCommand lists agent starters
+func newAgentStartersListCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "list [agent-uuid]",
+		Aliases: []string{"ls", "get"},
+		Short:   "📋 List agent starters",
+		Example: `  # List starters
+  kubiya agent starters list abc-123
+
+  # Export starters as YAML
+  kubiya agent starters list abc-123 --output yaml > starters.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+
+			agent, err := client.GetAgent(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get agent: %w", err)
+			}
+			starters, err := agent.GetStarters()
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "json":
+				return printJSON(starters)
+			case "yaml":
+				return yaml.NewEncoder(os.Stdout).Encode(starters)
+			default:
+				fmt.Printf("%s Agent Starters: %s\n\n",
+					style.TitleStyle.Render("🚀"),
+					style.HighlightStyle.Render(agent.Name))
+
+				if len(starters) == 0 {
+					fmt.Printf("%s No starters configured\n",
+						style.DimStyle.Render("  •"))
+					return nil
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintf(w, "  %s\t%s\t%s\n",
+					style.SubtitleStyle.Render("NAME"),
+					style.SubtitleStyle.Render("COMMAND"),
+					style.SubtitleStyle.Render("DISPLAY TEXT"))
+				for _, s := range starters {
+					command := s.Command
+					if len(command) > 50 {
+						command = command[:47] + "..."
+					}
+					fmt.Fprintf(w, "  %s\t%s\t%s\n",
+						style.HighlightStyle.Render(s.Name),
+						command,
+						style.DimStyle.Render(s.DisplayText))
+				}
+				w.Flush()
+
+				fmt.Printf("\n%s Total: %d starters\n",
+					style.SubtitleStyle.Render("📊"),
+					len(starters))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json|yaml)")
+	return cmd
+}
+
+// newAgentStartersAddCommand adds a starter to an agent
+func newAgentStartersAddCommand(cfg *config.Config) *cobra.Command {
+	var (
+		yes     bool
+		starter kubiya.Starter
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add [agent-uuid]",
+		Short: "➕ Add a starter to an agent",
+		Example: `  # Add a starter
+  kubiya agent starters add abc-123 --name pods \
+    --command "List the pods failing in production" \
+    --display-text "🔍 Failing pods"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentUUID := args[0]
+			client := kubiya.NewClient(cfg)
+
+			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			if err != nil {
+				return fmt.Errorf("failed to get agent: %w", err)
+			}
+			starters, err := agent.GetStarters()
+			if err != nil {
+				return err
+			}
+			starters, err = addStarter(starters, starter)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s Adding starter to agent: %s\n\n",
+				style.InfoStyle.Render("🚀"),
+				style.HighlightStyle.Render(agent.Name))
+			fmt.Printf("  • %s: %s\n\n",
+				style.HighlightStyle.Render(starter.Name),
+				style.DimStyle.Render(starter.Command))
+
+			if !yes {
+				if !confirmYesNo(fmt.Sprintf("Add starter '%s' to agent '%s'?", starter.Name, agent.Name)) {
+					return fmt.Errorf("starter update cancelled")
+				}
+			}
+
+			result, err := updateAgentStarters(cmd, client, agentUUID, agent, starters)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s Added starter '%s' to agent '%s'\n",
+				style.SuccessStyle.Render("✅"),
+				starter.Name,
+				style.HighlightStyle.Render(result.Name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&starter.Name, "name", "", "Name of the starter (required)")
+	cmd.Flags().StringVar(&starter.Command, "command", "", "Message sent to the agent when the starter is picked (required)")
+	cmd.Flags().StringVar(&starter.DisplayText, "display-text", "", "Text shown to the user for the starter")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("command")
+
+	return cmd
+}
+
+// newAgentStartersRemoveCommand removes starters from an agent
+func newAgentStartersRemoveCommand(cfg *config.Config) *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:     "remove [agent-uuid] [name...]",
+		Aliases: []string{"rm", "delete", "del"},
+		Short:   "🗑️ Remove starter(s) from an agent",
+		Example: `  # Remove a starter
+  kubiya agent starters remove abc-123 pods
+
+  # Remove several starters without confirmation
+  kubiya agent starters remove abc-123 pods deployments -y`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentUUID := args[0]
+			client := kubiya.NewClient(cfg)
+
+			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			if err != nil {
+				return fmt.Errorf("failed to get agent: %w", err)
+			}
+			starters, err := agent.GetStarters()
+			if err != nil {
+				return err
+			}
+
+			kept, removed, notFound := removeStarters(starters, args[1:])
+			if len(notFound) > 0 {
+				fmt.Printf("%s Starters not found: %s\n",
+					style.WarningStyle.Render("⚠️"),
+					strings.Join(notFound, ", "))
+			}
+			if len(removed) == 0 {
+				return fmt.Errorf("no matching starters found to remove")
+			}
+
+			fmt.Printf("%s Removing starters from agent: %s\n\n",
+				style.InfoStyle.Render("🗑️"),
+				style.HighlightStyle.Render(agent.Name))
+			for _, s := range removed {
+				fmt.Printf("  • %s: %s\n",
+					style.ErrorStyle.Render(s.Name),
+					style.DimStyle.Render(s.Command))
+			}
+			fmt.Println()
+
+			if !yes {
+				if !confirmYesNo(fmt.Sprintf("Remove %d starter(s) from agent '%s'?", len(removed), agent.Name)) {
+					return fmt.Errorf("starter removal cancelled")
+				}
+			}
+
+			result, err := updateAgentStarters(cmd, client, agentUUID, agent, kept)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s Removed %d starter(s) from agent '%s'\n",
+				style.SuccessStyle.Render("✅"),
+				len(removed),
+				style.HighlightStyle.Render(result.Name))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+
+	return cmd
+}
+
+// addStarter appends s, refusing a starter whose name is already taken
+func addStarter(starters []kubiya.Starter, s kubiya.Starter) ([]kubiya.Starter, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	for _, existing := range starters {
+		if existing.Name == s.Name {
+			return nil, fmt.Errorf("agent already has a starter named %q", s.Name)
+		}
+	}
+	return append(starters, s), nil
+}
+
+// removeStarters splits starters into those kept and those named in names,
+// and returns the names matching no starter
+func removeStarters(starters []kubiya.Starter, names []string) (kept, removed []kubiya.Starter, notFound []string) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	found := make(map[string]bool, len(names))
+	for _, s := range starters {
+		if wanted[s.Name] {
+			removed = append(removed, s)
+			found[s.Name] = true
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			notFound = append(notFound, name)
+		}
+	}
+	return kept, removed, notFound
+}
+
+// updateAgentStarters saves the starters of agent
+func updateAgentStarters(cmd *cobra.Command, client *kubiya.Client, agentUUID string, agent *kubiya.Agent, starters []kubiya.Starter) (*kubiya.Agent, error) {
+	agent.SetStarters(starters)
+
+	updateData := map[string]interface{}{
+		"name":                  agent.Name,
+		"description":           agent.Description,
+		"instruction_type":      agent.InstructionType,
+		"llm_model":             agent.LLMModel,
+		"sources":               agent.Sources,
+		"environment_variables": agent.Environment,
+		"secrets":               agent.Secrets,
+		"allowed_groups":        agent.AllowedGroups,
+		"allowed_users":         agent.AllowedUsers,
+		"owners":                agent.Owners,
+		"runners":               agent.Runners,
+		"is_debug_mode":         agent.IsDebugMode,
+		"ai_instructions":       agent.AIInstructions,
+		"image":                 agent.Image,
+		"managed_by":            agent.ManagedBy,
+		"integrations":          agent.Integrations,
+		"links":                 agent.Links,
+		"tools":                 agent.Tools,
+		"tasks":                 agent.Tasks,
+		"starters":              agent.Starters,
+		"tags":                  agent.Tags,
+	}
+
+	result, err := client.UpdateAgentRaw(cmd.Context(), agentUUID, updateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update agent: %w", err)
+	}
+	return result, nil
+}
+
+func starterNames(starters []kubiya.Starter) []string {
+	names := make([]string, len(starters))
+	for i, s := range starters {
+		names[i] = s.Name
+	}
+	return names
+}
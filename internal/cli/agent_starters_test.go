@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddStarter(t *testing.T) {
+	starters := []kubiya.Starter{{Name: "pods", Command: "List pods"}}
+
+	updated, err := addStarter(starters, kubiya.Starter{Name: "deploys", Command: "List deploys"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pods", "deploys"}, starterNames(updated))
+
+	_, err = addStarter(starters, kubiya.Starter{Name: "pods", Command: "Other"})
+	assert.ErrorContains(t, err, "already has a starter")
+
+	_, err = addStarter(starters, kubiya.Starter{Name: "empty"})
+	assert.Error(t, err)
+}
+
+func TestRemoveStarters(t *testing.T) {
+	starters := []kubiya.Starter{
+		{Name: "pods", Command: "List pods"},
+		{Name: "deploys", Command: "List deploys"},
+		{Name: "alerts", Command: "List alerts"},
+	}
+
+	kept, removed, notFound := removeStarters(starters, []string{"deploys", "missing"})
+	assert.Equal(t, []string{"pods", "alerts"}, starterNames(kept))
+	assert.Equal(t, []string{"deploys"}, starterNames(removed))
+	assert.Equal(t, []string{"missing"}, notFound)
+}
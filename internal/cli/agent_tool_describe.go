@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// maxDescribedContentLines caps the lines of a tool's script shown by
+// 'agent tools describe' in text mode
+const maxDescribedContentLines = 20
+
+// sourceMetadataLookup is the part of kubiya.Client used to resolve tools
+// against the sources of an agent
+type sourceMetadataLookup interface {
+	GetSourceMetadataCached(ctx context.Context, sourceUUID string) (*kubiya.Source, error)
+}
+
+// agentToolSource is the source a described tool is defined in
+type agentToolSource struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Type string `json:"type,omitempty"`
+	Ref  string `json:"ref,omitempty"`
+	// Inline is set when the tool is an inline tool of the source
+	Inline bool `json:"inline,omitempty"`
+}
+
+// agentToolDescription is what 'agent tools describe' reports
+type agentToolDescription struct {
+	Name      string `json:"name"`
+	AgentUUID string `json:"agent_uuid"`
+	AgentName string `json:"agent_name"`
+	// Attached is set when the tool is listed in the tools of the agent,
+	// rather than only provided by one of its sources
+	Attached       bool             `json:"attached"`
+	Source         *agentToolSource `json:"source,omitempty"`
+	LastModified   string           `json:"last_modified,omitempty"`
+	LastModifiedBy string           `json:"last_modified_by,omitempty"`
+	// OtherSources are the other sources of the agent defining a tool with
+	// the same name
+	OtherSources []string     `json:"other_sources,omitempty"`
+	Tool         *kubiya.Tool `json:"tool,omitempty"`
+}
+
+// describeAgentTool resolves a tool name, matched case-insensitively
+// against tool names and aliases, in the sources of agent, their inline
+// tools included. A tool attached to the agent whose definition is in none
+// of its sources is described without its definition.
+func describeAgentTool(ctx context.Context, client sourceMetadataLookup, agent *kubiya.Agent, name string) (*agentToolDescription, error) {
+	desc := &agentToolDescription{Name: name, AgentUUID: agent.UUID, AgentName: agent.Name}
+	for _, tool := range agent.Tools {
+		if strings.EqualFold(tool, name) {
+			desc.Attached = true
+			break
+		}
+	}
+
+	var unreadable []string
+	for _, uuid := range agent.Sources {
+		source, err := client.GetSourceMetadataCached(ctx, uuid)
+		if err != nil {
+			unreadable = append(unreadable, uuid)
+			continue
+		}
+		tool, inline := findSourceTool(source, name)
+		if tool == nil {
+			continue
+		}
+		if desc.Tool != nil {
+			desc.OtherSources = append(desc.OtherSources, firstNonEmpty(source.Name, source.UUID))
+			continue
+		}
+		desc.Name = tool.Name
+		desc.Tool = tool
+		desc.Source = &agentToolSource{
+			UUID:   source.UUID,
+			Name:   source.Name,
+			URL:    source.URL,
+			Type:   source.Type,
+			Ref:    firstNonEmpty(tool.Source.Ref, source.PinnedRef),
+			Inline: inline,
+		}
+		desc.LastModified = source.KubiyaMetadata.LastUpdated
+		if desc.LastModified == "" && !source.UpdatedAt.IsZero() {
+			desc.LastModified = source.UpdatedAt.Format(time.RFC3339)
+		}
+		desc.LastModifiedBy = source.KubiyaMetadata.UserLastUpdated
+	}
+
+	if desc.Tool == nil && !desc.Attached {
+		msg := fmt.Sprintf("tool '%s' not found on agent '%s' or its sources", name, agent.Name)
+		if len(unreadable) > 0 {
+			msg += fmt.Sprintf(" (could not read sources: %s)", strings.Join(unreadable, ", "))
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return desc, nil
+}
+
+// findSourceTool returns the tool of source named name, and whether it is
+// one of its inline tools
+func findSourceTool(source *kubiya.Source, name string) (*kubiya.Tool, bool) {
+	for i, tools := range [][]kubiya.Tool{source.Tools, source.InlineTools} {
+		for _, tool := range tools {
+			if strings.EqualFold(tool.Name, name) || (tool.Alias != "" && strings.EqualFold(tool.Alias, name)) {
+				return &tool, i == 1
+			}
+		}
+	}
+	return nil, false
+}
+
+// newAgentToolDescribeCommand creates the command to describe/show tool details
+func newAgentToolDescribeCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "describe [agent-uuid] [tool-name]",
+		Aliases: []string{"desc", "get", "show", "info"},
+		Short:   "📄 Describe a specific tool on an agent",
+		Long: `Show the definition of a tool available to an agent: its arguments, image,
+environment, secrets, files and the source it comes from.
+
+The tool is looked up in the sources of the agent, their inline tools
+included, so tools provided by a source can be described as well as those
+attached to the agent.`,
+		Example: `  # Describe a tool
+  kubiya agent tool describe abc-123 python_script_runner
+
+  # Get the full tool definition in JSON format
+  kubiya agent tool get abc-123 create_file --output json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentUUID := args[0]
+			toolName := args[1]
+
+			client := kubiya.NewClient(cfg)
+			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			if err != nil {
+				return fmt.Errorf("failed to get agent: %w", err)
+			}
+			if agent.UUID == "" {
+				agent.UUID = agentUUID
+			}
+
+			desc, err := describeAgentTool(cmd.Context(), client, agent, toolName)
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "json":
+				return printJSON(desc)
+			case "yaml":
+				return yaml.NewEncoder(os.Stdout).Encode(desc)
+			case "text":
+				printAgentToolDescription(desc)
+				return nil
+			default:
+				return fmt.Errorf("invalid output format %q (valid: text, json, yaml)", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json|yaml)")
+
+	return cmd
+}
+
+func printAgentToolDescription(desc *agentToolDescription) {
+	fmt.Printf("%s Tool Details\n\n", style.TitleStyle.Render("📄"))
+	fmt.Printf("Tool Name: %s\n", style.HighlightStyle.Render(desc.Name))
+	fmt.Printf("Agent: %s (%s)\n", style.HighlightStyle.Render(desc.AgentName), desc.AgentUUID)
+	if desc.Attached {
+		fmt.Printf("Status: %s\n", style.SuccessStyle.Render("✅ Attached"))
+	} else {
+		fmt.Printf("Status: %s\n", style.SuccessStyle.Render("✅ Provided by a source"))
+	}
+
+	if desc.Source != nil {
+		kind := ""
+		if desc.Source.Inline {
+			kind = ", inline tool"
+		}
+		fmt.Printf("Source: %s (%s%s)\n", style.HighlightStyle.Render(firstNonEmpty(desc.Source.Name, desc.Source.UUID)), desc.Source.UUID, kind)
+		if desc.Source.URL != "" {
+			fmt.Printf("URL: %s\n", desc.Source.URL)
+		}
+		if desc.Source.Ref != "" {
+			fmt.Printf("Ref: %s\n", desc.Source.Ref)
+		}
+	}
+	if desc.LastModified != "" {
+		modified := desc.LastModified
+		if desc.LastModifiedBy != "" {
+			modified += " by " + desc.LastModifiedBy
+		}
+		fmt.Printf("Last Modified: %s\n", modified)
+	}
+	if len(desc.OtherSources) > 0 {
+		fmt.Printf("%s Also defined in: %s\n", style.WarningStyle.Render("⚠️"), strings.Join(desc.OtherSources, ", "))
+	}
+
+	tool := desc.Tool
+	if tool == nil {
+		fmt.Printf("\n%s The definition of this tool is not in any source of the agent\n", style.WarningStyle.Render("⚠️"))
+		return
+	}
+	if tool.Description != "" {
+		fmt.Printf("Description: %s\n", tool.Description)
+	}
+	if tool.Type != "" {
+		fmt.Printf("Type: %s\n", tool.Type)
+	}
+	if tool.Image != "" {
+		fmt.Printf("Image: %s\n", tool.Image)
+	}
+	if tool.LongRunning {
+		fmt.Println("Long running: yes")
+	}
+
+	if len(tool.Args) > 0 {
+		fmt.Printf("\n%s Arguments:\n", style.SubtitleStyle.Render("📝"))
+		for _, arg := range tool.Args {
+			var details []string
+			if arg.Type != "" {
+				details = append(details, arg.Type)
+			}
+			if arg.Required {
+				details = append(details, "required")
+			}
+			if arg.Default != "" {
+				details = append(details, "default: "+arg.Default)
+			}
+			line := "  • " + style.HighlightStyle.Render(arg.Name)
+			if len(details) > 0 {
+				line += " (" + strings.Join(details, ", ") + ")"
+			}
+			if arg.Description != "" {
+				line += ": " + arg.Description
+			}
+			fmt.Println(line)
+			if len(arg.Options) > 0 {
+				fmt.Printf("      options: %s\n", strings.Join(arg.Options, ", "))
+			}
+		}
+	}
+
+	printDescribedList("🌍", "Environment", tool.Env)
+	printDescribedList("🔒", "Secrets", tool.Secrets)
+	files := tool.GetToolFiles()
+	sort.Strings(files)
+	printDescribedList("📁", "Files", files)
+	printDescribedList("🐳", "Services", tool.WithServices)
+
+	if content := strings.TrimRight(tool.Content, "\n"); content != "" {
+		lines := strings.Split(content, "\n")
+		fmt.Printf("\n%s Content:\n", style.SubtitleStyle.Render("📜"))
+		for i, line := range lines {
+			if i == maxDescribedContentLines {
+				fmt.Println(style.DimStyle.Render(fmt.Sprintf("  … %d more lines, use --output json to see them", len(lines)-i)))
+				break
+			}
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	fmt.Printf("\n%s Tool Management Commands:\n", style.SubtitleStyle.Render("🛠️"))
+	if desc.Attached {
+		fmt.Printf("  • Remove: %s\n", style.DimStyle.Render(fmt.Sprintf("kubiya agent tool remove %s %s", desc.AgentUUID, desc.Name)))
+	}
+	fmt.Printf("  • List all: %s\n", style.DimStyle.Render(fmt.Sprintf("kubiya agent tools list %s", desc.AgentUUID)))
+}
+
+func printDescribedList(icon, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("\n%s %s:\n", style.SubtitleStyle.Render(icon), title)
+	for _, item := range items {
+		fmt.Printf("  • %s\n", item)
+	}
+}
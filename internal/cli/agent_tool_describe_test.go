@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeSourceLookup map[string]*kubiya.Source
+
+func (f fakeSourceLookup) GetSourceMetadataCached(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	source, ok := f[uuid]
+	if !ok {
+		return nil, fmt.Errorf("source %s not found", uuid)
+	}
+	return source, nil
+}
+
+func TestDescribeAgentTool(t *testing.T) {
+	sources := fakeSourceLookup{
+		"s-k8s": {
+			UUID: "s-k8s", Name: "kubernetes", URL: "https://github.com/org/tools/tree/main/k8s", PinnedRef: "abc123",
+			KubiyaMetadata: kubiya.KubiyaMetadata{LastUpdated: "2025-03-01T10:00:00Z", UserLastUpdated: "dana@example.com"},
+			Tools: []kubiya.Tool{{
+				Name:  "kubectl",
+				Image: "bitnami/kubectl",
+				Args:  []kubiya.ToolArg{{Name: "command", Type: "str", Required: true}},
+				Env:   []string{"KUBECONFIG"},
+			}},
+		},
+		"s-inline": {
+			UUID: "s-inline", Name: "scripts", Type: "inline",
+			InlineTools: []kubiya.Tool{{Name: "rotate_keys", Alias: "rotate", Content: "./rotate.sh"}},
+		},
+		"s-copy": {UUID: "s-copy", Name: "legacy", Tools: []kubiya.Tool{{Name: "kubectl"}}},
+	}
+	agent := &kubiya.Agent{
+		UUID: "a-1", Name: "devops",
+		Sources: []string{"s-k8s", "s-inline", "s-copy", "s-gone"},
+		Tools:   []string{"kubectl", "create_file"},
+	}
+	ctx := context.Background()
+
+	desc, err := describeAgentTool(ctx, sources, agent, "KUBECTL")
+	require.NoError(t, err)
+	assert.Equal(t, "kubectl", desc.Name)
+	assert.True(t, desc.Attached)
+	assert.Equal(t, "bitnami/kubectl", desc.Tool.Image)
+	assert.Equal(t, &agentToolSource{UUID: "s-k8s", Name: "kubernetes", URL: "https://github.com/org/tools/tree/main/k8s", Ref: "abc123"}, desc.Source)
+	assert.Equal(t, "2025-03-01T10:00:00Z", desc.LastModified)
+	assert.Equal(t, "dana@example.com", desc.LastModifiedBy)
+	assert.Equal(t, []string{"legacy"}, desc.OtherSources)
+
+	// Inline tools of a source, found by alias, need not be attached
+	desc, err = describeAgentTool(ctx, sources, agent, "rotate")
+	require.NoError(t, err)
+	assert.Equal(t, "rotate_keys", desc.Name)
+	assert.False(t, desc.Attached)
+	assert.True(t, desc.Source.Inline)
+	assert.Equal(t, "./rotate.sh", desc.Tool.Content)
+
+	// Attached without a definition in any source
+	desc, err = describeAgentTool(ctx, sources, agent, "create_file")
+	require.NoError(t, err)
+	assert.True(t, desc.Attached)
+	assert.Nil(t, desc.Tool)
+
+	_, err = describeAgentTool(ctx, sources, agent, "helm")
+	assert.EqualError(t, err, "tool 'helm' not found on agent 'devops' or its sources (could not read sources: s-gone)")
+}
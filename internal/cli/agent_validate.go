@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	clierrors "github.com/kubiyabot/cli/internal/errors"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// agentValidationResult holds the violations found in one agent file
+type agentValidationResult struct {
+	File       string              `json:"file"`
+	Valid      bool                `json:"valid"`
+	Violations []kubiya.FieldError `json:"violations"`
+}
+
+// agentLiveChecker checks references of an agent against the organization
+type agentLiveChecker interface {
+	integrationLister
+	sourceLister
+}
+
+func newAgentValidateCommand(cfg *config.Config) *cobra.Command {
+	var (
+		files        []string
+		format       string
+		live         bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate [file...]",
+		Short: "✅ Validate agent definition files without creating them",
+		Long: `Validate agent definition files (JSON or YAML) with the same checks
+'kubiya agent create' runs, without creating or changing anything.
+
+With --live, referenced integrations and sources are also checked to exist in
+the organization. The command exits with 0 when every file is valid, 2 when
+violations were found and 4 when the API could not be reached for --live.`,
+		Example: `  # Validate an agent file
+  kubiya agent validate -f agent.yaml
+
+  # Also check that integrations and sources exist
+  kubiya agent validate -f agent.yaml --live
+
+  # Validate several files in CI with machine-readable output
+  kubiya agent validate agents/*.yaml -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files = append(files, args...)
+			if len(files) == 0 {
+				return clierrors.ValidationError(fmt.Errorf("no agent files given"), "Use -f agent.yaml or pass files as arguments")
+			}
+			if outputFormat != "text" && outputFormat != "json" {
+				return clierrors.ValidationError(fmt.Errorf("unsupported output format: %s", outputFormat), "Use -o text or -o json")
+			}
+
+			var checker agentLiveChecker
+			if live {
+				checker = kubiya.NewClient(cfg)
+			}
+
+			results := make([]agentValidationResult, 0, len(files))
+			for _, file := range files {
+				result, err := validateAgentFile(cmd.Context(), checker, file, format)
+				if err != nil {
+					return clierrors.APIErrorWithContext(err, "Run without --live to skip the existence checks")
+				}
+				results = append(results, result)
+			}
+
+			if outputFormat == "json" {
+				if err := output.EncodeJSON(cmd.OutOrStdout(), results); err != nil {
+					return err
+				}
+			} else {
+				printAgentValidation(cmd.OutOrStdout(), results)
+			}
+
+			violations, invalid := 0, 0
+			for _, r := range results {
+				if !r.Valid {
+					invalid++
+					violations += len(r.Violations)
+				}
+			}
+			if invalid > 0 {
+				return clierrors.ValidationError(fmt.Errorf("%d violation(s) in %d of %d file(s)", violations, invalid, len(results)), "")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&files, "file", "f", nil, "Agent file to validate, - for stdin (can be repeated)")
+	cmd.Flags().StringVar(&format, "format", "", "Input format (json|yaml), detected from the content by default")
+	cmd.Flags().BoolVar(&live, "live", false, "Check that referenced integrations and sources exist")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "output format (text|json)")
+	return cmd
+}
+
+// validateAgentFile validates one agent file. Problems with the file are
+// reported as violations; the error is only set when live checks failed.
+func validateAgentFile(ctx context.Context, checker agentLiveChecker, file, format string) (agentValidationResult, error) {
+	result := agentValidationResult{File: file, Violations: []kubiya.FieldError{}}
+	done := func(errs ...kubiya.FieldError) (agentValidationResult, error) {
+		result.Violations = append(result.Violations, errs...)
+		result.Valid = len(result.Violations) == 0
+		return result, nil
+	}
+
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return done(kubiya.FieldError{Message: fmt.Sprintf("failed to read file: %v", err)})
+	}
+
+	agent, err := parseAgentData(data, format)
+	if err != nil {
+		var verr *kubiya.ValidationError
+		if errors.As(err, &verr) {
+			return done(verr.Fields...)
+		}
+		return done(kubiya.FieldError{Message: err.Error()})
+	}
+
+	fieldErrs := agentFieldErrors(&agent)
+	if checker != nil {
+		if len(agent.Integrations) > 0 {
+			missing, err := missingIntegrations(ctx, checker, agent.Integrations)
+			if err != nil {
+				return result, fmt.Errorf("failed to validate integrations: %w", err)
+			}
+			fieldErrs = append(fieldErrs, missing...)
+		}
+		if len(agent.Sources) > 0 {
+			missing, err := missingSources(ctx, checker, agent.Sources)
+			if err != nil {
+				return result, fmt.Errorf("failed to validate sources: %w", err)
+			}
+			fieldErrs = append(fieldErrs, missing...)
+		}
+	}
+	return done(fieldErrs...)
+}
+
+// printAgentValidation prints the violations of each file
+func printAgentValidation(w io.Writer, results []agentValidationResult) {
+	for _, r := range results {
+		if r.Valid {
+			fmt.Fprintf(w, "%s %s is valid\n", style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(r.File))
+			continue
+		}
+		fmt.Fprintf(w, "%s %s\n", style.ErrorStyle.Render("✗"), style.HighlightStyle.Render(r.File))
+		for _, v := range r.Violations {
+			if v.Path == "" {
+				fmt.Fprintf(w, "  • %s\n", v.Message)
+			} else {
+				fmt.Fprintf(w, "  • %s: %s\n", v.Path, v.Message)
+			}
+		}
+	}
+}
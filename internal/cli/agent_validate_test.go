@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAgentLiveChecker struct {
+	integrations []kubiya.Integration
+	sources      []kubiya.Source
+	err          error
+}
+
+func (f *fakeAgentLiveChecker) ListIntegrations(ctx context.Context) ([]kubiya.Integration, error) {
+	return f.integrations, f.err
+}
+
+func (f *fakeAgentLiveChecker) ListSources(ctx context.Context) ([]kubiya.Source, error) {
+	return f.sources, f.err
+}
+
+func writeAgentFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func violationPaths(result agentValidationResult) []string {
+	paths := []string{}
+	for _, v := range result.Violations {
+		paths = append(paths, v.Path)
+	}
+	return paths
+}
+
+func TestValidateAgentFile(t *testing.T) {
+	const agent = "name: ops\nsources: [abc-12345678]\nintegrations: [slack, jira]\n"
+
+	t.Run("offline", func(t *testing.T) {
+		result, err := validateAgentFile(context.Background(), nil, writeAgentFile(t, "agent.yaml", agent), "")
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Violations)
+	})
+
+	t.Run("field errors", func(t *testing.T) {
+		path := writeAgentFile(t, "agent.json", `{"name": "", "sources": ["not-a-uuid!"], "environment_variables": {"TOKEN": ""}}`)
+		result, err := validateAgentFile(context.Background(), nil, path, "")
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, []string{"name", "sources[0]", "environment_variables.TOKEN"}, violationPaths(result))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		result, err := validateAgentFile(context.Background(), nil, writeAgentFile(t, "agent.json", `{"name": `), "")
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, []string{""}, violationPaths(result))
+	})
+
+	t.Run("live", func(t *testing.T) {
+		checker := &fakeAgentLiveChecker{
+			integrations: []kubiya.Integration{{Name: "slack"}},
+			sources:      []kubiya.Source{{UUID: "def-45678901"}},
+		}
+		result, err := validateAgentFile(context.Background(), checker, writeAgentFile(t, "agent.yaml", agent), "")
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, []string{"integrations[1]", "sources[0]"}, violationPaths(result))
+	})
+
+	t.Run("live api failure", func(t *testing.T) {
+		checker := &fakeAgentLiveChecker{err: errors.New("connection refused")}
+		_, err := validateAgentFile(context.Background(), checker, writeAgentFile(t, "agent.yaml", agent), "")
+		assert.ErrorContains(t, err, "connection refused")
+	})
+}
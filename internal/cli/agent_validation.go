@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kubiyabot/cli/internal/docload"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// Expected value kinds of the agent file fields
+const (
+	kindString     = "a string"
+	kindBool       = "a boolean"
+	kindStringList = "a list of strings"
+	kindList       = "a list"
+	kindStringMap  = "a map of strings"
+	kindObject     = "an object"
+)
+
+// agentFieldKinds lists the known agent file fields and the kind of value each
+// one accepts. Unknown fields are ignored to stay compatible with exported
+// agents carrying extra metadata.
+var agentFieldKinds = map[string]string{
+	"uuid":                  kindString,
+	"id":                    kindString,
+	"name":                  kindString,
+	"description":           kindString,
+	"desc":                  kindString,
+	"instruction_type":      kindString,
+	"llm_model":             kindString,
+	"ai_instructions":       kindString,
+	"image":                 kindString,
+	"managed_by":            kindString,
+	"is_debug_mode":         kindBool,
+	"sources":               kindStringList,
+	"secrets":               kindStringList,
+	"allowed_groups":        kindStringList,
+	"allowed_users":         kindStringList,
+	"owners":                kindStringList,
+	"runners":               kindStringList,
+	"integrations":          kindStringList,
+	"links":                 kindStringList,
+	"tools":                 kindStringList,
+	"tasks":                 kindStringList,
+	"tags":                  kindStringList,
+	"starters":              kindList,
+	"environment_variables": kindStringMap,
+	"metadata":              kindObject,
+}
+
+// validateAgentDocument checks the field types of a raw agent file and
+// returns one error per offending JSON path. Syntax errors are left to the
+// regular decoder.
+func validateAgentDocument(data []byte, format string) []kubiya.FieldError {
+	var doc map[string]interface{}
+	if err := docload.UnmarshalFormat(data, format, &doc); err != nil || doc == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []kubiya.FieldError
+	for _, key := range keys {
+		kind, known := agentFieldKinds[key]
+		value := doc[key]
+		if !known || value == nil {
+			continue
+		}
+
+		mismatch := func(path string, got interface{}) {
+			errs = append(errs, kubiya.FieldError{
+				Path:    path,
+				Message: fmt.Sprintf("expected %s, got %s", kind, describeValueType(got)),
+			})
+		}
+
+		switch kind {
+		case kindString:
+			if _, ok := value.(string); !ok {
+				mismatch(key, value)
+			}
+		case kindBool:
+			if _, ok := value.(bool); !ok {
+				mismatch(key, value)
+			}
+		case kindList:
+			if _, ok := value.([]interface{}); !ok {
+				mismatch(key, value)
+			}
+		case kindObject:
+			if _, ok := value.(map[string]interface{}); !ok {
+				mismatch(key, value)
+			}
+		case kindStringList:
+			items, ok := value.([]interface{})
+			if !ok {
+				mismatch(key, value)
+				continue
+			}
+			for i, item := range items {
+				if _, ok := item.(string); !ok {
+					errs = append(errs, kubiya.FieldError{
+						Path:    fmt.Sprintf("%s[%d]", key, i),
+						Message: fmt.Sprintf("expected a string, got %s", describeValueType(item)),
+					})
+				}
+			}
+		case kindStringMap:
+			entries, ok := value.(map[string]interface{})
+			if !ok {
+				mismatch(key, value)
+				continue
+			}
+			names := make([]string, 0, len(entries))
+			for name := range entries {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if _, ok := entries[name].(string); !ok {
+					errs = append(errs, kubiya.FieldError{
+						Path:    key + "." + name,
+						Message: fmt.Sprintf("expected a string, got %s (quote the value)", describeValueType(entries[name])),
+					})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// agentFieldErrors checks the values of a decoded agent without contacting
+// the API
+func agentFieldErrors(agent *kubiya.Agent) []kubiya.FieldError {
+	var fieldErrs []kubiya.FieldError
+	addErr := func(path, format string, args ...interface{}) {
+		fieldErrs = append(fieldErrs, kubiya.FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if agent.Name == "" {
+		addErr("name", "agent name cannot be empty")
+	}
+
+	// Validate all sources have non-empty UUIDs and match the expected UUID format
+	for i, sourceID := range agent.Sources {
+		path := fmt.Sprintf("sources[%d]", i)
+		if sourceID == "" {
+			addErr(path, "empty UUID")
+			continue
+		}
+		if !isValidUUID(sourceID) {
+			addErr(path, "invalid UUID format %q", sourceID)
+		}
+	}
+
+	for i, secret := range agent.Secrets {
+		if secret == "" {
+			addErr(fmt.Sprintf("secrets[%d]", i), "empty secret name")
+		}
+	}
+
+	for i, integration := range agent.Integrations {
+		if integration == "" {
+			addErr(fmt.Sprintf("integrations[%d]", i), "empty integration name")
+		}
+	}
+
+	// Validate environment variables have non-empty keys and values
+	envKeys := make([]string, 0, len(agent.Environment))
+	for key := range agent.Environment {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		if key == "" {
+			addErr("environment_variables", "environment variable has empty key")
+		} else if agent.Environment[key] == "" {
+			addErr("environment_variables."+key, "empty value")
+		}
+	}
+
+	return fieldErrs
+}
+
+// integrationLister lists the integrations of the organization
+type integrationLister interface {
+	ListIntegrations(ctx context.Context) ([]kubiya.Integration, error)
+}
+
+// sourceLister lists the sources of the organization
+type sourceLister interface {
+	ListSources(ctx context.Context) ([]kubiya.Source, error)
+}
+
+// missingIntegrations reports the named integrations that do not exist
+func missingIntegrations(ctx context.Context, client integrationLister, names []string) ([]kubiya.FieldError, error) {
+	integrations, err := client.ListIntegrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]bool, len(integrations))
+	for _, integration := range integrations {
+		available[integration.Name] = true
+	}
+
+	var errs []kubiya.FieldError
+	for i, name := range names {
+		if name != "" && !available[name] {
+			errs = append(errs, kubiya.FieldError{
+				Path:    fmt.Sprintf("integrations[%d]", i),
+				Message: fmt.Sprintf("integration %q does not exist in the system", name),
+			})
+		}
+	}
+	return errs, nil
+}
+
+// missingSources reports the source UUIDs that do not exist
+func missingSources(ctx context.Context, client sourceLister, uuids []string) ([]kubiya.FieldError, error) {
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		available[source.UUID] = true
+	}
+
+	var errs []kubiya.FieldError
+	for i, uuid := range uuids {
+		if uuid != "" && !available[uuid] {
+			errs = append(errs, kubiya.FieldError{
+				Path:    fmt.Sprintf("sources[%d]", i),
+				Message: fmt.Sprintf("source %q does not exist in the system", uuid),
+			})
+		}
+	}
+	return errs, nil
+}
+
+// describeValueType names the type of a decoded JSON/YAML value
+func describeValueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int, int64, float64:
+		return "a number"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestValidateAgentDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   []string
+	}{
+		{
+			name:   "valid yaml",
+			data:   "name: ops\nsources: [abc-12345678]\nenvironment_variables:\n  LOG_LEVEL: debug\n",
+			format: "yaml",
+		},
+		{
+			name:   "wrong types in yaml",
+			data:   "name: 42\nsources:\n  - abc-12345678\n  - 7\nenvironment_variables:\n  PORT: 8080\nis_debug_mode: \"yes\"\n",
+			format: "yaml",
+			want:   []string{"environment_variables.PORT", "is_debug_mode", "name", "sources[1]"},
+		},
+		{
+			name:   "list given as string in json",
+			data:   `{"name": "ops", "secrets": "DB_PASSWORD", "custom": 1}`,
+			format: "json",
+			want:   []string{"secrets"},
+		},
+		{
+			name:   "syntax errors are left to the decoder",
+			data:   `{"name": `,
+			format: "json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, fe := range validateAgentDocument([]byte(tt.data), tt.format) {
+				got = append(got, fe.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected paths %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseAgentDataReturnsValidationError(t *testing.T) {
+	_, err := parseAgentData([]byte("name: ops\nsources: abc\n"), "yaml")
+
+	var verr *kubiya.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if len(verr.Fields) != 1 || verr.Fields[0].Path != "sources" {
+		t.Errorf("unexpected field errors %+v", verr.Fields)
+	}
+}
+
+func TestParseAgentDataDetectsFormat(t *testing.T) {
+	inputs := map[string]string{
+		"json": `{"name": "ops", "llm_model": "gpt-4o", "environment_variables": {"REGION": "eu"}}`,
+		"yaml": "# ops agent\nname: ops\nllm_model: gpt-4o\nenvironment_variables:\n  REGION: eu\n",
+	}
+	for name, data := range inputs {
+		agent, err := parseAgentData([]byte(data), "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", name, err)
+		}
+		if agent.Name != "ops" || agent.LLMModel != "gpt-4o" || agent.Environment["REGION"] != "eu" {
+			t.Errorf("%s: unexpected agent %+v", name, agent)
+		}
+	}
+
+	if _, err := parseAgentData([]byte("name: ops\n"), "json"); err == nil {
+		t.Error("expected an error for YAML read with an explicit json format")
+	}
+}
+
+func TestParseWebhooks(t *testing.T) {
+	inputs := map[string]string{
+		"json list":   `[{"name": "alerts", "agent_id": "a-1", "prompt": "triage"}]`,
+		"json single": `{"name": "alerts", "agent_id": "a-1", "prompt": "triage"}`,
+		"yaml list":   "- name: alerts\n  agent_id: a-1\n  prompt: triage\n",
+		"yaml single": "name: alerts\nagent_id: a-1\nprompt: triage\n",
+	}
+	for name, data := range inputs {
+		webhooks, err := parseWebhooks([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", name, err)
+		}
+		if len(webhooks) != 1 || webhooks[0].Name != "alerts" || webhooks[0].AgentID != "a-1" {
+			t.Errorf("%s: unexpected webhooks %+v", name, webhooks)
+		}
+	}
+
+	if _, err := parseWebhooks([]byte("name: [")); err == nil {
+		t.Error("expected an error for an invalid document")
+	}
+}
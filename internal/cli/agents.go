@@ -3,12 +3,14 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -17,6 +19,8 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/docload"
+	"github.com/kubiyabot/cli/internal/httpclient"
 	"github.com/kubiyabot/cli/internal/kubiya"
 	"github.com/kubiyabot/cli/internal/style"
 	"github.com/kubiyabot/cli/internal/tui"
@@ -33,23 +37,34 @@ func newAgentCommand(cfg *config.Config) *cobra.Command {
 	// V2 Commands only - aligned with Control Plane API
 	cmd.AddCommand(
 		newListAgentsCommand(cfg),           // ✅ V2 - GET /api/v1/agents
-		newGetAgentCommand(cfg),             // ✅ V2 - GET /api/v1/agents/:id
+		paged(newGetAgentCommand(cfg)),      // ✅ V2 - GET /api/v1/agents/:id
 		newCreateAgentCommand(cfg),          // ✅ V2 - POST /api/v1/agents
 		newEditAgentCommand(cfg),            // ✅ V2 - PATCH /api/v1/agents/:id
 		newDeleteAgentCommand(cfg),          // ✅ V2 - DELETE /api/v1/agents/:id
 		newAgentInteractiveChatCommand(cfg), // ✅ V2 - POST /api/v1/agents/:id/execute
 		newAgentExecCommand(cfg),            // ✅ V2 - POST /api/v1/agents/:id/execute
+		newAgentCopyCommand(cfg),            // ✅ V2 - GET + POST /api/v1/agents across orgs
+		newAgentImportCommand(cfg),          // ✅ V2 - POST/PATCH /api/v1/agents from Terraform
+		newAgentEnvCommand(cfg),             // ✅ V2 - execution_environment.env_vars via PATCH /api/v1/agents/:id
+		newAgentPromptCommand(cfg),          // ✅ V2 - system_prompt via PATCH /api/v1/agents/:id, with local version history
+		newAgentValidateCommand(cfg),        // ⚠️ V1 - local checks, --live lists integrations and sources
+		newAgentStartersCommand(cfg),        // ⚠️ V1 - starters list/add/remove via PUT /agents/:id
+		newAgentModelCommand(cfg),           // ⚠️ V1 - llm_model of one agent, migrate across agents
+		newAgentChownCommand(cfg),           // ⚠️ V1 - owners via PUT /agents/:id, checked against users
+		newAgentSmokeTestCommand(cfg),       // ✅ V2 - canary prompt via POST /api/v1/agents/:id/execute
 	)
 
 	// V1 Commands - Removed for V2 Migration
 	// - tools: Part of agent configuration in V2
 	// - integrations: Part of agent execution_environment in V2
-	// - env: Part of agent execution_environment.env_vars in V2
 	// - secrets: Part of agent execution_environment.secrets in V2
-	// - model: Part of agent model_id in V2
 	// - access: Need V2 access control endpoints
 	// - runner: Part of agent runner_name in V2
-	// - prompt: Part of agent system_prompt in V2
+
+	// Kept for contexts still on the V1 API, where runners are set via PUT /agents/:id
+	if cfg.UseV1API {
+		cmd.AddCommand(newAgentRunnerCommand(cfg))
+	}
 
 	return cmd
 }
@@ -68,6 +83,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 		secrets             []string
 		integrations        []string
 		envVars             []string
+		envFile             string
 		inlineSourceFile    string
 		inlineSourceStdin   bool
 		webhooks            []string // Existing webhook IDs to attach
@@ -126,13 +142,22 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
   # Create with knowledge item
   kubiya agent create --name "Docs Bot" --knowledge-file docs.md`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Variables from --env-file come first so --env wins on conflicts
+			if envFile != "" {
+				entries, err := envFileEntries(envFile)
+				if err != nil {
+					return err
+				}
+				envVars = append(entries, envVars...)
+			}
+
 			// Route to V2 for simple creates (not interactive, not from file)
 			if !cfg.UseV1API && !interactive && inputFile == "" && !fromStdin {
 				if name == "" {
 					return fmt.Errorf("--name is required")
 				}
 				// Simple V2 create
-				return createAgentV2(cfg, name, description, llmModel, "", "", nil, nil)
+				return createAgentV2(cfg, name, description, llmModel, "", "", nil, nil, parseEnvVars(envVars))
 			}
 
 			// V1 API implementation (supports all advanced features)
@@ -146,7 +171,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 
 			var agent kubiya.Agent
 			var err error
-			var createdResources []string // Track resources created to show in summary
+			summary := &bulkSummary{} // Outcome of every resource, shown at the end
 
 			// Process input based on flags
 			if inputFile != "" || fromStdin {
@@ -210,7 +235,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			fmt.Printf("✅ Created agent: %s (UUID: %s)\n", created.Name, created.UUID)
-			createdResources = append(createdResources, fmt.Sprintf("Agent: %s (%s)", created.Name, created.UUID))
+			summary.ok(fmt.Sprintf("Agent: %s (%s)", created.Name, created.UUID))
 
 			// Handle inline sources if provided
 			if inlineSourceFile != "" || inlineSourceStdin {
@@ -259,7 +284,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 				}
 
 				fmt.Printf("✅ Created inline source with UUID: %s\n", sourceUUID)
-				createdResources = append(createdResources, fmt.Sprintf("Source: %s", sourceUUID))
+				summary.ok(fmt.Sprintf("Source: %s", sourceUUID))
 
 				// Bind the source to the agent
 				fmt.Printf("Attaching source %s to agent %s...\n", sourceUUID, created.UUID)
@@ -276,7 +301,9 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 					// Read file content
 					content, err := os.ReadFile(filename)
 					if err != nil {
-						return fmt.Errorf("failed to read knowledge file '%s': %w", filename, err)
+						fmt.Printf("⚠️ Failed to read knowledge file %s: %v\n", filename, err)
+						summary.fail("Knowledge file: "+filename, err)
+						continue
 					}
 
 					// Create a name from the filename if not specified
@@ -295,11 +322,13 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 
 					created, err := client.CreateKnowledge(cmd.Context(), item)
 					if err != nil {
-						return fmt.Errorf("failed to create knowledge item from file '%s': %w", filename, err)
+						fmt.Printf("⚠️ Failed to create knowledge item from file %s: %v\n", filename, err)
+						summary.fail("Knowledge file: "+filename, err)
+						continue
 					}
 
 					fmt.Printf("✅ Created knowledge item: %s (UUID: %s)\n", created.Name, created.UUID)
-					createdResources = append(createdResources, fmt.Sprintf("Knowledge: %s (%s)", created.Name, created.UUID))
+					summary.ok(fmt.Sprintf("Knowledge: %s (%s)", created.Name, created.UUID))
 
 					// Add the knowledge UUID to the list
 					knowledgeIds = append(knowledgeIds, created.UUID)
@@ -318,14 +347,16 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 
 				if created.UUID == "" {
 					fmt.Printf("⚠️ Cannot attach webhooks: agent UUID is empty\n")
+					summary.fail("Webhooks", fmt.Errorf("agent UUID is empty"))
 				} else {
 					// Attach existing webhooks
 					for _, webhookID := range webhooks {
 						if err := attachWebhookToAgent(cmd.Context(), client, webhookID, created.UUID); err != nil {
 							fmt.Printf("⚠️ Failed to attach webhook %s: %v\n", webhookID, err)
+							summary.fail("Attach webhook: "+webhookID, err)
 						} else {
 							fmt.Printf("✅ Attached webhook %s to agent\n", webhookID)
-							createdResources = append(createdResources, fmt.Sprintf("Attached webhook: %s", webhookID))
+							summary.ok(fmt.Sprintf("Attached webhook: %s", webhookID))
 						}
 					}
 
@@ -344,14 +375,15 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 						webhook, err := createWebhook(cmd.Context(), client, created.UUID, dest, method, prompt)
 						if err != nil {
 							fmt.Printf("⚠️ Failed to create webhook for %s: %v\n", dest, err)
+							summary.fail("Create webhook for "+dest, err)
 						} else {
 							fmt.Printf("✅ Created %s webhook (ID: %s)\n", method, webhook.ID)
 							if webhook.WebhookURL != "" {
 								fmt.Printf("   📋 Webhook URL: %s\n", style.HighlightStyle.Render(webhook.WebhookURL))
-								createdResources = append(createdResources, fmt.Sprintf("Created %s webhook: %s (URL: %s)",
+								summary.ok(fmt.Sprintf("Created %s webhook: %s (URL: %s)",
 									method, webhook.ID, webhook.WebhookURL))
 							} else {
-								createdResources = append(createdResources, fmt.Sprintf("Created %s webhook: %s",
+								summary.ok(fmt.Sprintf("Created %s webhook: %s",
 									method, webhook.ID))
 							}
 						}
@@ -367,14 +399,15 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 						webhook, err := createWebhook(cmd.Context(), client, created.UUID, "", "http", prompt)
 						if err != nil {
 							fmt.Printf("⚠️ Failed to create HTTP webhook: %v\n", err)
+							summary.fail("Create HTTP webhook", err)
 						} else {
 							fmt.Printf("✅ Created HTTP webhook (ID: %s)\n", webhook.ID)
 							if webhook.WebhookURL != "" {
 								fmt.Printf("   📋 Webhook URL: %s\n", style.HighlightStyle.Render(webhook.WebhookURL))
-								createdResources = append(createdResources, fmt.Sprintf("Created HTTP webhook: %s (URL: %s)",
+								summary.ok(fmt.Sprintf("Created HTTP webhook: %s (URL: %s)",
 									webhook.ID, webhook.WebhookURL))
 							} else {
-								createdResources = append(createdResources, fmt.Sprintf("Created HTTP webhook: %s",
+								summary.ok(fmt.Sprintf("Created HTTP webhook: %s",
 									webhook.ID))
 							}
 						}
@@ -385,20 +418,22 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 						webhooks, err := readWebhooksFromFile(webhookFile)
 						if err != nil {
 							fmt.Printf("⚠️ Failed to read webhook file: %v\n", err)
+							summary.fail("Webhook file: "+webhookFile, err)
 						} else {
 							for _, webhook := range webhooks {
 								webhook.AgentID = created.UUID // Set the agent ID
 								createdWebhook, err := client.CreateWebhook(cmd.Context(), webhook)
 								if err != nil {
 									fmt.Printf("⚠️ Failed to create webhook from file: %v\n", err)
+									summary.fail("Create webhook from file: "+webhook.Name, err)
 								} else {
 									fmt.Printf("✅ Created webhook from file (ID: %s)\n", createdWebhook.ID)
 									if createdWebhook.WebhookURL != "" {
 										fmt.Printf("   📋 Webhook URL: %s\n", style.HighlightStyle.Render(createdWebhook.WebhookURL))
-										createdResources = append(createdResources, fmt.Sprintf("Created webhook from file: %s (URL: %s)",
+										summary.ok(fmt.Sprintf("Created webhook from file: %s (URL: %s)",
 											createdWebhook.ID, createdWebhook.WebhookURL))
 									} else {
-										createdResources = append(createdResources, fmt.Sprintf("Created webhook from file: %s",
+										summary.ok(fmt.Sprintf("Created webhook from file: %s",
 											createdWebhook.ID))
 									}
 								}
@@ -408,13 +443,9 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 				}
 			}
 
-			// Show summary of created resources
-			if len(createdResources) > 0 {
-				fmt.Printf("\n%s\n", style.SubtitleStyle.Render("Created Resources"))
-				for _, resource := range createdResources {
-					fmt.Printf("• %s\n", resource)
-				}
-			}
+			// Show the outcome of every resource together
+			fmt.Printf("\n%s\n", style.SubtitleStyle.Render("Summary"))
+			summary.print(os.Stdout)
 
 			fmt.Printf("\n%s\n", style.SubtitleStyle.Render("Next Steps"))
 			if created.UUID != "" {
@@ -429,7 +460,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 					style.CommandStyle.Render("kubiya agent create --interactive"))
 			}
 
-			return nil
+			return summary.err()
 		},
 	}
 
@@ -442,7 +473,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 
 	// Input file flags
 	cmd.Flags().StringVarP(&inputFile, "file", "f", "", "File containing agent configuration (JSON or YAML)")
-	cmd.Flags().StringVar(&inputFormat, "format", "json", "Input format (json|yaml)")
+	cmd.Flags().StringVar(&inputFormat, "format", "", "Input format (json|yaml), detected from the content by default")
 	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read configuration from stdin")
 
 	// Component flags
@@ -450,6 +481,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 	cmd.Flags().StringArrayVar(&secrets, "secret", []string{}, "Secret name to attach (can be specified multiple times)")
 	cmd.Flags().StringArrayVar(&integrations, "integration", []string{}, "Integration to attach (can be specified multiple times)")
 	cmd.Flags().StringArrayVar(&envVars, "env", []string{}, "Environment variable in KEY=VALUE format (can be specified multiple times)")
+	cmd.Flags().StringVar(&envFile, "env-file", "", "Load environment variables from a .env file (--env values take precedence)")
 
 	// Add flags for inline sources
 	cmd.Flags().StringVar(&inlineSourceFile, "inline-source", "", "File containing inline source tool definitions (YAML or JSON)")
@@ -473,7 +505,7 @@ func newCreateAgentCommand(cfg *config.Config) *cobra.Command {
 		"  - slack: For Slack notifications. Destination should be a Slack channel or webhook URL.\n" +
 		"  - teams: For Microsoft Teams. Destination should be a Teams webhook URL."
 
-	return cmd
+	return withFlagRules(cmd, agentWebhookFlagRules())
 }
 
 // Helper function to attach a webhook to a agent
@@ -546,49 +578,34 @@ func readAgentFromStdin(format string) (kubiya.Agent, error) {
 	return parseAgentData(data, format)
 }
 
-// parseAgentData parses agent data from JSON or YAML
+// parseAgentData parses agent data from JSON or YAML. An empty format
+// detects it from the content.
 func parseAgentData(data []byte, format string) (kubiya.Agent, error) {
 	var agent kubiya.Agent
 
-	switch format {
-	case "json", "":
-		if err := json.Unmarshal(data, &agent); err != nil {
-			return kubiya.Agent{}, fmt.Errorf("invalid JSON: %w", err)
-		}
-		// Ensure nil fields are initialized to avoid API errors
-		if agent.Environment == nil {
-			fmt.Printf("DEBUG: Initializing nil Environment\n")
-			agent.Environment = make(map[string]string)
-		}
-		if agent.Owners == nil {
-			fmt.Printf("DEBUG: Initializing nil Owners\n")
-			agent.Owners = []string{}
-		}
-	case "yaml", "yml":
-		if err := yaml.Unmarshal(data, &agent); err != nil {
-			return kubiya.Agent{}, fmt.Errorf("invalid YAML: %w", err)
-		}
-		// Ensure nil fields are initialized to avoid API errors
-		if agent.Environment == nil {
-			agent.Environment = make(map[string]string)
-		}
-		if agent.Owners == nil {
-			agent.Owners = []string{}
-		}
-	default:
-		return kubiya.Agent{}, fmt.Errorf("unsupported format: %s", format)
+	// Check field types up front so users get every offending path at once
+	// instead of the first decoder error
+	if fieldErrs := validateAgentDocument(data, format); len(fieldErrs) > 0 {
+		return kubiya.Agent{}, &kubiya.ValidationError{Fields: fieldErrs}
+	}
+
+	if err := docload.UnmarshalFormat(data, format, &agent); err != nil {
+		return kubiya.Agent{}, err
+	}
+	// Ensure nil fields are initialized to avoid API errors
+	if agent.Environment == nil {
+		agent.Environment = make(map[string]string)
+	}
+	if agent.Owners == nil {
+		agent.Owners = []string{}
 	}
 
-	fmt.Printf("DEBUG: Agent after parsing - Environment: %+v, Owners: %+v\n", agent.Environment, agent.Owners)
 	return agent, nil
 }
 
-// validateAgent performs basic validation on a agent
+// validateAgent performs basic validation on a agent. All problems are
+// collected and returned together as a *kubiya.ValidationError.
 func validateAgent(client *kubiya.Client, ctx context.Context, agent *kubiya.Agent) error {
-	if agent.Name == "" {
-		return fmt.Errorf("agent name cannot be empty")
-	}
-
 	// Set defaults if not provided
 	if agent.LLMModel == "" {
 		agent.LLMModel = "azure/gpt-4"
@@ -606,64 +623,19 @@ func validateAgent(client *kubiya.Client, ctx context.Context, agent *kubiya.Age
 		agent.Owners = []string{}
 	}
 
-	// Validate all sources have non-empty UUIDs and match the expected UUID format
-	for i, sourceID := range agent.Sources {
-		if sourceID == "" {
-			return fmt.Errorf("source at index %d has empty UUID", i)
-		}
+	fieldErrs := agentFieldErrors(agent)
 
-		// Basic UUID format validation (simple regex check)
-		if !isValidUUID(sourceID) {
-			return fmt.Errorf("source at index %d has invalid UUID format: %s", i, sourceID)
-		}
-
-		// Optional: Validate that the source exists
-		// This requires an API call, so it might be expensive
-	}
-
-	// Validate all secrets have non-empty names
-	for i, secret := range agent.Secrets {
-		if secret == "" {
-			return fmt.Errorf("secret at index %d has empty name", i)
-		}
-
-		// Optionally validate that the secret exists
-		// This requires an API call, so it might be expensive
-	}
-
-	// Validate all integrations have non-empty names and actually exist
+	// Validate all integrations actually exist
 	if len(agent.Integrations) > 0 {
-		// Get all available integrations
-		integrations, err := client.ListIntegrations(ctx)
+		missing, err := missingIntegrations(ctx, client, agent.Integrations)
 		if err != nil {
 			return fmt.Errorf("failed to validate integrations: %w", err)
 		}
-
-		availableIntegrations := make(map[string]bool)
-		for _, integration := range integrations {
-			availableIntegrations[integration.Name] = true
-		}
-
-		for i, integration := range agent.Integrations {
-			if integration == "" {
-				return fmt.Errorf("integration at index %d has empty name", i)
-			}
-
-			// Check if integration exists in the system
-			if !availableIntegrations[integration] {
-				return fmt.Errorf("integration '%s' does not exist in the system", integration)
-			}
-		}
+		fieldErrs = append(fieldErrs, missing...)
 	}
 
-	// Validate environment variables have non-empty keys and values
-	for key, value := range agent.Environment {
-		if key == "" {
-			return fmt.Errorf("environment variable has empty key")
-		}
-		if value == "" {
-			return fmt.Errorf("environment variable '%s' has empty value", key)
-		}
+	if len(fieldErrs) > 0 {
+		return &kubiya.ValidationError{Fields: fieldErrs}
 	}
 
 	// Ensure all required fields are initialized properly
@@ -753,6 +725,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 	var (
 		interactive        bool
 		editor             bool
+		editorFormat       string
 		name               string
 		description        string
 		llmModel           string
@@ -765,6 +738,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 		removeSecrets      []string
 		addEnvVars         []string
 		removeEnvVars      []string
+		envFile            string
 		addIntegrations    []string
 		removeIntegrations []string
 		addTools           []string
@@ -800,12 +774,27 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
   # - Create inline sources with custom code or YAML
 
   # Edit using JSON editor
-  kubiya agent edit abc-123 --editor`,
+  kubiya agent edit abc-123 --editor
+
+  # Edit as YAML, with a comment describing each field
+  kubiya agent edit abc-123 --editor --format yaml`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if editorFormat != "json" && editorFormat != "yaml" {
+				return fmt.Errorf("unsupported editor format %q, use json or yaml", editorFormat)
+			}
 			client := kubiya.NewClient(cfg)
 			uuid := args[0]
 
+			// Variables from --env-file come first so --add-env wins on conflicts
+			if envFile != "" {
+				entries, err := envFileEntries(envFile)
+				if err != nil {
+					return err
+				}
+				addEnvVars = append(entries, addEnvVars...)
+			}
+
 			// Get existing agent
 			agent, err := client.GetAgent(cmd.Context(), uuid)
 			if err != nil {
@@ -813,7 +802,9 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			var updated kubiya.Agent
-			var createdResources []string // Track resources created to show in summary
+			summary := &bulkSummary{} // Outcome of every resource, shown at the end
+			// Fields unknown to the CLI, kept when editing as YAML
+			var rawAgent, extraFields map[string]interface{}
 
 			if interactive {
 				// Use TUI form
@@ -828,6 +819,21 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					return fmt.Errorf("agent editing cancelled")
 				}
 				updated = *result
+			} else if editor && editorFormat == "yaml" {
+				rawAgent, err = client.GetAgentRaw(cmd.Context(), uuid)
+				if err != nil {
+					return fmt.Errorf("failed to get agent: %w", err)
+				}
+				updated, extraFields, err = editAgentYAML(rawAgent, func(content []byte) ([]byte, error) {
+					return editInEditor(content, "kubiya-agent-*.yaml")
+				})
+				if errors.Is(err, errEditCancelled) {
+					fmt.Println("Edit cancelled, no changes made.")
+					return nil
+				}
+				if err != nil {
+					return err
+				}
 			} else if editor {
 				// Use JSON editor
 				updated, err = editAgentJSON(agent)
@@ -865,7 +871,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					}
 					newInstructions = string(data)
 				} else if instructionsURL != "" {
-					resp, err := http.Get(instructionsURL)
+					resp, err := httpclient.Get(instructionsURL)
 					if err != nil {
 						return fmt.Errorf("failed to fetch instructions from URL: %w", err)
 					}
@@ -984,7 +990,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 							return fmt.Errorf("failed to read tools file: %w", err)
 						}
 					} else if toolsURL != "" {
-						resp, err := http.Get(toolsURL)
+						resp, err := httpclient.Get(toolsURL)
 						if err != nil {
 							return fmt.Errorf("failed to fetch tools from URL: %w", err)
 						}
@@ -999,32 +1005,16 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					var newTools []string
 
 					// First try to parse as a simple string array (tool names/UUIDs)
-					if strings.HasSuffix(strings.ToLower(toolsFile), ".yaml") || strings.HasSuffix(strings.ToLower(toolsFile), ".yml") || strings.Contains(string(toolsData), "---") {
-						if err := yaml.Unmarshal(toolsData, &newTools); err != nil {
-							// If that fails, try parsing as full tool definitions
-							var toolDefinitions []map[string]interface{}
-							if err := yaml.Unmarshal(toolsData, &toolDefinitions); err != nil {
-								return fmt.Errorf("failed to parse tools YAML as either string array or tool definitions: %w", err)
-							}
-							// Extract tool names from definitions
-							for _, tool := range toolDefinitions {
-								if name, ok := tool["name"].(string); ok {
-									newTools = append(newTools, name)
-								}
-							}
+					if err := docload.Unmarshal(toolsData, &newTools); err != nil {
+						// If that fails, try parsing as full tool definitions
+						var toolDefinitions []map[string]interface{}
+						if err := docload.Unmarshal(toolsData, &toolDefinitions); err != nil {
+							return fmt.Errorf("failed to parse tools as either string array or tool definitions: %w", err)
 						}
-					} else {
-						if err := json.Unmarshal(toolsData, &newTools); err != nil {
-							// If that fails, try parsing as full tool definitions
-							var toolDefinitions []map[string]interface{}
-							if err := json.Unmarshal(toolsData, &toolDefinitions); err != nil {
-								return fmt.Errorf("failed to parse tools JSON as either string array or tool definitions: %w", err)
-							}
-							// Extract tool names from definitions
-							for _, tool := range toolDefinitions {
-								if name, ok := tool["name"].(string); ok {
-									newTools = append(newTools, name)
-								}
+						// Extract tool names from definitions
+						for _, tool := range toolDefinitions {
+							if name, ok := tool["name"].(string); ok {
+								newTools = append(newTools, name)
 							}
 						}
 					}
@@ -1114,6 +1104,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 
 			// Generate a diff for display
 			diff := generateAgentDiff(agent, &updated)
+			diff = append(diff, agentExtraFieldChanges(rawAgent, extraFields)...)
 
 			// Show changes
 			fmt.Printf("\n%s\n\n", style.TitleStyle.Render(" 🔄 Updating Agent "))
@@ -1164,12 +1155,21 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 				"tasks":                 updated.Tasks,
 				"tags":                  updated.Tags,
 			}
+			if updated.Starters != nil {
+				updateData["starters"] = updated.Starters
+			}
+			for key, value := range extraFields {
+				updateData[key] = value
+			}
 
 			// Update the agent using the map instead of struct
 			result, err := client.UpdateAgentRaw(cmd.Context(), uuid, updateData)
 			if err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
+			if updated.AIInstructions != agent.AIInstructions {
+				snapshotAgentPrompt(uuid, agent.AIInstructions, "agent edit")
+			}
 
 			fmt.Printf("%s Updated agent: %s\n\n",
 				style.SuccessStyle.Render("✅"),
@@ -1180,9 +1180,10 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 			for _, webhookID := range addWebhooks {
 				if err := attachWebhookToAgent(cmd.Context(), client, webhookID, uuid); err != nil {
 					fmt.Printf("⚠️ Failed to attach webhook %s: %v\n", webhookID, err)
+					summary.fail("Attach webhook: "+webhookID, err)
 				} else {
 					fmt.Printf("✅ Attached webhook %s to agent\n", webhookID)
-					createdResources = append(createdResources, fmt.Sprintf("Attached webhook: %s", webhookID))
+					summary.ok(fmt.Sprintf("Attached webhook: %s", webhookID))
 				}
 			}
 
@@ -1191,6 +1192,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 				webhook, err := client.GetWebhook(cmd.Context(), webhookID)
 				if err != nil {
 					fmt.Printf("⚠️ Failed to get webhook %s: %v\n", webhookID, err)
+					summary.fail("Detach webhook: "+webhookID, err)
 					continue
 				}
 
@@ -1199,12 +1201,14 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					webhook.AgentID = ""
 					if _, err := client.UpdateWebhook(cmd.Context(), webhookID, *webhook); err != nil {
 						fmt.Printf("⚠️ Failed to detach webhook %s: %v\n", webhookID, err)
+						summary.fail("Detach webhook: "+webhookID, err)
 					} else {
 						fmt.Printf("✅ Detached webhook %s from agent\n", webhookID)
-						createdResources = append(createdResources, fmt.Sprintf("Detached webhook: %s", webhookID))
+						summary.ok(fmt.Sprintf("Detached webhook: %s", webhookID))
 					}
 				} else {
 					fmt.Printf("⚠️ Webhook %s is not attached to this agent\n", webhookID)
+					summary.warn("Detach webhook: "+webhookID, fmt.Errorf("not attached to this agent"))
 				}
 			}
 
@@ -1225,14 +1229,15 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					webhook, err := createWebhook(cmd.Context(), client, uuid, dest, method, prompt)
 					if err != nil {
 						fmt.Printf("⚠️ Failed to create webhook for %s: %v\n", dest, err)
+						summary.fail("Create webhook for "+dest, err)
 					} else {
 						fmt.Printf("✅ Created %s webhook (ID: %s)\n", method, webhook.ID)
 						if webhook.WebhookURL != "" {
 							fmt.Printf("   📋 Webhook URL: %s\n", style.HighlightStyle.Render(webhook.WebhookURL))
-							createdResources = append(createdResources, fmt.Sprintf("Created %s webhook: %s (URL: %s)",
+							summary.ok(fmt.Sprintf("Created %s webhook: %s (URL: %s)",
 								method, webhook.ID, webhook.WebhookURL))
 						} else {
-							createdResources = append(createdResources, fmt.Sprintf("Created %s webhook: %s",
+							summary.ok(fmt.Sprintf("Created %s webhook: %s",
 								method, webhook.ID))
 						}
 					}
@@ -1243,15 +1248,16 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					webhook, err := createWebhook(cmd.Context(), client, uuid, "", "http", webhookPrompt)
 					if err != nil {
 						fmt.Printf("⚠️ Failed to create HTTP webhook: %v\n", err)
+						summary.fail("Create HTTP webhook", err)
 					} else {
 						fmt.Printf("✅ Created HTTP webhook (ID: %s)\n", webhook.ID)
 						if webhook.WebhookURL != "" {
 							fmt.Printf("   📋 Webhook URL: %s\n", style.HighlightStyle.Render(webhook.WebhookURL))
 							fmt.Printf("   📊 Use the webhooks API or web interface to track webhook activity\n")
-							createdResources = append(createdResources, fmt.Sprintf("Created HTTP webhook: %s (URL: %s)",
+							summary.ok(fmt.Sprintf("Created HTTP webhook: %s (URL: %s)",
 								webhook.ID, webhook.WebhookURL))
 						} else {
-							createdResources = append(createdResources, fmt.Sprintf("Created HTTP webhook: %s",
+							summary.ok(fmt.Sprintf("Created HTTP webhook: %s",
 								webhook.ID))
 						}
 					}
@@ -1266,29 +1272,9 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					return fmt.Errorf("failed to read webhook file: %w", err)
 				}
 
-				// Parse the webhooks (format depends on file extension)
-				var webhooks []kubiya.Webhook
-				ext := strings.ToLower(filepath.Ext(webhookFile))
-				if ext == ".json" {
-					if err := json.Unmarshal(webhookData, &webhooks); err != nil {
-						// Try single webhook parse if array fails
-						var singleWebhook kubiya.Webhook
-						if err := json.Unmarshal(webhookData, &singleWebhook); err != nil {
-							return fmt.Errorf("failed to parse webhook JSON: %w", err)
-						}
-						webhooks = []kubiya.Webhook{singleWebhook}
-					}
-				} else if ext == ".yaml" || ext == ".yml" {
-					if err := yaml.Unmarshal(webhookData, &webhooks); err != nil {
-						// Try single webhook parse if array fails
-						var singleWebhook kubiya.Webhook
-						if err := yaml.Unmarshal(webhookData, &singleWebhook); err != nil {
-							return fmt.Errorf("failed to parse webhook YAML: %w", err)
-						}
-						webhooks = []kubiya.Webhook{singleWebhook}
-					}
-				} else {
-					return fmt.Errorf("unsupported webhook file format: %s (use .json, .yaml, or .yml)", ext)
+				webhooks, err := parseWebhooks(webhookData)
+				if err != nil {
+					return err
 				}
 
 				// Create each webhook
@@ -1311,6 +1297,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 					createdWebhook, err := client.CreateWebhook(cmd.Context(), webhook)
 					if err != nil {
 						fmt.Printf("⚠️ Failed to create webhook %d from file: %v\n", i+1, err)
+						summary.fail(fmt.Sprintf("Create webhook %d from file", i+1), err)
 						continue
 					}
 
@@ -1319,21 +1306,19 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 
 					if createdWebhook.WebhookURL != "" {
 						fmt.Printf("   📋 Webhook URL: %s\n", style.HighlightStyle.Render(createdWebhook.WebhookURL))
-						createdResources = append(createdResources, fmt.Sprintf("Created %s webhook: %s (URL: %s)",
+						summary.ok(fmt.Sprintf("Created %s webhook: %s (URL: %s)",
 							webhook.Communication.Method, createdWebhook.ID, createdWebhook.WebhookURL))
 					} else {
-						createdResources = append(createdResources, fmt.Sprintf("Created %s webhook: %s",
+						summary.ok(fmt.Sprintf("Created %s webhook: %s",
 							webhook.Communication.Method, createdWebhook.ID))
 					}
 				}
 			}
 
-			// Show created resources summary if any
-			if len(createdResources) > 0 {
-				fmt.Printf("%s\n", style.SubtitleStyle.Render("Created/Updated Resources"))
-				for _, resource := range createdResources {
-					fmt.Printf("• %s\n", resource)
-				}
+			// Show the outcome of the webhook changes together
+			if summary.total() > 0 {
+				fmt.Printf("%s\n", style.SubtitleStyle.Render("Summary"))
+				summary.print(os.Stdout)
 				fmt.Println()
 			}
 
@@ -1344,13 +1329,14 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 			fmt.Printf("• List agents: %s\n",
 				style.CommandStyle.Render("kubiya agent list"))
 
-			return nil
+			return summary.err()
 		},
 	}
 
 	// Edit mode flags
 	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Use interactive form")
 	cmd.Flags().BoolVarP(&editor, "editor", "e", false, "Use JSON editor")
+	cmd.Flags().StringVar(&editorFormat, "format", "json", "Format to edit the agent in with --editor (json|yaml)")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
 
@@ -1366,6 +1352,7 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 	cmd.Flags().StringArrayVar(&addSources, "add-source", []string{}, "Add source UUID (can be specified multiple times)")
 	cmd.Flags().StringArrayVar(&addSecrets, "add-secret", []string{}, "Add secret name (can be specified multiple times)")
 	cmd.Flags().StringArrayVar(&addEnvVars, "add-env", []string{}, "Add environment variable in KEY=VALUE format (can be specified multiple times)")
+	cmd.Flags().StringVar(&envFile, "env-file", "", "Add environment variables from a .env file (--add-env values take precedence)")
 	cmd.Flags().StringArrayVar(&addIntegrations, "add-integration", []string{}, "Add integration (can be specified multiple times)")
 	cmd.Flags().StringArrayVar(&addTools, "add-tool", []string{}, "Add tool UUID (can be specified multiple times)")
 
@@ -1393,7 +1380,8 @@ func newEditAgentCommand(cfg *config.Config) *cobra.Command {
 	cmd.Flags().StringArrayVar(&addAllowedGroups, "add-allowed-group", []string{}, "Add allowed group UUID (can be specified multiple times)")
 	cmd.Flags().StringArrayVar(&removeAllowedGroups, "remove-allowed-group", []string{}, "Remove allowed group UUID (can be specified multiple times)")
 
-	return cmd
+	rules := append(agentWebhookFlagRules(), flagRule{flag: "format", needs: []string{"editor"}})
+	return withFlagRules(cmd, rules)
 }
 
 // Check if any command-line changes were specified
@@ -1545,6 +1533,20 @@ func generateAgentDiff(original, updated *kubiya.Agent) []string {
 		changes = append(changes, fmt.Sprintf("Removed %d tool(s)", len(removedTools)))
 	}
 
+	// Compare starters by name
+	originalStarters, _ := original.GetStarters()
+	updatedStarters, _ := updated.GetStarters()
+	addedStarters, removedStarters := diffStringSlices(starterNames(originalStarters), starterNames(updatedStarters))
+	if len(addedStarters) > 0 {
+		changes = append(changes, fmt.Sprintf("Added %d starter(s)", len(addedStarters)))
+	}
+	if len(removedStarters) > 0 {
+		changes = append(changes, fmt.Sprintf("Removed %d starter(s)", len(removedStarters)))
+	}
+	if len(addedStarters) == 0 && len(removedStarters) == 0 && !reflect.DeepEqual(originalStarters, updatedStarters) {
+		changes = append(changes, "Starters updated")
+	}
+
 	return changes
 }
 
@@ -1833,7 +1835,7 @@ func newListAgentsCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agents)
+				return printJSONList(agents)
 			case "text":
 				// Count active agents
 				activeCount := 0
@@ -2155,7 +2157,7 @@ func newGetAgentCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agent)
+				return printJSON(agent)
 
 			case "text":
 				fmt.Printf("\n%s\n\n", style.TitleStyle.Render(fmt.Sprintf(" 👤 Agent: %s ", agent.Name)))
@@ -2460,30 +2462,9 @@ func readWebhooksFromFile(filepath string) ([]kubiya.Webhook, error) {
 		return nil, fmt.Errorf("failed to read webhook file: %w", err)
 	}
 
-	// Parse the webhooks (format depends on file extension)
-	var webhooks []kubiya.Webhook
-	// get the extension from the file string (after the last dot)
-	ext := "yaml"
-	if ext == ".json" {
-		if err := json.Unmarshal(webhookData, &webhooks); err != nil {
-			// Try single webhook parse if array fails
-			var singleWebhook kubiya.Webhook
-			if err := json.Unmarshal(webhookData, &singleWebhook); err != nil {
-				return nil, fmt.Errorf("failed to parse webhook JSON: %w", err)
-			}
-			webhooks = []kubiya.Webhook{singleWebhook}
-		}
-	} else if ext == ".yaml" || ext == ".yml" {
-		if err := yaml.Unmarshal(webhookData, &webhooks); err != nil {
-			// Try single webhook parse if array fails
-			var singleWebhook kubiya.Webhook
-			if err := yaml.Unmarshal(webhookData, &singleWebhook); err != nil {
-				return nil, fmt.Errorf("failed to parse webhook YAML: %w", err)
-			}
-			webhooks = []kubiya.Webhook{singleWebhook}
-		}
-	} else {
-		return nil, fmt.Errorf("unsupported webhook file format: %s (use .json, .yaml, or .yml)", ext)
+	webhooks, err := parseWebhooks(webhookData)
+	if err != nil {
+		return nil, err
 	}
 
 	// Set defaults for each webhook
@@ -2502,6 +2483,20 @@ func readWebhooksFromFile(filepath string) ([]kubiya.Webhook, error) {
 	return webhooks, nil
 }
 
+// parseWebhooks parses a JSON or YAML document holding a list of webhooks
+// or a single one
+func parseWebhooks(data []byte) ([]kubiya.Webhook, error) {
+	var webhooks []kubiya.Webhook
+	if err := docload.Unmarshal(data, &webhooks); err != nil {
+		var singleWebhook kubiya.Webhook
+		if singleErr := docload.Unmarshal(data, &singleWebhook); singleErr != nil {
+			return nil, fmt.Errorf("failed to parse webhook file: %w", err)
+		}
+		webhooks = []kubiya.Webhook{singleWebhook}
+	}
+	return webhooks, nil
+}
+
 // newAgentToolsCommand creates the agent tools management command
 func newAgentToolsCommand(cfg *config.Config) *cobra.Command {
 	cmd := &cobra.Command{
@@ -2540,7 +2535,7 @@ func newAgentToolsListCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agent.Tools)
+				return printJSONList(agent.Tools)
 			case "yaml":
 				return yaml.NewEncoder(os.Stdout).Encode(agent.Tools)
 			default:
@@ -2584,6 +2579,8 @@ func newAgentToolAddCommand(cfg *config.Config) *cobra.Command {
 		toolVolumes     []string
 		toolSecrets     []string
 		toolEnvVars     []string
+		toolServices    []string
+		waitForServices bool
 		yes             bool
 		outputFormat    string
 	)
@@ -2599,7 +2596,12 @@ For inline tool creation, you can specify:
   --volume: Volume mounts in format "path:name"
   --secret: Required secrets
   --env: Environment variables in KEY=VALUE format
-  --arg: Tool arguments with name:description format`,
+  --arg: Tool arguments with name:description format
+  --service: Sidecar service image, with an optional =PORT (e.g. postgres:15)
+
+With --wait-for-services the tool waits for its services to accept
+connections before running; put {{wait_for_services}} in the content to
+choose where it waits instead.`,
 		Example: `  # Add existing tool
   kubiya agent tool add abc-123 python_script_runner
 
@@ -2611,7 +2613,14 @@ For inline tool creation, you can specify:
     --volume "/workspace:workspace" \
     --secret "API_KEY" \
     --env "TIMEOUT=30" \
-    --arg "input:Input text to process"`,
+    --arg "input:Input text to process"
+
+  # Create a tool with a database it waits for
+  kubiya agent tool add abc-123 migrate_db \
+    --image migrate/migrate \
+    --content "migrate -path /migrations -database postgres://postgres@postgres:5432/app up" \
+    --service postgres:15 --wait-for-services \
+    --env "POSTGRES_HOST_AUTH_METHOD=trust"`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentUUID := args[0]
@@ -2636,9 +2645,19 @@ For inline tool creation, you can specify:
 
 			// Check if it's an inline tool creation or adding existing tool
 			isInlineTool := toolDescription != "" || toolContent != "" || toolImage != "" ||
-				len(toolVolumes) > 0 || len(toolSecrets) > 0 || len(toolEnvVars) > 0 || len(toolArgs) > 0
+				len(toolVolumes) > 0 || len(toolSecrets) > 0 || len(toolEnvVars) > 0 || len(toolArgs) > 0 ||
+				len(toolServices) > 0
 
 			if isInlineTool {
+				services, err := parseToolServices(toolServices)
+				if err != nil {
+					return err
+				}
+				toolContent, err = applyServiceWait(toolContent, services, waitForServices)
+				if err != nil {
+					return err
+				}
+
 				// Create inline tool structure
 				toolDef := map[string]interface{}{
 					"name":        toolName,
@@ -2677,6 +2696,11 @@ For inline tool creation, you can specify:
 					toolDef["with_volumes"] = volumes
 				}
 
+				// Add sidecar services if provided
+				if len(services) > 0 {
+					toolDef["with_services"] = serviceImages(services)
+				}
+
 				// Add environment variables if provided
 				if len(toolEnvVars) > 0 {
 					env := map[string]string{}
@@ -2779,8 +2803,10 @@ For inline tool creation, you can specify:
 	cmd.Flags().StringArrayVar(&toolVolumes, "volume", []string{}, "Volume mount in format 'path:name'")
 	cmd.Flags().StringArrayVar(&toolSecrets, "secret", []string{}, "Required secret name")
 	cmd.Flags().StringArrayVar(&toolEnvVars, "env", []string{}, "Environment variable in KEY=VALUE format")
+	cmd.Flags().StringArrayVar(&toolServices, "service", []string{}, "Sidecar service image, with an optional =PORT to wait for (e.g. postgres:15)")
+	cmd.Flags().BoolVar(&waitForServices, "wait-for-services", false, "Wait for the services to accept connections before running the tool")
 
-	return cmd
+	return withFlagRules(cmd, []flagRule{{flag: "wait-for-services", needs: []string{"service"}}})
 }
 
 // newAgentPromptCommand creates the command group for managing agent AI instructions/system prompts
@@ -2794,11 +2820,14 @@ Supports viewing, editing, replacing, and appending to system prompts.`,
 	}
 
 	cmd.AddCommand(
-		newAgentPromptGetCommand(cfg),
+		paged(newAgentPromptGetCommand(cfg)),
 		newAgentPromptSetCommand(cfg),
 		newAgentPromptAppendCommand(cfg),
 		newAgentPromptEditCommand(cfg),
 		newAgentPromptClearCommand(cfg),
+		paged(newAgentPromptHistoryCommand(cfg)),
+		newAgentPromptDiffCommand(cfg),
+		newAgentPromptRestoreCommand(cfg),
 	)
 
 	return cmd
@@ -2820,11 +2849,9 @@ func newAgentPromptGetCommand(cfg *config.Config) *cobra.Command {
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentUUID := args[0]
-			client := kubiya.NewClient(cfg)
-
-			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			agent, err := loadAgentPrompt(cmd.Context(), cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			switch outputFormat {
@@ -2836,7 +2863,7 @@ func newAgentPromptGetCommand(cfg *config.Config) *cobra.Command {
 					"has_instructions":   agent.AIInstructions != "",
 					"instruction_length": len(agent.AIInstructions),
 				}
-				return json.NewEncoder(os.Stdout).Encode(result)
+				return printJSON(result)
 			default:
 				fmt.Printf("%s AI Instructions for Agent: %s\n\n",
 					style.TitleStyle.Render("💭"),
@@ -2904,13 +2931,12 @@ This will completely replace any existing instructions.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentUUID := args[0]
-			client := kubiya.NewClient(cfg)
 			ctx := cmd.Context()
 
 			// Get current agent
-			agent, err := client.GetAgent(ctx, agentUUID)
+			agent, err := loadAgentPrompt(ctx, cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			var newInstructions string
@@ -2925,7 +2951,7 @@ This will completely replace any existing instructions.`,
 				}
 				newInstructions = string(data)
 			} else if url != "" {
-				resp, err := http.Get(url)
+				resp, err := httpclient.Get(url)
 				if err != nil {
 					return fmt.Errorf("failed to fetch from URL: %w", err)
 				}
@@ -2980,33 +3006,10 @@ This will completely replace any existing instructions.`,
 			}
 
 			// Update agent
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": agent.Environment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               agent.Runners,
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       newInstructions,
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
-			_, err = client.UpdateAgentRaw(ctx, agentUUID, updateData)
-			if err != nil {
+			if err := agent.save(ctx, newInstructions); err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
+			snapshotAgentPrompt(agentUUID, agent.AIInstructions, "set")
 
 			fmt.Printf("%s AI instructions updated successfully\n",
 				style.SuccessStyle.Render("✅"))
@@ -3054,13 +3057,12 @@ This will add new content to the end of existing instructions.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentUUID := args[0]
-			client := kubiya.NewClient(cfg)
 			ctx := cmd.Context()
 
 			// Get current agent
-			agent, err := client.GetAgent(ctx, agentUUID)
+			agent, err := loadAgentPrompt(ctx, cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			var newContent string
@@ -3075,7 +3077,7 @@ This will add new content to the end of existing instructions.`,
 				}
 				newContent = string(data)
 			} else if url != "" {
-				resp, err := http.Get(url)
+				resp, err := httpclient.Get(url)
 				if err != nil {
 					return fmt.Errorf("failed to fetch from URL: %w", err)
 				}
@@ -3142,33 +3144,10 @@ This will add new content to the end of existing instructions.`,
 			}
 
 			// Update agent
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": agent.Environment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               agent.Runners,
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       finalInstructions,
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
-			_, err = client.UpdateAgentRaw(ctx, agentUUID, updateData)
-			if err != nil {
+			if err := agent.save(ctx, finalInstructions); err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
+			snapshotAgentPrompt(agentUUID, agent.AIInstructions, "append")
 
 			fmt.Printf("%s AI instructions updated successfully\n",
 				style.SuccessStyle.Render("✅"))
@@ -3206,13 +3185,12 @@ Opens the current instructions in $EDITOR (or nano as fallback).`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentUUID := args[0]
-			client := kubiya.NewClient(cfg)
 			ctx := cmd.Context()
 
 			// Get current agent
-			agent, err := client.GetAgent(ctx, agentUUID)
+			agent, err := loadAgentPrompt(ctx, cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			// Create temp file with current instructions
@@ -3270,33 +3248,10 @@ Opens the current instructions in $EDITOR (or nano as fallback).`,
 			}
 
 			// Update agent
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": agent.Environment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               agent.Runners,
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       newInstructions,
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
-			_, err = client.UpdateAgentRaw(ctx, agentUUID, updateData)
-			if err != nil {
+			if err := agent.save(ctx, newInstructions); err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
+			snapshotAgentPrompt(agentUUID, agent.AIInstructions, "edit")
 
 			fmt.Printf("%s AI instructions updated successfully\n",
 				style.SuccessStyle.Render("✅"))
@@ -3329,13 +3284,12 @@ func newAgentPromptClearCommand(cfg *config.Config) *cobra.Command {
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentUUID := args[0]
-			client := kubiya.NewClient(cfg)
 			ctx := cmd.Context()
 
 			// Get current agent
-			agent, err := client.GetAgent(ctx, agentUUID)
+			agent, err := loadAgentPrompt(ctx, cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			if agent.AIInstructions == "" {
@@ -3357,33 +3311,10 @@ func newAgentPromptClearCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			// Update agent
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": agent.Environment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               agent.Runners,
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       "",
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
-			_, err = client.UpdateAgentRaw(ctx, agentUUID, updateData)
-			if err != nil {
+			if err := agent.save(ctx, ""); err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
+			snapshotAgentPrompt(agentUUID, agent.AIInstructions, "clear")
 
 			fmt.Printf("%s AI instructions cleared successfully\n",
 				style.SuccessStyle.Render("✅"))
@@ -3397,4 +3328,3 @@ func newAgentPromptClearCommand(cfg *config.Config) *cobra.Command {
 
 	return cmd
 }
-
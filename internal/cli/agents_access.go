@@ -1,9 +1,7 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/kubiyabot/cli/internal/config"
 	"github.com/kubiyabot/cli/internal/kubiya"
@@ -60,7 +58,7 @@ func newAgentAccessShowCommand(cfg *config.Config) *cobra.Command {
 					"allowed_users":  agent.AllowedUsers,
 					"allowed_groups": agent.AllowedGroups,
 				}
-				return json.NewEncoder(os.Stdout).Encode(accessInfo)
+				return printJSON(accessInfo)
 			default:
 				fmt.Printf("%s Access Control: %s\n\n",
 					style.TitleStyle.Render("🔐"),
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -59,7 +58,7 @@ func newAgentIntegrationsListCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agent.Integrations)
+				return printJSONList(agent.Integrations)
 			case "yaml":
 				return yaml.NewEncoder(os.Stdout).Encode(agent.Integrations)
 			default:
@@ -383,6 +382,8 @@ func newAgentEnvCommand(cfg *config.Config) *cobra.Command {
 		newAgentEnvListCommand(cfg),
 		newAgentEnvSetCommand(cfg),
 		newAgentEnvUnsetCommand(cfg),
+		newAgentEnvExportCommand(cfg),
+		newAgentEnvOverrideCommand(),
 	)
 
 	return cmd
@@ -404,17 +405,14 @@ func newAgentEnvListCommand(cfg *config.Config) *cobra.Command {
   kubiya agent env list abc-123 --output json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			agentUUID := args[0]
-			client := kubiya.NewClient(cfg)
-
-			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			agent, err := loadAgentEnv(cmd, cfg, args[0])
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agent.Environment)
+				return printJSON(agent.Environment)
 			case "yaml":
 				return yaml.NewEncoder(os.Stdout).Encode(agent.Environment)
 			default:
@@ -494,12 +492,10 @@ func newAgentEnvSetCommand(cfg *config.Config) *cobra.Command {
 				return fmt.Errorf("at least one environment variable must be specified using --env KEY=VALUE")
 			}
 
-			client := kubiya.NewClient(cfg)
-			
 			// Get current agent
-			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			agent, err := loadAgentEnv(cmd, cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			// Parse environment variables
@@ -564,45 +560,20 @@ func newAgentEnvSetCommand(cfg *config.Config) *cobra.Command {
 				updatedEnvironment[k] = v
 			}
 
-			// Create update payload
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": updatedEnvironment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               agent.Runners,
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       agent.AIInstructions,
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
 			// Update the agent
-			result, err := client.UpdateAgentRaw(cmd.Context(), agentUUID, updateData)
-			if err != nil {
+			if err := agent.save(cmd.Context(), updatedEnvironment); err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
 
 			fmt.Printf("%s Set %d environment variable(s) for agent '%s'\n\n",
 				style.SuccessStyle.Render("✅"),
 				len(newEnvVars),
-				style.HighlightStyle.Render(result.Name))
+				style.HighlightStyle.Render(agent.Name))
 
 			// Show updated environment variables count
 			fmt.Printf("%s Agent now has %d environment variables\n",
 				style.InfoStyle.Render("📊"),
-				len(result.Environment))
+				len(updatedEnvironment))
 
 			return nil
 		},
@@ -640,12 +611,10 @@ func newAgentEnvUnsetCommand(cfg *config.Config) *cobra.Command {
 			agentUUID := args[0]
 			keysToRemove := args[1:]
 
-			client := kubiya.NewClient(cfg)
-			
 			// Get current agent
-			agent, err := client.GetAgent(cmd.Context(), agentUUID)
+			agent, err := loadAgentEnv(cmd, cfg, agentUUID)
 			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
+				return err
 			}
 
 			// Check which keys exist
@@ -706,45 +675,20 @@ func newAgentEnvUnsetCommand(cfg *config.Config) *cobra.Command {
 				}
 			}
 
-			// Create update payload
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": updatedEnvironment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               agent.Runners,
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       agent.AIInstructions,
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
 			// Update the agent
-			result, err := client.UpdateAgentRaw(cmd.Context(), agentUUID, updateData)
-			if err != nil {
+			if err := agent.save(cmd.Context(), updatedEnvironment); err != nil {
 				return fmt.Errorf("failed to update agent: %w", err)
 			}
 
 			fmt.Printf("%s Removed %d environment variable(s) from agent '%s'\n\n",
 				style.SuccessStyle.Render("✅"),
 				len(validKeys),
-				style.HighlightStyle.Render(result.Name))
+				style.HighlightStyle.Render(agent.Name))
 
 			// Show updated environment variables count
 			fmt.Printf("%s Agent now has %d environment variables\n",
 				style.InfoStyle.Render("📊"),
-				len(result.Environment))
+				len(updatedEnvironment))
 
 			return nil
 		},
@@ -800,7 +744,7 @@ func newAgentSecretsListCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agent.Secrets)
+				return printJSONList(agent.Secrets)
 			case "yaml":
 				return yaml.NewEncoder(os.Stdout).Encode(agent.Secrets)
 			default:
@@ -1240,5 +1184,7 @@ Supported models:
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
 
+	cmd.AddCommand(newAgentModelMigrateCommand(cfg))
+
 	return cmd
 }
\ No newline at end of file
@@ -1,15 +1,33 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/kubiyabot/cli/internal/config"
 	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
 	"github.com/kubiyabot/cli/internal/style"
+	"github.com/kubiyabot/cli/internal/suggest"
 	"github.com/spf13/cobra"
 )
 
+// virtualRunners are the runners managed by Kubiya, which are not listed by
+// kubiya runner list but can always be set on an agent
+var virtualRunners = []string{"kubiya-hosted", "kubiyamanaged"}
+
+// agentRunnerUpdate is the result of setting the runners of an agent
+type agentRunnerUpdate struct {
+	Agent     string   `json:"agent"`
+	UUID      string   `json:"uuid"`
+	Before    []string `json:"before"`
+	After     []string `json:"after"`
+	Unhealthy []string `json:"unhealthy,omitempty"`
+	Changed   bool     `json:"changed"`
+}
+
 // newAgentRunnerCommand creates the command to manage agent runners
 func newAgentRunnerCommand(cfg *config.Config) *cobra.Command {
 	var (
@@ -25,95 +43,229 @@ func newAgentRunnerCommand(cfg *config.Config) *cobra.Command {
 		Example: `  # Set runner to gke-poc-kubiya (common for working agents)
   kubiya agent runner abc-123 gke-poc-kubiya
 
-  # Set runner to gke-integration
-  kubiya agent runner abc-123 gke-integration
-
-  # Set runner to kubiya-hosted  
+  # Set runner to kubiya-hosted
   kubiya agent runner abc-123 kubiya-hosted
 
+  # Set several runners
+  kubiya agent runner set abc-123 gke-poc-kubiya,gke-integration
+
   # Set with confirmation skip
   kubiya agent runner abc-123 gke-poc-kubiya -y`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			agentUUID := args[0]
-			newRunner := args[1]
-
-			client := kubiya.NewClient(cfg)
-			
-			// Get current agent
-			agent, err := client.GetAgent(cmd.Context(), agentUUID)
-			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
-			}
-
-			// Check if runner is already set
-			currentRunners := strings.Join(agent.Runners, ", ")
-			if len(agent.Runners) == 1 && agent.Runners[0] == newRunner {
-				fmt.Printf("%s Agent '%s' is already using runner: %s\n",
-					style.InfoStyle.Render("ℹ️"),
-					style.HighlightStyle.Render(agent.Name),
-					style.HighlightStyle.Render(newRunner))
-				return nil
-			}
-
-			// Show what will be changed
-			fmt.Printf("%s Updating runner for agent: %s\n\n", 
-				style.InfoStyle.Render("🏃"), 
-				style.HighlightStyle.Render(agent.Name))
-			
-			fmt.Printf("  Current: %s\n", 
-				style.DimStyle.Render(currentRunners))
-			fmt.Printf("  New:     %s\n\n", 
-				style.SuccessStyle.Render(newRunner))
-
-			// Confirm change
-			if !yes {
-				if !confirmYesNo(fmt.Sprintf("Update runner for agent '%s' to '%s'?", agent.Name, newRunner)) {
-					return fmt.Errorf("runner update cancelled")
-				}
-			}
-
-			// Create update payload
-			updateData := map[string]interface{}{
-				"name":                  agent.Name,
-				"description":           agent.Description,
-				"instruction_type":      agent.InstructionType,
-				"llm_model":             agent.LLMModel,
-				"sources":               agent.Sources,
-				"environment_variables": agent.Environment,
-				"secrets":               agent.Secrets,
-				"allowed_groups":        agent.AllowedGroups,
-				"allowed_users":         agent.AllowedUsers,
-				"owners":                agent.Owners,
-				"runners":               []string{newRunner},
-				"is_debug_mode":         agent.IsDebugMode,
-				"ai_instructions":       agent.AIInstructions,
-				"image":                 agent.Image,
-				"managed_by":            agent.ManagedBy,
-				"integrations":          agent.Integrations,
-				"links":                 agent.Links,
-				"tools":                 agent.Tools,
-				"tasks":                 agent.Tasks,
-				"tags":                  agent.Tags,
-			}
-
-			// Update the agent
-			result, err := client.UpdateAgentRaw(cmd.Context(), agentUUID, updateData)
-			if err != nil {
-				return fmt.Errorf("failed to update agent: %w", err)
-			}
-
-			fmt.Printf("%s Updated runner for agent '%s' to: %s\n",
-				style.SuccessStyle.Render("✅"),
-				style.HighlightStyle.Render(result.Name),
-				style.HighlightStyle.Render(newRunner))
-
-			return nil
+			return setAgentRunners(cmd, kubiya.NewClient(cfg), args[0], args[1], yes, outputFormat)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
 
+	cmd.AddCommand(newAgentRunnerSetCommand(cfg))
+
 	return cmd
-}
\ No newline at end of file
+}
+
+// newAgentRunnerSetCommand sets the runners of an agent
+func newAgentRunnerSetCommand(cfg *config.Config) *cobra.Command {
+	var (
+		yes          bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set [agent-uuid] [runner,...]",
+		Short: "🏃 Set the runners of an agent",
+		Long: `Set the runners of an agent, checked against the runners of the
+organization. Unknown runners are rejected, and unhealthy ones are reported
+before the agent is updated. The runners managed by Kubiya, kubiya-hosted and
+kubiyamanaged, are always accepted.`,
+		Example: `  # Run the agent on two runners
+  kubiya agent runner set abc-123 gke-poc-kubiya,gke-integration
+
+  # Without confirmation
+  kubiya agent runner set abc-123 gke-poc-kubiya -y`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setAgentRunners(cmd, kubiya.NewClient(cfg), args[0], args[1], yes, outputFormat)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+func setAgentRunners(cmd *cobra.Command, client *kubiya.Client, agentUUID, list string, yes bool, outputFormat string) error {
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+	}
+	runners := parseRunnerList(list)
+	if len(runners) == 0 {
+		return fmt.Errorf("no runner given")
+	}
+
+	unhealthy, err := validateAgentRunners(cmd.Context(), client, runners)
+	if err != nil {
+		return err
+	}
+
+	// Get current agent
+	agent, err := client.GetAgent(cmd.Context(), agentUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	update := agentRunnerUpdate{
+		Agent:     agent.Name,
+		UUID:      agentUUID,
+		Before:    agent.Runners,
+		After:     runners,
+		Unhealthy: unhealthy,
+	}
+	out := cmd.OutOrStdout()
+	text := outputFormat == "text"
+
+	// Check if runners are already set
+	if strings.Join(agent.Runners, ",") == strings.Join(runners, ",") {
+		if !text {
+			return output.EncodeJSON(out, update)
+		}
+		fmt.Fprintf(out, "%s Agent '%s' is already using runners: %s\n",
+			style.InfoStyle.Render("ℹ️"),
+			style.HighlightStyle.Render(agent.Name),
+			style.HighlightStyle.Render(strings.Join(runners, ", ")))
+		return nil
+	}
+
+	if text {
+		// Show what will be changed
+		fmt.Fprintf(out, "%s Updating runners for agent: %s\n\n",
+			style.InfoStyle.Render("🏃"),
+			style.HighlightStyle.Render(agent.Name))
+
+		fmt.Fprintf(out, "  Current: %s\n",
+			style.DimStyle.Render(strings.Join(agent.Runners, ", ")))
+		fmt.Fprintf(out, "  New:     %s\n\n",
+			style.SuccessStyle.Render(strings.Join(runners, ", ")))
+
+		for _, name := range unhealthy {
+			fmt.Fprintf(out, "%s Runner %s is not healthy, the agent cannot run there until it recovers\n",
+				style.WarningStyle.Render("⚠️"), style.HighlightStyle.Render(name))
+		}
+		if len(unhealthy) > 0 {
+			fmt.Fprintln(out)
+		}
+	}
+
+	// Confirm change
+	if !yes {
+		if !confirmYesNo(fmt.Sprintf("Update runners for agent '%s' to '%s'?", agent.Name, strings.Join(runners, ", "))) {
+			return fmt.Errorf("runner update cancelled")
+		}
+	}
+
+	// Create update payload
+	updateData := map[string]interface{}{
+		"name":                  agent.Name,
+		"description":           agent.Description,
+		"instruction_type":      agent.InstructionType,
+		"llm_model":             agent.LLMModel,
+		"sources":               agent.Sources,
+		"environment_variables": agent.Environment,
+		"secrets":               agent.Secrets,
+		"allowed_groups":        agent.AllowedGroups,
+		"allowed_users":         agent.AllowedUsers,
+		"owners":                agent.Owners,
+		"runners":               runners,
+		"is_debug_mode":         agent.IsDebugMode,
+		"ai_instructions":       agent.AIInstructions,
+		"image":                 agent.Image,
+		"managed_by":            agent.ManagedBy,
+		"integrations":          agent.Integrations,
+		"links":                 agent.Links,
+		"tools":                 agent.Tools,
+		"tasks":                 agent.Tasks,
+		"starters":              agent.Starters,
+		"tags":                  agent.Tags,
+	}
+
+	// Update the agent
+	result, err := client.UpdateAgentRaw(cmd.Context(), agentUUID, updateData)
+	if err != nil {
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	update.Changed = true
+	if !text {
+		return output.EncodeJSON(out, update)
+	}
+	fmt.Fprintf(out, "%s Updated runners for agent '%s' to: %s\n",
+		style.SuccessStyle.Render("✅"),
+		style.HighlightStyle.Render(result.Name),
+		style.HighlightStyle.Render(strings.Join(runners, ", ")))
+
+	return nil
+}
+
+// parseRunnerList splits a comma separated list of runners, dropping blanks
+// and duplicates
+func parseRunnerList(list string) []string {
+	var runners []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !contains(runners, name) {
+			runners = append(runners, name)
+		}
+	}
+	return runners
+}
+
+// validateAgentRunners checks that the runners exist, and returns those
+// that are not healthy. The virtual runners are accepted as they are.
+func validateAgentRunners(ctx context.Context, client runnerLister, names []string) ([]string, error) {
+	live, err := client.ListRunners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runners: %w", err)
+	}
+	byName := make(map[string]kubiya.Runner, len(live))
+	for _, r := range live {
+		byName[r.Name] = r
+	}
+
+	var unknown, unhealthy []string
+	for _, name := range names {
+		runner, ok := byName[name]
+		switch {
+		case !ok && contains(virtualRunners, name):
+		case !ok:
+			unknown = append(unknown, name)
+		case !isRunnerHealthy(runner):
+			unhealthy = append(unhealthy, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return unhealthy, nil
+	}
+
+	problems := make([]string, 0, len(unknown))
+	for _, name := range unknown {
+		if suggestion := closestRunner(name, live); suggestion != "" {
+			problems = append(problems, fmt.Sprintf("%s (did you mean %s?)", name, suggestion))
+		} else {
+			problems = append(problems, name)
+		}
+	}
+	return nil, fmt.Errorf("unknown runner: %s; list runners with 'kubiya runner list'", strings.Join(problems, ", "))
+}
+
+// closestRunner returns the runner whose name is closest to name, when it
+// looks like a typo of it
+func closestRunner(name string, runners []kubiya.Runner) string {
+	candidates := append([]string(nil), virtualRunners...)
+	for _, r := range runners {
+		candidates = append(candidates, r.Name)
+	}
+	sort.Strings(candidates)
+	return suggest.Closest(name, candidates)
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRunnerList(t *testing.T) {
+	assert.Equal(t, []string{"gke-a", "gke-b"}, parseRunnerList(" gke-a, gke-b,,gke-a "))
+	assert.Empty(t, parseRunnerList(" , "))
+}
+
+func TestValidateAgentRunners(t *testing.T) {
+	lister := fakeRunnerLister{runners: []kubiya.Runner{
+		runnerWithStatus("gke-poc-kubiya", "healthy"),
+		runnerWithStatus("gke-integration", "unhealthy"),
+		runnerWithStatus("kubiya-hosted", ""),
+	}}
+
+	unhealthy, err := validateAgentRunners(context.Background(), lister, []string{"gke-poc-kubiya", "gke-integration", "kubiya-hosted"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gke-integration"}, unhealthy)
+
+	_, err = validateAgentRunners(context.Background(), lister, []string{"gke-poc-kubyia", "eks-prod"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gke-poc-kubyia (did you mean gke-poc-kubiya?)")
+	assert.Contains(t, err.Error(), "eks-prod")
+	assert.NotContains(t, err.Error(), "eks-prod (did you mean")
+}
+
+func TestValidateAgentRunnersVirtual(t *testing.T) {
+	lister := fakeRunnerLister{runners: []kubiya.Runner{runnerWithStatus("gke-poc-kubiya", "healthy")}}
+
+	unhealthy, err := validateAgentRunners(context.Background(), lister, []string{"kubiya-hosted", "kubiyamanaged"})
+	require.NoError(t, err)
+	assert.Empty(t, unhealthy)
+
+	_, err = validateAgentRunners(context.Background(), lister, []string{"kubiya-hosed"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kubiya-hosed (did you mean kubiya-hosted?)")
+}
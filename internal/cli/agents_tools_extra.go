@@ -1,16 +1,13 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/kubiyabot/cli/internal/config"
 	"github.com/kubiyabot/cli/internal/kubiya"
 	"github.com/kubiyabot/cli/internal/style"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 // newAgentToolRemoveCommand creates the command to remove tools from an agent
@@ -157,89 +154,3 @@ func newAgentToolRemoveCommand(cfg *config.Config) *cobra.Command {
 
 	return cmd
 }
-
-// newAgentToolDescribeCommand creates the command to describe/show tool details
-func newAgentToolDescribeCommand(cfg *config.Config) *cobra.Command {
-	var outputFormat string
-
-	cmd := &cobra.Command{
-		Use:     "describe [agent-uuid] [tool-name]",
-		Aliases: []string{"desc", "get", "show", "info"},
-		Short:   "📄 Describe a specific tool on an agent",
-		Long:    `Show detailed information about a specific tool attached to an agent.`,
-		Example: `  # Describe a tool
-  kubiya agent tool describe abc-123 python_script_runner
-
-  # Get tool info in JSON format
-  kubiya agent tool get abc-123 create_file --output json`,
-		Args: cobra.ExactArgs(2),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			agentUUID := args[0]
-			toolName := args[1]
-
-			client := kubiya.NewClient(cfg)
-			
-			// Get current agent
-			agent, err := client.GetAgent(cmd.Context(), agentUUID)
-			if err != nil {
-				return fmt.Errorf("failed to get agent: %w", err)
-			}
-
-			// Check if tool exists on agent
-			toolExists := false
-			for _, existingTool := range agent.Tools {
-				if existingTool == toolName {
-					toolExists = true
-					break
-				}
-			}
-
-			if !toolExists {
-				return fmt.Errorf("tool '%s' not found on agent '%s'", toolName, agent.Name)
-			}
-
-			switch outputFormat {
-			case "json":
-				toolInfo := map[string]interface{}{
-					"name":       toolName,
-					"agent_uuid": agentUUID,
-					"agent_name": agent.Name,
-					"attached":   true,
-				}
-				return json.NewEncoder(os.Stdout).Encode(toolInfo)
-			case "yaml":
-				toolInfo := map[string]interface{}{
-					"name":       toolName,
-					"agent_uuid": agentUUID,
-					"agent_name": agent.Name,
-					"attached":   true,
-				}
-				return yaml.NewEncoder(os.Stdout).Encode(toolInfo)
-			default:
-				fmt.Printf("%s Tool Details\n\n", style.TitleStyle.Render("📄"))
-				fmt.Printf("Tool Name: %s\n", style.HighlightStyle.Render(toolName))
-				fmt.Printf("Agent: %s (%s)\n", style.HighlightStyle.Render(agent.Name), agent.UUID)
-				fmt.Printf("Status: %s\n", style.SuccessStyle.Render("✅ Attached"))
-				
-				// Show position in tools list
-				for i, tool := range agent.Tools {
-					if tool == toolName {
-						fmt.Printf("Position: %s of %d\n", style.InfoStyle.Render(fmt.Sprintf("#%d", i+1)), len(agent.Tools))
-						break
-					}
-				}
-				
-				fmt.Printf("\n%s Tool Management Commands:\n", style.SubtitleStyle.Render("🛠️"))
-				fmt.Printf("  • Remove: %s\n", style.DimStyle.Render(fmt.Sprintf("kubiya agent tool remove %s %s", agentUUID, toolName)))
-				fmt.Printf("  • List all: %s\n", style.DimStyle.Render(fmt.Sprintf("kubiya agent tools list %s", agentUUID)))
-				
-			}
-
-			return nil
-		},
-	}
-
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json|yaml)")
-
-	return cmd
-}
\ No newline at end of file
@@ -14,7 +14,7 @@ import (
 
 // Control Plane V2 Agent Operations
 
-func createAgentV2(cfg *config.Config, name, description, model, systemPrompt, runtime string, teamID *string, environmentIDs []string) error {
+func createAgentV2(cfg *config.Config, name, description, model, systemPrompt, runtime string, teamID *string, environmentIDs []string, envVars map[string]string) error {
 	client, err := controlplane.New(cfg.APIKey, cfg.Debug)
 	if err != nil {
 		return fmt.Errorf("failed to create control plane client: %w", err)
@@ -43,6 +43,9 @@ func createAgentV2(cfg *config.Config, name, description, model, systemPrompt, r
 	if len(environmentIDs) > 0 {
 		req.EnvironmentIDs = environmentIDs
 	}
+	if len(envVars) > 0 {
+		req.ExecutionEnvironment = &entities.ExecutionEnvironment{EnvVars: envVars}
+	}
 
 	agent, err := client.CreateAgent(req)
 	if err != nil {
@@ -142,7 +142,7 @@ func newAuditListCommand(cfg *config.Config) *cobra.Command {
 			// Display items based on output format
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(items)
+				return printJSON(items)
 			case "yaml":
 				return fmt.Errorf("yaml output format not implemented yet")
 			default:
@@ -1072,7 +1072,7 @@ func newAuditDescribeCommand(cfg *config.Config) *cobra.Command {
 			// Display the item based on output format
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(targetItem)
+				return printJSON(targetItem)
 
 			default: // text
 				// Format the audit item details for display
@@ -1314,7 +1314,7 @@ func newAuditSearchCommand(cfg *config.Config) *cobra.Command {
 			// Display items based on output format
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(filteredItems)
+				return printJSON(filteredItems)
 
 			default: // text
 				// Print header
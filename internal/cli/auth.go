@@ -1,11 +1,16 @@
 package cli
 
 import (
+	stderrors "errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
 )
 
 // newAuthCommand creates a new auth command with subcommands
@@ -17,7 +22,7 @@ func newAuthCommand(cfg *config.Config) *cobra.Command {
 	}
 
 	// Add subcommands
-	cmd.AddCommand(newAuthStatusCommand(cfg))
+	cmd.AddCommand(newAuthStatusCommand(cfg), newAuthWhoamiCommand(cfg))
 
 	return cmd
 }
@@ -72,3 +77,98 @@ func runAuthStatus(cfg *config.Config) error {
 
 	return nil
 }
+
+// whoami is the output of auth whoami
+type whoami struct {
+	entities.Identity
+	KeySource    string     `json:"key_source,omitempty"`
+	KeyExpiresAt *time.Time `json:"key_expires_at,omitempty"`
+	ControlPlane string     `json:"control_plane"`
+	// Verified is false when the control plane could not confirm the
+	// identity and it was read from the API key instead
+	Verified bool `json:"verified"`
+}
+
+// newAuthWhoamiCommand creates the auth whoami subcommand
+func newAuthWhoamiCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show who the API key belongs to",
+		Long: `Show the user, organization and permissions of the API key, as seen by the
+control plane, and where the key was configured.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.ValidateAPIKey(); err != nil {
+				return err
+			}
+			client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+			if err != nil {
+				return err
+			}
+
+			info := whoami{KeySource: cfg.APIKeySource, ControlPlane: client.BaseURL}
+			if exp, ok := cfg.APIKeyExpiry(); ok {
+				info.KeyExpiresAt = &exp
+			}
+
+			identity, err := client.WhoAmI()
+			var statusErr *controlplane.StatusError
+			switch {
+			case err == nil:
+				info.Identity = *identity
+				info.Verified = true
+			case stderrors.As(err, &statusErr) && statusErr.StatusCode == 404:
+				// Older control planes have no whoami, fall back to the claims of the key
+				info.Email, info.OrganizationName = cfg.Email, cfg.Org
+			default:
+				if status := authErrorStatus(err); status != 0 {
+					return authFailure(cfg, client.BaseURL, status, err)
+				}
+				return fmt.Errorf("failed to look up the API key: %w", err)
+			}
+
+			if outputFormat == "json" {
+				return printJSON(info)
+			}
+			printWhoami(info)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+func printWhoami(info whoami) {
+	user := firstNonEmpty(info.Email, info.UserID, "unknown")
+	if info.Name != "" {
+		user += " (" + info.Name + ")"
+	}
+	fmt.Printf("User:           %s\n", user)
+
+	org := firstNonEmpty(info.OrganizationName, info.OrganizationID, "unknown")
+	if info.OrganizationName != "" && info.OrganizationID != "" {
+		org += " (" + info.OrganizationID + ")"
+	}
+	fmt.Printf("Organization:   %s\n", org)
+
+	if len(info.Roles) > 0 {
+		fmt.Printf("Roles:          %s\n", strings.Join(info.Roles, ", "))
+	}
+	if len(info.Permissions) > 0 {
+		fmt.Printf("Permissions:    %s\n", strings.Join(info.Permissions, ", "))
+	}
+	if info.KeySource != "" {
+		fmt.Printf("API key:        from %s\n", info.KeySource)
+	}
+	if info.KeyExpiresAt != nil {
+		fmt.Printf("Key expires:    %s\n", info.KeyExpiresAt.Local().Format("2006-01-02 15:04 MST"))
+	}
+	fmt.Printf("Control Plane:  %s\n", info.ControlPlane)
+	if !info.Verified {
+		fmt.Println()
+		fmt.Println("⚠️  The control plane does not report identities; user and organization were read from the API key and permissions are unknown")
+	}
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	clierrors "github.com/kubiyabot/cli/internal/errors"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+const (
+	// authCheckTTL is how long a successful API key check is trusted
+	authCheckTTL = 5 * time.Minute
+	// authCheckTimeout bounds the API key check so it never noticeably
+	// delays a command
+	authCheckTimeout = 3 * time.Second
+)
+
+// authCheckStore caches successful API key checks in ~/.kubiya/cache/auth,
+// one file per API key and control plane, named by their hash
+type authCheckStore struct {
+	dir string
+	now func() time.Time
+}
+
+// authCheck is a cached API key check. Identity is nil when the control
+// plane can't tell who the key belongs to.
+type authCheck struct {
+	Identity  *entities.Identity `json:"identity,omitempty"`
+	CheckedAt time.Time          `json:"checked_at"`
+}
+
+func defaultAuthCheckStore() (*authCheckStore, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return &authCheckStore{dir: filepath.Join(homeDir, ".kubiya", "cache", "auth"), now: time.Now}, nil
+}
+
+func (s *authCheckStore) path(apiKey, baseURL string) string {
+	sum := sha256.Sum256([]byte(baseURL + "\n" + apiKey))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// Get returns the check of apiKey when it is recent enough to be trusted
+func (s *authCheckStore) Get(apiKey, baseURL string) (*authCheck, bool) {
+	data, err := os.ReadFile(s.path(apiKey, baseURL))
+	if err != nil {
+		return nil, false
+	}
+	var check authCheck
+	if err := json.Unmarshal(data, &check); err != nil || s.now().Sub(check.CheckedAt) > authCheckTTL {
+		return nil, false
+	}
+	return &check, true
+}
+
+// Save records a successful check of apiKey
+func (s *authCheckStore) Save(apiKey, baseURL string, identity *entities.Identity) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(authCheck{Identity: identity, CheckedAt: s.now()})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(apiKey, baseURL), data, 0600)
+}
+
+// checkAPIKey validates apiKey with whoami unless a recent check is cached.
+// Only a rejected key (401) is an error: a key lacking the permissions of
+// whoami may still be allowed to run the command, and when the control plane
+// can't be reached the command runs and reports the problem itself.
+func checkAPIKey(store *authCheckStore, apiKey, baseURL string, whoami func() (*entities.Identity, error)) (int, error) {
+	if _, ok := store.Get(apiKey, baseURL); ok {
+		return 0, nil
+	}
+
+	identity, err := whoami()
+	var statusErr *controlplane.StatusError
+	switch {
+	case err == nil:
+	case stderrors.As(err, &statusErr) && statusErr.StatusCode == 401:
+		return statusErr.StatusCode, err
+	case stderrors.As(err, &statusErr) && (statusErr.StatusCode == 403 || statusErr.StatusCode == 404):
+		// Control planes without whoami, or keys not allowed to call it,
+		// are accepted as far as we know
+	default:
+		return 0, nil
+	}
+	_ = store.Save(apiKey, baseURL, identity)
+	return 0, nil
+}
+
+// v1Commands are the commands needing an API key that talk to the V1 API
+// rather than the control plane. The V1 API has no whoami to check the key
+// with: their own errors are explained by explainAuthError.
+var v1Commands = map[string]bool{
+	"workflow":  true,
+	"secret":    true,
+	"knowledge": true,
+	"export":    true,
+}
+
+// authPreflight fails control plane commands early when the API key is
+// rejected, explaining where the key came from and how to fix it. Commands
+// run against the V1 API, by V1 contexts too, are not checked.
+func authPreflight(cmd *cobra.Command, cfg *config.Config) error {
+	top, ok := authRequiredCommand(cmd)
+	if !ok || cfg.APIKey == "" || cfg.Mock || replaying(cmd) || cfg.UseV1API || v1Commands[top.Name()] {
+		return nil
+	}
+	if v, err := strconv.ParseBool(os.Getenv("KUBIYA_AUTH_PREFLIGHT")); err == nil && !v {
+		return nil
+	}
+
+	store, err := defaultAuthCheckStore()
+	if err != nil {
+		return nil
+	}
+	client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+	if err != nil {
+		return nil
+	}
+	client.HTTPClient.Timeout = authCheckTimeout
+
+	status, err := checkAPIKey(store, cfg.APIKey, client.BaseURL, client.WhoAmI)
+	if status != 0 {
+		return authFailure(cfg, client.BaseURL, status, err)
+	}
+	return nil
+}
+
+// authErrorStatus returns 401 or 403 when err is a response of the Kubiya
+// API or the control plane rejecting the API key, and 0 otherwise. Errors
+// of tools and third-party APIs are not status errors of our clients, and
+// are left alone whatever they say.
+func authErrorStatus(err error) int {
+	var cpErr *controlplane.StatusError
+	var apiErr *kubiya.StatusError
+	status := 0
+	switch {
+	case stderrors.As(err, &cpErr):
+		status = cpErr.StatusCode
+	case stderrors.As(err, &apiErr):
+		status = apiErr.StatusCode
+	}
+	if status == 401 || status == 403 {
+		return status
+	}
+	return 0
+}
+
+// explainAuthError turns an error of a command caused by a rejected API key
+// into an authentication error that explains how to fix it
+func explainAuthError(cfg *config.Config, err error) error {
+	if err == nil || cfg.APIKey == "" {
+		return err
+	}
+	var cliErr *clierrors.CLIError
+	if stderrors.As(err, &cliErr) {
+		return err
+	}
+	status := authErrorStatus(err)
+	if status == 0 {
+		return err
+	}
+	return authFailure(cfg, cfg.BaseURL, status, err)
+}
+
+// authFailure explains a 401 or 403 response: where the API key came from,
+// who it belongs to and what to do about it
+func authFailure(cfg *config.Config, baseURL string, status int, cause error) error {
+	var b strings.Builder
+	var summary string
+	if status == 403 {
+		summary = "the API key is not allowed to do this (403 Forbidden)"
+		b.WriteString("The API key is valid, but its user lacks the permissions for this command.\n\n")
+	} else {
+		summary = "the API rejected the API key (401 Unauthorized)"
+		b.WriteString("The API key is invalid, expired or revoked.\n\n")
+	}
+
+	source := cfg.APIKeySource
+	if source == "" {
+		source = "the CLI configuration"
+	}
+	fmt.Fprintf(&b, "  API key:       from %s\n", source)
+	if cfg.Org != "" {
+		org := cfg.Org
+		if cfg.Email != "" {
+			org += " (" + cfg.Email + ")"
+		}
+		fmt.Fprintf(&b, "  Organization:  %s\n", org)
+	}
+	if exp, ok := cfg.APIKeyExpiry(); ok {
+		label := "Expires:"
+		if time.Now().After(exp) {
+			label = "Expired:"
+		}
+		fmt.Fprintf(&b, "  %-14s %s\n", label, exp.Local().Format("2006-01-02 15:04 MST"))
+	}
+	if baseURL != "" {
+		fmt.Fprintf(&b, "  API:           %s\n", baseURL)
+	}
+
+	b.WriteString("\nTo fix it:\n")
+	switch {
+	case status == 403:
+		b.WriteString("  • Ask an admin of the organization for access, or\n")
+		b.WriteString("  • Switch to a context of another organization: kubiya config use-context NAME\n")
+	case strings.Contains(source, "KUBIYA_API_KEY"):
+		b.WriteString("  • Create a key at https://compose.kubiya.ai/settings#apiKeys and export KUBIYA_API_KEY=<new key>, or\n")
+		b.WriteString("  • Unset KUBIYA_API_KEY and run: kubiya login\n")
+	default:
+		b.WriteString("  • Sign in again: kubiya login, or\n")
+		b.WriteString("  • Switch to another context: kubiya config use-context NAME\n")
+	}
+	b.WriteString("Run 'kubiya auth whoami' to see who the key belongs to.")
+	if cause != nil {
+		fmt.Fprintf(&b, "\n\nAPI response: %v", cause)
+	}
+
+	return clierrors.AuthErrorWithContext(stderrors.New(summary), b.String())
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	clierrors "github.com/kubiyabot/cli/internal/errors"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestCheckAPIKeyCachesSuccess(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store := &authCheckStore{dir: t.TempDir(), now: func() time.Time { return now }}
+	calls := 0
+	whoami := func() (*entities.Identity, error) {
+		calls++
+		return &entities.Identity{UserID: "u-1", OrganizationName: "acme"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		status, err := checkAPIKey(store, "key", "https://cp", whoami)
+		require.NoError(t, err)
+		assert.Zero(t, status)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Another key or control plane is checked on its own
+	_, _ = checkAPIKey(store, "other-key", "https://cp", whoami)
+	assert.Equal(t, 2, calls)
+
+	// The check expires
+	now = now.Add(authCheckTTL + time.Second)
+	_, _ = checkAPIKey(store, "key", "https://cp", whoami)
+	assert.Equal(t, 3, calls)
+}
+
+func TestCheckAPIKeyRejected(t *testing.T) {
+	store := &authCheckStore{dir: t.TempDir(), now: time.Now}
+	calls := 0
+	whoami := func() (*entities.Identity, error) {
+		calls++
+		return nil, &controlplane.StatusError{StatusCode: 401, Body: `{"detail":"token expired"}`}
+	}
+
+	for i := 0; i < 2; i++ {
+		status, err := checkAPIKey(store, "key", "https://cp", whoami)
+		assert.Equal(t, 401, status)
+		assert.Error(t, err)
+	}
+	// Failures are never cached
+	assert.Equal(t, 2, calls)
+}
+
+func TestCheckAPIKeyUnknown(t *testing.T) {
+	store := &authCheckStore{dir: t.TempDir(), now: time.Now}
+
+	// Unreachable control planes don't block commands
+	status, err := checkAPIKey(store, "key", "https://cp", func() (*entities.Identity, error) {
+		return nil, fmt.Errorf("request failed: dial tcp: connection refused")
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, status)
+	_, cached := store.Get("key", "https://cp")
+	assert.False(t, cached)
+
+	// Keys not allowed to call whoami may still run the command
+	status, err = checkAPIKey(store, "key", "https://cp", func() (*entities.Identity, error) {
+		return nil, &controlplane.StatusError{StatusCode: 403}
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, status)
+
+	// Control planes without whoami accept the key
+	status, err = checkAPIKey(store, "other-key", "https://cp", func() (*entities.Identity, error) {
+		return nil, &controlplane.StatusError{StatusCode: 404}
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, status)
+	_, cached = store.Get("key", "https://cp")
+	assert.True(t, cached)
+}
+
+func TestAuthErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&controlplane.StatusError{StatusCode: 403}, 403},
+		{&controlplane.StatusError{StatusCode: 500}, 0},
+		{fmt.Errorf("failed to list agents: %w", &controlplane.StatusError{StatusCode: 401}), 401},
+		{fmt.Errorf("failed to get source: %w", &kubiya.StatusError{StatusCode: 403}), 403},
+		{&kubiya.StatusError{StatusCode: 404}, 0},
+		// Errors of tools and third-party APIs are not about the API key
+		{fmt.Errorf("kubectl: pods is forbidden: User cannot list pods"), 0},
+		{fmt.Errorf("GitHub API error (status 401): Bad credentials"), 0},
+		{fmt.Errorf("HTTP 401 Unauthorized"), 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, authErrorStatus(tt.err), tt.err.Error())
+	}
+}
+
+func TestExplainAuthError(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:       "not-a-jwt",
+		APIKeySource: "the KUBIYA_API_KEY environment variable",
+		Org:          "acme",
+		Email:        "jane@acme.com",
+		BaseURL:      "https://control-plane.kubiya.ai",
+	}
+
+	err := explainAuthError(cfg, fmt.Errorf("failed to list agents: %w", &controlplane.StatusError{StatusCode: 401, Body: "invalid token"}))
+	var cliErr *clierrors.CLIError
+	require.True(t, stderrors.As(err, &cliErr))
+	assert.Equal(t, clierrors.ErrorTypeAuth, cliErr.Type)
+	assert.Contains(t, cliErr.Error(), "401 Unauthorized")
+	assert.Contains(t, cliErr.Context, "from the KUBIYA_API_KEY environment variable")
+	assert.Contains(t, cliErr.Context, "acme (jane@acme.com)")
+	assert.Contains(t, cliErr.Context, "export KUBIYA_API_KEY")
+	assert.Contains(t, cliErr.Context, "invalid token")
+
+	err = explainAuthError(cfg, &kubiya.StatusError{StatusCode: 403, Body: "missing agents:write"})
+	require.True(t, stderrors.As(err, &cliErr))
+	assert.Contains(t, cliErr.Context, "lacks the permissions")
+
+	// Other errors are left alone
+	other := fmt.Errorf("agent not found")
+	assert.Equal(t, other, explainAuthError(cfg, other))
+	toolErr := fmt.Errorf("tool failed: Error from server (Forbidden): status 403")
+	assert.Equal(t, toolErr, explainAuthError(cfg, toolErr))
+}
+
+func TestRequiresAuth(t *testing.T) {
+	root := &cobra.Command{Use: "kubiya"}
+	agent := &cobra.Command{Use: "agent"}
+	agentList := &cobra.Command{Use: "list"}
+	schema := &cobra.Command{Use: "schema"}
+	schemaExport := &cobra.Command{Use: "export"}
+	agent.AddCommand(agentList)
+	schema.AddCommand(schemaExport)
+	root.AddCommand(agent, schema)
+
+	assert.True(t, requiresAuth(agentList))
+	assert.True(t, requiresAuth(agent))
+	assert.False(t, requiresAuth(schemaExport))
+	assert.False(t, requiresAuth(root))
+}
+
+func TestAuthPreflightChecksControlPlaneCommands(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", server.URL)
+
+	root := &cobra.Command{Use: "kubiya"}
+	agent := &cobra.Command{Use: "agent"}
+	workflow := &cobra.Command{Use: "workflow"}
+	root.AddCommand(agent, workflow)
+
+	cfg := &config.Config{APIKey: "key"}
+	err := authPreflight(agent, cfg)
+	var cliErr *clierrors.CLIError
+	require.True(t, stderrors.As(err, &cliErr))
+	assert.Equal(t, 1, calls)
+
+	// V1 commands, and every command of V1 contexts, are not checked
+	assert.NoError(t, authPreflight(workflow, cfg))
+	assert.NoError(t, authPreflight(agent, &config.Config{APIKey: "key", UseV1API: true}))
+	assert.Equal(t, 1, calls)
+}
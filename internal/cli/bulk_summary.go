@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Outcomes of one item of a bulk operation
+const (
+	bulkOK      = "ok"
+	bulkWarning = "warning"
+	bulkFailed  = "failed"
+)
+
+// bulkResult is the outcome of one item of a bulk operation
+type bulkResult struct {
+	Item    string
+	Status  string
+	Details string
+}
+
+// bulkSummary collects the outcome of every item of a command that works on
+// many resources, so that warnings and errors are shown together at the end
+// instead of scrolling away. It is safe for concurrent use.
+type bulkSummary struct {
+	mu      sync.Mutex
+	results []bulkResult
+}
+
+func (s *bulkSummary) add(item, status, details string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, bulkResult{Item: item, Status: status, Details: details})
+}
+
+// ok records an item that succeeded
+func (s *bulkSummary) ok(item string) {
+	s.add(item, bulkOK, "")
+}
+
+// warn records an item that succeeded with a problem worth reporting
+func (s *bulkSummary) warn(item string, err error) {
+	s.add(item, bulkWarning, err.Error())
+}
+
+// fail records an item that failed
+func (s *bulkSummary) fail(item string, err error) {
+	s.add(item, bulkFailed, err.Error())
+}
+
+// total returns the number of items
+func (s *bulkSummary) total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.results)
+}
+
+// count returns the number of items with status
+func (s *bulkSummary) count(status string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes a table of all items, failed ones first, followed by totals
+func (s *bulkSummary) print(w io.Writer) {
+	s.mu.Lock()
+	sorted := append([]bulkResult(nil), s.results...)
+	s.mu.Unlock()
+	if len(sorted) == 0 {
+		return
+	}
+
+	order := map[string]int{bulkFailed: 0, bulkWarning: 1, bulkOK: 2}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return order[sorted[i].Status] < order[sorted[j].Status]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ITEM\tSTATUS\tDETAILS")
+	for _, r := range sorted {
+		status := r.Status
+		switch r.Status {
+		case bulkFailed:
+			status = style.ErrorStyle.Render(status)
+		case bulkWarning:
+			status = style.WarningStyle.Render(status)
+		default:
+			status = style.SuccessStyle.Render(status)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Item, status, r.Details)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d ok, %d warnings, %d failed\n", s.count(bulkOK), s.count(bulkWarning), s.count(bulkFailed))
+}
+
+// err returns an error when any item failed, for a non-zero exit code
+func (s *bulkSummary) err() error {
+	if failed := s.count(bulkFailed); failed > 0 {
+		return fmt.Errorf("%d of %d items failed", failed, s.total())
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkSummary(t *testing.T) {
+	summary := &bulkSummary{}
+	assert.NoError(t, summary.err())
+
+	summary.ok("Agent: k8s-helper")
+	summary.warn("Detach webhook: wh-1", errors.New("not attached to this agent"))
+	summary.fail("Knowledge file: runbook.md", errors.New("open runbook.md: no such file or directory"))
+	summary.ok("Attached webhook: wh-2")
+
+	var out bytes.Buffer
+	summary.print(&out)
+	lines := strings.Split(out.String(), "\n")
+	require.GreaterOrEqual(t, len(lines), 5)
+	assert.True(t, strings.HasPrefix(lines[0], "ITEM"))
+	assert.True(t, strings.HasPrefix(lines[1], "Knowledge file: runbook.md"), "failed items come first")
+	assert.Contains(t, lines[1], "no such file or directory")
+	assert.True(t, strings.HasPrefix(lines[2], "Detach webhook: wh-1"))
+	assert.Contains(t, out.String(), "2 ok, 1 warnings, 1 failed")
+
+	assert.EqualError(t, summary.err(), "1 of 4 items failed")
+}
+
+func TestBulkSummaryEmpty(t *testing.T) {
+	var out bytes.Buffer
+	(&bulkSummary{}).print(&out)
+	assert.Empty(t, out.String())
+}
@@ -16,22 +16,34 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"text/template"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/docload"
+	"github.com/kubiyabot/cli/internal/httpclient"
 	"github.com/kubiyabot/cli/internal/kubiya"
+	klog "github.com/kubiyabot/cli/internal/log"
+	"github.com/kubiyabot/cli/internal/output"
 	sentryutil "github.com/kubiyabot/cli/internal/sentry"
 	"github.com/kubiyabot/cli/internal/style"
+	"github.com/kubiyabot/cli/internal/toolparse"
+	"github.com/kubiyabot/cli/internal/tracing"
 	"github.com/kubiyabot/cli/internal/tui"
+	"github.com/kubiyabot/cli/internal/version"
 )
 
+// chatLog logs diagnostics of the chat command, see --log-level
+var chatLog = klog.For(klog.Chat)
+
 // Helper function for min values
 func min(a, b int) int {
 	if a < b {
@@ -57,6 +69,8 @@ func isRetryableError(err error) bool {
 		"agent not found",
 		"bad request",
 		"invalid request",
+		"tool call denied",
+		"pre-send hook",
 	}
 
 	for _, nonRetryable := range nonRetryableErrors {
@@ -277,100 +291,6 @@ func formatLiveJSON(jsonStr string) string {
 	return cleaned
 }
 
-// Global map to track working animations by tool name
-var workingAnimations = make(map[string]chan bool)
-var workingAnimationsMu sync.Mutex
-
-// showWorkingAnimation displays rotating work messages while AI is thinking
-func showWorkingAnimation(toolName string) {
-	// Create stop channel for this animation
-	workingAnimationsMu.Lock()
-	stopChan := make(chan bool, 1)
-	workingAnimations[toolName] = stopChan
-	workingAnimationsMu.Unlock()
-
-	defer func() {
-		workingAnimationsMu.Lock()
-		delete(workingAnimations, toolName)
-		workingAnimationsMu.Unlock()
-	}()
-
-	workingMessages := []string{
-		"Analyzing request...",
-		"Planning approach...",
-		"Generating parameters...",
-		"Optimizing strategy...",
-		"Building context...",
-		"Processing input...",
-		"Thinking...",
-		"Computing...",
-		"Preparing...",
-	}
-
-	spinners := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	messageIndex := 0
-	spinnerIndex := 0
-
-	// Show initial state immediately
-	fmt.Printf("\r⚡ %s %s %s",
-		style.ToolExecutingStyle.Render(toolName),
-		style.SpinnerStyle.Render(spinners[spinnerIndex]),
-		style.DimStyle.Render(workingMessages[messageIndex]))
-	os.Stdout.Sync()
-
-	ticker := time.NewTicker(500 * time.Millisecond)       // Change message every 500ms
-	spinnerTicker := time.NewTicker(80 * time.Millisecond) // Spin every 80ms (faster for more dynamic feel)
-
-	defer ticker.Stop()
-	defer spinnerTicker.Stop()
-
-	for {
-		select {
-		case <-stopChan:
-			return // Stop the animation
-		case <-ticker.C:
-			// Rotate through working messages
-			messageIndex = (messageIndex + 1) % len(workingMessages)
-			fmt.Printf("\r⚡ %s %s %s                    ", // Add spaces to clear any leftover text
-				style.ToolExecutingStyle.Render(toolName),
-				style.SpinnerStyle.Render(spinners[spinnerIndex]),
-				style.DimStyle.Render(workingMessages[messageIndex]))
-			os.Stdout.Sync()
-
-		case <-spinnerTicker.C:
-			// Rotate spinner
-			spinnerIndex = (spinnerIndex + 1) % len(spinners)
-			fmt.Printf("\r⚡ %s %s %s                    ", // Add spaces to clear any leftover text
-				style.ToolExecutingStyle.Render(toolName),
-				style.SpinnerStyle.Render(spinners[spinnerIndex]),
-				style.DimStyle.Render(workingMessages[messageIndex]))
-			os.Stdout.Sync()
-		}
-	}
-}
-
-// stopWorkingAnimation stops the working animation for a specific tool
-func stopWorkingAnimation(toolName string) {
-	workingAnimationsMu.Lock()
-	defer workingAnimationsMu.Unlock()
-
-	if stopChan, exists := workingAnimations[toolName]; exists {
-		select {
-		case stopChan <- true:
-		default:
-		}
-	}
-}
-
-// isAnimationRunning checks if a working animation is currently running for a tool
-func isAnimationRunning(toolName string) bool {
-	workingAnimationsMu.Lock()
-	defer workingAnimationsMu.Unlock()
-
-	_, exists := workingAnimations[toolName]
-	return exists
-}
-
 // isCode detects if content looks like code
 func isCode(content string) bool {
 	// Basic heuristics for code detection
@@ -396,48 +316,23 @@ func isCode(content string) bool {
 	return false
 }
 
-// Clean, minimal loading animation for tool execution
-func startToolAnimation(te *toolExecution) {
-	if te.status != "running" {
-		return
-	}
-
-	spinners := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	spinnerIndex := 0
-
-	for te.status == "running" && !te.isComplete {
-		// Simple, clean spinner without extra text
-		fmt.Printf("\r   %s %s",
-			style.SpinnerStyle.Render(spinners[spinnerIndex]),
-			style.DimStyle.Render("Running..."))
-		os.Stdout.Sync()
-
-		spinnerIndex = (spinnerIndex + 1) % len(spinners)
-		time.Sleep(150 * time.Millisecond)
-	}
-
-	// Clean up the animation line completely
-	fmt.Printf("\r%s\r", strings.Repeat(" ", 50))
-}
-
 // Add this type definition at the package level
 type toolExecution struct {
-	name             string
-	args             string
-	output           strings.Builder
-	errorMsg         string // Store actual error message
-	hasOutput        bool
-	hasShownOutput   bool // Track if we've shown initial output message
-	hasShownError    bool // Track if we've shown error message
-	animationStarted bool // Track if animation is running
-	isComplete       bool
-	msgID            string
-	failed           bool
-	status           string // "waiting", "running", "done", "failed"
-	startTime        time.Time
-	runner           string
-	toolCallId       string
-	outputTruncated  bool
+	name            string
+	args            string
+	output          strings.Builder
+	errorMsg        string // Store actual error message
+	hasOutput       bool
+	hasShownOutput  bool // Track if we've shown initial output message
+	hasShownError   bool // Track if we've shown error message
+	isComplete      bool
+	msgID           string
+	failed          bool
+	status          string // "waiting", "running", "done", "failed"
+	startTime       time.Time
+	runner          string
+	toolCallId      string
+	outputTruncated bool
 }
 
 // Add connection status tracking
@@ -484,6 +379,11 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		promptFile   string   // New flag for prompt file
 		templateVars []string // New flag for Go template variables
 		noClassify   bool
+		requireTools []string
+
+		explainClassification bool
+		explainCandidates     int
+		classifyAmong         []string
 
 		interactive     bool
 		debug           bool
@@ -491,16 +391,60 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		clearSession    bool
 		sessionID       string
 		contextFiles    []string
+		maxContextBytes int64
+		bundles         []string
+		attachFiles     []string
 		stdinInput      bool
 		sourceTest      bool
 		sourceUUID      string
 		sourceName      string
 		suggestTool     string
 		permissionLevel string
+		guardrails      []string
+		recordCassette  string
+		replayCassette  string
+		parseOutput     string
+		outputFormat    string
+		onlyParts       []string
+		hideParts       []string
+		artifactsDir    string
+		noPreview       bool
+
+		// Approval flags
+		requireApprovalFrom  []string
+		approvalVia          string
+		approvalSlackChannel string
+		approvalTimeout      time.Duration
 
 		showToolCalls bool
 		retries       int
 		silent        bool // New flag for automation mode
+		renderMode    string
+		replayHistory int
+		fork          bool
+		forkSessionID string
+		async         bool
+		stdinStream   bool
+		delimiter     string
+		autoFollowUp  string
+		followUpTmpl  string
+		bridgeTarget  string
+
+		// Completion notification flags, and what they report
+		notifyOnComplete []string
+		completion       chatCompletion
+
+		// OTLP endpoint to export the chat to as traces, and its tracer
+		otelEndpoint string
+		tracer       *chatTracer
+
+		// Per-session environment overrides of the agent, as KEY=VALUE
+		overrideEnv []string
+
+		// Fail on missing template variables, and show the prompt instead
+		// of sending it
+		strictTemplates bool
+		printRendered   bool
 
 		// Inline agent flags
 		inline         bool
@@ -513,7 +457,12 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		integrations   []string
 		secrets        []string
 		envVars        []string
+		envFile        string
 		llmModel       string
+		withSources    []string
+		sourceIDs      []string
+		services       []string
+		waitServices   bool
 		isDebugMode    bool
 	)
 
@@ -526,9 +475,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			if matches := githubBlobRegex.FindStringSubmatch(rawURL); len(matches) == 5 {
 				user, repo, branch, path := matches[1], matches[2], matches[3], matches[4]
 				rawURL = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", user, repo, branch, path)
-				if debug {
-					fmt.Printf("🔗 Converted GitHub blob URL to raw URL: %s\n", rawURL)
-				}
+				chatLog.Debugf("Converted GitHub blob URL to raw URL: %s", rawURL)
 			}
 
 			// Convert github.com/user/repo/tree/branch/path to raw format for directory listing
@@ -580,8 +527,9 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		return cacheFile, nil
 	}
 
-	// Enhanced helper function to fetch content from URL with caching and validation
-	fetchURL := func(rawURL string) (string, error) {
+	// Enhanced helper function to fetch content from URL with caching and
+	// validation, calling progress with the bytes read when it isn't nil
+	fetchURLWithProgress := func(rawURL string, progress func(read int64)) (string, error) {
 		// Validate and normalize URL
 		validURL, err := validateURL(rawURL)
 		if err != nil {
@@ -591,16 +539,12 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		// Check cache first
 		cacheFile, err := getCacheFilePath(validURL)
 		if err != nil {
-			if debug {
-				fmt.Printf("⚠️ Cache setup failed: %v\n", err)
-			}
+			chatLog.Debugf("Cache setup failed: %v", err)
 		} else {
 			// Check if cache file exists and is recent (less than 1 hour old)
 			if info, err := os.Stat(cacheFile); err == nil {
 				if time.Since(info.ModTime()) < time.Hour {
-					if debug {
-						fmt.Printf("📁 Using cached content from: %s\n", cacheFile)
-					}
+					chatLog.Debugf("Using cached content from: %s", cacheFile)
 					content, err := os.ReadFile(cacheFile)
 					if err == nil {
 						return string(content), nil
@@ -609,14 +553,10 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			}
 		}
 
-		if debug {
-			fmt.Printf("🌐 Fetching content from URL: %s\n", validURL)
-		}
+		chatLog.Debugf("Fetching content from URL: %s", validURL)
 
 		// Create HTTP client with timeout and headers
-		client := &http.Client{
-			Timeout: 30 * time.Second,
-		}
+		client := httpclient.New(30 * time.Second)
 
 		req, err := http.NewRequest("GET", validURL, nil)
 		if err != nil {
@@ -650,12 +590,14 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		// Check content type
 		contentType := resp.Header.Get("Content-Type")
 		if contentType != "" && !strings.Contains(contentType, "text/") && !strings.Contains(contentType, "application/json") {
-			if debug {
-				fmt.Printf("⚠️ Warning: Content-Type is %s, expected text content\n", contentType)
-			}
+			chatLog.Debugf("Warning: Content-Type is %s, expected text content", contentType)
 		}
 
-		content, err := io.ReadAll(resp.Body)
+		var body io.Reader = resp.Body
+		if progress != nil {
+			body = &readProgress{r: resp.Body, progress: progress}
+		}
+		content, err := io.ReadAll(body)
 		if err != nil {
 			return "", fmt.Errorf("failed to read response body: %w", err)
 		}
@@ -670,71 +612,26 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		// Cache the content if cache is available
 		if cacheFile != "" {
 			if err := os.WriteFile(cacheFile, content, 0644); err != nil {
-				if debug {
-					fmt.Printf("⚠️ Failed to cache content: %v\n", err)
-				}
-			} else if debug {
-				fmt.Printf("📁 Cached content to: %s\n", cacheFile)
+				chatLog.Debugf("Failed to cache content: %v", err)
+			} else {
+				chatLog.Debugf("Cached content to: %s", cacheFile)
 			}
 		}
 
-		if debug {
-			fmt.Printf("✅ Successfully fetched %d bytes from URL\n", len(content))
-		}
+		chatLog.Debugf("Successfully fetched %d bytes from URL", len(content))
 
 		return contentStr, nil
 	}
 
-	// Helper function to expand wildcards and read files
-	expandAndReadFiles := func(patterns []string) (map[string]string, error) {
-		context := make(map[string]string)
-		for _, pattern := range patterns {
-			// Handle URLs
-			if strings.HasPrefix(pattern, "http://") || strings.HasPrefix(pattern, "https://") {
-				content, err := fetchURL(pattern)
-				if err != nil {
-					return nil, fmt.Errorf("failed to fetch URL %s: %w", pattern, err)
-				}
-				context[pattern] = content
-				continue
-			}
-
-			// Handle file patterns
-			matches, err := filepath.Glob(pattern)
-			if err != nil {
-				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
-			}
-
-			if len(matches) == 0 {
-				return nil, fmt.Errorf("no files match pattern: %s", pattern)
-			}
-
-			for _, match := range matches {
-				info, err := os.Stat(match)
-				if err != nil {
-					return nil, fmt.Errorf("failed to stat file %s: %w", match, err)
-				}
-
-				// Skip directories
-				if info.IsDir() {
-					continue
-				}
-
-				content, err := os.ReadFile(match)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read file %s: %w", match, err)
-				}
-				context[match] = string(content)
-			}
-		}
-		return context, nil
+	fetchURL := func(rawURL string) (string, error) {
+		return fetchURLWithProgress(rawURL, nil)
 	}
 
-	// Helper function to parse tools from JSON
+	// Helper function to parse tools from JSON or YAML
 	parseTools := func(toolsJSON string) ([]kubiya.Tool, error) {
 		var tools []kubiya.Tool
-		if err := json.Unmarshal([]byte(toolsJSON), &tools); err != nil {
-			return nil, fmt.Errorf("failed to parse tools JSON: %w", err)
+		if err := docload.Unmarshal([]byte(toolsJSON), &tools); err != nil {
+			return nil, fmt.Errorf("failed to parse tools: %w", err)
 		}
 		return tools, nil
 	}
@@ -764,22 +661,24 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 	// Helper function to load tools from file or URL
 
-	// Helper function to discover tools.json file in the same directory as agent spec
+	// Helper function to discover a tools file (tools.json, tools.yaml or
+	// tools.yml) in the same directory as agent spec
+	toolsFileNames := []string{"tools.json", "tools.yaml", "tools.yml"}
 	discoverToolsFile := func(agentSpecURL string) (string, error) {
 		if !strings.HasPrefix(agentSpecURL, "http://") && !strings.HasPrefix(agentSpecURL, "https://") {
 			// For local files, look in same directory
 			dir := filepath.Dir(agentSpecURL)
-			toolsPath := filepath.Join(dir, "tools.json")
-			if _, err := os.Stat(toolsPath); err == nil {
-				if debug {
-					fmt.Printf("🔍 Discovered local tools file: %s\n", toolsPath)
+			for _, name := range toolsFileNames {
+				toolsPath := filepath.Join(dir, name)
+				if _, err := os.Stat(toolsPath); err == nil {
+					chatLog.Debugf("Discovered local tools file: %s", toolsPath)
+					return toolsPath, nil
 				}
-				return toolsPath, nil
 			}
 			return "", nil
 		}
 
-		// For URLs, construct tools.json URL in same directory
+		// For URLs, construct the tools file URLs in same directory
 		parsedURL, err := url.Parse(agentSpecURL)
 		if err != nil {
 			return "", err
@@ -790,40 +689,40 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		if dir == "." {
 			dir = ""
 		}
-		toolsPath := filepath.Join(dir, "tools.json")
 
-		// Construct tools.json URL
-		toolsURL := fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, toolsPath)
+		client := httpclient.New(10 * time.Second)
+		for _, name := range toolsFileNames {
+			toolsURL := fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, filepath.Join(dir, name))
 
-		if debug {
-			fmt.Printf("🔍 Checking for tools file at: %s\n", toolsURL)
-		}
+			chatLog.Debugf("Checking for tools file at: %s", toolsURL)
 
-		// Try to fetch it with a quick HEAD request to avoid downloading if it doesn't exist
-		client := &http.Client{Timeout: 10 * time.Second}
-		req, err := http.NewRequest("HEAD", toolsURL, nil)
-		if err != nil {
-			return "", nil // Not critical, just return no tools file found
-		}
+			// Try to fetch it with a quick HEAD request to avoid downloading if it doesn't exist
+			req, err := http.NewRequest("HEAD", toolsURL, nil)
+			if err != nil {
+				return "", nil // Not critical, just return no tools file found
+			}
 
-		// Add GitHub token if available
-		if strings.Contains(toolsURL, "githubusercontent.com") {
-			if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-				req.Header.Set("Authorization", "token "+token)
+			// Add GitHub token if available
+			if strings.Contains(toolsURL, "githubusercontent.com") {
+				if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+					req.Header.Set("Authorization", "token "+token)
+				}
 			}
-		}
 
-		resp, err := client.Do(req)
-		if err != nil || resp.StatusCode != 200 {
-			return "", nil // Tools file doesn't exist, that's okay
-		}
-		resp.Body.Close()
+			resp, err := client.Do(req)
+			if err != nil {
+				continue
+			}
+			resp.Body.Close()
+			if resp.StatusCode != 200 {
+				continue // Tools file doesn't exist, that's okay
+			}
 
-		if debug {
-			fmt.Printf("✅ Found tools file at: %s\n", toolsURL)
-		}
+			chatLog.Debugf("Found tools file at: %s", toolsURL)
 
-		return toolsURL, nil
+			return toolsURL, nil
+		}
+		return "", nil
 	}
 
 	// Helper function to escape shell strings
@@ -935,7 +834,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 	}
 
 	// Helper function to handle command substitution
-	processCommandSubstitution := func(content string, debug bool, errors *[]string) string {
+	processCommandSubstitution := func(content string, errors *[]string) string {
 		result := content
 		for strings.Contains(result, "$(") {
 			start := strings.Index(result, "$(")
@@ -960,9 +859,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 				output, cmdErr := cmdExec.Output()
 				if cmdErr == nil {
 					result = result[:start] + strings.TrimSpace(string(output)) + result[end:]
-					if debug {
-						fmt.Printf("✅ Command substitution successful: %s\n", command)
-					}
+					chatLog.Debugf("Command substitution successful: %s", command)
 				} else {
 					*errors = append(*errors, fmt.Sprintf("command substitution '%s': %v", command, cmdErr))
 					break
@@ -976,7 +873,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 	}
 
 	// Helper function for manual environment variable substitution
-	manualEnvSubstitution := func(content string, debug bool) string {
+	manualEnvSubstitution := func(content string) string {
 		result := content
 		substitutionCount := 0
 
@@ -1023,8 +920,8 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			}
 		}
 
-		if debug && substitutionCount > 0 {
-			fmt.Printf("✅ Manual substitution: %d replacements\n", substitutionCount)
+		if substitutionCount > 0 {
+			chatLog.Debugf("Manual substitution: %d replacements", substitutionCount)
 		}
 
 		return result
@@ -1032,9 +929,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 	// Helper function to process content with templating support (both Go templates and shell)
 	processTemplateContent := func(content string, templateVars []string, contentType string) (string, error) {
-		if debug {
-			fmt.Printf("🔍 Processing %s with templating (%d characters, %d variables)\n", contentType, len(content), len(templateVars))
-		}
+		chatLog.Debugf("Processing %s with templating (%d characters, %d variables)", contentType, len(content), len(templateVars))
 
 		// Detect if content contains Go template syntax
 		hasGoTemplate := strings.Contains(content, "{{") && strings.Contains(content, "}}")
@@ -1043,14 +938,16 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		result := content
 		var processingErrors []string
 
-		// Phase 1: Process Go templates if present and variables provided
-		if hasGoTemplate && len(templateVars) > 0 {
-			if debug {
-				fmt.Printf("🔍 Processing Go template in %s with %d variables\n", contentType, len(templateVars))
-			}
+		// Phase 1: Process Go templates if present and variables provided,
+		// always in strict mode
+		if hasGoTemplate && (len(templateVars) > 0 || strictTemplates) {
+			chatLog.Debugf("Processing Go template in %s with %d variables", contentType, len(templateVars))
 
 			templateData, err := parseTemplateVars(templateVars)
 			if err != nil {
+				if strictTemplates {
+					return "", fmt.Errorf("invalid template variables: %w", err)
+				}
 				processingErrors = append(processingErrors, fmt.Sprintf("template variables: %v", err))
 			} else {
 				// Add environment variables to template data
@@ -1066,33 +963,38 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 				// Create and parse template
 				tmpl := template.New(contentType).Funcs(createTemplateFuncMap())
+				if strictTemplates {
+					tmpl = tmpl.Option("missingkey=error")
+				}
 				tmpl, err = tmpl.Parse(content)
 				if err != nil {
+					if strictTemplates {
+						return "", fmt.Errorf("invalid template in %s: %w", contentType, err)
+					}
 					processingErrors = append(processingErrors, fmt.Sprintf("template parsing in %s: %v", contentType, err))
+				} else if missing := missingTemplateVars(tmpl, templateData); strictTemplates && len(missing) > 0 {
+					return "", missingTemplateVarsError(contentType, missing)
 				} else {
 					var buf bytes.Buffer
 					err = tmpl.Execute(&buf, templateData)
 					if err != nil {
+						if strictTemplates {
+							return "", fmt.Errorf("failed to render template in %s: %w", contentType, err)
+						}
 						processingErrors = append(processingErrors, fmt.Sprintf("template execution in %s: %v", contentType, err))
 					} else {
 						result = buf.String()
-						if debug {
-							fmt.Printf("✅ Go template processing successful for %s\n", contentType)
-						}
+						chatLog.Debugf("Go template processing successful for %s", contentType)
 					}
 				}
 			}
 		} else if hasGoTemplate && len(templateVars) == 0 {
-			if debug {
-				fmt.Printf("⚠️ Go template syntax detected in %s but no --var provided, skipping template processing\n", contentType)
-			}
+			chatLog.Debugf("Go template syntax detected in %s but no --var provided, skipping template processing", contentType)
 		}
 
 		// Phase 2: Process shell substitution (always attempt if shell variables detected)
 		if hasShellVars {
-			if debug {
-				fmt.Printf("🔍 Processing shell substitution in %s\n", contentType)
-			}
+			chatLog.Debugf("Processing shell substitution in %s", contentType)
 
 			// Try envsubst first, then bash, then manual as fallbacks
 			func() {
@@ -1108,12 +1010,10 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 				if processedContent, err := cmd.Output(); err == nil {
 					result = string(processedContent)
-					if debug {
-						fmt.Printf("✅ envsubst processing successful for %s\n", contentType)
-					}
+					chatLog.Debugf("envsubst processing successful for %s", contentType)
 
 					// Handle command substitution after envsubst
-					result = processCommandSubstitution(result, debug, &processingErrors)
+					result = processCommandSubstitution(result, &processingErrors)
 				} else {
 					processingErrors = append(processingErrors, fmt.Sprintf("envsubst in %s: %v", contentType, err))
 				}
@@ -1121,9 +1021,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 			// Fallback to bash if envsubst failed
 			if len(processingErrors) > 0 {
-				if debug {
-					fmt.Printf("⚠️ envsubst failed for %s, trying bash expansion\n", contentType)
-				}
+				chatLog.Debugf("envsubst failed for %s, trying bash expansion", contentType)
 
 				func() {
 					defer func() {
@@ -1137,9 +1035,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 					if bashOutput, bashErr := bashCmd.Output(); bashErr == nil {
 						result = strings.TrimSpace(string(bashOutput))
-						if debug {
-							fmt.Printf("✅ bash expansion successful for %s\n", contentType)
-						}
+						chatLog.Debugf("bash expansion successful for %s", contentType)
 						// Clear envsubst errors since bash worked
 						processingErrors = []string{}
 					} else {
@@ -1150,26 +1046,20 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 			// Final fallback to manual substitution
 			if len(processingErrors) > 0 {
-				if debug {
-					fmt.Printf("⚠️ Both envsubst and bash failed for %s, using manual substitution\n", contentType)
-				}
+				chatLog.Debugf("Both envsubst and bash failed for %s, using manual substitution", contentType)
 
-				result = manualEnvSubstitution(result, debug)
-				if debug {
-					fmt.Printf("✅ Manual substitution completed for %s\n", contentType)
-				}
+				result = manualEnvSubstitution(result)
+				chatLog.Debugf("Manual substitution completed for %s", contentType)
 			}
 		}
 
 		// Report any processing warnings
-		if len(processingErrors) > 0 && debug {
-			fmt.Printf("⚠️ Processing warnings for %s: %s\n", contentType, strings.Join(processingErrors, "; "))
+		if len(processingErrors) > 0 {
+			chatLog.Debugf("Processing warnings for %s: %s", contentType, strings.Join(processingErrors, "; "))
 		}
 
 		finalResult := strings.TrimSpace(result)
-		if debug {
-			fmt.Printf("🔍 Final processed %s content: %d characters\n", contentType, len(finalResult))
-		}
+		chatLog.Debugf("Final processed %s content: %d characters", contentType, len(finalResult))
 
 		return finalResult, nil
 	}
@@ -1181,18 +1071,14 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 		// Load agent spec content (file or URL)
 		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
-			if debug {
-				fmt.Printf("🔍 Loading agent specification from URL: %s\n", source)
-			}
+			chatLog.Debugf("Loading agent specification from URL: %s", source)
 
 			specData, err = fetchURL(source)
 			if err != nil {
 				return nil, fmt.Errorf("failed to fetch agent spec from URL %s: %w", source, err)
 			}
 		} else {
-			if debug {
-				fmt.Printf("🔍 Loading agent specification from file: %s\n", source)
-			}
+			chatLog.Debugf("Loading agent specification from file: %s", source)
 
 			rawData, err := os.ReadFile(source)
 			if err != nil {
@@ -1207,15 +1093,13 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			return nil, fmt.Errorf("failed to process agent spec templating: %w", err)
 		}
 
-		// Parse agent specification JSON
+		// Parse agent specification (JSON or YAML)
 		var agentSpec map[string]interface{}
-		if err := json.Unmarshal([]byte(processedSpecData), &agentSpec); err != nil {
-			return nil, fmt.Errorf("failed to parse agent specification JSON from %s: %w", source, err)
+		if err := docload.Unmarshal([]byte(processedSpecData), &agentSpec); err != nil {
+			return nil, fmt.Errorf("failed to parse agent specification from %s: %w", source, err)
 		}
 
-		if debug {
-			fmt.Printf("✅ Successfully loaded and parsed agent specification from %s\n", source)
-		}
+		chatLog.Debugf("Successfully loaded and parsed agent specification from %s", source)
 
 		return agentSpec, nil
 	}
@@ -1227,18 +1111,14 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 		// Load tools content (file or URL)
 		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
-			if debug {
-				fmt.Printf("🔍 Loading tools from URL: %s\n", source)
-			}
+			chatLog.Debugf("Loading tools from URL: %s", source)
 
 			toolsData, err = fetchURL(source)
 			if err != nil {
 				return nil, fmt.Errorf("failed to fetch tools file from URL %s: %w", source, err)
 			}
 		} else {
-			if debug {
-				fmt.Printf("🔍 Loading tools from file: %s\n", source)
-			}
+			chatLog.Debugf("Loading tools from file: %s", source)
 
 			rawData, err := os.ReadFile(source)
 			if err != nil {
@@ -1253,15 +1133,10 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			return nil, fmt.Errorf("failed to process tools spec templating: %w", err)
 		}
 
-		// Validate JSON format
-		if !json.Valid([]byte(processedToolsData)) {
-			return nil, fmt.Errorf("tools specification from %s does not contain valid JSON after templating", source)
-		}
-
-		// Parse tools JSON
+		// Parse tools (JSON or YAML)
 		var tools []kubiya.Tool
-		if err := json.Unmarshal([]byte(processedToolsData), &tools); err != nil {
-			return nil, fmt.Errorf("failed to parse tools JSON from %s: %w", source, err)
+		if err := docload.Unmarshal([]byte(processedToolsData), &tools); err != nil {
+			return nil, fmt.Errorf("failed to parse tools specification from %s after templating: %w", source, err)
 		}
 
 		// Validate all tools
@@ -1271,9 +1146,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			}
 		}
 
-		if debug {
-			fmt.Printf("✅ Successfully loaded and validated %d tools from %s\n", len(tools), source)
-		}
+		chatLog.Debugf("Successfully loaded and validated %d tools from %s", len(tools), source)
 
 		return tools, nil
 	}
@@ -1305,9 +1178,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			if err != nil {
 				return "", fmt.Errorf("failed to fetch prompt from URL %s: %w", filePath, err)
 			}
-			if debug {
-				fmt.Printf("🔍 Fetched prompt from URL: %s (%d characters)\n", filePath, len(content))
-			}
+			chatLog.Debugf("Fetched prompt from URL: %s (%d characters)", filePath, len(content))
 		} else {
 			// Handle local file
 			// Validate file exists and is readable
@@ -1325,9 +1196,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			content = string(rawContent)
-			if debug {
-				fmt.Printf("🔍 Read prompt file: %s (%d characters)\n", filePath, len(content))
-			}
+			chatLog.Debugf("Read prompt file: %s (%d characters)", filePath, len(content))
 		}
 
 		// Detect if file contains Go template syntax ({{ }})
@@ -1337,14 +1206,16 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		var result string = content
 		var processingErrors []string
 
-		// Phase 1: Process Go templates if present and variables provided
-		if hasGoTemplate && len(templateVars) > 0 {
-			if debug {
-				fmt.Printf("🔍 Processing Go template with %d variables\n", len(templateVars))
-			}
+		// Phase 1: Process Go templates if present and variables provided,
+		// always in strict mode
+		if hasGoTemplate && (len(templateVars) > 0 || strictTemplates) {
+			chatLog.Debugf("Processing Go template with %d variables", len(templateVars))
 
 			templateData, err := parseTemplateVars(templateVars)
 			if err != nil {
+				if strictTemplates {
+					return "", fmt.Errorf("invalid template variables: %w", err)
+				}
 				processingErrors = append(processingErrors, fmt.Sprintf("template variables: %v", err))
 			} else {
 				// Add environment variables to template data
@@ -1360,33 +1231,38 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 				// Create and parse template
 				tmpl := template.New("prompt").Funcs(createTemplateFuncMap())
+				if strictTemplates {
+					tmpl = tmpl.Option("missingkey=error")
+				}
 				tmpl, err = tmpl.Parse(content)
 				if err != nil {
+					if strictTemplates {
+						return "", fmt.Errorf("invalid template in prompt file %s: %w", filePath, err)
+					}
 					processingErrors = append(processingErrors, fmt.Sprintf("template parsing: %v", err))
+				} else if missing := missingTemplateVars(tmpl, templateData); strictTemplates && len(missing) > 0 {
+					return "", missingTemplateVarsError("prompt file "+filePath, missing)
 				} else {
 					var buf bytes.Buffer
 					err = tmpl.Execute(&buf, templateData)
 					if err != nil {
+						if strictTemplates {
+							return "", fmt.Errorf("failed to render prompt file %s: %w", filePath, err)
+						}
 						processingErrors = append(processingErrors, fmt.Sprintf("template execution: %v", err))
 					} else {
 						result = buf.String()
-						if debug {
-							fmt.Printf("✅ Go template processing successful\n")
-						}
+						chatLog.Debug("Go template processing successful")
 					}
 				}
 			}
 		} else if hasGoTemplate && len(templateVars) == 0 {
-			if debug {
-				fmt.Printf("⚠️ Go template syntax detected but no --var provided, skipping template processing\n")
-			}
+			chatLog.Debug("Go template syntax detected but no --var provided, skipping template processing")
 		}
 
 		// Phase 2: Process shell substitution (always attempt if shell variables detected)
 		if hasShellVars {
-			if debug {
-				fmt.Printf("🔍 Processing shell substitution\n")
-			}
+			chatLog.Debug("Processing shell substitution")
 
 			// Method 1: Try envsubst (GNU gettext) - most reliable for env vars
 			func() {
@@ -1402,12 +1278,10 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 				if processedContent, err := cmd.Output(); err == nil {
 					result = string(processedContent)
-					if debug {
-						fmt.Printf("✅ envsubst processing successful\n")
-					}
+					chatLog.Debug("envsubst processing successful")
 
 					// Handle command substitution after envsubst
-					result = processCommandSubstitution(result, debug, &processingErrors)
+					result = processCommandSubstitution(result, &processingErrors)
 				} else {
 					processingErrors = append(processingErrors, fmt.Sprintf("envsubst: %v", err))
 				}
@@ -1415,9 +1289,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 			// Method 2: Fallback to bash expansion if envsubst failed
 			if len(processingErrors) > 0 {
-				if debug {
-					fmt.Printf("⚠️ envsubst failed, trying bash expansion\n")
-				}
+				chatLog.Debug("envsubst failed, trying bash expansion")
 
 				func() {
 					defer func() {
@@ -1431,9 +1303,7 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 					if bashOutput, bashErr := bashCmd.Output(); bashErr == nil {
 						result = strings.TrimSpace(string(bashOutput))
-						if debug {
-							fmt.Printf("✅ bash expansion successful\n")
-						}
+						chatLog.Debug("bash expansion successful")
 						// Clear envsubst errors since bash worked
 						processingErrors = []string{}
 					} else {
@@ -1444,26 +1314,20 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 
 			// Method 3: Manual environment variable substitution as final fallback
 			if len(processingErrors) > 0 {
-				if debug {
-					fmt.Printf("⚠️ Both envsubst and bash failed, using manual substitution\n")
-				}
+				chatLog.Debug("Both envsubst and bash failed, using manual substitution")
 
-				result = manualEnvSubstitution(result, debug)
-				if debug {
-					fmt.Printf("✅ Manual substitution completed\n")
-				}
+				result = manualEnvSubstitution(result)
+				chatLog.Debug("Manual substitution completed")
 			}
 		}
 
 		// Phase 3: Final validation and error reporting
-		if len(processingErrors) > 0 && debug {
-			fmt.Printf("⚠️ Processing warnings: %s\n", strings.Join(processingErrors, "; "))
+		if len(processingErrors) > 0 {
+			chatLog.Debugf("Processing warnings: %s", strings.Join(processingErrors, "; "))
 		}
 
 		finalResult := strings.TrimSpace(result)
-		if debug {
-			fmt.Printf("🔍 Final processed content: %d characters\n", len(finalResult))
-		}
+		chatLog.Debugf("Final processed content: %d characters", len(finalResult))
 
 		return finalResult, nil
 	}
@@ -1499,6 +1363,9 @@ func newChatCommand(cfg *config.Config) *cobra.Command {
 		Long: `Start a chat session with a Kubiya agent.
 You can either use enhanced interactive mode, specify a message directly, use a prompt file, or pipe input from stdin.
 Use --context to include additional files for context (supports wildcards and URLs).
+Binary files are skipped, and at most --max-context-bytes (5 MiB by default) are sent.
+Use --attach for large or binary files: they are uploaded to Kubiya file storage
+and referenced from the message instead of being inlined into the prompt.
 The command will automatically select the most appropriate agent unless one is specified.
 
 Enhanced Interactive Mode Features:
@@ -1517,9 +1384,10 @@ Automatic Retry Features:
 • Comprehensive retry patterns for network, TLS, DNS, and connection issues
 
 Permission Levels:
-• read: Execute read-only operations (kubectl get, describe, logs, etc.)
-• readwrite: Execute all operations including read-write (kubectl apply, delete, etc.)
-• ask: Ask for confirmation before executing operations
+The level is sent to the agent with the message, which enforces it:
+• read: The agent only runs read-only operations (kubectl get, describe, logs, etc.)
+• readwrite: The agent may run all operations, including changes (kubectl apply, delete, etc.)
+• ask: Every tool call is also confirmed on the terminal, and the response stops without one
 
 Automation Mode:
 Use --silent flag or set KUBIYA_AUTOMATION environment variable to suppress progress updates,
@@ -1541,11 +1409,18 @@ URL Support:
 - Automatic caching (1 hour) with GITHUB_TOKEN support for private repos
 
 Both methods can be used together. Go templates are processed first, then shell substitution.
+With --strict-templates, variables that are not set fail the command with the list of them,
+and --print-rendered prints the final prompt instead of sending it.
 
 For inline agents, use --inline with --tools-file or --tools-json to provide custom tools.`,
 		Example: `  # Enhanced interactive chat mode
   kubiya chat --interactive
 
+  # Submit from automation and collect the reply later
+  id=$(kubiya chat -n "DevOps Bot" -m "Upgrade the staging cluster" --async)
+  kubiya chat wait "$id" --timeout 30m
+  kubiya chat result "$id" --output json
+
   # Using context files with wildcards
   kubiya chat -n "security" -m "Review this code" --context "src/*.go" --context "tests/**/*_test.go"
 
@@ -1562,9 +1437,21 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
   # Pipe from stdin with context
   cat error.log | kubiya chat -n "debug" --stdin --context "config/*.yaml"
 
+  # Triage alerts as they come, one message per block
+  tail -f alerts.log | kubiya chat -n "triage" --stdin-stream --delimiter "---"
+
+  # Drive a session from the terminal while teammates follow it in a Slack thread
+  kubiya chat -n "incident-helper" --bridge "slack:#incident-42" -m "Why is checkout returning 502s?"
+
+  # Attach large or binary files instead of inlining them
+  kubiya chat -n "debug" -m "Why did the node crash?" --attach big.log --attach "dumps/*.core"
+
   # Auto-classify the most appropriate agent
   kubiya chat -m "Help me with Kubernetes deployment issues"
 
+  # Make sure the selected agent can actually run kubectl and helm
+  kubiya chat -m "Upgrade the ingress chart" --require-tools kubectl,helm
+
   # Different permission levels
   kubiya chat -n "devops" -m "Show me the pods" --permission-level read
   kubiya chat -n "devops" -m "Deploy the application" --permission-level readwrite
@@ -1574,6 +1461,14 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
   kubiya chat -n "devops" -m "kubectl get pods" --silent
   export KUBIYA_AUTOMATION=1 && kubiya chat -n "devops" -m "kubectl get nodes"
 
+  # Control how responses are rendered (markdown on terminals by default)
+  kubiya chat -n "devops" -m "Summarize the incident" --render markdown
+  kubiya chat -n "devops" -m "List failing pods" --render plain | tee pods.txt
+
+  # Record a session once, then replay it offline for demos and output tests
+  kubiya chat -n "devops" -m "Check the pods" --record pods.cassette
+  kubiya chat -n "devops" -m "Check the pods" --replay pods.cassette
+
   # Using prompt files with shell substitution
   kubiya chat -n "devops" --prompt-file deployment-prompt.txt
   kubiya chat -n "security" -f analysis-prompt.md --context "src/**/*.go"
@@ -1582,6 +1477,9 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
   kubiya chat -f template-prompt.txt --var "ProjectName=MyApp" --var "Environment=production"
   kubiya chat -f report-template.md --var "Debug=true" --var "Items=pod1,pod2,pod3"
 
+  # Check that every template variable is set and preview the prompt
+  kubiya chat -f template-prompt.txt --var "ProjectName=MyApp" --strict-templates --print-rendered
+
   # Using prompt files from URLs (GitHub raw URLs)
   kubiya chat -f https://raw.githubusercontent.com/user/repo/main/prompts/deploy.txt
   kubiya chat -f https://github.com/user/repo/blob/main/prompts/analysis.md --var "Project=MyApp"
@@ -1589,6 +1487,12 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
   # Continue a previous conversation
   kubiya chat --session abc123-def456-ghi789 -m "What about the logs?"
 
+  # Continue a conversation and replay the last 5 exchanges first
+  kubiya chat --session abc123-def456-ghi789 --replay-history 5 -m "Where were we?"
+
+  # Explore an alternative in a new session, leaving the original untouched
+  kubiya chat --session abc123-def456-ghi789 --fork -m "What if we used blue/green instead?"
+
   # Inline agent with tools from file
   kubiya chat --inline --tools-file tools.json --ai-instructions "You are a helpful assistant" \
     --description "Custom inline agent" --runners "kubiyamanaged" -m "kubectl get pods"
@@ -1605,25 +1509,131 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
   kubiya chat --inline --tools-file https://raw.githubusercontent.com/user/tools/main/k8s-tools.json \
     --ai-instructions "You are a Kubernetes expert" -m "Check cluster status"
   kubiya chat --inline --tools-file https://github.com/user/tools/blob/main/devops-tools.json \
-    --description "DevOps Assistant" -m "Deploy the application"`,
+    --description "DevOps Assistant" -m "Deploy the application"
+
+  # Try local tool code without pushing it (the source is removed afterwards)
+  kubiya chat --inline --with-source ./tools/ -m "Run the new health check tool"
+  kubiya chat -n "DevOps Bot" --with-source ./tools/ -m "Use my local tools"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Silence usage on errors for clean error messages
 			cmd.SilenceUsage = true
 
 			cfg.Debug = cfg.Debug || debug
+			if debug {
+				klog.Verbose(klog.LevelDebug)
+			}
 
 			// Check for automation mode (either --silent flag or KUBIYA_AUTOMATION env var)
 			automationMode := silent || os.Getenv("KUBIYA_AUTOMATION") != ""
 
+			resolvedRenderMode, err := resolveRenderMode(renderMode, automationMode)
+			if err != nil {
+				return err
+			}
+
+			// Record or replay the API interactions of this chat
+			stopCassette, err := startChatCassette(cfg, recordCassette, replayCassette, os.Stderr)
+			if err != nil {
+				return err
+			}
+			defer stopCassette()
+
+			if forkSessionID != "" {
+				if sessionID != "" && sessionID != forkSessionID {
+					return fmt.Errorf("--fork-session and --session name different sessions")
+				}
+				sessionID, fork = forkSessionID, true
+			}
+			if fork && interactive {
+				return fmt.Errorf("--fork is not supported in interactive mode")
+			}
+			if async {
+				// Nobody is left to confirm tool calls once the prompt is submitted
+				switch {
+				case interactive || inline:
+					return fmt.Errorf("--async is not supported with --interactive or --inline")
+				case len(requireApprovalFrom) > 0 || len(guardrails) > 0 || permissionLevel == "ask":
+					return fmt.Errorf("--async cannot confirm tool calls: drop --require-approval-from, --guardrail and --permission-level ask")
+				}
+				automationMode = true
+			}
+			if stdinStream {
+				switch {
+				case interactive || inline || async:
+					return fmt.Errorf("--stdin-stream is not supported with --interactive, --inline or --async")
+				case message != "" || stdinInput || promptFile != "":
+					return fmt.Errorf("--stdin-stream reads the messages from stdin, drop --message, --stdin and --prompt-file")
+				case agentID == "" && agentName == "":
+					return fmt.Errorf("--stdin-stream needs the agent to send the messages to (--name or --agent-uuid)")
+				case len(requireApprovalFrom) > 0 || len(guardrails) > 0 || permissionLevel == "ask":
+					return fmt.Errorf("--stdin-stream cannot confirm tool calls: drop --require-approval-from, --guardrail and --permission-level ask")
+				}
+				if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+					return fmt.Errorf("--stdin-stream needs input piped to stdin")
+				}
+				automationMode = true
+			}
+			if bridgeTarget != "" {
+				// Messages are read from the terminal and the bridged conversation
+				switch {
+				case interactive || inline || async || stdinStream:
+					return fmt.Errorf("--bridge is not supported with --interactive, --inline, --async or --stdin-stream")
+				case stdinInput:
+					return fmt.Errorf("--bridge reads the follow-up messages from the terminal, drop --stdin")
+				case agentID == "" && agentName == "":
+					return fmt.Errorf("--bridge needs the agent to talk to (--name or --agent-uuid)")
+				case len(guardrails) > 0 || permissionLevel == "ask":
+					return fmt.Errorf("--bridge cannot confirm tool calls in the terminal: drop --guardrail and --permission-level ask")
+				}
+				if _, err := parseBridgeTarget(bridgeTarget); err != nil {
+					return err
+				}
+			}
+
+			streamFilter, err := newChatStreamFilter(onlyParts, hideParts)
+			if err != nil {
+				return err
+			}
+			if streamFilter != nil && interactive {
+				return fmt.Errorf("--only and --hide are not supported in interactive mode")
+			}
+
 			if interactive {
 				return tui.RunEnhancedChat(cfg)
 			}
 
+			// Structured tool output: tables in the transcript, records in JSON lines
+			if outputFormat != chatOutputText && outputFormat != chatOutputJSONL {
+				return fmt.Errorf("invalid output format %q (valid: %s, %s)", outputFormat, chatOutputText, chatOutputJSONL)
+			}
+			parseMode := parseOutput
+			if parseMode == "" && outputFormat == chatOutputJSONL {
+				parseMode = toolparse.Auto
+			}
+			var parsing *toolOutputParsing
+			if parseMode != "" {
+				if parsing, err = newToolOutputParsing(parseMode); err != nil {
+					return err
+				}
+			}
+			var events *chatEventWriter
+			if outputFormat == chatOutputJSONL {
+				events = newChatEventWriter(os.Stdout, parsing)
+			} else if streamFilter != nil {
+				events = newChatTextEventWriter(os.Stdout, parsing)
+			}
+			if events != nil {
+				events.filter = streamFilter
+			}
+
 			// Handle inline agent validation
 			if inline {
 				if agentID != "" || agentName != "" {
 					return fmt.Errorf("cannot use --inline with --agent or --name")
 				}
+				if len(requireTools) > 0 {
+					return fmt.Errorf("cannot use --require-tools with --inline (the inline agent only has the tools you define)")
+				}
 
 				// Validate agent spec vs tools specification
 				if agentSpec != "" {
@@ -1633,8 +1643,8 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 					}
 				} else {
 					// No agent spec - must provide tools
-					if toolsFile == "" && toolsJSON == "" {
-						return fmt.Errorf("--inline requires either --agent-spec or tools specification (--tools-file or --tools-json)")
+					if toolsFile == "" && toolsJSON == "" && len(withSources) == 0 && len(sourceIDs) == 0 {
+						return fmt.Errorf("--inline requires either --agent-spec or tools specification (--tools-file, --tools-json, --source or --with-source)")
 					}
 					if toolsFile != "" && toolsJSON != "" {
 						return fmt.Errorf("cannot use both --tools-file and --tools-json")
@@ -1645,15 +1655,25 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				}
 				if len(runners) == 0 {
 					runners = []string{"kubiyamanaged"}
+					if cfg.Preferences.Runner != "" {
+						runners = []string{cfg.Preferences.Runner}
+					}
 				}
 				if llmModel == "" {
 					llmModel = "azure/gpt-4-32k"
+					if cfg.Preferences.LLMModel != "" {
+						llmModel = cfg.Preferences.LLMModel
+					}
 				}
 				if len(integrations) == 0 {
 					integrations = []string{}
 				}
 			}
 
+			if err := validateSourceDirs(withSources); err != nil {
+				return err
+			}
+
 			// Session storage file path
 			sessionFile := filepath.Join(os.TempDir(), "kubiya_last_session")
 
@@ -1713,60 +1733,144 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				}
 				message = promptContent
 
-				if debug {
-					fmt.Printf("🔍 Processed prompt from file: %s (%d characters)\n", promptFile, len(message))
-				}
+				chatLog.Debugf("Processed prompt from file: %s (%d characters)", promptFile, len(message))
 			}
 
 			// Validate input
-			if message == "" && !stdinInput {
+			if message == "" && !stdinInput && !stdinStream && bridgeTarget == "" {
 				return fmt.Errorf("message is required (use -m, --prompt-file, --stdin, or pipe input)")
 			}
 
-			// Validate permission level
-			if permissionLevel != "" && permissionLevel != "read" && permissionLevel != "readwrite" && permissionLevel != "ask" {
-				return fmt.Errorf("invalid permission level: %s (must be 'read', 'readwrite', or 'ask')", permissionLevel)
+			// Preview the final prompt without sending it
+			if printRendered {
+				if message == "" {
+					return fmt.Errorf("--print-rendered needs a prompt (use -m, --prompt-file or --stdin)")
+				}
+				fmt.Println(message)
+				return nil
 			}
+
+			// Validate permission level
 			if permissionLevel == "" {
-				permissionLevel = "read" // Default to read-only
+				permissionLevel = kubiya.PermissionRead // Default to read-only
+			}
+			permissions, err := kubiya.NewChatPermissions(permissionLevel)
+			if err != nil {
+				return err
+			}
+			followUps, err := newFollowUpPolicy(autoFollowUp, followUpTmpl, cfg.Preferences.Chat)
+			if err != nil {
+				return err
 			}
 
-			// Load context from all sources
-			context, err := expandAndReadFiles(contextFiles)
+			// Load context from all sources, including the named bundles
+			bundleGlobs, bundleOutputs, err := resolveContextBundles(cmd.Context(), bundles)
+			if err != nil {
+				return fmt.Errorf("failed to load context bundle: %w", err)
+			}
+			var contextOut io.Writer
+			if !automationMode {
+				contextOut = os.Stderr
+			}
+			context, _, err := loadContextFiles(append(contextFiles, bundleGlobs...), fetchURLWithProgress, maxContextBytes,
+				contextOut, isatty.IsTerminal(os.Stderr.Fd()) && !output.IsCI())
 			if err != nil {
 				return fmt.Errorf("failed to load context: %w", err)
 			}
-
-			// Enhance message with permission level context
-			enhancedMessage := message
-			if !interactive {
-				permissionMsg := ""
-				switch permissionLevel {
-				case "read":
-					permissionMsg = "\n\n[SYSTEM] You have permission to execute READ-ONLY operations (kubectl get, describe, logs, etc.). You should execute these operations directly without asking for confirmation."
-				case "readwrite":
-					permissionMsg = "\n\n[SYSTEM] You have FULL PERMISSION to execute any operations including read-write operations (kubectl apply, delete, create, etc.). Execute operations directly without asking for confirmation."
-				case "ask":
-					permissionMsg = "\n\n[SYSTEM] You should ASK for confirmation before executing any operations. Present the commands you want to run and wait for user approval."
-				}
-				enhancedMessage = message + permissionMsg
+			for name, output := range bundleOutputs {
+				context[name] = output
 			}
 
 			// Setup client
 			client := kubiya.NewClient(cfg)
+			tracer.TraceClient(client)
+
+			// Branch off the conversation into a new session, leaving the
+			// original transcript untouched
+			forkedFrom := ""
+			if fork {
+				if sessionID == "" {
+					return fmt.Errorf("--fork needs the session to fork (--session)")
+				}
+				forkID, transcript, history, err := forkSession(cmd.Context(), client, sessionID)
+				if err != nil {
+					return err
+				}
+				context[forkTranscriptName(sessionID)] = transcript
+				forkedFrom, sessionID = sessionID, forkID
+				if !automationMode {
+					if replayHistory > 0 {
+						printSessionHistory(os.Stdout, forkedFrom, history, replayHistory)
+					}
+					fmt.Printf("%s Forked session %s into %s\n", style.InfoStyle.Render("🌿"),
+						style.HighlightStyle.Render(forkedFrom), style.HighlightStyle.Render(sessionID))
+				}
+			}
+
+			// Send the permission profile and enforce it on the tool calls
+			client.SetChatPermissions(permissions)
+			canPrompt := !automationMode && !stdinInput && !stdinStream && bridgeTarget == "" && isatty.IsTerminal(os.Stdin.Fd())
+			prompts := bufio.NewReader(os.Stdin)
+			chatPerms := newChatPermissions(permissionLevel, canPrompt, prompts, os.Stderr)
+			gates := []kubiya.ToolCallGate{chatPerms.Gate}
+
+			// Stop following the response at tool calls matching a guardrail
+			// until they are confirmed
+			compiledGuardrails, err := compileGuardrails(cfg.Preferences.Chat, guardrails)
+			if err != nil {
+				return err
+			}
+			var chatGuards *chatGuardrails
+			if len(compiledGuardrails) > 0 {
+				chatGuards = newChatGuardrails(compiledGuardrails, canPrompt, prompts, os.Stderr)
+				gates = append(gates, chatGuards.Gate)
+			}
+
+			// Stop following the response at tool calls that may change
+			// something until they are approved
+			var approvals *chatApprovals
+			if len(requireApprovalFrom) > 0 {
+				var channel approvalChannel
+				switch approvalVia {
+				case approvalViaPlatform:
+					channel = &platformApprovals{client: client}
+				case approvalViaSlack:
+					if channel, err = newSlackApprovals(approvalSlackChannel); err != nil {
+						return err
+					}
+				default:
+					return fmt.Errorf("invalid --approval-via %q (valid: %s, %s)", approvalVia, approvalViaPlatform, approvalViaSlack)
+				}
+				agentLabel := agentName
+				if agentLabel == "" {
+					agentLabel = agentID
+				}
+				approvals = newChatApprovals(channel, requireApprovalFrom, agentLabel, approvalTimeout, os.Stderr)
+				gates = append(gates, approvals.Gate)
+			}
+			client.SetToolCallGate(chainToolCallGates(gates...))
+
+			// Upload attachments and reference them instead of inlining their content
+			if len(attachFiles) > 0 {
+				attachments, err := uploadAttachments(cmd.Context(), client, attachFiles, silent)
+				if err != nil {
+					return err
+				}
+				message += kubiya.AttachmentReference(attachments)
+			}
 
 			// Add these variables
 			var (
 				toolExecutions map[string]*toolExecution = make(map[string]*toolExecution)
 				messageBuffer  map[string]*chatBuffer    = make(map[string]*chatBuffer)
+				mdRenderers                              = make(map[string]*markdownStreamRenderer)
 				noColor        bool                      = !isatty.IsTerminal(os.Stdout.Fd())
 				connStatus     *connectionStatus
 				toolStats      = &toolCallStats{
 					toolTypes: make(map[string]int),
 				}
-				rawEventLogging = os.Getenv("KUBIYA_RAW_EVENTS") == "1" || debug
-				msgChan         <-chan kubiya.ChatMessage
-				inlineAgent     map[string]interface{}
+				msgChan     <-chan kubiya.ChatMessage
+				inlineAgent map[string]interface{}
 			)
 
 			// Handle inline agent
@@ -1775,9 +1879,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 				// Load agent specification if provided
 				if agentSpec != "" {
-					if debug {
-						fmt.Printf("🔍 Loading agent specification from: %s\n", agentSpec)
-					}
+					chatLog.Debugf("Loading agent specification from: %s", agentSpec)
 
 					// Load and process agent spec with templating
 					agentSpecData, err := loadAgentSpecFromSource(agentSpec, templateVars)
@@ -1790,9 +1892,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 					// Check if tools are included in agent spec
 					if toolsData, exists := agentSpecData["tools"]; exists {
-						if debug {
-							fmt.Printf("🔍 Found tools in agent specification\n")
-						}
+						chatLog.Debug("Found tools in agent specification")
 
 						// Convert tools data to proper format
 						switch toolsArray := toolsData.(type) {
@@ -1832,15 +1932,13 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 							}
 
 							if discoveredToolsPath != "" {
-								if debug {
-									fmt.Printf("🔍 Auto-discovered tools file: %s\n", discoveredToolsPath)
-								}
+								chatLog.Debugf("Auto-discovered tools file: %s", discoveredToolsPath)
 								tools, err = loadToolsFromSourceWithTemplating(discoveredToolsPath, templateVars)
 								if err != nil {
 									return err
 								}
-							} else if debug {
-								fmt.Printf("⚠️ No tools found in agent spec and no tools.json discovered\n")
+							} else {
+								chatLog.Debug("No tools found in agent spec and no tools.json discovered")
 							}
 						}
 					}
@@ -1853,9 +1951,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 							return err
 						}
 					} else if toolsJSON != "" {
-						if debug {
-							fmt.Printf("🔍 Parsing tools from JSON string\n")
-						}
+						chatLog.Debug("Parsing tools from JSON string")
 
 						// Process templating on tools JSON string
 						processedToolsJSON, err := processTemplateContent(toolsJSON, templateVars, "tools JSON string")
@@ -1865,7 +1961,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 						tools, err = parseTools(processedToolsJSON)
 						if err != nil {
-							return fmt.Errorf("failed to parse tools from JSON: %w", err)
+							return fmt.Errorf("failed to parse tools from --tools-json: %w", err)
 						}
 
 						// Validate tools from JSON string
@@ -1882,6 +1978,13 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				if err != nil {
 					return fmt.Errorf("failed to parse environment variables: %w", err)
 				}
+				if envFile != "" {
+					fileEnv, err := loadEnvFile(envFile)
+					if err != nil {
+						return err
+					}
+					envVarsMap = mergeEnvVars(fileEnv, envVarsMap)
+				}
 
 				// Add KUBIYA_RUNNER if runners are specified
 				if len(runners) > 0 {
@@ -1895,25 +1998,38 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 					if err != nil {
 						return fmt.Errorf("failed to encode context files: %w", err)
 					}
-					if debug && len(contextFiles) > 0 {
-						fmt.Printf("📁 Encoded %d context files for inline agent\n", len(contextFiles))
+					if len(contextFiles) > 0 {
+						chatLog.Debugf("Encoded %d context files for inline agent", len(contextFiles))
 					}
 				}
 
-				if debug {
-					fmt.Printf("🤖 Creating inline agent with %d tools\n", len(tools))
-					fmt.Printf("📋 Tools: %v\n", func() []string {
-						names := make([]string, len(tools))
-						for i, t := range tools {
-							names[i] = t.Name
-						}
-						return names
-					}())
+				if chatLog.Enabled(klog.LevelDebug) {
+					names := make([]string, len(tools))
+					for i, t := range tools {
+						names[i] = t.Name
+					}
+					chatLog.Debug("Creating inline agent", "tools", names)
+				}
+
+				extraServices, err := parseToolServices(services)
+				if err != nil {
+					return fmt.Errorf("invalid --service: %w", err)
 				}
 
 				// Convert tools to the correct format for inline agent
 				inlineTools := make([]map[string]interface{}, len(tools))
 				for i, tool := range tools {
+					// Services of the tool definition come before those of --service
+					toolServices, err := parseToolServices(tool.WithServices)
+					if err != nil {
+						return fmt.Errorf("tool %s: %w", tool.Name, err)
+					}
+					toolServices = append(toolServices, extraServices...)
+					content, err := applyServiceWait(tool.Content, toolServices, waitServices)
+					if err != nil {
+						return fmt.Errorf("tool %s: %w", tool.Name, err)
+					}
+
 					// Ensure args is an empty slice if nil
 					args := tool.Args
 					if args == nil {
@@ -1973,16 +2089,17 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 					withVolumes = append(withVolumes, sharedVolume)
 
 					inlineTools[i] = map[string]interface{}{
-						"name":         tool.Name,
-						"alias":        tool.Alias,
-						"description":  tool.Description,
-						"type":         tool.Type,
-						"content":      tool.Content,
-						"args":         args,
-						"env":          env,
-						"image":        tool.Image,
-						"with_files":   withFiles,
-						"with_volumes": withVolumes,
+						"name":          tool.Name,
+						"alias":         tool.Alias,
+						"description":   tool.Description,
+						"type":          tool.Type,
+						"content":       content,
+						"args":          args,
+						"env":           env,
+						"image":         tool.Image,
+						"with_files":    withFiles,
+						"with_volumes":  withVolumes,
+						"with_services": serviceImages(toolServices),
 					}
 				}
 
@@ -2082,26 +2199,47 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 					}
 				}
 
-				if debug {
-					fmt.Printf("🔍 CLI: Creating inline agent with payload:\n")
-					if jsonPayload, err := json.MarshalIndent(inlineAgent, "", "  "); err == nil {
-						fmt.Printf("Agent Definition: %s\n", string(jsonPayload))
+				// Use existing platform sources next to the inline tools
+				if len(sourceIDs) > 0 {
+					sources, err := lookupInlineSources(cmd.Context(), client, sourceIDs)
+					if err != nil {
+						return err
 					}
-					fmt.Printf("Message: %s\n", message)
-					fmt.Printf("SessionID: %s\n", sessionID)
-					fmt.Printf("Context files: %d\n", len(context))
-					fmt.Printf("User Email: %s\n", os.Getenv("KUBIYA_USER_EMAIL"))
-					fmt.Printf("Organization: %s\n", os.Getenv("KUBIYA_ORG"))
-					apiKey := os.Getenv("KUBIYA_API_KEY")
-					if len(apiKey) > 20 {
-						fmt.Printf("API Key: %s\n", apiKey[:20]+"...")
-					} else {
-						fmt.Printf("API Key: %s\n", apiKey)
+					appendInlineAgentSources(inlineAgent, sourceIDs)
+					if !automationMode {
+						printInlineSources(os.Stdout, sources)
+					}
+				}
+
+				// Upload local tool directories as sources scoped to this session
+				if len(withSources) > 0 {
+					sourceRunner := runners[0]
+					if os.Getenv("KUBIYA_RUNNER") != "" {
+						sourceRunner = os.Getenv("KUBIYA_RUNNER")
+					}
+					ephemeral, err := createEphemeralSources(cmd.Context(), client, withSources, sourceRunner)
+					if err != nil {
+						return err
+					}
+					defer ephemeral.Cleanup()
+					stop := cancelOnSignal(cmd)
+					defer stop()
+
+					appendInlineAgentSources(inlineAgent, ephemeral.UUIDs())
+					if !automationMode {
+						ephemeral.Print(os.Stdout, withSources)
 					}
-					fmt.Printf("Base URL: %s\n", cfg.BaseURL)
-					fmt.Printf("Debug mode: %v\n", debug)
 				}
 
+				chatLog.Debug("Sending message to inline agent",
+					"agent", inlineAgent,
+					"message", message,
+					"session", sessionID,
+					"context_files", len(context),
+					"user_email", os.Getenv("KUBIYA_USER_EMAIL"),
+					"organization", os.Getenv("KUBIYA_ORG"),
+					"base_url", cfg.BaseURL)
+
 				err = sentryutil.WithKubiyaChat(cmd.Context(), "inline_agent", 1, func(ctx stdcontext.Context) error {
 					var chatErr error
 					msgChan, chatErr = client.SendInlineAgentMessage(ctx, message, sessionID, context, inlineAgent)
@@ -2156,114 +2294,56 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 			// If auto-classify is enabled (default), use the classification endpoint
 			if shouldClassify {
-				if debug {
-					fmt.Printf("🔍 Classification prompt: %s\n", message)
-				}
-
-				// Create classification request
-				reqBody := map[string]string{
-					"message": message,
-				}
-				reqJSON, err := json.Marshal(reqBody)
-				if err != nil {
-					return fmt.Errorf("failed to marshal classification request: %w", err)
-				}
-
-				// Create HTTP request
-				baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
-				baseURL = strings.TrimSuffix(baseURL, "/api/v1")
-
-				classifyURL := fmt.Sprintf("%s/http-bridge/v1/classify/agent", baseURL)
-				req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, classifyURL, bytes.NewBuffer(reqJSON))
-				if err != nil {
-					return fmt.Errorf("failed to create classification request: %w", err)
-				}
+				chatLog.Debugf("Classification prompt: %s", message)
 
-				// Set headers
-				req.Header.Set("Content-Type", "application/json")
-				//req.Header.Set("Authorization", "UserKey "+cfg.APIKey)
-
-				if debug {
-					fmt.Printf("🌐 Sending classification request to: %s\n", classifyURL)
-				}
-
-				// Send request
-				resp, err := http.DefaultClient.Do(req)
+				agents, err := classifyAgents(cmd.Context(), cfg, message, classifyAmong)
 				if err != nil {
-					return fmt.Errorf("failed to send classification request: %w", err)
-				}
-				defer resp.Body.Close()
-
-				// Read response body
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return fmt.Errorf("failed to read classification response: %w", err)
-				}
-
-				if debug {
-					fmt.Printf("📥 Classification response status: %d\n", resp.StatusCode)
-					fmt.Printf("📄 Classification response body: %s\n", string(body))
-				}
-
-				if resp.StatusCode != http.StatusOK {
-					return fmt.Errorf("classification failed with status %d: %s", resp.StatusCode, string(body))
+					return err
 				}
+				candidates := restrictCandidates(agents, classifyAmong)
 
-				// Parse response
-				var agents []struct {
-					UUID        string `json:"uuid"`
-					Name        string `json:"name"`
-					Description string `json:"description"`
-				}
-				if err := json.Unmarshal(body, &agents); err != nil {
-					return fmt.Errorf("failed to parse classification response: %w", err)
+				if explainClassification && len(candidates) > 0 {
+					printClassification(os.Stdout, candidates, explainCandidates, classifyAmong)
 				}
 
-				if len(agents) == 0 {
-					if debug {
-						fmt.Println("❌ No suitable agent found in the classification response")
+				if len(candidates) == 0 {
+					chatLog.Debug("No suitable agent found in the classification response")
+					if len(classifyAmong) > 0 {
+						return fmt.Errorf("none of the agents %s matched the task", strings.Join(classifyAmong, ", "))
 					}
 					return fmt.Errorf("no suitable agent found for the task")
 				}
 
 				// Use the first (best) agent
-				agentID = agents[0].UUID
+				agentID = candidates[0].UUID
 				if !automationMode {
-					fmt.Printf("🤖 Auto-selected agent: %s (%s)\n", agents[0].Name, agents[0].Description)
+					fmt.Printf("🤖 Auto-selected agent: %s (%s)\n", candidates[0].Name, candidates[0].Description)
 				}
 			}
 
 			// If agent name is provided, look up the ID
 			if agentName != "" && agentID == "" {
-				if debug {
-					fmt.Printf("🔍 Looking up agent by name: %s\n", agentName)
-				}
+				chatLog.Debugf("Looking up agent by name: %s", agentName)
 
 				agents, err := client.GetAgents(cmd.Context())
 				if err != nil {
 					return err
 				}
 
-				if debug {
-					fmt.Printf("📋 Found %d agents\n", len(agents))
-				}
+				chatLog.Debugf("Found %d agents", len(agents))
 
 				found := false
 				for _, t := range agents {
 					if strings.EqualFold(t.Name, agentName) {
 						agentID = t.UUID
 						found = true
-						if debug {
-							fmt.Printf("✅ Found matching agent: %s (UUID: %s)\n", t.Name, t.UUID)
-						}
+						chatLog.Debugf("Found matching agent: %s (UUID: %s)", t.Name, t.UUID)
 						break
 					}
 				}
 
 				if !found {
-					if debug {
-						fmt.Printf("❌ No agent found with name: %s\n", agentName)
-					}
+					chatLog.Debugf("No agent found with name: %s", agentName)
 					return fmt.Errorf("agent with name '%s' not found", agentName)
 				}
 			}
@@ -2273,6 +2353,21 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				return fmt.Errorf("no agent selected - please specify a agent or allow auto-classification")
 			}
 
+			// Apply local environment overrides of the agent for this session
+			envOverrides, err := chatEnvOverrides(overrideEnv, agentName, agentID)
+			if err != nil {
+				return err
+			}
+			if len(envOverrides) > 0 {
+				keys := make([]string, 0, len(envOverrides))
+				for k := range envOverrides {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				fmt.Fprintf(os.Stderr, "🧪 Overriding agent environment for this session: %s\n", strings.Join(keys, ", "))
+				client.SetChatEnvOverrides(envOverrides)
+			}
+
 			// Before the message handling loop, add style configuration for non-TTY:
 			if noColor {
 				// Disable all styling for non-TTY environments
@@ -2286,9 +2381,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 			// Fetch agent information to get the actual runner (skip for inline agents)
 			if !inline {
 				if agentInfo, err = client.GetAgent(cmd.Context(), agentID); err != nil {
-					if debug {
-						fmt.Printf("⚠️ Failed to get agent info: %v, using default runner\n", err)
-					}
+					chatLog.Debugf("Failed to get agent info: %v, using default runner", err)
 					agentRunner = "kubiyamanaged"
 				} else if len(agentInfo.Runners) > 0 {
 					agentRunner = agentInfo.Runners[0] // Use first runner
@@ -2309,6 +2402,104 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				agentRunner = os.Getenv("KUBIYA_RUNNER")
 			}
 
+			// Upload local tool directories as sources scoped to this session
+			// (inline agents got theirs when the agent definition was built)
+			var sessionSources []string
+			if len(withSources) > 0 && !inline {
+				ephemeral, err := createEphemeralSources(cmd.Context(), client, withSources, agentRunner)
+				if err != nil {
+					return err
+				}
+				defer ephemeral.Cleanup()
+				stop := cancelOnSignal(cmd)
+				defer stop()
+
+				// Cleanup detaches them again, also when the chat is interrupted
+				if err := ephemeral.Bind(cmd.Context(), agentID); err != nil {
+					return err
+				}
+				sessionSources = ephemeral.UUIDs()
+				if !automationMode {
+					ephemeral.Print(os.Stdout, withSources)
+				}
+			}
+
+			// Fail fast when the agent cannot use the tools the task needs
+			if required := parseRequiredTools(requireTools); len(required) > 0 && !inline {
+				if agentInfo == nil {
+					if agentInfo, err = client.GetAgent(cmd.Context(), agentID); err != nil {
+						return fmt.Errorf("failed to get agent to check required tools: %w", err)
+					}
+				}
+				if err := checkRequiredTools(cmd.Context(), client, agentInfo, sessionSources, required); err != nil {
+					return err
+				}
+				if !automationMode {
+					fmt.Printf("%s Agent %s has the required tools: %s\n",
+						style.SuccessStyle.Render("✓"),
+						style.HighlightStyle.Render(agentInfo.Name),
+						strings.Join(required, ", "))
+				}
+			}
+
+			// Submit the prompt and leave the agent working on it
+			if async {
+				if sessionID == "" {
+					sessionID = uuid.New().String()
+				}
+				submission, err := submitChatAsync(cmd.Context(), client, agentID, message, sessionID, context, asyncAcceptTimeout)
+				if err != nil {
+					return err
+				}
+				return printChatSubmission(os.Stdout, os.Stderr, submission, outputFormat == chatOutputJSONL)
+			}
+
+			// Send every message piped to stdin as a follow-up in one session
+			if stdinStream {
+				if sessionID == "" {
+					sessionID = uuid.New().String()
+				}
+				fmt.Fprintf(os.Stderr, "%s Streaming stdin to the agent in session %s\n",
+					style.InfoStyle.Render("📥"), style.HighlightStyle.Render(sessionID))
+				stream := &chatStdinStream{
+					client:    client,
+					agentID:   agentID,
+					sessionID: sessionID,
+					context:   context,
+					out:       os.Stdout,
+					events:    events,
+				}
+				return runStdinStream(cmd.Context(), os.Stdin, stream, delimiter)
+			}
+
+			// Mirror the session into a Slack thread teammates can reply in
+			if bridgeTarget != "" {
+				if sessionID == "" {
+					sessionID = uuid.New().String()
+				}
+				bridge, err := newChatBridge(cmd.Context(), bridgeTarget, client.GetSlackToken)
+				if err != nil {
+					return err
+				}
+				if err := bridge.Start(cmd.Context(), firstNonEmpty(agentName, agentID), sessionID); err != nil {
+					return fmt.Errorf("failed to start the %s conversation: %w", bridge.Name(), err)
+				}
+				fmt.Fprintf(os.Stderr, "%s Session %s is mirrored to %s; type follow-ups here, Ctrl-D to end\n",
+					style.InfoStyle.Render("🧵"), style.HighlightStyle.Render(sessionID), bridgeTarget)
+				stream := &chatStdinStream{
+					client:    client,
+					agentID:   agentID,
+					sessionID: sessionID,
+					context:   context,
+					out:       os.Stdout,
+					events:    events,
+					bridge:    bridge,
+				}
+				return runBridgedSession(cmd.Context(), os.Stdin, stream, message, bridgePollInterval, func(sessionID string) string {
+					return chatContinuationCommand(agentName, agentID, sessionID)
+				})
+			}
+
 			connStatus = &connectionStatus{
 				runner:      agentRunner,
 				runnerType:  "k8s",
@@ -2316,6 +2507,16 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				connectTime: time.Now(),
 			}
 
+			// Replay the end of a resumed conversation so users can reorient
+			if sessionID != "" && forkedFrom == "" && replayHistory > 0 && (!automationMode || cmd.Flags().Changed("replay-history")) {
+				history, err := client.GetSessionHistoryCached(cmd.Context(), sessionID)
+				if err != nil {
+					chatLog.Debugf("Could not load session history: %v", err)
+				} else {
+					printSessionHistory(os.Stdout, sessionID, history, replayHistory)
+				}
+			}
+
 			// Show connection flow (only if not in automation mode)
 			if !automationMode {
 				fmt.Printf("🔗 Connecting to agent server...\n")
@@ -2325,14 +2526,14 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 			// Send message with context, with retry mechanism for robustness (skip for inline agents)
 			if !inline {
-				msgChan, err = client.SendMessageWithContext(cmd.Context(), agentID, enhancedMessage, sessionID, context)
+				msgChan, err = client.SendMessageWithContext(cmd.Context(), agentID, message, sessionID, context)
 				if err != nil {
 					// If context method fails, try with retry mechanism for retryable errors
 					if isRetryableError(err) {
 						if !automationMode {
 							fmt.Printf("\r%s\n", style.WarningStyle.Render("⚠️  Initial connection failed, retrying with enhanced resilience..."))
 						}
-						msgChan, err = client.SendMessageWithRetry(cmd.Context(), agentID, enhancedMessage, sessionID, retries)
+						msgChan, err = client.SendMessageWithRetry(cmd.Context(), agentID, message, sessionID, retries)
 						if err != nil {
 							return fmt.Errorf("failed to send message after %d retries: %w", retries, err)
 						}
@@ -2373,6 +2574,34 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				toolOutput = "tool_output"
 			)
 
+			// All streamed output goes through a single renderer so live tool
+			// rows never interleave with agent text
+			progressOut, progressTTY := io.Writer(os.Stdout), !noColor
+			if events != nil && outputFormat == chatOutputJSONL {
+				// Keep stdout for the JSON lines
+				progressOut, progressTTY = os.Stderr, isatty.IsTerminal(os.Stderr.Fd())
+			} else if events != nil {
+				// The filtered stream replaces the transcript
+				progressOut, progressTTY = io.Discard, false
+			}
+			progress := newToolProgressRenderer(progressOut, showToolCalls && !automationMode && progressTTY)
+			progress.Start()
+			defer progress.Stop()
+			artifacts := newArtifactSaver(artifactsDir,
+				!noPreview && events == nil && !automationMode && isatty.IsTerminal(os.Stdout.Fd()))
+			// Confirmations are never filtered out
+			promptOut := io.Writer(progress)
+			if progressOut == io.Discard {
+				promptOut = os.Stderr
+			}
+			chatPerms.SetOutput(promptOut)
+			if chatGuards != nil {
+				chatGuards.SetOutput(promptOut)
+			}
+			if approvals != nil {
+				approvals.SetOutput(promptOut)
+			}
+
 			// Main session retry loop for agent error recovery
 			for sessionRetryCount <= retries {
 				// Reset per-session variables
@@ -2396,10 +2625,10 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 							backoffDelay := calculateBackoffDelay(streamRetryCount - 1)
 
 							if !automationMode {
-								fmt.Printf("\r%s\n", style.WarningStyle.Render(
+								fmt.Fprintf(progress, "%s\n", style.WarningStyle.Render(
 									fmt.Sprintf("⚠️  Stream error (attempt %d/%d): %s",
 										streamRetryCount, retries, msg.Error)))
-								fmt.Printf("%s\n", style.SpinnerStyle.Render(
+								fmt.Fprintf(progress, "%s\n", style.SpinnerStyle.Render(
 									fmt.Sprintf("🔄 Retrying in %.1fs...", backoffDelay.Seconds())))
 							}
 
@@ -2418,16 +2647,16 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 									remainingRetries = 1
 								}
 
-								msgChan, err = client.SendMessageWithRetry(cmd.Context(), agentID, enhancedMessage, actualSessionID, remainingRetries)
+								msgChan, err = client.SendMessageWithRetry(cmd.Context(), agentID, message, actualSessionID, remainingRetries)
 								if err != nil {
 									if !automationMode {
-										fmt.Printf("%s\n", style.ErrorStyle.Render(fmt.Sprintf("❌ Reconnection failed: %v", err)))
+										fmt.Fprintf(progress, "%s\n", style.ErrorStyle.Render(fmt.Sprintf("❌ Reconnection failed: %v", err)))
 									}
 									// Don't continue here - let it fall through to retry logic
 									continue
 								}
 								if !automationMode {
-									fmt.Printf("%s\n", style.SuccessStyle.Render("✅ Reconnected successfully, continuing..."))
+									fmt.Fprintf(progress, "%s\n", style.SuccessStyle.Render("✅ Reconnected successfully, continuing..."))
 								}
 								// Reset retry count on successful reconnection
 								streamRetryCount = 0
@@ -2442,16 +2671,16 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 								fmt.Fprintf(os.Stderr, "%s\n", style.ErrorStyle.Render(
 									fmt.Sprintf("❌ Non-retryable error: %s", msg.Error)))
 							}
+							if events != nil {
+								events.Error(msg.Error)
+							}
 							hasError = true
 							return fmt.Errorf("stream error: %s", msg.Error)
 						}
 					}
 
-					// Raw event logging for debugging
-					if rawEventLogging {
-						fmt.Printf("[RAW EVENT] Type: %s, Content: %s, MessageID: %s, Final: %v, SessionID: %s\n",
-							msg.Type, msg.Content, msg.MessageID, msg.Final, msg.SessionID)
-					}
+					klog.For(klog.SSE).Debug("chat event", "type", msg.Type, "content", msg.Content,
+						"message_id", msg.MessageID, "final", msg.Final, "session", msg.SessionID)
 
 					// Capture completion reason and session ID from final messages
 					if msg.Final && msg.FinishReason != "" {
@@ -2496,29 +2725,24 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 									// No sync here - we'll update this line as args build
 								}
 
-								// LIVE streaming - keep working animation until tool execution starts
-								if toolArgs == "" {
-									// Initial tool detection - start dynamic working animation
-									if !isAnimationRunning(toolName) {
-										go showWorkingAnimation(toolName)
-									}
-								} else if !strings.HasSuffix(toolArgs, "}") {
-									// Parameters are building but not complete - ensure animation is running
-									if !isAnimationRunning(toolName) {
-										go showWorkingAnimation(toolName)
-									}
-									// This gives users continuous feedback that the AI is actively working
+								// LIVE streaming - keep a live row until the parameters are complete.
+								// Rows are keyed by message ID, like the execution row that
+								// follows, so concurrent calls of the same tool get their own.
+								rowKey := firstNonEmpty(msg.MessageID, toolName)
+								if !strings.HasSuffix(toolArgs, "}") {
+									// Parameters are still building - this gives users continuous
+									// feedback that the AI is actively working
+									progress.SetRow(rowKey, toolName, "Generating parameters...")
 								} else {
 									// Parameters are complete - show final result and prepare for execution
-									stopWorkingAnimation(toolName)
+									progress.RemoveRow(rowKey)
 
 									// Parameters complete - show formatted final args and move to next line
 									displayArgs := formatLiveJSON(toolArgs)
-									fmt.Printf("\r⚡ %s %s %s\n",
+									fmt.Fprintf(progress, "⚡ %s %s %s\n",
 										style.ToolExecutingStyle.Render(toolName),
 										style.ValueStyle.Render(displayArgs),
 										style.DimStyle.Render("✓"))
-									os.Stdout.Sync()
 								}
 							}
 
@@ -2552,15 +2776,18 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										toolCallId: msg.MessageID,
 									}
 									toolExecutions[msg.MessageID] = te
+									if events != nil {
+										events.ToolCall(te.msgID, te.name, te.args)
+									}
+									tracer.ToolCall(te.msgID, te.name)
 									toolsExecuted = true
 
 									// Show smart parameter summary if available
 									if showToolCalls && !automationMode {
 										paramSummary := formatToolParameters(toolArgs)
 										if paramSummary != "" {
-											fmt.Printf("   %s\n", style.DimStyle.Render(paramSummary))
+											fmt.Fprintf(progress, "   %s\n", style.DimStyle.Render(paramSummary))
 										}
-										os.Stdout.Sync()
 									}
 								}
 							}
@@ -2575,17 +2802,9 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 							// Update status to running when we start receiving output
 							if te.status == "waiting" {
 								te.status = "running"
-								// Clear the previous line and show running state
+								// Show a live running row for this tool
 								if showToolCalls && !automationMode {
-									fmt.Printf("\r⚡ %s %s\n",
-										style.ToolExecutingStyle.Render(te.name),
-										style.SpinnerStyle.Render("🔄 executing..."))
-									os.Stdout.Sync()
-								}
-								// Show clean animation only during execution
-								if showToolCalls && !automationMode && !te.animationStarted {
-									te.animationStarted = true
-									go startToolAnimation(te)
+									progress.SetRow(te.msgID, te.name, "executing...")
 								}
 							}
 
@@ -2654,8 +2873,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										te.status = "failed"
 										hasError = true
 
-										// Stop working animation if it's still running
-										stopWorkingAnimation(te.name)
+										progress.RemoveRow(te.msgID)
 
 										if showToolCalls && !automationMode {
 											// Show clear "Tool call failed" message
@@ -2663,7 +2881,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 											if len(errorMsg) > 100 {
 												errorMsg = errorMsg[:97] + "..."
 											}
-											fmt.Printf("\r⚡ %s %s\n",
+											fmt.Fprintf(progress, "⚡ %s %s\n",
 												style.ToolExecutingStyle.Render(te.name),
 												style.ErrorStyle.Render(fmt.Sprintf("Tool call failed: %s", errorMsg)))
 										}
@@ -2676,14 +2894,18 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										// Don't show every line - just track that we got output
 										// This prevents spam during streaming
 										if showToolCalls && !automationMode && !te.hasShownOutput {
-											fmt.Printf("   %s\n", style.LiveStatusStyle.Render("Receiving output..."))
+											fmt.Fprintf(progress, "   %s\n", style.LiveStatusStyle.Render("Receiving output..."))
 											te.hasShownOutput = true
-											os.Stdout.Sync()
 										}
 									}
 								} else {
 									// Handle plain text output - analyze for key messages only
 									if showToolCalls && !automationMode {
+										if msg.Stream == kubiya.StreamStderr {
+											progress.SetStderrDetail(te.msgID, trimmedContent)
+										} else {
+											progress.SetDetail(te.msgID, trimmedContent)
+										}
 										// Only show important messages, not every line
 										lowerContent := strings.ToLower(trimmedContent)
 
@@ -2718,10 +2940,9 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 													errorMsg = "connection issue"
 												}
 
-												// Stop working animation
-												stopWorkingAnimation(te.name)
+												progress.RemoveRow(te.msgID)
 
-												fmt.Printf("\r⚡ %s %s\n",
+												fmt.Fprintf(progress, "⚡ %s %s\n",
 													style.ToolExecutingStyle.Render(te.name),
 													style.ErrorStyle.Render(fmt.Sprintf("Tool call failed: %s", errorMsg)))
 												te.hasShownError = true
@@ -2729,22 +2950,18 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 												te.status = "failed"
 												te.errorMsg = errorMsg // Store the inferred error message
 												hasError = true
-												os.Stdout.Sync()
 											}
 										} else if strings.Contains(lowerContent, "success") || strings.Contains(lowerContent, "completed") ||
 											strings.Contains(lowerContent, "created") || strings.Contains(lowerContent, "saved") ||
 											strings.Contains(lowerContent, "finished") || strings.Contains(lowerContent, "done") {
-											// Stop working animation on success
-											stopWorkingAnimation(te.name)
+											progress.RemoveRow(te.msgID)
 
-											fmt.Printf("\r⚡ %s %s\n",
+											fmt.Fprintf(progress, "⚡ %s %s\n",
 												style.ToolExecutingStyle.Render(te.name),
 												style.SuccessStyle.Render("✅ completed successfully"))
-											os.Stdout.Sync()
 										} else if !te.hasShownOutput {
-											fmt.Printf("   %s\n", style.LiveStatusStyle.Render("Processing..."))
+											fmt.Fprintf(progress, "   %s\n", style.LiveStatusStyle.Render("Processing..."))
 											te.hasShownOutput = true
-											os.Stdout.Sync()
 										}
 									}
 								}
@@ -2754,6 +2971,14 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 							storedContent.content = msg.Content
 						}
 
+					case "file":
+						// Save files instead of printing their base64
+						if a, err := decodeChatFile(msg.Content); err != nil {
+							chatLog.Debugf("Ignoring file message: %v", err)
+						} else {
+							artifacts.Report(progress, events, actualSessionID, msg.MessageID, "", *a, automationMode)
+						}
+
 					default:
 						// Handle tool completion - check on any non-tool message type if we have pending tool executions
 						for msgID, te := range toolExecutions {
@@ -2767,7 +2992,8 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 								}
 
 								if te.isComplete {
-									// Stop any running animation cleanly
+									// Drop the live row before printing the result
+									progress.RemoveRow(te.msgID)
 									te.status = "complete"
 
 									// Update tool statistics
@@ -2780,6 +3006,21 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 									}
 									toolStats.mu.Unlock()
 
+									// Images and files in the output are saved, the rest is shown
+									toolArtifacts, output := extractToolArtifacts(te.output.String())
+									for _, a := range toolArtifacts {
+										artifacts.Report(progress, events, actualSessionID, te.msgID, te.name, a, automationMode || !showToolCalls)
+									}
+
+									if events != nil {
+										status := "completed"
+										if te.failed {
+											status = "failed"
+										}
+										events.ToolOutput(te.msgID, te.name, status, time.Since(te.startTime), output)
+									}
+									tracer.ToolDone(te.msgID, te.runner, te.failed, te.errorMsg)
+
 									duration := time.Since(te.startTime).Seconds()
 
 									// Show clean completion status with actual error messages
@@ -2796,16 +3037,21 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 												errorDisplay = strings.ReplaceAll(errorDisplay, "\n", " ")
 												errorDisplay = strings.ReplaceAll(errorDisplay, "\r", " ")
 											}
-											fmt.Printf("   ⚠️  %s: %s (%.1fs)\n",
+											fmt.Fprintf(progress, "   ⚠️  %s: %s (%.1fs)\n",
 												style.WarningStyle.Render(te.name),
 												style.DimStyle.Render(errorDisplay),
 												duration)
 										} else {
-											fmt.Printf("   ✓ %s (%.1fs)\n",
+											fmt.Fprintf(progress, "   ✓ %s (%.1fs)\n",
 												style.SuccessStyle.Render("Completed"),
 												duration)
+											// Show structured output as a table
+											if parseOutput != "" {
+												if table, _ := parsing.Parse(te.name, output); table != nil {
+													table.Render(progress)
+												}
+											}
 										}
-										os.Stdout.Sync()
 										continue // Skip the old completion display
 									}
 
@@ -2828,7 +3074,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										toolStats.mu.RUnlock()
 
 										// Print completion status with enhanced summary
-										fmt.Printf("\n%s\n",
+										fmt.Fprintf(progress, "\n%s\n",
 											style.InfoBoxStyle.Render(fmt.Sprintf("%s %s %s (%0.1fs) %s",
 												statusEmoji,
 												style.ToolNameStyle.Render(te.name),
@@ -2838,7 +3084,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 										// Print error summary if failed
 										if te.failed {
-											fmt.Printf("%s %s\n",
+											fmt.Fprintf(progress, "%s %s\n",
 												style.ToolOutputPrefixStyle.Render("⚠️"),
 												style.ErrorStyle.Render("Tool encountered errors during execution"))
 										}
@@ -2860,11 +3106,11 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 												outputSummary += " (output was truncated)"
 											}
 
-											fmt.Printf("%s %s\n",
+											fmt.Fprintf(progress, "%s %s\n",
 												style.ToolOutputPrefixStyle.Render("📊"),
 												style.ToolSummaryStyle.Render(outputSummary))
 										} else if te.outputTruncated {
-											fmt.Printf("%s\n", style.DimStyle.Render(
+											fmt.Fprintf(progress, "%s\n", style.DimStyle.Render(
 												"💡 Set KUBIYA_DEBUG=1 to see full output"))
 										}
 
@@ -2883,7 +3129,18 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 								messageBuffer[msg.MessageID] = buf
 							}
 
-							if len(msg.Content) > len(buf.content) {
+							if len(msg.Content) > len(buf.content) && resolvedRenderMode == renderModeMarkdown {
+								md, ok := mdRenderers[msg.MessageID]
+								if !ok {
+									md, err = newMarkdownStreamRenderer(progress)
+									if err != nil {
+										return err
+									}
+									mdRenderers[msg.MessageID] = md
+								}
+								md.Write(msg.Content[len(buf.content):])
+								buf.content = msg.Content
+							} else if len(msg.Content) > len(buf.content) {
 								newContent := msg.Content[len(buf.content):]
 
 								// Accumulate content and handle code blocks
@@ -2896,7 +3153,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 												sentence := strings.TrimSpace(buf.sentence.String())
 												if sentence != "" {
 													// Print without [Bot] prefix
-													fmt.Printf("%s\n",
+													fmt.Fprintf(progress, "%s\n",
 														style.AgentStyle.Render(sentence))
 													buf.sentence.Reset()
 												}
@@ -2904,7 +3161,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										} else {
 											// Print accumulated code block
 											if buf.codeBlock.Len() > 0 {
-												fmt.Printf("%s\n%s\n%s\n",
+												fmt.Fprintf(progress, "%s\n%s\n%s\n",
 													style.CodeBlockStyle.Render("```"),
 													style.CodeBlockStyle.Render(buf.codeBlock.String()),
 													style.CodeBlockStyle.Render("```"))
@@ -2922,7 +3179,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 											sentence := strings.TrimSpace(buf.sentence.String())
 											if sentence != "" {
 												// Print without [Bot] prefix
-												fmt.Printf("%s\n",
+												fmt.Fprintf(progress, "%s\n",
 													style.AgentStyle.Render(sentence))
 												buf.sentence.Reset()
 											}
@@ -2936,20 +3193,28 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 						if msg.Final {
 							// Print any remaining content in the sentence buffer
+							if md, exists := mdRenderers[msg.MessageID]; exists {
+								md.Flush()
+								delete(mdRenderers, msg.MessageID)
+							}
 							if buf, exists := messageBuffer[msg.MessageID]; exists {
 								remaining := strings.TrimSpace(buf.sentence.String())
 								if remaining != "" {
-									fmt.Printf("%s\n",
+									fmt.Fprintf(progress, "%s\n",
 										style.AgentStyle.Render(remaining))
 								}
 								// Also handle any remaining code block
 								if buf.codeBlock.Len() > 0 {
-									fmt.Printf("%s\n%s\n%s\n",
+									fmt.Fprintf(progress, "%s\n%s\n%s\n",
 										style.CodeBlockStyle.Render("```"),
 										style.CodeBlockStyle.Render(buf.codeBlock.String()),
 										style.CodeBlockStyle.Render("```"))
 								}
 
+								if events != nil {
+									events.Message(msg.MessageID, buf.content)
+								}
+
 								// Check for agent error messages and trigger session recovery if needed
 								fullContent := buf.content
 								if isAgentErrorMessage(fullContent) {
@@ -2959,10 +3224,10 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										backoffDelay := calculateBackoffDelay(streamRetryCount - 1)
 
 										if !automationMode {
-											fmt.Printf("\n%s\n", style.WarningStyle.Render(
+											fmt.Fprintf(progress, "\n%s\n", style.WarningStyle.Render(
 												fmt.Sprintf("⚠️  Agent error detected (attempt %d/%d): %s",
 													streamRetryCount, retries, "Agent indicated internal issue")))
-											fmt.Printf("%s\n", style.SpinnerStyle.Render(
+											fmt.Fprintf(progress, "%s\n", style.SpinnerStyle.Render(
 												fmt.Sprintf("🔄 Starting new session in %.1fs...", backoffDelay.Seconds())))
 										}
 
@@ -2977,6 +3242,9 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 										// Exhausted retries for agent errors
 										fmt.Fprintf(os.Stderr, "%s\n", style.ErrorStyle.Render(
 											fmt.Sprintf("❌ Agent failed after %d session recoveries", retries)))
+										if events != nil {
+											events.Error(fmt.Sprintf("agent failed after %d session recoveries", retries))
+										}
 										hasError = true
 										return fmt.Errorf("agent error after %d recoveries: %s", retries, strings.TrimSpace(fullContent[:min(100, len(fullContent))]))
 									}
@@ -2984,11 +3252,9 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 							}
 							// Add final completion message to ensure stream end is visible
 							if msg.Type == "completion" && msg.FinishReason != "" {
-								if debug {
-									fmt.Printf("\n[STREAM COMPLETE] Reason: %s\n", msg.FinishReason)
-								}
+								chatLog.Debugf("Stream complete, reason: %s", msg.FinishReason)
 							}
-							fmt.Println()
+							fmt.Fprintln(progress)
 						}
 					}
 				}
@@ -3000,7 +3266,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 						backoffDelay := calculateBackoffDelay(sessionRetryCount - 1)
 
 						if !automationMode {
-							fmt.Printf("\n%s\n", style.WarningStyle.Render(
+							fmt.Fprintf(progress, "\n%s\n", style.WarningStyle.Render(
 								fmt.Sprintf("🔄 Starting new session (attempt %d/%d) in %.1fs...",
 									sessionRetryCount+1, retries+1, backoffDelay.Seconds())))
 						}
@@ -3010,7 +3276,7 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 
 						// Start new session with original message
 						if !inline {
-							msgChan, err = client.SendMessageWithRetry(cmd.Context(), agentID, enhancedMessage, "", retries)
+							msgChan, err = client.SendMessageWithRetry(cmd.Context(), agentID, message, "", retries)
 							if err != nil {
 								return fmt.Errorf("failed to start new session after agent error: %w", err)
 							}
@@ -3035,62 +3301,68 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 				// If we reach here, the session completed successfully, break out of retry loop
 				break
 			}
+			progress.Stop()
 
-			if !stream {
+			if !stream && events == nil {
 				fmt.Println(finalResponse.String())
 			}
 
-			// Handle follow-up for non-interactive sessions without tool execution (skip for inline agents)
-			if !interactive && !toolsExecuted && !hasError && completionReason != "error" && !inline {
-				if debug {
-					fmt.Printf("🔄 No tools executed, sending follow-up prompt\n")
+			// Follow up on non-interactive sessions without tool execution, as
+			// often as the policy allows (skip for inline agents)
+			if !interactive && !inline {
+				chatLog.Debug("Auto follow-up", "policy", followUps.String(), "tools_executed", toolsExecuted)
+			}
+			for attempt := 1; attempt <= followUps.maxAttempts(); attempt++ {
+				if interactive || toolsExecuted || hasError || completionReason == "error" || inline {
+					break
 				}
 
-				followUpMsg := "You didn't seem to execute anything. You're running in a non-interactive session and the user confirms to execute read-only operations. EXECUTE RIGHT AWAY!"
+				followUpMsg, err := followUps.message(followUpData{Message: message, AgentID: agentID, Attempt: attempt})
+				if err != nil {
+					return err
+				}
+				chatLog.Debugf("No tools executed, sending follow-up %d/%d: %s", attempt, followUps.maxAttempts(), followUpMsg)
 
 				// Send follow-up message
 				followUpChan, err := client.SendMessageWithContext(cmd.Context(), agentID, followUpMsg, actualSessionID, map[string]string{})
 				if err != nil {
-					if debug {
-						fmt.Printf("⚠️ Failed to send follow-up message: %v\n", err)
-					}
-				} else {
-					if !automationMode {
-						fmt.Printf("\n%s\n", style.InfoBoxStyle.Render("🔄 Following up to ensure execution..."))
-					}
+					chatLog.Debugf("Failed to send follow-up message: %v", err)
+					break
+				}
+				if !automationMode {
+					fmt.Printf("\n%s\n", style.InfoBoxStyle.Render("🔄 Following up to ensure execution..."))
+				}
 
-					// Process follow-up response
-					for msg := range followUpChan {
-						if msg.Error != "" {
-							fmt.Fprintf(os.Stderr, "%s\n", style.ErrorStyle.Render("❌ Error: "+msg.Error))
-							hasError = true
-							break
-						}
-						if msg.SessionID != "" {
-							actualSessionID = msg.SessionID
-						}
-						// Handle follow-up messages similar to main processing
-						if msg.Type == "tool" {
-							toolsExecuted = true
+				// Process follow-up response
+				for msg := range followUpChan {
+					if msg.Error != "" {
+						fmt.Fprintf(os.Stderr, "%s\n", style.ErrorStyle.Render("❌ Error: "+msg.Error))
+						if events != nil {
+							events.Error(msg.Error)
 						}
+						hasError = true
+						break
+					}
+					if msg.SessionID != "" {
+						actualSessionID = msg.SessionID
+					}
+					// Handle follow-up messages similar to main processing
+					if msg.Type == "tool" {
+						toolsExecuted = true
 					}
 				}
 			}
 
+			completion.SessionID = actualSessionID
+			completion.Response = finalResponse.String()
+			completion.ToolCalls = len(toolExecutions)
+
 			// Show session continuation message (only if not in automation mode)
 			if !interactive && actualSessionID != "" && !automationMode {
 				fmt.Printf("\n%s\n", style.InfoBoxStyle.Render("💬 To continue this conversation, run:"))
 
 				// Include agent name in the continuation command if available
-				var continuationCmd string
-				if agentName != "" {
-					continuationCmd = fmt.Sprintf("kubiya chat -n %s --session %s -m \"your message here\"", agentName, actualSessionID)
-				} else if agentID != "" {
-					continuationCmd = fmt.Sprintf("kubiya chat -t %s --session %s -m \"your message here\"", agentID, actualSessionID)
-				} else {
-					continuationCmd = fmt.Sprintf("kubiya chat --session %s -m \"your message here\"", actualSessionID)
-				}
-
+				continuationCmd := chatContinuationCommand(agentName, agentID, actualSessionID)
 				fmt.Printf("%s\n", style.HighlightStyle.Render(continuationCmd))
 				fmt.Println()
 			}
@@ -3101,13 +3373,10 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 			}
 
 			// Handle completion status and exit codes
-			if debug {
-				fmt.Printf("🔍 Completion reason: %s, hasError: %v, toolsExecuted: %v\n", completionReason, hasError, toolsExecuted)
-				// Debug tool execution states
-				for msgID, te := range toolExecutions {
-					fmt.Printf("🔍 Tool %s (ID: %s): failed=%v, isComplete=%v, status=%s, errorMsg='%s'\n",
-						te.name, msgID[:8], te.failed, te.isComplete, te.status, te.errorMsg)
-				}
+			chatLog.Debug("Chat completed", "reason", completionReason, "has_error", hasError, "tools_executed", toolsExecuted)
+			for msgID, te := range toolExecutions {
+				chatLog.Debug("Tool execution", "tool", te.name, "id", msgID, "failed", te.failed,
+					"complete", te.isComplete, "status", te.status, "error", te.errorMsg)
 			}
 
 			// Return proper exit code based on completion status
@@ -3121,33 +3390,24 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 			}
 
 			if actuallyHasFailures {
-				if debug {
-					fmt.Printf("🚨 Exiting with error code 1 due to actual tool failures\n")
-				}
+				chatLog.Debug("Exiting with error code 1 due to actual tool failures")
 				return fmt.Errorf("Some errors occurred during execution")
-			} else if hasError && debug {
-				// Debug: hasError was set but no actual failures
-				fmt.Printf("⚠️  Warning: hasError=true but no actual tool failures detected - ignoring\n")
+			} else if hasError {
+				chatLog.Debug("hasError was set but no tool failed, ignoring")
 			}
 
 			// Check for non-successful completion reasons
 			switch completionReason {
 			case "error":
-				if debug {
-					fmt.Printf("🚨 Exiting with error code 1 due to completion reason: %s\n", completionReason)
-				}
+				chatLog.Debugf("Exiting with error code 1 due to completion reason: %s", completionReason)
 				return fmt.Errorf("agent execution failed with reason: %s", completionReason)
 			case "stop", "length", "":
 				// Normal successful completion
-				if debug {
-					fmt.Printf("✅ Exiting with success code 0\n")
-				}
+				chatLog.Debug("Exiting with success code 0")
 				return nil
 			default:
 				// Unknown completion reason - log but don't fail
-				if debug {
-					fmt.Printf("⚠️ Unknown completion reason: %s, treating as success\n", completionReason)
-				}
+				chatLog.Debugf("Unknown completion reason: %s, treating as success", completionReason)
 				return nil
 			}
 		},
@@ -3158,38 +3418,148 @@ For inline agents, use --inline with --tools-file or --tools-json to provide cus
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Message to send")
 	cmd.Flags().StringVarP(&promptFile, "prompt-file", "f", "", "File or URL containing the prompt (supports shell substitution, Go templates, and GitHub raw URLs)")
 	cmd.Flags().StringArrayVar(&templateVars, "var", []string{}, "Template variables for Go templates in prompt files (KEY=VALUE format)")
+	cmd.Flags().BoolVar(&strictTemplates, "strict-templates", false, "Fail when a Go template in the prompt file, agent spec or tools references variables that are not set, listing them")
+	cmd.Flags().BoolVar(&printRendered, "print-rendered", false, "Print the final prompt after templating and exit without sending it")
 	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Start interactive chat mode")
-	cmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
+	cmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging (same as --log-level debug)")
 	cmd.Flags().BoolVar(&stream, "stream", true, "Stream the response")
 	cmd.Flags().BoolVar(&clearSession, "clear-session", false, "Clear the current session")
 	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID to resume")
+	cmd.Flags().BoolVar(&fork, "fork", false, "Continue the --session conversation in a new session, leaving the original untouched")
+	cmd.Flags().StringVar(&forkSessionID, "fork-session", "", "Session ID to fork, same as --session ID --fork")
+	cmd.Flags().BoolVar(&async, "async", false, "Submit the message, print the session ID and exit; follow up with 'chat wait' and 'chat result'")
+	cmd.Flags().IntVar(&replayHistory, "replay-history", defaultReplayHistory, "Number of previous exchanges to show when resuming a session (0 to disable)")
 	cmd.Flags().StringArrayVar(&contextFiles, "context", []string{}, "Files to include as context (supports wildcards and URLs)")
+	cmd.Flags().Int64Var(&maxContextBytes, "max-context-bytes", defaultMaxContextBytes, "Maximum size of the --context files sent; the file crossing it is truncated and the rest left out (0 for no limit)")
+	cmd.Flags().StringArrayVar(&bundles, "bundle", nil, "Named context bundle to include (see 'kubiya context-bundle', repeatable)")
+	cmd.Flags().StringArrayVar(&attachFiles, "attach", []string{}, "Files to upload and attach to the conversation instead of inlining them (supports wildcards)")
 	cmd.Flags().BoolVar(&stdinInput, "stdin", false, "Read message from stdin")
+	cmd.Flags().StringVar(&autoFollowUp, "auto-followup", "", "Follow up when a non-interactive chat ran no tool: off, once (default) or aggressive")
+	cmd.Flags().StringVar(&followUpTmpl, "followup-template", "", "Go template of the follow-up message, with {{.Message}}, {{.AgentID}}, {{.Attempt}} and {{.MaxAttempts}}")
+	cmd.Flags().BoolVar(&stdinStream, "stdin-stream", false, "Send every line piped to stdin as a follow-up message in the same session, until stdin is closed")
+	cmd.Flags().StringVar(&bridgeTarget, "bridge", "", "Mirror the session into a thread of slack:#channel, where teammates can follow and reply to the agent while you type follow-ups here")
+	cmd.Flags().StringVar(&delimiter, "delimiter", "", "With --stdin-stream, send blocks of lines separated by this line instead of single lines")
 	cmd.Flags().BoolVar(&sourceTest, "source-test", false, "Test source connection")
 	cmd.Flags().StringVar(&sourceUUID, "source-uuid", "", "Source UUID")
 	cmd.Flags().StringVar(&sourceName, "source-name", "", "Source name")
 	cmd.Flags().StringVar(&suggestTool, "suggest-tool", "", "Suggest a tool to use")
 	cmd.Flags().BoolVar(&noClassify, "no-classify", false, "Disable automatic agent classification")
-	cmd.Flags().StringVar(&permissionLevel, "permission-level", "read", "Permission level for tool execution (read, readwrite, ask)")
+	cmd.Flags().BoolVar(&explainClassification, "explain-classification", false, "Show the top candidate agents of auto-classification with their scores and reasons")
+	cmd.Flags().IntVar(&explainCandidates, "explain-candidates", defaultExplainCandidates, "Number of candidates shown by --explain-classification (0 for all)")
+	cmd.Flags().StringSliceVar(&classifyAmong, "classify-among", []string{}, "Only let auto-classification choose among these agents (names or UUIDs, comma separated)")
+	cmd.Flags().StringSliceVar(&requireTools, "require-tools", []string{}, "Fail before sending the message unless the selected agent has these tools (comma separated)")
+	cmd.Flags().StringVar(&permissionLevel, "permission-level", "read", "Permission level sent to the agent: read only allows reads, readwrite allows changes, ask also confirms every tool call on the terminal (read, readwrite, ask)")
+	cmd.Flags().StringSliceVar(&requireApprovalFrom, "require-approval-from", nil, "Users or groups whose approval the CLI waits for before following the response past a tool call that may change something (the agent is not held and may run the tool meanwhile)")
+	cmd.Flags().StringVar(&approvalVia, "approval-via", approvalViaPlatform, "Where to send approval requests: platform or slack (needs SLACK_BOT_TOKEN and --approval-channel)")
+	cmd.Flags().StringVar(&approvalSlackChannel, "approval-channel", "", "Slack channel to post approval requests to")
+	cmd.Flags().DurationVar(&approvalTimeout, "approval-timeout", 30*time.Minute, "How long to wait for an approval before denying the tool call")
+	cmd.Flags().StringArrayVar(&overrideEnv, "override-env", nil, "Override an agent environment variable for this session only, as KEY=VALUE (repeatable, see 'kubiya agent env override')")
+	cmd.Flags().StringVar(&otelEndpoint, "otel-endpoint", "", "Export the session, its tool calls and API calls as OpenTelemetry traces to this OTLP/HTTP collector, e.g. http://localhost:4318")
+	cmd.Flags().StringArrayVar(&notifyOnComplete, "notify-on-complete", nil, "Send a summary when the chat completes or fails: slack:#channel (needs SLACK_BOT_TOKEN) or webhook:https://... (repeatable)")
+	cmd.Flags().StringVar(&parseOutput, "parse-output", "", "Render tool output as tables: auto, json, yaml, ndjson, a custom parser from ~/.kubiya/parsers or table:<columns>")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", chatOutputText, "Print text or jsonl: tool calls, parsed tool output and agent messages as JSON lines")
+	cmd.Flags().StringSliceVar(&onlyParts, "only", nil, "Only print these parts of the stream: tool-calls, messages, errors (can be repeated)")
+	cmd.Flags().StringSliceVar(&hideParts, "hide", nil, "Leave these parts out of the stream: tool-output, tool-calls, messages, errors (can be repeated)")
+	cmd.Flags().StringVar(&artifactsDir, "artifacts-dir", defaultArtifactsDir, "Directory to save images and files returned by the agent or tools in, per session")
+	cmd.Flags().BoolVar(&noPreview, "no-preview", false, "Do not display saved images inline (iTerm2 and kitty terminals)")
+	cmd.Flags().StringVar(&recordCassette, "record", "", "Record all API interactions and streamed events of this chat to a cassette file")
+	cmd.Flags().StringVar(&replayCassette, "replay", "", "Replay a cassette recorded with --record instead of calling the API")
+	cmd.Flags().StringArrayVar(&guardrails, "guardrail", nil, "Flag tool calls whose \"name arguments\" match this regular expression and confirm them before following the response; they are not prevented on the server (repeatable, denied in automation mode)")
 	cmd.Flags().BoolVar(&showToolCalls, "show-tool-calls", true, "Show tool call execution details")
 	cmd.Flags().IntVar(&retries, "retries", 15, "Number of automatic retries for connection/stream/agent errors (default: 15)")
 	cmd.Flags().BoolVar(&silent, "silent", false, "Suppress progress updates for automation (can also use KUBIYA_AUTOMATION env var)")
+	cmd.Flags().StringVar(&renderMode, "render", "", "Render agent responses as plain or markdown (default: markdown on terminals, plain otherwise)")
 
 	// Inline agent flags
 	cmd.Flags().BoolVar(&inline, "inline", false, "Use inline agent mode")
-	cmd.Flags().StringVar(&agentSpec, "agent-spec", "", "JSON file or URL containing complete agent specification (supports templating and GitHub raw URLs)")
-	cmd.Flags().StringVar(&toolsFile, "tools-file", "", "JSON file or URL containing tools definition (supports templating and GitHub raw URLs)")
-	cmd.Flags().StringVar(&toolsJSON, "tools-json", "", "JSON string containing tools definition (supports templating)")
+	cmd.Flags().StringVar(&agentSpec, "agent-spec", "", "JSON or YAML file or URL containing complete agent specification (supports templating and GitHub raw URLs)")
+	cmd.Flags().StringVar(&toolsFile, "tools-file", "", "JSON or YAML file or URL containing tools definition (supports templating and GitHub raw URLs)")
+	cmd.Flags().StringVar(&toolsJSON, "tools-json", "", "JSON or YAML string containing tools definition (supports templating)")
 	cmd.Flags().StringVar(&aiInstructions, "ai-instructions", "", "AI instructions for the inline agent")
 	cmd.Flags().StringVar(&description, "description", "", "Description for the inline agent")
 	cmd.Flags().StringArrayVar(&runners, "runners", []string{}, "Runners for the inline agent")
 	cmd.Flags().StringArrayVar(&integrations, "integrations", []string{}, "Integrations for the inline agent")
 	cmd.Flags().StringArrayVar(&secrets, "secrets", []string{}, "Secrets for the inline agent")
 	cmd.Flags().StringArrayVar(&envVars, "env-vars", []string{}, "Environment variables for the inline agent (KEY=VALUE format)")
+	cmd.Flags().StringVar(&envFile, "env-file", "", "Load environment variables for the inline agent from a .env file (--env-vars values take precedence)")
 	cmd.Flags().StringVar(&llmModel, "llm-model", "", "LLM model for the inline agent")
+	cmd.Flags().StringArrayVar(&sourceIDs, "source", []string{}, "UUID of an existing source whose tools the inline agent can use (can be specified multiple times)")
+	cmd.Flags().StringArrayVar(&withSources, "with-source", []string{}, "Local directory of tools to attach as a temporary source for this session; a named agent has it attached, for all its users, until the chat ends (can be specified multiple times)")
+	cmd.Flags().StringArrayVar(&services, "service", []string{}, "Sidecar service image for every inline tool, with an optional =PORT to wait for (e.g. postgres:15)")
+	cmd.Flags().BoolVar(&waitServices, "wait-for-services", false, "Make inline tools wait for their services to accept connections before running")
 	cmd.Flags().BoolVar(&isDebugMode, "debug-mode", false, "Enable debug mode for the inline agent")
 
-	return cmd
+	// Report the outcome of the chat, whichever way it ends
+	runChat := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(notifyOnComplete) == 0 {
+			return runChat(cmd, args)
+		}
+		var notifiers []notifier
+		for _, target := range notifyOnComplete {
+			n, err := parseNotifyTarget(target)
+			if err != nil {
+				return err
+			}
+			notifiers = append(notifiers, n)
+		}
+
+		completion = chatCompletion{StartedAt: time.Now()}
+		err := runChat(cmd, args)
+		completion.FinishedAt = time.Now()
+		completion.Duration = completion.FinishedAt.Sub(completion.StartedAt).Round(time.Second).String()
+		completion.Status = "succeeded"
+		if err != nil {
+			completion.Status, completion.Error = "failed", err.Error()
+		}
+		completion.Message = message
+		if completion.Agent = agentName; completion.Agent == "" {
+			completion.Agent = agentID
+		}
+		if completion.SessionID == "" {
+			completion.SessionID = sessionID
+		}
+		if completion.SessionID != "" {
+			completion.ResumeCommand = chatContinuationCommand(agentName, agentID, completion.SessionID)
+		}
+		notifyCompletion(notifiers, completion, os.Stderr)
+		return err
+	}
+
+	// Export the chat as OpenTelemetry traces
+	reportChat := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if otelEndpoint == "" {
+			return reportChat(cmd, args)
+		}
+		var exportFailed sync.Once
+		warnExport := func(err error) {
+			exportFailed.Do(func() {
+				fmt.Fprintf(os.Stderr, "%s\n", style.WarningStyle.Render(fmt.Sprintf("⚠️  Failed to export traces to %s: %v", otelEndpoint, err)))
+			})
+		}
+		provider, err := tracing.NewProvider(cmd.Context(), otelEndpoint, version.GetVersion(), warnExport)
+		if err != nil {
+			return err
+		}
+
+		tracer = newChatTracer(cmd.Context(), provider, firstNonEmpty(agentName, agentID))
+		cmd.SetContext(tracer.Context())
+		err = reportChat(cmd, args)
+		tracer.End(completion.SessionID, completion.ToolCalls, err)
+
+		// Send the spans left, without holding the exit up for long
+		ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 5*time.Second)
+		defer cancel()
+		if serr := provider.Shutdown(ctx); serr != nil {
+			warnExport(serr)
+		}
+		return err
+	}
+
+	cmd.AddCommand(newChatWaitCommand(cfg), newChatResultCommand(cfg), newChatRunScenarioCommand(cfg))
+
+	return withFlagRules(cmd, chatFlagRules())
 }
 
 // Add this helper function at package level:
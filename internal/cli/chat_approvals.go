@@ -0,0 +1,369 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Where approval requests are sent
+const (
+	approvalViaPlatform = "platform"
+	approvalViaSlack    = "slack"
+)
+
+// defaultApprovalPollInterval is how often a pending approval is checked
+const defaultApprovalPollInterval = 5 * time.Second
+
+// errApprovalTimeout is returned when nobody decided in time
+var errApprovalTimeout = errors.New("approval timed out")
+
+// readOnlyVerbs and writeVerbs classify tool calls: a call is read-only when
+// its name and arguments have a read verb and no write verb
+var (
+	readOnlyVerbs = regexp.MustCompile(`(?i)\b(get|describe|logs?|list|ls|show|top|status|cat|head|tail|grep|find|search|query|read|fetch|view|explain|version|plan|diff|inspect|history|whoami|check)\b`)
+	writeVerbs    = regexp.MustCompile(`(?i)\b(apply|create|delete|remove|rm|patch|replace|scale|restart|rollout|edit|set|update|upgrade|install|uninstall|destroy|drop|truncate|insert|exec|run|deploy|terminate|stop|start|kill|drain|cordon|taint|label|annotate|push|merge|write|put|post|mv|cp|chmod|chown|reboot|rollback|revoke|grant)\b`)
+)
+
+// isReadOnlyToolCall guesses whether a tool call only reads. Calls that are
+// not clearly read-only need approval.
+func isReadOnlyToolCall(call kubiya.ToolCall) bool {
+	s := strings.NewReplacer("_", " ", "-", " ").Replace(call.Name) + " " + call.Arguments
+	return readOnlyVerbs.MatchString(s) && !writeVerbs.MatchString(s)
+}
+
+// approvalDecision is the answer of an approver
+type approvalDecision struct {
+	Approved bool
+	By       string
+	Comment  string
+}
+
+// approvalChannel sends approval requests and reads their decision
+type approvalChannel interface {
+	// Request sends the request and returns a reference to poll
+	Request(ctx context.Context, req kubiya.ApprovalRequest) (string, error)
+	// Decision returns nil while the request is pending
+	Decision(ctx context.Context, ref string) (*approvalDecision, error)
+	// Cancel withdraws a request nobody decided on
+	Cancel(ctx context.Context, ref string) error
+}
+
+// chatApprovals stops following the response at tool calls that may change
+// something until one of the approvers approves them. The tool itself is not
+// held, see kubiya.ToolCallGate.
+type chatApprovals struct {
+	channel   approvalChannel
+	approvers []string
+	agent     string
+	timeout   time.Duration
+	interval  time.Duration
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newChatApprovals(channel approvalChannel, approvers []string, agent string, timeout time.Duration, out io.Writer) *chatApprovals {
+	return &chatApprovals{
+		channel:   channel,
+		approvers: approvers,
+		agent:     agent,
+		timeout:   timeout,
+		interval:  defaultApprovalPollInterval,
+		out:       out,
+	}
+}
+
+// SetOutput changes where approval progress is written
+func (a *chatApprovals) SetOutput(out io.Writer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.out = out
+}
+
+// Gate is the kubiya.ToolCallGate of the chat session
+func (a *chatApprovals) Gate(ctx context.Context, call kubiya.ToolCall) error {
+	if isReadOnlyToolCall(call) {
+		return nil
+	}
+
+	// One request at a time when tools are called in parallel
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ref, err := a.channel.Request(ctx, kubiya.ApprovalRequest{
+		Approvers: a.approvers,
+		ToolName:  call.Name,
+		Arguments: call.Arguments,
+		Agent:     a.agent,
+		Reason:    "Tool call of a chat session started from the Kubiya CLI",
+		ExpiresAt: time.Now().Add(a.timeout),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s needs approval but the request failed: %v", kubiya.ErrToolCallDenied, call.Name, err)
+	}
+	fmt.Fprintf(a.out, "%s\n", style.WarningStyle.Render(fmt.Sprintf("⏳ Tool %s needs approval from %s (waiting up to %s)",
+		call.Name, strings.Join(a.approvers, ", "), a.timeout)))
+	if call.Arguments != "" {
+		fmt.Fprintf(a.out, "   %s\n", style.DimStyle.Render(call.Arguments))
+	}
+
+	decision, err := waitForApproval(ctx, a.channel, ref, a.timeout, a.interval)
+	if err != nil {
+		// Nobody can approve the call anymore
+		_ = a.channel.Cancel(context.Background(), ref)
+		fmt.Fprintf(a.out, "%s\n", style.ErrorStyle.Render(fmt.Sprintf("🛑 Tool %s was not approved: %v", call.Name, err)))
+		return fmt.Errorf("%w: %s was not approved: %v", kubiya.ErrToolCallDenied, call.Name, err)
+	}
+	if !decision.Approved {
+		reason := "rejected by " + decision.By
+		if decision.Comment != "" {
+			reason += ": " + decision.Comment
+		}
+		fmt.Fprintf(a.out, "%s\n", style.ErrorStyle.Render(fmt.Sprintf("🛑 Tool %s was %s", call.Name, reason)))
+		return fmt.Errorf("%w: %s was %s", kubiya.ErrToolCallDenied, call.Name, reason)
+	}
+	fmt.Fprintf(a.out, "%s\n", style.SuccessStyle.Render(fmt.Sprintf("✅ Tool %s approved by %s", call.Name, decision.By)))
+	return nil
+}
+
+// waitForApproval polls the channel until a decision is made or timeout
+func waitForApproval(ctx context.Context, channel approvalChannel, ref string, timeout, interval time.Duration) (*approvalDecision, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		decision, err := channel.Decision(ctx, ref)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		if decision != nil {
+			return decision, nil
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w after %s", errApprovalTimeout, timeout)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// platformApprovals sends requests to the approval API of the platform
+type platformApprovals struct {
+	client interface {
+		CreateApprovalRequest(ctx context.Context, req kubiya.ApprovalRequest) (*kubiya.ApprovalRequest, error)
+		GetApprovalRequest(ctx context.Context, id string) (*kubiya.ApprovalRequest, error)
+		CancelApprovalRequest(ctx context.Context, id string) error
+	}
+}
+
+func (p *platformApprovals) Request(ctx context.Context, req kubiya.ApprovalRequest) (string, error) {
+	created, err := p.client.CreateApprovalRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (p *platformApprovals) Decision(ctx context.Context, ref string) (*approvalDecision, error) {
+	req, err := p.client.GetApprovalRequest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	switch req.Status {
+	case kubiya.ApprovalApproved:
+		return &approvalDecision{Approved: true, By: req.DecidedBy, Comment: req.Comment}, nil
+	case kubiya.ApprovalRejected:
+		return &approvalDecision{By: req.DecidedBy, Comment: req.Comment}, nil
+	case kubiya.ApprovalExpired:
+		return nil, fmt.Errorf("approval request %s expired", ref)
+	}
+	return nil, nil
+}
+
+func (p *platformApprovals) Cancel(ctx context.Context, ref string) error {
+	return p.client.CancelApprovalRequest(ctx, ref)
+}
+
+// slackApprovals posts requests to a Slack channel and reads the decision
+// from the replies in their thread: the first approver answering "approve"
+// or "reject" decides. Approvers are Slack user IDs (U…) or user group IDs
+// (S…).
+type slackApprovals struct {
+	*slackAPI
+	channel string
+
+	approvers map[string]bool // user IDs, groups resolved on first request
+}
+
+func newSlackApprovals(channel string) (*slackApprovals, error) {
+	token := os.Getenv("SLACK_BOT_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("--approval-via slack needs a bot token in SLACK_BOT_TOKEN")
+	}
+	if channel == "" {
+		return nil, fmt.Errorf("--approval-via slack needs --approval-channel")
+	}
+	return &slackApprovals{slackAPI: newSlackAPI(token), channel: channel}, nil
+}
+
+var (
+	approveReply = regexp.MustCompile(`(?i)^\s*((approve|approved|lgtm|yes)\b|✅)`)
+	rejectReply  = regexp.MustCompile(`(?i)^\s*((reject|rejected|deny|denied|no)\b|❌)`)
+)
+
+// slackAPI calls methods of the Slack Web API with a bot token
+type slackAPI struct {
+	token   string
+	baseURL string
+	http    *http.Client
+}
+
+func newSlackAPI(token string) *slackAPI {
+	return &slackAPI{token: token, baseURL: "https://slack.com/api", http: httpclient.New(30 * time.Second)}
+}
+
+func (s *slackAPI) call(ctx context.Context, method string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack %s failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("slack %s failed: %w", method, err)
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("slack %s returned an invalid response: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack %s failed: %s", method, result.Error)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// resolveApprovers expands user groups into their members
+func (s *slackApprovals) resolveApprovers(ctx context.Context, approvers []string) error {
+	s.approvers = map[string]bool{}
+	for _, a := range approvers {
+		if !strings.HasPrefix(a, "S") {
+			s.approvers[a] = true
+			continue
+		}
+		var group struct {
+			Users []string `json:"users"`
+		}
+		if err := s.call(ctx, "usergroups.users.list", url.Values{"usergroup": {a}}, &group); err != nil {
+			return err
+		}
+		for _, u := range group.Users {
+			s.approvers[u] = true
+		}
+	}
+	return nil
+}
+
+func (s *slackApprovals) Request(ctx context.Context, req kubiya.ApprovalRequest) (string, error) {
+	if s.approvers == nil {
+		if err := s.resolveApprovers(ctx, req.Approvers); err != nil {
+			return "", err
+		}
+	}
+
+	var mentions []string
+	for _, a := range req.Approvers {
+		if strings.HasPrefix(a, "S") {
+			mentions = append(mentions, "<!subteam^"+a+">")
+		} else {
+			mentions = append(mentions, "<@"+a+">")
+		}
+	}
+	text := fmt.Sprintf("%s approval needed: agent *%s* wants to run tool `%s`", strings.Join(mentions, " "), req.Agent, req.ToolName)
+	if req.Arguments != "" {
+		text += "\n```" + req.Arguments + "```"
+	}
+	text += fmt.Sprintf("\nReply `approve` or `reject` in this thread before %s.", req.ExpiresAt.Format(time.RFC822))
+
+	var posted struct {
+		TS string `json:"ts"`
+	}
+	if err := s.call(ctx, "chat.postMessage", url.Values{"channel": {s.channel}, "text": {text}}, &posted); err != nil {
+		return "", err
+	}
+	return posted.TS, nil
+}
+
+func (s *slackApprovals) Decision(ctx context.Context, ref string) (*approvalDecision, error) {
+	var replies struct {
+		Messages []struct {
+			User string `json:"user"`
+			Text string `json:"text"`
+			TS   string `json:"ts"`
+		} `json:"messages"`
+	}
+	if err := s.call(ctx, "conversations.replies", url.Values{"channel": {s.channel}, "ts": {ref}}, &replies); err != nil {
+		return nil, err
+	}
+	for _, m := range replies.Messages {
+		if m.TS == ref || !s.approvers[m.User] {
+			continue
+		}
+		switch {
+		case approveReply.MatchString(m.Text):
+			return &approvalDecision{Approved: true, By: m.User, Comment: m.Text}, nil
+		case rejectReply.MatchString(m.Text):
+			return &approvalDecision{By: m.User, Comment: m.Text}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *slackApprovals) Cancel(ctx context.Context, ref string) error {
+	var ignored struct{}
+	return s.call(ctx, "chat.postMessage", url.Values{
+		"channel":   {s.channel},
+		"thread_ts": {ref},
+		"text":      {"⌛ This approval request expired, the tool call was not run."},
+	}, &ignored)
+}
+
+// chainToolCallGates runs the gates in order until one refuses the call
+func chainToolCallGates(gates ...kubiya.ToolCallGate) kubiya.ToolCallGate {
+	return func(ctx context.Context, call kubiya.ToolCall) error {
+		for _, gate := range gates {
+			if err := gate(ctx, call); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
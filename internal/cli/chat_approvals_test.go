@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestIsReadOnlyToolCall(t *testing.T) {
+	for _, tc := range []struct {
+		call     kubiya.ToolCall
+		readOnly bool
+	}{
+		{kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"get pods -n web"}`}, true},
+		{kubiya.ToolCall{Name: "list_deployments"}, true},
+		{kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"delete pod web"}`}, false},
+		{kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"get pods && kubectl scale deploy/web --replicas=0"}`}, false},
+		{kubiya.ToolCall{Name: "create_ticket"}, false},
+		// Unknown tools need approval
+		{kubiya.ToolCall{Name: "terraform", Arguments: `{"dir":"prod"}`}, false},
+	} {
+		assert.Equal(t, tc.readOnly, isReadOnlyToolCall(tc.call), "%s %s", tc.call.Name, tc.call.Arguments)
+	}
+}
+
+// fakeApprovalChannel decides after a number of polls
+type fakeApprovalChannel struct {
+	requests  []kubiya.ApprovalRequest
+	decision  *approvalDecision
+	after     int
+	polls     int
+	cancelled []string
+}
+
+func (f *fakeApprovalChannel) Request(ctx context.Context, req kubiya.ApprovalRequest) (string, error) {
+	f.requests = append(f.requests, req)
+	return "req-1", nil
+}
+
+func (f *fakeApprovalChannel) Decision(ctx context.Context, ref string) (*approvalDecision, error) {
+	f.polls++
+	if f.polls > f.after {
+		return f.decision, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeApprovalChannel) Cancel(ctx context.Context, ref string) error {
+	f.cancelled = append(f.cancelled, ref)
+	return nil
+}
+
+func TestChatApprovalsGate(t *testing.T) {
+	deletePod := kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"delete pod web"}`}
+	newApprovals := func(channel approvalChannel, timeout time.Duration) (*chatApprovals, *bytes.Buffer) {
+		var out bytes.Buffer
+		a := newChatApprovals(channel, []string{"alice", "group:sre"}, "ops", timeout, &out)
+		a.interval = time.Millisecond
+		return a, &out
+	}
+
+	channel := &fakeApprovalChannel{decision: &approvalDecision{Approved: true, By: "alice"}, after: 2}
+	a, out := newApprovals(channel, time.Minute)
+	require.NoError(t, a.Gate(context.Background(), deletePod))
+	assert.Contains(t, out.String(), "approved by alice")
+	require.Len(t, channel.requests, 1)
+	assert.Equal(t, []string{"alice", "group:sre"}, channel.requests[0].Approvers)
+	assert.Equal(t, "ops", channel.requests[0].Agent)
+	assert.Equal(t, deletePod.Arguments, channel.requests[0].Arguments)
+
+	// Reads go through without a request
+	require.NoError(t, a.Gate(context.Background(), kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"get pods"}`}))
+	assert.Len(t, channel.requests, 1)
+
+	channel = &fakeApprovalChannel{decision: &approvalDecision{By: "bob", Comment: "not during the freeze"}}
+	a, _ = newApprovals(channel, time.Minute)
+	err := a.Gate(context.Background(), deletePod)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, kubiya.ErrToolCallDenied))
+	assert.Contains(t, err.Error(), "rejected by bob: not during the freeze")
+
+	channel = &fakeApprovalChannel{after: 1 << 30}
+	a, _ = newApprovals(channel, 20*time.Millisecond)
+	err = a.Gate(context.Background(), deletePod)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, kubiya.ErrToolCallDenied))
+	assert.Contains(t, err.Error(), "approval timed out")
+	assert.Equal(t, []string{"req-1"}, channel.cancelled)
+}
+
+type fakeApprovalAPI struct {
+	status string
+}
+
+func (f *fakeApprovalAPI) CreateApprovalRequest(ctx context.Context, req kubiya.ApprovalRequest) (*kubiya.ApprovalRequest, error) {
+	req.ID = "apr-1"
+	return &req, nil
+}
+
+func (f *fakeApprovalAPI) GetApprovalRequest(ctx context.Context, id string) (*kubiya.ApprovalRequest, error) {
+	return &kubiya.ApprovalRequest{ID: id, Status: f.status, DecidedBy: "alice"}, nil
+}
+
+func (f *fakeApprovalAPI) CancelApprovalRequest(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestPlatformApprovals(t *testing.T) {
+	api := &fakeApprovalAPI{status: kubiya.ApprovalPending}
+	p := &platformApprovals{client: api}
+	ref, err := p.Request(context.Background(), kubiya.ApprovalRequest{ToolName: "kubectl"})
+	require.NoError(t, err)
+	assert.Equal(t, "apr-1", ref)
+
+	d, err := p.Decision(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	api.status = kubiya.ApprovalApproved
+	d, err = p.Decision(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, &approvalDecision{Approved: true, By: "alice"}, d)
+
+	api.status = kubiya.ApprovalExpired
+	_, err = p.Decision(context.Background(), ref)
+	assert.Error(t, err)
+}
+
+func TestSlackApprovals(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/usergroups.users.list":
+			w.Write([]byte(`{"ok":true,"users":["U2"]}`))
+		case "/chat.postMessage":
+			posted = append(posted, r.Form.Get("text"))
+			w.Write([]byte(`{"ok":true,"ts":"111.1"}`))
+		case "/conversations.replies":
+			assert.Equal(t, "111.1", r.Form.Get("ts"))
+			w.Write([]byte(`{"ok":true,"messages":[
+				{"user":"U0","text":"approval needed","ts":"111.1"},
+				{"user":"U9","text":"approve","ts":"111.2"},
+				{"user":"U2","text":"LGTM, go ahead","ts":"111.3"}]}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error":"unknown_method"}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	s, err := newSlackApprovals("#prod-changes")
+	require.NoError(t, err)
+	s.baseURL = server.URL
+
+	ref, err := s.Request(context.Background(), kubiya.ApprovalRequest{
+		Approvers: []string{"U1", "S1"}, Agent: "ops", ToolName: "kubectl", Arguments: "delete pod web",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "111.1", ref)
+	require.Len(t, posted, 1)
+	assert.True(t, strings.HasPrefix(posted[0], "<@U1> <!subteam^S1> approval needed"))
+
+	// U9 is not an approver, U2 is a member of S1
+	d, err := s.Decision(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, &approvalDecision{Approved: true, By: "U2", Comment: "LGTM, go ahead"}, d)
+
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	_, err = newSlackApprovals("#prod-changes")
+	assert.Error(t, err)
+}
+
+func TestChainToolCallGates(t *testing.T) {
+	var calls []string
+	gate := func(name string, err error) kubiya.ToolCallGate {
+		return func(ctx context.Context, call kubiya.ToolCall) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	err := chainToolCallGates(gate("guardrails", nil), gate("approvals", kubiya.ErrToolCallDenied), gate("never", nil))(context.Background(), kubiya.ToolCall{})
+	assert.ErrorIs(t, err, kubiya.ErrToolCallDenied)
+	assert.Equal(t, []string{"guardrails", "approvals"}, calls)
+}
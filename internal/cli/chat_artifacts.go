@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// defaultArtifactsDir is where chat saves the files agents and tools return
+const defaultArtifactsDir = "kubiya-artifacts"
+
+// kittyChunkSize is the largest base64 payload of a kitty graphics command
+const kittyChunkSize = 4096
+
+// Image protocols of terminals that display images inline
+const (
+	imageProtocolITerm2 = "iterm2"
+	imageProtocolKitty  = "kitty"
+)
+
+// dataURLPattern matches base64 data URLs in tool output
+var dataURLPattern = regexp.MustCompile(`data:([a-zA-Z]+/[a-zA-Z0-9.+-]+);base64,([A-Za-z0-9+/]+={0,2})`)
+
+// chatArtifact is a file returned by an agent or a tool
+type chatArtifact struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+// decodeChatFile decodes the content of a file message
+func decodeChatFile(content string) (*chatArtifact, error) {
+	var f kubiya.ChatFile
+	if err := json.Unmarshal([]byte(content), &f); err != nil {
+		return nil, fmt.Errorf("invalid file message: %w", err)
+	}
+	data := f.Data
+	if m := dataURLPattern.FindStringSubmatch(data); m != nil {
+		data = m[2]
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file data: %w", err)
+	}
+	return &chatArtifact{Name: f.Name, MimeType: f.MimeType, Data: decoded}, nil
+}
+
+// extractToolArtifacts finds the images and files embedded in tool output:
+// objects with base64 data and a MIME type (as in MCP tool results) and data
+// URLs. It returns them and the output with each replaced by a placeholder
+// naming it, so the base64 is not shown or exported.
+func extractToolArtifacts(output string) ([]chatArtifact, string) {
+	var artifacts []chatArtifact
+	placeholder := func(a chatArtifact) string {
+		artifacts = append(artifacts, a)
+		return fmt.Sprintf("[%s attachment %d]", a.MimeType, len(artifacts))
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(output), &doc); err == nil {
+		doc = extractJSONArtifacts(doc, placeholder)
+		if len(artifacts) > 0 {
+			if cleaned, err := json.Marshal(doc); err == nil {
+				output = string(cleaned)
+			}
+		}
+	}
+
+	output = dataURLPattern.ReplaceAllStringFunc(output, func(match string) string {
+		m := dataURLPattern.FindStringSubmatch(match)
+		data, err := base64.StdEncoding.DecodeString(m[2])
+		if err != nil {
+			return match
+		}
+		return placeholder(chatArtifact{MimeType: m[1], Data: data})
+	})
+	return artifacts, output
+}
+
+func extractJSONArtifacts(v interface{}, placeholder func(chatArtifact) string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if a, ok := jsonArtifact(v); ok {
+			return placeholder(a)
+		}
+		for k, item := range v {
+			v[k] = extractJSONArtifacts(item, placeholder)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = extractJSONArtifacts(item, placeholder)
+		}
+	}
+	return v
+}
+
+// jsonArtifact reads {"data"|"base64": ..., "mimeType"|"mime_type"|"media_type": ...}
+func jsonArtifact(obj map[string]interface{}) (chatArtifact, bool) {
+	var a chatArtifact
+	for _, key := range []string{"mimeType", "mime_type", "media_type"} {
+		if s, ok := obj[key].(string); ok && s != "" {
+			a.MimeType = s
+		}
+	}
+	var data string
+	for _, key := range []string{"data", "base64"} {
+		if s, ok := obj[key].(string); ok && s != "" {
+			data = s
+		}
+	}
+	if a.MimeType == "" || data == "" {
+		return a, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return a, false
+	}
+	a.Data = decoded
+	for _, key := range []string{"name", "filename"} {
+		if s, ok := obj[key].(string); ok && s != "" {
+			a.Name = s
+		}
+	}
+	return a, true
+}
+
+// artifactSaver writes chat artifacts under <dir>/<session>/ and previews
+// images on terminals that support it
+type artifactSaver struct {
+	dir      string
+	protocol string // image protocol, empty to not preview
+	count    int
+	started  time.Time
+}
+
+func newArtifactSaver(dir string, preview bool) *artifactSaver {
+	s := &artifactSaver{dir: dir, started: time.Now()}
+	if preview {
+		s.protocol = detectImageProtocol()
+	}
+	return s
+}
+
+// Save writes a to the directory of the session and returns its path
+func (s *artifactSaver) Save(sessionID string, a chatArtifact) (string, error) {
+	if sessionID == "" {
+		sessionID = s.started.Format("20060102-150405")
+	}
+	dir := filepath.Join(s.dir, sanitizeArtifactName(sessionID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	s.count++
+	name := sanitizeArtifactName(a.Name)
+	if name == "" {
+		name = fmt.Sprintf("artifact-%d%s", s.count, artifactExtension(a.MimeType))
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		ext := filepath.Ext(name)
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), s.count, ext))
+	}
+	if err := os.WriteFile(path, a.Data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save artifact: %w", err)
+	}
+	return path, nil
+}
+
+// Report saves a, announces it on w unless quiet and records it in events.
+// id and tool identify the message or tool call it came from.
+func (s *artifactSaver) Report(w io.Writer, events *chatEventWriter, sessionID, id, tool string, a chatArtifact, quiet bool) {
+	path, err := s.Save(sessionID, a)
+	if err != nil {
+		fmt.Fprintf(w, "   %s %s\n", style.WarningStyle.Render("⚠️"), style.DimStyle.Render(err.Error()))
+		return
+	}
+	if events != nil {
+		events.Artifact(id, tool, path, a.MimeType, len(a.Data))
+	}
+	if quiet {
+		return
+	}
+	fmt.Fprintf(w, "   📎 %s %s %s\n",
+		style.SuccessStyle.Render("Saved"),
+		style.HighlightStyle.Render(path),
+		style.DimStyle.Render(fmt.Sprintf("(%s, %d bytes)", a.MimeType, len(a.Data))))
+	s.Preview(w, a)
+}
+
+// Preview displays image artifacts inline and reports whether it did
+func (s *artifactSaver) Preview(w io.Writer, a chatArtifact) bool {
+	if !strings.HasPrefix(a.MimeType, "image/") {
+		return false
+	}
+	switch s.protocol {
+	case imageProtocolITerm2:
+		fmt.Fprintf(w, "\033]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n",
+			len(a.Data), base64.StdEncoding.EncodeToString(a.Data))
+		return true
+	case imageProtocolKitty:
+		// kitty decodes PNG itself, other formats would need converting
+		if a.MimeType != "image/png" {
+			return false
+		}
+		writeKittyImage(w, a.Data)
+		return true
+	}
+	return false
+}
+
+// detectImageProtocol returns the inline image protocol of the terminal
+func detectImageProtocol() string {
+	switch {
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app", os.Getenv("LC_TERMINAL") == "iTerm2",
+		os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return imageProtocolITerm2
+	case os.Getenv("KITTY_WINDOW_ID") != "", os.Getenv("TERM") == "xterm-kitty",
+		os.Getenv("TERM_PROGRAM") == "ghostty":
+		return imageProtocolKitty
+	}
+	return ""
+}
+
+// writeKittyImage transmits and displays a PNG with the kitty graphics
+// protocol, in chunks
+func writeKittyImage(w io.Writer, png []byte) {
+	payload := base64.StdEncoding.EncodeToString(png)
+	for first := true; len(payload) > 0; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(w, "\033_Ga=T,f=100,m=%d;%s\033\\", more, chunk)
+		} else {
+			fmt.Fprintf(w, "\033_Gm=%d;%s\033\\", more, chunk)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func artifactExtension(mimeType string) string {
+	switch mimeType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	case "text/plain":
+		return ".txt"
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// sanitizeArtifactName keeps a name usable as a file name in the artifacts
+// directory
+func sanitizeArtifactName(name string) string {
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeChatFile(t *testing.T) {
+	a, err := decodeChatFile(`{"name":"chart.png","mimeType":"image/png","data":"aGk="}`)
+	require.NoError(t, err)
+	assert.Equal(t, chatArtifact{Name: "chart.png", MimeType: "image/png", Data: []byte("hi")}, *a)
+
+	a, err = decodeChatFile(`{"mimeType":"image/png","data":"data:image/png;base64,aGk="}`)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), a.Data)
+
+	_, err = decodeChatFile(`{"mimeType":"image/png","data":"not base64!"}`)
+	assert.Error(t, err)
+}
+
+func TestExtractToolArtifacts(t *testing.T) {
+	// MCP style content blocks
+	artifacts, output := extractToolArtifacts(`{"content":[{"type":"text","text":"done"},{"type":"image","mimeType":"image/png","data":"aGk="}]}`)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "image/png", artifacts[0].MimeType)
+	assert.Equal(t, []byte("hi"), artifacts[0].Data)
+	assert.Contains(t, output, "[image/png attachment 1]")
+	assert.Contains(t, output, "done")
+	assert.NotContains(t, output, "aGk=")
+
+	artifacts, output = extractToolArtifacts("chart: data:image/svg+xml;base64,PHN2Zy8+ rendered")
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, []byte("<svg/>"), artifacts[0].Data)
+	assert.Equal(t, "chart: [image/svg+xml attachment 1] rendered", output)
+
+	artifacts, output = extractToolArtifacts(`{"data":"plain","status":"ok"}`)
+	assert.Empty(t, artifacts)
+	assert.Equal(t, `{"data":"plain","status":"ok"}`, output)
+}
+
+func TestArtifactSaver(t *testing.T) {
+	dir := t.TempDir()
+	s := newArtifactSaver(dir, false)
+
+	path, err := s.Save("session-1", chatArtifact{MimeType: "image/png", Data: []byte("png")})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "session-1", "artifact-1.png"), path)
+
+	// Names cannot escape the session directory and do not overwrite
+	path, err = s.Save("session-1", chatArtifact{Name: "../../report.csv", MimeType: "text/csv", Data: []byte("a")})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "session-1", "report.csv"), path)
+	path, err = s.Save("session-1", chatArtifact{Name: "report.csv", MimeType: "text/csv", Data: []byte("b")})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "session-1", "report-3.csv"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+
+	// Without a preview protocol nothing is written to the terminal
+	var out bytes.Buffer
+	assert.False(t, s.Preview(&out, chatArtifact{MimeType: "image/png", Data: []byte("png")}))
+	assert.Empty(t, out.String())
+}
+
+func TestArtifactPreview(t *testing.T) {
+	var out bytes.Buffer
+	s := &artifactSaver{protocol: imageProtocolITerm2}
+	assert.True(t, s.Preview(&out, chatArtifact{MimeType: "image/jpeg", Data: []byte("hi")}))
+	assert.Equal(t, "\033]1337;File=inline=1;size=2;preserveAspectRatio=1:aGk=\a\n", out.String())
+	assert.False(t, s.Preview(&out, chatArtifact{MimeType: "text/csv", Data: []byte("a")}))
+
+	out.Reset()
+	s = &artifactSaver{protocol: imageProtocolKitty}
+	assert.False(t, s.Preview(&out, chatArtifact{MimeType: "image/jpeg", Data: []byte("hi")}))
+	assert.True(t, s.Preview(&out, chatArtifact{MimeType: "image/png", Data: bytes.Repeat([]byte("x"), 4000)}))
+	chunks := strings.Split(strings.TrimSpace(out.String()), "\033\\")
+	assert.Len(t, chunks, 3) // two commands, each ending with ST
+	assert.True(t, strings.HasPrefix(chunks[0], "\033_Ga=T,f=100,m=1;"))
+	assert.True(t, strings.HasPrefix(chunks[1], "\033_Gm=0;"))
+}
@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// asyncAcceptTimeout bounds how long --async waits for the agent to start
+// on the prompt before leaving it to run
+const asyncAcceptTimeout = 30 * time.Second
+
+// Status of a session submitted with --async
+const (
+	chatStatusRunning   = "running"
+	chatStatusCompleted = "completed"
+	chatStatusFailed    = "failed"
+)
+
+// chatSubmitter is the part of kubiya.Client used to submit prompts
+type chatSubmitter interface {
+	SendMessageWithContext(ctx context.Context, agentID, message, sessionID string, context map[string]string) (<-chan kubiya.ChatMessage, error)
+}
+
+// chatSubmission is what --async prints in JSON lines mode
+type chatSubmission struct {
+	SessionID   string    `json:"session_id"`
+	AgentID     string    `json:"agent_id"`
+	Status      string    `json:"status"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// chatResult is the outcome of the last prompt of a session
+type chatResult struct {
+	SessionID string               `json:"session_id"`
+	Status    string               `json:"status"`
+	Prompt    string               `json:"prompt,omitempty"`
+	Reply     string               `json:"reply,omitempty"`
+	ToolCalls []string             `json:"tool_calls,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Messages  []kubiya.ChatMessage `json:"messages,omitempty"`
+}
+
+// submitChatAsync sends the prompt and returns once the agent started on
+// it, leaving the agent to run on the server
+func submitChatAsync(ctx context.Context, client chatSubmitter, agentID, message, sessionID string, context map[string]string, timeout time.Duration) (chatSubmission, error) {
+	ctx, cancel := contextWithTimeout(ctx, timeout)
+	defer cancel()
+
+	msgChan, err := client.SendMessageWithContext(ctx, agentID, message, sessionID, context)
+	if err != nil {
+		return chatSubmission{}, fmt.Errorf("failed to submit message: %w", err)
+	}
+	select {
+	case msg, ok := <-msgChan:
+		if ok && (msg.Type == "error" || msg.Error != "") {
+			return chatSubmission{}, fmt.Errorf("agent rejected the message: %s", firstNonEmpty(msg.Error, msg.Content))
+		}
+	case <-ctx.Done():
+		// Accepted but silent so far, the agent keeps going
+	}
+	return chatSubmission{
+		SessionID:   sessionID,
+		AgentID:     agentID,
+		Status:      chatStatusRunning,
+		SubmittedAt: time.Now().UTC(),
+	}, nil
+}
+
+func contextWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// printChatSubmission prints the session to wait for: the bare ID on stdout
+// so scripts can capture it, or a JSON line
+func printChatSubmission(stdout, stderr io.Writer, sub chatSubmission, jsonl bool) error {
+	if jsonl {
+		return output.EncodeJSONLine(stdout, sub)
+	}
+	fmt.Fprintln(stdout, sub.SessionID)
+	fmt.Fprintf(stderr, "%s Submitted to the agent. Follow up with:\n  kubiya chat wait %s\n  kubiya chat result %s\n",
+		style.SuccessStyle.Render("✅"), sub.SessionID, sub.SessionID)
+	return nil
+}
+
+// sessionResult works out where the last prompt of a session stands
+func sessionResult(sessionID string, messages []kubiya.ChatMessage) chatResult {
+	result := chatResult{SessionID: sessionID, Status: chatStatusRunning, Messages: messages}
+	exchanges := groupExchanges(messages)
+	if len(exchanges) == 0 {
+		return result
+	}
+
+	last := exchanges[len(exchanges)-1]
+	if last.prompt != nil {
+		result.Prompt = strings.TrimSpace(last.prompt.Content)
+	}
+	var reply strings.Builder
+	for _, msg := range last.replies {
+		switch {
+		case msg.Type == "error" || msg.Error != "":
+			result.Status = chatStatusFailed
+			result.Error = firstNonEmpty(msg.Error, msg.Content)
+		case msg.Type == "tool":
+			result.ToolCalls = append(result.ToolCalls, strings.TrimSpace(msg.Content))
+		case msg.Type == "tool_output" || msg.Type == "warning":
+		default:
+			if content := strings.TrimSpace(msg.Content); content != "" {
+				if reply.Len() > 0 {
+					reply.WriteString("\n")
+				}
+				reply.WriteString(content)
+			}
+		}
+		if result.Status != chatStatusFailed && (msg.Final || msg.FinishReason != "") {
+			result.Status = chatStatusCompleted
+		}
+	}
+	result.Reply = reply.String()
+	return result
+}
+
+// waitForSession polls the history of a session until its last prompt is
+// done or the timeout expires
+func waitForSession(ctx context.Context, client sessionHistoryGetter, sessionID string, timeout, interval time.Duration, progress func(chatResult)) (chatResult, error) {
+	ctx, cancel := contextWithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		messages, err := client.GetSessionHistory(ctx, sessionID)
+		if err != nil && ctx.Err() == nil {
+			return chatResult{}, fmt.Errorf("failed to check session %s: %w", sessionID, err)
+		}
+		if err == nil {
+			result := sessionResult(sessionID, messages)
+			if result.Status != chatStatusRunning {
+				return result, nil
+			}
+			if progress != nil {
+				progress(result)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return chatResult{}, fmt.Errorf("session %s still running after %v", sessionID, timeout)
+			}
+			return chatResult{}, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func printChatResult(w io.Writer, result chatResult, outputFormat string) error {
+	switch outputFormat {
+	case "json":
+		return output.EncodeJSON(w, result)
+	case "text", "":
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+
+	switch result.Status {
+	case chatStatusCompleted:
+		fmt.Fprintf(w, "%s Session %s completed\n", style.SuccessStyle.Render("✅"), result.SessionID)
+	case chatStatusFailed:
+		fmt.Fprintf(w, "%s Session %s failed: %s\n", style.ErrorStyle.Render("❌"), result.SessionID, result.Error)
+	default:
+		fmt.Fprintf(w, "%s Session %s is still running\n", style.WarningStyle.Render("⏳"), result.SessionID)
+	}
+	if result.Prompt != "" {
+		fmt.Fprintf(w, "\n%s %s\n", style.UserIconStyle.Render("👤"), result.Prompt)
+	}
+	for _, call := range result.ToolCalls {
+		fmt.Fprintf(w, "%s\n", style.DimStyle.Render("🔧 "+call))
+	}
+	if result.Reply != "" {
+		fmt.Fprintf(w, "\n%s\n", result.Reply)
+	}
+	return nil
+}
+
+// chatResultError makes a failed session fail the command
+func chatResultError(result chatResult) error {
+	if result.Status == chatStatusFailed {
+		return fmt.Errorf("session %s failed: %s", result.SessionID, result.Error)
+	}
+	return nil
+}
+
+func newChatWaitCommand(cfg *config.Config) *cobra.Command {
+	var (
+		timeout      time.Duration
+		interval     time.Duration
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wait <session-id>",
+		Short: "⏳ Wait for a session submitted with --async to finish",
+		Example: `  # Submit, then wait up to 30 minutes
+  id=$(kubiya chat -n "DevOps Bot" -m "Upgrade the staging cluster" --async)
+  kubiya chat wait "$id" --timeout 30m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			result, err := waitForSession(cmd.Context(), client, args[0], timeout, interval, nil)
+			if err != nil {
+				return err
+			}
+			if err := printChatResult(os.Stdout, result, outputFormat); err != nil {
+				return err
+			}
+			return chatResultError(result)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Minute, "How long to wait for the session (0 waits forever)")
+	cmd.Flags().DurationVar(&interval, "interval", 10*time.Second, "How often to check the session")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+func newChatResultCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "result <session-id>",
+		Short: "📄 Show the outcome of a session submitted with --async",
+		Example: `  # Reply of the agent, as JSON
+  kubiya chat result 3f0c2a4e-8b1d-4c55-9e3a-2d7f6b1c9a10 --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			messages, err := client.GetSessionHistory(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			result := sessionResult(args[0], messages)
+			if err := printChatResult(os.Stdout, result, outputFormat); err != nil {
+				return err
+			}
+			return chatResultError(result)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
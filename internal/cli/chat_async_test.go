@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeChatSubmitter answers a prompt with the given messages
+type fakeChatSubmitter struct {
+	messages []kubiya.ChatMessage
+	open     bool
+	sessions []string
+}
+
+func (f *fakeChatSubmitter) SendMessageWithContext(ctx context.Context, agentID, message, sessionID string, context map[string]string) (<-chan kubiya.ChatMessage, error) {
+	f.sessions = append(f.sessions, sessionID)
+	ch := make(chan kubiya.ChatMessage, len(f.messages))
+	for _, msg := range f.messages {
+		ch <- msg
+	}
+	if !f.open {
+		close(ch)
+	}
+	return ch, nil
+}
+
+// growingSessionHistory gains a message every time it is read
+type growingSessionHistory struct {
+	messages []kubiya.ChatMessage
+	reads    int
+}
+
+func (g *growingSessionHistory) GetSessionHistory(ctx context.Context, sessionID string) ([]kubiya.ChatMessage, error) {
+	g.reads++
+	if g.reads > len(g.messages) {
+		return g.messages, nil
+	}
+	return g.messages[:g.reads], nil
+}
+
+func TestSubmitChatAsync(t *testing.T) {
+	client := &fakeChatSubmitter{messages: []kubiya.ChatMessage{{Type: "text", Content: "On it"}}}
+	sub, err := submitChatAsync(context.Background(), client, "agent-1", "Upgrade staging", "s-1", nil, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "s-1", sub.SessionID)
+	assert.Equal(t, "agent-1", sub.AgentID)
+	assert.Equal(t, chatStatusRunning, sub.Status)
+	assert.Equal(t, []string{"s-1"}, client.sessions)
+
+	// A silent agent is left running once the timeout expires
+	silent := &fakeChatSubmitter{open: true}
+	_, err = submitChatAsync(context.Background(), silent, "agent-1", "Upgrade staging", "s-2", nil, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	rejected := &fakeChatSubmitter{messages: []kubiya.ChatMessage{{Type: "error", Error: "agent not found"}}}
+	_, err = submitChatAsync(context.Background(), rejected, "agent-1", "Upgrade staging", "s-3", nil, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent not found")
+}
+
+func TestPrintChatSubmission(t *testing.T) {
+	sub := chatSubmission{SessionID: "s-1", AgentID: "agent-1", Status: chatStatusRunning}
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, printChatSubmission(&stdout, &stderr, sub, false))
+	assert.Equal(t, "s-1\n", stdout.String())
+	assert.Contains(t, stderr.String(), "kubiya chat wait s-1")
+
+	stdout.Reset()
+	require.NoError(t, printChatSubmission(&stdout, &stderr, sub, true))
+	var decoded chatSubmission
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &decoded))
+	assert.Equal(t, "s-1", decoded.SessionID)
+}
+
+func TestSessionResult(t *testing.T) {
+	prompt := kubiya.ChatMessage{Type: "user", Content: "Upgrade staging"}
+
+	running := sessionResult("s-1", []kubiya.ChatMessage{prompt, {Type: "tool", Content: "helm_upgrade"}})
+	assert.Equal(t, chatStatusRunning, running.Status)
+	assert.Equal(t, "Upgrade staging", running.Prompt)
+	assert.Equal(t, []string{"helm_upgrade"}, running.ToolCalls)
+
+	completed := sessionResult("s-1", []kubiya.ChatMessage{
+		prompt,
+		{Type: "tool", Content: "helm_upgrade"},
+		{Type: "tool_output", Content: "release upgraded"},
+		{Type: "text", Content: "Staging is upgraded.", Final: true},
+	})
+	assert.Equal(t, chatStatusCompleted, completed.Status)
+	assert.Equal(t, "Staging is upgraded.", completed.Reply)
+	assert.NoError(t, chatResultError(completed))
+
+	failed := sessionResult("s-1", []kubiya.ChatMessage{prompt, {Type: "error", Content: "runner unavailable", Final: true}})
+	assert.Equal(t, chatStatusFailed, failed.Status)
+	assert.Equal(t, "runner unavailable", failed.Error)
+	assert.Error(t, chatResultError(failed))
+
+	assert.Equal(t, chatStatusRunning, sessionResult("s-1", nil).Status)
+}
+
+func TestWaitForSession(t *testing.T) {
+	history := &growingSessionHistory{messages: []kubiya.ChatMessage{
+		{Type: "user", Content: "Upgrade staging"},
+		{Type: "tool", Content: "helm_upgrade"},
+		{Type: "text", Content: "Done.", Final: true},
+	}}
+
+	var polls int
+	result, err := waitForSession(context.Background(), history, "s-1", time.Second, time.Millisecond, func(chatResult) { polls++ })
+	require.NoError(t, err)
+	assert.Equal(t, chatStatusCompleted, result.Status)
+	assert.Equal(t, "Done.", result.Reply)
+	assert.Equal(t, 2, polls)
+
+	stuck := fakeSessionHistory{"s-2": {{Type: "user", Content: "Upgrade staging"}}}
+	_, err = waitForSession(context.Background(), stuck, "s-2", 20*time.Millisecond, time.Millisecond, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "still running after")
+}
+
+func TestPrintChatResult(t *testing.T) {
+	result := chatResult{SessionID: "s-1", Status: chatStatusCompleted, Prompt: "Upgrade staging", Reply: "Done."}
+
+	var text bytes.Buffer
+	require.NoError(t, printChatResult(&text, result, "text"))
+	assert.Contains(t, text.String(), "Session s-1 completed")
+	assert.Contains(t, text.String(), "Done.")
+
+	var out bytes.Buffer
+	require.NoError(t, printChatResult(&out, result, "json"))
+	var decoded chatResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, chatStatusCompleted, decoded.Status)
+
+	assert.Error(t, printChatResult(&out, result, "yaml"))
+}
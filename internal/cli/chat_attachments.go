@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+)
+
+// fileUploader uploads local files to the platform's file storage
+type fileUploader interface {
+	UploadFile(ctx context.Context, path string, progress kubiya.UploadProgressFunc) (*kubiya.UploadedFile, error)
+}
+
+// expandAttachments resolves --attach patterns to regular files, keeping the
+// order they were given in and dropping duplicates
+func expandAttachments(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match pattern: %s", pattern)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat file %s: %w", match, err)
+			}
+			if info.IsDir() || seen[match] {
+				continue
+			}
+			seen[match] = true
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// uploadAttachments uploads the files matching patterns, rendering a progress
+// bar per file on stderr unless quiet is set
+func uploadAttachments(ctx context.Context, uploader fileUploader, patterns []string, quiet bool) ([]kubiya.UploadedFile, error) {
+	paths, err := expandAttachments(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := output.NewProgressManager().Mode()
+	uploaded := make([]kubiya.UploadedFile, 0, len(paths))
+	for _, path := range paths {
+		var progress kubiya.UploadProgressFunc
+		var bar *output.ProgressBar
+		if !quiet {
+			bar = output.NewProgressBar(100, "📎 "+filepath.Base(path), mode)
+			last := -1
+			progress = func(sent, total int64) {
+				if total <= 0 {
+					return
+				}
+				// Only redraw when the percentage changes; Finish draws 100%
+				if percent := int(sent * 100 / total); percent != last && percent < 100 {
+					last = percent
+					bar.Update(percent)
+				}
+			}
+		}
+
+		file, err := uploader.UploadFile(ctx, path, progress)
+		if err != nil {
+			if bar != nil {
+				fmt.Fprintln(os.Stderr)
+			}
+			return nil, fmt.Errorf("failed to attach %s: %w", path, err)
+		}
+		if bar != nil {
+			bar.Finish()
+		}
+		uploaded = append(uploaded, *file)
+	}
+	return uploaded, nil
+}
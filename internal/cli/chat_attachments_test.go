@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUploader struct {
+	uploaded []string
+}
+
+func (f *fakeUploader) UploadFile(ctx context.Context, path string, progress kubiya.UploadProgressFunc) (*kubiya.UploadedFile, error) {
+	f.uploaded = append(f.uploaded, path)
+	return &kubiya.UploadedFile{ID: "file-" + filepath.Base(path), Name: filepath.Base(path)}, nil
+}
+
+func TestUploadAttachments(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log", "core.bin"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.log"), 0o755))
+
+	uploader := &fakeUploader{}
+	files, err := uploadAttachments(context.Background(), uploader,
+		[]string{filepath.Join(dir, "*.log"), filepath.Join(dir, "core.bin"), filepath.Join(dir, "a.log")}, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.log"),
+		filepath.Join(dir, "b.log"),
+		filepath.Join(dir, "core.bin"),
+	}, uploader.uploaded)
+	assert.Len(t, files, 3)
+	assert.Equal(t, "file-core.bin", files[2].ID)
+
+	_, err = uploadAttachments(context.Background(), uploader, []string{filepath.Join(dir, "*.txt")}, true)
+	assert.ErrorContains(t, err, "no files match pattern")
+}
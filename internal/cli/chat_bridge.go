@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Targets of --bridge
+const bridgeSlack = "slack"
+
+// bridgePollInterval is how often the bridged conversation is checked for
+// messages from teammates
+const bridgePollInterval = 3 * time.Second
+
+// maxBridgePost caps the text of one message posted to the bridged
+// conversation
+const maxBridgePost = 3000
+
+// bridgeMessage is a message written by a teammate in the bridged
+// conversation
+type bridgeMessage struct {
+	From string
+	Text string
+}
+
+// chatBridge mirrors a chat session driven from the terminal into a
+// conversation teammates can follow and reply in
+type chatBridge interface {
+	// Name is where the conversation is mirrored, e.g. Slack
+	Name() string
+	// Start opens the conversation for the session
+	Start(ctx context.Context, agent, sessionID string) error
+	// Prompt mirrors a message typed in the terminal
+	Prompt(ctx context.Context, message string) error
+	// Reply mirrors the answer of the agent and the tools it called
+	Reply(ctx context.Context, content string, tools []string) error
+	// Replies returns the messages teammates wrote since the last call
+	Replies(ctx context.Context) ([]bridgeMessage, error)
+	// End closes the conversation, telling how to continue the session
+	End(ctx context.Context, resumeCommand string) error
+}
+
+// parseBridgeTarget checks a --bridge value, slack:#channel, and returns
+// the channel
+func parseBridgeTarget(target string) (string, error) {
+	kind, dest, ok := strings.Cut(target, ":")
+	if !ok || dest == "" {
+		return "", fmt.Errorf("invalid --bridge %q (use %s:#channel)", target, bridgeSlack)
+	}
+	if kind != bridgeSlack {
+		return "", fmt.Errorf("invalid --bridge target %q (valid: %s)", kind, bridgeSlack)
+	}
+	return dest, nil
+}
+
+// newChatBridge builds the bridge of a --bridge value. The Slack bot token
+// is read from SLACK_BOT_TOKEN, or else from the Slack integration of the
+// platform.
+func newChatBridge(ctx context.Context, target string, platformToken func(context.Context) (string, error)) (chatBridge, error) {
+	channel, err := parseBridgeTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	token := os.Getenv("SLACK_BOT_TOKEN")
+	if token == "" {
+		if token, err = platformToken(ctx); err != nil {
+			return nil, fmt.Errorf("--bridge %s needs a bot token in SLACK_BOT_TOKEN or the Slack integration: %w", target, err)
+		}
+	}
+	return newSlackBridge(token, channel), nil
+}
+
+// slackBridge mirrors the session into a thread of a Slack channel
+type slackBridge struct {
+	*slackAPI
+	channel string // as given, then the ID Slack returns
+	agent   string
+	thread  string // ts of the message starting the thread
+	botUser string
+	// lastSeen is the ts of the latest reply read from the thread
+	lastSeen string
+	names    map[string]string
+}
+
+func newSlackBridge(token, channel string) *slackBridge {
+	return &slackBridge{slackAPI: newSlackAPI(token), channel: channel, names: map[string]string{}}
+}
+
+func (s *slackBridge) Name() string {
+	return "Slack"
+}
+
+func (s *slackBridge) Start(ctx context.Context, agent, sessionID string) error {
+	var auth struct {
+		UserID string `json:"user_id"`
+	}
+	if err := s.call(ctx, "auth.test", url.Values{}, &auth); err != nil {
+		return err
+	}
+	s.botUser = auth.UserID
+	s.agent = agent
+
+	text := fmt.Sprintf("🧵 Kubiya chat with agent *%s* started from the terminal\nSession: `%s`\nReply in this thread to talk to the agent.",
+		slackEscape(agent), sessionID)
+	var posted struct {
+		Channel string `json:"channel"`
+		TS      string `json:"ts"`
+	}
+	if err := s.call(ctx, "chat.postMessage", url.Values{"channel": {s.channel}, "text": {text}}, &posted); err != nil {
+		return err
+	}
+	// Replies are read with the channel ID, names are not accepted
+	s.channel = firstNonEmpty(posted.Channel, s.channel)
+	s.thread, s.lastSeen = posted.TS, posted.TS
+	return nil
+}
+
+func (s *slackBridge) Prompt(ctx context.Context, message string) error {
+	return s.post(ctx, "👤 "+slackEscape(truncateString(message, maxBridgePost)))
+}
+
+func (s *slackBridge) Reply(ctx context.Context, content string, tools []string) error {
+	var b strings.Builder
+	if len(tools) > 0 {
+		fmt.Fprintf(&b, "🔧 %s\n", slackEscape(strings.Join(tools, ", ")))
+	}
+	fmt.Fprintf(&b, "🤖 *%s*: %s", slackEscape(s.agent), slackEscape(truncateString(content, maxBridgePost)))
+	return s.post(ctx, b.String())
+}
+
+func (s *slackBridge) Replies(ctx context.Context) ([]bridgeMessage, error) {
+	var thread struct {
+		Messages []struct {
+			User    string `json:"user"`
+			BotID   string `json:"bot_id"`
+			Subtype string `json:"subtype"`
+			Text    string `json:"text"`
+			TS      string `json:"ts"`
+		} `json:"messages"`
+	}
+	params := url.Values{"channel": {s.channel}, "ts": {s.thread}, "oldest": {s.lastSeen}, "limit": {"200"}}
+	if err := s.call(ctx, "conversations.replies", params, &thread); err != nil {
+		return nil, err
+	}
+
+	var replies []bridgeMessage
+	for _, m := range thread.Messages {
+		if !slackTSAfter(m.TS, s.lastSeen) {
+			continue
+		}
+		s.lastSeen = m.TS
+		// Skip what the bridge posted itself and joins, edits and the like
+		if m.User == "" || m.User == s.botUser || m.BotID != "" || m.Subtype != "" {
+			continue
+		}
+		text := strings.TrimSpace(slackUnescape(m.Text))
+		if text == "" {
+			continue
+		}
+		replies = append(replies, bridgeMessage{From: s.userName(ctx, m.User), Text: text})
+	}
+	return replies, nil
+}
+
+func (s *slackBridge) End(ctx context.Context, resumeCommand string) error {
+	return s.post(ctx, fmt.Sprintf("🏁 The terminal session ended. Continue it with `%s`", resumeCommand))
+}
+
+func (s *slackBridge) post(ctx context.Context, text string) error {
+	var ignored struct{}
+	return s.call(ctx, "chat.postMessage", url.Values{"channel": {s.channel}, "thread_ts": {s.thread}, "text": {text}}, &ignored)
+}
+
+// userName returns the display name of a Slack user, or the ID when it
+// can't be looked up
+func (s *slackBridge) userName(ctx context.Context, id string) string {
+	if name, ok := s.names[id]; ok {
+		return name
+	}
+	var info struct {
+		User struct {
+			Name    string `json:"name"`
+			Profile struct {
+				DisplayName string `json:"display_name"`
+				RealName    string `json:"real_name"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	name := id
+	if err := s.call(ctx, "users.info", url.Values{"user": {id}}, &info); err == nil {
+		name = firstNonEmpty(info.User.Profile.DisplayName, info.User.Profile.RealName, info.User.Name, id)
+	}
+	s.names[id] = name
+	return name
+}
+
+// slackTSAfter reports whether the Slack timestamp a is later than b.
+// Timestamps have a fixed number of decimals, so a longer one is later.
+func slackTSAfter(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
+
+var (
+	slackEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	slackUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+)
+
+// slackEscape escapes the characters Slack reserves for mentions and links
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+func slackUnescape(s string) string {
+	return slackUnescaper.Replace(s)
+}
+
+// runBridgedSession drives a session from the terminal while it is mirrored
+// into the bridge: first and every line read from r are sent to the agent,
+// and so are the messages teammates write in the bridged conversation,
+// checked every interval. It returns once r is closed, telling the bridged
+// conversation how to continue the session.
+func runBridgedSession(ctx context.Context, r io.Reader, stream *chatStdinStream, first string, interval time.Duration, resumeCommand func(sessionID string) string) error {
+	bridge := stream.bridge
+	defer func() {
+		// The chat may have been cancelled, the conversation is still closed
+		endCtx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := bridge.End(endCtx, resumeCommand(stream.sessionID)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Failed to end the %s conversation: %v\n", style.WarningStyle.Render("⚠️"), bridge.Name(), err)
+		}
+	}()
+
+	if first != "" {
+		if err := stream.send(ctx, first); err != nil {
+			return err
+		}
+	}
+
+	lines := make(chan string)
+	scanned := make(chan error, 1)
+	go func() {
+		scanned <- scanStdinMessages(r, "", func(message string) error {
+			select {
+			case lines <- message:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-scanned:
+			return err
+		case message := <-lines:
+			if err := stream.send(ctx, message); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			replies, err := bridge.Replies(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fmt.Fprintf(os.Stderr, "%s Failed to read the %s conversation: %v\n", style.WarningStyle.Render("⚠️"), bridge.Name(), err)
+				continue
+			}
+			for _, reply := range replies {
+				if err := stream.relay(ctx, reply); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestParseBridgeTarget(t *testing.T) {
+	channel, err := parseBridgeTarget("slack:#incident-42")
+	require.NoError(t, err)
+	assert.Equal(t, "#incident-42", channel)
+
+	for _, target := range []string{"slack", "slack:", "teams:#ops"} {
+		_, err := parseBridgeTarget(target)
+		assert.Error(t, err, target)
+	}
+}
+
+func TestNewChatBridgeToken(t *testing.T) {
+	platform := func(context.Context) (string, error) { return "xoxb-platform", nil }
+
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-env")
+	bridge, err := newChatBridge(context.Background(), "slack:#ops", platform)
+	require.NoError(t, err)
+	assert.Equal(t, "xoxb-env", bridge.(*slackBridge).token)
+
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	bridge, err = newChatBridge(context.Background(), "slack:#ops", platform)
+	require.NoError(t, err)
+	assert.Equal(t, "xoxb-platform", bridge.(*slackBridge).token)
+}
+
+func TestSlackBridge(t *testing.T) {
+	var posts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch r.URL.Path {
+		case "/auth.test":
+			w.Write([]byte(`{"ok":true,"user_id":"UBOT"}`))
+		case "/chat.postMessage":
+			posts = append(posts, r.Form.Get("thread_ts")+"|"+r.Form.Get("text"))
+			assert.Contains(t, []string{"#ops", "C1"}, r.Form.Get("channel"))
+			w.Write([]byte(`{"ok":true,"channel":"C1","ts":"1700000000.000100"}`))
+		case "/conversations.replies":
+			assert.Equal(t, "C1", r.Form.Get("channel"))
+			assert.Equal(t, "1700000000.000100", r.Form.Get("ts"))
+			w.Write([]byte(`{"ok":true,"messages":[
+				{"user":"UBOT","text":"started","ts":"1700000000.000100"},
+				{"user":"UBOT","text":"🤖 answer","ts":"1700000001.000100"},
+				{"user":"U2","text":"check &lt;pod&gt; logs","ts":"1700000002.000100"},
+				{"user":"U2","subtype":"thread_broadcast","text":"also","ts":"1700000003.000100"},
+				{"bot_id":"B1","text":"alert","ts":"1700000004.000100"},
+				{"user":"U3","text":"restart it","ts":"1700000005.000100"}]}`))
+		case "/users.info":
+			if r.Form.Get("user") == "U2" {
+				w.Write([]byte(`{"ok":true,"user":{"name":"dana","profile":{"display_name":"Dana"}}}`))
+				return
+			}
+			w.Write([]byte(`{"ok":false,"error":"user_not_found"}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error":"unknown_method"}`))
+		}
+	}))
+	defer server.Close()
+
+	s := newSlackBridge("xoxb-test", "#ops")
+	s.baseURL = server.URL
+	ctx := context.Background()
+
+	require.NoError(t, s.Start(ctx, "ops", "s-1"))
+	require.NoError(t, s.Prompt(ctx, "why is <api> down?"))
+	require.NoError(t, s.Reply(ctx, "It ran out of memory.", []string{"kubectl"}))
+	require.NoError(t, s.End(ctx, "kubiya chat -n ops --session s-1"))
+
+	require.Len(t, posts, 4)
+	assert.True(t, strings.HasPrefix(posts[0], "|🧵 Kubiya chat with agent *ops*"), posts[0])
+	assert.Equal(t, "1700000000.000100|👤 why is &lt;api&gt; down?", posts[1])
+	assert.Equal(t, "1700000000.000100|🔧 kubectl\n🤖 *ops*: It ran out of memory.", posts[2])
+	assert.Contains(t, posts[3], "`kubiya chat -n ops --session s-1`")
+
+	replies, err := s.Replies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []bridgeMessage{{From: "Dana", Text: "check <pod> logs"}, {From: "U3", Text: "restart it"}}, replies)
+
+	// Replies already read are not returned again
+	replies, err = s.Replies(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, replies)
+}
+
+func TestSlackTSAfter(t *testing.T) {
+	assert.True(t, slackTSAfter("1700000000.000200", "1700000000.000100"))
+	assert.True(t, slackTSAfter("10000000000.000100", "9999999999.000100"))
+	assert.False(t, slackTSAfter("1700000000.000100", "1700000000.000100"))
+}
+
+// fakeBridge records what is mirrored and hands out replies once
+type fakeBridge struct {
+	mu      sync.Mutex
+	posts   []string
+	replies []bridgeMessage
+	drained chan struct{}
+	ended   string
+}
+
+func (b *fakeBridge) Name() string { return "Slack" }
+
+func (b *fakeBridge) Start(ctx context.Context, agent, sessionID string) error { return nil }
+
+func (b *fakeBridge) Prompt(ctx context.Context, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.posts = append(b.posts, "prompt: "+message)
+	return nil
+}
+
+func (b *fakeBridge) Reply(ctx context.Context, content string, tools []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.posts = append(b.posts, "reply: "+strings.Join(tools, ",")+" "+content)
+	return nil
+}
+
+func (b *fakeBridge) Replies(ctx context.Context) ([]bridgeMessage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	replies := b.replies
+	if replies != nil {
+		b.replies = nil
+		close(b.drained)
+	}
+	return replies, nil
+}
+
+func (b *fakeBridge) End(ctx context.Context, resumeCommand string) error {
+	b.ended = resumeCommand
+	return nil
+}
+
+func TestRunBridgedSession(t *testing.T) {
+	chat := &scriptedChat{reply: []kubiya.ChatMessage{
+		{Type: "tool", MessageID: "t1", Content: "Tool: kubectl Arguments: {}"},
+		{Type: "text", MessageID: "m1", Content: "Done.", Final: true, SessionID: "s-1"},
+	}}
+	bridge := &fakeBridge{
+		replies: []bridgeMessage{{From: "Dana", Text: "check the logs"}},
+		drained: make(chan struct{}),
+	}
+	var out bytes.Buffer
+	stream := &chatStdinStream{client: chat, agentID: "agent-1", sessionID: "s-1", out: &out, bridge: bridge}
+
+	// Keep the terminal open until the teammate's reply was read
+	r, w := io.Pipe()
+	go func() {
+		io.WriteString(w, "from the terminal\n")
+		<-bridge.drained
+		w.Close()
+	}()
+
+	err := runBridgedSession(context.Background(), r, stream, "why is the api down?", 10*time.Millisecond, func(sessionID string) string {
+		return "resume " + sessionID
+	})
+	require.NoError(t, err)
+
+	require.Len(t, chat.messages, 3)
+	assert.Equal(t, "why is the api down?", chat.messages[0])
+	assert.ElementsMatch(t, []string{"from the terminal", "[Dana via Slack] check the logs"}, chat.messages[1:])
+	assert.Equal(t, []string{"s-1", "s-1", "s-1"}, chat.sessions)
+
+	// Only terminal prompts are mirrored, teammates see their own messages
+	assert.Contains(t, bridge.posts, "prompt: why is the api down?")
+	assert.Contains(t, bridge.posts, "prompt: from the terminal")
+	assert.NotContains(t, bridge.posts, "prompt: [Dana via Slack] check the logs")
+	assert.Equal(t, 3, strings.Count(strings.Join(bridge.posts, "\n"), "reply: kubectl Done."))
+	assert.Contains(t, out.String(), "Dana (Slack): check the logs")
+	assert.Equal(t, "resume s-1", bridge.ended)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/cassette"
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// startChatCassette records the API interactions of the chat to a cassette
+// or replays them from one, by pointing cfg at a local recording proxy or
+// player. The returned function stops it and reports the result.
+func startChatCassette(cfg *config.Config, record, replay string, w io.Writer) (func(), error) {
+	if record != "" && replay != "" {
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	}
+	if record == "" && replay == "" {
+		return func() {}, nil
+	}
+
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid API base URL %q", cfg.BaseURL)
+	}
+	basePath := strings.TrimSuffix(base.Path, "/")
+
+	if record != "" {
+		recorder := cassette.NewRecorder(base, record)
+		proxyURL, stop, err := recorder.Start("127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		cfg.BaseURL = proxyURL + basePath
+		return func() {
+			stop()
+			if err := recorder.Err(); err != nil {
+				fmt.Fprintf(w, "%s\n", style.ErrorStyle.Render(fmt.Sprintf("❌ Failed to save cassette: %v", err)))
+				return
+			}
+			fmt.Fprintf(w, "%s Recorded %d API interactions to %s\n",
+				style.SuccessStyle.Render("✓"), recorder.Len(), style.HighlightStyle.Render(record))
+		}, nil
+	}
+
+	recording, err := cassette.Load(replay)
+	if err != nil {
+		return nil, err
+	}
+	player := cassette.NewPlayer(recording)
+	playerURL, stop, err := player.Start("127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	cfg.BaseURL = playerURL + basePath
+	if cfg.APIKey == "" {
+		cfg.APIKey = "replay-api-key"
+	}
+	// Keep control plane requests off the network too
+	os.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", playerURL)
+	if cfg.Debug {
+		fmt.Fprintf(w, "Replaying %d API interactions from %s at %s\n", len(recording.Interactions), replay, playerURL)
+	}
+	return func() {
+		stop()
+		if unused := player.Unused(); unused > 0 && cfg.Debug {
+			fmt.Fprintf(w, "%d recorded interactions were not replayed\n", unused)
+		}
+	}, nil
+}
+
+// replaying reports whether cmd replays a cassette instead of using the API
+func replaying(cmd *cobra.Command) bool {
+	f := cmd.Flags().Lookup("replay")
+	return f != nil && f.Value.String() != ""
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/mockserver"
+)
+
+func TestChatCassette(t *testing.T) {
+	apiURL, stopAPI, err := mockserver.New(mockserver.DefaultFixtures()).Start("127.0.0.1:0")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "chat.cassette")
+	t.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", "")
+
+	_, err = startChatCassette(&config.Config{BaseURL: apiURL + "/api/v1"}, path, path, &bytes.Buffer{})
+	assert.Error(t, err, "--record and --replay are exclusive")
+
+	var out bytes.Buffer
+	cfg := &config.Config{APIKey: "key", BaseURL: apiURL + "/api/v1"}
+	stop, err := startChatCassette(cfg, path, "", &out)
+	require.NoError(t, err)
+	assert.NotEqual(t, apiURL+"/api/v1", cfg.BaseURL)
+	assert.True(t, strings.HasSuffix(cfg.BaseURL, "/api/v1"))
+	agents, err := kubiya.NewClient(cfg).GetAgents(context.Background())
+	require.NoError(t, err)
+	stop()
+	stopAPI()
+	assert.Contains(t, out.String(), "Recorded 1 API interactions")
+
+	cfg = &config.Config{BaseURL: apiURL + "/api/v1"}
+	stop, err = startChatCassette(cfg, "", path, &bytes.Buffer{})
+	require.NoError(t, err)
+	defer stop()
+	assert.NotEmpty(t, cfg.APIKey)
+	replayed, err := kubiya.NewClient(cfg).GetAgents(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, agents[0].UUID, replayed[0].UUID)
+}
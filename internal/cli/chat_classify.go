@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// defaultExplainCandidates is how many candidates --explain-classification
+// shows unless --explain-candidates says otherwise
+const defaultExplainCandidates = 5
+
+// classifiedAgent is a candidate agent returned by the classification
+// endpoint, best match first. Score and reason are only set when the
+// platform provides them.
+type classifiedAgent struct {
+	UUID        string   `json:"uuid"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Score       *float64 `json:"score,omitempty"`
+	Confidence  *float64 `json:"confidence,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Reasoning   string   `json:"reasoning,omitempty"`
+}
+
+func (a classifiedAgent) score() *float64 {
+	if a.Score != nil {
+		return a.Score
+	}
+	return a.Confidence
+}
+
+func (a classifiedAgent) reason() string {
+	if a.Reason != "" {
+		return a.Reason
+	}
+	return a.Reasoning
+}
+
+// classifyAgents asks the platform which agents fit message best, among the
+// agents named in among (names or UUIDs) unless it is empty. Platforms that
+// ignore the restriction return any agent, so the caller still filters the
+// candidates with restrictCandidates.
+func classifyAgents(ctx context.Context, cfg *config.Config, message string, among []string) ([]classifiedAgent, error) {
+	reqJSON, err := json.Marshal(struct {
+		Message string   `json:"message"`
+		Agents  []string `json:"agents,omitempty"`
+	}{Message: message, Agents: among})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal classification request: %w", err)
+	}
+
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/api/v1")
+
+	classifyURL := fmt.Sprintf("%s/http-bridge/v1/classify/agent", baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, classifyURL, bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create classification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	chatLog.Debugf("Sending classification request to: %s", classifyURL)
+
+	resp, err := httpclient.Default().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send classification request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classification response: %w", err)
+	}
+
+	chatLog.Debug("Classification response", "status", resp.StatusCode, "body", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classification failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var agents []classifiedAgent
+	if err := json.Unmarshal(body, &agents); err != nil {
+		return nil, fmt.Errorf("failed to parse classification response: %w", err)
+	}
+	return agents, nil
+}
+
+// restrictCandidates keeps the candidates whose name or UUID is in allowed,
+// preserving their order. An empty allowed list keeps all candidates.
+func restrictCandidates(candidates []classifiedAgent, allowed []string) []classifiedAgent {
+	if len(allowed) == 0 {
+		return candidates
+	}
+	var kept []classifiedAgent
+	for _, c := range candidates {
+		for _, a := range allowed {
+			if strings.EqualFold(c.Name, a) || c.UUID == a {
+				kept = append(kept, c)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// printClassification shows the top candidates of a classification with
+// their scores and reasons, marking the selected one
+func printClassification(w io.Writer, candidates []classifiedAgent, top int, restricted []string) {
+	if top <= 0 || top > len(candidates) {
+		top = len(candidates)
+	}
+
+	fmt.Fprintf(w, "%s Classification candidates", style.InfoStyle.Render("🔍"))
+	if len(restricted) > 0 {
+		fmt.Fprintf(w, " (restricted to %s)", strings.Join(restricted, ", "))
+	}
+	fmt.Fprintln(w, ":")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  RANK\tAGENT\tSCORE\tREASON")
+	for i, c := range candidates[:top] {
+		rank := fmt.Sprintf("%d", i+1)
+		name := c.Name
+		if i == 0 {
+			rank += " ✓"
+			name = style.HighlightStyle.Render(name)
+		}
+		score := "-"
+		if s := c.score(); s != nil {
+			score = fmt.Sprintf("%.2f", *s)
+		}
+		reason := c.reason()
+		if reason == "" {
+			reason = c.Description
+		}
+		if len(reason) > 80 {
+			reason = reason[:77] + "..."
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", rank, name, score, style.DimStyle.Render(reason))
+	}
+	tw.Flush()
+
+	if hidden := len(candidates) - top; hidden > 0 {
+		fmt.Fprintf(w, "  %s\n", style.DimStyle.Render(fmt.Sprintf("... and %d more", hidden)))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+func TestRestrictCandidates(t *testing.T) {
+	candidates := []classifiedAgent{
+		{UUID: "1", Name: "devops"},
+		{UUID: "2", Name: "security"},
+		{UUID: "3", Name: "platform"},
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		want    []string
+	}{
+		{name: "no restriction", want: []string{"devops", "security", "platform"}},
+		{name: "by name keeps ranking", allowed: []string{"Platform", "devops"}, want: []string{"devops", "platform"}},
+		{name: "by uuid", allowed: []string{"2"}, want: []string{"security"}},
+		{name: "no match", allowed: []string{"billing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range restrictCandidates(candidates, tt.allowed) {
+				got = append(got, c.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("restrictCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintClassification(t *testing.T) {
+	style.DisableColors()
+
+	var candidates []classifiedAgent
+	if err := json.Unmarshal([]byte(`[
+		{"uuid":"1","name":"devops","description":"Kubernetes operations","score":0.91,"reason":"mentions pods"},
+		{"uuid":"2","name":"security","description":"Security reviews","confidence":0.4},
+		{"uuid":"3","name":"platform","description":"Platform team"}
+	]`), &candidates); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	printClassification(&out, candidates, 2, nil)
+	got := out.String()
+
+	for _, want := range []string{"1 ✓", "devops", "0.91", "mentions pods", "0.40", "Security reviews", "and 1 more"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Platform team") {
+		t.Errorf("output shows more than the top 2 candidates:\n%s", got)
+	}
+}
+
+func TestClassifyAgentsSendsAmong(t *testing.T) {
+	var got struct {
+		Message string   `json:"message"`
+		Agents  []string `json:"agents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/http-bridge/v1/classify/agent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Write([]byte(`[{"uuid":"1","name":"devops"}]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{BaseURL: server.URL + "/api/v1"}
+	agents, err := classifyAgents(context.Background(), cfg, "restart pods", []string{"devops", "security"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 || agents[0].Name != "devops" {
+		t.Errorf("unexpected agents: %+v", agents)
+	}
+	if got.Message != "restart pods" {
+		t.Errorf("message = %q", got.Message)
+	}
+	if want := []string{"devops", "security"}; !reflect.DeepEqual(got.Agents, want) {
+		t.Errorf("agents = %v, want %v", got.Agents, want)
+	}
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// defaultMaxContextBytes is the default of --max-context-bytes
+const defaultMaxContextBytes = 5 << 20
+
+// contextProgressFiles is the number of --context files from which loading
+// them shows a progress bar; URLs always show it
+const contextProgressFiles = 20
+
+// binarySniffLen is how much of a file is checked for NUL bytes to tell
+// binary files apart, as git does
+const binarySniffLen = 8000
+
+// contextFetchFunc fetches the content of a URL, calling progress with the
+// bytes read so far when it isn't nil
+type contextFetchFunc func(rawURL string, progress func(read int64)) (string, error)
+
+// contextLoadStats summarizes the files and URLs read for --context
+type contextLoadStats struct {
+	Files int
+	URLs  int
+	// Bytes is the size of the context sent
+	Bytes int64
+	// Binaries are the files skipped because they aren't text
+	Binaries []string
+	// Truncated is the file cut at the --max-context-bytes limit, and
+	// Omitted the files left out after it
+	Truncated string
+	Omitted   []string
+	MaxBytes  int64
+}
+
+// contextEntry is a file or URL matched by the --context patterns
+type contextEntry struct {
+	name string
+	url  bool
+}
+
+// expandContextPatterns resolves --context patterns to URLs and regular
+// files, keeping the order they were given in and dropping duplicates
+func expandContextPatterns(patterns []string) ([]contextEntry, error) {
+	var entries []contextEntry
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "http://") || strings.HasPrefix(pattern, "https://") {
+			if !seen[pattern] {
+				seen[pattern] = true
+				entries = append(entries, contextEntry{name: pattern, url: true})
+			}
+			continue
+		}
+
+		matches, err := globFiles(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match pattern: %s", pattern)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat file %s: %w", match, err)
+			}
+			if info.IsDir() || seen[match] {
+				continue
+			}
+			seen[match] = true
+			entries = append(entries, contextEntry{name: match})
+		}
+	}
+	return entries, nil
+}
+
+// loadContextFiles reads the files and URLs matching patterns. Binary files
+// are skipped. Once maxBytes (0 for no limit) would be exceeded, the file
+// crossing the limit is truncated to fit and the remaining ones are left
+// out. When w isn't nil, a summary is written to it if anything was skipped
+// or the load was long enough to show an aggregate progress bar, which is
+// rendered on w when interactive is set.
+func loadContextFiles(patterns []string, fetch contextFetchFunc, maxBytes int64, w io.Writer, interactive bool) (map[string]string, contextLoadStats, error) {
+	stats := contextLoadStats{MaxBytes: maxBytes}
+	entries, err := expandContextPatterns(patterns)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	long := w != nil && showContextProgress(entries)
+	var bar *contextProgress
+	if long && interactive {
+		bar = newContextProgress(w, entries)
+	}
+
+	context := make(map[string]string)
+	for i, entry := range entries {
+		if maxBytes > 0 && stats.Bytes >= maxBytes {
+			stats.Omitted = append(stats.Omitted, entry.name)
+			continue
+		}
+
+		var content string
+		if entry.url {
+			var onRead func(int64)
+			if bar != nil {
+				onRead = func(read int64) { bar.reading(i, entry.name, read) }
+			}
+			content, err = fetch(entry.name, onRead)
+			if err != nil {
+				bar.finish()
+				return nil, stats, fmt.Errorf("failed to fetch URL %s: %w", entry.name, err)
+			}
+			stats.URLs++
+		} else {
+			data, err := os.ReadFile(entry.name)
+			if err != nil {
+				bar.finish()
+				return nil, stats, fmt.Errorf("failed to read file %s: %w", entry.name, err)
+			}
+			if isBinaryContent(data) {
+				stats.Binaries = append(stats.Binaries, entry.name)
+				bar.done(i, stats.Bytes)
+				continue
+			}
+			content = string(data)
+			stats.Files++
+		}
+
+		if maxBytes > 0 && stats.Bytes+int64(len(content)) > maxBytes {
+			content = truncateContext(content, maxBytes-stats.Bytes)
+			stats.Truncated = entry.name
+		}
+		stats.Bytes += int64(len(content))
+		context[entry.name] = content
+		bar.done(i, stats.Bytes)
+	}
+
+	bar.finish()
+	if w != nil && (long || len(stats.Binaries) > 0 || stats.Truncated != "" || len(stats.Omitted) > 0) {
+		stats.print(w)
+	}
+	return context, stats, nil
+}
+
+// showContextProgress reports whether loading entries is long enough to
+// show a progress bar
+func showContextProgress(entries []contextEntry) bool {
+	if len(entries) >= contextProgressFiles {
+		return true
+	}
+	for _, entry := range entries {
+		if entry.url {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinaryContent reports whether data looks binary: it has a NUL byte
+// near its start or isn't valid UTF-8
+func isBinaryContent(data []byte) bool {
+	head := data
+	if len(head) > binarySniffLen {
+		head = head[:binarySniffLen]
+		// Don't count a rune cut at the end of the sniffed prefix
+		for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+			head = head[:len(head)-1]
+		}
+	}
+	return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(head)
+}
+
+// truncateContext cuts content to at most limit bytes, on a rune boundary,
+// and marks the cut
+func truncateContext(content string, limit int64) string {
+	total := len(content)
+	if limit < 0 {
+		limit = 0
+	}
+	cut := int(limit)
+	for cut > 0 && cut < total && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n\n[truncated by --max-context-bytes: %s of %s sent]",
+		content[:cut], formatBytes(int64(cut)), formatBytes(int64(total)))
+}
+
+// print writes the summary of the context load and its warnings to w
+func (s contextLoadStats) print(w io.Writer) {
+	var sources []string
+	if s.Files > 0 {
+		sources = append(sources, pluralize(s.Files, "file", "files"))
+	}
+	if s.URLs > 0 {
+		sources = append(sources, pluralize(s.URLs, "URL", "URLs"))
+	}
+	if len(sources) == 0 {
+		sources = append(sources, "nothing")
+	}
+	fmt.Fprintf(w, "%s Context: %s, %s\n", style.InfoStyle.Render("📎"), strings.Join(sources, " and "), formatBytes(s.Bytes))
+
+	if len(s.Binaries) > 0 {
+		fmt.Fprintf(w, "%s Skipped %s (use --attach to upload them): %s\n", style.WarningStyle.Render("⚠️"),
+			pluralize(len(s.Binaries), "binary file", "binary files"), listNames(s.Binaries))
+	}
+	if s.Truncated != "" || len(s.Omitted) > 0 {
+		var parts []string
+		if s.Truncated != "" {
+			parts = append(parts, s.Truncated+" was truncated")
+		}
+		if len(s.Omitted) > 0 {
+			parts = append(parts, fmt.Sprintf("%s left out (%s)", pluralize(len(s.Omitted), "file", "files"), listNames(s.Omitted)))
+		}
+		fmt.Fprintf(w, "%s Context limit of %s reached (--max-context-bytes): %s\n", style.WarningStyle.Render("⚠️"),
+			formatBytes(s.MaxBytes), strings.Join(parts, ", "))
+	}
+}
+
+// pluralize returns n followed by the singular or plural noun
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// listNames joins the first names, with the number of the others
+func listNames(names []string) string {
+	const shown = 5
+	if len(names) <= shown {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:shown], ", "), len(names)-shown)
+}
+
+// contextProgress renders the aggregate progress of a context load on one
+// line: the files done out of the total and the bytes read so far. Methods
+// are no-ops on a nil receiver.
+type contextProgress struct {
+	w        io.Writer
+	total    int
+	lastDraw time.Time
+}
+
+func newContextProgress(w io.Writer, entries []contextEntry) *contextProgress {
+	return &contextProgress{w: w, total: len(entries)}
+}
+
+func (p *contextProgress) draw(done int, msg string) {
+	const width = 30
+	filled := width * done / p.total
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	fmt.Fprintf(p.w, "\r\033[K📎 [%s] %d/%d %s", bar, done, p.total, msg)
+	p.lastDraw = time.Now()
+}
+
+// reading reports the bytes read so far of the entry at index i, redrawn
+// at most every 100ms
+func (p *contextProgress) reading(i int, name string, read int64) {
+	if p == nil || time.Since(p.lastDraw) < 100*time.Millisecond {
+		return
+	}
+	p.draw(i, fmt.Sprintf("%s (%s)", shortContextName(name), formatBytes(read)))
+}
+
+// done reports that the entry at index i was read, with bytes of context
+// loaded so far
+func (p *contextProgress) done(i int, bytes int64) {
+	if p == nil {
+		return
+	}
+	if i+1 < p.total && time.Since(p.lastDraw) < 100*time.Millisecond {
+		return
+	}
+	p.draw(i+1, formatBytes(bytes))
+}
+
+// finish clears the progress line, making room for the summary
+func (p *contextProgress) finish() {
+	if p != nil {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
+
+// shortContextName shortens long paths and URLs for the progress line
+func shortContextName(name string) string {
+	const max = 40
+	if utf8.RuneCountInString(name) <= max {
+		return name
+	}
+	runes := []rune(name)
+	return "…" + string(runes[len(runes)-max+1:])
+}
+
+// readProgress reports the bytes read from r so far
+type readProgress struct {
+	r        io.Reader
+	read     int64
+	progress func(read int64)
+}
+
+func (r *readProgress) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	r.progress(r.read)
+	return n, err
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noFetch(t *testing.T) contextFetchFunc {
+	return func(rawURL string, progress func(int64)) (string, error) {
+		t.Fatalf("unexpected fetch of %s", rawURL)
+		return "", nil
+	}
+}
+
+func TestLoadContextFilesSkipsBinaries(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG\r\n\x1a\n\x00\x00"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("héllo"), 0o644))
+
+	var out bytes.Buffer
+	context, stats, err := loadContextFiles([]string{filepath.Join(dir, "*")}, noFetch(t), 0, &out, false)
+	require.NoError(t, err)
+
+	assert.Len(t, context, 2)
+	assert.Equal(t, "héllo", context[filepath.Join(dir, "notes.txt")])
+	assert.Equal(t, []string{filepath.Join(dir, "logo.png")}, stats.Binaries)
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, int64(len("package main\n")+len("héllo")), stats.Bytes)
+	assert.Contains(t, out.String(), "Context: 2 files, 19 B")
+	assert.Contains(t, out.String(), "Skipped 1 binary file (use --attach to upload them): "+filepath.Join(dir, "logo.png"))
+}
+
+func TestLoadContextFilesLimit(t *testing.T) {
+	dir := t.TempDir()
+	var patterns []string
+	for i, size := range []int{40, 50, 30, 10} {
+		name := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+		require.NoError(t, os.WriteFile(name, []byte(strings.Repeat("é", size/2)), 0o644))
+		patterns = append(patterns, name)
+	}
+
+	var out bytes.Buffer
+	context, stats, err := loadContextFiles(patterns, noFetch(t), 65, &out, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.Repeat("é", 20), context[patterns[0]])
+	// 25 bytes remain for the second file, cut back to a rune boundary
+	second := context[patterns[1]]
+	assert.True(t, strings.HasPrefix(second, strings.Repeat("é", 12)+"\n\n[truncated by --max-context-bytes: 24 B of 50 B sent]"), second)
+	assert.Equal(t, patterns[1], stats.Truncated)
+	assert.Equal(t, patterns[2:], stats.Omitted)
+	assert.NotContains(t, context, patterns[2])
+	assert.Contains(t, out.String(), "Context limit of 65 B reached (--max-context-bytes): "+patterns[1]+" was truncated, 2 files left out")
+
+	// No limit
+	context, stats, err = loadContextFiles(patterns, noFetch(t), 0, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, context, 4)
+	assert.Empty(t, stats.Truncated)
+	assert.Equal(t, int64(130), stats.Bytes)
+}
+
+func TestLoadContextFilesProgress(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < contextProgressFiles; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%02d.txt", i)), []byte("data"), 0o644))
+	}
+	fetched := ""
+	fetch := func(rawURL string, progress func(int64)) (string, error) {
+		fetched = rawURL
+		require.NotNil(t, progress)
+		progress(4)
+		return "docs", nil
+	}
+
+	var out bytes.Buffer
+	context, stats, err := loadContextFiles([]string{filepath.Join(dir, "*.txt"), "https://example.com/README.md"}, fetch, 0, &out, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/README.md", fetched)
+	assert.Equal(t, "docs", context[fetched])
+	assert.Equal(t, 1, stats.URLs)
+	assert.Contains(t, out.String(), "21/21 84 B")
+	assert.Contains(t, out.String(), "Context: 20 files and 1 URL, 84 B")
+
+	// A few files load without a bar or summary
+	out.Reset()
+	_, _, err = loadContextFiles([]string{filepath.Join(dir, "f0*.txt")}, noFetch(t), 0, &out, true)
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+}
+
+func TestIsBinaryContent(t *testing.T) {
+	assert.False(t, isBinaryContent([]byte("plain text\n")))
+	assert.False(t, isBinaryContent(nil))
+	assert.True(t, isBinaryContent([]byte("a\x00b")))
+	assert.True(t, isBinaryContent([]byte{0xff, 0xfe, 'a'}))
+	// A rune cut at the end of the sniffed prefix doesn't make text binary
+	long := strings.Repeat("a", binarySniffLen-1) + "é"
+	assert.False(t, isBinaryContent([]byte(long)))
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+)
+
+// Auto follow-up policies of non-interactive chats whose answer ran no tool
+const (
+	followUpOff        = "off"
+	followUpOnce       = "once"
+	followUpAggressive = "aggressive"
+)
+
+// aggressiveFollowUps caps the follow-ups of the aggressive policy
+const aggressiveFollowUps = 3
+
+const defaultFollowUpTemplate = "You didn't seem to execute anything. You're running in a non-interactive session and the user confirms to execute read-only operations. EXECUTE RIGHT AWAY!"
+
+// followUpPolicy decides whether and how agents are nudged to run tools
+type followUpPolicy struct {
+	mode     string
+	template *template.Template
+}
+
+// followUpData is the data of follow-up templates
+type followUpData struct {
+	Message     string // the original prompt
+	AgentID     string
+	Attempt     int // starts at 1
+	MaxAttempts int
+}
+
+// newFollowUpPolicy builds the policy from the flags, falling back to the
+// chat preferences and then to a single follow-up with the default nudge
+func newFollowUpPolicy(mode, tmpl string, prefs *kubiyacontext.ChatConfig) (*followUpPolicy, error) {
+	if mode == "" && prefs != nil {
+		mode = prefs.AutoFollowUp
+	}
+	if mode == "" {
+		mode = followUpOnce
+	}
+	if err := kubiyacontext.ValidateAutoFollowUp(mode); err != nil {
+		return nil, err
+	}
+
+	if tmpl == "" && prefs != nil {
+		tmpl = prefs.FollowUpTemplate
+	}
+	if tmpl == "" {
+		tmpl = defaultFollowUpTemplate
+	}
+	t, err := template.New("followup").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid follow-up template: %w", err)
+	}
+	policy := &followUpPolicy{mode: mode, template: t}
+	// Fail before sending anything on fields the template does not have
+	if _, err := policy.message(followUpData{Attempt: 1}); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// maxAttempts is the number of follow-ups the policy sends at most
+func (p *followUpPolicy) maxAttempts() int {
+	switch p.mode {
+	case followUpOff:
+		return 0
+	case followUpAggressive:
+		return aggressiveFollowUps
+	default:
+		return 1
+	}
+}
+
+// message renders the follow-up for the given attempt
+func (p *followUpPolicy) message(data followUpData) (string, error) {
+	data.MaxAttempts = p.maxAttempts()
+	var b strings.Builder
+	if err := p.template.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render follow-up template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func (p *followUpPolicy) String() string {
+	if p.mode == followUpOff {
+		return p.mode
+	}
+	return fmt.Sprintf("%s (up to %d follow-ups)", p.mode, p.maxAttempts())
+}
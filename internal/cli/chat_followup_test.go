@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+)
+
+func TestFollowUpPolicyDefaults(t *testing.T) {
+	policy, err := newFollowUpPolicy("", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policy.maxAttempts())
+
+	msg, err := policy.message(followUpData{Attempt: 1})
+	require.NoError(t, err)
+	assert.Equal(t, defaultFollowUpTemplate, msg)
+}
+
+func TestFollowUpPolicyFromPreferences(t *testing.T) {
+	prefs := &kubiyacontext.ChatConfig{AutoFollowUp: "off"}
+	policy, err := newFollowUpPolicy("", "", prefs)
+	require.NoError(t, err)
+	assert.Equal(t, 0, policy.maxAttempts())
+	assert.Equal(t, "off", policy.String())
+
+	// Flags win over preferences
+	policy, err = newFollowUpPolicy("aggressive", "", prefs)
+	require.NoError(t, err)
+	assert.Equal(t, aggressiveFollowUps, policy.maxAttempts())
+}
+
+func TestFollowUpPolicyTemplate(t *testing.T) {
+	policy, err := newFollowUpPolicy("aggressive", "Attempt {{.Attempt}}/{{.MaxAttempts}}: run the tools needed for {{printf \"%q\" .Message}}", nil)
+	require.NoError(t, err)
+
+	msg, err := policy.message(followUpData{Message: "check pods", Attempt: 2})
+	require.NoError(t, err)
+	assert.Equal(t, `Attempt 2/3: run the tools needed for "check pods"`, msg)
+}
+
+func TestFollowUpPolicyInvalid(t *testing.T) {
+	_, err := newFollowUpPolicy("always", "", nil)
+	assert.ErrorContains(t, err, `invalid auto follow-up policy "always"`)
+
+	_, err = newFollowUpPolicy("", "{{.Message", nil)
+	assert.ErrorContains(t, err, "invalid follow-up template")
+
+	_, err = newFollowUpPolicy("", "{{.Prompt}}", nil)
+	assert.ErrorContains(t, err, "failed to render follow-up template")
+}
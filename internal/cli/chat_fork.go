@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// maxForkedToolOutput bounds each tool output carried into a fork
+const maxForkedToolOutput = 2000
+
+// sessionHistoryGetter is the part of kubiya.Client used to fork sessions
+type sessionHistoryGetter interface {
+	GetSessionHistory(ctx context.Context, sessionID string) ([]kubiya.ChatMessage, error)
+}
+
+// forkSession starts a new session carrying the conversation of an existing
+// one. The original session is only read: the transcript goes to the agent
+// as context of the first message of the fork.
+func forkSession(ctx context.Context, client sessionHistoryGetter, sessionID string) (string, string, []kubiya.ChatMessage, error) {
+	history, err := client.GetSessionHistory(ctx, sessionID)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to fork session %s: %w", sessionID, err)
+	}
+	if len(history) == 0 {
+		return "", "", nil, fmt.Errorf("failed to fork session %s: it has no messages", sessionID)
+	}
+	return uuid.New().String(), forkTranscript(sessionID, history), history, nil
+}
+
+// forkTranscriptName is the context entry holding the transcript of a fork
+func forkTranscriptName(sessionID string) string {
+	return fmt.Sprintf("session-%s-transcript.md", sessionID)
+}
+
+// forkTranscript renders a session for the agent to pick the conversation
+// up from
+func forkTranscript(sessionID string, messages []kubiya.ChatMessage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Transcript of conversation %s, which this conversation branches off. ", sessionID)
+	b.WriteString("Continue from it as if it happened in this conversation.\n")
+
+	for _, ex := range groupExchanges(messages) {
+		if ex.prompt != nil {
+			fmt.Fprintf(&b, "\nUser: %s\n", strings.TrimSpace(ex.prompt.Content))
+		}
+		for _, reply := range ex.replies {
+			content := strings.TrimSpace(reply.Content)
+			if content == "" {
+				continue
+			}
+			switch reply.Type {
+			case "tool":
+				fmt.Fprintf(&b, "Tool call: %s\n", content)
+			case "tool_output":
+				if len(content) > maxForkedToolOutput {
+					content = content[:maxForkedToolOutput] + "\n[output truncated]"
+				}
+				fmt.Fprintf(&b, "Tool output:\n%s\n", content)
+			default:
+				fmt.Fprintf(&b, "Assistant: %s\n", content)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeSessionHistory serves the messages of sessions
+type fakeSessionHistory map[string][]kubiya.ChatMessage
+
+func (f fakeSessionHistory) GetSessionHistory(ctx context.Context, sessionID string) ([]kubiya.ChatMessage, error) {
+	return f[sessionID], nil
+}
+
+func TestForkSession(t *testing.T) {
+	sessions := fakeSessionHistory{
+		"abc": {
+			{Type: "user", Content: "The api is down"},
+			{Type: "tool", Content: "kubectl_get_pods"},
+			{Type: "tool_output", Content: strings.Repeat("x", maxForkedToolOutput+10)},
+			{Type: "text", Content: "Rolling back the deployment should fix it."},
+		},
+	}
+
+	forkID, transcript, history, err := forkSession(context.Background(), sessions, "abc")
+	require.NoError(t, err)
+	assert.NotEqual(t, "abc", forkID)
+	assert.Len(t, history, 4)
+	assert.Contains(t, transcript, "conversation abc")
+	assert.Contains(t, transcript, "User: The api is down")
+	assert.Contains(t, transcript, "Tool call: kubectl_get_pods")
+	assert.Contains(t, transcript, "[output truncated]")
+	assert.Contains(t, transcript, "Assistant: Rolling back the deployment should fix it.")
+	assert.Equal(t, "session-abc-transcript.md", forkTranscriptName("abc"))
+
+	_, _, _, err = forkSession(context.Background(), sessions, "empty")
+	assert.Error(t, err)
+}
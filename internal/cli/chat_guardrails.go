@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// guardrail is a compiled guardrail rule
+type guardrail struct {
+	tool   *regexp.Regexp
+	args   *regexp.Regexp
+	line   *regexp.Regexp // pattern of --guardrail, matched against "tool args"
+	deny   bool
+	source string
+}
+
+// compileGuardrails compiles the rules of the chat preferences and the
+// patterns passed with --guardrail
+func compileGuardrails(chat *kubiyacontext.ChatConfig, patterns []string) ([]guardrail, error) {
+	var rules []kubiyacontext.GuardrailRule
+	if chat != nil {
+		rules = chat.Guardrails
+	}
+
+	var compiled []guardrail
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		g := guardrail{deny: rule.Action == "deny", source: describeGuardrailRule(rule)}
+		if rule.Tool != "" {
+			g.tool = regexp.MustCompile(rule.Tool)
+		}
+		if rule.Args != "" {
+			g.args = regexp.MustCompile(rule.Args)
+		}
+		compiled = append(compiled, g)
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --guardrail pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, guardrail{line: re, source: pattern})
+	}
+	return compiled, nil
+}
+
+func describeGuardrailRule(rule kubiyacontext.GuardrailRule) string {
+	var parts []string
+	if rule.Tool != "" {
+		parts = append(parts, "tool="+rule.Tool)
+	}
+	if rule.Args != "" {
+		parts = append(parts, "args="+rule.Args)
+	}
+	return strings.Join(parts, " ")
+}
+
+// matches reports whether the tool call is covered by the rule
+func (g guardrail) matches(call kubiya.ToolCall) bool {
+	if g.line != nil {
+		return g.line.MatchString(call.Name + " " + call.Arguments)
+	}
+	if g.tool != nil && !g.tool.MatchString(call.Name) {
+		return false
+	}
+	if g.args != nil && !g.args.MatchString(call.Arguments) {
+		return false
+	}
+	return true
+}
+
+// matchGuardrail returns the first rule matching the tool call. Deny rules
+// win over confirm rules.
+func matchGuardrail(rules []guardrail, call kubiya.ToolCall) (guardrail, bool) {
+	var found *guardrail
+	for i := range rules {
+		if !rules[i].matches(call) {
+			continue
+		}
+		if rules[i].deny {
+			return rules[i], true
+		}
+		if found == nil {
+			found = &rules[i]
+		}
+	}
+	if found == nil {
+		return guardrail{}, false
+	}
+	return *found, true
+}
+
+// chatGuardrails flags the tool calls matching a guardrail and confirms them
+// before the CLI follows the stream further. Without an interactive terminal
+// matching calls are denied. Neither prevents the call: the API cannot hold
+// or refuse a tool call, so the agent may run it anyway.
+type chatGuardrails struct {
+	rules       []guardrail
+	interactive bool
+	in          *bufio.Reader
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newChatGuardrails(rules []guardrail, interactive bool, in io.Reader, out io.Writer) *chatGuardrails {
+	return &chatGuardrails{
+		rules:       rules,
+		interactive: interactive,
+		in:          bufio.NewReader(in),
+		out:         out,
+	}
+}
+
+// SetOutput changes where confirmation prompts are written
+func (g *chatGuardrails) SetOutput(out io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.out = out
+}
+
+// Gate is the kubiya.ToolCallGate of the chat session
+func (g *chatGuardrails) Gate(ctx context.Context, call kubiya.ToolCall) error {
+	rule, ok := matchGuardrail(g.rules, call)
+	if !ok {
+		return nil
+	}
+
+	// One prompt at a time when tools are called in parallel
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if rule.deny || !g.interactive {
+		fmt.Fprintf(g.out, "%s\n", style.ErrorStyle.Render(
+			fmt.Sprintf("🛑 Tool %s matches guardrail %s; stopped following the response, the agent may still run it", call.Name, rule.source)))
+		return fmt.Errorf("%w: %s matches guardrail %s", kubiya.ErrToolCallDenied, call.Name, rule.source)
+	}
+
+	return confirmToolCall(ctx, g.in, g.out, call, fmt.Sprintf("matches guardrail %s", rule.source))
+}
+
+// confirmToolCall asks on in whether call may run, reason telling why
+func confirmToolCall(ctx context.Context, in *bufio.Reader, out io.Writer, call kubiya.ToolCall, reason string) error {
+	fmt.Fprintf(out, "%s\n", style.WarningStyle.Render(
+		fmt.Sprintf("⚠️  Tool %s %s", call.Name, reason)))
+	if call.Arguments != "" {
+		fmt.Fprintf(out, "   %s\n", style.DimStyle.Render(call.Arguments))
+	}
+	fmt.Fprintf(out, "%s\n", style.HighlightStyle.Render("   Allow this tool call? [y/N]"))
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := in.ReadString('\n')
+		answer <- strings.ToLower(strings.TrimSpace(line))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case a := <-answer:
+		if a == "y" || a == "yes" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s was not confirmed", kubiya.ErrToolCallDenied, call.Name)
+}
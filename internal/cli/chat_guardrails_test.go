@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestMatchGuardrail(t *testing.T) {
+	rules, err := compileGuardrails(&kubiyacontext.ChatConfig{Guardrails: []kubiyacontext.GuardrailRule{
+		{Tool: "^kubectl$", Args: `\bdelete\b`},
+		{Tool: "terraform", Args: "destroy", Action: "deny"},
+	}}, []string{"terraform.*apply"})
+	require.NoError(t, err)
+
+	_, ok := matchGuardrail(rules, kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"get pods"}`})
+	assert.False(t, ok)
+
+	rule, ok := matchGuardrail(rules, kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"delete pod web"}`})
+	require.True(t, ok)
+	assert.False(t, rule.deny)
+
+	rule, ok = matchGuardrail(rules, kubiya.ToolCall{Name: "terraform", Arguments: `{"command":"apply -destroy"}`})
+	require.True(t, ok)
+	assert.True(t, rule.deny, "deny rules win")
+
+	rule, ok = matchGuardrail(rules, kubiya.ToolCall{Name: "terraform", Arguments: `{"command":"apply"}`})
+	require.True(t, ok)
+	assert.Equal(t, "terraform.*apply", rule.source)
+
+	_, err = compileGuardrails(nil, []string{"("})
+	assert.Error(t, err)
+}
+
+func TestChatGuardrailsGate(t *testing.T) {
+	rules, err := compileGuardrails(nil, []string{"delete"})
+	require.NoError(t, err)
+	call := kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"delete ns prod"}`}
+
+	var out bytes.Buffer
+	assert.NoError(t, newChatGuardrails(rules, true, strings.NewReader("y\n"), &out).Gate(context.Background(), call))
+	assert.Contains(t, out.String(), "Allow this tool call?")
+
+	err = newChatGuardrails(rules, true, strings.NewReader("\n"), &out).Gate(context.Background(), call)
+	assert.True(t, errors.Is(err, kubiya.ErrToolCallDenied))
+
+	err = newChatGuardrails(rules, false, strings.NewReader("y\n"), &out).Gate(context.Background(), call)
+	assert.True(t, errors.Is(err, kubiya.ErrToolCallDenied), "denied without a terminal")
+
+	assert.NoError(t, newChatGuardrails(rules, false, nil, &out).Gate(context.Background(), kubiya.ToolCall{Name: "kubectl", Arguments: "get pods"}))
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// defaultReplayHistory is the number of exchanges shown when resuming a session
+const defaultReplayHistory = 3
+
+// chatExchange is a user message followed by everything the agent sent in reply
+type chatExchange struct {
+	prompt  *kubiya.ChatMessage
+	replies []kubiya.ChatMessage
+}
+
+func isUserChatMessage(msg kubiya.ChatMessage) bool {
+	return msg.SenderName == "You" || msg.Type == "user"
+}
+
+// groupExchanges splits a session history into prompt/reply exchanges.
+// Replies that precede the first user message form an exchange without a prompt.
+func groupExchanges(messages []kubiya.ChatMessage) []chatExchange {
+	var exchanges []chatExchange
+	for i := range messages {
+		msg := messages[i]
+		if isUserChatMessage(msg) {
+			exchanges = append(exchanges, chatExchange{prompt: &messages[i]})
+			continue
+		}
+		if len(exchanges) == 0 {
+			exchanges = append(exchanges, chatExchange{})
+		}
+		last := &exchanges[len(exchanges)-1]
+		last.replies = append(last.replies, msg)
+	}
+	return exchanges
+}
+
+// printSessionHistory prints the last n exchanges of a session so users can
+// reorient before continuing the conversation
+func printSessionHistory(w io.Writer, sessionID string, messages []kubiya.ChatMessage, n int) {
+	exchanges := groupExchanges(messages)
+	if len(exchanges) == 0 {
+		fmt.Fprintf(w, "%s\n", style.DimStyle.Render(fmt.Sprintf("No previous messages found in session %s", sessionID)))
+		return
+	}
+
+	skipped := 0
+	if n > 0 && len(exchanges) > n {
+		skipped = len(exchanges) - n
+		exchanges = exchanges[skipped:]
+	}
+
+	fmt.Fprintf(w, "\n%s\n", style.InfoBoxStyle.Render(fmt.Sprintf("📜 Session %s — last %d exchange(s)", sessionID, len(exchanges))))
+	if skipped > 0 {
+		fmt.Fprintf(w, "%s\n", style.DimStyle.Render(fmt.Sprintf("   … %d earlier exchange(s) not shown", skipped)))
+	}
+
+	for _, ex := range exchanges {
+		if ex.prompt != nil {
+			fmt.Fprintf(w, "\n%s %s\n", style.UserIconStyle.Render("👤"), strings.TrimSpace(ex.prompt.Content))
+		}
+		for _, reply := range ex.replies {
+			content := strings.TrimSpace(reply.Content)
+			switch reply.Type {
+			case "tool":
+				name := strings.SplitN(content, "\n", 2)[0]
+				fmt.Fprintf(w, "   ⚡ %s\n", style.ToolNameStyle.Render(strings.TrimSpace(name)))
+			case "tool_output":
+				// Tool output is usually long; the agent reply summarizes it
+				continue
+			default:
+				if content != "" {
+					fmt.Fprintf(w, "%s %s\n", style.RobotIconStyle.Render("🤖"), style.AgentStyle.Render(content))
+				}
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n\n", style.DimStyle.Render("─── continuing session ───"))
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestPrintSessionHistoryShowsLastExchanges(t *testing.T) {
+	messages := []kubiya.ChatMessage{
+		{MessageID: "1", SenderName: "You", Content: "first question"},
+		{MessageID: "2", Content: "first answer"},
+		{MessageID: "3", SenderName: "You", Content: "second question"},
+		{MessageID: "4", Type: "tool", Content: "kubectl\n{\"command\":\"get pods\"}"},
+		{MessageID: "5", Type: "tool_output", Content: "pod-a Running"},
+		{MessageID: "6", Content: "second answer"},
+	}
+
+	if got := len(groupExchanges(messages)); got != 2 {
+		t.Fatalf("expected 2 exchanges, got %d", got)
+	}
+
+	var out bytes.Buffer
+	printSessionHistory(&out, "session-1", messages, 1)
+	got := out.String()
+
+	if strings.Contains(got, "first question") {
+		t.Errorf("expected older exchanges to be skipped, got %q", got)
+	}
+	for _, want := range []string{"second question", "kubectl", "second answer", "1 earlier exchange"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got %q", want, got)
+		}
+	}
+	if strings.Contains(got, "pod-a Running") {
+		t.Errorf("expected tool output to be omitted, got %q", got)
+	}
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+)
+
+// Targets of --notify-on-complete
+const (
+	notifySlack   = "slack"
+	notifyWebhook = "webhook"
+)
+
+// notifyTimeout bounds the delivery of one completion notification
+const notifyTimeout = 30 * time.Second
+
+// maxNotifyResponse caps the excerpt of the agent's answer in notifications
+const maxNotifyResponse = 1000
+
+// chatCompletion is the summary of a finished chat that notifiers deliver
+type chatCompletion struct {
+	Status        string    `json:"status"` // succeeded or failed
+	Error         string    `json:"error,omitempty"`
+	Agent         string    `json:"agent,omitempty"`
+	Message       string    `json:"message"`
+	Response      string    `json:"response,omitempty"`
+	ToolCalls     int       `json:"tool_calls"`
+	SessionID     string    `json:"session_id,omitempty"`
+	ResumeCommand string    `json:"resume_command,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Duration      string    `json:"duration"`
+}
+
+// text is the summary as a short chat message
+func (c chatCompletion) text() string {
+	var b strings.Builder
+	icon := "✅"
+	if c.Status != "succeeded" {
+		icon = "❌"
+	}
+	agent := c.Agent
+	if agent == "" {
+		agent = "unknown"
+	}
+	fmt.Fprintf(&b, "%s Kubiya chat with agent *%s* %s after %s", icon, agent, c.Status, c.Duration)
+	if c.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s", c.Error)
+	}
+	fmt.Fprintf(&b, "\nPrompt: %s", truncateString(c.Message, 200))
+	if c.ToolCalls > 0 {
+		fmt.Fprintf(&b, "\nTool calls: %d", c.ToolCalls)
+	}
+	if c.ResumeCommand != "" {
+		fmt.Fprintf(&b, "\nSession: `%s`", c.ResumeCommand)
+	}
+	if c.Response != "" {
+		fmt.Fprintf(&b, "\n```%s```", c.Response)
+	}
+	return b.String()
+}
+
+// notifier delivers the summary of a finished chat
+type notifier interface {
+	Notify(ctx context.Context, c chatCompletion) error
+}
+
+// parseNotifyTarget builds the notifier of a --notify-on-complete value:
+// slack:#channel or webhook:https://...
+func parseNotifyTarget(target string) (notifier, error) {
+	kind, dest, ok := strings.Cut(target, ":")
+	if !ok || dest == "" {
+		return nil, fmt.Errorf("invalid --notify-on-complete %q (use %s:#channel or %s:https://...)", target, notifySlack, notifyWebhook)
+	}
+	switch kind {
+	case notifySlack:
+		token := os.Getenv("SLACK_BOT_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("--notify-on-complete %s needs a bot token in SLACK_BOT_TOKEN", target)
+		}
+		return &slackNotifier{slackAPI: newSlackAPI(token), channel: dest}, nil
+	case notifyWebhook:
+		u, err := url.Parse(dest)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid --notify-on-complete webhook URL %q", dest)
+		}
+		return &webhookNotifier{url: dest, http: httpclient.New(notifyTimeout)}, nil
+	default:
+		return nil, fmt.Errorf("invalid --notify-on-complete target %q (valid: %s, %s)", kind, notifySlack, notifyWebhook)
+	}
+}
+
+// slackNotifier posts the summary to a Slack channel
+type slackNotifier struct {
+	*slackAPI
+	channel string
+}
+
+func (s *slackNotifier) Notify(ctx context.Context, c chatCompletion) error {
+	var ignored struct{}
+	return s.call(ctx, "chat.postMessage", url.Values{"channel": {s.channel}, "text": {c.text()}}, &ignored)
+}
+
+// webhookNotifier posts the summary as JSON. The text field makes it render
+// in Slack and Teams incoming webhooks as well.
+type webhookNotifier struct {
+	url  string
+	http *http.Client
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, c chatCompletion) error {
+	body, err := json.Marshal(struct {
+		chatCompletion
+		Text string `json:"text"`
+	}{c, c.text()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// notifyCompletion delivers c to every notifier. Failed deliveries are
+// reported on w and do not change the outcome of the chat.
+func notifyCompletion(notifiers []notifier, c chatCompletion, w io.Writer) {
+	c.Response = truncateString(strings.TrimSpace(c.Response), maxNotifyResponse)
+	// The chat may have been cancelled, notifications are still sent
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, c); err != nil {
+			fmt.Fprintf(w, "⚠️  Failed to send completion notification: %v\n", err)
+		}
+	}
+}
+
+// chatContinuationCommand is the command that continues a chat session
+func chatContinuationCommand(agentName, agentID, sessionID string) string {
+	switch {
+	case agentName != "":
+		return fmt.Sprintf("kubiya chat -n %s --session %s -m \"your message here\"", agentName, sessionID)
+	case agentID != "":
+		return fmt.Sprintf("kubiya chat -t %s --session %s -m \"your message here\"", agentID, sessionID)
+	default:
+		return fmt.Sprintf("kubiya chat --session %s -m \"your message here\"", sessionID)
+	}
+}
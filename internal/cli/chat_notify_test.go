@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotifyTarget(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+
+	n, err := parseNotifyTarget("slack:#ops")
+	require.NoError(t, err)
+	assert.Equal(t, "#ops", n.(*slackNotifier).channel)
+
+	n, err = parseNotifyTarget("webhook:https://hooks.example.com/chat?x=1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/chat?x=1", n.(*webhookNotifier).url)
+
+	for _, target := range []string{"slack", "slack:", "email:me@example.com", "webhook:ftp://example.com", "webhook:not a url"} {
+		_, err := parseNotifyTarget(target)
+		assert.Error(t, err, target)
+	}
+
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	_, err = parseNotifyTarget("slack:#ops")
+	assert.ErrorContains(t, err, "SLACK_BOT_TOKEN")
+}
+
+func TestNotifyCompletion(t *testing.T) {
+	var got map[string]interface{}
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer webhook.Close()
+
+	var posted string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		assert.Equal(t, "#ops", r.Form.Get("channel"))
+		posted = r.Form.Get("text")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer slack.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer failing.Close()
+
+	api := newSlackAPI("xoxb-test")
+	api.baseURL = slack.URL
+	notifiers := []notifier{
+		&webhookNotifier{url: webhook.URL, http: http.DefaultClient},
+		&slackNotifier{slackAPI: api, channel: "#ops"},
+		&webhookNotifier{url: failing.URL, http: http.DefaultClient},
+	}
+
+	c := chatCompletion{
+		Status:        "failed",
+		Error:         "agent execution failed with reason: error",
+		Agent:         "ops",
+		Message:       "restart the web pods",
+		Response:      strings.Repeat("x", 2*maxNotifyResponse),
+		ToolCalls:     2,
+		SessionID:     "s-1",
+		ResumeCommand: chatContinuationCommand("ops", "", "s-1"),
+		StartedAt:     time.Now().Add(-time.Minute),
+		FinishedAt:    time.Now(),
+		Duration:      "1m0s",
+	}
+	var warnings bytes.Buffer
+	notifyCompletion(notifiers, c, &warnings)
+
+	assert.Equal(t, "failed", got["status"])
+	assert.Equal(t, "s-1", got["session_id"])
+	assert.EqualValues(t, 2, got["tool_calls"])
+	assert.Len(t, got["response"], maxNotifyResponse)
+	assert.Equal(t, posted, got["text"])
+
+	assert.True(t, strings.HasPrefix(posted, "❌ Kubiya chat with agent *ops* failed after 1m0s\nError: agent execution failed"), posted)
+	assert.Contains(t, posted, "kubiya chat -n ops --session s-1")
+
+	assert.Contains(t, warnings.String(), "webhook returned 410 Gone: gone")
+}
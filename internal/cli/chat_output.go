@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/kubiyabot/cli/internal/toolparse"
+)
+
+// Chat output formats
+const (
+	chatOutputText  = "text"
+	chatOutputJSONL = "jsonl"
+)
+
+// toolOutputParsing is the --parse-output setting of a chat
+type toolOutputParsing struct {
+	registry *toolparse.Registry
+	parser   string   // parser name or toolparse.Auto
+	columns  []string // columns to keep, from table:<columns>
+}
+
+// newToolOutputParsing resolves a --parse-output mode: auto, the name of a
+// parser, table or table:<columns>. Custom parsers are loaded from
+// ~/.kubiya/parsers.
+func newToolOutputParsing(mode string) (*toolOutputParsing, error) {
+	registry := toolparse.NewRegistry()
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		if err := registry.LoadDir(filepath.Join(homeDir, config.KUBIYA_DIR, "parsers")); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &toolOutputParsing{registry: registry, parser: mode}
+	if mode == "table" || strings.HasPrefix(mode, "table:") {
+		p.parser = toolparse.Auto
+		for _, c := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(mode, "table"), ":"), ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				p.columns = append(p.columns, c)
+			}
+		}
+	}
+	if _, ok := registry.Get(p.parser); !ok && p.parser != toolparse.Auto {
+		return nil, fmt.Errorf("invalid --parse-output %q (use auto, table:<columns> or one of: %s)", mode, strings.Join(registry.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Parse returns the output of tool as a table, or nil when no parser
+// understood it or output is not parsed
+func (p *toolOutputParsing) Parse(tool, output string) (*toolparse.Table, string) {
+	if p == nil {
+		return nil, ""
+	}
+	table, parser, err := p.registry.Parse(p.parser, tool, output)
+	if err != nil || table == nil {
+		return nil, ""
+	}
+	if len(p.columns) > 0 {
+		table = table.Select(p.columns)
+	}
+	return table, parser
+}
+
+// chatEvent is a line of chat --output jsonl
+type chatEvent struct {
+	Type      string                   `json:"type"`
+	ID        string                   `json:"id,omitempty"`
+	Tool      string                   `json:"tool,omitempty"`
+	Args      json.RawMessage          `json:"args,omitempty"`
+	Status    string                   `json:"status,omitempty"`
+	Duration  float64                  `json:"duration_seconds,omitempty"`
+	Parser    string                   `json:"parser,omitempty"`
+	Records   []map[string]interface{} `json:"records,omitempty"`
+	Output    string                   `json:"output,omitempty"`
+	Content   string                   `json:"content,omitempty"`
+	Path      string                   `json:"path,omitempty"`
+	MimeType  string                   `json:"mime_type,omitempty"`
+	Size      int                      `json:"size,omitempty"`
+	Timestamp string                   `json:"timestamp"`
+
+	table *toolparse.Table // parsed output, for text
+}
+
+// chatEventWriter writes chat events as JSON lines, or as text when the
+// stream is filtered with --only and --hide. Tool output is exported as
+// records when a parser understands it.
+type chatEventWriter struct {
+	out     io.Writer
+	text    bool
+	parsing *toolOutputParsing
+	filter  *chatStreamFilter
+}
+
+func newChatEventWriter(w io.Writer, parsing *toolOutputParsing) *chatEventWriter {
+	return &chatEventWriter{out: w, parsing: parsing}
+}
+
+// newChatTextEventWriter writes chat events as a compact transcript
+func newChatTextEventWriter(w io.Writer, parsing *toolOutputParsing) *chatEventWriter {
+	return &chatEventWriter{out: w, text: true, parsing: parsing}
+}
+
+func (w *chatEventWriter) write(e chatEvent) {
+	e, ok := w.filter.Apply(e)
+	if !ok {
+		return
+	}
+	if w.text {
+		w.render(e)
+		return
+	}
+	e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	_ = output.EncodeJSONLine(w.out, e)
+}
+
+// render prints an event as text
+func (w *chatEventWriter) render(e chatEvent) {
+	switch e.Type {
+	case "tool_call":
+		fmt.Fprintf(w.out, "⚡ %s %s\n", style.ToolExecutingStyle.Render(e.Tool), style.ValueStyle.Render(formatLiveJSON(string(e.Args))))
+	case "tool_output":
+		if e.Status == "failed" {
+			fmt.Fprintf(w.out, "   ⚠️  %s %s (%.1fs)\n", style.WarningStyle.Render(e.Tool), style.ErrorStyle.Render("failed"), e.Duration)
+		} else {
+			fmt.Fprintf(w.out, "   ✓ %s %s (%.1fs)\n", style.SuccessStyle.Render(e.Tool), "completed", e.Duration)
+		}
+		if e.table != nil {
+			e.table.Render(w.out)
+		} else if output := strings.TrimSpace(e.Output); output != "" {
+			fmt.Fprintln(w.out, output)
+		}
+	case "artifact":
+		fmt.Fprintf(w.out, "   📎 %s saved %s\n", e.Tool, e.Path)
+	case "prompt":
+		fmt.Fprintf(w.out, "> %s\n", e.Content)
+	case "message":
+		fmt.Fprintln(w.out, style.AgentStyle.Render(strings.TrimSpace(e.Content)))
+	case "error":
+		fmt.Fprintln(w.out, style.ErrorStyle.Render("❌ "+e.Content))
+	}
+}
+
+// ToolCall records the start of a tool call
+func (w *chatEventWriter) ToolCall(id, tool, args string) {
+	e := chatEvent{Type: "tool_call", ID: id, Tool: tool}
+	if json.Valid([]byte(args)) {
+		e.Args = json.RawMessage(args)
+	}
+	w.write(e)
+}
+
+// ToolOutput records the output of a finished tool call
+func (w *chatEventWriter) ToolOutput(id, tool, status string, duration time.Duration, output string) {
+	e := chatEvent{Type: "tool_output", ID: id, Tool: tool, Status: status, Duration: duration.Round(time.Millisecond).Seconds()}
+	if table, parser := w.parsing.Parse(tool, output); table != nil {
+		e.Parser, e.Records, e.table = parser, table.Rows, table
+	} else {
+		e.Output = toolparse.Text(output)
+	}
+	w.write(e)
+}
+
+// Artifact records a file returned by the agent or a tool and saved to path
+func (w *chatEventWriter) Artifact(id, tool, path, mimeType string, size int) {
+	w.write(chatEvent{Type: "artifact", ID: id, Tool: tool, Path: path, MimeType: mimeType, Size: size})
+}
+
+// Prompt records a message sent to the agent
+func (w *chatEventWriter) Prompt(sessionID, content string) {
+	w.write(chatEvent{Type: "prompt", ID: sessionID, Content: content})
+}
+
+// Message records a complete agent message
+func (w *chatEventWriter) Message(id, content string) {
+	w.write(chatEvent{Type: "message", ID: id, Content: content})
+}
+
+// Error records an error ending the chat
+func (w *chatEventWriter) Error(content string) {
+	w.write(chatEvent{Type: "error", Content: content})
+}
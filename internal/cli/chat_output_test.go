@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolOutputParsing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".kubiya", "parsers"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".kubiya", "parsers", "df.yaml"),
+		[]byte("pattern: '^(?P<fs>/\\S+)\\s+(?P<use>\\d+%)'\n"), 0644))
+
+	p, err := newToolOutputParsing("table:metadata.name,status.phase")
+	require.NoError(t, err)
+	table, parser := p.Parse("kubectl", `"{\"items\":[{\"metadata\":{\"name\":\"web\"},\"status\":{\"phase\":\"Running\"}}]}"`)
+	require.NotNil(t, table)
+	assert.Equal(t, "json", parser)
+	assert.Equal(t, []string{"metadata.name", "status.phase"}, table.Columns)
+	assert.Equal(t, "Running", table.Rows[0]["status.phase"])
+
+	p, err = newToolOutputParsing("df")
+	require.NoError(t, err)
+	table, _ = p.Parse("df", `"/dev/sda1 42%\n/dev/sdb1 7%"`)
+	require.NotNil(t, table)
+	assert.Len(t, table.Rows, 2)
+
+	table, _ = p.Parse("echo", `"plain text"`)
+	assert.Nil(t, table)
+
+	_, err = newToolOutputParsing("csv")
+	assert.ErrorContains(t, err, "invalid --parse-output")
+}
+
+func TestChatEventWriter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	p, err := newToolOutputParsing("auto")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	w := newChatEventWriter(&out, p)
+	w.ToolCall("call-1", "kubectl", `{"command":"get pods -o json"}`)
+	w.ToolOutput("call-1", "kubectl", "completed", 1500*time.Millisecond, `"[{\"name\":\"web\"}]"`)
+	w.ToolOutput("call-2", "echo", "completed", time.Second, `"hello"`)
+	w.Message("msg-1", "All pods are running")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	var events []chatEvent
+	for _, line := range lines {
+		var e chatEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+	assert.Equal(t, "tool_call", events[0].Type)
+	assert.JSONEq(t, `{"command":"get pods -o json"}`, string(events[0].Args))
+	assert.Equal(t, "json", events[1].Parser)
+	assert.Equal(t, "web", events[1].Records[0]["name"])
+	assert.Equal(t, 1.5, events[1].Duration)
+	assert.Equal(t, "hello", events[2].Output)
+	assert.Equal(t, "All pods are running", events[3].Content)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// chatPermissions checks the tool calls of a chat session against its
+// --permission-level. Only ask acts on them: it confirms every call, and
+// denies without an interactive terminal. read and readwrite are left to the
+// agent, which gets the level in the permissions field of the message; the
+// stream does not say which tools only read, so the CLI cannot tell them
+// apart.
+type chatPermissions struct {
+	level       string
+	interactive bool
+	in          *bufio.Reader
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newChatPermissions(level string, interactive bool, in io.Reader, out io.Writer) *chatPermissions {
+	return &chatPermissions{
+		level:       level,
+		interactive: interactive,
+		in:          bufio.NewReader(in),
+		out:         out,
+	}
+}
+
+// SetOutput changes where denials and prompts are written
+func (p *chatPermissions) SetOutput(out io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out = out
+}
+
+// Gate is the kubiya.ToolCallGate of the chat session
+func (p *chatPermissions) Gate(ctx context.Context, call kubiya.ToolCall) error {
+	if p.level != kubiya.PermissionAsk {
+		return nil
+	}
+
+	// One prompt at a time when tools are called in parallel
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.interactive {
+		fmt.Fprintf(p.out, "%s\n", style.ErrorStyle.Render(
+			fmt.Sprintf("🛑 Blocked tool %s: permission level ask needs a terminal to confirm it", call.Name)))
+		return fmt.Errorf("%w: %s needs confirmation but there is no terminal", kubiya.ErrToolCallDenied, call.Name)
+	}
+	return confirmToolCall(ctx, p.in, p.out, call, "needs confirmation (permission level ask)")
+}
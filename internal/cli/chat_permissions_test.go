@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestChatPermissionsGate(t *testing.T) {
+	ctx := context.Background()
+	read := kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"get pods -n prod"}`}
+	change := kubiya.ToolCall{Name: "kubectl", Arguments: `{"command":"delete ns prod"}`}
+	var out bytes.Buffer
+
+	p := newChatPermissions(kubiya.PermissionRead, true, strings.NewReader("y\n"), &out)
+	assert.NoError(t, p.Gate(ctx, read))
+	assert.NoError(t, p.Gate(ctx, change), "read is left to the agent")
+	assert.Empty(t, out.String())
+
+	p = newChatPermissions(kubiya.PermissionReadWrite, false, nil, &out)
+	assert.NoError(t, p.Gate(ctx, change))
+
+	out.Reset()
+	p = newChatPermissions(kubiya.PermissionAsk, true, strings.NewReader("y\n\n"), &out)
+	assert.NoError(t, p.Gate(ctx, change))
+	assert.Contains(t, out.String(), "Allow this tool call?")
+	err := p.Gate(ctx, read)
+	assert.True(t, errors.Is(err, kubiya.ErrToolCallDenied), "ask confirms read-only calls too")
+
+	p = newChatPermissions(kubiya.PermissionAsk, false, strings.NewReader("y\n"), &out)
+	err = p.Gate(ctx, read)
+	assert.True(t, errors.Is(err, kubiya.ErrToolCallDenied), "denied without a terminal")
+}
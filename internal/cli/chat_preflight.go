@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// maxToolSuggestions caps how many alternative agents a failed pre-flight
+// check suggests
+const maxToolSuggestions = 5
+
+// agentToolLookup is the part of kubiya.Client used by the pre-flight check
+type agentToolLookup interface {
+	GetAgents(ctx context.Context) ([]kubiya.Agent, error)
+	GetSourceMetadataCached(ctx context.Context, sourceUUID string) (*kubiya.Source, error)
+}
+
+// parseRequiredTools normalizes --require-tools values, which may be given
+// comma separated or repeated
+func parseRequiredTools(values []string) []string {
+	seen := map[string]bool{}
+	var tools []string
+	for _, v := range values {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			tools = append(tools, name)
+		}
+	}
+	return tools
+}
+
+// agentToolNames returns the lower-cased names of the tools an agent can use:
+// the tools set on the agent and those of all of its sources
+func agentToolNames(ctx context.Context, client agentToolLookup, tools, sources []string) (map[string]bool, error) {
+	names := make(map[string]bool)
+	for _, t := range tools {
+		names[strings.ToLower(t)] = true
+	}
+	for _, uuid := range sources {
+		source, err := client.GetSourceMetadataCached(ctx, uuid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tools of source %s: %w", uuid, err)
+		}
+		for _, t := range append(source.Tools, source.InlineTools...) {
+			names[strings.ToLower(t.Name)] = true
+		}
+	}
+	return names, nil
+}
+
+// missingTools returns the required tools not in available
+func missingTools(available map[string]bool, required []string) []string {
+	var missing []string
+	for _, name := range required {
+		if !available[strings.ToLower(name)] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// suggestAgentsWithTools returns the names of agents other than exclude that
+// have access to all required tools. Agents whose sources cannot be read are
+// skipped.
+func suggestAgentsWithTools(ctx context.Context, client agentToolLookup, required []string, exclude string) ([]string, error) {
+	agents, err := client.GetAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	var names []string
+	for _, agent := range agents {
+		if agent.UUID == exclude {
+			continue
+		}
+		available, err := agentToolNames(ctx, client, agent.Tools, agent.Sources)
+		if err != nil {
+			continue
+		}
+		if len(missingTools(available, required)) == 0 {
+			names = append(names, agent.Name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > maxToolSuggestions {
+		names = names[:maxToolSuggestions]
+	}
+	return names, nil
+}
+
+// checkRequiredTools fails when the agent lacks any of the required tools.
+// The error lists the missing tools and agents that have all of them.
+func checkRequiredTools(ctx context.Context, client agentToolLookup, agent *kubiya.Agent, extraSources, required []string) error {
+	available, err := agentToolNames(ctx, client, agent.Tools, append(append([]string(nil), agent.Sources...), extraSources...))
+	if err != nil {
+		return err
+	}
+	missing := missingTools(available, required)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("agent %s does not have access to required tools: %s", agent.Name, strings.Join(missing, ", "))
+	suggestions, err := suggestAgentsWithTools(ctx, client, required, agent.UUID)
+	switch {
+	case err != nil:
+	case len(suggestions) > 0:
+		msg += fmt.Sprintf("\nAgents with all required tools: %s (use --name to select one)", strings.Join(suggestions, ", "))
+	default:
+		msg += "\nNo agent has all required tools; add a source providing them with 'kubiya source add'"
+	}
+	return fmt.Errorf("%s", msg)
+}
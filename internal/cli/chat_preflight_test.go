@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeToolLookup struct {
+	agents  []kubiya.Agent
+	sources map[string][]string
+}
+
+func (f *fakeToolLookup) GetAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	return f.agents, nil
+}
+
+func (f *fakeToolLookup) GetSourceMetadataCached(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	names, ok := f.sources[uuid]
+	if !ok {
+		return nil, fmt.Errorf("source %s not found", uuid)
+	}
+	source := &kubiya.Source{UUID: uuid}
+	for _, name := range names {
+		source.Tools = append(source.Tools, kubiya.Tool{Name: name})
+	}
+	return source, nil
+}
+
+func TestParseRequiredTools(t *testing.T) {
+	got := parseRequiredTools([]string{"kubectl, helm", "Helm", "", "aws"})
+	want := []string{"kubectl", "helm", "aws"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRequiredTools() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckRequiredTools(t *testing.T) {
+	lookup := &fakeToolLookup{
+		agents: []kubiya.Agent{
+			{UUID: "a1", Name: "devops", Sources: []string{"s-k8s"}},
+			{UUID: "a2", Name: "platform", Sources: []string{"s-k8s", "s-helm"}},
+			{UUID: "a3", Name: "broken", Sources: []string{"s-missing"}},
+			{UUID: "a4", Name: "cloud", Tools: []string{"kubectl", "helm"}},
+		},
+		sources: map[string][]string{
+			"s-k8s":  {"kubectl"},
+			"s-helm": {"helm"},
+			"s-tmp":  {"helm"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		agent        kubiya.Agent
+		extraSources []string
+		required     []string
+		wantErr      []string
+	}{
+		{
+			name:     "all tools available",
+			agent:    lookup.agents[1],
+			required: []string{"KUBECTL", "helm"},
+		},
+		{
+			name:         "tool provided by a session source",
+			agent:        lookup.agents[0],
+			extraSources: []string{"s-tmp"},
+			required:     []string{"kubectl", "helm"},
+		},
+		{
+			name:     "missing tool suggests other agents",
+			agent:    lookup.agents[0],
+			required: []string{"kubectl", "helm"},
+			wantErr:  []string{"devops does not have access to required tools: helm", "cloud, platform"},
+		},
+		{
+			name:     "no agent has the tools",
+			agent:    lookup.agents[0],
+			required: []string{"terraform"},
+			wantErr:  []string{"required tools: terraform", "No agent has all required tools"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := tt.agent
+			err := checkRequiredTools(context.Background(), lookup, &agent, tt.extraSources, tt.required)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("checkRequiredTools() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("checkRequiredTools() expected an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+const (
+	toolProgressFrameInterval = 100 * time.Millisecond
+	// toolProgressDetailWidth caps the output line shown under a tool
+	toolProgressDetailWidth = 80
+	// defaultToolProgressWidth is assumed when the terminal size is unknown
+	defaultToolProgressWidth = 80
+)
+
+var toolProgressSpinner = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// toolProgressRow is a single live line in the tool progress area
+type toolProgressRow struct {
+	key     string
+	name    string
+	status  string
+	detail  string // last line of tool output
+	stderr  bool   // detail was written to stderr
+	started time.Time
+}
+
+// toolProgressRenderer owns the terminal while a chat response streams.
+// Permanent output (agent text, tool results) is written through the renderer
+// so it always lands above a live area with one row per running tool. A single
+// render loop redraws the live area, so concurrent tools never fight over the
+// cursor. Without a TTY no live area is drawn and only permanent lines and
+// status changes are printed.
+type toolProgressRenderer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	tty     bool
+	width   int // columns of the terminal, to count wrapped lines
+	rows    []*toolProgressRow
+	drawn   int          // number of live lines currently on screen
+	partial bytes.Buffer // permanent output waiting for a newline
+	frame   int
+
+	stop     chan struct{}
+	done     chan struct{}
+	started  bool
+	stopOnce sync.Once
+}
+
+func newToolProgressRenderer(out io.Writer, tty bool) *toolProgressRenderer {
+	width := defaultToolProgressWidth
+	if f, ok := out.(*os.File); ok && tty {
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 0 {
+			width = w
+		}
+	}
+	return &toolProgressRenderer{
+		out:   out,
+		tty:   tty,
+		width: width,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start launches the render loop. It is a no-op without a TTY.
+func (r *toolProgressRenderer) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.tty || r.started {
+		return
+	}
+	r.started = true
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(toolProgressFrameInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.mu.Lock()
+				r.frame++
+				r.redrawLocked()
+				r.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop ends the render loop, clears the live area and flushes pending output
+func (r *toolProgressRenderer) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		r.mu.Lock()
+		started := r.started
+		r.mu.Unlock()
+		if started {
+			<-r.done
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.clearLocked()
+		if r.partial.Len() > 0 {
+			r.partial.WriteByte('\n')
+			r.out.Write(r.partial.Bytes())
+			r.partial.Reset()
+		}
+		r.rows = nil
+	})
+}
+
+// SetRow adds a live row or updates the status of an existing one
+func (r *toolProgressRenderer) SetRow(key, name, status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, row := range r.rows {
+		if row.key == key {
+			if row.status != status && !r.tty {
+				fmt.Fprintf(r.out, "⚡ %s %s\n", name, status)
+			}
+			row.name, row.status = name, status
+			r.redrawLocked()
+			return
+		}
+	}
+
+	r.rows = append(r.rows, &toolProgressRow{key: key, name: name, status: status, started: time.Now()})
+	if !r.tty {
+		fmt.Fprintf(r.out, "⚡ %s %s\n", name, status)
+	}
+	r.redrawLocked()
+}
+
+// SetDetail shows the latest line of output under a running tool
+func (r *toolProgressRenderer) SetDetail(key, output string) {
+	r.setDetail(key, output, false)
+}
+
+// SetStderrDetail is SetDetail for output the tool wrote to stderr
+func (r *toolProgressRenderer) SetStderrDetail(key, output string) {
+	r.setDetail(key, output, true)
+}
+
+func (r *toolProgressRenderer) setDetail(key, output string, stderr bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	for _, row := range r.rows {
+		if row.key == key {
+			row.detail, row.stderr = last, stderr
+			r.redrawLocked()
+			return
+		}
+	}
+}
+
+// RemoveRow drops a live row, typically right before its result is printed
+func (r *toolProgressRenderer) RemoveRow(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, row := range r.rows {
+		if row.key == key {
+			r.rows = append(r.rows[:i], r.rows[i+1:]...)
+			r.redrawLocked()
+			return
+		}
+	}
+}
+
+// Write prints permanent output above the live area. Only complete lines are
+// emitted; a trailing partial line is held until its newline arrives.
+func (r *toolProgressRenderer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partial.Write(p)
+	data := r.partial.Bytes()
+	idx := bytes.LastIndexByte(data, '\n')
+	if idx < 0 {
+		return len(p), nil
+	}
+
+	r.clearLocked()
+	if _, err := r.out.Write(data[:idx+1]); err != nil {
+		return 0, err
+	}
+	rest := append([]byte(nil), data[idx+1:]...)
+	r.partial.Reset()
+	r.partial.Write(rest)
+	r.drawLocked()
+	return len(p), nil
+}
+
+func (r *toolProgressRenderer) redrawLocked() {
+	if !r.tty {
+		return
+	}
+	r.clearLocked()
+	r.drawLocked()
+}
+
+// clearLocked erases the live area and leaves the cursor where it started
+func (r *toolProgressRenderer) clearLocked() {
+	if !r.tty || r.drawn == 0 {
+		return
+	}
+	fmt.Fprintf(r.out, "\033[%dA\r\033[J", r.drawn)
+	r.drawn = 0
+}
+
+func (r *toolProgressRenderer) drawLocked() {
+	if !r.tty || len(r.rows) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	lines := 0
+	writeLine := func(line string) {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+		lines += r.screenLines(line)
+	}
+	spinner := toolProgressSpinner[r.frame%len(toolProgressSpinner)]
+	for _, row := range r.rows {
+		elapsed := time.Since(row.started).Truncate(100 * time.Millisecond)
+		writeLine(fmt.Sprintf("⚡ %s %s %s %s",
+			style.ToolExecutingStyle.Render(row.name),
+			style.SpinnerStyle.Render(spinner),
+			style.DimStyle.Render(row.status),
+			style.DimStyle.Render(fmt.Sprintf("(%.1fs)", elapsed.Seconds()))))
+		if row.detail != "" {
+			detailStyle := style.DimStyle
+			if row.stderr {
+				detailStyle = style.StderrStyle
+			}
+			writeLine("   " + detailStyle.Render(truncateRunes(row.detail, toolProgressDetailWidth)))
+		}
+	}
+
+	fmt.Fprint(r.out, sb.String())
+	r.drawn = lines
+}
+
+// screenLines returns how many terminal lines line takes once wrapped
+func (r *toolProgressRenderer) screenLines(line string) int {
+	width := lipgloss.Width(line)
+	if r.width <= 0 || width <= r.width {
+		return 1
+	}
+	return (width + r.width - 1) / r.width
+}
+
+// truncateRunes shortens s to max characters, ending with "...", without
+// cutting a multi-byte character in half
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToolProgressRendererNonTTY(t *testing.T) {
+	var out bytes.Buffer
+	r := newToolProgressRenderer(&out, false)
+	r.Start()
+
+	r.SetRow("1", "kubectl", "executing...")
+	r.SetRow("2", "helm", "executing...")
+	fmt.Fprint(r, "partial")
+	if strings.Contains(out.String(), "partial") {
+		t.Fatalf("expected partial line to be buffered, got %q", out.String())
+	}
+	fmt.Fprintln(r, " line")
+	r.RemoveRow("1")
+	r.Stop()
+
+	got := out.String()
+	if strings.Contains(got, "\033[") {
+		t.Errorf("expected no escape sequences without a TTY, got %q", got)
+	}
+	for _, want := range []string{"⚡ kubectl executing...", "⚡ helm executing...", "partial line\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestToolProgressRendererTTYKeepsRowsBelowOutput(t *testing.T) {
+	var out bytes.Buffer
+	r := newToolProgressRenderer(&out, true)
+
+	r.SetRow("1", "kubectl", "executing...")
+	fmt.Fprintln(r, "agent text")
+	r.RemoveRow("1")
+	r.Stop()
+
+	got := out.String()
+	textIdx := strings.Index(got, "agent text")
+	if textIdx < 0 {
+		t.Fatalf("expected permanent output, got %q", got)
+	}
+	if !strings.Contains(got[:textIdx], "\033[1A\r\033[J") {
+		t.Fatalf("expected live row to be cleared before permanent output, got %q", got)
+	}
+	if r.drawn != 0 {
+		t.Errorf("expected live area to be cleared after Stop, %d lines remain", r.drawn)
+	}
+}
+
+func TestToolProgressRendererStderrDetail(t *testing.T) {
+	var out bytes.Buffer
+	r := newToolProgressRenderer(&out, false)
+
+	r.SetRow("1", "make", "executing...")
+	r.SetStderrDetail("1", "warning: unused variable\n")
+	if row := r.rows[0]; !row.stderr || row.detail != "warning: unused variable" {
+		t.Fatalf("expected stderr detail, got %+v", row)
+	}
+
+	r.SetDetail("1", "build ok")
+	if row := r.rows[0]; row.stderr || row.detail != "build ok" {
+		t.Errorf("expected stdout detail to replace stderr detail, got %+v", row)
+	}
+}
+
+func TestToolProgressRendererConcurrentCallsOfSameTool(t *testing.T) {
+	var out bytes.Buffer
+	r := newToolProgressRenderer(&out, true)
+
+	r.SetRow("msg-1", "kubectl", "Generating parameters...")
+	r.SetRow("msg-2", "kubectl", "Generating parameters...")
+	r.RemoveRow("msg-1")
+	r.SetRow("msg-1", "kubectl", "executing...")
+	r.SetDetail("msg-1", "pod/api restarted")
+
+	if len(r.rows) != 2 {
+		t.Fatalf("expected a row per call, got %d", len(r.rows))
+	}
+	if row := r.rows[0]; row.key != "msg-2" || row.status != "Generating parameters..." || row.detail != "" {
+		t.Errorf("expected the second call to keep its own row, got %+v", row)
+	}
+	if row := r.rows[1]; row.key != "msg-1" || row.status != "executing..." || row.detail != "pod/api restarted" {
+		t.Errorf("expected the first call to be executing, got %+v", row)
+	}
+	if r.drawn != 3 {
+		t.Errorf("expected 3 live lines, got %d", r.drawn)
+	}
+}
+
+func TestToolProgressRendererCountsWrappedLines(t *testing.T) {
+	var out bytes.Buffer
+	r := newToolProgressRenderer(&out, true)
+	r.width = 20
+
+	r.SetRow("1", "kubectl", "executing...")
+	if r.drawn != 2 {
+		t.Errorf("expected the row to wrap onto 2 lines, got %d", r.drawn)
+	}
+	r.Stop()
+	if !strings.Contains(out.String(), "\033[2A\r\033[J") {
+		t.Errorf("expected both wrapped lines to be cleared, got %q", out.String())
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	detail := strings.Repeat("é", 100)
+	got := truncateRunes(detail, 80)
+	if !utf8.ValidString(got) {
+		t.Fatalf("expected valid UTF-8, got %q", got)
+	}
+	if want := strings.Repeat("é", 77) + "..."; got != want {
+		t.Errorf("truncateRunes() = %q, want %q", got, want)
+	}
+	if got := truncateRunes("short", 80); got != "short" {
+		t.Errorf("truncateRunes() = %q, want short", got)
+	}
+}
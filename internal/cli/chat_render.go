@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/mattn/go-isatty"
+)
+
+// Render modes for agent responses in chat
+const (
+	renderModePlain    = "plain"
+	renderModeMarkdown = "markdown"
+
+	markdownWrapWidth = 100
+)
+
+// resolveRenderMode validates the --render flag. An empty value selects
+// markdown on interactive terminals and plain text everywhere else, so
+// scripts piping the output keep receiving unformatted text.
+func resolveRenderMode(mode string, automationMode bool) (string, error) {
+	switch mode {
+	case renderModePlain, renderModeMarkdown:
+		return mode, nil
+	case "":
+		if automationMode || !isatty.IsTerminal(os.Stdout.Fd()) {
+			return renderModePlain, nil
+		}
+		return renderModeMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid --render value %q (must be %s or %s)", mode, renderModePlain, renderModeMarkdown)
+	}
+}
+
+// markdownStreamRenderer renders streamed markdown as terminal-formatted text.
+// Content is buffered until a block is complete (a paragraph ends or a code
+// fence closes) so partial constructs such as tables and fences are never
+// rendered half way.
+type markdownStreamRenderer struct {
+	out      io.Writer
+	renderer *glamour.TermRenderer
+	block    strings.Builder // complete lines of the current block
+	line     strings.Builder // incomplete trailing line
+	inFence  bool
+}
+
+func newMarkdownStreamRenderer(out io.Writer) (*markdownStreamRenderer, error) {
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(markdownWrapWidth),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create markdown renderer: %w", err)
+	}
+
+	return &markdownStreamRenderer{out: out, renderer: renderer}, nil
+}
+
+// Write appends streamed content and renders every block it completes
+func (r *markdownStreamRenderer) Write(content string) {
+	for _, char := range content {
+		if char != '\n' {
+			r.line.WriteRune(char)
+			continue
+		}
+
+		line := r.line.String()
+		r.line.Reset()
+		r.block.WriteString(line)
+		r.block.WriteByte('\n')
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			r.inFence = !r.inFence
+			if !r.inFence {
+				r.renderBlock()
+			}
+		case trimmed == "" && !r.inFence:
+			r.renderBlock()
+		}
+	}
+}
+
+// Flush renders whatever is left in the buffer, closing an open code fence
+func (r *markdownStreamRenderer) Flush() {
+	if r.line.Len() > 0 {
+		r.block.WriteString(r.line.String())
+		r.block.WriteByte('\n')
+		r.line.Reset()
+	}
+	if r.inFence {
+		r.block.WriteString("```\n")
+		r.inFence = false
+	}
+	r.renderBlock()
+}
+
+func (r *markdownStreamRenderer) renderBlock() {
+	text := r.block.String()
+	r.block.Reset()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	out, err := r.renderer.Render(text)
+	if err != nil {
+		// Never lose content because of a rendering problem
+		fmt.Fprint(r.out, text)
+		return
+	}
+	fmt.Fprint(r.out, strings.Trim(out, "\n")+"\n")
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownStreamRendererBuffersBlocks(t *testing.T) {
+	var out bytes.Buffer
+	r, err := newMarkdownStreamRenderer(&out)
+	if err != nil {
+		t.Fatalf("newMarkdownStreamRenderer() error = %v", err)
+	}
+
+	r.Write("Here is **bold** text")
+	if out.Len() != 0 {
+		t.Fatalf("expected incomplete paragraph to stay buffered, got %q", out.String())
+	}
+
+	r.Write(" and more.\n\n```go\nfunc main() {}\n")
+	if !strings.Contains(out.String(), "bold") {
+		t.Fatalf("expected completed paragraph to be rendered, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "func main") {
+		t.Fatalf("expected open code fence to stay buffered, got %q", out.String())
+	}
+
+	r.Flush()
+	if !strings.Contains(out.String(), "func main") {
+		t.Errorf("expected flush to render the unterminated code block, got %q", out.String())
+	}
+}
+
+func TestResolveRenderMode(t *testing.T) {
+	if mode, err := resolveRenderMode("", true); err != nil || mode != renderModePlain {
+		t.Errorf("expected plain in automation mode, got %q (err %v)", mode, err)
+	}
+	if mode, err := resolveRenderMode(renderModeMarkdown, true); err != nil || mode != renderModeMarkdown {
+		t.Errorf("expected explicit markdown to be honored, got %q (err %v)", mode, err)
+	}
+	if _, err := resolveRenderMode("html", false); err == nil {
+		t.Error("expected error for unknown render mode")
+	}
+}
@@ -0,0 +1,749 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// defaultScenarioStepTimeout bounds a step of a scenario without a timeout
+const defaultScenarioStepTimeout = 5 * time.Minute
+
+// Where assertions and captures look
+const (
+	scenarioInResponse   = "response"
+	scenarioInToolOutput = "tool_output"
+)
+
+// chatScenario is a conversation with an agent, scripted in a YAML file, and
+// what the agent must answer at each step
+type chatScenario struct {
+	Name    string            `yaml:"name" json:"name,omitempty"`
+	Agent   string            `yaml:"agent" json:"agent,omitempty"`     // name or UUID
+	Vars    map[string]string `yaml:"vars" json:"vars,omitempty"`       // available as {{ .name }}
+	Timeout string            `yaml:"timeout" json:"timeout,omitempty"` // of each step, e.g. 5m
+	Steps   []scenarioStep    `yaml:"steps" json:"steps"`
+}
+
+// scenarioStep is one prompt of a scenario
+type scenarioStep struct {
+	Name    string                     `yaml:"name" json:"name,omitempty"`
+	Prompt  string                     `yaml:"prompt" json:"prompt"`
+	Timeout string                     `yaml:"timeout" json:"timeout,omitempty"`
+	Expect  []scenarioAssertion        `yaml:"expect" json:"expect,omitempty"`
+	Capture map[string]scenarioCapture `yaml:"capture" json:"capture,omitempty"` // variables for later steps
+}
+
+// scenarioAssertion checks the answer to a step. Exactly one of response,
+// not_response, tool_called, tool_output and json_path is set.
+type scenarioAssertion struct {
+	Response    string `yaml:"response" json:"response,omitempty"`         // regex the reply matches
+	NotResponse string `yaml:"not_response" json:"not_response,omitempty"` // regex the reply does not match
+	ToolCalled  string `yaml:"tool_called" json:"tool_called,omitempty"`   // regex the name of a tool call matches
+	ToolOutput  string `yaml:"tool_output" json:"tool_output,omitempty"`   // regex the output of a tool matches
+	// JSONPath selects a value in the JSON of the reply, or of a tool output
+	// with in: tool_output, e.g. $.items[0].status. The value must exist and
+	// be equal to equals or match matches when they are set.
+	JSONPath string      `yaml:"json_path" json:"json_path,omitempty"`
+	In       string      `yaml:"in" json:"in,omitempty"`
+	Equals   interface{} `yaml:"equals" json:"equals,omitempty"`
+	Matches  string      `yaml:"matches" json:"matches,omitempty"`
+}
+
+// scenarioCapture sets a variable from the answer to a step
+type scenarioCapture struct {
+	In       string `yaml:"in" json:"in,omitempty"`
+	Regex    string `yaml:"regex" json:"regex,omitempty"` // the first group, or the whole match
+	JSONPath string `yaml:"json_path" json:"json_path,omitempty"`
+}
+
+// chatTurn is the answer of the agent to a prompt
+type chatTurn struct {
+	Reply       string   `json:"reply"`
+	ToolCalls   []string `json:"tool_calls,omitempty"`
+	ToolOutputs []string `json:"tool_outputs,omitempty"`
+}
+
+// scenarioStepResult is the outcome of a step
+type scenarioStepResult struct {
+	Name     string        `json:"name"`
+	Prompt   string        `json:"prompt"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Failures []string      `json:"failures,omitempty"`
+	Turn     *chatTurn     `json:"turn,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// scenarioReport is the outcome of a scenario
+type scenarioReport struct {
+	Scenario  string               `json:"scenario"`
+	AgentID   string               `json:"agent_id"`
+	SessionID string               `json:"session_id"`
+	Passed    bool                 `json:"passed"`
+	Steps     []scenarioStepResult `json:"steps"`
+	Duration  time.Duration        `json:"duration"`
+}
+
+// loadChatScenario reads and checks a scenario file
+func loadChatScenario(path string) (*chatScenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var s chatScenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func (s *chatScenario) validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	if _, err := parseScenarioTimeout(s.Timeout, defaultScenarioStepTimeout); err != nil {
+		return err
+	}
+	for i, step := range s.Steps {
+		where := fmt.Sprintf("step %d", i+1)
+		if strings.TrimSpace(step.Prompt) == "" {
+			return fmt.Errorf("%s: prompt is required", where)
+		}
+		if _, err := parseScenarioTimeout(step.Timeout, 0); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		for j, a := range step.Expect {
+			if err := a.validate(); err != nil {
+				return fmt.Errorf("%s, expectation %d: %w", where, j+1, err)
+			}
+		}
+		for name, c := range step.Capture {
+			if (c.Regex == "") == (c.JSONPath == "") {
+				return fmt.Errorf("%s, capture %s: set one of regex and json_path", where, name)
+			}
+			if err := validScenarioIn(c.In); err != nil {
+				return fmt.Errorf("%s, capture %s: %w", where, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func parseScenarioTimeout(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	return d, nil
+}
+
+func validScenarioIn(in string) error {
+	switch in {
+	case "", scenarioInResponse, scenarioInToolOutput:
+		return nil
+	}
+	return fmt.Errorf("invalid in %q (valid: %s, %s)", in, scenarioInResponse, scenarioInToolOutput)
+}
+
+func (a scenarioAssertion) validate() error {
+	set := 0
+	for _, v := range []string{a.Response, a.NotResponse, a.ToolCalled, a.ToolOutput, a.JSONPath} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("set exactly one of response, not_response, tool_called, tool_output and json_path")
+	}
+	if a.JSONPath == "" && (a.In != "" || a.Equals != nil || a.Matches != "") {
+		return fmt.Errorf("in, equals and matches only apply to json_path")
+	}
+	return validScenarioIn(a.In)
+}
+
+// expandScenarioVars replaces the {{ .var }} references of s
+func expandScenarioVars(s string, vars map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("scenario").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// scenarioRunner plays a scenario in a single chat session
+type scenarioRunner struct {
+	client    chatSubmitter
+	agentID   string
+	sessionID string
+	vars      map[string]string
+	progress  io.Writer
+}
+
+func (r *scenarioRunner) run(ctx context.Context, s *chatScenario) scenarioReport {
+	report := scenarioReport{Scenario: s.Name, AgentID: r.agentID, SessionID: r.sessionID, Passed: true}
+	start := time.Now()
+	defaultTimeout, _ := parseScenarioTimeout(s.Timeout, defaultScenarioStepTimeout)
+
+	stopped := false
+	for i, step := range s.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+		result := scenarioStepResult{Name: name, Prompt: step.Prompt}
+		if stopped {
+			result.Skipped = true
+			report.Steps = append(report.Steps, result)
+			continue
+		}
+
+		stepStart := time.Now()
+		timeout, _ := parseScenarioTimeout(step.Timeout, defaultTimeout)
+		fatal := r.runStep(ctx, step, timeout, &result)
+		result.Duration = time.Since(stepStart)
+		result.Passed = len(result.Failures) == 0
+		report.Steps = append(report.Steps, result)
+		if !result.Passed {
+			report.Passed = false
+		}
+		r.printStep(result)
+		// Later steps build on the conversation, which is broken now
+		if fatal || ctx.Err() != nil {
+			stopped = true
+		}
+	}
+	report.SessionID = r.sessionID
+	report.Duration = time.Since(start)
+	return report
+}
+
+// runStep sends the prompt of a step and checks its answer, returning true
+// when the conversation cannot go on
+func (r *scenarioRunner) runStep(ctx context.Context, step scenarioStep, timeout time.Duration, result *scenarioStepResult) bool {
+	prompt, err := expandScenarioVars(step.Prompt, r.vars)
+	if err != nil {
+		result.Failures = append(result.Failures, fmt.Sprintf("prompt: %v", err))
+		return true
+	}
+	result.Prompt = prompt
+	if r.progress != nil {
+		fmt.Fprintf(r.progress, "%s %s\n", style.UserIconStyle.Render("👤"), firstLine(prompt))
+	}
+
+	stepCtx, cancel := contextWithTimeout(ctx, timeout)
+	defer cancel()
+	turn, err := r.send(stepCtx, prompt)
+	if err != nil {
+		if stepCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("no answer after %v", timeout)
+		}
+		result.Failures = append(result.Failures, err.Error())
+		return true
+	}
+	result.Turn = &turn
+
+	for _, a := range step.Expect {
+		if failure := r.check(a, turn); failure != "" {
+			result.Failures = append(result.Failures, failure)
+		}
+	}
+	for name, c := range step.Capture {
+		value, err := captureScenarioVar(c, turn)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("capture %s: %v", name, err))
+			continue
+		}
+		r.vars[name] = value
+	}
+	return false
+}
+
+// send sends a prompt and collects the whole answer of the agent
+func (r *scenarioRunner) send(ctx context.Context, prompt string) (chatTurn, error) {
+	msgChan, err := r.client.SendMessageWithContext(ctx, r.agentID, prompt, r.sessionID, nil)
+	if err != nil {
+		return chatTurn{}, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	// Messages are streamed whole again with every update
+	var turn chatTurn
+	replies, outputs := map[string]string{}, map[string]string{}
+	var replyOrder, outputOrder []string
+	seen := map[string]bool{}
+	for msg := range msgChan {
+		if msg.Type == "error" || msg.Error != "" {
+			return chatTurn{}, fmt.Errorf("agent error: %s", firstNonEmpty(msg.Error, msg.Content))
+		}
+		if msg.SessionID != "" {
+			r.sessionID = msg.SessionID
+		}
+		switch msg.Type {
+		case "tool":
+			name, _ := splitToolCall(msg.Content)
+			if !seen[msg.MessageID+name] {
+				seen[msg.MessageID+name] = true
+				turn.ToolCalls = append(turn.ToolCalls, name)
+			}
+		case "tool_output":
+			if _, ok := outputs[msg.MessageID]; !ok {
+				outputOrder = append(outputOrder, msg.MessageID)
+			}
+			if len(msg.Content) >= len(outputs[msg.MessageID]) {
+				outputs[msg.MessageID] = msg.Content
+			}
+		case "system", "warning":
+		default:
+			if msg.SenderName == "You" || msg.MessageID == "" {
+				continue
+			}
+			if _, ok := replies[msg.MessageID]; !ok {
+				replyOrder = append(replyOrder, msg.MessageID)
+			}
+			if len(msg.Content) >= len(replies[msg.MessageID]) {
+				replies[msg.MessageID] = msg.Content
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return chatTurn{}, err
+	}
+
+	var reply []string
+	for _, id := range replyOrder {
+		if content := strings.TrimSpace(replies[id]); content != "" {
+			reply = append(reply, content)
+		}
+	}
+	turn.Reply = strings.Join(reply, "\n")
+	for _, id := range outputOrder {
+		turn.ToolOutputs = append(turn.ToolOutputs, outputs[id])
+	}
+	return turn, nil
+}
+
+// check returns why the answer does not meet a, or "" when it does
+func (r *scenarioRunner) check(a scenarioAssertion, turn chatTurn) string {
+	expand := func(s string) (string, error) { return expandScenarioVars(s, r.vars) }
+	match := func(kind, pattern string, texts []string, want bool) string {
+		pattern, err := expand(pattern)
+		if err != nil {
+			return fmt.Sprintf("%s: %v", kind, err)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Sprintf("%s: invalid regex: %v", kind, err)
+		}
+		for _, text := range texts {
+			if re.MatchString(text) {
+				if want {
+					return ""
+				}
+				return fmt.Sprintf("%s: %q matched %q", kind, pattern, truncateString(text, 200))
+			}
+		}
+		if !want {
+			return ""
+		}
+		if len(texts) == 0 {
+			return fmt.Sprintf("%s: %q, but there was none", kind, pattern)
+		}
+		return fmt.Sprintf("%s: %q did not match %q", kind, pattern, truncateString(strings.Join(texts, "\n"), 200))
+	}
+
+	switch {
+	case a.Response != "":
+		return match("response", a.Response, []string{turn.Reply}, true)
+	case a.NotResponse != "":
+		return match("not_response", a.NotResponse, []string{turn.Reply}, false)
+	case a.ToolCalled != "":
+		return match("tool_called", a.ToolCalled, turn.ToolCalls, true)
+	case a.ToolOutput != "":
+		return match("tool_output", a.ToolOutput, turn.ToolOutputs, true)
+	}
+
+	var lastFailure string
+	for _, text := range scenarioTexts(a.In, turn) {
+		failure := r.checkJSONPath(a, text)
+		if failure == "" {
+			return ""
+		}
+		lastFailure = failure
+	}
+	if lastFailure == "" {
+		lastFailure = fmt.Sprintf("json_path %s: no %s", a.JSONPath, firstNonEmpty(a.In, scenarioInResponse))
+	}
+	return lastFailure
+}
+
+func (r *scenarioRunner) checkJSONPath(a scenarioAssertion, text string) string {
+	doc, err := extractJSON(text)
+	if err != nil {
+		return fmt.Sprintf("json_path %s: %v", a.JSONPath, err)
+	}
+	value, err := lookupJSONPath(doc, a.JSONPath)
+	if err != nil {
+		return fmt.Sprintf("json_path %s: %v", a.JSONPath, err)
+	}
+	if a.Equals != nil {
+		want := a.Equals
+		if s, ok := want.(string); ok {
+			if want, err = expandScenarioVars(s, r.vars); err != nil {
+				return fmt.Sprintf("json_path %s: %v", a.JSONPath, err)
+			}
+		}
+		got, _ := json.Marshal(value)
+		expected, _ := json.Marshal(want)
+		if !bytes.Equal(got, expected) {
+			return fmt.Sprintf("json_path %s: got %s, want %s", a.JSONPath, got, expected)
+		}
+	}
+	if a.Matches != "" {
+		pattern, err := expandScenarioVars(a.Matches, r.vars)
+		if err != nil {
+			return fmt.Sprintf("json_path %s: %v", a.JSONPath, err)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Sprintf("json_path %s: invalid regex: %v", a.JSONPath, err)
+		}
+		if s := jsonScalarString(value); !re.MatchString(s) {
+			return fmt.Sprintf("json_path %s: %q did not match %q", a.JSONPath, s, pattern)
+		}
+	}
+	return ""
+}
+
+// scenarioTexts returns the reply or the tool outputs of a turn
+func scenarioTexts(in string, turn chatTurn) []string {
+	if in == scenarioInToolOutput {
+		return turn.ToolOutputs
+	}
+	return []string{turn.Reply}
+}
+
+// captureScenarioVar returns the value a capture selects in a turn, from
+// the first text it matches
+func captureScenarioVar(c scenarioCapture, turn chatTurn) (string, error) {
+	texts := scenarioTexts(c.In, turn)
+	if c.Regex != "" {
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex: %w", err)
+		}
+		for _, text := range texts {
+			if m := re.FindStringSubmatch(text); m != nil {
+				if len(m) > 1 {
+					return m[1], nil
+				}
+				return m[0], nil
+			}
+		}
+		return "", fmt.Errorf("%q did not match", c.Regex)
+	}
+
+	var lastErr error
+	for _, text := range texts {
+		doc, err := extractJSON(text)
+		if err == nil {
+			var value interface{}
+			if value, err = lookupJSONPath(doc, c.JSONPath); err == nil {
+				return jsonScalarString(value), nil
+			}
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("nothing to capture from")
+	}
+	return "", lastErr
+}
+
+// jsonScalarString formats a JSON value for regexes and variables: strings
+// as they are, anything else as JSON
+func jsonScalarString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// fencedJSON matches a fenced code block in a markdown reply
+var fencedJSON = regexp.MustCompile("(?s)```(?:json)?\\s*\\n(.*?)```")
+
+// extractJSON decodes the JSON of a reply or tool output: the whole text,
+// its first fenced code block, or the text from its first { or [ on
+func extractJSON(text string) (interface{}, error) {
+	candidates := []string{strings.TrimSpace(text)}
+	if m := fencedJSON.FindStringSubmatch(text); m != nil {
+		candidates = append(candidates, m[1])
+	}
+	if i := strings.IndexAny(text, "{["); i >= 0 {
+		candidates = append(candidates, text[i:])
+	}
+	for _, c := range candidates {
+		var v interface{}
+		dec := json.NewDecoder(strings.NewReader(c))
+		if err := dec.Decode(&v); err == nil {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("no JSON found")
+}
+
+// jsonPathToken matches the parts of a JSON path: .key, ["key"] and [0]
+var jsonPathToken = regexp.MustCompile(`^(?:\.([^.\[]+)|\["([^"]*)"\]|\[(\d+)\])`)
+
+// lookupJSONPath returns the value at a path such as $.items[0].status
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+	value := doc
+	walked := "$"
+	for rest != "" {
+		m := jsonPathToken.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid path at %q", rest)
+		}
+		rest = rest[len(m[0]):]
+		if m[3] != "" {
+			i, _ := strconv.Atoi(m[3])
+			list, ok := value.([]interface{})
+			if !ok || i >= len(list) {
+				return nil, fmt.Errorf("no element %d in %s", i, walked)
+			}
+			value = list[i]
+		} else {
+			key := m[1] + m[2]
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not an object", walked)
+			}
+			if value, ok = obj[key]; !ok {
+				return nil, fmt.Errorf("no %s in %s", key, walked)
+			}
+		}
+		walked += m[0]
+	}
+	return value, nil
+}
+
+func (r *scenarioRunner) printStep(result scenarioStepResult) {
+	if r.progress == nil {
+		return
+	}
+	if result.Passed {
+		fmt.Fprintf(r.progress, "%s %s %s\n\n", style.SuccessStyle.Render("✓"), result.Name,
+			style.DimStyle.Render(fmt.Sprintf("(%.1fs)", result.Duration.Seconds())))
+		return
+	}
+	fmt.Fprintf(r.progress, "%s %s\n", style.ErrorStyle.Render("✗"), result.Name)
+	for _, f := range result.Failures {
+		fmt.Fprintf(r.progress, "    %s\n", f)
+	}
+	fmt.Fprintln(r.progress)
+}
+
+func printScenarioReport(w io.Writer, report scenarioReport) {
+	passed, skipped := 0, 0
+	for _, s := range report.Steps {
+		switch {
+		case s.Skipped:
+			skipped++
+		case s.Passed:
+			passed++
+		}
+	}
+	summary := fmt.Sprintf("%d of %d steps passed", passed, len(report.Steps))
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if report.Passed {
+		fmt.Fprintf(w, "%s Scenario %s passed: %s in %.1fs\n", style.SuccessStyle.Render("✅"), report.Scenario, summary, report.Duration.Seconds())
+	} else {
+		fmt.Fprintf(w, "%s Scenario %s failed: %s\n", style.ErrorStyle.Render("❌"), report.Scenario, summary)
+	}
+	fmt.Fprintf(w, "%s Session: %s\n", style.DimStyle.Render("💬"), report.SessionID)
+}
+
+// scenarioTestResults converts a report for the JUnit report of tool tests
+func scenarioTestResults(report scenarioReport) []toolTestResult {
+	results := make([]toolTestResult, 0, len(report.Steps))
+	for _, s := range report.Steps {
+		r := toolTestResult{Tool: report.Scenario, Test: s.Name, Passed: s.Passed, Duration: s.Duration}
+		switch {
+		case s.Skipped:
+			r.Failure = "skipped after a failed step"
+		case !s.Passed:
+			r.Failure = strings.Join(s.Failures, "\n")
+		}
+		if s.Turn != nil {
+			r.Output = s.Turn.Reply
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func newChatRunScenarioCommand(cfg *config.Config) *cobra.Command {
+	var (
+		agent        string
+		vars         []string
+		junitFile    string
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run-scenario <scenario.yaml>",
+		Short: "🧪 Run a scripted conversation with an agent and check its answers",
+		Long: `Run the prompts of a scenario file one after the other in a single chat
+session, and check each answer against the expectations of the step: regexes
+on the reply, the tools called or their output, and JSON paths into a JSON
+reply or tool output. Values captured from an answer are available to later
+steps as {{ .name }}, like the vars of the scenario and --var.
+
+The command fails when a step fails, so scenarios can be used as regression
+tests of agent behavior in CI.`,
+		Example: `  # Run a scenario
+  kubiya chat run-scenario scenarios/restart.yaml
+
+  # Against another agent, with a JUnit report
+  kubiya chat run-scenario scenarios/restart.yaml --agent devbot-staging --junit scenario.xml
+
+  # scenarios/restart.yaml
+  name: restart api
+  agent: devbot
+  vars:
+    namespace: staging
+  steps:
+    - prompt: List the pods of the api in {{ .namespace }} as JSON
+      expect:
+        - tool_called: kubectl
+        - json_path: $.items[0].status.phase
+          equals: Running
+      capture:
+        pod:
+          json_path: $.items[0].metadata.name
+    - prompt: Restart {{ .pod }}
+      expect:
+        - response: (?i)restarted
+        - not_response: (?i)error`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scenario, err := loadChatScenario(args[0])
+			if err != nil {
+				return err
+			}
+			if agent == "" {
+				agent = scenario.Agent
+			}
+			if agent == "" {
+				return fmt.Errorf("no agent: set agent in the scenario or use --agent")
+			}
+
+			runVars := map[string]string{}
+			for k, v := range scenario.Vars {
+				runVars[k] = v
+			}
+			overrides, err := parseEnvAssignments(vars)
+			if err != nil {
+				return fmt.Errorf("invalid --var: %w", err)
+			}
+			for k, v := range overrides {
+				runVars[k] = v
+			}
+
+			client := kubiya.NewClient(cfg)
+			agentID, err := resolveAgentRef(cmd.Context(), client, agent)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			runner := &scenarioRunner{
+				client:    client,
+				agentID:   agentID,
+				sessionID: uuid.New().String(),
+				vars:      runVars,
+				progress:  out,
+			}
+			if outputFormat == "json" {
+				runner.progress = nil
+			} else {
+				fmt.Fprintf(out, "%s Running scenario %s with agent %s\n\n",
+					style.InfoStyle.Render("🧪"), style.HighlightStyle.Render(scenario.Name), style.HighlightStyle.Render(agent))
+			}
+			report := runner.run(cmd.Context(), scenario)
+
+			if junitFile != "" {
+				f, err := os.Create(junitFile)
+				if err != nil {
+					return fmt.Errorf("failed to create JUnit report: %w", err)
+				}
+				err = writeJUnitReport(f, scenario.Name, scenarioTestResults(report))
+				if cerr := f.Close(); err == nil {
+					err = cerr
+				}
+				if err != nil {
+					return fmt.Errorf("failed to write JUnit report: %w", err)
+				}
+			}
+
+			if outputFormat == "json" {
+				if err := printJSON(report); err != nil {
+					return err
+				}
+			} else {
+				printScenarioReport(out, report)
+			}
+			if !report.Passed {
+				return fmt.Errorf("scenario %s failed", scenario.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&agent, "agent", "n", "", "Agent name or UUID, instead of the agent of the scenario")
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Set a scenario variable, as NAME=VALUE (repeatable)")
+	cmd.Flags().StringVar(&junitFile, "junit", "", "Write a JUnit XML report to this file")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// scenarioChat answers each prompt with the messages of the first entry of
+// replies whose key it contains
+type scenarioChat struct {
+	replies  map[string][]kubiya.ChatMessage
+	messages []string
+}
+
+func (c *scenarioChat) SendMessageWithContext(ctx context.Context, agentID, message, sessionID string, context map[string]string) (<-chan kubiya.ChatMessage, error) {
+	c.messages = append(c.messages, message)
+	var reply []kubiya.ChatMessage
+	for key, msgs := range c.replies {
+		if strings.Contains(message, key) {
+			reply = msgs
+		}
+	}
+	ch := make(chan kubiya.ChatMessage, len(reply))
+	for _, msg := range reply {
+		ch <- msg
+	}
+	close(ch)
+	return ch, nil
+}
+
+func writeScenario(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "restart.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+const restartScenario = `
+agent: devbot
+vars:
+  namespace: staging
+steps:
+  - name: list pods
+    prompt: List the pods in {{ .namespace }}
+    expect:
+      - tool_called: ^kubectl$
+      - tool_output: Running
+      - json_path: $.items[0].status.phase
+        equals: Running
+      - json_path: $.items[0].metadata.name
+        matches: ^api-
+    capture:
+      pod:
+        json_path: $.items[0].metadata.name
+  - name: restart
+    prompt: Restart {{ .pod }}
+    expect:
+      - response: (?i)restarted api-7f9
+      - not_response: (?i)error
+`
+
+func TestLoadChatScenario(t *testing.T) {
+	s, err := loadChatScenario(writeScenario(t, restartScenario))
+	require.NoError(t, err)
+	assert.Equal(t, "restart", s.Name)
+	assert.Equal(t, "devbot", s.Agent)
+	require.Len(t, s.Steps, 2)
+	assert.Len(t, s.Steps[0].Expect, 4)
+
+	for name, content := range map[string]string{
+		"no steps":       "agent: devbot\n",
+		"unknown key":    "steps:\n  - prompt: hi\n    expects: []\n",
+		"no prompt":      "steps:\n  - name: empty\n",
+		"two checks":     "steps:\n  - prompt: hi\n    expect:\n      - response: a\n        tool_called: b\n",
+		"stray equals":   "steps:\n  - prompt: hi\n    expect:\n      - response: a\n        equals: b\n",
+		"bad timeout":    "timeout: soon\nsteps:\n  - prompt: hi\n",
+		"bad capture":    "steps:\n  - prompt: hi\n    capture:\n      x: {}\n",
+		"bad capture in": "steps:\n  - prompt: hi\n    capture:\n      x: {regex: a, in: logs}\n",
+	} {
+		_, err := loadChatScenario(writeScenario(t, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestScenarioRunnerPasses(t *testing.T) {
+	s, err := loadChatScenario(writeScenario(t, restartScenario))
+	require.NoError(t, err)
+
+	chat := &scenarioChat{replies: map[string][]kubiya.ChatMessage{
+		"List the pods": {
+			{Type: "tool", MessageID: "t1", Content: "Tool: kubectl Arguments: get pods -o json"},
+			{Type: "tool_output", MessageID: "t1", Content: "api-7f9 Running"},
+			{MessageID: "m1", Content: "Here they are:\n```json\n{\"items\": [{\"metadata\": {\"name\": \"api-7f9\"}, \"status\": {\"phase\": \"Running\"}}]}\n```"},
+		},
+		"Restart": {
+			{MessageID: "m2", Content: "Restarted"},
+			{MessageID: "m2", Content: "Restarted api-7f9", Final: true},
+		},
+	}}
+	var out bytes.Buffer
+	runner := &scenarioRunner{client: chat, agentID: "a-1", sessionID: "s-1", vars: map[string]string{"namespace": "staging"}, progress: &out}
+	report := runner.run(context.Background(), s)
+
+	assert.True(t, report.Passed, out.String())
+	assert.Equal(t, []string{"List the pods in staging", "Restart api-7f9"}, chat.messages)
+	require.Len(t, report.Steps, 2)
+	assert.Equal(t, "Restarted api-7f9", report.Steps[1].Turn.Reply)
+}
+
+func TestScenarioRunnerReportsFailures(t *testing.T) {
+	s, err := loadChatScenario(writeScenario(t, restartScenario))
+	require.NoError(t, err)
+
+	chat := &scenarioChat{replies: map[string][]kubiya.ChatMessage{
+		"List the pods": {{MessageID: "m1", Content: `{"items": [{"metadata": {"name": "web-1"}, "status": {"phase": "Pending"}}]}`}},
+	}}
+	runner := &scenarioRunner{client: chat, agentID: "a-1", sessionID: "s-1", vars: map[string]string{"namespace": "staging"}}
+	report := runner.run(context.Background(), s)
+
+	assert.False(t, report.Passed)
+	failures := report.Steps[0].Failures
+	require.Len(t, failures, 4)
+	assert.Contains(t, failures[0], "tool_called")
+	assert.Contains(t, failures[1], "there was none")
+	assert.Contains(t, failures[2], `got "Pending", want "Running"`)
+	assert.Contains(t, failures[3], `"web-1" did not match`)
+	// The capture still worked, the next step ran and failed on its own
+	assert.Equal(t, "Restart web-1", chat.messages[1])
+	assert.False(t, report.Steps[1].Passed)
+
+	results := scenarioTestResults(report)
+	require.Len(t, results, 2)
+	assert.Equal(t, "restart", results[0].Tool)
+	assert.False(t, results[0].Passed)
+}
+
+func TestScenarioRunnerStopsOnAgentError(t *testing.T) {
+	s, err := loadChatScenario(writeScenario(t, restartScenario))
+	require.NoError(t, err)
+
+	chat := &scenarioChat{replies: map[string][]kubiya.ChatMessage{
+		"List the pods": {{Type: "error", Error: "agent not found"}},
+	}}
+	runner := &scenarioRunner{client: chat, agentID: "a-1", sessionID: "s-1", vars: map[string]string{"namespace": "staging"}}
+	report := runner.run(context.Background(), s)
+
+	assert.False(t, report.Passed)
+	assert.Contains(t, report.Steps[0].Failures[0], "agent not found")
+	assert.True(t, report.Steps[1].Skipped)
+	assert.Len(t, chat.messages, 1)
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc, err := extractJSON(`Result: {"items": [{"name": "a", "labels": {"app.kubernetes.io/name": "api"}}], "count": 1}`)
+	require.NoError(t, err)
+
+	v, err := lookupJSONPath(doc, "$.items[0].name")
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+
+	v, err = lookupJSONPath(doc, `items[0].labels["app.kubernetes.io/name"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "api", v)
+
+	v, err = lookupJSONPath(doc, "$.count")
+	require.NoError(t, err)
+	assert.Equal(t, "1", jsonScalarString(v))
+
+	for _, path := range []string{"$.items[1]", "$.missing", "$.count.x", "$.items[x]"} {
+		_, err := lookupJSONPath(doc, path)
+		assert.Error(t, err, path)
+	}
+
+	_, err = extractJSON("no json here")
+	assert.Error(t, err)
+}
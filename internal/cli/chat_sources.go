@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// ephemeralSources are local tool directories uploaded as sources for the
+// duration of a single chat session
+type ephemeralSources struct {
+	client  *kubiya.Client
+	runner  string
+	sources []*kubiya.Source
+	// agent the sources are bound to, which every user of the agent sees
+	// until Cleanup unbinds them
+	agent string
+	bound []*kubiya.Source
+	once  sync.Once
+}
+
+// validateSourceDirs checks that every --with-source path is a directory
+func validateSourceDirs(dirs []string) error {
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("--with-source %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("--with-source %s: not a directory", dir)
+		}
+	}
+	return nil
+}
+
+// createEphemeralSources uploads each directory as a zip source on runner.
+// Sources created before a failure are removed again.
+func createEphemeralSources(ctx context.Context, client *kubiya.Client, dirs []string, runner string) (*ephemeralSources, error) {
+	e := &ephemeralSources{client: client, runner: runner}
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			e.Cleanup()
+			return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+
+		name := fmt.Sprintf("chat-%s-%d", filepath.Base(abs), time.Now().Unix())
+		source, err := client.CreateSource(ctx, abs, kubiya.WithName(name), kubiya.WithRunner(runner))
+		if err != nil {
+			e.Cleanup()
+			return nil, fmt.Errorf("failed to create source from %s: %w", dir, err)
+		}
+		e.sources = append(e.sources, source)
+	}
+	return e, nil
+}
+
+// UUIDs returns the IDs of the uploaded sources
+func (e *ephemeralSources) UUIDs() []string {
+	ids := make([]string, 0, len(e.sources))
+	for _, s := range e.sources {
+		ids = append(ids, s.UUID)
+	}
+	return ids
+}
+
+// Bind attaches the sources to agent. Bindings made before a failure are
+// undone by Cleanup.
+func (e *ephemeralSources) Bind(ctx context.Context, agent string) error {
+	e.agent = agent
+	for _, s := range e.sources {
+		if err := e.client.BindSourceToAgent(ctx, s.UUID, agent); err != nil {
+			return fmt.Errorf("failed to attach temporary source %s: %w", s.Name, err)
+		}
+		e.bound = append(e.bound, s)
+	}
+	return nil
+}
+
+// Print lists the uploaded sources next to the directories they came from
+func (e *ephemeralSources) Print(w io.Writer, dirs []string) {
+	for i, source := range e.sources {
+		fmt.Fprintf(w, "📦 Attached %s as temporary source %s (%d tools)\n",
+			dirs[i], style.HighlightStyle.Render(source.Name), len(source.Tools)+len(source.InlineTools))
+	}
+}
+
+// cancelOnSignal cancels the context of cmd when the chat is interrupted,
+// so that the stream ends and the deferred cleanups, such as the removal of
+// the temporary sources, run before the command returns
+func cancelOnSignal(cmd *cobra.Command) context.CancelFunc {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	cmd.SetContext(ctx)
+	return stop
+}
+
+// Cleanup unbinds the uploaded sources from the agent, so that it is left as
+// it was even when a deletion fails, and then deletes them. It is safe to
+// call more than once.
+func (e *ephemeralSources) Cleanup() {
+	e.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		for _, s := range e.bound {
+			if err := e.client.UnbindSourceFromAgent(ctx, s.UUID, e.agent); err != nil {
+				fmt.Fprintf(os.Stderr, "%s failed to detach temporary source %s (%s) from agent %s: %v\n",
+					style.WarningStyle.Render("⚠️"), s.Name, s.UUID, e.agent, err)
+			}
+		}
+		for _, s := range e.sources {
+			if err := e.client.DeleteSource(ctx, s.UUID, e.runner); err != nil {
+				fmt.Fprintf(os.Stderr, "%s failed to delete temporary source %s (%s): %v\n",
+					style.WarningStyle.Render("⚠️"), s.Name, s.UUID, err)
+			}
+		}
+	})
+}
+
+// appendInlineAgentSources adds source IDs to the sources of an inline agent
+// request, which may come from flags ([]string) or an agent spec ([]interface{})
+func appendInlineAgentSources(agent map[string]interface{}, ids []string) {
+	var sources []string
+	switch existing := agent["sources"].(type) {
+	case []string:
+		sources = append(sources, existing...)
+	case []interface{}:
+		for _, s := range existing {
+			if id, ok := s.(string); ok {
+				sources = append(sources, id)
+			}
+		}
+	}
+	for _, id := range ids {
+		if !contains(sources, id) {
+			sources = append(sources, id)
+		}
+	}
+	agent["sources"] = sources
+}
+
+// sourceGetter is the part of kubiya.Client used to look up --source IDs
+type sourceGetter interface {
+	GetSource(ctx context.Context, uuid string) (*kubiya.Source, error)
+}
+
+// lookupInlineSources fetches the platform sources given with --source, so
+// that a mistyped ID fails before the inline agent is created
+func lookupInlineSources(ctx context.Context, client sourceGetter, ids []string) ([]*kubiya.Source, error) {
+	sources := make([]*kubiya.Source, 0, len(ids))
+	for _, id := range ids {
+		source, err := client.GetSource(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("--source %s: %w", id, err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// printInlineSources lists the platform sources an inline agent uses
+func printInlineSources(w io.Writer, sources []*kubiya.Source) {
+	for _, source := range sources {
+		fmt.Fprintf(w, "📦 Using source %s (%d tools)\n",
+			style.HighlightStyle.Render(source.Name), len(source.Tools)+len(source.InlineTools))
+	}
+}
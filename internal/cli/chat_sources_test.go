@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/spf13/cobra"
+)
+
+func TestValidateSourceDirs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tool.py")
+	if err := os.WriteFile(file, []byte("print('hi')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validateSourceDirs([]string{dir}); err != nil {
+		t.Errorf("expected directory to be accepted, got %v", err)
+	}
+	if err := validateSourceDirs([]string{file}); err == nil {
+		t.Error("expected a file to be rejected")
+	}
+	if err := validateSourceDirs([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected a missing path to be rejected")
+	}
+}
+
+func TestAppendInlineAgentSources(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent map[string]interface{}
+		want  []string
+	}{
+		{"from flags", map[string]interface{}{"sources": []string{"a"}}, []string{"a", "tmp"}},
+		{"from agent spec", map[string]interface{}{"sources": []interface{}{"a", "b"}}, []string{"a", "b", "tmp"}},
+		{"no sources", map[string]interface{}{}, []string{"tmp"}},
+		{"already attached", map[string]interface{}{"sources": []string{"tmp"}}, []string{"tmp"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appendInlineAgentSources(tt.agent, []string{"tmp"})
+			if got := tt.agent["sources"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sources = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSourceGetter serves the sources it holds
+type fakeSourceGetter map[string]*kubiya.Source
+
+func (f fakeSourceGetter) GetSource(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	if source, ok := f[uuid]; ok {
+		return source, nil
+	}
+	return nil, fmt.Errorf("failed to fetch source: not found")
+}
+
+func TestLookupInlineSources(t *testing.T) {
+	client := fakeSourceGetter{
+		"src-1": {UUID: "src-1", Name: "k8s-tools", Tools: []kubiya.Tool{{Name: "kubectl"}}},
+	}
+
+	sources, err := lookupInlineSources(context.Background(), client, []string{"src-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sources) != 1 || sources[0].Name != "k8s-tools" {
+		t.Errorf("sources = %v, want k8s-tools", sources)
+	}
+
+	var out strings.Builder
+	printInlineSources(&out, sources)
+	if !strings.Contains(out.String(), "(1 tools)") {
+		t.Errorf("output = %q, want the tool count", out.String())
+	}
+
+	_, err = lookupInlineSources(context.Background(), client, []string{"src-1", "typo"})
+	if err == nil || !strings.Contains(err.Error(), "--source typo") {
+		t.Errorf("err = %v, want the missing source named", err)
+	}
+}
+
+func TestEphemeralSourcesLifecycle(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created []string
+		deleted []string
+		bound   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/sources/zip":
+			created = append(created, r.URL.Query().Get("name"))
+			json.NewEncoder(w).Encode(kubiya.Source{UUID: "src-1", Name: r.URL.Query().Get("name")})
+		case r.Method == http.MethodPost && r.URL.Path == "/sources/src-1/agents/agent-1":
+			bound = append(bound, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "tools")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tool.py"), []byte("print('hi')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := kubiya.NewClient(&config.Config{BaseURL: server.URL, APIKey: "test"})
+	ephemeral, err := createEphemeralSources(context.Background(), client, []string{dir}, "runner-1")
+	if err != nil {
+		t.Fatalf("createEphemeralSources: %v", err)
+	}
+	if got := ephemeral.UUIDs(); !reflect.DeepEqual(got, []string{"src-1"}) {
+		t.Errorf("UUIDs() = %v", got)
+	}
+	if len(created) != 1 || !strings.HasPrefix(created[0], "chat-tools-") {
+		t.Fatalf("expected one source named after the directory, got %v", created)
+	}
+
+	if err := ephemeral.Bind(context.Background(), "agent-1"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if len(bound) != 1 {
+		t.Fatalf("expected the source to be bound to the agent, got %v", bound)
+	}
+
+	ephemeral.Cleanup()
+	ephemeral.Cleanup()
+	want := []string{"/sources/src-1/agents/agent-1", "/sources/src-1"}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("expected the source to be unbound and then deleted once, got %v", deleted)
+	}
+}
+
+func TestCancelOnSignalCancelsCommandContext(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	stop := cancelOnSignal(cmd)
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-cmd.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the command context to be canceled on SIGTERM")
+	}
+}
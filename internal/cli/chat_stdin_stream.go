@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+const (
+	// maxStdinStreamLine bounds a single line of --stdin-stream input
+	maxStdinStreamLine = 1024 * 1024
+	// maxStdinStreamFailures stops the stream after this many messages in a
+	// row failed, as when the token expired or the agent is gone
+	maxStdinStreamFailures = 3
+)
+
+// scanStdinMessages reads messages from r until it is closed: one per line,
+// or with a delimiter one per block of lines between delimiter lines. Blank
+// messages are skipped.
+func scanStdinMessages(r io.Reader, delimiter string, emit func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStdinStreamLine)
+
+	var block strings.Builder
+	flush := func() error {
+		message := strings.TrimSpace(block.String())
+		block.Reset()
+		if message == "" {
+			return nil
+		}
+		return emit(message)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if delimiter == "" {
+			block.WriteString(line)
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == delimiter {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		block.WriteString(line)
+		block.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading stdin: %w", err)
+	}
+	return flush()
+}
+
+// chatStdinStream sends every message read from stdin as a follow-up in the same
+// session, waiting for the reply to each before sending the next
+type chatStdinStream struct {
+	client    chatSubmitter
+	agentID   string
+	sessionID string
+	// context is sent with the first message only, later ones are in the
+	// same session
+	context map[string]string
+	out     io.Writer
+	events  *chatEventWriter
+	// bridge, when set, mirrors the messages and replies with --bridge
+	bridge chatBridge
+
+	sent     int
+	failures int
+}
+
+// send sends a message and prints the reply, returning an error only when
+// too many messages in a row failed
+func (s *chatStdinStream) send(ctx context.Context, message string) error {
+	if s.events != nil {
+		s.events.Prompt(s.sessionID, message)
+	} else {
+		fmt.Fprintf(s.out, "%s %s\n", style.UserIconStyle.Render("👤"), firstLine(message))
+	}
+	if s.bridge != nil {
+		s.mirror(s.bridge.Prompt(ctx, message))
+	}
+	return s.deliver(ctx, message)
+}
+
+// relay sends a message a teammate wrote in the bridged conversation, naming
+// them to the agent
+func (s *chatStdinStream) relay(ctx context.Context, m bridgeMessage) error {
+	message := fmt.Sprintf("[%s via %s] %s", m.From, s.bridge.Name(), m.Text)
+	if s.events != nil {
+		s.events.Prompt(s.sessionID, message)
+	} else {
+		fmt.Fprintf(s.out, "%s %s (%s): %s\n", style.UserIconStyle.Render("💬"), m.From, s.bridge.Name(), firstLine(m.Text))
+	}
+	return s.deliver(ctx, message)
+}
+
+func (s *chatStdinStream) deliver(ctx context.Context, message string) error {
+	context := s.context
+	if s.sent > 0 {
+		context = nil
+	}
+	s.sent++
+
+	err := s.reply(ctx, message, context)
+	if err == nil {
+		s.failures = 0
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	s.failures++
+	fmt.Fprintf(os.Stderr, "%s %v\n", style.ErrorStyle.Render("❌"), err)
+	if s.bridge != nil {
+		s.mirror(s.bridge.Reply(ctx, "❌ "+err.Error(), nil))
+	}
+	if s.failures >= maxStdinStreamFailures {
+		return fmt.Errorf("stopping after %d failed messages in a row: %w", s.failures, err)
+	}
+	return nil
+}
+
+func (s *chatStdinStream) reply(ctx context.Context, message string, context map[string]string) error {
+	msgChan, err := s.client.SendMessageWithContext(ctx, s.agentID, message, s.sessionID, context)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	// Messages are streamed whole again with every update
+	contents := map[string]string{}
+	var order []string
+	tools := map[string]bool{}
+	var toolNames []string
+	for msg := range msgChan {
+		if msg.Type == "error" || msg.Error != "" {
+			return fmt.Errorf("agent error: %s", firstNonEmpty(msg.Error, msg.Content))
+		}
+		if msg.SessionID != "" {
+			s.sessionID = msg.SessionID
+		}
+		switch msg.Type {
+		case "tool":
+			name, args := splitToolCall(msg.Content)
+			if tools[msg.MessageID+name] {
+				continue
+			}
+			tools[msg.MessageID+name] = true
+			toolNames = append(toolNames, name)
+			if s.events != nil {
+				s.events.ToolCall(msg.MessageID, name, args)
+			} else {
+				fmt.Fprintf(s.out, "%s\n", style.DimStyle.Render("🔧 "+name))
+			}
+		case "tool_output", "system", "warning":
+		default:
+			if msg.SenderName == "You" || msg.MessageID == "" {
+				continue
+			}
+			if _, ok := contents[msg.MessageID]; !ok {
+				order = append(order, msg.MessageID)
+			}
+			if len(msg.Content) >= len(contents[msg.MessageID]) {
+				contents[msg.MessageID] = msg.Content
+			}
+		}
+	}
+
+	var answer []string
+	for _, id := range order {
+		content := strings.TrimSpace(contents[id])
+		if content == "" {
+			continue
+		}
+		answer = append(answer, content)
+		if s.events != nil {
+			s.events.Message(id, content)
+		} else {
+			fmt.Fprintf(s.out, "%s\n", style.AgentStyle.Render(content))
+		}
+	}
+	if s.events == nil {
+		fmt.Fprintln(s.out)
+	}
+	if s.bridge != nil && (len(answer) > 0 || len(toolNames) > 0) {
+		s.mirror(s.bridge.Reply(ctx, strings.Join(answer, "\n\n"), toolNames))
+	}
+	return nil
+}
+
+// mirror warns about a message that couldn't be mirrored; the session goes
+// on in the terminal
+func (s *chatStdinStream) mirror(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s Failed to mirror to %s: %v\n", style.WarningStyle.Render("⚠️"), s.bridge.Name(), err)
+	}
+}
+
+// splitToolCall splits "Tool: name Arguments: {...}" tool messages
+func splitToolCall(content string) (string, string) {
+	content = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(content), "Tool:"))
+	name, args, _ := strings.Cut(content, "Arguments:")
+	return strings.TrimSpace(name), strings.TrimSpace(args)
+}
+
+// runStdinStream sends what is piped to stdin to the agent until stdin is
+// closed, e.g. tail -f alerts.log | kubiya chat -n triage --stdin-stream
+func runStdinStream(ctx context.Context, r io.Reader, stream *chatStdinStream, delimiter string) error {
+	return scanStdinMessages(r, delimiter, func(message string) error {
+		return stream.send(ctx, message)
+	})
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// scriptedChat answers every message with the same reply and records what
+// was sent
+type scriptedChat struct {
+	reply    []kubiya.ChatMessage
+	err      error
+	messages []string
+	sessions []string
+	contexts []map[string]string
+}
+
+func (c *scriptedChat) SendMessageWithContext(ctx context.Context, agentID, message, sessionID string, context map[string]string) (<-chan kubiya.ChatMessage, error) {
+	c.messages = append(c.messages, message)
+	c.sessions = append(c.sessions, sessionID)
+	c.contexts = append(c.contexts, context)
+	if c.err != nil {
+		return nil, c.err
+	}
+	ch := make(chan kubiya.ChatMessage, len(c.reply))
+	for _, msg := range c.reply {
+		ch <- msg
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestScanStdinMessages(t *testing.T) {
+	collect := func(input, delimiter string) []string {
+		var got []string
+		require.NoError(t, scanStdinMessages(strings.NewReader(input), delimiter, func(m string) error {
+			got = append(got, m)
+			return nil
+		}))
+		return got
+	}
+
+	assert.Equal(t, []string{"disk full on db-1", "api 5xx"}, collect("disk full on db-1\n\napi 5xx\n", ""))
+	assert.Equal(t, []string{"alert: disk\nhost: db-1", "alert: 5xx"},
+		collect("alert: disk\nhost: db-1\n---\nalert: 5xx\n---\n", "---"))
+	assert.Equal(t, []string{"no trailing delimiter"}, collect("no trailing delimiter", "---"))
+
+	stop := errors.New("stop")
+	err := scanStdinMessages(strings.NewReader("a\nb\n"), "", func(string) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestStdinStreamFollowUps(t *testing.T) {
+	chat := &scriptedChat{reply: []kubiya.ChatMessage{
+		{Type: "tool", MessageID: "t1", Content: "Tool: kubectl Arguments: {\"args\": \"get pods\"}"},
+		{Type: "tool", MessageID: "t1", Content: "Tool: kubectl Arguments: {\"args\": \"get pods\"}"},
+		{Type: "tool_output", MessageID: "t1", Content: "api-1 Running"},
+		{Type: "text", MessageID: "m1", Content: "Looks"},
+		{Type: "text", MessageID: "m1", Content: "Looks healthy.", Final: true, SessionID: "s-1"},
+	}}
+	var out bytes.Buffer
+	stream := &chatStdinStream{
+		client:    chat,
+		agentID:   "agent-1",
+		sessionID: "s-1",
+		context:   map[string]string{"runbook.md": "..."},
+		out:       &out,
+	}
+
+	require.NoError(t, runStdinStream(context.Background(), strings.NewReader("first\nsecond\n"), stream, ""))
+	assert.Equal(t, []string{"first", "second"}, chat.messages)
+	assert.Equal(t, []string{"s-1", "s-1"}, chat.sessions)
+	assert.NotNil(t, chat.contexts[0])
+	assert.Nil(t, chat.contexts[1], "context is only sent with the first message")
+	assert.Equal(t, 2, strings.Count(out.String(), "Looks healthy."))
+	assert.Equal(t, 2, strings.Count(out.String(), "kubectl"), "a tool call is shown once per reply")
+}
+
+func TestStdinStreamJSONL(t *testing.T) {
+	chat := &scriptedChat{reply: []kubiya.ChatMessage{
+		{Type: "text", MessageID: "m1", Content: "On it.", Final: true},
+	}}
+	var out bytes.Buffer
+	stream := &chatStdinStream{client: chat, agentID: "agent-1", sessionID: "s-1", out: &out, events: newChatEventWriter(&out, nil)}
+
+	require.NoError(t, runStdinStream(context.Background(), strings.NewReader("disk full\n"), stream, ""))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var prompt, message chatEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &prompt))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &message))
+	assert.Equal(t, "prompt", prompt.Type)
+	assert.Equal(t, "disk full", prompt.Content)
+	assert.Equal(t, "message", message.Type)
+	assert.Equal(t, "On it.", message.Content)
+}
+
+func TestStdinStreamStopsAfterRepeatedFailures(t *testing.T) {
+	chat := &scriptedChat{err: errors.New("unauthorized")}
+	stream := &chatStdinStream{client: chat, agentID: "agent-1", sessionID: "s-1", out: &bytes.Buffer{}}
+
+	err := runStdinStream(context.Background(), strings.NewReader("a\nb\nc\nd\n"), stream, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 failed messages in a row")
+	assert.Len(t, chat.messages, maxStdinStreamFailures)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parts of the chat stream, for --only and --hide
+const (
+	streamToolCalls  = "tool-calls"
+	streamMessages   = "messages"
+	streamErrors     = "errors"
+	streamToolOutput = "tool-output"
+)
+
+// chatStreamFilter selects the chat events printed with --only and --hide
+type chatStreamFilter struct {
+	only map[string]bool // parts printed, all of them when empty
+	hide map[string]bool // parts left out
+}
+
+// newChatStreamFilter validates --only and --hide. It returns nil when
+// neither is set, i.e. when the whole stream is printed.
+func newChatStreamFilter(only, hide []string) (*chatStreamFilter, error) {
+	if len(only) == 0 && len(hide) == 0 {
+		return nil, nil
+	}
+	f := &chatStreamFilter{only: map[string]bool{}, hide: map[string]bool{}}
+	for _, part := range only {
+		part = strings.TrimSpace(part)
+		if part != streamToolCalls && part != streamMessages && part != streamErrors {
+			return nil, fmt.Errorf("invalid --only %q (valid: %s, %s, %s)", part, streamToolCalls, streamMessages, streamErrors)
+		}
+		f.only[part] = true
+	}
+	for _, part := range hide {
+		part = strings.TrimSpace(part)
+		if part != streamToolOutput && part != streamToolCalls && part != streamMessages && part != streamErrors {
+			return nil, fmt.Errorf("invalid --hide %q (valid: %s, %s, %s, %s)", part, streamToolOutput, streamToolCalls, streamMessages, streamErrors)
+		}
+		f.hide[part] = true
+	}
+	return f, nil
+}
+
+// chatEventParts returns the parts of the stream an event belongs to. A
+// failed tool call is both a tool call and an error.
+func chatEventParts(e chatEvent) []string {
+	switch e.Type {
+	case "tool_call", "artifact":
+		return []string{streamToolCalls}
+	case "tool_output":
+		if e.Status == "failed" {
+			return []string{streamToolCalls, streamErrors}
+		}
+		return []string{streamToolCalls}
+	case "prompt", "message":
+		return []string{streamMessages}
+	case "error":
+		return []string{streamErrors}
+	}
+	return nil
+}
+
+// Apply returns the event as printed, and false when it is left out. With
+// --hide tool-output, tool_output events keep their status and duration but
+// lose the output.
+func (f *chatStreamFilter) Apply(e chatEvent) (chatEvent, bool) {
+	if f == nil {
+		return e, true
+	}
+	selected := false
+	for _, part := range chatEventParts(e) {
+		if (len(f.only) == 0 || f.only[part]) && !f.hide[part] {
+			selected = true
+			break
+		}
+	}
+	if !selected {
+		return e, false
+	}
+	if e.Type == "tool_output" && f.hide[streamToolOutput] {
+		e.Parser, e.Records, e.Output, e.table = "", nil, "", nil
+	}
+	return e, true
+}
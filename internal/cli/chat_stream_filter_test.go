@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatStreamFilter(t *testing.T) {
+	f, err := newChatStreamFilter(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, f, "no filter prints the whole stream")
+
+	_, err = newChatStreamFilter([]string{"tool-output"}, nil)
+	assert.ErrorContains(t, err, `invalid --only "tool-output"`)
+	_, err = newChatStreamFilter(nil, []string{"thinking"})
+	assert.ErrorContains(t, err, `invalid --hide "thinking"`)
+
+	call := chatEvent{Type: "tool_call", Tool: "kubectl"}
+	done := chatEvent{Type: "tool_output", Tool: "kubectl", Status: "completed", Output: "pod/web"}
+	failed := chatEvent{Type: "tool_output", Tool: "kubectl", Status: "failed", Output: "forbidden"}
+	message := chatEvent{Type: "message", Content: "Done"}
+	stop := chatEvent{Type: "error", Content: "stream closed"}
+	all := []chatEvent{call, done, failed, message, stop}
+
+	kept := func(f *chatStreamFilter) []chatEvent {
+		var out []chatEvent
+		for _, e := range all {
+			if e, ok := f.Apply(e); ok {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+
+	f, err = newChatStreamFilter([]string{"errors"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []chatEvent{failed, stop}, kept(f), "failed tool calls are errors")
+
+	f, err = newChatStreamFilter([]string{"tool-calls", "errors"}, []string{"tool-output"})
+	require.NoError(t, err)
+	got := kept(f)
+	require.Len(t, got, 4)
+	assert.Equal(t, "completed", got[1].Status)
+	assert.Empty(t, got[1].Output, "the output is hidden, not the call")
+	assert.Empty(t, got[2].Output)
+
+	f, err = newChatStreamFilter(nil, []string{"tool-calls"})
+	require.NoError(t, err)
+	assert.Equal(t, []chatEvent{failed, message, stop}, kept(f))
+}
+
+func TestChatTextEventWriter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	filter, err := newChatStreamFilter([]string{"tool-calls", "errors"}, nil)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	w := newChatTextEventWriter(&out, nil)
+	w.filter = filter
+	w.ToolCall("call-1", "kubectl", `{"command":"get pods"}`)
+	w.ToolOutput("call-1", "kubectl", "completed", 1500*time.Millisecond, `"web   Running"`)
+	w.Message("msg-1", "All pods are running")
+	w.Error("stream closed")
+
+	text := out.String()
+	assert.Contains(t, text, "kubectl")
+	assert.Contains(t, text, "get pods")
+	assert.Contains(t, text, "completed (1.5s)")
+	assert.Contains(t, text, "web   Running")
+	assert.Contains(t, text, "stream closed")
+	assert.NotContains(t, text, "All pods are running")
+
+	// JSON lines are filtered the same way
+	out.Reset()
+	filter, err = newChatStreamFilter([]string{"messages"}, nil)
+	require.NoError(t, err)
+	w = newChatEventWriter(&out, nil)
+	w.filter = filter
+	w.ToolCall("call-1", "kubectl", `{}`)
+	w.Message("msg-1", "All pods are running")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 1)
+	var e chatEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+	assert.Equal(t, "message", e.Type)
+}
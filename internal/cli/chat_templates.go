@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// missingTemplateVars returns the top-level variables the templates of tmpl
+// reference, as {{.Name}} or {{$.Name}}, that data doesn't define, in the
+// order they first appear
+func missingTemplateVars(tmpl *template.Template, data map[string]interface{}) []string {
+	var missing []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if _, ok := data[name]; !ok && !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+
+	var walkPipe func(pipe *parse.PipeNode, root bool)
+	var walkArg func(arg parse.Node, root bool)
+	var walk func(node parse.Node, root bool)
+	walkArg = func(arg parse.Node, root bool) {
+		switch n := arg.(type) {
+		case *parse.FieldNode:
+			if root {
+				add(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				add(n.Ident[1])
+			}
+		case *parse.ChainNode:
+			walkArg(n.Node, root)
+		case *parse.PipeNode:
+			walkPipe(n, root)
+		}
+	}
+	walkPipe = func(pipe *parse.PipeNode, root bool) {
+		if pipe == nil {
+			return
+		}
+		for _, cmd := range pipe.Cmds {
+			for _, arg := range cmd.Args {
+				walkArg(arg, root)
+			}
+		}
+	}
+	// Within range and with, dot is no longer the data
+	walk = func(node parse.Node, root bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, root)
+			}
+		case *parse.ActionNode:
+			walkPipe(n.Pipe, root)
+		case *parse.IfNode:
+			walkPipe(n.Pipe, root)
+			walk(n.List, root)
+			walk(n.ElseList, root)
+		case *parse.RangeNode:
+			walkPipe(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.WithNode:
+			walkPipe(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.TemplateNode:
+			walkPipe(n.Pipe, root)
+		}
+	}
+
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root, true)
+	}
+	return missing
+}
+
+// missingTemplateVarsError is the --strict-templates error for the missing
+// variables of contentType
+func missingTemplateVarsError(contentType string, missing []string) error {
+	return fmt.Errorf("missing template variables in %s: %s (set them with --var KEY=VALUE)", contentType, strings.Join(missing, ", "))
+}
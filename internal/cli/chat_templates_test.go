@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingTemplateVars(t *testing.T) {
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+	tmpl, err := template.New("prompt").Funcs(funcs).Parse(`Investigate {{.Service}} in {{upper .Env}}.
+{{if .Ticket}}Ticket: {{.Ticket}}{{end}}
+{{range .Hosts}}- {{.Name}} ({{$.Region}}){{end}}
+{{with .Owner}}Owner: {{.Email}}{{else}}{{.Team}}{{end}}
+{{.Service}} again`)
+	require.NoError(t, err)
+
+	missing := missingTemplateVars(tmpl, map[string]interface{}{"Service": "api", "Hosts": []string{}})
+	// Fields within range and with belong to the element, not the data
+	assert.Equal(t, []string{"Env", "Ticket", "Region", "Owner", "Team"}, missing)
+
+	assert.Empty(t, missingTemplateVars(tmpl, map[string]interface{}{
+		"Service": "api", "Env": "prod", "Ticket": "", "Hosts": nil, "Region": "eu", "Owner": nil, "Team": "sre",
+	}))
+}
+
+func TestMissingTemplateVarsError(t *testing.T) {
+	err := missingTemplateVarsError("prompt file p.md", []string{"Env", "Ticket"})
+	assert.EqualError(t, err, "missing template variables in prompt file p.md: Env, Ticket (set them with --var KEY=VALUE)")
+}
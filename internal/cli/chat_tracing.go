@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/tracing"
+)
+
+// chatTracer records a chat as OpenTelemetry spans: a span for the session
+// with a child for every tool call and API call. A nil chatTracer records
+// nothing.
+type chatTracer struct {
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+	ctx      context.Context // of the session span
+	session  trace.Span
+
+	mu    sync.Mutex
+	tools map[string]*tracedToolCall
+}
+
+type tracedToolCall struct {
+	span  trace.Span
+	start time.Time
+}
+
+func newChatTracer(ctx context.Context, provider trace.TracerProvider, agent string) *chatTracer {
+	tracer := provider.Tracer(tracing.TracerName)
+	ctx, session := tracer.Start(ctx, "kubiya chat", trace.WithAttributes(
+		attribute.String("kubiya.agent", agent),
+		attribute.String("kubiya.correlation_id", correlation.ID()),
+	))
+	return &chatTracer{
+		provider: provider,
+		tracer:   tracer,
+		ctx:      ctx,
+		session:  session,
+		tools:    map[string]*tracedToolCall{},
+	}
+}
+
+// Context returns the context of the session span
+func (t *chatTracer) Context() context.Context {
+	return t.ctx
+}
+
+// TraceClient records the API calls of client as spans of the session
+func (t *chatTracer) TraceClient(client *kubiya.Client) {
+	if t == nil {
+		return
+	}
+	client.WrapTransport(func(transport http.RoundTripper) http.RoundTripper {
+		return tracing.Transport(transport, t.provider, t.session)
+	})
+}
+
+// ToolCall starts the span of a tool call. The arguments are left out of
+// the span, they may hold secrets.
+func (t *chatTracer) ToolCall(id, name string) {
+	if t == nil {
+		return
+	}
+	_, span := t.tracer.Start(t.ctx, "execute_tool "+name, trace.WithAttributes(
+		attribute.String("kubiya.tool.name", name),
+		attribute.String("kubiya.tool.call_id", id),
+	))
+	t.mu.Lock()
+	t.tools[id] = &tracedToolCall{span: span, start: time.Now()}
+	t.mu.Unlock()
+}
+
+// ToolDone ends the span of a tool call with the runner it ran on and how
+// it went
+func (t *chatTracer) ToolDone(id, runner string, failed bool, errMsg string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	call := t.tools[id]
+	delete(t.tools, id)
+	t.mu.Unlock()
+	if call == nil {
+		return
+	}
+
+	status := "completed"
+	if failed {
+		status = "failed"
+		call.span.SetStatus(codes.Error, firstNonEmpty(errMsg, "tool call failed"))
+	}
+	call.span.SetAttributes(
+		attribute.String("kubiya.runner", runner),
+		attribute.String("kubiya.tool.status", status),
+		attribute.Int64("kubiya.tool.duration_ms", time.Since(call.start).Milliseconds()),
+	)
+	call.span.End()
+}
+
+// End ends the session span, and the spans of tool calls that never
+// completed
+func (t *chatTracer) End(sessionID string, toolCalls int, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	for id, call := range t.tools {
+		call.span.SetAttributes(
+			attribute.String("kubiya.tool.status", "incomplete"),
+			attribute.Int64("kubiya.tool.duration_ms", time.Since(call.start).Milliseconds()),
+		)
+		call.span.End()
+		delete(t.tools, id)
+	}
+	t.mu.Unlock()
+
+	t.session.SetAttributes(
+		attribute.String("kubiya.session.id", sessionID),
+		attribute.Int("kubiya.tool_calls", toolCalls),
+	)
+	if err != nil {
+		t.session.RecordError(err)
+		t.session.SetStatus(codes.Error, err.Error())
+	}
+	t.session.End()
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[string]string {
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestChatTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tracer := newChatTracer(context.Background(), provider, "devops")
+	tracer.ToolCall("m-1", "kubectl")
+	tracer.ToolCall("m-2", "deploy")
+	tracer.ToolCall("m-3", "wait")
+	tracer.ToolDone("m-1", "eu-1", false, "")
+	tracer.ToolDone("m-2", "eu-1", true, "exit status 1")
+	tracer.ToolDone("m-unknown", "eu-1", false, "")
+	tracer.End("s-1", 3, errors.New("Some errors occurred during execution"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+
+	session := byName["kubiya chat"]
+	require.NotNil(t, session)
+	attrs := spanAttributes(session)
+	assert.Equal(t, "devops", attrs["kubiya.agent"])
+	assert.Equal(t, "s-1", attrs["kubiya.session.id"])
+	assert.Equal(t, "3", attrs["kubiya.tool_calls"])
+	assert.NotEmpty(t, attrs["kubiya.correlation_id"])
+	assert.Equal(t, codes.Error, session.Status().Code)
+
+	kubectl := byName["execute_tool kubectl"]
+	require.NotNil(t, kubectl)
+	assert.Equal(t, session.SpanContext().SpanID(), kubectl.Parent().SpanID())
+	attrs = spanAttributes(kubectl)
+	assert.Equal(t, "eu-1", attrs["kubiya.runner"])
+	assert.Equal(t, "completed", attrs["kubiya.tool.status"])
+	assert.Contains(t, attrs, "kubiya.tool.duration_ms")
+	assert.Equal(t, codes.Unset, kubectl.Status().Code)
+
+	deploy := byName["execute_tool deploy"]
+	require.NotNil(t, deploy)
+	assert.Equal(t, "failed", spanAttributes(deploy)["kubiya.tool.status"])
+	assert.Equal(t, "exit status 1", deploy.Status().Description)
+
+	assert.Equal(t, "incomplete", spanAttributes(byName["execute_tool wait"])["kubiya.tool.status"])
+	assert.NotContains(t, spanAttributes(byName["execute_tool wait"]), "kubiya.runner")
+
+	// Without --otel-endpoint nothing is recorded
+	var none *chatTracer
+	none.ToolCall("m-1", "kubectl")
+	none.ToolDone("m-1", "eu-1", false, "")
+	none.End("s-1", 1, nil)
+}
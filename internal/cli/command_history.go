@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+)
+
+// maxCommandHistory is how many distinct command lines are remembered
+const maxCommandHistory = 50
+
+// sensitiveFlagPattern matches flags whose values must not be written to the
+// command history
+var sensitiveFlagPattern = regexp.MustCompile(`(?i)^--?[a-z0-9-]*(key|token|secret|password|passwd|credential)`)
+
+// unrecordedCommands are command groups whose invocations are never recorded
+var unrecordedCommands = map[string]bool{
+	"help":       true,
+	"completion": true,
+	"version":    true,
+	"login":      true,
+	"auth":       true,
+	"secret":     true,
+}
+
+// historyEntry is a command line that ran successfully
+type historyEntry struct {
+	Args     []string  `json:"args"`
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// commandHistory stores recently used command lines in
+// ~/.kubiya/command-history.json for the command palette
+type commandHistory struct {
+	path string
+}
+
+func newCommandHistory() (*commandHistory, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return &commandHistory{path: filepath.Join(homeDir, config.KUBIYA_DIR, "command-history.json")}, nil
+}
+
+// List returns the entries, most recently used first
+func (h *commandHistory) List() ([]historyEntry, error) {
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command history: %w", err)
+	}
+
+	var entries []historyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid command history %s: %w", h.path, err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Record adds a command line to the history, or bumps it when it's known
+func (h *commandHistory) Record(args []string, now time.Time) error {
+	entries, err := h.List()
+	if err != nil {
+		// Start over rather than failing every command on a broken file
+		entries = nil
+	}
+
+	key := strings.Join(args, "\x00")
+	found := false
+	for i := range entries {
+		if strings.Join(entries[i].Args, "\x00") == key {
+			entries[i].Count++
+			entries[i].LastUsed = now
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, historyEntry{Args: args, Count: 1, LastUsed: now})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	if len(entries) > maxCommandHistory {
+		entries = entries[:maxCommandHistory]
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	return os.WriteFile(h.path, data, 0600)
+}
+
+// shouldRecordCommand reports whether the invocation of cmd with args may
+// be stored in the command history
+func shouldRecordCommand(cmd *cobra.Command, args []string) bool {
+	if cmd == nil || !cmd.HasParent() || cmd.Hidden || len(args) == 0 {
+		return false
+	}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if unrecordedCommands[c.Name()] || strings.HasPrefix(c.Name(), "__") {
+			return false
+		}
+	}
+	for _, arg := range args {
+		if sensitiveFlagPattern.MatchString(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordCommand stores a successful invocation, ignoring failures so the
+// history never breaks a command
+func recordCommand(cmd *cobra.Command, args []string) {
+	if !shouldRecordCommand(cmd, args) {
+		return
+	}
+	history, err := newCommandHistory()
+	if err != nil {
+		return
+	}
+	_ = history.Record(args, time.Now())
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kubiyabot/cli/internal/config"
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/kubiyabot/cli/internal/version"
+)
+
+// commandHookTimeout bounds every hook script
+const commandHookTimeout = 30 * time.Second
+
+// errCommandBlocked is returned when a pre-run hook blocks a command
+var errCommandBlocked = errors.New("blocked by hook")
+
+// commandHookPayload is the JSON a hook script receives on stdin
+type commandHookPayload struct {
+	Event   string   `json:"event"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Flags are the flags set on the command line, and by earlier hooks
+	Flags   map[string]string `json:"flags"`
+	Context string            `json:"context,omitempty"`
+	Org     string            `json:"org,omitempty"`
+	User    string            `json:"user,omitempty"`
+	Version string            `json:"version"`
+	// DurationMS and Error are set for post-run and on-error hooks
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// commandHookResult is what a pre-run hook script may print on stdout
+type commandHookResult struct {
+	SetFlags map[string]string `json:"set-flags"`
+}
+
+// commandHooks runs the hooks of the config around the command being
+// executed
+type commandHooks struct {
+	cfg    *config.Config
+	stderr io.Writer
+	// started is when the pre-run hooks ran, zero when they did not
+	started time.Time
+	args    []string
+}
+
+func newCommandHooks(cfg *config.Config) *commandHooks {
+	return &commandHooks{cfg: cfg, stderr: os.Stderr}
+}
+
+// commandPath is the path of cmd without the root command
+func commandPath(cmd *cobra.Command) string {
+	path := cmd.CommandPath()
+	if i := strings.IndexByte(path, ' '); i >= 0 {
+		return path[i+1:]
+	}
+	return ""
+}
+
+// hookable reports whether hooks apply to cmd: not to the root command and
+// not to shell completion requests
+func hookable(cmd *cobra.Command) bool {
+	if cmd == nil || !cmd.HasParent() {
+		return false
+	}
+	return cmd.Name() != cobra.ShellCompRequestCmd && cmd.Name() != cobra.ShellCompNoDescRequestCmd
+}
+
+// preRun runs the pre-run hooks matching cmd, in order. A hook denying the
+// command, or whose script fails, stops the command.
+func (h *commandHooks) preRun(cmd *cobra.Command, args []string) error {
+	if !hookable(cmd) {
+		return nil
+	}
+	h.started, h.args = time.Now(), args
+	path := commandPath(cmd)
+	for _, hook := range h.cfg.Preferences.Hooks.Hooks(kubiyacontext.HookPreRun) {
+		if !hook.Matches(path) {
+			continue
+		}
+		if hook.Deny != "" {
+			return fmt.Errorf("kubiya %s %w %s: %s", path, errCommandBlocked, hook.DisplayName(), hook.Deny)
+		}
+		if err := setHookFlags(cmd, hook.SetFlags); err != nil {
+			return fmt.Errorf("hook %s: %w", hook.DisplayName(), err)
+		}
+		if hook.Run == "" {
+			continue
+		}
+
+		stdout, err := h.runScript(cmd.Context(), hook, h.payload(kubiyacontext.HookPreRun, cmd, args, nil))
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			reason := strings.TrimSpace(string(exitErr.Stderr))
+			if reason == "" {
+				reason = fmt.Sprintf("exit code %d", exitErr.ExitCode())
+			}
+			return fmt.Errorf("kubiya %s %w %s: %s", path, errCommandBlocked, hook.DisplayName(), reason)
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(stdout)) == 0 {
+			continue
+		}
+		var result commandHookResult
+		if err := json.Unmarshal(stdout, &result); err != nil {
+			return fmt.Errorf("hook %s printed invalid JSON: %w", hook.DisplayName(), err)
+		}
+		if err := setHookFlags(cmd, result.SetFlags); err != nil {
+			return fmt.Errorf("hook %s: %w", hook.DisplayName(), err)
+		}
+	}
+	return nil
+}
+
+// postRun runs the post-run hooks of cmd when it succeeded, its on-error
+// hooks otherwise. They cannot change the outcome of the command: their
+// failures are only reported.
+func (h *commandHooks) postRun(cmd *cobra.Command, cmdErr error) {
+	if !hookable(cmd) || h.started.IsZero() {
+		return
+	}
+	event := kubiyacontext.HookPostRun
+	if cmdErr != nil {
+		event = kubiyacontext.HookOnError
+	}
+	path := commandPath(cmd)
+	for _, hook := range h.cfg.Preferences.Hooks.Hooks(event) {
+		if hook.Run == "" || !hook.Matches(path) {
+			continue
+		}
+		if _, err := h.runScript(context.Background(), hook, h.payload(event, cmd, h.args, cmdErr)); err != nil {
+			fmt.Fprintf(h.stderr, "%s %s hook %s failed: %v\n", style.WarningStyle.Render("⚠️"), event, hook.DisplayName(), err)
+		}
+	}
+}
+
+func (h *commandHooks) payload(event string, cmd *cobra.Command, args []string, cmdErr error) commandHookPayload {
+	payload := commandHookPayload{
+		Event:   event,
+		Command: commandPath(cmd),
+		Args:    args,
+		Flags:   map[string]string{},
+		Context: h.cfg.ContextName,
+		Org:     h.cfg.Org,
+		User:    h.cfg.Email,
+		Version: version.Version,
+	}
+	if payload.Args == nil {
+		payload.Args = []string{}
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		payload.Flags[f.Name] = f.Value.String()
+	})
+	if event != kubiyacontext.HookPreRun {
+		payload.DurationMS = time.Since(h.started).Milliseconds()
+	}
+	if cmdErr != nil {
+		payload.Error = cmdErr.Error()
+	}
+	return payload
+}
+
+// runScript runs the script of hook with payload on stdin and returns its
+// output. A script exiting with a non-zero code returns an *exec.ExitError
+// holding its stderr.
+func (h *commandHooks) runScript(ctx context.Context, hook kubiyacontext.CommandHook, payload commandHookPayload) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, commandHookTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	script := hook.Run
+	if strings.HasPrefix(script, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			script = filepath.Join(home, script[2:])
+		}
+	}
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		"KUBIYA_HOOK_EVENT="+payload.Event,
+		"KUBIYA_HOOK_COMMAND="+payload.Command,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, fmt.Errorf("hook %s did not finish within %s", hook.DisplayName(), commandHookTimeout)
+	case errors.As(err, &exitErr):
+		exitErr.Stderr = stderr.Bytes()
+		return out, exitErr
+	case err != nil:
+		return nil, fmt.Errorf("failed to run hook %s: %w", hook.DisplayName(), err)
+	}
+	return out, nil
+}
+
+// setHookFlags sets flags on cmd, in name order. Flags cmd does not have
+// are ignored so that a hook can apply to commands with different flags.
+func setHookFlags(cmd *cobra.Command, flags map[string]string) error {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cmd.Flags().Lookup(name) == nil {
+			continue
+		}
+		if err := cmd.Flags().Set(name, flags[name]); err != nil {
+			return fmt.Errorf("failed to set --%s: %w", name, err)
+		}
+	}
+	return nil
+}
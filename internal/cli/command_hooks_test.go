@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+)
+
+func writeCommandHook(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755))
+	return path
+}
+
+// hookedChatCommand returns "kubiya agent chat" with a --permission-level flag
+func hookedChatCommand(permission *string) *cobra.Command {
+	root := &cobra.Command{Use: "kubiya"}
+	agent := &cobra.Command{Use: "agent"}
+	chat := &cobra.Command{Use: "chat", Run: func(*cobra.Command, []string) {}}
+	chat.Flags().StringVar(permission, "permission-level", "write", "")
+	agent.AddCommand(chat)
+	root.AddCommand(agent)
+	return chat
+}
+
+func TestCommandHooksPreRun(t *testing.T) {
+	var permission string
+	chat := hookedChatCommand(&permission)
+	require.NoError(t, chat.Flags().Parse([]string{"--permission-level", "admin"}))
+
+	hooks := newCommandHooks(&config.Config{Preferences: kubiyacontext.Preferences{Hooks: &kubiyacontext.HooksConfig{
+		PreRun: []kubiyacontext.CommandHook{
+			{Commands: []string{"agent chat"}, SetFlags: map[string]string{"permission-level": "read", "namespace": "prod"}},
+			{Commands: []string{"agent delete"}, Deny: "deletions go through Terraform"},
+		},
+	}}})
+	require.NoError(t, hooks.preRun(chat, nil))
+	assert.Equal(t, "read", permission, "set-flags replaces the command line value")
+
+	// A script sets flags on stdout and sees those set before
+	script := writeCommandHook(t, `grep -q '"permission-level":"read"' && echo '{"set-flags": {"permission-level": "none"}}'`)
+	hooks.cfg.Preferences.Hooks.PreRun = append(hooks.cfg.Preferences.Hooks.PreRun, kubiyacontext.CommandHook{Run: script})
+	require.NoError(t, hooks.preRun(chat, nil))
+	assert.Equal(t, "none", permission)
+
+	hooks.cfg.Preferences.Hooks.PreRun = []kubiyacontext.CommandHook{
+		{Name: "change-window", Run: writeCommandHook(t, `echo "outside the change window" >&2; exit 3`)},
+	}
+	err := hooks.preRun(chat, nil)
+	assert.ErrorIs(t, err, errCommandBlocked)
+	assert.EqualError(t, err, "kubiya agent chat blocked by hook change-window: outside the change window")
+
+	hooks.cfg.Preferences.Hooks.PreRun = []kubiyacontext.CommandHook{{Commands: []string{"agent"}, Deny: "agents are read-only"}}
+	assert.EqualError(t, hooks.preRun(chat, nil), "kubiya agent chat blocked by hook config: agents are read-only")
+}
+
+func TestCommandHooksPostRun(t *testing.T) {
+	var permission string
+	chat := hookedChatCommand(&permission)
+	out := filepath.Join(t.TempDir(), "payload.json")
+	script := writeCommandHook(t, `cat > "`+out+`"; echo "$KUBIYA_HOOK_EVENT" >> "`+out+`.event"`)
+
+	var stderr bytes.Buffer
+	hooks := newCommandHooks(&config.Config{ContextName: "prod", Preferences: kubiyacontext.Preferences{Hooks: &kubiyacontext.HooksConfig{
+		PostRun: []kubiyacontext.CommandHook{{Commands: []string{"team *"}, Run: "/does/not/run"}},
+		OnError: []kubiyacontext.CommandHook{{Run: script}, {Run: filepath.Join(t.TempDir(), "missing.sh")}},
+	}}})
+	hooks.stderr = &stderr
+
+	// Nothing runs when the pre-run hooks did not
+	hooks.postRun(chat, nil)
+	assert.NoFileExists(t, out)
+
+	require.NoError(t, chat.Flags().Parse([]string{"--permission-level", "read"}))
+	require.NoError(t, hooks.preRun(chat, []string{"agent-1"}))
+	hooks.postRun(chat, nil)
+	assert.NoFileExists(t, out, "post-run hooks of other commands do not run")
+
+	hooks.postRun(chat, errors.New("agent not found"))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var payload commandHookPayload
+	require.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, "on-error", payload.Event)
+	assert.Equal(t, "agent chat", payload.Command)
+	assert.Equal(t, []string{"agent-1"}, payload.Args)
+	assert.Equal(t, map[string]string{"permission-level": "read"}, payload.Flags)
+	assert.Equal(t, "prod", payload.Context)
+	assert.Equal(t, "agent not found", payload.Error)
+	event, err := os.ReadFile(out + ".event")
+	require.NoError(t, err)
+	assert.Equal(t, "on-error\n", string(event))
+	assert.Contains(t, stderr.String(), "on-error hook")
+	assert.Contains(t, stderr.String(), "missing.sh failed")
+}
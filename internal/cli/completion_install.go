@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// completionShells are the shells completion can be installed for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionTarget is a place a completion script can be installed to.
+// Targets of a shell are tried in order, system-wide ones first, so that a
+// user without root ends up with a location in their home directory.
+type completionTarget struct {
+	path string
+	// hint tells how to load the script when the shell does not on its own
+	hint string
+}
+
+func newCompletionInstallCommand(cfg *config.Config) *cobra.Command {
+	var (
+		shell   string
+		path    string
+		withMan bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "📦 Install the completion script for your shell",
+		Long: `Detect your shell and write the completion script where it is loaded.
+
+System-wide locations are used when writable, otherwise a location in your
+home directory. When the shell does not load that location on its own, the
+line to add to your shell profile is printed.`,
+		Example: `  # Install completion for the current shell
+  kubiya completion install
+
+  # Install zsh completion and the man pages
+  kubiya completion install --shell zsh --man
+
+  # Install to a given file
+  kubiya completion install --shell bash --path ~/.bash_completion.d/kubiya`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if shell == "" {
+				detected, err := detectShell()
+				if err != nil {
+					return err
+				}
+				shell = detected
+			}
+			if !contains(completionShells, shell) {
+				return fmt.Errorf("unsupported shell %q, use one of: bash, zsh, fish, powershell", shell)
+			}
+
+			var script bytes.Buffer
+			if err := genCompletionScript(cmd.Root(), shell, &script); err != nil {
+				return fmt.Errorf("failed to generate %s completion: %w", shell, err)
+			}
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to find home directory: %w", err)
+			}
+			targets := completionTargets(shell, home)
+			if path != "" {
+				targets = []completionTarget{{path: path, hint: completionSourceHint(shell, path)}}
+			}
+
+			target, err := installFirstWritable(targets, script.Bytes())
+			if err != nil {
+				return fmt.Errorf("failed to install %s completion: %w", shell, err)
+			}
+			fmt.Printf("%s Installed %s completion to %s\n", style.SuccessStyle.Render("✅"), shell, target.path)
+			if target.hint != "" {
+				fmt.Printf("\n%s\n", style.DimStyle.Render(target.hint))
+			}
+			fmt.Println(style.DimStyle.Render("Start a new shell for completion to take effect."))
+
+			if withMan {
+				dir, pages, err := installManPages(cmd.Root(), home)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s Installed %d man pages to %s\n", style.SuccessStyle.Render("✅"), pages, dir)
+				if hint := manPathHint(dir, home); hint != "" {
+					fmt.Printf("\n%s\n", style.DimStyle.Render(hint))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&shell, "shell", "", "Shell to install completion for: bash, zsh, fish or powershell (default: detected from $SHELL)")
+	cmd.Flags().StringVar(&path, "path", "", "File to write the completion script to instead of the default location")
+	cmd.Flags().BoolVar(&withMan, "man", false, "Also generate and install the man pages")
+	_ = cmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions(completionShells, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// detectShell returns the shell of the user from $SHELL, or powershell on
+// Windows
+func detectShell() (string, error) {
+	if shell := filepath.Base(os.Getenv("SHELL")); contains(completionShells, shell) {
+		return shell, nil
+	}
+	if runtime.GOOS == "windows" {
+		return "powershell", nil
+	}
+	return "", fmt.Errorf("could not detect your shell from $SHELL, use --shell")
+}
+
+func genCompletionScript(root *cobra.Command, shell string, out *bytes.Buffer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, true)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	default:
+		return root.GenPowerShellCompletionWithDesc(out)
+	}
+}
+
+// completionTargets returns where the completion script of shell goes, in
+// order of preference
+func completionTargets(shell, home string) []completionTarget {
+	dataHome := xdgDir("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	configHome := xdgDir("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	switch shell {
+	case "bash":
+		user := filepath.Join(dataHome, "bash-completion", "completions", "kubiya")
+		return []completionTarget{
+			{path: "/etc/bash_completion.d/kubiya"},
+			{path: user, hint: fmt.Sprintf("Loaded by the bash-completion package. Without it, add to ~/.bashrc:\n  source %s", user)},
+		}
+	case "zsh":
+		dir := filepath.Join(home, ".zsh", "completions")
+		return []completionTarget{
+			{path: "/usr/local/share/zsh/site-functions/_kubiya"},
+			{path: filepath.Join(dir, "_kubiya"), hint: fmt.Sprintf("Add to ~/.zshrc, before compinit is called:\n  fpath=(%s $fpath)\n  autoload -U compinit && compinit", dir)},
+		}
+	case "fish":
+		return []completionTarget{
+			{path: filepath.Join(configHome, "fish", "completions", "kubiya.fish")},
+		}
+	default:
+		path := filepath.Join(configHome, "powershell", "kubiya-completion.ps1")
+		return []completionTarget{
+			{path: path, hint: completionSourceHint(shell, path)},
+		}
+	}
+}
+
+// completionSourceHint tells how to load a script installed to a location
+// of the user's choosing
+func completionSourceHint(shell, path string) string {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf("Unless that location is loaded already, add to ~/.bashrc:\n  source %s", path)
+	case "zsh":
+		return fmt.Sprintf("Unless that location is in $fpath already, add to ~/.zshrc:\n  source %s", path)
+	case "fish":
+		return fmt.Sprintf("Unless that location is loaded already, add to ~/.config/fish/config.fish:\n  source %s", path)
+	default:
+		return fmt.Sprintf("Add to your PowerShell profile ($PROFILE):\n  . %s", path)
+	}
+}
+
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	return fallback
+}
+
+// installFirstWritable writes content to the first target that can be
+// written to. Only permission errors move on to the next target.
+func installFirstWritable(targets []completionTarget, content []byte) (completionTarget, error) {
+	var errs []error
+	for _, target := range targets {
+		err := writeFileAll(target.path, content)
+		if err == nil {
+			return target, nil
+		}
+		if !errors.Is(err, os.ErrPermission) {
+			return completionTarget{}, err
+		}
+		errs = append(errs, err)
+	}
+	return completionTarget{}, errors.Join(errs...)
+}
+
+func writeFileAll(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
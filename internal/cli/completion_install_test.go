@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectShell(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	shell, err := detectShell()
+	require.NoError(t, err)
+	assert.Equal(t, "zsh", shell)
+
+	t.Setenv("SHELL", "/usr/local/bin/fish")
+	shell, err = detectShell()
+	require.NoError(t, err)
+	assert.Equal(t, "fish", shell)
+}
+
+func TestCompletionTargets(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+
+	bash := completionTargets("bash", "/home/me")
+	require.Len(t, bash, 2)
+	assert.Equal(t, "/etc/bash_completion.d/kubiya", bash[0].path)
+	assert.Equal(t, "/home/me/.local/share/bash-completion/completions/kubiya", bash[1].path)
+
+	zsh := completionTargets("zsh", "/home/me")
+	require.Len(t, zsh, 2)
+	assert.Equal(t, "/home/me/.zsh/completions/_kubiya", zsh[1].path)
+	assert.Contains(t, zsh[1].hint, "fpath=(/home/me/.zsh/completions $fpath)")
+
+	fish := completionTargets("fish", "/home/me")
+	require.Len(t, fish, 1)
+	assert.Equal(t, "/xdg/config/fish/completions/kubiya.fish", fish[0].path)
+	assert.Empty(t, fish[0].hint)
+}
+
+func TestInstallFirstWritable(t *testing.T) {
+	dir := t.TempDir()
+	target, err := installFirstWritable([]completionTarget{
+		{path: filepath.Join(dir, "a", "kubiya")},
+		{path: filepath.Join(dir, "b", "kubiya")},
+	}, []byte("script"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "a", "kubiya"), target.path)
+	content, err := os.ReadFile(target.path)
+	require.NoError(t, err)
+	assert.Equal(t, "script", string(content))
+
+	// Errors other than permission errors are not skipped
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	_, err = installFirstWritable([]completionTarget{
+		{path: filepath.Join(file, "kubiya")},
+		{path: filepath.Join(dir, "b", "kubiya")},
+	}, []byte("script"))
+	assert.Error(t, err)
+}
+
+func TestInstallFirstWritableFallsBack(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	dir := t.TempDir()
+	readOnly := filepath.Join(dir, "system")
+	require.NoError(t, os.Mkdir(readOnly, 0555))
+
+	target, err := installFirstWritable([]completionTarget{
+		{path: filepath.Join(readOnly, "completions", "kubiya")},
+		{path: filepath.Join(dir, "home", "kubiya"), hint: "source it"},
+	}, []byte("script"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "home", "kubiya"), target.path)
+	assert.Equal(t, "source it", target.hint)
+}
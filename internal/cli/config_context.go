@@ -25,6 +25,13 @@ func NewConfigCmd() *cobra.Command {
 		newConfigDeleteContextCmd(),
 		newConfigRenameContextCmd(),
 		newConfigViewCmd(),
+		newConfigGetCmd(),
+		newConfigSetCmd(),
+		newConfigUnsetCmd(),
+		newConfigValidateCmd(),
+		newConfigGetOrgsCmd(),
+		newConfigSetOrgCmd(),
+		newConfigDeleteOrgCmd(),
 	)
 
 	return cmd
@@ -115,6 +122,11 @@ func newConfigSetContextCmd() *cobra.Command {
 		user         string
 		token        string
 		useV1API     bool
+		rateLimit    float64
+		rateBurst    int
+		caBundle     string
+		clientCert   string
+		clientKey    string
 	)
 
 	cmd := &cobra.Command{
@@ -123,7 +135,16 @@ func newConfigSetContextCmd() *cobra.Command {
 		Long: `Create or update a context with API configuration.
 
 The api-url should point to your control plane instance (default: https://control-plane.kubiya.ai).
-For legacy V1 API, use --use-v1-api flag and set api-url to https://api.kubiya.ai/api/v1.`,
+For legacy V1 API, use --use-v1-api flag and set api-url to https://api.kubiya.ai/api/v1.
+
+Use --rate-limit to cap the requests per second sent by the CLI with this
+context, e.g. for bulk commands against a busy organization. Responses with
+status 429 are always retried after the delay requested by the API, up to
+20 seconds, and slow the client down until requests succeed again.
+
+Behind a TLS-intercepting proxy, point --ca-bundle at a PEM file with the
+proxy CA. --client-cert and --client-key enable mutual TLS. Proxies are taken
+from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			contextName := args[0]
@@ -141,6 +162,20 @@ For legacy V1 API, use --use-v1-api flag and set api-url to https://api.kubiya.a
 				if user == "" {
 					user = existingCtx.User
 				}
+				if existingCtx.RateLimit != nil {
+					if !cmd.Flags().Changed("rate-limit") {
+						rateLimit = existingCtx.RateLimit.RequestsPerSecond
+					}
+					if !cmd.Flags().Changed("rate-burst") {
+						rateBurst = existingCtx.RateLimit.Burst
+					}
+				}
+				if !cmd.Flags().Changed("ca-bundle") {
+					caBundle = existingCtx.CABundle
+				}
+				if !cmd.Flags().Changed("client-cert") && !cmd.Flags().Changed("client-key") {
+					clientCert, clientKey = existingCtx.ClientCert, existingCtx.ClientKey
+				}
 			}
 
 			// Validate required fields
@@ -160,6 +195,18 @@ For legacy V1 API, use --use-v1-api flag and set api-url to https://api.kubiya.a
 				Organization: organization,
 				User:         user,
 				UseV1API:     useV1API,
+				CABundle:     caBundle,
+				ClientCert:   clientCert,
+				ClientKey:    clientKey,
+			}
+			if (clientCert == "") != (clientKey == "") {
+				return fmt.Errorf("--client-cert and --client-key must be set together")
+			}
+			if rateLimit < 0 || rateBurst < 0 {
+				return fmt.Errorf("--rate-limit and --rate-burst must not be negative")
+			}
+			if rateLimit > 0 {
+				ctx.RateLimit = &context.RateLimitConfig{RequestsPerSecond: rateLimit, Burst: rateBurst}
 			}
 
 			if err := context.CreateContext(contextName, ctx); err != nil {
@@ -185,6 +232,11 @@ For legacy V1 API, use --use-v1-api flag and set api-url to https://api.kubiya.a
 	cmd.Flags().StringVar(&user, "user", "", "User name/email (required)")
 	cmd.Flags().StringVar(&token, "token", "", "API token (if not provided, will use existing)")
 	cmd.Flags().BoolVar(&useV1API, "use-v1-api", false, "Use V1 API (api.kubiya.ai) instead of control plane")
+	cmd.Flags().Float64Var(&rateLimit, "rate-limit", 0, "Maximum API requests per second (0 = unlimited, overridable with KUBIYA_RATE_LIMIT)")
+	cmd.Flags().IntVar(&rateBurst, "rate-burst", 0, "Requests allowed in a burst above --rate-limit (default: the rate limit)")
+	cmd.Flags().StringVar(&caBundle, "ca-bundle", "", "PEM file with additional trusted CAs (overridable with KUBIYA_CA_BUNDLE)")
+	cmd.Flags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for mutual TLS (overridable with KUBIYA_CLIENT_CERT)")
+	cmd.Flags().StringVar(&clientKey, "client-key", "", "PEM key of the client certificate (overridable with KUBIYA_CLIENT_KEY)")
 
 	return cmd
 }
@@ -232,7 +284,8 @@ func newConfigRenameContextCmd() *cobra.Command {
 }
 
 func newConfigViewCmd() *cobra.Command {
-	return &cobra.Command{
+	var redacted bool
+	cmd := &cobra.Command{
 		Use:   "view",
 		Short: "Display the full configuration file",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -241,6 +294,15 @@ func newConfigViewCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
+			// Hide credentials, e.g. when sharing the config in a bug report
+			if redacted {
+				for i := range config.Users {
+					if config.Users[i].User.Token != "" {
+						config.Users[i].User.Token = "REDACTED"
+					}
+				}
+			}
+
 			// Marshal to YAML for display
 			data, err := yaml.Marshal(config)
 			if err != nil {
@@ -251,6 +313,121 @@ func newConfigViewCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&redacted, "redacted", false, "Replace user tokens with REDACTED")
+	return cmd
+}
+
+func newConfigGetOrgsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-orgs",
+		Short: "List all configured organizations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orgs, err := context.ListOrganizations()
+			if err != nil {
+				return fmt.Errorf("failed to list organizations: %w", err)
+			}
+
+			if len(orgs) == 0 {
+				fmt.Println("No organizations configured. Use 'kubiya config set-org' to add one.")
+				return nil
+			}
+
+			fmt.Println("NAME                ORG-ID                               USER                 API-URL")
+			for _, no := range orgs {
+				apiURL := no.Organization.APIURL
+				if apiURL == "" {
+					apiURL = "(default)"
+				}
+				fmt.Printf("%-19s %-36s %-20s %s\n",
+					no.Name,
+					no.Organization.ID,
+					no.Organization.User,
+					apiURL,
+				)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newConfigSetOrgCmd() *cobra.Command {
+	var (
+		orgID  string
+		user   string
+		token  string
+		apiURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set-org ORG_NAME",
+		Short: "Add or update an organization",
+		Long: `Add or update an organization that commands can target with --to-org.
+
+Each organization references a user whose token is used to act in it, so a
+single configuration can manage several customer organizations side by side.`,
+		Example: `  kubiya config set-org acme-staging --id 7f0c... --user acme-staging-bot --token $ACME_STAGING_KEY
+  kubiya agent copy <uuid> --to-org acme-staging`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orgName := args[0]
+
+			if user == "" {
+				user = orgName
+			}
+
+			org := context.Organization{
+				ID:     orgID,
+				Name:   orgName,
+				User:   user,
+				APIURL: apiURL,
+			}
+
+			if token != "" {
+				if err := context.SetUser(user, context.User{Token: token}); err != nil {
+					return fmt.Errorf("failed to set user: %w", err)
+				}
+			} else if _, err := context.GetUser(user); err != nil {
+				return fmt.Errorf("user %q has no token yet, pass --token", user)
+			}
+
+			if err := context.SetOrganization(orgName, org); err != nil {
+				return fmt.Errorf("failed to set organization: %w", err)
+			}
+
+			fmt.Printf("%s Organization %s created/updated\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(orgName))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&orgID, "id", "", "Organization ID")
+	cmd.Flags().StringVar(&user, "user", "", "User whose token is used for this organization (default: the organization name)")
+	cmd.Flags().StringVar(&token, "token", "", "API token for the user (if not provided, will use existing)")
+	cmd.Flags().StringVar(&apiURL, "api-url", "", "Control plane URL (default: https://control-plane.kubiya.ai)")
+
+	return cmd
+}
+
+func newConfigDeleteOrgCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-org ORG_NAME",
+		Short: "Delete an organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orgName := args[0]
+
+			if err := context.DeleteOrganization(orgName); err != nil {
+				return fmt.Errorf("failed to delete organization: %w", err)
+			}
+
+			fmt.Printf("%s Organization %s deleted\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(orgName))
+			return nil
+		},
+	}
 }
 
 func init() {
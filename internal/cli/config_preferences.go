@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configKeysHelp lists the preference keys for the help of config get/set/unset
+func configKeysHelp() string {
+	keys := context.PreferenceKeys()
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Keys:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-18s %s\n", name, keys[name])
+	}
+	return b.String()
+}
+
+func newConfigGetCmd() *cobra.Command {
+	var contextName string
+	cmd := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print the effective value of a preference",
+		Long: `Print the effective value of a preference: the value set for the context,
+or the global value when the context does not override it.
+
+` + configKeysHelp(),
+		Example: `  kubiya config get runner
+  kubiya config get output --context staging`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := context.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			if contextName == "" {
+				if _, name, err := context.GetCurrentContext(); err == nil {
+					contextName = name
+				}
+			}
+
+			prefs := config.EffectivePreferences(contextName)
+			value, ok, err := prefs.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&contextName, "context", "", "Context to read (default: current context)")
+	return cmd
+}
+
+func newConfigSetCmd() *cobra.Command {
+	var contextName string
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set a preference",
+		Long: `Set a preference for all contexts, or only for one with --context.
+
+` + configKeysHelp(),
+		Example: `  kubiya config set runner my-runner
+  kubiya config set output json --context ci
+  kubiya config set telemetry.enabled false
+  kubiya config set cache.ttl 10m`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updatePreferences(cmd.OutOrStdout(), contextName, func(p *context.Preferences) error {
+				return p.Set(args[0], args[1])
+			}, fmt.Sprintf("%s set to %s", style.HighlightStyle.Render(args[0]), style.HighlightStyle.Render(args[1])))
+		},
+	}
+	cmd.Flags().StringVar(&contextName, "context", "", "Only set the preference for this context")
+	return cmd
+}
+
+func newConfigUnsetCmd() *cobra.Command {
+	var contextName string
+	cmd := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove a preference",
+		Long: `Remove a global preference, or the override of one context with --context.
+
+` + configKeysHelp(),
+		Example: `  kubiya config unset runner
+  kubiya config unset output --context ci`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updatePreferences(cmd.OutOrStdout(), contextName, func(p *context.Preferences) error {
+				return p.Unset(args[0])
+			}, fmt.Sprintf("%s unset", style.HighlightStyle.Render(args[0])))
+		},
+	}
+	cmd.Flags().StringVar(&contextName, "context", "", "Only unset the override of this context")
+	return cmd
+}
+
+// updatePreferences applies update to the global preferences or those of
+// contextName and saves the config file
+func updatePreferences(w io.Writer, contextName string, update func(p *context.Preferences) error, done string) error {
+	config, err := context.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	prefs, err := config.PreferencesFor(contextName)
+	if err != nil {
+		return err
+	}
+	if err := update(prefs); err != nil {
+		return err
+	}
+	config.CompactPreferences()
+	if err := context.SaveConfig(config); err != nil {
+		return err
+	}
+
+	if contextName != "" {
+		done += fmt.Sprintf(" for context %s", style.HighlightStyle.Render(contextName))
+	}
+	fmt.Fprintf(w, "%s %s\n", style.SuccessStyle.Render("✓"), done)
+	return nil
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration file for unknown keys and invalid values",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := context.GetConfigPath()
+			if err != nil {
+				return err
+			}
+			issues, err := context.ValidateConfigFile()
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s is valid\n", style.SuccessStyle.Render("✓"), path)
+				return nil
+			}
+
+			for _, issue := range issues {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", style.ErrorStyle.Render("✗"), issue)
+			}
+			return fmt.Errorf("%s has %d issue(s)", path, len(issues))
+		},
+	}
+}
+
+// warnConfigIssues prints the problems of the config file to w, pointing to
+// `kubiya config validate`
+func warnConfigIssues(w io.Writer) {
+	issues, err := context.ValidateConfigFile()
+	if err != nil || len(issues) == 0 {
+		return
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s config: %s\n", style.WarningStyle.Render("⚠️"), issue)
+	}
+	fmt.Fprintf(w, "%s\n", style.DimStyle.Render("Run 'kubiya config validate' after fixing the configuration file"))
+}
+
+// applyOutputPreference defaults the --output flag of cmd to the preferred
+// format when the flag was not given and the command supports the format
+func applyOutputPreference(cmd *cobra.Command, format string) {
+	if format == "" {
+		return
+	}
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil || flag.Changed || !outputFlagSupports(flag, format) {
+		return
+	}
+	_ = flag.Value.Set(format)
+}
+
+// noEmojiDefault returns the default of --no-emoji: KUBIYA_NO_EMOJI when it
+// is set, otherwise the no-emoji preference
+func noEmojiDefault(prefs context.Preferences) bool {
+	if v, err := strconv.ParseBool(os.Getenv("KUBIYA_NO_EMOJI")); err == nil {
+		return v
+	}
+	return prefs.NoEmoji != nil && *prefs.NoEmoji
+}
+
+// structuredOutput reports whether cmd writes JSON or YAML to stdout, which
+// is passed on untouched by --no-emoji
+func structuredOutput(cmd *cobra.Command) bool {
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil {
+		return false
+	}
+	switch flag.Value.String() {
+	case "json", "yaml":
+		return true
+	}
+	return false
+}
+
+// outputFlagSupports reports whether the usage of an output flag lists format
+func outputFlagSupports(flag *pflag.Flag, format string) bool {
+	usage := strings.ToLower(flag.Usage)
+	if !strings.HasPrefix(usage, "output format") {
+		return false
+	}
+	return strings.ContainsAny(usage, "(:") && strings.Contains(usage, format)
+}
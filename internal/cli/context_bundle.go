@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+)
+
+// bundleCommandTimeout bounds how long a command of a context bundle may run
+const bundleCommandTimeout = time.Minute
+
+func newContextBundleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "context-bundle",
+		Aliases: []string{"context-bundles", "bundle"},
+		Short:   "📦 Manage named chat context bundles",
+		Long: `Manage named collections of chat context.
+
+A bundle groups file globs (or URLs) and shell commands whose output is sent
+as context, so they can be reused with 'kubiya chat --bundle NAME' instead of
+repeating --context flags. Bundles are stored under context-bundles in the
+config file.`,
+	}
+
+	cmd.AddCommand(
+		newContextBundleCreateCommand(),
+		newContextBundleListCommand(),
+		newContextBundleShowCommand(),
+		newContextBundleDeleteCommand(),
+	)
+
+	return cmd
+}
+
+func newContextBundleCreateCommand() *cobra.Command {
+	var (
+		globs       []string
+		commands    []string
+		description string
+		force       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "➕ Create a context bundle",
+		Example: `  kubiya context-bundle create prod-k8s --glob "k8s/**/*.yaml" --cmd "kubectl get nodes -o wide"
+  kubiya chat --bundle prod-k8s -m "Why are pods pending?"
+
+  # Replace an existing bundle
+  kubiya context-bundle create prod-k8s --glob "k8s/prod/*.yaml" --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if len(globs) == 0 && len(commands) == 0 {
+				return fmt.Errorf("a bundle needs at least one --glob or --cmd")
+			}
+			for _, pattern := range globs {
+				if isURL(pattern) {
+					continue
+				}
+				if _, err := globFiles(pattern); err != nil {
+					return err
+				}
+			}
+			if !force {
+				if _, err := kubiyacontext.GetContextBundle(name); err == nil {
+					return fmt.Errorf("context bundle %q already exists (use --force to replace it)", name)
+				}
+			}
+
+			bundle := kubiyacontext.ContextBundle{
+				Description: description,
+				Globs:       globs,
+				Commands:    commands,
+			}
+			if err := kubiyacontext.SetContextBundle(name, bundle); err != nil {
+				return fmt.Errorf("failed to save context bundle: %w", err)
+			}
+
+			fmt.Printf("Context bundle %q saved. Use it with: kubiya chat --bundle %s\n", name, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&globs, "glob", nil, "Files to include, ** matches any directories (repeatable, URLs allowed)")
+	cmd.Flags().StringArrayVar(&commands, "cmd", nil, "Shell command whose output is included (repeatable)")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "Description of the bundle")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing bundle with the same name")
+
+	return cmd
+}
+
+func newContextBundleListCommand() *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "📋 List context bundles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bundles, err := kubiyacontext.ListContextBundles()
+			if err != nil {
+				return fmt.Errorf("failed to list context bundles: %w", err)
+			}
+
+			if outputFormat == "json" {
+				return printJSON(bundles)
+			}
+
+			if len(bundles) == 0 {
+				fmt.Println("No context bundles configured. Use 'kubiya context-bundle create' to add one.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tGLOBS\tCOMMANDS\tDESCRIPTION")
+			for _, nb := range bundles {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", nb.Name, len(nb.Bundle.Globs), len(nb.Bundle.Commands), valueOrDash(nb.Bundle.Description))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+func newContextBundleShowCommand() *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "show NAME",
+		Aliases: []string{"get"},
+		Short:   "🔍 Show a context bundle and the files it matches",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bundle, err := kubiyacontext.GetContextBundle(args[0])
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				return printJSON(kubiyacontext.NamedContextBundle{Name: args[0], Bundle: *bundle})
+			}
+
+			fmt.Printf("Name:        %s\n", args[0])
+			fmt.Printf("Description: %s\n", valueOrDash(bundle.Description))
+			if len(bundle.Globs) > 0 {
+				fmt.Println("Globs:")
+				for _, pattern := range bundle.Globs {
+					if isURL(pattern) {
+						fmt.Printf("  %s (URL)\n", pattern)
+						continue
+					}
+					matches, err := globFiles(pattern)
+					if err != nil {
+						fmt.Printf("  %s (%v)\n", pattern, err)
+						continue
+					}
+					fmt.Printf("  %s (%d files)\n", pattern, len(matches))
+				}
+			}
+			if len(bundle.Commands) > 0 {
+				fmt.Println("Commands:")
+				for _, command := range bundle.Commands {
+					fmt.Printf("  $ %s\n", command)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+func newContextBundleDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete NAME",
+		Aliases: []string{"rm"},
+		Short:   "🗑️ Delete a context bundle",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubiyacontext.DeleteContextBundle(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Context bundle %q deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+// resolveContextBundles looks up the named bundles and returns their globs,
+// to be read like --context patterns, and the output of their commands keyed
+// by "$ command"
+func resolveContextBundles(ctx context.Context, names []string) ([]string, map[string]string, error) {
+	var globs []string
+	outputs := make(map[string]string)
+	for _, name := range names {
+		bundle, err := kubiyacontext.GetContextBundle(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		globs = append(globs, bundle.Globs...)
+		for _, command := range bundle.Commands {
+			out, err := runBundleCommand(ctx, command)
+			if err != nil {
+				return nil, nil, fmt.Errorf("context bundle %s: %w", name, err)
+			}
+			outputs["$ "+command] = out
+		}
+	}
+	return globs, outputs, nil
+}
+
+// runBundleCommand runs command with the shell and returns its combined output
+func runBundleCommand(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bundleCommandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("command %q did not finish within %s", command, bundleCommandTimeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			return "", fmt.Errorf("command %q failed: %w", command, err)
+		}
+		return "", fmt.Errorf("command %q failed: %w: %s", command, err, msg)
+	}
+	return string(out), nil
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// globFiles is filepath.Glob with support for ** matching any number of
+// directories, e.g. k8s/**/*.yaml
+func globFiles(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	for _, seg := range segments {
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+	}
+
+	// Walk from the longest directory prefix without wildcards
+	fixed := 0
+	for fixed < len(segments)-1 && !strings.ContainsAny(segments[fixed], `*?[\`) {
+		fixed++
+	}
+	root := strings.Join(segments[:fixed], "/")
+	switch {
+	case fixed == 1 && segments[0] == "":
+		root = "/"
+	case root == "":
+		root = "."
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == filepath.FromSlash(root) && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && matchSegments(segments, strings.Split(filepath.ToSlash(p), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// matchSegments matches path segments against pattern segments, where a **
+// segment matches zero or more path segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
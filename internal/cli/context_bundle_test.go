@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+)
+
+func TestGlobFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"k8s/a.yaml", "k8s/prod/b.yaml", "k8s/prod/deep/c.yaml", "k8s/prod/notes.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o644))
+	}
+
+	matches, err := globFiles(filepath.Join(dir, "k8s/**/*.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "k8s/a.yaml"),
+		filepath.Join(dir, "k8s/prod/b.yaml"),
+		filepath.Join(dir, "k8s/prod/deep/c.yaml"),
+	}, matches)
+
+	matches, err = globFiles(filepath.Join(dir, "k8s/prod/**"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 3)
+
+	matches, err = globFiles(filepath.Join(dir, "missing/**/*.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = globFiles(filepath.Join(dir, "k8s/**/[.yaml"))
+	assert.Error(t, err)
+}
+
+func TestResolveContextBundles(t *testing.T) {
+	t.Setenv("KUBIYA_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, kubiyacontext.SetContextBundle("prod-k8s", kubiyacontext.ContextBundle{
+		Globs:    []string{"k8s/**/*.yaml"},
+		Commands: []string{"echo nodes"},
+	}))
+
+	globs, outputs, err := resolveContextBundles(context.Background(), []string{"prod-k8s"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k8s/**/*.yaml"}, globs)
+	assert.Equal(t, map[string]string{"$ echo nodes": "nodes\n"}, outputs)
+
+	_, _, err = resolveContextBundles(context.Background(), []string{"staging"})
+	assert.EqualError(t, err, `context bundle "staging" not found`)
+
+	require.NoError(t, kubiyacontext.SetContextBundle("broken", kubiyacontext.ContextBundle{
+		Commands: []string{"echo oops >&2; exit 3"},
+	}))
+	_, _, err = resolveContextBundles(context.Background(), []string{"broken"})
+	assert.ErrorContains(t, err, "oops")
+}
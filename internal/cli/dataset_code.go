@@ -3,7 +3,6 @@ package cli
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -243,8 +242,7 @@ Supported languages: Python, JavaScript, TypeScript, Go, Java, Rust, C/C++, Ruby
 					"total_files":       len(files),
 					"session_id":        session.ID,
 				}
-				data, _ := json.MarshalIndent(result, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(result)
 			case "yaml":
 				result := map[string]interface{}{
 					"job_id":          commitResp.JobID,
@@ -320,8 +318,9 @@ func newDatasetCodeStatusCommand(cfg *config.Config) *cobra.Command {
 				// Output
 				switch outputFormat {
 				case "json":
-					data, _ := json.MarshalIndent(status, "", "  ")
-					fmt.Println(string(data))
+					if err := printJSON(status); err != nil {
+						return err
+					}
 				case "yaml":
 					data, _ := yaml.Marshal(status)
 					fmt.Println(string(data))
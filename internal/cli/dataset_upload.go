@@ -106,8 +106,7 @@ The uploaded knowledge will be processed and made available for semantic search.
 					"uploaded_count": uploadedCount,
 					"status":         "success",
 				}
-				data, _ := json.MarshalIndent(result, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(result)
 			case "yaml":
 				result := map[string]interface{}{
 					"dataset_id":     datasetID,
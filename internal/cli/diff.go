@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// unifiedDiff returns a unified text diff between two texts, or an empty
+// string when they are equal
+func unifiedDiff(from, to, fromName, toName string) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(ensureTrailingNewline(from)),
+		B:        difflib.SplitLines(ensureTrailingNewline(to)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute diff: %w", err)
+	}
+	return diff, nil
+}
+
+// printColoredDiff writes a unified diff with added lines in green, removed
+// lines in red and hunk headers dimmed
+func printColoredDiff(w io.Writer, diff string) {
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			fmt.Fprintln(w, style.HighlightStyle.Render(text))
+		case strings.HasPrefix(text, "@@"):
+			fmt.Fprintln(w, style.DimStyle.Render(text))
+		case strings.HasPrefix(text, "+"):
+			fmt.Fprintln(w, style.SuccessStyle.Render(text))
+		case strings.HasPrefix(text, "-"):
+			fmt.Fprintln(w, style.ErrorStyle.Render(text))
+		default:
+			fmt.Fprintln(w, text)
+		}
+	}
+}
+
+func ensureTrailingNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -57,6 +57,7 @@ func newDocumentationCommand(cfg *config.Config) *cobra.Command {
 
 	cmd.AddCommand(
 		newQueryDocumentationCommand(cfg),
+		newDocsManCommand(cfg),
 	)
 
 	return cmd
@@ -3,13 +3,16 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // loadEnvFile loads environment variables from a .env file with security validations.
-// It parses KEY=VALUE format, skips comments, and removes quotes from values.
+// It parses KEY=VALUE format with the usual dotenv rules: comments, an optional
+// "export " prefix, single quoted literals and double quoted values with escapes.
 func loadEnvFile(path string) (map[string]string, error) {
 	// Validate path for security
 	if err := validateFilePath(path); err != nil {
@@ -34,6 +37,7 @@ func loadEnvFile(path string) (map[string]string, error) {
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
 
 		// Parse KEY=VALUE format
 		parts := strings.SplitN(line, "=", 2)
@@ -44,7 +48,6 @@ func loadEnvFile(path string) (map[string]string, error) {
 		}
 
 		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
 
 		// Validate key is not empty
 		if key == "" {
@@ -52,14 +55,16 @@ func loadEnvFile(path string) (map[string]string, error) {
 			continue
 		}
 
-		// Remove surrounding quotes if present (both single and double)
-		value = strings.Trim(value, `"'`)
-
 		// Basic sanitization: ensure key doesn't contain suspicious characters
 		if strings.ContainsAny(key, "\n\r\t") {
 			return nil, fmt.Errorf("line %d: key contains invalid characters", lineNumber)
 		}
 
+		value, err := parseEnvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
 		envMap[key] = value
 	}
 
@@ -70,6 +75,93 @@ func loadEnvFile(path string) (map[string]string, error) {
 	return envMap, nil
 }
 
+// parseEnvValue applies dotenv quoting rules to the raw value of a line.
+// Single quoted values are taken literally, double quoted values support
+// \n, \t, \" and \\ escapes, and unquoted values end at an inline " #" comment.
+func parseEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var sb strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return sb.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case 'r':
+					sb.WriteByte('\r')
+				default:
+					sb.WriteByte(raw[i])
+				}
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	}
+
+	if idx := strings.Index(raw, " #"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// writeDotenv writes env as a .env file that loadEnvFile reads back unchanged.
+// Keys are sorted and values are double quoted when they need it.
+func writeDotenv(w io.Writer, env map[string]string) error {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value := env[k]
+		if value != "" && strings.ContainsAny(value, " \t\n\r#\"'\\") {
+			value = `"` + dotenvEscaper.Replace(value) + `"`
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", k, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// envFileEntries returns the variables of an env file as sorted KEY=VALUE
+// strings, so they can be put in front of --env style flags which then win
+// on conflicts.
+func envFileEntries(path string) ([]string, error) {
+	env, err := loadEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]string, 0, len(env))
+	for k, v := range env {
+		entries = append(entries, k+"="+v)
+	}
+	sort.Strings(entries)
+	return entries, nil
+}
+
 // validateFilePath validates a file path for security concerns.
 // It prevents directory traversal and ensures the file exists and is readable.
 func validateFilePath(path string) error {
@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -380,3 +381,51 @@ OPTIONAL_CONFIG=
 		assert.Equal(t, expected, result)
 	})
 }
+
+func TestLoadEnvFile_QuotingRules(t *testing.T) {
+	content := `export REGION=us-east-1
+GREETING="hello \"world\"\nbye" # trailing comment
+LITERAL='no \n escapes # here'
+INLINE=value # comment
+HASH=abc#def
+`
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	result, err := loadEnvFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"REGION":   "us-east-1",
+		"GREETING": "hello \"world\"\nbye",
+		"LITERAL":  `no \n escapes # here`,
+		"INLINE":   "value",
+		"HASH":     "abc#def",
+	}, result)
+
+	require.NoError(t, os.WriteFile(path, []byte("BROKEN=\"never closed\n"), 0600))
+	_, err = loadEnvFile(path)
+	assert.ErrorContains(t, err, "line 1: unterminated double quote")
+}
+
+func TestWriteDotenv_RoundTrip(t *testing.T) {
+	env := map[string]string{
+		"PLAIN":     "value",
+		"SPACES":    "hello world",
+		"MULTILINE": "a\nb",
+		"QUOTES":    `say "hi" and 'bye'`,
+		"BACKSLASH": `C:\path`,
+		"EMPTY":     "",
+	}
+
+	var buf strings.Builder
+	require.NoError(t, writeDotenv(&buf, env))
+	assert.True(t, strings.HasPrefix(buf.String(), "BACKSLASH="), "expected sorted keys, got %q", buf.String())
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte(buf.String()), 0600))
+
+	result, err := loadEnvFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, env, result)
+}
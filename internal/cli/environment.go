@@ -97,8 +97,7 @@ func newCreateEnvironmentCommand(cfg *config.Config) *cobra.Command {
 				environment.ID)
 
 			if outputFormat == "json" {
-				data, _ := json.MarshalIndent(environment, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(environment)
 			} else if outputFormat == "yaml" {
 				data, _ := yaml.Marshal(environment)
 				fmt.Print(string(data))
@@ -41,7 +41,7 @@ You can list all executions, view details, stream logs, and cancel running execu
 
 	cmd.AddCommand(
 		newListExecutionsCommand(cfg),
-		newGetExecutionCommand(cfg),
+		paged(newGetExecutionCommand(cfg)),
 		newExecutionLogsCommand(cfg),
 		newCancelExecutionCommand(cfg),
 	)
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/kubiyabot/cli/internal/tfexport"
+)
+
+// exportableResources are the resource kinds export terraform can include
+var exportableResources = []string{"agents", "sources", "webhooks"}
+
+func newExportCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "📤 Export resources as infrastructure as code",
+		Long:  `Export existing resources to configuration managed by other tools.`,
+	}
+
+	cmd.AddCommand(newExportTerraformCommand(cfg))
+	return cmd
+}
+
+func newExportTerraformCommand(cfg *config.Config) *cobra.Command {
+	var (
+		outDir  string
+		include []string
+		force   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "terraform",
+		Aliases: []string{"tf", "opentofu"},
+		Short:   "🏗️  Generate Terraform configuration for existing resources",
+		Long: `Read the agents, sources and webhooks of the organization and write HCL for
+the Kubiya Terraform provider, with import blocks that adopt the existing
+resources on the first apply instead of recreating them.
+
+Resource names are derived from the resource names, made unique and safe to
+use as Terraform identifiers. Agents refer to the exported sources and
+webhooks to the exported agents.
+
+The import blocks need Terraform 1.5 or later, or OpenTofu.`,
+		Example: `  # Export everything
+  kubiya export terraform --out ./tf
+  cd tf && terraform init && terraform plan
+
+  # Only agents and their sources
+  kubiya export terraform --out ./tf --include agents,sources`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selected := map[string]bool{}
+			for _, kind := range include {
+				kind = strings.TrimSpace(kind)
+				if !contains(exportableResources, kind) {
+					return fmt.Errorf("invalid --include %q (use %s)", kind, strings.Join(exportableResources, ", "))
+				}
+				selected[kind] = true
+			}
+
+			client := kubiya.NewClient(cfg)
+			ctx := cmd.Context()
+
+			var (
+				agents   []kubiya.Agent
+				sources  []kubiya.Source
+				webhooks []kubiya.Webhook
+				err      error
+			)
+			if selected["agents"] {
+				if agents, err = client.GetAgents(ctx); err != nil {
+					return fmt.Errorf("failed to list agents: %w", err)
+				}
+			}
+			if selected["sources"] {
+				if sources, err = client.ListSources(ctx); err != nil {
+					return err
+				}
+			}
+			if selected["webhooks"] {
+				if webhooks, err = client.ListWebhooks(ctx); err != nil {
+					return fmt.Errorf("failed to list webhooks: %w", err)
+				}
+			}
+
+			export, err := tfexport.Build(agents, sources, webhooks)
+			if err != nil {
+				return err
+			}
+			written, err := export.WriteDir(outDir, force)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			for _, path := range written {
+				fmt.Fprintf(out, "  %s %s\n", style.DimStyle.Render("•"), path)
+			}
+			fmt.Fprintf(out, "%s Exported %d agents, %d sources and %d webhooks to %s\n",
+				style.SuccessStyle.Render("✓"),
+				len(export.Agents), len(export.Sources), len(export.Webhooks),
+				style.HighlightStyle.Render(outDir))
+			fmt.Fprintf(out, "%s Run 'terraform init && terraform plan' there to review the imports\n",
+				style.DimStyle.Render("→"))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&outDir, "out", "./tf", "Directory to write the configuration to")
+	cmd.Flags().StringSliceVar(&include, "include", exportableResources, "Resources to export (agents, sources, webhooks)")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing files")
+	return cmd
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// flagRule is an entry of the flag compatibility matrix of a command. It is
+// about flag, when set on the command line, and is broken when flag:
+//   - has no effect unless one of needs is set
+//   - has no effect when one of ignoredWith is set
+//   - cannot be combined with one of conflicts
+//   - has no effect at all, when none of the above are given
+type flagRule struct {
+	flag        string
+	needs       []string
+	ignoredWith []string
+	conflicts   []string
+	// when restricts the rule to some values of the flags, e.g. a webhook
+	// method that needs a destination
+	when func(flags *pflag.FlagSet) bool
+	// hint is appended to the message of the rule
+	hint string
+}
+
+// check returns what is wrong with the flags, or "" when the rule holds
+func (r flagRule) check(flags *pflag.FlagSet) string {
+	if !flags.Changed(r.flag) || (r.when != nil && !r.when(flags)) {
+		return ""
+	}
+
+	var problem string
+	switch {
+	case len(r.needs) > 0:
+		if anyFlagChanged(flags, r.needs) {
+			return ""
+		}
+		problem = fmt.Sprintf("--%s has no effect without %s", r.flag, flagList(r.needs, "or"))
+	case len(r.ignoredWith) > 0:
+		set := changedFlags(flags, r.ignoredWith)
+		if len(set) == 0 {
+			return ""
+		}
+		problem = fmt.Sprintf("--%s has no effect with %s", r.flag, flagList(set, "and"))
+	case len(r.conflicts) > 0:
+		set := changedFlags(flags, r.conflicts)
+		if len(set) == 0 {
+			return ""
+		}
+		problem = fmt.Sprintf("--%s cannot be used with %s", r.flag, flagList(set, "and"))
+	default:
+		problem = fmt.Sprintf("--%s has no effect", r.flag)
+	}
+	if r.hint != "" {
+		problem += " (" + r.hint + ")"
+	}
+	return problem
+}
+
+func anyFlagChanged(flags *pflag.FlagSet, names []string) bool {
+	return len(changedFlags(flags, names)) > 0
+}
+
+func changedFlags(flags *pflag.FlagSet, names []string) []string {
+	var set []string
+	for _, name := range names {
+		if flags.Changed(name) {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+func flagList(names []string, conj string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "--" + name
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " " + conj + " " + quoted[len(quoted)-1]
+}
+
+// checkFlagRules fails listing every broken rule of flags, or with lenient
+// prints them to w as warnings
+func checkFlagRules(flags *pflag.FlagSet, rules []flagRule, lenient bool, w io.Writer) error {
+	var problems []string
+	for _, rule := range rules {
+		if problem := rule.check(flags); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if lenient {
+		for _, problem := range problems {
+			fmt.Fprintf(w, "%s %s\n", style.WarningStyle.Render("⚠️"), problem)
+		}
+		return nil
+	}
+	if len(problems) == 1 {
+		return fmt.Errorf("%s; use --lenient to ignore", problems[0])
+	}
+	return fmt.Errorf("incompatible flags; use --lenient to ignore:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// withFlagRules checks the flags of cmd against rules before it runs, and
+// adds --lenient to only warn about broken rules
+func withFlagRules(cmd *cobra.Command, rules []flagRule) *cobra.Command {
+	var lenient bool
+	cmd.Flags().BoolVar(&lenient, "lenient", false, "Warn about flags that conflict or have no effect instead of failing")
+
+	preRunE := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := checkFlagRules(cmd.Flags(), rules, lenient, os.Stderr); err != nil {
+			return err
+		}
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		return nil
+	}
+	return cmd
+}
+
+// flagValueIsNot restricts a rule to flag not having value
+func flagValueIsNot(flag, value string) func(*pflag.FlagSet) bool {
+	return func(flags *pflag.FlagSet) bool {
+		f := flags.Lookup(flag)
+		return f != nil && f.Value.String() != value
+	}
+}
+
+// chatInlineFlags only apply to the agent created by chat --inline
+var chatInlineFlags = []string{
+	"agent-spec", "tools-file", "tools-json", "ai-instructions", "description", "runners",
+	"integrations", "secrets", "env-vars", "env-file", "llm-model", "debug-mode", "source", "service",
+	"wait-for-services",
+}
+
+// chatFlagRules is the flag compatibility matrix of chat
+func chatFlagRules() []flagRule {
+	rules := []flagRule{
+		{flag: "source-test", hint: "kept for compatibility"},
+		{flag: "source-uuid", hint: "use --source with --inline"},
+		{flag: "source-name", hint: "use --source with --inline"},
+		{flag: "suggest-tool", hint: "kept for compatibility"},
+		{flag: "replay-history", needs: []string{"session", "fork-session"}},
+		{flag: "delimiter", needs: []string{"stdin-stream"}},
+		{flag: "approval-via", needs: []string{"require-approval-from"}},
+		{flag: "approval-timeout", needs: []string{"require-approval-from"}},
+		{flag: "approval-channel", needs: []string{"require-approval-from"}},
+		{flag: "approval-channel", when: flagValueIsNot("approval-via", approvalViaSlack),
+			hint: "only Slack approvals are posted to a channel, use --approval-via slack"},
+		{flag: "notify-on-complete", conflicts: []string{"interactive"}},
+		{flag: "classify-among", ignoredWith: []string{"name", "agent", "inline", "no-classify"}},
+		{flag: "explain-classification", ignoredWith: []string{"name", "agent", "inline", "no-classify"}},
+		{flag: "explain-candidates", needs: []string{"explain-classification"}},
+		{flag: "no-classify", ignoredWith: []string{"name", "agent", "inline"}},
+		{flag: "clear-session", conflicts: []string{"message", "prompt-file", "stdin", "stdin-stream", "session", "fork-session"}},
+		{flag: "stdin", conflicts: []string{"message"}},
+	}
+	for _, flag := range chatInlineFlags {
+		rules = append(rules, flagRule{flag: flag, needs: []string{"inline"}})
+	}
+	return rules
+}
+
+// agentWebhookFlagRules is the flag compatibility matrix of the webhook
+// flags of agent create and edit
+func agentWebhookFlagRules() []flagRule {
+	return []flagRule{
+		{flag: "webhook-method", when: flagValueIsNot("webhook-method", "http"), needs: []string{"webhook-dest"},
+			hint: "slack and teams webhooks are created for each destination"},
+		{flag: "webhook-prompt", when: flagValueIsNot("webhook-method", "http"), needs: []string{"webhook-dest"}},
+	}
+}
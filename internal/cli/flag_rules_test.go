@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+)
+
+func TestFlagRules(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"inline flag without inline", []string{"--tools-json", "[]"}, "--tools-json has no effect without --inline"},
+		{"inline flag with inline", []string{"--inline", "--tools-json", "[]"}, ""},
+		{"ignored with", []string{"-n", "devops", "--classify-among", "a,b"}, "--classify-among has no effect with --name"},
+		{"conflicts", []string{"--clear-session", "-m", "hi"}, "--clear-session cannot be used with --message"},
+		{"no effect at all", []string{"--source-uuid", "abc"}, "--source-uuid has no effect (use --source with --inline)"},
+		{"value dependent", []string{"--require-approval-from", "group:sre", "--approval-channel", "C1"}, "--approval-channel has no effect (only Slack approvals"},
+		{"value dependent holds", []string{"--require-approval-from", "group:sre", "--approval-via", "slack", "--approval-channel", "C1"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newChatCommand(&config.Config{})
+			require.NoError(t, cmd.ParseFlags(tt.args))
+
+			err := checkFlagRules(cmd.Flags(), chatFlagRules(), false, &bytes.Buffer{})
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestFlagRulesLenient(t *testing.T) {
+	cmd := newChatCommand(&config.Config{})
+	require.NoError(t, cmd.ParseFlags([]string{"--llm-model", "gpt-4o", "--delimiter", "---"}))
+
+	err := checkFlagRules(cmd.Flags(), chatFlagRules(), false, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "incompatible flags")
+	assert.Contains(t, err.Error(), "--delimiter has no effect without --stdin-stream")
+	assert.Contains(t, err.Error(), "--llm-model has no effect without --inline")
+
+	var warnings bytes.Buffer
+	require.NoError(t, checkFlagRules(cmd.Flags(), chatFlagRules(), true, &warnings))
+	assert.Contains(t, warnings.String(), "--llm-model has no effect without --inline")
+}
+
+func TestWithFlagRules(t *testing.T) {
+	ran := false
+	cmd := withFlagRules(&cobra.Command{
+		Use:     "test",
+		PreRunE: func(*cobra.Command, []string) error { ran = true; return nil },
+		RunE:    func(*cobra.Command, []string) error { return nil },
+	}, agentWebhookFlagRules())
+	cmd.Flags().String("webhook-method", "http", "")
+	cmd.Flags().StringArray("webhook-dest", nil, "")
+	cmd.Flags().String("webhook-prompt", "", "")
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+
+	cmd.SetArgs([]string{"--webhook-method", "slack"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--webhook-method has no effect without --webhook-dest")
+	assert.False(t, ran)
+
+	cmd.SetArgs([]string{"--webhook-method", "slack", "--webhook-dest", "#alerts"})
+	require.NoError(t, cmd.Execute())
+	assert.True(t, ran)
+
+	cmd.SetArgs([]string{"--webhook-method", "http", "--lenient"})
+	assert.NoError(t, cmd.Execute())
+}
@@ -2,9 +2,7 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/kubiyabot/cli/internal/config"
@@ -827,9 +825,7 @@ Only read-only queries are allowed.`,
 
 // printGraphJSON prints data as formatted JSON
 func printGraphJSON(data interface{}) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	return printJSON(data)
 }
 
 // newGraphSearchCommand creates the intelligent search command
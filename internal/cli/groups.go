@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func newGroupCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "group",
+		Aliases: []string{"groups"},
+		Short:   "👥 Manage groups",
+		Long:    "List groups and their members, for access control management",
+	}
+
+	cmd.AddCommand(
+		newGroupListCommand(cfg),
+		newGroupGetCommand(cfg),
+		newGroupMembersCommand(cfg),
+	)
+
+	return cmd
+}
+
+func newGroupListCommand(cfg *config.Config) *cobra.Command {
+	var (
+		outputFormat string
+		search       string
+		groupType    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "📋 List all groups",
+		Long:  "Display a list of all groups in the organization",
+		Example: `  kubiya group list
+  kubiya group list --output json
+
+  # Custom groups whose name or description contains "ops"
+  kubiya group list --search ops --type custom`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if groupType != "" && groupType != "system" && groupType != "custom" {
+				return fmt.Errorf("invalid --type %q (use system or custom)", groupType)
+			}
+
+			client := kubiya.NewClient(cfg)
+
+			groups, err := client.ListGroups(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list groups: %w", err)
+			}
+			groups = filterGroups(groups, search, groupType)
+
+			if outputFormat == "json" {
+				return printJSON(groups)
+			}
+
+			return printGroupsTable(groups)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.Flags().StringVarP(&search, "search", "s", "", "Only groups whose name or description contains this text")
+	cmd.Flags().StringVar(&groupType, "type", "", "Only system or custom groups")
+
+	return cmd
+}
+
+func newGroupGetCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "get [name|uuid]",
+		Short: "🔍 Show a group",
+		Example: `  kubiya group get DevOps
+  kubiya group get 5b9d1c2e-0f4a-4e3b-9a7c-1d2e3f4a5b6c --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+
+			groups, err := client.ListGroups(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list groups: %w", err)
+			}
+			group, err := findGroup(groups, args[0])
+			if err != nil {
+				return err
+			}
+			users, err := client.ListUsers(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list users: %w", err)
+			}
+			members := groupMembers(users, *group)
+
+			if outputFormat == "json" {
+				return printJSON(struct {
+					kubiya.Group
+					MemberCount int `json:"member_count"`
+				}{*group, len(members)})
+			}
+
+			groupType := "Custom"
+			if group.System {
+				groupType = "System"
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Name:\t%s\n", group.Name)
+			fmt.Fprintf(w, "UUID:\t%s\n", group.UUID)
+			fmt.Fprintf(w, "Description:\t%s\n", valueOrDash(group.Description))
+			fmt.Fprintf(w, "Type:\t%s\n", groupType)
+			if !group.CreatedAt.IsZero() {
+				fmt.Fprintf(w, "Created:\t%s\n", group.CreatedAt.Format("2006-01-02"))
+			}
+			fmt.Fprintf(w, "Members:\t%d\n", len(members))
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Printf("\nList them with: kubiya group members %q\n", group.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+func newGroupMembersCommand(cfg *config.Config) *cobra.Command {
+	var (
+		outputFormat string
+		search       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "members [name|uuid]",
+		Short: "👤 List the members of a group",
+		Example: `  kubiya group members DevOps
+  kubiya group members DevOps --search jane --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+
+			groups, err := client.ListGroups(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list groups: %w", err)
+			}
+			group, err := findGroup(groups, args[0])
+			if err != nil {
+				return err
+			}
+			users, err := client.ListUsers(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list users: %w", err)
+			}
+			members := filterUsers(groupMembers(users, *group), search, "")
+
+			if outputFormat == "json" {
+				return printJSON(members)
+			}
+
+			return printUsersTable(members)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.Flags().StringVarP(&search, "search", "s", "", "Only members whose email or name contains this text")
+
+	return cmd
+}
+
+// filterGroups keeps the groups whose name or description contains search,
+// ignoring case, and of groupType (system or custom); empty filters match
+// everything
+func filterGroups(groups []kubiya.Group, search, groupType string) []kubiya.Group {
+	search = strings.ToLower(search)
+	var filtered []kubiya.Group
+	for _, g := range groups {
+		if search != "" && !strings.Contains(strings.ToLower(g.Name), search) && !strings.Contains(strings.ToLower(g.Description), search) {
+			continue
+		}
+		if (groupType == "system" && !g.System) || (groupType == "custom" && g.System) {
+			continue
+		}
+		filtered = append(filtered, g)
+	}
+	return filtered
+}
@@ -21,6 +21,7 @@ func newIntegrationsCommand(cfg *config.Config) *cobra.Command {
 
 	cmd.AddCommand(
 		newActivateIntegrationCommand(cfg),
+		newIntegrationTemplatesCommand(cfg),
 	)
 
 	return cmd
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kbimport"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// knowledgeCreator is the part of kubiya.Client used to import knowledge
+type knowledgeCreator interface {
+	CreateKnowledge(ctx context.Context, item kubiya.Knowledge) (*kubiya.Knowledge, error)
+}
+
+// knowledgeImportResult is an item of an import
+type knowledgeImportResult struct {
+	Name      string `json:"name"`
+	Hierarchy string `json:"hierarchy"`
+	Size      int    `json:"size"`
+	UUID      string `json:"uuid,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// knowledgeImportSummary reports an import
+type knowledgeImportSummary struct {
+	Format     string                  `json:"format"`
+	Pages      int                     `json:"pages"`
+	EmptyPages int                     `json:"empty_pages"`
+	Skipped    []string                `json:"skipped_files,omitempty"`
+	Created    int                     `json:"created"`
+	Failed     int                     `json:"failed"`
+	DryRun     bool                    `json:"dry_run,omitempty"`
+	Items      []knowledgeImportResult `json:"items"`
+}
+
+func newKnowledgeImportCommand(cfg *config.Config) *cobra.Command {
+	var (
+		from         string
+		format       string
+		maxChars     int
+		labels       []string
+		dryRun       bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "📥 Import a Confluence or Notion export as knowledge items",
+		Long: `Import the pages of a Confluence space exported as HTML, or of a Notion
+workspace exported as Markdown, as knowledge items.
+
+Pages are converted to Markdown and long pages are split between sections
+into items of at most --max-chars characters, named "<title> (n/m)". Items
+keep the labels of their page and are labeled with the export format. The
+hierarchy of the page and the file it came from are kept as properties.`,
+		Example: `  # Preview what would be imported
+  kubiya knowledge import --from confluence-export.zip --dry-run
+
+  # Import a Notion export with an extra label
+  kubiya knowledge import --from notion-export.zip --label runbooks
+
+  # Import an extracted export in smaller items
+  kubiya knowledge import --from ./export --format confluence --max-chars 4000`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			export, err := kbimport.Open(from, format)
+			if err != nil {
+				return err
+			}
+
+			var client knowledgeCreator
+			if !dryRun {
+				client = kubiya.NewClient(cfg)
+			}
+			out := cmd.OutOrStdout()
+			progress := out
+			if outputFormat == "json" {
+				progress = io.Discard
+			}
+			summary := importKnowledge(cmd.Context(), client, export, maxChars, labels, progress)
+
+			if outputFormat == "json" {
+				if err := output.EncodeJSON(out, summary); err != nil {
+					return err
+				}
+			} else {
+				printKnowledgeImportSummary(out, summary)
+			}
+			if summary.Failed > 0 {
+				return fmt.Errorf("%d of %d knowledge items failed to import", summary.Failed, len(summary.Items))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Export zip file or directory to import")
+	cmd.Flags().StringVar(&format, "format", kbimport.FormatAuto, "Export format: auto, confluence or notion")
+	cmd.Flags().IntVar(&maxChars, "max-chars", kbimport.DefaultMaxChars, "Largest content of an item, longer pages are split")
+	cmd.Flags().StringSliceVar(&labels, "label", nil, "Label to add to every item (can be specified multiple times)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the items to create without creating them")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// importKnowledge creates the items of export, or only lists them when
+// client is nil. Failed items are reported and the import goes on.
+func importKnowledge(ctx context.Context, client knowledgeCreator, export *kbimport.Export, maxChars int, labels []string, w io.Writer) *knowledgeImportSummary {
+	summary := &knowledgeImportSummary{
+		Format:  export.Format,
+		Pages:   len(export.Pages),
+		Skipped: export.Skipped,
+		DryRun:  client == nil,
+		Items:   []knowledgeImportResult{},
+	}
+	pagesWithItems := map[*kbimport.Page]bool{}
+
+	for _, item := range export.Items(maxChars) {
+		pagesWithItems[item.Page] = true
+		result := knowledgeImportResult{Name: item.Name, Hierarchy: item.Page.Hierarchy(), Size: len(item.Content)}
+		if client != nil {
+			created, err := client.CreateKnowledge(ctx, kubiya.Knowledge{
+				Name:        item.Name,
+				Description: fmt.Sprintf("Imported from %s: %s", export.Format, item.Page.Hierarchy()),
+				Content:     item.Content,
+				Labels:      append(item.Labels, labels...),
+				Properties:  item.Properties,
+				Type:        "knowledge",
+				Source:      export.Format + "_import",
+			})
+			if err != nil {
+				result.Error = err.Error()
+				summary.Failed++
+				fmt.Fprintf(w, "%s %s: %v\n", style.ErrorStyle.Render("✗"), item.Name, err)
+			} else {
+				result.UUID = created.UUID
+				summary.Created++
+				fmt.Fprintf(w, "%s %s %s\n", style.SuccessStyle.Render("✓"), item.Name, style.DimStyle.Render(created.UUID))
+			}
+		}
+		summary.Items = append(summary.Items, result)
+	}
+	summary.EmptyPages = summary.Pages - len(pagesWithItems)
+	return summary
+}
+
+func printKnowledgeImportSummary(w io.Writer, s *knowledgeImportSummary) {
+	if s.DryRun {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ITEM\tSIZE\tHIERARCHY")
+		for _, item := range s.Items {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", item.Name, item.Size, item.Hierarchy)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%s %s export: %d pages, %d items\n",
+		style.HighlightStyle.Render("📥"), s.Format, s.Pages, len(s.Items))
+	if s.DryRun {
+		fmt.Fprintf(w, "   %s\n", style.DimStyle.Render("Dry run: nothing was created"))
+	} else {
+		fmt.Fprintf(w, "   %s %d created\n", style.SuccessStyle.Render("✓"), s.Created)
+		if s.Failed > 0 {
+			fmt.Fprintf(w, "   %s %d failed\n", style.ErrorStyle.Render("✗"), s.Failed)
+		}
+	}
+	if s.EmptyPages > 0 {
+		fmt.Fprintf(w, "   %s\n", style.DimStyle.Render(fmt.Sprintf("%d pages without content were left out", s.EmptyPages)))
+	}
+	for _, f := range s.Skipped {
+		fmt.Fprintf(w, "   %s %s\n", style.WarningStyle.Render("⚠️  Skipped"), f)
+	}
+}
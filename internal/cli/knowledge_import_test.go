@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kbimport"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeKnowledgeCreator struct {
+	created []kubiya.Knowledge
+	fail    string
+}
+
+func (f *fakeKnowledgeCreator) CreateKnowledge(ctx context.Context, item kubiya.Knowledge) (*kubiya.Knowledge, error) {
+	if item.Name == f.fail {
+		return nil, fmt.Errorf("quota exceeded")
+	}
+	f.created = append(f.created, item)
+	item.UUID = fmt.Sprintf("kb-%d", len(f.created))
+	return &item, nil
+}
+
+func TestImportKnowledge(t *testing.T) {
+	export := &kbimport.Export{Format: kbimport.FormatConfluence, Pages: []kbimport.Page{
+		{Title: "Deploy", Parents: []string{"Ops"}, Labels: []string{"release"}, Body: "Run the pipeline.", File: "Ops/Deploy_1.html"},
+		{Title: "Rollback", Parents: []string{"Ops"}, Body: "Revert the commit.", File: "Ops/Rollback_2.html"},
+		{Title: "Folder", Body: ""},
+	}, Skipped: []string{"Ops/Broken.html"}}
+
+	client := &fakeKnowledgeCreator{fail: "Rollback"}
+	var out bytes.Buffer
+	summary := importKnowledge(context.Background(), client, export, 0, []string{"runbooks"}, &out)
+
+	assert.Equal(t, 3, summary.Pages)
+	assert.Equal(t, 1, summary.EmptyPages)
+	assert.Equal(t, 1, summary.Created)
+	assert.Equal(t, 1, summary.Failed)
+	require.Len(t, summary.Items, 2)
+	assert.Equal(t, "kb-1", summary.Items[0].UUID)
+	assert.Equal(t, "quota exceeded", summary.Items[1].Error)
+
+	require.Len(t, client.created, 1)
+	item := client.created[0]
+	assert.Equal(t, []string{"confluence", "release", "runbooks"}, item.Labels)
+	assert.Equal(t, "Ops / Deploy", item.Properties["hierarchy"])
+	assert.Equal(t, "Ops/Deploy_1.html", item.Properties["source_file"])
+	assert.Equal(t, "confluence_import", item.Source)
+
+	// A dry run only lists the items
+	out.Reset()
+	summary = importKnowledge(context.Background(), nil, export, 0, nil, &out)
+	assert.True(t, summary.DryRun)
+	assert.Len(t, summary.Items, 2)
+	assert.Empty(t, out.String())
+	printKnowledgeImportSummary(&out, summary)
+	assert.Contains(t, out.String(), "Ops / Rollback")
+	assert.Contains(t, out.String(), "Ops/Broken.html")
+}
@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+const (
+	defaultKnowledgePageSize = 100
+	// maxKnowledgeCellWidth keeps the table readable when pages are
+	// aligned one at a time
+	maxKnowledgeCellWidth = 48
+)
+
+// knowledgeLister is the part of kubiya.Client used to list knowledge
+type knowledgeLister interface {
+	ListKnowledgePage(ctx context.Context, opts kubiya.KnowledgeListOptions) (*kubiya.KnowledgePage, error)
+}
+
+// knowledgeColumn is a column of 'knowledge list'
+type knowledgeColumn struct {
+	name  string
+	value func(k kubiya.Knowledge) interface{}
+}
+
+var knowledgeColumns = []knowledgeColumn{
+	{"uuid", func(k kubiya.Knowledge) interface{} { return k.UUID }},
+	{"name", func(k kubiya.Knowledge) interface{} { return k.Name }},
+	{"description", func(k kubiya.Knowledge) interface{} { return k.Description }},
+	{"labels", func(k kubiya.Knowledge) interface{} { return k.Labels }},
+	{"groups", func(k kubiya.Knowledge) interface{} { return k.Groups }},
+	{"type", func(k kubiya.Knowledge) interface{} { return k.Type }},
+	{"source", func(k kubiya.Knowledge) interface{} { return k.Source }},
+	{"owner", func(k kubiya.Knowledge) interface{} { return k.Owner }},
+	{"managed-by", func(k kubiya.Knowledge) interface{} { return k.ManagedBy }},
+	{"created", func(k kubiya.Knowledge) interface{} { return k.CreatedAt }},
+	{"updated", func(k kubiya.Knowledge) interface{} { return k.UpdatedAt }},
+}
+
+var defaultKnowledgeColumns = []string{"uuid", "name", "labels", "updated"}
+
+// selectKnowledgeColumns returns the named columns, in the given order
+func selectKnowledgeColumns(names []string) ([]knowledgeColumn, error) {
+	var columns []knowledgeColumn
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, c := range knowledgeColumns {
+			if c.name == name {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			valid := make([]string, len(knowledgeColumns))
+			for i, c := range knowledgeColumns {
+				valid[i] = c.name
+			}
+			return nil, fmt.Errorf("unknown column %q (valid: %s)", name, strings.Join(valid, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// eachKnowledgePage calls fn with the pages of knowledge items matching
+// opts, from opts.Page on, until there are no more items or limit items
+// were passed when limit > 0. It returns whether items were left out
+// because of the limit.
+func eachKnowledgePage(ctx context.Context, client knowledgeLister, opts kubiya.KnowledgeListOptions, limit int, fn func(page *kubiya.KnowledgePage) error) (bool, error) {
+	seen := 0
+	for {
+		page, err := client.ListKnowledgePage(ctx, opts)
+		if err != nil {
+			return false, fmt.Errorf("failed to list knowledge items (page %d): %w", opts.Page, err)
+		}
+		truncated := false
+		if limit > 0 && seen+len(page.Items) >= limit {
+			truncated = seen+len(page.Items) > limit || page.More
+			page.Items = page.Items[:limit-seen]
+			page.More = false
+		}
+		seen += len(page.Items)
+		if err := fn(page); err != nil {
+			return false, err
+		}
+		if !page.More {
+			return truncated, nil
+		}
+		opts.Page++
+	}
+}
+
+// knowledgeRecord is an item reduced to columns, for --stream
+func knowledgeRecord(k kubiya.Knowledge, columns []knowledgeColumn) map[string]interface{} {
+	record := make(map[string]interface{}, len(columns))
+	for _, c := range columns {
+		record[c.name] = c.value(k)
+	}
+	return record
+}
+
+func knowledgeCell(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case []string:
+		s = strings.Join(v, ",")
+	case time.Time:
+		if !v.IsZero() {
+			s = v.Local().Format("2006-01-02 15:04")
+		}
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "-"
+	}
+	return truncateString(s, maxKnowledgeCellWidth)
+}
+
+func newKnowledgeListCommand(cfg *config.Config) *cobra.Command {
+	var (
+		page         int
+		pageSize     int
+		limit        int
+		labels       []string
+		columnNames  []string
+		stream       bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "📋 List knowledge items",
+		Long: `List the knowledge items of the organization, a page at a time.
+
+Pages of --page-size items are fetched from the API and printed as they
+arrive, so that large knowledge bases do not have to fit in memory. With
+--stream every item is written as a line of JSON (NDJSON), for jq and other
+tools. JSON and YAML output is only written once all pages are fetched.
+
+Items can be filtered by --label; an item must have all the labels given.`,
+		Example: `  # List knowledge items
+  kubiya knowledge list
+
+  # Runbooks of the payments team, with their descriptions
+  kubiya knowledge list --label runbook --label payments --columns name,description,updated
+
+  # Stream every item as NDJSON
+  kubiya knowledge list --stream | jq -r 'select(.owner == "sre") | .uuid'
+
+  # The first 500 items, as of the third page of 100
+  kubiya knowledge list --page 3 --limit 500`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch outputFormat {
+			case "text", "json", "yaml":
+			default:
+				return fmt.Errorf("invalid output format %q (valid: text, json, yaml)", outputFormat)
+			}
+			if stream && cmd.Flags().Changed("output") {
+				return fmt.Errorf("--stream writes NDJSON and cannot be combined with --output")
+			}
+			if pageSize < 1 {
+				return fmt.Errorf("--page-size must be at least 1")
+			}
+			if page < 1 {
+				return fmt.Errorf("--page must be at least 1")
+			}
+			if cmd.Flags().Changed("columns") && outputFormat != "text" {
+				return fmt.Errorf("--columns applies to text output and --stream")
+			}
+			names := columnNames
+			if len(names) == 0 {
+				names = defaultKnowledgeColumns
+			}
+			columns, err := selectKnowledgeColumns(names)
+			if err != nil {
+				return err
+			}
+
+			client := kubiya.NewClient(cfg)
+			opts := kubiya.KnowledgeListOptions{Page: page, PageSize: pageSize, Labels: labels}
+			switch {
+			case stream:
+				if len(columnNames) == 0 {
+					columns = nil
+				}
+				_, err := streamKnowledge(cmd.Context(), client, opts, limit, columns, os.Stdout)
+				return err
+			case outputFormat == "text":
+				return printKnowledgeTable(cmd.Context(), client, opts, limit, columns, os.Stdout)
+			}
+
+			var items []kubiya.Knowledge
+			if _, err := eachKnowledgePage(cmd.Context(), client, opts, limit, func(p *kubiya.KnowledgePage) error {
+				items = append(items, p.Items...)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if items == nil {
+				items = []kubiya.Knowledge{}
+			}
+			if outputFormat == "yaml" {
+				return yaml.NewEncoder(os.Stdout).Encode(items)
+			}
+			return printJSONList(items)
+		},
+	}
+
+	cmd.Flags().IntVar(&page, "page", 1, "Page to start from")
+	cmd.Flags().IntVar(&pageSize, "page-size", defaultKnowledgePageSize, "Number of items fetched per request")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 0, "Maximum number of items to list (0 for all)")
+	cmd.Flags().StringSliceVar(&labels, "label", nil, "Only list items having this label (can be specified multiple times)")
+	cmd.Flags().StringSliceVar(&columnNames, "columns", nil, "Columns to show, e.g. uuid,name,labels,updated (also selects the fields of --stream)")
+	cmd.Flags().BoolVar(&stream, "stream", false, "Write every item as a line of JSON as pages arrive (NDJSON)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json|yaml)")
+
+	return cmd
+}
+
+// streamKnowledge writes the items as NDJSON, reduced to columns unless
+// columns is empty, and returns how many were written
+func streamKnowledge(ctx context.Context, client knowledgeLister, opts kubiya.KnowledgeListOptions, limit int, columns []knowledgeColumn, w io.Writer) (int, error) {
+	written := 0
+	_, err := eachKnowledgePage(ctx, client, opts, limit, func(p *kubiya.KnowledgePage) error {
+		for _, item := range p.Items {
+			var record interface{} = item
+			if len(columns) > 0 {
+				record = knowledgeRecord(item, columns)
+			}
+			if err := output.EncodeJSONLine(w, record); err != nil {
+				return fmt.Errorf("failed to write item: %w", err)
+			}
+			written++
+		}
+		return nil
+	})
+	return written, err
+}
+
+// printKnowledgeTable prints the items as a table, a page at a time
+func printKnowledgeTable(ctx context.Context, client knowledgeLister, opts kubiya.KnowledgeListOptions, limit int, columns []knowledgeColumn, w io.Writer) error {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = strings.ToUpper(c.name)
+	}
+
+	shown, total := 0, 0
+	truncated, err := eachKnowledgePage(ctx, client, opts, limit, func(p *kubiya.KnowledgePage) error {
+		total = p.Total
+		if len(p.Items) == 0 {
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if shown == 0 {
+			fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		}
+		for _, item := range p.Items {
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = knowledgeCell(c.value(item))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		shown += len(p.Items)
+		return tw.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case shown == 0:
+		fmt.Fprintln(w, "No knowledge items found")
+	case truncated:
+		fmt.Fprintf(w, "\n%s\n", style.DimStyle.Render(fmt.Sprintf("Showing %d items; raise --limit to see more", shown)))
+	case total > 0:
+		fmt.Fprintf(w, "\n%s\n", style.DimStyle.Render(fmt.Sprintf("%d of %d items", shown, total)))
+	default:
+		fmt.Fprintf(w, "\n%s\n", style.DimStyle.Render(fmt.Sprintf("%d items", shown)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeKnowledgePages serves items in pages, like the API without a total
+type fakeKnowledgePages struct {
+	items    []kubiya.Knowledge
+	requests []int
+}
+
+func (f *fakeKnowledgePages) ListKnowledgePage(ctx context.Context, opts kubiya.KnowledgeListOptions) (*kubiya.KnowledgePage, error) {
+	f.requests = append(f.requests, opts.Page)
+	start := min((opts.Page-1)*opts.PageSize, len(f.items))
+	end := min(start+opts.PageSize, len(f.items))
+	return &kubiya.KnowledgePage{Items: append([]kubiya.Knowledge(nil), f.items[start:end]...), More: end < len(f.items)}, nil
+}
+
+func knowledgeItems(n int) []kubiya.Knowledge {
+	items := make([]kubiya.Knowledge, n)
+	for i := range items {
+		items[i] = kubiya.Knowledge{
+			UUID:      fmt.Sprintf("k-%d", i+1),
+			Name:      fmt.Sprintf("item %d", i+1),
+			Labels:    []string{"runbook", "sre"},
+			Content:   "content",
+			UpdatedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		}
+	}
+	return items
+}
+
+func TestEachKnowledgePage(t *testing.T) {
+	client := &fakeKnowledgePages{items: knowledgeItems(7)}
+	count := func(page, limit int) (int, bool) {
+		client.requests = nil
+		n := 0
+		truncated, err := eachKnowledgePage(context.Background(), client, kubiya.KnowledgeListOptions{Page: page, PageSize: 3}, limit, func(p *kubiya.KnowledgePage) error {
+			n += len(p.Items)
+			return nil
+		})
+		require.NoError(t, err)
+		return n, truncated
+	}
+
+	n, truncated := count(1, 0)
+	assert.Equal(t, 7, n)
+	assert.False(t, truncated)
+	assert.Equal(t, []int{1, 2, 3}, client.requests)
+
+	n, truncated = count(1, 4)
+	assert.Equal(t, 4, n)
+	assert.True(t, truncated)
+	assert.Equal(t, []int{1, 2}, client.requests, "no page is fetched past the limit")
+
+	n, truncated = count(2, 0)
+	assert.Equal(t, 4, n)
+	assert.False(t, truncated)
+
+	// A limit reached exactly on the last item leaves nothing out
+	n, truncated = count(1, 7)
+	assert.Equal(t, 7, n)
+	assert.False(t, truncated)
+}
+
+func TestStreamKnowledge(t *testing.T) {
+	client := &fakeKnowledgePages{items: knowledgeItems(3)}
+	opts := kubiya.KnowledgeListOptions{Page: 1, PageSize: 2}
+
+	columns, err := selectKnowledgeColumns([]string{"uuid", "Labels"})
+	require.NoError(t, err)
+	var out bytes.Buffer
+	n, err := streamKnowledge(context.Background(), client, opts, 0, columns, &out)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, `{"labels":["runbook","sre"],"uuid":"k-1"}
+{"labels":["runbook","sre"],"uuid":"k-2"}
+{"labels":["runbook","sre"],"uuid":"k-3"}
+`, out.String())
+
+	// Whole items without columns
+	out.Reset()
+	_, err = streamKnowledge(context.Background(), client, opts, 1, nil, &out)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	assert.Contains(t, out.String(), `"content":"content"`)
+
+	_, err = selectKnowledgeColumns([]string{"name", "size"})
+	assert.ErrorContains(t, err, `unknown column "size" (valid: uuid, name,`)
+}
+
+func TestPrintKnowledgeTable(t *testing.T) {
+	client := &fakeKnowledgePages{items: knowledgeItems(3)}
+	columns, err := selectKnowledgeColumns(defaultKnowledgeColumns)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, printKnowledgeTable(context.Background(), client, kubiya.KnowledgeListOptions{Page: 1, PageSize: 2}, 2, columns, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, []string{"UUID", "NAME", "LABELS", "UPDATED"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"k-1", "item", "1", "runbook,sre"}, strings.Fields(lines[1])[:4])
+	assert.Contains(t, lines[4], "Showing 2 items; raise --limit to see more")
+
+	out.Reset()
+	empty := &fakeKnowledgePages{}
+	require.NoError(t, printKnowledgeTable(context.Background(), empty, kubiya.KnowledgeListOptions{Page: 1, PageSize: 2}, 0, columns, &out))
+	assert.Equal(t, "No knowledge items found\n", out.String())
+}
@@ -17,11 +17,17 @@ func newKnowledgeCommand(cfg *config.Config) *cobra.Command {
 		Use:     "knowledge",
 		Aliases: []string{"kb"},
 		Short:   "🔍 Query the central knowledge base",
-		Long:    `Query the central knowledge base for contextual information with intelligent search capabilities.`,
+		Long:    `List and query the central knowledge base for contextual information with intelligent search capabilities.`,
 	}
 
 	cmd.AddCommand(
+		newKnowledgeListCommand(cfg),
 		newQueryKnowledgeCommand(cfg),
+		newKnowledgeCopyCommand(cfg),
+		newKnowledgeVersionsCommand(cfg),
+		newKnowledgeDiffCommand(cfg),
+		newKnowledgeRollbackCommand(cfg),
+		newKnowledgeImportCommand(cfg),
 	)
 
 	return cmd
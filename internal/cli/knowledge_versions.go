@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+func newKnowledgeVersionsCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "versions [uuid]",
+		Aliases: []string{"history"},
+		Short:   "📜 List the version history of a knowledge item",
+		Example: "  kubiya knowledge versions abc-123\n  kubiya knowledge versions abc-123 --output json",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			versions, err := client.ListKnowledgeVersions(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to list versions: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, versions)
+			}
+
+			if len(versions) == 0 {
+				fmt.Fprintln(out, "No versions found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tCREATED\tBY\tNAME\tHASH")
+			for i, v := range versions {
+				version := fmt.Sprintf("%d", v.Version)
+				if i == 0 {
+					version += " (current)"
+				}
+				hash := v.ContentHash
+				if len(hash) > 12 {
+					hash = hash[:12]
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					version,
+					v.CreatedAt.Format("2006-01-02 15:04"),
+					v.CreatedBy,
+					v.Name,
+					hash,
+				)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+func newKnowledgeDiffCommand(cfg *config.Config) *cobra.Command {
+	var fromVersion, toVersion int
+
+	cmd := &cobra.Command{
+		Use:   "diff [uuid]",
+		Short: "🔀 Show the differences between two versions of a knowledge item",
+		Long: `Show a unified diff between two versions of a knowledge item.
+
+Without --to the version is compared with the current content. Without --from
+the version preceding --to is used.`,
+		Example: `  # What changed in the latest edit
+  kubiya knowledge diff abc-123
+
+  # Compare version 3 with the current content
+  kubiya knowledge diff abc-123 --from 3
+
+  # Compare two specific versions
+  kubiya knowledge diff abc-123 --from 2 --to 4`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			uuid := args[0]
+
+			if fromVersion == 0 || toVersion == 0 {
+				versions, err := client.ListKnowledgeVersions(cmd.Context(), uuid)
+				if err != nil {
+					return fmt.Errorf("failed to list versions: %w", err)
+				}
+				if toVersion == 0 {
+					if len(versions) == 0 {
+						return fmt.Errorf("knowledge item %s has no versions", uuid)
+					}
+					toVersion = versions[0].Version
+				}
+				if fromVersion == 0 {
+					fromVersion = previousKnowledgeVersion(versions, toVersion)
+					if fromVersion == 0 {
+						return fmt.Errorf("version %d has no previous version to compare with", toVersion)
+					}
+				}
+			}
+
+			from, err := client.GetKnowledgeVersion(cmd.Context(), uuid, fromVersion)
+			if err != nil {
+				return err
+			}
+			to, err := client.GetKnowledgeVersion(cmd.Context(), uuid, toVersion)
+			if err != nil {
+				return err
+			}
+
+			diff, err := unifiedDiff(
+				knowledgeVersionText(from), knowledgeVersionText(to),
+				fmt.Sprintf("%s@v%d", uuid, from.Version), fmt.Sprintf("%s@v%d", uuid, to.Version))
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if diff == "" {
+				fmt.Fprintf(out, "Versions %d and %d are identical\n", from.Version, to.Version)
+				return nil
+			}
+			printColoredDiff(out, diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&fromVersion, "from", 0, "Version to compare from (default: the version before --to)")
+	cmd.Flags().IntVar(&toVersion, "to", 0, "Version to compare to (default: current version)")
+	return cmd
+}
+
+func newKnowledgeRollbackCommand(cfg *config.Config) *cobra.Command {
+	var (
+		toVersion int
+		yes       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback [uuid]",
+		Short: "⏪ Restore a previous version of a knowledge item",
+		Long: `Restore the name, description, labels and content of a previous version.
+
+The rollback is recorded as a new version, so it can itself be rolled back.`,
+		Example: "  kubiya knowledge rollback abc-123 --to-version 3",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			uuid := args[0]
+
+			current, err := client.GetKnowledge(cmd.Context(), uuid)
+			if err != nil {
+				return fmt.Errorf("failed to get knowledge item: %w", err)
+			}
+			target, err := client.GetKnowledgeVersion(cmd.Context(), uuid, toVersion)
+			if err != nil {
+				return err
+			}
+
+			restored := *current
+			restored.Name = target.Name
+			restored.Description = target.Description
+			restored.Content = target.Content
+			if target.Labels != nil {
+				restored.Labels = target.Labels
+			}
+
+			diff, err := unifiedDiff(
+				knowledgeText(current.Name, current.Description, current.Labels, current.Content),
+				knowledgeVersionText(target),
+				uuid+"@current", fmt.Sprintf("%s@v%d", uuid, target.Version))
+			if err != nil {
+				return err
+			}
+			if diff == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Knowledge item already matches version %d\n", target.Version)
+				return nil
+			}
+
+			if !yes {
+				printColoredDiff(cmd.OutOrStdout(), diff)
+				fmt.Fprintf(cmd.OutOrStdout(), "\nRoll back %s to version %d? [y/N] ", current.Name, target.Version)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+					return fmt.Errorf("rollback cancelled")
+				}
+			}
+
+			if _, err := client.UpdateKnowledge(cmd.Context(), uuid, restored); err != nil {
+				return fmt.Errorf("failed to roll back knowledge item: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Knowledge item %s rolled back to version %d\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(restored.Name),
+				target.Version)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&toVersion, "to-version", 0, "Version to restore")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	_ = cmd.MarkFlagRequired("to-version")
+	return cmd
+}
+
+// previousKnowledgeVersion returns the newest version older than version, or 0
+func previousKnowledgeVersion(versions []kubiya.KnowledgeVersion, version int) int {
+	prev := 0
+	for _, v := range versions {
+		if v.Version < version && v.Version > prev {
+			prev = v.Version
+		}
+	}
+	return prev
+}
+
+func knowledgeVersionText(v *kubiya.KnowledgeVersion) string {
+	return knowledgeText(v.Name, v.Description, v.Labels, v.Content)
+}
+
+// knowledgeText renders the diffable fields of a knowledge item so metadata
+// changes show up in diffs next to content changes
+func knowledgeText(name, description string, labels []string, content string) string {
+	return fmt.Sprintf("name: %s\ndescription: %s\nlabels: %s\n\n%s",
+		name, description, strings.Join(labels, ", "), content)
+}
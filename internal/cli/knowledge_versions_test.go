@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestPreviousKnowledgeVersion(t *testing.T) {
+	versions := []kubiya.KnowledgeVersion{{Version: 5}, {Version: 3}, {Version: 2}}
+
+	tests := []struct {
+		version int
+		want    int
+	}{
+		{5, 3},
+		{3, 2},
+		{4, 3},
+		{2, 0},
+	}
+	for _, tt := range tests {
+		if got := previousKnowledgeVersion(versions, tt.version); got != tt.want {
+			t.Errorf("previousKnowledgeVersion(%d) = %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestKnowledgeVersionDiff(t *testing.T) {
+	from := &kubiya.KnowledgeVersion{Version: 1, Name: "runbook", Content: "step one\nstep two\n"}
+	to := &kubiya.KnowledgeVersion{Version: 2, Name: "runbook", Labels: []string{"ops"}, Content: "step one\nstep 2\n"}
+
+	diff, err := unifiedDiff(knowledgeVersionText(from), knowledgeVersionText(to), "kb@v1", "kb@v2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"--- kb@v1", "+++ kb@v2", "-labels: \n", "+labels: ops", "-step two", "+step 2"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+
+	same, err := unifiedDiff(knowledgeVersionText(from), knowledgeVersionText(from), "a", "b")
+	if err != nil || same != "" {
+		t.Errorf("expected empty diff for identical versions, got %q (%v)", same, err)
+	}
+}
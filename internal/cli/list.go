@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"text/tabwriter"
@@ -27,7 +26,7 @@ func newListCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(agents)
+				return printJSONList(agents)
 			case "text":
 				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 				fmt.Fprintln(w, "👥 AGENTS")
@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 const (
@@ -128,7 +129,7 @@ func generateCodeChallenge(verifier string) string {
 
 // runAuthCodeFlow executes the Auth0 Authorization Code Flow with PKCE.
 func runAuthCodeFlow(cfg *config.Config, browser bool) error {
-	httpClient := &http.Client{Timeout: 10 * time.Second}
+	httpClient := httpclient.New(10 * time.Second)
 
 	// Step 1: Generate PKCE code verifier and challenge
 	codeVerifier, err := generateCodeVerifier()
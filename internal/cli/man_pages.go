@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/style"
+	"github.com/kubiyabot/cli/internal/version"
+)
+
+func newDocsManCommand(cfg *config.Config) *cobra.Command {
+	var (
+		dir     string
+		install bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "man",
+		Short: "📖 Generate man pages for all commands",
+		Long: `Generate a man page for every kubiya command.
+
+With --install the pages go to the system man directory when writable,
+otherwise to ~/.local/share/man, so that "man kubiya" and
+"man kubiya-agent-list" work.`,
+		Example: `  # Install the man pages
+  kubiya docs man --install
+
+  # Write the man pages to a directory
+  kubiya docs man --dir ./man`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if install {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("failed to find home directory: %w", err)
+				}
+				dir, pages, err := installManPages(cmd.Root(), home)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s Installed %d man pages to %s\n", style.SuccessStyle.Render("✅"), pages, dir)
+				if hint := manPathHint(dir, home); hint != "" {
+					fmt.Printf("\n%s\n", style.DimStyle.Render(hint))
+				}
+				return nil
+			}
+
+			pages, err := writeManPages(cmd.Root(), dir)
+			if err != nil {
+				return fmt.Errorf("failed to write man pages: %w", err)
+			}
+			fmt.Printf("%s Wrote %d man pages to %s\n", style.SuccessStyle.Render("✅"), pages, dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "man", "Directory to write the man pages to")
+	cmd.Flags().BoolVar(&install, "install", false, "Install the man pages where man finds them")
+	cmd.MarkFlagsMutuallyExclusive("dir", "install")
+
+	return cmd
+}
+
+// manDirs returns where man pages are installed to, in order of preference
+func manDirs(home string) []string {
+	return []string{
+		"/usr/local/share/man/man1",
+		filepath.Join(xdgDir("XDG_DATA_HOME", filepath.Join(home, ".local", "share")), "man", "man1"),
+	}
+}
+
+// installManPages writes the man pages to the first man directory that can
+// be written to
+func installManPages(root *cobra.Command, home string) (string, int, error) {
+	var errs []error
+	for _, dir := range manDirs(home) {
+		pages, err := writeManPages(root, dir)
+		if err == nil {
+			return dir, pages, nil
+		}
+		if !errors.Is(err, os.ErrPermission) {
+			return "", 0, fmt.Errorf("failed to install man pages: %w", err)
+		}
+		errs = append(errs, err)
+	}
+	return "", 0, fmt.Errorf("failed to install man pages: %w", errors.Join(errs...))
+}
+
+// manPathHint tells how to make man find pages installed in the home
+// directory
+func manPathHint(dir, home string) string {
+	if !strings.HasPrefix(dir, home) {
+		return ""
+	}
+	return fmt.Sprintf("If \"man kubiya\" does not find them, add to your shell profile:\n  export MANPATH=\"%s:$MANPATH\"", filepath.Dir(dir))
+}
+
+// writeManPages writes a page for root and every command below it to dir
+func writeManPages(root *cobra.Command, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	pages := 0
+	var walk func(cmd *cobra.Command) error
+	walk = func(cmd *cobra.Command) error {
+		for _, c := range cmd.Commands() {
+			if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+				continue
+			}
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		path := filepath.Join(dir, manPageName(cmd)+".1")
+		if err := os.WriteFile(path, genManPage(cmd, manDate()), 0644); err != nil {
+			return err
+		}
+		pages++
+		return nil
+	}
+	return pages, walk(root)
+}
+
+// manPageName is the name of the page of cmd, e.g. kubiya-agent-list
+func manPageName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+// manDate is the date of the pages, SOURCE_DATE_EPOCH when set so that
+// packaged pages are reproducible
+func manDate() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Now()
+}
+
+// genManPage renders the man page of cmd in roff
+func genManPage(cmd *cobra.Command, date time.Time) []byte {
+	cmd.InitDefaultHelpFlag()
+	var b bytes.Buffer
+	name := manPageName(cmd)
+
+	fmt.Fprintf(&b, ".TH %q \"1\" %q \"Kubiya CLI %s\" \"Kubiya Manual\"\n",
+		strings.ToUpper(name), date.Format("Jan 2006"), version.Version)
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", name, roffEscape(strings.TrimSpace(cmd.Short)))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, ".B %s\n", roffEscape(cmd.UseLine()))
+
+	b.WriteString(".SH DESCRIPTION\n")
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	writeRoffText(&b, description)
+
+	writeManFlags(&b, "OPTIONS", cmd.NonInheritedFlags())
+	writeManFlags(&b, "OPTIONS INHERITED FROM PARENT COMMANDS", cmd.InheritedFlags())
+
+	if cmd.Example != "" {
+		b.WriteString(".SH EXAMPLE\n.nf\n")
+		writeRoffLines(&b, cmd.Example)
+		b.WriteString(".fi\n")
+	}
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, manPageName(cmd.Parent()))
+	}
+	for _, c := range cmd.Commands() {
+		if c.IsAvailableCommand() && !c.IsAdditionalHelpTopicCommand() {
+			related = append(related, manPageName(c))
+		}
+	}
+	if len(related) > 0 {
+		sort.Strings(related)
+		b.WriteString(".SH SEE ALSO\n")
+		for i, page := range related {
+			sep := ","
+			if i == len(related)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&b, ".BR %s (1)%s\n", roffEscape(page), sep)
+		}
+	}
+	return b.Bytes()
+}
+
+func writeManFlags(b *bytes.Buffer, section string, flags *pflag.FlagSet) {
+	if !flags.HasAvailableFlags() {
+		return
+	}
+	fmt.Fprintf(b, ".SH %s\n", section)
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" {
+			return
+		}
+		varname, usage := pflag.UnquoteUsage(f)
+		var names string
+		if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+			names = fmt.Sprintf("-%s, --%s", f.Shorthand, f.Name)
+		} else {
+			names = "--" + f.Name
+		}
+		if varname != "" {
+			names += " " + varname
+		}
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "[]" && f.Value.Type() != "bool" {
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(b, ".TP\n.B %s\n", roffEscape(names))
+		writeRoffText(b, usage)
+	})
+}
+
+// writeRoffText writes paragraphs of text, keeping blank lines as breaks
+func writeRoffText(b *bytes.Buffer, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString(".PP\n")
+			continue
+		}
+		fmt.Fprintf(b, "%s\n.br\n", roffEscape(line))
+	}
+}
+
+// writeRoffLines writes text line by line, for use in no-fill mode
+func writeRoffLines(b *bytes.Buffer, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Fprintf(b, "%s\n", roffEscape(line))
+	}
+}
+
+// roffEscape escapes text so that roff prints it as is
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManTestTree() *cobra.Command {
+	root := &cobra.Command{Use: "kubiya", Short: "Kubiya CLI"}
+	root.PersistentFlags().Bool("mock", false, "Use the mock API")
+
+	agent := &cobra.Command{Use: "agent", Short: "Manage agents"}
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "List agents",
+		Long:    "List the agents of the organization.\n\n.Dotted lines are escaped.",
+		Example: "  kubiya agent list --output json",
+		Run:     func(cmd *cobra.Command, args []string) {},
+	}
+	list.Flags().StringP("output", "o", "text", "Output format")
+	hidden := &cobra.Command{Use: "internal", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+	agent.AddCommand(list, hidden)
+	root.AddCommand(agent)
+	return root
+}
+
+func TestGenManPage(t *testing.T) {
+	root := newManTestTree()
+	list, _, err := root.Find([]string{"agent", "list"})
+	require.NoError(t, err)
+
+	page := string(genManPage(list, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Contains(t, page, `.TH "KUBIYA-AGENT-LIST" "1" "Oct 2026"`)
+	assert.Contains(t, page, "kubiya-agent-list \\- List agents\n")
+	assert.Contains(t, page, ".B kubiya agent list [flags]\n")
+	assert.Contains(t, page, ".B \\-o, \\-\\-output string\nOutput format (default text)\n")
+	assert.Contains(t, page, ".SH OPTIONS INHERITED FROM PARENT COMMANDS\n.TP\n.B \\-\\-mock\n")
+	assert.Contains(t, page, ".PP\n\\&.Dotted lines are escaped.\n")
+	assert.Contains(t, page, ".nf\n  kubiya agent list \\-\\-output json\n.fi\n")
+	assert.Contains(t, page, ".SH SEE ALSO\n.BR kubiya\\-agent (1)\n")
+}
+
+func TestWriteManPages(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "man1")
+	pages, err := writeManPages(newManTestTree(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pages)
+
+	for _, name := range []string{"kubiya.1", "kubiya-agent.1", "kubiya-agent-list.1"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+	_, err = os.Stat(filepath.Join(dir, "kubiya-agent-internal.1"))
+	assert.True(t, os.IsNotExist(err), "hidden commands get no page")
+}
@@ -33,5 +33,8 @@ configuration files of these local tools.`,
 	// Add subcommands
 	cmd.AddCommand(newMcpSetupCommand(cfg, fs))
 	cmd.AddCommand(NewMCPServeCmd())
+	cmd.AddCommand(newMcpCallCommand())
+	cmd.AddCommand(newMcpInspectCommand())
+	cmd.AddCommand(newMcpWhitelistCommand(cfg, fs))
 	return cmd
 }
@@ -0,0 +1,394 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/version"
+)
+
+// mcpClientOptions holds the connection flags shared by the MCP client commands
+type mcpClientOptions struct {
+	server        string
+	transportType string
+	env           []string
+	headers       []string
+	timeout       time.Duration
+	showStderr    bool
+	outputFormat  string
+}
+
+func (o *mcpClientOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.server, "server", "s", "", "MCP server to connect to: a command line (stdio) or an http(s) URL")
+	cmd.Flags().StringVar(&o.transportType, "transport", "", "Transport to use (stdio|http|sse), detected from --server when empty")
+	cmd.Flags().StringArrayVarP(&o.env, "env", "e", nil, "Environment variables for stdio servers (KEY=VALUE)")
+	cmd.Flags().StringArrayVar(&o.headers, "header", nil, "HTTP headers for http/sse servers (Key: Value)")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 30*time.Second, "Timeout for the whole operation")
+	cmd.Flags().BoolVar(&o.showStderr, "show-stderr", false, "Forward the stderr output of stdio servers")
+	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("server")
+}
+
+// splitShellWords splits a command line into words like a POSIX shell:
+// single quotes keep everything literally, double quotes keep spaces and
+// honor backslash escapes, and a backslash outside quotes escapes the next
+// character. Expansions are not performed.
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune // the open quote, 0 outside quotes
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			// In double quotes, a backslash only escapes these characters
+			if quote == '"' && !strings.ContainsRune("\\\"$`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", s)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// detectTransport returns the transport to use for the configured server
+func (o *mcpClientOptions) detectTransport() (string, error) {
+	switch o.transportType {
+	case "stdio", "http", "sse":
+		return o.transportType, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid transport %q (must be stdio, http or sse)", o.transportType)
+	}
+
+	if strings.HasPrefix(o.server, "http://") || strings.HasPrefix(o.server, "https://") {
+		if strings.HasSuffix(strings.TrimRight(o.server, "/"), "/sse") {
+			return "sse", nil
+		}
+		return "http", nil
+	}
+	return "stdio", nil
+}
+
+// connect starts the transport and performs the MCP initialize handshake
+func (o *mcpClientOptions) connect(ctx context.Context) (*mcpclient.Client, *mcp.InitializeResult, error) {
+	transportType, err := o.detectTransport()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := make(map[string]string)
+	for _, h := range o.headers {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid header %q (expected 'Key: Value')", h)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	var client *mcpclient.Client
+	switch transportType {
+	case "stdio":
+		parts, err := splitShellWords(o.server)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid server command: %w", err)
+		}
+		if len(parts) == 0 {
+			return nil, nil, fmt.Errorf("server command is empty")
+		}
+		env := append(os.Environ(), o.env...)
+		stdio := transport.NewStdio(parts[0], env, parts[1:]...)
+		client = mcpclient.NewClient(stdio)
+		if err := client.Start(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to start MCP server %q: %w", o.server, err)
+		}
+		// Always drain stderr so a chatty server never blocks on a full pipe
+		stderr := io.Discard
+		if o.showStderr {
+			stderr = os.Stderr
+		}
+		go func() { _, _ = io.Copy(stderr, stdio.Stderr()) }()
+	case "http":
+		client, err = mcpclient.NewStreamableHttpClient(o.server, transport.WithHTTPHeaders(headers))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := client.Start(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to MCP server %q: %w", o.server, err)
+		}
+	case "sse":
+		client, err = mcpclient.NewSSEMCPClient(o.server, mcpclient.WithHeaders(headers))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := client.Start(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to MCP server %q: %w", o.server, err)
+		}
+	}
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{
+		Name:    "kubiya-cli",
+		Version: version.Version,
+	}
+
+	initResult, err := client.Initialize(ctx, initReq)
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("failed to initialize MCP session: %w", err)
+	}
+
+	return client, initResult, nil
+}
+
+func newMcpCallCommand() *cobra.Command {
+	var (
+		opts     mcpClientOptions
+		toolName string
+		argsJSON string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "call",
+		Short: "Call a tool on any MCP server",
+		Long: `Connect to an MCP server as a client, call a single tool and print the result.
+
+The server can be a local command speaking MCP over stdio, or an HTTP endpoint
+(streamable HTTP, or SSE when the URL ends with /sse). The command is split
+into words like a shell does, so arguments with spaces can be quoted.`,
+		Example: `  # Call a tool on the Kubiya MCP server itself
+  kubiya mcp call --server "kubiya mcp serve" --tool list_runners --args '{}'
+
+  # Call a tool on a remote streamable HTTP server
+  kubiya mcp call --server https://mcp.example.com/mcp --tool search --args '{"query":"pods"}'
+
+  # Print the raw result as JSON
+  kubiya mcp call --server ./my-server --tool echo --args '{"text":"hi"}' -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var toolArgs map[string]any
+			if argsJSON != "" {
+				if err := json.Unmarshal([]byte(argsJSON), &toolArgs); err != nil {
+					return fmt.Errorf("invalid --args JSON: %w", err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			client, _, err := opts.connect(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			req := mcp.CallToolRequest{}
+			req.Params.Name = toolName
+			req.Params.Arguments = toolArgs
+
+			result, err := client.CallTool(ctx, req)
+			if err != nil {
+				return fmt.Errorf("failed to call tool %q: %w", toolName, err)
+			}
+
+			if opts.outputFormat == "json" {
+				if err := output.EncodeJSON(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			} else {
+				printMcpContent(cmd.OutOrStdout(), result.Content)
+			}
+
+			if result.IsError {
+				return fmt.Errorf("tool %q returned an error", toolName)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringVarP(&toolName, "tool", "t", "", "Name of the tool to call")
+	cmd.Flags().StringVarP(&argsJSON, "args", "a", "", "Tool arguments as a JSON object")
+	_ = cmd.MarkFlagRequired("tool")
+
+	return cmd
+}
+
+func newMcpInspectCommand() *cobra.Command {
+	var opts mcpClientOptions
+
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "List the tools, resources and prompts of any MCP server",
+		Long: `Connect to an MCP server as a client and list everything it exposes.
+Handy for verifying whitelisted tool configurations before wiring them into an IDE.`,
+		Example: `  # Inspect the Kubiya MCP server with a custom config
+  kubiya mcp inspect --server "kubiya mcp serve --config ./mcp.json"
+
+  # Inspect a remote server as JSON
+  kubiya mcp inspect --server https://mcp.example.com/mcp -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			client, initResult, err := opts.connect(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			inspection := struct {
+				Server    mcp.Implementation `json:"server"`
+				Protocol  string             `json:"protocol_version"`
+				Tools     []mcp.Tool         `json:"tools"`
+				Resources []mcp.Resource     `json:"resources"`
+				Prompts   []mcp.Prompt       `json:"prompts"`
+			}{
+				Server:   initResult.ServerInfo,
+				Protocol: initResult.ProtocolVersion,
+			}
+
+			caps := initResult.Capabilities
+			if caps.Tools != nil {
+				tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+				if err != nil {
+					return fmt.Errorf("failed to list tools: %w", err)
+				}
+				inspection.Tools = tools.Tools
+			}
+			if caps.Resources != nil {
+				resources, err := client.ListResources(ctx, mcp.ListResourcesRequest{})
+				if err != nil {
+					return fmt.Errorf("failed to list resources: %w", err)
+				}
+				inspection.Resources = resources.Resources
+			}
+			if caps.Prompts != nil {
+				prompts, err := client.ListPrompts(ctx, mcp.ListPromptsRequest{})
+				if err != nil {
+					return fmt.Errorf("failed to list prompts: %w", err)
+				}
+				inspection.Prompts = prompts.Prompts
+			}
+
+			out := cmd.OutOrStdout()
+			if opts.outputFormat == "json" {
+				return output.EncodeJSON(out, inspection)
+			}
+
+			fmt.Fprintf(out, "🔌 %s %s (protocol %s)\n", inspection.Server.Name, inspection.Server.Version, inspection.Protocol)
+
+			fmt.Fprintf(out, "\n🛠️  Tools (%d)\n", len(inspection.Tools))
+			for _, t := range inspection.Tools {
+				fmt.Fprintf(out, "  • %s", t.Name)
+				if t.Description != "" {
+					fmt.Fprintf(out, " - %s", firstLine(t.Description))
+				}
+				fmt.Fprintln(out)
+				names := make([]string, 0, len(t.InputSchema.Properties))
+				for name := range t.InputSchema.Properties {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					required := ""
+					for _, r := range t.InputSchema.Required {
+						if r == name {
+							required = " (required)"
+							break
+						}
+					}
+					fmt.Fprintf(out, "      %s%s\n", name, required)
+				}
+			}
+
+			fmt.Fprintf(out, "\n📚 Resources (%d)\n", len(inspection.Resources))
+			for _, r := range inspection.Resources {
+				fmt.Fprintf(out, "  • %s (%s)\n", r.Name, r.URI)
+			}
+
+			fmt.Fprintf(out, "\n💬 Prompts (%d)\n", len(inspection.Prompts))
+			for _, p := range inspection.Prompts {
+				fmt.Fprintf(out, "  • %s", p.Name)
+				if p.Description != "" {
+					fmt.Fprintf(out, " - %s", firstLine(p.Description))
+				}
+				fmt.Fprintln(out)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	return cmd
+}
+
+// printMcpContent prints tool result content in a human readable form
+func printMcpContent(w io.Writer, contents []mcp.Content) {
+	for _, c := range contents {
+		switch content := c.(type) {
+		case mcp.TextContent:
+			fmt.Fprintln(w, content.Text)
+		case mcp.ImageContent:
+			fmt.Fprintf(w, "[image: %s, %d bytes base64]\n", content.MIMEType, len(content.Data))
+		case mcp.AudioContent:
+			fmt.Fprintf(w, "[audio: %s, %d bytes base64]\n", content.MIMEType, len(content.Data))
+		case mcp.EmbeddedResource:
+			output.EncodeJSON(w, content.Resource)
+		default:
+			output.EncodeJSON(w, content)
+		}
+	}
+}
+
+// firstLine returns the first line of a (possibly multi-line) description
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"kubiya mcp serve", []string{"kubiya", "mcp", "serve"}},
+		{`kubiya mcp serve --config "./my config.json"`, []string{"kubiya", "mcp", "serve", "--config", "./my config.json"}},
+		{`python -c 'print("hi there")'`, []string{"python", "-c", `print("hi there")`}},
+		{`./server --name my\ server`, []string{"./server", "--name", "my server"}},
+		{`echo "a \"b\" \n"`, []string{"echo", `a "b" \n`}},
+		{`run '' x`, []string{"run", "", "x"}},
+		{"  spaced\tout  ", []string{"spaced", "out"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitShellWords(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{`node "server.js`, `python -c 'x`, `server \`} {
+		_, err := splitShellWords(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestMcpClientDetectTransport(t *testing.T) {
+	tests := []struct {
+		server, transport, want string
+	}{
+		{"kubiya mcp serve", "", "stdio"},
+		{"https://mcp.example.com/mcp", "", "http"},
+		{"https://mcp.example.com/sse/", "", "sse"},
+		{"https://mcp.example.com/events", "sse", "sse"},
+	}
+	for _, tt := range tests {
+		opts := mcpClientOptions{server: tt.server, transportType: tt.transport}
+		got, err := opts.detectTransport()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.server)
+	}
+
+	_, err := (&mcpClientOptions{server: "x", transportType: "grpc"}).detectTransport()
+	assert.Error(t, err)
+}
+
+func newTestMcpServer(t *testing.T) string {
+	t.Helper()
+	s := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("echo", mcp.WithDescription("Echoes its text"), mcp.WithString("text", mcp.Required())),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(fmt.Sprint(req.Params.Arguments["text"])), nil
+		})
+	srv := server.NewTestServer(s)
+	t.Cleanup(srv.Close)
+	return srv.URL + "/sse"
+}
+
+func TestMcpCallCommand(t *testing.T) {
+	url := newTestMcpServer(t)
+
+	var out bytes.Buffer
+	cmd := newMcpCallCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--server", url, "--tool", "echo", "--args", `{"text":"hi"}`})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	assert.Equal(t, "hi\n", out.String())
+}
+
+func TestMcpInspectCommand(t *testing.T) {
+	url := newTestMcpServer(t)
+
+	var out bytes.Buffer
+	cmd := newMcpInspectCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--server", url})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	assert.Contains(t, out.String(), "test-server 1.0.0")
+	assert.Contains(t, out.String(), "• echo - Echoes its text")
+	assert.Contains(t, out.String(), "text (required)")
+}
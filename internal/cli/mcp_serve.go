@@ -2,7 +2,10 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/spf13/afero"
@@ -12,6 +15,7 @@ import (
 	"github.com/kubiyabot/cli/internal/kubiya"
 	"github.com/kubiyabot/cli/internal/mcp"
 	sentryutil "github.com/kubiyabot/cli/internal/sentry"
+	"github.com/kubiyabot/cli/internal/style"
 )
 
 func NewMCPServeCmd() *cobra.Command {
@@ -27,6 +31,7 @@ func NewMCPServeCmd() *cobra.Command {
 		serverName                                                     string
 		serverVersion                                                  string
 		disableDynamicTools, enableVerboseLogging, enableDocumentation bool
+		validateConfig, printEffectiveConfig                           bool
 	)
 
 	cmd := &cobra.Command{
@@ -34,6 +39,10 @@ func NewMCPServeCmd() *cobra.Command {
 		Short: "Start the Kubiya MCP server",
 		Long:  `Start the Kubiya Model Context Protocol (MCP) server that exposes Kubiya tools to MCP clients.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if validateConfig {
+				return runMCPConfigValidate(cmd.OutOrStdout(), configFile, productionMode)
+			}
+
 			// Load config
 			cfg, err := config.Load()
 			if err != nil {
@@ -91,6 +100,10 @@ func NewMCPServeCmd() *cobra.Command {
 				// Set org ID from user config
 				serverConfig.OrgID = cfg.Org
 
+				if printEffectiveConfig {
+					return printJSON(serverConfig)
+				}
+
 				// Create production server
 				server, err := mcp.NewProductionServer(kubiyaClient, serverConfig)
 				if err != nil {
@@ -123,6 +136,9 @@ func NewMCPServeCmd() *cobra.Command {
 				if enableDocumentation {
 					serverConfig.EnableDocumentation = true
 				}
+				if printEffectiveConfig {
+					return printJSON(serverConfig)
+				}
 				// Create and start server
 				server := mcp.NewServer(cfg, serverConfig)
 				return server.Start()
@@ -152,6 +168,11 @@ func NewMCPServeCmd() *cobra.Command {
   # Enable kubiya documentation search
   kubiya mcp serve --enable-documentation
 
+  # Check the config file strictly, then show the result of defaults, file,
+  # environment and flags
+  kubiya mcp serve --config ~/my-mcp-config.json --config-validate
+  kubiya mcp serve --production --print-effective-config
+
   # Configure in Claude Desktop (~/Library/Application Support/Claude/claude_desktop_config.json):
   {
     "mcpServers": {
@@ -169,6 +190,8 @@ func NewMCPServeCmd() *cobra.Command {
 	// Configuration flags
 	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to MCP server configuration file")
 	cmd.Flags().BoolVar(&productionMode, "production", false, "Run in production mode with session management, middleware, and hooks")
+	cmd.Flags().BoolVar(&validateConfig, "config-validate", false, "Check the configuration file strictly, with line numbers, and exit")
+	cmd.Flags().BoolVar(&printEffectiveConfig, "print-effective-config", false, "Print the configuration merged from defaults, file, environment and flags, and exit")
 
 	// Core functionality flags
 	cmd.Flags().BoolVar(&disablePlatformAPIs, "disable-platform-apis", false, "Disable access to platform management APIs (platform APIs are enabled by default)")
@@ -193,3 +216,32 @@ func NewMCPServeCmd() *cobra.Command {
 
 	return cmd
 }
+
+// runMCPConfigValidate checks the MCP server config file and prints its
+// issues. It fails when there are errors; warnings alone pass.
+func runMCPConfigValidate(w io.Writer, configFile string, production bool) error {
+	path := mcp.ConfigPath(configFile)
+	issues, err := mcp.ValidateConfigFile(afero.NewOsFs(), path, production)
+	if errors.Is(err, os.ErrNotExist) && configFile == "" {
+		fmt.Fprintf(w, "%s No config file at %s, the defaults are used\n", style.InfoStyle.Render("ℹ️"), path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	errs := 0
+	for _, issue := range issues {
+		mark := style.WarningStyle.Render("⚠️")
+		if !issue.Warning {
+			mark = style.ErrorStyle.Render("✗")
+			errs++
+		}
+		fmt.Fprintf(w, "%s %s\n", mark, issue)
+	}
+	if errs > 0 {
+		return fmt.Errorf("%s has %d error(s)", path, errs)
+	}
+	fmt.Fprintf(w, "%s %s is valid\n", style.SuccessStyle.Render("✓"), path)
+	return nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/docload"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/mcp"
+	"github.com/kubiyabot/cli/internal/output"
+)
+
+// newMcpWhitelistCommand creates the `mcp whitelist` command group.
+func newMcpWhitelistCommand(cfg *config.Config, fs afero.Fs) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "whitelist",
+		Short: "📋 Manage whitelisted tools for the MCP server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newMcpWhitelistGenerateCommand(cfg, fs))
+	return cmd
+}
+
+func newMcpWhitelistGenerateCommand(cfg *config.Config, fs afero.Fs) *cobra.Command {
+	var (
+		sourceUUID string
+		toolNames  []string
+		outFile    string
+		runner     string
+		timeout    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a whitelist config from a source's tool definitions",
+		Long: `Pull the full Kubiya tool definitions (args, env, files, image) from a source
+and emit a ready-to-use MCP whitelist configuration.
+
+When --out points to an existing configuration file, the generated tools are
+merged into its whitelisted_tools (replacing tools with the same name) and all
+other settings are preserved. JSON and YAML files are both supported and keep
+their format.`,
+		Example: `  # Print a whitelist config for two tools of a source
+  kubiya mcp whitelist generate --source abc-123 --tools kubectl,helm
+
+  # Write (or update) an MCP server config file
+  kubiya mcp whitelist generate --source abc-123 --tools kubectl,helm --out mcp-config.json
+
+  # Whitelist every tool of a source and pin them to a runner
+  kubiya mcp whitelist generate --source abc-123 --runner my-runner --out ~/.kubiya/mcp-server.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+
+			source, err := client.GetSourceMetadata(context.Background(), sourceUUID)
+			if err != nil {
+				return fmt.Errorf("failed to get source %s: %w", sourceUUID, err)
+			}
+
+			tools, err := mcp.WhitelistFromSource(source, toolNames)
+			if err != nil {
+				return err
+			}
+			if len(tools) == 0 {
+				return fmt.Errorf("source %s has no tools", sourceUUID)
+			}
+
+			for i := range tools {
+				if runner != "" {
+					tools[i].Runner = runner
+				}
+				if timeout > 0 {
+					tools[i].Timeout = timeout
+				}
+			}
+
+			whitelistCfg, format, err := buildWhitelistConfig(fs, outFile, tools)
+			if err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := output.EncodeJSON(&buf, whitelistCfg); err != nil {
+				return fmt.Errorf("failed to marshal config: %w", err)
+			}
+			data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+			if format == docload.YAML {
+				// Keep YAML configs in YAML
+				var doc interface{}
+				if err := json.Unmarshal(data, &doc); err != nil {
+					return fmt.Errorf("failed to marshal config: %w", err)
+				}
+				if data, err = yaml.Marshal(doc); err != nil {
+					return fmt.Errorf("failed to marshal config: %w", err)
+				}
+				data = bytes.TrimSuffix(data, []byte("\n"))
+			}
+
+			if outFile == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return nil
+			}
+
+			if err := afero.WriteFile(fs, outFile, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("failed to write config file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "✅ Whitelisted %d tool(s) from source %s in %s\n", len(tools), source.Name, outFile)
+			fmt.Fprintf(cmd.ErrOrStderr(), "   Start the server with: kubiya mcp serve --config %s\n", outFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&sourceUUID, "source", "s", "", "Source UUID to read tool definitions from")
+	cmd.Flags().StringSliceVarP(&toolNames, "tools", "t", nil, "Comma-separated tool names to whitelist (default: all tools in the source)")
+	cmd.Flags().StringVar(&outFile, "out", "", "Config file to create or update (default: print to stdout)")
+	cmd.Flags().StringVar(&runner, "runner", "", "Runner to execute the whitelisted tools on")
+	cmd.Flags().IntVar(&timeout, "timeout", 0, "Execution timeout in seconds for the whitelisted tools")
+	_ = cmd.MarkFlagRequired("source")
+
+	return cmd
+}
+
+// buildWhitelistConfig returns the configuration document to write and the
+// format (JSON or YAML) of the existing file. Existing files are loaded
+// generically so fields unknown to the simple server configuration (e.g.
+// production settings) survive the rewrite.
+func buildWhitelistConfig(fs afero.Fs, path string, tools []mcp.WhitelistedTool) (interface{}, string, error) {
+	if path == "" {
+		return &mcp.Configuration{
+			WhitelistedTools: tools,
+			EnableRunners:    true,
+		}, docload.JSON, nil
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if os.IsNotExist(err) {
+		return &mcp.Configuration{
+			WhitelistedTools: tools,
+			EnableRunners:    true,
+		}, docload.JSON, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var doc map[string]interface{}
+	if err := docload.Unmarshal(data, &doc); err != nil {
+		return nil, "", fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	var existing []interface{}
+	if raw, ok := doc["whitelisted_tools"]; ok && raw != nil {
+		if existing, ok = raw.([]interface{}); !ok {
+			return nil, "", fmt.Errorf("failed to parse whitelisted tools in %s: not a list", path)
+		}
+	}
+
+	merged, err := mcp.MergeWhitelistedTools(existing, tools)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to merge whitelisted tools: %w", err)
+	}
+	doc["whitelisted_tools"] = merged
+	return doc, docload.Format(data), nil
+}
@@ -142,8 +142,7 @@ your organization context for later recall.`,
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(resp, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(resp)
 			case "yaml":
 				data, _ := yaml.Marshal(resp)
 				fmt.Println(string(data))
@@ -243,8 +242,7 @@ and set minimum similarity scores.`,
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(resp, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(resp)
 			case "yaml":
 				data, _ := yaml.Marshal(resp)
 				fmt.Println(string(data))
@@ -343,8 +341,7 @@ func newMemoryListCommand(cfg *config.Config) *cobra.Command {
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(memories, "", "  ")
-				fmt.Println(string(data))
+				return printJSONList(memories)
 			case "yaml":
 				data, _ := yaml.Marshal(memories)
 				fmt.Println(string(data))
@@ -438,8 +435,7 @@ func newMemoryStatusCommand(cfg *config.Config) *cobra.Command {
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(status, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(status)
 			case "yaml":
 				data, _ := yaml.Marshal(status)
 				fmt.Println(string(data))
@@ -585,8 +581,7 @@ Scopes:
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(dataset, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(dataset)
 			case "yaml":
 				data, _ := yaml.Marshal(dataset)
 				fmt.Println(string(data))
@@ -644,8 +639,7 @@ func newDatasetListCommand(cfg *config.Config) *cobra.Command {
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(datasets, "", "  ")
-				fmt.Println(string(data))
+				return printJSONList(datasets)
 			case "yaml":
 				data, _ := yaml.Marshal(datasets)
 				fmt.Println(string(data))
@@ -727,8 +721,7 @@ func newDatasetGetCommand(cfg *config.Config) *cobra.Command {
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(dataset, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(dataset)
 			case "yaml":
 				data, _ := yaml.Marshal(dataset)
 				fmt.Println(string(data))
@@ -842,8 +835,7 @@ This is useful when you want to refresh the dataset content without recreating i
 			// Output results
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(resp, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(resp)
 			default:
 				fmt.Println()
 				fmt.Printf("%s Purge initiated successfully\n", style.SuccessStyle.Render("✓"))
@@ -899,8 +891,7 @@ func newDatasetGetDataCommand(cfg *config.Config) *cobra.Command {
 			// Output
 			switch outputFormat {
 			case "json":
-				data, _ := json.MarshalIndent(dataResp, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(dataResp)
 			case "yaml":
 				data, _ := yaml.Marshal(dataResp)
 				fmt.Println(string(data))
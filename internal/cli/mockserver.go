@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/mockserver"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// loadMockFixtures returns the fixtures in dir, or the built-in ones when dir
+// is empty
+func loadMockFixtures(dir string) (mockserver.Fixtures, error) {
+	if dir == "" {
+		return mockserver.DefaultFixtures(), nil
+	}
+	return mockserver.LoadFixtures(dir)
+}
+
+// startMockAPI serves the API for this process from an embedded mock server
+// and points cfg and the control plane client at it. Fixtures are read from
+// KUBIYA_MOCK_FIXTURES when set.
+func startMockAPI(cfg *config.Config) error {
+	fixtures, err := loadMockFixtures(os.Getenv("KUBIYA_MOCK_FIXTURES"))
+	if err != nil {
+		return err
+	}
+	url, _, err := mockserver.New(fixtures).Start("127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	cfg.BaseURL = url + "/api/v1"
+	if cfg.APIKey == "" {
+		cfg.APIKey = "mock-api-key"
+	}
+	os.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", url)
+
+	if cfg.Debug {
+		fmt.Fprintf(os.Stderr, "Using mock API at %s\n", url)
+	}
+	return nil
+}
+
+func newMockServerCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mockserver",
+		Short: "🧪 Run a local mock of the Kubiya API",
+		Long: `Run an in-memory mock of the Kubiya API serving agents, sources, runners and
+streaming chat from fixture files, for development and CI without credentials
+or network access.
+
+The CLI itself uses the mock when run with --mock, KUBIYA_MOCK=1 or a context
+named "mock".`,
+	}
+
+	cmd.AddCommand(newMockServerServeCommand())
+	return cmd
+}
+
+func newMockServerServeCommand() *cobra.Command {
+	var (
+		addr     string
+		fixtures string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the mock API until interrupted",
+		Long: `Serve the mock API until interrupted.
+
+The fixtures directory may contain agents.json, sources.json and runners.json
+(JSON arrays as returned by the API) and chat.json, a list of canned replies:
+
+  [{"match": "pods", "reply": "All pods are running",
+    "tool_calls": [{"name": "kubectl", "args": {"command": "get pods"}, "output": "..."}]}]
+
+Missing files fall back to a built-in agent, source and runner.`,
+		Example: `  kubiya mockserver serve --addr 127.0.0.1:8787 --fixtures test/fixtures/mock
+  KUBIYA_BASE_URL=http://127.0.0.1:8787/api/v1 KUBIYA_API_KEY=test kubiya agent list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := loadMockFixtures(fixtures)
+			if err != nil {
+				return err
+			}
+			url, stop, err := mockserver.New(data).Start(addr)
+			if err != nil {
+				return err
+			}
+			defer stop()
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Mock API listening on %s (%d agents, %d sources, %d runners)\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(url),
+				len(data.Agents), len(data.Sources), len(data.Runners))
+			fmt.Fprintf(cmd.OutOrStdout(), "  Point the CLI at it with KUBIYA_BASE_URL=%s/api/v1\n", url)
+
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+			select {
+			case <-sigs:
+			case <-cmd.Context().Done():
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8787", "Address to listen on")
+	cmd.Flags().StringVar(&fixtures, "fixtures", "", "Directory with fixture files (default: built-in fixtures)")
+	return cmd
+}
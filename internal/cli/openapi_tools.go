@@ -0,0 +1,707 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// openAPIToolImage runs the tools generated from OpenAPI specs; the script
+// only needs the Python standard library
+const openAPIToolImage = "python:3.12-slim"
+
+// openAPISpec is the part of an OpenAPI 3 or Swagger 2 document that tools
+// are generated from
+type openAPISpec struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+		Version     string `yaml:"version"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Host       string                     `yaml:"host"`
+	BasePath   string                     `yaml:"basePath"`
+	Schemes    []string                   `yaml:"schemes"`
+	Paths      map[string]openAPIPathItem `yaml:"paths"`
+	Components struct {
+		Schemas         map[string]*openAPISchema        `yaml:"schemas"`
+		Parameters      map[string]openAPIParameter      `yaml:"parameters"`
+		RequestBodies   map[string]openAPIRequestBody    `yaml:"requestBodies"`
+		SecuritySchemes map[string]openAPISecurityScheme `yaml:"securitySchemes"`
+	} `yaml:"components"`
+	Definitions         map[string]*openAPISchema        `yaml:"definitions"`
+	Parameters          map[string]openAPIParameter      `yaml:"parameters"`
+	SecurityDefinitions map[string]openAPISecurityScheme `yaml:"securityDefinitions"`
+	Security            []map[string][]string            `yaml:"security"`
+}
+
+type openAPIPathItem struct {
+	Parameters []openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation  `yaml:"get"`
+	Put        *openAPIOperation  `yaml:"put"`
+	Post       *openAPIOperation  `yaml:"post"`
+	Delete     *openAPIOperation  `yaml:"delete"`
+	Patch      *openAPIOperation  `yaml:"patch"`
+	Head       *openAPIOperation  `yaml:"head"`
+	Options    *openAPIOperation  `yaml:"options"`
+}
+
+type openAPIOperation struct {
+	OperationID string              `yaml:"operationId"`
+	Summary     string              `yaml:"summary"`
+	Description string              `yaml:"description"`
+	Tags        []string            `yaml:"tags"`
+	Deprecated  bool                `yaml:"deprecated"`
+	Parameters  []openAPIParameter  `yaml:"parameters"`
+	RequestBody *openAPIRequestBody `yaml:"requestBody"`
+}
+
+type openAPIParameter struct {
+	Ref         string         `yaml:"$ref"`
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description"`
+	Required    bool           `yaml:"required"`
+	Schema      *openAPISchema `yaml:"schema"`
+	// Swagger 2 keeps the type of non-body parameters on the parameter
+	Type    string        `yaml:"type"`
+	Enum    []interface{} `yaml:"enum"`
+	Default interface{}   `yaml:"default"`
+}
+
+type openAPIRequestBody struct {
+	Ref         string `yaml:"$ref"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Content     map[string]struct {
+		Schema *openAPISchema `yaml:"schema"`
+	} `yaml:"content"`
+}
+
+type openAPISchema struct {
+	Ref         string                    `yaml:"$ref"`
+	Type        string                    `yaml:"type"`
+	Description string                    `yaml:"description"`
+	Enum        []interface{}             `yaml:"enum"`
+	Default     interface{}               `yaml:"default"`
+	Properties  map[string]*openAPISchema `yaml:"properties"`
+	Required    []string                  `yaml:"required"`
+	AllOf       []*openAPISchema          `yaml:"allOf"`
+	ReadOnly    bool                      `yaml:"readOnly"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+	In     string `yaml:"in"`
+	Name   string `yaml:"name"`
+}
+
+// loadOpenAPISpec parses an OpenAPI 3 or Swagger 2 document in YAML or JSON
+func loadOpenAPISpec(data []byte) (*openAPISpec, error) {
+	var spec openAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if spec.OpenAPI == "" && spec.Swagger == "" {
+		return nil, fmt.Errorf("not an OpenAPI document: it has neither an openapi nor a swagger version")
+	}
+	if len(spec.Paths) == 0 {
+		return nil, fmt.Errorf("the OpenAPI document has no paths")
+	}
+	return &spec, nil
+}
+
+// baseURL returns the URL of the first server of the spec, resolved
+// against specURL when it is relative
+func (s *openAPISpec) baseURL(specURL string) string {
+	var base string
+	switch {
+	case len(s.Servers) > 0:
+		base = s.Servers[0].URL
+	case s.Host != "":
+		scheme := "https"
+		if len(s.Schemes) > 0 {
+			scheme = s.Schemes[0]
+		}
+		base = scheme + "://" + s.Host + s.BasePath
+	}
+	if ref, err := url.Parse(base); err == nil && !ref.IsAbs() && specURL != "" {
+		if spec, err := url.Parse(specURL); err == nil && spec.IsAbs() {
+			base = spec.ResolveReference(ref).String()
+		}
+	}
+	return strings.TrimRight(base, "/")
+}
+
+func (s *openAPISpec) parameter(p openAPIParameter) (openAPIParameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	params := s.Components.Parameters
+	if !ok {
+		name, ok = strings.CutPrefix(p.Ref, "#/parameters/")
+		params = s.Parameters
+	}
+	resolved, found := params[name]
+	if !ok || !found {
+		return p, fmt.Errorf("unresolved parameter reference %s", p.Ref)
+	}
+	return resolved, nil
+}
+
+func (s *openAPISpec) requestBody(b *openAPIRequestBody) (*openAPIRequestBody, error) {
+	if b == nil || b.Ref == "" {
+		return b, nil
+	}
+	name, ok := strings.CutPrefix(b.Ref, "#/components/requestBodies/")
+	resolved, found := s.Components.RequestBodies[name]
+	if !ok || !found {
+		return nil, fmt.Errorf("unresolved request body reference %s", b.Ref)
+	}
+	return &resolved, nil
+}
+
+// schema follows the references of a schema and merges allOf, so that the
+// properties of a request body can become tool args
+func (s *openAPISpec) schema(schema *openAPISchema, depth int) (*openAPISchema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if depth > 16 {
+		return nil, fmt.Errorf("schema references nest too deeply")
+	}
+	if schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		schemas := s.Components.Schemas
+		if !ok {
+			name, ok = strings.CutPrefix(schema.Ref, "#/definitions/")
+			schemas = s.Definitions
+		}
+		resolved, found := schemas[name]
+		if !ok || !found {
+			return nil, fmt.Errorf("unresolved schema reference %s", schema.Ref)
+		}
+		return s.schema(resolved, depth+1)
+	}
+	if len(schema.AllOf) == 0 {
+		return schema, nil
+	}
+
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = map[string]*openAPISchema{}
+	for name, prop := range schema.Properties {
+		merged.Properties[name] = prop
+	}
+	for _, part := range schema.AllOf {
+		resolved, err := s.schema(part, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if resolved == nil {
+			continue
+		}
+		if merged.Type == "" {
+			merged.Type = resolved.Type
+		}
+		for name, prop := range resolved.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, resolved.Required...)
+	}
+	return &merged, nil
+}
+
+// openAPIParam is a tool arg and where the script puts its value in the
+// request
+type openAPIParam struct {
+	Arg      string `json:"arg"`
+	Name     string `json:"name"`
+	In       string `json:"in"` // path, query, header, body or body_raw
+	Type     string `json:"type,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	JSON     bool   `json:"json,omitempty"`
+}
+
+// openAPIToolOperation is an operation as the script of a tool sees it
+type openAPIToolOperation struct {
+	Name        string         `json:"name"`
+	Method      string         `json:"method"`
+	Path        string         `json:"path"`
+	ContentType string         `json:"content_type,omitempty"`
+	Params      []openAPIParam `json:"params"`
+
+	summary string
+	tags    []string
+	args    []kubiya.ToolArg
+}
+
+// openAPIToolAuth tells the script how to send the secret of a tool
+type openAPIToolAuth struct {
+	Secret string `json:"secret"`
+	In     string `json:"in"` // header or query
+	Name   string `json:"name"`
+	Prefix string `json:"prefix,omitempty"`
+	Basic  bool   `json:"basic,omitempty"`
+}
+
+// openAPIToolOptions configure the tools generated from a spec
+type openAPIToolOptions struct {
+	BaseURL string
+	// GroupBy is "operation" for a tool per operation, or "tag" for a tool
+	// per tag that takes the operation as an arg
+	GroupBy string
+	// Secret holds the credentials sent as the spec's security scheme
+	Secret            string
+	IncludeDeprecated bool
+}
+
+var (
+	camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// openAPIIdentifier turns an operation ID, tag or parameter name into a
+// snake_case name usable as tool name, arg and environment variable
+func openAPIIdentifier(s string) string {
+	s = camelBoundary.ReplaceAllString(s, "${1}_${2}")
+	s = strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+// openAPIArgType maps a schema type to the type of a tool arg. Arrays and
+// objects are passed as JSON strings.
+func openAPIArgType(t string) (string, bool) {
+	switch t {
+	case "integer", "number", "boolean":
+		return t, false
+	case "array", "object":
+		return "string", true
+	}
+	return "string", false
+}
+
+func openAPIArg(name, description, schemaType string, required bool, enum []interface{}, def interface{}) (kubiya.ToolArg, bool) {
+	argType, isJSON := openAPIArgType(schemaType)
+	if isJSON {
+		description = strings.TrimSpace(description + " (JSON " + schemaType + ")")
+	}
+	arg := kubiya.ToolArg{
+		Name:        name,
+		Type:        argType,
+		Description: firstLine(description),
+		Required:    required,
+	}
+	for _, v := range enum {
+		arg.Options = append(arg.Options, fmt.Sprint(v))
+	}
+	if def != nil {
+		arg.Default = fmt.Sprint(def)
+	}
+	return arg, isJSON
+}
+
+// operations returns the operations of the spec, sorted by path and method,
+// with their args
+func (s *openAPISpec) operations(opts openAPIToolOptions) ([]openAPIToolOperation, error) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []openAPIToolOperation
+	names := map[string]int{}
+	for _, path := range paths {
+		item := s.Paths[path]
+		for _, m := range []struct {
+			method string
+			op     *openAPIOperation
+		}{
+			{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch},
+			{"DELETE", item.Delete}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+		} {
+			if m.op == nil || (m.op.Deprecated && !opts.IncludeDeprecated) {
+				continue
+			}
+			op, err := s.operation(m.method, path, item.Parameters, m.op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", m.method, path, err)
+			}
+			// Operation IDs are optional and not always unique
+			names[op.Name]++
+			if n := names[op.Name]; n > 1 {
+				op.Name = fmt.Sprintf("%s_%d", op.Name, n)
+			}
+			ops = append(ops, op)
+		}
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("the OpenAPI document has no operations")
+	}
+	return ops, nil
+}
+
+func (s *openAPISpec) operation(method, path string, shared []openAPIParameter, op *openAPIOperation) (openAPIToolOperation, error) {
+	name := openAPIIdentifier(op.OperationID)
+	if name == "" {
+		name = openAPIIdentifier(strings.ToLower(method) + " " + strings.NewReplacer("{", "by ", "}", "").Replace(path))
+	}
+	out := openAPIToolOperation{
+		Name:    name,
+		Method:  method,
+		Path:    path,
+		summary: firstNonEmpty(op.Summary, firstLine(op.Description), method+" "+path),
+		tags:    op.Tags,
+	}
+
+	// Parameters of the operation override those of the path
+	byKey := map[string]openAPIParameter{}
+	var order []string
+	for _, raw := range append(append([]openAPIParameter{}, shared...), op.Parameters...) {
+		p, err := s.parameter(raw)
+		if err != nil {
+			return out, err
+		}
+		key := p.In + ":" + p.Name
+		if _, seen := byKey[key]; !seen {
+			order = append(order, key)
+		}
+		byKey[key] = p
+	}
+
+	used := map[string]bool{"operation": true}
+	addArg := func(param openAPIParam, arg kubiya.ToolArg) {
+		for used[param.Arg] {
+			param.Arg = "body_" + param.Arg
+		}
+		used[param.Arg] = true
+		arg.Name = param.Arg
+		out.Params = append(out.Params, param)
+		out.args = append(out.args, arg)
+	}
+
+	var body *openAPIRequestBody
+	for _, key := range order {
+		p := byKey[key]
+		switch p.In {
+		case "path", "query", "header":
+		case "body":
+			// Swagger 2 request body
+			body = &openAPIRequestBody{Description: p.Description, Required: p.Required}
+			body.Content = map[string]struct {
+				Schema *openAPISchema `yaml:"schema"`
+			}{"application/json": {Schema: p.Schema}}
+			continue
+		default:
+			// Cookies and form data are not supported
+			continue
+		}
+		if p.In == "header" && strings.EqualFold(p.Name, "Authorization") {
+			continue
+		}
+		schemaType, enum, def := p.Type, p.Enum, p.Default
+		if p.Schema != nil {
+			schema, err := s.schema(p.Schema, 0)
+			if err != nil {
+				return out, err
+			}
+			schemaType, enum, def = schema.Type, schema.Enum, schema.Default
+		}
+		required := p.Required || p.In == "path"
+		arg, isJSON := openAPIArg(p.Name, p.Description, schemaType, required, enum, def)
+		addArg(openAPIParam{Arg: openAPIIdentifier(p.Name), Name: p.Name, In: p.In, Type: arg.Type, Required: required, JSON: isJSON}, arg)
+	}
+
+	if op.RequestBody != nil {
+		var err error
+		if body, err = s.requestBody(op.RequestBody); err != nil {
+			return out, err
+		}
+	}
+	if body != nil {
+		if err := s.bodyArgs(&out, body, addArg); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// bodyArgs adds an arg per property of a JSON object body, or a single body
+// arg for other bodies
+func (s *openAPISpec) bodyArgs(out *openAPIToolOperation, body *openAPIRequestBody, addArg func(openAPIParam, kubiya.ToolArg)) error {
+	contentTypes := make([]string, 0, len(body.Content))
+	for ct := range body.Content {
+		contentTypes = append(contentTypes, ct)
+	}
+	sort.Strings(contentTypes)
+	contentType := "application/json"
+	if _, ok := body.Content[contentType]; !ok && len(contentTypes) > 0 {
+		contentType = contentTypes[0]
+	}
+	out.ContentType = contentType
+
+	schema, err := s.schema(body.Content[contentType].Schema, 0)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(contentType, "json") && schema != nil && len(schema.Properties) > 0 {
+		required := map[string]bool{}
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+		props := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			props = append(props, name)
+		}
+		sort.Strings(props)
+		for _, name := range props {
+			prop, err := s.schema(schema.Properties[name], 0)
+			if err != nil {
+				return err
+			}
+			if prop == nil || prop.ReadOnly {
+				continue
+			}
+			arg, isJSON := openAPIArg(name, prop.Description, prop.Type, required[name], prop.Enum, prop.Default)
+			addArg(openAPIParam{Arg: openAPIIdentifier(name), Name: name, In: "body", Type: arg.Type, Required: required[name], JSON: isJSON}, arg)
+		}
+		return nil
+	}
+
+	description := firstNonEmpty(body.Description, "Request body")
+	arg := kubiya.ToolArg{Type: "string", Description: firstLine(description) + " (" + contentType + ")", Required: body.Required}
+	addArg(openAPIParam{Arg: "body", Name: "body", In: "body_raw", Type: "string", Required: body.Required}, arg)
+	return nil
+}
+
+// auth returns how the secret of the tools is sent, following the first
+// security scheme of the spec and sending a bearer token without one
+func (s *openAPISpec) auth(secret string) *openAPIToolAuth {
+	if secret == "" {
+		return nil
+	}
+	auth := &openAPIToolAuth{Secret: secret, In: "header", Name: "Authorization", Prefix: "Bearer "}
+
+	schemes := s.Components.SecuritySchemes
+	if len(schemes) == 0 {
+		schemes = s.SecurityDefinitions
+	}
+	var scheme *openAPISecurityScheme
+	for _, requirement := range s.Security {
+		for name := range requirement {
+			if sch, ok := schemes[name]; ok && scheme == nil {
+				scheme = &sch
+			}
+		}
+	}
+	if scheme == nil && len(schemes) == 1 {
+		for _, sch := range schemes {
+			scheme = &sch
+		}
+	}
+	if scheme == nil {
+		return auth
+	}
+
+	switch {
+	case scheme.Type == "apiKey" && (scheme.In == "header" || scheme.In == "query"):
+		auth.In, auth.Name, auth.Prefix = scheme.In, scheme.Name, ""
+	case scheme.Type == "basic" || (scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic")):
+		auth.Prefix, auth.Basic = "Basic ", true
+	}
+	return auth
+}
+
+// openAPITools generates the tools of a spec: one per operation, or one per
+// tag that takes the operation as an arg
+func openAPITools(spec *openAPISpec, opts openAPIToolOptions) ([]kubiya.Tool, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("the OpenAPI document has no server URL, pass --base-url")
+	}
+	ops, err := spec.operations(opts)
+	if err != nil {
+		return nil, err
+	}
+	auth := spec.auth(opts.Secret)
+
+	var groups []string
+	byGroup := map[string][]openAPIToolOperation{}
+	for _, op := range ops {
+		group := op.Name
+		if opts.GroupBy == "tag" {
+			group = "default"
+			if len(op.tags) > 0 {
+				group = openAPIIdentifier(op.tags[0])
+			}
+		}
+		if _, ok := byGroup[group]; !ok {
+			groups = append(groups, group)
+		}
+		byGroup[group] = append(byGroup[group], op)
+	}
+
+	tools := make([]kubiya.Tool, 0, len(groups))
+	for _, group := range groups {
+		tool, err := openAPITool(spec, group, byGroup[group], opts.GroupBy == "tag", opts.BaseURL, auth)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+func openAPITool(spec *openAPISpec, name string, ops []openAPIToolOperation, perTag bool, baseURL string, auth *openAPIToolAuth) (kubiya.Tool, error) {
+	tool := kubiya.Tool{
+		Name:  name,
+		Type:  "docker",
+		Image: openAPIToolImage,
+	}
+	if auth != nil {
+		tool.Secrets = []string{auth.Secret}
+	}
+
+	if !perTag {
+		op := ops[0]
+		tool.Description = fmt.Sprintf("%s (%s %s)", op.summary, op.Method, op.Path)
+		tool.Args = op.args
+	} else {
+		// A tool per tag takes the operation as an arg; the args of all its
+		// operations are optional, the script checks those of the operation
+		var lines []string
+		operation := kubiya.ToolArg{Name: "operation", Type: "string", Description: "Operation to call", Required: true}
+		seen := map[string]bool{}
+		for _, op := range ops {
+			operation.Options = append(operation.Options, op.Name)
+			lines = append(lines, fmt.Sprintf("- %s: %s (%s %s)", op.Name, op.summary, op.Method, op.Path))
+			for _, arg := range op.args {
+				if seen[arg.Name] {
+					continue
+				}
+				seen[arg.Name] = true
+				arg.Required = false
+				tool.Args = append(tool.Args, arg)
+			}
+		}
+		tool.Args = append([]kubiya.ToolArg{operation}, tool.Args...)
+		title := firstNonEmpty(spec.Info.Title, "the API")
+		tool.Description = fmt.Sprintf("Call the %s operations of %s:\n%s", name, title, strings.Join(lines, "\n"))
+	}
+
+	config, err := json.Marshal(struct {
+		BaseURL    string                 `json:"base_url"`
+		Auth       *openAPIToolAuth       `json:"auth,omitempty"`
+		Operations []openAPIToolOperation `json:"operations"`
+	}{baseURL, auth, ops})
+	if err != nil {
+		return tool, err
+	}
+	// A JSON string is a valid Python string literal
+	literal, err := json.Marshal(string(config))
+	if err != nil {
+		return tool, err
+	}
+	tool.Content = strings.Replace(openAPIToolScript, "__SPEC__", string(literal), 1)
+	return tool, nil
+}
+
+// openAPIToolScript calls an operation with the args of the tool, which are
+// available as environment variables
+const openAPIToolScript = `python3 - <<'PY'
+import base64, json, os, sys, urllib.error, urllib.parse, urllib.request
+
+SPEC = json.loads(__SPEC__)
+
+
+def arg(name):
+    value = os.environ.get(name, "")
+    return value if value != "" else None
+
+
+ops = SPEC["operations"]
+op = ops[0]
+if len(ops) > 1 or arg("operation"):
+    wanted = arg("operation")
+    op = next((o for o in ops if o["name"] == wanted), None)
+    if op is None:
+        sys.exit("unknown operation %r, use one of: %s" % (wanted, ", ".join(o["name"] for o in ops)))
+
+missing = [p["arg"] for p in op["params"] if p.get("required") and arg(p["arg"]) is None]
+if missing:
+    sys.exit("missing required arguments: " + ", ".join(missing))
+
+path, query, headers, body = op["path"], [], {"Accept": "application/json"}, None
+for p in op["params"]:
+    value = arg(p["arg"])
+    if value is None:
+        continue
+    if p["in"] == "body" and (p.get("json") or p.get("type") in ("integer", "number", "boolean")):
+        try:
+            value = json.loads(value.lower() if p.get("type") == "boolean" else value)
+        except ValueError:
+            pass
+    if p["in"] == "path":
+        path = path.replace("{" + p["name"] + "}", urllib.parse.quote(value, safe=""))
+    elif p["in"] == "query":
+        query.append((p["name"], value))
+    elif p["in"] == "header":
+        headers[p["name"]] = value
+    elif p["in"] == "body":
+        body = body if isinstance(body, dict) else {}
+        body[p["name"]] = value
+    elif p["in"] == "body_raw":
+        body = value
+
+data = None
+if body is not None:
+    data = (body if isinstance(body, str) else json.dumps(body)).encode()
+    headers["Content-Type"] = op.get("content_type") or "application/json"
+
+auth = SPEC.get("auth")
+if auth:
+    secret = os.environ.get(auth["secret"], "")
+    if not secret:
+        sys.exit("the secret %s is not set" % auth["secret"])
+    if auth.get("basic"):
+        secret = base64.b64encode(secret.encode()).decode()
+    if auth["in"] == "query":
+        query.append((auth["name"], auth.get("prefix", "") + secret))
+    else:
+        headers[auth["name"]] = auth.get("prefix", "") + secret
+
+url = SPEC["base_url"] + path
+if query:
+    url += "?" + urllib.parse.urlencode(query)
+request = urllib.request.Request(url, data=data, method=op["method"], headers=headers)
+try:
+    with urllib.request.urlopen(request, timeout=60) as response:
+        status, text = response.status, response.read().decode("utf-8", "replace")
+except urllib.error.HTTPError as e:
+    status, text = e.code, e.read().decode("utf-8", "replace")
+
+try:
+    text = json.dumps(json.loads(text), indent=2)
+except ValueError:
+    pass
+print(text)
+if status >= 400:
+    sys.exit("%s %s failed with status %d" % (op["method"], path, status))
+PY
+`
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  description: Manage the pets of the store
+servers:
+  - url: /v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List all pets
+      tags: [pets]
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available, sold]
+    post:
+      operationId: createPet
+      summary: Create a pet
+      tags: [pets]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema:
+          type: string
+    get:
+      operationId: showPetById
+      summary: Info for a specific pet
+      tags: [pets]
+    delete:
+      summary: Delete a pet
+      deprecated: true
+  /store/inventory:
+    get:
+      operationId: getInventory
+      tags: [store]
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      description: How many items to return
+      schema:
+        type: integer
+        default: 20
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+    NewPet:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          properties:
+            tags:
+              type: array
+  securitySchemes:
+    api_key:
+      type: apiKey
+      in: header
+      name: X-API-Key
+security:
+  - api_key: []
+`
+
+func toolArg(t *testing.T, tool kubiya.Tool, name string) kubiya.ToolArg {
+	t.Helper()
+	for _, arg := range tool.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	t.Fatalf("tool %s has no arg %s", tool.Name, name)
+	return kubiya.ToolArg{}
+}
+
+func TestOpenAPIToolsPerOperation(t *testing.T) {
+	spec, err := loadOpenAPISpec([]byte(petstoreSpec))
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v1", spec.baseURL("https://api.example.com/openapi.yaml"))
+
+	tools, err := openAPITools(spec, openAPIToolOptions{BaseURL: "https://api.example.com/v1", GroupBy: "operation", Secret: "PETSTORE_KEY"})
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	// The deprecated operation is skipped
+	assert.Equal(t, []string{"list_pets", "create_pet", "show_pet_by_id", "get_inventory"}, names)
+
+	list := tools[0]
+	assert.Equal(t, "docker", list.Type)
+	assert.Equal(t, []string{"PETSTORE_KEY"}, list.Secrets)
+	assert.Contains(t, list.Description, "GET /pets")
+	limit := toolArg(t, list, "limit")
+	assert.Equal(t, "integer", limit.Type)
+	assert.Equal(t, "20", limit.Default)
+	assert.False(t, limit.Required)
+	assert.Equal(t, []string{"available", "sold"}, toolArg(t, list, "status").Options)
+
+	create := tools[1]
+	assert.True(t, toolArg(t, create, "name").Required)
+	assert.Contains(t, toolArg(t, create, "tags").Description, "JSON array")
+	assert.Len(t, create.Args, 2, "read-only properties are not args")
+
+	assert.True(t, toolArg(t, tools[2], "pet_id").Required)
+
+	assert.Contains(t, list.Content, `\"in\":\"header\",\"name\":\"X-API-Key\"`)
+	assert.True(t, strings.HasPrefix(list.Content, "python3 - <<'PY'"))
+}
+
+func TestOpenAPIToolsPerTag(t *testing.T) {
+	spec, err := loadOpenAPISpec([]byte(petstoreSpec))
+	require.NoError(t, err)
+
+	tools, err := openAPITools(spec, openAPIToolOptions{BaseURL: "https://api.example.com/v1", GroupBy: "tag", IncludeDeprecated: true})
+	require.NoError(t, err)
+	require.Len(t, tools, 3)
+
+	pets := tools[0]
+	assert.Equal(t, "pets", pets.Name)
+	operation := toolArg(t, pets, "operation")
+	assert.True(t, operation.Required)
+	assert.Equal(t, []string{"list_pets", "create_pet", "show_pet_by_id"}, operation.Options)
+	assert.False(t, toolArg(t, pets, "pet_id").Required, "args of a single operation are optional")
+	assert.Empty(t, pets.Secrets)
+
+	// Operations without a tag or operation ID
+	assert.Equal(t, "default", tools[1].Name)
+	assert.Equal(t, []string{"delete_pets_by_pet_id"}, toolArg(t, tools[1], "operation").Options)
+	assert.Equal(t, "store", tools[2].Name)
+}
+
+func TestOpenAPISwagger2(t *testing.T) {
+	spec, err := loadOpenAPISpec([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "Users"},
+  "host": "users.example.com",
+  "basePath": "/api",
+  "schemes": ["https"],
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "paths": {
+    "/users/{id}": {
+      "put": {
+        "operationId": "updateUser",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "integer"},
+          {"name": "id", "in": "body", "schema": {"$ref": "#/definitions/User"}}
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "User": {"type": "object", "properties": {"id": {"type": "integer"}, "admin": {"type": "boolean"}}}
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://users.example.com/api", spec.baseURL(""))
+
+	tools, err := openAPITools(spec, openAPIToolOptions{BaseURL: spec.baseURL(""), GroupBy: "operation", Secret: "USERS_LOGIN"})
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	var names []string
+	for _, arg := range tools[0].Args {
+		names = append(names, arg.Name)
+	}
+	// The body property that collides with the path parameter is prefixed
+	assert.Equal(t, []string{"id", "admin", "body_id"}, names)
+	assert.Equal(t, "boolean", toolArg(t, tools[0], "admin").Type)
+
+	auth := spec.auth("USERS_LOGIN")
+	require.NotNil(t, auth)
+	assert.True(t, auth.Basic)
+	assert.Equal(t, "Authorization", auth.Name)
+}
+
+func TestOpenAPIToolScriptEmbedsOperations(t *testing.T) {
+	spec, err := loadOpenAPISpec([]byte(petstoreSpec))
+	require.NoError(t, err)
+	tools, err := openAPITools(spec, openAPIToolOptions{BaseURL: "https://api.example.com/v1", GroupBy: "operation"})
+	require.NoError(t, err)
+
+	content := tools[2].Content
+	start := strings.Index(content, "json.loads(") + len("json.loads(")
+	end := strings.Index(content[start:], ")\n") + start
+	var literal string
+	require.NoError(t, json.Unmarshal([]byte(content[start:end]), &literal))
+
+	var config struct {
+		BaseURL    string                 `json:"base_url"`
+		Auth       *openAPIToolAuth       `json:"auth"`
+		Operations []openAPIToolOperation `json:"operations"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(literal), &config))
+	assert.Equal(t, "https://api.example.com/v1", config.BaseURL)
+	assert.Nil(t, config.Auth)
+	require.Len(t, config.Operations, 1)
+	assert.Equal(t, "/pets/{petId}", config.Operations[0].Path)
+	assert.Equal(t, []openAPIParam{{Arg: "pet_id", Name: "petId", In: "path", Type: "string", Required: true}}, config.Operations[0].Params)
+}
+
+func TestLoadOpenAPISpecErrors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"not a spec": "name: petstore\n",
+		"no paths":   "openapi: 3.0.0\ninfo: {title: x}\n",
+		"invalid":    "openapi: [",
+	} {
+		_, err := loadOpenAPISpec([]byte(doc))
+		assert.Error(t, err, name)
+	}
+
+	spec, err := loadOpenAPISpec([]byte("openapi: 3.0.0\npaths:\n  /x:\n    get:\n      parameters:\n        - $ref: '#/components/parameters/Missing'\n"))
+	require.NoError(t, err)
+	_, err = openAPITools(spec, openAPIToolOptions{BaseURL: "https://x", GroupBy: "operation"})
+	assert.ErrorContains(t, err, "unresolved parameter reference")
+
+	_, err = openAPITools(spec, openAPIToolOptions{GroupBy: "operation"})
+	assert.ErrorContains(t, err, "--base-url")
+}
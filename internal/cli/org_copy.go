@@ -0,0 +1,464 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// copyPlaceholder replaces values that must not leave the source organization
+const copyPlaceholder = "<REPLACE_ME>"
+
+// sensitiveKeyPattern matches configuration keys whose values are credentials
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(secret|token|password|passwd|api[_-]?key|private[_-]?key|credential)`)
+
+// copyReport collects what a cross-org copy changed, so the user knows what
+// is left to do in the target organization
+type copyReport struct {
+	Remapped       []string `json:"remapped,omitempty"`
+	Dropped        []string `json:"dropped,omitempty"`
+	Placeholders   []string `json:"placeholders,omitempty"`
+	MissingSecrets []string `json:"missing_secrets,omitempty"`
+}
+
+func (r *copyReport) print(w io.Writer, orgName string) {
+	for _, line := range r.Remapped {
+		fmt.Fprintf(w, "  🔁 %s\n", line)
+	}
+	for _, line := range r.Dropped {
+		fmt.Fprintf(w, "  %s %s\n", style.WarningStyle.Render("⚠"), line)
+	}
+	if len(r.Placeholders) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.SubtitleStyle.Render("Values replaced with "+copyPlaceholder))
+		for _, key := range r.Placeholders {
+			fmt.Fprintf(w, "  • %s\n", key)
+		}
+	}
+	if len(r.MissingSecrets) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.SubtitleStyle.Render("Secrets to create in "+orgName))
+		for _, name := range r.MissingSecrets {
+			fmt.Fprintf(w, "  kubiya secret create %s --value %s\n", name, copyPlaceholder)
+		}
+	}
+}
+
+// redactSensitive returns a copy of values with credential-looking entries
+// replaced by copyPlaceholder. Replaced keys are reported with their path.
+func redactSensitive(values map[string]interface{}, path string, report *copyReport) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(values))
+	for _, k := range keys {
+		v := values[k]
+		keyPath := k
+		if path != "" {
+			keyPath = path + "." + k
+		}
+
+		switch val := v.(type) {
+		case map[string]interface{}:
+			out[k] = redactSensitive(val, keyPath, report)
+		case string:
+			if val != "" && sensitiveKeyPattern.MatchString(k) {
+				out[k] = copyPlaceholder
+				report.Placeholders = append(report.Placeholders, keyPath)
+			} else {
+				out[k] = val
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// buildAgentCopy turns an agent of the source organization into a create
+// request for the target organization. Team and environment IDs are left for
+// the caller to re-map since they only make sense in the source organization.
+func buildAgentCopy(agent *entities.Agent, name string) (*entities.AgentCreateRequest, *copyReport) {
+	report := &copyReport{}
+
+	if name == "" {
+		name = agent.Name
+	}
+
+	req := &entities.AgentCreateRequest{
+		Name:          name,
+		Description:   agent.Description,
+		Capabilities:  agent.Capabilities,
+		Configuration: redactSensitive(agent.Configuration, "configuration", report),
+		ModelID:       agent.ModelID,
+		Model:         agent.Model,
+		LLMConfig:     redactSensitive(agent.LLMConfig, "llm_config", report),
+		SystemPrompt:  agent.SystemPrompt,
+	}
+	if agent.Runtime != "" {
+		runtime := agent.Runtime
+		req.Runtime = &runtime
+	}
+
+	if env := agent.ExecutionEnvironment; env != nil {
+		copied := &entities.ExecutionEnvironment{
+			Secrets:      env.Secrets,
+			Integrations: env.Integrations,
+		}
+		if env.EnvVars != nil {
+			copied.EnvVars = make(map[string]string, len(env.EnvVars))
+			for k, v := range env.EnvVars {
+				if v != "" && sensitiveKeyPattern.MatchString(k) {
+					v = copyPlaceholder
+					report.Placeholders = append(report.Placeholders, "env_vars."+k)
+				}
+				copied.EnvVars[k] = v
+			}
+			sort.Strings(report.Placeholders)
+		}
+		req.ExecutionEnvironment = copied
+	}
+
+	return req, report
+}
+
+// remapAgentReferences replaces the team and environment IDs of the source
+// organization with the IDs of same-named resources in the target one
+func remapAgentReferences(src, dst *controlplane.Client, agent *entities.Agent, req *entities.AgentCreateRequest, report *copyReport) error {
+	if agent.TeamID != nil && *agent.TeamID != "" {
+		team, err := src.GetTeam(*agent.TeamID)
+		if err != nil {
+			return fmt.Errorf("failed to get team %s: %w", *agent.TeamID, err)
+		}
+		teams, err := dst.ListTeams()
+		if err != nil {
+			return fmt.Errorf("failed to list teams in target organization: %w", err)
+		}
+		for _, t := range teams {
+			if t.Name == team.Name {
+				id := t.ID
+				req.TeamID = &id
+				report.Remapped = append(report.Remapped, fmt.Sprintf("team %s: %s → %s", team.Name, team.ID, t.ID))
+				break
+			}
+		}
+		if req.TeamID == nil {
+			report.Dropped = append(report.Dropped, fmt.Sprintf("team %s does not exist in the target organization", team.Name))
+		}
+	}
+
+	if len(agent.EnvironmentIDs) > 0 {
+		targetEnvs, err := dst.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments in target organization: %w", err)
+		}
+		byName := make(map[string]string, len(targetEnvs))
+		for _, e := range targetEnvs {
+			byName[e.Name] = e.ID
+		}
+
+		for _, id := range agent.EnvironmentIDs {
+			env, err := src.GetEnvironment(id)
+			if err != nil {
+				return fmt.Errorf("failed to get environment %s: %w", id, err)
+			}
+			if newID, ok := byName[env.Name]; ok {
+				req.EnvironmentIDs = append(req.EnvironmentIDs, newID)
+				report.Remapped = append(report.Remapped, fmt.Sprintf("environment %s: %s → %s", env.Name, id, newID))
+			} else {
+				report.Dropped = append(report.Dropped, fmt.Sprintf("environment %s does not exist in the target organization", env.Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// missingSecrets returns the referenced secrets that do not exist in the
+// target organization. When the secrets cannot be listed, all of them are
+// reported so nothing is silently assumed to exist.
+func missingSecrets(cmd *cobra.Command, targetCfg *config.Config, names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	secrets, err := kubiya.NewClient(targetCfg).ListSecrets(cmd.Context())
+	if err != nil {
+		return names
+	}
+	existing := make(map[string]bool, len(secrets))
+	for _, s := range secrets {
+		existing[s.Name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !existing[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// targetOrgConfig resolves --to-org and returns the target organization along
+// with a copy of cfg authenticated against it
+func targetOrgConfig(cfg *config.Config, orgName string) (*kubiyacontext.OrganizationTarget, *config.Config, error) {
+	target, err := kubiyacontext.ResolveOrganization(orgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, configForTarget(cfg, target), nil
+}
+
+// configForTarget returns a copy of cfg sending the token of the target
+// organization to its API. Organizations without an API URL of their own
+// are on the same API as cfg.
+func configForTarget(cfg *config.Config, target *kubiyacontext.OrganizationTarget) *config.Config {
+	targetCfg := *cfg
+	targetCfg.APIKey = target.Token
+	targetCfg.Org = target.ID
+	if target.APIURL != "" {
+		targetCfg.BaseURL = target.APIURL
+	}
+	return &targetCfg
+}
+
+func newAgentCopyCommand(cfg *config.Config) *cobra.Command {
+	var (
+		toOrg  string
+		name   string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "copy [uuid]",
+		Short: "📋 Copy an agent to another organization",
+		Long: `Copy an agent to another configured organization.
+
+Teams and environments are re-mapped to the resources with the same name in the
+target organization. Credential-looking values are replaced with ` + copyPlaceholder + `
+and referenced secrets missing from the target organization are listed.`,
+		Example: `  kubiya config set-org acme-staging --user acme-staging --token $ACME_STAGING_KEY
+  kubiya agent copy abc-123 --to-org acme-staging
+  kubiya agent copy abc-123 --to-org acme-staging --name devops-staging --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, targetCfg, err := targetOrgConfig(cfg, toOrg)
+			if err != nil {
+				return err
+			}
+
+			src, err := controlplane.New(cfg.APIKey, cfg.Debug)
+			if err != nil {
+				return fmt.Errorf("failed to create control plane client: %w", err)
+			}
+			dst, err := controlplane.NewWithURL(target.Token, target.APIURL, cfg.Debug)
+			if err != nil {
+				return fmt.Errorf("failed to create control plane client for %s: %w", toOrg, err)
+			}
+
+			agent, err := src.GetAgent(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get agent: %w", err)
+			}
+
+			req, report := buildAgentCopy(agent, name)
+			if err := remapAgentReferences(src, dst, agent, req, report); err != nil {
+				return err
+			}
+			if req.ExecutionEnvironment != nil {
+				report.MissingSecrets = missingSecrets(cmd, targetCfg, req.ExecutionEnvironment.Secrets)
+			}
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				return output.EncodeJSON(out, struct {
+					Request *entities.AgentCreateRequest `json:"request"`
+					Report  *copyReport                  `json:"report"`
+				}{req, report})
+			}
+
+			created, err := dst.CreateAgent(req)
+			if err != nil {
+				return fmt.Errorf("failed to create agent in %s: %w", toOrg, err)
+			}
+
+			fmt.Fprintf(out, "%s Copied agent %s to %s\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(agent.Name),
+				style.HighlightStyle.Render(toOrg))
+			fmt.Fprintf(out, "  🔁 agent: %s → %s\n", agent.ID, created.ID)
+			report.print(out, toOrg)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&toOrg, "to-org", "", "Organization (or context) to copy the agent to")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the copy (default: same name)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the agent that would be created without creating it")
+	_ = cmd.MarkFlagRequired("to-org")
+
+	return cmd
+}
+
+func newSourceCopyCommand(cfg *config.Config) *cobra.Command {
+	var (
+		toOrg  string
+		name   string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "copy [uuid]",
+		Short: "📋 Copy a source to another organization",
+		Long: `Copy a source to another configured organization.
+
+Credential-looking values in the dynamic configuration are replaced with
+` + copyPlaceholder + `; set them in the target organization once the copy exists.`,
+		Example:      "  kubiya source copy abc-123 --to-org acme-staging",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, targetCfg, err := targetOrgConfig(cfg, toOrg)
+			if err != nil {
+				return err
+			}
+
+			source, err := kubiya.NewClient(cfg).GetSourceMetadata(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get source: %w", err)
+			}
+
+			report := &copyReport{}
+			if name == "" {
+				name = source.Name
+			}
+			opts := []kubiya.SourceOption{
+				kubiya.WithName(name),
+				kubiya.WithDynamicConfig(redactSensitive(source.DynamicConfig, "dynamic_config", report)),
+			}
+			if source.Runner != "" {
+				opts = append(opts, kubiya.WithRunner(source.Runner))
+			}
+			if len(source.InlineTools) > 0 {
+				opts = append(opts, kubiya.WithInlineTools(source.InlineTools))
+			}
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				fmt.Fprintf(out, "Would copy source %s (%s) to %s\n", source.Name, source.URL, toOrg)
+				report.print(out, toOrg)
+				return nil
+			}
+
+			created, err := kubiya.NewClient(targetCfg).CreateSource(cmd.Context(), source.URL, opts...)
+			if err != nil {
+				return fmt.Errorf("failed to create source in %s: %w", toOrg, err)
+			}
+
+			fmt.Fprintf(out, "%s Copied source %s to %s\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(source.Name),
+				style.HighlightStyle.Render(toOrg))
+			fmt.Fprintf(out, "  🔁 source: %s → %s\n", source.UUID, created.UUID)
+			report.print(out, toOrg)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&toOrg, "to-org", "", "Organization (or context) to copy the source to")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the copy (default: same name)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be copied without creating anything")
+	_ = cmd.MarkFlagRequired("to-org")
+
+	return cmd
+}
+
+func newKnowledgeCopyCommand(cfg *config.Config) *cobra.Command {
+	var (
+		toOrg  string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "copy [uuid]",
+		Short: "📋 Copy a knowledge item to another organization",
+		Long: `Copy a knowledge item to another configured organization.
+
+Agent and group restrictions reference IDs of the source organization and are
+dropped; re-apply them in the target organization after copying.`,
+		Example: "  kubiya knowledge copy abc-123 --to-org acme-staging",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, targetCfg, err := targetOrgConfig(cfg, toOrg)
+			if err != nil {
+				return err
+			}
+
+			item, err := kubiya.NewClient(cfg).GetKnowledge(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get knowledge item: %w", err)
+			}
+
+			report := &copyReport{}
+			sourceUUID := item.UUID
+			copied := kubiya.Knowledge{
+				Name:        item.Name,
+				Description: item.Description,
+				Content:     item.Content,
+				Labels:      item.Labels,
+				Properties:  item.Properties,
+				Type:        item.Type,
+				Source:      item.Source,
+			}
+			if len(item.SupportedAgents) > 0 {
+				report.Dropped = append(report.Dropped, fmt.Sprintf("%d supported agent(s)", len(item.SupportedAgents)))
+			}
+			if len(item.SupportedAgentGroups)+len(item.Groups) > 0 {
+				report.Dropped = append(report.Dropped, fmt.Sprintf("%d group restriction(s)", len(item.SupportedAgentGroups)+len(item.Groups)))
+			}
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				fmt.Fprintf(out, "Would copy knowledge item %s to %s\n", item.Name, toOrg)
+				report.print(out, toOrg)
+				return nil
+			}
+
+			created, err := kubiya.NewClient(targetCfg).CreateKnowledge(cmd.Context(), copied)
+			if err != nil {
+				return fmt.Errorf("failed to create knowledge item in %s: %w", toOrg, err)
+			}
+
+			fmt.Fprintf(out, "%s Copied knowledge item %s to %s\n",
+				style.SuccessStyle.Render("✓"),
+				style.HighlightStyle.Render(item.Name),
+				style.HighlightStyle.Render(toOrg))
+			fmt.Fprintf(out, "  🔁 knowledge: %s → %s\n", sourceUUID, created.UUID)
+			report.print(out, toOrg)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&toOrg, "to-org", "", "Organization (or context) to copy the knowledge item to")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be copied without creating anything")
+	_ = cmd.MarkFlagRequired("to-org")
+
+	return cmd
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+)
+
+func TestBuildAgentCopyReplacesCredentials(t *testing.T) {
+	desc := "deploys things"
+	team := "team-1"
+	agent := &entities.Agent{
+		ID:          "agent-1",
+		Name:        "devops",
+		Description: &desc,
+		Runtime:     entities.RuntimeClaudeCode,
+		TeamID:      &team,
+		Configuration: map[string]interface{}{
+			"region": "us-east-1",
+			"github": map[string]interface{}{
+				"api_token": "ghp_secret",
+				"org":       "acme",
+			},
+		},
+		EnvironmentIDs: []string{"env-1"},
+		ExecutionEnvironment: &entities.ExecutionEnvironment{
+			EnvVars: map[string]string{
+				"LOG_LEVEL":       "debug",
+				"SLACK_BOT_TOKEN": "xoxb-123",
+			},
+			Secrets: []string{"AWS_ACCESS_KEY"},
+		},
+	}
+
+	req, report := buildAgentCopy(agent, "")
+
+	if req.Name != "devops" || req.Description != &desc {
+		t.Errorf("expected name and description to be kept, got %q", req.Name)
+	}
+	if req.Runtime == nil || *req.Runtime != entities.RuntimeClaudeCode {
+		t.Errorf("expected runtime to be kept, got %v", req.Runtime)
+	}
+	if req.TeamID != nil || req.EnvironmentIDs != nil {
+		t.Errorf("expected org-specific IDs to be left for re-mapping, got team=%v envs=%v", req.TeamID, req.EnvironmentIDs)
+	}
+
+	github := req.Configuration["github"].(map[string]interface{})
+	if github["api_token"] != copyPlaceholder || github["org"] != "acme" {
+		t.Errorf("unexpected github configuration %v", github)
+	}
+	if agent.Configuration["github"].(map[string]interface{})["api_token"] != "ghp_secret" {
+		t.Error("expected the source agent to be left untouched")
+	}
+
+	env := req.ExecutionEnvironment.EnvVars
+	if env["SLACK_BOT_TOKEN"] != copyPlaceholder || env["LOG_LEVEL"] != "debug" {
+		t.Errorf("unexpected env vars %v", env)
+	}
+	if !reflect.DeepEqual(req.ExecutionEnvironment.Secrets, []string{"AWS_ACCESS_KEY"}) {
+		t.Errorf("expected secret references to be kept, got %v", req.ExecutionEnvironment.Secrets)
+	}
+
+	want := []string{"configuration.github.api_token", "env_vars.SLACK_BOT_TOKEN"}
+	if !reflect.DeepEqual(report.Placeholders, want) {
+		t.Errorf("expected placeholders %v, got %v", want, report.Placeholders)
+	}
+}
+
+func TestBuildAgentCopyRename(t *testing.T) {
+	req, _ := buildAgentCopy(&entities.Agent{Name: "devops"}, "devops-staging")
+	if req.Name != "devops-staging" {
+		t.Errorf("expected renamed copy, got %q", req.Name)
+	}
+	if req.ExecutionEnvironment != nil || req.Configuration != nil {
+		t.Errorf("expected empty fields to stay empty, got %+v", req)
+	}
+}
+
+func TestConfigForTargetUsesTargetAPI(t *testing.T) {
+	sourceCalls := 0
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sourceCalls++
+		w.Write([]byte(`[]`))
+	}))
+	defer source.Close()
+
+	var targetAuth string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targetAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`[{"name":"AWS_ACCESS_KEY"}]`))
+	}))
+	defer target.Close()
+
+	cfg := &config.Config{APIKey: "source-key", Org: "acme", BaseURL: source.URL}
+	targetCfg := configForTarget(cfg, &kubiyacontext.OrganizationTarget{
+		Name: "acme-staging", ID: "acme-staging", APIURL: target.URL, Token: "target-key",
+	})
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	missing := missingSecrets(cmd, targetCfg, []string{"AWS_ACCESS_KEY", "GITHUB_TOKEN"})
+
+	if !reflect.DeepEqual(missing, []string{"GITHUB_TOKEN"}) {
+		t.Errorf("expected only GITHUB_TOKEN to be missing, got %v", missing)
+	}
+	if sourceCalls != 0 {
+		t.Errorf("expected no request to the source organization, got %d", sourceCalls)
+	}
+	if !strings.Contains(targetAuth, "target-key") {
+		t.Errorf("expected the target token to be sent to the target, got %q", targetAuth)
+	}
+	if cfg.APIKey != "source-key" || cfg.BaseURL != source.URL {
+		t.Errorf("expected the source config to be left untouched, got %+v", cfg)
+	}
+
+	// Organizations without an API URL are on the same API
+	sameAPI := configForTarget(cfg, &kubiyacontext.OrganizationTarget{ID: "acme-dev", Token: "dev-key"})
+	if sameAPI.BaseURL != source.URL || sameAPI.APIKey != "dev-key" {
+		t.Errorf("expected the source API with the target token, got %+v", sameAPI)
+	}
+}
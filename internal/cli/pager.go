@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// pagedAnnotation marks commands whose output goes through $PAGER when it is
+// taller than the terminal
+const pagedAnnotation = "kubiya/paged"
+
+// paged marks cmd for paging and returns it
+func paged(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[pagedAnnotation] = "true"
+	return cmd
+}
+
+// shouldPage reports whether the output of cmd should be paged. Streaming
+// output (--follow, --watch) is never paged.
+func shouldPage(cmd *cobra.Command, noPager bool) bool {
+	if noPager || cmd.Annotations[pagedAnnotation] == "" {
+		return false
+	}
+	for _, name := range []string{"follow", "watch"} {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() == "true" {
+			return false
+		}
+	}
+	return true
+}
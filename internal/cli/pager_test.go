@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldPage(t *testing.T) {
+	plain := &cobra.Command{Use: "list"}
+	assert.False(t, shouldPage(plain, false))
+
+	cmd := paged(&cobra.Command{Use: "get"})
+	cmd.Flags().Bool("follow", false, "")
+	assert.True(t, shouldPage(cmd, false))
+	assert.False(t, shouldPage(cmd, true), "--no-pager disables paging")
+
+	_ = cmd.Flags().Set("follow", "true")
+	assert.False(t, shouldPage(cmd, false), "streaming output is not paged")
+}
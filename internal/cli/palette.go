@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// paletteEntry is an action of the command palette
+type paletteEntry struct {
+	Title       string
+	Description string
+	// Args is the command line run when the entry is chosen
+	Args []string
+	// NeedsArgs entries are completed into the input instead of run
+	NeedsArgs bool
+}
+
+// paletteAvailable reports whether the command palette can take over the
+// terminal
+func paletteAvailable() bool {
+	return !output.IsCI() && output.IsTTY() && isatty.IsTerminal(os.Stdin.Fd())
+}
+
+// buildPaletteEntries lists the recently used command lines followed by the
+// available commands of root
+func buildPaletteEntries(root *cobra.Command, history []historyEntry) []paletteEntry {
+	var entries []paletteEntry
+	seen := make(map[string]bool)
+
+	for _, h := range history {
+		// Skip command lines of commands that no longer exist
+		if c, _, err := root.Find(h.Args); err != nil || c == root {
+			continue
+		}
+		line := strings.Join(h.Args, " ")
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+		entries = append(entries, paletteEntry{
+			Title:       "kubiya " + line,
+			Description: fmt.Sprintf("🕘 used %d×, %s", h.Count, formatDate(h.LastUsed.Format(time.RFC3339))),
+			Args:        h.Args,
+		})
+	}
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			if !sub.IsAvailableCommand() || sub.Name() == "completion" {
+				continue
+			}
+			if sub.Runnable() {
+				path := strings.TrimPrefix(sub.CommandPath(), root.Name()+" ")
+				needsArgs := sub.ValidateArgs(nil) != nil || hasRequiredFlags(sub)
+				title := "kubiya " + path
+				if needsArgs {
+					title = "kubiya " + strings.TrimPrefix(sub.UseLine(), root.Name()+" ")
+				}
+				if !seen[path] {
+					entries = append(entries, paletteEntry{
+						Title:       title,
+						Description: sub.Short,
+						Args:        strings.Fields(path),
+						NeedsArgs:   needsArgs,
+					})
+				}
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+	return entries
+}
+
+// hasRequiredFlags reports whether cmd has flags marked as required
+func hasRequiredFlags(cmd *cobra.Command) bool {
+	required := false
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[cobra.BashCompOneRequiredFlag]; ok {
+			required = true
+		}
+	})
+	return required
+}
+
+// filterPalette returns the entries matching query, best match first:
+// titles containing the query, then fuzzy matches of the title and finally
+// fuzzy matches of the description
+func filterPalette(entries []paletteEntry, query string) []paletteEntry {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return entries
+	}
+
+	type match struct {
+		tier, distance, index int
+	}
+	var matches []match
+	lower := strings.ToLower(query)
+	for i, e := range entries {
+		switch {
+		case strings.Contains(strings.ToLower(e.Title), lower):
+			matches = append(matches, match{0, len(e.Title), i})
+		case fuzzy.MatchFold(query, e.Title):
+			matches = append(matches, match{1, fuzzy.RankMatchFold(query, e.Title), i})
+		case fuzzy.MatchFold(query, e.Title+" "+e.Description):
+			matches = append(matches, match{2, fuzzy.RankMatchFold(query, e.Title+" "+e.Description), i})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].tier != matches[j].tier {
+			return matches[i].tier < matches[j].tier
+		}
+		return matches[i].distance < matches[j].distance
+	})
+
+	result := make([]paletteEntry, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, entries[m.index])
+	}
+	return result
+}
+
+// typedCommand returns the input as a command line when it starts with the
+// path of a runnable command, e.g. "agent get abc-123"
+func typedCommand(root *cobra.Command, input string) []string {
+	fields := strings.Fields(input)
+	if len(fields) > 0 && fields[0] == root.Name() {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	c, rest, err := root.Find(fields)
+	if err != nil || c == root || !c.Runnable() {
+		return nil
+	}
+
+	// Wait for the arguments of e.g. a completed "agent get "; with flags
+	// the arguments can't be told apart from flag values, so leave it to
+	// the command
+	var positional []string
+	for _, arg := range rest {
+		if strings.HasPrefix(arg, "-") {
+			return fields
+		}
+		positional = append(positional, arg)
+	}
+	if c.ValidateArgs(positional) != nil {
+		return nil
+	}
+	return fields
+}
+
+type paletteModel struct {
+	root     *cobra.Command
+	input    textinput.Model
+	entries  []paletteEntry
+	matches  []paletteEntry
+	cursor   int
+	height   int
+	selected []string
+}
+
+func newPaletteModel(root *cobra.Command, entries []paletteEntry) paletteModel {
+	input := textinput.New()
+	input.Prompt = "› "
+	input.Placeholder = "Type to search commands, Tab to complete"
+	input.Focus()
+
+	return paletteModel{
+		root:    root,
+		input:   input,
+		entries: entries,
+		matches: entries,
+		height:  20,
+	}
+}
+
+func (m paletteModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m paletteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+
+		case "up", "ctrl+p", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+
+		case "down", "ctrl+n", "ctrl+j":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil
+
+		case "tab":
+			m.complete()
+			return m, nil
+
+		case "enter":
+			if args := typedCommand(m.root, m.input.Value()); args != nil {
+				m.selected = args
+				return m, tea.Quit
+			}
+			if m.cursor < len(m.matches) {
+				if m.matches[m.cursor].NeedsArgs {
+					m.complete()
+					return m, nil
+				}
+				m.selected = m.matches[m.cursor].Args
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	previous := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != previous {
+		m.matches = filterPalette(m.entries, m.input.Value())
+		m.cursor = 0
+	}
+	return m, cmd
+}
+
+// complete replaces the input with the command line of the current entry so
+// arguments can be added
+func (m *paletteModel) complete() {
+	if m.cursor >= len(m.matches) {
+		return
+	}
+	m.input.SetValue(strings.Join(m.matches[m.cursor].Args, " ") + " ")
+	m.input.CursorEnd()
+	m.matches = filterPalette(m.entries, m.input.Value())
+	m.cursor = 0
+}
+
+func (m paletteModel) View() string {
+	var b strings.Builder
+	b.WriteString(style.TitleStyle.Render("🔎 Kubiya command palette") + "\n")
+	b.WriteString(style.DimStyle.Render("↑/↓ move • tab complete • enter run • esc quit") + "\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	if len(m.matches) == 0 {
+		b.WriteString(style.DimStyle.Render("  No matching commands") + "\n")
+		return b.String()
+	}
+
+	// Keep the cursor visible in the space left by the header
+	visible := m.height - 6
+	if visible < 3 {
+		visible = 3
+	}
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := start + visible
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+
+	for i := start; i < end; i++ {
+		e := m.matches[i]
+		if i == m.cursor {
+			b.WriteString(style.HighlightStyle.Render("> "+e.Title) + "  " + style.DimStyle.Render(e.Description) + "\n")
+		} else {
+			b.WriteString("  " + e.Title + "  " + style.DimStyle.Render(e.Description) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// runCommandPalette shows the palette and returns the chosen command line,
+// or nil when it was dismissed
+func runCommandPalette(root *cobra.Command) ([]string, error) {
+	var history []historyEntry
+	if h, err := newCommandHistory(); err == nil {
+		history, _ = h.List()
+	}
+
+	model := newPaletteModel(root, buildPaletteEntries(root, history))
+	final, err := tea.NewProgram(model).Run()
+	if err != nil {
+		return nil, fmt.Errorf("command palette failed: %w", err)
+	}
+	return final.(paletteModel).selected, nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPaletteTestRoot() *cobra.Command {
+	noop := func(cmd *cobra.Command, args []string) error { return nil }
+	root := &cobra.Command{Use: "kubiya", RunE: noop}
+	agent := &cobra.Command{Use: "agent", Short: "Manage agents"}
+	agent.AddCommand(
+		&cobra.Command{Use: "list", Short: "List agents", RunE: noop},
+		&cobra.Command{Use: "get [uuid]", Short: "Get agent details", Args: cobra.ExactArgs(1), RunE: noop},
+	)
+	worker := &cobra.Command{Use: "worker", Short: "Manage workers"}
+	status := &cobra.Command{Use: "status", Short: "Check worker status", RunE: noop}
+	status.Flags().String("queue-id", "", "")
+	_ = status.MarkFlagRequired("queue-id")
+	worker.AddCommand(status)
+	root.AddCommand(agent, worker, &cobra.Command{Use: "old", Hidden: true, RunE: noop})
+	return root
+}
+
+func TestCommandHistoryRecord(t *testing.T) {
+	h := &commandHistory{path: filepath.Join(t.TempDir(), "command-history.json")}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, h.Record([]string{"agent", "list"}, now))
+	require.NoError(t, h.Record([]string{"agent", "get", "abc"}, now.Add(time.Minute)))
+	require.NoError(t, h.Record([]string{"agent", "list"}, now.Add(2*time.Minute)))
+
+	entries, err := h.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{"agent", "list"}, entries[0].Args)
+	assert.Equal(t, 2, entries[0].Count)
+	assert.Equal(t, []string{"agent", "get", "abc"}, entries[1].Args)
+}
+
+func TestShouldRecordCommand(t *testing.T) {
+	root := newPaletteTestRoot()
+	list, _, _ := root.Find([]string{"agent", "list"})
+
+	assert.True(t, shouldRecordCommand(list, []string{"agent", "list"}))
+	assert.False(t, shouldRecordCommand(list, []string{"agent", "list", "--api-key", "x"}))
+	assert.False(t, shouldRecordCommand(list, []string{"agent", "list", "--token=x"}))
+	assert.False(t, shouldRecordCommand(root, nil))
+
+	secret := &cobra.Command{Use: "secret"}
+	create := &cobra.Command{Use: "create"}
+	secret.AddCommand(create)
+	root.AddCommand(secret)
+	assert.False(t, shouldRecordCommand(create, []string{"secret", "create", "name", "value"}))
+}
+
+func TestBuildPaletteEntries(t *testing.T) {
+	root := newPaletteTestRoot()
+	history := []historyEntry{
+		{Args: []string{"agent", "get", "abc"}, Count: 3, LastUsed: time.Now()},
+		{Args: []string{"removed", "command"}, Count: 1, LastUsed: time.Now()},
+		{Args: []string{"agent", "list"}, Count: 1, LastUsed: time.Now()},
+	}
+
+	entries := buildPaletteEntries(root, history)
+	var titles []string
+	for _, e := range entries {
+		titles = append(titles, e.Title)
+	}
+	assert.Equal(t, []string{
+		"kubiya agent get abc",
+		"kubiya agent list",
+		"kubiya agent get [uuid]",
+		"kubiya worker status [flags]",
+	}, titles)
+	assert.True(t, entries[2].NeedsArgs)
+	assert.True(t, entries[3].NeedsArgs, "required flags need completing")
+}
+
+func TestFilterPalette(t *testing.T) {
+	entries := buildPaletteEntries(newPaletteTestRoot(), nil)
+
+	assert.Len(t, filterPalette(entries, ""), len(entries))
+	matches := filterPalette(entries, "wrk stat")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, []string{"worker", "status"}, matches[0].Args)
+	assert.Empty(t, filterPalette(entries, "zzz"))
+}
+
+func TestTypedCommand(t *testing.T) {
+	root := newPaletteTestRoot()
+
+	assert.Equal(t, []string{"agent", "list"}, typedCommand(root, "agent list"))
+	assert.Equal(t, []string{"agent", "get", "abc"}, typedCommand(root, "kubiya agent get abc"))
+	assert.Nil(t, typedCommand(root, "agent get "), "waits for the argument")
+	assert.Nil(t, typedCommand(root, "agent"), "groups are not runnable")
+	assert.Nil(t, typedCommand(root, "agls"))
+}
+
+func TestPaletteModelCompletesArguments(t *testing.T) {
+	root := newPaletteTestRoot()
+	m := newPaletteModel(root, buildPaletteEntries(root, nil))
+
+	for _, r := range "agent get" {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = next.(paletteModel)
+	}
+	require.NotEmpty(t, m.matches)
+	require.Equal(t, []string{"agent", "get"}, m.matches[0].Args)
+
+	// Enter on a command that needs arguments completes it
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(paletteModel)
+	assert.Equal(t, "agent get ", m.input.Value())
+	assert.Nil(t, m.selected)
+
+	for _, r := range "abc" {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = next.(paletteModel)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(paletteModel)
+	assert.Equal(t, []string{"agent", "get", "abc"}, m.selected)
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -19,7 +18,7 @@ func newPolicyCommand(cfg *config.Config) *cobra.Command {
 		Use:     "policy",
 		Aliases: []string{"policies", "pol"},
 		Short:   "🛡️  Manage OPA policies for access control",
-		Long:    `Create, update, validate, and manage Open Policy Agent (OPA) policies.`,
+		Long:    `Create, update, test, and manage Open Policy Agent (OPA) policies.`,
 	}
 
 	cmd.AddCommand(
@@ -28,6 +27,8 @@ func newPolicyCommand(cfg *config.Config) *cobra.Command {
 		newCreatePolicyCommand(cfg),
 		newUpdatePolicyCommand(cfg),
 		newDeletePolicyCommand(cfg),
+		newPutPolicyCommand(cfg),
+		newTestPolicyCommand(cfg),
 	)
 
 	return cmd
@@ -57,7 +58,7 @@ func newListPoliciesCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(policies)
+				return printJSONList(policies)
 			}
 
 			if len(policies) == 0 {
@@ -154,7 +155,7 @@ func newGetPolicyCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(policy)
+				return printJSON(policy)
 			}
 
 			// Display policy details
@@ -259,7 +260,7 @@ func newCreatePolicyCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(policy)
+				return printJSON(policy)
 			}
 
 			fmt.Printf("\n%s\n\n", style.TitleStyle.Render("✅ Policy created successfully!"))
@@ -351,7 +352,7 @@ func newUpdatePolicyCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(policy)
+				return printJSON(policy)
 			}
 
 			fmt.Printf("\n%s\n\n", style.HighlightStyle.Render("✅ Policy updated successfully!"))
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// toolPolicyPackageRe matches the package declaration of a policy deciding
+// on tool executions, i.e. of package tools or of one of its subpackages
+var toolPolicyPackageRe = regexp.MustCompile(`(?m)^\s*package\s+tools(\.|\s|$)`)
+
+// toolPolicyEvaluator is the part of kubiya.Client used to test policies
+type toolPolicyEvaluator interface {
+	ExplainToolExecution(ctx context.Context, toolName string, args map[string]interface{}, runner, user string) (*kubiya.ToolPolicyDecision, error)
+	ListPolicies(ctx context.Context) ([]kubiya.Policy, error)
+}
+
+// toolPolicyReport explains the decision on a tool call
+type toolPolicyReport struct {
+	Tool          string   `json:"tool"`
+	User          string   `json:"user,omitempty"`
+	Runner        string   `json:"runner,omitempty"`
+	Policies      []string `json:"policies"`
+	PoliciesError string   `json:"policies_error,omitempty"`
+	*kubiya.ToolPolicyDecision
+}
+
+func newTestPolicyCommand(cfg *config.Config) *cobra.Command {
+	var (
+		toolName     string
+		argsJSON     string
+		user         string
+		runner       string
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "🧪 Test whether a tool call is allowed by the policies",
+		Long: `Evaluate the tool execution policies on a tool call, as done before a tool
+is executed, and explain the decision.
+
+The call is described by the tool, its arguments as a JSON object, the user
+running it and the runner. The policies see them as input.tool_name,
+input.args, input.user.email (or input.user.id) and input.runner. Their
+package, tools, is evaluated in full so that the decision comes with the
+messages of the rules: message, reason, reasons, deny and violations.
+
+The policies declaring the tools package are listed, and the input is
+printed, to tell why a tool call is denied.`,
+		Example: `  # Why can't bob delete pods?
+  kubiya policy test --tool kubectl --args '{"command":"delete pod"}' --user bob@corp.com
+
+  # On a given runner
+  kubiya policy test --tool terraform --args '{"command":"apply"}' --runner prod-runner
+
+  # Output in JSON format
+  kubiya policy test --tool kubectl --args '{"command":"get pods"}' --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			toolArgs := map[string]interface{}{}
+			if argsJSON != "" {
+				if err := json.Unmarshal([]byte(argsJSON), &toolArgs); err != nil {
+					return fmt.Errorf("invalid --args, expected a JSON object: %w", err)
+				}
+			}
+
+			report, err := testToolPolicy(cmd.Context(), kubiya.NewClient(cfg), toolName, toolArgs, runner, user)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, report)
+			}
+			printToolPolicyReport(out, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&toolName, "tool", "", "Name of the tool (required)")
+	cmd.Flags().StringVar(&argsJSON, "args", "", "Arguments of the tool call as a JSON object")
+	cmd.Flags().StringVar(&user, "user", "", "User running the tool, by email or user ID")
+	cmd.Flags().StringVar(&runner, "runner", "", "Runner the tool runs on")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("tool")
+
+	return cmd
+}
+
+// testToolPolicy evaluates the policies on a tool call. Failing to list the
+// policies is reported rather than returned, the decision is what matters.
+func testToolPolicy(ctx context.Context, client toolPolicyEvaluator, toolName string, args map[string]interface{}, runner, user string) (*toolPolicyReport, error) {
+	decision, err := client.ExplainToolExecution(ctx, toolName, args, runner, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate policies: %w", err)
+	}
+	report := &toolPolicyReport{
+		Tool:               toolName,
+		User:               user,
+		Runner:             runner,
+		Policies:           []string{},
+		ToolPolicyDecision: decision,
+	}
+
+	policies, err := client.ListPolicies(ctx)
+	if err != nil {
+		report.PoliciesError = err.Error()
+		return report, nil
+	}
+	for _, p := range policies {
+		if toolPolicyPackageRe.MatchString(p.Policy) {
+			report.Policies = append(report.Policies, p.Name)
+		}
+	}
+	return report, nil
+}
+
+func printToolPolicyReport(w io.Writer, report *toolPolicyReport) {
+	if report.Allowed {
+		fmt.Fprintf(w, "%s Tool call %s\n\n", style.SuccessStyle.Render("✅"), style.SuccessStyle.Render("ALLOWED"))
+	} else {
+		fmt.Fprintf(w, "%s Tool call %s\n\n", style.ErrorStyle.Render("❌"), style.ErrorStyle.Render("DENIED"))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Tool:\t%s\n", style.HighlightStyle.Render(report.Tool))
+	fmt.Fprintf(tw, "User:\t%s\n", firstNonEmpty(report.User, "-"))
+	fmt.Fprintf(tw, "Runner:\t%s\n", firstNonEmpty(report.Runner, "-"))
+	fmt.Fprintf(tw, "Query:\t%s\n", report.Query)
+	switch {
+	case report.PoliciesError != "":
+		fmt.Fprintf(tw, "Policies:\t%s\n", style.DimStyle.Render("unknown, failed to list policies: "+report.PoliciesError))
+	case len(report.Policies) == 0:
+		fmt.Fprintf(tw, "Policies:\t%s\n", style.DimStyle.Render("none declares package tools"))
+	default:
+		fmt.Fprintf(tw, "Policies:\t%s\n", strings.Join(report.Policies, ", "))
+	}
+	tw.Flush()
+
+	if len(report.Reasons) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.SubtitleStyle.Render("Reasons:"))
+		for _, reason := range report.Reasons {
+			fmt.Fprintf(w, "  %s %s\n", style.DimStyle.Render("›"), reason)
+		}
+	}
+
+	var input bytes.Buffer
+	if err := output.EncodeJSON(&input, report.Input); err == nil {
+		fmt.Fprintf(w, "\n%s\n%s", style.SubtitleStyle.Render("Input:"), input.String())
+	}
+
+	if enforce := os.Getenv("KUBIYA_OPA_ENFORCE"); enforce != "true" && enforce != "1" {
+		fmt.Fprintf(w, "\n%s\n", style.DimStyle.Render("Note: kubiya tool exec only enforces policies when KUBIYA_OPA_ENFORCE=true"))
+	}
+}
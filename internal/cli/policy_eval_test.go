@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeToolPolicyEvaluator struct {
+	decision *kubiya.ToolPolicyDecision
+	policies []kubiya.Policy
+	listErr  error
+}
+
+func (f *fakeToolPolicyEvaluator) ExplainToolExecution(ctx context.Context, toolName string, args map[string]interface{}, runner, user string) (*kubiya.ToolPolicyDecision, error) {
+	return f.decision, nil
+}
+
+func (f *fakeToolPolicyEvaluator) ListPolicies(ctx context.Context) ([]kubiya.Policy, error) {
+	return f.policies, f.listErr
+}
+
+func TestTestToolPolicy(t *testing.T) {
+	client := &fakeToolPolicyEvaluator{
+		decision: &kubiya.ToolPolicyDecision{
+			Reasons: []string{"deleting pods requires the sre group"},
+			Query:   "data.tools",
+			Input:   map[string]interface{}{"tool_name": "kubectl"},
+		},
+		policies: []kubiya.Policy{
+			{Name: "kubectl-guard", Policy: "# guards kubectl\npackage tools\n\nallow { true }"},
+			{Name: "prod", Policy: "package tools.prod\ndeny[msg] { msg := \"frozen\" }"},
+			{Name: "workflows", Policy: "package workflows\nallow = true"},
+			{Name: "toolsmith", Policy: "package toolsmith"},
+		},
+	}
+
+	report, err := testToolPolicy(context.Background(), client, "kubectl", nil, "", "bob@corp.com")
+	require.NoError(t, err)
+	assert.False(t, report.Allowed)
+	assert.Equal(t, []string{"kubectl-guard", "prod"}, report.Policies)
+
+	var out bytes.Buffer
+	printToolPolicyReport(&out, report)
+	assert.Contains(t, out.String(), "DENIED")
+	assert.Contains(t, out.String(), "bob@corp.com")
+	assert.Contains(t, out.String(), "kubectl-guard, prod")
+	assert.Contains(t, out.String(), "deleting pods requires the sre group")
+	assert.Contains(t, out.String(), `"tool_name": "kubectl"`)
+
+	// The decision is still explained when the policies cannot be listed
+	client.listErr = errors.New("403 forbidden")
+	report, err = testToolPolicy(context.Background(), client, "kubectl", nil, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "403 forbidden", report.PoliciesError)
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Outcomes of putting a policy
+const (
+	policyCreated   = "created"
+	policyUpdated   = "updated"
+	policyUnchanged = "unchanged"
+)
+
+// policyStore is the part of controlplane.Client used to put policies
+type policyStore interface {
+	ListPolicies() ([]*entities.Policy, error)
+	CreatePolicy(req *entities.PolicyCreateRequest) (*entities.Policy, error)
+	UpdatePolicy(id string, req *entities.PolicyUpdateRequest) (*entities.Policy, error)
+}
+
+// putPolicyOptions describes the policy to create or update
+type putPolicyOptions struct {
+	Name        string
+	Description *string // kept as is when nil
+	Rego        string
+	Enabled     *bool // enabled on creation and kept on update when nil
+}
+
+// putPolicyResult is printed after putting a policy
+type putPolicyResult struct {
+	Status string           `json:"status"`
+	Policy *entities.Policy `json:"policy"`
+}
+
+func newPutPolicyCommand(cfg *config.Config) *cobra.Command {
+	var (
+		name         string
+		description  string
+		regoFile     string
+		enabled      bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "put",
+		Short: "📥 Create or update a policy from a Rego file",
+		Long: `Create the policy named --name from a Rego file, or update it when a policy
+with that name exists. The name defaults to the name of the file without its
+extension. Putting the same file twice changes nothing, which makes put
+suited to keeping policies in git and applying them from CI.`,
+		Example: `  # Create or update the policy "tools" from tools.rego
+  kubiya policy put --file tools.rego
+
+  # Name the policy and describe it
+  kubiya policy put --name "Production tools" --file prod.rego --description "Guards kubectl in prod"
+
+  # Update the policy and disable it
+  kubiya policy put --file tools.rego --enabled=false`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			rego, err := os.ReadFile(regoFile)
+			if err != nil {
+				return fmt.Errorf("failed to read rego file: %w", err)
+			}
+			opts := putPolicyOptions{Name: name, Rego: string(rego)}
+			if opts.Name == "" {
+				opts.Name = strings.TrimSuffix(filepath.Base(regoFile), filepath.Ext(regoFile))
+			}
+			if cmd.Flags().Changed("description") {
+				opts.Description = &description
+			}
+			if cmd.Flags().Changed("enabled") {
+				opts.Enabled = &enabled
+			}
+
+			client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+			result, err := putPolicy(client, opts)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, result)
+			}
+			printPutPolicy(out, result)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Policy name (default: the file name without extension)")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "Policy description")
+	cmd.Flags().StringVarP(&regoFile, "file", "f", "", "Path to Rego policy file (required)")
+	cmd.Flags().BoolVar(&enabled, "enabled", true, "Enable or disable the policy")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// putPolicy creates the policy, or updates the policy having its name. Only
+// the fields that differ are updated, and nothing when none does.
+func putPolicy(store policyStore, opts putPolicyOptions) (*putPolicyResult, error) {
+	policies, err := store.ListPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	var existing []*entities.Policy
+	for _, p := range policies {
+		if p.Name == opts.Name {
+			existing = append(existing, p)
+		}
+	}
+
+	switch len(existing) {
+	case 0:
+		enabled := true
+		if opts.Enabled != nil {
+			enabled = *opts.Enabled
+		}
+		policy, err := store.CreatePolicy(&entities.PolicyCreateRequest{
+			Name:        opts.Name,
+			Description: opts.Description,
+			Rego:        opts.Rego,
+			Enabled:     &enabled,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create policy: %w", err)
+		}
+		return &putPolicyResult{Status: policyCreated, Policy: policy}, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d policies are named %q, update one by ID with kubiya policy update", len(existing), opts.Name)
+	}
+
+	current := existing[0]
+	req := &entities.PolicyUpdateRequest{}
+	changed := false
+	if current.Rego != opts.Rego {
+		req.Rego = &opts.Rego
+		changed = true
+	}
+	if opts.Description != nil && (current.Description == nil || *current.Description != *opts.Description) {
+		req.Description = opts.Description
+		changed = true
+	}
+	if opts.Enabled != nil && current.Enabled != *opts.Enabled {
+		req.Enabled = opts.Enabled
+		changed = true
+	}
+	if !changed {
+		return &putPolicyResult{Status: policyUnchanged, Policy: current}, nil
+	}
+
+	policy, err := store.UpdatePolicy(current.ID, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update policy: %w", err)
+	}
+	return &putPolicyResult{Status: policyUpdated, Policy: policy}, nil
+}
+
+func printPutPolicy(w io.Writer, result *putPolicyResult) {
+	p := result.Policy
+	switch result.Status {
+	case policyCreated:
+		fmt.Fprintf(w, "%s Policy %s created (%s)\n", style.SuccessStyle.Render("✅"), style.HighlightStyle.Render(p.Name), p.ID)
+	case policyUpdated:
+		fmt.Fprintf(w, "%s Policy %s updated (%s)\n", style.SuccessStyle.Render("✅"), style.HighlightStyle.Render(p.Name), p.ID)
+	default:
+		fmt.Fprintf(w, "%s Policy %s is up to date (%s)\n", style.InfoStyle.Render("ℹ️"), style.HighlightStyle.Render(p.Name), p.ID)
+	}
+	if !p.Enabled {
+		fmt.Fprintf(w, "%s The policy is disabled\n", style.WarningStyle.Render("⚠️"))
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+)
+
+type fakePolicyStore struct {
+	policies []*entities.Policy
+	created  []*entities.PolicyCreateRequest
+	updated  map[string]*entities.PolicyUpdateRequest
+}
+
+func (f *fakePolicyStore) ListPolicies() ([]*entities.Policy, error) {
+	return f.policies, nil
+}
+
+func (f *fakePolicyStore) CreatePolicy(req *entities.PolicyCreateRequest) (*entities.Policy, error) {
+	f.created = append(f.created, req)
+	return &entities.Policy{ID: "new", Name: req.Name, Rego: req.Rego, Enabled: *req.Enabled}, nil
+}
+
+func (f *fakePolicyStore) UpdatePolicy(id string, req *entities.PolicyUpdateRequest) (*entities.Policy, error) {
+	if f.updated == nil {
+		f.updated = map[string]*entities.PolicyUpdateRequest{}
+	}
+	f.updated[id] = req
+	return &entities.Policy{ID: id, Name: "tools", Rego: *req.Rego, Enabled: true}, nil
+}
+
+func TestPutPolicy(t *testing.T) {
+	desc := "guards kubectl"
+	store := &fakePolicyStore{policies: []*entities.Policy{
+		{ID: "p-1", Name: "tools", Rego: "package tools\nallow = true", Description: &desc, Enabled: true},
+		{ID: "p-2", Name: "dup", Rego: "package a"},
+		{ID: "p-3", Name: "dup", Rego: "package b"},
+	}}
+
+	result, err := putPolicy(store, putPolicyOptions{Name: "tools", Rego: "package tools\nallow = true", Description: &desc})
+	require.NoError(t, err)
+	assert.Equal(t, policyUnchanged, result.Status)
+	assert.Empty(t, store.updated)
+
+	result, err = putPolicy(store, putPolicyOptions{Name: "tools", Rego: "package tools\nallow = false"})
+	require.NoError(t, err)
+	assert.Equal(t, policyUpdated, result.Status)
+	require.Contains(t, store.updated, "p-1")
+	assert.Equal(t, "package tools\nallow = false", *store.updated["p-1"].Rego)
+	assert.Nil(t, store.updated["p-1"].Description, "unchanged fields are not sent")
+	assert.Nil(t, store.updated["p-1"].Enabled)
+
+	result, err = putPolicy(store, putPolicyOptions{Name: "runners", Rego: "package runners"})
+	require.NoError(t, err)
+	assert.Equal(t, policyCreated, result.Status)
+	require.Len(t, store.created, 1)
+	assert.True(t, *store.created[0].Enabled, "new policies are enabled by default")
+
+	_, err = putPolicy(store, putPolicyOptions{Name: "dup", Rego: "package c"})
+	assert.ErrorContains(t, err, `2 policies are named "dup"`)
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -57,7 +56,7 @@ func newListProjectsCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(projects)
+				return printJSONList(projects)
 			}
 
 			if len(projects) == 0 {
@@ -146,7 +145,7 @@ func newGetProjectCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(project)
+				return printJSON(project)
 			}
 
 			// Display project details
@@ -222,7 +221,7 @@ func newCreateProjectCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(project)
+				return printJSON(project)
 			}
 
 			fmt.Printf("\n%s\n\n", style.TitleStyle.Render("✅ Project created successfully!"))
@@ -290,7 +289,7 @@ func newUpdateProjectCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			if outputFormat == "json" {
-				return json.NewEncoder(os.Stdout).Encode(project)
+				return printJSON(project)
 			}
 
 			fmt.Printf("\n%s\n\n", style.HighlightStyle.Render("✅ Project updated successfully!"))
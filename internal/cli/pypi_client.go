@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // PyPIPackageInfo represents the structure of PyPI JSON API response
@@ -59,7 +61,7 @@ func GetLatestPackageVersionWithTimeout(packageName string, timeout time.Duratio
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	client := &http.Client{}
+	client := httpclient.Default()
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to query PyPI: %w", err)
@@ -102,7 +104,7 @@ func checkPyPIAvailable() bool {
 		return false
 	}
 
-	client := &http.Client{}
+	client := httpclient.Default()
 	resp, err := client.Do(req)
 	if err != nil {
 		return false
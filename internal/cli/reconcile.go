@@ -0,0 +1,518 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest kinds reconciled by `kubiya serve --reconcile`. Sources are
+// reconciled first and webhooks last so that agents can reference sources
+// and webhooks can reference agents by name.
+const (
+	manifestKindSource  = "Source"
+	manifestKindAgent   = "Agent"
+	manifestKindWebhook = "Webhook"
+)
+
+var manifestKindOrder = []string{manifestKindSource, manifestKindAgent, manifestKindWebhook}
+
+// Reconcile actions reported per resource
+const (
+	reconcileCreated   = "created"
+	reconcileUpdated   = "updated"
+	reconcileUnchanged = "unchanged"
+	reconcileFailed    = "failed"
+)
+
+// manifest is a declarative resource:
+//
+//	kind: Agent
+//	name: k8s-helper
+//	spec:
+//	  description: Helps with Kubernetes
+//	  sources: [k8s-tools]
+//
+// The spec uses the field names of the API. Agents may list sources by name
+// and webhooks may reference their agent by name with "agent".
+type manifest struct {
+	Kind string                 `yaml:"kind"`
+	Name string                 `yaml:"name"`
+	Spec map[string]interface{} `yaml:"spec"`
+	file string
+}
+
+func (m manifest) String() string {
+	return fmt.Sprintf("%s/%s", m.Kind, m.Name)
+}
+
+// loadManifests reads every YAML document of the .yaml and .yml files under
+// dir, ordered by kind
+func loadManifests(dir string) ([]manifest, error) {
+	var manifests []manifest
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest: %w", err)
+		}
+		docs, err := parseManifests(data, path)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, docs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rank := map[string]int{}
+	for i, kind := range manifestKindOrder {
+		rank[kind] = i
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return rank[manifests[i].Kind] < rank[manifests[j].Kind]
+	})
+	return manifests, nil
+}
+
+// parseManifests decodes and checks the YAML documents of a manifest file
+func parseManifests(data []byte, path string) ([]manifest, error) {
+	var manifests []manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var m manifest
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+		}
+		if m.Kind == "" && m.Name == "" && m.Spec == nil {
+			continue // empty document
+		}
+		switch m.Kind {
+		case manifestKindSource, manifestKindAgent, manifestKindWebhook:
+		default:
+			return nil, fmt.Errorf("invalid manifest %s: unsupported kind %q (must be one of %s)",
+				path, m.Kind, strings.Join(manifestKindOrder, ", "))
+		}
+		if m.Name == "" {
+			return nil, fmt.Errorf("invalid manifest %s: %s has no name", path, m.Kind)
+		}
+		if m.Spec == nil {
+			m.Spec = map[string]interface{}{}
+		}
+		m.Spec["name"] = m.Name
+		m.file = path
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// reconcileClient is the part of kubiya.Client used by the reconciler
+type reconcileClient interface {
+	ListSources(ctx context.Context) ([]kubiya.Source, error)
+	CreateSource(ctx context.Context, url string, opts ...kubiya.SourceOption) (*kubiya.Source, error)
+	UpdateSource(ctx context.Context, uuid string, opts ...kubiya.SourceOption) (*kubiya.Source, error)
+	GetAgents(ctx context.Context) ([]kubiya.Agent, error)
+	CreateAgent(ctx context.Context, agent kubiya.Agent) (*kubiya.Agent, error)
+	UpdateAgent(ctx context.Context, uuid string, agent kubiya.Agent) (*kubiya.Agent, error)
+	ListWebhooks(ctx context.Context) ([]kubiya.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook kubiya.Webhook) (*kubiya.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, webhook kubiya.Webhook) (*kubiya.Webhook, error)
+}
+
+// reconcileResult is the outcome of reconciling one manifest
+type reconcileResult struct {
+	Resource string
+	Action   string
+	Err      error
+}
+
+// reconciler makes the platform match a set of manifests. Resources are
+// matched by name; resources without a manifest are left untouched.
+type reconciler struct {
+	client reconcileClient
+	dryRun bool
+
+	sources  []kubiya.Source
+	agents   []kubiya.Agent
+	webhooks []kubiya.Webhook
+}
+
+// reconcile applies manifests and returns the outcome for each of them
+func (r *reconciler) reconcile(ctx context.Context, manifests []manifest) ([]reconcileResult, error) {
+	var err error
+	if r.sources, err = r.client.ListSources(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+	if r.agents, err = r.client.GetAgents(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	if r.webhooks, err = r.client.ListWebhooks(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	results := make([]reconcileResult, 0, len(manifests))
+	for _, m := range manifests {
+		var action string
+		switch m.Kind {
+		case manifestKindSource:
+			action, err = r.reconcileSource(ctx, m)
+		case manifestKindAgent:
+			action, err = r.reconcileAgent(ctx, m)
+		case manifestKindWebhook:
+			action, err = r.reconcileWebhook(ctx, m)
+		}
+		if err != nil {
+			action = reconcileFailed
+		}
+		results = append(results, reconcileResult{Resource: m.String(), Action: action, Err: err})
+	}
+	return results, nil
+}
+
+func (r *reconciler) reconcileSource(ctx context.Context, m manifest) (string, error) {
+	var desired kubiya.Source
+	if err := decodeSpec(m.Spec, &desired); err != nil {
+		return "", err
+	}
+	opts := []kubiya.SourceOption{kubiya.WithName(desired.Name)}
+	if desired.Runner != "" {
+		opts = append(opts, kubiya.WithRunner(desired.Runner))
+	}
+	if desired.DynamicConfig != nil {
+		opts = append(opts, kubiya.WithDynamicConfig(desired.DynamicConfig))
+	}
+
+	for _, current := range r.sources {
+		if current.Name != m.Name && (desired.URL == "" || current.URL != desired.URL) {
+			continue
+		}
+		if current.Name == desired.Name &&
+			(desired.URL == "" || current.URL == desired.URL) &&
+			(desired.DynamicConfig == nil || jsonEqual(current.DynamicConfig, desired.DynamicConfig)) {
+			return reconcileUnchanged, nil
+		}
+		if desired.URL != "" {
+			opts = append(opts, kubiya.WithURL(desired.URL))
+		}
+		if r.dryRun {
+			return reconcileUpdated, nil
+		}
+		if _, err := r.client.UpdateSource(ctx, current.UUID, opts...); err != nil {
+			return "", fmt.Errorf("failed to update source: %w", err)
+		}
+		return reconcileUpdated, nil
+	}
+
+	if desired.URL == "" {
+		return "", fmt.Errorf("source %s has no url", m.Name)
+	}
+	if r.dryRun {
+		return reconcileCreated, nil
+	}
+	created, err := r.client.CreateSource(ctx, desired.URL, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create source: %w", err)
+	}
+	r.sources = append(r.sources, *created)
+	return reconcileCreated, nil
+}
+
+func (r *reconciler) reconcileAgent(ctx context.Context, m manifest) (string, error) {
+	spec := copySpec(m.Spec)
+	if sources, ok := spec["sources"].([]interface{}); ok {
+		resolved := make([]interface{}, 0, len(sources))
+		for _, s := range sources {
+			uuid, err := r.resolveSource(fmt.Sprint(s))
+			if err != nil {
+				return "", err
+			}
+			resolved = append(resolved, uuid)
+		}
+		spec["sources"] = resolved
+	}
+
+	for _, current := range r.agents {
+		if current.Name != m.Name {
+			continue
+		}
+		var merged kubiya.Agent
+		changed, err := mergeSpec(current, spec, &merged)
+		if err != nil {
+			return "", err
+		}
+		if !changed {
+			return reconcileUnchanged, nil
+		}
+		if r.dryRun {
+			return reconcileUpdated, nil
+		}
+		if _, err := r.client.UpdateAgent(ctx, current.UUID, merged); err != nil {
+			return "", fmt.Errorf("failed to update agent: %w", err)
+		}
+		return reconcileUpdated, nil
+	}
+
+	var desired kubiya.Agent
+	if err := decodeSpec(spec, &desired); err != nil {
+		return "", err
+	}
+	if r.dryRun {
+		return reconcileCreated, nil
+	}
+	created, err := r.client.CreateAgent(ctx, desired)
+	if err != nil {
+		return "", fmt.Errorf("failed to create agent: %w", err)
+	}
+	r.agents = append(r.agents, *created)
+	return reconcileCreated, nil
+}
+
+func (r *reconciler) reconcileWebhook(ctx context.Context, m manifest) (string, error) {
+	spec := copySpec(m.Spec)
+	if agent, ok := spec["agent"].(string); ok {
+		uuid, err := r.resolveAgent(agent)
+		if err != nil {
+			return "", err
+		}
+		spec["agent_id"] = uuid
+		delete(spec, "agent")
+	}
+
+	for _, current := range r.webhooks {
+		if current.Name != m.Name {
+			continue
+		}
+		var merged kubiya.Webhook
+		changed, err := mergeSpec(current, spec, &merged)
+		if err != nil {
+			return "", err
+		}
+		if !changed {
+			return reconcileUnchanged, nil
+		}
+		if r.dryRun {
+			return reconcileUpdated, nil
+		}
+		if _, err := r.client.UpdateWebhook(ctx, current.ID, merged); err != nil {
+			return "", fmt.Errorf("failed to update webhook: %w", err)
+		}
+		return reconcileUpdated, nil
+	}
+
+	var desired kubiya.Webhook
+	if err := decodeSpec(spec, &desired); err != nil {
+		return "", err
+	}
+	if r.dryRun {
+		return reconcileCreated, nil
+	}
+	created, err := r.client.CreateWebhook(ctx, desired)
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook: %w", err)
+	}
+	r.webhooks = append(r.webhooks, *created)
+	return reconcileCreated, nil
+}
+
+// resolveSource returns the UUID of the source with the given name, UUID
+// or URL
+func (r *reconciler) resolveSource(ref string) (string, error) {
+	for _, s := range r.sources {
+		if s.UUID == ref || s.Name == ref || s.URL == ref {
+			return s.UUID, nil
+		}
+	}
+	if r.dryRun {
+		return ref, nil
+	}
+	return "", fmt.Errorf("source %q not found", ref)
+}
+
+// resolveAgent returns the UUID of the agent with the given name or UUID
+func (r *reconciler) resolveAgent(ref string) (string, error) {
+	for _, a := range r.agents {
+		if a.UUID == ref || a.Name == ref {
+			return a.UUID, nil
+		}
+	}
+	if r.dryRun {
+		return ref, nil
+	}
+	return "", fmt.Errorf("agent %q not found", ref)
+}
+
+// decodeSpec converts a manifest spec to an API type through its JSON form
+func decodeSpec(spec map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("invalid spec: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid spec: %w", err)
+	}
+	return nil
+}
+
+// mergeSpec overlays spec on current, decodes the result into merged and
+// reports whether any field of spec differs from current
+func mergeSpec(current interface{}, spec map[string]interface{}, merged interface{}) (bool, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false, err
+	}
+
+	changed := false
+	for k, v := range spec {
+		if !jsonEqual(fields[k], v) {
+			changed = true
+		}
+		fields[k] = v
+	}
+	return changed, decodeSpec(fields, merged)
+}
+
+// jsonEqual compares values by their JSON representation, so that e.g. nil
+// and empty lists or ints and floats compare equal
+func jsonEqual(a, b interface{}) bool {
+	normalize := func(v interface{}) interface{} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var out interface{}
+		_ = json.Unmarshal(data, &out)
+		switch x := out.(type) {
+		case []interface{}:
+			if len(x) == 0 {
+				return nil
+			}
+		case map[string]interface{}:
+			if len(x) == 0 {
+				return nil
+			}
+		}
+		return out
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func copySpec(spec map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(spec))
+	for k, v := range spec {
+		out[k] = v
+	}
+	return out
+}
+
+// reconcileMetrics tracks reconcile loops for the /metrics endpoint
+type reconcileMetrics struct {
+	mu             sync.Mutex
+	runs           int
+	failedRuns     int
+	lastSuccess    time.Time
+	lastDuration   time.Duration
+	lastResults    map[string]map[string]int // kind -> action -> count
+	resourceErrors int
+}
+
+// record stores the outcome of a reconcile loop
+func (m *reconcileMetrics) record(results []reconcileResult, err error, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.runs++
+	m.lastDuration = duration
+	failed := err != nil
+	m.lastResults = map[string]map[string]int{}
+	for _, r := range results {
+		kind := strings.SplitN(r.Resource, "/", 2)[0]
+		if m.lastResults[kind] == nil {
+			m.lastResults[kind] = map[string]int{}
+		}
+		m.lastResults[kind][r.Action]++
+		if r.Err != nil {
+			m.resourceErrors++
+			failed = true
+		}
+	}
+	if failed {
+		m.failedRuns++
+	} else {
+		m.lastSuccess = time.Now()
+	}
+}
+
+// ready reports whether a reconcile loop has completed without errors
+func (m *reconcileMetrics) ready() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.lastSuccess.IsZero()
+}
+
+// write renders the metrics in the Prometheus text exposition format
+func (m *reconcileMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP kubiya_reconcile_runs_total Reconcile loops run.")
+	fmt.Fprintln(w, "# TYPE kubiya_reconcile_runs_total counter")
+	fmt.Fprintf(w, "kubiya_reconcile_runs_total %d\n", m.runs)
+	fmt.Fprintln(w, "# HELP kubiya_reconcile_failed_runs_total Reconcile loops with at least one error.")
+	fmt.Fprintln(w, "# TYPE kubiya_reconcile_failed_runs_total counter")
+	fmt.Fprintf(w, "kubiya_reconcile_failed_runs_total %d\n", m.failedRuns)
+	fmt.Fprintln(w, "# HELP kubiya_reconcile_resource_errors_total Resources that failed to reconcile.")
+	fmt.Fprintln(w, "# TYPE kubiya_reconcile_resource_errors_total counter")
+	fmt.Fprintf(w, "kubiya_reconcile_resource_errors_total %d\n", m.resourceErrors)
+	fmt.Fprintln(w, "# HELP kubiya_reconcile_last_success_timestamp_seconds Time of the last successful reconcile loop.")
+	fmt.Fprintln(w, "# TYPE kubiya_reconcile_last_success_timestamp_seconds gauge")
+	lastSuccess := int64(0)
+	if !m.lastSuccess.IsZero() {
+		lastSuccess = m.lastSuccess.Unix()
+	}
+	fmt.Fprintf(w, "kubiya_reconcile_last_success_timestamp_seconds %d\n", lastSuccess)
+	fmt.Fprintln(w, "# HELP kubiya_reconcile_duration_seconds Duration of the last reconcile loop.")
+	fmt.Fprintln(w, "# TYPE kubiya_reconcile_duration_seconds gauge")
+	fmt.Fprintf(w, "kubiya_reconcile_duration_seconds %g\n", m.lastDuration.Seconds())
+	fmt.Fprintln(w, "# HELP kubiya_reconcile_resources Resources by kind and action in the last reconcile loop.")
+	fmt.Fprintln(w, "# TYPE kubiya_reconcile_resources gauge")
+	for _, kind := range manifestKindOrder {
+		for _, action := range []string{reconcileCreated, reconcileUpdated, reconcileUnchanged, reconcileFailed} {
+			fmt.Fprintf(w, "kubiya_reconcile_resources{kind=%q,action=%q} %d\n",
+				strings.ToLower(kind), action, m.lastResults[kind][action])
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReconcileClient struct {
+	sources  []kubiya.Source
+	agents   []kubiya.Agent
+	webhooks []kubiya.Webhook
+	calls    []string
+}
+
+func (f *fakeReconcileClient) ListSources(ctx context.Context) ([]kubiya.Source, error) {
+	return f.sources, nil
+}
+
+func (f *fakeReconcileClient) CreateSource(ctx context.Context, url string, opts ...kubiya.SourceOption) (*kubiya.Source, error) {
+	s := kubiya.Source{UUID: "src-new", URL: url}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	f.calls = append(f.calls, "create source "+s.Name)
+	return &s, nil
+}
+
+func (f *fakeReconcileClient) UpdateSource(ctx context.Context, uuid string, opts ...kubiya.SourceOption) (*kubiya.Source, error) {
+	s := kubiya.Source{UUID: uuid}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	f.calls = append(f.calls, "update source "+uuid+" url="+s.URL)
+	return &s, nil
+}
+
+func (f *fakeReconcileClient) GetAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	return f.agents, nil
+}
+
+func (f *fakeReconcileClient) CreateAgent(ctx context.Context, agent kubiya.Agent) (*kubiya.Agent, error) {
+	f.calls = append(f.calls, "create agent "+agent.Name+" sources="+strings.Join(agent.Sources, ","))
+	agent.UUID = "agent-new"
+	return &agent, nil
+}
+
+func (f *fakeReconcileClient) UpdateAgent(ctx context.Context, uuid string, agent kubiya.Agent) (*kubiya.Agent, error) {
+	f.calls = append(f.calls, "update agent "+uuid+" description="+agent.Description)
+	return &agent, nil
+}
+
+func (f *fakeReconcileClient) ListWebhooks(ctx context.Context) ([]kubiya.Webhook, error) {
+	return f.webhooks, nil
+}
+
+func (f *fakeReconcileClient) CreateWebhook(ctx context.Context, webhook kubiya.Webhook) (*kubiya.Webhook, error) {
+	f.calls = append(f.calls, "create webhook "+webhook.Name+" agent="+webhook.AgentID)
+	return &webhook, nil
+}
+
+func (f *fakeReconcileClient) UpdateWebhook(ctx context.Context, id string, webhook kubiya.Webhook) (*kubiya.Webhook, error) {
+	f.calls = append(f.calls, "update webhook "+id)
+	return &webhook, nil
+}
+
+const testManifests = `kind: Webhook
+name: alerts
+spec:
+  agent: k8s-helper
+  prompt: Investigate
+---
+kind: Agent
+name: k8s-helper
+spec:
+  description: Helps with Kubernetes
+  sources: [k8s-tools]
+---
+kind: Source
+name: k8s-tools
+spec:
+  url: https://github.com/acme/tools
+`
+
+func writeManifests(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kubiya.yaml"), []byte(content), 0o644))
+	return dir
+}
+
+func TestLoadManifests(t *testing.T) {
+	manifests, err := loadManifests(writeManifests(t, testManifests))
+	require.NoError(t, err)
+	require.Len(t, manifests, 3)
+	assert.Equal(t, "Source/k8s-tools", manifests[0].String())
+	assert.Equal(t, "Agent/k8s-helper", manifests[1].String())
+	assert.Equal(t, "Webhook/alerts", manifests[2].String())
+
+	_, err = loadManifests(writeManifests(t, "kind: Team\nname: x\n"))
+	assert.ErrorContains(t, err, `unsupported kind "Team"`)
+}
+
+func TestReconcileCreatesInDependencyOrder(t *testing.T) {
+	manifests, err := loadManifests(writeManifests(t, testManifests))
+	require.NoError(t, err)
+
+	client := &fakeReconcileClient{}
+	results, err := (&reconciler{client: client}).reconcile(context.Background(), manifests)
+	require.NoError(t, err)
+
+	for _, r := range results {
+		assert.NoError(t, r.Err)
+		assert.Equal(t, reconcileCreated, r.Action, r.Resource)
+	}
+	assert.Equal(t, []string{
+		"create source k8s-tools",
+		"create agent k8s-helper sources=src-new",
+		"create webhook alerts agent=agent-new",
+	}, client.calls)
+}
+
+func TestReconcileUpdatesDrift(t *testing.T) {
+	manifests, err := loadManifests(writeManifests(t, testManifests))
+	require.NoError(t, err)
+
+	client := &fakeReconcileClient{
+		sources:  []kubiya.Source{{UUID: "src-1", Name: "k8s-tools", URL: "https://github.com/acme/tools"}},
+		agents:   []kubiya.Agent{{UUID: "agent-1", Name: "k8s-helper", Description: "old", Sources: []string{"src-1"}}},
+		webhooks: []kubiya.Webhook{{ID: "wh-1", Name: "alerts", AgentID: "agent-1", Prompt: "Investigate"}},
+	}
+	results, err := (&reconciler{client: client}).reconcile(context.Background(), manifests)
+	require.NoError(t, err)
+
+	actions := map[string]string{}
+	for _, r := range results {
+		require.NoError(t, r.Err)
+		actions[r.Resource] = r.Action
+	}
+	assert.Equal(t, map[string]string{
+		"Source/k8s-tools": reconcileUnchanged,
+		"Agent/k8s-helper": reconcileUpdated,
+		"Webhook/alerts":   reconcileUnchanged,
+	}, actions)
+	assert.Equal(t, []string{"update agent agent-1 description=Helps with Kubernetes"}, client.calls)
+}
+
+func TestReconcileUpdatesSourceURL(t *testing.T) {
+	manifests, err := loadManifests(writeManifests(t, testManifests))
+	require.NoError(t, err)
+
+	client := &fakeReconcileClient{
+		sources: []kubiya.Source{{UUID: "src-1", Name: "k8s-tools", URL: "https://github.com/acme/old-tools"}},
+	}
+	results, err := (&reconciler{client: client}).reconcile(context.Background(), manifests[:1])
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, reconcileUpdated, results[0].Action)
+	assert.Equal(t, []string{"update source src-1 url=https://github.com/acme/tools"}, client.calls)
+}
+
+func TestReconcileDryRun(t *testing.T) {
+	manifests, err := loadManifests(writeManifests(t, testManifests))
+	require.NoError(t, err)
+
+	client := &fakeReconcileClient{}
+	results, err := (&reconciler{client: client, dryRun: true}).reconcile(context.Background(), manifests)
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Empty(t, client.calls)
+}
+
+func TestReconcileMetrics(t *testing.T) {
+	m := &reconcileMetrics{}
+	assert.False(t, m.ready())
+
+	m.record([]reconcileResult{
+		{Resource: "Agent/a", Action: reconcileCreated},
+		{Resource: "Agent/b", Action: reconcileUnchanged},
+	}, nil, 2*time.Second)
+	assert.True(t, m.ready())
+
+	var buf bytes.Buffer
+	m.write(&buf)
+	assert.Contains(t, buf.String(), "kubiya_reconcile_runs_total 1\n")
+	assert.Contains(t, buf.String(), `kubiya_reconcile_resources{kind="agent",action="created"} 1`)
+	assert.Contains(t, buf.String(), "kubiya_reconcile_duration_seconds 2\n")
+}
@@ -3,14 +3,35 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	klog "github.com/kubiyabot/cli/internal/log"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
 	"github.com/kubiyabot/cli/internal/version"
 )
 
 func Execute(cfg *config.Config) error {
+	var (
+		strictVersion bool
+		noPager       bool
+		pager         *output.Pager
+		noEmoji       = noEmojiDefault(cfg.Preferences)
+		restoreEmoji  = func() {}
+		// paletteArgs is the command line chosen in the command palette
+		paletteArgs []string
+		logOpts     = klog.OptionsFromEnv()
+		logFile     string
+		hooks       = newCommandHooks(cfg)
+	)
+	defer func() { pager.Close() }()
+	// Flush the stripped output into the pager before it closes
+	defer func() { restoreEmoji() }()
+
 	rootCmd := &cobra.Command{
 		Use:   "kubiya",
 		Short: "🤖 Kubiya CLI - Your Agentic AI Automation Companion",
@@ -40,15 +61,90 @@ Need help? Visit: https://docs.kubiya.ai`,
 		Version:       version.GetVersion(),
 		SilenceUsage:  true,  // Never show usage on errors - errors are formatted by handleError in main.go
 		SilenceErrors: false, // Let errors propagate to main.go for proper handling
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Diagnostics go to stderr or --log-file, apart from the output
+			if logFile != "" {
+				f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+				if err != nil {
+					return fmt.Errorf("failed to open log file: %w", err)
+				}
+				logOpts.Output = f
+			}
+			if err := klog.Configure(logOpts); err != nil {
+				return err
+			}
+			if cfg.Debug {
+				klog.Verbose(klog.LevelDebug)
+			}
+
+			// Apply the preferences of the config file
+			applyOutputPreference(cmd, cfg.Preferences.Output)
+
+			// Plain output when redirected or NO_COLOR is set; decided before
+			// the pager replaces stdout. Emoji are stripped before the output
+			// reaches the pager.
+			if !style.ShouldUseColors() {
+				style.DisableColors()
+			}
+			if shouldPage(cmd, noPager) {
+				pager = output.StartPager()
+			}
+			if noEmoji {
+				restoreEmoji = style.DisableEmoji(!structuredOutput(cmd))
+			}
+
+			// Faults are only injected on purpose, say so on every run
+			if cfg.FaultInject != "" {
+				faults, err := kubiya.ParseFaultSpec(cfg.FaultInject)
+				if err != nil {
+					return fmt.Errorf("invalid fault injection spec: %w", err)
+				}
+				if faults.Enabled() {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s Injecting faults into API calls: %s\n",
+						style.WarningStyle.Render("⚠️"), cfg.FaultInject)
+				}
+			}
+
+			// Serve the API from the embedded mock before anything talks to it
+			if cfg.Mock {
+				if err := startMockAPI(cfg); err != nil {
+					return err
+				}
+			}
+
+			// Point out problems of the config file
+			if cmd.CommandPath() != "kubiya config validate" {
+				warnConfigIssues(cmd.ErrOrStderr())
+			}
+
+			// Fail early, and helpfully, when the API key is rejected; the
+			// explanation is printed once, by main
+			if err := authPreflight(cmd, cfg); err != nil {
+				cmd.SilenceErrors = true
+				return err
+			}
+
+			// Hooks of the config may block the command or set its flags
+			if err := hooks.preRun(cmd, args); err != nil {
+				return err
+			}
+
 			// Skip update check for version and update commands
 			if cmd.Name() == "version" || cmd.Name() == "update" {
-				return
+				return nil
+			}
+
+			// Fail early when the platform no longer supports this CLI version
+			if strictVersion {
+				if err := runVersionSkewCheck(cmd.ErrOrStderr(), cfg, true, false); err != nil {
+					return err
+				}
 			}
 
-			// Skip update check in automation mode
-			if os.Getenv("KUBIYA_AUTOMATION") != "" {
-				return
+			// Skip update check in automation mode and against the offline mock
+			// or a replayed cassette
+			if os.Getenv("KUBIYA_AUTOMATION") != "" || cfg.Mock || replaying(cmd) {
+				return nil
 			}
 
 			// Check for updates
@@ -56,8 +152,14 @@ Need help? Visit: https://docs.kubiya.ai`,
 				fmt.Fprint(cmd.ErrOrStderr(), msg)
 			}
 
+			// Warn when the platform recommends a newer CLI version
+			if !strictVersion {
+				_ = runVersionSkewCheck(cmd.ErrOrStderr(), cfg, false, false)
+			}
+
 			// Show authentication hint for commands that need auth if not configured
 			showAuthHintIfNeeded(cmd, cfg)
+			return nil
 		},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -85,11 +187,33 @@ Need help? Visit: https://docs.kubiya.ai
 				return nil
 			}
 
+			// Let the user pick a command when running interactively
+			if paletteAvailable() {
+				selected, err := runCommandPalette(cmd)
+				if err != nil {
+					return err
+				}
+				paletteArgs = selected
+				return nil
+			}
+
 			// Show help if no subcommand
 			return cmd.Help()
 		},
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&cfg.Mock, "mock", cfg.Mock, "Use an embedded mock of the Kubiya API (also KUBIYA_MOCK=1 or a context named \"mock\")")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Do not pipe long output into $PAGER")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", noEmoji, "Strip emoji and decorative glyphs from output, e.g. for CI logs and serial consoles (also KUBIYA_NO_EMOJI)")
+	rootCmd.PersistentFlags().BoolVar(&output.SortKeys, "sort-keys", output.SortKeys, "Sort JSON keys and lists by name so that output is stable between runs (also KUBIYA_SORT_KEYS)")
+	rootCmd.PersistentFlags().BoolVar(&strictVersion, "strict-version", false, "Fail when the platform no longer supports this CLI version, or when its supported versions cannot be looked up")
+	rootCmd.PersistentFlags().StringVar(&cfg.FaultInject, "fault-inject", cfg.FaultInject, "Inject faults into API calls for resilience testing, e.g. stream_error:0.1,latency:2s (also KUBIYA_FAULT_INJECT)")
+	_ = rootCmd.PersistentFlags().MarkHidden("fault-inject")
+	rootCmd.PersistentFlags().StringVar(&logOpts.Level, "log-level", logOpts.Level, "Log diagnostics at this level and above: trace, debug, info or warn (default warn, also KUBIYA_LOG_LEVEL)")
+	rootCmd.PersistentFlags().StringVar(&logOpts.Format, "log-format", logOpts.Format, "Format of log records: text or json (also KUBIYA_LOG_FORMAT)")
+	rootCmd.PersistentFlags().StringSliceVar(&logOpts.Subsystems, "log-subsystems", logOpts.Subsystems, "Only log these subsystems: http, sse, mcp, tui, chat (default all, also KUBIYA_LOG_SUBSYSTEMS)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append log records to this file instead of stderr")
+
 	// V2 Control Plane Commands
 	rootCmd.AddCommand(
 		// Core V2 Commands
@@ -106,57 +230,100 @@ Need help? Visit: https://docs.kubiya.ai
 		newWorkerCommand(cfg),      // V2: Worker management
 		newGraphCommand(cfg),       // V2: Context Graph (includes intelligent search)
 		newMemoryCommand(cfg),      // V2: Cognitive memory management
+		newSearchCommand(cfg),      // Search across agents, tools, sources, knowledge, webhooks and secrets
+		newTraceCommand(cfg),       // Server-side events of a CLI invocation, by correlation ID
 
 		// V1 Legacy Commands (still on api.kubiya.ai)
-		newWorkflowCommand(cfg), // V1: Workflows
-		newUsersCommand(cfg),    // V1: User management
-		newSecretsCommand(cfg),  // V1: Secrets
+		newWorkflowCommand(cfg),  // V1: Workflows
+		newUsersCommand(cfg),     // V1: User management
+		newGroupCommand(cfg),     // V1: Group management
+		newSecretsCommand(cfg),   // V1: Secrets
 		newKnowledgeCommand(cfg), // V1: Knowledge service
+		newWebhookCommand(cfg),   // V1: Webhooks
+		newServeCommand(cfg),     // V1: Declarative reconcile daemon
+		newRunnerCommand(cfg),    // V1: Runner installation
+		newTriggerCommand(cfg),   // V1: External event triggers
+		newExportCommand(cfg),    // V1: Terraform export of agents, sources and webhooks
 
 		// System Commands
-		newAuthCommand(cfg),  // Authentication management
+		newAuthCommand(cfg), // Authentication management
 		newLoginCommand(cfg),
 		newUpdateCommand(cfg),
 		newVersionCommand(cfg),
-		NewConfigCmd(),       // Context management
-		newMcpCommand(cfg),   // MCP server management
+		NewConfigCmd(),            // Context management
+		newMcpCommand(cfg),        // MCP server management
+		newMockServerCommand(cfg), // Local mock API for development and CI
+		newSchemaCommand(cfg),     // JSON Schemas of the CLI file formats
+		newContextBundleCommand(), // Named chat context collections
 	)
 
-	return rootCmd.Execute()
+	// The completion scripts come from cobra, "completion install" from us
+	rootCmd.InitDefaultCompletionCmd()
+	if completionCmd, _, err := rootCmd.Find([]string{"completion"}); err == nil && completionCmd != rootCmd {
+		completionCmd.AddCommand(newCompletionInstallCommand(cfg))
+	}
+
+	args := os.Args[1:]
+	executed, err := rootCmd.ExecuteC()
+	if err == nil && paletteArgs != nil {
+		// Keep global flags given with the bare command, e.g. --mock
+		args = append(args, paletteArgs...)
+		fmt.Fprintf(os.Stderr, "%s kubiya %s\n", style.DimStyle.Render("$"), strings.Join(args, " "))
+		rootCmd.SetArgs(args)
+		executed, err = rootCmd.ExecuteC()
+	}
+	hooks.postRun(executed, err)
+	if err == nil {
+		recordCommand(executed, args)
+	}
+	return explainAuthError(cfg, err)
+}
+
+// authRequiredCommands are the top-level commands that need an API key
+var authRequiredCommands = map[string]bool{
+	"workflow":  true,
+	"agent":     true,
+	"team":      true,
+	"execution": true,
+	"job":       true,
+	"model":     true,
+	"skill":     true,
+	"policy":    true,
+	"secret":    true,
+	"knowledge": true,
+	"graph":     true,
+	"export":    true,
+}
+
+// authRequiredCommand returns the top-level command of cmd when it needs an
+// API key. It goes by the top-level command, so that e.g. "schema export" is
+// not mistaken for "export".
+func authRequiredCommand(cmd *cobra.Command) (*cobra.Command, bool) {
+	for current := cmd; current != nil; current = current.Parent() {
+		if current.Parent() == cmd.Root() {
+			return current, authRequiredCommands[current.Name()]
+		}
+	}
+	return nil, false
+}
+
+// requiresAuth reports whether cmd needs an API key
+func requiresAuth(cmd *cobra.Command) bool {
+	_, ok := authRequiredCommand(cmd)
+	return ok
 }
 
 // showAuthHintIfNeeded displays a helpful authentication message for commands that require auth
 func showAuthHintIfNeeded(cmd *cobra.Command, cfg *config.Config) {
-	// Commands that require authentication
-	authRequiredCommands := map[string]bool{
-		"workflow":  true,
-		"agent":     true,
-		"team":      true,
-		"execution": true,
-		"job":       true,
-		"model":     true,
-		"skill":     true,
-		"policy":    true,
-		"secret":    true,
-		"knowledge": true,
-		"graph":     true,
+	top, ok := authRequiredCommand(cmd)
+	if !ok || cfg.APIKey != "" {
+		return
 	}
-
-	// Check if this command or its parent requires auth
-	currentCmd := cmd
-	for currentCmd != nil {
-		if authRequiredCommands[currentCmd.Name()] {
-			if cfg.APIKey == "" {
-				fmt.Fprintf(os.Stderr, `
+	fmt.Fprintf(os.Stderr, `
 ⚠️  Authentication required for '%s' command
 
 🔑 Quick setup: kubiya login
 🌐 Get API key: https://compose.kubiya.ai/settings#apiKeys
 
-`, currentCmd.Name())
-			}
-			break
-		}
-		currentCmd = currentCmd.Parent()
-	}
+`, top.Name())
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
 	"github.com/kubiyabot/cli/internal/kubiya"
 	"github.com/kubiyabot/cli/internal/style"
 )
@@ -91,7 +92,7 @@ func newRunCommand(cfg *config.Config) *cobra.Command {
 				}
 
 				// Send request
-				resp, err := http.DefaultClient.Do(req)
+				resp, err := httpclient.Default().Do(req)
 				if err != nil {
 					return fmt.Errorf("failed to send classification request: %w", err)
 				}
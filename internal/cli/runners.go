@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Runner installation methods
+const (
+	runnerModeManifest = "manifest"
+	runnerModeHelm     = "helm"
+)
+
+// runnerInstallOptions configures where and how a runner is installed
+type runnerInstallOptions struct {
+	Name        string
+	Mode        string
+	Kubeconfig  string
+	KubeContext string
+	Namespace   string
+	Chart       string
+	ChartRepo   string
+	DryRun      bool
+	OutputFile  string
+}
+
+// release is the Helm release name of the runner
+func (o runnerInstallOptions) release() string {
+	return "kubiya-runner-" + o.Name
+}
+
+// kubectlArgs builds the arguments of a kubectl command reading manifests
+// from stdin
+func (o runnerInstallOptions) kubectlArgs(verb string) []string {
+	args := []string{verb, "-f", "-"}
+	if verb == "delete" {
+		args = append(args, "--ignore-not-found")
+	}
+	if o.Namespace != "" {
+		args = append(args, "--namespace", o.Namespace)
+	}
+	return append(args, o.kubeFlags("--context")...)
+}
+
+// helmInstallArgs builds the arguments of `helm upgrade --install` using the
+// values in valuesFile
+func (o runnerInstallOptions) helmInstallArgs(valuesFile string) []string {
+	args := []string{"upgrade", "--install", o.release(), o.Chart,
+		"--namespace", o.Namespace, "--create-namespace", "--values", valuesFile}
+	if o.ChartRepo != "" {
+		args = append(args, "--repo", o.ChartRepo)
+	}
+	return append(args, o.kubeFlags("--kube-context")...)
+}
+
+// helmUninstallArgs builds the arguments of `helm uninstall`
+func (o runnerInstallOptions) helmUninstallArgs() []string {
+	args := []string{"uninstall", o.release(), "--namespace", o.Namespace}
+	return append(args, o.kubeFlags("--kube-context")...)
+}
+
+// kubeFlags returns the kubeconfig and context flags; kubectl and helm name
+// the context flag differently
+func (o runnerInstallOptions) kubeFlags(contextFlag string) []string {
+	var args []string
+	if o.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", o.Kubeconfig)
+	}
+	if o.KubeContext != "" {
+		args = append(args, contextFlag, o.KubeContext)
+	}
+	return args
+}
+
+func newRunnerCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "runner",
+		Aliases: []string{"runners"},
+		Short:   "🏃 Install and remove runners",
+		Long: `Install runners into your Kubernetes clusters.
+
+Runners execute tools close to your infrastructure. 'kubiya runner install'
+registers the runner, fetches its manifests (or Helm values) with the
+registration token from the API and applies them to the cluster.`,
+	}
+
+	cmd.AddCommand(
+		newRunnerInstallCommand(cfg),
+		newRunnerUninstallCommand(cfg),
+	)
+	return cmd
+}
+
+// addRunnerClusterFlags adds the flags shared by install and uninstall
+func addRunnerClusterFlags(cmd *cobra.Command, opts *runnerInstallOptions) {
+	cmd.Flags().StringVar(&opts.Mode, "mode", runnerModeManifest, "Installation method (manifest|helm)")
+	cmd.Flags().StringVar(&opts.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default: kubectl/helm default)")
+	cmd.Flags().StringVar(&opts.KubeContext, "kube-context", "", "Kubeconfig context to use")
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "kubiya", "Kubernetes namespace of the runner")
+}
+
+func newRunnerInstallCommand(cfg *config.Config) *cobra.Command {
+	opts := runnerInstallOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "install <name>",
+		Short: "Register a runner and install it into a Kubernetes cluster",
+		Long: `Register a runner and install it into a Kubernetes cluster.
+
+With --mode manifest (the default) the runner manifests are generated by the
+API and applied with kubectl. With --mode helm the runner's Helm values,
+including its registration token, are fetched and installed with
+'helm upgrade --install', so running it again upgrades the runner.
+
+Use --dry-run to print the manifests or values instead of applying them.
+They contain the runner's registration token: store them as a secret.`,
+		Example: `  # Install a runner with kubectl into the current cluster
+  kubiya runner install prod-runner
+
+  # Install into a specific cluster and namespace
+  kubiya runner install prod-runner --kubeconfig ~/.kube/prod --namespace kubiya
+
+  # Install with Helm
+  kubiya runner install prod-runner --mode helm
+
+  # Write the Helm values to a file for GitOps
+  kubiya runner install prod-runner --mode helm --output values.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			if opts.Mode != runnerModeManifest && opts.Mode != runnerModeHelm {
+				return fmt.Errorf("invalid --mode %q (must be %s or %s)", opts.Mode, runnerModeManifest, runnerModeHelm)
+			}
+			if opts.OutputFile != "" {
+				opts.DryRun = true
+			}
+			return installRunner(cmd.Context(), cmd.OutOrStdout(), kubiya.NewClient(cfg), opts)
+		},
+	}
+
+	addRunnerClusterFlags(cmd, &opts)
+	cmd.Flags().StringVar(&opts.Chart, "chart", "kubiya-runner", "Helm chart of the runner (--mode helm)")
+	cmd.Flags().StringVar(&opts.ChartRepo, "chart-repo", "https://charts.kubiya.ai", "Helm repository of the chart (--mode helm)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the manifests or Helm values without applying them")
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "", "Write the manifests or Helm values to a file (implies --dry-run)")
+	return cmd
+}
+
+func newRunnerUninstallCommand(cfg *config.Config) *cobra.Command {
+	opts := runnerInstallOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "uninstall <name>",
+		Short: "Remove a runner from a Kubernetes cluster",
+		Long: `Remove a runner installed with 'kubiya runner install' from a Kubernetes
+cluster. Use the same --mode, --namespace and cluster flags as for install.
+
+The runner stays registered with the platform and can be installed again.`,
+		Example: `  # Remove a runner installed with kubectl
+  kubiya runner uninstall prod-runner
+
+  # Remove a runner installed with Helm
+  kubiya runner uninstall prod-runner --mode helm --namespace kubiya`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+
+			switch opts.Mode {
+			case runnerModeHelm:
+				if err := runKubeTool(ctx, "helm", opts.helmUninstallArgs(), nil, out); err != nil {
+					return err
+				}
+			case runnerModeManifest:
+				manifests, err := fetchRunnerManifests(ctx, kubiya.NewClient(cfg), opts.Name, false)
+				if err != nil {
+					return err
+				}
+				if err := runKubeTool(ctx, "kubectl", opts.kubectlArgs("delete"), manifests, out); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid --mode %q (must be %s or %s)", opts.Mode, runnerModeManifest, runnerModeHelm)
+			}
+
+			fmt.Fprintf(out, "%s Runner %s uninstalled from namespace %s\n",
+				style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(opts.Name), opts.Namespace)
+			return nil
+		},
+	}
+
+	addRunnerClusterFlags(cmd, &opts)
+	return cmd
+}
+
+// installRunner fetches the runner's manifests or Helm values and applies,
+// prints or saves them
+func installRunner(ctx context.Context, out io.Writer, client *kubiya.Client, opts runnerInstallOptions) error {
+	var content []byte
+	var err error
+	switch opts.Mode {
+	case runnerModeHelm:
+		content, err = fetchRunnerHelmValues(ctx, client, opts.Name)
+	default:
+		content, err = fetchRunnerManifests(ctx, client, opts.Name, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.OutputFile != "" {
+		// The content holds the registration token
+		if err := os.WriteFile(opts.OutputFile, content, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.OutputFile, err)
+		}
+		fmt.Fprintf(out, "%s Wrote %s for runner %s\n",
+			style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(opts.OutputFile), opts.Name)
+		return nil
+	}
+	if opts.DryRun {
+		_, err := out.Write(content)
+		return err
+	}
+
+	switch opts.Mode {
+	case runnerModeHelm:
+		values, err := os.CreateTemp("", "kubiya-runner-*.yaml")
+		if err != nil {
+			return fmt.Errorf("failed to create values file: %w", err)
+		}
+		defer os.Remove(values.Name())
+		if _, err := values.Write(content); err != nil {
+			values.Close()
+			return fmt.Errorf("failed to write values file: %w", err)
+		}
+		values.Close()
+		err = runKubeTool(ctx, "helm", opts.helmInstallArgs(values.Name()), nil, out)
+	default:
+		err = runKubeTool(ctx, "kubectl", opts.kubectlArgs("apply"), content, out)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s Runner %s installed into namespace %s\n",
+		style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(opts.Name), opts.Namespace)
+	return nil
+}
+
+// fetchRunnerManifests downloads the Kubernetes manifests of a runner,
+// registering it first when create is set
+func fetchRunnerManifests(ctx context.Context, client *kubiya.Client, name string, create bool) ([]byte, error) {
+	var manifest *kubiya.RunnerManifest
+	if create {
+		created, err := client.CreateRunnerManifest(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register runner %s: %w", name, err)
+		}
+		manifest = &created
+	} else {
+		existing, err := client.GetRunnerManifest(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get manifest of runner %s: %w", name, err)
+		}
+		manifest = existing
+	}
+	if manifest.URL == "" {
+		return nil, fmt.Errorf("no manifest available for runner %s", name)
+	}
+
+	content, err := client.DownloadManifest(ctx, manifest.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download manifest of runner %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// fetchRunnerHelmValues registers the runner if needed and renders its Helm
+// values as YAML
+func fetchRunnerHelmValues(ctx context.Context, client *kubiya.Client, name string) ([]byte, error) {
+	if _, err := client.GetRunner(ctx, name); err != nil {
+		if _, err := client.CreateRunnerManifest(ctx, name); err != nil {
+			return nil, fmt.Errorf("failed to register runner %s: %w", name, err)
+		}
+	}
+
+	chart, err := client.GetRunnerHelmChart(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Helm values of runner %s: %w", name, err)
+	}
+	return runnerHelmValues(chart)
+}
+
+// runnerHelmValues converts the Helm chart configuration returned by the API
+// into a values file
+func runnerHelmValues(chart *kubiya.RunnerHelmChart) ([]byte, error) {
+	data, err := json.Marshal(chart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Helm values: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to encode Helm values: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(values); err != nil {
+		return nil, fmt.Errorf("failed to encode Helm values: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// runKubeTool runs kubectl or helm with stdin as input, streaming its output
+func runKubeTool(ctx context.Context, tool string, args []string, stdin []byte, out io.Writer) error {
+	if _, err := exec.LookPath(tool); err != nil {
+		return fmt.Errorf("%s not found in PATH: install it or use --dry-run to print the configuration", tool)
+	}
+
+	cmd := exec.CommandContext(ctx, tool, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", tool, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
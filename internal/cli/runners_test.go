@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerInstallArgs(t *testing.T) {
+	opts := runnerInstallOptions{
+		Name:        "prod",
+		Kubeconfig:  "/home/me/.kube/prod",
+		KubeContext: "eks-prod",
+		Namespace:   "kubiya",
+		Chart:       "kubiya-runner",
+		ChartRepo:   "https://charts.kubiya.ai",
+	}
+
+	assert.Equal(t, []string{"apply", "-f", "-", "--namespace", "kubiya",
+		"--kubeconfig", "/home/me/.kube/prod", "--context", "eks-prod"}, opts.kubectlArgs("apply"))
+	assert.Equal(t, []string{"delete", "-f", "-", "--ignore-not-found", "--namespace", "kubiya",
+		"--kubeconfig", "/home/me/.kube/prod", "--context", "eks-prod"}, opts.kubectlArgs("delete"))
+	assert.Equal(t, []string{"upgrade", "--install", "kubiya-runner-prod", "kubiya-runner",
+		"--namespace", "kubiya", "--create-namespace", "--values", "values.yaml",
+		"--repo", "https://charts.kubiya.ai",
+		"--kubeconfig", "/home/me/.kube/prod", "--kube-context", "eks-prod"}, opts.helmInstallArgs("values.yaml"))
+	assert.Equal(t, []string{"uninstall", "kubiya-runner-prod", "--namespace", "kubiya"},
+		runnerInstallOptions{Name: "prod", Namespace: "kubiya"}.helmUninstallArgs())
+}
+
+func TestRunnerHelmValues(t *testing.T) {
+	chart := &kubiya.RunnerHelmChart{Organization: "acme", RunnerName: "prod", UUID: "r-1"}
+	chart.Nats.JWT = "token"
+
+	values, err := runnerHelmValues(chart)
+	require.NoError(t, err)
+	assert.Contains(t, string(values), "organization: acme\n")
+	assert.Contains(t, string(values), "runner_name: prod\n")
+	assert.Contains(t, string(values), "nats:\n  jwt: token\n")
+}
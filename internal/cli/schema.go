@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/mcp"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/schema"
+)
+
+// fileFormat is a file format of the CLI with a JSON Schema
+type fileFormat struct {
+	Title       string
+	Description string
+	Type        reflect.Type
+	// OneOrMany formats hold a single value or a list of them
+	OneOrMany bool
+}
+
+// fileFormats are the file formats exported by `kubiya schema export`, by
+// --type
+var fileFormats = map[string]fileFormat{
+	"agent": {
+		Title:       "Kubiya agent",
+		Description: "Agent definition, as read by kubiya agent create --file and kubiya agent validate",
+		Type:        reflect.TypeOf(kubiya.Agent{}),
+	},
+	"tool": {
+		Title:       "Kubiya tools",
+		Description: "A tool or a list of tools, as in tools.json and kubiya source inline add --file",
+		Type:        reflect.TypeOf(kubiya.Tool{}),
+		OneOrMany:   true,
+	},
+	"webhook": {
+		Title:       "Kubiya webhooks",
+		Description: "A webhook or a list of webhooks, as read by kubiya agent create --webhook-file",
+		Type:        reflect.TypeOf(kubiya.Webhook{}),
+		OneOrMany:   true,
+	},
+	"chat-scenario": {
+		Title:       "Kubiya chat scenario",
+		Description: "Scripted conversation with an agent and its expected answers, as read by kubiya chat run-scenario",
+		Type:        reflect.TypeOf(chatScenario{}),
+	},
+	"mcp-config": {
+		Title:       "Kubiya MCP server configuration",
+		Description: "MCP server configuration with its whitelisted tools, as read by kubiya mcp serve --config",
+		Type:        reflect.TypeOf(mcp.Config{}),
+	},
+}
+
+func fileFormatNames() []string {
+	names := make([]string, 0, len(fileFormats))
+	for name := range fileFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fileSchema returns the JSON Schema of the file format name
+func fileSchema(name string) (*schema.Schema, error) {
+	format, ok := fileFormats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q (available: %s)", name, strings.Join(fileFormatNames(), ", "))
+	}
+	var s *schema.Schema
+	if format.OneOrMany {
+		s = schema.OneOrMany(format.Type)
+	} else {
+		s = schema.Generate(format.Type)
+	}
+	s.Title = format.Title
+	s.Description = format.Description
+	return s, nil
+}
+
+func newSchemaCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "📐 JSON Schemas of the CLI file formats",
+		Long: `JSON Schemas of the files read by the CLI, for validation and completion
+in editors. The schemas are generated from the definitions built into this
+binary, so they always match its version.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newSchemaExportCommand(cfg),
+		newSchemaListCommand(cfg),
+	)
+	return cmd
+}
+
+func newSchemaExportCommand(cfg *config.Config) *cobra.Command {
+	var (
+		schemaType string
+		outFile    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print the JSON Schema of a file format",
+		Example: `  # Schema of agent files
+  kubiya schema export --type agent > agent.schema.json
+
+  # Schema of the MCP server configuration
+  kubiya schema export --type mcp-config --out mcp-config.schema.json
+
+  # Use it in VS Code with the YAML extension, on the first line of agent.yaml
+  # yaml-language-server: $schema=./agent.schema.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := fileSchema(schemaType)
+			if err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			if err := output.EncodeJSON(&buf, s); err != nil {
+				return fmt.Errorf("failed to marshal schema: %w", err)
+			}
+			data := buf.Bytes()
+
+			if outFile == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(outFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write schema: %w", err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "✅ Wrote the %s schema to %s\n", schemaType, outFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&schemaType, "type", "t", "", "File format: "+strings.Join(fileFormatNames(), ", "))
+	cmd.Flags().StringVarP(&outFile, "out", "o", "", "File to write the schema to (default: stdout)")
+	_ = cmd.MarkFlagRequired("type")
+	_ = cmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(fileFormatNames(), cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func newSchemaListCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the file formats with a schema",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range fileFormatNames() {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-12s %s\n", name, fileFormats[name].Description)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+)
+
+func TestSchemaExport(t *testing.T) {
+	for _, name := range fileFormatNames() {
+		t.Run(name, func(t *testing.T) {
+			cmd := newSchemaExportCommand(&config.Config{})
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"--type", name})
+			require.NoError(t, cmd.Execute())
+
+			var doc map[string]interface{}
+			require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+			assert.Equal(t, fileFormats[name].Title, doc["title"])
+			assert.NotEmpty(t, doc["$defs"])
+		})
+	}
+}
+
+func TestSchemaExportFields(t *testing.T) {
+	s, err := fileSchema("mcp-config")
+	require.NoError(t, err)
+	config := s.Defs["Config"]
+	require.NotNil(t, config)
+	assert.Equal(t, "#/$defs/WhitelistedTool", config.Properties["whitelisted_tools"].Items.Ref)
+	assert.Equal(t, "integer", s.Defs["WhitelistedTool"].Properties["timeout"].Type)
+
+	_, err = fileSchema("workflow")
+	assert.ErrorContains(t, err, "available: agent, chat-scenario, mcp-config, tool, webhook")
+}
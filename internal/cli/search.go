@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// searchTypes are the kinds of resources kubiya search looks through, in
+// the order results of equal relevance are shown
+var searchTypes = []string{"agent", "tool", "source", "knowledge", "webhook", "secret"}
+
+var searchTypeIcons = map[string]string{
+	"agent":     "🤖",
+	"tool":      "🛠️",
+	"source":    "📦",
+	"knowledge": "📚",
+	"webhook":   "🪝",
+	"secret":    "🔑",
+}
+
+// searchClient is the part of kubiya.Client used by kubiya search
+type searchClient interface {
+	ListAgents(ctx context.Context) ([]kubiya.Agent, error)
+	ListSources(ctx context.Context) ([]kubiya.Source, error)
+	GetSourceMetadataCached(ctx context.Context, sourceUUID string) (*kubiya.Source, error)
+	ListKnowledge(ctx context.Context, query string, limit int) ([]kubiya.Knowledge, error)
+	ListWebhooks(ctx context.Context) ([]kubiya.Webhook, error)
+	ListSecrets(ctx context.Context) ([]kubiya.Secret, error)
+}
+
+// searchAction is a command to follow a result up with
+type searchAction struct {
+	Label   string `json:"label"`
+	Command string `json:"command"`
+}
+
+// orgSearchResult is one resource matching a search
+type orgSearchResult struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	UUID        string         `json:"uuid,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Source      string         `json:"source,omitempty"` // source of a tool
+	Agents      []string       `json:"agents,omitempty"` // agents using a tool
+	Owners      []string       `json:"owners,omitempty"`
+	MatchedOn   string         `json:"matched_on"`
+	Score       int            `json:"score"`
+	Actions     []searchAction `json:"actions,omitempty"`
+}
+
+// searchField is a text of a resource and how much a match in it counts
+type searchField struct {
+	name   string
+	value  string
+	weight int
+}
+
+func newSearchCommand(cfg *config.Config) *cobra.Command {
+	var (
+		types        []string
+		limit        int
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "🔎 Search agents, tools, sources, knowledge, webhooks and secrets",
+		Long: `Search the whole organization at once.
+
+Every word of the query must appear in the name, description or another field
+of a resource. Results are ranked by where the words were found (names first)
+and come with commands to follow them up, such as chatting with an agent or
+executing a tool. Tool results list the agents using the tool and their owners.
+
+Secrets are searched by name and description only, their values are never
+fetched.`,
+		Example: `  # Who owns the tool that restarts the api?
+  kubiya search restart api
+
+  # Only agents and tools
+  kubiya search kubernetes --type agent,tool
+
+  # For scripts
+  kubiya search "aws cost" -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			for _, t := range types {
+				if !contains(searchTypes, t) {
+					return fmt.Errorf("invalid type %q (valid: %s)", t, strings.Join(searchTypes, ", "))
+				}
+			}
+			if len(types) == 0 {
+				types = searchTypes
+			}
+
+			query := strings.Join(args, " ")
+			client := kubiya.NewClient(cfg)
+			results, errs := searchOrg(cmd.Context(), client, query, types)
+			if len(errs) == len(types) {
+				return fmt.Errorf("search failed: %w", errs[types[0]])
+			}
+			if limit > 0 && len(results) > limit {
+				results = results[:limit]
+			}
+
+			if outputFormat == "json" {
+				failed := map[string]string{}
+				for t, err := range errs {
+					failed[t] = err.Error()
+				}
+				return output.EncodeJSON(cmd.OutOrStdout(), map[string]interface{}{
+					"query":   query,
+					"results": results,
+					"errors":  failed,
+				})
+			}
+
+			printSearchResults(cmd.OutOrStdout(), query, results, errs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&types, "type", nil, "Only search these types: "+strings.Join(searchTypes, ", "))
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of results (0 for all)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// searchOrg searches the given types concurrently. Types that could not be
+// listed are returned as errors and the others are still searched.
+func searchOrg(ctx context.Context, client searchClient, query string, types []string) ([]orgSearchResult, map[string]error) {
+	words := strings.Fields(strings.ToLower(query))
+	want := map[string]bool{}
+	for _, t := range types {
+		want[t] = true
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []orgSearchResult
+		errs    = map[string]error{}
+	)
+	collect := func(t string, found []orgSearchResult, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[t] = err
+			return
+		}
+		results = append(results, found...)
+	}
+	run := func(t string, search func() ([]orgSearchResult, error)) {
+		if !want[t] {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found, err := search()
+			collect(t, found, err)
+		}()
+	}
+
+	// Agents are needed to tell who uses a tool
+	var agents []kubiya.Agent
+	var agentsErr error
+	if want["agent"] || want["tool"] {
+		agents, agentsErr = client.ListAgents(ctx)
+	}
+	if want["agent"] {
+		collect("agent", searchAgents(agents, words), agentsErr)
+	}
+
+	run("tool", func() ([]orgSearchResult, error) { return searchTools(ctx, client, agents, words) })
+	run("source", func() ([]orgSearchResult, error) {
+		sources, err := client.ListSources(ctx)
+		return searchSources(sources, words), err
+	})
+	run("knowledge", func() ([]orgSearchResult, error) {
+		items, err := client.ListKnowledge(ctx, "", 0)
+		return searchKnowledge(items, words), err
+	})
+	run("webhook", func() ([]orgSearchResult, error) {
+		webhooks, err := client.ListWebhooks(ctx)
+		return searchWebhooks(webhooks, words), err
+	})
+	run("secret", func() ([]orgSearchResult, error) {
+		secrets, err := client.ListSecrets(ctx)
+		return searchSecrets(secrets, words), err
+	})
+	wg.Wait()
+
+	sortSearchResults(results)
+	return results, errs
+}
+
+// matchSearchFields scores the fields of a resource against the words of a
+// query: every word must be found, and counts the weight of the best field
+// it was found in. It returns 0 when a word is missing.
+func matchSearchFields(words []string, fields []searchField) (int, string) {
+	score, matchedOn, best := 0, "", 0
+	for _, w := range words {
+		found := 0
+		for _, f := range fields {
+			if f.weight > found && strings.Contains(strings.ToLower(f.value), w) {
+				found = f.weight
+				if found > best {
+					best, matchedOn = found, f.name
+				}
+			}
+		}
+		if found == 0 {
+			return 0, ""
+		}
+		score += found
+	}
+	if len(fields) > 0 && strings.EqualFold(fields[0].value, strings.Join(words, " ")) {
+		score += 100
+	}
+	return score, matchedOn
+}
+
+func sortSearchResults(results []orgSearchResult) {
+	order := map[string]int{}
+	for i, t := range searchTypes {
+		order[t] = i
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Type != b.Type {
+			return order[a.Type] < order[b.Type]
+		}
+		return a.Name < b.Name
+	})
+}
+
+func searchAgents(agents []kubiya.Agent, words []string) []orgSearchResult {
+	var results []orgSearchResult
+	for _, a := range agents {
+		score, matched := matchSearchFields(words, []searchField{
+			{"name", a.Name, 10},
+			{"description", a.Description, 5},
+			{"tags", strings.Join(a.Tags, " "), 5},
+			{"tools", strings.Join(a.Tools, " "), 3},
+			{"integrations", strings.Join(a.Integrations, " "), 3},
+			{"instructions", a.AIInstructions, 1},
+		})
+		if score == 0 {
+			continue
+		}
+		id := a.UUID
+		if id == "" {
+			id = a.ID
+		}
+		results = append(results, orgSearchResult{
+			Type: "agent", Name: a.Name, UUID: id, Description: a.Description, Owners: a.Owners,
+			MatchedOn: matched, Score: score,
+			Actions: []searchAction{
+				{"chat with", fmt.Sprintf("kubiya chat -t %s -m \"...\"", id)},
+				{"show", fmt.Sprintf("kubiya agent get %s", id)},
+			},
+		})
+	}
+	return results
+}
+
+// searchTools looks through the tools of every source, five sources at a
+// time
+func searchTools(ctx context.Context, client searchClient, agents []kubiya.Agent, words []string) ([]orgSearchResult, error) {
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []orgSearchResult
+		sem     = make(chan struct{}, 5)
+	)
+	for _, s := range sources {
+		wg.Add(1)
+		go func(s kubiya.Source) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			metadata, err := client.GetSourceMetadataCached(ctx, s.UUID)
+			if err != nil {
+				return // a source that cannot be read has no tools to find
+			}
+			users, owners := agentsUsingSource(agents, s.UUID)
+			if creator := s.KubiyaMetadata.UserCreated; creator != "" && !contains(owners, creator) {
+				owners = append(owners, creator)
+			}
+			tools := make([]kubiya.Tool, 0, len(metadata.Tools)+len(metadata.InlineTools))
+			tools = append(append(tools, metadata.Tools...), metadata.InlineTools...)
+			for _, tool := range tools {
+				score, matched := matchSearchFields(words, []searchField{
+					{"name", tool.Name, 10},
+					{"alias", tool.Alias, 8},
+					{"description", tool.Description, 5},
+					{"source", s.Name, 3},
+					{"content", tool.Content, 1},
+				})
+				if score == 0 {
+					continue
+				}
+				mu.Lock()
+				results = append(results, orgSearchResult{
+					Type: "tool", Name: tool.Name, UUID: s.UUID, Description: tool.Description,
+					Source: s.Name, Agents: users, Owners: owners, MatchedOn: matched, Score: score,
+					Actions: []searchAction{
+						{"execute", fmt.Sprintf("kubiya tool exec --name %s --source-uuid %s", tool.Name, s.UUID)},
+					},
+				})
+				mu.Unlock()
+			}
+		}(s)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// agentsUsingSource returns the names and owners of the agents having a
+// source
+func agentsUsingSource(agents []kubiya.Agent, sourceUUID string) ([]string, []string) {
+	var names, owners []string
+	for _, a := range agents {
+		if !contains(a.Sources, sourceUUID) {
+			continue
+		}
+		names = append(names, a.Name)
+		for _, o := range a.Owners {
+			if !contains(owners, o) {
+				owners = append(owners, o)
+			}
+		}
+	}
+	return names, owners
+}
+
+func searchSources(sources []kubiya.Source, words []string) []orgSearchResult {
+	var results []orgSearchResult
+	for _, s := range sources {
+		score, matched := matchSearchFields(words, []searchField{
+			{"name", s.Name, 10},
+			{"description", s.Description, 5},
+			{"url", s.URL, 3},
+		})
+		if score == 0 {
+			continue
+		}
+		var owners []string
+		if s.KubiyaMetadata.UserCreated != "" {
+			owners = []string{s.KubiyaMetadata.UserCreated}
+		}
+		results = append(results, orgSearchResult{
+			Type: "source", Name: s.Name, UUID: s.UUID, Description: s.Description, Owners: owners,
+			MatchedOn: matched, Score: score,
+			Actions: []searchAction{{"describe", fmt.Sprintf("kubiya source describe %s", s.UUID)}},
+		})
+	}
+	return results
+}
+
+func searchKnowledge(items []kubiya.Knowledge, words []string) []orgSearchResult {
+	var results []orgSearchResult
+	for _, k := range items {
+		score, matched := matchSearchFields(words, []searchField{
+			{"name", k.Name, 10},
+			{"description", k.Description, 5},
+			{"labels", strings.Join(k.Labels, " "), 5},
+			{"content", k.Content, 1},
+		})
+		if score == 0 {
+			continue
+		}
+		var owners []string
+		if k.Owner != "" {
+			owners = []string{k.Owner}
+		}
+		results = append(results, orgSearchResult{
+			Type: "knowledge", Name: k.Name, UUID: k.UUID, Description: k.Description, Owners: owners,
+			MatchedOn: matched, Score: score,
+			Actions: []searchAction{{"history", fmt.Sprintf("kubiya knowledge versions %s", k.UUID)}},
+		})
+	}
+	return results
+}
+
+func searchWebhooks(webhooks []kubiya.Webhook, words []string) []orgSearchResult {
+	var results []orgSearchResult
+	for _, w := range webhooks {
+		score, matched := matchSearchFields(words, []searchField{
+			{"name", w.Name, 10},
+			{"source", w.Source, 5},
+			{"prompt", w.Prompt, 3},
+			{"destination", w.Communication.Destination, 3},
+		})
+		if score == 0 {
+			continue
+		}
+		var owners []string
+		if w.CreatedBy != "" {
+			owners = []string{w.CreatedBy}
+		}
+		results = append(results, orgSearchResult{
+			Type: "webhook", Name: w.Name, UUID: w.ID, Description: w.Source, Owners: owners,
+			MatchedOn: matched, Score: score,
+			Actions: []searchAction{{"deliveries", fmt.Sprintf("kubiya webhook deliveries %s", w.ID)}},
+		})
+	}
+	return results
+}
+
+func searchSecrets(secrets []kubiya.Secret, words []string) []orgSearchResult {
+	var results []orgSearchResult
+	for _, s := range secrets {
+		score, matched := matchSearchFields(words, []searchField{
+			{"name", s.Name, 10},
+			{"description", s.Description, 5},
+		})
+		if score == 0 {
+			continue
+		}
+		var owners []string
+		if s.CreatedBy != "" {
+			owners = []string{s.CreatedBy}
+		}
+		results = append(results, orgSearchResult{
+			Type: "secret", Name: s.Name, Description: s.Description, Owners: owners,
+			MatchedOn: matched, Score: score,
+			Actions: []searchAction{{"show", fmt.Sprintf("kubiya secret get %s", s.Name)}},
+		})
+	}
+	return results
+}
+
+func printSearchResults(w io.Writer, query string, results []orgSearchResult, errs map[string]error) {
+	for _, t := range searchTypes {
+		if err, ok := errs[t]; ok {
+			fmt.Fprintf(w, "%s\n", style.WarningStyle.Render(fmt.Sprintf("⚠️  Could not search %ss: %v", t, err)))
+		}
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(w, "%s No results for %q\n", style.InfoStyle.Render("ℹ️"), query)
+		return
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", style.TitleStyle.Render(fmt.Sprintf(" 🔎 %d results for %q ", len(results), query)))
+	for _, r := range results {
+		fmt.Fprintf(w, "%s %s %s", searchTypeIcons[r.Type], style.DimStyle.Render(fmt.Sprintf("%-9s", r.Type)), style.HighlightStyle.Render(r.Name))
+		if r.UUID != "" && r.Type != "tool" {
+			fmt.Fprintf(w, " %s", style.DimStyle.Render("("+r.UUID+")"))
+		}
+		fmt.Fprintln(w)
+		if r.Description != "" {
+			fmt.Fprintf(w, "   %s\n", r.Description)
+		}
+		if r.Source != "" {
+			fmt.Fprintf(w, "   Source: %s\n", r.Source)
+		}
+		if len(r.Agents) > 0 {
+			fmt.Fprintf(w, "   Agents: %s\n", strings.Join(r.Agents, ", "))
+		}
+		if len(r.Owners) > 0 {
+			fmt.Fprintf(w, "   Owners: %s\n", strings.Join(r.Owners, ", "))
+		}
+		for _, a := range r.Actions {
+			fmt.Fprintf(w, "   %s %s\n", style.DimStyle.Render("→ "+a.Label+":"), a.Command)
+		}
+		fmt.Fprintln(w)
+	}
+}
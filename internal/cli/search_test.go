@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeSearchClient serves fixed resources
+type fakeSearchClient struct {
+	agents     []kubiya.Agent
+	sources    []kubiya.Source
+	tools      map[string][]kubiya.Tool
+	knowledge  []kubiya.Knowledge
+	webhooks   []kubiya.Webhook
+	secrets    []kubiya.Secret
+	secretsErr error
+}
+
+func (f *fakeSearchClient) ListAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	return f.agents, nil
+}
+
+func (f *fakeSearchClient) ListSources(ctx context.Context) ([]kubiya.Source, error) {
+	return f.sources, nil
+}
+
+func (f *fakeSearchClient) GetSourceMetadataCached(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	return &kubiya.Source{UUID: uuid, Tools: f.tools[uuid]}, nil
+}
+
+func (f *fakeSearchClient) ListKnowledge(ctx context.Context, query string, limit int) ([]kubiya.Knowledge, error) {
+	return f.knowledge, nil
+}
+
+func (f *fakeSearchClient) ListWebhooks(ctx context.Context) ([]kubiya.Webhook, error) {
+	return f.webhooks, nil
+}
+
+func (f *fakeSearchClient) ListSecrets(ctx context.Context) ([]kubiya.Secret, error) {
+	return f.secrets, f.secretsErr
+}
+
+func newFakeSearchClient() *fakeSearchClient {
+	return &fakeSearchClient{
+		agents: []kubiya.Agent{
+			{UUID: "ag-1", Name: "oncall", Description: "Restarts services", Sources: []string{"src-1"}, Owners: []string{"sre@acme.io"}},
+			{UUID: "ag-2", Name: "billing", Description: "Answers cost questions"},
+		},
+		sources: []kubiya.Source{{UUID: "src-1", Name: "k8s-tools", KubiyaMetadata: kubiya.KubiyaMetadata{UserCreated: "dev@acme.io"}}},
+		tools: map[string][]kubiya.Tool{"src-1": {
+			{Name: "restart-service", Description: "Restart a deployment", Content: "kubectl rollout restart deploy/$service"},
+			{Name: "list-pods", Description: "List pods"},
+		}},
+		knowledge: []kubiya.Knowledge{{UUID: "kn-1", Name: "Restart runbook", Content: "How to restart the api", Owner: "sre@acme.io"}},
+		webhooks:  []kubiya.Webhook{{ID: "wh-1", Name: "alerts", Source: "datadog"}},
+		secrets:   []kubiya.Secret{{Name: "RESTART_TOKEN", Description: "Token to restart services"}},
+	}
+}
+
+func TestSearchOrg(t *testing.T) {
+	results, errs := searchOrg(context.Background(), newFakeSearchClient(), "restart", searchTypes)
+	require.Empty(t, errs)
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Type+":"+r.Name)
+	}
+	assert.ElementsMatch(t, []string{"agent:oncall", "tool:restart-service", "knowledge:Restart runbook", "secret:RESTART_TOKEN"}, names)
+
+	// Name matches rank before description matches
+	assert.Equal(t, "name", results[0].MatchedOn)
+	for _, r := range results {
+		if r.Type == "tool" {
+			assert.Equal(t, "k8s-tools", r.Source)
+			assert.Equal(t, []string{"oncall"}, r.Agents)
+			assert.Equal(t, []string{"sre@acme.io", "dev@acme.io"}, r.Owners)
+			assert.Contains(t, r.Actions[0].Command, "kubiya tool exec --name restart-service --source-uuid src-1")
+		}
+	}
+
+	// Every word must match
+	results, _ = searchOrg(context.Background(), newFakeSearchClient(), "restart pods", searchTypes)
+	assert.Empty(t, results)
+}
+
+func TestSearchOrgTypesAndErrors(t *testing.T) {
+	client := newFakeSearchClient()
+	client.secretsErr = fmt.Errorf("forbidden")
+
+	results, errs := searchOrg(context.Background(), client, "restart", []string{"tool", "secret"})
+	require.Len(t, results, 1)
+	assert.Equal(t, "tool", results[0].Type)
+	assert.EqualError(t, errs["secret"], "forbidden")
+
+	var out bytes.Buffer
+	printSearchResults(&out, "restart", results, errs)
+	assert.Contains(t, out.String(), "Could not search secrets: forbidden")
+	assert.Contains(t, out.String(), "Agents: oncall")
+	assert.Contains(t, out.String(), "→ execute:")
+}
+
+func TestMatchSearchFields(t *testing.T) {
+	score, matched := matchSearchFields([]string{"api"}, []searchField{{"name", "API", 10}, {"description", "the api", 5}})
+	assert.Equal(t, 110, score, "exact names rank first")
+	assert.Equal(t, "name", matched)
+
+	score, _ = matchSearchFields([]string{"api", "db"}, []searchField{{"name", "api", 10}})
+	assert.Zero(t, score)
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// secretAuditLookup is the part of kubiya.Client used by secret audit
+type secretAuditLookup interface {
+	ListSecrets(ctx context.Context) ([]kubiya.Secret, error)
+	GetAgents(ctx context.Context) ([]kubiya.Agent, error)
+	ListSources(ctx context.Context) ([]kubiya.Source, error)
+	GetSourceMetadataCached(ctx context.Context, sourceUUID string) (*kubiya.Source, error)
+}
+
+// secretReference is a secret and what refers to it
+type secretReference struct {
+	Secret       string   `json:"secret"`
+	ReferencedBy []string `json:"referenced_by"`
+}
+
+// secretAuditReport is the result of secret audit
+type secretAuditReport struct {
+	// Missing secrets are referenced but not defined and break at runtime
+	Missing []secretReference `json:"missing"`
+	// Unused secrets are defined but referenced by nothing
+	Unused []string `json:"unused"`
+	// Used secrets are defined and referenced
+	Used []secretReference `json:"used"`
+	// SourceErrors lists the sources whose tools could not be read
+	SourceErrors []string `json:"source_errors,omitempty"`
+}
+
+func newSecretAuditCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "🔍 Find missing and unused secrets",
+		Long: `Cross-reference the secrets used by agents and by the tools of all sources
+against the secrets defined in the organization. Reports the secrets that are
+referenced but not defined, which break at runtime, and the secrets that are
+defined but not referenced, which are candidates for cleanup.`,
+		Example: `  kubiya secret audit
+  kubiya secret audit --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			report, err := auditSecrets(cmd.Context(), kubiya.NewClient(cfg))
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, report)
+			}
+			return printSecretAudit(out, report)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+// auditSecrets collects the secrets referenced by agents and tools and
+// compares them with the defined ones. Sources whose tools cannot be read are
+// reported rather than failing the audit.
+func auditSecrets(ctx context.Context, client secretAuditLookup) (*secretAuditReport, error) {
+	secrets, err := client.ListSecrets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	agents, err := client.GetAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string][]string{}
+	addRef := func(secret, by string) {
+		if secret = strings.TrimSpace(secret); secret != "" && !contains(refs[secret], by) {
+			refs[secret] = append(refs[secret], by)
+		}
+	}
+
+	for _, agent := range agents {
+		for _, s := range agent.Secrets {
+			addRef(s, "agent:"+agent.Name)
+		}
+	}
+
+	report := &secretAuditReport{Missing: []secretReference{}, Unused: []string{}, Used: []secretReference{}}
+	for _, source := range sources {
+		name := source.Name
+		if name == "" {
+			name = source.UUID
+		}
+		metadata, err := client.GetSourceMetadataCached(ctx, source.UUID)
+		if err != nil {
+			report.SourceErrors = append(report.SourceErrors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, tool := range append(metadata.Tools, metadata.InlineTools...) {
+			for _, s := range tool.Secrets {
+				addRef(s, fmt.Sprintf("tool:%s (source %s)", tool.Name, name))
+			}
+		}
+	}
+
+	defined := map[string]bool{}
+	for _, s := range secrets {
+		defined[s.Name] = true
+		if _, ok := refs[s.Name]; !ok {
+			report.Unused = append(report.Unused, s.Name)
+		}
+	}
+	for secret, by := range refs {
+		sort.Strings(by)
+		ref := secretReference{Secret: secret, ReferencedBy: by}
+		if defined[secret] {
+			report.Used = append(report.Used, ref)
+		} else {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+
+	sort.Strings(report.Unused)
+	for _, list := range [][]secretReference{report.Missing, report.Used} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Secret < list[j].Secret })
+	}
+	return report, nil
+}
+
+func printSecretAudit(out io.Writer, report *secretAuditReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "%s\n", style.ErrorStyle.Render(fmt.Sprintf("❌ MISSING (%d) - referenced but not defined", len(report.Missing))))
+	if len(report.Missing) > 0 {
+		fmt.Fprintln(w, "SECRET\tREFERENCED BY")
+		for _, ref := range report.Missing {
+			fmt.Fprintf(w, "%s\t%s\n", ref.Secret, strings.Join(ref.ReferencedBy, ", "))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", style.WarningStyle.Render(fmt.Sprintf("⚠️ UNUSED (%d) - defined but not referenced", len(report.Unused))))
+	for _, name := range report.Unused {
+		fmt.Fprintln(w, name)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", style.SuccessStyle.Render(fmt.Sprintf("✓ IN USE (%d)", len(report.Used))))
+	if len(report.Used) > 0 {
+		fmt.Fprintln(w, "SECRET\tREFERENCED BY")
+		for _, ref := range report.Used {
+			fmt.Fprintf(w, "%s\t%s\n", ref.Secret, strings.Join(ref.ReferencedBy, ", "))
+		}
+	}
+
+	if len(report.SourceErrors) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.DimStyle.Render("Sources that could not be read, their tools were not audited:"))
+		for _, e := range report.SourceErrors {
+			fmt.Fprintf(w, "  • %s\n", e)
+		}
+	}
+	return w.Flush()
+}
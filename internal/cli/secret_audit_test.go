@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeSecretAuditLookup struct {
+	secrets []kubiya.Secret
+	agents  []kubiya.Agent
+	sources map[string]*kubiya.Source
+}
+
+func (f *fakeSecretAuditLookup) ListSecrets(ctx context.Context) ([]kubiya.Secret, error) {
+	return f.secrets, nil
+}
+
+func (f *fakeSecretAuditLookup) GetAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	return f.agents, nil
+}
+
+func (f *fakeSecretAuditLookup) ListSources(ctx context.Context) ([]kubiya.Source, error) {
+	return []kubiya.Source{{UUID: "src-1", Name: "k8s"}, {UUID: "src-2", Name: "broken"}}, nil
+}
+
+func (f *fakeSecretAuditLookup) GetSourceMetadataCached(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	if source, ok := f.sources[uuid]; ok {
+		return source, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestAuditSecrets(t *testing.T) {
+	client := &fakeSecretAuditLookup{
+		secrets: []kubiya.Secret{{Name: "GH_TOKEN"}, {Name: "OLD_KEY"}, {Name: "KUBECONFIG"}},
+		agents: []kubiya.Agent{
+			{Name: "ops", Secrets: []string{"GH_TOKEN", "PAGERDUTY_KEY"}},
+		},
+		sources: map[string]*kubiya.Source{
+			"src-1": {Tools: []kubiya.Tool{
+				{Name: "kubectl", Secrets: []string{"KUBECONFIG"}},
+				{Name: "gh", Secrets: []string{"GH_TOKEN"}},
+			}},
+		},
+	}
+
+	report, err := auditSecrets(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.Equal(t, []secretReference{{Secret: "PAGERDUTY_KEY", ReferencedBy: []string{"agent:ops"}}}, report.Missing)
+	assert.Equal(t, []string{"OLD_KEY"}, report.Unused)
+	require.Len(t, report.Used, 2)
+	assert.Equal(t, "GH_TOKEN", report.Used[0].Secret)
+	assert.Equal(t, []string{"agent:ops", "tool:gh (source k8s)"}, report.Used[0].ReferencedBy)
+	assert.Equal(t, []string{"broken: not found"}, report.SourceErrors)
+}
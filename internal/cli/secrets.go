@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -29,6 +28,7 @@ func newSecretsCommand(cfg *config.Config) *cobra.Command {
 		newUpdateSecretCommand(cfg),
 		newDeleteSecretCommand(cfg),
 		newEditSecretCommand(cfg),
+		newSecretAuditCommand(cfg),
 	)
 
 	return cmd
@@ -50,7 +50,7 @@ func newListSecretsCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(secrets)
+				return printJSONList(secrets)
 			case "text":
 				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 				fmt.Fprintln(w, "🔒 SECRETS")
@@ -95,7 +95,7 @@ func newGetSecretValueCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(map[string]string{"value": value})
+				return printJSON(map[string]string{"value": value})
 			default:
 				fmt.Printf("Value: %s\n", value)
 				return nil
@@ -129,7 +129,7 @@ func newGetSecretCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(secret)
+				return printJSON(secret)
 			default:
 				fmt.Printf("Name: %s\n", secret.Name)
 				fmt.Printf("Created By: %s\n", secret.CreatedBy)
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+func newServeCommand(cfg *config.Config) *cobra.Command {
+	var (
+		reconcile bool
+		dir       string
+		gitURL    string
+		gitBranch string
+		interval  time.Duration
+		addr      string
+		once      bool
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "🔁 Continuously reconcile declarative manifests with the platform",
+		Long: `Run the CLI as a long-lived daemon that keeps agents, sources and webhooks in
+sync with a directory (or Git repository) of YAML manifests.
+
+Each manifest declares one resource by name; several can share a file,
+separated by "---":
+
+  kind: Source
+  name: k8s-tools
+  spec:
+    url: https://github.com/kubiyabot/community-tools/tree/main/kubernetes
+  ---
+  kind: Agent
+  name: k8s-helper
+  spec:
+    description: Helps with Kubernetes
+    ai_instructions: You are a Kubernetes expert.
+    sources: [k8s-tools]
+    runners: [kubiya-hosted]
+  ---
+  kind: Webhook
+  name: alerts
+  spec:
+    agent: k8s-helper
+    source: datadog
+    prompt: Investigate this alert
+    communication: {method: Slack, destination: "#alerts"}
+
+Missing resources are created and drifted ones updated; resources without a
+manifest are left untouched. Prometheus metrics are served on /metrics,
+liveness on /healthz and readiness (after a successful reconcile) on /readyz,
+at localhost:9090 unless --addr says otherwise. In a container, use --addr :9090
+so that probes and scrapers can reach them.`,
+		Example: `  # Reconcile a local directory every minute
+  kubiya serve --reconcile --dir ./kubiya
+
+  # Reconcile from a Git repository, as a sidecar or deployment
+  kubiya serve --reconcile --git-url https://github.com/acme/kubiya-config --interval 5m --addr :9090
+
+  # Show what would change once, without applying it
+  kubiya serve --reconcile --dir ./kubiya --once --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !reconcile {
+				return fmt.Errorf("nothing to serve: use --reconcile, or see 'kubiya serve openai'")
+			}
+			if (dir == "") == (gitURL == "") {
+				return fmt.Errorf("exactly one of --dir or --git-url is required")
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			out := cmd.OutOrStdout()
+			if gitURL != "" {
+				checkout, err := os.MkdirTemp("", "kubiya-reconcile-*")
+				if err != nil {
+					return fmt.Errorf("failed to create checkout directory: %w", err)
+				}
+				defer os.RemoveAll(checkout)
+				if err := gitCheckout(ctx, gitURL, gitBranch, checkout); err != nil {
+					return err
+				}
+				dir = checkout
+			}
+
+			metrics := &reconcileMetrics{}
+			r := &reconciler{client: kubiya.NewClient(cfg), dryRun: dryRun}
+			if once {
+				return runReconcile(ctx, out, r, dir, metrics)
+			}
+
+			srv := &http.Server{Addr: addr, Handler: reconcileHandler(metrics), ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s Metrics server failed: %v\n", style.ErrorStyle.Render("✗"), err)
+				}
+			}()
+			defer srv.Close()
+
+			origin := dir
+			if gitURL != "" {
+				origin = gitURL
+			}
+			fmt.Fprintf(out, "%s Reconciling %s every %s (metrics on %s)\n",
+				style.InfoStyle.Render("🔁"), style.HighlightStyle.Render(origin), interval, addr)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if gitURL != "" {
+					if err := gitPull(ctx, dir); err != nil {
+						fmt.Fprintf(out, "%s %v\n", style.WarningStyle.Render("⚠️"), err)
+					}
+				}
+				if err := runReconcile(ctx, out, r, dir, metrics); err != nil {
+					fmt.Fprintf(out, "%s %v\n", style.ErrorStyle.Render("✗"), err)
+				}
+
+				select {
+				case <-ctx.Done():
+					fmt.Fprintf(out, "%s Stopped\n", style.DimStyle.Render("›"))
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&reconcile, "reconcile", false, "Reconcile manifests with the platform")
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory with YAML manifests")
+	cmd.Flags().StringVar(&gitURL, "git-url", "", "Git repository with YAML manifests, pulled before every reconcile")
+	cmd.Flags().StringVar(&gitBranch, "git-branch", "", "Branch of --git-url (default: the default branch)")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "Time between reconciles")
+	cmd.Flags().StringVar(&addr, "addr", "localhost:9090", "Address of the metrics and health endpoints (e.g. :9090 to listen on all interfaces)")
+	cmd.Flags().BoolVar(&once, "once", false, "Reconcile once and exit, without serving metrics")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report changes without applying them")
+
+	cmd.AddCommand(newServeOpenAICommand(cfg))
+	return cmd
+}
+
+// runReconcile loads the manifests in dir, reconciles them and records the
+// outcome in metrics. It fails when any resource failed to reconcile.
+func runReconcile(ctx context.Context, w io.Writer, r *reconciler, dir string, metrics *reconcileMetrics) error {
+	start := time.Now()
+	manifests, err := loadManifests(dir)
+	if err != nil {
+		metrics.record(nil, err, time.Since(start))
+		return err
+	}
+	results, err := r.reconcile(ctx, manifests)
+	metrics.record(results, err, time.Since(start))
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if r.dryRun {
+		prefix = "(dry run) "
+	}
+	changes := 0
+	summary := &bulkSummary{}
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			summary.fail(res.Resource, res.Err)
+		case res.Action != reconcileUnchanged:
+			changes++
+			summary.ok(res.Resource + " " + res.Action)
+			fmt.Fprintf(w, "%s %s%s %s\n", style.SuccessStyle.Render("✓"), prefix, res.Resource, res.Action)
+		}
+	}
+	fmt.Fprintf(w, "%s %sReconciled %d resources, %d changed (%s)\n",
+		style.DimStyle.Render("›"), prefix, len(results), changes, time.Since(start).Round(time.Millisecond))
+	// Failures are listed together, with the changes of the same run
+	if failed := summary.count(bulkFailed); failed > 0 {
+		fmt.Fprintln(w)
+		summary.print(w)
+		return fmt.Errorf("%d of %d resources failed to reconcile", failed, len(results))
+	}
+	return nil
+}
+
+// reconcileHandler serves /metrics, /healthz and /readyz
+func reconcileHandler(metrics *reconcileMetrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		metrics.write(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !metrics.ready() {
+			http.Error(w, "not reconciled yet", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// gitCheckout clones a shallow copy of url into dir
+func gitCheckout(ctx context.Context, url, branch, dir string) error {
+	args := []string{"clone", "--depth", "1"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(args, url, dir)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0") // Disable interactive prompts
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to clone %s: %w: %s", url, err, out)
+	}
+	return nil
+}
+
+// gitPull fast-forwards the checkout in dir
+func gitPull(ctx context.Context, dir string) error {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "pull", "--ff-only", "--depth", "1")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pull manifests: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// openAISessionHeader continues a Kubiya session instead of replaying the
+// conversation sent with the request
+const openAISessionHeader = "X-Kubiya-Session-Id"
+
+func newServeOpenAICommand(cfg *config.Config) *cobra.Command {
+	var (
+		listen string
+		agent  string
+		apiKey string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "openai",
+		Short: "🔌 Serve an agent over the OpenAI chat completions API",
+		Long: `Expose a Kubiya agent as an OpenAI-compatible API, so tooling and SDKs that
+speak the OpenAI protocol can use the agent without modification.
+
+POST /v1/chat/completions sends the last user message to the agent, with or
+without "stream": true, and GET /v1/models lists the agent as the only model.
+
+The OpenAI protocol is stateless: every request carries the whole
+conversation. Earlier messages are sent to a new Kubiya session along with the
+last one, unless the request has an X-Kubiya-Session-Id header, in which case
+only the last user message is sent to that session. Every response carries
+the header with the session that was used.
+
+Tools are run by the agent, not by the caller. Tool calls of the agent are
+reported as tool_calls of the assistant message for visibility, with finish
+reason "stop", and need no tool results in return.`,
+		Example: `  # Serve an agent on port 8800 of this machine
+  kubiya serve openai --agent abc-123
+
+  # Serve it to other hosts, requiring callers to present a key
+  kubiya serve openai --listen :8800 --agent devops --api-key "$PROXY_KEY"
+
+  # Use it from any OpenAI client
+  OPENAI_BASE_URL=http://localhost:8800/v1 OPENAI_API_KEY="$PROXY_KEY" python app.py`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if agent == "" {
+				return fmt.Errorf("--agent is required")
+			}
+			// Without a key, whoever reaches the server uses the agent with
+			// the credentials of this CLI
+			if apiKey == "" && !isLoopbackAddr(listen) {
+				return fmt.Errorf("--listen %s is reachable from other hosts: set --api-key, or listen on 127.0.0.1", listen)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			client := kubiya.NewClient(cfg)
+			agentID, err := resolveAgentRef(ctx, client, agent)
+			if err != nil {
+				return err
+			}
+
+			server := &openAIServer{client: client, agentID: agentID, model: agent, apiKey: apiKey}
+			srv := &http.Server{Addr: listen, Handler: server.handler(), ReadHeaderTimeout: 10 * time.Second}
+			errCh := make(chan error, 1)
+			go func() { errCh <- srv.ListenAndServe() }()
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s Serving agent %s on http://%s/v1\n",
+				style.InfoStyle.Render("🔌"), style.HighlightStyle.Render(agent), listen)
+			if apiKey == "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%s No --api-key: anyone who can reach %s can use the agent with your credentials\n",
+					style.WarningStyle.Render("⚠️"), listen)
+			}
+
+			select {
+			case err := <-errCh:
+				return fmt.Errorf("server failed: %w", err)
+			case <-ctx.Done():
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("failed to stop server: %w", err)
+			}
+			fmt.Fprintf(out, "%s Stopped\n", style.DimStyle.Render("›"))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8800", "Address to listen on; other than a loopback address it needs --api-key")
+	cmd.Flags().StringVar(&agent, "agent", "", "UUID or name of the agent to serve")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "Key callers must send as a bearer token (default: no authentication)")
+	return cmd
+}
+
+// isLoopbackAddr tells whether a listen address only accepts connections
+// from this machine. An empty host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// openAIServer translates OpenAI chat completion requests into messages to
+// a Kubiya agent
+type openAIServer struct {
+	client  chatSubmitter
+	agentID string
+	// model is the name the agent is listed under in /v1/models
+	model  string
+	apiKey string
+}
+
+type openAIChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+type openAIMessage struct {
+	Role      string           `json:"role"`
+	Content   openAIContent    `json:"content"`
+	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+}
+
+// openAIContent is the content of a message, sent either as a string or as
+// a list of parts of which only text parts are kept
+type openAIContent string
+
+func (c *openAIContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = openAIContent(text)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or a list of parts")
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	*c = openAIContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIChoice struct {
+	Index        int            `json:"index"`
+	Message      *openAIMessage `json:"message,omitempty"`
+	Delta        *openAIMessage `json:"delta,omitempty"`
+	FinishReason *string        `json:"finish_reason"`
+}
+
+type openAICompletion struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+}
+
+func (s *openAIServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", s.authorized(s.handleModels))
+	mux.HandleFunc("/v1/chat/completions", s.authorized(s.handleChatCompletions))
+	return mux
+}
+
+func (s *openAIServer) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.apiKey)) != 1 {
+				writeOpenAIError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (s *openAIServer) handleModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "Use GET")
+		return
+	}
+	writeOpenAIJSON(w, http.StatusOK, map[string]interface{}{
+		"object": "list",
+		"data": []map[string]interface{}{
+			{"id": s.model, "object": "model", "created": 0, "owned_by": "kubiya"},
+		},
+	})
+}
+
+func (s *openAIServer) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "Use POST")
+		return
+	}
+	var req openAIChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	sessionID := r.Header.Get(openAISessionHeader)
+	prompt, err := openAIPrompt(req.Messages, sessionID != "")
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
+	msgChan, err := s.client.SendMessageWithContext(r.Context(), s.agentID, prompt, sessionID, nil)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, "api_error", fmt.Sprintf("Failed to send message: %v", err))
+		return
+	}
+	w.Header().Set(openAISessionHeader, sessionID)
+
+	completion := openAICompletion{
+		ID:      "chatcmpl-" + uuid.New().String(),
+		Created: time.Now().Unix(),
+		Model:   s.model,
+	}
+	if req.Stream {
+		s.stream(w, completion, msgChan)
+		return
+	}
+
+	var content strings.Builder
+	var toolCalls []openAIToolCall
+	err = translateAgentReply(msgChan,
+		func(delta string) { content.WriteString(delta) },
+		func(call openAIToolCall) { toolCalls = append(toolCalls, call) })
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+
+	stop := "stop"
+	completion.Object = "chat.completion"
+	completion.Choices = []openAIChoice{{
+		Message:      &openAIMessage{Role: "assistant", Content: openAIContent(content.String()), ToolCalls: toolCalls},
+		FinishReason: &stop,
+	}}
+	writeOpenAIJSON(w, http.StatusOK, completion)
+}
+
+// stream sends the reply as server-sent chat.completion.chunk events
+func (s *openAIServer) stream(w http.ResponseWriter, completion openAICompletion, msgChan <-chan kubiya.ChatMessage) {
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	completion.Object = "chat.completion.chunk"
+	send := func(v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	chunk := func(delta openAIMessage, finishReason *string) {
+		c := completion
+		c.Choices = []openAIChoice{{Delta: &delta, FinishReason: finishReason}}
+		send(c)
+	}
+
+	chunk(openAIMessage{Role: "assistant"}, nil)
+	tools := 0
+	err := translateAgentReply(msgChan,
+		func(delta string) { chunk(openAIMessage{Content: openAIContent(delta)}, nil) },
+		func(call openAIToolCall) {
+			index := tools
+			tools++
+			call.Index = &index
+			chunk(openAIMessage{ToolCalls: []openAIToolCall{call}}, nil)
+		})
+	if err != nil {
+		send(map[string]interface{}{"error": map[string]string{"message": err.Error(), "type": "api_error"}})
+	} else {
+		stop := "stop"
+		chunk(openAIMessage{}, &stop)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// openAIPrompt is the message sent to the agent: the last user message,
+// preceded by the rest of the conversation unless the session already has it
+func openAIPrompt(messages []openAIMessage, inSession bool) (string, error) {
+	last := -1
+	for i, m := range messages {
+		if m.Role == "user" {
+			last = i
+		}
+	}
+	if last < 0 || strings.TrimSpace(string(messages[last].Content)) == "" {
+		return "", fmt.Errorf("messages must contain a user message")
+	}
+	prompt := string(messages[last].Content)
+	if inSession {
+		return prompt, nil
+	}
+
+	var history []string
+	for _, m := range messages[:last] {
+		if m.Role == "tool" || strings.TrimSpace(string(m.Content)) == "" {
+			continue
+		}
+		history = append(history, fmt.Sprintf("%s: %s", m.Role, m.Content))
+	}
+	if len(history) == 0 {
+		return prompt, nil
+	}
+	return fmt.Sprintf("Conversation so far:\n%s\n\n%s", strings.Join(history, "\n"), prompt), nil
+}
+
+// translateAgentReply reads the reply of the agent, calling text with the
+// new text of every update and tool once per tool call
+func translateAgentReply(msgChan <-chan kubiya.ChatMessage, text func(string), tool func(openAIToolCall)) error {
+	// Messages are streamed whole again with every update
+	contents := map[string]string{}
+	lastID := ""
+	tools := map[string]bool{}
+	for msg := range msgChan {
+		if msg.Type == "error" || msg.Error != "" {
+			return fmt.Errorf("agent error: %s", firstNonEmpty(msg.Error, msg.Content))
+		}
+		switch msg.Type {
+		case "tool":
+			name, args := splitToolCall(msg.Content)
+			if tools[msg.MessageID+name] {
+				continue
+			}
+			tools[msg.MessageID+name] = true
+			call := openAIToolCall{ID: fmt.Sprintf("call_%d", len(tools)), Type: "function"}
+			call.Function.Name = name
+			call.Function.Arguments = args
+			tool(call)
+		case "tool_output", "system", "warning":
+		default:
+			if msg.SenderName == "You" || msg.MessageID == "" {
+				continue
+			}
+			previous := contents[msg.MessageID]
+			if !strings.HasPrefix(msg.Content, previous) {
+				// A rewritten message cannot be taken back from the caller
+				continue
+			}
+			delta := msg.Content[len(previous):]
+			contents[msg.MessageID] = msg.Content
+			if delta == "" {
+				continue
+			}
+			if previous == "" && lastID != "" {
+				delta = "\n\n" + delta
+			}
+			lastID = msg.MessageID
+			text(delta)
+		}
+	}
+	return nil
+}
+
+func writeOpenAIError(w http.ResponseWriter, status int, errType, message string) {
+	writeOpenAIJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "type": errType},
+	})
+}
+
+func writeOpenAIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
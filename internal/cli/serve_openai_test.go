@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func newOpenAITestServer(t *testing.T, chat *scriptedChat, apiKey string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer((&openAIServer{client: chat, agentID: "agent-1", model: "devops", apiKey: apiKey}).handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postCompletion(t *testing.T, url, body string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+"/v1/chat/completions", strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+var openAITestReply = []kubiya.ChatMessage{
+	{Type: "tool", MessageID: "t1", Content: "Tool: kubectl Arguments: {\"args\": \"get pods\"}"},
+	{Type: "tool", MessageID: "t1", Content: "Tool: kubectl Arguments: {\"args\": \"get pods\"}"},
+	{Type: "tool_output", MessageID: "t1", Content: "api-1 Running"},
+	{Type: "text", MessageID: "m1", Content: "All pods"},
+	{Type: "text", MessageID: "m1", Content: "All pods are running.", Final: true},
+}
+
+func TestServeOpenAICompletion(t *testing.T) {
+	chat := &scriptedChat{reply: openAITestReply}
+	srv := newOpenAITestServer(t, chat, "")
+
+	resp := postCompletion(t, srv.URL, `{"model":"devops","messages":[
+		{"role":"system","content":"Be brief"},
+		{"role":"user","content":[{"type":"text","text":"Are the pods ok?"}]}]}`, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(openAISessionHeader))
+
+	var completion openAICompletion
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&completion))
+	assert.Equal(t, "chat.completion", completion.Object)
+	require.Len(t, completion.Choices, 1)
+	msg := completion.Choices[0].Message
+	assert.Equal(t, "All pods are running.", string(msg.Content))
+	require.Len(t, msg.ToolCalls, 1)
+	assert.Equal(t, "kubectl", msg.ToolCalls[0].Function.Name)
+	assert.Equal(t, "stop", *completion.Choices[0].FinishReason)
+
+	assert.Equal(t, []string{"Conversation so far:\nsystem: Be brief\n\nAre the pods ok?"}, chat.messages)
+}
+
+func TestServeOpenAIStream(t *testing.T) {
+	chat := &scriptedChat{reply: openAITestReply}
+	srv := newOpenAITestServer(t, chat, "")
+
+	resp := postCompletion(t, srv.URL, `{"stream":true,"messages":[{"role":"user","content":"Are the pods ok?"}]}`,
+		http.Header{openAISessionHeader: {"s-1"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"s-1"}, chat.sessions)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	require.Equal(t, "data: [DONE]", events[len(events)-1])
+
+	var content strings.Builder
+	var tools []string
+	var finish string
+	for _, event := range events[:len(events)-1] {
+		var chunk openAICompletion
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(event, "data: ")), &chunk))
+		assert.Equal(t, "chat.completion.chunk", chunk.Object)
+		delta := chunk.Choices[0].Delta
+		content.WriteString(string(delta.Content))
+		for _, call := range delta.ToolCalls {
+			tools = append(tools, call.Function.Name)
+		}
+		if chunk.Choices[0].FinishReason != nil {
+			finish = *chunk.Choices[0].FinishReason
+		}
+	}
+	assert.Equal(t, "All pods are running.", content.String())
+	assert.Equal(t, []string{"kubectl"}, tools)
+	assert.Equal(t, "stop", finish)
+}
+
+func TestServeOpenAIErrors(t *testing.T) {
+	chat := &scriptedChat{reply: []kubiya.ChatMessage{{Type: "error", Error: "runner unavailable"}}}
+	srv := newOpenAITestServer(t, chat, "secret")
+
+	resp := postCompletion(t, srv.URL, `{"messages":[{"role":"user","content":"hi"}]}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+	resp = postCompletion(t, srv.URL, `{"messages":[{"role":"system","content":"hi"}]}`, auth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postCompletion(t, srv.URL, `{"messages":[{"role":"user","content":"hi"}]}`, auth)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Contains(t, body.Error.Message, "runner unavailable")
+}
+
+func TestTranslateAgentReplySeparatesMessages(t *testing.T) {
+	ch := make(chan kubiya.ChatMessage, 4)
+	ch <- kubiya.ChatMessage{Type: "text", MessageID: "m1", Content: "Checking."}
+	ch <- kubiya.ChatMessage{Type: "text", MessageID: "m2", Content: ""}
+	ch <- kubiya.ChatMessage{Type: "text", MessageID: "m2", Content: "Done."}
+	ch <- kubiya.ChatMessage{Type: "text", SenderName: "You", MessageID: "u1", Content: "hi"}
+	close(ch)
+
+	var text strings.Builder
+	require.NoError(t, translateAgentReply(ch, func(d string) { text.WriteString(d) }, func(openAIToolCall) {}))
+	assert.Equal(t, "Checking.\n\nDone.", text.String())
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8800": true,
+		"localhost:8800": true,
+		"[::1]:8800":     true,
+		":8800":          false,
+		"0.0.0.0:8800":   false,
+		"10.0.0.5:8800":  false,
+		"8800":           false,
+	} {
+		assert.Equal(t, want, isLoopbackAddr(addr), addr)
+	}
+}
+
+func TestServeOpenAIRefusesPublicListenWithoutKey(t *testing.T) {
+	cmd := newServeOpenAICommand(&config.Config{})
+	cmd.SetArgs([]string{"--agent", "devops", "--listen", ":8800"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--api-key")
+}
@@ -114,8 +114,7 @@ func newCreateSkillCommand(cfg *config.Config) *cobra.Command {
 				skill.ID)
 
 			if outputFormat == "json" {
-				data, _ := json.MarshalIndent(skill, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(skill)
 			} else if outputFormat == "yaml" {
 				data, _ := yaml.Marshal(skill)
 				fmt.Print(string(data))
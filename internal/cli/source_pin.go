@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// commitSHA matches abbreviated and full git commit hashes
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+func newSourcePinCommand(cfg *config.Config) *cobra.Command {
+	var (
+		ref          string
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:          "pin [uuid]",
+		Short:        "📌 Pin the tools of a git source to a commit",
+		SilenceUsage: true,
+		Long: `Pin a git source to a commit, so that its tools and every execution of them
+resolve to that version until the source is unpinned, even when the branch
+moves on. Branches and tags are resolved to the commit they point to now.`,
+		Example: `  # Pin a source to a commit
+  kubiya source pin abc-123 --ref 4f2c1ab
+
+  # Pin a source to the commit a tag points to
+  kubiya source pin abc-123 --ref v1.4.0`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			source, err := client.GetSource(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if source.Type == "inline" || source.URL == "" {
+				return fmt.Errorf("source %s is not a git source, only git sources can be pinned", args[0])
+			}
+
+			commit, err := resolveSourceRef(cmd.Context(), source.URL, ref)
+			if err != nil {
+				return err
+			}
+
+			if _, err := client.UpdateSource(cmd.Context(), source.UUID, kubiya.WithPinnedRef(commit)); err != nil {
+				return fmt.Errorf("failed to pin source: %w", err)
+			}
+			// Reload the tools from the pinned commit
+			synced, err := client.SyncSource(cmd.Context(), source.UUID, kubiya.SyncOptions{Ref: commit}, runnerName)
+			if err != nil {
+				return fmt.Errorf("source pinned to %s, but failed to load its tools: %w", commit, err)
+			}
+			synced.PinnedRef = commit
+
+			if outputFormat == "json" {
+				return printJSON(synced)
+			}
+			fmt.Printf("%s Pinned %s to %s (%d tools)\n", style.SuccessStyle.Render("📌"),
+				style.HighlightStyle.Render(source.Name), style.HighlightStyle.Render(commit), len(synced.Tools))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&ref, "ref", "", "Commit, tag or branch to pin the source to")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	_ = cmd.MarkFlagRequired("ref")
+	return cmd
+}
+
+func newSourceUnpinCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "unpin [uuid]",
+		Short:        "🔓 Let a git source follow its branch again",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			source, err := client.GetSource(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if source.PinnedRef == "" {
+				fmt.Printf("Source %s is not pinned\n", style.HighlightStyle.Render(source.Name))
+				return nil
+			}
+
+			if _, err := client.UpdateSource(cmd.Context(), source.UUID, kubiya.WithPinnedRef("")); err != nil {
+				return fmt.Errorf("failed to unpin source: %w", err)
+			}
+			fmt.Printf("%s Unpinned %s from %s\n", style.SuccessStyle.Render("🔓"),
+				style.HighlightStyle.Render(source.Name), source.PinnedRef)
+			fmt.Printf("%s Run 'kubiya source sync %s' to load the latest tools\n", style.DimStyle.Render("💡"), source.UUID)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// resolveSourceRef returns the commit of ref in the repository of a source.
+// Commit hashes are used as they are, branches and tags are looked up with
+// git ls-remote.
+func resolveSourceRef(ctx context.Context, sourceURL, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("--ref is required")
+	}
+	if commitSHA.MatchString(strings.ToLower(ref)) {
+		return strings.ToLower(ref), nil
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git is needed to resolve %q, pass a commit hash instead", ref)
+	}
+	out, err := exec.CommandContext(ctx, "git", "ls-remote", gitRepoURL(sourceURL), ref, ref+"^{}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q in %s: %w", ref, gitRepoURL(sourceURL), err)
+	}
+
+	var commit string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		// The peeled commit of an annotated tag wins over the tag object
+		if strings.HasSuffix(fields[1], "^{}") || commit == "" {
+			commit = fields[0]
+		}
+	}
+	if commit == "" {
+		return "", fmt.Errorf("no branch or tag %q in %s", ref, gitRepoURL(sourceURL))
+	}
+	return commit, nil
+}
+
+// gitRepoURL returns the repository of a source URL, which may point into a
+// directory of it, e.g. https://github.com/org/repo/tree/main/tools
+func gitRepoURL(sourceURL string) string {
+	for _, sep := range []string{"/tree/", "/blob/"} {
+		if i := strings.Index(sourceURL, sep); i >= 0 {
+			return sourceURL[:i]
+		}
+	}
+	return sourceURL
+}
+
+// pinnedTool makes a tool of a pinned source resolve to the pinned commit
+// when it is executed
+func pinnedTool(tool kubiya.Tool, source *kubiya.Source) kubiya.Tool {
+	if source.PinnedRef == "" {
+		return tool
+	}
+	if tool.Source.ID == "" {
+		tool.Source.ID = source.UUID
+	}
+	if tool.Source.URL == "" {
+		tool.Source.URL = source.URL
+	}
+	tool.Source.Ref = source.PinnedRef
+	return tool
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestResolveSourceRefCommit(t *testing.T) {
+	commit, err := resolveSourceRef(context.Background(), "https://github.com/org/repo", "4F2C1AB")
+	require.NoError(t, err)
+	assert.Equal(t, "4f2c1ab", commit)
+
+	_, err = resolveSourceRef(context.Background(), "https://github.com/org/repo", " ")
+	assert.Error(t, err)
+}
+
+func TestResolveSourceRefTagAndBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "first")
+	first := git("rev-parse", "HEAD")
+	git("tag", "-a", "v1", "-m", "v1")
+	git("commit", "-q", "--allow-empty", "-m", "second")
+	second := git("rev-parse", "HEAD")
+
+	commit, err := resolveSourceRef(context.Background(), repo, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, first, commit, "annotated tags resolve to their commit")
+
+	commit, err = resolveSourceRef(context.Background(), repo, "main")
+	require.NoError(t, err)
+	assert.Equal(t, second, commit)
+
+	_, err = resolveSourceRef(context.Background(), repo, "missing")
+	assert.Error(t, err)
+}
+
+func TestGitRepoURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/org/repo", gitRepoURL("https://github.com/org/repo/tree/main/tools/aws"))
+	assert.Equal(t, "https://github.com/org/repo", gitRepoURL("https://github.com/org/repo/blob/main/tools.py"))
+	assert.Equal(t, "https://github.com/org/repo", gitRepoURL("https://github.com/org/repo"))
+}
+
+func TestPinnedTool(t *testing.T) {
+	source := &kubiya.Source{UUID: "src-1", URL: "https://github.com/org/repo"}
+	tool := kubiya.Tool{Name: "deploy"}
+	assert.Equal(t, tool, pinnedTool(tool, source))
+
+	source.PinnedRef = "4f2c1ab"
+	pinned := pinnedTool(tool, source)
+	assert.Equal(t, kubiya.ToolSource{ID: "src-1", URL: "https://github.com/org/repo", Ref: "4f2c1ab"}, pinned.Source)
+}
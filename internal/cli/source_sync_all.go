@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// Outcomes of syncing a single source
+const (
+	sourceSyncUpdated   = "updated"
+	sourceSyncUnchanged = "unchanged"
+	sourceSyncFailed    = "failed"
+	sourceSyncSkipped   = "skipped"
+)
+
+// sourceSyncer is the part of kubiya.Client used by syncAllSources
+type sourceSyncer interface {
+	SyncSource(ctx context.Context, sourceID string, opts kubiya.SyncOptions, runnerName string) (*kubiya.Source, error)
+}
+
+// sourceSyncResult is the outcome of syncing one source
+type sourceSyncResult struct {
+	Source   kubiya.Source
+	Status   string
+	Tools    int
+	Delta    int
+	Duration time.Duration
+	Err      error
+}
+
+// syncAllSources syncs sources with a pool of concurrency workers and reports
+// progress through progress. Inline sources have nothing to sync and are
+// skipped. Results are returned in the order of sources.
+func syncAllSources(ctx context.Context, client sourceSyncer, sources []kubiya.Source, opts kubiya.SyncOptions, runner string, concurrency int, progress *toolProgressRenderer) []sourceSyncResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]sourceSyncResult, len(sources))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = syncOneSource(ctx, client, sources[i], opts, runner, progress)
+			}
+		}()
+	}
+
+	for i := range sources {
+		if sources[i].Type == "inline" {
+			results[i] = sourceSyncResult{Source: sources[i], Status: sourceSyncSkipped, Tools: sourceToolCount(sources[i])}
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i] = sourceSyncResult{Source: sources[i], Status: sourceSyncFailed, Err: ctx.Err()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func syncOneSource(ctx context.Context, client sourceSyncer, source kubiya.Source, opts kubiya.SyncOptions, runner string, progress *toolProgressRenderer) sourceSyncResult {
+	name := source.Name
+	if name == "" {
+		name = source.UUID
+	}
+	progress.SetRow(source.UUID, name, "syncing...")
+	defer progress.RemoveRow(source.UUID)
+
+	if source.PinnedRef != "" {
+		opts.Ref = source.PinnedRef
+	}
+	start := time.Now()
+	synced, err := client.SyncSource(ctx, source.UUID, opts, runner)
+	result := sourceSyncResult{Source: source, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = sourceSyncFailed
+		result.Err = err
+		fmt.Fprintf(progress, "%s %s %s\n", style.ErrorStyle.Render("✗"), name, style.DimStyle.Render(err.Error()))
+		return result
+	}
+
+	result.Tools = len(synced.Tools)
+	result.Delta = result.Tools - sourceToolCount(source)
+	result.Status = sourceSyncUnchanged
+	if sourceToolsChanged(source, *synced) {
+		result.Status = sourceSyncUpdated
+	}
+	fmt.Fprintf(progress, "%s %s %s\n", style.SuccessStyle.Render("✓"), name,
+		style.DimStyle.Render(fmt.Sprintf("%s, %d tools (%.1fs)", result.Status, result.Tools, result.Duration.Seconds())))
+	return result
+}
+
+// sourceToolCount returns the number of tools of a listed source, which may
+// only carry a count instead of the tools themselves
+func sourceToolCount(s kubiya.Source) int {
+	if n := len(s.Tools) + len(s.InlineTools); n > 0 {
+		return n
+	}
+	return s.ConnectedToolsCount
+}
+
+// sourceToolsChanged reports whether a sync changed the tools of a source.
+// Tool names are compared when the listing included them, counts otherwise.
+func sourceToolsChanged(before, after kubiya.Source) bool {
+	if len(before.Tools) == 0 {
+		return sourceToolCount(before) != len(after.Tools)
+	}
+	if len(before.Tools) != len(after.Tools) {
+		return true
+	}
+	names := make(map[string]bool, len(before.Tools))
+	for _, t := range before.Tools {
+		names[t.Name] = true
+	}
+	for _, t := range after.Tools {
+		if !names[t.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// printSourceSyncSummary prints a table of all results followed by totals per
+// status. Failed sources are listed first.
+func printSourceSyncSummary(w io.Writer, results []sourceSyncResult) {
+	sorted := append([]sourceSyncResult(nil), results...)
+	order := map[string]int{sourceSyncFailed: 0, sourceSyncUpdated: 1, sourceSyncUnchanged: 2, sourceSyncSkipped: 3}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return order[sorted[i].Status] < order[sorted[j].Status]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tSTATUS\tTOOLS\tCHANGE\tDURATION\tDETAILS")
+	counts := map[string]int{}
+	for _, r := range sorted {
+		counts[r.Status]++
+
+		status := r.Status
+		switch r.Status {
+		case sourceSyncFailed:
+			status = style.ErrorStyle.Render(status)
+		case sourceSyncUpdated:
+			status = style.SuccessStyle.Render(status)
+		default:
+			status = style.DimStyle.Render(status)
+		}
+
+		change, duration, details := "-", "-", ""
+		if r.Status == sourceSyncUpdated || r.Status == sourceSyncUnchanged {
+			change = fmt.Sprintf("%+d", r.Delta)
+		}
+		if r.Duration > 0 {
+			duration = fmt.Sprintf("%.1fs", r.Duration.Seconds())
+		}
+		if r.Err != nil {
+			details = r.Err.Error()
+			if len(details) > 60 {
+				details = details[:57] + "..."
+			}
+		} else if r.Status == sourceSyncSkipped {
+			details = "inline source"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", r.Source.Name, status, r.Tools, change, duration, details)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d updated, %d unchanged, %d failed, %d skipped\n",
+		counts[sourceSyncUpdated], counts[sourceSyncUnchanged], counts[sourceSyncFailed], counts[sourceSyncSkipped])
+}
+
+// printThrottleStats notes how much the API slowed a bulk command down, when
+// it throttled any request
+func printThrottleStats(w io.Writer, stats kubiya.ThrottleStats) {
+	if stats.Throttled == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s The API throttled %d of %d requests: %d retried, %s spent waiting\n",
+		style.WarningStyle.Render("⏳"), stats.Throttled, stats.Requests, stats.Retries, stats.Waited.Round(time.Second))
+}
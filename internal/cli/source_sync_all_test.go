@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeSourceSyncer struct {
+	mu       sync.Mutex
+	active   int32
+	peak     int32
+	tools    map[string][]kubiya.Tool
+	failures map[string]error
+	synced   []string
+	refs     map[string]string
+}
+
+func (f *fakeSourceSyncer) SyncSource(ctx context.Context, id string, opts kubiya.SyncOptions, runner string) (*kubiya.Source, error) {
+	n := atomic.AddInt32(&f.active, 1)
+	defer atomic.AddInt32(&f.active, -1)
+	f.mu.Lock()
+	if n > f.peak {
+		f.peak = n
+	}
+	f.synced = append(f.synced, id)
+	if opts.Ref != "" {
+		if f.refs == nil {
+			f.refs = map[string]string{}
+		}
+		f.refs[id] = opts.Ref
+	}
+	f.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := f.failures[id]; err != nil {
+		return nil, err
+	}
+	return &kubiya.Source{UUID: id, Tools: f.tools[id]}, nil
+}
+
+func TestSyncAllSources(t *testing.T) {
+	tools := func(names ...string) []kubiya.Tool {
+		var out []kubiya.Tool
+		for _, n := range names {
+			out = append(out, kubiya.Tool{Name: n})
+		}
+		return out
+	}
+
+	sources := []kubiya.Source{
+		{UUID: "a", Name: "unchanged", Tools: tools("x", "y"), PinnedRef: "4f2c1ab"},
+		{UUID: "b", Name: "renamed", Tools: tools("x")},
+		{UUID: "c", Name: "counted", ConnectedToolsCount: 1},
+		{UUID: "d", Name: "broken"},
+		{UUID: "e", Name: "inline", Type: "inline", InlineTools: tools("z")},
+	}
+	syncer := &fakeSourceSyncer{
+		tools: map[string][]kubiya.Tool{
+			"a": tools("y", "x"),
+			"b": tools("w"),
+			"c": tools("x", "y", "z"),
+		},
+		failures: map[string]error{"d": fmt.Errorf("git clone failed")},
+	}
+
+	progress := newToolProgressRenderer(io.Discard, false)
+	results := syncAllSources(context.Background(), syncer, sources, kubiya.SyncOptions{}, "", 2, progress)
+
+	want := []struct {
+		status string
+		delta  int
+	}{
+		{sourceSyncUnchanged, 0},
+		{sourceSyncUpdated, 0},
+		{sourceSyncUpdated, 2},
+		{sourceSyncFailed, 0},
+		{sourceSyncSkipped, 0},
+	}
+	for i, w := range want {
+		if results[i].Status != w.status || results[i].Delta != w.delta {
+			t.Errorf("%s: got status %q delta %d, want %q delta %d",
+				sources[i].Name, results[i].Status, results[i].Delta, w.status, w.delta)
+		}
+	}
+	if len(syncer.synced) != 4 {
+		t.Errorf("expected the inline source to be skipped, synced %v", syncer.synced)
+	}
+	if len(syncer.refs) != 1 || syncer.refs["a"] != "4f2c1ab" {
+		t.Errorf("expected the pinned source to sync its commit, got refs %v", syncer.refs)
+	}
+	if syncer.peak > 2 {
+		t.Errorf("expected at most 2 concurrent syncs, got %d", syncer.peak)
+	}
+
+	var out bytes.Buffer
+	printSourceSyncSummary(&out, results)
+	got := out.String()
+	if !strings.Contains(got, "2 updated, 1 unchanged, 1 failed, 1 skipped") {
+		t.Errorf("unexpected totals in summary:\n%s", got)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[1], "broken") {
+		t.Errorf("expected failed sources first, got:\n%s", got)
+	}
+}
+
+func TestPrintThrottleStats(t *testing.T) {
+	var buf bytes.Buffer
+	printThrottleStats(&buf, kubiya.ThrottleStats{Requests: 10})
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing without throttling, got %q", buf.String())
+	}
+
+	printThrottleStats(&buf, kubiya.ThrottleStats{Requests: 12, Throttled: 2, Retries: 2, Waited: 3 * time.Second})
+	if !strings.Contains(buf.String(), "throttled 2 of 12 requests: 2 retried, 3s spent waiting") {
+		t.Errorf("unexpected summary %q", buf.String())
+	}
+}
@@ -0,0 +1,442 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// toolExecutor is the part of kubiya.Client used to run tool tests
+type toolExecutor interface {
+	ExecuteToolWithTimeout(ctx context.Context, toolName string, toolDef map[string]interface{}, runner string, timeout time.Duration, args map[string]any) (<-chan kubiya.WorkflowSSEEvent, error)
+}
+
+// toolTestResult is the outcome of a test of a tool
+type toolTestResult struct {
+	Tool     string        `json:"tool"`
+	Test     string        `json:"test"`
+	Passed   bool          `json:"passed"`
+	ExitCode int           `json:"exit_code"`
+	Failure  string        `json:"failure,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+func newSourceTestCommand(cfg *config.Config) *cobra.Command {
+	var (
+		junitFile    string
+		toolNames    []string
+		timeout      time.Duration
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test [uuid|path]",
+		Short: "🧪 Run the tests of the tools of a source",
+		Long: `Run the tests declared in the tool definitions of a source and report
+whether each tool behaves as expected.
+
+A tool definition may have a tests block. Each test runs the tool with sample
+args, then checks its exit code (0 when not set) and, optionally, that its
+output matches a regular expression:
+
+  tests:
+    - name: lists pods
+      args:
+        namespace: default
+      exit_code: 0
+      output_regex: "NAME\\s+READY"
+
+The source is a source UUID, a tools file or a directory of tools files. Tools
+without tests are left out. Use --junit to write a JUnit report for CI.`,
+		Example: `  # Run the tests of a source on the staging runner
+  kubiya source test abc-123 --runner staging
+
+  # Run the tests of a local tools file and write a JUnit report
+  kubiya source test ./tools.yaml --runner staging --junit out.xml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			ctx := cmd.Context()
+			client := kubiya.NewClient(cfg)
+
+			tools, err := loadToolsForTest(ctx, client, args[0])
+			if err != nil {
+				return err
+			}
+			tools = toolsWithTests(tools, toolNames)
+			if len(tools) == 0 {
+				return fmt.Errorf("no tool of %s has tests", args[0])
+			}
+
+			runner := runnerName
+			if runner == "" {
+				runner = os.Getenv("KUBIYA_DEFAULT_RUNNER")
+			}
+			if runner == "" || runner == "auto" {
+				ranked, err := client.SelectRunner(ctx, kubiya.RunnerSelectOptions{})
+				if err != nil {
+					return err
+				}
+				runner = ranked[0].Runner.Name
+			}
+
+			out := cmd.OutOrStdout()
+			progress := out
+			if outputFormat == "json" {
+				progress = io.Discard
+			}
+			fmt.Fprintf(progress, "%s Running the tests of %d tools on runner %s\n\n",
+				style.InfoStyle.Render("🧪"), len(tools), style.HighlightStyle.Render(runner))
+			results := runToolTests(ctx, client, tools, runner, timeout, progress)
+
+			if junitFile != "" {
+				f, err := os.Create(junitFile)
+				if err != nil {
+					return fmt.Errorf("failed to create JUnit report: %w", err)
+				}
+				err = writeJUnitReport(f, args[0], results)
+				if cerr := f.Close(); err == nil {
+					err = cerr
+				}
+				if err != nil {
+					return fmt.Errorf("failed to write JUnit report: %w", err)
+				}
+			}
+
+			if outputFormat == "json" {
+				if err := output.EncodeJSON(out, results); err != nil {
+					return err
+				}
+			} else {
+				printToolTestResults(out, results)
+			}
+
+			failed := 0
+			for _, r := range results {
+				if !r.Passed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d tool tests failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&junitFile, "junit", "", "Write a JUnit XML report to this file")
+	cmd.Flags().StringSliceVar(&toolNames, "tool", nil, "Only run the tests of these tools (can be specified multiple times)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Timeout of each test")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// loadToolsForTest returns the tools of a source UUID, of a tools file or of
+// the tools files of a directory
+func loadToolsForTest(ctx context.Context, client *kubiya.Client, target string) ([]kubiya.Tool, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		source, err := client.GetSourceMetadata(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s is neither a local path nor a source: %w", target, err)
+		}
+		return append(source.Tools, source.InlineTools...), nil
+	}
+	if !info.IsDir() {
+		return loadToolsFromFile(target)
+	}
+
+	// Files of a directory that are not tools files are ignored
+	var tools []kubiya.Tool
+	err = filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != target && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+			if found, err := loadToolsFromFile(path); err == nil {
+				tools = append(tools, found...)
+			}
+		}
+		return nil
+	})
+	return tools, err
+}
+
+// toolsWithTests keeps the tools having tests, only those named when names
+// are given
+func toolsWithTests(tools []kubiya.Tool, names []string) []kubiya.Tool {
+	var kept []kubiya.Tool
+	for _, t := range tools {
+		if len(t.Tests) == 0 {
+			continue
+		}
+		if len(names) > 0 && !contains(names, t.Name) {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
+
+// runToolTests runs the tests of tools one after the other
+func runToolTests(ctx context.Context, client toolExecutor, tools []kubiya.Tool, runner string, timeout time.Duration, progress io.Writer) []toolTestResult {
+	var results []toolTestResult
+	for _, tool := range tools {
+		for i, test := range tool.Tests {
+			name := test.Name
+			if name == "" {
+				name = fmt.Sprintf("test %d", i+1)
+			}
+			result := runToolTest(ctx, client, tool, test, runner, timeout)
+			result.Test = name
+			if result.Passed {
+				fmt.Fprintf(progress, "%s %s › %s %s\n", style.SuccessStyle.Render("✓"), tool.Name, name,
+					style.DimStyle.Render(fmt.Sprintf("(%.1fs)", result.Duration.Seconds())))
+			} else {
+				fmt.Fprintf(progress, "%s %s › %s %s\n", style.ErrorStyle.Render("✗"), tool.Name, name,
+					style.DimStyle.Render(result.Failure))
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// runToolTest executes tool with the args of test and checks the outcome
+func runToolTest(ctx context.Context, client toolExecutor, tool kubiya.Tool, test kubiya.ToolTest, runner string, timeout time.Duration) (result toolTestResult) {
+	result.Tool = tool.Name
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	toolDef := map[string]interface{}{}
+	tool.Tests = nil
+	data, err := json.Marshal(tool)
+	if err == nil {
+		err = json.Unmarshal(data, &toolDef)
+	}
+	if err != nil {
+		result.Failure = fmt.Sprintf("invalid tool definition: %v", err)
+		return result
+	}
+	args := map[string]any{}
+	for k, v := range test.Args {
+		args[k] = v
+	}
+
+	events, err := client.ExecuteToolWithTimeout(ctx, tool.Name, toolDef, runner, timeout, args)
+	if err != nil {
+		result.Failure = fmt.Sprintf("failed to execute: %v", err)
+		return result
+	}
+	output, exitCode := collectToolRun(events)
+	result.Output = output
+	result.ExitCode = exitCode
+
+	if exitCode != test.ExitCode {
+		result.Failure = fmt.Sprintf("exit code %d, want %d", exitCode, test.ExitCode)
+		return result
+	}
+	if test.OutputRegex != "" {
+		re, err := regexp.Compile(test.OutputRegex)
+		if err != nil {
+			result.Failure = fmt.Sprintf("invalid output_regex: %v", err)
+			return result
+		}
+		if !re.MatchString(output) {
+			result.Failure = fmt.Sprintf("output does not match %q", test.OutputRegex)
+			return result
+		}
+	}
+	result.Passed = true
+	return result
+}
+
+// collectToolRun reads the events of a tool execution and returns its
+// output and exit code. Executions failing without an exit code exit 1.
+func collectToolRun(events <-chan kubiya.WorkflowSSEEvent) (string, int) {
+	var output strings.Builder
+	exitCode := -1
+	failed := false
+
+	for event := range events {
+		if chunk, ok := event.OutputChunk(); ok {
+			output.WriteString(chunk.Content)
+			continue
+		}
+		switch event.Type {
+		case "error":
+			failed = true
+			output.WriteString(event.Data)
+			output.WriteString("\n")
+		case "data":
+			var status struct {
+				Type       string `json:"type"`
+				Status     string `json:"status"`
+				End        bool   `json:"end"`
+				ExitCode   *int   `json:"exit_code"`
+				ExitCodeJS *int   `json:"exitCode"`
+			}
+			if err := json.Unmarshal([]byte(event.Data), &status); err != nil {
+				output.WriteString(event.Data)
+				output.WriteString("\n")
+				continue
+			}
+			if status.Type != "status" || !status.End {
+				continue
+			}
+			switch {
+			case status.ExitCode != nil:
+				exitCode = *status.ExitCode
+			case status.ExitCodeJS != nil:
+				exitCode = *status.ExitCodeJS
+			}
+			if status.Status != "success" {
+				failed = true
+			}
+		}
+	}
+
+	if exitCode < 0 {
+		exitCode = 0
+		if failed {
+			exitCode = 1
+		}
+	}
+	return output.String(), exitCode
+}
+
+func printToolTestResults(w io.Writer, results []toolTestResult) {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tTEST\tRESULT\tEXIT\tDURATION")
+	passed := 0
+	for _, r := range results {
+		status := style.SuccessStyle.Render("pass")
+		if r.Passed {
+			passed++
+		} else {
+			status = style.ErrorStyle.Render("fail")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.1fs\n", r.Tool, r.Test, status, r.ExitCode, r.Duration.Seconds())
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if passed == len(results) {
+		fmt.Fprintf(w, "%s %d tests passed\n", style.SuccessStyle.Render("✅"), passed)
+	} else {
+		fmt.Fprintf(w, "%s %d of %d tests failed\n", style.ErrorStyle.Render("❌"), len(results)-passed, len(results))
+	}
+}
+
+// JUnit report, a test suite per tool
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes results as a JUnit XML report named after source
+func writeJUnitReport(w io.Writer, source string, results []toolTestResult) error {
+	report := junitTestSuites{Name: source}
+	suites := map[string]int{}
+	durations := map[string]time.Duration{}
+	var total time.Duration
+
+	for _, r := range results {
+		i, ok := suites[r.Tool]
+		if !ok {
+			i = len(report.Suites)
+			suites[r.Tool] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: r.Tool})
+		}
+		suite := &report.Suites[i]
+
+		tc := junitTestCase{
+			Name:      r.Test,
+			ClassName: r.Tool,
+			Time:      junitSeconds(r.Duration),
+			SystemOut: r.Output,
+		}
+		if !r.Passed {
+			tc.Failure = &junitFailure{Message: r.Failure, Text: r.Failure}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		report.Tests++
+		durations[r.Tool] += r.Duration
+		total += r.Duration
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = junitSeconds(durations[report.Suites[i].Name])
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// fakeToolExecutor replays events by tool name and records the args
+type fakeToolExecutor struct {
+	events map[string][]kubiya.WorkflowSSEEvent
+	args   []map[string]any
+}
+
+func (f *fakeToolExecutor) ExecuteToolWithTimeout(ctx context.Context, toolName string, toolDef map[string]interface{}, runner string, timeout time.Duration, args map[string]any) (<-chan kubiya.WorkflowSSEEvent, error) {
+	f.args = append(f.args, args)
+	ch := make(chan kubiya.WorkflowSSEEvent, len(f.events[toolName]))
+	for _, e := range f.events[toolName] {
+		ch <- e
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestRunToolTests(t *testing.T) {
+	client := &fakeToolExecutor{events: map[string][]kubiya.WorkflowSSEEvent{
+		"list-pods": {
+			{Type: "data", Data: `{"type":"tool-output","content":"NAME   READY\napi-1  1/1\n"}`},
+			{Type: "data", Data: `{"type":"status","status":"success","end":true}`},
+		},
+		"restart": {
+			{Type: "data", Data: `{"type":"tool-output","content":"permission denied","stream":"stderr"}`},
+			{Type: "data", Data: `{"type":"status","status":"failed","end":true,"exit_code":2}`},
+		},
+	}}
+	tools := []kubiya.Tool{
+		{Name: "list-pods", Tests: []kubiya.ToolTest{
+			{Name: "lists pods", Args: map[string]interface{}{"namespace": "default"}, OutputRegex: `NAME\s+READY`},
+			{OutputRegex: "nginx"},
+		}},
+		{Name: "restart", Tests: []kubiya.ToolTest{{Name: "denied", ExitCode: 2}}},
+	}
+
+	var progress bytes.Buffer
+	results := runToolTests(context.Background(), client, tools, "staging", time.Minute, &progress)
+	require.Len(t, results, 3)
+
+	assert.True(t, results[0].Passed, results[0].Failure)
+	assert.Equal(t, "lists pods", results[0].Test)
+	assert.Equal(t, "default", client.args[0]["namespace"])
+
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "test 2", results[1].Test)
+	assert.Contains(t, results[1].Failure, "does not match")
+
+	assert.True(t, results[2].Passed, results[2].Failure)
+	assert.Equal(t, 2, results[2].ExitCode)
+	assert.Contains(t, progress.String(), "list-pods › lists pods")
+
+	var report bytes.Buffer
+	require.NoError(t, writeJUnitReport(&report, "tools.yaml", results))
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal(report.Bytes(), &parsed))
+	assert.Equal(t, 3, parsed.Tests)
+	assert.Equal(t, 1, parsed.Failures)
+	require.Len(t, parsed.Suites, 2)
+	assert.Equal(t, "list-pods", parsed.Suites[0].Name)
+	require.NotNil(t, parsed.Suites[0].Cases[1].Failure)
+	assert.Nil(t, parsed.Suites[1].Cases[0].Failure)
+}
+
+func TestCollectToolRunExitCode(t *testing.T) {
+	events := make(chan kubiya.WorkflowSSEEvent, 1)
+	events <- kubiya.WorkflowSSEEvent{Type: "error", Data: "runner unreachable"}
+	close(events)
+	output, exitCode := collectToolRun(events)
+	assert.Equal(t, 1, exitCode, "failures without an exit code exit 1")
+	assert.Contains(t, output, "runner unreachable")
+}
+
+func TestLoadToolsForTestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`tools:
+  - name: list-pods
+    description: List pods
+    type: docker
+    image: bitnami/kubectl
+    content: kubectl get pods -n $namespace
+    tests:
+      - name: lists pods
+        args:
+          namespace: default
+        output_regex: "NAME"
+  - name: untested
+    description: No tests
+    content: echo hi
+`), 0644))
+
+	tools, err := loadToolsForTest(context.Background(), nil, path)
+	require.NoError(t, err)
+	tools = toolsWithTests(tools, nil)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "list-pods", tools[0].Name)
+	assert.Equal(t, "default", tools[0].Tests[0].Args["namespace"])
+	assert.Equal(t, "NAME", tools[0].Tests[0].OutputRegex)
+}
@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/mattn/go-isatty"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// toolArgTypes are the types a tool argument may declare
+var toolArgTypes = map[string]bool{
+	"": true, "str": true, "string": true, "int": true, "integer": true, "number": true, "float": true,
+	"bool": true, "boolean": true, "array": true, "list": true, "object": true, "dict": true, "file": true,
+}
+
+// Choices after the preview of a scan
+const (
+	wizardCreate = "Create the source"
+	wizardRescan = "Scan again"
+	wizardCancel = "Cancel"
+	wizardDone   = "Done"
+)
+
+// agentSourceBinder is the part of kubiya.Client used to add a source to
+// agents
+type agentSourceBinder interface {
+	GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error)
+	UpdateAgent(ctx context.Context, uuid string, agent kubiya.Agent) (*kubiya.Agent, error)
+}
+
+// runSourceWizard guides through the creation of a source: where it is,
+// a scan previewing its tools, the creation and the agents using it
+func runSourceWizard(ctx context.Context, client *kubiya.Client, defaultURL, name, runner string, options []kubiya.SourceOption) error {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("--wizard needs an interactive terminal")
+	}
+	fmt.Printf("\n%s\n\n", style.TitleStyle.Render(" 🧙 New Source "))
+
+	repoURL, err := (&promptui.Prompt{Label: "Repository URL", Default: defaultURL, Validate: validateWizardURL}).Run()
+	if err != nil {
+		return wizardPromptError(err)
+	}
+	branch, err := (&promptui.Prompt{Label: "Branch (empty for the default branch)"}).Run()
+	if err != nil {
+		return wizardPromptError(err)
+	}
+	dir, err := (&promptui.Prompt{Label: "Path of the tools in the repository (empty for the root)"}).Run()
+	if err != nil {
+		return wizardPromptError(err)
+	}
+	sourceURL := wizardSourceURL(repoURL, branch, dir)
+
+	if runner == "" {
+		if runner, err = selectWizardRunner(ctx, client); err != nil {
+			return err
+		}
+	}
+	if name == "" {
+		if name, err = (&promptui.Prompt{Label: "Source name", Default: wizardSourceName(sourceURL)}).Run(); err != nil {
+			return wizardPromptError(err)
+		}
+	}
+
+	// Scan until the tools look right
+	for {
+		fmt.Printf("\n%s Scanning %s...\n", style.InfoStyle.Render("🔍"), sourceURL)
+		discovery, err := client.DiscoverSource(ctx, sourceURL, nil, runner, nil)
+		var scanErrors []kubiya.SourceError
+		if err != nil {
+			var found *kubiya.SourceDiscoveryResponse
+			if !errors.As(err, &found) {
+				return fmt.Errorf("failed to scan %s: %w", sourceURL, err)
+			}
+			scanErrors = found.Errors
+		}
+		problems := printSourceWizardPreview(os.Stdout, discovery.Tools, scanErrors)
+
+		label := "What next?"
+		if problems > 0 {
+			label = fmt.Sprintf("%d problems were found. What next?", problems)
+		}
+		_, choice, err := (&promptui.Select{Label: label, Items: []string{wizardCreate, wizardRescan, wizardCancel}}).Run()
+		if err != nil {
+			return wizardPromptError(err)
+		}
+		if choice == wizardCancel {
+			return fmt.Errorf("operation cancelled")
+		}
+		if choice == wizardCreate {
+			break
+		}
+	}
+
+	options = append(options, kubiya.WithName(name))
+	if runner != "" {
+		options = append(options, kubiya.WithRunner(runner))
+	}
+	created, err := client.CreateSource(ctx, sourceURL, options...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", style.SuccessStyle.Render("✅ Source added successfully!"))
+	fmt.Printf("UUID: %s\n", created.UUID)
+	fmt.Printf("Tools: %d\n\n", len(created.Tools))
+
+	agents, err := client.ListAgents(ctx)
+	if err != nil || len(agents) == 0 {
+		return nil
+	}
+	selected, err := selectWizardAgents(agents)
+	if err != nil {
+		return err
+	}
+	bound, err := bindSourceToAgents(ctx, client, created.UUID, selected)
+	for _, a := range bound {
+		fmt.Printf("%s Added to agent %s\n", style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(a))
+	}
+	return err
+}
+
+func wizardPromptError(err error) error {
+	if errors.Is(err, promptui.ErrInterrupt) || errors.Is(err, promptui.ErrEOF) {
+		return fmt.Errorf("operation cancelled")
+	}
+	return fmt.Errorf("prompt failed: %w", err)
+}
+
+func validateWizardURL(s string) error {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("enter an http(s) repository URL")
+	}
+	return nil
+}
+
+// wizardSourceURL points at the tools of a branch and directory of a
+// repository, the way GitHub URLs address them
+func wizardSourceURL(repoURL, branch, dir string) string {
+	u := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repoURL), "/"), ".git")
+	branch = strings.TrimSpace(branch)
+	dir = strings.Trim(strings.TrimSpace(dir), "/")
+	if branch == "" && dir == "" {
+		return u
+	}
+	if branch == "" {
+		branch = "main"
+	}
+	u += "/tree/" + branch
+	if dir != "" {
+		u += "/" + dir
+	}
+	return u
+}
+
+// wizardSourceName names a source after its repository and directory
+func wizardSourceName(sourceURL string) string {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return path.Base(u.Path)
+	}
+	name := parts[1]
+	if len(parts) > 4 && parts[2] == "tree" {
+		name += "/" + strings.Join(parts[4:], "/")
+	}
+	return name
+}
+
+func selectWizardRunner(ctx context.Context, client *kubiya.Client) (string, error) {
+	runners, err := client.ListRunners(ctx)
+	if err != nil || len(runners) == 0 {
+		return "", nil
+	}
+	items := []string{"(default runner)"}
+	for _, r := range runners {
+		if isRunnerHealthy(r) {
+			items = append(items, r.Name)
+		}
+	}
+	_, choice, err := (&promptui.Select{Label: "Runner to scan and run the tools on", Items: items}).Run()
+	if err != nil {
+		return "", wizardPromptError(err)
+	}
+	if choice == items[0] {
+		return "", nil
+	}
+	return choice, nil
+}
+
+// selectWizardAgents lets the user pick agents one at a time until Done
+func selectWizardAgents(agents []kubiya.Agent) ([]kubiya.Agent, error) {
+	picked := map[int]bool{}
+	for {
+		items := []string{wizardDone}
+		for i, a := range agents {
+			mark := "  "
+			if picked[i] {
+				mark = "✓ "
+			}
+			items = append(items, mark+a.Name)
+		}
+		i, _, err := (&promptui.Select{Label: "Add the source to agents (select to toggle)", Items: items, Size: 10}).Run()
+		if err != nil {
+			return nil, wizardPromptError(err)
+		}
+		if i == 0 {
+			break
+		}
+		picked[i-1] = !picked[i-1]
+	}
+
+	var selected []kubiya.Agent
+	for i, a := range agents {
+		if picked[i] {
+			selected = append(selected, a)
+		}
+	}
+	return selected, nil
+}
+
+// bindSourceToAgents adds a source to agents and returns the names of the
+// agents it was added to
+func bindSourceToAgents(ctx context.Context, client agentSourceBinder, sourceUUID string, agents []kubiya.Agent) ([]string, error) {
+	var bound []string
+	for _, a := range agents {
+		id := a.UUID
+		if id == "" {
+			id = a.ID
+		}
+		agent, err := client.GetAgent(ctx, id)
+		if err != nil {
+			return bound, fmt.Errorf("failed to get agent %s: %w", a.Name, err)
+		}
+		if !contains(agent.Sources, sourceUUID) {
+			agent.Sources = append(agent.Sources, sourceUUID)
+			if _, err := client.UpdateAgent(ctx, id, *agent); err != nil {
+				return bound, fmt.Errorf("failed to add the source to agent %s: %w", a.Name, err)
+			}
+		}
+		bound = append(bound, a.Name)
+	}
+	return bound, nil
+}
+
+// toolSchemaProblems checks the argument schema of a tool
+func toolSchemaProblems(tool kubiya.Tool) []string {
+	var problems []string
+	if tool.Name == "" {
+		problems = append(problems, "tool has no name")
+	}
+	seen := map[string]bool{}
+	for i, arg := range tool.Args {
+		if arg.Name == "" {
+			problems = append(problems, fmt.Sprintf("argument %d has no name", i+1))
+			continue
+		}
+		if seen[arg.Name] {
+			problems = append(problems, fmt.Sprintf("argument %s is declared twice", arg.Name))
+		}
+		seen[arg.Name] = true
+		if !toolArgTypes[strings.ToLower(arg.Type)] {
+			problems = append(problems, fmt.Sprintf("argument %s has unknown type %q", arg.Name, arg.Type))
+		}
+		if arg.Default != "" && len(arg.Options) > 0 && !contains(arg.Options, arg.Default) {
+			problems = append(problems, fmt.Sprintf("default %q of argument %s is not one of its options", arg.Default, arg.Name))
+		}
+	}
+	return problems
+}
+
+// printSourceWizardPreview shows the discovered tools with their argument
+// schema and problems, and returns the number of problems
+func printSourceWizardPreview(w io.Writer, tools []kubiya.Tool, scanErrors []kubiya.SourceError) int {
+	problems := len(scanErrors)
+	fmt.Fprintf(w, "\n%s\n\n", style.TitleStyle.Render(fmt.Sprintf(" 📦 %d tools found ", len(tools))))
+
+	for _, tool := range tools {
+		fmt.Fprintf(w, "• %s\n", style.HighlightStyle.Render(tool.Name))
+		if tool.Description != "" {
+			fmt.Fprintf(w, "  %s\n", tool.Description)
+		}
+		for _, arg := range tool.Args {
+			typ := arg.Type
+			if typ == "" {
+				typ = "string"
+			}
+			details := typ
+			if arg.Required {
+				details += ", required"
+			}
+			if arg.Default != "" {
+				details += fmt.Sprintf(", default %q", arg.Default)
+			}
+			if len(arg.Options) > 0 {
+				details += ", one of " + strings.Join(arg.Options, "|")
+			}
+			fmt.Fprintf(w, "    %s %s\n", arg.Name, style.DimStyle.Render("("+details+")"))
+		}
+		for _, p := range toolSchemaProblems(tool) {
+			problems++
+			fmt.Fprintf(w, "    %s\n", style.ErrorStyle.Render("✗ "+p))
+		}
+	}
+
+	if len(scanErrors) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.ErrorStyle.Render("Scan errors:"))
+		for _, e := range scanErrors {
+			fmt.Fprintf(w, "  %s %s: %s\n", style.ErrorStyle.Render("✗"), e.File, e.Error)
+			if e.Details != "" {
+				fmt.Fprintf(w, "    %s\n", style.DimStyle.Render(e.Details))
+			}
+		}
+	}
+	fmt.Fprintln(w)
+	return problems
+}
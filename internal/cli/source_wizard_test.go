@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestWizardSourceURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/org/tools", wizardSourceURL("https://github.com/org/tools.git", "", ""))
+	assert.Equal(t, "https://github.com/org/tools/tree/dev", wizardSourceURL("https://github.com/org/tools/", "dev", ""))
+	assert.Equal(t, "https://github.com/org/tools/tree/main/k8s/tools", wizardSourceURL("https://github.com/org/tools", "", "/k8s/tools/"))
+
+	assert.Equal(t, "tools", wizardSourceName("https://github.com/org/tools"))
+	assert.Equal(t, "tools/k8s", wizardSourceName("https://github.com/org/tools/tree/main/k8s"))
+
+	assert.NoError(t, validateWizardURL("https://github.com/org/tools"))
+	assert.Error(t, validateWizardURL("github.com/org/tools"))
+}
+
+func TestSourceWizardPreview(t *testing.T) {
+	tools := []kubiya.Tool{
+		{Name: "deploy", Description: "Deploy a service", Args: []kubiya.ToolArg{
+			{Name: "service", Required: true},
+			{Name: "env", Default: "qa", Options: []string{"staging", "prod"}},
+			{Name: "replicas", Type: "numbr"},
+			{Name: "service"},
+		}},
+		{Name: "status", Args: []kubiya.ToolArg{{Name: "service", Type: "string"}}},
+	}
+	scanErrors := []kubiya.SourceError{{File: "broken.py", Type: "SyntaxError", Error: "invalid syntax"}}
+
+	var out bytes.Buffer
+	problems := printSourceWizardPreview(&out, tools, scanErrors)
+	assert.Equal(t, 4, problems)
+	assert.Contains(t, out.String(), "(string, required)")
+	assert.Contains(t, out.String(), `default "qa" of argument env is not one of its options`)
+	assert.Contains(t, out.String(), `unknown type "numbr"`)
+	assert.Contains(t, out.String(), "argument service is declared twice")
+	assert.Contains(t, out.String(), "broken.py: invalid syntax")
+
+	assert.Empty(t, toolSchemaProblems(tools[1]))
+}
+
+// fakeAgentBinder keeps agents in memory
+type fakeAgentBinder struct {
+	agents  map[string]*kubiya.Agent
+	updates int
+}
+
+func (f *fakeAgentBinder) GetAgent(ctx context.Context, id string) (*kubiya.Agent, error) {
+	a, ok := f.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("agent not found: %s", id)
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (f *fakeAgentBinder) UpdateAgent(ctx context.Context, id string, agent kubiya.Agent) (*kubiya.Agent, error) {
+	f.updates++
+	f.agents[id] = &agent
+	return &agent, nil
+}
+
+func TestBindSourceToAgents(t *testing.T) {
+	client := &fakeAgentBinder{agents: map[string]*kubiya.Agent{
+		"a-1": {UUID: "a-1", Name: "devops", Sources: []string{"src-old"}},
+		"a-2": {UUID: "a-2", Name: "sre", Sources: []string{"src-new"}},
+	}}
+
+	bound, err := bindSourceToAgents(context.Background(), client, "src-new", []kubiya.Agent{
+		{UUID: "a-1", Name: "devops"}, {UUID: "a-2", Name: "sre"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"devops", "sre"}, bound)
+	assert.Equal(t, []string{"src-old", "src-new"}, client.agents["a-1"].Sources)
+	assert.Equal(t, 1, client.updates, "agents having the source are not updated")
+
+	_, err = bindSourceToAgents(context.Background(), client, "src-new", []kubiya.Agent{{UUID: "missing", Name: "gone"}})
+	assert.Error(t, err)
+}
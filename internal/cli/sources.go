@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"text/tabwriter"
@@ -19,9 +20,12 @@ import (
 
 	"github.com/briandowns/spinner"
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/docload"
+	"github.com/kubiyabot/cli/internal/httpclient"
 	"github.com/kubiyabot/cli/internal/kubiya"
 	"github.com/kubiyabot/cli/internal/style"
 	"github.com/kubiyabot/cli/internal/util"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -60,12 +64,16 @@ Sources contain the tools and capabilities that your agents can use.`,
 		newListSourcesCommand(cfg),
 		newScanSourceCommand(cfg),
 		newAddSourceCommand(cfg),
-		newDescribeSourceCommand(cfg),
+		paged(newDescribeSourceCommand(cfg)),
 		newDeleteSourceCommand(cfg),
 		newSyncSourceCommand(cfg),
 		newUpdateSourceCommand(cfg),
+		newSourcePinCommand(cfg),
+		newSourceUnpinCommand(cfg),
 		newDebugSourceCommand(cfg),
 		newInlineSourceCommand(cfg),
+		newSourceCopyCommand(cfg),
+		newSourceTestCommand(cfg),
 	)
 
 	cmd.PersistentFlags().StringVarP(&runnerName, "runner", "r", "", "Runner name")
@@ -252,7 +260,7 @@ func newListSourcesCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(sources)
+				return printJSONList(sources)
 			default:
 				// Group sources by type for better organization
 				gitSources := []kubiya.Source{}
@@ -712,7 +720,7 @@ When scanning a local directory:
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(discovered)
+				return printJSON(discovered)
 			default:
 				fmt.Printf("\n%s\n", style.SuccessStyle.Render("✅ Scan completed successfully"))
 
@@ -1217,6 +1225,7 @@ func newAddSourceCommand(cfg *config.Config) *cobra.Command {
 		inlineFile    string
 		inlineStdin   bool
 		runnerName    string
+		wizard        bool
 	)
 
 	cmd := &cobra.Command{
@@ -1233,11 +1242,32 @@ func newAddSourceCommand(cfg *config.Config) *cobra.Command {
   kubiya source add --inline tools.yaml --name "My Inline Tools" --runner my-runner
   
   # Add an inline source from stdin
-  kubiya source add --inline-stdin --name "From Stdin" --runner my-runner`,
+  kubiya source add --inline-stdin --name "From Stdin" --runner my-runner
+
+  # Be guided through the creation, previewing the tools
+  kubiya source add --wizard`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := kubiya.NewClient(cfg)
 			sourceURL := ""
 
+			if wizard {
+				if inlineFile != "" || inlineStdin {
+					return fmt.Errorf("--wizard cannot be used with --inline or --inline-stdin")
+				}
+				var options []kubiya.SourceOption
+				if dynamicConfig != "" {
+					configData, err := loadDynamicConfig(dynamicConfig)
+					if err != nil {
+						return err
+					}
+					options = append(options, kubiya.WithDynamicConfig(configData))
+				}
+				if len(args) > 0 {
+					sourceURL = args[0]
+				}
+				return runSourceWizard(cmd.Context(), client, sourceURL, name, runnerName, options)
+			}
+
 			// Check if we're adding an inline source
 			if inlineFile != "" || inlineStdin {
 				var tools []kubiya.Tool
@@ -1456,6 +1486,7 @@ func newAddSourceCommand(cfg *config.Config) *cobra.Command {
 	cmd.Flags().StringVar(&inlineFile, "inline", "", "File containing inline tool definitions (YAML or JSON)")
 	cmd.Flags().BoolVar(&inlineStdin, "inline-stdin", false, "Read inline tool definitions from stdin")
 	cmd.Flags().StringVar(&runnerName, "runner", "", "Runner name for the source")
+	cmd.Flags().BoolVar(&wizard, "wizard", false, "Be guided through the creation: location, tools preview and agents to add the source to")
 
 	return cmd
 }
@@ -1480,247 +1511,26 @@ func loadToolsFromStdin() ([]kubiya.Tool, error) {
 	return parseToolsData(data, "")
 }
 
-// Helper function to parse tools data from YAML or JSON
+// Helper function to parse tools data from YAML or JSON. The format is
+// detected from the content; data that isn't a tool document is taken as
+// the script of a single tool named after the file.
 func parseToolsData(data []byte, filename string) ([]kubiya.Tool, error) {
-	var tools []kubiya.Tool
-
-	// Determine format based on file extension or content
-	isJSON := false
+	toolName := "tool-from-content"
 	if filename != "" {
-		if strings.HasSuffix(strings.ToLower(filename), ".json") {
-			isJSON = true
-		}
-	} else {
-		// Try to determine from content
-		trimmed := bytes.TrimSpace(data)
-		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
-			isJSON = true
-		}
+		toolName = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
 	}
 
-	// Add debug output to see the data we're trying to parse
-	fmt.Printf("Parsing data format: %s\n", map[bool]string{true: "JSON", false: "YAML"}[isJSON])
-
-	if isJSON {
-		if err := json.Unmarshal(data, &tools); err != nil {
-			// Try as a single tool
-			var tool kubiya.Tool
-			if jsonErr := json.Unmarshal(data, &tool); jsonErr == nil {
-				return []kubiya.Tool{tool}, nil
-			}
-			return nil, fmt.Errorf("failed to parse JSON: %w", err)
-		}
-	} else {
-		// Try to parse YAML in multiple ways
-
-		// 1. First try as an array of tools (standard format)
-		if err := yaml.Unmarshal(data, &tools); err != nil {
-			fmt.Printf("Failed to parse YAML as array, trying other formats: %v\n", err)
-
-			// 2. Try as a structure with a 'tools' key containing an array
-			var toolsWrapper struct {
-				Tools []kubiya.Tool `yaml:"tools"`
-			}
-			if err := yaml.Unmarshal(data, &toolsWrapper); err == nil && len(toolsWrapper.Tools) > 0 {
-				fmt.Printf("Successfully parsed YAML with 'tools' key containing %d tools\n", len(toolsWrapper.Tools))
-				return toolsWrapper.Tools, nil
-			}
-
-			// 3. Try as a single tool
-			var tool kubiya.Tool
-			if yamlErr := yaml.Unmarshal(data, &tool); yamlErr == nil {
-				// Ensure the tool has a name
-				if tool.Name == "" && filename != "" {
-					baseName := filepath.Base(filename)
-					ext := filepath.Ext(baseName)
-					if ext != "" {
-						baseName = baseName[:len(baseName)-len(ext)]
-					}
-					tool.Name = baseName
-				}
-
-				if tool.Name != "" {
-					return []kubiya.Tool{tool}, nil
-				}
-			}
-
-			// 4. Try as a map with tool names as keys
-			var toolMap map[string]interface{}
-			if mapErr := yaml.Unmarshal(data, &toolMap); mapErr == nil {
-				fmt.Printf("Parsed YAML as a map with %d keys\n", len(toolMap))
-
-				// Check for a 'tools' key first - it might be an array of tools
-				if toolsData, ok := toolMap["tools"]; ok {
-					if toolList, ok := toolsData.([]interface{}); ok {
-						fmt.Printf("Found 'tools' key with array of %d items\n", len(toolList))
-						for _, item := range toolList {
-							// Convert each item to a Tool
-							itemBytes, _ := yaml.Marshal(item)
-							var parsedTool kubiya.Tool
-							if err := yaml.Unmarshal(itemBytes, &parsedTool); err == nil {
-								tools = append(tools, parsedTool)
-								fmt.Printf("Added tool from tools array: %s\n", parsedTool.Name)
-							}
-						}
-						if len(tools) > 0 {
-							return tools, nil
-						}
-					}
-				}
-
-				// Try to convert each key-value pair to a Tool
-				for name, rawValue := range toolMap {
-					// Skip the 'tools' key if we already processed it
-					if name == "tools" {
-						continue
-					}
-
-					// Convert the value to YAML for remarshaling
-					valueBytes, mErr := yaml.Marshal(rawValue)
-					if mErr != nil {
-						fmt.Printf("Failed to marshal value for key %s: %v\n", name, mErr)
-						continue
-					}
-
-					var parsedTool kubiya.Tool
-					if tErr := yaml.Unmarshal(valueBytes, &parsedTool); tErr == nil {
-						if parsedTool.Name == "" {
-							parsedTool.Name = name
-						}
-						tools = append(tools, parsedTool)
-						fmt.Printf("Added tool with name: %s\n", parsedTool.Name)
-					} else {
-						fmt.Printf("Failed to parse tool from key %s: %v\n", name, tErr)
-
-						// If the value is a string, it might be a simple command
-						if strValue, ok := rawValue.(string); ok {
-							tools = append(tools, kubiya.Tool{
-								Name:    name,
-								Content: strValue,
-							})
-							fmt.Printf("Added simple command tool: %s\n", name)
-						}
-					}
-				}
-
-				if len(tools) > 0 {
-					return tools, nil
-				}
-			}
-
-			// 5. Try a more relaxed approach for nested YAML structures
-			var rawYaml interface{}
-			if rawErr := yaml.Unmarshal(data, &rawYaml); rawErr == nil {
-				fmt.Println("Attempting to parse with relaxed YAML structure...")
-
-				// Handle case where tool(s) might be nested under a top-level key
-				if m, ok := rawYaml.(map[string]interface{}); ok {
-					for key, value := range m {
-						// Handle the 'tools' array specifically
-						if key == "tools" {
-							if toolsList, ok := value.([]interface{}); ok {
-								for i, toolData := range toolsList {
-									toolBytes, _ := yaml.Marshal(toolData)
-									var tool kubiya.Tool
-									if err := yaml.Unmarshal(toolBytes, &tool); err == nil {
-										tools = append(tools, tool)
-										fmt.Printf("Added tool %d from 'tools' array: %s\n", i+1, tool.Name)
-									} else {
-										fmt.Printf("Failed to parse tool %d from 'tools' array: %v\n", i+1, err)
-									}
-								}
-								if len(tools) > 0 {
-									return tools, nil
-								}
-							}
-							continue
-						}
-
-						// If we find a map of maps, each inner map might be a tool
-						if innerMap, ok := value.(map[string]interface{}); ok {
-							for innerKey, innerValue := range innerMap {
-								// Convert to YAML and try to parse as Tool
-								innerBytes, _ := yaml.Marshal(innerValue)
-								var innerTool kubiya.Tool
-								if innerErr := yaml.Unmarshal(innerBytes, &innerTool); innerErr == nil {
-									if innerTool.Name == "" {
-										innerTool.Name = innerKey
-									}
-									tools = append(tools, innerTool)
-									fmt.Printf("Added nested tool: %s\n", innerTool.Name)
-								}
-							}
-						} else {
-							// Try to parse this item directly as a Tool
-							itemBytes, _ := yaml.Marshal(value)
-							var tool kubiya.Tool
-							if itemErr := yaml.Unmarshal(itemBytes, &tool); itemErr == nil {
-								if tool.Name == "" {
-									tool.Name = key
-								}
-								tools = append(tools, tool)
-								fmt.Printf("Added top-level tool: %s\n", tool.Name)
-							}
-						}
-					}
-				}
-
-				if len(tools) > 0 {
-					return tools, nil
-				}
-			}
-
-			// If we made it here with an empty tools slice, try a last-resort approach
-			if len(tools) == 0 && !isJSON {
-				// The data might be a single tool file, but YAML unmarshaler is strict about types
-				// Let's create a single tool with the content from the file
-				var toolName string
-				if filename != "" {
-					baseName := filepath.Base(filename)
-					ext := filepath.Ext(baseName)
-					if ext != "" {
-						baseName = baseName[:len(baseName)-len(ext)]
-					}
-					toolName = baseName
-				} else {
-					toolName = "tool-from-content"
-				}
-
-				// Create a tool with the content as is
-				tool := kubiya.Tool{
-					Name:    toolName,
-					Content: string(data),
-				}
-
-				// Try to extract description from the first line if it looks like a comment
-				lines := strings.Split(string(data), "\n")
-				if len(lines) > 0 && (strings.HasPrefix(lines[0], "#") || strings.HasPrefix(lines[0], "//")) {
-					tool.Description = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(lines[0], "#"), "//"))
-				}
-
-				return []kubiya.Tool{tool}, nil
-			}
-
-			return nil, fmt.Errorf("failed to parse YAML data as tools: %w", err)
+	var tools []kubiya.Tool
+	var doc interface{}
+	if err := docload.Unmarshal(data, &doc); err == nil {
+		if tools, err = toolsFromDocument(doc, toolName); err != nil {
+			return nil, err
 		}
+	} else if docload.LooksLikeJSON(data) {
+		return nil, fmt.Errorf("failed to parse tools: %w", err)
 	}
 
-	// If we made it here with an empty tools slice, try a last-resort approach
-	if len(tools) == 0 && !isJSON {
-		// The data might be a single tool file, but YAML unmarshaler is strict about types
-		// Let's create a single tool with the content from the file
-		var toolName string
-		if filename != "" {
-			baseName := filepath.Base(filename)
-			ext := filepath.Ext(baseName)
-			if ext != "" {
-				baseName = baseName[:len(baseName)-len(ext)]
-			}
-			toolName = baseName
-		} else {
-			toolName = "tool-from-content"
-		}
-
+	if len(tools) == 0 && docload.Format(data) != docload.JSON {
 		// Create a tool with the content as is
 		tool := kubiya.Tool{
 			Name:    toolName,
@@ -1833,6 +1643,98 @@ func parseToolsData(data []byte, filename string) ([]kubiya.Tool, error) {
 	return validatedTools, nil
 }
 
+// toolDefinitionKeys are the keys telling a tool definition apart from a
+// map of tools by name
+var toolDefinitionKeys = []string{"name", "content", "description", "image", "args", "type"}
+
+// toolsFromDocument returns the tools of a decoded tools document: a list
+// of tools, an object with a tools list, a single tool (named toolName
+// unless it has a name), or a map of tools by name whose values are tool
+// definitions, commands, or maps of tools one level deep
+func toolsFromDocument(doc interface{}, toolName string) ([]kubiya.Tool, error) {
+	switch v := doc.(type) {
+	case []interface{}:
+		var tools []kubiya.Tool
+		if err := remarshalTool(v, &tools); err != nil {
+			return nil, fmt.Errorf("failed to parse tools: %w", err)
+		}
+		return tools, nil
+	case map[string]interface{}:
+		if list, ok := v["tools"].([]interface{}); ok {
+			return toolsFromDocument(list, toolName)
+		}
+		if isToolDefinition(v) {
+			var tool kubiya.Tool
+			if err := remarshalTool(v, &tool); err != nil {
+				return nil, fmt.Errorf("failed to parse tool: %w", err)
+			}
+			if tool.Name == "" {
+				tool.Name = toolName
+			}
+			return []kubiya.Tool{tool}, nil
+		}
+		return toolsFromMap(v, 1)
+	}
+	return nil, nil
+}
+
+// toolsFromMap returns the tools of a map of tools by name, descending
+// depth levels into maps that aren't tool definitions
+func toolsFromMap(m map[string]interface{}, depth int) ([]kubiya.Tool, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tools []kubiya.Tool
+	for _, name := range names {
+		switch v := m[name].(type) {
+		case string:
+			// A simple command
+			tools = append(tools, kubiya.Tool{Name: name, Content: v})
+		case map[string]interface{}:
+			if !isToolDefinition(v) {
+				if depth > 0 {
+					nested, err := toolsFromMap(v, depth-1)
+					if err != nil {
+						return nil, err
+					}
+					tools = append(tools, nested...)
+				}
+				continue
+			}
+			var tool kubiya.Tool
+			if err := remarshalTool(v, &tool); err != nil {
+				return nil, fmt.Errorf("failed to parse tool %s: %w", name, err)
+			}
+			if tool.Name == "" {
+				tool.Name = name
+			}
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+func isToolDefinition(m map[string]interface{}) bool {
+	for _, key := range toolDefinitionKeys {
+		if _, ok := m[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// remarshalTool decodes a generic document into v through its json tags
+func remarshalTool(doc interface{}, v interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
 // Helper function to load dynamic configuration
 func loadDynamicConfig(path string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(path)
@@ -1852,8 +1754,9 @@ func newDescribeSourceCommand(cfg *config.Config) *cobra.Command {
 	var outputFormat string
 
 	cmd := &cobra.Command{
-		Use:   "describe [uuid]",
-		Short: "📖 Show detailed information about a source",
+		Use:     "describe [uuid]",
+		Aliases: []string{"get"},
+		Short:   "📖 Show detailed information about a source",
 		Example: `  # Show source details
   kubiya source describe abc-123
   
@@ -1869,7 +1772,7 @@ func newDescribeSourceCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(source)
+				return printJSON(source)
 			default:
 				fmt.Printf("\n%s\n\n", style.TitleStyle.Render(" 📦 Source Details "))
 				fmt.Printf("UUID: %s\n", style.DimStyle.Render(source.UUID))
@@ -1892,6 +1795,11 @@ func newDescribeSourceCommand(cfg *config.Config) *cobra.Command {
 				}
 
 				fmt.Printf("URL: %s\n", source.URL)
+				if source.PinnedRef != "" {
+					fmt.Printf("Pinned: %s\n", style.HighlightStyle.Render(source.PinnedRef))
+				} else if sourceType != "inline" {
+					fmt.Printf("Pinned: %s\n", style.DimStyle.Render("no, follows the branch"))
+				}
 
 				// Show runner if available
 				if source.Runner != "" {
@@ -2004,8 +1912,10 @@ func newSyncSourceCommand(cfg *config.Config) *cobra.Command {
 		mode       string
 		branch     string
 		force      bool
-		autoCommit bool
-		noDiff     bool
+		autoCommit  bool
+		noDiff      bool
+		all         bool
+		concurrency int
 	)
 
 	cmd := &cobra.Command{
@@ -2019,11 +1929,62 @@ func newSyncSourceCommand(cfg *config.Config) *cobra.Command {
   kubiya source sync abc-123 --branch main
   
   # Non-interactive sync
-  kubiya source sync abc-123 --mode non-interactive --force`,
-		Args: cobra.ExactArgs(1),
+  kubiya source sync abc-123 --mode non-interactive --force
+
+  # Sync every source, 8 at a time
+  kubiya source sync --all --concurrency 8 --mode ci`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := kubiya.NewClient(cfg)
 
+			if all {
+				if concurrency < 1 {
+					return fmt.Errorf("--concurrency must be at least 1")
+				}
+				sources, err := client.ListSources(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("failed to list sources: %w", err)
+				}
+				if len(sources) == 0 {
+					fmt.Println("No sources found")
+					return nil
+				}
+
+				opts := kubiya.SyncOptions{
+					Mode:       mode,
+					Branch:     branch,
+					Force:      force,
+					AutoCommit: autoCommit,
+					NoDiff:     noDiff,
+				}
+
+				fmt.Printf("\n%s\n\n", style.TitleStyle.Render(fmt.Sprintf(" 🔄 Syncing %d Sources ", len(sources))))
+				progress := newToolProgressRenderer(os.Stdout, isatty.IsTerminal(os.Stdout.Fd()))
+				progress.Start()
+				results := syncAllSources(cmd.Context(), client, sources, opts, runnerName, concurrency, progress)
+				progress.Stop()
+
+				fmt.Println()
+				printSourceSyncSummary(os.Stdout, results)
+				printThrottleStats(os.Stdout, client.ThrottleStats())
+
+				failed := 0
+				for _, r := range results {
+					if r.Status == sourceSyncFailed {
+						failed++
+					}
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d of %d sources failed to sync", failed, len(sources))
+				}
+				return nil
+			}
+
 			// Get source details first
 			source, err := client.GetSource(cmd.Context(), args[0])
 			if err != nil {
@@ -2041,9 +2002,20 @@ func newSyncSourceCommand(cfg *config.Config) *cobra.Command {
 				NoDiff:     noDiff,
 			}
 
+			// Pinned sources load their pinned commit, not the branch
+			if source.PinnedRef != "" {
+				if branch != "" {
+					return fmt.Errorf("source is pinned to %s, run 'kubiya source unpin %s' to sync a branch", source.PinnedRef, args[0])
+				}
+				opts.Ref = source.PinnedRef
+			}
+
 			fmt.Printf("\n%s\n\n", style.TitleStyle.Render(" 🔄 Syncing Source "))
 			fmt.Printf("Name: %s\n", style.HighlightStyle.Render(source.Name))
 			fmt.Printf("URL: %s\n", source.URL)
+			if opts.Ref != "" {
+				fmt.Printf("Pinned: %s\n", opts.Ref)
+			}
 
 			// Call sync endpoint with options
 			synced, err := client.SyncSource(cmd.Context(), args[0], opts, runnerName)
@@ -2081,6 +2053,8 @@ func newSyncSourceCommand(cfg *config.Config) *cobra.Command {
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force sync")
 	cmd.Flags().BoolVar(&autoCommit, "auto-commit", false, "Automatically commit changes")
 	cmd.Flags().BoolVar(&noDiff, "no-diff", false, "Skip showing diffs")
+	cmd.Flags().BoolVar(&all, "all", false, "Sync all sources")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of sources synced in parallel with --all")
 
 	return cmd
 }
@@ -2498,7 +2472,7 @@ func newDebugSourceCommand(cfg *config.Config) *cobra.Command {
 				} else {
 					result["metadata_error"] = err.Error()
 				}
-				return json.NewEncoder(os.Stdout).Encode(result)
+				return printJSON(result)
 			}
 
 			// Show raw output if requested
@@ -2735,7 +2709,7 @@ func newInlineSourceCommand(cfg *config.Config) *cobra.Command {
 		newInlineToolEditCommand(cfg),
 		newInlineToolDeleteCommand(cfg),
 		newInlineToolUpdateCommand(cfg),
-		newInlineToolListCommand(cfg),
+		paged(newInlineToolListCommand(cfg)),
 	)
 
 	return cmd
@@ -3103,7 +3077,7 @@ func newInlineToolUpdateCommand(cfg *config.Config) *cobra.Command {
 				// Load tool from URL
 				fmt.Printf("%s Loading tool from URL: %s\n", style.InfoStyle.Render("🌐"), toolURL)
 
-				resp, err := http.Get(toolURL)
+				resp, err := httpclient.Get(toolURL)
 				if err != nil {
 					return fmt.Errorf("failed to fetch tool from URL: %w", err)
 				}
@@ -3198,7 +3172,7 @@ func newInlineToolListCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(source.InlineTools)
+				return printJSONList(source.InlineTools)
 			default:
 				fmt.Printf("%s Tools in Source: %s\n\n", 
 					style.TitleStyle.Render("📋"), 
@@ -3340,7 +3314,7 @@ func addToolFromURL(ctx context.Context, client *kubiya.Client, source *kubiya.S
 	fmt.Printf("%s Loading tool from URL: %s\n", style.InfoStyle.Render("🌐"), url)
 
 	// Fetch the tool definition from URL
-	resp, err := http.Get(url)
+	resp, err := httpclient.Get(url)
 	if err != nil {
 		return fmt.Errorf("failed to fetch tool from URL: %w", err)
 	}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolsDataMixedInputs(t *testing.T) {
+	inputs := map[string]string{
+		"json list": `[{"name": "deploy", "description": "Deploy", "content": "make deploy", "image": "alpine", "secrets": ["TOKEN"]}]`,
+		"yaml list": `
+- name: deploy
+  description: Deploy
+  content: make deploy
+  image: alpine
+  secrets: [TOKEN]
+`,
+		"yaml tools key": "tools:\n  - name: deploy\n    description: Deploy\n    content: make deploy\n    image: alpine\n    secrets: [TOKEN]\n",
+		"yaml by name":   "deploy:\n  description: Deploy\n  content: make deploy\n  image: alpine\n  secrets: [TOKEN]\n",
+	}
+	for name, data := range inputs {
+		// The extension is deliberately misleading: the content decides
+		tools, err := parseToolsData([]byte(data), "tools.json")
+		require.NoError(t, err, name)
+		require.Len(t, tools, 1, name)
+		assert.Equal(t, "deploy", tools[0].Name, name)
+		assert.Equal(t, "make deploy", tools[0].Content, name)
+		assert.Equal(t, "alpine", tools[0].Image, "%s: json-only fields are read from YAML", name)
+		assert.Equal(t, []string{"TOKEN"}, tools[0].Secrets, name)
+	}
+}
+
+func TestParseToolsDataShapes(t *testing.T) {
+	tools, err := parseToolsData([]byte("description: Restart the API\ncontent: systemctl restart api\n"), "tools/restart.yaml")
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "restart", tools[0].Name, "a single tool is named after the file")
+
+	tools, err = parseToolsData([]byte("ops:\n  uptime: uptime\n  disk:\n    content: df -h\n"), "")
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "disk", tools[0].Name)
+	assert.Equal(t, "uptime", tools[1].Name)
+	assert.Equal(t, "uptime", tools[1].Content)
+
+	script := "# Print the date\ndate -u\n"
+	tools, err = parseToolsData([]byte(script), "date.sh")
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "date", tools[0].Name)
+	assert.Equal(t, script, tools[0].Content)
+	assert.Equal(t, "Print the date", tools[0].Description)
+
+	_, err = parseToolsData([]byte(`[{"name": "deploy",}]`), "tools.yaml")
+	assert.ErrorContains(t, err, "invalid JSON at line 1")
+}
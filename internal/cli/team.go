@@ -89,8 +89,7 @@ func newCreateTeamCommand(cfg *config.Config) *cobra.Command {
 				team.ID)
 
 			if outputFormat == "json" {
-				data, _ := json.MarshalIndent(team, "", "  ")
-				fmt.Println(string(data))
+				return printJSON(team)
 			} else if outputFormat == "yaml" {
 				data, _ := yaml.Marshal(team)
 				fmt.Print(string(data))
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// watchPollInterval is how often watched files are checked for changes
+const watchPollInterval = 500 * time.Millisecond
+
+// fileWatcher polls files for changes of their content. Content is
+// compared rather than modification times, so that editors saving through
+// a temporary file and touching a file without changing it behave the same.
+type fileWatcher struct {
+	paths    []string
+	sums     map[string][sha256.Size]byte
+	interval time.Duration
+}
+
+func newFileWatcher(paths []string) (*fileWatcher, error) {
+	w := &fileWatcher{paths: paths, sums: map[string][sha256.Size]byte{}, interval: watchPollInterval}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		w.sums[path] = sha256.Sum256(data)
+	}
+	return w, nil
+}
+
+// changed returns the files whose content changed since the last call.
+// Files that cannot be read, e.g. while an editor replaces them, are
+// checked again on the next call.
+func (w *fileWatcher) changed() []string {
+	var changed []string
+	for _, path := range w.paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if sum := sha256.Sum256(data); sum != w.sums[path] {
+			w.sums[path] = sum
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// wait blocks until files change and stop changing for one poll interval,
+// and returns them
+func (w *fileWatcher) wait(ctx context.Context) ([]string, error) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var changed []string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		more := w.changed()
+		if len(more) == 0 && len(changed) > 0 {
+			return changed, nil
+		}
+		for _, path := range more {
+			if !contains(changed, path) {
+				changed = append(changed, path)
+			}
+		}
+	}
+}
+
+// watchToolExec runs the tool, then runs it again whenever the watched
+// files change, printing how its output differs from the previous run. A
+// failed run is reported and the loop goes on; it ends when ctx is done.
+func watchToolExec(ctx context.Context, w io.Writer, watcher *fileWatcher, run func(ctx context.Context) (string, error)) error {
+	var previous string
+	for n := 1; ; n++ {
+		output, err := run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(w, "%s Run %d failed: %v\n", style.ErrorStyle.Render("✗"), n, err)
+		}
+
+		if n > 1 {
+			diff, err := unifiedDiff(previous, output, fmt.Sprintf("run %d", n-1), fmt.Sprintf("run %d", n))
+			if err != nil {
+				return err
+			}
+			if diff == "" {
+				fmt.Fprintf(w, "\n%s Output unchanged since run %d\n", style.DimStyle.Render("="), n-1)
+			} else {
+				fmt.Fprintf(w, "\n%s Output changes since run %d:\n", style.InfoStyle.Render("±"), n-1)
+				printColoredDiff(w, diff)
+			}
+		}
+		previous = output
+
+		fmt.Fprintf(w, "\n%s Watching %s for changes (Ctrl+C to stop)\n",
+			style.DimStyle.Render("👀"), strings.Join(watcher.paths, ", "))
+		changed, err := watcher.wait(ctx)
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(w, "\n%s %s changed, running the tool again\n\n",
+			style.InfoStyle.Render("🔄"), strings.Join(changed, ", "))
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcher(t *testing.T) {
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "tool.json")
+	script := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(tool, []byte(`{"name":"a"}`), 0o644))
+	require.NoError(t, os.WriteFile(script, []byte("echo a"), 0o644))
+
+	_, err := newFileWatcher([]string{tool, filepath.Join(dir, "missing")})
+	assert.ErrorContains(t, err, "failed to watch")
+
+	watcher, err := newFileWatcher([]string{tool, script})
+	require.NoError(t, err)
+	assert.Empty(t, watcher.changed())
+
+	require.NoError(t, os.WriteFile(script, []byte("echo a"), 0o644))
+	assert.Empty(t, watcher.changed(), "writing the same content is not a change")
+
+	require.NoError(t, os.WriteFile(script, []byte("echo b"), 0o644))
+	require.NoError(t, os.Remove(tool))
+	assert.Equal(t, []string{script}, watcher.changed(), "files being replaced are checked later")
+	require.NoError(t, os.WriteFile(tool, []byte(`{"name":"b"}`), 0o644))
+	assert.Equal(t, []string{tool}, watcher.changed())
+	assert.Empty(t, watcher.changed())
+}
+
+func TestWatchToolExec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.sh")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o644))
+	watcher, err := newFileWatcher([]string{path})
+	require.NoError(t, err)
+	watcher.interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	outputs := []string{"hello\nworld\n", "hello\nthere\n", "hello\nthere\n"}
+	runs := 0
+	var out bytes.Buffer
+	err = watchToolExec(ctx, &out, watcher, func(ctx context.Context) (string, error) {
+		output := outputs[runs]
+		runs++
+		if runs == len(outputs) {
+			cancel()
+			return output, nil
+		}
+		// Edit the file while the tool runs
+		require.NoError(t, os.WriteFile(path, []byte{byte(runs)}, 0o644))
+		if runs == 2 {
+			return output, errors.New("exit status 1")
+		}
+		return output, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, runs)
+
+	text := out.String()
+	assert.Contains(t, text, "tool.sh changed, running the tool again")
+	assert.Contains(t, text, "Run 2 failed: exit status 1")
+	assert.Contains(t, text, "Output changes since run 1:")
+	assert.Contains(t, text, "-world")
+	assert.Contains(t, text, "+there")
+	assert.NotContains(t, text, "Output unchanged since run 2", "the interrupted run is not compared")
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	sentryutil "github.com/kubiyabot/cli/internal/sentry"
+)
+
+const (
+	// runnerDiagnosticsTimeout bounds fetching the state of a runner after
+	// an execution on it failed
+	runnerDiagnosticsTimeout = 5 * time.Second
+	// maxAlternateRunners caps the runners suggested instead of a busy one
+	maxAlternateRunners = 3
+)
+
+// runnerDiagnostics is the state of a runner fetched when an execution on it
+// timed out or stayed queued, to tell a busy or unhealthy runner apart from
+// a slow tool
+type runnerDiagnostics struct {
+	Runner  string
+	Status  string
+	Healthy bool
+	// Capacity is nil when the platform does not report the load of the runner
+	Capacity *kubiya.RunnerCapacity
+	// Alternates are healthy runners to retry on, least busy first
+	Alternates []string
+	// Err is set when the runner could not be fetched
+	Err error
+}
+
+// isRunnerTimeout reports whether an execution error is a timeout, or the
+// execution waiting in the queue of its runner for too long
+func isRunnerTimeout(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, s := range []string{"timeout", "timed out", "deadline exceeded", "queue"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnoseRunner fetches the state of runner and the healthy runners that
+// could serve the execution instead. It runs after ctx may have expired, so
+// it uses a context of its own.
+func diagnoseRunner(ctx context.Context, client runnerLister, runner string) *runnerDiagnostics {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), runnerDiagnosticsTimeout)
+	defer cancel()
+
+	diag := &runnerDiagnostics{Runner: runner}
+	if info, err := client.GetRunner(ctx, runner); err != nil {
+		diag.Err = err
+	} else {
+		diag.Status = firstNonEmpty(info.RunnerHealth.Status, info.RunnerHealth.Health)
+		diag.Healthy = isRunnerHealthy(info)
+		diag.Capacity = info.Capacity
+	}
+
+	all, err := client.ListRunners(ctx)
+	if err != nil {
+		return diag
+	}
+	var alternates []kubiya.Runner
+	for _, r := range all {
+		if r.Name != runner && isRunnerHealthy(r) {
+			alternates = append(alternates, r)
+		}
+	}
+	// Runners not reporting their load keep the platform order, after the
+	// others
+	sort.SliceStable(alternates, func(i, j int) bool {
+		a, b := alternates[i].Capacity, alternates[j].Capacity
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.QueueDepth < b.QueueDepth
+	})
+	for i, r := range alternates {
+		if i == maxAlternateRunners {
+			break
+		}
+		diag.Alternates = append(diag.Alternates, r.Name)
+	}
+	return diag
+}
+
+// String summarizes the state of the runner, e.g. "runner eu-1: healthy,
+// 12 queued, 4/4 running; try --runner eu-2"
+func (d *runnerDiagnostics) String() string {
+	var parts []string
+	switch {
+	case d.Err != nil:
+		parts = append(parts, "state unknown")
+	case d.Healthy:
+		parts = append(parts, firstNonEmpty(d.Status, "healthy"))
+	default:
+		parts = append(parts, "unhealthy (status: "+d.Status+")")
+	}
+	if c := d.Capacity; c != nil {
+		parts = append(parts, fmt.Sprintf("%d queued", c.QueueDepth))
+		if c.MaxConcurrency > 0 {
+			parts = append(parts, fmt.Sprintf("%d/%d running", c.Running, c.MaxConcurrency))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d running", c.Running))
+		}
+	}
+	s := fmt.Sprintf("runner %s: %s", d.Runner, strings.Join(parts, ", "))
+	if len(d.Alternates) > 0 {
+		s += "; try --runner " + strings.Join(d.Alternates, ", ")
+	} else {
+		s += "; no other healthy runner"
+	}
+	return s
+}
+
+// Tags are the Sentry tags describing the runner
+func (d *runnerDiagnostics) Tags() map[string]string {
+	tags := map[string]string{
+		"runner":         d.Runner,
+		"runner.status":  firstNonEmpty(d.Status, "unknown"),
+		"runner.healthy": strconv.FormatBool(d.Healthy),
+	}
+	if c := d.Capacity; c != nil {
+		tags["runner.queue_depth"] = strconv.Itoa(c.QueueDepth)
+		tags["runner.running"] = strconv.Itoa(c.Running)
+		tags["runner.max_concurrency"] = strconv.Itoa(c.MaxConcurrency)
+	}
+	if len(d.Alternates) > 0 {
+		tags["runner.alternates"] = strings.Join(d.Alternates, ",")
+	}
+	return tags
+}
+
+// withRunnerDiagnostics enriches an execution error with the state of the
+// runner it ran on when it is a timeout, and tags it for Sentry. Other
+// errors are returned as is.
+func withRunnerDiagnostics(ctx context.Context, client runnerLister, runner string, err error) error {
+	if err == nil || runner == "" {
+		return err
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && !isRunnerTimeout(err.Error()) {
+		return err
+	}
+	diag := diagnoseRunner(ctx, client, runner)
+	var extras map[string]interface{}
+	if diag.Err != nil {
+		extras = map[string]interface{}{"runner_fetch_error": diag.Err.Error()}
+	}
+	return sentryutil.NewError(fmt.Errorf("%w (%s)", err, diag), diag.Tags(), extras)
+}
+
+// toolExecutionFailed is the error of an execution that streamed an error
+// event, enriched with the state of the runner when the event is a timeout
+func toolExecutionFailed(ctx context.Context, client runnerLister, runner, event string) error {
+	if event == "" || !isRunnerTimeout(event) {
+		return fmt.Errorf("tool execution failed")
+	}
+	return withRunnerDiagnostics(ctx, client, runner, fmt.Errorf("tool execution failed: %s", event))
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	sentryutil "github.com/kubiyabot/cli/internal/sentry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runnerWithLoad(name, status string, queued, running, max int) kubiya.Runner {
+	r := runnerWithStatus(name, status)
+	r.Capacity = &kubiya.RunnerCapacity{QueueDepth: queued, Running: running, MaxConcurrency: max}
+	return r
+}
+
+func TestWithRunnerDiagnostics(t *testing.T) {
+	client := fakeRunnerLister{runners: []kubiya.Runner{
+		runnerWithLoad("busy", "healthy", 12, 4, 4),
+		runnerWithStatus("quiet", "ok"),
+		runnerWithStatus("broken", "error"),
+		runnerWithLoad("idle", "healthy", 0, 1, 4),
+		runnerWithLoad("loaded", "healthy", 3, 4, 4),
+		runnerWithStatus("spare", "healthy"),
+	}}
+	ctx := context.Background()
+
+	err := withRunnerDiagnostics(ctx, client, "busy", errors.New("runner busy: execution timed out"))
+	assert.EqualError(t, err, "runner busy: execution timed out (runner busy: healthy, 12 queued, 4/4 running; try --runner idle, loaded, quiet)")
+
+	var serr *sentryutil.SentryError
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, map[string]string{
+		"runner":                 "busy",
+		"runner.status":          "healthy",
+		"runner.healthy":         "true",
+		"runner.queue_depth":     "12",
+		"runner.running":         "4",
+		"runner.max_concurrency": "4",
+		"runner.alternates":      "idle,loaded,quiet",
+	}, serr.Tags)
+
+	// Failures other than timeouts are left alone
+	cause := errors.New("runner busy: execution failed with status 400")
+	assert.Same(t, cause, withRunnerDiagnostics(ctx, client, "busy", cause))
+
+	err = withRunnerDiagnostics(ctx, client, "broken", fmt.Errorf("stream: %w", context.DeadlineExceeded))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "(runner broken: unhealthy (status: error); try --runner idle, loaded, busy)")
+
+	err = withRunnerDiagnostics(ctx, fakeRunnerLister{}, "gone", errors.New("timeout"))
+	assert.EqualError(t, err, "timeout (runner gone: state unknown; no other healthy runner)")
+}
+
+func TestToolExecutionFailed(t *testing.T) {
+	client := fakeRunnerLister{runners: []kubiya.Runner{runnerWithStatus("r1", "healthy")}}
+	ctx := context.Background()
+
+	assert.EqualError(t, toolExecutionFailed(ctx, client, "r1", "exit status 1"), "tool execution failed")
+	assert.EqualError(t, toolExecutionFailed(ctx, client, "r1", ""), "tool execution failed")
+	assert.EqualError(t, toolExecutionFailed(ctx, client, "r1", "execution queued for too long"),
+		"tool execution failed: execution queued for too long (runner r1: healthy; no other healthy runner)")
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// runnerFallbackAuto retries on any healthy runner, in the order the
+// platform lists them
+const runnerFallbackAuto = "auto"
+
+// runnerLister is the part of kubiya.Client used to pick fallback runners
+type runnerLister interface {
+	GetRunner(ctx context.Context, name string) (kubiya.Runner, error)
+	ListRunners(ctx context.Context) ([]kubiya.Runner, error)
+}
+
+// runToolOn starts a tool execution on runner
+type runToolOn func(ctx context.Context, runner string) (<-chan kubiya.WorkflowSSEEvent, error)
+
+// fallbackRunners returns the runners to retry on after primary for a
+// --runner-fallback policy: "auto" or a comma separated list of runners
+func fallbackRunners(ctx context.Context, client runnerLister, policy, primary string) ([]string, error) {
+	policy = strings.TrimSpace(policy)
+	if policy == "" {
+		return nil, nil
+	}
+
+	var runners []string
+	if policy == runnerFallbackAuto {
+		all, err := client.ListRunners(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runners for fallback: %w", err)
+		}
+		for _, r := range all {
+			if r.Name != primary && isRunnerHealthy(r) {
+				runners = append(runners, r.Name)
+			}
+		}
+		return runners, nil
+	}
+
+	for _, name := range strings.Split(policy, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && name != primary {
+			runners = append(runners, name)
+		}
+	}
+	return runners, nil
+}
+
+// printRankedRunners shows the first max runners of an auto selection with
+// what they were ranked on
+func printRankedRunners(w io.Writer, ranked []kubiya.RankedRunner, max int) {
+	for i, r := range ranked {
+		if i == max {
+			fmt.Fprintf(w, "   %s\n", style.DimStyle.Render(fmt.Sprintf("… %d more", len(ranked)-max)))
+			break
+		}
+		details := fmt.Sprintf("probe %s", r.Probe.Round(time.Millisecond))
+		if r.HasRecent {
+			details += fmt.Sprintf(", recent start %s", r.Recent.Round(time.Millisecond))
+		}
+		if r.Preferred {
+			details += ", preferred labels"
+		}
+		fmt.Fprintf(w, "   %d. %s %s\n", i+1, r.Runner.Name, style.DimStyle.Render("("+details+")"))
+	}
+}
+
+func rankedRunnerNames(ranked []kubiya.RankedRunner) []string {
+	names := make([]string, len(ranked))
+	for i, r := range ranked {
+		names[i] = r.Runner.Name
+	}
+	return names
+}
+
+// executeWithRunnerFallback runs a tool on the first runner of candidates and
+// moves on to the next one when the runner is unavailable, i.e. when the
+// execution could not start (see kubiya.IsRunnerUnavailable). Once the tool
+// has started, failures are never retried on another runner, as the tool
+// may not be idempotent. Fallback runners are health checked before use. It
+// returns the events of the execution and the runner serving it.
+func executeWithRunnerFallback(ctx context.Context, client runnerLister, candidates []string, run runToolOn, w io.Writer) (<-chan kubiya.WorkflowSSEEvent, string, error) {
+	var lastErr error
+	for i, runner := range candidates {
+		if i > 0 {
+			if info, err := client.GetRunner(ctx, runner); err == nil && !isRunnerHealthy(info) {
+				fmt.Fprintf(w, "%s Skipping runner %s (status: %s)\n",
+					style.WarningStyle.Render("⚠️"), runner, info.RunnerHealth.Status)
+				lastErr = fmt.Errorf("runner '%s' is not healthy", runner)
+				continue
+			}
+			fmt.Fprintf(w, "%s Retrying on runner %s\n", style.InfoStyle.Render("↪"), style.HighlightStyle.Render(runner))
+		}
+
+		events, err := run(ctx, runner)
+		if err != nil {
+			if !kubiya.IsRunnerUnavailable(err) || i == len(candidates)-1 {
+				return nil, runner, err
+			}
+			fmt.Fprintf(w, "%s Runner %s failed: %v\n", style.WarningStyle.Render("⚠️"), runner, err)
+			lastErr = err
+			continue
+		}
+		return events, runner, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no runner to execute on")
+	}
+	return nil, "", fmt.Errorf("all runners failed: %w", lastErr)
+}
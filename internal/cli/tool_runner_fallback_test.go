@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRunnerLister struct {
+	runners []kubiya.Runner
+}
+
+func (f fakeRunnerLister) ListRunners(ctx context.Context) ([]kubiya.Runner, error) {
+	return f.runners, nil
+}
+
+func (f fakeRunnerLister) GetRunner(ctx context.Context, name string) (kubiya.Runner, error) {
+	for _, r := range f.runners {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return kubiya.Runner{}, errors.New("not found")
+}
+
+func runnerWithStatus(name, status string) kubiya.Runner {
+	r := kubiya.Runner{Name: name}
+	r.RunnerHealth.Status = status
+	return r
+}
+
+func eventsOf(events ...kubiya.WorkflowSSEEvent) <-chan kubiya.WorkflowSSEEvent {
+	ch := make(chan kubiya.WorkflowSSEEvent, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func TestFallbackRunners(t *testing.T) {
+	client := fakeRunnerLister{runners: []kubiya.Runner{
+		runnerWithStatus("primary", "healthy"),
+		runnerWithStatus("broken", "error"),
+		runnerWithStatus("backup", "ok"),
+	}}
+
+	auto, err := fallbackRunners(context.Background(), client, "auto", "primary")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup"}, auto)
+
+	list, err := fallbackRunners(context.Background(), client, "backup, primary,other", "primary")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup", "other"}, list)
+
+	none, err := fallbackRunners(context.Background(), client, "", "primary")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestExecuteWithRunnerFallback(t *testing.T) {
+	client := fakeRunnerLister{runners: []kubiya.Runner{
+		runnerWithStatus("primary", "healthy"),
+		runnerWithStatus("sick", "error"),
+		runnerWithStatus("backup", "healthy"),
+	}}
+
+	unreachable := func(runner string) error {
+		return &kubiya.ToolExecutionError{Runner: runner, Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	}
+	tests := []struct {
+		name       string
+		candidates []string
+		results    map[string]<-chan kubiya.WorkflowSSEEvent
+		errs       map[string]error
+		wantRunner string
+		wantErr    bool
+		wantFirst  string
+	}{
+		{
+			name:       "primary succeeds",
+			candidates: []string{"primary", "backup"},
+			results:    map[string]<-chan kubiya.WorkflowSSEEvent{"primary": eventsOf(kubiya.WorkflowSSEEvent{Type: "data", Data: "hello"})},
+			wantRunner: "primary",
+			wantFirst:  "hello",
+		},
+		{
+			name:       "runner unreachable",
+			candidates: []string{"primary", "sick", "backup"},
+			errs:       map[string]error{"primary": unreachable("primary")},
+			results:    map[string]<-chan kubiya.WorkflowSSEEvent{"backup": eventsOf(kubiya.WorkflowSSEEvent{Type: "data", Data: "from backup"})},
+			wantRunner: "backup",
+			wantFirst:  "from backup",
+		},
+		{
+			name:       "gateway can't reach the runner",
+			candidates: []string{"primary", "backup"},
+			errs: map[string]error{"primary": fmt.Errorf("all runners failed, last error: %w",
+				&kubiya.ToolExecutionError{Runner: "primary", StatusCode: 503, Body: "runner unavailable"})},
+			results:    map[string]<-chan kubiya.WorkflowSSEEvent{"backup": eventsOf(kubiya.WorkflowSSEEvent{Type: "data", Data: "ok"})},
+			wantRunner: "backup",
+			wantFirst:  "ok",
+		},
+		{
+			name:       "errors streamed once the tool started are not retried",
+			candidates: []string{"primary", "backup"},
+			results: map[string]<-chan kubiya.WorkflowSSEEvent{
+				"primary": eventsOf(kubiya.WorkflowSSEEvent{Type: "error", Data: "runner primary: execution timed out"}),
+			},
+			wantRunner: "primary",
+			wantFirst:  "runner primary: execution timed out",
+		},
+		{
+			name:       "invalid tool definition is not retried",
+			candidates: []string{"primary", "backup"},
+			errs: map[string]error{"primary": &kubiya.ToolExecutionError{
+				Runner: "primary", StatusCode: 400, Body: "invalid tool definition"}},
+			wantErr: true,
+		},
+		{
+			name:       "server errors are not retried",
+			candidates: []string{"primary", "backup"},
+			errs:       map[string]error{"primary": &kubiya.ToolExecutionError{Runner: "primary", StatusCode: 500}},
+			wantErr:    true,
+		},
+		{
+			name:       "timeouts before the stream are not retried",
+			candidates: []string{"primary", "backup"},
+			errs:       map[string]error{"primary": &kubiya.ToolExecutionError{Runner: "primary", Err: context.DeadlineExceeded}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(ctx context.Context, runner string) (<-chan kubiya.WorkflowSSEEvent, error) {
+				if err := tt.errs[runner]; err != nil {
+					return nil, err
+				}
+				if events, ok := tt.results[runner]; ok {
+					return events, nil
+				}
+				t.Fatalf("unexpected run on runner %s", runner)
+				return nil, nil
+			}
+
+			events, runner, err := executeWithRunnerFallback(context.Background(), client, tt.candidates, run, io.Discard)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, "primary", runner)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRunner, runner)
+			first := <-events
+			assert.Equal(t, tt.wantFirst, first.Data)
+			for range events {
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// toolSearchClient is the part of kubiya.Client used by kubiya tool search
+type toolSearchClient interface {
+	ListAgents(ctx context.Context) ([]kubiya.Agent, error)
+	ListSources(ctx context.Context) ([]kubiya.Source, error)
+	GetSourceMetadataCached(ctx context.Context, sourceUUID string) (*kubiya.Source, error)
+}
+
+// rankedTool is a tool found by kubiya tool search
+type rankedTool struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source"`
+	SourceUUID  string   `json:"source_uuid"`
+	Score       int      `json:"score"`
+	MatchedOn   []string `json:"matched_on"`
+	Agents      []string `json:"agents"`
+}
+
+// toolSearchReport is the result of kubiya tool search
+type toolSearchReport struct {
+	Query         string       `json:"query"`
+	Total         int          `json:"total"`
+	Tools         []rankedTool `json:"results"` // by rank; "tools" would be sorted by name in JSON output
+	Sources       int          `json:"sources"`
+	FailedSources []string     `json:"failed_sources,omitempty"`
+	AgentsError   string       `json:"agents_error,omitempty"`
+}
+
+// Weights of the fields of a tool a query word can be found in
+const (
+	toolNameWeight        = 10
+	toolAliasWeight       = 8
+	toolDescriptionWeight = 5
+	toolArgsWeight        = 3
+	toolSourceWeight      = 2
+)
+
+func newSearchToolsCommand(cfg *config.Config) *cobra.Command {
+	var (
+		outputFormat   string
+		limit          int
+		nonInteractive bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "🔍 Search for tools",
+		Long: `Search the tools of all sources by name, description and argument
+descriptions, and rank them by relevance.
+
+Each word of the query is looked up in the name, alias, description, argument
+names and descriptions and source of every tool. Words match on their stem
+("deployments" finds "deploy") and despite typos, and a tool ranks higher the
+more words it matches and the more relevant the fields they match in. The
+agents having the source of a tool, or the tool itself, are listed as the
+agents that can already execute it.`,
+		Args: cobra.MinimumNArgs(1),
+		Example: `  # Search for tools
+  kubiya tool search "rollback deployment"
+
+  # Show the 20 best matches
+  kubiya tool search rollback deployment --limit 20
+
+  # JSON output
+  kubiya tool search deploy --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+			query := strings.Join(args, " ")
+			if !nonInteractive && outputFormat == "text" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "🔍 Searching tools for %q...\n", query)
+			}
+
+			report, err := searchToolsRanked(cmd.Context(), kubiya.NewClient(cfg), query, limit)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, report)
+			}
+			printToolSearchReport(out, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of results (0 for all)")
+	cmd.Flags().BoolVarP(&nonInteractive, "non-interactive", "n", false, "Do not print progress")
+	return cmd
+}
+
+// searchToolsRanked searches the tools of every source, five sources at a
+// time, and returns the best matches first. Sources that cannot be read are
+// reported rather than failing the search, and so are the agents.
+func searchToolsRanked(ctx context.Context, client toolSearchClient, query string, limit int) (*toolSearchReport, error) {
+	words := toolSearchWords(query)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("the query has no words to search for")
+	}
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+	report := &toolSearchReport{Query: query, Tools: []rankedTool{}, Sources: len(sources)}
+
+	agents, err := client.ListAgents(ctx)
+	if err != nil {
+		report.AgentsError = err.Error()
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, 5)
+	)
+	for _, s := range sources {
+		wg.Add(1)
+		go func(s kubiya.Source) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			metadata, err := client.GetSourceMetadataCached(ctx, s.UUID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.FailedSources = append(report.FailedSources, firstNonEmpty(s.Name, s.UUID))
+				return
+			}
+			tools := append(append([]kubiya.Tool(nil), metadata.Tools...), metadata.InlineTools...)
+			for _, tool := range tools {
+				score, matchedOn := rankTool(words, tool, s.Name)
+				if score == 0 {
+					continue
+				}
+				report.Tools = append(report.Tools, rankedTool{
+					Name:        tool.Name,
+					Description: tool.Description,
+					Source:      s.Name,
+					SourceUUID:  s.UUID,
+					Score:       score,
+					MatchedOn:   matchedOn,
+					Agents:      agentsRunningTool(agents, s.UUID, tool.Name),
+				})
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	sort.Strings(report.FailedSources)
+	sort.SliceStable(report.Tools, func(i, j int) bool {
+		a, b := report.Tools[i], report.Tools[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Source < b.Source
+	})
+	report.Total = len(report.Tools)
+	if limit > 0 && len(report.Tools) > limit {
+		report.Tools = report.Tools[:limit]
+	}
+	return report, nil
+}
+
+// rankTool scores a tool against the words of a query. Every word found
+// counts the weight of the best field it is found in, halved when found by
+// its stem or despite a typo only. Tools matching more words always rank
+// higher, and a name equal to the query ranks first. It returns 0 when no
+// word is found.
+func rankTool(words []string, tool kubiya.Tool, source string) (int, []string) {
+	argTexts := make([]string, 0, 2*len(tool.Args))
+	for _, a := range tool.Args {
+		argTexts = append(argTexts, a.Name, a.Description)
+	}
+	fields := []struct {
+		name   string
+		weight int
+		words  []string
+	}{
+		{"name", toolNameWeight, toolSearchWords(tool.Name)},
+		{"alias", toolAliasWeight, toolSearchWords(tool.Alias)},
+		{"description", toolDescriptionWeight, toolSearchWords(tool.Description)},
+		{"args", toolArgsWeight, toolSearchWords(strings.Join(argTexts, " "))},
+		{"source", toolSourceWeight, toolSearchWords(source)},
+	}
+
+	score, found := 0, 0
+	var matchedOn []string
+	for _, w := range words {
+		best, bestField := 0, ""
+		for _, f := range fields {
+			if f.weight*2 <= best {
+				continue
+			}
+			if s := f.weight * matchWord(w, f.words); s > best {
+				best, bestField = s, f.name
+			}
+		}
+		if best == 0 {
+			continue
+		}
+		score += best
+		found++
+		if !contains(matchedOn, bestField) {
+			matchedOn = append(matchedOn, bestField)
+		}
+	}
+	if found == 0 {
+		return 0, nil
+	}
+	// Outweighs any score of the words of a tool matching fewer of them
+	score += found * 2 * toolNameWeight * len(words)
+	if strings.EqualFold(strings.Join(toolSearchWords(tool.Name), " "), strings.Join(words, " ")) {
+		score += 100
+	}
+	return score, matchedOn
+}
+
+// matchWord tells how well a query word matches the words of a field: 2
+// when one of them starts with it, 1 when they share a stem or are a typo
+// apart, 0 otherwise
+func matchWord(w string, fieldWords []string) int {
+	best := 0
+	stem := stemWord(w)
+	for _, fw := range fieldWords {
+		if strings.HasPrefix(fw, w) {
+			return 2
+		}
+		if stemWord(fw) == stem || (len(w) >= 5 && kubiya.LevenshteinDistance(w, fw) <= len(w)/5) {
+			best = 1
+		}
+	}
+	return best
+}
+
+// toolSearchWords splits text into lower case words, also at the dashes,
+// underscores and dots of tool names
+func toolSearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemWord strips the common English suffixes of a word, so that
+// "deployments", "deploying" and "deployed" share the stem "deploy"
+func stemWord(w string) string {
+	for _, suffix := range []string{"ments", "ment", "ings", "ing", "ies", "es", "ed", "s"} {
+		if strings.HasSuffix(w, suffix) && len(w)-len(suffix) >= 4 {
+			return strings.TrimSuffix(w, suffix)
+		}
+	}
+	return w
+}
+
+// agentsRunningTool returns the names of the agents having the source of a
+// tool or the tool itself
+func agentsRunningTool(agents []kubiya.Agent, sourceUUID, toolName string) []string {
+	names := []string{}
+	for _, a := range agents {
+		if contains(a.Sources, sourceUUID) || contains(a.Tools, toolName) {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+func printToolSearchReport(w io.Writer, report *toolSearchReport) {
+	if len(report.FailedSources) > 0 {
+		fmt.Fprintf(w, "%s Could not read %d of %d sources: %s\n", style.WarningStyle.Render("⚠️"),
+			len(report.FailedSources), report.Sources, strings.Join(report.FailedSources, ", "))
+	}
+	if report.AgentsError != "" {
+		fmt.Fprintf(w, "%s Could not list agents, the agents running each tool are unknown: %s\n",
+			style.WarningStyle.Render("⚠️"), report.AgentsError)
+	}
+	if len(report.Tools) == 0 {
+		fmt.Fprintf(w, "No tools found matching %q\n", report.Query)
+		return
+	}
+
+	title := fmt.Sprintf(" Top %d of %d tools matching %q ", len(report.Tools), report.Total, report.Query)
+	fmt.Fprintf(w, "\n%s\n\n", style.TitleStyle.Render(title))
+	for i, t := range report.Tools {
+		fmt.Fprintf(w, "%d. %s %s\n", i+1, style.HighlightStyle.Render(t.Name), style.DimStyle.Render("("+t.Source+")"))
+		if t.Description != "" {
+			fmt.Fprintf(w, "   %s\n", t.Description)
+		}
+		fmt.Fprintf(w, "   %s %s\n", style.DimStyle.Render("Matched on:"), strings.Join(t.MatchedOn, ", "))
+		switch {
+		case report.AgentsError != "":
+		case len(t.Agents) == 0:
+			fmt.Fprintf(w, "   %s %s\n", style.DimStyle.Render("Agents:"), style.DimStyle.Render("none yet"))
+		default:
+			fmt.Fprintf(w, "   %s %s\n", style.DimStyle.Render("Agents:"), strings.Join(t.Agents, ", "))
+		}
+		fmt.Fprintf(w, "   %s kubiya tool exec --name %s --source-uuid %s\n\n", style.DimStyle.Render("Run:"), t.Name, t.SourceUUID)
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+)
+
+// failingSourceClient fails to read the metadata of some sources
+type failingSourceClient struct {
+	*fakeSearchClient
+	failing map[string]bool
+}
+
+func (f *failingSourceClient) GetSourceMetadataCached(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	if f.failing[uuid] {
+		return nil, fmt.Errorf("forbidden")
+	}
+	return f.fakeSearchClient.GetSourceMetadataCached(ctx, uuid)
+}
+
+func newFakeToolSearchClient() *fakeSearchClient {
+	return &fakeSearchClient{
+		agents: []kubiya.Agent{
+			{Name: "oncall", Sources: []string{"src-1"}},
+			{Name: "deployer", Tools: []string{"helm-rollback"}},
+			{Name: "billing", Sources: []string{"src-3"}},
+		},
+		sources: []kubiya.Source{
+			{UUID: "src-1", Name: "k8s-tools"},
+			{UUID: "src-2", Name: "helm-tools"},
+			{UUID: "src-3", Name: "cost-tools"},
+		},
+		tools: map[string][]kubiya.Tool{
+			"src-1": {
+				{Name: "rollback-deployment", Description: "Roll back a deployment to its previous revision"},
+				{Name: "scale-deployment", Description: "Scale the replicas of a deployment"},
+				{Name: "list-pods", Description: "List pods"},
+			},
+			"src-2": {
+				{Name: "helm-rollback", Description: "Roll back a helm release", Args: []kubiya.ToolArg{
+					{Name: "release", Description: "Release of the deployments to restore"},
+				}},
+			},
+			"src-3": {
+				{Name: "cost-report", Description: "Report the cost of the cluster"},
+			},
+		},
+	}
+}
+
+func TestSearchToolsRanked(t *testing.T) {
+	report, err := searchToolsRanked(context.Background(), newFakeToolSearchClient(), "rollback deployment", 0)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range report.Tools {
+		names = append(names, tool.Name)
+	}
+	// Tools matching both words first, the name equal to the query before
+	// the words found in the description and arguments
+	assert.Equal(t, []string{"rollback-deployment", "helm-rollback", "scale-deployment"}, names)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 3, report.Sources)
+
+	assert.Equal(t, []string{"name"}, report.Tools[0].MatchedOn)
+	assert.Equal(t, []string{"oncall"}, report.Tools[0].Agents)
+	assert.Equal(t, "src-1", report.Tools[0].SourceUUID)
+	assert.Equal(t, []string{"name", "args"}, report.Tools[1].MatchedOn)
+	assert.Equal(t, []string{"deployer"}, report.Tools[1].Agents)
+}
+
+func TestToolSearchJSONKeepsRank(t *testing.T) {
+	report, err := searchToolsRanked(context.Background(), newFakeToolSearchClient(), "rollback deployment", 0)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, output.EncodeJSON(&buf, report))
+	var decoded toolSearchReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded.Tools, 3)
+	assert.Equal(t, "rollback-deployment", decoded.Tools[0].Name)
+	assert.Equal(t, "scale-deployment", decoded.Tools[2].Name)
+}
+
+func TestSearchToolsRankedLimit(t *testing.T) {
+	report, err := searchToolsRanked(context.Background(), newFakeToolSearchClient(), "rollback deployment", 1)
+	require.NoError(t, err)
+	require.Len(t, report.Tools, 1)
+	assert.Equal(t, "rollback-deployment", report.Tools[0].Name)
+	assert.Equal(t, 3, report.Total)
+}
+
+func TestSearchToolsRankedTypos(t *testing.T) {
+	report, err := searchToolsRanked(context.Background(), newFakeToolSearchClient(), "rolback", 0)
+	require.NoError(t, err)
+	require.Len(t, report.Tools, 2)
+	assert.Equal(t, "helm-rollback", report.Tools[0].Name)
+	assert.Equal(t, "rollback-deployment", report.Tools[1].Name)
+}
+
+func TestSearchToolsRankedFailedSources(t *testing.T) {
+	client := &failingSourceClient{newFakeToolSearchClient(), map[string]bool{"src-1": true}}
+	report, err := searchToolsRanked(context.Background(), client, "rollback", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k8s-tools"}, report.FailedSources)
+	require.Len(t, report.Tools, 1)
+	assert.Equal(t, "helm-rollback", report.Tools[0].Name)
+}
+
+func TestSearchToolsRankedEmptyQuery(t *testing.T) {
+	_, err := searchToolsRanked(context.Background(), newFakeToolSearchClient(), " - ", 0)
+	assert.Error(t, err)
+}
+
+func TestMatchWord(t *testing.T) {
+	tests := []struct {
+		word   string
+		fields []string
+		want   int
+	}{
+		{"deploy", []string{"deployment"}, 2},
+		{"deployments", []string{"deploy"}, 1},
+		{"restarting", []string{"restarted"}, 1},
+		{"kubernets", []string{"kubernetes"}, 1},
+		{"pod", []string{"deployment"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchWord(tt.word, tt.fields))
+		})
+	}
+}
+
+func TestPrintToolSearchReport(t *testing.T) {
+	var buf bytes.Buffer
+	printToolSearchReport(&buf, &toolSearchReport{
+		Query: "rollback", Total: 1, Sources: 2, FailedSources: []string{"secret-tools"},
+		Tools: []rankedTool{{Name: "helm-rollback", Source: "helm-tools", SourceUUID: "src-2", MatchedOn: []string{"name"}, Agents: []string{}}},
+	})
+	out := buf.String()
+	assert.Contains(t, out, "Could not read 1 of 2 sources: secret-tools")
+	assert.Contains(t, out, "helm-rollback")
+	assert.Contains(t, out, "none yet")
+	assert.Contains(t, out, "kubiya tool exec --name helm-rollback --source-uuid src-2")
+}
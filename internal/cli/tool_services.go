@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// waitForServicesPlaceholder in the content of a tool is replaced by the
+	// script waiting for its services
+	waitForServicesPlaceholder = "{{wait_for_services}}"
+	// serviceWaitSeconds is how long tools wait for a service to accept
+	// connections
+	serviceWaitSeconds = 60
+)
+
+// wellKnownServicePorts are the ports waited for when --service gives none
+var wellKnownServicePorts = map[string]int{
+	"postgres":      5432,
+	"mysql":         3306,
+	"mariadb":       3306,
+	"redis":         6379,
+	"mongo":         27017,
+	"rabbitmq":      5672,
+	"elasticsearch": 9200,
+	"memcached":     11211,
+	"docker":        2376,
+	"minio":         9000,
+}
+
+// toolService is a sidecar service of a tool, reachable from the tool on
+// Host:Port
+type toolService struct {
+	Image string
+	Host  string
+	Port  int
+}
+
+// parseToolServices parses --service values: an image with an optional
+// =PORT, e.g. postgres:15 or ghcr.io/acme/api:1.2=8080. The service is
+// reachable on the last path element of the image, without tag.
+func parseToolServices(values []string) ([]toolService, error) {
+	services := make([]toolService, 0, len(values))
+	for _, value := range values {
+		image, portText, hasPort := strings.Cut(strings.TrimSpace(value), "=")
+		if image == "" {
+			return nil, fmt.Errorf("invalid service %q: missing image", value)
+		}
+
+		host := image[strings.LastIndex(image, "/")+1:]
+		host, _, _ = strings.Cut(host, "@")
+		host, _, _ = strings.Cut(host, ":")
+		service := toolService{Image: image, Host: host, Port: wellKnownServicePorts[host]}
+		if hasPort {
+			port, err := strconv.Atoi(portText)
+			if err != nil || port <= 0 || port > 65535 {
+				return nil, fmt.Errorf("invalid service %q: port must be a number between 1 and 65535", value)
+			}
+			service.Port = port
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// serviceImages returns the with_services entries of services
+func serviceImages(services []toolService) []string {
+	images := make([]string, len(services))
+	for i, s := range services {
+		images[i] = s.Image
+	}
+	return images
+}
+
+// serviceWaitScript is a shell snippet that waits until every service
+// accepts connections, using nc or bash's /dev/tcp, whichever the image has
+func serviceWaitScript(services []toolService) (string, error) {
+	var b strings.Builder
+	b.WriteString("# Wait for services\n")
+	fmt.Fprintf(&b, `kubiya_wait_for() { i=0; until nc -z "$1" "$2" 2>/dev/null || (echo > "/dev/tcp/$1/$2") 2>/dev/null; do i=$((i+1)); if [ "$i" -ge %d ]; then echo "$1:$2 is not ready after %ds" >&2; exit 1; fi; sleep 1; done; }`+"\n",
+		serviceWaitSeconds, serviceWaitSeconds)
+	for _, s := range services {
+		if s.Port == 0 {
+			return "", fmt.Errorf("unknown port of service %s, give it as %s=PORT", s.Image, s.Image)
+		}
+		fmt.Fprintf(&b, "kubiya_wait_for %s %d\n", s.Host, s.Port)
+	}
+	return b.String(), nil
+}
+
+// applyServiceWait replaces the wait placeholder in the content of a tool
+// with the script waiting for services, or with wait puts the script first
+// (after the shebang) when there is no placeholder
+func applyServiceWait(content string, services []toolService, wait bool) (string, error) {
+	placeholder := strings.Contains(content, waitForServicesPlaceholder)
+	if len(services) == 0 || (!placeholder && !wait) {
+		if placeholder {
+			return "", fmt.Errorf("%s is used but the tool has no service", waitForServicesPlaceholder)
+		}
+		return content, nil
+	}
+
+	script, err := serviceWaitScript(services)
+	if err != nil {
+		return "", err
+	}
+	if placeholder {
+		return strings.ReplaceAll(content, waitForServicesPlaceholder, strings.TrimSuffix(script, "\n")), nil
+	}
+	if strings.HasPrefix(content, "#!") {
+		shebang, rest, _ := strings.Cut(content, "\n")
+		return shebang + "\n" + script + rest, nil
+	}
+	return script + content, nil
+}
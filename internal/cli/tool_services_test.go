@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolServices(t *testing.T) {
+	services, err := parseToolServices([]string{"postgres:15", "ghcr.io/acme/api:1.2=8080", "redis@sha256:abc", "busybox"})
+	require.NoError(t, err)
+	assert.Equal(t, []toolService{
+		{Image: "postgres:15", Host: "postgres", Port: 5432},
+		{Image: "ghcr.io/acme/api:1.2", Host: "api", Port: 8080},
+		{Image: "redis@sha256:abc", Host: "redis", Port: 6379},
+		{Image: "busybox", Host: "busybox"},
+	}, services)
+	assert.Equal(t, []string{"postgres:15", "ghcr.io/acme/api:1.2", "redis@sha256:abc", "busybox"}, serviceImages(services))
+
+	for _, value := range []string{"=5432", "postgres:15=db", "postgres:15=70000"} {
+		_, err := parseToolServices([]string{value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestApplyServiceWait(t *testing.T) {
+	postgres := []toolService{{Image: "postgres:15", Host: "postgres", Port: 5432}}
+
+	content, err := applyServiceWait("#!/bin/bash\npsql -c 'select 1'", postgres, true)
+	require.NoError(t, err)
+	assert.Regexp(t, `^#!/bin/bash\n# Wait for services\n`, content)
+	assert.Contains(t, content, "kubiya_wait_for postgres 5432\npsql -c 'select 1'")
+
+	content, err = applyServiceWait("set -e\n{{wait_for_services}}\npsql", postgres, false)
+	require.NoError(t, err)
+	assert.Regexp(t, `^set -e\n# Wait for services\n.*\nkubiya_wait_for postgres 5432\npsql$`, content)
+
+	content, err = applyServiceWait("psql", postgres, false)
+	require.NoError(t, err)
+	assert.Equal(t, "psql", content, "no wait unless asked for")
+
+	_, err = applyServiceWait("run", []toolService{{Image: "busybox", Host: "busybox"}}, true)
+	assert.ErrorContains(t, err, "give it as busybox=PORT")
+
+	_, err = applyServiceWait("{{wait_for_services}}\nrun", nil, false)
+	assert.ErrorContains(t, err, "has no service")
+}
@@ -8,14 +8,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"text/tabwriter"
 	"time"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
+	"github.com/kubiyabot/cli/internal/integrations"
 	"github.com/kubiyabot/cli/internal/kubiya"
+	klog "github.com/kubiyabot/cli/internal/log"
 	"github.com/kubiyabot/cli/internal/style"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -61,9 +64,9 @@ func newToolsCommand(cfg *config.Config) *cobra.Command {
 	}
 
 	cmd.AddCommand(
-		newListToolsCommand(cfg),
+		paged(newListToolsCommand(cfg)),
 		newSearchToolsCommand(cfg),
-		newDescribeToolCommand(cfg),
+		paged(newDescribeToolCommand(cfg)),
 		newGenerateToolCommand(cfg),
 		newExecToolCommand(cfg),
 		newToolIntegrationsCommand(cfg),
@@ -122,7 +125,7 @@ func newListToolsCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(tools)
+				return printJSONList(tools)
 			case "text":
 				if len(tools) == 0 {
 					fmt.Println("No tools found")
@@ -184,226 +187,6 @@ func newListToolsCommand(cfg *config.Config) *cobra.Command {
 	return cmd
 }
 
-func newSearchToolsCommand(cfg *config.Config) *cobra.Command {
-	var (
-		outputFormat   string
-		nonInteractive bool
-	)
-
-	cmd := &cobra.Command{
-		Use:   "search [query]",
-		Short: "🔍 Search for tools",
-		Args:  cobra.ExactArgs(1),
-		Example: `  # Interactive search (default)
-  kubiya tool search kubernetes
-
-  # Non-interactive search
-  kubiya tool search kubernetes --non-interactive
-
-  # JSON output
-  kubiya tool search deploy --output json`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			client := kubiya.NewClient(cfg)
-			query := strings.ToLower(args[0])
-
-			// Create context that can be cancelled
-			ctx, cancel := context.WithCancel(cmd.Context())
-			defer cancel()
-
-			// Handle interrupt
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt)
-			go func() {
-				<-sigChan
-				cancel()
-			}()
-
-			// Initialize all variables before any goto statements
-			var matches []struct {
-				Tool     kubiya.Tool
-				Source   kubiya.Source
-				Distance int
-			}
-			var completed int
-			spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-			spinnerIdx := 0
-			ticker := time.NewTicker(100 * time.Millisecond)
-			defer ticker.Stop()
-
-			sources, err := client.ListSources(ctx)
-			if err != nil {
-				return err
-			}
-
-			// Pre-filter sources based on name/description prefix match
-			var relevantSources []kubiya.Source
-			for _, source := range sources {
-				if strings.Contains(strings.ToLower(source.Name), query) ||
-					strings.Contains(strings.ToLower(source.Description), query) {
-					relevantSources = append(relevantSources, source)
-					continue
-				}
-				// If no direct match, check if source name starts with any word from query
-				queryWords := strings.Fields(query)
-				for _, word := range queryWords {
-					if strings.HasPrefix(strings.ToLower(source.Name), word) ||
-						strings.HasPrefix(strings.ToLower(source.Description), word) {
-						relevantSources = append(relevantSources, source)
-						break
-					}
-				}
-			}
-
-			// If no relevant sources found, search all sources
-			if len(relevantSources) == 0 {
-				relevantSources = sources
-			}
-
-			type searchResult struct {
-				Tool     kubiya.Tool
-				Source   kubiya.Source
-				Distance int
-				Error    error
-			}
-
-			resultChan := make(chan searchResult, len(relevantSources))
-			sem := make(chan struct{}, 5)
-
-			if !nonInteractive {
-				fmt.Printf("🔍 Starting search for '%s' in %d sources...\n", args[0], len(relevantSources))
-			}
-
-			var activeSearches int
-			for _, source := range relevantSources {
-				select {
-				case <-ctx.Done():
-					goto SUMMARIZE
-				case sem <- struct{}{}:
-				}
-
-				activeSearches++
-				go func(s kubiya.Source) {
-					defer func() {
-						<-sem
-						resultChan <- searchResult{Source: s, Error: fmt.Errorf("done")}
-					}()
-
-					metadata, err := client.GetSourceMetadataCached(ctx, s.UUID)
-					if err != nil {
-						resultChan <- searchResult{Source: s, Error: err}
-						return
-					}
-
-					// First check for exact matches
-					for _, tool := range metadata.Tools {
-						select {
-						case <-ctx.Done():
-							return
-						default:
-							toolName := strings.ToLower(tool.Name)
-							toolDesc := strings.ToLower(tool.Description)
-
-							// Prioritize exact matches
-							if strings.Contains(toolName, query) || strings.Contains(toolDesc, query) {
-								resultChan <- searchResult{Tool: tool, Source: s, Distance: 0}
-								continue
-							}
-
-							// Then check Levenshtein distance for close matches
-							nameDistance := kubiya.LevenshteinDistance(toolName, query)
-							descDistance := kubiya.LevenshteinDistance(toolDesc, query)
-							distance := min(nameDistance, descDistance)
-
-							if distance <= len(query)/2 {
-								resultChan <- searchResult{Tool: tool, Source: s, Distance: distance}
-							}
-						}
-					}
-				}(source)
-			}
-
-			for completed < activeSearches {
-				select {
-				case <-ctx.Done():
-					goto SUMMARIZE
-				case result := <-resultChan:
-					if result.Error != nil {
-						if result.Error.Error() != "done" {
-							continue
-						}
-						completed++
-					} else {
-						matches = append(matches, struct {
-							Tool     kubiya.Tool
-							Source   kubiya.Source
-							Distance int
-						}{result.Tool, result.Source, result.Distance})
-					}
-					if !nonInteractive {
-						fmt.Printf("\r\033[K🔍 Progress: %d/%d sources (%d matches)",
-							completed, activeSearches, len(matches))
-					}
-				case <-ticker.C:
-					if !nonInteractive {
-						fmt.Printf("\r\033[K🔍 Searching... %s", spinner[spinnerIdx])
-						spinnerIdx = (spinnerIdx + 1) % len(spinner)
-					}
-				}
-
-				// Early return if we have enough exact matches
-				if len(matches) >= 10 && matches[0].Distance == 0 {
-					goto SUMMARIZE
-				}
-			}
-
-		SUMMARIZE:
-			fmt.Printf("\r\033[K") // Clear line
-
-			// Sort first by distance, then by name for ties
-			sort.Slice(matches, func(i, j int) bool {
-				if matches[i].Distance == matches[j].Distance {
-					return matches[i].Tool.Name < matches[j].Tool.Name
-				}
-				return matches[i].Distance < matches[j].Distance
-			})
-
-			// Limit to top 10 matches
-			if len(matches) > 10 {
-				matches = matches[:10]
-			}
-
-			switch outputFormat {
-			case "json":
-				return json.NewEncoder(os.Stdout).Encode(matches)
-			case "text":
-				if len(matches) == 0 {
-					fmt.Printf("No tools found matching '%s'\n", args[0])
-					return nil
-				}
-
-				fmt.Printf("\n%s\n\n", style.TitleStyle.Render(fmt.Sprintf(" Top %d Tools ", len(matches))))
-
-				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-				for i, match := range matches {
-					fmt.Fprintf(w, "%d. %s\n", i+1, style.HighlightStyle.Render(match.Tool.Name))
-					fmt.Fprintf(w, "   Source: %s\n", match.Source.Name)
-					if match.Tool.Description != "" {
-						fmt.Fprintf(w, "   %s\n", match.Tool.Description)
-					}
-					fmt.Fprintln(w)
-				}
-				return w.Flush()
-			default:
-				return fmt.Errorf("unknown output format: %s", outputFormat)
-			}
-		},
-	}
-
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
-	cmd.Flags().BoolVarP(&nonInteractive, "non-interactive", "n", false, "Non-interactive search mode")
-	return cmd
-}
-
 func newDescribeToolCommand(cfg *config.Config) *cobra.Command {
 	var (
 		outputFormat string
@@ -492,7 +275,7 @@ func newDescribeToolCommand(cfg *config.Config) *cobra.Command {
 
 			switch outputFormat {
 			case "json":
-				return json.NewEncoder(os.Stdout).Encode(tool)
+				return printJSON(tool)
 			case "text":
 				fmt.Printf("\n%s\n\n", style.TitleStyle.Render(fmt.Sprintf(" 🛠️  Tool: %s ", tool.Name)))
 				fmt.Printf("%s %s\n\n", style.SubtitleStyle.Render("Source:"), sourceName)
@@ -604,7 +387,7 @@ func newExecToolCommand(cfg *config.Config) *cobra.Command {
 		skipHealthCheck bool
 		skipPolicyCheck bool
 		timeout         int
-		integrations    []string
+		integrationList []string
 		withFiles       []string
 		withVolumes     []string
 		withServices    []string
@@ -614,6 +397,14 @@ func newExecToolCommand(cfg *config.Config) *cobra.Command {
 		iconURL         string
 		toolURL         string
 		sourceUUID      string
+		stderrFile      string
+		runnerFallback  string
+		watchFiles      []string
+
+		preferRunnerLabels []string
+
+		// Output of the tool, kept to diff runs with --watch-file
+		runOutput *strings.Builder
 	)
 
 	cmd := &cobra.Command{
@@ -623,9 +414,11 @@ func newExecToolCommand(cfg *config.Config) *cobra.Command {
 The tool execution will stream output in real-time.
 
 By default, the runner is set to "auto" which will:
-1. Try to use 'kubiya-hosted' if it's healthy
-2. Fall back to the first available healthy runner if kubiya-hosted is not available
-3. Show which runner was selected and why
+1. Probe the healthy runners and measure their round trip
+2. Rank them by probe latency plus the median start latency of recent
+   executions (kept in ~/.kubiya/runner-latency.json)
+3. Prefer runners having the labels of --prefer-runner-labels (e.g. zone=eu)
+4. Show the ranking and the runner selected
 
 Environment Variables:
   KUBIYA_TOOL_TIMEOUT       - Default timeout in seconds (default: 300)
@@ -633,7 +426,17 @@ Environment Variables:
   KUBIYA_DEFAULT_RUNNER     - Default runner when "default" is specified or runner is empty
   KUBIYA_TOOL_OUTPUT_FORMAT - Default output format: text or stream-json (default: text)
   KUBIYA_TOOL_TYPE          - Default tool type (default: docker)
-  KUBIYA_SKIP_HEALTH_CHECK  - Skip runner health check if set to "true" or "1"`,
+  KUBIYA_SKIP_HEALTH_CHECK  - Skip runner health check if set to "true" or "1"
+
+Use --runner-fallback to retry on another runner when the selected one is
+unhealthy or unreachable, i.e. when the execution could not start. A tool
+that started is never run again elsewhere, even when it fails or times out.
+"auto" tries the other healthy runners, a comma separated list tries the
+given runners in order.
+
+Use --watch-file while writing a tool: the tool runs again, with the same
+arguments, whenever one of the watched files changes, and the changes of its
+output since the previous run are shown as a diff.`,
 		Example: `  # Execute a simple bash tool (auto runner selection)
   kubiya tool exec --name "hello" --content "echo Hello World"
 
@@ -644,15 +447,29 @@ Environment Variables:
   # Execute with a specific runner
   kubiya tool exec --name "test" --content "date" --runner core-testing-1
 
+  # Auto-select the fastest runner in the EU zone
+  kubiya tool exec --name "test" --content "date" --runner auto --prefer-runner-labels zone=eu
+
+  # Retry on other runners if the selected one fails
+  kubiya tool exec --name "test" --content "date" --runner core-testing-1 --runner-fallback auto
+  kubiya tool exec --name "test" --content "date" --runner core-testing-1 --runner-fallback backup-1,backup-2
+
   # Execute a tool from JSON file
   kubiya tool exec --json-file tool.json
 
+  # Run the tool again on every save of its definition or script
+  kubiya tool exec --json-file tool.json --args '{"env":"dev"}' \
+    --with-file ./deploy.sh:/deploy.sh --watch-file tool.json --watch-file deploy.sh
+
   # Execute with direct JSON input
   kubiya tool exec --json '{"name":"test","type":"docker","image":"alpine","content":"echo hello"}' 
 
   # Execute with raw JSON stream output
   kubiya tool exec --name "test" --content "date" --output stream-json
 
+  # Capture stderr in a file (stdout keeps streaming to the terminal)
+  kubiya tool exec --name "build" --content "make" --stderr-file build.err
+
   # Execute with custom timeout (in seconds)
   kubiya tool exec --name "long-job" --content "sleep 60" --timeout 120
 
@@ -758,7 +575,7 @@ Environment Variables:
 				fmt.Printf("%s Loading tool from URL: %s\n", style.InfoStyle.Render("🌐"), toolURL)
 
 				// Fetch the tool definition from URL
-				resp, err := http.Get(toolURL)
+				resp, err := httpclient.Get(toolURL)
 				if err != nil {
 					return fmt.Errorf("failed to fetch tool from URL: %w", err)
 				}
@@ -812,7 +629,7 @@ Environment Variables:
 				for _, tool := range source.Tools {
 					if tool.Name == toolName {
 						// Convert tool to map
-						toolJSON, err := json.Marshal(tool)
+						toolJSON, err := json.Marshal(pinnedTool(tool, source))
 						if err != nil {
 							return fmt.Errorf("failed to marshal tool: %w", err)
 						}
@@ -829,7 +646,7 @@ Environment Variables:
 					for _, tool := range source.InlineTools {
 						if tool.Name == toolName {
 							// Convert tool to map
-							toolJSON, err := json.Marshal(tool)
+							toolJSON, err := json.Marshal(pinnedTool(tool, source))
 							if err != nil {
 								return fmt.Errorf("failed to marshal tool: %w", err)
 							}
@@ -847,6 +664,9 @@ Environment Variables:
 				}
 
 				fmt.Printf("%s Loaded tool: %s\n", style.SuccessStyle.Render("✓"), toolName)
+				if source.PinnedRef != "" {
+					fmt.Printf("%s Source pinned to %s\n", style.DimStyle.Render("📌"), source.PinnedRef)
+				}
 			} else if jsonInput != "" {
 				// Parse direct JSON input
 				if err := json.Unmarshal([]byte(jsonInput), &toolDef); err != nil {
@@ -899,26 +719,25 @@ Environment Variables:
 			}
 
 			// Apply integration templates
-			if len(integrations) > 0 {
+			if len(integrationList) > 0 {
 				fmt.Printf("%s Applying integration templates...\n", style.InfoStyle.Render("🔌"))
-				fs := afero.NewOsFs()
-				allIntegrations, err := GetAllToolIntegrations(fs)
+				registry, err := integrations.Load(afero.NewOsFs())
 				if err != nil {
 					return fmt.Errorf("failed to load integrations: %w", err)
 				}
 
-				for _, integrationName := range integrations {
-					integration, ok := allIntegrations[integrationName]
+				for _, integrationName := range integrationList {
+					integration, ok := registry.Get(integrationName)
 					if !ok {
-						return fmt.Errorf("integration '%s' not found", integrationName)
+						return fmt.Errorf("integration '%s' not found (see 'kubiya integration templates list')", integrationName)
 					}
 
 					fmt.Printf("%s Applying %s integration (%s)\n",
 						style.SuccessStyle.Render("✓"),
-						style.HighlightStyle.Render(integrationName),
+						style.HighlightStyle.Render(integration.Name),
 						integration.Description)
 
-					if err := ApplyIntegrationToTool(toolDef, integration); err != nil {
+					if err := registry.ApplyTemplate(toolDef, integration); err != nil {
 						return fmt.Errorf("failed to apply integration '%s': %w", integrationName, err)
 					}
 				}
@@ -957,48 +776,35 @@ Environment Variables:
 
 			// Handle auto runner selection
 			selectedRunner := runner
+			primaryUnhealthy := false
+			var latencies *kubiya.RunnerLatencyHistory
 			if runner == "auto" {
 				fmt.Printf("%s Auto-selecting runner...\n", style.InfoStyle.Render("🔍"))
 
-				// Try kubiya-hosted first
-				runnerInfo, err := client.GetRunner(ctx, "kubiya-hosted")
-				if err == nil && isRunnerHealthy(runnerInfo) {
-					selectedRunner = "kubiya-hosted"
-					fmt.Printf("%s Selected primary runner: %s (healthy)\n",
-						style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(selectedRunner))
-				} else {
-					// kubiya-hosted is not healthy, find an alternative
-					if err != nil {
-						fmt.Printf("%s Primary runner 'kubiya-hosted' not accessible: %v\n",
-							style.WarningStyle.Render("⚠️"), err)
-					} else {
-						fmt.Printf("%s Primary runner 'kubiya-hosted' is not healthy (status: %s)\n",
-							style.WarningStyle.Render("⚠️"), runnerInfo.RunnerHealth.Status)
-					}
-
-					// List all runners and find a healthy one
-					fmt.Printf("%s Searching for alternative runners...\n", style.DimStyle.Render("›"))
-					runners, err := client.ListRunners(ctx)
-					if err != nil {
-						return fmt.Errorf("failed to list runners: %w", err)
-					}
-
-					// Find the first healthy runner
-					for _, r := range runners {
-						if r.Name != "kubiya-hosted" && isRunnerHealthy(r) {
-							selectedRunner = r.Name
-							fmt.Printf("%s Selected fallback runner: %s (healthy, %s)\n",
-								style.SuccessStyle.Render("✓"),
-								style.HighlightStyle.Render(selectedRunner),
-								r.RunnerType)
-							break
-						}
-					}
-
-					// If no healthy runner found
-					if selectedRunner == "auto" {
-						return fmt.Errorf("no healthy runners available")
-					}
+				preferLabels, err := kubiya.ParseRunnerLabels(preferRunnerLabels)
+				if err != nil {
+					return err
+				}
+				latencies, err = kubiya.LoadRunnerLatencyHistory()
+				if err != nil {
+					return err
+				}
+				ranked, err := client.SelectRunner(ctx, kubiya.RunnerSelectOptions{PreferLabels: preferLabels, History: latencies})
+				if err != nil {
+					return err
+				}
+				if len(preferLabels) > 0 && !ranked[0].Preferred {
+					fmt.Printf("%s No healthy runner has the labels %s, using the fastest one\n",
+						style.WarningStyle.Render("⚠️"), strings.Join(preferRunnerLabels, ","))
+				}
+				printRankedRunners(os.Stdout, ranked, 3)
+
+				selectedRunner = ranked[0].Runner.Name
+				fmt.Printf("%s Selected runner: %s\n",
+					style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(selectedRunner))
+				if runnerFallback == runnerFallbackAuto {
+					// Fall back in ranking order rather than listing order
+					runnerFallback = strings.Join(rankedRunnerNames(ranked[1:]), ",")
 				}
 			} else if !skipHealthCheck {
 				// For explicitly specified runners, check health
@@ -1008,16 +814,21 @@ Environment Variables:
 						style.WarningStyle.Render("⚠️"), err)
 				} else {
 					// Check runner health status
-					if !isRunnerHealthy(runnerInfo) {
+					if !isRunnerHealthy(runnerInfo) && runnerFallback != "" {
+						primaryUnhealthy = true
+						fmt.Printf("%s Runner '%s' is not healthy (status: %s), falling back\n",
+							style.WarningStyle.Render("⚠️"), selectedRunner, runnerInfo.RunnerHealth.Status)
+					} else if !isRunnerHealthy(runnerInfo) {
 						if runnerInfo.RunnerHealth.Error != "" {
 							return fmt.Errorf("runner '%s' is not healthy: %s (error: %s)",
 								selectedRunner, runnerInfo.RunnerHealth.Status, runnerInfo.RunnerHealth.Error)
 						}
 						return fmt.Errorf("runner '%s' is not healthy: %s",
 							selectedRunner, runnerInfo.RunnerHealth.Status)
+					} else {
+						fmt.Printf("%s Runner '%s' is healthy (v%s)\n",
+							style.SuccessStyle.Render("✓"), selectedRunner, runnerInfo.Version)
 					}
-					fmt.Printf("%s Runner '%s' is healthy (v%s)\n",
-						style.SuccessStyle.Render("✓"), selectedRunner, runnerInfo.Version)
 				}
 			}
 
@@ -1058,8 +869,8 @@ Environment Variables:
 			// Show execution info
 			fmt.Printf("\n%s Executing tool: %s\n", style.StatusStyle.Render("🚀"), style.HighlightStyle.Render(toolName))
 			fmt.Printf("%s Runner: %s", style.DimStyle.Render("📍"), style.HighlightStyle.Render(selectedRunner))
-			if runner == "auto" && selectedRunner != "kubiya-hosted" {
-				fmt.Printf(" %s", style.DimStyle.Render("(auto-selected fallback)"))
+			if runner == "auto" {
+				fmt.Printf(" %s", style.DimStyle.Render("(auto-selected)"))
 			}
 			fmt.Printf("\n%s Timeout: ", style.DimStyle.Render("⏱️"))
 			if timeout == 0 {
@@ -1070,26 +881,79 @@ Environment Variables:
 			fmt.Println()
 
 			argVals := make(map[string]any)
-			
+
 			// Parse arguments from --args JSON or --arg flags
 			if err := parseToolArguments(toolDef, args, argsJSON, argVals); err != nil {
 				return fmt.Errorf("failed to parse tool arguments: %w", err)
 			}
+			// Runners to try, in order, when --runner-fallback is set
+			candidates := []string{selectedRunner}
+			if runnerFallback != "" {
+				fallbacks, err := fallbackRunners(ctx, client, runnerFallback, selectedRunner)
+				if err != nil {
+					return err
+				}
+				if primaryUnhealthy {
+					candidates = fallbacks
+				} else {
+					candidates = append(candidates, fallbacks...)
+				}
+			}
+			notices := io.Writer(os.Stdout)
+			if outputFormat == "stream-json" {
+				notices = os.Stderr
+			}
+
 			// Execute tool with streaming
-			events, err := client.ExecuteToolWithTimeout(ctx, toolName, toolDef, selectedRunner, time.Duration(timeout)*time.Second, argVals)
+			execStart := time.Now()
+			events, servedBy, err := executeWithRunnerFallback(ctx, client, candidates, func(ctx context.Context, runner string) (<-chan kubiya.WorkflowSSEEvent, error) {
+				return client.ExecuteToolWithTimeout(ctx, toolName, toolDef, runner, time.Duration(timeout)*time.Second, argVals)
+			}, notices)
 			if err != nil {
+				// The last runner tried when all of them failed
+				if servedBy == "" && len(candidates) > 0 {
+					servedBy = candidates[len(candidates)-1]
+				}
+				err = withRunnerDiagnostics(ctx, client, servedBy, err)
 				return fmt.Errorf("failed to execute tool: %w", err)
 			}
+			if servedBy != selectedRunner {
+				fmt.Fprintf(notices, "%s Execution served by runner %s (fallback from %s)\n",
+					style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(servedBy), selectedRunner)
+			}
+			if latencies != nil && servedBy == selectedRunner {
+				// The first event has arrived: remember how fast the runner started
+				latencies.Record(servedBy, time.Since(execStart))
+				if err := latencies.Save(); err != nil {
+					klog.For(klog.HTTP).Debug("failed to save runner latencies", "error", err)
+				}
+			}
+
+			// Capture stderr separately when requested
+			var stderrOut *os.File
+			if stderrFile != "" {
+				stderrOut, err = os.Create(stderrFile)
+				if err != nil {
+					return fmt.Errorf("failed to create stderr file: %w", err)
+				}
+				defer stderrOut.Close()
+			}
 
 			// Process streaming events based on output format
 			if outputFormat == "stream-json" {
 				// Raw JSON stream output
 				for event := range events {
+					if chunk, ok := event.OutputChunk(); ok && chunk.Stream == kubiya.StreamStderr && stderrOut != nil {
+						if _, err := stderrOut.WriteString(chunk.Content); err != nil {
+							return fmt.Errorf("failed to write stderr file: %w", err)
+						}
+						continue
+					}
 					if event.Type == "data" {
 						fmt.Println(event.Data)
 					} else if event.Type == "error" {
 						fmt.Fprintf(os.Stderr, `{"type":"error","message":"%s"}`+"\n", event.Data)
-						return fmt.Errorf("tool execution failed")
+						return toolExecutionFailed(ctx, client, servedBy, event.Data)
 					}
 				}
 				return nil
@@ -1097,10 +961,30 @@ Environment Variables:
 
 			// Text output format (default)
 			var hasError bool
+			var lastError string
 			startTime := time.Now()
 			var outputLines []string
 
 			for event := range events {
+				if chunk, ok := event.OutputChunk(); ok {
+					if chunk.Stream == kubiya.StreamStderr && stderrOut != nil {
+						if _, err := stderrOut.WriteString(chunk.Content); err != nil {
+							return fmt.Errorf("failed to write stderr file: %w", err)
+						}
+						continue
+					}
+					if watch {
+						lines := printOutputChunk(chunk)
+						outputLines = append(outputLines, lines...)
+						if runOutput != nil {
+							for _, line := range lines {
+								fmt.Fprintln(runOutput, line)
+							}
+						}
+					}
+					continue
+				}
+
 				switch event.Type {
 				case "data":
 					if watch {
@@ -1124,19 +1008,6 @@ Environment Variables:
 											fmt.Printf("%s %s\n", style.DimStyle.Render("📝"), content)
 										}
 									}
-								case "tool-output":
-									// Display tool output
-									if content, ok := jsonData["content"].(string); ok {
-										// Remove trailing newline for cleaner output
-										content = strings.TrimRight(content, "\n")
-										lines := strings.Split(content, "\n")
-										for _, line := range lines {
-											if line != "" {
-												fmt.Printf("%s %s\n", style.OutputStyle.Render("│"), line)
-												outputLines = append(outputLines, line)
-											}
-										}
-									}
 								case "status":
 									// Handle completion status
 									if status, ok := jsonData["status"].(string); ok {
@@ -1168,10 +1039,14 @@ Environment Variables:
 						} else {
 							// If not JSON, display as plain text
 							fmt.Printf("%s %s\n", style.OutputStyle.Render("│"), event.Data)
+							if runOutput != nil {
+								fmt.Fprintln(runOutput, event.Data)
+							}
 						}
 					}
 				case "error":
 					hasError = true
+					lastError = event.Data
 					fmt.Printf("\n%s Error: %s\n", style.ErrorStyle.Render("✗"), event.Data)
 				case "complete", "done":
 					duration := time.Since(startTime)
@@ -1193,7 +1068,7 @@ Environment Variables:
 			}
 
 			if hasError {
-				return fmt.Errorf("tool execution failed")
+				return toolExecutionFailed(ctx, client, servedBy, lastError)
 			}
 
 			return nil
@@ -1211,9 +1086,11 @@ Environment Variables:
 	cmd.Flags().StringVar(&jsonInput, "json", "", "Tool definition as JSON string")
 	cmd.Flags().StringVar(&outputFormat, "output", "text", "Output format: text (default) or stream-json")
 	cmd.Flags().BoolVar(&skipHealthCheck, "skip-health-check", false, "Skip runner health check")
+	cmd.Flags().StringVar(&runnerFallback, "runner-fallback", "", "Retry on other runners when the runner fails: 'auto' or a comma separated list of runners")
+	cmd.Flags().StringSliceVar(&preferRunnerLabels, "prefer-runner-labels", nil, "With --runner auto, prefer runners having these labels (e.g. zone=eu)")
 	cmd.Flags().BoolVar(&skipPolicyCheck, "skip-policy-check", false, "Skip policy validation check")
 	cmd.Flags().IntVar(&timeout, "timeout", 300, "Timeout in seconds for tool execution (0 for no timeout)")
-	cmd.Flags().StringSliceVar(&integrations, "integration", []string{}, "Integration templates to apply (can be specified multiple times)")
+	cmd.Flags().StringSliceVar(&integrationList, "integration", []string{}, "Integration templates to apply (can be specified multiple times)")
 	cmd.Flags().StringSliceVar(&withFiles, "with-file", []string{}, "File mappings in format 'source:destination' (can be specified multiple times)")
 	cmd.Flags().StringSliceVar(&withVolumes, "with-volume", []string{}, "Volume mappings in format 'source:destination[:ro]' (can be specified multiple times)")
 	cmd.Flags().StringSliceVar(&withServices, "with-service", []string{}, "Service dependencies (can be specified multiple times)")
@@ -1223,6 +1100,39 @@ Environment Variables:
 	cmd.Flags().StringVar(&iconURL, "icon-url", "", "Icon URL for the tool")
 	cmd.Flags().StringVar(&toolURL, "tool-url", "", "URL to load tool definition from")
 	cmd.Flags().StringVar(&sourceUUID, "source-uuid", "", "Source UUID to load tool from")
+	cmd.Flags().StringVar(&stderrFile, "stderr-file", "", "Write the tool's stderr to a file instead of the terminal")
+	cmd.Flags().StringSliceVar(&watchFiles, "watch-file", nil, "Run the tool again when this file changes and diff the output (can be specified multiple times)")
+
+	execOnce := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(watchFiles) == 0 {
+			return execOnce(cmd, args)
+		}
+		if outputFormat == "stream-json" || !cmd.Flags().Changed("output") && os.Getenv("KUBIYA_TOOL_OUTPUT_FORMAT") == "stream-json" {
+			return fmt.Errorf("--watch-file prints diffs and cannot be combined with --output stream-json")
+		}
+		if !watch {
+			return fmt.Errorf("--watch-file needs the output of the tool, it cannot be combined with --watch=false")
+		}
+		watcher, err := newFileWatcher(watchFiles)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		cmd.SetContext(ctx)
+
+		// Runs change some flags, e.g. the name read from --json-file or the
+		// runner picked by auto: start every run from the command line values
+		name, runnerFlag, fallback, toolTypeFlag, timeoutFlag := toolName, runner, runnerFallback, toolType, timeout
+		return watchToolExec(ctx, os.Stdout, watcher, func(ctx context.Context) (string, error) {
+			toolName, runner, runnerFallback, toolType, timeout = name, runnerFlag, fallback, toolTypeFlag, timeoutFlag
+			runOutput = &strings.Builder{}
+			err := execOnce(cmd, args)
+			return runOutput.String(), err
+		})
+	}
 
 	return cmd
 }
@@ -1249,7 +1159,7 @@ func applyToolPropertiesFromFlags(toolDef map[string]interface{}, withFiles, wit
 				return fmt.Errorf("invalid file mapping format: %s (expected source:destination)", fileMapping)
 			}
 
-			source := expandPath(parts[0])
+			source := integrations.ExpandPath(parts[0])
 			dest := parts[1]
 
 			fileMap := map[string]interface{}{
@@ -1258,7 +1168,7 @@ func applyToolPropertiesFromFlags(toolDef map[string]interface{}, withFiles, wit
 			}
 
 			// Check if source is a local file that should be read
-			if IsLocalPath(source) {
+			if integrations.IsLocalPath(source) {
 				content, err := os.ReadFile(source)
 				if err != nil {
 					fmt.Printf("%s Warning: Could not read local file %s: %v\n", style.WarningStyle.Render("⚠"), source, err)
@@ -1288,7 +1198,7 @@ func applyToolPropertiesFromFlags(toolDef map[string]interface{}, withFiles, wit
 			}
 
 			volMap := map[string]interface{}{
-				"source":      expandPath(parts[0]),
+				"source":      integrations.ExpandPath(parts[0]),
 				"destination": parts[1],
 			}
 
@@ -1371,38 +1281,6 @@ func applyToolPropertiesFromFlags(toolDef map[string]interface{}, withFiles, wit
 	return nil
 }
 
-// IsLocalPath checks if a path refers to a local file
-func IsLocalPath(path string) bool {
-	// Paths that start with these are typically remote/container paths
-	remotePathPrefixes := []string{
-		"/var/run/",
-		"/tmp/kubernetes",
-		"/etc/",
-		"/opt/",
-		"/usr/",
-		"/sys/",
-		"/proc/",
-	}
-
-	for _, prefix := range remotePathPrefixes {
-		if strings.HasPrefix(path, prefix) {
-			return false
-		}
-	}
-
-	// Check if path starts with $HOME or ~ (local user paths)
-	if strings.HasPrefix(path, "$HOME") || strings.HasPrefix(path, "~") {
-		return true
-	}
-
-	// Check if file exists locally
-	if _, err := os.Stat(path); err == nil {
-		return true
-	}
-
-	return false
-}
-
 // parseToolArguments parses command line tool arguments and populates argVals
 // Supports both --args JSON format and --arg flags format
 // Arguments from --arg flags are in format "name:type:description[:required]"
@@ -1414,13 +1292,13 @@ func parseToolArguments(toolDef map[string]interface{}, argFlags []string, argsJ
 		if err := json.Unmarshal([]byte(argsJSON), &jsonArgs); err != nil {
 			return fmt.Errorf("failed to parse --args JSON: %w", err)
 		}
-		
+
 		// Copy parsed JSON args to argVals
 		for key, value := range jsonArgs {
 			argVals[key] = value
 		}
-		
-		fmt.Printf("%s Parsed %d arguments from JSON\n", 
+
+		fmt.Printf("%s Parsed %d arguments from JSON\n",
 			style.InfoStyle.Render("ℹ"), len(jsonArgs))
 		return nil
 	}
@@ -1462,7 +1340,7 @@ func parseToolArguments(toolDef map[string]interface{}, argFlags []string, argsJ
 	// For CLI tool exec, we need to prompt for required arguments or set defaults
 	// Since we don't have a way to pass actual values via flags, we'll use defaults
 	// or prompt the user if arguments are required
-	
+
 	for name, argDef := range argDefs {
 		// Check if required
 		required := false
@@ -1502,7 +1380,7 @@ func parseToolArguments(toolDef map[string]interface{}, argFlags []string, argsJ
 			default:
 				argVals[name] = ""
 			}
-			
+
 			fmt.Printf("%s Required argument '%s' set to default value for type '%s'\n",
 				style.InfoStyle.Render("ℹ"), name, argType)
 		}
@@ -1510,3 +1388,26 @@ func parseToolArguments(toolDef map[string]interface{}, argFlags []string, argsJ
 
 	return nil
 }
+
+// printOutputChunk prints tool output line by line, marking stderr lines so
+// they stand out from stdout, and returns the printed lines
+func printOutputChunk(chunk kubiya.OutputChunk) []string {
+	prefix := style.OutputStyle.Render("│")
+	if chunk.Stream == kubiya.StreamStderr {
+		prefix = style.StderrStyle.Render("┃")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(chunk.Content, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if chunk.Stream == kubiya.StreamStderr {
+			fmt.Printf("%s %s\n", prefix, style.StderrStyle.Render(line))
+		} else {
+			fmt.Printf("%s %s\n", prefix, line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+const (
+	tracePageSize = 100
+	traceMaxPages = 10
+)
+
+// auditLister is the part of kubiya.AuditClient used to trace invocations
+type auditLister interface {
+	ListAuditItems(ctx context.Context, query kubiya.AuditQuery) ([]kubiya.AuditItem, error)
+}
+
+func newTraceCommand(cfg *config.Config) *cobra.Command {
+	var (
+		since        time.Duration
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "trace <correlation-id>",
+		Short: "🔎 Show the server-side events of a CLI invocation",
+		Long: `Show the server-side events of a CLI invocation, oldest first.
+
+Every invocation of the CLI sends a correlation ID with its API calls and
+tool executions. It is printed when a command fails, and can be set with
+KUBIYA_CORRELATION_ID to tie several invocations together.`,
+		Example: `  # Events of a failed command
+  kubiya trace 3f0c2a4e-8b1d-4c55-9e3a-2d7f6b1c9a10
+
+  # Look further back, as JSON
+  kubiya trace 3f0c2a4e-8b1d-4c55-9e3a-2d7f6b1c9a10 --since 720h -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := kubiya.NewClient(cfg)
+			items, err := traceInvocation(cmd.Context(), client.Audit(), args[0], time.Now().Add(-since))
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "json":
+				return printJSON(items)
+			case "text", "":
+				return printTrace(args[0], items)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 7*24*time.Hour, "How far back to look for events")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// traceInvocation returns the audit events of the invocation with the given
+// correlation ID, oldest first. Events are also checked on our side, so that
+// a server not filtering by correlation ID does not mix in other events.
+func traceInvocation(ctx context.Context, audit auditLister, id string, since time.Time) ([]kubiya.AuditItem, error) {
+	query := kubiya.AuditQuery{
+		Filter:   kubiya.AuditFilter{CorrelationID: id},
+		PageSize: tracePageSize,
+		Sort:     kubiya.AuditSort{Timestamp: 1},
+	}
+	query.Filter.Timestamp.GTE = since.UTC().Format(time.RFC3339)
+
+	var items []kubiya.AuditItem
+	for page := 1; page <= traceMaxPages; page++ {
+		query.Page = page
+		batch, err := audit.ListAuditItems(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch events of %s: %w", id, err)
+		}
+		for _, item := range batch {
+			if auditCorrelationID(item) == id {
+				items = append(items, item)
+			}
+		}
+		if len(batch) < tracePageSize {
+			break
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Timestamp < items[j].Timestamp
+	})
+	return items, nil
+}
+
+// auditCorrelationID returns the correlation ID an audit event was recorded
+// with
+func auditCorrelationID(item kubiya.AuditItem) string {
+	for _, key := range []string{"correlation_id", correlation.Header} {
+		if id, ok := item.Extra[key].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+func printTrace(id string, items []kubiya.AuditItem) error {
+	fmt.Printf("%s %s\n\n", style.TitleStyle.Render("🔎 Trace"), style.HighlightStyle.Render(id))
+	if len(items) == 0 {
+		fmt.Println(style.DimStyle.Render("No events found. Events take a few seconds to be recorded; try --since for older invocations."))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCATEGORY\tRESOURCE\tACTION\tRESULT")
+	for _, item := range items {
+		category := item.CategoryType
+		if item.CategoryName != "" {
+			category += "/" + item.CategoryName
+		}
+		resource := item.ResourceType
+		if item.ResourceText != "" {
+			resource += ": " + item.ResourceText
+		}
+		result := style.SuccessStyle.Render("Success")
+		if !item.ActionSuccessful {
+			result = style.ErrorStyle.Render("Failed")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Timestamp, category,
+			truncateAuditString(resource, 40), item.ActionType, result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d events\n", len(items))
+	return nil
+}
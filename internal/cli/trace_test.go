@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeAuditLister struct {
+	pages   [][]kubiya.AuditItem
+	queries []kubiya.AuditQuery
+	err     error
+}
+
+func (f *fakeAuditLister) ListAuditItems(ctx context.Context, query kubiya.AuditQuery) ([]kubiya.AuditItem, error) {
+	f.queries = append(f.queries, query)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if query.Page > len(f.pages) {
+		return nil, nil
+	}
+	return f.pages[query.Page-1], nil
+}
+
+func auditEvent(id, ts, action string) kubiya.AuditItem {
+	return kubiya.AuditItem{Timestamp: ts, ActionType: action, Extra: map[string]interface{}{"correlation_id": id}}
+}
+
+func TestTraceInvocation(t *testing.T) {
+	full := make([]kubiya.AuditItem, tracePageSize)
+	for i := range full {
+		full[i] = auditEvent("other", "2026-10-16T10:00:00Z", "list")
+	}
+	full[3] = auditEvent("abc", "2026-10-16T10:00:05Z", "execute")
+	lister := &fakeAuditLister{pages: [][]kubiya.AuditItem{
+		full,
+		{auditEvent("abc", "2026-10-16T10:00:01Z", "create"), {Timestamp: "2026-10-16T10:00:02Z"}},
+	}}
+	since := time.Date(2026, 10, 9, 10, 0, 0, 0, time.UTC)
+
+	items, err := traceInvocation(context.Background(), lister, "abc", since)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "create", items[0].ActionType)
+	assert.Equal(t, "execute", items[1].ActionType)
+
+	require.Len(t, lister.queries, 2)
+	assert.Equal(t, "abc", lister.queries[0].Filter.CorrelationID)
+	assert.Equal(t, "2026-10-09T10:00:00Z", lister.queries[0].Filter.Timestamp.GTE)
+	assert.Equal(t, 1, lister.queries[0].Sort.Timestamp)
+}
+
+func TestTraceInvocationError(t *testing.T) {
+	_, err := traceInvocation(context.Background(), &fakeAuditLister{err: fmt.Errorf("unauthorized")}, "abc", time.Now())
+	assert.ErrorContains(t, err, "failed to fetch events of abc")
+}
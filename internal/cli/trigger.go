@@ -9,17 +9,21 @@ import (
 func newTriggerCommand(cfg *config.Config) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "trigger",
-		Short: "🔗 Manage workflow triggers",
-		Long: `Manage external triggers that can execute workflows automatically.
+		Short: "🔗 Manage workflow and agent triggers",
+		Long: `Manage external triggers that can execute workflows or run agents automatically.
 
 Triggers allow you to integrate with external systems to automatically execute workflows
-when certain events occur. Supported providers include:
+or agents when certain events occur. Supported providers include:
 
 • Datadog: Create webhooks for alerts, monitors, and incidents
-• GitHub: Create webhooks for repository events (coming soon)
+• PagerDuty: Create webhook subscriptions for incidents
+• GitHub: Create webhooks for repository events
 
 Each provider has its own configuration requirements and capabilities.`,
-		Example: `  # Create a Datadog webhook trigger
+		Example: `  # Run an agent when a Datadog monitor alerts
+  kubiya trigger create --provider datadog --monitor-id 123 --agent abc --name cpu-triage --prompt-template triage.tmpl
+
+  # Create a Datadog webhook trigger
   kubiya trigger create datadog --workflow my-workflow.yaml --name "incident-response"
   
   # List all triggers
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// agentTriggerOptions configures a trigger routing provider events to an
+// agent webhook
+type agentTriggerOptions struct {
+	Provider       TriggerProvider
+	Name           string
+	Agent          string
+	Prompt         string
+	PromptTemplate string
+	Method         string
+	Destination    string
+
+	// Datadog
+	MonitorIDs []string
+	DDAPIKey   string
+	DDAppKey   string
+	DDSite     string
+
+	// PagerDuty
+	ServiceID string
+	PDEvents  []string
+	PDToken   string
+
+	// GitHub
+	Repository  string
+	Events      []string
+	Secret      string
+	GitHubToken string
+}
+
+// agentTriggerClient is the part of kubiya.Client used to create agent
+// triggers
+type agentTriggerClient interface {
+	GetAgents(ctx context.Context) ([]kubiya.Agent, error)
+	CreateWebhook(ctx context.Context, webhook kubiya.Webhook) (*kubiya.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+// defaultTriggerPrompts are used when neither --prompt nor --prompt-template
+// is given. The event payload is passed to the agent along with the prompt.
+var defaultTriggerPrompts = map[TriggerProvider]string{
+	ProviderDatadog:   "Investigate this Datadog alert, find the likely root cause and suggest remediation steps.",
+	ProviderPagerDuty: "Investigate this PagerDuty incident, find the likely root cause and suggest remediation steps.",
+	ProviderGitHub:    "Review this GitHub event and take the appropriate action.",
+}
+
+// triggerPrompt returns the prompt of the trigger webhook
+func (o agentTriggerOptions) triggerPrompt() (string, error) {
+	if o.Prompt != "" && o.PromptTemplate != "" {
+		return "", fmt.Errorf("--prompt and --prompt-template are mutually exclusive")
+	}
+	if o.PromptTemplate != "" {
+		content, err := os.ReadFile(o.PromptTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			return "", fmt.Errorf("prompt template %s is empty", o.PromptTemplate)
+		}
+		return string(content), nil
+	}
+	if o.Prompt != "" {
+		return o.Prompt, nil
+	}
+	return defaultTriggerPrompts[o.Provider], nil
+}
+
+// validate checks the provider specific flags
+func (o agentTriggerOptions) validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("--name flag is required")
+	}
+	if o.Method != "http" && o.Destination == "" {
+		return fmt.Errorf("--destination is required for %s notifications", o.Method)
+	}
+	switch o.Provider {
+	case ProviderDatadog, ProviderPagerDuty:
+	case ProviderGitHub:
+		if o.Repository == "" {
+			return fmt.Errorf("--repository flag is required for GitHub provider")
+		}
+	default:
+		return fmt.Errorf("unsupported provider: %s (supported: datadog, pagerduty, github)", o.Provider)
+	}
+	return nil
+}
+
+// webhookName is the name of the trigger in the provider
+func (o agentTriggerOptions) webhookName() string {
+	return strings.ReplaceAll(strings.ToLower(o.Name), " ", "-")
+}
+
+// resolveAgentRef returns the UUID of the agent with the given name or UUID
+func resolveAgentRef(ctx context.Context, client agentTriggerClient, ref string) (string, error) {
+	agents, err := client.GetAgents(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list agents: %w", err)
+	}
+	for _, a := range agents {
+		if a.UUID == ref || a.Name == ref {
+			return a.UUID, nil
+		}
+	}
+	return "", fmt.Errorf("agent %q not found", ref)
+}
+
+// createAgentTrigger creates the agent webhook, then provisions the provider
+// side to call it. The agent webhook is removed again when provisioning fails.
+func createAgentTrigger(ctx context.Context, cfg *config.Config, client agentTriggerClient, opts agentTriggerOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	prompt, err := opts.triggerPrompt()
+	if err != nil {
+		return err
+	}
+
+	// Initialize the provider first so missing credentials fail before
+	// anything is created
+	var provision func(url string) (string, error)
+	switch opts.Provider {
+	case ProviderDatadog:
+		dd, err := NewDatadogProviderWithCredentials(cfg, opts.DDAPIKey, opts.DDAppKey, opts.DDSite)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Datadog provider: %w", err)
+		}
+		provision = func(url string) (string, error) {
+			name := opts.webhookName()
+			if err := dd.CreateAgentWebhook(name, url); err != nil {
+				return "", err
+			}
+			for _, id := range opts.MonitorIDs {
+				if err := dd.AttachWebhookToMonitor(id, name); err != nil {
+					return name, err
+				}
+				fmt.Printf("🔔 Monitor %s notifies @webhook-%s\n", id, name)
+			}
+			return name, nil
+		}
+	case ProviderPagerDuty:
+		pd, err := NewPagerDutyProviderWithCredentials(cfg, opts.PDToken)
+		if err != nil {
+			return fmt.Errorf("failed to initialize PagerDuty provider: %w", err)
+		}
+		provision = func(url string) (string, error) {
+			return pd.CreateWebhookSubscription(opts.Name, url, opts.ServiceID, opts.PDEvents)
+		}
+	case ProviderGitHub:
+		gh, err := NewGitHubProviderWithCredentials(cfg, opts.GitHubToken)
+		if err != nil {
+			return fmt.Errorf("failed to initialize GitHub provider: %w", err)
+		}
+		provision = func(url string) (string, error) {
+			return gh.CreateWebhook(opts.Repository, url, opts.Events, opts.Secret)
+		}
+	}
+
+	agentID, err := resolveAgentRef(ctx, client, opts.Agent)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := client.CreateWebhook(ctx, kubiya.Webhook{
+		Name:    opts.Name,
+		Source:  string(opts.Provider),
+		AgentID: agentID,
+		Prompt:  prompt,
+		Communication: kubiya.Communication{
+			Method:      opts.Method,
+			Destination: opts.Destination,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create agent webhook: %w", err)
+	}
+	if webhook.WebhookURL == "" {
+		_ = client.DeleteWebhook(ctx, webhook.ID)
+		return fmt.Errorf("the API returned no URL for webhook %s", webhook.ID)
+	}
+	fmt.Printf("✅ Created agent webhook %s\n", webhook.ID)
+
+	providerRef, err := provision(webhook.WebhookURL)
+	if err != nil {
+		if providerRef == "" {
+			// Nothing points to the agent webhook yet
+			if delErr := client.DeleteWebhook(ctx, webhook.ID); delErr != nil {
+				fmt.Printf("⚠️  Failed to remove agent webhook %s: %v\n", webhook.ID, delErr)
+			}
+		}
+		return fmt.Errorf("failed to provision %s webhook: %w", opts.Provider, err)
+	}
+
+	fmt.Printf("\n%s\n", style.SuccessStyle.Render("✅ Trigger created successfully!"))
+	fmt.Printf("\n%s\n", style.InfoStyle.Render("Trigger Details:"))
+	fmt.Printf("• Name: %s\n", opts.Name)
+	fmt.Printf("• Provider: %s\n", opts.Provider)
+	fmt.Printf("• Agent: %s\n", opts.Agent)
+	fmt.Printf("• Kubiya webhook: %s\n", webhook.ID)
+	fmt.Printf("• Webhook URL: %s\n", style.HighlightStyle.Render(webhook.WebhookURL))
+	if providerRef != "" {
+		fmt.Printf("• %s webhook: %s\n", opts.Provider, providerRef)
+	}
+	if opts.Destination != "" {
+		fmt.Printf("• Responses: %s %s\n", opts.Method, opts.Destination)
+	}
+
+	fmt.Printf("\n%s\n", style.InfoStyle.Render("Next Steps:"))
+	fmt.Printf("• Watch deliveries: kubiya webhook deliveries %s --follow\n", webhook.ID)
+	if providerRef != "" {
+		fmt.Printf("• Remove the trigger: kubiya trigger delete %s %s\n", opts.Provider, providerRef)
+	}
+	return nil
+}
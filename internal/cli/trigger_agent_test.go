@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAgentTriggerClient struct {
+	agents  []kubiya.Agent
+	created []kubiya.Webhook
+	deleted []string
+	url     string
+}
+
+func (f *fakeAgentTriggerClient) GetAgents(ctx context.Context) ([]kubiya.Agent, error) {
+	return f.agents, nil
+}
+
+func (f *fakeAgentTriggerClient) CreateWebhook(ctx context.Context, webhook kubiya.Webhook) (*kubiya.Webhook, error) {
+	f.created = append(f.created, webhook)
+	webhook.ID = "wh-1"
+	webhook.WebhookURL = f.url
+	return &webhook, nil
+}
+
+func (f *fakeAgentTriggerClient) DeleteWebhook(ctx context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestMentionDatadogWebhook(t *testing.T) {
+	msg, changed := mentionDatadogWebhook("CPU is high", "triage")
+	assert.True(t, changed)
+	assert.Equal(t, "CPU is high\n@webhook-triage", msg)
+
+	msg, changed = mentionDatadogWebhook(msg, "triage")
+	assert.False(t, changed)
+	assert.Equal(t, "CPU is high\n@webhook-triage", msg)
+
+	msg, changed = mentionDatadogWebhook("", "triage")
+	assert.True(t, changed)
+	assert.Equal(t, "@webhook-triage", msg)
+
+	// Prefixes of other handles don't count as a mention
+	_, changed = mentionDatadogWebhook("@webhook-triage-old", "triage")
+	assert.True(t, changed)
+}
+
+func TestAgentTriggerPrompt(t *testing.T) {
+	opts := agentTriggerOptions{Provider: ProviderPagerDuty}
+	prompt, err := opts.triggerPrompt()
+	require.NoError(t, err)
+	assert.Equal(t, defaultTriggerPrompts[ProviderPagerDuty], prompt)
+
+	opts.Prompt = "Look into it"
+	prompt, err = opts.triggerPrompt()
+	require.NoError(t, err)
+	assert.Equal(t, "Look into it", prompt)
+
+	path := filepath.Join(t.TempDir(), "triage.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("Triage {{.event}}\n"), 0644))
+	opts.PromptTemplate = path
+	_, err = opts.triggerPrompt()
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	opts.Prompt = ""
+	prompt, err = opts.triggerPrompt()
+	require.NoError(t, err)
+	assert.Equal(t, "Triage {{.event}}\n", prompt)
+}
+
+func TestAgentTriggerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    agentTriggerOptions
+		wantErr string
+	}{
+		{"datadog", agentTriggerOptions{Provider: ProviderDatadog, Name: "t", Method: "http"}, ""},
+		{"missing name", agentTriggerOptions{Provider: ProviderDatadog, Method: "http"}, "--name"},
+		{"github without repository", agentTriggerOptions{Provider: ProviderGitHub, Name: "t", Method: "http"}, "--repository"},
+		{"slack without destination", agentTriggerOptions{Provider: ProviderPagerDuty, Name: "t", Method: "slack"}, "--destination"},
+		{"unknown provider", agentTriggerOptions{Provider: "opsgenie", Name: "t", Method: "http"}, "unsupported provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveAgentRef(t *testing.T) {
+	client := &fakeAgentTriggerClient{agents: []kubiya.Agent{{UUID: "abc-12345678", Name: "triage"}}}
+
+	id, err := resolveAgentRef(context.Background(), client, "triage")
+	require.NoError(t, err)
+	assert.Equal(t, "abc-12345678", id)
+
+	id, err = resolveAgentRef(context.Background(), client, "abc-12345678")
+	require.NoError(t, err)
+	assert.Equal(t, "abc-12345678", id)
+
+	_, err = resolveAgentRef(context.Background(), client, "missing")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestCreateAgentTriggerRollsBackWithoutURL(t *testing.T) {
+	t.Setenv("PAGERDUTY_TOKEN", "token")
+	client := &fakeAgentTriggerClient{agents: []kubiya.Agent{{UUID: "abc-12345678", Name: "triage"}}}
+
+	err := createAgentTrigger(context.Background(), &config.Config{}, client, agentTriggerOptions{
+		Provider: ProviderPagerDuty,
+		Name:     "pd-incidents",
+		Agent:    "triage",
+		Method:   "http",
+	})
+	assert.ErrorContains(t, err, "no URL")
+	require.Len(t, client.created, 1)
+	assert.Equal(t, "pagerduty", client.created[0].Source)
+	assert.Equal(t, "abc-12345678", client.created[0].AgentID)
+	assert.Equal(t, defaultTriggerPrompts[ProviderPagerDuty], client.created[0].Prompt)
+	assert.Equal(t, []string{"wh-1"}, client.deleted)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
 	"github.com/kubiyabot/cli/internal/style"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +28,19 @@ func newTriggerCreateCommand(cfg *config.Config) *cobra.Command {
 		ddAppKey     string
 		ddSite       string
 		githubToken  string
+		pdToken      string
+		// Agent triggers
+		agent          string
+		prompt         string
+		promptTemplate string
+		method         string
+		destination    string
+		providerFlag   string
+		// Datadog specific
+		monitorIDs []string
+		// PagerDuty specific
+		serviceID string
+		pdEvents  []string
 		// GitHub specific
 		repository string
 		events     []string
@@ -34,16 +48,37 @@ func newTriggerCreateCommand(cfg *config.Config) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "create <provider>",
-		Short: "Create a new workflow trigger",
-		Long: `Create a new trigger that will execute a workflow when external events occur.
+		Use:   "create [provider]",
+		Short: "Create a new workflow or agent trigger",
+		Long: `Create a new trigger that will execute a workflow or run an agent when
+external events occur.
 
 Currently supported providers:
 • datadog - Create Datadog webhook triggers for alerts and incidents  
+• pagerduty - Create PagerDuty webhook subscriptions for incidents (agents only)
 • github - Create GitHub webhook triggers for repository events
 
+With --agent, a Kubiya webhook is created for the agent and the provider is
+configured to call it. With --monitor-id, the webhook is also added to the
+notifications of the given Datadog monitors. Provider API tokens are read from
+flags or environment variables (DD_API_KEY/DD_APPLICATION_KEY, PAGERDUTY_TOKEN,
+GITHUB_TOKEN).
+
 Each provider requires specific configuration and environment variables.`,
-		Example: `  # Create a Datadog webhook trigger
+		Example: `  # Run an agent when a Datadog monitor alerts
+  kubiya trigger create --provider datadog \
+    --monitor-id 123 \
+    --agent abc \
+    --name "cpu-alert-triage" \
+    --prompt-template triage.tmpl
+
+  # Run an agent on PagerDuty incidents of a service
+  kubiya trigger create pagerduty \
+    --agent incident-responder \
+    --name "pd-incidents" \
+    --service-id PXXXXXX
+
+  # Create a Datadog webhook trigger
   kubiya trigger create datadog \
     --workflow my-workflow.yaml \
     --name "incident-response" \
@@ -63,9 +98,44 @@ Each provider requires specific configuration and environment variables.`,
     --webhook-name "kubiya-critical-webhook" \
     --runner "production-runner" \
     --payload '{"alert": "$EVENT_MSG", "severity": "$PRIORITY"}'`,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			provider := TriggerProvider(strings.ToLower(args[0]))
+			if len(args) == 1 && providerFlag != "" && !strings.EqualFold(args[0], providerFlag) {
+				return fmt.Errorf("provider given both as argument (%s) and --provider (%s)", args[0], providerFlag)
+			}
+			if len(args) == 1 {
+				providerFlag = args[0]
+			}
+			if providerFlag == "" {
+				return fmt.Errorf("provider is required (datadog, pagerduty, github)")
+			}
+			provider := TriggerProvider(strings.ToLower(providerFlag))
+
+			if agent != "" {
+				if workflowFile != "" {
+					return fmt.Errorf("--workflow and --agent are mutually exclusive")
+				}
+				return createAgentTrigger(cmd.Context(), cfg, kubiya.NewClient(cfg), agentTriggerOptions{
+					Provider:       provider,
+					Name:           name,
+					Agent:          agent,
+					Prompt:         prompt,
+					PromptTemplate: promptTemplate,
+					Method:         method,
+					Destination:    destination,
+					MonitorIDs:     monitorIDs,
+					DDAPIKey:       ddAPIKey,
+					DDAppKey:       ddAppKey,
+					DDSite:         ddSite,
+					ServiceID:      serviceID,
+					PDEvents:       pdEvents,
+					PDToken:        pdToken,
+					Repository:     repository,
+					Events:         events,
+					Secret:         secret,
+					GitHubToken:    githubToken,
+				})
+			}
 
 			// Validate provider
 			if provider != ProviderDatadog && provider != ProviderGitHub {
@@ -74,7 +144,7 @@ Each provider requires specific configuration and environment variables.`,
 
 			// Validate required flags
 			if workflowFile == "" {
-				return fmt.Errorf("--workflow flag is required")
+				return fmt.Errorf("--workflow or --agent flag is required")
 			}
 
 			if name == "" {
@@ -109,7 +179,13 @@ Each provider requires specific configuration and environment variables.`,
 		},
 	}
 
-	cmd.Flags().StringVarP(&workflowFile, "workflow", "w", "", "Path to the workflow file (required)")
+	cmd.Flags().StringVar(&providerFlag, "provider", "", "Event provider (datadog, pagerduty, github)")
+	cmd.Flags().StringVarP(&workflowFile, "workflow", "w", "", "Path to the workflow file to execute")
+	cmd.Flags().StringVar(&agent, "agent", "", "Agent name or UUID to run on events (instead of --workflow)")
+	cmd.Flags().StringVar(&prompt, "prompt", "", "Prompt sent to the agent with the event payload")
+	cmd.Flags().StringVar(&promptTemplate, "prompt-template", "", "File containing the prompt sent to the agent")
+	cmd.Flags().StringVar(&method, "method", "http", "Where agent responses are sent (slack, teams, http)")
+	cmd.Flags().StringVar(&destination, "destination", "", "Response destination, e.g. a Slack channel (required unless --method http)")
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Human-readable name for the trigger (required)")
 	cmd.Flags().StringVar(&webhookName, "webhook-name", "", "Name for the webhook in the external provider (defaults to trigger name)")
 	cmd.Flags().StringVar(&customHeaders, "custom-headers", "", "Custom headers for the webhook (newline-separated)")
@@ -122,6 +198,14 @@ Each provider requires specific configuration and environment variables.`,
 	cmd.Flags().StringVar(&ddAppKey, "dd-app-key", "", "Datadog application key (alternative to DD_APPLICATION_KEY env var)")
 	cmd.Flags().StringVar(&ddSite, "dd-site", "", "Datadog site (alternative to DD_SITE env var)")
 	cmd.Flags().StringVar(&githubToken, "github-token", "", "GitHub token (alternative to GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&pdToken, "pd-token", "", "PagerDuty API token (alternative to PAGERDUTY_TOKEN env var)")
+
+	// Datadog specific flags
+	cmd.Flags().StringSliceVar(&monitorIDs, "monitor-id", nil, "Datadog monitor IDs to notify the trigger webhook (agent triggers)")
+
+	// PagerDuty specific flags
+	cmd.Flags().StringVar(&serviceID, "service-id", "", "PagerDuty service ID (defaults to all services)")
+	cmd.Flags().StringSliceVar(&pdEvents, "pd-events", nil, "PagerDuty events to subscribe to (default incident.triggered,incident.escalated)")
 	
 	// GitHub specific flags
 	cmd.Flags().StringVar(&repository, "repository", "", "GitHub repository in format 'owner/repo' (required for GitHub)")
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // DatadogProvider implements the TriggerProviderInterface for Datadog
@@ -61,7 +62,7 @@ func NewDatadogProviderWithCredentials(cfg *config.Config, apiKey, appKey, site
 		apiKey:     apiKey,
 		appKey:     appKey,
 		baseURL:    baseURL,
-		httpClient: &http.Client{},
+		httpClient: httpclient.Default(),
 	}, nil
 }
 
@@ -91,11 +92,74 @@ func (d *DatadogProvider) CreateTrigger(trigger *Trigger) error {
 		"payload":        d.getPayload(config, workflowContent),
 	}
 
-	// Create the webhook in Datadog using the correct webhook integration API
+	return d.createWebhook(webhookPayload)
+}
+
+// CreateAgentWebhook creates a Datadog webhook that posts alert details to
+// the URL of a Kubiya agent webhook
+func (d *DatadogProvider) CreateAgentWebhook(name, url string) error {
+	return d.createWebhook(map[string]interface{}{
+		"name":      fmt.Sprintf("webhooks/%s", name),
+		"url":       url,
+		"encode_as": "json",
+		"payload": `{
+		"title": "$EVENT_TITLE",
+		"body": "$EVENT_MSG",
+		"transition": "$ALERT_TRANSITION",
+		"priority": "$PRIORITY",
+		"event_id": "$ID",
+		"monitor_id": "$ALERT_ID",
+		"hostname": "$HOSTNAME",
+		"tags": "$TAGS",
+		"link": "$LINK",
+		"date": "$DATE"
+	}`,
+	})
+}
+
+// AttachWebhookToMonitor mentions the webhook in the monitor message so the
+// monitor notifies it. Monitors already mentioning the webhook are left as is.
+func (d *DatadogProvider) AttachWebhookToMonitor(monitorID, webhookName string) error {
+	url := fmt.Sprintf("%s/api/v1/monitor/%s", d.baseURL, monitorID)
+
+	var monitor struct {
+		Message string `json:"message"`
+	}
+	if err := d.makeDatadogRequest("GET", url, nil, &monitor); err != nil {
+		return fmt.Errorf("failed to get monitor %s: %w", monitorID, err)
+	}
+
+	message, changed := mentionDatadogWebhook(monitor.Message, webhookName)
+	if !changed {
+		return nil
+	}
+	if err := d.makeDatadogRequest("PUT", url, map[string]interface{}{"message": message}, nil); err != nil {
+		return fmt.Errorf("failed to update monitor %s: %w", monitorID, err)
+	}
+	return nil
+}
+
+// mentionDatadogWebhook appends the @webhook-<name> notification handle to a
+// monitor message unless it is already there
+func mentionDatadogWebhook(message, webhookName string) (string, bool) {
+	handle := "@webhook-" + webhookName
+	for _, field := range strings.Fields(message) {
+		if field == handle {
+			return message, false
+		}
+	}
+	if message == "" {
+		return handle, true
+	}
+	return strings.TrimRight(message, "\n") + "\n" + handle, true
+}
+
+// createWebhook creates a webhook with the webhook integration API
+func (d *DatadogProvider) createWebhook(webhookPayload map[string]interface{}) error {
 	url := fmt.Sprintf("%s/api/v1/integration/webhooks", d.baseURL)
 
 	fmt.Printf("🔗 Creating webhook at: %s\n", url)
-	fmt.Printf("📍 Webhook URL: %s\n", kubiyaWebhookURL)
+	fmt.Printf("📍 Webhook URL: %s\n", webhookPayload["url"])
 
 	return d.makeDatadogRequest("POST", url, webhookPayload, nil)
 }
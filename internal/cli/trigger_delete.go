@@ -19,6 +19,7 @@ func newTriggerDeleteCommand(cfg *config.Config) *cobra.Command {
 		ddAppKey    string
 		ddSite      string
 		githubToken string
+		pdToken     string
 		repository  string // For GitHub
 	)
 
@@ -30,12 +31,16 @@ func newTriggerDeleteCommand(cfg *config.Config) *cobra.Command {
 This command connects directly to the provider API to delete the webhook.
 
 For Datadog: Use the webhook name
+For PagerDuty: Use the webhook subscription ID
 For GitHub: Use the webhook ID and specify --repository
 
 This action cannot be undone.`,
 		Example: `  # Delete a Datadog webhook
   kubiya trigger delete datadog my-webhook-name
   
+  # Delete a PagerDuty webhook subscription
+  kubiya trigger delete pagerduty PXXXXXX
+
   # Delete a GitHub webhook
   kubiya trigger delete github 12345 --repository myorg/myrepo
   
@@ -45,7 +50,7 @@ This action cannot be undone.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			provider := TriggerProvider(strings.ToLower(args[0]))
 			webhookID := args[1]
-			return deleteTrigger(cfg, provider, webhookID, repository, force, ddAPIKey, ddAppKey, ddSite, githubToken)
+			return deleteTrigger(cfg, provider, webhookID, repository, force, ddAPIKey, ddAppKey, ddSite, githubToken, pdToken)
 		},
 	}
 
@@ -57,16 +62,17 @@ This action cannot be undone.`,
 	cmd.Flags().StringVar(&ddAppKey, "dd-app-key", "", "Datadog application key (alternative to DD_APPLICATION_KEY env var)")
 	cmd.Flags().StringVar(&ddSite, "dd-site", "", "Datadog site (alternative to DD_SITE env var)")
 	cmd.Flags().StringVar(&githubToken, "github-token", "", "GitHub token (alternative to GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&pdToken, "pd-token", "", "PagerDuty API token (alternative to PAGERDUTY_TOKEN env var)")
 
 	return cmd
 }
 
-func deleteTrigger(cfg *config.Config, provider TriggerProvider, webhookID, repository string, force bool, ddAPIKey, ddAppKey, ddSite, githubToken string) error {
+func deleteTrigger(cfg *config.Config, provider TriggerProvider, webhookID, repository string, force bool, ddAPIKey, ddAppKey, ddSite, githubToken, pdToken string) error {
 	fmt.Printf("%s\n", style.HeaderStyle.Render("🗑️ Delete Trigger"))
 
 	// Validate provider
-	if provider != ProviderDatadog && provider != ProviderGitHub {
-		return fmt.Errorf("unsupported provider: %s (supported: datadog, github)", provider)
+	if provider != ProviderDatadog && provider != ProviderPagerDuty && provider != ProviderGitHub {
+		return fmt.Errorf("unsupported provider: %s (supported: datadog, pagerduty, github)", provider)
 	}
 
 	// Validate GitHub-specific requirements
@@ -112,6 +118,16 @@ func deleteTrigger(cfg *config.Config, provider TriggerProvider, webhookID, repo
 			return fmt.Errorf("failed to delete Datadog webhook: %w", err)
 		}
 
+	case ProviderPagerDuty:
+		pdProvider, err := NewPagerDutyProviderWithCredentials(cfg, pdToken)
+		if err != nil {
+			return fmt.Errorf("failed to initialize PagerDuty provider: %w", err)
+		}
+
+		if err := pdProvider.DeleteWebhookSubscription(webhookID); err != nil {
+			return fmt.Errorf("failed to delete PagerDuty webhook: %w", err)
+		}
+
 	case ProviderGitHub:
 		ghProvider, err := NewGitHubProviderWithCredentials(cfg, githubToken)
 		if err != nil {
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // GitHubProvider implements the TriggerProviderInterface for GitHub
@@ -43,7 +44,7 @@ func NewGitHubProviderWithCredentials(cfg *config.Config, token string) (*GitHub
 		cfg:        cfg,
 		token:      token,
 		baseURL:    baseURL,
-		httpClient: &http.Client{},
+		httpClient: httpclient.Default(),
 	}, nil
 }
 
@@ -64,40 +65,52 @@ func (g *GitHubProvider) CreateTrigger(trigger *Trigger) error {
 	runner := g.getRunnerFromConfig(config)
 	kubiyaWebhookURL := fmt.Sprintf("https://api.kubiya.ai/api/v1/workflow?runner=%s&operation=execute_workflow", runner)
 
+	_, err = g.CreateWebhook(config.Repository, kubiyaWebhookURL, config.Events, config.Secret)
+	return err
+}
+
+// CreateWebhook creates a repository webhook delivering events to url and
+// returns its ID
+func (g *GitHubProvider) CreateWebhook(repository, url string, events []string, secret string) (string, error) {
 	// Prepare webhook payload for GitHub
 	webhookPayload := map[string]interface{}{
 		"name":   "web",
 		"active": true,
-		"events": config.Events,
+		"events": events,
 		"config": map[string]interface{}{
-			"url":          kubiyaWebhookURL,
+			"url":          url,
 			"content_type": "json",
 			"insecure_ssl": "0",
 		},
 	}
 
 	// Add secret if provided
-	if config.Secret != "" {
-		webhookPayload["config"].(map[string]interface{})["secret"] = config.Secret
+	if secret != "" {
+		webhookPayload["config"].(map[string]interface{})["secret"] = secret
 	}
 
 	// Parse repository owner/name
-	parts := strings.Split(config.Repository, "/")
+	parts := strings.Split(repository, "/")
 	if len(parts) != 2 {
-		return fmt.Errorf("repository must be in format 'owner/repo', got: %s", config.Repository)
+		return "", fmt.Errorf("repository must be in format 'owner/repo', got: %s", repository)
 	}
 	owner, repo := parts[0], parts[1]
 
 	// Create the webhook in GitHub
-	url := fmt.Sprintf("%s/repos/%s/%s/hooks", g.baseURL, owner, repo)
+	hooksURL := fmt.Sprintf("%s/repos/%s/%s/hooks", g.baseURL, owner, repo)
 
-	fmt.Printf("🔗 Creating webhook at: %s\n", url)
-	fmt.Printf("📍 Webhook URL: %s\n", kubiyaWebhookURL)
-	fmt.Printf("📦 Repository: %s\n", config.Repository)
-	fmt.Printf("🎯 Events: %s\n", strings.Join(config.Events, ", "))
+	fmt.Printf("🔗 Creating webhook at: %s\n", hooksURL)
+	fmt.Printf("📍 Webhook URL: %s\n", url)
+	fmt.Printf("📦 Repository: %s\n", repository)
+	fmt.Printf("🎯 Events: %s\n", strings.Join(events, ", "))
 
-	var result map[string]interface{}
-	return g.makeGitHubRequest("POST", url, webhookPayload, &result)
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.makeGitHubRequest("POST", hooksURL, webhookPayload, &result); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d", result.ID), nil
 }
 
 // UpdateTrigger updates an existing GitHub webhook trigger
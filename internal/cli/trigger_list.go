@@ -20,6 +20,7 @@ func newTriggerListCommand(cfg *config.Config) *cobra.Command {
 		ddAppKey    string
 		ddSite      string
 		githubToken string
+		pdToken     string
 		repository  string // For GitHub
 	)
 
@@ -44,11 +45,11 @@ You can filter by provider and show only Kubiya-related webhooks.`,
   # List GitHub webhooks for a specific repository
   kubiya trigger list --provider github --repository myorg/myrepo`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listTriggers(cfg, provider, kubiyaOnly, repository, ddAPIKey, ddAppKey, ddSite, githubToken)
+			return listTriggers(cfg, provider, kubiyaOnly, repository, ddAPIKey, ddAppKey, ddSite, githubToken, pdToken)
 		},
 	}
 
-	cmd.Flags().StringVar(&provider, "provider", "", "Filter by provider (datadog, github)")
+	cmd.Flags().StringVar(&provider, "provider", "", "Filter by provider (datadog, pagerduty, github)")
 	cmd.Flags().BoolVar(&kubiyaOnly, "kubiya-only", false, "Show only webhooks that point to Kubiya API")
 	cmd.Flags().StringVar(&repository, "repository", "", "GitHub repository (required for GitHub provider)")
 	
@@ -57,11 +58,12 @@ You can filter by provider and show only Kubiya-related webhooks.`,
 	cmd.Flags().StringVar(&ddAppKey, "dd-app-key", "", "Datadog application key (alternative to DD_APPLICATION_KEY env var)")
 	cmd.Flags().StringVar(&ddSite, "dd-site", "", "Datadog site (alternative to DD_SITE env var)")
 	cmd.Flags().StringVar(&githubToken, "github-token", "", "GitHub token (alternative to GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&pdToken, "pd-token", "", "PagerDuty API token (alternative to PAGERDUTY_TOKEN env var)")
 
 	return cmd
 }
 
-func listTriggers(cfg *config.Config, providerFilter string, kubiyaOnly bool, repository, ddAPIKey, ddAppKey, ddSite, githubToken string) error {
+func listTriggers(cfg *config.Config, providerFilter string, kubiyaOnly bool, repository, ddAPIKey, ddAppKey, ddSite, githubToken, pdToken string) error {
 	fmt.Printf("%s\n", style.HeaderStyle.Render("📋 Workflow Triggers"))
 	
 	var allWebhooks []WebhookInfo
@@ -83,6 +85,23 @@ func listTriggers(cfg *config.Config, providerFilter string, kubiyaOnly bool, re
 		}
 	}
 	
+	// List PagerDuty webhook subscriptions if requested or no provider filter
+	if providerFilter == "" || providerFilter == "pagerduty" {
+		fmt.Printf("\n🔍 Checking PagerDuty webhooks...\n")
+		pdProvider, err := NewPagerDutyProviderWithCredentials(cfg, pdToken)
+		if err != nil {
+			fmt.Printf("⚠️  Skipping PagerDuty: %v\n", err)
+		} else {
+			webhooks, err := pdProvider.ListWebhooks()
+			if err != nil {
+				fmt.Printf("❌ Failed to list PagerDuty webhooks: %v\n", err)
+			} else {
+				allWebhooks = append(allWebhooks, webhooks...)
+				fmt.Printf("✅ Found %d PagerDuty webhooks\n", len(webhooks))
+			}
+		}
+	}
+	
 	// List GitHub webhooks if requested or no provider filter
 	if providerFilter == "" || providerFilter == "github" {
 		if repository == "" && providerFilter == "github" {
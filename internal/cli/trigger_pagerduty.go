@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
+)
+
+// defaultPagerDutyEvents are the incident events a PagerDuty trigger
+// subscribes to by default
+var defaultPagerDutyEvents = []string{"incident.triggered", "incident.escalated"}
+
+// PagerDutyProvider manages PagerDuty webhook subscriptions
+type PagerDutyProvider struct {
+	cfg        *config.Config
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPagerDutyProviderWithCredentials creates a new PagerDuty provider
+// instance, falling back to the PAGERDUTY_TOKEN environment variable
+func NewPagerDutyProviderWithCredentials(cfg *config.Config, token string) (*PagerDutyProvider, error) {
+	if token == "" {
+		token = os.Getenv("PAGERDUTY_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("PAGERDUTY_TOKEN environment variable or --pd-token flag is required")
+	}
+
+	return &PagerDutyProvider{
+		cfg:        cfg,
+		token:      token,
+		baseURL:    "https://api.pagerduty.com",
+		httpClient: httpclient.Default(),
+	}, nil
+}
+
+// CreateWebhookSubscription subscribes url to incident events of a service,
+// or of the whole account when serviceID is empty, and returns the
+// subscription ID
+func (p *PagerDutyProvider) CreateWebhookSubscription(description, url, serviceID string, events []string) (string, error) {
+	if len(events) == 0 {
+		events = defaultPagerDutyEvents
+	}
+	filter := map[string]interface{}{"type": "account_reference"}
+	if serviceID != "" {
+		filter = map[string]interface{}{"type": "service_reference", "id": serviceID}
+	}
+
+	payload := map[string]interface{}{
+		"webhook_subscription": map[string]interface{}{
+			"type":        "webhook_subscription",
+			"description": description,
+			"events":      events,
+			"filter":      filter,
+			"delivery_method": map[string]interface{}{
+				"type": "http_delivery_method",
+				"url":  url,
+			},
+		},
+	}
+
+	fmt.Printf("🔗 Creating PagerDuty webhook subscription\n")
+	fmt.Printf("📍 Webhook URL: %s\n", url)
+	fmt.Printf("🎯 Events: %s\n", strings.Join(events, ", "))
+
+	var result struct {
+		WebhookSubscription struct {
+			ID string `json:"id"`
+		} `json:"webhook_subscription"`
+	}
+	if err := p.makePagerDutyRequest("POST", p.baseURL+"/webhook_subscriptions", payload, &result); err != nil {
+		return "", err
+	}
+	return result.WebhookSubscription.ID, nil
+}
+
+// DeleteWebhookSubscription removes a webhook subscription by ID
+func (p *PagerDutyProvider) DeleteWebhookSubscription(id string) error {
+	fmt.Printf("🗑️  Deleting webhook subscription: %s\n", id)
+	return p.makePagerDutyRequest("DELETE", fmt.Sprintf("%s/webhook_subscriptions/%s", p.baseURL, id), nil, nil)
+}
+
+// ListWebhooks lists the webhook subscriptions and identifies Kubiya ones
+func (p *PagerDutyProvider) ListWebhooks() ([]WebhookInfo, error) {
+	var response struct {
+		WebhookSubscriptions []struct {
+			ID             string   `json:"id"`
+			Description    string   `json:"description"`
+			Events         []string `json:"events"`
+			DeliveryMethod struct {
+				URL string `json:"url"`
+			} `json:"delivery_method"`
+		} `json:"webhook_subscriptions"`
+	}
+	if err := p.makePagerDutyRequest("GET", p.baseURL+"/webhook_subscriptions", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var webhooks []WebhookInfo
+	for _, sub := range response.WebhookSubscriptions {
+		webhooks = append(webhooks, WebhookInfo{
+			ID:       sub.ID,
+			Name:     sub.Description,
+			URL:      sub.DeliveryMethod.URL,
+			Provider: "pagerduty",
+			IsKubiya: strings.Contains(sub.DeliveryMethod.URL, "kubiya"),
+			Events:   sub.Events,
+		})
+	}
+	return webhooks, nil
+}
+
+func (p *PagerDutyProvider) makePagerDutyRequest(method, url string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	req.Header.Set("Authorization", "Token token="+p.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("PagerDuty API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+
+	return nil
+}
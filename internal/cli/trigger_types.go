@@ -8,8 +8,9 @@ import (
 type TriggerProvider string
 
 const (
-	ProviderDatadog TriggerProvider = "datadog"
-	ProviderGitHub  TriggerProvider = "github"
+	ProviderDatadog   TriggerProvider = "datadog"
+	ProviderGitHub    TriggerProvider = "github"
+	ProviderPagerDuty TriggerProvider = "pagerduty"
 )
 
 // Trigger represents a workflow trigger configuration
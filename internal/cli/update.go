@@ -9,24 +9,34 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
 	"github.com/kubiyabot/cli/internal/version"
 	"github.com/spf13/cobra"
 )
 
 const (
-	githubAPIURL = "https://api.github.com/repos/kubiyabot/cli/releases/latest"
-	owner        = "kubiyabot"
-	repo         = "cli"
+	githubReleasesAPIURL = "https://api.github.com/repos/kubiyabot/cli/releases?per_page=50"
+	owner                = "kubiyabot"
+	repo                 = "cli"
+
+	updateChannelStable = "stable"
+	updateChannelBeta   = "beta"
 )
 
 type githubRelease struct {
-	TagName string  `json:"tag_name"`
-	Assets  []asset `json:"assets"`
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Body       string  `json:"body"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []asset `json:"assets"`
 }
 
 type asset struct {
@@ -35,28 +45,57 @@ type asset struct {
 }
 
 func newUpdateCommand(cfg *config.Config) *cobra.Command {
-	var force bool
+	var (
+		force     bool
+		channel   string
+		checkOnly bool
+		insecure  bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "🔄 Update Kubiya CLI to the latest version",
 		Long: `Check for and install the latest version of Kubiya CLI.
-The command will only update if a newer version is available, unless --force is used.`,
+The command will only update if a newer version is available, unless --force is used.
+
+Releases are published on two channels:
+  • stable - regular releases (default)
+  • beta   - includes pre-releases
+
+The downloaded binary is verified against the SHA-256 checksums published
+with the release (checksums.txt) and the current executable is replaced
+atomically. Releases are not signed: the checksums catch corrupted or
+truncated downloads, not a tampered release.`,
 		Example: `  # Check for updates
   kubiya update
 
+  # Only check whether an update is available
+  kubiya update --check-only
+
+  # Update to the latest beta release
+  kubiya update --channel beta
+
   # Force update to latest version
   kubiya update --force`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if channel != updateChannelStable && channel != updateChannelBeta {
+				return fmt.Errorf("invalid channel %q (must be %s or %s)", channel, updateChannelStable, updateChannelBeta)
+			}
+
 			// Get current version
 			currentVersion := version.Version
 
-			// Get latest release info from GitHub
-			release, err := getLatestRelease()
+			// Get release info from GitHub
+			releases, err := getReleases()
 			if err != nil {
 				return fmt.Errorf("failed to check for updates: %w", err)
 			}
 
+			release := selectRelease(releases, channel)
+			if release == nil {
+				return fmt.Errorf("no releases found on the %s channel", channel)
+			}
+
 			latestVersion := release.TagName
 
 			// Compare versions
@@ -66,7 +105,21 @@ The command will only update if a newer version is available, unless --force is
 			}
 
 			if !force && !needsUpdate {
-				fmt.Printf("✨ You're already running the latest version (%s)\n", currentVersion)
+				fmt.Printf("✨ You're already running the latest %s version (%s)\n", channel, currentVersion)
+				return nil
+			}
+
+			if needsUpdate {
+				printChangelog(changelogSince(releases, currentVersion, latestVersion, channel))
+			}
+
+			if checkOnly {
+				if needsUpdate {
+					fmt.Printf("📢 Update available: %s → %s (%s channel)\n", currentVersion, latestVersion, channel)
+					fmt.Printf("Run 'kubiya update --channel %s' to install it\n", channel)
+				} else {
+					fmt.Printf("✨ You're already running the latest %s version (%s)\n", channel, currentVersion)
+				}
 				return nil
 			}
 
@@ -94,20 +147,37 @@ The command will only update if a newer version is available, unless --force is
 			fmt.Printf("📥 Downloading checksums...\n")
 			checksums, err := downloadChecksums(latestVersion)
 			if err != nil {
+				if !insecure {
+					return fmt.Errorf("could not download checksums: %w (use --insecure to skip verification)", err)
+				}
 				fmt.Printf("⚠️  Warning: Could not verify checksums: %v\n", err)
 				fmt.Printf("    Continuing without checksum verification...\n")
 			}
 
+			// Get the path to the current executable
+			execPath, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to get executable path: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+				execPath = resolved
+			}
+
 			// Download the new binary
 			fmt.Printf("📥 Downloading new version...\n")
-			resp, err := http.Get(assetURL)
+			resp, err := httpclient.Get(assetURL)
 			if err != nil {
 				return fmt.Errorf("failed to download update: %w", err)
 			}
 			defer resp.Body.Close()
 
-			// Create temporary file
-			tmpFile, err := os.CreateTemp("", "kubiya-update-*")
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("failed to download update: status %d", resp.StatusCode)
+			}
+
+			// Create the temporary file next to the executable so the final
+			// rename stays on the same filesystem and is atomic
+			tmpFile, err := os.CreateTemp(filepath.Dir(execPath), ".kubiya-update-*")
 			if err != nil {
 				return fmt.Errorf("failed to create temporary file: %w", err)
 			}
@@ -124,9 +194,12 @@ The command will only update if a newer version is available, unless --force is
 			hasher := sha256.New()
 			multiWriter := io.MultiWriter(tmpFile, hasher)
 			if _, err := io.Copy(multiWriter, resp.Body); err != nil {
+				tmpFile.Close()
+				return fmt.Errorf("failed to save update: %w", err)
+			}
+			if err := tmpFile.Close(); err != nil {
 				return fmt.Errorf("failed to save update: %w", err)
 			}
-			tmpFile.Close()
 
 			// Verify checksum if available
 			if checksums != nil {
@@ -134,49 +207,66 @@ The command will only update if a newer version is available, unless --force is
 				downloadedChecksum := hex.EncodeToString(hasher.Sum(nil))
 				expectedChecksum, found := checksums[binaryName]
 
-				if found {
-					if downloadedChecksum != expectedChecksum {
-						return fmt.Errorf("checksum verification failed!\nExpected: %s\nGot: %s", expectedChecksum, downloadedChecksum)
-					}
+				switch {
+				case found && downloadedChecksum != expectedChecksum:
+					return fmt.Errorf("checksum verification failed!\nExpected: %s\nGot: %s", expectedChecksum, downloadedChecksum)
+				case found:
 					fmt.Printf("✓ Checksum verified\n")
-				} else {
+				case !insecure:
+					return fmt.Errorf("no checksum found for %s (use --insecure to skip verification)", binaryName)
+				default:
 					fmt.Printf("⚠️  Warning: No checksum found for %s\n", binaryName)
 				}
 			}
 
-			// Get the path to the current executable
-			execPath, err := os.Executable()
-			if err != nil {
-				return fmt.Errorf("failed to get executable path: %w", err)
-			}
-
-			// Rename the current executable (as backup)
-			backupPath := execPath + ".bak"
-			if err := os.Rename(execPath, backupPath); err != nil {
-				return fmt.Errorf("failed to create backup: %w", err)
+			if err := replaceExecutable(execPath, tmpFile.Name()); err != nil {
+				return err
 			}
 
-			// Move the new executable into place
-			if err := os.Rename(tmpFile.Name(), execPath); err != nil {
-				// Try to restore backup if update fails
-				os.Rename(backupPath, execPath)
-				return fmt.Errorf("failed to install update: %w", err)
-			}
-
-			// Remove backup
-			os.Remove(backupPath)
-
 			fmt.Printf("✅ Successfully updated to version %s!\n", latestVersion)
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force update even if already on latest version")
+	cmd.Flags().StringVar(&channel, "channel", updateChannelStable, "Release channel to update from (stable|beta)")
+	cmd.Flags().BoolVar(&checkOnly, "check-only", false, "Only check for updates and print the changelog, do not install")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Install even if the checksum cannot be verified")
 	return cmd
 }
 
-func getLatestRelease() (*githubRelease, error) {
-	resp, err := http.Get(githubAPIURL)
+// replaceExecutable swaps the binary at execPath for newPath.
+// On Unix a single rename is atomic; Windows cannot overwrite a running
+// executable, so the old binary is moved aside first.
+func replaceExecutable(execPath, newPath string) error {
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(newPath, execPath); err != nil {
+			return fmt.Errorf("failed to install update: %w", err)
+		}
+		return nil
+	}
+
+	// Rename the current executable (as backup)
+	backupPath := execPath + ".bak"
+	if err := os.Rename(execPath, backupPath); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	// Move the new executable into place
+	if err := os.Rename(newPath, execPath); err != nil {
+		// Try to restore backup if update fails
+		os.Rename(backupPath, execPath)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+
+	// Remove backup (may fail while the old binary is still running)
+	os.Remove(backupPath)
+	return nil
+}
+
+// getReleases returns the most recent releases published on GitHub
+func getReleases() ([]githubRelease, error) {
+	resp, err := httpclient.Get(githubReleasesAPIURL)
 	if err != nil {
 		return nil, err
 	}
@@ -186,12 +276,111 @@ func getLatestRelease() (*githubRelease, error) {
 		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
-	var release githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
 		return nil, err
 	}
 
-	return &release, nil
+	return releases, nil
+}
+
+// releaseInChannel reports whether a release is published on the given channel.
+// The beta channel includes every non-draft release, stable excludes pre-releases.
+func releaseInChannel(r githubRelease, channel string) bool {
+	if r.Draft {
+		return false
+	}
+	if channel == updateChannelBeta {
+		return true
+	}
+	return !r.Prerelease
+}
+
+// selectRelease picks the highest semantic version available on the channel
+func selectRelease(releases []githubRelease, channel string) *githubRelease {
+	var (
+		best        *githubRelease
+		bestVersion *semver.Version
+	)
+	for i := range releases {
+		r := &releases[i]
+		if !releaseInChannel(*r, channel) {
+			continue
+		}
+		v, err := semver.NewVersion(r.TagName)
+		if err != nil {
+			continue
+		}
+		if bestVersion == nil || v.GreaterThan(bestVersion) {
+			best, bestVersion = r, v
+		}
+	}
+	return best
+}
+
+// changelogSince returns the channel releases newer than currentVersion and
+// up to (and including) targetVersion, newest first
+func changelogSince(releases []githubRelease, currentVersion, targetVersion, channel string) []githubRelease {
+	target, err := semver.NewVersion(targetVersion)
+	if err != nil {
+		return nil
+	}
+	// A dev build has no meaningful baseline, only show the target release
+	current, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		current = nil
+	}
+
+	type entry struct {
+		release githubRelease
+		version *semver.Version
+	}
+	var entries []entry
+	for _, r := range releases {
+		if !releaseInChannel(r, channel) {
+			continue
+		}
+		v, err := semver.NewVersion(r.TagName)
+		if err != nil || v.GreaterThan(target) {
+			continue
+		}
+		if current == nil {
+			if !v.Equal(target) {
+				continue
+			}
+		} else if !v.GreaterThan(current) {
+			continue
+		}
+		entries = append(entries, entry{release: r, version: v})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].version.GreaterThan(entries[j].version)
+	})
+
+	result := make([]githubRelease, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.release)
+	}
+	return result
+}
+
+// printChangelog prints the release notes for the given releases
+func printChangelog(releases []githubRelease) {
+	if len(releases) == 0 {
+		return
+	}
+
+	fmt.Printf("\n📝 Changes since your version:\n")
+	for _, r := range releases {
+		fmt.Printf("\n── %s ──\n", r.TagName)
+		notes := strings.TrimSpace(r.Body)
+		if notes == "" {
+			notes = "(no release notes)"
+		}
+		fmt.Println(notes)
+	}
+	fmt.Println()
 }
 
 // isUpdateAvailable compares two semantic version strings
@@ -222,7 +411,7 @@ func isUpdateAvailable(currentVersion, latestVersion string) (bool, error) {
 func downloadChecksums(version string) (map[string]string, error) {
 	checksumURL := fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/checksums.txt", owner, repo, version)
 
-	resp, err := http.Get(checksumURL)
+	resp, err := httpclient.Get(checksumURL)
 	if err != nil {
 		return nil, err
 	}
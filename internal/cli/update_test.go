@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestSelectRelease(t *testing.T) {
+	releases := []githubRelease{
+		{TagName: "v2.6.0"},
+		{TagName: "v2.7.0-beta.1", Prerelease: true},
+		{TagName: "v2.8.0", Draft: true},
+		{TagName: "v2.6.1"},
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		want    string
+	}{
+		{name: "stable skips pre-releases and drafts", channel: updateChannelStable, want: "v2.6.1"},
+		{name: "beta includes pre-releases", channel: updateChannelBeta, want: "v2.7.0-beta.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectRelease(releases, tt.channel)
+			if got == nil {
+				t.Fatalf("selectRelease() returned nil, want %s", tt.want)
+			}
+			if got.TagName != tt.want {
+				t.Errorf("selectRelease() = %s, want %s", got.TagName, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangelogSince(t *testing.T) {
+	releases := []githubRelease{
+		{TagName: "v2.5.0"},
+		{TagName: "v2.6.1"},
+		{TagName: "v2.6.0"},
+		{TagName: "v2.7.0"},
+		{TagName: "v2.8.0"},
+	}
+
+	got := changelogSince(releases, "v2.6.0", "v2.7.0", updateChannelStable)
+	want := []string{"v2.7.0", "v2.6.1"}
+	if len(got) != len(want) {
+		t.Fatalf("changelogSince() returned %d releases, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].TagName != want[i] {
+			t.Errorf("changelogSince()[%d] = %s, want %s", i, got[i].TagName, want[i])
+		}
+	}
+
+	dev := changelogSince(releases, "dev", "v2.7.0", updateChannelStable)
+	if len(dev) != 1 || dev[0].TagName != "v2.7.0" {
+		t.Errorf("changelogSince() from dev = %v, want only v2.7.0", dev)
+	}
+}
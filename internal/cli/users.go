@@ -1,24 +1,29 @@
 package cli
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kubiyabot/cli/internal/config"
 	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
 )
 
-// printJSON prints data as formatted JSON
+// printJSON prints data as formatted JSON, with stable key order unless
+// --sort-keys=false
 func printJSON(data interface{}) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	return output.EncodeJSON(os.Stdout, data)
+}
+
+// printJSONList prints a list of resources as formatted JSON, sorted by name
+// unless --sort-keys=false
+func printJSONList(data interface{}) error {
+	return output.EncodeJSONList(os.Stdout, data)
 }
 
 func newUsersCommand(cfg *config.Config) *cobra.Command {
@@ -26,11 +31,12 @@ func newUsersCommand(cfg *config.Config) *cobra.Command {
 		Use:     "user",
 		Aliases: []string{"users"},
 		Short:   "👥 Manage users and groups",
-		Long:    "List users and groups for access control management",
+		Long:    "List and look up the users of the organization, for access control management",
 	}
 
 	cmd.AddCommand(
 		newListUsersCommand(cfg),
+		newGetUserCommand(cfg),
 		newListGroupsCommand(cfg),
 	)
 
@@ -38,21 +44,46 @@ func newUsersCommand(cfg *config.Config) *cobra.Command {
 }
 
 func newListUsersCommand(cfg *config.Config) *cobra.Command {
-	var outputFormat string
+	var (
+		outputFormat string
+		search       string
+		group        string
+		status       string
+	)
 
 	cmd := &cobra.Command{
-		Use:     "list",
-		Short:   "📋 List all users",
-		Long:    "Display a list of all users in the organization",
-		Example: "  kubiya user list\n  kubiya user list --output json",
+		Use:   "list",
+		Short: "📋 List all users",
+		Long:  "Display a list of all users in the organization",
+		Example: `  kubiya user list
+  kubiya user list --output json
+
+  # Users whose email or name contains "jane"
+  kubiya user list --search jane
+
+  # Active members of a group
+  kubiya user list --group "DevOps" --status active`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := kubiya.NewClient(cfg)
 
-			users, err := client.ListUsers(context.Background())
+			users, err := client.ListUsers(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("failed to list users: %w", err)
 			}
 
+			if group != "" {
+				groups, err := client.ListGroups(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("failed to list groups: %w", err)
+				}
+				g, err := findGroup(groups, group)
+				if err != nil {
+					return err
+				}
+				users = groupMembers(users, *g)
+			}
+			users = filterUsers(users, search, status)
+
 			if outputFormat == "json" {
 				return printJSON(users)
 			}
@@ -62,31 +93,62 @@ func newListUsersCommand(cfg *config.Config) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.Flags().StringVarP(&search, "search", "s", "", "Only users whose email or name contains this text")
+	cmd.Flags().StringVarP(&group, "group", "g", "", "Only members of this group (name or UUID)")
+	cmd.Flags().StringVar(&status, "status", "", "Only users with this status, e.g. active or invited")
 
 	return cmd
 }
 
-func newListGroupsCommand(cfg *config.Config) *cobra.Command {
+func newGetUserCommand(cfg *config.Config) *cobra.Command {
 	var outputFormat string
 
 	cmd := &cobra.Command{
-		Use:     "groups",
-		Short:   "📋 List all groups",
-		Long:    "Display a list of all groups in the organization",
-		Example: "  kubiya user groups\n  kubiya user groups --output json",
+		Use:   "get [email|uuid|name]",
+		Short: "👤 Show a user and their groups",
+		Long: `Show a user of the organization, found by email, UUID or name, with the
+groups they belong to. Use it to verify an identity before granting access.`,
+		Example: `  kubiya user get jane@example.com
+  kubiya user get 3f0c2a4e-8b1d-4c55-9e3a-2d7f6b1c9a10 --output json`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := kubiya.NewClient(cfg)
 
-			groups, err := client.ListGroups(context.Background())
+			users, err := client.ListUsers(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list users: %w", err)
+			}
+			user, err := findUser(users, args[0])
+			if err != nil {
+				return err
+			}
+			groups, err := client.ListGroups(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("failed to list groups: %w", err)
 			}
+			memberOf := userGroups(*user, groups)
 
 			if outputFormat == "json" {
-				return printJSON(groups)
+				return printJSON(struct {
+					kubiya.User
+					GroupDetails []kubiya.Group `json:"group_details"`
+				}{*user, memberOf})
 			}
 
-			return printGroupsTable(groups)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Email:\t%s\n", user.Email)
+			fmt.Fprintf(w, "Name:\t%s\n", valueOrDash(user.Name))
+			fmt.Fprintf(w, "UUID:\t%s\n", user.UUID)
+			fmt.Fprintf(w, "Status:\t%s\n", valueOrDash(user.Status))
+			names := make([]string, 0, len(memberOf))
+			for _, g := range memberOf {
+				names = append(names, g.Name)
+			}
+			if len(names) == 0 {
+				names = append(names, "none")
+			}
+			fmt.Fprintf(w, "Groups:\t%s\n", strings.Join(names, ", "))
+			return w.Flush()
 		},
 	}
 
@@ -95,6 +157,123 @@ func newListGroupsCommand(cfg *config.Config) *cobra.Command {
 	return cmd
 }
 
+func newListGroupsCommand(cfg *config.Config) *cobra.Command {
+	cmd := newGroupListCommand(cfg)
+	cmd.Use = "groups"
+	cmd.Example = "  kubiya user groups\n  kubiya user groups --output json"
+	cmd.Deprecated = "use 'kubiya group list' instead"
+	return cmd
+}
+
+// findUser returns the user whose UUID, ID or email is ref, or whose name
+// is ref when no other user has it. Matching ignores case.
+func findUser(users []kubiya.User, ref string) (*kubiya.User, error) {
+	var byName []int
+	for i, u := range users {
+		if u.UUID == ref || u.ID == ref || strings.EqualFold(u.Email, ref) {
+			return &users[i], nil
+		}
+		if u.Name != "" && strings.EqualFold(u.Name, ref) {
+			byName = append(byName, i)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("user %q not found (see 'kubiya user list --search')", ref)
+	case 1:
+		return &users[byName[0]], nil
+	default:
+		emails := make([]string, 0, len(byName))
+		for _, i := range byName {
+			emails = append(emails, users[i].Email)
+		}
+		return nil, fmt.Errorf("%d users are named %q, use their email instead: %s", len(byName), ref, strings.Join(emails, ", "))
+	}
+}
+
+// findGroup returns the group whose UUID or name is ref, ignoring the case
+// of names
+func findGroup(groups []kubiya.Group, ref string) (*kubiya.Group, error) {
+	var byName []int
+	for i, g := range groups {
+		if g.UUID == ref {
+			return &groups[i], nil
+		}
+		if strings.EqualFold(g.Name, ref) {
+			byName = append(byName, i)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("group %q not found (see 'kubiya group list')", ref)
+	case 1:
+		return &groups[byName[0]], nil
+	default:
+		uuids := make([]string, 0, len(byName))
+		for _, i := range byName {
+			uuids = append(uuids, groups[i].UUID)
+		}
+		return nil, fmt.Errorf("%d groups are named %q, use their UUID instead: %s", len(byName), ref, strings.Join(uuids, ", "))
+	}
+}
+
+// inGroup reports whether user belongs to group. Users list their groups
+// by UUID, or by name on older organizations.
+func inGroup(user kubiya.User, group kubiya.Group) bool {
+	for _, g := range user.Groups {
+		if g == group.UUID || (group.Name != "" && g == group.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupMembers returns the users that belong to group
+func groupMembers(users []kubiya.User, group kubiya.Group) []kubiya.User {
+	var members []kubiya.User
+	for _, u := range users {
+		if inGroup(u, group) {
+			members = append(members, u)
+		}
+	}
+	return members
+}
+
+// userGroups returns the groups user belongs to
+func userGroups(user kubiya.User, groups []kubiya.Group) []kubiya.Group {
+	var memberOf []kubiya.Group
+	for _, g := range groups {
+		if inGroup(user, g) {
+			memberOf = append(memberOf, g)
+		}
+	}
+	return memberOf
+}
+
+// filterUsers keeps the users whose email or name contains search and
+// whose status is status, ignoring case; empty filters match everything
+func filterUsers(users []kubiya.User, search, status string) []kubiya.User {
+	search = strings.ToLower(search)
+	var filtered []kubiya.User
+	for _, u := range users {
+		if search != "" && !strings.Contains(strings.ToLower(u.Email), search) && !strings.Contains(strings.ToLower(u.Name), search) {
+			continue
+		}
+		if status != "" && !strings.EqualFold(u.Status, status) {
+			continue
+		}
+		filtered = append(filtered, u)
+	}
+	return filtered
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func printUsersTable(users []kubiya.User) error {
 	if len(users) == 0 {
 		fmt.Println("No users found.")
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+var (
+	testGroups = []kubiya.Group{
+		{UUID: "g-admin", Name: "Admin", System: true},
+		{UUID: "g-devops", Name: "DevOps", Description: "Platform operations"},
+		{UUID: "g-ops-1", Name: "Ops"},
+		{UUID: "g-ops-2", Name: "ops"},
+	}
+	testUsers = []kubiya.User{
+		{UUID: "u-jane", Email: "jane@example.com", Name: "Jane Doe", Status: "active", Groups: []string{"g-admin", "g-devops"}},
+		{UUID: "u-john", Email: "john@example.com", Name: "John Smith", Status: "invited", Groups: []string{"DevOps"}},
+		{UUID: "u-jd", ID: "mongo-1", Email: "jd@example.com", Name: "John Smith", Status: "active"},
+	}
+)
+
+func TestFindUser(t *testing.T) {
+	for ref, want := range map[string]string{
+		"u-jane":           "u-jane",
+		"mongo-1":          "u-jd",
+		"JANE@example.com": "u-jane",
+		"jane doe":         "u-jane",
+	} {
+		user, err := findUser(testUsers, ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, user.UUID, ref)
+	}
+
+	_, err := findUser(testUsers, "John Smith")
+	assert.ErrorContains(t, err, "john@example.com, jd@example.com")
+	_, err = findUser(testUsers, "nobody")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestFindGroup(t *testing.T) {
+	group, err := findGroup(testGroups, "devops")
+	require.NoError(t, err)
+	assert.Equal(t, "g-devops", group.UUID)
+
+	group, err = findGroup(testGroups, "g-ops-2")
+	require.NoError(t, err)
+	assert.Equal(t, "ops", group.Name)
+
+	_, err = findGroup(testGroups, "OPS")
+	assert.ErrorContains(t, err, "g-ops-1, g-ops-2")
+}
+
+func TestGroupMembership(t *testing.T) {
+	members := groupMembers(testUsers, testGroups[1])
+	require.Len(t, members, 2, "users list groups by UUID or by name")
+	assert.Equal(t, "u-jane", members[0].UUID)
+	assert.Equal(t, "u-john", members[1].UUID)
+
+	memberOf := userGroups(testUsers[0], testGroups)
+	require.Len(t, memberOf, 2)
+	assert.Equal(t, "Admin", memberOf[0].Name)
+}
+
+func TestFilterUsersAndGroups(t *testing.T) {
+	users := filterUsers(testUsers, "JOHN", "")
+	assert.Len(t, users, 2)
+	users = filterUsers(testUsers, "john", "active")
+	require.Len(t, users, 1)
+	assert.Equal(t, "u-jd", users[0].UUID)
+	assert.Len(t, filterUsers(testUsers, "", ""), 3)
+
+	groups := filterGroups(testGroups, "ops", "custom")
+	assert.Len(t, groups, 3, "matches names and descriptions")
+	assert.Len(t, filterGroups(testGroups, "", "system"), 1)
+}
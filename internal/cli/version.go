@@ -2,19 +2,62 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/controlplane"
+	"github.com/kubiyabot/cli/internal/output"
 	"github.com/kubiyabot/cli/internal/version"
 	"github.com/spf13/cobra"
 )
 
+// versionSkewTimeout bounds the startup version policy lookup so it never
+// noticeably delays a command
+const versionSkewTimeout = 3 * time.Second
+
 func newVersionCommand(cfg *config.Config) *cobra.Command {
-	return &cobra.Command{
+	var (
+		check        bool
+		verbose      bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "📋 Show CLI version information",
-		Run: func(cmd *cobra.Command, args []string) {
+		Example: `  # Show version information
+  kubiya version
+
+  # Check the CLI version against the versions supported by the platform
+  kubiya version --check
+
+  # Show the build provenance: Go version, VCS state and dependencies
+  kubiya version --verbose
+
+  # The same as JSON, listing every module compiled into the binary
+  kubiya version --verbose --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch outputFormat {
+			case "text":
+			case "json":
+				return output.EncodeJSON(cmd.OutOrStdout(), version.GetBuildInfo(verbose))
+			default:
+				return fmt.Errorf("invalid output format %q (valid: text, json)", outputFormat)
+			}
+
 			fmt.Printf("Kubiya CLI %s\n", version.GetVersion())
+			if verbose {
+				printBuildInfo(cmd.OutOrStdout(), version.GetBuildInfo(true))
+			}
+
+			if check {
+				strict, _ := cmd.Flags().GetBool("strict-version")
+				return runVersionSkewCheck(cmd.OutOrStdout(), cfg, strict, true)
+			}
 
 			// Check for updates (skip in automation mode)
 			if os.Getenv("KUBIYA_AUTOMATION") == "" {
@@ -23,6 +66,118 @@ func newVersionCommand(cfg *config.Config) *cobra.Command {
 					fmt.Println("Run 'kubiya update' to update to the latest version")
 				}
 			}
+			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Check the CLI version against the platform's supported versions")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show the build provenance: Go version, platform, VCS revision and dirty flag, build settings and key dependencies")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+// printBuildInfo writes the provenance shown by version --verbose
+func printBuildInfo(w io.Writer, info version.BuildInfo) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Go version:  %s\n", info.GoVersion)
+	fmt.Fprintf(w, "Platform:    %s\n", info.Platform)
+	if info.Module != nil {
+		fmt.Fprintf(w, "Module:      %s\n", info.Module)
+	}
+	if info.VCS != nil {
+		state := "clean"
+		if info.Dirty() {
+			state = "dirty (built with uncommitted changes)"
+		}
+		if info.VCS.Revision != "" {
+			fmt.Fprintf(w, "Revision:    %s\n", strings.TrimSpace(info.VCS.Revision+" "+info.VCS.Time))
+		}
+		fmt.Fprintf(w, "Tree:        %s\n", state)
+	} else {
+		fmt.Fprintln(w, "Tree:        unknown (built without VCS information)")
+	}
+	if len(info.Settings) > 0 {
+		var settings []string
+		for k, v := range info.Settings {
+			// The full list is in the JSON output
+			if v == "" || k == "DefaultGODEBUG" {
+				continue
+			}
+			settings = append(settings, k+"="+v)
+		}
+		sort.Strings(settings)
+		fmt.Fprintf(w, "Settings:    %s\n", strings.Join(settings, " "))
+	}
+
+	if deps := info.KeyDependencies(); len(deps) > 0 {
+		fmt.Fprintln(w, "\nKey dependencies:")
+		for _, dep := range deps {
+			fmt.Fprintf(w, "  %s\n", dep)
+		}
+	}
+	fmt.Fprintf(w, "\n%d modules compiled in, use --output json to list them all with their checksums\n", len(info.Dependencies))
+}
+
+// runVersionSkewCheck looks up the CLI versions supported by the platform
+// and writes a warning when the installed CLI is too old. The lookup is
+// cached for a day, like the update check, except when verbose, i.e. for
+// kubiya version --check. With strict set, an unsupported CLI, or a lookup
+// that failed, is returned as an error. Otherwise lookup failures are only
+// reported when verbose, so the check never blocks normal usage.
+func runVersionSkewCheck(w io.Writer, cfg *config.Config, strict, verbose bool) error {
+	if cfg.APIKey == "" {
+		if verbose {
+			fmt.Fprintln(w, "⚠️  Not authenticated, skipping platform version check (run 'kubiya login')")
+		}
+		return nil
+	}
+
+	client, err := controlplane.New(cfg.APIKey, cfg.Debug)
+	if err != nil {
+		return checkFailure(strict, verbose, err)
+	}
+	if !verbose {
+		client.HTTPClient.Timeout = versionSkewTimeout
+	}
+
+	policy, err := version.CheckPlatformPolicy(client.BaseURL, verbose, func() (string, string, error) {
+		p, err := client.GetCLIVersionPolicy()
+		if err != nil {
+			return "", "", err
+		}
+		return p.MinimumVersion, p.RecommendedVersion, nil
+	})
+	if err != nil {
+		return checkFailure(strict, verbose, err)
+	}
+
+	status, err := version.CheckCompatibility(version.Version, policy.Minimum, policy.Recommended)
+	if err != nil {
+		return checkFailure(strict, verbose, err)
+	}
+
+	if msg := version.GetCompatibilityMessage(status, policy.Minimum, policy.Recommended); msg != "" {
+		fmt.Fprint(w, msg)
+	} else if verbose {
+		switch status {
+		case version.CompatibilityUnknown:
+			fmt.Fprintf(w, "ℹ️  Development build, platform supports %s and above\n", policy.Minimum)
+		default:
+			fmt.Fprintln(w, "✅ CLI version is supported by the platform")
+		}
+	}
+
+	if strict && status == version.CompatibilityUnsupported {
+		return fmt.Errorf("CLI version %s is no longer supported by the platform (minimum: %s)",
+			version.Version, policy.Minimum)
+	}
+
+	return nil
+}
+
+func checkFailure(strict, verbose bool, err error) error {
+	if !strict && !verbose {
+		return nil
+	}
+	return fmt.Errorf("failed to check platform version requirements: %w", err)
 }
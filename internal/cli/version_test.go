@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/version"
+)
+
+func TestRunVersionSkewCheckStrict(t *testing.T) {
+	defer func(old string) { version.Version = old }(version.Version)
+
+	policy := `{"minimum_version":"v1.0.0","recommended_version":"v1.2.0"}`
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(policy))
+	}))
+	defer server.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KUBIYA_CONTROL_PLANE_BASE_URL", server.URL)
+	cfg := &config.Config{APIKey: "key"}
+
+	// Outdated but supported CLIs only get a warning
+	version.Version = "v1.1.0"
+	assert.NoError(t, runVersionSkewCheck(io.Discard, cfg, true, false))
+
+	version.Version = "v0.9.0"
+	assert.Error(t, runVersionSkewCheck(io.Discard, cfg, true, false))
+
+	// The policy is looked up once a day
+	assert.Equal(t, 1, calls)
+
+	// Failed lookups fail strict checks only
+	t.Setenv("HOME", t.TempDir())
+	policy = `not json`
+	assert.Error(t, runVersionSkewCheck(io.Discard, cfg, true, false))
+	assert.NoError(t, runVersionSkewCheck(io.Discard, cfg, false, false))
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// webhookFollowInterval is how often --follow polls for new deliveries
+var webhookFollowInterval = 5 * time.Second
+
+func newWebhookCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "webhook",
+		Aliases: []string{"webhooks"},
+		Short:   "🪝 Manage webhooks",
+		Long:    "List webhooks, pause and resume them, inspect their recent deliveries and re-point them to other agents",
+	}
+
+	cmd.AddCommand(
+		newWebhookListCommand(cfg),
+		newWebhookPauseCommand(cfg),
+		newWebhookResumeCommand(cfg),
+		newWebhookDeliveriesCommand(cfg),
+		newWebhookReassignCommand(cfg),
+	)
+
+	return cmd
+}
+
+func newWebhookListCommand(cfg *config.Config) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "📋 List webhooks",
+		Example: "  kubiya webhook list\n  kubiya webhook list --output json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			webhooks, err := kubiya.NewClient(cfg).ListWebhooks(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list webhooks: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSONList(out, webhooks)
+			}
+
+			if len(webhooks) == 0 {
+				fmt.Fprintln(out, "No webhooks found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tSTATE\tSOURCE\tDESTINATION")
+			for _, wh := range webhooks {
+				state := style.SuccessStyle.Render("active")
+				if wh.Paused {
+					state = style.DimStyle.Render("paused")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", wh.ID, wh.Name, state, wh.Source, wh.Communication.Destination)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+func newWebhookPauseCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause [id]",
+		Short: "⏸️  Stop a webhook from starting sessions",
+		Long: `Pause a webhook. Incoming events are still accepted and recorded as
+deliveries, but no sessions are started until the webhook is resumed.`,
+		Example: "  kubiya webhook pause abc-123",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubiya.NewClient(cfg).PauseWebhook(cmd.Context(), args[0]); err != nil {
+				return fmt.Errorf("failed to pause webhook: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Webhook %s paused\n",
+				style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(args[0]))
+			return nil
+		},
+	}
+}
+
+func newWebhookResumeCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "resume [id]",
+		Short:   "▶️  Resume a paused webhook",
+		Example: "  kubiya webhook resume abc-123",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubiya.NewClient(cfg).ResumeWebhook(cmd.Context(), args[0]); err != nil {
+				return fmt.Errorf("failed to resume webhook: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Webhook %s resumed\n",
+				style.SuccessStyle.Render("✓"), style.HighlightStyle.Render(args[0]))
+			return nil
+		},
+	}
+}
+
+// webhookDeliveryLister is the part of kubiya.Client used to read deliveries
+type webhookDeliveryLister interface {
+	ListWebhookDeliveries(ctx context.Context, id string, since time.Time) ([]kubiya.WebhookDelivery, error)
+}
+
+func newWebhookDeliveriesCommand(cfg *config.Config) *cobra.Command {
+	var (
+		since        time.Duration
+		follow       bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deliveries [id]",
+		Short: "📬 Show recent deliveries of a webhook",
+		Long: `Show the recent invocations of a webhook with their payload size, the session
+they started and their status.
+
+With --follow new deliveries are printed as they arrive until interrupted.`,
+		Example: `  kubiya webhook deliveries abc-123
+  kubiya webhook deliveries abc-123 --since 1h
+  kubiya webhook deliveries abc-123 --follow
+  kubiya webhook deliveries abc-123 --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format %q (must be text or json)", outputFormat)
+			}
+			client := kubiya.NewClient(cfg)
+			start := time.Now().Add(-since)
+
+			if follow {
+				return followWebhookDeliveries(cmd.Context(), client, args[0], start, outputFormat, cmd.OutOrStdout())
+			}
+
+			deliveries, err := client.ListWebhookDeliveries(cmd.Context(), args[0], start)
+			if err != nil {
+				return fmt.Errorf("failed to list webhook deliveries: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSON(out, deliveries)
+			}
+			if len(deliveries) == 0 {
+				fmt.Fprintf(out, "No deliveries in the last %s\n", since)
+				return nil
+			}
+			return printWebhookDeliveries(out, deliveries, true)
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "Only show deliveries newer than this")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep polling and print new deliveries as they arrive")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	return cmd
+}
+
+// followWebhookDeliveries prints the deliveries since start oldest first and
+// then polls for new ones until ctx is cancelled. JSON output is written as
+// one delivery per line.
+func followWebhookDeliveries(ctx context.Context, client webhookDeliveryLister, id string, start time.Time, outputFormat string, out io.Writer) error {
+	seen := map[string]bool{}
+	header := outputFormat == "text"
+
+	ticker := time.NewTicker(webhookFollowInterval)
+	defer ticker.Stop()
+
+	for {
+		deliveries, err := client.ListWebhookDeliveries(ctx, id, start)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to list webhook deliveries: %w", err)
+		}
+
+		fresh := newWebhookDeliveries(deliveries, seen)
+		if len(fresh) > 0 {
+			if outputFormat == "json" {
+				for _, d := range fresh {
+					if err := output.EncodeJSONLine(out, d); err != nil {
+						return err
+					}
+				}
+			} else if err := printWebhookDeliveries(out, fresh, header); err != nil {
+				return err
+			}
+			header = false
+			// Only ask for what is newer than the last delivery seen
+			start = fresh[len(fresh)-1].Timestamp
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// newWebhookDeliveries returns the deliveries not in seen, oldest first, and
+// marks them as seen
+func newWebhookDeliveries(deliveries []kubiya.WebhookDelivery, seen map[string]bool) []kubiya.WebhookDelivery {
+	var fresh []kubiya.WebhookDelivery
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		d := deliveries[i]
+		if seen[d.ID] {
+			continue
+		}
+		seen[d.ID] = true
+		fresh = append(fresh, d)
+	}
+	return fresh
+}
+
+func printWebhookDeliveries(out io.Writer, deliveries []kubiya.WebhookDelivery, header bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(w, "TIME\tSTATUS\tPAYLOAD\tSESSION\tDETAILS")
+	}
+	for _, d := range deliveries {
+		session := d.SessionID
+		if session == "" {
+			session = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			d.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			webhookDeliveryStatus(d),
+			formatBytes(int64(d.PayloadSize)),
+			session,
+			d.Error)
+	}
+	return w.Flush()
+}
+
+func webhookDeliveryStatus(d kubiya.WebhookDelivery) string {
+	status := d.Status
+	if d.StatusCode != 0 {
+		status = fmt.Sprintf("%s (%d)", status, d.StatusCode)
+	}
+	switch d.Status {
+	case "success", "delivered":
+		return style.SuccessStyle.Render(status)
+	case "failed", "error":
+		return style.ErrorStyle.Render(status)
+	default:
+		return style.DimStyle.Render(status)
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/kubiyabot/cli/internal/output"
+	"github.com/kubiyabot/cli/internal/style"
+)
+
+// webhookUpdater is the part of kubiya.Client used to re-point webhooks
+type webhookUpdater interface {
+	ListWebhooks(ctx context.Context) ([]kubiya.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, webhook kubiya.Webhook) (*kubiya.Webhook, error)
+}
+
+func newWebhookReassignCommand(cfg *config.Config) *cobra.Command {
+	var (
+		fromAgent    string
+		toAgent      string
+		dryRun       bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reassign",
+		Short: "🔀 Re-point all webhooks of an agent to another agent",
+		Long: `Find every webhook that targets --from-agent and point it at --to-agent.
+Prompts, filters, destinations and pause state are kept as they are.
+
+The webhooks are updated all or nothing: if one update fails, the webhooks
+already re-pointed are moved back to the original agent.`,
+		Example: `  # Show which webhooks would move
+  kubiya webhook reassign --from-agent old-uuid --to-agent new-uuid --dry-run
+
+  # Re-point them
+  kubiya webhook reassign --from-agent old-uuid --to-agent new-uuid`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromAgent == toAgent {
+				return fmt.Errorf("--from-agent and --to-agent must be different agents")
+			}
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format %q (must be text or json)", outputFormat)
+			}
+
+			client := kubiya.NewClient(cfg)
+			if _, err := client.GetAgent(cmd.Context(), toAgent); err != nil {
+				return fmt.Errorf("failed to get target agent %s: %w", toAgent, err)
+			}
+
+			webhooks, err := reassignWebhooks(cmd.Context(), client, fromAgent, toAgent, dryRun)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				return output.EncodeJSONList(out, webhooks)
+			}
+			if len(webhooks) == 0 {
+				fmt.Fprintf(out, "No webhooks target agent %s\n", fromAgent)
+				return nil
+			}
+			if err := printReassignedWebhooks(out, webhooks); err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Fprintf(out, "\n%s %d webhook(s) would be re-pointed to %s (dry run)\n",
+					style.DimStyle.Render("•"), len(webhooks), style.HighlightStyle.Render(toAgent))
+				return nil
+			}
+			fmt.Fprintf(out, "\n%s Re-pointed %d webhook(s) to %s\n",
+				style.SuccessStyle.Render("✓"), len(webhooks), style.HighlightStyle.Render(toAgent))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromAgent, "from-agent", "", "UUID of the agent the webhooks target now")
+	cmd.Flags().StringVar(&toAgent, "to-agent", "", "UUID of the agent to point the webhooks at")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only show the webhooks that would be re-pointed")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.MarkFlagRequired("from-agent")
+	cmd.MarkFlagRequired("to-agent")
+	return cmd
+}
+
+// reassignWebhooks points the webhooks targeting from at to and returns them.
+// If an update fails the webhooks already updated are restored, so either all
+// of them move or none does. With dryRun nothing is updated.
+func reassignWebhooks(ctx context.Context, client webhookUpdater, from, to string, dryRun bool) ([]kubiya.Webhook, error) {
+	webhooks, err := client.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	matched := []kubiya.Webhook{}
+	for _, wh := range webhooks {
+		if wh.AgentID == from {
+			matched = append(matched, wh)
+		}
+	}
+	if dryRun {
+		return matched, nil
+	}
+
+	reassigned := make([]kubiya.Webhook, 0, len(matched))
+	for i, wh := range matched {
+		wh.AgentID = to
+		if _, err := client.UpdateWebhook(ctx, wh.ID, wh); err != nil {
+			err = fmt.Errorf("failed to re-point webhook %s: %w", matched[i].ID, err)
+			return nil, errors.Join(err, restoreWebhooks(ctx, client, matched[:i]))
+		}
+		reassigned = append(reassigned, wh)
+	}
+	return reassigned, nil
+}
+
+// restoreWebhooks puts back the original version of webhooks
+func restoreWebhooks(ctx context.Context, client webhookUpdater, webhooks []kubiya.Webhook) error {
+	var errs []error
+	for _, wh := range webhooks {
+		if _, err := client.UpdateWebhook(ctx, wh.ID, wh); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore webhook %s to agent %s: %w", wh.ID, wh.AgentID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func printReassignedWebhooks(out io.Writer, webhooks []kubiya.Webhook) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSOURCE\tDESTINATION")
+	for _, wh := range webhooks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", wh.ID, wh.Name, wh.Source, wh.Communication.Destination)
+	}
+	return w.Flush()
+}
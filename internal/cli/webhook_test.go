@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeDeliveryLister struct {
+	pages  [][]kubiya.WebhookDelivery
+	calls  int
+	since  []time.Time
+	cancel context.CancelFunc
+}
+
+func (f *fakeDeliveryLister) ListWebhookDeliveries(ctx context.Context, id string, since time.Time) ([]kubiya.WebhookDelivery, error) {
+	f.since = append(f.since, since)
+	page := f.pages[f.calls]
+	f.calls++
+	if f.calls == len(f.pages) {
+		f.cancel()
+	}
+	return page, nil
+}
+
+func TestFollowWebhookDeliveries(t *testing.T) {
+	old := webhookFollowInterval
+	webhookFollowInterval = time.Millisecond
+	defer func() { webhookFollowInterval = old }()
+
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	d1 := kubiya.WebhookDelivery{ID: "d1", Timestamp: t0, Status: "success", PayloadSize: 512, SessionID: "s-1"}
+	d2 := kubiya.WebhookDelivery{ID: "d2", Timestamp: t0.Add(time.Minute), Status: "failed", PayloadSize: 2048, Error: "agent not found"}
+	d3 := kubiya.WebhookDelivery{ID: "d3", Timestamp: t0.Add(2 * time.Minute), Status: "success", SessionID: "s-3"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lister := &fakeDeliveryLister{
+		// Listings are newest first and overlap with what was already printed
+		pages:  [][]kubiya.WebhookDelivery{{d2, d1}, {d2}, {d3, d2}},
+		cancel: cancel,
+	}
+
+	var out bytes.Buffer
+	if err := followWebhookDeliveries(ctx, lister, "wh-1", t0.Add(-time.Hour), "text", &out); err != nil {
+		t.Fatalf("followWebhookDeliveries() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and 3 deliveries, got:\n%s", out.String())
+	}
+	for i, want := range []string{"TIME", "s-1", "agent not found", "s-3"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[1], "512 B") || !strings.Contains(lines[2], "2.0 KB") {
+		t.Errorf("payload sizes not rendered:\n%s", out.String())
+	}
+	if !lister.since[1].Equal(d2.Timestamp) {
+		t.Errorf("second poll since = %v, want %v", lister.since[1], d2.Timestamp)
+	}
+}
+
+type fakeWebhookUpdater struct {
+	webhooks map[string]kubiya.Webhook
+	order    []string
+	failOn   string
+}
+
+func (f *fakeWebhookUpdater) ListWebhooks(ctx context.Context) ([]kubiya.Webhook, error) {
+	var list []kubiya.Webhook
+	for _, id := range f.order {
+		list = append(list, f.webhooks[id])
+	}
+	return list, nil
+}
+
+func (f *fakeWebhookUpdater) UpdateWebhook(ctx context.Context, id string, webhook kubiya.Webhook) (*kubiya.Webhook, error) {
+	if id == f.failOn {
+		return nil, errors.New("server error")
+	}
+	f.webhooks[id] = webhook
+	return &webhook, nil
+}
+
+func newFakeWebhookUpdater() *fakeWebhookUpdater {
+	f := &fakeWebhookUpdater{webhooks: map[string]kubiya.Webhook{}}
+	for _, wh := range []kubiya.Webhook{
+		{ID: "wh-1", AgentID: "old", Prompt: "Triage this alert", Filter: "severity == 'high'"},
+		{ID: "wh-2", AgentID: "other"},
+		{ID: "wh-3", AgentID: "old", Prompt: "Summarize the PR"},
+	} {
+		f.webhooks[wh.ID] = wh
+		f.order = append(f.order, wh.ID)
+	}
+	return f
+}
+
+func TestReassignWebhooks(t *testing.T) {
+	t.Run("dry run", func(t *testing.T) {
+		f := newFakeWebhookUpdater()
+		webhooks, err := reassignWebhooks(context.Background(), f, "old", "new", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(webhooks) != 2 || f.webhooks["wh-1"].AgentID != "old" {
+			t.Errorf("dry run matched %d webhooks and left wh-1 on %q", len(webhooks), f.webhooks["wh-1"].AgentID)
+		}
+	})
+
+	t.Run("reassign", func(t *testing.T) {
+		f := newFakeWebhookUpdater()
+		webhooks, err := reassignWebhooks(context.Background(), f, "old", "new", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(webhooks) != 2 {
+			t.Fatalf("reassigned %d webhooks, want 2", len(webhooks))
+		}
+		wh := f.webhooks["wh-1"]
+		if wh.AgentID != "new" || wh.Prompt != "Triage this alert" || wh.Filter != "severity == 'high'" {
+			t.Errorf("wh-1 = %+v, want it on the new agent with its prompt and filter", wh)
+		}
+		if f.webhooks["wh-2"].AgentID != "other" {
+			t.Errorf("wh-2 should not have moved")
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		f := newFakeWebhookUpdater()
+		f.failOn = "wh-3"
+		if _, err := reassignWebhooks(context.Background(), f, "old", "new", false); err == nil || !strings.Contains(err.Error(), "wh-3") {
+			t.Fatalf("expected an error about wh-3, got %v", err)
+		}
+		if f.webhooks["wh-1"].AgentID != "old" {
+			t.Errorf("wh-1 was not restored after the failed update")
+		}
+	})
+}
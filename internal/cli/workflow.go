@@ -52,7 +52,7 @@ This command provides comprehensive workflow management capabilities:
 	// Add subcommands
 	cmd.AddCommand(
 		newWorkflowGenerateCommand(cfg),
-		newWorkflowDescribeCommand(cfg),
+		paged(newWorkflowDescribeCommand(cfg)),
 		newWorkflowTestCommand(cfg),
 		newWorkflowExecuteCommand(cfg),
 		newWorkflowRunCommand(cfg), // Execute stored workflows by ID/name
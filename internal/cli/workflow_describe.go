@@ -177,9 +177,7 @@ func parseWorkflowDescribeFile(filename string) (*WorkflowDescribe, error) {
 }
 
 func outputJSON(workflow *WorkflowDescribe) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(workflow)
+	return printJSON(workflow)
 }
 
 func outputYAML(workflow *WorkflowDescribe) error {
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/httpclient"
 	"github.com/kubiyabot/cli/internal/kubiya"
 	sentryutil "github.com/kubiyabot/cli/internal/sentry"
 	"github.com/kubiyabot/cli/internal/style"
@@ -161,7 +162,7 @@ func fetchWorkflowFromURL(urlInfo *URLInfo) (string, error) {
 func downloadFromRawURL(urlInfo *URLInfo) (string, error) {
 	fmt.Printf("%s Downloading workflow from URL...\n", style.InfoStyle.Render("📥"))
 	
-	resp, err := http.Get(urlInfo.Original)
+	resp, err := httpclient.Get(urlInfo.Original)
 	if err != nil {
 		return "", fmt.Errorf("failed to download from URL: %w", err)
 	}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +12,7 @@ import (
 
 	"github.com/kubiyabot/cli/internal/composer"
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/output"
 	"github.com/kubiyabot/cli/internal/style"
 	"github.com/spf13/cobra"
 )
@@ -37,9 +37,7 @@ func (o *WorkflowDetailsOutput) PrintJSON(w io.Writer) error {
 	if w == nil {
 		w = os.Stdout
 	}
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(o)
+	return output.EncodeJSON(w, o)
 }
 
 func (o *WorkflowDetailsOutput) PrintTable(w io.Writer) error {
@@ -100,9 +98,7 @@ func (o *WorkflowListOutput) PrintJSON(w io.Writer) error {
 	if w == nil {
 		w = os.Stdout
 	}
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(o)
+	return output.EncodeJSON(w, o)
 }
 
 func (o *WorkflowListOutput) PrintTable(w io.Writer) error {
@@ -392,9 +388,7 @@ func (o *WorkflowExecutionListOutput) PrintJSON(w io.Writer) error {
 	if w == nil {
 		w = os.Stdout
 	}
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(o)
+	return output.EncodeJSON(w, o)
 }
 
 func (o *WorkflowExecutionListOutput) PrintTable(w io.Writer) error {
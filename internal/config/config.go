@@ -6,9 +6,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 const (
@@ -19,14 +21,21 @@ const (
 )
 
 type Config struct {
-	Org         string
-	Email       string
-	APIKey      string
-	BaseURL     string
-	Debug       bool
-	AutoSession bool
-	UseV1API    bool // Whether to use V1 API (from context or env var)
-	ContextName string // Current context name
+	Org               string
+	Email             string
+	APIKey            string
+	APIKeySource      string // Where APIKey came from, e.g. the KUBIYA_API_KEY environment variable
+	BaseURL           string
+	Debug             bool
+	AutoSession       bool
+	UseV1API          bool                // Whether to use V1 API (from context or env var)
+	ContextName       string              // Current context name
+	RateLimit         float64             // Client-side request rate limit in requests per second (0 = unlimited)
+	RateBurst         int                 // Requests allowed in a burst above RateLimit
+	Mock              bool                // Serve the API from the embedded mock server (KUBIYA_MOCK=1 or the "mock" context)
+	FaultInject       string              // Faults injected into API calls for resilience testing (KUBIYA_FAULT_INJECT)
+	StreamIdleTimeout time.Duration       // Silence after which an event stream is considered dead (KUBIYA_STREAM_IDLE_TIMEOUT, 0 = never)
+	Preferences       context.Preferences // Defaults from the config file, with overrides of the current context
 }
 
 // GetConfigFilePath returns the expected full path to the config file.
@@ -75,16 +84,33 @@ func Load() (*Config, error) {
 		}
 	}
 	cfg.AutoSession = autoSession
+	cfg.Mock, _ = strconv.ParseBool(os.Getenv("KUBIYA_MOCK"))
+	cfg.FaultInject = os.Getenv("KUBIYA_FAULT_INJECT")
+	if val := os.Getenv("KUBIYA_STREAM_IDLE_TIMEOUT"); val != "" {
+		if parsed, err := time.ParseDuration(val); err == nil && parsed >= 0 {
+			cfg.StreamIdleTimeout = parsed
+		}
+	}
+	defer cfg.applyPreferences()
 
 	// Try to load from context first
 	if ctx, name, err := context.GetCurrentContext(); err == nil {
 		cfg.ContextName = name
+		cfg.Mock = cfg.Mock || name == "mock"
 		cfg.UseV1API = ctx.UseV1API
 		cfg.BaseURL = ctx.APIURL
+		if ctx.RateLimit != nil {
+			cfg.RateLimit = ctx.RateLimit.RequestsPerSecond
+			cfg.RateBurst = ctx.RateLimit.Burst
+		}
+		cfg.applyRateLimitEnv()
+
+		configureHTTP(ctx)
 
 		// Get API key from user
 		if user, err := context.GetUser(ctx.User); err == nil {
 			cfg.APIKey = user.Token
+			cfg.APIKeySource = contextKeySource(name, ctx.User)
 		}
 
 		// Extract org and email from JWT
@@ -95,9 +121,14 @@ func Load() (*Config, error) {
 		return cfg, nil
 	}
 
+	configureHTTP(nil)
+
 	// Fallback to environment variables if no context is configured
 	apiKey := os.Getenv("KUBIYA_API_KEY")
 	cfg.APIKey = apiKey
+	if apiKey != "" {
+		cfg.APIKeySource = "the KUBIYA_API_KEY environment variable"
+	}
 
 	// Check for V1 API flag
 	cfg.UseV1API = context.ShouldUseV1API()
@@ -122,10 +153,90 @@ func Load() (*Config, error) {
 	if cfg.APIKey != "" {
 		_, _ = cfg.jwtDecoder()
 	}
+	cfg.applyRateLimitEnv()
 
 	return cfg, nil
 }
 
+// contextKeySource describes the API key of the user of a context
+func contextKeySource(contextName, userName string) string {
+	selected := "the current context"
+	if os.Getenv("KUBIYA_CONTEXT") == contextName {
+		selected = "the KUBIYA_CONTEXT environment variable"
+	}
+	source := fmt.Sprintf("user %q of context %q, selected by %s", userName, contextName, selected)
+	if path, err := context.GetConfigPath(); err == nil {
+		source += " in " + path
+	}
+	return source
+}
+
+// applyPreferences loads the preferences of the current context. The default
+// runner is exported as KUBIYA_DEFAULT_RUNNER, unless already set, so that
+// every command resolving the default runner honors it.
+func (c *Config) applyPreferences() {
+	file, err := context.LoadConfig()
+	if err != nil {
+		return
+	}
+	c.Preferences = file.EffectivePreferences(c.ContextName)
+	if c.Preferences.Runner != "" && os.Getenv("KUBIYA_DEFAULT_RUNNER") == "" {
+		os.Setenv("KUBIYA_DEFAULT_RUNNER", c.Preferences.Runner)
+	}
+}
+
+// TelemetryEnabled reports whether crashes and errors may be reported.
+// KUBIYA_TELEMETRY takes precedence over the telemetry.enabled preference.
+func TelemetryEnabled() bool {
+	if val := os.Getenv("KUBIYA_TELEMETRY"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			return enabled
+		}
+	}
+	file, err := context.LoadConfig()
+	if err != nil {
+		return true
+	}
+	name := os.Getenv("KUBIYA_CONTEXT")
+	if name == "" {
+		name = file.CurrentContext
+	}
+	return file.EffectivePreferences(name).TelemetryEnabled()
+}
+
+// configureHTTP applies the TLS settings of the current context to all HTTP
+// clients. KUBIYA_CA_BUNDLE, KUBIYA_CLIENT_CERT and KUBIYA_CLIENT_KEY take
+// precedence over the context. Invalid settings fail the requests of the
+// clients rather than loading the config, so that commands making no
+// requests, like fixing the context, still work.
+func configureHTTP(ctx *context.Context) {
+	opts := httpclient.OptionsFromEnv()
+	if ctx != nil {
+		if opts.CABundle == "" {
+			opts.CABundle = ctx.CABundle
+		}
+		if opts.ClientCert == "" && opts.ClientKey == "" {
+			opts.ClientCert, opts.ClientKey = ctx.ClientCert, ctx.ClientKey
+		}
+	}
+	_ = httpclient.Configure(opts)
+}
+
+// applyRateLimitEnv lets KUBIYA_RATE_LIMIT and KUBIYA_RATE_BURST override the
+// rate limit of the current context
+func (c *Config) applyRateLimitEnv() {
+	if val := os.Getenv("KUBIYA_RATE_LIMIT"); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil && parsed >= 0 {
+			c.RateLimit = parsed
+		}
+	}
+	if val := os.Getenv("KUBIYA_RATE_BURST"); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil && parsed >= 0 {
+			c.RateBurst = parsed
+		}
+	}
+}
+
 func (c *Config) BaseURLV2() string {
 	const (
 		v1 = "/v1"
@@ -163,6 +274,24 @@ func (c *Config) jwtDecoder() (*Config, error) {
 	return c, nil
 }
 
+// APIKeyExpiry returns when the API key expires, when it is a JWT with an
+// expiry claim
+func (c *Config) APIKeyExpiry() (time.Time, bool) {
+	token, _, _ := new(jwt.Parser).ParseUnverified(c.APIKey, jwt.MapClaims{})
+	if token == nil {
+		return time.Time{}, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return time.Time{}, false
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
 // ValidateAPIKey checks if an API key is configured
 func (c *Config) ValidateAPIKey() error {
 	if c.APIKey == "" {
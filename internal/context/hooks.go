@@ -0,0 +1,127 @@
+package context
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// HooksConfig are commands run around every CLI command: before it runs
+// (pre-run), after it succeeded (post-run) and after it failed (on-error)
+type HooksConfig struct {
+	PreRun  []CommandHook `yaml:"pre-run,omitempty"`
+	PostRun []CommandHook `yaml:"post-run,omitempty"`
+	OnError []CommandHook `yaml:"on-error,omitempty"`
+}
+
+// CommandHook is a hook of a command lifecycle event. Deny and SetFlags
+// only apply to pre-run hooks.
+type CommandHook struct {
+	// Name identifies the hook in messages, the script is used by default
+	Name string `yaml:"name,omitempty"`
+	// Commands are glob patterns matched against the command path without
+	// "kubiya", e.g. "agent chat" or "* delete". A pattern also matches the
+	// subcommands of the commands it matches. Hooks without patterns apply
+	// to every command.
+	Commands []string `yaml:"commands,omitempty"`
+	// Run is a script receiving the invocation as JSON on stdin. A pre-run
+	// script blocks the command by exiting with a non-zero code and can set
+	// flags by printing {"set-flags": {...}}.
+	Run string `yaml:"run,omitempty"`
+	// Deny blocks the command with this message
+	Deny string `yaml:"deny,omitempty"`
+	// SetFlags are flags set on the command, replacing the values given on
+	// the command line. Flags the command does not have are ignored.
+	SetFlags map[string]string `yaml:"set-flags,omitempty"`
+}
+
+// Hook lifecycle events
+const (
+	HookPreRun  = "pre-run"
+	HookPostRun = "post-run"
+	HookOnError = "on-error"
+)
+
+// Hooks returns the hooks of event
+func (h *HooksConfig) Hooks(event string) []CommandHook {
+	if h == nil {
+		return nil
+	}
+	switch event {
+	case HookPreRun:
+		return h.PreRun
+	case HookPostRun:
+		return h.PostRun
+	case HookOnError:
+		return h.OnError
+	}
+	return nil
+}
+
+// Matches reports whether the hook applies to the command at path, e.g.
+// "agent tools list"
+func (h CommandHook) Matches(commandPath string) bool {
+	if len(h.Commands) == 0 {
+		return true
+	}
+	for _, pattern := range h.Commands {
+		for p := commandPath; p != ""; p = parentCommandPath(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parentCommandPath(commandPath string) string {
+	for i := len(commandPath) - 1; i >= 0; i-- {
+		if commandPath[i] == ' ' {
+			return commandPath[:i]
+		}
+	}
+	return ""
+}
+
+// DisplayName is the name of the hook in messages
+func (h CommandHook) DisplayName() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.Run != "":
+		return h.Run
+	}
+	return "config"
+}
+
+// Validate checks that the hook does something and that its patterns are
+// valid. Deny and set-flags are only valid for pre-run hooks.
+func (h CommandHook) Validate(event string) error {
+	if h.Run == "" && h.Deny == "" && len(h.SetFlags) == 0 {
+		return fmt.Errorf("hook needs run, deny or set-flags")
+	}
+	if event != HookPreRun && (h.Deny != "" || len(h.SetFlags) > 0) {
+		return fmt.Errorf("deny and set-flags only apply to pre-run hooks")
+	}
+	for _, pattern := range h.Commands {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid command pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// merge returns the hooks of h followed by those of other
+func (h *HooksConfig) merge(other *HooksConfig) *HooksConfig {
+	if h == nil {
+		return other
+	}
+	if other == nil {
+		return h
+	}
+	return &HooksConfig{
+		PreRun:  slices.Concat(h.PreRun, other.PreRun),
+		PostRun: slices.Concat(h.PostRun, other.PostRun),
+		OnError: slices.Concat(h.OnError, other.OnError),
+	}
+}
@@ -0,0 +1,66 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHookMatches(t *testing.T) {
+	hook := CommandHook{Commands: []string{"agent", "* delete"}}
+	assert.True(t, hook.Matches("agent"))
+	assert.True(t, hook.Matches("agent tools list"), "subcommands match")
+	assert.True(t, hook.Matches("team delete"))
+	assert.True(t, hook.Matches("agent tools delete"))
+	assert.False(t, hook.Matches("agents"))
+	assert.False(t, hook.Matches("team list"))
+	assert.True(t, CommandHook{}.Matches("team list"), "hooks without patterns apply to every command")
+}
+
+func TestHooksPreferences(t *testing.T) {
+	usage := CommandHook{Run: "~/bin/usage"}
+	readOnly := CommandHook{Commands: []string{"exec", "agent chat"}, SetFlags: map[string]string{"permission-level": "read"}}
+	config := &Config{
+		Preferences: &Preferences{Hooks: &HooksConfig{PostRun: []CommandHook{usage}}},
+		Contexts: []NamedContext{
+			{Name: "prod", Context: Context{Preferences: &Preferences{
+				Hooks: &HooksConfig{PreRun: []CommandHook{readOnly}, PostRun: []CommandHook{{Run: "~/bin/audit"}}},
+			}}},
+			{Name: "dev"},
+		},
+	}
+
+	prod := config.EffectivePreferences("prod").Hooks
+	assert.Equal(t, []CommandHook{readOnly}, prod.Hooks(HookPreRun))
+	assert.Equal(t, []CommandHook{usage, {Run: "~/bin/audit"}}, prod.Hooks(HookPostRun), "hooks of a context run after the global ones")
+	assert.Equal(t, []CommandHook{usage}, config.EffectivePreferences("dev").Hooks.Hooks(HookPostRun))
+	assert.Len(t, config.Preferences.Hooks.PostRun, 1, "merging keeps the global hooks unchanged")
+}
+
+func TestValidateHooks(t *testing.T) {
+	data := `preferences:
+  hooks:
+    pre-run:
+    - commands: ["[agent"]
+      deny: no
+    - name: nothing
+    post-run:
+    - deny: too late
+    - run: ~/bin/usage
+      comand: agent
+`
+	issues, err := ValidateConfig([]byte(data))
+	require.NoError(t, err)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	assert.Equal(t, []string{
+		`line 10: preferences.hooks.post-run[1].comand: unknown key (did you mean "commands"?)`,
+		`preferences.hooks.pre-run[0]: invalid command pattern "[agent": syntax error in pattern`,
+		`preferences.hooks.pre-run[1]: hook needs run, deny or set-flags`,
+		`preferences.hooks.post-run[0]: deny and set-flags only apply to pre-run hooks`,
+	}, got)
+}
@@ -232,3 +232,189 @@ func ShouldUseV1API() bool {
 
 	return ctx.UseV1API
 }
+
+// OrganizationTarget holds everything needed to call the API of an organization
+type OrganizationTarget struct {
+	Name   string
+	ID     string
+	APIURL string
+	Token  string
+}
+
+// ListOrganizations returns all configured organizations
+func ListOrganizations() ([]NamedOrganization, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Organizations, nil
+}
+
+// SetOrganization creates or updates an organization
+func SetOrganization(name string, org Organization) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	for i, no := range config.Organizations {
+		if no.Name == name {
+			config.Organizations[i].Organization = org
+			return SaveConfig(config)
+		}
+	}
+
+	config.Organizations = append(config.Organizations, NamedOrganization{
+		Name:         name,
+		Organization: org,
+	})
+
+	return SaveConfig(config)
+}
+
+// DeleteOrganization deletes an organization
+func DeleteOrganization(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	for i, no := range config.Organizations {
+		if no.Name == name {
+			config.Organizations = append(config.Organizations[:i], config.Organizations[i+1:]...)
+			return SaveConfig(config)
+		}
+	}
+
+	return fmt.Errorf("organization %q not found", name)
+}
+
+// ListContextBundles returns all configured context bundles
+func ListContextBundles() ([]NamedContextBundle, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.ContextBundles, nil
+}
+
+// GetContextBundle returns the context bundle with the given name
+func GetContextBundle(name string) (*ContextBundle, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nb := range config.ContextBundles {
+		if nb.Name == name {
+			return &nb.Bundle, nil
+		}
+	}
+
+	return nil, fmt.Errorf("context bundle %q not found", name)
+}
+
+// SetContextBundle creates or updates a context bundle
+func SetContextBundle(name string, bundle ContextBundle) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	for i, nb := range config.ContextBundles {
+		if nb.Name == name {
+			config.ContextBundles[i].Bundle = bundle
+			return SaveConfig(config)
+		}
+	}
+
+	config.ContextBundles = append(config.ContextBundles, NamedContextBundle{
+		Name:   name,
+		Bundle: bundle,
+	})
+
+	return SaveConfig(config)
+}
+
+// DeleteContextBundle deletes a context bundle
+func DeleteContextBundle(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	for i, nb := range config.ContextBundles {
+		if nb.Name == name {
+			config.ContextBundles = append(config.ContextBundles[:i], config.ContextBundles[i+1:]...)
+			return SaveConfig(config)
+		}
+	}
+
+	return fmt.Errorf("context bundle %q not found", name)
+}
+
+// ResolveOrganization looks up an organization by name. When no organization
+// entry matches, a context with that name is used instead so existing
+// per-org contexts work as copy targets too.
+func ResolveOrganization(name string) (*OrganizationTarget, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.ResolveOrganization(name)
+}
+
+// ResolveOrganization resolves an organization or context name to its API
+// endpoint and credentials
+func (c *Config) ResolveOrganization(name string) (*OrganizationTarget, error) {
+	var target *OrganizationTarget
+	var user string
+
+	for _, no := range c.Organizations {
+		if no.Name == name {
+			target = &OrganizationTarget{
+				Name:   name,
+				ID:     no.Organization.ID,
+				APIURL: no.Organization.APIURL,
+			}
+			user = no.Organization.User
+			break
+		}
+	}
+
+	if target == nil {
+		for _, nc := range c.Contexts {
+			if nc.Name == name {
+				target = &OrganizationTarget{
+					Name:   name,
+					ID:     nc.Context.Organization,
+					APIURL: nc.Context.APIURL,
+				}
+				user = nc.Context.User
+				break
+			}
+		}
+	}
+
+	if target == nil {
+		return nil, fmt.Errorf("organization %q not found (configure it with 'kubiya config set-org')", name)
+	}
+
+	if user == "" {
+		return nil, fmt.Errorf("organization %q has no user configured", name)
+	}
+	for _, nu := range c.Users {
+		if nu.Name == user {
+			target.Token = nu.User.Token
+			break
+		}
+	}
+	if target.Token == "" {
+		return nil, fmt.Errorf("no token found for user %q of organization %q", user, name)
+	}
+
+	return target, nil
+}
@@ -0,0 +1,493 @@
+package context
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/suggest"
+)
+
+// Preferences are defaults applied to commands. They can be set for all
+// contexts at the top level of the config and overridden per context.
+type Preferences struct {
+	Runner    string           `yaml:"runner,omitempty"`
+	LLMModel  string           `yaml:"llm-model,omitempty"`
+	Output    string           `yaml:"output,omitempty"`
+	NoEmoji   *bool            `yaml:"no-emoji,omitempty"`
+	Telemetry *TelemetryConfig `yaml:"telemetry,omitempty"`
+	Cache     *CacheConfig     `yaml:"cache,omitempty"`
+	Chat      *ChatConfig      `yaml:"chat,omitempty"`
+	Hooks     *HooksConfig     `yaml:"hooks,omitempty"`
+}
+
+// TelemetryConfig controls error reporting
+type TelemetryConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty"`
+}
+
+// CacheConfig controls the in-memory API response cache and the ETags of
+// listings kept in ~/.kubiya/cache/http
+type CacheConfig struct {
+	Enabled *bool  `yaml:"enabled,omitempty"`
+	TTL     string `yaml:"ttl,omitempty"`
+}
+
+// ChatConfig configures chat sessions
+type ChatConfig struct {
+	Guardrails []GuardrailRule `yaml:"guardrails,omitempty"`
+	// PreSendHook is a script that receives every outgoing prompt on stdin
+	// and can rewrite it (stdout) or reject it (non-zero exit code)
+	PreSendHook string `yaml:"pre-send-hook,omitempty"`
+	// AutoFollowUp is the policy for nudging agents that answered a
+	// non-interactive message without running a tool (off, once, aggressive)
+	AutoFollowUp string `yaml:"auto-followup,omitempty"`
+	// FollowUpTemplate is a Go template replacing the default nudge
+	FollowUpTemplate string `yaml:"followup-template,omitempty"`
+}
+
+// with returns a copy of c changed by change; the chat config may be shared
+// with merged preferences so it is never changed in place
+func (c *ChatConfig) with(change func(*ChatConfig)) *ChatConfig {
+	var chat ChatConfig
+	if c != nil {
+		chat = *c
+	}
+	change(&chat)
+	return &chat
+}
+
+func (c *ChatConfig) isEmpty() bool {
+	return len(c.Guardrails) == 0 && c.PreSendHook == "" && c.AutoFollowUp == "" && c.FollowUpTemplate == ""
+}
+
+// AutoFollowUpPolicies are the accepted values of the auto-followup preference
+var AutoFollowUpPolicies = []string{"off", "once", "aggressive"}
+
+// ValidateAutoFollowUp checks that v is a known follow-up policy
+func ValidateAutoFollowUp(v string) error {
+	if !slices.Contains(AutoFollowUpPolicies, v) {
+		return fmt.Errorf("invalid auto follow-up policy %q (must be one of %s)", v, strings.Join(AutoFollowUpPolicies, ", "))
+	}
+	return nil
+}
+
+// ValidateFollowUpTemplate checks that v parses as a Go template
+func ValidateFollowUpTemplate(v string) error {
+	if _, err := template.New("followup").Option("missingkey=error").Parse(v); err != nil {
+		return fmt.Errorf("invalid follow-up template: %w", err)
+	}
+	return nil
+}
+
+// GuardrailRule matches tool calls that are flagged in chat sessions and need
+// confirmation before the CLI follows the response further. A rule matches
+// when all of its patterns match.
+type GuardrailRule struct {
+	// Tool is a regular expression matched against the tool name
+	Tool string `yaml:"tool,omitempty"`
+	// Args is a regular expression matched against the JSON arguments
+	Args string `yaml:"args,omitempty"`
+	// Action is confirm (the default) or deny
+	Action string `yaml:"action,omitempty"`
+}
+
+// GuardrailActions are the accepted values of a guardrail action
+var GuardrailActions = []string{"confirm", "deny"}
+
+// Validate checks that the patterns compile and the action is known
+func (r GuardrailRule) Validate() error {
+	if r.Tool == "" && r.Args == "" {
+		return fmt.Errorf("guardrail needs a tool or args pattern")
+	}
+	for _, pattern := range []string{r.Tool, r.Args} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid guardrail pattern %q: %w", pattern, err)
+		}
+	}
+	if r.Action != "" && !slices.Contains(GuardrailActions, r.Action) {
+		return fmt.Errorf("invalid guardrail action %q (valid: %s)", r.Action, strings.Join(GuardrailActions, ", "))
+	}
+	return nil
+}
+
+// OutputFormats are the accepted values of the output preference
+var OutputFormats = []string{"text", "json", "yaml"}
+
+// preferenceKey describes a key of `kubiya config get/set/unset`
+type preferenceKey struct {
+	description string
+	get         func(p *Preferences) (string, bool)
+	set         func(p *Preferences, value string) error
+	unset       func(p *Preferences)
+}
+
+var preferenceKeys = map[string]preferenceKey{
+	"runner": {
+		description: "Default runner for tools, workflows and inline agents",
+		get:         func(p *Preferences) (string, bool) { return p.Runner, p.Runner != "" },
+		set:         func(p *Preferences, v string) error { p.Runner = v; return nil },
+		unset:       func(p *Preferences) { p.Runner = "" },
+	},
+	"llm-model": {
+		description: "Default LLM model for inline agents",
+		get:         func(p *Preferences) (string, bool) { return p.LLMModel, p.LLMModel != "" },
+		set:         func(p *Preferences, v string) error { p.LLMModel = v; return nil },
+		unset:       func(p *Preferences) { p.LLMModel = "" },
+	},
+	"output": {
+		description: "Default --output format (" + strings.Join(OutputFormats, ", ") + ")",
+		get:         func(p *Preferences) (string, bool) { return p.Output, p.Output != "" },
+		set: func(p *Preferences, v string) error {
+			if err := validateOutputFormat(v); err != nil {
+				return err
+			}
+			p.Output = v
+			return nil
+		},
+		unset: func(p *Preferences) { p.Output = "" },
+	},
+	"no-emoji": {
+		description: "Strip emoji and decorative glyphs from output (true or false)",
+		get: func(p *Preferences) (string, bool) {
+			if p.NoEmoji == nil {
+				return "", false
+			}
+			return strconv.FormatBool(*p.NoEmoji), true
+		},
+		set: func(p *Preferences, v string) error {
+			b, err := parsePreferenceBool(v)
+			if err != nil {
+				return err
+			}
+			p.NoEmoji = &b
+			return nil
+		},
+		unset: func(p *Preferences) { p.NoEmoji = nil },
+	},
+	"telemetry.enabled": {
+		description: "Report crashes and errors to Kubiya (true or false)",
+		get: func(p *Preferences) (string, bool) {
+			if p.Telemetry == nil || p.Telemetry.Enabled == nil {
+				return "", false
+			}
+			return strconv.FormatBool(*p.Telemetry.Enabled), true
+		},
+		set: func(p *Preferences, v string) error {
+			b, err := parsePreferenceBool(v)
+			if err != nil {
+				return err
+			}
+			p.Telemetry = &TelemetryConfig{Enabled: &b}
+			return nil
+		},
+		unset: func(p *Preferences) { p.Telemetry = nil },
+	},
+	"cache.enabled": {
+		description: "Cache API responses such as source metadata (true or false)",
+		get: func(p *Preferences) (string, bool) {
+			if p.Cache == nil || p.Cache.Enabled == nil {
+				return "", false
+			}
+			return strconv.FormatBool(*p.Cache.Enabled), true
+		},
+		set: func(p *Preferences, v string) error {
+			b, err := parsePreferenceBool(v)
+			if err != nil {
+				return err
+			}
+			if p.Cache == nil {
+				p.Cache = &CacheConfig{}
+			}
+			p.Cache.Enabled = &b
+			return nil
+		},
+		unset: func(p *Preferences) {
+			if p.Cache != nil {
+				p.Cache.Enabled = nil
+			}
+		},
+	},
+	"cache.ttl": {
+		description: "How long cached API responses are reused (e.g. 5m)",
+		get: func(p *Preferences) (string, bool) {
+			if p.Cache == nil || p.Cache.TTL == "" {
+				return "", false
+			}
+			return p.Cache.TTL, true
+		},
+		set: func(p *Preferences, v string) error {
+			if err := validateCacheTTL(v); err != nil {
+				return err
+			}
+			if p.Cache == nil {
+				p.Cache = &CacheConfig{}
+			}
+			p.Cache.TTL = v
+			return nil
+		},
+		unset: func(p *Preferences) {
+			if p.Cache != nil {
+				p.Cache.TTL = ""
+			}
+		},
+	},
+	"chat.pre-send-hook": {
+		description: "Script that can rewrite or reject every chat prompt before it is sent",
+		get: func(p *Preferences) (string, bool) {
+			if p.Chat == nil || p.Chat.PreSendHook == "" {
+				return "", false
+			}
+			return p.Chat.PreSendHook, true
+		},
+		set: func(p *Preferences, v string) error {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("pre-send hook needs a script path")
+			}
+			p.Chat = p.Chat.with(func(c *ChatConfig) { c.PreSendHook = v })
+			return nil
+		},
+		unset: func(p *Preferences) {
+			if p.Chat != nil {
+				p.Chat = p.Chat.with(func(c *ChatConfig) { c.PreSendHook = "" })
+			}
+		},
+	},
+	"chat.auto-followup": {
+		description: "Follow up when a non-interactive chat ran no tool (" + strings.Join(AutoFollowUpPolicies, ", ") + ")",
+		get: func(p *Preferences) (string, bool) {
+			if p.Chat == nil || p.Chat.AutoFollowUp == "" {
+				return "", false
+			}
+			return p.Chat.AutoFollowUp, true
+		},
+		set: func(p *Preferences, v string) error {
+			if err := ValidateAutoFollowUp(v); err != nil {
+				return err
+			}
+			p.Chat = p.Chat.with(func(c *ChatConfig) { c.AutoFollowUp = v })
+			return nil
+		},
+		unset: func(p *Preferences) {
+			if p.Chat != nil {
+				p.Chat = p.Chat.with(func(c *ChatConfig) { c.AutoFollowUp = "" })
+			}
+		},
+	},
+	"chat.followup-template": {
+		description: "Go template of the follow-up message, e.g. {{.Message}} and {{.Attempt}}",
+		get: func(p *Preferences) (string, bool) {
+			if p.Chat == nil || p.Chat.FollowUpTemplate == "" {
+				return "", false
+			}
+			return p.Chat.FollowUpTemplate, true
+		},
+		set: func(p *Preferences, v string) error {
+			if err := ValidateFollowUpTemplate(v); err != nil {
+				return err
+			}
+			p.Chat = p.Chat.with(func(c *ChatConfig) { c.FollowUpTemplate = v })
+			return nil
+		},
+		unset: func(p *Preferences) {
+			if p.Chat != nil {
+				p.Chat = p.Chat.with(func(c *ChatConfig) { c.FollowUpTemplate = "" })
+			}
+		},
+	},
+}
+
+// PreferenceKeys returns the keys accepted by Get, Set and Unset with their
+// descriptions
+func PreferenceKeys() map[string]string {
+	keys := make(map[string]string, len(preferenceKeys))
+	for k, v := range preferenceKeys {
+		keys[k] = v.description
+	}
+	return keys
+}
+
+func lookupPreferenceKey(key string) (preferenceKey, error) {
+	if k, ok := preferenceKeys[key]; ok {
+		return k, nil
+	}
+	names := make([]string, 0, len(preferenceKeys))
+	for name := range preferenceKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msg := fmt.Sprintf("unknown config key %q", key)
+	if s := suggest.Closest(key, names); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	return preferenceKey{}, fmt.Errorf("%s; valid keys: %s", msg, strings.Join(names, ", "))
+}
+
+// Get returns the value of key and whether it is set
+func (p *Preferences) Get(key string) (string, bool, error) {
+	k, err := lookupPreferenceKey(key)
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := k.get(p)
+	return value, ok, nil
+}
+
+// Set validates and sets the value of key
+func (p *Preferences) Set(key, value string) error {
+	k, err := lookupPreferenceKey(key)
+	if err != nil {
+		return err
+	}
+	return k.set(p, value)
+}
+
+// Unset removes key
+func (p *Preferences) Unset(key string) error {
+	k, err := lookupPreferenceKey(key)
+	if err != nil {
+		return err
+	}
+	k.unset(p)
+	if p.Cache != nil && p.Cache.Enabled == nil && p.Cache.TTL == "" {
+		p.Cache = nil
+	}
+	if p.Chat != nil && p.Chat.isEmpty() {
+		p.Chat = nil
+	}
+	return nil
+}
+
+// IsEmpty reports whether no preference is set
+func (p *Preferences) IsEmpty() bool {
+	return p == nil || *p == (Preferences{})
+}
+
+// MergePreferences returns base with every preference set in override
+// replacing it
+func MergePreferences(base, override *Preferences) Preferences {
+	var merged Preferences
+	if base != nil {
+		merged = *base
+	}
+	if override == nil {
+		return merged
+	}
+	for _, k := range preferenceKeys {
+		if v, ok := k.get(override); ok {
+			_ = k.set(&merged, v)
+		}
+	}
+	// Guardrails of a context replace the global ones as a whole
+	if override.Chat != nil && override.Chat.Guardrails != nil {
+		var chat ChatConfig
+		if merged.Chat != nil {
+			chat = *merged.Chat
+		}
+		chat.Guardrails = override.Chat.Guardrails
+		merged.Chat = &chat
+	}
+	// Hooks of a context run after the global ones
+	merged.Hooks = merged.Hooks.merge(override.Hooks)
+	return merged
+}
+
+// TelemetryEnabled reports whether error reporting is enabled (the default)
+func (p Preferences) TelemetryEnabled() bool {
+	return p.Telemetry == nil || p.Telemetry.Enabled == nil || *p.Telemetry.Enabled
+}
+
+// CacheEnabled reports whether API responses may be cached
+func (p Preferences) CacheEnabled() bool {
+	return p.Cache == nil || p.Cache.Enabled == nil || *p.Cache.Enabled
+}
+
+// CacheTTL returns the configured cache lifetime: 0 when caching is
+// disabled, def when no TTL is configured
+func (p Preferences) CacheTTL(def time.Duration) time.Duration {
+	if p.Cache == nil {
+		return def
+	}
+	if p.Cache.Enabled != nil && !*p.Cache.Enabled {
+		return 0
+	}
+	if ttl, err := time.ParseDuration(p.Cache.TTL); err == nil {
+		return ttl
+	}
+	return def
+}
+
+// EffectivePreferences returns the preferences for the named context: the
+// global preferences overridden by those of the context
+func (c *Config) EffectivePreferences(contextName string) Preferences {
+	for _, nc := range c.Contexts {
+		if nc.Name == contextName {
+			return MergePreferences(c.Preferences, nc.Context.Preferences)
+		}
+	}
+	return MergePreferences(c.Preferences, nil)
+}
+
+// PreferencesFor returns the preferences stored for the named context, or the
+// global preferences when contextName is empty, creating them if needed
+func (c *Config) PreferencesFor(contextName string) (*Preferences, error) {
+	if contextName == "" {
+		if c.Preferences == nil {
+			c.Preferences = &Preferences{}
+		}
+		return c.Preferences, nil
+	}
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == contextName {
+			ctx := &c.Contexts[i].Context
+			if ctx.Preferences == nil {
+				ctx.Preferences = &Preferences{}
+			}
+			return ctx.Preferences, nil
+		}
+	}
+	return nil, fmt.Errorf("context %q not found", contextName)
+}
+
+// CompactPreferences drops empty preference sections so they are not
+// written to the config file
+func (c *Config) CompactPreferences() {
+	if c.Preferences.IsEmpty() {
+		c.Preferences = nil
+	}
+	for i := range c.Contexts {
+		if c.Contexts[i].Context.Preferences.IsEmpty() {
+			c.Contexts[i].Context.Preferences = nil
+		}
+	}
+}
+
+func validateOutputFormat(v string) error {
+	for _, f := range OutputFormats {
+		if v == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q (must be one of %s)", v, strings.Join(OutputFormats, ", "))
+}
+
+func validateCacheTTL(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid cache ttl %q (use a duration such as 30s or 5m)", v)
+	}
+	return nil
+}
+
+func parsePreferenceBool(v string) (bool, error) {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q (must be true or false)", v)
+	}
+	return b, nil
+}
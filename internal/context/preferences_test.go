@@ -0,0 +1,220 @@
+package context
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferencesSetGetUnset(t *testing.T) {
+	var p Preferences
+
+	require.NoError(t, p.Set("runner", "my-runner"))
+	require.NoError(t, p.Set("cache.ttl", "10m"))
+	require.NoError(t, p.Set("telemetry.enabled", "false"))
+	require.NoError(t, p.Set("no-emoji", "true"))
+
+	value, ok, err := p.Get("runner")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "my-runner", value)
+	assert.False(t, p.TelemetryEnabled())
+	assert.Equal(t, 10*time.Minute, p.CacheTTL(time.Minute))
+	value, ok, err = p.Get("no-emoji")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "true", value)
+
+	require.NoError(t, p.Unset("cache.ttl"))
+	assert.Nil(t, p.Cache)
+	require.NoError(t, p.Unset("runner"))
+	require.NoError(t, p.Unset("telemetry.enabled"))
+	require.NoError(t, p.Unset("no-emoji"))
+	assert.True(t, p.IsEmpty())
+}
+
+func TestPreferencesSetInvalid(t *testing.T) {
+	var p Preferences
+
+	tests := []struct {
+		key, value, want string
+	}{
+		{"output", "xml", "invalid output format"},
+		{"cache.ttl", "soon", "invalid cache ttl"},
+		{"cache.enabled", "maybe", "invalid boolean"},
+		{"runer", "x", `did you mean "runner"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := p.Set(tt.key, tt.value)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestEffectivePreferences(t *testing.T) {
+	disabled := false
+	config := &Config{
+		Preferences: &Preferences{Runner: "global-runner", Output: "json"},
+		Contexts: []NamedContext{
+			{Name: "ci", Context: Context{Preferences: &Preferences{
+				Output: "yaml",
+				Cache:  &CacheConfig{Enabled: &disabled},
+			}}},
+			{Name: "dev"},
+		},
+	}
+
+	ci := config.EffectivePreferences("ci")
+	assert.Equal(t, "global-runner", ci.Runner)
+	assert.Equal(t, "yaml", ci.Output)
+	assert.Zero(t, ci.CacheTTL(5*time.Minute))
+	assert.False(t, ci.CacheEnabled())
+
+	dev := config.EffectivePreferences("dev")
+	assert.Equal(t, "json", dev.Output)
+	assert.Equal(t, 5*time.Minute, dev.CacheTTL(5*time.Minute))
+	assert.True(t, dev.CacheEnabled())
+
+	// The merge must not modify the global preferences
+	assert.Equal(t, "json", config.Preferences.Output)
+}
+
+func TestGuardrailRules(t *testing.T) {
+	assert.NoError(t, GuardrailRule{Tool: "kubectl", Args: `\bdelete\b`}.Validate())
+	assert.NoError(t, GuardrailRule{Args: "terraform apply", Action: "deny"}.Validate())
+	assert.Error(t, GuardrailRule{}.Validate())
+	assert.Error(t, GuardrailRule{Tool: "("}.Validate())
+	assert.Error(t, GuardrailRule{Tool: "kubectl", Action: "ask"}.Validate())
+
+	config := &Config{
+		Preferences: &Preferences{Chat: &ChatConfig{Guardrails: []GuardrailRule{{Tool: "kubectl"}}}},
+		Contexts: []NamedContext{
+			{Name: "prod", Context: Context{Preferences: &Preferences{
+				Chat: &ChatConfig{Guardrails: []GuardrailRule{{Tool: "terraform", Action: "deny"}}},
+			}}},
+			{Name: "dev"},
+		},
+	}
+	assert.Equal(t, "terraform", config.EffectivePreferences("prod").Chat.Guardrails[0].Tool)
+	assert.Equal(t, "kubectl", config.EffectivePreferences("dev").Chat.Guardrails[0].Tool)
+}
+
+func TestPreSendHookPreference(t *testing.T) {
+	global := &Preferences{Chat: &ChatConfig{Guardrails: []GuardrailRule{{Tool: "kubectl"}}}}
+	require.NoError(t, global.Set("chat.pre-send-hook", "~/.kubiya/redact.sh"))
+	assert.Equal(t, "kubectl", global.Chat.Guardrails[0].Tool, "setting the hook keeps the guardrails")
+	assert.Error(t, global.Set("chat.pre-send-hook", " "))
+
+	prod := &Preferences{}
+	require.NoError(t, prod.Set("chat.pre-send-hook", "/opt/lint.sh"))
+	config := &Config{
+		Preferences: global,
+		Contexts: []NamedContext{
+			{Name: "prod", Context: Context{Preferences: prod}},
+			{Name: "dev", Context: Context{Preferences: &Preferences{
+				Chat: &ChatConfig{Guardrails: []GuardrailRule{{Tool: "terraform"}}},
+			}}},
+		},
+	}
+	assert.Equal(t, "/opt/lint.sh", config.EffectivePreferences("prod").Chat.PreSendHook)
+	assert.Equal(t, "kubectl", config.EffectivePreferences("prod").Chat.Guardrails[0].Tool)
+	dev := config.EffectivePreferences("dev")
+	assert.Equal(t, "~/.kubiya/redact.sh", dev.Chat.PreSendHook, "guardrails of a context keep the global hook")
+	assert.Equal(t, "terraform", dev.Chat.Guardrails[0].Tool)
+	assert.Equal(t, "~/.kubiya/redact.sh", global.Chat.PreSendHook, "merging leaves the global preferences alone")
+
+	require.NoError(t, prod.Unset("chat.pre-send-hook"))
+	assert.Nil(t, prod.Chat)
+}
+
+func TestValidateConfig(t *testing.T) {
+	data := `apiVersion: v1
+kind: Config
+current-context: prod
+preferences:
+  runnr: my-runner
+  output: xml
+  cache:
+    ttl: 5m
+contexts:
+- name: prod
+  context:
+    api-url: https://api.kubiya.ai/api/v1
+    user: admin
+    organisation: acme
+users:
+- name: admin
+  user:
+    token: secret
+`
+	issues, err := ValidateConfig([]byte(data))
+	require.NoError(t, err)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	assert.Equal(t, []string{
+		`line 5: preferences.runnr: unknown key (did you mean "runner"?)`,
+		`line 14: contexts[0].context.organisation: unknown key (did you mean "organization"?)`,
+		`preferences.output: invalid output format "xml" (must be one of text, json, yaml)`,
+	}, got)
+}
+
+func TestValidateConfigReferences(t *testing.T) {
+	data := `current-context: missing
+contexts:
+- name: prod
+  context:
+    user: nobody
+`
+	issues, err := ValidateConfig([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.True(t, strings.Contains(issues[0].Message, `unknown user "nobody"`))
+	assert.True(t, strings.Contains(issues[1].Message, `context "missing" does not exist`))
+}
+
+func TestContextBundles(t *testing.T) {
+	t.Setenv("KUBIYA_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	bundle := ContextBundle{Globs: []string{"k8s/**/*.yaml"}, Commands: []string{"kubectl get nodes"}}
+	require.NoError(t, SetContextBundle("prod-k8s", bundle))
+
+	got, err := GetContextBundle("prod-k8s")
+	require.NoError(t, err)
+	assert.Equal(t, bundle, *got)
+
+	require.NoError(t, DeleteContextBundle("prod-k8s"))
+	_, err = GetContextBundle("prod-k8s")
+	assert.EqualError(t, err, `context bundle "prod-k8s" not found`)
+
+	issues, err := ValidateConfig([]byte("context-bundles:\n- name: empty\n  bundle:\n    description: nothing\n"))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, `context-bundles[0].bundle: context bundle "empty" has no globs or commands`, issues[0].String())
+}
+
+func TestAutoFollowUpPreferences(t *testing.T) {
+	p := &Preferences{}
+	require.NoError(t, p.Set("chat.auto-followup", "off"))
+	require.NoError(t, p.Set("chat.followup-template", "Run {{.Message}}"))
+	assert.EqualError(t, p.Set("chat.auto-followup", "sometimes"),
+		`invalid auto follow-up policy "sometimes" (must be one of off, once, aggressive)`)
+	assert.ErrorContains(t, p.Set("chat.followup-template", "{{"), "invalid follow-up template")
+
+	merged := MergePreferences(p, &Preferences{Chat: &ChatConfig{AutoFollowUp: "aggressive"}})
+	assert.Equal(t, "aggressive", merged.Chat.AutoFollowUp)
+	assert.Equal(t, "Run {{.Message}}", merged.Chat.FollowUpTemplate)
+	assert.Equal(t, "off", p.Chat.AutoFollowUp)
+
+	require.NoError(t, p.Unset("chat.auto-followup"))
+	require.NoError(t, p.Unset("chat.followup-template"))
+	assert.Nil(t, p.Chat)
+}
@@ -2,12 +2,14 @@ package context
 
 // Config represents the kubectl-style configuration file
 type Config struct {
-	APIVersion     string            `yaml:"apiVersion"`
-	Kind           string            `yaml:"kind"`
-	CurrentContext string            `yaml:"current-context"`
-	Contexts       []NamedContext    `yaml:"contexts"`
-	Users          []NamedUser       `yaml:"users"`
-	Organizations  []NamedOrganization `yaml:"organizations,omitempty"`
+	APIVersion     string               `yaml:"apiVersion"`
+	Kind           string               `yaml:"kind"`
+	CurrentContext string               `yaml:"current-context"`
+	Contexts       []NamedContext       `yaml:"contexts"`
+	Users          []NamedUser          `yaml:"users"`
+	Organizations  []NamedOrganization  `yaml:"organizations,omitempty"`
+	Preferences    *Preferences         `yaml:"preferences,omitempty"`
+	ContextBundles []NamedContextBundle `yaml:"context-bundles,omitempty"`
 }
 
 // NamedContext represents a named context
@@ -23,6 +25,19 @@ type Context struct {
 	User         string              `yaml:"user"`
 	UseV1API     bool                `yaml:"use-v1-api,omitempty"`
 	LiteLLMProxy *LiteLLMProxyConfig `yaml:"litellm-proxy,omitempty"`
+	RateLimit    *RateLimitConfig    `yaml:"rate-limit,omitempty"`
+	// TLS settings for corporate proxies and private control planes
+	CABundle   string `yaml:"ca-bundle,omitempty"`
+	ClientCert string `yaml:"client-cert,omitempty"`
+	ClientKey  string `yaml:"client-key,omitempty"`
+	// Preferences override the global preferences while the context is used
+	Preferences *Preferences `yaml:"preferences,omitempty"`
+}
+
+// RateLimitConfig caps the request rate of API clients using a context
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests-per-second"`
+	Burst             int     `yaml:"burst,omitempty"`
 }
 
 // LiteLLMProxyConfig represents local LiteLLM proxy configuration
@@ -50,8 +65,26 @@ type NamedOrganization struct {
 	Organization Organization `yaml:"organization"`
 }
 
-// Organization represents organization details
+// Organization represents organization details. User references the
+// credentials used to act in the organization and APIURL optionally points
+// to a different control plane.
 type Organization struct {
-	ID   string `yaml:"id"`
-	Name string `yaml:"name"`
+	ID     string `yaml:"id"`
+	Name   string `yaml:"name"`
+	User   string `yaml:"user,omitempty"`
+	APIURL string `yaml:"api-url,omitempty"`
+}
+
+// NamedContextBundle represents a named context bundle
+type NamedContextBundle struct {
+	Name   string        `yaml:"name"`
+	Bundle ContextBundle `yaml:"bundle"`
+}
+
+// ContextBundle is a reusable collection of chat context: files matching
+// Globs (or URLs) and the output of Commands, run with the shell
+type ContextBundle struct {
+	Description string   `yaml:"description,omitempty"`
+	Globs       []string `yaml:"globs,omitempty"`
+	Commands    []string `yaml:"commands,omitempty"`
 }
@@ -0,0 +1,204 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/suggest"
+)
+
+// ConfigIssue is a problem found while validating the config file
+type ConfigIssue struct {
+	Line    int
+	Path    string
+	Message string
+}
+
+func (i ConfigIssue) String() string {
+	switch {
+	case i.Line > 0:
+		return fmt.Sprintf("line %d: %s: %s", i.Line, i.Path, i.Message)
+	case i.Path != "":
+		return fmt.Sprintf("%s: %s", i.Path, i.Message)
+	default:
+		return i.Message
+	}
+}
+
+// ValidateConfigFile checks the config file for unknown keys, with a
+// suggestion for likely typos, and for invalid values. A missing config
+// file has no issues.
+func ValidateConfigFile() ([]ConfigIssue, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ValidateConfig(data)
+}
+
+// ValidateConfig checks raw config YAML. See ValidateConfigFile.
+func ValidateConfig(data []byte) ([]ConfigIssue, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	var issues []ConfigIssue
+	checkKnownFields(doc.Content[0], reflect.TypeOf(Config{}), "", &issues)
+
+	var config Config
+	if err := doc.Decode(&config); err != nil {
+		return append(issues, ConfigIssue{Message: err.Error()}), nil
+	}
+	if config.APIVersion != "" && config.APIVersion != ConfigAPIVersion {
+		issues = append(issues, ConfigIssue{Path: "apiVersion",
+			Message: fmt.Sprintf("unsupported version %q (expected %q)", config.APIVersion, ConfigAPIVersion)})
+	}
+	checkPreferences(config.Preferences, "preferences", &issues)
+	for i, nc := range config.Contexts {
+		checkPreferences(nc.Context.Preferences, fmt.Sprintf("contexts[%d].context.preferences", i), &issues)
+		if nc.Context.User != "" && !hasUser(&config, nc.Context.User) {
+			issues = append(issues, ConfigIssue{Path: fmt.Sprintf("contexts[%d].context.user", i),
+				Message: fmt.Sprintf("context %q references unknown user %q", nc.Name, nc.Context.User)})
+		}
+	}
+	for i, nb := range config.ContextBundles {
+		if len(nb.Bundle.Globs) == 0 && len(nb.Bundle.Commands) == 0 {
+			issues = append(issues, ConfigIssue{Path: fmt.Sprintf("context-bundles[%d].bundle", i),
+				Message: fmt.Sprintf("context bundle %q has no globs or commands", nb.Name)})
+		}
+	}
+	if config.CurrentContext != "" && !hasContext(&config, config.CurrentContext) {
+		issues = append(issues, ConfigIssue{Path: "current-context",
+			Message: fmt.Sprintf("context %q does not exist", config.CurrentContext)})
+	}
+	return issues, nil
+}
+
+// checkKnownFields reports mapping keys that do not match a yaml tag of t
+func checkKnownFields(node *yaml.Node, t reflect.Type, path string, issues *[]ConfigIssue) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, key.Value)
+			field, ok := fields[key.Value]
+			if !ok {
+				msg := "unknown key"
+				if s := suggest.Closest(key.Value, names); s != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", s)
+				}
+				*issues = append(*issues, ConfigIssue{Line: key.Line, Path: childPath, Message: msg})
+				continue
+			}
+			checkKnownFields(value, field, childPath, issues)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			checkKnownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+	}
+}
+
+// yamlFields maps the yaml key of each field of t to its type
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func checkPreferences(p *Preferences, path string, issues *[]ConfigIssue) {
+	if p == nil {
+		return
+	}
+	if p.Output != "" {
+		if err := validateOutputFormat(p.Output); err != nil {
+			*issues = append(*issues, ConfigIssue{Path: path + ".output", Message: err.Error()})
+		}
+	}
+	if p.Cache != nil && p.Cache.TTL != "" {
+		if err := validateCacheTTL(p.Cache.TTL); err != nil {
+			*issues = append(*issues, ConfigIssue{Path: path + ".cache.ttl", Message: err.Error()})
+		}
+	}
+	if p.Chat != nil {
+		for i, rule := range p.Chat.Guardrails {
+			if err := rule.Validate(); err != nil {
+				*issues = append(*issues, ConfigIssue{Path: fmt.Sprintf("%s.chat.guardrails[%d]", path, i), Message: err.Error()})
+			}
+		}
+		if p.Chat.AutoFollowUp != "" {
+			if err := ValidateAutoFollowUp(p.Chat.AutoFollowUp); err != nil {
+				*issues = append(*issues, ConfigIssue{Path: path + ".chat.auto-followup", Message: err.Error()})
+			}
+		}
+		if p.Chat.FollowUpTemplate != "" {
+			if err := ValidateFollowUpTemplate(p.Chat.FollowUpTemplate); err != nil {
+				*issues = append(*issues, ConfigIssue{Path: path + ".chat.followup-template", Message: err.Error()})
+			}
+		}
+	}
+	for _, event := range []string{HookPreRun, HookPostRun, HookOnError} {
+		for i, hook := range p.Hooks.Hooks(event) {
+			if err := hook.Validate(event); err != nil {
+				*issues = append(*issues, ConfigIssue{Path: fmt.Sprintf("%s.hooks.%s[%d]", path, event, i), Message: err.Error()})
+			}
+		}
+	}
+}
+
+func hasUser(c *Config, name string) bool {
+	for _, u := range c.Users {
+		if u.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasContext(c *Config, name string) bool {
+	for _, nc := range c.Contexts {
+		if nc.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -10,6 +10,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // Client is a direct client for the Context Graph API
@@ -22,11 +25,9 @@ type Client struct {
 // NewClient creates a new context graph client
 func NewClient(baseURL, apiKey string) *Client {
 	return &Client{
-		BaseURL: baseURL,
-		APIKey:  apiKey,
-		HTTPClient: &http.Client{
-			Timeout: 5 * time.Minute, // Long timeout for streaming
-		},
+		BaseURL:    baseURL,
+		APIKey:     apiKey,
+		HTTPClient: httpclient.New(5 * time.Minute), // Long timeout for streaming
 	}
 }
 
@@ -64,6 +65,7 @@ func (c *Client) StreamSearch(ctx context.Context, req IntelligentSearchRequest)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	correlation.Set(httpReq)
 
 	// Execute request
 	resp, err := c.HTTPClient.Do(httpReq)
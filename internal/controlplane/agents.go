@@ -42,6 +42,33 @@ func (c *Client) UpdateAgent(id string, req *entities.AgentUpdateRequest) (*enti
 	return &agent, nil
 }
 
+// UpdateAgentExecutionEnvironment replaces the execution environment of an
+// agent. Unlike UpdateAgent, empty env vars, secrets and integrations are
+// sent rather than omitted, so that they can be cleared.
+func (c *Client) UpdateAgentExecutionEnvironment(id string, env entities.ExecutionEnvironment) (*entities.Agent, error) {
+	if env.EnvVars == nil {
+		env.EnvVars = map[string]string{}
+	}
+	if env.Secrets == nil {
+		env.Secrets = []string{}
+	}
+	if env.Integrations == nil {
+		env.Integrations = []string{}
+	}
+	body := map[string]interface{}{
+		"execution_environment": map[string]interface{}{
+			"env_vars":     env.EnvVars,
+			"secrets":      env.Secrets,
+			"integrations": env.Integrations,
+		},
+	}
+	var agent entities.Agent
+	if err := c.patch(fmt.Sprintf("/api/v1/agents/%s", id), body, &agent); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
 // DeleteAgent deletes an agent
 func (c *Client) DeleteAgent(id string) error {
 	return c.delete(fmt.Sprintf("/api/v1/agents/%s", id))
@@ -0,0 +1,39 @@
+package controlplane
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kubiyabot/cli/internal/controlplane/entities"
+)
+
+// StatusError is an API response with an unexpected status code
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// WhoAmI returns the identity of the API key. A rejected key is reported as
+// a *StatusError with status 401 or 403.
+func (c *Client) WhoAmI() (*entities.Identity, error) {
+	resp, err := c.DoRequest(http.MethodGet, "/api/v1/auth/whoami", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var identity entities.Identity
+	if err := c.ParseResponse(resp, &identity); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
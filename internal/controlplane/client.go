@@ -9,6 +9,9 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // Client represents a control plane API client
@@ -37,9 +40,7 @@ func NewWithURL(apiKey, baseURL string, debug bool) (*Client, error) {
 		baseURL = getBaseURL()
 	}
 
-	httpClient := &http.Client{
-		Timeout: 180 * time.Second,
-	}
+	httpClient := httpclient.New(180 * time.Second)
 
 	client := &Client{
 		APIKey:     apiKey,
@@ -102,6 +103,7 @@ func (c *Client) DoRequest(method, path string, body interface{}) (*http.Respons
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	correlation.Set(req)
 
 	if c.Debug {
 		fmt.Printf("[DEBUG] %s %s\n", method, fullURL)
@@ -147,7 +149,7 @@ func (c *Client) ParseResponse(resp *http.Response, target interface{}) error {
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	if target != nil && len(bodyBytes) > 0 {
@@ -303,3 +305,19 @@ func (c *Client) GetClientConfig() (*ClientConfig, error) {
 	}
 	return &config, nil
 }
+
+// CLIVersionPolicy describes the CLI versions supported by the control plane
+type CLIVersionPolicy struct {
+	MinimumVersion     string `json:"minimum_version"`
+	RecommendedVersion string `json:"recommended_version"`
+}
+
+// GetCLIVersionPolicy fetches the minimum and recommended CLI versions
+func (c *Client) GetCLIVersionPolicy() (*CLIVersionPolicy, error) {
+	var policy CLIVersionPolicy
+	err := c.get("/api/v1/client/cli-version", &policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CLI version policy: %w", err)
+	}
+	return &policy, nil
+}
@@ -0,0 +1,12 @@
+package entities
+
+// Identity is the user and organization an API key authenticates as
+type Identity struct {
+	UserID           string   `json:"user_id"`
+	Email            string   `json:"email,omitempty"`
+	Name             string   `json:"name,omitempty"`
+	OrganizationID   string   `json:"organization_id,omitempty"`
+	OrganizationName string   `json:"organization_name,omitempty"`
+	Roles            []string `json:"roles,omitempty"`
+	Permissions      []string `json:"permissions,omitempty"`
+}
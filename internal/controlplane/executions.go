@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/kubiyabot/cli/internal/controlplane/entities"
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // ExecuteAgentV2 creates a new agent execution (V2 API)
@@ -47,7 +49,7 @@ func (c *Client) GetExecution(id string) (*entities.AgentExecution, error) {
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Try to unmarshal as a single object first (newer API format)
@@ -219,6 +221,7 @@ func (c *Client) streamWithReconnect(ctx context.Context, executionID, lastEvent
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	correlation.Set(req)
 
 	// Set Last-Event-ID for resumption if we have one
 	if lastEventID != "" {
@@ -226,9 +229,7 @@ func (c *Client) streamWithReconnect(ctx context.Context, executionID, lastEvent
 	}
 
 	// Use a client with no timeout for SSE streaming
-	streamClient := &http.Client{
-		Timeout: 0, // No timeout for SSE streams
-	}
+	streamClient := httpclient.New(0) // No timeout for SSE streams
 	resp, err := streamClient.Do(req)
 	if err != nil {
 		return false, lastEventID, fmt.Errorf("failed to connect to stream: %w", err)
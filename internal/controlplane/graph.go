@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/kubiyabot/cli/internal/correlation"
 )
 
 // GraphNode represents a node in the context graph
@@ -414,6 +416,7 @@ func (c *Client) IntelligentSearchStream(req *IntelligentSearchRequest) (<-chan
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	correlation.Set(httpReq)
 	httpReq.Header.Set("Cache-Control", "no-cache")
 	httpReq.Header.Set("Connection", "keep-alive")
 
@@ -0,0 +1,52 @@
+// Package correlation identifies the API calls made by one CLI invocation.
+// The ID is sent with every call to the Kubiya API and passed on to the
+// runners with tool executions, so that a failure reported with it can be
+// found in the server-side logs and events.
+package correlation
+
+import (
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header carries the correlation ID of a request
+	Header = "X-Correlation-ID"
+	// EnvVar sets the correlation ID instead of generating one, so that
+	// scripts can tie several invocations together
+	EnvVar = "KUBIYA_CORRELATION_ID"
+)
+
+var (
+	once sync.Once
+	id   string
+	used atomic.Bool
+)
+
+// ID returns the correlation ID of this invocation
+func ID() string {
+	once.Do(func() {
+		id = os.Getenv(EnvVar)
+		if id == "" {
+			id = uuid.New().String()
+		}
+	})
+	return id
+}
+
+// Set adds the correlation ID to req, unless it carries one already
+func Set(req *http.Request) {
+	if req.Header.Get(Header) == "" {
+		req.Header.Set(Header, ID())
+	}
+	used.Store(true)
+}
+
+// Used reports whether the correlation ID was sent with any request
+func Used() bool {
+	return used.Load()
+}
@@ -0,0 +1,28 @@
+package correlation
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	if ID() == "" || ID() != ID() {
+		t.Fatalf("expected a stable ID, got %q", ID())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.kubiya.ai/api/v1/agents", nil)
+	Set(req)
+	if got := req.Header.Get(Header); got != ID() {
+		t.Errorf("expected header %q, got %q", ID(), got)
+	}
+	if !Used() {
+		t.Error("expected the ID to be marked as used")
+	}
+
+	// An ID set by the caller is kept
+	req.Header.Set(Header, "from-caller")
+	Set(req)
+	if got := req.Header.Get(Header); got != "from-caller" {
+		t.Errorf("expected the caller's ID to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Package docload decodes the documents users hand to the CLI (agent specs,
+// tool lists, webhooks, MCP configurations) whether they are written in
+// JSON or YAML. The format is detected from the content, never from the
+// file extension, and YAML is decoded through JSON so the json tags of the
+// target types apply to both formats.
+package docload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of a document
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// Format returns JSON when data is a JSON document and YAML otherwise
+func Format(data []byte) string {
+	data = trimBOM(data)
+	if LooksLikeJSON(data) && json.Valid(data) {
+		return JSON
+	}
+	return YAML
+}
+
+// LooksLikeJSON reports whether the first significant character of data
+// opens a JSON object or array, whether or not the rest is valid
+func LooksLikeJSON(data []byte) bool {
+	data = bytes.TrimSpace(trimBOM(data))
+	return len(data) > 0 && (data[0] == '{' || data[0] == '[')
+}
+
+// ToJSON returns data as JSON. YAML documents are converted; a stream of
+// several YAML documents becomes a JSON array of them.
+func ToJSON(data []byte) ([]byte, error) {
+	data = trimBOM(data)
+	if Format(data) == JSON {
+		return data, nil
+	}
+	if LooksLikeJSON(data) {
+		// JSON with a syntax error is far more likely than YAML flow style,
+		// so report the JSON error, which is the more helpful one
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, jsonError(data, err)
+		}
+	}
+
+	var docs []interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+		if doc != nil {
+			docs = append(docs, normalize(doc))
+		}
+	}
+
+	var v interface{}
+	switch len(docs) {
+	case 0:
+	case 1:
+		v = docs[0]
+	default:
+		v = docs
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
+	}
+	return out, nil
+}
+
+// Unmarshal decodes a JSON or YAML document into v using the json tags of v
+func Unmarshal(data []byte, v interface{}) error {
+	jsonData, err := ToJSON(data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(jsonData, v); err != nil {
+		// The document is well formed, so this is a type mismatch
+		return fmt.Errorf("invalid document: %w", err)
+	}
+	return nil
+}
+
+// UnmarshalFormat decodes data like Unmarshal, but requires it to be in
+// format when format is JSON. An empty format or YAML accepts both, since
+// JSON is valid YAML.
+func UnmarshalFormat(data []byte, format string, v interface{}) error {
+	switch strings.ToLower(format) {
+	case "", "auto", YAML, "yml":
+		return Unmarshal(data, v)
+	case JSON:
+		data = trimBOM(data)
+		if err := json.Unmarshal(data, v); err != nil {
+			return jsonError(data, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (use json or yaml)", format)
+	}
+}
+
+// trimBOM removes the UTF-8 byte order mark some editors write
+func trimBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+}
+
+// jsonError adds the line of syntax errors to err
+func jsonError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line := 1 + bytes.Count(data[:min(int(syntaxErr.Offset), len(data))], []byte("\n"))
+		return fmt.Errorf("invalid JSON at line %d: %w", line, err)
+	}
+	return fmt.Errorf("invalid JSON: %w", err)
+}
+
+// normalize converts the maps yaml.v3 decodes with non-string keys into
+// maps with string keys, which JSON requires
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[fmt.Sprint(k)] = normalize(val)
+		}
+		return out
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	}
+	return v
+}
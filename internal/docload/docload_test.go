@@ -0,0 +1,104 @@
+package docload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tool struct {
+	Name    string   `json:"name"`
+	IconURL string   `json:"icon_url"`
+	Env     []string `json:"env"`
+	Args    []struct {
+		Name     string `json:"name"`
+		Required bool   `json:"required"`
+	} `json:"args"`
+}
+
+func TestFormat(t *testing.T) {
+	for input, want := range map[string]string{
+		`{"name": "a"}`:             JSON,
+		"\n  [1, 2]\n":              JSON,
+		"\xef\xbb\xbf{}":            JSON,
+		"name: a\n":                 YAML,
+		"# comment\n{\"a\": 1}":     YAML,
+		"[a, b]":                    YAML,
+		"- name: a\n- name: b\n":    YAML,
+		"tools.json":                YAML,
+		"":                          YAML,
+		`{"name": "a", "icon": }`:   YAML,
+		"---\nname: a\n---\nb: 1\n": YAML,
+	} {
+		assert.Equal(t, want, Format([]byte(input)), "%q", input)
+	}
+}
+
+func TestUnmarshalMixedInputs(t *testing.T) {
+	inputs := map[string]string{
+		"json": `[{"name": "deploy", "icon_url": "https://x/i.png", "env": ["A"], "args": [{"name": "app", "required": true}]}]`,
+		"yaml": `
+# Tools of the deploy source
+- name: deploy
+  icon_url: https://x/i.png
+  env: [A]
+  args:
+    - name: app
+      required: true
+`,
+		"yaml with JSON flow": `- {"name": "deploy", "icon_url": "https://x/i.png", "env": ["A"], "args": [{"name": "app", "required": true}]}`,
+		"multi document":      "name: deploy\nicon_url: https://x/i.png\nenv: [A]\nargs: [{name: app, required: true}]\n---\n",
+	}
+	for name, input := range inputs {
+		var tools []tool
+		if name == "multi document" {
+			var single tool
+			require.NoError(t, Unmarshal([]byte(input), &single), name)
+			tools = []tool{single}
+		} else {
+			require.NoError(t, Unmarshal([]byte(input), &tools), name)
+		}
+		require.Len(t, tools, 1, name)
+		assert.Equal(t, "deploy", tools[0].Name, name)
+		assert.Equal(t, "https://x/i.png", tools[0].IconURL, "%s: json tags apply to YAML", name)
+		assert.Equal(t, []string{"A"}, tools[0].Env, name)
+		require.Len(t, tools[0].Args, 1, name)
+		assert.True(t, tools[0].Args[0].Required, name)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	out, err := ToJSON([]byte("a: 1\n---\nb: [x]\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"a": 1}, {"b": ["x"]}]`, string(out))
+
+	out, err = ToJSON([]byte("1: one\ntrue: yes\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"1": "one", "true": "yes"}`, string(out))
+
+	out, err = ToJSON([]byte("# nothing here\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(out))
+}
+
+func TestErrors(t *testing.T) {
+	var v interface{}
+	err := Unmarshal([]byte("{\n  \"name\": \"a\",\n  \"env\": [\n}"), &v)
+	assert.ErrorContains(t, err, "invalid JSON at line 4")
+
+	err = Unmarshal([]byte("name: a\n  env: b\n"), &v)
+	assert.ErrorContains(t, err, "invalid YAML: line 2")
+
+	var tools []tool
+	err = Unmarshal([]byte("name: a\n"), &tools)
+	assert.ErrorContains(t, err, "invalid document")
+
+	err = UnmarshalFormat([]byte("name: a\n"), JSON, &v)
+	assert.ErrorContains(t, err, "invalid JSON at line 1")
+	assert.ErrorContains(t, UnmarshalFormat([]byte("{}"), "toml", &v), "unsupported format")
+
+	var single tool
+	require.NoError(t, UnmarshalFormat([]byte(`{"name": "a"}`), YAML, &single))
+	assert.Equal(t, "a", single.Name)
+}
@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is what Decompress negotiates
+const acceptEncoding = "gzip, deflate"
+
+// Decompress wraps next to ask for gzip or deflate compressed responses and
+// hand them over decompressed, as they arrive, which suits long-lived
+// event streams. Requests that set Accept-Encoding themselves are left alone,
+// and nothing is negotiated when compression is disabled.
+func Decompress(next http.RoundTripper) http.RoundTripper {
+	return &decompressor{next: next}
+}
+
+type decompressor struct {
+	next http.RoundTripper
+}
+
+func (d *decompressor) RoundTrip(req *http.Request) (*http.Response, error) {
+	mu.Lock()
+	enabled := compress
+	mu.Unlock()
+	if !enabled || req.Header.Get("Accept-Encoding") != "" || req.Method == http.MethodHead {
+		return d.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	resp, err := d.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := decodeBody(resp.Header.Get("Content-Encoding"), resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if body != resp.Body {
+		resp.Body = body
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	return resp, nil
+}
+
+// decodeBody returns body decoded according to encoding, body itself when
+// it is not encoded
+func decodeBody(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		// The gzip header is only read with the first data, which may take
+		// long on an event stream
+		return &lazyReadCloser{body: body, open: func() (io.Reader, error) { return gzip.NewReader(body) }}, nil
+	case "deflate":
+		return &lazyReadCloser{body: body, open: func() (io.Reader, error) { return newDeflateReader(body) }}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+}
+
+// newDeflateReader reads deflate content, which servers send either zlib
+// wrapped as the specification says or raw
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// lazyReadCloser opens its decoder on the first Read
+type lazyReadCloser struct {
+	body   io.ReadCloser
+	open   func() (io.Reader, error)
+	reader io.Reader
+}
+
+func (l *lazyReadCloser) Read(p []byte) (int, error) {
+	if l.reader == nil {
+		r, err := l.open()
+		if err != nil {
+			return 0, err
+		}
+		l.reader = r
+	}
+	return l.reader.Read(p)
+}
+
+func (l *lazyReadCloser) Close() error {
+	return l.body.Close()
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// flushWriter is a compressing writer that can push what it has out
+type flushWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func TestDecompressStreams(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		wrap     func(io.Writer) flushWriter
+	}{
+		{"gzip", "gzip", func(w io.Writer) flushWriter { return gzip.NewWriter(w) }},
+		{"zlib deflate", "deflate", func(w io.Writer) flushWriter { return zlib.NewWriter(w) }},
+		{"raw deflate", "deflate", func(w io.Writer) flushWriter { fw, _ := flate.NewWriter(w, flate.DefaultCompression); return fw }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept-Encoding"); got != acceptEncoding {
+					t.Errorf("Accept-Encoding = %q, want %q", got, acceptEncoding)
+				}
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Header().Set("Content-Encoding", tt.encoding)
+				cw := tt.wrap(w)
+				io.WriteString(cw, "data: first\n\n")
+				cw.Flush()
+				w.(http.Flusher).Flush()
+				<-release
+				io.WriteString(cw, "data: second\n\n")
+				cw.Close()
+			}))
+			defer server.Close()
+			defer close(release)
+
+			client := &http.Client{Transport: Decompress(Transport()), Timeout: 5 * time.Second}
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.Header.Get("Content-Encoding") != "" {
+				t.Error("expected Content-Encoding to be removed from the decompressed response")
+			}
+
+			// The first event is readable before the stream ends
+			line, err := bufio.NewReader(resp.Body).ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line != "data: first\n" {
+				t.Errorf("first line = %q", line)
+			}
+		})
+	}
+}
+
+func TestDecompressLeavesExplicitEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get("Accept-Encoding"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept-Encoding", "identity")
+	resp, err := Decompress(Transport()).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "identity" {
+		t.Errorf("Accept-Encoding = %q, want identity", body)
+	}
+}
+
+func TestDecompressUnsupportedEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		io.WriteString(w, "compressed")
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := Decompress(Transport()).RoundTrip(req); err == nil {
+		t.Fatal("expected an error for an unsupported Content-Encoding")
+	}
+}
@@ -0,0 +1,237 @@
+// Package httpclient builds the HTTP clients used by the CLI. All clients
+// honor the proxy environment variables (HTTPS_PROXY, HTTP_PROXY, NO_PROXY),
+// trust the CAs of an optional bundle in addition to the system pool and can
+// present a client certificate for mutual TLS.
+//
+// Connections use HTTP/2 when the server supports it and are probed while
+// idle, with TCP keep-alives and HTTP/2 pings, so that a connection that died
+// silently, as behind a flaky VPN, fails instead of hanging.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultKeepAlive is the idle time before TCP keep-alive probes, and
+	// the time between them
+	DefaultKeepAlive = 15 * time.Second
+	// DefaultPingInterval is the idle time before an HTTP/2 connection is
+	// pinged; it is closed when the ping is not answered within half of it
+	DefaultPingInterval = 30 * time.Second
+	// keepAliveProbes are the unanswered probes after which a connection is
+	// dropped
+	keepAliveProbes = 4
+)
+
+// Options configures TLS and connections for all clients built by this
+// package
+type Options struct {
+	CABundle   string // PEM file with CAs trusted in addition to the system pool
+	ClientCert string // PEM client certificate presented for mutual TLS
+	ClientKey  string // PEM private key of ClientCert
+
+	DisableHTTP2       bool          // Only speak HTTP/1.1
+	DisableCompression bool          // Do not ask for compressed responses
+	KeepAlive          time.Duration // DefaultKeepAlive when zero
+	PingInterval       time.Duration // DefaultPingInterval when zero
+}
+
+// OptionsFromEnv reads KUBIYA_CA_BUNDLE, KUBIYA_CLIENT_CERT,
+// KUBIYA_CLIENT_KEY, KUBIYA_HTTP2, KUBIYA_HTTP_COMPRESSION, KUBIYA_KEEPALIVE
+// and KUBIYA_HTTP2_PING_INTERVAL. Invalid values are ignored with a warning.
+func OptionsFromEnv() Options {
+	return Options{
+		CABundle:           os.Getenv("KUBIYA_CA_BUNDLE"),
+		ClientCert:         os.Getenv("KUBIYA_CLIENT_CERT"),
+		ClientKey:          os.Getenv("KUBIYA_CLIENT_KEY"),
+		DisableHTTP2:       !envBool("KUBIYA_HTTP2", true),
+		DisableCompression: !envBool("KUBIYA_HTTP_COMPRESSION", true),
+		KeepAlive:          envDuration("KUBIYA_KEEPALIVE"),
+		PingInterval:       envDuration("KUBIYA_HTTP2_PING_INTERVAL"),
+	}
+}
+
+func envBool(name string, def bool) bool {
+	val := os.Getenv(name)
+	if val == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v\n", name, err)
+		return def
+	}
+	return parsed
+}
+
+func envDuration(name string) time.Duration {
+	val := os.Getenv(name)
+	if val == "" {
+		return 0
+	}
+	parsed, err := time.ParseDuration(val)
+	if err != nil || parsed <= 0 {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s: expected a positive duration such as 30s\n", name)
+		return 0
+	}
+	return parsed
+}
+
+var (
+	mu        sync.Mutex
+	transport *http.Transport
+	// configErr is why the options last passed to Configure are invalid
+	configErr error
+	// compress is whether Decompress asks for compressed responses
+	compress = true
+)
+
+// Configure rebuilds the shared transport from opts. Clients created before
+// keep their transport. When opts are invalid the error is returned and the
+// clients built afterwards fail every request with it, so that only the
+// commands making requests fail.
+func Configure(opts Options) error {
+	t, err := newTransport(opts)
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		configErr = fmt.Errorf("invalid TLS configuration: %w", err)
+		return err
+	}
+	configErr = nil
+	transport = t
+	compress = !opts.DisableCompression
+	return nil
+}
+
+// Transport returns the shared transport. Until Configure is called it is
+// built from OptionsFromEnv, falling back to the system defaults when those
+// options are invalid. After Configure failed, it fails every request.
+func Transport() http.RoundTripper {
+	mu.Lock()
+	defer mu.Unlock()
+	if configErr != nil {
+		return errorTransport{configErr}
+	}
+	if transport == nil {
+		opts := OptionsFromEnv()
+		t, err := newTransport(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring TLS settings: %v\n", err)
+			opts.CABundle, opts.ClientCert, opts.ClientKey = "", "", ""
+			t, _ = newTransport(opts)
+		}
+		transport = t
+		compress = !opts.DisableCompression
+	}
+	return transport
+}
+
+// errorTransport fails every request with err
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// New returns a client using the shared transport. A zero timeout means no
+// timeout, as for streaming connections.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport()}
+}
+
+// Default returns a client without timeout, replacing http.DefaultClient
+func Default() *http.Client {
+	return New(0)
+}
+
+// Get is http.Get using the shared transport
+func Get(url string) (*http.Response, error) {
+	return Default().Get(url)
+}
+
+func newTransport(opts Options) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	configureConnections(t, opts)
+
+	if opts.CABundle == "" && opts.ClientCert == "" && opts.ClientKey == "" {
+		return t, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opts.CABundle != "" {
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", opts.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		if opts.ClientCert == "" || opts.ClientKey == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required for mTLS")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// configureConnections sets up keep-alive probes and HTTP/2 on t
+func configureConnections(t *http.Transport, opts Options) {
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		KeepAliveConfig: net.KeepAliveConfig{
+			Enable:   true,
+			Idle:     keepAlive,
+			Interval: keepAlive,
+			Count:    keepAliveProbes,
+		},
+	}
+	t.DialContext = dialer.DialContext
+
+	if opts.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP1(true)
+		return
+	}
+	ping := opts.PingInterval
+	if ping <= 0 {
+		ping = DefaultPingInterval
+	}
+	t.ForceAttemptHTTP2 = true
+	t.HTTP2 = &http.HTTP2Config{SendPingTimeout: ping, PingTimeout: ping / 2}
+}
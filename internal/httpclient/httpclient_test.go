@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Configure(Options{}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if _, err := New(5 * time.Second).Get(server.URL); err == nil {
+		t.Fatal("expected the test server certificate to be untrusted without a CA bundle")
+	}
+
+	if err := Configure(Options{CABundle: bundle}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	defer Configure(Options{})
+
+	resp, err := New(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the CA bundle to be trusted: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestConfigureErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"missing bundle", Options{CABundle: filepath.Join(dir, "missing.pem")}},
+		{"bundle without certificates", Options{CABundle: empty}},
+		{"certificate without key", Options{ClientCert: empty}},
+		{"unreadable key pair", Options{ClientCert: empty, ClientKey: empty}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Configure(tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestInvalidConfigurationFailsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := Configure(Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Fatal("expected an error")
+	}
+	_, err := New(5 * time.Second).Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "invalid TLS configuration") {
+		t.Fatalf("expected requests to fail with the configuration error, got %v", err)
+	}
+
+	if err := Configure(Options{}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	resp, err := New(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected a valid configuration to replace the invalid one: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportHonorsProxyEnvironment(t *testing.T) {
+	if err := Configure(Options{}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if Transport().(*http.Transport).Proxy == nil {
+		t.Error("expected the transport to read proxies from the environment")
+	}
+}
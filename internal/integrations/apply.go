@@ -0,0 +1,263 @@
+package integrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Applier applies one part of a template, such as its files or its
+// environment, to a tool definition
+type Applier interface {
+	Apply(toolDef map[string]interface{}, t Template) error
+}
+
+// ApplierFunc is a function used as an Applier
+type ApplierFunc func(toolDef map[string]interface{}, t Template) error
+
+// Apply calls f
+func (f ApplierFunc) Apply(toolDef map[string]interface{}, t Template) error {
+	return f(toolDef, t)
+}
+
+// list returns the list at key of toolDef, which holds []interface{} when
+// decoded from JSON and may hold []string when built in code
+func list(toolDef map[string]interface{}, key string) []interface{} {
+	switch v := toolDef[key].(type) {
+	case []interface{}:
+		return v
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	}
+	return []interface{}{}
+}
+
+// applyImage sets the default image of the template unless the tool has
+// one
+func applyImage(toolDef map[string]interface{}, t Template) error {
+	if t.DefaultImage == "" {
+		return nil
+	}
+	if image, _ := toolDef["image"].(string); image == "" {
+		toolDef["image"] = t.DefaultImage
+	}
+	return nil
+}
+
+// applyContent wraps the content of the tool with the before and after
+// scripts of the template
+func applyContent(toolDef map[string]interface{}, t Template) error {
+	originalContent, _ := toolDef["content"].(string)
+	if originalContent == "" {
+		return nil
+	}
+
+	var wrappedContent strings.Builder
+	// Add shebang if not present
+	if !strings.HasPrefix(strings.TrimSpace(originalContent), "#!") {
+		wrappedContent.WriteString("#!/bin/bash\nset -e\n\n")
+	}
+	if t.BeforeScript != "" {
+		wrappedContent.WriteString("# Integration setup\n")
+		wrappedContent.WriteString(t.BeforeScript)
+		wrappedContent.WriteString("\n\n# User script\n")
+	}
+	wrappedContent.WriteString(originalContent)
+	if t.AfterScript != "" {
+		wrappedContent.WriteString("\n\n# Integration cleanup\n")
+		wrappedContent.WriteString(t.AfterScript)
+	}
+
+	toolDef["content"] = wrappedContent.String()
+	return nil
+}
+
+// applyEnv adds the environment variables of the template, expanded with
+// the local environment
+func applyEnv(toolDef map[string]interface{}, t Template) error {
+	if len(t.EnvVars) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(t.EnvVars))
+	for k := range t.EnvVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := list(toolDef, "env")
+	for _, k := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", k, ExpandEnv(t.EnvVars[k])))
+	}
+	toolDef["env"] = env
+	return nil
+}
+
+// applyFiles adds the file mappings of the template, with the content of
+// local source files inlined
+func applyFiles(toolDef map[string]interface{}, t Template) error {
+	if len(t.WithFiles) == 0 {
+		return nil
+	}
+	withFiles := list(toolDef, "with_files")
+	for _, file := range t.WithFiles {
+		source := ExpandPath(ExpandEnv(file.Source))
+		fileMap := map[string]interface{}{
+			"source":      source,
+			"destination": ExpandPath(ExpandEnv(file.Destination)),
+		}
+		if file.Content != "" {
+			fileMap["content"] = file.Content
+		} else if IsLocalPath(source) {
+			if content, err := os.ReadFile(source); err == nil {
+				fileMap["content"] = string(content)
+			}
+		}
+		withFiles = append(withFiles, fileMap)
+	}
+	toolDef["with_files"] = withFiles
+	return nil
+}
+
+// applyVolumes adds the volume mappings of the template
+func applyVolumes(toolDef map[string]interface{}, t Template) error {
+	if len(t.WithVolumes) == 0 {
+		return nil
+	}
+	withVolumes := list(toolDef, "with_volumes")
+	for _, vol := range t.WithVolumes {
+		volumeMap := map[string]interface{}{
+			"source":      ExpandPath(ExpandEnv(vol.Source)),
+			"destination": ExpandPath(ExpandEnv(vol.Destination)),
+		}
+		if vol.ReadOnly {
+			volumeMap["read_only"] = true
+		}
+		withVolumes = append(withVolumes, volumeMap)
+	}
+	toolDef["with_volumes"] = withVolumes
+	return nil
+}
+
+// applyServices adds the sidecar services of the template
+func applyServices(toolDef map[string]interface{}, t Template) error {
+	if len(t.WithServices) == 0 {
+		return nil
+	}
+	withServices := list(toolDef, "with_services")
+	for _, service := range t.WithServices {
+		withServices = append(withServices, service)
+	}
+	toolDef["with_services"] = withServices
+	return nil
+}
+
+// applyPackages prepends the installation of the packages the template
+// requires to the content, for Alpine and Debian based default images
+func applyPackages(toolDef map[string]interface{}, t Template) error {
+	if len(t.RequiredPackages) == 0 || t.DefaultImage == "" {
+		return nil
+	}
+	content, _ := toolDef["content"].(string)
+	packages := strings.Join(t.RequiredPackages, " ")
+
+	switch {
+	case strings.Contains(t.DefaultImage, "alpine"):
+		installCmd := "apk add --no-cache " + packages
+		if !strings.Contains(content, installCmd) {
+			toolDef["content"] = fmt.Sprintf("#!/bin/sh\n%s\n\n%s", installCmd, content)
+		}
+	case strings.Contains(t.DefaultImage, "ubuntu") || strings.Contains(t.DefaultImage, "debian"):
+		installCmd := "apt-get update && apt-get install -y " + packages
+		if !strings.Contains(content, installCmd) {
+			toolDef["content"] = fmt.Sprintf("#!/bin/bash\n%s\n\n%s", installCmd, content)
+		}
+	}
+	return nil
+}
+
+// applySecrets adds the secrets of the template
+func applySecrets(toolDef map[string]interface{}, t Template) error {
+	if len(t.Secrets) == 0 {
+		return nil
+	}
+	secrets := list(toolDef, "secrets")
+	for _, secret := range t.Secrets {
+		secrets = append(secrets, secret)
+	}
+	toolDef["secrets"] = secrets
+	return nil
+}
+
+// applyConfig sets the additional tool properties of the template that the
+// tool doesn't set
+func applyConfig(toolDef map[string]interface{}, t Template) error {
+	for k, v := range t.Config {
+		if _, exists := toolDef[k]; !exists {
+			toolDef[k] = v
+		}
+	}
+	return nil
+}
+
+// ExpandEnv replaces $VAR and ${VAR} in s with the local environment, and
+// ${VAR:-default} with default when VAR is unset or empty
+func ExpandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name, def, ok := strings.Cut(name, ":-"); ok {
+			if v := os.Getenv(name); v != "" {
+				return v
+			}
+			return def
+		}
+		return os.Getenv(name)
+	})
+}
+
+// ExpandPath expands ~ to the home directory
+func ExpandPath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(homeDir, path[2:])
+		}
+	}
+	return path
+}
+
+// IsLocalPath checks if a path refers to a local file
+func IsLocalPath(path string) bool {
+	// Paths that start with these are typically remote/container paths
+	remotePathPrefixes := []string{
+		"/var/run/",
+		"/tmp/kubernetes",
+		"/etc/",
+		"/opt/",
+		"/usr/",
+		"/sys/",
+		"/proc/",
+	}
+
+	for _, prefix := range remotePathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+
+	// Check if path starts with $HOME or ~ (local user paths)
+	if strings.HasPrefix(path, "$HOME") || strings.HasPrefix(path, "~") {
+		return true
+	}
+
+	// Check if file exists locally
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+
+	return false
+}
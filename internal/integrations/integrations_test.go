@@ -0,0 +1,126 @@
+package integrations
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryGetResolvesAliases(t *testing.T) {
+	r := NewRegistry()
+
+	tmpl, ok := r.Get("k8s/incluster")
+	require.True(t, ok)
+	assert.Equal(t, "kubernetes/incluster", tmpl.Name)
+
+	_, ok = r.Get("k8s/unknown")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"aws/creds"}, r.Aliases("aws/cli"))
+	assert.Contains(t, r.Names(), "database/postgres")
+	assert.Equal(t, len(r.Names()), len(r.List()))
+}
+
+func TestApplyKubernetesInCluster(t *testing.T) {
+	toolDef := map[string]interface{}{
+		"name":    "pods",
+		"content": "kubectl get pods",
+		"env":     []string{"NAMESPACE=default"},
+	}
+	require.NoError(t, NewRegistry().Apply(toolDef, "kubernetes/incluster"))
+
+	assert.Equal(t, "bitnami/kubectl:latest", toolDef["image"])
+	assert.Equal(t, []interface{}{
+		"NAMESPACE=default",
+		"KUBERNETES_SERVICE_HOST=kubernetes.default.svc",
+		"KUBERNETES_SERVICE_PORT=443",
+	}, toolDef["env"])
+
+	files := toolDef["with_files"].([]interface{})
+	require.Len(t, files, 2)
+	token := files[0].(map[string]interface{})
+	assert.Equal(t, "/var/run/secrets/kubernetes.io/serviceaccount/token", token["source"])
+	assert.NotContains(t, token, "content", "in-cluster paths are not read locally")
+
+	content := toolDef["content"].(string)
+	assert.True(t, strings.HasPrefix(content, "#!/bin/bash\nset -e\n"))
+	assert.Contains(t, content, "kubectl config use-context in-cluster")
+	assert.True(t, strings.HasSuffix(content, "# User script\nkubectl get pods"))
+}
+
+func TestApplyKeepsToolImageAndAddsServices(t *testing.T) {
+	t.Setenv("PGUSER", "app")
+	toolDef := map[string]interface{}{"image": "acme/migrate:1", "content": "migrate up"}
+	require.NoError(t, NewRegistry().Apply(toolDef, "postgres"))
+
+	assert.Equal(t, "acme/migrate:1", toolDef["image"])
+	assert.Equal(t, []interface{}{"postgres:15"}, toolDef["with_services"])
+	env := toolDef["env"].([]interface{})
+	assert.Contains(t, env, "PGUSER=app")
+	assert.Contains(t, env, "PGHOST=postgres")
+}
+
+func TestApplyUnknownIntegration(t *testing.T) {
+	err := NewRegistry().Apply(map[string]interface{}{}, "aws/cli", "vault/token")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"vault/token" not found`)
+	assert.Contains(t, err.Error(), "aws/cli")
+}
+
+func TestRegisterApplier(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterApplier("content", ApplierFunc(func(toolDef map[string]interface{}, tmpl Template) error {
+		toolDef["content"] = tmpl.Name + ": " + toolDef["content"].(string)
+		return nil
+	}))
+	r.RegisterApplier("labels", ApplierFunc(func(toolDef map[string]interface{}, tmpl Template) error {
+		toolDef["labels"] = []string{tmpl.Type}
+		return nil
+	}))
+
+	toolDef := map[string]interface{}{"content": "aws s3 ls"}
+	require.NoError(t, r.Apply(toolDef, "aws/env"))
+	assert.Equal(t, "aws/env: aws s3 ls", toolDef["content"])
+	assert.Equal(t, []string{"aws"}, toolDef["labels"])
+}
+
+func TestLoadCustomTemplates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(home, CustomFile), []byte(`{
+  "vault/token": {"type": "vault", "env_vars": {"VAULT_ADDR": "https://vault:8200"}, "secrets": ["VAULT_TOKEN"]},
+  "aws/cli": {"type": "aws", "description": "Company AWS", "default_image": "acme/aws:2"}
+}`), 0600))
+
+	r, err := Load(fs)
+	require.NoError(t, err)
+
+	vault, ok := r.Get("vault/token")
+	require.True(t, ok)
+	assert.Equal(t, "vault/token", vault.Name)
+
+	toolDef := map[string]interface{}{}
+	require.NoError(t, r.Apply(toolDef, "vault/token", "aws/creds"))
+	assert.Equal(t, []interface{}{"VAULT_TOKEN"}, toolDef["secrets"])
+	assert.Equal(t, "acme/aws:2", toolDef["image"], "custom templates replace built-in ones")
+
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(home, CustomFile), []byte("{"), 0600))
+	_, err = Load(fs)
+	assert.Error(t, err)
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("REGION", "eu-west-1")
+	t.Setenv("EMPTY", "")
+
+	assert.Equal(t, "eu-west-1", ExpandEnv("${REGION:-us-east-1}"))
+	assert.Equal(t, "default", ExpandEnv("${EMPTY:-default}"))
+	assert.Equal(t, "default", ExpandEnv("${UNSET_FOR_TEST:-default}"))
+	assert.Equal(t, "eu-west-1/x", ExpandEnv("$REGION/x"))
+}
@@ -0,0 +1,161 @@
+package integrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// CustomFile is where user-defined templates are read from, relative to
+// the home directory. It holds a JSON object of templates by name.
+const CustomFile = ".kubiya/tool-integrations.json"
+
+// Registry holds the integration templates and the appliers that apply
+// them to tool definitions
+type Registry struct {
+	templates map[string]Template
+	aliases   map[string]string
+	appliers  []namedApplier
+}
+
+type namedApplier struct {
+	name    string
+	applier Applier
+}
+
+// NewRegistry returns a registry with the built-in templates and appliers
+func NewRegistry() *Registry {
+	r := &Registry{templates: make(map[string]Template), aliases: make(map[string]string)}
+	for name, t := range builtin {
+		r.Register(name, t)
+	}
+	for alias, name := range aliases {
+		r.aliases[alias] = name
+	}
+	r.RegisterApplier("image", ApplierFunc(applyImage))
+	r.RegisterApplier("content", ApplierFunc(applyContent))
+	r.RegisterApplier("env", ApplierFunc(applyEnv))
+	r.RegisterApplier("files", ApplierFunc(applyFiles))
+	r.RegisterApplier("volumes", ApplierFunc(applyVolumes))
+	r.RegisterApplier("services", ApplierFunc(applyServices))
+	r.RegisterApplier("packages", ApplierFunc(applyPackages))
+	r.RegisterApplier("secrets", ApplierFunc(applySecrets))
+	r.RegisterApplier("config", ApplierFunc(applyConfig))
+	return r
+}
+
+// Load returns a registry with the built-in templates and those of the
+// user's CustomFile, which take precedence
+func Load(fs afero.Fs) (*Registry, error) {
+	r := NewRegistry()
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return r, nil
+	}
+	data, err := afero.ReadFile(fs, filepath.Join(homeDir, CustomFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+
+	var custom map[string]Template
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(CustomFile), err)
+	}
+	for name, t := range custom {
+		r.Register(name, t)
+	}
+	return r, nil
+}
+
+// Register adds or replaces a template
+func (r *Registry) Register(name string, t Template) {
+	if t.Name == "" {
+		t.Name = name
+	}
+	r.templates[name] = t
+}
+
+// RegisterApplier adds an applier, run after those registered before it,
+// or replaces the applier registered as name
+func (r *Registry) RegisterApplier(name string, a Applier) {
+	for i := range r.appliers {
+		if r.appliers[i].name == name {
+			r.appliers[i].applier = a
+			return
+		}
+	}
+	r.appliers = append(r.appliers, namedApplier{name: name, applier: a})
+}
+
+// Get returns the template registered as name or one of its aliases
+func (r *Registry) Get(name string) (Template, bool) {
+	if t, ok := r.templates[name]; ok {
+		return t, true
+	}
+	t, ok := r.templates[r.aliases[name]]
+	return t, ok
+}
+
+// Names returns the names of the registered templates, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// List returns the registered templates, sorted by name
+func (r *Registry) List() []Template {
+	list := make([]Template, 0, len(r.templates))
+	for _, name := range r.Names() {
+		list = append(list, r.templates[name])
+	}
+	return list
+}
+
+// Aliases returns the aliases of the template registered as name, sorted
+func (r *Registry) Aliases(name string) []string {
+	var out []string
+	for alias, target := range r.aliases {
+		if target == name {
+			out = append(out, alias)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Apply applies the named templates to toolDef in order
+func (r *Registry) Apply(toolDef map[string]interface{}, names ...string) error {
+	for _, name := range names {
+		t, ok := r.Get(name)
+		if !ok {
+			return fmt.Errorf("integration %q not found (available: %s)", name, strings.Join(r.Names(), ", "))
+		}
+		if err := r.ApplyTemplate(toolDef, t); err != nil {
+			return fmt.Errorf("failed to apply integration %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyTemplate runs every applier with t on toolDef
+func (r *Registry) ApplyTemplate(toolDef map[string]interface{}, t Template) error {
+	for _, a := range r.appliers {
+		if err := a.applier.Apply(toolDef, t); err != nil {
+			return fmt.Errorf("%s: %w", a.name, err)
+		}
+	}
+	return nil
+}
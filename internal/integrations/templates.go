@@ -0,0 +1,497 @@
+// Package integrations holds the integration templates that prepare a tool
+// to run against a platform, such as in-cluster Kubernetes credentials or
+// the local AWS configuration, and applies them to tool definitions. The CLI
+// and the MCP server share it so a template behaves the same in both.
+package integrations
+
+// Template defines a reusable integration configuration
+type Template struct {
+	Name         string                 `json:"name" yaml:"name"`
+	Description  string                 `json:"description" yaml:"description"`
+	Type         string                 `json:"type" yaml:"type"` // kubernetes, aws, gcp, etc.
+	EnvVars      map[string]string      `json:"env_vars,omitempty" yaml:"env_vars,omitempty"`
+	WithFiles    []FileMapping          `json:"with_files,omitempty" yaml:"with_files,omitempty"`
+	WithVolumes  []VolumeMapping        `json:"with_volumes,omitempty" yaml:"with_volumes,omitempty"`
+	WithServices []string               `json:"with_services,omitempty" yaml:"with_services,omitempty"`
+	Secrets      []string               `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Config       map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	// New fields for more comprehensive integration
+	EntrypointWrapper string            `json:"entrypoint_wrapper,omitempty" yaml:"entrypoint_wrapper,omitempty"`
+	BeforeScript      string            `json:"before_script,omitempty" yaml:"before_script,omitempty"`
+	AfterScript       string            `json:"after_script,omitempty" yaml:"after_script,omitempty"`
+	RequiredPackages  []string          `json:"required_packages,omitempty" yaml:"required_packages,omitempty"`
+	DefaultImage      string            `json:"default_image,omitempty" yaml:"default_image,omitempty"`
+	ImageOverrides    map[string]string `json:"image_overrides,omitempty" yaml:"image_overrides,omitempty"`
+}
+
+// FileMapping represents a file to be mapped into the tool container
+type FileMapping struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	Content     string `json:"content,omitempty" yaml:"content,omitempty"` // Optional inline content
+}
+
+// VolumeMapping represents a volume to be mounted into the tool container
+type VolumeMapping struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	ReadOnly    bool   `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+}
+
+// builtin contains the built-in integration templates
+var builtin = map[string]Template{
+	"kubernetes/incluster": {
+		Name:        "kubernetes/incluster",
+		Description: "Kubernetes in-cluster authentication with automatic kubectl configuration",
+		Type:        "kubernetes",
+		WithFiles: []FileMapping{
+			{
+				Source:      "/var/run/secrets/kubernetes.io/serviceaccount/token",
+				Destination: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+			},
+			{
+				Source:      "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
+				Destination: "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
+			},
+		},
+		EnvVars: map[string]string{
+			"KUBERNETES_SERVICE_HOST": "kubernetes.default.svc",
+			"KUBERNETES_SERVICE_PORT": "443",
+		},
+		BeforeScript: `#!/bin/bash
+# Setup Kubernetes in-cluster authentication
+if [ -f /var/run/secrets/kubernetes.io/serviceaccount/token ]; then
+    export KUBE_TOKEN=$(cat /var/run/secrets/kubernetes.io/serviceaccount/token)
+    kubectl config set-cluster in-cluster \
+        --server=https://kubernetes.default.svc \
+        --certificate-authority=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt > /dev/null 2>&1
+    kubectl config set-credentials in-cluster --token=$KUBE_TOKEN > /dev/null 2>&1
+    kubectl config set-context in-cluster --cluster=in-cluster --user=in-cluster > /dev/null 2>&1
+    kubectl config use-context in-cluster > /dev/null 2>&1
+    echo "✓ Kubernetes in-cluster authentication configured"
+fi
+`,
+		DefaultImage: "bitnami/kubectl:latest",
+	},
+	"kubernetes/kubeconfig": {
+		Name:        "kubernetes/kubeconfig",
+		Description: "Kubernetes authentication using local kubeconfig file",
+		Type:        "kubernetes",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.kube/config",
+				Destination: "/root/.kube/config",
+			},
+		},
+		EnvVars: map[string]string{
+			"KUBECONFIG": "/root/.kube/config",
+		},
+		DefaultImage: "bitnami/kubectl:latest",
+	},
+	"kubernetes/eks": {
+		Name:        "kubernetes/eks",
+		Description: "Amazon EKS authentication with aws-cli",
+		Type:        "kubernetes",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.aws/credentials",
+				Destination: "/root/.aws/credentials",
+			},
+			{
+				Source:      "~/.aws/config",
+				Destination: "/root/.aws/config",
+			},
+			{
+				Source:      "~/.kube/config",
+				Destination: "/root/.kube/config",
+			},
+		},
+		EnvVars: map[string]string{
+			"AWS_PROFILE": "${AWS_PROFILE:-default}",
+			"KUBECONFIG":  "/root/.kube/config",
+		},
+		RequiredPackages: []string{"aws-cli", "kubectl"},
+		BeforeScript: `#!/bin/bash
+# Update kubeconfig for EKS
+if [ -n "$EKS_CLUSTER_NAME" ] && [ -n "$AWS_REGION" ]; then
+    aws eks update-kubeconfig --name $EKS_CLUSTER_NAME --region $AWS_REGION
+    echo "✓ EKS kubeconfig updated for cluster: $EKS_CLUSTER_NAME"
+fi
+`,
+		DefaultImage: "amazon/aws-cli:latest",
+	},
+	"kubernetes/gke": {
+		Name:        "kubernetes/gke",
+		Description: "Google Kubernetes Engine authentication",
+		Type:        "kubernetes",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.config/gcloud",
+				Destination: "/root/.config/gcloud",
+			},
+		},
+		EnvVars: map[string]string{
+			"GOOGLE_APPLICATION_CREDENTIALS": "/root/.config/gcloud/application_default_credentials.json",
+			"CLOUDSDK_CORE_PROJECT":          "${GCP_PROJECT}",
+		},
+		BeforeScript: `#!/bin/bash
+# Get GKE credentials
+if [ -n "$GKE_CLUSTER_NAME" ] && [ -n "$GKE_CLUSTER_ZONE" ]; then
+    gcloud container clusters get-credentials $GKE_CLUSTER_NAME --zone $GKE_CLUSTER_ZONE
+    echo "✓ GKE credentials configured for cluster: $GKE_CLUSTER_NAME"
+fi
+`,
+		DefaultImage: "google/cloud-sdk:slim",
+	},
+	"aws/cli": {
+		Name:        "aws/cli",
+		Description: "AWS CLI with credentials from local AWS config",
+		Type:        "aws",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.aws/credentials",
+				Destination: "/root/.aws/credentials",
+			},
+			{
+				Source:      "~/.aws/config",
+				Destination: "/root/.aws/config",
+			},
+		},
+		EnvVars: map[string]string{
+			"AWS_PROFILE":        "${AWS_PROFILE:-default}",
+			"AWS_DEFAULT_REGION": "${AWS_DEFAULT_REGION:-us-east-1}",
+		},
+		DefaultImage: "amazon/aws-cli:latest",
+	},
+	"aws/iam-role": {
+		Name:        "aws/iam-role",
+		Description: "AWS CLI with IAM role assumption",
+		Type:        "aws",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.aws/credentials",
+				Destination: "/root/.aws/credentials",
+			},
+			{
+				Source:      "~/.aws/config",
+				Destination: "/root/.aws/config",
+			},
+		},
+		EnvVars: map[string]string{
+			"AWS_PROFILE":        "${AWS_PROFILE:-default}",
+			"AWS_DEFAULT_REGION": "${AWS_DEFAULT_REGION:-us-east-1}",
+		},
+		BeforeScript: `#!/bin/bash
+# Assume IAM role if specified
+if [ -n "$AWS_ROLE_ARN" ]; then
+    CREDS=$(aws sts assume-role --role-arn $AWS_ROLE_ARN --role-session-name kubiya-session)
+    export AWS_ACCESS_KEY_ID=$(echo $CREDS | jq -r '.Credentials.AccessKeyId')
+    export AWS_SECRET_ACCESS_KEY=$(echo $CREDS | jq -r '.Credentials.SecretAccessKey')
+    export AWS_SESSION_TOKEN=$(echo $CREDS | jq -r '.Credentials.SessionToken')
+    echo "✓ Assumed IAM role: $AWS_ROLE_ARN"
+fi
+`,
+		RequiredPackages: []string{"jq"},
+		DefaultImage:     "amazon/aws-cli:latest",
+	},
+	"aws/env": {
+		Name:        "aws/env",
+		Description: "AWS CLI with credentials from environment variables",
+		Type:        "aws",
+		EnvVars: map[string]string{
+			"AWS_ACCESS_KEY_ID":     "${AWS_ACCESS_KEY_ID}",
+			"AWS_SECRET_ACCESS_KEY": "${AWS_SECRET_ACCESS_KEY}",
+			"AWS_SESSION_TOKEN":     "${AWS_SESSION_TOKEN}",
+			"AWS_DEFAULT_REGION":    "${AWS_DEFAULT_REGION:-us-east-1}",
+		},
+		DefaultImage: "amazon/aws-cli:latest",
+	},
+	"gcp/adc": {
+		Name:        "gcp/adc",
+		Description: "Google Cloud with Application Default Credentials",
+		Type:        "gcp",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.config/gcloud/application_default_credentials.json",
+				Destination: "/root/.config/gcloud/application_default_credentials.json",
+			},
+		},
+		EnvVars: map[string]string{
+			"GOOGLE_APPLICATION_CREDENTIALS": "/root/.config/gcloud/application_default_credentials.json",
+			"CLOUDSDK_CORE_PROJECT":          "${GCP_PROJECT}",
+		},
+		DefaultImage: "google/cloud-sdk:slim",
+	},
+	"gcp/service-account": {
+		Name:        "gcp/service-account",
+		Description: "Google Cloud with service account key file",
+		Type:        "gcp",
+		WithFiles: []FileMapping{
+			{
+				Source:      "${GCP_SERVICE_ACCOUNT_KEY_FILE}",
+				Destination: "/tmp/gcp-key.json",
+			},
+		},
+		EnvVars: map[string]string{
+			"GOOGLE_APPLICATION_CREDENTIALS": "/tmp/gcp-key.json",
+			"CLOUDSDK_CORE_PROJECT":          "${GCP_PROJECT}",
+		},
+		BeforeScript: `#!/bin/bash
+# Activate service account
+if [ -f /tmp/gcp-key.json ]; then
+    gcloud auth activate-service-account --key-file=/tmp/gcp-key.json
+    echo "✓ GCP service account activated"
+fi
+`,
+		DefaultImage: "google/cloud-sdk:slim",
+	},
+	"azure/cli": {
+		Name:        "azure/cli",
+		Description: "Azure CLI with local credentials",
+		Type:        "azure",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.azure",
+				Destination: "/root/.azure",
+			},
+		},
+		EnvVars: map[string]string{
+			"AZURE_SUBSCRIPTION_ID": "${AZURE_SUBSCRIPTION_ID}",
+		},
+		DefaultImage: "mcr.microsoft.com/azure-cli:latest",
+	},
+	"azure/sp": {
+		Name:        "azure/sp",
+		Description: "Azure CLI with service principal authentication",
+		Type:        "azure",
+		EnvVars: map[string]string{
+			"AZURE_CLIENT_ID":       "${AZURE_CLIENT_ID}",
+			"AZURE_CLIENT_SECRET":   "${AZURE_CLIENT_SECRET}",
+			"AZURE_TENANT_ID":       "${AZURE_TENANT_ID}",
+			"AZURE_SUBSCRIPTION_ID": "${AZURE_SUBSCRIPTION_ID}",
+		},
+		BeforeScript: `#!/bin/bash
+# Login with service principal
+if [ -n "$AZURE_CLIENT_ID" ] && [ -n "$AZURE_CLIENT_SECRET" ] && [ -n "$AZURE_TENANT_ID" ]; then
+    az login --service-principal -u $AZURE_CLIENT_ID -p $AZURE_CLIENT_SECRET --tenant $AZURE_TENANT_ID
+    echo "✓ Azure service principal authenticated"
+fi
+`,
+		DefaultImage: "mcr.microsoft.com/azure-cli:latest",
+	},
+	"docker/socket": {
+		Name:        "docker/socket",
+		Description: "Docker CLI with socket access",
+		Type:        "docker",
+		WithVolumes: []VolumeMapping{
+			{
+				Source:      "/var/run/docker.sock",
+				Destination: "/var/run/docker.sock",
+			},
+		},
+		DefaultImage: "docker:cli",
+	},
+	"docker/dind": {
+		Name:        "docker/dind",
+		Description: "Docker-in-Docker with full Docker daemon",
+		Type:        "docker",
+		WithServices: []string{
+			"docker:dind",
+		},
+		EnvVars: map[string]string{
+			"DOCKER_HOST":       "tcp://docker:2376",
+			"DOCKER_TLS_VERIFY": "1",
+			"DOCKER_CERT_PATH":  "/certs/client",
+		},
+		WithVolumes: []VolumeMapping{
+			{
+				Source:      "docker-certs-client",
+				Destination: "/certs/client",
+				ReadOnly:    true,
+			},
+		},
+		DefaultImage: "docker:cli",
+	},
+	"terraform/aws": {
+		Name:        "terraform/aws",
+		Description: "Terraform with AWS provider",
+		Type:        "terraform",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.aws/credentials",
+				Destination: "/root/.aws/credentials",
+			},
+			{
+				Source:      "~/.aws/config",
+				Destination: "/root/.aws/config",
+			},
+		},
+		EnvVars: map[string]string{
+			"AWS_PROFILE":        "${AWS_PROFILE:-default}",
+			"AWS_DEFAULT_REGION": "${AWS_DEFAULT_REGION:-us-east-1}",
+		},
+		BeforeScript: `#!/bin/bash
+# Initialize Terraform
+terraform init -upgrade
+echo "✓ Terraform initialized"
+`,
+		DefaultImage: "hashicorp/terraform:latest",
+	},
+	"ansible/ssh": {
+		Name:        "ansible/ssh",
+		Description: "Ansible with SSH key authentication",
+		Type:        "ansible",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.ssh/id_rsa",
+				Destination: "/root/.ssh/id_rsa",
+			},
+			{
+				Source:      "~/.ssh/id_rsa.pub",
+				Destination: "/root/.ssh/id_rsa.pub",
+			},
+			{
+				Source:      "~/.ssh/known_hosts",
+				Destination: "/root/.ssh/known_hosts",
+			},
+		},
+		BeforeScript: `#!/bin/bash
+# Set proper SSH key permissions
+chmod 600 /root/.ssh/id_rsa
+chmod 644 /root/.ssh/id_rsa.pub
+echo "✓ SSH keys configured"
+`,
+		DefaultImage: "ansible/ansible:latest",
+	},
+	"git/ssh": {
+		Name:        "git/ssh",
+		Description: "Git with SSH authentication",
+		Type:        "git",
+		WithFiles: []FileMapping{
+			{
+				Source:      "~/.ssh/id_rsa",
+				Destination: "/root/.ssh/id_rsa",
+			},
+			{
+				Source:      "~/.ssh/known_hosts",
+				Destination: "/root/.ssh/known_hosts",
+			},
+			{
+				Source:      "~/.gitconfig",
+				Destination: "/root/.gitconfig",
+			},
+		},
+		BeforeScript: `#!/bin/bash
+# Configure Git SSH
+chmod 600 /root/.ssh/id_rsa
+git config --global core.sshCommand "ssh -o StrictHostKeyChecking=no"
+echo "✓ Git SSH configured"
+`,
+		DefaultImage: "alpine/git:latest",
+	},
+	"database/postgres": {
+		Name:        "database/postgres",
+		Description: "PostgreSQL client with database service",
+		Type:        "database",
+		WithServices: []string{
+			"postgres:15",
+		},
+		EnvVars: map[string]string{
+			"PGHOST":     "${PGHOST:-postgres}",
+			"PGPORT":     "${PGPORT:-5432}",
+			"PGDATABASE": "${PGDATABASE:-postgres}",
+			"PGUSER":     "${PGUSER:-postgres}",
+			"PGPASSWORD": "${PGPASSWORD:-postgres}",
+		},
+		BeforeScript: `#!/bin/bash
+# Wait for PostgreSQL to be ready
+for i in {1..30}; do
+    if pg_isready -h $PGHOST -p $PGPORT -U $PGUSER; then
+        echo "✓ PostgreSQL is ready"
+        break
+    fi
+    echo "Waiting for PostgreSQL... ($i/30)"
+    sleep 1
+done
+`,
+		DefaultImage: "postgres:15-alpine",
+	},
+	"database/mysql": {
+		Name:        "database/mysql",
+		Description: "MySQL client with database service",
+		Type:        "database",
+		WithServices: []string{
+			"mysql:8",
+		},
+		EnvVars: map[string]string{
+			"MYSQL_HOST":          "${MYSQL_HOST:-mysql}",
+			"MYSQL_PORT":          "${MYSQL_PORT:-3306}",
+			"MYSQL_DATABASE":      "${MYSQL_DATABASE:-test}",
+			"MYSQL_USER":          "${MYSQL_USER:-root}",
+			"MYSQL_PASSWORD":      "${MYSQL_PASSWORD:-root}",
+			"MYSQL_ROOT_PASSWORD": "${MYSQL_ROOT_PASSWORD:-root}",
+		},
+		BeforeScript: `#!/bin/bash
+# Wait for MySQL to be ready
+for i in {1..30}; do
+    if mysqladmin ping -h $MYSQL_HOST -u $MYSQL_USER -p$MYSQL_PASSWORD --silent; then
+        echo "✓ MySQL is ready"
+        break
+    fi
+    echo "Waiting for MySQL... ($i/30)"
+    sleep 1
+done
+`,
+		DefaultImage: "mysql:8",
+	},
+	"cache/redis": {
+		Name:        "cache/redis",
+		Description: "Redis client with cache service",
+		Type:        "cache",
+		WithServices: []string{
+			"redis:7-alpine",
+		},
+		EnvVars: map[string]string{
+			"REDIS_HOST": "${REDIS_HOST:-redis}",
+			"REDIS_PORT": "${REDIS_PORT:-6379}",
+		},
+		BeforeScript: `#!/bin/bash
+# Wait for Redis to be ready
+for i in {1..30}; do
+    if redis-cli -h $REDIS_HOST -p $REDIS_PORT ping > /dev/null 2>&1; then
+        echo "✓ Redis is ready"
+        break
+    fi
+    echo "Waiting for Redis... ($i/30)"
+    sleep 1
+done
+`,
+		DefaultImage: "redis:7-alpine",
+	},
+	"monitoring/prometheus": {
+		Name:        "monitoring/prometheus",
+		Description: "Prometheus monitoring stack",
+		Type:        "monitoring",
+		WithServices: []string{
+			"prom/prometheus:latest",
+			"grafana/grafana:latest",
+		},
+		EnvVars: map[string]string{
+			"PROMETHEUS_URL": "http://prometheus:9090",
+			"GRAFANA_URL":    "http://grafana:3000",
+		},
+		DefaultImage: "prom/prometheus:latest",
+	},
+}
+
+// aliases are the short names templates can be referred to by
+var aliases = map[string]string{
+	"k8s/incluster":  "kubernetes/incluster",
+	"k8s/kubeconfig": "kubernetes/kubeconfig",
+	"k8s/eks":        "kubernetes/eks",
+	"k8s/gke":        "kubernetes/gke",
+	"aws/creds":      "aws/cli",
+	"postgres":       "database/postgres",
+	"mysql":          "database/mysql",
+	"redis":          "cache/redis",
+}
@@ -0,0 +1,91 @@
+package kbimport
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// readConfluence reads a Confluence space exported as HTML: a page per file
+// with its content in #main-content, its ancestors in #breadcrumbs and its
+// labels as aui-label links. The index.html overview of the space is not a
+// page.
+func readConfluence(fsys fs.FS, files []string) (*Export, error) {
+	e := &Export{}
+	for _, f := range files {
+		ext := strings.ToLower(path.Ext(f))
+		if (ext != ".html" && ext != ".htm") || path.Base(f) == "index.html" || isAttachment(f) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			e.Skipped = append(e.Skipped, f)
+			continue
+		}
+		page, ok := parseConfluencePage(data)
+		if !ok {
+			e.Skipped = append(e.Skipped, f)
+			continue
+		}
+		page.File = f
+		e.Pages = append(e.Pages, page)
+	}
+	return e, nil
+}
+
+func isAttachment(f string) bool {
+	for _, dir := range strings.Split(path.Dir(f), "/") {
+		if dir == "attachments" || dir == "images" || dir == "styles" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseConfluencePage(data []byte) (Page, bool) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return Page{}, false
+	}
+	content := find(doc, func(n *html.Node) bool { return attr(n, "id") == "main-content" })
+	if content == nil {
+		return Page{}, false
+	}
+
+	var page Page
+	if breadcrumbs := find(doc, func(n *html.Node) bool { return attr(n, "id") == "breadcrumbs" }); breadcrumbs != nil {
+		for _, li := range findAll(breadcrumbs, func(n *html.Node) bool { return n.DataAtom == atom.Li }) {
+			if t := text(li); t != "" {
+				page.Parents = append(page.Parents, t)
+			}
+		}
+	}
+
+	// Titles read "<space> : <page>"
+	if title := find(doc, func(n *html.Node) bool { return attr(n, "id") == "title-text" }); title != nil {
+		page.Title = text(title)
+	} else if title := find(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }); title != nil {
+		page.Title = text(title)
+	}
+	if len(page.Parents) > 0 {
+		page.Title = strings.TrimPrefix(page.Title, page.Parents[0]+" : ")
+	}
+	page.Title = strings.TrimSpace(page.Title)
+
+	seen := map[string]bool{}
+	for _, label := range findAll(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.A && (hasClass(n, "aui-label-split-main") || hasClass(n, "label"))
+	}) {
+		if t := text(label); t != "" && !seen[t] {
+			seen[t] = true
+			page.Labels = append(page.Labels, t)
+		}
+	}
+
+	page.Body = htmlToMarkdown(content)
+	return page, page.Title != ""
+}
@@ -0,0 +1,132 @@
+// Package kbimport reads Confluence and Notion exports and splits their
+// pages into knowledge items, keeping titles, labels and the page hierarchy.
+package kbimport
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Export formats
+const (
+	FormatAuto       = "auto"
+	FormatConfluence = "confluence"
+	FormatNotion     = "notion"
+)
+
+// Page is a page of an export, converted to Markdown
+type Page struct {
+	Title string
+	// Parents are the titles of the ancestors of the page, from the root
+	Parents []string
+	Labels  []string
+	Body    string
+	// File is the path of the page in the export
+	File string
+}
+
+// Hierarchy returns the titles from the root to the page, joined with " / "
+func (p Page) Hierarchy() string {
+	return strings.Join(append(append([]string{}, p.Parents...), p.Title), " / ")
+}
+
+// Export is the content of an export
+type Export struct {
+	Format string
+	Pages  []Page
+	// Skipped lists the files that looked like pages but could not be read
+	Skipped []string
+}
+
+// Open reads an export from a zip file or a directory it was extracted to.
+// format is FormatAuto, FormatConfluence or FormatNotion.
+func Open(name, format string) (*Export, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	if info.IsDir() {
+		return Read(os.DirFS(name), format)
+	}
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s as a zip file: %w", name, err)
+	}
+	defer r.Close()
+	return Read(r, format)
+}
+
+// Read reads an export from fsys
+func Read(fsys fs.FS, format string) (*Export, error) {
+	files, err := listFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" || format == FormatAuto {
+		format = detectFormat(fsys, files)
+	}
+
+	var e *Export
+	switch format {
+	case FormatConfluence:
+		e, err = readConfluence(fsys, files)
+	case FormatNotion:
+		e, err = readNotion(fsys, files)
+	case "":
+		return nil, fmt.Errorf("not a Confluence HTML or Notion Markdown export: no pages found")
+	default:
+		return nil, fmt.Errorf("unknown export format %q (valid: %s, %s, %s)", format, FormatAuto, FormatConfluence, FormatNotion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Format = format
+	return e, nil
+}
+
+func listFiles(fsys fs.FS) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), "__MACOSX") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// detectFormat recognizes Confluence by the main-content block of its pages
+// and Notion by its Markdown files
+func detectFormat(fsys fs.FS, files []string) string {
+	markdown := false
+	for _, f := range files {
+		switch strings.ToLower(path.Ext(f)) {
+		case ".html", ".htm":
+			if data, err := fs.ReadFile(fsys, f); err == nil && strings.Contains(string(data), `id="main-content"`) {
+				return FormatConfluence
+			}
+		case ".md":
+			markdown = true
+		}
+	}
+	if markdown {
+		return FormatNotion
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package kbimport
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const confluencePage = `<html><head><title>Ops : Restart the API</title></head><body>
+<div id="breadcrumb-section"><ol id="breadcrumbs">
+  <li class="first"><span><a href="index.html">Ops</a></span></li>
+  <li><span><a href="Runbooks_1.html">Runbooks</a></span></li>
+</ol></div>
+<h1 id="title-heading"><span id="title-text"> Ops : Restart the API </span></h1>
+<div id="main-content" class="wiki-content group">
+<h2>Steps</h2>
+<ol><li>Scale <strong>down</strong> the deployment</li><li>Scale it up
+  <ul><li>wait for <code>Ready</code></li></ul></li></ol>
+<pre class="syntaxhighlighter-pre" data-syntaxhighlighter-params="brush: bash; gutter: false">kubectl rollout restart deploy/api
+kubectl get pods</pre>
+<table><tr><th>Env</th><th>Namespace</th></tr><tr><td>prod</td><td>api | v2</td></tr></table>
+<p>See <a href="https://example.com/docs">the docs</a>.</p>
+</div>
+<div class="labels"><ul class="label-list"><li class="aui-label"><a class="aui-label-split-main" href="#">k8s</a></li></ul></div>
+</body></html>`
+
+func TestReadConfluence(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Ops/index.html":                {Data: []byte(`<html><div id="main-content">Space overview</div></html>`)},
+		"Ops/Restart-the-API_2.html":    {Data: []byte(confluencePage)},
+		"Ops/attachments/2/diagram.png": {Data: []byte("png")},
+		"Ops/Broken_3.html":             {Data: []byte(`<html><body>no content</body></html>`)},
+	}
+	e, err := Read(fsys, FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Format != FormatConfluence {
+		t.Fatalf("format = %q, want confluence", e.Format)
+	}
+	if len(e.Pages) != 1 || len(e.Skipped) != 1 || e.Skipped[0] != "Ops/Broken_3.html" {
+		t.Fatalf("pages = %d, skipped = %v", len(e.Pages), e.Skipped)
+	}
+
+	p := e.Pages[0]
+	if p.Title != "Restart the API" || p.Hierarchy() != "Ops / Runbooks / Restart the API" {
+		t.Errorf("title = %q, hierarchy = %q", p.Title, p.Hierarchy())
+	}
+	if len(p.Labels) != 1 || p.Labels[0] != "k8s" {
+		t.Errorf("labels = %v", p.Labels)
+	}
+	for _, want := range []string{
+		"## Steps",
+		"1. Scale **down** the deployment",
+		"2. Scale it up\n   - wait for `Ready`",
+		"```bash\nkubectl rollout restart deploy/api\nkubectl get pods\n```",
+		"| Env | Namespace |\n| --- | --- |\n| prod | api \\| v2 |",
+		"See [the docs](https://example.com/docs).",
+	} {
+		if !strings.Contains(p.Body, want) {
+			t.Errorf("body does not contain %q:\n%s", want, p.Body)
+		}
+	}
+}
+
+func TestReadNotion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Runbooks 0123456789abcdef0123456789abcdef.md": {Data: []byte("# Runbooks\n\nAll our runbooks.\n")},
+		"Runbooks 0123456789abcdef0123456789abcdef/Deploy fedcba9876543210fedcba9876543210.md": {Data: []byte(
+			"# Deploy\n\nTags: release, prod\nOwner: SRE\n\nRun the pipeline.\n")},
+		"Runbooks 0123456789abcdef0123456789abcdef/Note 11111111111111111111111111111111.md": {Data: []byte(
+			"# Note\n\nWarning: this is text\n\nMore text.\n")},
+		"Runbooks 0123456789abcdef0123456789abcdef/Table 22222222222222222222222222222222.csv": {Data: []byte("a,b\n")},
+	}
+	e, err := Read(fsys, FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Format != FormatNotion || len(e.Pages) != 3 {
+		t.Fatalf("format = %q, pages = %d", e.Format, len(e.Pages))
+	}
+
+	pages := map[string]Page{}
+	for _, p := range e.Pages {
+		pages[p.Title] = p
+	}
+	deploy := pages["Deploy"]
+	if deploy.Hierarchy() != "Runbooks / Deploy" {
+		t.Errorf("hierarchy = %q", deploy.Hierarchy())
+	}
+	if strings.Join(deploy.Labels, ",") != "release,prod" || deploy.Body != "Run the pipeline." {
+		t.Errorf("labels = %v, body = %q", deploy.Labels, deploy.Body)
+	}
+	if note := pages["Note"]; note.Body != "Warning: this is text\n\nMore text." {
+		t.Errorf("a single property-like line should be kept, body = %q", note.Body)
+	}
+}
+
+func TestReadUnknownExport(t *testing.T) {
+	if _, err := Read(fstest.MapFS{"a.txt": {Data: []byte("x")}}, FormatAuto); err == nil {
+		t.Error("expected an error for an export without pages")
+	}
+	if _, err := Read(fstest.MapFS{}, "wiki"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestItems(t *testing.T) {
+	section := func(title string, n int) string {
+		return "## " + title + "\n\n" + strings.Repeat("word ", n) + "\n\n"
+	}
+	e := &Export{Format: FormatNotion, Pages: []Page{
+		{Title: "Short", Body: "Just this."},
+		{Title: "Empty", Body: "  "},
+		{Title: "Long", Parents: []string{"Runbooks"}, Labels: []string{"ops"},
+			Body: section("One", 80) + section("Two", 80) + section("Three", 300) + "```\n## not a heading\n```\n"},
+	}}
+
+	items := e.Items(1000)
+	if len(items) < 4 {
+		t.Fatalf("got %d items, want the short page and parts of the long one", len(items))
+	}
+	if items[0].Name != "Short" || items[0].Content != "# Short\n\nJust this." {
+		t.Errorf("item = %+v", items[0])
+	}
+
+	parts := items[1:]
+	for i, item := range parts {
+		want := "Long (" + string(rune('1'+i)) + "/" + string(rune('0'+len(parts))) + ")"
+		if item.Name != want {
+			t.Errorf("name = %q, want %q", item.Name, want)
+		}
+		if len(item.Content) > 1000+len("# "+want+"\n\n") {
+			t.Errorf("%s is %d bytes", item.Name, len(item.Content))
+		}
+		if item.Properties["hierarchy"] != "Runbooks / Long" || item.Labels[0] != FormatNotion || item.Labels[1] != "ops" {
+			t.Errorf("item = %+v", item)
+		}
+	}
+	// Sections One and Two fit together, Three is cut between lines
+	if !strings.Contains(parts[0].Content, "## One") || !strings.Contains(parts[0].Content, "## Two") {
+		t.Errorf("first part = %q", parts[0].Content)
+	}
+	if !strings.HasPrefix(strings.TrimPrefix(parts[1].Content, "# Long (2/"+string(rune('0'+len(parts)))+")\n\n"), "## Three") {
+		t.Errorf("second part = %q", parts[1].Content)
+	}
+}
@@ -0,0 +1,273 @@
+package kbimport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	trailingSpace = regexp.MustCompile(`[ \t]+\n`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown converts the content of a page to Markdown. Formatting
+// without a Markdown equivalent is dropped and its text kept.
+func htmlToMarkdown(n *html.Node) string {
+	c := &mdConverter{}
+	c.children(n)
+	md := trailingSpace.ReplaceAllString(c.b.String(), "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(md, "\n\n"))
+}
+
+type mdConverter struct {
+	b     strings.Builder
+	lists []listState
+	pre   bool
+}
+
+type listState struct {
+	ordered bool
+	n       int
+}
+
+func (c *mdConverter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *mdConverter) block(s string) {
+	c.b.WriteString("\n\n" + s + "\n\n")
+}
+
+func (c *mdConverter) inline(n *html.Node) string {
+	sub := &mdConverter{pre: c.pre}
+	sub.children(n)
+	return strings.TrimSpace(sub.b.String())
+}
+
+func (c *mdConverter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if c.pre {
+			c.b.WriteString(n.Data)
+		} else {
+			c.b.WriteString(collapseSpace(n.Data))
+		}
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head:
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		if text := c.inline(n); text != "" {
+			c.block(strings.Repeat("#", level) + " " + text)
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article:
+		c.b.WriteString("\n\n")
+		c.children(n)
+		c.b.WriteString("\n\n")
+	case atom.Br:
+		c.b.WriteString("\n")
+	case atom.Hr:
+		c.block("---")
+	case atom.Strong, atom.B:
+		if text := c.inline(n); text != "" {
+			c.b.WriteString("**" + text + "**")
+		}
+	case atom.Em, atom.I:
+		if text := c.inline(n); text != "" {
+			c.b.WriteString("_" + text + "_")
+		}
+	case atom.Code:
+		if c.pre {
+			c.children(n)
+		} else if text := c.inline(n); text != "" {
+			c.b.WriteString("`" + text + "`")
+		}
+	case atom.Pre:
+		sub := &mdConverter{pre: true}
+		sub.children(n)
+		c.block("```" + codeLanguage(n) + "\n" + strings.Trim(sub.b.String(), "\n") + "\n```")
+	case atom.A:
+		text := c.inline(n)
+		href := attr(n, "href")
+		if text == "" {
+			return
+		}
+		if href == "" || strings.HasPrefix(href, "#") {
+			c.b.WriteString(text)
+		} else {
+			c.b.WriteString("[" + text + "](" + href + ")")
+		}
+	case atom.Img:
+		if alt := attr(n, "alt"); alt != "" {
+			c.b.WriteString("[image: " + alt + "]")
+		}
+	case atom.Ul, atom.Ol:
+		c.lists = append(c.lists, listState{ordered: n.DataAtom == atom.Ol})
+		c.b.WriteString("\n")
+		c.children(n)
+		c.lists = c.lists[:len(c.lists)-1]
+		if len(c.lists) == 0 {
+			c.b.WriteString("\n")
+		}
+	case atom.Li:
+		marker := "-"
+		if len(c.lists) > 0 {
+			l := &c.lists[len(c.lists)-1]
+			l.n++
+			if l.ordered {
+				marker = fmt.Sprintf("%d.", l.n)
+			}
+		}
+		// Nested lists are rendered on their own and indented under the item
+		text := strings.ReplaceAll(c.inline(n), "\n\n", "\n")
+		c.b.WriteString("\n" + marker + " " + strings.ReplaceAll(text, "\n", "\n"+strings.Repeat(" ", len(marker)+1)))
+	case atom.Blockquote:
+		text := c.inline(n)
+		c.block("> " + strings.ReplaceAll(text, "\n", "\n> "))
+	case atom.Table:
+		c.block(c.table(n))
+	default:
+		c.children(n)
+	}
+}
+
+// table renders rows as a Markdown table, the first row being the header
+func (c *mdConverter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.DataAtom == atom.Tr {
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						text := strings.Join(strings.Fields(c.inline(cell)), " ")
+						row = append(row, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				rows = append(rows, row)
+			} else {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// codeLanguage reads the language of a Confluence code macro
+func codeLanguage(n *html.Node) string {
+	for _, part := range strings.Split(attr(n, "data-syntaxhighlighter-params"), ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), ":"); ok && strings.TrimSpace(k) == "brush" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func collapseSpace(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			return " "
+		}
+		return ""
+	}
+	out := strings.Join(fields, " ")
+	if strings.TrimLeft(s, " \t\n\r") != s {
+		out = " " + out
+	}
+	if strings.TrimRight(s, " \t\n\r") != s {
+		out += " "
+	}
+	return out
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the first element below n matching match
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && match(child) {
+			return child
+		}
+		if found := find(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll returns the elements below n matching match
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && match(child) {
+			found = append(found, child)
+			continue
+		}
+		found = append(found, findAll(child, match)...)
+	}
+	return found
+}
+
+// text returns the text of n with collapsed spaces
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,91 @@
+package kbimport
+
+import (
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// notionID matches the page ID Notion appends to file and directory names
+var notionID = regexp.MustCompile(`\s+[0-9a-f]{32}$`)
+
+// notionProperty matches a property line of a database page, e.g. "Tags: ops"
+var notionProperty = regexp.MustCompile(`^([A-Z][\w ]{0,40}):\s+(.+)$`)
+
+// readNotion reads a Notion Markdown export: a file per page, the sub-pages
+// of a page in a directory of the same name. Database pages start with
+// their properties; Tags, Labels and Category become labels.
+func readNotion(fsys fs.FS, files []string) (*Export, error) {
+	e := &Export{}
+	for _, f := range files {
+		if strings.ToLower(path.Ext(f)) != ".md" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			e.Skipped = append(e.Skipped, f)
+			continue
+		}
+		page := parseNotionPage(f, string(data))
+		if page.Title == "" {
+			e.Skipped = append(e.Skipped, f)
+			continue
+		}
+		e.Pages = append(e.Pages, page)
+	}
+	return e, nil
+}
+
+func notionName(name string) string {
+	return strings.TrimSpace(notionID.ReplaceAllString(name, ""))
+}
+
+func parseNotionPage(file, data string) Page {
+	page := Page{File: file, Title: notionName(strings.TrimSuffix(path.Base(file), path.Ext(file)))}
+	if dir := path.Dir(file); dir != "." {
+		for _, d := range strings.Split(dir, "/") {
+			page.Parents = append(page.Parents, notionName(d))
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], "# ") {
+		page.Title = strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+		i++
+	}
+
+	// Properties follow the title, separated by a blank line
+	j := i
+	for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+		j++
+	}
+	var labels []string
+	k := j
+	for ; k < len(lines); k++ {
+		m := notionProperty.FindStringSubmatch(lines[k])
+		if m == nil {
+			break
+		}
+		switch strings.ToLower(m[1]) {
+		case "tags", "labels", "category":
+			for _, l := range strings.Split(m[2], ",") {
+				if l = strings.TrimSpace(l); l != "" {
+					labels = append(labels, l)
+				}
+			}
+		}
+	}
+	// A single "Key: value" line may as well be text
+	if (k-j >= 2 || len(labels) > 0) && (k == len(lines) || strings.TrimSpace(lines[k]) == "") {
+		page.Labels = labels
+		i = k
+	}
+
+	page.Body = strings.TrimSpace(strings.Join(lines[i:], "\n"))
+	return page
+}
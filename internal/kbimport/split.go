@@ -0,0 +1,159 @@
+package kbimport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxChars is the default size of the content of a knowledge item
+const DefaultMaxChars = 8000
+
+// Item is a knowledge item made from a page, or from a part of a long page
+type Item struct {
+	Name       string
+	Content    string
+	Labels     []string
+	Properties map[string]string
+	Page       *Page
+}
+
+// Items splits the pages of the export into items of at most maxChars of
+// content. Pages are split between sections, then between paragraphs, so
+// each part reads on its own; parts are named "<title> (n/m)". Pages without
+// content are left out.
+func (e *Export) Items(maxChars int) []Item {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxChars
+	}
+	var items []Item
+	for i := range e.Pages {
+		page := &e.Pages[i]
+		if strings.TrimSpace(page.Body) == "" {
+			continue
+		}
+		parts := splitMarkdown(page.Body, maxChars)
+		for n, part := range parts {
+			name := page.Title
+			props := map[string]string{
+				"source_format": e.Format,
+				"source_file":   page.File,
+				"hierarchy":     page.Hierarchy(),
+			}
+			if len(parts) > 1 {
+				name = fmt.Sprintf("%s (%d/%d)", page.Title, n+1, len(parts))
+				props["part"] = fmt.Sprintf("%d/%d", n+1, len(parts))
+			}
+			items = append(items, Item{
+				Name:       name,
+				Content:    "# " + name + "\n\n" + part,
+				Labels:     append([]string{e.Format}, page.Labels...),
+				Properties: props,
+				Page:       page,
+			})
+		}
+	}
+	return items
+}
+
+// splitMarkdown cuts text into parts of at most max bytes, preferring
+// heading boundaries, then blank lines, then line ends. Code blocks are only
+// cut when they do not fit on their own.
+func splitMarkdown(text string, max int) []string {
+	if len(text) <= max {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			parts = append(parts, s)
+		}
+		current.Reset()
+	}
+	add := func(block string) {
+		if current.Len() > 0 && current.Len()+len(block) > max {
+			flush()
+		}
+		if len(block) > max {
+			for _, piece := range splitLines(block, max) {
+				if current.Len() > 0 && current.Len()+len(piece) > max {
+					flush()
+				}
+				current.WriteString(piece)
+			}
+			return
+		}
+		current.WriteString(block)
+	}
+
+	for _, section := range splitBlocks(text, func(line string) bool { return strings.HasPrefix(line, "#") }) {
+		if current.Len() > 0 && current.Len()+len(section) > max {
+			flush()
+		}
+		if len(section) <= max {
+			current.WriteString(section)
+			continue
+		}
+		for _, para := range splitBlocks(section, func(line string) bool { return strings.TrimSpace(line) == "" }) {
+			add(para)
+		}
+	}
+	flush()
+	return parts
+}
+
+// splitBlocks cuts text before each line matching start, outside code blocks
+func splitBlocks(text string, start func(string) bool) []string {
+	var blocks []string
+	var b strings.Builder
+	fenced := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if !fenced && start(line) && b.Len() > 0 {
+			blocks = append(blocks, b.String())
+			b.Reset()
+		}
+		b.WriteString(line)
+	}
+	if b.Len() > 0 {
+		blocks = append(blocks, b.String())
+	}
+	return blocks
+}
+
+// splitLines cuts text into pieces of at most max bytes at line ends, and
+// within lines longer than max
+func splitLines(text string, max int) []string {
+	var pieces []string
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for len(line) > max {
+			if b.Len() > 0 {
+				pieces = append(pieces, b.String())
+				b.Reset()
+			}
+			cut := max
+			for cut > 0 && !isRuneStart(line[cut]) {
+				cut--
+			}
+			pieces = append(pieces, line[:cut])
+			line = line[cut:]
+		}
+		if b.Len()+len(line) > max {
+			pieces = append(pieces, b.String())
+			b.Reset()
+		}
+		b.WriteString(line)
+	}
+	if b.Len() > 0 {
+		pieces = append(pieces, b.String())
+	}
+	return pieces
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
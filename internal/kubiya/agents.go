@@ -135,3 +135,29 @@ func (c *Client) GetAgent(ctx context.Context, agentID string) (*Agent, error) {
 
 	return &agent, nil
 }
+
+// GetAgentRaw retrieves an agent as returned by the API, including fields
+// the Agent type does not know about
+func (c *Client) GetAgentRaw(ctx context.Context, agentID string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("%s/agents/%s", c.cfg.BaseURL, agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var agent map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&agent); err != nil {
+		return nil, fmt.Errorf("failed to decode agent: %w", err)
+	}
+	return agent, nil
+}
@@ -0,0 +1,78 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Statuses of an approval request
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+	ApprovalExpired  = "expired"
+)
+
+// ApprovalRequest asks users or groups to approve a tool execution
+type ApprovalRequest struct {
+	ID        string    `json:"id,omitempty"`
+	Approvers []string  `json:"approvers"`
+	ToolName  string    `json:"tool_name"`
+	Arguments string    `json:"arguments,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	DecidedBy string    `json:"decided_by,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateApprovalRequest sends an approval request to its approvers
+func (c *Client) CreateApprovalRequest(ctx context.Context, req ApprovalRequest) (*ApprovalRequest, error) {
+	resp, err := c.post(ctx, "/approvals", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approval request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to create approval request: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var created ApprovalRequest
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode approval request: %w", err)
+	}
+	return &created, nil
+}
+
+// GetApprovalRequest returns an approval request with its current status
+func (c *Client) GetApprovalRequest(ctx context.Context, id string) (*ApprovalRequest, error) {
+	resp, err := c.get(ctx, "/approvals/"+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval request %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	var req ApprovalRequest
+	if err := json.NewDecoder(resp.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode approval request: %w", err)
+	}
+	return &req, nil
+}
+
+// CancelApprovalRequest withdraws a pending approval request
+func (c *Client) CancelApprovalRequest(ctx context.Context, id string) error {
+	resp, err := c.delete(ctx, "/approvals/"+id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel approval request %s: %w", id, err)
+	}
+	resp.Body.Close()
+	return nil
+}
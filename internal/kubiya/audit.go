@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // AuditItem represents a single audit log entry
@@ -37,6 +40,8 @@ type AuditFilter struct {
 	ResourceType string `json:"resource_type,omitempty"`
 	ActionType   string `json:"action_type,omitempty"`
 	SessionID    string `json:"session_id,omitempty"` // Used to filter by session ID
+	// CorrelationID filters by the CLI invocation that made the calls
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
 
 // AuditSort represents the sorting parameters for audit queries
@@ -96,6 +101,7 @@ func (ac *AuditClient) ListAuditItems(ctx context.Context, query AuditQuery) ([]
 
 	// Set headers
 	req.Header.Set("Authorization", "UserKey "+ac.client.cfg.APIKey)
+	correlation.Set(req)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -153,12 +159,13 @@ func (ac *AuditClient) StreamAuditItems(ctx context.Context, query AuditQuery) (
 
 		// Set headers for SSE
 		req.Header.Set("Authorization", "UserKey "+ac.client.cfg.APIKey)
+		correlation.Set(req)
 		req.Header.Set("Accept", "text/event-stream")
 		req.Header.Set("Cache-Control", "no-cache")
 		req.Header.Set("Connection", "keep-alive")
 
 		// Create client with no timeout for streaming
-		client := &http.Client{Timeout: 0}
+		client := httpclient.New(0)
 
 		// Execute request
 		resp, err := client.Do(req)
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 type AuthRoundTripper struct {
@@ -18,7 +21,7 @@ func NewAuthRoundTripper(apiKey string) *AuthRoundTripper {
 	tp := &AuthRoundTripper{
 		Type:      "UserKey",
 		Token:     apiKey,
-		Transport: http.DefaultTransport,
+		Transport: httpclient.Transport(),
 	}
 
 	// If no API key was provided, try environment variable
@@ -61,10 +64,11 @@ func (a *AuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	if a.Token != "" {
 		t := "%s %s"
 		req.Header.Set("Authorization", fmt.Sprintf(t, a.Type, a.Token))
+		correlation.Set(req)
 	}
 
 	if a.Transport == nil {
-		a.Transport = http.DefaultTransport
+		a.Transport = httpclient.Transport()
 	}
 
 	return a.Transport.RoundTrip(req)
@@ -7,31 +7,29 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-)
 
-var logger *log.Logger
+	"github.com/kubiyabot/cli/internal/httpclient"
+	klog "github.com/kubiyabot/cli/internal/log"
+)
 
-func init() {
-	file, err := os.OpenFile("kubiya_chat.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		// If we can't open the log file, fall back to stderr
-		logger = log.New(os.Stderr, "KUBIYA: ", log.LstdFlags|log.Lshortfile)
-		logger.Printf("Warning: Could not open log file: %v, logging to stderr", err)
-		return
-	}
-	logger = log.New(file, "", log.LstdFlags|log.Lshortfile)
-}
+var (
+	sseLog = klog.For(klog.SSE)
+	// logger traces the processing of chat streams
+	logger = log.New(sseLog.Writer(klog.LevelTrace), "", 0)
+)
 
 // ChatSession maintains chat session state
 type ChatSession struct {
@@ -118,6 +116,9 @@ func (c *Client) SendMessageWithRetry(ctx context.Context, agentID, message stri
 		if logger != nil {
 			logger.Printf("Attempt %d failed: %v", attempt+1, err)
 		}
+		if errors.Is(err, ErrPromptRejected) {
+			return nil, err
+		}
 
 		// Use smart error classification - don't retry non-retryable errors
 		errorStr := strings.ToLower(err.Error())
@@ -170,6 +171,12 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 		sessionID = session.ID
 	}
 
+	message, err := c.preparePrompt(ctx, agentID, sessionID, message)
+	if err != nil {
+		close(messagesChan)
+		return nil, err
+	}
+
 	// Get user email and org from environment variables with fallback to config
 	userEmail := os.Getenv("KUBIYA_USER_EMAIL")
 	org := os.Getenv("KUBIYA_ORG")
@@ -181,17 +188,21 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 	}
 
 	payload := struct {
-		Message   string `json:"message"`
-		AgentUUID string `json:"agent_uuid"`
-		SessionID string `json:"session_id"`
-		UserEmail string `json:"user_email,omitempty"`
-		Org       string `json:"org,omitempty"`
+		Message      string            `json:"message"`
+		AgentUUID    string            `json:"agent_uuid"`
+		SessionID    string            `json:"session_id"`
+		UserEmail    string            `json:"user_email,omitempty"`
+		Org          string            `json:"org,omitempty"`
+		Permissions  *ChatPermissions  `json:"permissions,omitempty"`
+		EnvOverrides map[string]string `json:"env_overrides,omitempty"`
 	}{
-		Message:   message,
-		AgentUUID: agentID,
-		SessionID: sessionID,
-		UserEmail: userEmail,
-		Org:       org,
+		Message:      message,
+		AgentUUID:    agentID,
+		SessionID:    sessionID,
+		UserEmail:    userEmail,
+		Org:          org,
+		Permissions:  c.chatPermissions,
+		EnvOverrides: c.chatEnvOverrides,
 	}
 
 	reqURL := fmt.Sprintf("%s/hb/v4/stream", c.baseURL)
@@ -406,19 +417,7 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 			line := scanner.Text()
 			lastActivityTime = time.Now()
 
-			if logger != nil {
-				logger.Printf("Processing line %d: %s", lineCount, line)
-			}
-
-			// Print raw events for debugging (when debug mode is enabled)
-			if os.Getenv("KUBIYA_DEBUG") == "1" || os.Getenv("DEBUG") == "1" || os.Getenv("KUBIYA_RAW_EVENTS") == "1" {
-				fmt.Printf("[RAW STREAM EVENT] %s\n", line)
-			}
-
-			// Also log to file for detailed debugging
-			if logger != nil {
-				logger.Printf("[STREAM] Line %d: %s", lineCount, line)
-			}
+			sseLog.Trace("raw stream event", "line", lineCount, "event", line)
 
 			// Handle empty lines gracefully
 			if strings.TrimSpace(line) == "" {
@@ -466,7 +465,10 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 					}
 					continue
 				}
-				// Process data parts if needed
+				// Files sent as data are forwarded as file messages
+				for _, m := range fileMessages(typ, payload, sessionID) {
+					messagesChan <- m
+				}
 
 			case '3': // partError
 				var errMsg string
@@ -499,10 +501,7 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 					finishReason = reason
 				}
 
-				// Print raw finish data for debugging
-				if os.Getenv("KUBIYA_DEBUG") == "1" || os.Getenv("DEBUG") == "1" {
-					fmt.Printf("[FINISH DATA] %+v\n", finishData)
-				}
+				sseLog.Debug("stream finished", "data", finishData)
 
 				messagesChan <- ChatMessage{
 					Content:      textBuilder.String(),
@@ -526,6 +525,11 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 					}
 				}
 
+			case 'k': // partFile
+				for _, m := range fileMessages(typ, payload, sessionID) {
+					messagesChan <- m
+				}
+
 			case 'g': // partReasoning
 				var reasoning string
 				if err := json.Unmarshal([]byte(payload), &reasoning); err != nil {
@@ -560,6 +564,11 @@ func (c *Client) SendMessage(ctx context.Context, agentID, message string, sessi
 					if toolCallID == "" {
 						toolCallID = uuid.New().String()
 					}
+					// Hold the stream until the call is acknowledged
+					if denied := c.acknowledgeToolCall(ctx, ToolCall{ID: toolCallID, Name: toolName, Arguments: argsStr}, sessionID); denied != nil {
+						messagesChan <- *denied
+						return
+					}
 					messagesChan <- ChatMessage{
 						Content:    fmt.Sprintf("Tool: %s\nArguments: %s", toolName, argsStr),
 						Type:       "tool",
@@ -739,6 +748,73 @@ func (c *Client) GetConversationMessages(ctx context.Context, agentID, message,
 	return ret, nil
 }
 
+// GetSessionHistory fetches the messages of an existing chat session, oldest first.
+// Streaming events share a message ID, so only the latest version of each
+// message is kept.
+func (c *Client) GetSessionHistory(ctx context.Context, sessionID string) ([]ChatMessage, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("/hb/v4/sessions/%s/messages", url.PathEscape(sessionID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch session history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session history: %w", err)
+	}
+
+	// The API returns either a bare list or an object wrapping the list
+	var messages []ChatMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		var wrapped struct {
+			Messages []ChatMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to parse session history: %w", err)
+		}
+		messages = wrapped.Messages
+	}
+
+	messages = dedupeChatMessages(messages)
+	c.cache.Set(sessionHistoryCacheKey(sessionID), messages)
+	return messages, nil
+}
+
+// GetSessionHistoryCached retrieves the messages of a chat session, only
+// fetching them when the client has not loaded them yet
+func (c *Client) GetSessionHistoryCached(ctx context.Context, sessionID string) ([]ChatMessage, error) {
+	if cached, ok := c.cache.Get(sessionHistoryCacheKey(sessionID)); ok {
+		if messages, ok := cached.([]ChatMessage); ok {
+			return messages, nil
+		}
+	}
+	return c.GetSessionHistory(ctx, sessionID)
+}
+
+func sessionHistoryCacheKey(sessionID string) string {
+	return "session-history/" + sessionID
+}
+
+// dedupeChatMessages keeps the last event for each message ID while
+// preserving the order in which messages first appeared
+func dedupeChatMessages(messages []ChatMessage) []ChatMessage {
+	index := make(map[string]int, len(messages))
+	result := make([]ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.MessageID == "" {
+			result = append(result, msg)
+			continue
+		}
+		if i, ok := index[msg.MessageID]; ok {
+			result[i] = msg
+			continue
+		}
+		index[msg.MessageID] = len(result)
+		result = append(result, msg)
+	}
+	return result
+}
+
 // SendInlineAgentMessage sends a message to an inline agent with custom tools
 func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID string, context map[string]string, agentDef map[string]interface{}) (<-chan ChatMessage, error) {
 	messagesChan := make(chan ChatMessage, 100)
@@ -762,6 +838,12 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 		sessionID = uuid.New().String()
 	}
 
+	message, err := c.preparePrompt(ctx, "", sessionID, contextMsg.String())
+	if err != nil {
+		close(messagesChan)
+		return nil, err
+	}
+
 	// Log session and user details
 	if logger != nil {
 		logger.Printf("Session ID: %s", sessionID)
@@ -780,17 +862,21 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 	}
 
 	payload := struct {
-		Message   string                 `json:"message"`
-		SessionID string                 `json:"session_id"`
-		UserEmail string                 `json:"user_email"`
-		Org       string                 `json:"org"`
-		Agent     map[string]interface{} `json:"agent"`
+		Message      string                 `json:"message"`
+		SessionID    string                 `json:"session_id"`
+		UserEmail    string                 `json:"user_email"`
+		Org          string                 `json:"org"`
+		Agent        map[string]interface{} `json:"agent"`
+		Permissions  *ChatPermissions       `json:"permissions,omitempty"`
+		EnvOverrides map[string]string      `json:"env_overrides,omitempty"`
 	}{
-		Message:   contextMsg.String(),
-		SessionID: sessionID,
-		UserEmail: userEmail,
-		Org:       org,
-		Agent:     agentDef,
+		Message:      message,
+		SessionID:    sessionID,
+		UserEmail:    userEmail,
+		Org:          org,
+		Agent:        agentDef,
+		Permissions:  c.chatPermissions,
+		EnvOverrides: c.chatEnvOverrides,
 	}
 
 	reqURL := fmt.Sprintf("%s/hb/v4/stream", c.baseURL)
@@ -849,7 +935,7 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 		logger.Printf("Request body length: %d bytes", len(jsonData))
 	}
 
-	client := &http.Client{Timeout: 0}
+	client := httpclient.New(0)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -974,19 +1060,7 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 			line := scanner.Text()
 			lastActivityTime = time.Now()
 
-			if logger != nil {
-				logger.Printf("Processing line %d: %s", lineCount, line)
-			}
-
-			// Print raw events for debugging (when debug mode is enabled)
-			if os.Getenv("KUBIYA_DEBUG") == "1" || os.Getenv("DEBUG") == "1" || os.Getenv("KUBIYA_RAW_EVENTS") == "1" {
-				fmt.Printf("[RAW STREAM EVENT] %s\n", line)
-			}
-
-			// Also log to file for detailed debugging
-			if logger != nil {
-				logger.Printf("[STREAM] Line %d: %s", lineCount, line)
-			}
+			sseLog.Trace("raw stream event", "line", lineCount, "event", line)
 
 			// Handle empty lines gracefully
 			if strings.TrimSpace(line) == "" {
@@ -1034,7 +1108,10 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 					}
 					continue
 				}
-				// Process data parts if needed
+				// Files sent as data are forwarded as file messages
+				for _, m := range fileMessages(typ, payload, sessionID) {
+					messagesChan <- m
+				}
 
 			case '3': // partError
 				var errMsg string
@@ -1067,10 +1144,7 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 					finishReason = reason
 				}
 
-				// Print raw finish data for debugging
-				if os.Getenv("KUBIYA_DEBUG") == "1" || os.Getenv("DEBUG") == "1" {
-					fmt.Printf("[FINISH DATA] %+v\n", finishData)
-				}
+				sseLog.Debug("stream finished", "data", finishData)
 
 				messagesChan <- ChatMessage{
 					Content:      textBuilder.String(),
@@ -1094,6 +1168,11 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 					}
 				}
 
+			case 'k': // partFile
+				for _, m := range fileMessages(typ, payload, sessionID) {
+					messagesChan <- m
+				}
+
 			case 'g': // partReasoning
 				var reasoning string
 				if err := json.Unmarshal([]byte(payload), &reasoning); err != nil {
@@ -1128,6 +1207,11 @@ func (c *Client) SendInlineAgentMessage(ctx context.Context, message, sessionID
 					if toolCallID == "" {
 						toolCallID = uuid.New().String()
 					}
+					// Hold the stream until the call is acknowledged
+					if denied := c.acknowledgeToolCall(ctx, ToolCall{ID: toolCallID, Name: toolName, Arguments: argsStr}, sessionID); denied != nil {
+						messagesChan <- *denied
+						return
+					}
 					messagesChan <- ChatMessage{
 						Content:    fmt.Sprintf("Tool: %s\nArguments: %s", toolName, argsStr),
 						Type:       "tool",
@@ -0,0 +1,8 @@
+package kubiya
+
+// SetChatEnvOverrides sets environment variables that override those of the
+// agent for the chat messages sent afterwards, without changing the agent.
+// Messages carry no overrides when env is empty.
+func (c *Client) SetChatEnvOverrides(env map[string]string) {
+	c.chatEnvOverrides = env
+}
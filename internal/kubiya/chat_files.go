@@ -0,0 +1,65 @@
+package kubiya
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatFile is a file sent by an agent, as carried by file stream parts and
+// file messages: Data is base64 encoded or a data URL
+type ChatFile struct {
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// fileMessages returns the file messages of a stream part: a file part is a
+// single file, a data part may hold files among other data
+func fileMessages(typ byte, payload, sessionID string) []ChatMessage {
+	var files []ChatFile
+	switch typ {
+	case 'k':
+		var f ChatFile
+		if err := json.Unmarshal([]byte(payload), &f); err == nil {
+			files = append(files, f)
+		}
+	case '2':
+		var items []json.RawMessage
+		if err := json.Unmarshal([]byte(payload), &items); err == nil {
+			for _, item := range items {
+				var f struct {
+					ChatFile
+					Filename string `json:"filename"`
+				}
+				if json.Unmarshal(item, &f) == nil {
+					if f.Name == "" {
+						f.Name = f.Filename
+					}
+					files = append(files, f.ChatFile)
+				}
+			}
+		}
+	}
+
+	var messages []ChatMessage
+	for _, f := range files {
+		if f.Data == "" || f.MimeType == "" {
+			continue
+		}
+		content, err := json.Marshal(f)
+		if err != nil {
+			continue
+		}
+		messages = append(messages, ChatMessage{
+			Content:    string(content),
+			Type:       "file",
+			Timestamp:  time.Now().Format(time.RFC3339),
+			SenderName: "Bot",
+			SessionID:  sessionID,
+			MessageID:  "file-" + uuid.New().String(),
+		})
+	}
+	return messages
+}
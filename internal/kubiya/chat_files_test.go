@@ -0,0 +1,28 @@
+package kubiya
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMessages(t *testing.T) {
+	msgs := fileMessages('k', `{"mimeType":"image/png","data":"iVBORw0K"}`, "s1")
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "file", msgs[0].Type)
+	assert.Equal(t, "s1", msgs[0].SessionID)
+	var f ChatFile
+	require.NoError(t, json.Unmarshal([]byte(msgs[0].Content), &f))
+	assert.Equal(t, ChatFile{MimeType: "image/png", Data: "iVBORw0K"}, f)
+
+	// Data parts carry files among other data
+	msgs = fileMessages('2', `[{"progress":50},{"mimeType":"text/csv","data":"YSxi","filename":"report.csv"}]`, "s1")
+	require.Len(t, msgs, 1)
+	require.NoError(t, json.Unmarshal([]byte(msgs[0].Content), &f))
+	assert.Equal(t, "report.csv", f.Name)
+
+	assert.Empty(t, fileMessages('2', `{"not":"a list"}`, "s1"))
+	assert.Empty(t, fileMessages('0', `"text"`, "s1"))
+}
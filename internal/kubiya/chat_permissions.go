@@ -0,0 +1,38 @@
+package kubiya
+
+import "fmt"
+
+// Permission levels of a chat session
+const (
+	PermissionRead      = "read"
+	PermissionReadWrite = "readwrite"
+	PermissionAsk       = "ask"
+)
+
+// ChatPermissions is the permission profile sent with chat messages, which
+// the agent is expected to enforce. The CLI cannot enforce it: it can only
+// stop reading the stream when a tool call is refused.
+type ChatPermissions struct {
+	Level               string   `json:"level"`
+	AllowedOperations   []string `json:"allowed_operations"`
+	RequireConfirmation bool     `json:"require_confirmation"`
+}
+
+// NewChatPermissions returns the profile of a permission level
+func NewChatPermissions(level string) (*ChatPermissions, error) {
+	switch level {
+	case PermissionRead:
+		return &ChatPermissions{Level: level, AllowedOperations: []string{"read"}}, nil
+	case PermissionReadWrite:
+		return &ChatPermissions{Level: level, AllowedOperations: []string{"read", "write"}}, nil
+	case PermissionAsk:
+		return &ChatPermissions{Level: level, AllowedOperations: []string{"read", "write"}, RequireConfirmation: true}, nil
+	}
+	return nil, fmt.Errorf("invalid permission level: %s (must be '%s', '%s', or '%s')", level, PermissionRead, PermissionReadWrite, PermissionAsk)
+}
+
+// SetChatPermissions sets the permission profile of the chat messages sent
+// afterwards. Messages carry no profile when permissions is nil.
+func (c *Client) SetChatPermissions(permissions *ChatPermissions) {
+	c.chatPermissions = permissions
+}
@@ -0,0 +1,56 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSendMessagePermissions(t *testing.T) {
+	var payload map[string]json.RawMessage
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+	})
+
+	drain := func() {
+		messages, err := client.SendMessage(context.Background(), "agent-1", "show pods", "session-1")
+		if err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+		for range messages {
+		}
+	}
+
+	drain()
+	if _, ok := payload["permissions"]; ok {
+		t.Errorf("payload has permissions without a profile: %s", payload["permissions"])
+	}
+
+	permissions, err := NewChatPermissions(PermissionRead)
+	if err != nil {
+		t.Fatalf("NewChatPermissions() error = %v", err)
+	}
+	client.SetChatPermissions(permissions)
+	drain()
+
+	var sent ChatPermissions
+	if err := json.Unmarshal(payload["permissions"], &sent); err != nil {
+		t.Fatalf("payload permissions = %s: %v", payload["permissions"], err)
+	}
+	if sent.Level != PermissionRead || len(sent.AllowedOperations) != 1 || sent.AllowedOperations[0] != "read" || sent.RequireConfirmation {
+		t.Errorf("sent permissions = %+v", sent)
+	}
+}
+
+func TestNewChatPermissions(t *testing.T) {
+	ask, err := NewChatPermissions(PermissionAsk)
+	if err != nil || !ask.RequireConfirmation {
+		t.Errorf("NewChatPermissions(ask) = %+v, %v", ask, err)
+	}
+	if _, err := NewChatPermissions("admin"); err == nil {
+		t.Error("NewChatPermissions(admin) succeeded, want an error")
+	}
+}
@@ -9,13 +9,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
+	klog "github.com/kubiyabot/cli/internal/log"
 	sentryutil "github.com/kubiyabot/cli/internal/sentry"
 )
 
@@ -27,56 +29,70 @@ type fileState struct {
 
 // Client represents a Kubiya API client
 type Client struct {
-	cfg     *config.Config
-	client  *http.Client
-	baseURL string
-	debug   bool
-	cache   *Cache
-	audit   *AuditClient
+	cfg      *config.Config
+	client   *http.Client
+	baseURL  string
+	debug    bool
+	cache    *Cache
+	audit    *AuditClient
+	throttle *RateLimitRoundTripper
+	// conditional revalidates listings, nil when caching is disabled
+	conditional *ConditionalRoundTripper
+
+	// toolCallGate acknowledges tool calls of chat streams
+	toolCallGate ToolCallGate
+	// chatPermissions is sent with chat messages
+	chatPermissions *ChatPermissions
+	// chatEnvOverrides is sent with chat messages
+	chatEnvOverrides map[string]string
+	// promptHook may rewrite or reject chat messages before they are sent
+	promptHook PromptHook
 }
 
-// logAPICall logs all API calls to /tmp/klog.txt
-func logAPICall(method, url string, headers map[string]string, body []byte, responseStatus int, responseBody []byte) {
-	if os.Getenv("KUBIYA_DEBUG") != "true" {
-		return // Only log if KUBIYA_DEBUG is set to true
-	}
-	f, err := os.OpenFile("/tmp/klog.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return // Silently fail if we can't log
-	}
-	defer f.Close()
-
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+var httpLog = klog.For(klog.HTTP)
 
-	logEntry := fmt.Sprintf("\n=== API Call %s ===\n", timestamp)
-	logEntry += fmt.Sprintf("Method: %s\n", method)
-	logEntry += fmt.Sprintf("URL: %s\n", url)
-	logEntry += fmt.Sprintf("Headers: %v\n", headers)
-	if body != nil {
-		logEntry += fmt.Sprintf("Request Body: %s\n", string(body))
-	}
-	logEntry += fmt.Sprintf("Response Status: %d\n", responseStatus)
-	if responseBody != nil {
-		logEntry += fmt.Sprintf("Response Body: %s\n", string(responseBody))
+// logAPICall logs an API call to the http subsystem, with its headers and
+// bodies at the trace level
+func logAPICall(method, url string, headers map[string]string, body []byte, responseStatus int, responseBody []byte) {
+	httpLog.Debug("api call", "method", method, "url", url, "status", responseStatus)
+	if httpLog.Enabled(klog.LevelTrace) {
+		httpLog.Trace("api call details", "method", method, "url", url, "headers", headers,
+			"request", string(body), "response", string(responseBody))
 	}
-	logEntry += "=======================================\n"
-
-	f.WriteString(logEntry)
 }
 
 // NewClient creates a new Kubiya API client
 func NewClient(cfg *config.Config) *Client {
+	auth := NewAuthRoundTripper(cfg.APIKey)
+	auth.Transport = faultInjectedTransport(httpclient.Decompress(auth.Transport), cfg.FaultInject)
+	var conditional *ConditionalRoundTripper
+	if cfg.Preferences.CacheEnabled() {
+		// Revalidate listings with their ETag, under the Authorization header
+		conditional = NewConditionalRoundTripper(auth.Transport, DefaultETagStore())
+		auth.Transport = conditional
+	}
+	throttle := NewRateLimitRoundTripper(
+		auth,
+		sharedRateLimiter(cfg.ContextName, cfg.RateLimit, cfg.RateBurst),
+	)
+	streams := NewResumableStreamRoundTripper(throttle)
+	streams.IdleTimeout = cfg.StreamIdleTimeout
 	client := &Client{
 		cfg:     cfg,
 		baseURL: cfg.BaseURL,
 		debug:   cfg.Debug,
 		client: &http.Client{
 			Timeout:   30 * time.Second,
-			Transport: NewAuthRoundTripper(cfg.APIKey),
+			Transport: streams,
 		},
-		cache: NewCache(5 * time.Minute),
+		cache:       NewCache(cfg.Preferences.CacheTTL(5 * time.Minute)),
+		throttle:    throttle,
+		conditional: conditional,
 	}
 	client.audit = NewAuditClient(client)
+	if chat := cfg.Preferences.Chat; chat != nil && chat.PreSendHook != "" {
+		client.promptHook = ScriptPromptHook(chat.PreSendHook)
+	}
 	return client
 }
 
@@ -85,6 +101,25 @@ func (c *Client) Audit() *AuditClient {
 	return c.audit
 }
 
+// CacheStats reports how many listings were answered from the ETag cache
+func (c *Client) CacheStats() CacheStats {
+	if c.conditional == nil {
+		return CacheStats{}
+	}
+	return c.conditional.Stats()
+}
+
+// ThrottleStats reports how much the client was slowed down by rate limiting
+func (c *Client) ThrottleStats() ThrottleStats {
+	return c.throttle.Stats()
+}
+
+// WrapTransport wraps the transport of every API request, outside of
+// authentication, caching and rate limiting
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.client.Transport = wrap(c.client.Transport)
+}
+
 // do performs an HTTP request and decodes the response into v
 func (c *Client) do(req *http.Request, v interface{}) error {
 	req.Header.Set("Content-Type", "application/json")
@@ -96,7 +131,8 @@ func (c *Client) do(req *http.Request, v interface{}) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if v != nil {
@@ -120,7 +156,8 @@ func (c *Client) doRaw(req *http.Request) ([]byte, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	return io.ReadAll(resp.Body)
 }
@@ -178,7 +215,7 @@ func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(responseBody)}
 	}
 
 	return resp, nil
@@ -303,7 +340,7 @@ func (c *Client) put(ctx context.Context, path string, payload interface{}) (*ht
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(responseBody))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(responseBody)}
 	}
 
 	return resp, nil
@@ -715,7 +752,7 @@ func (c *Client) ListRunners(ctx context.Context) ([]Runner, error) {
 
 // GetRunnerManifest retrieves a runner's manifest
 func (c *Client) GetRunnerManifest(ctx context.Context, name string) (*RunnerManifest, error) {
-	req, err := c.newJSONRequest(ctx, "GET", fmt.Sprintf("/runners/%s/manifest", name), nil)
+	req, err := c.newJSONRequest(ctx, "GET", fmt.Sprintf("%s/runners/%s/manifest", c.baseURL, name), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -823,23 +860,32 @@ func (c *Client) DownloadManifest(ctx context.Context, url string) ([]byte, erro
 	return content, nil
 }
 
-// ListUsers retrieves all users in the organization
+// usersPageSize is the number of users requested per page
+const usersPageSize = 100
+
+// ListUsers retrieves all users in the organization, page by page
 func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
 	// The baseURL is like https://api.kubiya.ai/api/v1, we need https://api.kubiya.ai/api/v2/users
 	baseURL := strings.Replace(c.baseURL, "/v1", "/v2", 1)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/users?limit=100&page=1", nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
 
-	var response struct {
-		Items []User `json:"items"`
-	}
-	if err := c.do(req, &response); err != nil {
-		return nil, err
-	}
+	var users []User
+	for page := 1; ; page++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/users?limit=%d&page=%d", baseURL, usersPageSize, page), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	return response.Items, nil
+		var response struct {
+			Items []User `json:"items"`
+		}
+		if err := c.do(req, &response); err != nil {
+			return nil, err
+		}
+		users = append(users, response.Items...)
+		if len(response.Items) < usersPageSize {
+			return users, nil
+		}
+	}
 }
 
 // ListGroups retrieves all groups in the organization
@@ -889,6 +935,9 @@ func (c *Client) CreateAgent(ctx context.Context, agent Agent) (*Agent, error) {
 	if agent.Environment != nil && len(agent.Environment) > 0 {
 		payload["environment_variables"] = agent.Environment
 	}
+	if len(agent.Starters) > 0 {
+		payload["starters"] = agent.Starters
+	}
 
 	// Debug output for the request
 	if c.debug {
@@ -921,8 +970,11 @@ func (c *Client) CreateAgent(ctx context.Context, agent Agent) (*Agent, error) {
 	}
 
 	// Check for error status codes
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity {
+		return nil, ParseValidationError(resp.StatusCode, bodyBytes)
+	}
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("agent creation failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("agent creation failed: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(bodyBytes)})
 	}
 
 	// Unmarshal into created agent
@@ -992,6 +1044,9 @@ func (c *Client) UpdateAgent(ctx context.Context, uuid string, agent Agent) (*Ag
 	if agent.Environment != nil && len(agent.Environment) > 0 {
 		payload["environment_variables"] = agent.Environment
 	}
+	if len(agent.Starters) > 0 {
+		payload["starters"] = agent.Starters
+	}
 
 	// Debug output
 	if c.debug {
@@ -1069,6 +1124,20 @@ func (c *Client) BindSourceToAgent(ctx context.Context, sourceUUID, agentUUID st
 	return nil
 }
 
+// UnbindSourceFromAgent detaches a source from an agent
+func (c *Client) UnbindSourceFromAgent(ctx context.Context, sourceUUID, agentUUID string) error {
+	resp, err := c.delete(ctx, fmt.Sprintf("/sources/%s/agents/%s", sourceUUID, agentUUID))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to unbind source from agent: %s", resp.Status)
+	}
+	return nil
+}
+
 // Example method: listing agents for a particular source
 func (c *Client) GetSourceAgents(ctx context.Context, sourceUUID string) ([]Agent, error) {
 	// Get all agents first
@@ -1174,12 +1243,14 @@ func (c *Client) SyncSource(ctx context.Context, sourceID string, opts SyncOptio
 		Force      bool   `json:"force,omitempty"`
 		AutoCommit bool   `json:"auto_commit,omitempty"`
 		NoDiff     bool   `json:"no_diff,omitempty"`
+		Ref        string `json:"ref,omitempty"`
 	}{
 		Mode:       opts.Mode,
 		Branch:     opts.Branch,
 		Force:      opts.Force,
 		AutoCommit: opts.AutoCommit,
 		NoDiff:     opts.NoDiff,
+		Ref:        opts.Ref,
 	}
 
 	url := fmt.Sprintf("/sources/%s/sync", sourceID)
@@ -1252,9 +1323,7 @@ func (c *Client) GenerateTool(ctx context.Context, description, sessionID string
 		req.Header.Set("Connection", "keep-alive")
 
 		// Execute request with extended timeout for long-running tools
-		httpClient := &http.Client{
-			Timeout: 0, // No timeout for streaming connections
-		}
+		httpClient := httpclient.New(0) // No timeout for streaming connections
 		resp, err := httpClient.Do(req)
 		if err != nil {
 			messages <- ToolGenerationChatMessage{Type: "error", GeneratedToolContent: []GeneratedToolContent{{Content: fmt.Sprintf("failed to execute request: %v", err)}}}
@@ -1898,6 +1967,10 @@ func validateVariableType(value, expectedType string) (bool, string) {
 
 // isRunnerHealthy checks if a runner is healthy based on various status fields
 func (c *Client) isRunnerHealthy(runner Runner) bool {
+	return runnerHealthy(runner)
+}
+
+func runnerHealthy(runner Runner) bool {
 	// Check various status fields that indicate health
 	status := strings.ToLower(runner.RunnerHealth.Status)
 	health := strings.ToLower(runner.RunnerHealth.Health)
@@ -1908,38 +1981,22 @@ func (c *Client) isRunnerHealthy(runner Runner) bool {
 		(status == "" && health == "") // Sometimes no status means it's running fine
 }
 
-// findHealthyRunnerQuickly attempts to quickly find a healthy runner with short timeouts
+// findHealthyRunnerQuickly picks the best healthy runner by probe and recent
+// execution latency, with short timeouts
 func (c *Client) findHealthyRunnerQuickly(ctx context.Context) (string, error) {
 	// Create a context with a short timeout for the entire operation
 	quickCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	// Get list of runners quickly
-	runners, err := c.ListRunners(quickCtx)
-	if err != nil {
-		return "", fmt.Errorf("failed to list runners quickly: %w", err)
+	opts := RunnerSelectOptions{ProbeTimeout: time.Second}
+	if history, err := LoadRunnerLatencyHistory(); err == nil {
+		opts.History = history
 	}
-
-	// Define priority order for runners
-	priorityRunners := []string{"kubiya-hosted", "kubiya-hosted-1", "kubiya-cloud"}
-
-	// First, check priority runners
-	for _, runnerName := range priorityRunners {
-		for _, runner := range runners {
-			if runner.Name == runnerName && c.isRunnerHealthy(runner) {
-				return runner.Name, nil
-			}
-		}
-	}
-
-	// Then check any other healthy runner
-	for _, runner := range runners {
-		if c.isRunnerHealthy(runner) {
-			return runner.Name, nil
-		}
+	ranked, err := c.SelectRunner(quickCtx, opts)
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("no healthy runners found")
+	return ranked[0].Runner.Name, nil
 }
 
 // ExecuteToolWithTimeout executes a tool directly using the tool execution API with a configurable timeout
@@ -2007,6 +2064,10 @@ func (c *Client) executeToolWithTimeoutInternal(ctx context.Context, toolName st
 		"tool_name": toolName,
 		"tool_def":  toolDef,
 		"args":      args,
+		// Passed on to the runner, to find the execution from the CLI invocation
+		"metadata": map[string]interface{}{
+			"correlation_id": correlation.ID(),
+		},
 	}
 
 	// If auto was selected and we're using a fallback runner, prepare a list of runners to try
@@ -2071,6 +2132,7 @@ func (c *Client) executeToolWithTimeoutInternal(ctx context.Context, toolName st
 		req.Header.Set("x-vercel-ai-data-stream", "v1") // protocol flag
 		req.Header.Set("Cache-Control", "no-cache")
 		req.Header.Set("Connection", "keep-alive")
+		correlation.Set(req)
 
 		// Use a shorter timeout for initial connection to fail fast
 		connectTimeout := 10 * time.Second
@@ -2079,12 +2141,12 @@ func (c *Client) executeToolWithTimeoutInternal(ctx context.Context, toolName st
 		}
 
 		// Execute request with shorter timeout for connection
-		httpClient := &http.Client{
-			Timeout: connectTimeout,
-		}
+		httpClient := httpclient.New(connectTimeout)
+		httpClient.Transport = NewResumableStreamRoundTripper(
+			faultInjectedTransport(httpClient.Transport, c.cfg.FaultInject))
 		resp, err := httpClient.Do(req)
 		if err != nil {
-			lastErr = fmt.Errorf("runner %s: failed to connect: %w", tryRunner, err)
+			lastErr = &ToolExecutionError{Runner: tryRunner, Err: err}
 			// Log the failed API call
 			headers := map[string]string{
 				"Authorization": "UserKey [REDACTED]",
@@ -2092,8 +2154,8 @@ func (c *Client) executeToolWithTimeoutInternal(ctx context.Context, toolName st
 				"Accept":        "text/event-stream",
 			}
 			logAPICall("POST", execURL, headers, jsonBody, 0, []byte(fmt.Sprintf("Connection failed: %v", err)))
-			// Check if it's retryable
-			if attemptIndex < maxAttempts-1 && runner == "auto" {
+			// Retry only when the tool certainly did not start
+			if attemptIndex < maxAttempts-1 && runner == "auto" && IsRunnerUnavailable(lastErr) {
 				continue
 			}
 			return nil, lastErr
@@ -2109,15 +2171,12 @@ func (c *Client) executeToolWithTimeoutInternal(ctx context.Context, toolName st
 				"Accept":        "text/event-stream",
 			}
 			logAPICall("POST", execURL, headers, jsonBody, resp.StatusCode, body)
-			lastErr = fmt.Errorf("runner %s: execution failed with status %d: %s", tryRunner, resp.StatusCode, string(body))
-
-			// Don't retry on client errors (4xx)
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-				return nil, lastErr
-			}
+			lastErr = &ToolExecutionError{Runner: tryRunner, StatusCode: resp.StatusCode, Body: string(body)}
 
-			// Retry on server errors if we have more runners
-			if attemptIndex < maxAttempts-1 && runner == "auto" && resp.StatusCode >= 500 {
+			// Retry only when the runner could not be reached: client
+			// errors won't go away and other server errors may come from a
+			// tool that already started
+			if attemptIndex < maxAttempts-1 && runner == "auto" && IsRunnerUnavailable(lastErr) {
 				continue
 			}
 
@@ -2135,10 +2194,7 @@ func (c *Client) executeToolWithTimeoutInternal(ctx context.Context, toolName st
 		// Success! Create streaming channel
 		events, streamErr := c.streamToolExecution(resp, timeout, tryRunner)
 		if streamErr != nil {
-			lastErr = streamErr
-			if attemptIndex < maxAttempts-1 && runner == "auto" {
-				continue
-			}
+			// The tool has started, running it elsewhere could run it twice
 			return nil, streamErr
 		}
 
@@ -2254,8 +2310,12 @@ func (c *Client) streamToolExecution(resp *http.Response, timeout time.Duration,
 					return
 				}
 
-				// Send data event
-				events <- WorkflowSSEEvent{Type: "data", Data: data}
+				// Send data event, tagging tool output with its stream
+				event := WorkflowSSEEvent{Type: "data", Data: data}
+				if out, ok := ParseToolOutput(data); ok {
+					event.Stream = out.Stream
+				}
+				events <- event
 			} else if strings.HasPrefix(line, "event: ") {
 				eventType := strings.TrimPrefix(line, "event: ")
 
@@ -2314,3 +2374,35 @@ func (c *Client) GetGitHubToken(ctx context.Context) (string, error) {
 
 	return "", fmt.Errorf("no active GitHub integration found")
 }
+
+// SlackIntegration represents the Slack integration of the organization
+type SlackIntegration struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	TeamID      string `json:"team_id"`
+	AccessToken string `json:"access_token"`
+	Status      string `json:"status"`
+}
+
+// GetSlackToken retrieves the bot token of the Slack integration
+func (c *Client) GetSlackToken(ctx context.Context) (string, error) {
+	resp, err := c.get(ctx, "/api/v2/integrations/slack")
+	if err != nil {
+		return "", fmt.Errorf("failed to get Slack integration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var integrations []SlackIntegration
+	if err := json.NewDecoder(resp.Body).Decode(&integrations); err != nil {
+		return "", fmt.Errorf("failed to decode Slack integration response: %w", err)
+	}
+
+	for _, integration := range integrations {
+		if integration.Status == "active" && integration.AccessToken != "" {
+			return integration.AccessToken, nil
+		}
+	}
+
+	return "", fmt.Errorf("no active Slack integration found")
+}
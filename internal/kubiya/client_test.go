@@ -2,9 +2,12 @@ package kubiya
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kubiyabot/cli/internal/config"
 )
@@ -85,4 +88,141 @@ func TestListAgents(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+} 
+
+func TestGetSessionHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			name:     "bare list",
+			response: `[{"message_id":"1","content":"hi","sender_name":"You"},{"message_id":"2","content":"Hel"},{"message_id":"2","content":"Hello"}]`,
+		},
+		{
+			name:     "wrapped list",
+			response: `{"messages":[{"message_id":"1","content":"hi","sender_name":"You"},{"message_id":"2","content":"Hello"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/hb/v4/sessions/session-1/messages" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tt.response))
+			})
+
+			messages, err := client.GetSessionHistory(context.Background(), "session-1")
+			if err != nil {
+				t.Fatalf("GetSessionHistory() error = %v", err)
+			}
+			if len(messages) != 2 {
+				t.Fatalf("expected 2 messages, got %d", len(messages))
+			}
+			if messages[1].Content != "Hello" {
+				t.Errorf("expected latest streamed content, got %q", messages[1].Content)
+			}
+		})
+	}
+}
+
+func TestGetSessionHistoryCached(t *testing.T) {
+	requests := 0
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"message_id":"1","content":"hi","sender_name":"You"}]`))
+	})
+
+	for i := 0; i < 2; i++ {
+		messages, err := client.GetSessionHistoryCached(context.Background(), "session-1")
+		if err != nil {
+			t.Fatalf("GetSessionHistoryCached() error = %v", err)
+		}
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(messages))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected history to be fetched once, got %d requests", requests)
+	}
+
+	// Fetching another session is not answered from the cache
+	if _, err := client.GetSessionHistoryCached(context.Background(), "session-2"); err != nil {
+		t.Fatalf("GetSessionHistoryCached() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestListKnowledgeVersions(t *testing.T) {
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/knowledge/kb-1/versions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"version":1,"name":"a"},{"version":3,"name":"c"},{"version":2,"name":"b"}]`))
+	})
+
+	versions, err := client.ListKnowledgeVersions(context.Background(), "kb-1")
+	if err != nil {
+		t.Fatalf("ListKnowledgeVersions() error = %v", err)
+	}
+	if len(versions) != 3 || versions[0].Version != 3 || versions[2].Version != 1 {
+		t.Errorf("expected versions newest first, got %+v", versions)
+	}
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/event/wh-1/deliveries" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("since"); got != "2024-05-01T12:00:00Z" {
+			t.Errorf("since = %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"id":"a","timestamp":"2024-05-01T12:01:00Z","status":"success"},{"id":"b","timestamp":"2024-05-01T12:05:00Z","status":"failed"}]`))
+	})
+
+	deliveries, err := client.ListWebhookDeliveries(context.Background(), "wh-1", since)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries() error = %v", err)
+	}
+	if len(deliveries) != 2 || deliveries[0].ID != "b" {
+		t.Errorf("expected deliveries newest first, got %+v", deliveries)
+	}
+}
+
+func TestListUsersPages(t *testing.T) {
+	var pages []string
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		count := usersPageSize
+		if page == "2" {
+			count = 3
+		}
+		items := make([]User, count)
+		for i := range items {
+			items[i] = User{UUID: fmt.Sprintf("%s-%d", page, i)}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	})
+
+	users, err := client.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if len(users) != usersPageSize+3 {
+		t.Errorf("expected %d users, got %d", usersPageSize+3, len(users))
+	}
+	if len(pages) != 2 || pages[0] != "1" || pages[1] != "2" {
+		t.Errorf("expected pages 1 and 2 to be requested, got %v", pages)
+	}
+}
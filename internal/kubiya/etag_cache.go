@@ -0,0 +1,203 @@
+package kubiya
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+)
+
+// conditionalPaths are the listings of sources, tools and agents that are
+// revalidated with If-None-Match / If-Modified-Since
+var conditionalPaths = regexp.MustCompile(`/api/v[13]/(sources|tools|agents)(/|$)`)
+
+// etagEntry is a response kept with its validators
+type etagEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// ETagStore keeps responses with their ETag on disk, a file per URL and
+// credentials, so they outlive the process
+type ETagStore struct {
+	dir string
+}
+
+// NewETagStore returns a store keeping its entries in dir
+func NewETagStore(dir string) *ETagStore {
+	return &ETagStore{dir: dir}
+}
+
+// DefaultETagStore returns the store in ~/.kubiya/cache/http, or nil
+// without a home directory
+func DefaultETagStore() *ETagStore {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return NewETagStore(filepath.Join(homeDir, ".kubiya", "cache", "http"))
+}
+
+// key keeps the responses of different users apart
+func (s *ETagStore) key(url, authorization string) string {
+	sum := sha256.Sum256([]byte(authorization + "\n" + url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *ETagStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+func (s *ETagStore) load(key string) *etagEntry {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry etagEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// save writes the entry through a temporary file so concurrent commands
+// never read half of it. Entries may hold secrets: they are private to the
+// user.
+func (s *ETagStore) save(key string, entry *etagEntry) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+func (s *ETagStore) remove(key string) {
+	os.Remove(s.path(key))
+}
+
+// Clear removes all entries
+func (s *ETagStore) Clear() error {
+	return os.RemoveAll(s.dir)
+}
+
+// CacheStats counts how listings were served
+type CacheStats struct {
+	Revalidated int64 // 304 responses answered from the store
+	Stored      int64 // responses stored with their validators
+}
+
+// ConditionalRoundTripper sends the listings of sources, tools and agents
+// as conditional requests. A 304 response is answered from the store as the
+// 200 response it stood for, so callers do not see the difference.
+type ConditionalRoundTripper struct {
+	Transport http.RoundTripper
+	Store     *ETagStore
+
+	revalidated atomic.Int64
+	stored      atomic.Int64
+}
+
+// NewConditionalRoundTripper wraps transport; a nil store disables it
+func NewConditionalRoundTripper(transport http.RoundTripper, store *ETagStore) *ConditionalRoundTripper {
+	return &ConditionalRoundTripper{Transport: transport, Store: store}
+}
+
+// Stats returns how many listings were revalidated and stored
+func (rt *ConditionalRoundTripper) Stats() CacheStats {
+	return CacheStats{Revalidated: rt.revalidated.Load(), Stored: rt.stored.Load()}
+}
+
+func (rt *ConditionalRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.Store == nil || req.Method != http.MethodGet || !conditionalPaths.MatchString(req.URL.Path) ||
+		req.Header.Get("Cache-Control") == "no-cache" {
+		return rt.Transport.RoundTrip(req)
+	}
+
+	key := rt.Store.key(req.URL.String(), req.Header.Get("Authorization"))
+	entry := rt.Store.load(key)
+	if entry != nil && (entry.ETag != "" || entry.LastModified != "") {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" && req.Header.Get("If-None-Match") == "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := rt.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		rt.revalidated.Add(1)
+		httpLog.Debug("not modified, using stored response", "path", req.URL.Path)
+
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		if entry.ContentType != "" {
+			resp.Header.Set("Content-Type", entry.ContentType)
+		}
+		resp.Header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+		resp.ContentLength = int64(len(entry.Body))
+		resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+		return resp, nil
+
+	case resp.StatusCode == http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			if entry != nil {
+				rt.Store.remove(key)
+			}
+			return resp, nil
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err := rt.Store.save(key, &etagEntry{
+			URL:          req.URL.String(),
+			ETag:         etag,
+			LastModified: lastModified,
+			ContentType:  resp.Header.Get("Content-Type"),
+			Body:         body,
+		}); err == nil {
+			rt.stored.Add(1)
+		} else {
+			httpLog.Debug("failed to store response", "path", req.URL.Path, "error", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,96 @@
+package kubiya
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/config"
+)
+
+func TestConditionalRoundTripper(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"uuid":"src-1","name":"tools"}]`)
+	}))
+	defer server.Close()
+
+	rt := NewConditionalRoundTripper(http.DefaultTransport, NewETagStore(t.TempDir()))
+	client := &http.Client{Transport: rt}
+
+	get := func(path string) string {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	first := get("/api/v1/sources")
+	second := get("/api/v1/sources")
+	if first != second || second != `[{"uuid":"src-1","name":"tools"}]` {
+		t.Errorf("bodies = %q, %q", first, second)
+	}
+	if notModified != 1 {
+		t.Errorf("304 responses = %d, want 1", notModified)
+	}
+	if stats := rt.Stats(); stats.Revalidated != 1 || stats.Stored != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Other paths are sent as they are
+	get("/api/v1/runners")
+	get("/api/v1/runners")
+	if notModified != 1 || requests != 4 {
+		t.Errorf("requests = %d, 304 responses = %d", requests, notModified)
+	}
+}
+
+func TestListSourcesRevalidated(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "UserKey test-key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[{"uuid":"src-1","name":"tools","url":"https://github.com/org/tools"}]`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{APIKey: "test-key", BaseURL: server.URL + "/api/v1"}
+	for i := 0; i < 2; i++ {
+		// A new client each time, like separate commands
+		sources, err := NewClient(cfg).ListSources(context.Background())
+		if err != nil {
+			t.Fatalf("ListSources() error = %v", err)
+		}
+		if len(sources) != 1 || sources[0].UUID != "src-1" {
+			t.Fatalf("sources = %+v", sources)
+		}
+	}
+	if notModified != 1 {
+		t.Errorf("304 responses = %d, want 1", notModified)
+	}
+}
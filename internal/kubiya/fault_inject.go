@@ -0,0 +1,224 @@
+package kubiya
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+)
+
+// FaultSpec describes the faults injected into API calls, to check that
+// automations built on the CLI survive an unreliable network. It is parsed
+// from KUBIYA_FAULT_INJECT or the hidden --fault-inject flag, e.g.
+// "stream_error:0.1,latency:2s".
+type FaultSpec struct {
+	StreamError float64       // probability a streamed response is cut mid-way
+	ConnError   float64       // probability a request fails to connect
+	HTTPError   float64       // probability a request is answered with 503
+	Latency     time.Duration // requests are delayed by up to this much
+	Seed        int64         // makes the faults reproducible when not 0
+}
+
+// Enabled reports whether any fault is injected
+func (s FaultSpec) Enabled() bool {
+	return s.StreamError > 0 || s.ConnError > 0 || s.HTTPError > 0 || s.Latency > 0
+}
+
+// ParseFaultSpec parses a comma separated list of fault:value pairs. The
+// faults are stream_error, conn_error and http_error with a probability
+// between 0 and 1, latency with a duration and seed with an integer.
+func ParseFaultSpec(spec string) (FaultSpec, error) {
+	var s FaultSpec
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return FaultSpec{}, fmt.Errorf("invalid fault %q: expected name:value", part)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		var err error
+		switch name {
+		case "stream_error":
+			s.StreamError, err = parseFaultProbability(value)
+		case "conn_error":
+			s.ConnError, err = parseFaultProbability(value)
+		case "http_error":
+			s.HTTPError, err = parseFaultProbability(value)
+		case "latency":
+			s.Latency, err = time.ParseDuration(value)
+			if err == nil && s.Latency < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "seed":
+			s.Seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			return FaultSpec{}, fmt.Errorf("unknown fault %q: use stream_error, conn_error, http_error, latency or seed", name)
+		}
+		if err != nil {
+			return FaultSpec{}, fmt.Errorf("invalid fault %q: %w", part, err)
+		}
+	}
+	return s, nil
+}
+
+func parseFaultProbability(value string) (float64, error) {
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 1 {
+		return 0, fmt.Errorf("probability must be between 0 and 1")
+	}
+	return p, nil
+}
+
+// FaultInjectionRoundTripper injects the faults of Spec into the requests
+// it sends. The errors injected are the retryable ones of a flaky network:
+// refused connections, 503 responses and streams reset mid-way.
+type FaultInjectionRoundTripper struct {
+	Transport http.RoundTripper
+	Spec      FaultSpec
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewFaultInjectionRoundTripper wraps transport, httpclient.Transport()
+// when nil
+func NewFaultInjectionRoundTripper(transport http.RoundTripper, spec FaultSpec) *FaultInjectionRoundTripper {
+	seed := spec.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultInjectionRoundTripper{
+		Transport: transport,
+		Spec:      spec,
+		rnd:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// faultInjectedTransport wraps transport with the faults of spec, when any
+func faultInjectedTransport(transport http.RoundTripper, spec string) http.RoundTripper {
+	if spec == "" {
+		return transport
+	}
+	faults, err := ParseFaultSpec(spec)
+	if err != nil || !faults.Enabled() {
+		return transport
+	}
+	return NewFaultInjectionRoundTripper(transport, faults)
+}
+
+func (rt *FaultInjectionRoundTripper) transport() http.RoundTripper {
+	if rt.Transport == nil {
+		return httpclient.Transport()
+	}
+	return rt.Transport
+}
+
+// roll reports whether a fault of probability p happens
+func (rt *FaultInjectionRoundTripper) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.rnd.Float64() < p
+}
+
+func (rt *FaultInjectionRoundTripper) randInt63n(n int64) int64 {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.rnd.Int63n(n)
+}
+
+func (rt *FaultInjectionRoundTripper) logf(format string, args ...interface{}) {
+	httpLog.Debugf("fault injection: "+format, args...)
+}
+
+func (rt *FaultInjectionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.Spec.Latency > 0 {
+		delay := time.Duration(rt.randInt63n(int64(rt.Spec.Latency) + 1))
+		rt.logf("delaying %s %s by %v", req.Method, req.URL.Path, delay)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if rt.roll(rt.Spec.ConnError) {
+		rt.logf("refusing connection for %s %s", req.Method, req.URL.Path)
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("dial tcp %s: connection refused (injected fault)", req.URL.Host)
+	}
+
+	if rt.roll(rt.Spec.HTTPError) {
+		rt.logf("answering %s %s with 503", req.Method, req.URL.Path)
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return &http.Response{
+			Status:     "503 Service Unavailable",
+			StatusCode: http.StatusServiceUnavailable,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"text/plain"}, "Retry-After": {"1"}},
+			Body:       io.NopCloser(strings.NewReader("service unavailable (injected fault)")),
+			Request:    req,
+		}, nil
+	}
+
+	resp, err := rt.transport().RoundTrip(req)
+	if err != nil || !isStreamExchange(req, resp) || !rt.roll(rt.Spec.StreamError) {
+		return resp, err
+	}
+	// Cut the stream somewhere in its first few kilobytes
+	cutAfter := rt.randInt63n(4096)
+	rt.logf("cutting the stream of %s %s after %d bytes", req.Method, req.URL.Path, cutAfter)
+	resp.Body = &faultyStream{body: resp.Body, remaining: cutAfter}
+	return resp, nil
+}
+
+// isStreamExchange reports whether resp streams its body, as event streams
+// and chat data streams do
+func isStreamExchange(req *http.Request, resp *http.Response) bool {
+	return resp.StatusCode == http.StatusOK &&
+		(isEventStream(resp) || req.Header.Get("x-vercel-ai-data-stream") != "" ||
+			strings.Contains(req.Header.Get("Accept"), "text/event-stream"))
+}
+
+// faultyStream fails with a connection reset once remaining bytes are read
+type faultyStream struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (s *faultyStream) Read(p []byte) (int, error) {
+	if s.remaining <= 0 {
+		return 0, fmt.Errorf("stream_error: read: connection reset by peer (injected fault)")
+	}
+	if int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+	n, err := s.body.Read(p)
+	s.remaining -= int64(n)
+	return n, err
+}
+
+func (s *faultyStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,102 @@
+package kubiya
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFaultSpec(t *testing.T) {
+	spec, err := ParseFaultSpec("stream_error:0.1, latency:2s,conn_error:0.05,http_error:1,seed:42")
+	require.NoError(t, err)
+	assert.Equal(t, FaultSpec{StreamError: 0.1, ConnError: 0.05, HTTPError: 1, Latency: 2 * time.Second, Seed: 42}, spec)
+	assert.True(t, spec.Enabled())
+
+	spec, err = ParseFaultSpec("")
+	require.NoError(t, err)
+	assert.False(t, spec.Enabled())
+
+	for _, invalid := range []string{"stream_error", "stream_error:1.5", "latency:fast", "latency:-1s", "drop:0.1", "seed:x"} {
+		_, err := ParseFaultSpec(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func streamServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFaultInjectionErrors(t *testing.T) {
+	srv := streamServer(t, "data: hello\n\n")
+
+	client := &http.Client{Transport: NewFaultInjectionRoundTripper(nil, FaultSpec{ConnError: 1})}
+	_, err := client.Get(srv.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+
+	client = &http.Client{Transport: NewFaultInjectionRoundTripper(nil, FaultSpec{HTTPError: 1})}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestFaultInjectionCutsStreams(t *testing.T) {
+	body := strings.Repeat("data: chunk\n\n", 1000)
+	srv := streamServer(t, body)
+
+	client := &http.Client{Transport: NewFaultInjectionRoundTripper(nil, FaultSpec{StreamError: 1, Seed: 7})}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	read, err := io.ReadAll(resp.Body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream_error")
+	assert.Less(t, len(read), len(body))
+	assert.True(t, strings.HasPrefix(body, string(read)))
+
+	// Responses that are not streamed are left alone
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer plain.Close()
+	resp, err = client.Get(plain.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	read, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(read))
+}
+
+func TestFaultInjectionLatency(t *testing.T) {
+	srv := streamServer(t, "data: hello\n\n")
+	rt := NewFaultInjectionRoundTripper(nil, FaultSpec{Latency: time.Hour, Seed: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFaultInjectedTransport(t *testing.T) {
+	base := http.DefaultTransport
+	assert.Equal(t, base, faultInjectedTransport(base, ""))
+	assert.Equal(t, base, faultInjectedTransport(base, "bogus"))
+	assert.IsType(t, &FaultInjectionRoundTripper{}, faultInjectedTransport(base, "latency:1ms"))
+}
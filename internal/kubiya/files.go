@@ -0,0 +1,139 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UploadedFile is a file stored in the platform's file storage
+type UploadedFile struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// UploadProgressFunc reports the number of bytes sent out of total
+type UploadProgressFunc func(sent, total int64)
+
+// UploadFile uploads a local file to the platform's file storage. The file is
+// streamed rather than buffered, so it may be larger than available memory.
+// progress, if set, is called as the upload advances.
+func (c *Client) UploadFile(ctx context.Context, path string, progress UploadProgressFunc) (*UploadedFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	name := filepath.Base(path)
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+		header.Set("Content-Type", contentType)
+		part, err := form.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(part, &progressReader{r: file, total: info.Size(), progress: progress})
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/v1/files", c.baseURL), body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	// Uploads are bounded by ctx rather than the default client timeout
+	uploadClient := &http.Client{Transport: c.client.Transport}
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to upload %s: unexpected status code: %d, body: %s", name, resp.StatusCode, string(bodyBytes))
+	}
+
+	var uploaded UploadedFile
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if uploaded.Name == "" {
+		uploaded.Name = name
+	}
+	if uploaded.Size == 0 {
+		uploaded.Size = info.Size()
+	}
+	if uploaded.ContentType == "" {
+		uploaded.ContentType = contentType
+	}
+	return &uploaded, nil
+}
+
+// AttachmentReference renders the note appended to a chat message so the
+// agent can retrieve uploaded files instead of receiving them inline
+func AttachmentReference(files []UploadedFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\nAttached files (stored in Kubiya file storage, fetch them by ID or URL when needed):\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- %s (id: %s, %s, %d bytes", f.Name, f.ID, f.ContentType, f.Size)
+		if f.URL != "" {
+			fmt.Fprintf(&b, ", url: %s", f.URL)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// progressReader reports read progress of an upload
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress UploadProgressFunc
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.sent += int64(n)
+	if p.progress != nil && n > 0 {
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
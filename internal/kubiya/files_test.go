@@ -0,0 +1,69 @@
+package kubiya
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	content := strings.Repeat("log line\n", 10000)
+	path := filepath.Join(t.TempDir(), "big.log")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/files" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "UserKey test-key" {
+			t.Errorf("Expected Authorization header, got %s", auth)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("failed to read form file: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if header.Filename != "big.log" || string(data) != content {
+			t.Errorf("unexpected upload %s (%d bytes)", header.Filename, len(data))
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"file-1","url":"https://files.example.com/file-1"}`))
+	})
+
+	var sent, total int64
+	uploaded, err := client.UploadFile(context.Background(), path, func(s, t int64) { sent, total = s, t })
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if uploaded.ID != "file-1" || uploaded.Name != "big.log" || uploaded.Size != int64(len(content)) {
+		t.Errorf("UploadFile() = %+v", uploaded)
+	}
+	if sent != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("progress = %d/%d, want %d", sent, total, len(content))
+	}
+
+	ref := AttachmentReference([]UploadedFile{*uploaded})
+	if !strings.Contains(ref, "big.log (id: file-1") || !strings.Contains(ref, "url: https://files.example.com/file-1") {
+		t.Errorf("AttachmentReference() = %q", ref)
+	}
+}
+
+func TestUploadFileError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.bin")
+	if err := os.WriteFile(path, []byte{0, 1, 2}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	})
+	if _, err := client.UploadFile(context.Background(), path, nil); err == nil {
+		t.Error("UploadFile() expected error")
+	}
+}
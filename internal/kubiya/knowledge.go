@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -305,6 +308,104 @@ func (c *Client) ListKnowledge(ctx context.Context, query string, limit int) ([]
 	return items, nil
 }
 
+// KnowledgeListOptions select a page of knowledge items
+type KnowledgeListOptions struct {
+	// Page is the page to fetch, from 1
+	Page     int
+	PageSize int
+	// Labels are labels the items must all have
+	Labels []string
+}
+
+// KnowledgePage is a page of knowledge items
+type KnowledgePage struct {
+	Items []Knowledge
+	// Total is the number of items of all pages, 0 when the API does not
+	// report it
+	Total int
+	// More is set when there are items after this page
+	More bool
+}
+
+// ListKnowledgePage returns a page of knowledge items. The API answers with
+// a list or with {"items": [...], "total": n}; labels are checked on the
+// items as well, in case the API ignores them.
+func (c *Client) ListKnowledgePage(ctx context.Context, opts KnowledgeListOptions) (*KnowledgePage, error) {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(opts.Page))
+	if opts.PageSize > 0 {
+		params.Set("limit", strconv.Itoa(opts.PageSize))
+	}
+	for _, label := range opts.Labels {
+		params.Add("label", label)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("%s/knowledge?%s", c.cfg.BaseURL, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var page struct {
+		Items []Knowledge `json:"items"`
+		Total int         `json:"total"`
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &page.Items)
+	} else {
+		err = json.Unmarshal(body, &page)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode knowledge items: %w", err)
+	}
+
+	result := &KnowledgePage{Total: page.Total}
+	if page.Total > 0 {
+		result.More = opts.Page*opts.PageSize < page.Total
+	} else {
+		result.More = opts.PageSize > 0 && len(page.Items) == opts.PageSize
+	}
+	for _, item := range page.Items {
+		if hasAllLabels(item.Labels, opts.Labels) {
+			result.Items = append(result.Items, item)
+		}
+	}
+	return result, nil
+}
+
+func hasAllLabels(labels, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, l := range labels {
+			if strings.EqualFold(l, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Client) SearchKnowledge(ctx context.Context, query string, limit int) ([]Knowledge, error) {
 	url := fmt.Sprintf("%s/knowledge/search?query=%s", c.cfg.BaseURL, query)
 	if limit > 0 {
@@ -446,3 +547,61 @@ func (c *Client) DeleteKnowledge(ctx context.Context, uuid string) error {
 
 	return nil
 }
+
+// ListKnowledgeVersions returns the version history of a knowledge item,
+// newest first
+func (c *Client) ListKnowledgeVersions(ctx context.Context, uuid string) ([]KnowledgeVersion, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("%s/knowledge/%s/versions", c.cfg.BaseURL, uuid), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var versions []KnowledgeVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+// GetKnowledgeVersion returns a single version of a knowledge item including
+// its content
+func (c *Client) GetKnowledgeVersion(ctx context.Context, uuid string, version int) (*KnowledgeVersion, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("%s/knowledge/%s/versions/%d", c.cfg.BaseURL, uuid, version), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("version %d of knowledge item %s not found", version, uuid)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var v KnowledgeVersion
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
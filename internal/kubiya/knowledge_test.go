@@ -0,0 +1,83 @@
+package kubiya
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestListKnowledgePage(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		opts      KnowledgeListOptions
+		wantNames []string
+		wantTotal int
+		wantMore  bool
+	}{
+		{
+			name:      "paginated object",
+			response:  `{"items":[{"name":"a","labels":["runbook"]},{"name":"b","labels":["Runbook","sre"]}],"total":5}`,
+			opts:      KnowledgeListOptions{Page: 2, PageSize: 2, Labels: []string{"runbook"}},
+			wantNames: []string{"a", "b"},
+			wantTotal: 5,
+			wantMore:  true,
+		},
+		{
+			name:      "last page of a total",
+			response:  `{"items":[{"name":"e","labels":["runbook"]}],"total":5}`,
+			opts:      KnowledgeListOptions{Page: 3, PageSize: 2},
+			wantNames: []string{"e"},
+			wantTotal: 5,
+		},
+		{
+			name:      "full list page, labels checked on the items",
+			response:  `[{"name":"a","labels":["runbook"]},{"name":"b","labels":["faq"]}]`,
+			opts:      KnowledgeListOptions{Page: 1, PageSize: 2, Labels: []string{"runbook"}},
+			wantNames: []string{"a"},
+			wantMore:  true,
+		},
+		{
+			name:      "short list page",
+			response:  `[{"name":"a"}]`,
+			opts:      KnowledgeListOptions{PageSize: 2},
+			wantNames: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query map[string][]string
+			_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/knowledge" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				query = r.URL.Query()
+				w.Write([]byte(tt.response))
+			})
+
+			page, err := client.ListKnowledgePage(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("ListKnowledgePage() error = %v", err)
+			}
+			var names []string
+			for _, item := range page.Items {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("items = %v, want %v", names, tt.wantNames)
+			}
+			if page.Total != tt.wantTotal || page.More != tt.wantMore {
+				t.Errorf("total = %d, more = %v, want %d and %v", page.Total, page.More, tt.wantTotal, tt.wantMore)
+			}
+			if want := max(tt.opts.Page, 1); query["page"][0] != strconv.Itoa(want) {
+				t.Errorf("page = %v, want %d", query["page"], want)
+			}
+			if !reflect.DeepEqual(query["label"], tt.opts.Labels) {
+				t.Errorf("labels = %v, want %v", query["label"], tt.opts.Labels)
+			}
+		})
+	}
+}
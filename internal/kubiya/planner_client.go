@@ -10,6 +10,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // PlannerClient wraps the Kubiya client to provide planning capabilities
@@ -44,13 +47,12 @@ func (pc *PlannerClient) CreatePlan(ctx context.Context, req *PlanRequest) (*Pla
 	httpReq.Header.Set("Content-Type", "application/json")
 	if pc.client.cfg.APIKey != "" {
 		httpReq.Header.Set("Authorization", fmt.Sprintf("UserKey %s", pc.client.cfg.APIKey))
+		correlation.Set(httpReq)
 	}
 
 	// Use extended timeout client for planning (3 minutes)
 	// Default 30s timeout is not enough for AI planning which takes 40-120 seconds
-	httpClient := &http.Client{
-		Timeout: 3 * time.Minute,
-	}
+	httpClient := httpclient.New(3 * time.Minute)
 
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
@@ -140,12 +142,11 @@ func (pc *PlannerClient) StreamPlanProgress(ctx context.Context, req *PlanReques
 			// IMPORTANT: Add Authorization header (required for backend)
 			if pc.client.cfg.APIKey != "" {
 				httpReq.Header.Set("Authorization", fmt.Sprintf("UserKey %s", pc.client.cfg.APIKey))
+				correlation.Set(httpReq)
 			}
 
 			// Execute request with no timeout for streaming
-			httpClient := &http.Client{
-				Timeout: 0, // No timeout for SSE streams
-			}
+			httpClient := httpclient.New(0) // No timeout for SSE streams
 			resp, err = httpClient.Do(httpReq)
 			if err != nil {
 				lastErr = fmt.Errorf("failed to execute request: %w", err)
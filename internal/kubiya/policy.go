@@ -174,19 +174,9 @@ func (c *Client) EvaluatePolicy(ctx context.Context, evaluation PolicyEvaluation
 
 // ValidateToolExecution validates if a user can execute a specific tool with given parameters
 func (c *Client) ValidateToolExecution(ctx context.Context, toolName string, args map[string]interface{}, runner string) (bool, string, error) {
-	input := map[string]interface{}{
-		"action":    "tool_execution",
-		"tool_name": toolName,
-		"args":      args,
-		"runner":    runner,
-		"user":      map[string]interface{}{
-			// Add user context if available
-		},
-	}
-
 	// Create a generic policy evaluation for tool execution
 	evaluation := PolicyEvaluationRequest{
-		Input: input,
+		Input: toolExecutionInput(toolName, args, runner, ""),
 		Query: "data.tools.allow", // Standard query for tool permissions
 		Data:  map[string]interface{}{},
 	}
@@ -0,0 +1,94 @@
+package kubiya
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrPromptRejected is returned when a PromptHook refused a prompt
+var ErrPromptRejected = errors.New("prompt rejected by the pre-send hook")
+
+// promptHookTimeout bounds how long a pre-send hook script may run
+const promptHookTimeout = 30 * time.Second
+
+// Prompt is an outgoing chat prompt handed to a PromptHook
+type Prompt struct {
+	AgentID   string // empty for inline agents
+	SessionID string
+	Message   string
+}
+
+// PromptHook sees every chat prompt before it is sent and returns the
+// message to send instead, or an error wrapping ErrPromptRejected
+type PromptHook func(ctx context.Context, prompt Prompt) (string, error)
+
+// SetPromptHook installs hook for the chat messages sent afterwards
+func (c *Client) SetPromptHook(hook PromptHook) {
+	c.promptHook = hook
+}
+
+// preparePrompt runs the prompt hook on message
+func (c *Client) preparePrompt(ctx context.Context, agentID, sessionID, message string) (string, error) {
+	if c.promptHook == nil || message == "" {
+		return message, nil
+	}
+	return c.promptHook(ctx, Prompt{AgentID: agentID, SessionID: sessionID, Message: message})
+}
+
+// ScriptPromptHook runs the script at path with the prompt on stdin. When it
+// exits with 0 its output, if any, replaces the prompt; any other exit code
+// rejects the prompt, with the script's stderr as the reason. The agent and
+// session are passed in KUBIYA_AGENT_ID and KUBIYA_SESSION_ID.
+func ScriptPromptHook(path string) PromptHook {
+	return func(ctx context.Context, prompt Prompt) (string, error) {
+		script := expandHome(path)
+		ctx, cancel := context.WithTimeout(ctx, promptHookTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, script)
+		cmd.Stdin = strings.NewReader(prompt.Message)
+		cmd.Env = append(os.Environ(),
+			"KUBIYA_AGENT_ID="+prompt.AgentID,
+			"KUBIYA_SESSION_ID="+prompt.SessionID,
+		)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			return "", fmt.Errorf("pre-send hook %s did not finish within %s", path, promptHookTimeout)
+		case errors.As(err, &exitErr):
+			reason := strings.TrimSpace(stderr.String())
+			if reason == "" {
+				reason = fmt.Sprintf("exit code %d", exitErr.ExitCode())
+			}
+			return "", fmt.Errorf("%w: %s", ErrPromptRejected, reason)
+		case err != nil:
+			return "", fmt.Errorf("failed to run pre-send hook %s: %w", path, err)
+		}
+
+		if stdout.Len() == 0 {
+			return prompt.Message, nil
+		}
+		return stdout.String(), nil
+	}
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,90 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeHookScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScriptPromptHook(t *testing.T) {
+	prompt := Prompt{AgentID: "agent-1", SessionID: "session-1", Message: "token=abc123 check pods"}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr error
+	}{
+		{"rewrite", `sed 's/token=[^ ]*/token=[REDACTED]/'`, "token=[REDACTED] check pods", nil},
+		{"environment", `printf '%s %s: ' "$KUBIYA_AGENT_ID" "$KUBIYA_SESSION_ID"; cat`, "agent-1 session-1: token=abc123 check pods", nil},
+		{"unchanged without output", `cat > /dev/null`, prompt.Message, nil},
+		{"rejected", `echo "prompts must not contain tokens" >&2; exit 1`, "", ErrPromptRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScriptPromptHook(writeHookScript(t, tt.script))(context.Background(), prompt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !strings.Contains(err.Error(), "prompts must not contain tokens") {
+				t.Errorf("error %q does not carry the reason", err)
+			}
+			if got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ScriptPromptHook(filepath.Join(t.TempDir(), "missing.sh"))(context.Background(), prompt); err == nil || errors.Is(err, ErrPromptRejected) {
+		t.Errorf("expected a failure to run a missing hook, got %v", err)
+	}
+}
+
+func TestSendMessageRunsPromptHook(t *testing.T) {
+	var sent []string
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		sent = append(sent, payload.Message)
+	})
+	client.SetPromptHook(func(ctx context.Context, prompt Prompt) (string, error) {
+		if strings.Contains(prompt.Message, "secret") {
+			return "", ErrPromptRejected
+		}
+		return "[team-a] " + prompt.Message, nil
+	})
+
+	messages, err := client.SendMessage(context.Background(), "agent-1", "list pods", "session-1")
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	for range messages {
+	}
+	if _, err := client.SendMessageWithRetry(context.Background(), "agent-1", "print the secret", "session-1", 3); !errors.Is(err, ErrPromptRejected) {
+		t.Errorf("SendMessageWithRetry() error = %v, want a rejection", err)
+	}
+
+	if len(sent) != 1 || sent[0] != "[team-a] list pods" {
+		t.Errorf("sent %q, want only the rewritten prompt", sent)
+	}
+}
@@ -0,0 +1,239 @@
+package kubiya
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+)
+
+const (
+	// defaultRateLimitRetries is how often a throttled request is retried
+	defaultRateLimitRetries = 5
+	// maxRetryAfter caps how long a single Retry-After is honored, below the
+	// 30s timeout of the API client so that the retry can still complete
+	maxRetryAfter = 20 * time.Second
+	// minAdaptiveRate is the lowest rate adaptive throttling slows down to
+	minAdaptiveRate = rate.Limit(0.5)
+)
+
+// ThrottleStats counts how often requests were slowed down by rate limiting
+type ThrottleStats struct {
+	Requests  int64         // requests sent to the API
+	Throttled int64         // 429 responses received
+	Retries   int64         // requests retried after being throttled
+	Waited    time.Duration // total time spent waiting for the rate limiter or Retry-After
+}
+
+// rateLimiters shares one token bucket per profile and rate across all
+// clients of the process, so commands creating several clients still stay
+// within the configured rate
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = map[string]*rate.Limiter{}
+)
+
+func sharedRateLimiter(profile string, limit float64, burst int) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(limit)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+
+	key := fmt.Sprintf("%s|%g|%d", profile, limit, burst)
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	if l, ok := rateLimiters[key]; ok {
+		return l
+	}
+	l := rate.NewLimiter(rate.Limit(limit), burst)
+	rateLimiters[key] = l
+	return l
+}
+
+// RateLimitRoundTripper throttles requests with a client-side token bucket
+// and retries 429 responses, honoring Retry-After. When the API throttles,
+// the requests of this round tripper are slowed down by a bucket of its own,
+// which recovers gradually on successful responses; the shared bucket keeps
+// its configured rate.
+type RateLimitRoundTripper struct {
+	Transport  http.RoundTripper
+	Limiter    *rate.Limiter // nil disables client-side throttling
+	MaxRetries int
+
+	adaptive   *rate.Limiter // slowed down on 429s, nil without Limiter
+	configured rate.Limit
+	requests   atomic.Int64
+	throttled  atomic.Int64
+	retries    atomic.Int64
+	waited     atomic.Int64
+}
+
+// NewRateLimitRoundTripper wraps transport with rate limiting. A nil limiter
+// only enables 429 handling.
+func NewRateLimitRoundTripper(transport http.RoundTripper, limiter *rate.Limiter) *RateLimitRoundTripper {
+	rt := &RateLimitRoundTripper{
+		Transport:  transport,
+		Limiter:    limiter,
+		MaxRetries: defaultRateLimitRetries,
+	}
+	if limiter != nil {
+		rt.configured = limiter.Limit()
+		rt.adaptive = rate.NewLimiter(limiter.Limit(), limiter.Burst())
+	}
+	return rt
+}
+
+// Stats returns the throttling counters collected so far
+func (rt *RateLimitRoundTripper) Stats() ThrottleStats {
+	return ThrottleStats{
+		Requests:  rt.requests.Load(),
+		Throttled: rt.throttled.Load(),
+		Retries:   rt.retries.Load(),
+		Waited:    time.Duration(rt.waited.Load()),
+	}
+}
+
+func (rt *RateLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := rt.Transport
+	if transport == nil {
+		transport = httpclient.Transport()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if rt.Limiter != nil {
+			start := time.Now()
+			if err := rt.Limiter.Wait(req.Context()); err != nil {
+				return nil, fmt.Errorf("rate limiter: %w", err)
+			}
+			if err := rt.adaptive.Wait(req.Context()); err != nil {
+				return nil, fmt.Errorf("rate limiter: %w", err)
+			}
+			if waited := time.Since(start); waited > time.Millisecond {
+				rt.recordWait(waited, "rate limit", req)
+			}
+		}
+
+		rt.requests.Add(1)
+		resp, err := transport.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			if err == nil {
+				rt.recover()
+			}
+			return resp, err
+		}
+
+		rt.throttled.Add(1)
+		rt.slowDown()
+
+		delay := retryAfter(resp.Header, attempt)
+		if attempt >= rt.MaxRetries || !canRetry(req) || !fitsDeadline(req, delay) {
+			return resp, nil
+		}
+
+		// Drain the throttled response so the connection can be reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		// Send a copy with a fresh body: the body of the caller's request
+		// has been consumed, and round trippers must not modify it
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		rt.recordWait(delay, "429 Retry-After", req)
+		rt.retries.Add(1)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (rt *RateLimitRoundTripper) recordWait(d time.Duration, reason string, req *http.Request) {
+	rt.waited.Add(int64(d))
+	httpLog.Debug("throttled request", "wait", d.Round(time.Millisecond), "reason", reason,
+		"method", req.Method, "path", req.URL.Path)
+}
+
+// slowDown halves the adaptive rate after the API throttled a request
+func (rt *RateLimitRoundTripper) slowDown() {
+	if rt.adaptive == nil {
+		return
+	}
+	limit := rt.adaptive.Limit() / 2
+	if limit < minAdaptiveRate {
+		limit = minAdaptiveRate
+	}
+	rt.adaptive.SetLimit(limit)
+	httpLog.Debug("API returned 429, slowing down", "rate", float64(limit))
+}
+
+// recover speeds the adaptive rate back up towards the configured rate
+func (rt *RateLimitRoundTripper) recover() {
+	if rt.adaptive == nil {
+		return
+	}
+	if limit := rt.adaptive.Limit(); limit < rt.configured {
+		limit *= 1.1
+		if limit > rt.configured {
+			limit = rt.configured
+		}
+		rt.adaptive.SetLimit(limit)
+	}
+}
+
+// retryAfter returns how long to wait before retrying a throttled request.
+// It honors Retry-After (seconds or HTTP date) and X-RateLimit-Reset (unix
+// seconds) and falls back to exponential backoff.
+func retryAfter(h http.Header, attempt int) time.Duration {
+	var delay time.Duration
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			delay = time.Duration(secs * float64(time.Second))
+		} else if t, err := http.ParseTime(v); err == nil {
+			delay = time.Until(t)
+		}
+	} else if v := h.Get("X-RateLimit-Reset"); v != "" {
+		if reset, err := strconv.ParseInt(v, 10, 64); err == nil {
+			delay = time.Until(time.Unix(reset, 0))
+		}
+	}
+
+	if delay <= 0 {
+		delay = time.Second << attempt
+	}
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+	return delay
+}
+
+// canRetry reports whether the request body can be sent again
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// fitsDeadline reports whether waiting delay leaves the request within its
+// context deadline
+func fitsDeadline(req *http.Request, delay time.Duration) bool {
+	deadline, ok := req.Context().Deadline()
+	return !ok || time.Until(deadline) > delay
+}
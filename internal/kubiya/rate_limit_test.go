@@ -0,0 +1,149 @@
+package kubiya
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitRoundTripperRetriesThrottledRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, 5)
+		r.Body.Read(body)
+		if string(body) != "hello" {
+			t.Errorf("expected the body to be replayed, got %q", body)
+		}
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limiter := rate.NewLimiter(100, 1)
+	rt := NewRateLimitRoundTripper(http.DefaultTransport, limiter)
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after retries, got %d", resp.StatusCode)
+	}
+	stats := rt.Stats()
+	if stats.Requests != 3 || stats.Throttled != 2 || stats.Retries != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Waited < 20*time.Millisecond {
+		t.Errorf("expected Retry-After waits to be recorded, got %s", stats.Waited)
+	}
+	if rt.adaptive.Limit() >= 100 {
+		t.Errorf("expected the client to slow down after 429s, got %v", rt.adaptive.Limit())
+	}
+	if limiter.Limit() != 100 {
+		t.Errorf("expected the shared limiter to keep its rate, got %v", limiter.Limit())
+	}
+}
+
+func TestRateLimitRoundTripperDoesNotModifyRequest(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 2 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := req.Body
+	rt := NewRateLimitRoundTripper(http.DefaultTransport, nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after a retry, got %d", resp.StatusCode)
+	}
+	if req.Body != body {
+		t.Error("expected the retry to leave the caller's request body alone")
+	}
+}
+
+func TestRateLimitRoundTripperGivesUpAfterMaxRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0.001")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	rt := NewRateLimitRoundTripper(http.DefaultTransport, nil)
+	rt.MaxRetries = 2
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the last 429 to be returned, got %d", resp.StatusCode)
+	}
+	if got := rt.Stats().Requests; got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		attempt int
+		want    time.Duration
+	}{
+		{"seconds", http.Header{"Retry-After": {"3"}}, 0, 3 * time.Second},
+		{"backoff without header", http.Header{}, 2, 4 * time.Second},
+		{"capped", http.Header{"Retry-After": {"3600"}}, 0, maxRetryAfter},
+		{"invalid falls back to backoff", http.Header{"Retry-After": {"soon"}}, 0, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.header, tt.attempt); got != tt.want {
+				t.Errorf("retryAfter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	if sharedRateLimiter("prod", 0, 0) != nil {
+		t.Error("expected no limiter without a rate")
+	}
+	a := sharedRateLimiter("prod", 5, 0)
+	if a != sharedRateLimiter("prod", 5, 0) {
+		t.Error("expected clients of the same profile to share a limiter")
+	}
+	if a == sharedRateLimiter("staging", 5, 0) {
+		t.Error("expected profiles to have separate limiters")
+	}
+	if a.Burst() != 5 {
+		t.Errorf("expected the burst to default to the rate, got %d", a.Burst())
+	}
+}
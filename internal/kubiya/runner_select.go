@@ -0,0 +1,266 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRunnerProbeTimeout bounds the health probe of each runner during
+// auto selection
+const DefaultRunnerProbeTimeout = 2 * time.Second
+
+// Samples kept per runner in the latency history, and how long they count
+const (
+	runnerLatencySamples = 10
+	runnerLatencyMaxAge  = 24 * time.Hour
+)
+
+// RunnerSelectOptions tunes auto runner selection
+type RunnerSelectOptions struct {
+	// PreferLabels ranks the runners having all these labels first
+	PreferLabels map[string]string
+	// History holds recent execution latencies, may be nil
+	History *RunnerLatencyHistory
+	// ProbeTimeout defaults to DefaultRunnerProbeTimeout
+	ProbeTimeout time.Duration
+}
+
+// RankedRunner is a healthy runner with what auto selection measured
+type RankedRunner struct {
+	Runner Runner
+	// Probe is the round trip of the health probe
+	Probe time.Duration
+	// Recent is the median time to start recent executions, when known
+	Recent    time.Duration
+	HasRecent bool
+	// Preferred is set when the runner has all the preferred labels
+	Preferred bool
+}
+
+// Score orders runners of the same preference: lower is better. Runners
+// without history are scored on the probe alone.
+func (r RankedRunner) Score() time.Duration {
+	return r.Probe + r.Recent
+}
+
+// RunnerLabels returns the labels of a runner: the labels set on it, plus
+// name, type, managed_by, version and namespace
+func RunnerLabels(r Runner) map[string]string {
+	labels := map[string]string{}
+	for k, v := range map[string]string{
+		"name":       r.Name,
+		"type":       r.RunnerType,
+		"managed_by": r.ManagedBy,
+		"version":    r.Version,
+		"namespace":  r.Namespace,
+	} {
+		if v != "" {
+			labels[k] = v
+		}
+	}
+	for k, v := range r.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// ParseRunnerLabels parses key=value pairs
+func ParseRunnerLabels(pairs []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid runner label %q (expected key=value)", pair)
+		}
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return labels, nil
+}
+
+func hasLabels(r Runner, want map[string]string) bool {
+	labels := RunnerLabels(r)
+	for k, v := range want {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectRunner ranks the healthy runners for auto selection: each one is
+// probed, runners with the preferred labels come first, then the fastest by
+// probe round trip plus recent execution latency. Runners failing the probe
+// are left out.
+func (c *Client) SelectRunner(ctx context.Context, opts RunnerSelectOptions) ([]RankedRunner, error) {
+	runners, err := c.ListRunners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runners: %w", err)
+	}
+
+	timeout := opts.ProbeTimeout
+	if timeout <= 0 {
+		timeout = DefaultRunnerProbeTimeout
+	}
+
+	probes := map[string]time.Duration{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, r := range runners {
+		if !runnerHealthy(r) {
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			d, err := c.ProbeRunner(probeCtx, name)
+			if err != nil {
+				httpLog.Debug("runner failed the probe", "runner", name, "error", err)
+				return
+			}
+			mu.Lock()
+			probes[name] = d
+			mu.Unlock()
+		}(r.Name)
+	}
+	wg.Wait()
+
+	ranked := rankRunners(runners, probes, opts)
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("no healthy runners found")
+	}
+	return ranked, nil
+}
+
+// rankRunners orders the runners that answered the probe
+func rankRunners(runners []Runner, probes map[string]time.Duration, opts RunnerSelectOptions) []RankedRunner {
+	var ranked []RankedRunner
+	for _, r := range runners {
+		probe, ok := probes[r.Name]
+		if !ok || !runnerHealthy(r) {
+			continue
+		}
+		rr := RankedRunner{Runner: r, Probe: probe, Preferred: len(opts.PreferLabels) > 0 && hasLabels(r, opts.PreferLabels)}
+		if opts.History != nil {
+			rr.Recent, rr.HasRecent = opts.History.Recent(r.Name)
+		}
+		ranked = append(ranked, rr)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Preferred != ranked[j].Preferred {
+			return ranked[i].Preferred
+		}
+		if ranked[i].Score() != ranked[j].Score() {
+			return ranked[i].Score() < ranked[j].Score()
+		}
+		return ranked[i].Runner.Name < ranked[j].Runner.Name
+	})
+	return ranked
+}
+
+// ProbeRunner checks the health endpoint of a runner and returns the round
+// trip
+func (c *Client) ProbeRunner(ctx context.Context, name string) (time.Duration, error) {
+	baseURL := strings.TrimSuffix(c.baseURL, "/api/v1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v3/runners/%s/health", baseURL, name), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe runner %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("runner %s health check returned %s", name, resp.Status)
+	}
+	return time.Since(start), nil
+}
+
+// RunnerLatencyHistory keeps how long recent executions took to start on
+// each runner, in ~/.kubiya/runner-latency.json
+type RunnerLatencyHistory struct {
+	path    string
+	Runners map[string][]RunnerLatencySample `json:"runners"`
+}
+
+// RunnerLatencySample is the time an execution took to start
+type RunnerLatencySample struct {
+	Latency time.Duration `json:"latency"`
+	At      time.Time     `json:"at"`
+}
+
+// LoadRunnerLatencyHistory reads the latency history. A missing or invalid
+// file gives an empty history.
+func LoadRunnerLatencyHistory() (*RunnerLatencyHistory, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	h := &RunnerLatencyHistory{path: filepath.Join(homeDir, ".kubiya", "runner-latency.json")}
+	if data, err := os.ReadFile(h.path); err == nil {
+		_ = json.Unmarshal(data, h)
+	}
+	if h.Runners == nil {
+		h.Runners = map[string][]RunnerLatencySample{}
+	}
+	return h, nil
+}
+
+// Record adds an execution latency of runner
+func (h *RunnerLatencyHistory) Record(runner string, latency time.Duration) {
+	samples := append(h.Runners[runner], RunnerLatencySample{Latency: latency, At: time.Now()})
+	if len(samples) > runnerLatencySamples {
+		samples = samples[len(samples)-runnerLatencySamples:]
+	}
+	h.Runners[runner] = samples
+}
+
+// Recent returns the median of the recent latencies of runner
+func (h *RunnerLatencyHistory) Recent(runner string) (time.Duration, bool) {
+	var latencies []time.Duration
+	for _, s := range h.Runners[runner] {
+		if time.Since(s.At) <= runnerLatencyMaxAge {
+			latencies = append(latencies, s.Latency)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0, false
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[len(latencies)/2], true
+}
+
+// Save writes the history
+func (h *RunnerLatencyHistory) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode runner latencies: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(h.path), err)
+	}
+	if err := os.WriteFile(h.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", h.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package kubiya
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRankRunners(t *testing.T) {
+	healthy := HealthStatus{Status: "healthy"}
+	runners := []Runner{
+		{Name: "us-1", RunnerHealth: healthy, Labels: map[string]string{"zone": "us"}},
+		{Name: "eu-slow", RunnerHealth: healthy, Labels: map[string]string{"zone": "eu"}},
+		{Name: "eu-fast", RunnerHealth: healthy, Labels: map[string]string{"zone": "eu"}},
+		{Name: "down", RunnerHealth: HealthStatus{Status: "unhealthy"}},
+		{Name: "no-probe", RunnerHealth: healthy},
+	}
+	probes := map[string]time.Duration{
+		"us-1":    10 * time.Millisecond,
+		"eu-slow": 20 * time.Millisecond,
+		"eu-fast": 30 * time.Millisecond,
+		"down":    time.Millisecond,
+	}
+	history := &RunnerLatencyHistory{Runners: map[string][]RunnerLatencySample{}}
+	history.Record("eu-slow", 2*time.Second)
+
+	names := func(ranked []RankedRunner) []string {
+		var n []string
+		for _, r := range ranked {
+			n = append(n, r.Runner.Name)
+		}
+		return n
+	}
+
+	ranked := rankRunners(runners, probes, RunnerSelectOptions{History: history})
+	if got := names(ranked); len(got) != 3 || got[0] != "us-1" || got[1] != "eu-fast" || got[2] != "eu-slow" {
+		t.Errorf("ranking = %v, want [us-1 eu-fast eu-slow]", got)
+	}
+	if !ranked[2].HasRecent || ranked[2].Recent != 2*time.Second {
+		t.Errorf("eu-slow recent = %v, %v", ranked[2].Recent, ranked[2].HasRecent)
+	}
+
+	ranked = rankRunners(runners, probes, RunnerSelectOptions{History: history, PreferLabels: map[string]string{"zone": "eu"}})
+	if got := names(ranked); got[0] != "eu-fast" || got[1] != "eu-slow" || got[2] != "us-1" {
+		t.Errorf("ranking with zone=eu = %v, want [eu-fast eu-slow us-1]", got)
+	}
+	if !ranked[0].Preferred || ranked[2].Preferred {
+		t.Error("only eu runners should be preferred")
+	}
+}
+
+func TestParseRunnerLabels(t *testing.T) {
+	labels, err := ParseRunnerLabels([]string{"zone=eu", " tier = prod "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["zone"] != "eu" || labels["tier"] != "prod" {
+		t.Errorf("labels = %v", labels)
+	}
+	if _, err := ParseRunnerLabels([]string{"zone"}); err == nil {
+		t.Error("expected an error for a label without a value")
+	}
+
+	l := RunnerLabels(Runner{Name: "r1", RunnerType: "k8s", Labels: map[string]string{"zone": "eu"}})
+	if l["name"] != "r1" || l["type"] != "k8s" || l["zone"] != "eu" {
+		t.Errorf("runner labels = %v", l)
+	}
+}
+
+func TestRunnerLatencyHistory(t *testing.T) {
+	h := &RunnerLatencyHistory{path: filepath.Join(t.TempDir(), "runner-latency.json"), Runners: map[string][]RunnerLatencySample{}}
+	for i := 1; i <= runnerLatencySamples+2; i++ {
+		h.Record("r1", time.Duration(i)*time.Second)
+	}
+	if len(h.Runners["r1"]) != runnerLatencySamples {
+		t.Errorf("kept %d samples, want %d", len(h.Runners["r1"]), runnerLatencySamples)
+	}
+	if recent, ok := h.Recent("r1"); !ok || recent != 8*time.Second {
+		t.Errorf("median = %v, want 8s", recent)
+	}
+
+	h.Runners["old"] = []RunnerLatencySample{{Latency: time.Second, At: time.Now().Add(-48 * time.Hour)}}
+	if _, ok := h.Recent("old"); ok {
+		t.Error("samples older than a day should not count")
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelectRunner(t *testing.T) {
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/runners":
+			w.Write([]byte(`[{"name":"fast"},{"name":"broken"},{"name":"slow"}]`))
+		case "/api/v3/runners/fast/health", "/api/v3/runners/slow/health":
+			if r.URL.Path == "/api/v3/runners/slow/health" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			w.Write([]byte(`{"status":"healthy","health":"true"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	ranked, err := client.SelectRunner(context.Background(), RunnerSelectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 2 || ranked[0].Runner.Name != "fast" || ranked[1].Runner.Name != "slow" {
+		t.Errorf("ranked = %+v", ranked)
+	}
+}
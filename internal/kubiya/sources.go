@@ -472,6 +472,13 @@ func WithDynamicConfig(config map[string]interface{}) SourceOption {
 	}
 }
 
+// WithURL sets the URL the tools of a source are loaded from
+func WithURL(url string) SourceOption {
+	return func(s *Source) {
+		s.URL = url
+	}
+}
+
 // WithRunner sets the runner for a source
 func WithRunner(runner string) SourceOption {
 	return func(s *Source) {
@@ -479,6 +486,14 @@ func WithRunner(runner string) SourceOption {
 	}
 }
 
+// WithPinnedRef pins the tools of a git source to a commit, or unpins them
+// when ref is empty
+func WithPinnedRef(ref string) SourceOption {
+	return func(s *Source) {
+		s.PinnedRef = ref
+	}
+}
+
 // UpdateSource updates an existing source
 func (c *Client) UpdateSource(ctx context.Context, uuid string, opts ...SourceOption) (*Source, error) {
 	// First get the existing source
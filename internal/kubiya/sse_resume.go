@@ -0,0 +1,286 @@
+package kubiya
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+)
+
+const (
+	defaultStreamResumes = 5
+	defaultStreamRetry   = time.Second
+	maxStreamRetry       = 30 * time.Second
+	// maxBufferedEvent bounds an event held back until it is complete;
+	// streams with larger events are passed through and not resumed
+	maxBufferedEvent = 4 << 20
+)
+
+// ResumableStreamRoundTripper reconnects server-sent event streams dropped
+// by the network. Once the server has sent event IDs, a stream failing
+// mid-way is requested again with Last-Event-ID so that the server carries
+// on after the last event received instead of running the request again.
+//
+// Events are handed to the reader whole, so that an event cut by the drop
+// is not seen twice. Streams without event IDs are passed through as they
+// are and fail as before.
+//
+// With an IdleTimeout, a stream that received nothing, not even a keep-alive
+// comment, for that long is considered dead: it is resumed when it has event
+// IDs and fails with ErrStreamIdle otherwise, instead of hanging.
+type ResumableStreamRoundTripper struct {
+	Transport   http.RoundTripper
+	MaxResumes  int // reconnections in a row before giving up
+	IdleTimeout time.Duration
+}
+
+// ErrStreamIdle is the error of event streams that received nothing for
+// longer than their idle timeout
+var ErrStreamIdle = errors.New("no data received on the stream")
+
+// NewResumableStreamRoundTripper wraps transport, httpclient.Transport()
+// when nil
+func NewResumableStreamRoundTripper(transport http.RoundTripper) *ResumableStreamRoundTripper {
+	return &ResumableStreamRoundTripper{
+		Transport:  transport,
+		MaxResumes: defaultStreamResumes,
+	}
+}
+
+func (rt *ResumableStreamRoundTripper) transport() http.RoundTripper {
+	if rt.Transport == nil {
+		return httpclient.Transport()
+	}
+	return rt.Transport
+}
+
+func (rt *ResumableStreamRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.transport().RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+		return resp, err
+	}
+	resp.Body = rt.watch(resp.Body)
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil // the request cannot be sent again
+	}
+	resp.Body = &resumableStream{
+		rt:     rt,
+		req:    req,
+		body:   resp.Body,
+		reader: bufio.NewReader(resp.Body),
+		retry:  defaultStreamRetry,
+	}
+	return resp, nil
+}
+
+// watch closes body once nothing was received for IdleTimeout
+func (rt *ResumableStreamRoundTripper) watch(body io.ReadCloser) io.ReadCloser {
+	if rt.IdleTimeout <= 0 {
+		return body
+	}
+	w := &idleWatchdog{body: body, timeout: rt.IdleTimeout}
+	w.timer = time.AfterFunc(rt.IdleTimeout, func() {
+		w.idle.Store(true)
+		body.Close()
+	})
+	return w
+}
+
+// idleWatchdog is the body of a stream that is closed when idle for timeout
+type idleWatchdog struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	idle    atomic.Bool
+}
+
+func (w *idleWatchdog) Read(p []byte) (int, error) {
+	n, err := w.body.Read(p)
+	if w.idle.Load() {
+		return n, fmt.Errorf("%w for %s, the connection is considered dead", ErrStreamIdle, w.timeout)
+	}
+	if n > 0 {
+		w.timer.Reset(w.timeout)
+	}
+	return n, err
+}
+
+func (w *idleWatchdog) Close() error {
+	w.timer.Stop()
+	return w.body.Close()
+}
+
+func isEventStream(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// resumableStream is the body of an event stream that reconnects when the
+// connection fails
+type resumableStream struct {
+	rt  *ResumableStreamRoundTripper
+	req *http.Request
+
+	mu     sync.Mutex
+	body   io.ReadCloser
+	closed bool
+
+	reader    *bufio.Reader
+	pending   []byte       // read from the server, not yet by the caller
+	event     bytes.Buffer // lines of the event being received
+	eventID   string       // id of the event being received
+	buffering bool         // events are held back until complete
+	lastID    string       // id of the last complete event
+	retry     time.Duration
+	resumes   int // reconnections since the last complete event
+	err       error
+}
+
+func (s *resumableStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		line, err := s.reader.ReadBytes('\n')
+		if err == nil {
+			s.feed(line)
+			continue
+		}
+		if err != io.EOF && s.resume(err) {
+			continue
+		}
+		// Hand over what was received and end with the error
+		s.pending = append(append(s.pending, s.event.Bytes()...), line...)
+		s.event.Reset()
+		s.err = err
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// feed takes a complete line of the stream
+func (s *resumableStream) feed(line []byte) {
+	field := strings.TrimRight(string(line), "\r\n")
+	switch {
+	case field == "":
+		// The event is complete
+		if s.eventID != "" {
+			s.lastID = s.eventID
+			s.eventID = ""
+		}
+		s.resumes = 0
+		s.pending = append(append(s.pending, s.event.Bytes()...), line...)
+		s.event.Reset()
+		return
+	case strings.HasPrefix(field, "id:"):
+		s.eventID = strings.TrimPrefix(strings.TrimPrefix(field, "id:"), " ")
+		s.buffering = true
+	case strings.HasPrefix(field, "retry:"):
+		if ms, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(field, "retry:"))); err == nil && ms > 0 {
+			s.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if !s.buffering {
+		s.pending = append(s.pending, line...)
+		return
+	}
+	s.event.Write(line)
+	if s.event.Len() > maxBufferedEvent {
+		s.pending = append(s.pending, s.event.Bytes()...)
+		s.event.Reset()
+		s.buffering = false
+		s.lastID = ""
+	}
+}
+
+// resume reconnects after the stream failed with cause, and reports
+// whether reading can go on
+func (s *resumableStream) resume(cause error) bool {
+	ctx := s.req.Context()
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed || s.lastID == "" || ctx.Err() != nil {
+		return false
+	}
+
+	// The incomplete event is sent again by the server
+	s.event.Reset()
+	s.eventID = ""
+
+	for s.resumes < s.rt.MaxResumes {
+		s.resumes++
+		wait := s.retry * time.Duration(s.resumes)
+		if wait > maxStreamRetry {
+			wait = maxStreamRetry
+		}
+		sseLog.Debug("stream dropped, resuming", "path", s.req.URL.Path, "cause", cause,
+			"last_event_id", s.lastID, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(wait):
+		}
+
+		resp, err := s.reconnect()
+		if err != nil {
+			cause = err
+			continue
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			resp.Body.Close()
+			return false
+		}
+		s.body.Close()
+		s.body = resp.Body
+		s.mu.Unlock()
+		s.reader = bufio.NewReader(resp.Body)
+		return true
+	}
+	return false
+}
+
+func (s *resumableStream) reconnect() (*http.Response, error) {
+	req := s.req.Clone(s.req.Context())
+	if s.req.GetBody != nil {
+		body, err := s.req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	req.Header.Set("Last-Event-ID", s.lastID)
+
+	resp, err := s.rt.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("stream could not be resumed: %s", resp.Status)
+	}
+	resp.Body = s.rt.watch(resp.Body)
+	return resp, nil
+}
+
+func (s *resumableStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.body.Close()
+}
@@ -0,0 +1,186 @@
+package kubiya
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// droppingStreamServer sends numbered events and drops the connection in the
+// middle of event dropAt, the first time only
+func droppingStreamServer(t *testing.T, withIDs bool, dropAt int) (*httptest.Server, *[]string, *[]string) {
+	var (
+		mu      sync.Mutex
+		lastIDs []string
+		bodies  []string
+		dropped bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		first := 1
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			fmt.Sscanf(id, "%d", &first)
+			first++
+		}
+		fmt.Fprint(w, "retry: 1\n\n")
+		for i := first; i <= 4; i++ {
+			if withIDs {
+				fmt.Fprintf(w, "id: %d\n", i)
+			}
+			fmt.Fprintf(w, "data: event %d\n", i)
+			mu.Lock()
+			drop := i == dropAt && !dropped
+			if drop {
+				dropped = true
+			}
+			mu.Unlock()
+			if drop {
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			fmt.Fprint(w, "\n")
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &lastIDs, &bodies
+}
+
+func TestResumableStreamResumesAfterLastEvent(t *testing.T) {
+	srv, lastIDs, bodies := droppingStreamServer(t, true, 3)
+	client := &http.Client{Transport: NewResumableStreamRoundTripper(nil)}
+
+	resp, err := client.Post(srv.URL+"/api/v1/stream", "application/json", strings.NewReader(`{"prompt":"hi"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, strings.Count(string(data), "data: event 3\n"), "the event cut by the drop is seen once")
+	assert.Contains(t, string(data), "data: event 4\n")
+	assert.Equal(t, []string{"", "2"}, *lastIDs)
+	assert.Equal(t, []string{`{"prompt":"hi"}`, `{"prompt":"hi"}`}, *bodies, "the request is sent again")
+}
+
+func TestResumableStreamWithoutIDsFails(t *testing.T) {
+	srv, lastIDs, _ := droppingStreamServer(t, false, 2)
+	client := &http.Client{Transport: NewResumableStreamRoundTripper(nil)}
+
+	resp, err := client.Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	assert.Error(t, err, "streams without event IDs are not resumed")
+	assert.Contains(t, string(data), "data: event 1\n")
+	assert.Len(t, *lastIDs, 1)
+}
+
+func TestResumableStreamPassesOtherResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	rt := NewResumableStreamRoundTripper(nil)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, wrapped := resp.Body.(*resumableStream)
+	assert.False(t, wrapped)
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, buf.String())
+}
+
+// stallingStreamServer sends events with IDs, then goes silent on the first
+// connection
+func stallingStreamServer(t *testing.T) (*httptest.Server, *[]string) {
+	var (
+		mu      sync.Mutex
+		lastIDs []string
+	)
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		first := len(lastIDs) == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if first {
+			fmt.Fprint(w, "retry: 1\n\nid: 1\ndata: event 1\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-stop:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		fmt.Fprint(w, "id: 2\ndata: event 2\n\n")
+	}))
+	t.Cleanup(func() {
+		close(stop)
+		srv.Close()
+	})
+	return srv, &lastIDs
+}
+
+func TestResumableStreamResumesWhenIdle(t *testing.T) {
+	srv, lastIDs := stallingStreamServer(t)
+	rt := NewResumableStreamRoundTripper(nil)
+	rt.IdleTimeout = 100 * time.Millisecond
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "data: event 1\n")
+	assert.Contains(t, string(data), "data: event 2\n")
+	assert.Equal(t, []string{"", "1"}, *lastIDs)
+}
+
+func TestResumableStreamIdleWithoutIDsFails(t *testing.T) {
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: event 1\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(stop)
+
+	rt := NewResumableStreamRoundTripper(nil)
+	rt.IdleTimeout = 100 * time.Millisecond
+	resp, err := (&http.Client{Transport: rt}).Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, ErrStreamIdle)
+	assert.Contains(t, string(data), "data: event 1\n")
+}
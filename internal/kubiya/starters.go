@@ -0,0 +1,60 @@
+package kubiya
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Starter is a conversation quick-action of an agent: DisplayText is shown to
+// the user and Command is sent to the agent when it is picked
+type Starter struct {
+	Name        string `json:"name" yaml:"name"`
+	Command     string `json:"command" yaml:"command"`
+	DisplayText string `json:"display_text,omitempty" yaml:"display_text,omitempty"`
+}
+
+// Validate checks that the starter has a name and a command
+func (s Starter) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("starter name is required")
+	}
+	if s.Command == "" {
+		return fmt.Errorf("starter %q has no command", s.Name)
+	}
+	return nil
+}
+
+// GetStarters returns the starters of the agent. Starters stored as plain
+// strings are used as both name and command.
+func (a *Agent) GetStarters() ([]Starter, error) {
+	starters := make([]Starter, 0, len(a.Starters))
+	for _, raw := range a.Starters {
+		switch v := raw.(type) {
+		case string:
+			starters = append(starters, Starter{Name: v, Command: v})
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid starter: %w", err)
+			}
+			var s Starter
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("invalid starter %s: %w", data, err)
+			}
+			starters = append(starters, s)
+		}
+	}
+	return starters, nil
+}
+
+// SetStarters replaces the starters of the agent
+func (a *Agent) SetStarters(starters []Starter) {
+	a.Starters = make([]interface{}, 0, len(starters))
+	for _, s := range starters {
+		a.Starters = append(a.Starters, map[string]interface{}{
+			"name":         s.Name,
+			"command":      s.Command,
+			"display_text": s.DisplayText,
+		})
+	}
+}
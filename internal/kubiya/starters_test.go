@@ -0,0 +1,47 @@
+package kubiya
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAgentStarters(t *testing.T) {
+	var agent Agent
+	data := `{"name": "ops", "starters": [
+		"check pods",
+		{"name": "deploys", "command": "List recent deploys", "display_text": "🚀 Deploys"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &agent); err != nil {
+		t.Fatal(err)
+	}
+
+	starters, err := agent.GetStarters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Starter{
+		{Name: "check pods", Command: "check pods"},
+		{Name: "deploys", Command: "List recent deploys", DisplayText: "🚀 Deploys"},
+	}
+	if len(starters) != len(want) {
+		t.Fatalf("got %d starters, want %d", len(starters), len(want))
+	}
+	for i := range want {
+		if starters[i] != want[i] {
+			t.Errorf("starter %d = %+v, want %+v", i, starters[i], want[i])
+		}
+	}
+
+	agent.SetStarters(starters[1:])
+	roundTrip, err := agent.GetStarters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roundTrip) != 1 || roundTrip[0] != want[1] {
+		t.Errorf("after SetStarters got %+v", roundTrip)
+	}
+
+	if err := (Starter{Name: "empty"}).Validate(); err == nil {
+		t.Error("expected a starter without command to be invalid")
+	}
+}
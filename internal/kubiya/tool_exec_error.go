@@ -0,0 +1,53 @@
+package kubiya
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ToolExecutionError is a tool execution that did not start: the API could
+// not be reached or answered with an error status instead of the stream
+type ToolExecutionError struct {
+	Runner     string
+	StatusCode int    // 0 when the API could not be reached
+	Body       string // response body of the error status
+	Err        error  // connection error when StatusCode is 0
+}
+
+func (e *ToolExecutionError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("runner %s: failed to connect: %v", e.Runner, e.Err)
+	}
+	return fmt.Sprintf("runner %s: execution failed with status %d: %s", e.Runner, e.StatusCode, e.Body)
+}
+
+func (e *ToolExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// RunnerUnavailable reports whether the tool certainly did not run because
+// its runner, or the way to it, is unavailable: the connection to the API
+// could not be opened, or the gateway could not reach the runner (502, 503
+// or 504). Any other failure may happen once the tool has started, so
+// retrying it elsewhere could run it twice.
+func (e *ToolExecutionError) RunnerUnavailable() bool {
+	switch e.StatusCode {
+	case 0:
+		var dnsErr *net.DNSError
+		var opErr *net.OpError
+		return errors.As(e.Err, &dnsErr) || (errors.As(e.Err, &opErr) && opErr.Op == "dial")
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsRunnerUnavailable reports whether err is a tool execution that did not
+// start because its runner is unavailable, so that it can be retried on
+// another runner
+func IsRunnerUnavailable(err error) bool {
+	var execErr *ToolExecutionError
+	return errors.As(err, &execErr) && execErr.RunnerUnavailable()
+}
@@ -0,0 +1,48 @@
+package kubiya
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExecuteToolErrorStatus(t *testing.T) {
+	tests := []struct {
+		status          int
+		wantUnavailable bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("nope"))
+			})
+
+			_, err := client.ExecuteToolWithTimeout(context.Background(), "test", map[string]interface{}{}, "runner-1", time.Second, nil)
+			var execErr *ToolExecutionError
+			if !errors.As(err, &execErr) || execErr.StatusCode != tt.status {
+				t.Fatalf("expected a tool execution error with status %d, got %v", tt.status, err)
+			}
+			if IsRunnerUnavailable(err) != tt.wantUnavailable {
+				t.Errorf("expected runner unavailable %v, got %v", tt.wantUnavailable, !tt.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestExecuteToolUnreachable(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	server.Close()
+
+	_, err := client.ExecuteToolWithTimeout(context.Background(), "test", map[string]interface{}{}, "runner-1", time.Second, nil)
+	if !IsRunnerUnavailable(err) {
+		t.Errorf("expected a closed API to make the runner unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package kubiya
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrToolCallDenied is returned by a ToolCallGate that refused a tool call
+var ErrToolCallDenied = errors.New("tool call denied")
+
+// ToolCall is a tool invocation announced on a chat stream
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// ToolCallGate acknowledges the tool calls of a chat stream. Reading the
+// stream is paused while it runs; when it returns an error the stream is
+// closed and the error is delivered as the final message.
+//
+// A gate is not enforcement: the API has no way to refuse a single tool
+// call, so a denial only stops the CLI from following the response. The
+// agent may already have started the tool, or run it anyway. Restrictions
+// that must hold are sent with the message, see ChatPermissions.
+type ToolCallGate func(ctx context.Context, call ToolCall) error
+
+// SetToolCallGate installs gate for the chat streams started afterwards
+func (c *Client) SetToolCallGate(gate ToolCallGate) {
+	c.toolCallGate = gate
+}
+
+// acknowledgeToolCall runs the tool call gate and returns the message ending
+// the stream when the call was refused. The refusal is not sent to the API;
+// closing the stream is all the client can do.
+func (c *Client) acknowledgeToolCall(ctx context.Context, call ToolCall, sessionID string) *ChatMessage {
+	if c.toolCallGate == nil {
+		return nil
+	}
+	err := c.toolCallGate(ctx, call)
+	if err == nil {
+		return nil
+	}
+	return &ChatMessage{
+		Content:    err.Error(),
+		Type:       "error",
+		Error:      err.Error(),
+		Timestamp:  time.Now().Format(time.RFC3339),
+		SenderName: "System",
+		Final:      true,
+		SessionID:  sessionID,
+		MessageID:  call.ID,
+	}
+}
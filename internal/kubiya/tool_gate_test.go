@@ -0,0 +1,44 @@
+package kubiya
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestToolCallGateStopsStream(t *testing.T) {
+	_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `9:{"toolCallId":"call-1","toolName":"kubectl","args":{"command":"delete ns prod"}}`)
+		fmt.Fprintln(w, `a:{"toolCallId":"call-1","result":"namespace deleted"}`)
+	})
+
+	var gated []ToolCall
+	client.SetToolCallGate(func(ctx context.Context, call ToolCall) error {
+		gated = append(gated, call)
+		return ErrToolCallDenied
+	})
+
+	messages, err := client.SendMessage(context.Background(), "agent-1", "clean up", "session-1")
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	var received []ChatMessage
+	for msg := range messages {
+		received = append(received, msg)
+	}
+
+	if len(gated) != 1 || gated[0].Name != "kubectl" || gated[0].ID != "call-1" {
+		t.Fatalf("gate calls = %+v, want the kubectl call", gated)
+	}
+	if gated[0].Arguments != `{"command":"delete ns prod"}` {
+		t.Errorf("gate arguments = %q", gated[0].Arguments)
+	}
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want only the denial: %+v", len(received), received)
+	}
+	if received[0].Type != "error" || !received[0].Final || received[0].Error != ErrToolCallDenied.Error() {
+		t.Errorf("denial message = %+v", received[0])
+	}
+}
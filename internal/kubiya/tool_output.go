@@ -0,0 +1,58 @@
+package kubiya
+
+import "encoding/json"
+
+// Output streams of a tool execution
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputChunk is a chunk of output written by a running tool
+type OutputChunk struct {
+	Stream  string
+	Content string
+}
+
+// ParseToolOutput extracts tool output from the data of a tool execution
+// event. It understands {"type":"tool-output","content":...} chunks with an
+// optional "stream" (or "fd") field, and {"type":"stdout","stdout":...} /
+// {"type":"stderr","stderr":...} chunks. Output without a stream is stdout.
+func ParseToolOutput(data string) (OutputChunk, bool) {
+	var chunk struct {
+		Type    string `json:"type"`
+		Stream  string `json:"stream"`
+		FD      int    `json:"fd"`
+		Content string `json:"content"`
+		Stdout  string `json:"stdout"`
+		Stderr  string `json:"stderr"`
+	}
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return OutputChunk{}, false
+	}
+
+	switch chunk.Type {
+	case "tool-output":
+		stream := StreamStdout
+		if chunk.Stream == StreamStderr || chunk.FD == 2 {
+			stream = StreamStderr
+		}
+		return OutputChunk{Stream: stream, Content: chunk.Content}, true
+	case StreamStdout:
+		return OutputChunk{Stream: StreamStdout, Content: chunk.Stdout}, true
+	case StreamStderr:
+		return OutputChunk{Stream: StreamStderr, Content: chunk.Stderr}, true
+	}
+	return OutputChunk{}, false
+}
+
+// OutputChunk returns the tool output carried by the event, if any
+func (e WorkflowSSEEvent) OutputChunk() (OutputChunk, bool) {
+	switch e.Type {
+	case StreamStdout, StreamStderr:
+		return OutputChunk{Stream: e.Type, Content: e.Data}, true
+	case "data":
+		return ParseToolOutput(e.Data)
+	}
+	return OutputChunk{}, false
+}
@@ -0,0 +1,67 @@
+package kubiya
+
+import "testing"
+
+func TestWorkflowSSEEventOutputChunk(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  WorkflowSSEEvent
+		want   OutputChunk
+		wantOK bool
+	}{
+		{
+			name:   "tool output defaults to stdout",
+			event:  WorkflowSSEEvent{Type: "data", Data: `{"type":"tool-output","content":"hello\n"}`},
+			want:   OutputChunk{Stream: StreamStdout, Content: "hello\n"},
+			wantOK: true,
+		},
+		{
+			name:   "tool output on stderr",
+			event:  WorkflowSSEEvent{Type: "data", Data: `{"type":"tool-output","stream":"stderr","content":"oops"}`},
+			want:   OutputChunk{Stream: StreamStderr, Content: "oops"},
+			wantOK: true,
+		},
+		{
+			name:   "tool output with file descriptor",
+			event:  WorkflowSSEEvent{Type: "data", Data: `{"type":"tool-output","fd":2,"content":"oops"}`},
+			want:   OutputChunk{Stream: StreamStderr, Content: "oops"},
+			wantOK: true,
+		},
+		{
+			name:   "stderr chunk",
+			event:  WorkflowSSEEvent{Type: "data", Data: `{"type":"stderr","stderr":"warn"}`},
+			want:   OutputChunk{Stream: StreamStderr, Content: "warn"},
+			wantOK: true,
+		},
+		{
+			name:   "stdout event",
+			event:  WorkflowSSEEvent{Type: "stdout", Data: "plain"},
+			want:   OutputChunk{Stream: StreamStdout, Content: "plain"},
+			wantOK: true,
+		},
+		{
+			name:  "log is not tool output",
+			event: WorkflowSSEEvent{Type: "data", Data: `{"type":"log","content":"connecting"}`},
+		},
+		{
+			name:  "plain data is not tool output",
+			event: WorkflowSSEEvent{Type: "data", Data: "not json"},
+		},
+		{
+			name:  "error event",
+			event: WorkflowSSEEvent{Type: "error", Data: "boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.event.OutputChunk()
+			if ok != tt.wantOK {
+				t.Fatalf("OutputChunk() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("OutputChunk() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package kubiya
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ToolPolicyPackage is the OPA package deciding on tool executions. Its
+// allow rule is what tool executions are checked against.
+const ToolPolicyPackage = "data.tools"
+
+// ToolPolicyDecision explains the decision of the tool execution policies
+// on a tool call
+type ToolPolicyDecision struct {
+	Allowed bool                   `json:"allowed"`
+	Reasons []string               `json:"reasons,omitempty"`
+	Query   string                 `json:"query"`
+	Input   map[string]interface{} `json:"input"`
+	Result  interface{}            `json:"result"`
+}
+
+// toolExecutionInput is the input the tool execution policies evaluate. The
+// user is an email or a user ID, and may be empty.
+func toolExecutionInput(toolName string, args map[string]interface{}, runner, user string) map[string]interface{} {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	userInput := map[string]interface{}{}
+	switch {
+	case strings.Contains(user, "@"):
+		userInput["email"] = user
+	case user != "":
+		userInput["id"] = user
+	}
+	return map[string]interface{}{
+		"action":    "tool_execution",
+		"tool_name": toolName,
+		"args":      args,
+		"runner":    runner,
+		"user":      userInput,
+	}
+}
+
+// ExplainToolExecution evaluates the tool execution policies on a tool call
+// the way tool executions are checked, and explains the decision. The whole
+// tools package is evaluated rather than its allow rule alone, so that deny
+// messages and reasons given by the policies come along.
+func (c *Client) ExplainToolExecution(ctx context.Context, toolName string, args map[string]interface{}, runner, user string) (*ToolPolicyDecision, error) {
+	input := toolExecutionInput(toolName, args, runner, user)
+	result, err := c.EvaluatePolicy(ctx, PolicyEvaluationRequest{
+		Input: input,
+		Query: ToolPolicyPackage,
+		Data:  map[string]interface{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("policy evaluation failed: %s", result.Error)
+	}
+
+	decision := &ToolPolicyDecision{Query: ToolPolicyPackage, Input: input, Result: result.Result}
+	switch doc := result.Result.(type) {
+	case nil:
+		decision.Reasons = []string{fmt.Sprintf("no tool execution policy is loaded: %s is undefined", ToolPolicyPackage)}
+	case bool:
+		decision.Allowed = doc
+	case map[string]interface{}:
+		allowed, ok := doc["allow"].(bool)
+		decision.Allowed = ok && allowed
+		for _, key := range []string{"message", "reason", "reasons", "deny", "violations"} {
+			decision.Reasons = append(decision.Reasons, policyMessages(doc[key])...)
+		}
+		if !decision.Allowed && len(decision.Reasons) == 0 {
+			decision.Reasons = []string{"no allow rule matched the tool call"}
+		}
+	default:
+		decision.Reasons = []string{fmt.Sprintf("%s evaluated to %v, which is not a decision", ToolPolicyPackage, doc)}
+	}
+	return decision, nil
+}
+
+// policyMessages returns the messages of a rule value: a string, a set of
+// strings, or objects with a msg, message or reason
+func policyMessages(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		var messages []string
+		for _, item := range v {
+			messages = append(messages, policyMessages(item)...)
+		}
+		return messages
+	case map[string]interface{}:
+		for _, key := range []string{"msg", "message", "reason"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return []string{s}
+			}
+		}
+	}
+	return nil
+}
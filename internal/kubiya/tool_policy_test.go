@@ -0,0 +1,87 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestExplainToolExecution(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		wantAllowed bool
+		wantReasons []string
+		wantErr     bool
+	}{
+		{
+			name:        "allowed",
+			response:    `{"result":{"allow":true}}`,
+			wantAllowed: true,
+		},
+		{
+			name:        "denied with deny messages",
+			response:    `{"result":{"allow":false,"deny":["deleting pods requires the sre group",{"msg":"prod is frozen"}]}}`,
+			wantReasons: []string{"deleting pods requires the sre group", "prod is frozen"},
+		},
+		{
+			name:        "no allow rule matched",
+			response:    `{"result":{}}`,
+			wantReasons: []string{"no allow rule matched the tool call"},
+		},
+		{
+			name:        "no policy loaded",
+			response:    `{}`,
+			wantReasons: []string{"no tool execution policy is loaded: data.tools is undefined"},
+		},
+		{
+			name:     "evaluation error",
+			response: `{"error":"rego_parse_error: unexpected eof"}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PolicyEvaluationRequest
+			_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/opa/policies/evaluate" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				fmt.Fprint(w, tt.response)
+			})
+
+			decision, err := client.ExplainToolExecution(context.Background(), "kubectl",
+				map[string]interface{}{"command": "delete pod"}, "prod-runner", "bob@corp.com")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ExplainToolExecution() = %+v, want an error", decision)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExplainToolExecution() error = %v", err)
+			}
+
+			if got.Query != "data.tools" {
+				t.Errorf("query = %q", got.Query)
+			}
+			user, _ := got.Input["user"].(map[string]interface{})
+			if got.Input["tool_name"] != "kubectl" || got.Input["runner"] != "prod-runner" || user["email"] != "bob@corp.com" {
+				t.Errorf("input = %v", got.Input)
+			}
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("allowed = %v, want %v", decision.Allowed, tt.wantAllowed)
+			}
+			if !reflect.DeepEqual(decision.Reasons, tt.wantReasons) {
+				t.Errorf("reasons = %q, want %q", decision.Reasons, tt.wantReasons)
+			}
+		})
+	}
+}
@@ -70,6 +70,19 @@ type Knowledge struct {
 	TaskID               string            `json:"task_id"`
 }
 
+// KnowledgeVersion is a snapshot of a knowledge item. Content is only
+// populated when a single version is fetched.
+type KnowledgeVersion struct {
+	Version     int       `json:"version"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Content     string    `json:"content,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+}
+
 // Source represents a tool source
 type Source struct {
 	UUID                    string                 `json:"uuid"`
@@ -88,6 +101,7 @@ type Source struct {
 	InlineTools             []Tool                 `json:"inline_tools,omitempty"`   // For inline sources
 	DynamicConfig           map[string]interface{} `json:"dynamic_config,omitempty"` // Dynamic configuration
 	Runner                  string                 `json:"runner,omitempty"`         // Runner name
+	PinnedRef               string                 `json:"pinned_ref"`               // Commit the tools are loaded from, empty to follow the branch
 	CreatedAt               time.Time              `json:"created_at"`
 	UpdatedAt               time.Time              `json:"updated_at"`
 }
@@ -114,6 +128,7 @@ type ToolArg struct {
 type ToolSource struct {
 	ID  string `json:"id"`
 	URL string `json:"url"`
+	Ref string `json:"ref,omitempty"` // Pinned commit of the source
 }
 
 // Tool represents a tool in a source
@@ -131,10 +146,23 @@ type Tool struct {
 	Alias       string      `json:"alias,omitempty"`
 	WithFiles   interface{} `json:"with_files,omitempty"`   // Can be []string or map[string]interface{}
 	WithVolumes interface{} `json:"with_volumes,omitempty"` // Can be []string or map[string]interface{}
-	LongRunning bool        `json:"long_running,omitempty"`
-	Metadata    interface{} `json:"metadata,omitempty"` // Can be []string or other formats
-	Mermaid     string      `json:"mermaid,omitempty"`
-	Image       string      `json:"image,omitempty"`
+	// WithServices are sidecar service images, e.g. postgres:15
+	WithServices []string    `json:"with_services,omitempty" yaml:"with_services,omitempty"`
+	LongRunning  bool        `json:"long_running,omitempty"`
+	Metadata     interface{} `json:"metadata,omitempty"` // Can be []string or other formats
+	Mermaid      string      `json:"mermaid,omitempty"`
+	Image        string      `json:"image,omitempty"`
+	Tests        []ToolTest  `json:"tests,omitempty" yaml:"tests,omitempty"`
+}
+
+// ToolTest is a sample execution of a tool run by `kubiya source test`: the
+// tool is executed with Args and passes when it exits with ExitCode and its
+// output matches OutputRegex
+type ToolTest struct {
+	Name        string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Args        map[string]interface{} `json:"args,omitempty" yaml:"args,omitempty"`
+	ExitCode    int                    `json:"exit_code" yaml:"exit_code"`
+	OutputRegex string                 `json:"output_regex,omitempty" yaml:"output_regex,omitempty"`
 }
 
 // GetToolFiles returns a list of files associated with this tool,
@@ -312,6 +340,8 @@ type ChatMessage struct {
 	SessionID    string `json:"session_id"`
 	Error        string `json:"error"`
 	FinishReason string `json:"finish_reason,omitempty"`
+	// Stream is StreamStdout or StreamStderr on tool output messages
+	Stream string `json:"stream,omitempty"`
 }
 
 // Add these new types to support Tool
@@ -358,6 +388,18 @@ type Runner struct {
 	ToolManagerHealth   HealthStatus `json:"tool_manager_health"`
 	AgentManagerHealth  HealthStatus `json:"agent_manager_health"`
 	KubernetesNamespace string       `json:"kubernetes_namespace"`
+	// Labels are set on the runner by the platform, e.g. zone=eu
+	Labels map[string]string `json:"labels,omitempty"`
+	// Capacity is the load of the runner, when the platform reports it
+	Capacity *RunnerCapacity `json:"capacity,omitempty"`
+}
+
+// RunnerCapacity is the load of a runner
+type RunnerCapacity struct {
+	// QueueDepth is the number of executions waiting for a slot
+	QueueDepth     int `json:"queue_depth"`
+	Running        int `json:"running"`
+	MaxConcurrency int `json:"max_concurrency"`
 }
 
 // HealthStatus represents the health status of a component
@@ -422,6 +464,7 @@ type Webhook struct {
 	UpdatedAt          string        `json:"updated_at,omitempty"`
 	WebhookURL         string        `json:"webhook_url,omitempty"`
 	HideWebhookHeaders bool          `json:"hide_webhook_headers,omitempty"`
+	Paused             bool          `json:"paused,omitempty"`
 }
 
 // Communication represents webhook communication settings
@@ -512,6 +555,7 @@ type SyncOptions struct {
 	Force      bool   `json:"force,omitempty"`
 	AutoCommit bool   `json:"auto_commit,omitempty"`
 	NoDiff     bool   `json:"no_diff,omitempty"`
+	Ref        string `json:"ref,omitempty"` // Commit to load, for pinned sources
 }
 
 // Arg represents a tool argument
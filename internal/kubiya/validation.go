@@ -0,0 +1,137 @@
+package kubiya
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a problem with a single field of a request payload.
+// Path is a JSON path such as "sources[1]" or "environment_variables.TOKEN".
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports every problem found in a payload, either by local
+// pre-validation (StatusCode 0) or from a 400 or 422 API response.
+type ValidationError struct {
+	StatusCode int          `json:"status_code,omitempty"`
+	Fields     []FieldError `json:"fields"`
+	// Message holds the API error text when it could not be mapped to fields
+	Message string `json:"message,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	if e.StatusCode != 0 {
+		sb.WriteString(fmt.Sprintf("request rejected by the API (status %d)", e.StatusCode))
+	} else {
+		sb.WriteString(fmt.Sprintf("%d validation error(s)", len(e.Fields)))
+	}
+	if e.Message != "" {
+		sb.WriteString(": " + e.Message)
+	}
+	for _, f := range e.Fields {
+		path := f.Path
+		if path == "" {
+			path = "(root)"
+		}
+		sb.WriteString(fmt.Sprintf("\n  • %s: %s", path, f.Message))
+	}
+	return sb.String()
+}
+
+// StatusError is an API response with an unexpected status code that is not
+// a validation error, e.g. a rejected API key or a missing resource
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// ParseValidationError maps a 400 or 422 response body to per-field errors. It
+// understands FastAPI style details ({"detail": [{"loc": [...], "msg": ...}]}),
+// field maps ({"errors": {"name": "is required"}}) and plain messages.
+func ParseValidationError(statusCode int, body []byte) *ValidationError {
+	verr := &ValidationError{StatusCode: statusCode}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		verr.Message = strings.TrimSpace(string(body))
+		return verr
+	}
+
+	for _, key := range []string{"detail", "errors"} {
+		switch v := doc[key].(type) {
+		case []interface{}:
+			for _, item := range v {
+				entry, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				msg, _ := entry["msg"].(string)
+				if msg == "" {
+					msg, _ = entry["message"].(string)
+				}
+				path, _ := entry["field"].(string)
+				if loc, ok := entry["loc"].([]interface{}); ok {
+					path = formatErrorLoc(loc)
+				}
+				verr.Fields = append(verr.Fields, FieldError{Path: path, Message: msg})
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				verr.Fields = append(verr.Fields, FieldError{Path: k, Message: fmt.Sprint(v[k])})
+			}
+		case string:
+			if verr.Message == "" {
+				verr.Message = v
+			}
+		}
+	}
+
+	if len(verr.Fields) == 0 && verr.Message == "" {
+		for _, key := range []string{"message", "error"} {
+			if msg, ok := doc[key].(string); ok {
+				verr.Message = msg
+				break
+			}
+		}
+	}
+	if len(verr.Fields) == 0 && verr.Message == "" {
+		verr.Message = strings.TrimSpace(string(body))
+	}
+
+	return verr
+}
+
+// formatErrorLoc turns a FastAPI error location like ["body", "sources", 1]
+// into a JSON path like "sources[1]"
+func formatErrorLoc(loc []interface{}) string {
+	var sb strings.Builder
+	for i, part := range loc {
+		switch p := part.(type) {
+		case float64:
+			sb.WriteString(fmt.Sprintf("[%d]", int(p)))
+		case string:
+			if i == 0 && p == "body" {
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(p)
+		}
+	}
+	return sb.String()
+}
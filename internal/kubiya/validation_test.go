@@ -1,6 +1,11 @@
 package kubiya
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -54,3 +59,86 @@ func TestValidateVariableType(t *testing.T) {
 		})
 	}
 }
+
+func TestParseValidationError(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantFields  []FieldError
+		wantMessage string
+	}{
+		{
+			name: "fastapi details",
+			body: `{"detail":[{"loc":["body","sources",1],"msg":"invalid uuid","type":"value_error"},{"loc":["body","name"],"msg":"field required"}]}`,
+			wantFields: []FieldError{
+				{Path: "sources[1]", Message: "invalid uuid"},
+				{Path: "name", Message: "field required"},
+			},
+		},
+		{
+			name: "field map",
+			body: `{"errors":{"name":"is required","llm_model":"unknown model"}}`,
+			wantFields: []FieldError{
+				{Path: "llm_model", Message: "unknown model"},
+				{Path: "name", Message: "is required"},
+			},
+		},
+		{
+			name:        "plain detail",
+			body:        `{"detail":"agent already exists"}`,
+			wantMessage: "agent already exists",
+		},
+		{
+			name:        "not json",
+			body:        "bad request\n",
+			wantMessage: "bad request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verr := ParseValidationError(422, []byte(tt.body))
+			if !reflect.DeepEqual(verr.Fields, tt.wantFields) {
+				t.Errorf("expected fields %+v, got %+v", tt.wantFields, verr.Fields)
+			}
+			if verr.Message != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, verr.Message)
+			}
+			if !strings.Contains(verr.Error(), "status 422") {
+				t.Errorf("expected status in error, got %q", verr.Error())
+			}
+		})
+	}
+}
+
+func TestCreateAgentErrorStatus(t *testing.T) {
+	tests := []struct {
+		status         int
+		wantValidation bool
+	}{
+		{400, true},
+		{422, true},
+		{401, false},
+		{403, false},
+		{404, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			_, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"detail":"rejected"}`))
+			})
+
+			_, err := client.CreateAgent(context.Background(), Agent{Name: "test"})
+			var verr *ValidationError
+			var statusErr *StatusError
+			if errors.As(err, &verr) != tt.wantValidation {
+				t.Errorf("expected validation error %v, got %v", tt.wantValidation, err)
+			}
+			if !tt.wantValidation && (!errors.As(err, &statusErr) || statusErr.StatusCode != tt.status) {
+				t.Errorf("expected status error %d, got %v", tt.status, err)
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"time"
 )
 
 // ListWebhooks retrieves all webhooks
@@ -200,3 +203,65 @@ func (c *Client) ImportWebhookFromJSON(ctx context.Context, jsonData []byte) (*W
 
 	return c.CreateWebhook(ctx, webhook)
 }
+
+// WebhookDelivery is a single invocation of a webhook
+type WebhookDelivery struct {
+	ID          string    `json:"id"`
+	WebhookID   string    `json:"webhook_id"`
+	Timestamp   time.Time `json:"timestamp"`
+	Status      string    `json:"status"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	PayloadSize int       `json:"payload_size"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// PauseWebhook stops a webhook from starting sessions until it is resumed.
+// Deliveries received while paused are still recorded.
+func (c *Client) PauseWebhook(ctx context.Context, id string) error {
+	return c.setWebhookState(ctx, id, "pause")
+}
+
+// ResumeWebhook re-enables a paused webhook
+func (c *Client) ResumeWebhook(ctx context.Context, id string) error {
+	return c.setWebhookState(ctx, id, "resume")
+}
+
+func (c *Client) setWebhookState(ctx context.Context, id, action string) error {
+	resp, err := c.post(ctx, fmt.Sprintf("/event/%s/%s", id, action), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the deliveries of a webhook received after
+// since, newest first
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id string, since time.Time) ([]WebhookDelivery, error) {
+	path := fmt.Sprintf("/event/%s/deliveries", id)
+	if !since.IsZero() {
+		path += "?since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+
+	resp, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var deliveries []WebhookDelivery
+	if err := json.NewDecoder(resp.Body).Decode(&deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %w", err)
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].Timestamp.After(deliveries[j].Timestamp)
+	})
+	return deliveries, nil
+}
@@ -12,6 +12,9 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // WorkflowClient handles workflow-specific operations
@@ -69,6 +72,8 @@ type WorkflowSSEEvent struct {
 	Data     string
 	Step     string
 	Progress int
+	// Stream is set to StreamStdout or StreamStderr on tool output events
+	Stream string
 }
 
 // GenerateWorkflow generates a workflow from a natural language prompt using the orchestration API
@@ -116,12 +121,11 @@ func (wc *WorkflowClient) GenerateWorkflow(ctx context.Context, prompt string, o
 	// Always use UserKey format for API key authentication
 	// The orchestrator expects UserKey format for Kubiya API keys (even if they are JWTs)
 	req.Header.Set("Authorization", "UserKey "+options.BearerToken)
+	correlation.Set(req)
 
 	// Execute request
 	// Create a custom client with longer timeout for orchestration
-	orchestrationClient := &http.Client{
-		Timeout: 5 * time.Minute, // Longer timeout for orchestration
-	}
+	orchestrationClient := httpclient.New(5 * time.Minute) // Longer timeout for orchestration
 	resp, err := orchestrationClient.Do(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "deadline exceeded") {
@@ -296,6 +300,7 @@ func (wc *WorkflowClient) ExecuteWorkflow(ctx context.Context, req WorkflowExecu
 
 	// Set headers - use UserKey format like other CLI commands
 	httpReq.Header.Set("Authorization", "UserKey "+wc.client.cfg.APIKey)
+	correlation.Set(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("x-vercel-ai-data-stream", "v1") // protocol flag
@@ -303,9 +308,7 @@ func (wc *WorkflowClient) ExecuteWorkflow(ctx context.Context, req WorkflowExecu
 	httpReq.Header.Set("Connection", "keep-alive")
 
 	// Execute request with no timeout for streaming connections
-	streamingClient := &http.Client{
-		Timeout: 0, // No timeout for streaming connections
-	}
+	streamingClient := httpclient.New(0) // No timeout for streaming connections
 	resp, err := streamingClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
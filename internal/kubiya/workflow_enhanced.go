@@ -11,6 +11,9 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/correlation"
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // EnhancedWorkflowClient provides robust workflow operations with proper error handling
@@ -162,9 +165,7 @@ type WorkflowProgress struct {
 func NewEnhancedWorkflowClient(client *Client) *EnhancedWorkflowClient {
 	daguClient := &DAGUClient{
 		baseURL: client.baseURL,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: httpclient.New(30 * time.Second),
 		apiKey: client.cfg.APIKey,
 	}
 
@@ -274,6 +275,7 @@ func (ewc *EnhancedWorkflowClient) executeWithRetry(ctx context.Context, req Wor
 
 	// Set headers for enhanced streaming
 	httpReq.Header.Set("Authorization", "UserKey "+ewc.client.cfg.APIKey)
+	correlation.Set(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("x-vercel-ai-data-stream", "v1")
@@ -282,7 +284,7 @@ func (ewc *EnhancedWorkflowClient) executeWithRetry(ctx context.Context, req Wor
 	httpReq.Header.Set("X-Request-Enhanced", "true")
 
 	// Execute request
-	client := &http.Client{Timeout: 0} // No timeout for streaming
+	client := httpclient.New(0) // No timeout for streaming
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
@@ -610,6 +612,7 @@ func (ewc *EnhancedWorkflowClient) callWorkflowAPI(ctx context.Context, operatio
 	}
 
 	httpReq.Header.Set("Authorization", "UserKey "+ewc.client.cfg.APIKey)
+	correlation.Set(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := ewc.daguClient.client.Do(httpReq)
@@ -656,6 +659,7 @@ func (ewc *EnhancedWorkflowClient) callWorkflowListAPI(ctx context.Context, oper
 	}
 
 	httpReq.Header.Set("Authorization", "UserKey "+ewc.client.cfg.APIKey)
+	correlation.Set(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := ewc.daguClient.client.Do(httpReq)
@@ -0,0 +1,268 @@
+// Package log is the leveled, structured logger of the CLI. Diagnostics are
+// written to stderr or a file, never to stdout, so that they do not mix with
+// command output. Each message belongs to a subsystem that can be turned on
+// and off on its own, e.g. to see the raw SSE events of a chat stream without
+// the HTTP calls that started it.
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Level is the severity of a message
+type Level = slog.Level
+
+// Levels, from the most to the least verbose
+const (
+	LevelTrace Level = slog.LevelDebug - 4
+	LevelDebug Level = slog.LevelDebug
+	LevelInfo  Level = slog.LevelInfo
+	LevelWarn  Level = slog.LevelWarn
+)
+
+// Subsystems that messages belong to
+const (
+	HTTP = "http" // API requests and responses
+	SSE  = "sse"  // events of chat and execution streams
+	MCP  = "mcp"  // the MCP server
+	TUI  = "tui"  // terminal user interfaces
+	Chat = "chat" // the chat command
+)
+
+// Subsystems lists every subsystem
+var Subsystems = []string{HTTP, SSE, MCP, TUI, Chat}
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Environment variables that configure logging when there are no flags
+const (
+	EnvLevel      = "KUBIYA_LOG_LEVEL"
+	EnvFormat     = "KUBIYA_LOG_FORMAT"
+	EnvSubsystems = "KUBIYA_LOG_SUBSYSTEMS"
+)
+
+var levelNames = map[string]Level{
+	"trace": LevelTrace,
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+}
+
+// Options configure logging
+type Options struct {
+	Level      string   // trace, debug, info or warn
+	Format     string   // text or json
+	Subsystems []string // empty for all
+	Output     io.Writer
+}
+
+// config is the active configuration
+type config struct {
+	level      *slog.LevelVar
+	subsystems map[string]bool // nil for all
+	logger     *slog.Logger
+}
+
+var (
+	active atomic.Pointer[config]
+	// mu serializes writes of records so that lines never interleave
+	mu sync.Mutex
+)
+
+func init() {
+	opts := OptionsFromEnv()
+	if err := Configure(opts); err != nil {
+		opts.Level, opts.Format, opts.Subsystems = "", "", nil
+		_ = Configure(opts)
+	}
+}
+
+// OptionsFromEnv returns the options set by the environment. KUBIYA_DEBUG
+// and DEBUG select the debug level and KUBIYA_RAW_EVENTS the trace level of
+// the sse subsystem, as before leveled logging.
+func OptionsFromEnv() Options {
+	opts := Options{
+		Level:  os.Getenv(EnvLevel),
+		Format: os.Getenv(EnvFormat),
+	}
+	if s := os.Getenv(EnvSubsystems); s != "" {
+		opts.Subsystems = strings.Split(s, ",")
+	}
+	if opts.Level == "" {
+		switch {
+		case os.Getenv("KUBIYA_RAW_EVENTS") == "1":
+			opts.Level = "trace"
+			if opts.Subsystems == nil {
+				opts.Subsystems = []string{SSE}
+			}
+		case isTrue(os.Getenv("KUBIYA_DEBUG")) || os.Getenv("DEBUG") == "1":
+			opts.Level = "debug"
+		}
+	}
+	return opts
+}
+
+func isTrue(s string) bool {
+	return s == "1" || strings.EqualFold(s, "true")
+}
+
+// ParseLevel parses a level name
+func ParseLevel(s string) (Level, error) {
+	level, ok := levelNames[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("invalid log level %q (valid: trace, debug, info, warn)", s)
+	}
+	return level, nil
+}
+
+// Configure replaces the active configuration. The level defaults to warn,
+// the format to text and the output to stderr.
+func Configure(opts Options) error {
+	level := LevelWarn
+	if opts.Level != "" {
+		var err error
+		if level, err = ParseLevel(opts.Level); err != nil {
+			return err
+		}
+	}
+
+	var subsystems map[string]bool
+	for _, s := range opts.Subsystems {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if !isSubsystem(s) {
+			return fmt.Errorf("invalid log subsystem %q (valid: %s)", s, strings.Join(Subsystems, ", "))
+		}
+		if subsystems == nil {
+			subsystems = map[string]bool{}
+		}
+		subsystems[s] = true
+	}
+
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level)
+	handlerOpts := &slog.HandlerOptions{
+		Level:       levelVar,
+		ReplaceAttr: replaceLevel,
+	}
+	var handler slog.Handler
+	switch opts.Format {
+	case "", FormatText:
+		handler = slog.NewTextHandler(out, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		return fmt.Errorf("invalid log format %q (valid: %s, %s)", opts.Format, FormatText, FormatJSON)
+	}
+
+	active.Store(&config{level: levelVar, subsystems: subsystems, logger: slog.New(handler)})
+	return nil
+}
+
+// Verbose lowers the level to level when it is less verbose, e.g. for the
+// --debug flag of a command
+func Verbose(level Level) {
+	if c := active.Load(); c.level.Level() > level {
+		c.level.Set(level)
+	}
+}
+
+func isSubsystem(s string) bool {
+	for _, sub := range Subsystems {
+		if s == sub {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceLevel names the trace level, which slog prints as DEBUG-4
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level == LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
+
+// Logger writes the messages of one subsystem. It follows the active
+// configuration, so package level loggers may be created before Configure.
+type Logger struct {
+	subsystem string
+}
+
+// For returns the logger of a subsystem
+func For(subsystem string) *Logger {
+	return &Logger{subsystem: subsystem}
+}
+
+// Enabled reports whether messages of level are written
+func (l *Logger) Enabled(level Level) bool {
+	c := active.Load()
+	return level >= c.level.Level() && (c.subsystems == nil || c.subsystems[l.subsystem])
+}
+
+func (l *Logger) log(level Level, msg string, args ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	c := active.Load()
+	mu.Lock()
+	defer mu.Unlock()
+	c.logger.Log(context.Background(), level, msg, append([]any{"subsystem", l.subsystem}, args...)...)
+}
+
+// Trace logs the most verbose diagnostics, such as raw stream events
+func (l *Logger) Trace(msg string, args ...any) { l.log(LevelTrace, msg, args...) }
+
+// Debug logs diagnostics
+func (l *Logger) Debug(msg string, args ...any) { l.log(LevelDebug, msg, args...) }
+
+// Info logs progress worth knowing about
+func (l *Logger) Info(msg string, args ...any) { l.log(LevelInfo, msg, args...) }
+
+// Warn logs problems that do not stop the command
+func (l *Logger) Warn(msg string, args ...any) { l.log(LevelWarn, msg, args...) }
+
+// Debugf logs a formatted debug message
+func (l *Logger) Debugf(format string, args ...any) {
+	if l.Enabled(LevelDebug) {
+		l.log(LevelDebug, fmt.Sprintf(format, args...))
+	}
+}
+
+// Writer returns a writer that logs every line written to it at level, for
+// code that takes a standard library logger
+func (l *Logger) Writer(level Level) io.Writer {
+	return lineWriter{logger: l, level: level}
+}
+
+type lineWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.logger.log(w.level, line)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLevelsAndSubsystems(t *testing.T) {
+	var out bytes.Buffer
+	if err := Configure(Options{Level: "debug", Subsystems: []string{"sse", " HTTP "}, Output: &out}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Configure(Options{}) })
+
+	For(SSE).Trace("hidden, below the level")
+	For(SSE).Debug("stream finished", "reason", "stop")
+	For(HTTP).Warn("slow call")
+	For(TUI).Warn("hidden, subsystem is off")
+
+	got := out.String()
+	if strings.Contains(got, "hidden") {
+		t.Errorf("unexpected records:\n%s", got)
+	}
+	if !strings.Contains(got, `level=DEBUG msg="stream finished" subsystem=sse reason=stop`) {
+		t.Errorf("missing sse record:\n%s", got)
+	}
+	if !strings.Contains(got, `level=WARN msg="slow call" subsystem=http`) {
+		t.Errorf("missing http record:\n%s", got)
+	}
+}
+
+func TestJSONAndTrace(t *testing.T) {
+	var out bytes.Buffer
+	if err := Configure(Options{Level: "trace", Format: FormatJSON, Output: &out}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Configure(Options{}) })
+
+	For(MCP).Trace("raw event", "line", 3)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, out.String())
+	}
+	if record["level"] != "TRACE" || record["subsystem"] != "mcp" || record["msg"] != "raw event" || record["line"] != float64(3) {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+func TestVerboseAndWriter(t *testing.T) {
+	var out bytes.Buffer
+	if err := Configure(Options{Output: &out}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Configure(Options{}) })
+
+	For(Chat).Debug("hidden at the default level")
+	Verbose(LevelDebug)
+	Verbose(LevelWarn) // never less verbose
+	For(Chat).Debugf("found %d tools", 2)
+	For(MCP).Writer(LevelInfo).Write([]byte("first\nsecond\n"))
+
+	got := out.String()
+	if strings.Contains(got, "hidden") {
+		t.Errorf("unexpected record:\n%s", got)
+	}
+	for _, want := range []string{`msg="found 2 tools" subsystem=chat`, "msg=first subsystem=mcp", "msg=second subsystem=mcp"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, opts := range []Options{{Level: "verbose"}, {Format: "xml"}, {Subsystems: []string{"db"}}} {
+		if err := Configure(opts); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv(EnvLevel, "")
+	t.Setenv("KUBIYA_RAW_EVENTS", "")
+	t.Setenv("DEBUG", "")
+	t.Setenv("KUBIYA_DEBUG", "true")
+	if opts := OptionsFromEnv(); opts.Level != "debug" {
+		t.Errorf("KUBIYA_DEBUG: got level %q", opts.Level)
+	}
+
+	t.Setenv("KUBIYA_RAW_EVENTS", "1")
+	if opts := OptionsFromEnv(); opts.Level != "trace" || strings.Join(opts.Subsystems, ",") != SSE {
+		t.Errorf("KUBIYA_RAW_EVENTS: got %+v", opts)
+	}
+
+	t.Setenv(EnvLevel, "info")
+	t.Setenv(EnvSubsystems, "http,mcp")
+	if opts := OptionsFromEnv(); opts.Level != "info" || strings.Join(opts.Subsystems, ",") != "http,mcp" {
+		t.Errorf("KUBIYA_LOG_LEVEL: got %+v", opts)
+	}
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// defaultAgentToolsRefresh is how often the tools of an exposed agent are
+// discovered again
+const defaultAgentToolsRefresh = 5 * time.Minute
+
+// agentToolsClient is the part of kubiya.Client used to discover the tools
+// of an agent
+type agentToolsClient interface {
+	GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error)
+	GetSourceMetadata(ctx context.Context, uuid string) (*kubiya.Source, error)
+}
+
+// toolRegistry is the part of the MCP server tools are added to and
+// removed from
+type toolRegistry interface {
+	AddTools(tools ...server.ServerTool)
+	DeleteTools(names ...string)
+}
+
+// DiscoverAgentTools returns the tools of all the sources of an agent. When
+// two sources have a tool of the same name, the first source wins. Tools
+// run on the first runner of the agent.
+func DiscoverAgentTools(ctx context.Context, client agentToolsClient, agentUUID string) ([]WhitelistedTool, error) {
+	agent, err := client.GetAgent(ctx, agentUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent %s: %w", agentUUID, err)
+	}
+	runner := ""
+	if len(agent.Runners) > 0 {
+		runner = agent.Runners[0]
+	}
+
+	seen := map[string]bool{}
+	var tools []WhitelistedTool
+	for _, sourceUUID := range agent.Sources {
+		source, err := client.GetSourceMetadata(ctx, sourceUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source %s of agent %s: %w", sourceUUID, agent.Name, err)
+		}
+		fromSource, err := WhitelistFromSource(source, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, wt := range fromSource {
+			if seen[wt.Name] {
+				continue
+			}
+			seen[wt.Name] = true
+			wt.Runner = runner
+			tools = append(tools, wt)
+		}
+	}
+	return tools, nil
+}
+
+// agentToolExposer keeps the tools of an agent exposed as individual MCP
+// tools, adding, updating and removing them as the sources of the agent
+// change
+type agentToolExposer struct {
+	client    agentToolsClient
+	agentUUID string
+	registry  toolRegistry
+	interval  time.Duration
+	logger    *log.Logger
+	// serverTool builds the MCP tool and handler of a Kubiya tool
+	serverTool func(WhitelistedTool) server.ServerTool
+
+	exposed map[string]WhitelistedTool
+}
+
+func newAgentToolExposer(client agentToolsClient, agentUUID string, registry toolRegistry, interval time.Duration, logger *log.Logger, serverTool func(WhitelistedTool) server.ServerTool) *agentToolExposer {
+	if interval <= 0 {
+		interval = defaultAgentToolsRefresh
+	}
+	return &agentToolExposer{
+		client:     client,
+		agentUUID:  agentUUID,
+		registry:   registry,
+		interval:   interval,
+		logger:     logger,
+		serverTool: serverTool,
+		exposed:    map[string]WhitelistedTool{},
+	}
+}
+
+// refresh discovers the tools of the agent and updates the server. On
+// error the tools exposed so far are kept.
+func (e *agentToolExposer) refresh(ctx context.Context) error {
+	tools, err := DiscoverAgentTools(ctx, e.client, e.agentUUID)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]WhitelistedTool, len(tools))
+	var changed []server.ServerTool
+	for _, wt := range tools {
+		current[wt.Name] = wt
+		if old, ok := e.exposed[wt.Name]; !ok || !reflect.DeepEqual(old, wt) {
+			changed = append(changed, e.serverTool(wt))
+		}
+	}
+	var removed []string
+	for name := range e.exposed {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+
+	if len(removed) > 0 {
+		e.registry.DeleteTools(removed...)
+	}
+	if len(changed) > 0 {
+		e.registry.AddTools(changed...)
+	}
+	if (len(removed) > 0 || len(changed) > 0) && e.logger != nil {
+		e.logger.Printf("Agent %s tools: %d exposed, %d added or updated, %d removed",
+			e.agentUUID, len(current), len(changed), len(removed))
+	}
+	e.exposed = current
+	return nil
+}
+
+// run refreshes the tools until ctx is done
+func (e *agentToolExposer) run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.refresh(ctx); err != nil && e.logger != nil {
+				e.logger.Printf("Failed to refresh the tools of agent %s: %v", e.agentUUID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+type fakeAgentToolsClient struct {
+	agent   *kubiya.Agent
+	sources map[string]*kubiya.Source
+	err     error
+}
+
+func (f *fakeAgentToolsClient) GetAgent(ctx context.Context, agentID string) (*kubiya.Agent, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.agent, nil
+}
+
+func (f *fakeAgentToolsClient) GetSourceMetadata(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	source, ok := f.sources[uuid]
+	if !ok {
+		return nil, fmt.Errorf("source %s not found", uuid)
+	}
+	return source, nil
+}
+
+type fakeToolRegistry struct {
+	tools   map[string]server.ServerTool
+	added   []string
+	deleted []string
+}
+
+func (f *fakeToolRegistry) AddTools(tools ...server.ServerTool) {
+	for _, t := range tools {
+		f.tools[t.Tool.Name] = t
+		f.added = append(f.added, t.Tool.Name)
+	}
+}
+
+func (f *fakeToolRegistry) DeleteTools(names ...string) {
+	for _, name := range names {
+		delete(f.tools, name)
+		f.deleted = append(f.deleted, name)
+	}
+}
+
+func newFakeAgentTools() *fakeAgentToolsClient {
+	return &fakeAgentToolsClient{
+		agent: &kubiya.Agent{
+			UUID:    "agent-1",
+			Name:    "devops",
+			Sources: []string{"src-1", "src-2"},
+			Runners: []string{"k8s-runner"},
+		},
+		sources: map[string]*kubiya.Source{
+			"src-1": {
+				UUID: "src-1",
+				Tools: []kubiya.Tool{
+					{Name: "kubectl", Description: "Run kubectl", Args: []kubiya.ToolArg{
+						{Name: "command", Type: "string", Required: true},
+						{Name: "output", Options: []string{"json", "yaml"}, Default: "json"},
+					}},
+				},
+			},
+			"src-2": {
+				UUID: "src-2",
+				Tools: []kubiya.Tool{
+					{Name: "kubectl", Description: "Shadowed"},
+					{Name: "helm", Description: "Run helm"},
+				},
+			},
+		},
+	}
+}
+
+func TestDiscoverAgentTools(t *testing.T) {
+	tools, err := DiscoverAgentTools(context.Background(), newFakeAgentTools(), "agent-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(tools))
+	}
+	if tools[0].Name != "kubectl" || tools[0].Description != "Run kubectl" {
+		t.Errorf("expected kubectl of the first source, got %+v", tools[0])
+	}
+	for _, tool := range tools {
+		if tool.Runner != "k8s-runner" {
+			t.Errorf("expected %s to run on k8s-runner, got %q", tool.Name, tool.Runner)
+		}
+	}
+}
+
+func TestDiscoverAgentToolsErrors(t *testing.T) {
+	client := newFakeAgentTools()
+	client.agent.Sources = append(client.agent.Sources, "missing")
+	if _, err := DiscoverAgentTools(context.Background(), client, "agent-1"); err == nil {
+		t.Error("expected an error for a missing source")
+	}
+
+	client = newFakeAgentTools()
+	client.err = fmt.Errorf("not found")
+	if _, err := DiscoverAgentTools(context.Background(), client, "agent-1"); err == nil {
+		t.Error("expected an error for a missing agent")
+	}
+}
+
+func TestAgentToolExposerRefresh(t *testing.T) {
+	client := newFakeAgentTools()
+	registry := &fakeToolRegistry{tools: map[string]server.ServerTool{}}
+	exposer := newAgentToolExposer(client, "agent-1", registry, 0, nil, func(wt WhitelistedTool) server.ServerTool {
+		return (&WhitelistedToolHandler{tool: wt}).ServerTool()
+	})
+	if exposer.interval != defaultAgentToolsRefresh {
+		t.Errorf("expected the default interval, got %v", exposer.interval)
+	}
+
+	if err := exposer.refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registry.tools) != 2 {
+		t.Fatalf("expected 2 tools exposed, got %d", len(registry.tools))
+	}
+	schema := registry.tools["kubectl"].Tool.InputSchema
+	if len(schema.Required) != 1 || schema.Required[0] != "command" {
+		t.Errorf("expected command to be required, got %v", schema.Required)
+	}
+	output, _ := schema.Properties["output"].(map[string]interface{})
+	if output["default"] != "json" {
+		t.Errorf("expected output to default to json, got %v", output)
+	}
+
+	// Unchanged tools are left alone
+	registry.added = nil
+	if err := exposer.refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registry.added) != 0 || len(registry.deleted) != 0 {
+		t.Errorf("expected no changes, got added %v deleted %v", registry.added, registry.deleted)
+	}
+
+	// Changed tools are updated, removed ones deleted
+	client.sources["src-1"].Tools[0].Description = "Run kubectl commands"
+	client.sources["src-2"].Tools = client.sources["src-2"].Tools[:1]
+	if err := exposer.refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registry.added) != 1 || registry.added[0] != "kubectl" {
+		t.Errorf("expected kubectl to be updated, got %v", registry.added)
+	}
+	if len(registry.deleted) != 1 || registry.deleted[0] != "helm" {
+		t.Errorf("expected helm to be removed, got %v", registry.deleted)
+	}
+	if registry.tools["kubectl"].Tool.Description != "Run kubectl commands" {
+		t.Errorf("expected the updated description, got %q", registry.tools["kubectl"].Tool.Description)
+	}
+
+	// Failed refreshes keep the exposed tools
+	client.err = fmt.Errorf("unavailable")
+	if err := exposer.refresh(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+	var names []string
+	for name := range registry.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 1 || names[0] != "kubectl" {
+		t.Errorf("expected kubectl to stay exposed, got %v", names)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 const (
@@ -106,9 +108,7 @@ func EnsureKubiyaCLI(forceUpdate bool) (string, error) {
 
 // getLatestVersion fetches the latest version from GitHub API
 func getLatestVersion() (string, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := httpclient.New(10 * time.Second)
 
 	req, err := http.NewRequest("GET", githubAPI, nil)
 	if err != nil {
@@ -178,9 +178,7 @@ func downloadCLI(version, destination string) error {
 	logInfo(fmt.Sprintf("Downloading from: %s", downloadURL))
 
 	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
+	client := httpclient.New(5 * time.Minute)
 
 	// Download the file
 	resp, err := client.Get(downloadURL)
@@ -1,13 +1,13 @@
 package mcp
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 
+	"github.com/kubiyabot/cli/internal/docload"
 	"github.com/kubiyabot/cli/internal/mcp/filter"
+	"github.com/kubiyabot/cli/internal/mcp/middleware"
 	"github.com/spf13/afero"
 )
 
@@ -22,6 +22,11 @@ type Configuration struct {
 	VerboseLogging      bool              `json:"verbose_logging" yaml:"verbose_logging"`
 	EnableDocumentation bool              `json:"enable_documentation" yaml:"enable_documentation"`
 
+	// Agent whose tools are exposed as individual MCP tools, and how often
+	// they are discovered again (in seconds, default 300)
+	ExposeAgentTools  string `json:"expose_agent_tools,omitempty" yaml:"expose_agent_tools,omitempty"`
+	AgentToolsRefresh int    `json:"agent_tools_refresh,omitempty" yaml:"agent_tools_refresh,omitempty"`
+
 	// Content size limits for responses
 	MaxResponseSize     int `json:"max_response_size,omitempty" yaml:"max_response_size,omitempty"`
 	MaxToolsInResponse  int `json:"max_tools_in_response,omitempty" yaml:"max_tools_in_response,omitempty"`
@@ -55,6 +60,14 @@ type Config struct {
 	VerboseLogging      bool `json:"verbose_logging" yaml:"verbose_logging"`
 	EnableDocumentation bool `json:"enable_documentation" yaml:"enable_documentation"`
 
+	// Agent whose tools are exposed as individual MCP tools, and how often
+	// they are discovered again (in seconds, default 300)
+	ExposeAgentTools  string `json:"expose_agent_tools,omitempty" yaml:"expose_agent_tools,omitempty"`
+	AgentToolsRefresh int    `json:"agent_tools_refresh,omitempty" yaml:"agent_tools_refresh,omitempty"`
+
+	// Rules appending organization context (runbooks, knowledge) to tool results
+	EnrichmentRules []middleware.EnrichmentRule `json:"enrichment_rules,omitempty" yaml:"enrichment_rules,omitempty"`
+
 	// User organization ID (set automatically from user config)
 	OrgID string `json:"org_id,omitempty" yaml:"org_id,omitempty"`
 }
@@ -142,19 +155,13 @@ func LoadConfiguration(fs afero.Fs, configFile string, disablePlatformAPIsFlag b
 	}
 
 	// Use provided config file or default location
-	configPath := configFile
-	if configPath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			configPath = filepath.Join(homeDir, ".kubiya", "mcp-server.json")
-		}
-	}
+	configPath := ConfigPath(configFile)
 
 	// Load from file if it exists
 	if configPath != "" {
 		data, err := afero.ReadFile(fs, configPath)
 		if err == nil {
-			if err := json.Unmarshal(data, config); err != nil {
+			if err := docload.Unmarshal(data, config); err != nil {
 				return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 			}
 		} else if !os.IsNotExist(err) {
@@ -175,6 +182,10 @@ func LoadConfiguration(fs afero.Fs, configFile string, disablePlatformAPIsFlag b
 		config.EnableOPAPolicies = envOPAPolicies == "true" || envOPAPolicies == "1"
 	}
 
+	if envAgent := os.Getenv("KUBIYA_MCP_EXPOSE_AGENT_TOOLS"); envAgent != "" {
+		config.ExposeAgentTools = envAgent
+	}
+
 	// Content size environment overrides for simple Configuration
 	if envMaxSize := os.Getenv("KUBIYA_MCP_MAX_RESPONSE_SIZE"); envMaxSize != "" {
 		if size, err := strconv.Atoi(envMaxSize); err == nil && size > 0 {
@@ -235,19 +246,13 @@ func LoadProductionConfig(fs afero.Fs, configFile string, disablePlatformAPIsFla
 	}
 
 	// Use provided config file or default location
-	configPath := configFile
-	if configPath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			configPath = filepath.Join(homeDir, ".kubiya", "mcp-server.json")
-		}
-	}
+	configPath := ConfigPath(configFile)
 
 	// Load from file if it exists
 	if configPath != "" {
 		data, err := afero.ReadFile(fs, configPath)
 		if err == nil {
-			if err := json.Unmarshal(data, config); err != nil {
+			if err := docload.Unmarshal(data, config); err != nil {
 				return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 			}
 		} else if !os.IsNotExist(err) {
@@ -268,6 +273,10 @@ func LoadProductionConfig(fs afero.Fs, configFile string, disablePlatformAPIsFla
 		config.EnableOPAPolicies = envOPAPolicies == "true" || envOPAPolicies == "1"
 	}
 
+	if envAgent := os.Getenv("KUBIYA_MCP_EXPOSE_AGENT_TOOLS"); envAgent != "" {
+		config.ExposeAgentTools = envAgent
+	}
+
 	if envAuth := os.Getenv("KUBIYA_MCP_REQUIRE_AUTH"); envAuth != "" {
 		config.RequireAuth = envAuth == "true"
 	}
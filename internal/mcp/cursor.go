@@ -0,0 +1,308 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Cursor pagination of list_sources and search_tools. Sources are walked
+// in UUID order, and a page ends with an opaque cursor for the next item:
+// the index and UUID of its source and, for tools, its offset in the
+// source. The UUID anchors the position, so that sources added or removed
+// between two pages neither repeat nor skip the items of the others.
+
+// Page limits used when the server configuration sets none
+const (
+	defaultPageSize        = 20
+	defaultMaxPageSize     = 50
+	defaultMaxResponseSize = 51200 // 50KB
+)
+
+// sourceCatalog is the part of kubiya.Client paginated over
+type sourceCatalog interface {
+	ListSources(ctx context.Context) ([]kubiya.Source, error)
+	GetSourceMetadata(ctx context.Context, uuid string) (*kubiya.Source, error)
+}
+
+// pageLimits bounds the pages of a server
+type pageLimits struct {
+	pageSize        int // items per page unless page_size is given
+	maxPageSize     int
+	maxResponseSize int // bytes of the items of a page
+}
+
+func newPageLimits(pageSize, maxPageSize, maxResponseSize int) pageLimits {
+	l := pageLimits{defaultPageSize, defaultMaxPageSize, defaultMaxResponseSize}
+	if pageSize > 0 {
+		l.pageSize = pageSize
+	}
+	if maxPageSize > 0 {
+		l.maxPageSize = maxPageSize
+	}
+	if maxResponseSize > 0 {
+		l.maxResponseSize = maxResponseSize
+	}
+	return l
+}
+
+// size returns the page size asked for in args, within the limits
+func (l pageLimits) size(args map[string]interface{}) int {
+	size := l.pageSize
+	switch v := args["page_size"].(type) {
+	case string:
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			size = n
+		}
+	case float64:
+		if v > 0 {
+			size = int(v)
+		}
+	}
+	if size > l.maxPageSize {
+		size = l.maxPageSize
+	}
+	return size
+}
+
+// pageCursor is the position of the first item of the next page
+type pageCursor struct {
+	Source int    `json:"s"`           // index of the source in UUID order
+	UUID   string `json:"u"`           // UUID of that source
+	Tool   int    `json:"t,omitempty"` // offset of the tool in the source
+	Search string `json:"q,omitempty"` // hash of the search the cursor belongs to
+}
+
+func (c pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor reads the cursor argument, if any, of a search. The search
+// is empty for list_sources.
+func decodeCursor(args map[string]interface{}, search string) (*pageCursor, error) {
+	token, _ := args["cursor"].(string)
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	var c pageCursor
+	if err != nil || json.Unmarshal(data, &c) != nil || c.UUID == "" || c.Source < 0 || c.Tool < 0 {
+		return nil, fmt.Errorf("invalid cursor: pass pagination.next_cursor of the previous page")
+	}
+	if c.Search != search {
+		return nil, fmt.Errorf("the cursor belongs to another listing: start again without a cursor")
+	}
+	return &c, nil
+}
+
+// resume returns the index of the source and the offset of the tool the
+// cursor points at. When its source was removed, the next source is.
+func (c *pageCursor) resume(sources []kubiya.Source) (int, int) {
+	if c == nil {
+		return 0, 0
+	}
+	if c.Source < len(sources) && sources[c.Source].UUID == c.UUID {
+		return c.Source, c.Tool
+	}
+	i := sort.Search(len(sources), func(i int) bool { return sources[i].UUID >= c.UUID })
+	if i < len(sources) && sources[i].UUID == c.UUID {
+		return i, c.Tool
+	}
+	return i, 0
+}
+
+// sortedSources returns the sources in UUID order
+func sortedSources(sources []kubiya.Source) []kubiya.Source {
+	sorted := append([]kubiya.Source(nil), sources...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].UUID < sorted[j].UUID })
+	return sorted
+}
+
+// pageInfo describes a page and how to get the next one
+type pageInfo struct {
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	TotalItems *int   `json:"total_items,omitempty"`
+}
+
+// pageBudget tells when the items of a page reach the response size limit.
+// A page always holds one item, however large.
+type pageBudget struct {
+	left  int
+	items int
+}
+
+func (b *pageBudget) fits(item interface{}) bool {
+	data, _ := json.Marshal(item)
+	if b.items > 0 && len(data)+1 > b.left {
+		return false
+	}
+	b.left -= len(data) + 1
+	b.items++
+	return true
+}
+
+// sourcesPage is the result of list_sources
+type sourcesPage struct {
+	Sources    []SourceMetadata `json:"sources"`
+	Pagination pageInfo         `json:"pagination"`
+}
+
+// listSourcesPage returns a page of source metadata
+func listSourcesPage(ctx context.Context, catalog sourceCatalog, args map[string]interface{}, limits pageLimits) (*mcp.CallToolResult, error) {
+	cursor, err := decodeCursor(args, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sources, err := catalog.ListSources(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list sources: %v", err)), nil
+	}
+	sources = sortedSources(sources)
+
+	size := limits.size(args)
+	total := len(sources)
+	page := sourcesPage{Sources: []SourceMetadata{}, Pagination: pageInfo{PageSize: size, TotalItems: &total}}
+	budget := pageBudget{left: limits.maxResponseSize}
+	i, _ := cursor.resume(sources)
+	for ; i < len(sources) && len(page.Sources) < size; i++ {
+		source := sources[i]
+		metadata := SourceMetadata{
+			UUID:        source.UUID,
+			Name:        source.Name,
+			Description: source.Description,
+			URL:         source.URL,
+			ToolCount:   len(source.Tools) + len(source.InlineTools),
+			CreatedAt:   source.CreatedAt,
+			UpdatedAt:   source.UpdatedAt,
+			Status:      "active", // Default status
+		}
+		if !budget.fits(metadata) {
+			break
+		}
+		page.Sources = append(page.Sources, metadata)
+	}
+	if i < len(sources) {
+		page.Pagination.HasMore = true
+		page.Pagination.NextCursor = pageCursor{Source: i, UUID: sources[i].UUID}.encode()
+	}
+	return jsonToolResult(page)
+}
+
+// toolSearch is the query and filters of search_tools
+type toolSearch struct {
+	Query           string `json:"-"`
+	SourceUUID      string `json:"source_uuid"`
+	ToolType        string `json:"tool_type"`
+	LongRunningOnly bool   `json:"long_running_only"`
+}
+
+// hash identifies the search in its cursors
+func (s toolSearch) hash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		s.Query, s.SourceUUID, s.ToolType, strconv.FormatBool(s.LongRunningOnly),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// toolsPage is the result of search_tools
+type toolsPage struct {
+	Tools      []ToolMetadata `json:"tools"`
+	Pagination pageInfo       `json:"pagination"`
+	Query      string         `json:"query"`
+	Filters    toolSearch     `json:"filters"`
+}
+
+// searchToolsPage returns a page of the tools matching the search. Sources
+// are only fetched until the page is full and the next match is found.
+func searchToolsPage(ctx context.Context, catalog sourceCatalog, args map[string]interface{}, limits pageLimits) (*mcp.CallToolResult, error) {
+	search := toolSearch{Query: stringArg(args, "query")}
+	if search.Query == "" {
+		return mcp.NewToolResultError("query parameter is required"), nil
+	}
+	search.SourceUUID = stringArg(args, "source_uuid")
+	search.ToolType = stringArg(args, "tool_type")
+	search.LongRunningOnly, _ = args["long_running_only"].(bool)
+
+	cursor, err := decodeCursor(args, search.hash())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sources, err := catalog.ListSources(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list sources: %v", err)), nil
+	}
+	if search.SourceUUID != "" {
+		var filtered []kubiya.Source
+		for _, source := range sources {
+			if source.UUID == search.SourceUUID {
+				filtered = append(filtered, source)
+				break
+			}
+		}
+		sources = filtered
+	}
+	sources = sortedSources(sources)
+
+	size := limits.size(args)
+	page := toolsPage{Tools: []ToolMetadata{}, Pagination: pageInfo{PageSize: size}, Query: search.Query, Filters: search}
+	budget := pageBudget{left: limits.maxResponseSize}
+	queryLower := strings.ToLower(search.Query)
+	si, ti := cursor.resume(sources)
+scan:
+	for ; si < len(sources); si, ti = si+1, 0 {
+		source := sources[si]
+		metadata, err := catalog.GetSourceMetadata(ctx, source.UUID)
+		if err != nil {
+			continue // Skip sources that can't be accessed
+		}
+		tools := append(append([]kubiya.Tool(nil), metadata.Tools...), metadata.InlineTools...)
+		for ; ti < len(tools); ti++ {
+			tool := tools[ti]
+			if !matchesTool(tool, queryLower, search.ToolType, search.LongRunningOnly) {
+				continue
+			}
+			toolMeta := ToolMetadata{
+				Name:        tool.Name,
+				Description: tool.Description,
+				SourceUUID:  source.UUID,
+				SourceName:  source.Name,
+				Type:        tool.Type,
+				ArgCount:    len(tool.Args),
+				LongRunning: tool.LongRunning,
+			}
+			if len(page.Tools) == size || !budget.fits(toolMeta) {
+				page.Pagination.HasMore = true
+				page.Pagination.NextCursor = pageCursor{Source: si, UUID: source.UUID, Tool: ti, Search: search.hash()}.encode()
+				break scan
+			}
+			page.Tools = append(page.Tools, toolMeta)
+		}
+	}
+	return jsonToolResult(page)
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// jsonToolResult returns a result whose text is the JSON of v
+func jsonToolResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type fakeCatalog struct {
+	sources []kubiya.Source
+	fetched []string
+}
+
+func (f *fakeCatalog) ListSources(ctx context.Context) ([]kubiya.Source, error) {
+	return f.sources, nil
+}
+
+func (f *fakeCatalog) GetSourceMetadata(ctx context.Context, uuid string) (*kubiya.Source, error) {
+	f.fetched = append(f.fetched, uuid)
+	for i := range f.sources {
+		if f.sources[i].UUID == uuid {
+			return &f.sources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("source %s not found", uuid)
+}
+
+func newFakeSource(uuid string, tools ...string) kubiya.Source {
+	s := kubiya.Source{UUID: uuid, Name: "source-" + uuid}
+	for _, name := range tools {
+		s.Tools = append(s.Tools, kubiya.Tool{Name: name, Description: "deploy helper"})
+	}
+	return s
+}
+
+func decodeResult(t *testing.T, result *mcp.CallToolResult, v interface{}) {
+	t.Helper()
+	text, ok := mcp.AsTextContent(result.Content[0])
+	if !ok {
+		t.Fatal("expected text content")
+	}
+	if result.IsError {
+		t.Fatalf("tool error: %s", text.Text)
+	}
+	if err := json.Unmarshal([]byte(text.Text), v); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, text.Text)
+	}
+}
+
+func TestSearchToolsPageCursor(t *testing.T) {
+	catalog := &fakeCatalog{sources: []kubiya.Source{
+		newFakeSource("c", "deploy-c1", "deploy-c2"),
+		newFakeSource("a", "deploy-a1", "lint", "deploy-a2"),
+		newFakeSource("b", "deploy-b1"),
+	}}
+	limits := newPageLimits(2, 0, 0)
+	ctx := context.Background()
+
+	var names []string
+	args := map[string]interface{}{"query": "deploy-"}
+	for pages := 0; ; pages++ {
+		result, err := searchToolsPage(ctx, catalog, args, limits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var page toolsPage
+		decodeResult(t, result, &page)
+		for _, tool := range page.Tools {
+			names = append(names, tool.Name)
+		}
+		if !page.Pagination.HasMore {
+			break
+		}
+		if pages == 0 {
+			// Sources change between pages: one is added before the
+			// cursor, and the source of the next page is removed
+			catalog.sources = []kubiya.Source{
+				newFakeSource("c", "deploy-c1", "deploy-c2"),
+				newFakeSource("a", "deploy-a1", "lint", "deploy-a2"),
+				newFakeSource("0", "deploy-new"),
+			}
+		}
+		args["cursor"] = page.Pagination.NextCursor
+	}
+
+	want := []string{"deploy-a1", "deploy-a2", "deploy-c1", "deploy-c2"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("tools = %v, want %v", names, want)
+	}
+
+	// The first page only fetches the sources it needs
+	catalog.fetched = nil
+	if _, err := searchToolsPage(ctx, catalog, map[string]interface{}{"query": "deploy-a"}, newPageLimits(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(catalog.fetched) != "[0 a]" {
+		t.Errorf("fetched = %v", catalog.fetched)
+	}
+
+	// A cursor only continues its own search
+	args["query"] = "lint"
+	result, _ := searchToolsPage(ctx, catalog, args, limits)
+	if !result.IsError {
+		t.Error("expected an error for the cursor of another search")
+	}
+	args["cursor"] = "not-a-cursor"
+	result, _ = searchToolsPage(ctx, catalog, args, limits)
+	if !result.IsError {
+		t.Error("expected an error for an invalid cursor")
+	}
+}
+
+func TestListSourcesPageCursor(t *testing.T) {
+	catalog := &fakeCatalog{sources: []kubiya.Source{
+		newFakeSource("b"), newFakeSource("a"), newFakeSource("c"),
+	}}
+
+	result, _ := listSourcesPage(context.Background(), catalog, map[string]interface{}{"page_size": float64(2)}, newPageLimits(0, 0, 0))
+	var page sourcesPage
+	decodeResult(t, result, &page)
+	if len(page.Sources) != 2 || page.Sources[0].UUID != "a" || !page.Pagination.HasMore || *page.Pagination.TotalItems != 3 {
+		t.Fatalf("first page = %+v", page)
+	}
+
+	result, _ = listSourcesPage(context.Background(), catalog, map[string]interface{}{"cursor": page.Pagination.NextCursor}, newPageLimits(0, 0, 0))
+	page = sourcesPage{}
+	decodeResult(t, result, &page)
+	if len(page.Sources) != 1 || page.Sources[0].UUID != "c" || page.Pagination.HasMore || page.Pagination.NextCursor != "" {
+		t.Fatalf("second page = %+v", page)
+	}
+
+	// The response size limit ends a page early, keeping the JSON whole
+	result, _ = listSourcesPage(context.Background(), catalog, map[string]interface{}{}, newPageLimits(10, 0, 200))
+	page = sourcesPage{}
+	decodeResult(t, result, &page)
+	if len(page.Sources) != 1 || !page.Pagination.HasMore {
+		t.Fatalf("limited page = %+v", page)
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultEnrichmentResults caps the knowledge items a rule appends
+const defaultEnrichmentResults = 3
+
+// EnrichmentRule appends organization context to tool results matching a
+// pattern, e.g. runbook links when a kubectl call fails with a known error.
+// Append, Links and KnowledgeQuery may reference capture groups of Match
+// as $1 or ${name}.
+type EnrichmentRule struct {
+	Name           string   `json:"name" yaml:"name"`
+	Tools          []string `json:"tools,omitempty" yaml:"tools,omitempty"` // Tool name globs, all tools when empty
+	Match          string   `json:"match" yaml:"match"`                     // Regular expression matched against the result text
+	OnlyErrors     bool     `json:"only_errors,omitempty" yaml:"only_errors,omitempty"`
+	Append         string   `json:"append,omitempty" yaml:"append,omitempty"`
+	Links          []string `json:"links,omitempty" yaml:"links,omitempty"`
+	KnowledgeQuery string   `json:"knowledge_query,omitempty" yaml:"knowledge_query,omitempty"`
+	MaxResults     int      `json:"max_results,omitempty" yaml:"max_results,omitempty"`
+}
+
+// KnowledgeSearchFunc returns up to limit knowledge items matching query,
+// each formatted as a single line
+type KnowledgeSearchFunc func(ctx context.Context, query string, limit int) ([]string, error)
+
+type compiledEnrichmentRule struct {
+	EnrichmentRule
+	re *regexp.Regexp
+}
+
+// EnrichmentMiddleware enriches tool results with context from rules
+type EnrichmentMiddleware struct {
+	rules  []compiledEnrichmentRule
+	search KnowledgeSearchFunc
+	logger *log.Logger
+}
+
+// NewEnrichmentMiddleware creates enrichment middleware. search may be nil,
+// in which case knowledge queries are skipped.
+func NewEnrichmentMiddleware(rules []EnrichmentRule, search KnowledgeSearchFunc, logger *log.Logger) (*EnrichmentMiddleware, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	m := &EnrichmentMiddleware{search: search, logger: logger}
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.Match == "" {
+			return nil, fmt.Errorf("enrichment rule %s: match is required", name)
+		}
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("enrichment rule %s: invalid match: %w", name, err)
+		}
+		for _, glob := range rule.Tools {
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("enrichment rule %s: invalid tool pattern %q: %w", name, glob, err)
+			}
+		}
+		if rule.Append == "" && len(rule.Links) == 0 && rule.KnowledgeQuery == "" {
+			return nil, fmt.Errorf("enrichment rule %s: one of append, links or knowledge_query is required", name)
+		}
+		rule.Name = name
+		m.rules = append(m.rules, compiledEnrichmentRule{EnrichmentRule: rule, re: re})
+	}
+	return m, nil
+}
+
+// Apply applies the enrichment middleware
+func (m *EnrichmentMiddleware) Apply(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		if err != nil || result == nil || len(m.rules) == 0 {
+			return result, err
+		}
+
+		text := resultText(result)
+		var sections []string
+		for _, rule := range m.rules {
+			if rule.OnlyErrors && !result.IsError || !rule.appliesTo(req.Params.Name) {
+				continue
+			}
+			match := rule.re.FindStringSubmatchIndex(text)
+			if match == nil {
+				continue
+			}
+			if section := m.enrich(ctx, rule, text, match); section != "" {
+				sections = append(sections, section)
+			}
+		}
+
+		if len(sections) > 0 {
+			result.Content = append(result.Content,
+				mcp.NewTextContent("\n---\nRelated context:\n"+strings.Join(sections, "\n")))
+		}
+		return result, nil
+	}
+}
+
+// enrich renders the context a matching rule adds
+func (m *EnrichmentMiddleware) enrich(ctx context.Context, rule compiledEnrichmentRule, text string, match []int) string {
+	expand := func(template string) string {
+		return string(rule.re.ExpandString(nil, template, text, match))
+	}
+
+	var lines []string
+	if rule.Append != "" {
+		lines = append(lines, expand(rule.Append))
+	}
+	for _, link := range rule.Links {
+		lines = append(lines, "• "+expand(link))
+	}
+	if rule.KnowledgeQuery != "" && m.search != nil {
+		limit := rule.MaxResults
+		if limit <= 0 {
+			limit = defaultEnrichmentResults
+		}
+		items, err := m.search(ctx, expand(rule.KnowledgeQuery), limit)
+		if err != nil {
+			m.logger.Printf("[ENRICHMENT] rule=%s knowledge search failed: %v", rule.Name, err)
+		}
+		if len(items) > limit {
+			items = items[:limit]
+		}
+		for _, item := range items {
+			lines = append(lines, "• "+item)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r compiledEnrichmentRule) appliesTo(tool string) bool {
+	if len(r.Tools) == 0 {
+		return true
+	}
+	for _, glob := range r.Tools {
+		if ok, _ := path.Match(glob, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resultText concatenates the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var b strings.Builder
+	for _, c := range result.Content {
+		switch t := c.(type) {
+		case mcp.TextContent:
+			b.WriteString(t.Text)
+			b.WriteString("\n")
+		case *mcp.TextContent:
+			b.WriteString(t.Text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
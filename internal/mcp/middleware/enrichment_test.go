@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toolResult(text string, isError bool) ToolHandler {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText(text)
+		result.IsError = isError
+		return result, nil
+	}
+}
+
+func callTool(t *testing.T, handler ToolHandler, tool string) string {
+	t.Helper()
+	var req mcp.CallToolRequest
+	req.Params.Name = tool
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	return resultText(result)
+}
+
+func TestEnrichmentMiddleware(t *testing.T) {
+	var queries []string
+	search := func(ctx context.Context, query string, limit int) ([]string, error) {
+		queries = append(queries, query)
+		return []string{"Crash loop runbook", "Pod debugging", "Extra", "Dropped"}, nil
+	}
+
+	m, err := NewEnrichmentMiddleware([]EnrichmentRule{{
+		Name:           "crashloop",
+		Tools:          []string{"kubectl*"},
+		Match:          `CrashLoopBackOff: pod/(?P<pod>\S+)`,
+		OnlyErrors:     true,
+		Append:         "Pod ${pod} is crash looping.",
+		Links:          []string{"https://wiki.example.com/runbooks/$1"},
+		KnowledgeQuery: "crashloop $pod",
+		MaxResults:     2,
+	}}, search, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		tool    string
+		text    string
+		isError bool
+		want    string
+	}{
+		{
+			name:    "matching error",
+			tool:    "kubectl_get",
+			text:    "Error: CrashLoopBackOff: pod/api-7f9",
+			isError: true,
+			want: "Error: CrashLoopBackOff: pod/api-7f9\n\n---\nRelated context:\n" +
+				"Pod api-7f9 is crash looping.\n" +
+				"• https://wiki.example.com/runbooks/api-7f9\n" +
+				"• Crash loop runbook\n" +
+				"• Pod debugging\n",
+		},
+		{
+			name: "successful result",
+			tool: "kubectl_get",
+			text: "CrashLoopBackOff: pod/api-7f9",
+			want: "CrashLoopBackOff: pod/api-7f9\n",
+		},
+		{
+			name:    "other tool",
+			tool:    "helm",
+			text:    "CrashLoopBackOff: pod/api-7f9",
+			isError: true,
+			want:    "CrashLoopBackOff: pod/api-7f9\n",
+		},
+		{
+			name:    "no match",
+			tool:    "kubectl_get",
+			text:    "connection refused",
+			isError: true,
+			want:    "connection refused\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := callTool(t, m.Apply(toolResult(tt.text, tt.isError)), tt.tool)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, []string{"crashloop api-7f9"}, queries)
+}
+
+func TestEnrichmentMiddlewareSearchFailure(t *testing.T) {
+	search := func(ctx context.Context, query string, limit int) ([]string, error) {
+		return nil, errors.New("unavailable")
+	}
+	m, err := NewEnrichmentMiddleware([]EnrichmentRule{
+		{Match: "timeout", KnowledgeQuery: "timeouts"},
+		{Match: "timeout", Append: "Check the runner health."},
+	}, search, nil)
+	require.NoError(t, err)
+
+	got := callTool(t, m.Apply(toolResult("request timeout", true)), "any")
+	assert.Equal(t, "request timeout\n\n---\nRelated context:\nCheck the runner health.\n", got)
+}
+
+func TestNewEnrichmentMiddlewareInvalid(t *testing.T) {
+	tests := []struct {
+		rule EnrichmentRule
+		want string
+	}{
+		{EnrichmentRule{Name: "a", Append: "x"}, "match is required"},
+		{EnrichmentRule{Name: "b", Match: "(", Append: "x"}, "invalid match"},
+		{EnrichmentRule{Name: "c", Match: "x"}, "one of append, links or knowledge_query"},
+		{EnrichmentRule{Name: "d", Match: "x", Append: "x", Tools: []string{"["}}, "invalid tool pattern"},
+	}
+	for _, tt := range tests {
+		_, err := NewEnrichmentMiddleware([]EnrichmentRule{tt.rule}, nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+}
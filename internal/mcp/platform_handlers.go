@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -170,99 +169,15 @@ func (s *Server) createIntegrationHandler(ctx context.Context, request mcp.CallT
 }
 
 func (s *Server) listSourcesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	args := request.Params.Arguments
-	
-	// Parse pagination parameters
-	page := 1
-	if pageStr, ok := args["page"].(string); ok {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	} else if pageFloat, ok := args["page"].(float64); ok && pageFloat > 0 {
-		page = int(pageFloat)
-	}
-	
-	// Set default page size with fallback
-	pageSize := 20 // Default fallback
-	maxResponseSize := 51200 // 50KB default
-	maxToolsInResponse := 50 // Default max tools
-	
-	// Use serverConfig values if available
-	if s.serverConfig != nil {
-		if s.serverConfig.DefaultPageSize > 0 {
-			pageSize = s.serverConfig.DefaultPageSize
-		}
-		if s.serverConfig.MaxResponseSize > 0 {
-			maxResponseSize = s.serverConfig.MaxResponseSize
-		}
-		if s.serverConfig.MaxToolsInResponse > 0 {
-			maxToolsInResponse = s.serverConfig.MaxToolsInResponse
-		}
-	}
-	
-	if pageSizeStr, ok := args["page_size"].(string); ok {
-		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
-			pageSize = ps
-		}
-	} else if pageSizeFloat, ok := args["page_size"].(float64); ok && pageSizeFloat > 0 {
-		pageSize = int(pageSizeFloat)
-	}
-	
-	// Limit page size to maximum configured
-	if pageSize > maxToolsInResponse {
-		pageSize = maxToolsInResponse
-	}
-
-	// Get sources from API
-	sources, err := s.client.ListSources(ctx)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list sources: %v", err)), nil
-	}
-
-	// Convert to metadata-only format
-	var sourceMetadata []interface{}
-	for _, source := range sources {
-		// Get basic metadata for each source
-		metadata := SourceMetadata{
-			UUID:        source.UUID,
-			Name:        source.Name,
-			Description: source.Description,
-			URL:         source.URL,
-			ToolCount:   len(source.Tools) + len(source.InlineTools),
-			CreatedAt:   source.CreatedAt,
-			UpdatedAt:   source.UpdatedAt,
-			Status:      "active", // Default status
-		}
-		
-		// Note: Git metadata would need to be fetched separately if needed
-		
-		sourceMetadata = append(sourceMetadata, metadata)
-	}
-
-	// Apply pagination
-	paginatedItems, currentPage, totalPages, hasMore := paginateItems(sourceMetadata, page, pageSize)
-
-	// Create response with pagination info
-	response := map[string]interface{}{
-		"sources": paginatedItems,
-		"pagination": map[string]interface{}{
-			"page":        currentPage,
-			"page_size":   pageSize,
-			"total_pages": totalPages,
-			"total_items": len(sourceMetadata),
-			"has_more":    hasMore,
-		},
-	}
+	return listSourcesPage(ctx, s.client, request.Params.Arguments, s.pageLimits())
+}
 
-	// Marshal and apply size limits
-	data, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal sources: %v", err)), nil
+// pageLimits returns the page limits of the server configuration
+func (s *Server) pageLimits() pageLimits {
+	if s.serverConfig == nil {
+		return newPageLimits(0, 0, 0)
 	}
-
-	// Apply response size limit
-	limitedData := s.limitResponseSize(data, maxResponseSize)
-	return mcp.NewToolResultText(string(limitedData)), nil
+	return newPageLimits(s.serverConfig.DefaultPageSize, s.serverConfig.MaxToolsInResponse, s.serverConfig.MaxResponseSize)
 }
 
 func (s *Server) listSecretsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -574,7 +489,7 @@ func (s *Server) executeToolFromSourceHandler(ctx context.Context, request mcp.C
 	}
 
 	// Execute the tool using the existing execution logic
-	return s.executeSpecificTool(ctx, foundTool, toolArgs, runner)
+	return s.executeSpecificTool(ctx, foundTool, toolArgs, runner, newProgressReporter(ctx, request))
 }
 
 func (s *Server) discoverSourceHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -603,7 +518,7 @@ func (s *Server) discoverSourceHandler(ctx context.Context, request mcp.CallTool
 }
 
 // Helper method to execute a specific tool
-func (s *Server) executeSpecificTool(ctx context.Context, tool *kubiya.Tool, args map[string]interface{}, runner string) (*mcp.CallToolResult, error) {
+func (s *Server) executeSpecificTool(ctx context.Context, tool *kubiya.Tool, args map[string]interface{}, runner string, progress *progressReporter) (*mcp.CallToolResult, error) {
 	// Convert tool arguments to the format expected by ExecuteToolWithTimeout
 	toolDef := map[string]interface{}{
 		"name":        tool.Name,
@@ -645,21 +560,23 @@ func (s *Server) executeSpecificTool(ctx context.Context, tool *kubiya.Tool, arg
 	}
 	output.WriteString("=" + strings.Repeat("=", 50) + "\n\n")
 
+	streams := toolStreams{progress: progress}
 	for event := range eventChan {
+		if streams.add(event, &output) {
+			continue
+		}
 		switch event.Type {
 		case "error":
 			output.WriteString(fmt.Sprintf("❌ Error: %s\n", event.Data))
+			streams.appendTo(&output)
 			return mcp.NewToolResultText(output.String()), nil
-		case "stdout":
-			output.WriteString(event.Data)
-		case "stderr":
-			output.WriteString(fmt.Sprintf("⚠️ %s", event.Data))
 		case "done":
 			output.WriteString("\n✅ Tool execution completed\n")
 		default:
 			output.WriteString(fmt.Sprintf("📝 %s: %s\n", event.Type, event.Data))
 		}
 	}
+	streams.appendTo(&output)
 
 	return mcp.NewToolResultText(output.String()), nil
 }
@@ -730,179 +647,30 @@ func (s *Server) createOnDemandToolHandler(ctx context.Context, request mcp.Call
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute on-demand tool: %v", err)), nil
 	}
 
+	streams := toolStreams{progress: newProgressReporter(ctx, request)}
 	for event := range eventChan {
+		if streams.add(event, &output) {
+			continue
+		}
 		switch event.Type {
 		case "error":
 			output.WriteString(fmt.Sprintf("❌ Error: %s\n", event.Data))
+			streams.appendTo(&output)
 			return mcp.NewToolResultText(output.String()), nil
-		case "stdout":
-			output.WriteString(event.Data)
-		case "stderr":
-			output.WriteString(fmt.Sprintf("⚠️ %s", event.Data))
 		case "done":
 			output.WriteString("\n✅ On-demand tool execution completed\n")
 		default:
 			output.WriteString(fmt.Sprintf("📝 %s: %s\n", event.Type, event.Data))
 		}
 	}
+	streams.appendTo(&output)
 
 	return mcp.NewToolResultText(output.String()), nil
 }
 
 // searchToolsHandler handles searching for tools across all sources
 func (s *Server) searchToolsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	args := request.Params.Arguments
-	
-	// Get required query parameter
-	query, ok := args["query"].(string)
-	if !ok || query == "" {
-		return mcp.NewToolResultError("query parameter is required"), nil
-	}
-	
-	// Parse pagination parameters
-	page := 1
-	if pageStr, ok := args["page"].(string); ok {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	} else if pageFloat, ok := args["page"].(float64); ok && pageFloat > 0 {
-		page = int(pageFloat)
-	}
-	
-	// Set default page size with fallback
-	pageSize := 20 // Default fallback
-	maxResponseSize := 51200 // 50KB default
-	maxToolsInResponse := 50 // Default max tools
-	
-	// Use serverConfig values if available
-	if s.serverConfig != nil {
-		if s.serverConfig.DefaultPageSize > 0 {
-			pageSize = s.serverConfig.DefaultPageSize
-		}
-		if s.serverConfig.MaxResponseSize > 0 {
-			maxResponseSize = s.serverConfig.MaxResponseSize
-		}
-		if s.serverConfig.MaxToolsInResponse > 0 {
-			maxToolsInResponse = s.serverConfig.MaxToolsInResponse
-		}
-	}
-	
-	if pageSizeStr, ok := args["page_size"].(string); ok {
-		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
-			pageSize = ps
-		}
-	} else if pageSizeFloat, ok := args["page_size"].(float64); ok && pageSizeFloat > 0 {
-		pageSize = int(pageSizeFloat)
-	}
-	
-	// Limit page size to maximum configured
-	if pageSize > maxToolsInResponse {
-		pageSize = maxToolsInResponse
-	}
-	
-	// Get optional filter parameters
-	sourceUUID, _ := args["source_uuid"].(string)
-	toolType, _ := args["tool_type"].(string)
-	longRunningOnly, _ := args["long_running_only"].(bool)
-
-	// Get all sources
-	sources, err := s.client.ListSources(ctx)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list sources: %v", err)), nil
-	}
-
-	// Filter sources if sourceUUID is provided
-	if sourceUUID != "" {
-		var filteredSources []kubiya.Source
-		for _, source := range sources {
-			if source.UUID == sourceUUID {
-				filteredSources = append(filteredSources, source)
-				break
-			}
-		}
-		sources = filteredSources
-	}
-
-	// Search through all tools
-	var allMatches []ToolMetadata
-	queryLower := strings.ToLower(query)
-
-	for _, source := range sources {
-		// Get source metadata to access tools
-		metadata, err := s.client.GetSourceMetadata(ctx, source.UUID)
-		if err != nil {
-			continue // Skip sources that can't be accessed
-		}
-
-		// Search through regular tools
-		for _, tool := range metadata.Tools {
-			if matchesTool(tool, queryLower, toolType, longRunningOnly) {
-				toolMeta := ToolMetadata{
-					Name:        tool.Name,
-					Description: tool.Description,
-					SourceUUID:  source.UUID,
-					SourceName:  source.Name,
-					Type:        tool.Type,
-					ArgCount:    len(tool.Args),
-					LongRunning: tool.LongRunning,
-				}
-				allMatches = append(allMatches, toolMeta)
-			}
-		}
-
-		// Search through inline tools
-		for _, tool := range metadata.InlineTools {
-			if matchesTool(tool, queryLower, toolType, longRunningOnly) {
-				toolMeta := ToolMetadata{
-					Name:        tool.Name,
-					Description: tool.Description,
-					SourceUUID:  source.UUID,
-					SourceName:  source.Name,
-					Type:        tool.Type,
-					ArgCount:    len(tool.Args),
-					LongRunning: tool.LongRunning,
-				}
-				allMatches = append(allMatches, toolMeta)
-			}
-		}
-	}
-
-	// Convert to interface{} slice for pagination
-	var items []interface{}
-	for _, match := range allMatches {
-		items = append(items, match)
-	}
-
-	// Apply pagination
-	paginatedItems, currentPage, totalPages, hasMore := paginateItems(items, page, pageSize)
-
-	// Create response
-	response := map[string]interface{}{
-		"tools": paginatedItems,
-		"pagination": map[string]interface{}{
-			"page":        currentPage,
-			"page_size":   pageSize,
-			"total_pages": totalPages,
-			"total_items": len(allMatches),
-			"has_more":    hasMore,
-		},
-		"query": query,
-		"filters": map[string]interface{}{
-			"source_uuid":       sourceUUID,
-			"tool_type":         toolType,
-			"long_running_only": longRunningOnly,
-		},
-	}
-
-	// Marshal and apply size limits
-	data, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal search results: %v", err)), nil
-	}
-
-	// Apply response size limit
-	limitedData := s.limitResponseSize(data, maxResponseSize)
-	return mcp.NewToolResultText(string(limitedData)), nil
+	return searchToolsPage(ctx, s.client, request.Params.Arguments, s.pageLimits())
 }
 
 // matchesTool checks if a tool matches the search criteria
@@ -1092,6 +860,10 @@ func (s *Server) executeWorkflowHandler(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute workflow: %v", err)), nil
 	}
 
+	// Stream the output below to the client as it is written
+	progress := newProgressReporter(ctx, request)
+	sent := output.Len()
+
 	var executionID string
 	for event := range eventChan {
 		switch event.Type {
@@ -1157,28 +929,21 @@ func (s *Server) executeWorkflowHandler(ctx context.Context, request mcp.CallToo
 			// Error events
 			if event.Error != "" {
 				output.WriteString(fmt.Sprintf("💀 Error: %s\n", event.Error))
+				progress.write(output.String()[sent:])
+				progress.flush()
 				return mcp.NewToolResultError(output.String()), nil
 			}
 		}
+		progress.write(output.String()[sent:])
+		sent = output.Len()
 	}
+	progress.flush()
 
 	return mcp.NewToolResultText(output.String()), nil
 }
 
 // Helper functions for content size limiting and pagination
 
-// limitResponseSize ensures the response doesn't exceed the configured maximum size
-func (s *Server) limitResponseSize(data []byte, maxSize int) []byte {
-	if len(data) <= maxSize {
-		return data
-	}
-	
-	// Truncate response and add indicator
-	truncated := data[:maxSize-100] // Reserve space for truncation message
-	truncated = append(truncated, []byte("\n\n... Response truncated due to size limit ...")...)
-	return truncated
-}
-
 // paginateItems applies pagination to a slice of items
 func paginateItems(items []interface{}, page, pageSize int) ([]interface{}, int, int, bool) {
 	if pageSize <= 0 {
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressInterval is how often partial output is sent to clients; output
+// received in between is sent together
+const progressInterval = 250 * time.Millisecond
+
+// progressReporter streams the partial output of a tool call to the client
+// as progress notifications while the full output is buffered for the
+// result. Clients that did not send a progress token only get the result.
+// A nil progressReporter does nothing.
+type progressReporter struct {
+	notify   func(params map[string]any) error
+	token    mcp.ProgressToken
+	interval time.Duration
+
+	mu       sync.Mutex
+	progress float64
+	pending  strings.Builder
+	last     time.Time
+	timer    *time.Timer
+}
+
+// newProgressReporter returns a reporter for request, or nil when the client
+// did not ask for progress
+func newProgressReporter(ctx context.Context, request mcp.CallToolRequest) *progressReporter {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+	return &progressReporter{
+		notify: func(params map[string]any) error {
+			return srv.SendNotificationToClient(ctx, "notifications/progress", params)
+		},
+		token:    request.Params.Meta.ProgressToken,
+		interval: progressInterval,
+	}
+}
+
+// write queues partial output. It is sent at once when the interval has
+// passed since the last notification, and at the end of it otherwise.
+func (p *progressReporter) write(text string) {
+	if p == nil || text == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending.WriteString(text)
+	if wait := p.interval - time.Since(p.last); wait > 0 {
+		if p.timer == nil {
+			p.timer = time.AfterFunc(wait, p.flush)
+		}
+		return
+	}
+	p.send()
+}
+
+// flush sends the queued output
+func (p *progressReporter) flush() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.send()
+}
+
+func (p *progressReporter) send() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if p.pending.Len() == 0 {
+		return
+	}
+	p.progress++
+	// A client that went away or a full notification channel only loses
+	// partial output, the result still carries all of it
+	_ = p.notify(map[string]any{
+		"progressToken": p.token,
+		"progress":      p.progress,
+		"message":       p.pending.String(),
+	})
+	p.pending.Reset()
+	p.last = time.Now()
+}
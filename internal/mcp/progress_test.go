@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func newTestProgressReporter(interval time.Duration) (*progressReporter, *[]map[string]any) {
+	var sent []map[string]any
+	return &progressReporter{
+		notify: func(params map[string]any) error {
+			sent = append(sent, params)
+			return nil
+		},
+		token:    "tok-1",
+		interval: interval,
+	}, &sent
+}
+
+func TestNewProgressReporterWithoutToken(t *testing.T) {
+	var request mcp.CallToolRequest
+	if p := newProgressReporter(context.Background(), request); p != nil {
+		t.Error("expected no reporter without a progress token")
+	}
+	request.Params.Meta = &mcp.Meta{ProgressToken: "tok-1"}
+	if p := newProgressReporter(context.Background(), request); p != nil {
+		t.Error("expected no reporter outside of a server")
+	}
+
+	// A nil reporter does nothing
+	var p *progressReporter
+	p.write("output")
+	p.flush()
+}
+
+func TestProgressReporterBatchesOutput(t *testing.T) {
+	p, sent := newTestProgressReporter(time.Hour)
+	p.write("first\n")
+	p.write("second\n")
+	p.write("third\n")
+	if len(*sent) != 1 || (*sent)[0]["message"] != "first\n" {
+		t.Fatalf("expected the first write to be sent at once, got %v", *sent)
+	}
+
+	p.flush()
+	if len(*sent) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(*sent))
+	}
+	last := (*sent)[1]
+	if last["message"] != "second\nthird\n" {
+		t.Errorf("expected the queued writes together, got %q", last["message"])
+	}
+	if last["progressToken"] != "tok-1" || last["progress"] != float64(2) {
+		t.Errorf("unexpected notification %v", last)
+	}
+
+	// Nothing queued, nothing sent
+	p.flush()
+	if len(*sent) != 2 {
+		t.Errorf("expected no notification without output, got %d", len(*sent))
+	}
+}
+
+func TestProgressReporterSendsQueuedOutputLater(t *testing.T) {
+	done := make(chan string, 2)
+	p := &progressReporter{
+		notify: func(params map[string]any) error {
+			done <- params["message"].(string)
+			return nil
+		},
+		interval: 20 * time.Millisecond,
+	}
+	p.write("a")
+	p.write("b")
+	if got := <-done; got != "a" {
+		t.Fatalf("expected a, got %q", got)
+	}
+	select {
+	case got := <-done:
+		if got != "b" {
+			t.Errorf("expected b, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued output was not sent")
+	}
+}
+
+func TestToolStreamsReportProgress(t *testing.T) {
+	p, sent := newTestProgressReporter(0)
+	streams := toolStreams{progress: p}
+	var output strings.Builder
+
+	streams.add(kubiya.WorkflowSSEEvent{Type: "data", Data: `{"type":"tool-output","content":"building\n"}`}, &output)
+	streams.add(kubiya.WorkflowSSEEvent{Type: "data", Data: `{"type":"tool-output","stream":"stderr","content":"warning\n"}`}, &output)
+	streams.appendTo(&output)
+
+	if len(*sent) != 2 || (*sent)[0]["message"] != "building\n" || (*sent)[1]["message"] != "warning\n" {
+		t.Errorf("expected both streams to be reported, got %v", *sent)
+	}
+	if !strings.Contains(output.String(), "building\n") || !strings.Contains(output.String(), "--- stderr ---\nwarning\n") {
+		t.Errorf("expected the buffered output in the result, got %q", output.String())
+	}
+}
@@ -1,16 +1,22 @@
 package mcp
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/kubiyabot/cli/internal/composer"
 	"github.com/kubiyabot/cli/internal/config"
 	"github.com/kubiyabot/cli/internal/kubiya"
+	klog "github.com/kubiyabot/cli/internal/log"
 )
 
+// mcpLog logs the MCP servers, away from stdout which carries the protocol
+var mcpLog = klog.For(klog.MCP)
+
 // Server wraps the Kubiya client and provides MCP tools
 type Server struct {
 	client         *kubiya.Client
@@ -62,8 +68,23 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to add resources: %w", err)
 	}
 
+	// Expose the tools of an agent, kept in sync with its sources
+	if s.serverConfig.ExposeAgentTools != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		exposer := newAgentToolExposer(s.client, s.serverConfig.ExposeAgentTools, mcpServer,
+			time.Duration(s.serverConfig.AgentToolsRefresh)*time.Second, log.New(mcpLog.Writer(klog.LevelInfo), "", 0),
+			func(wt WhitelistedTool) server.ServerTool {
+				return NewWhitelistedToolHandler(s.client, wt, whitelistedKubiyaTool(wt)).ServerTool()
+			})
+		if err := exposer.refresh(ctx); err != nil {
+			return fmt.Errorf("failed to expose agent tools: %w", err)
+		}
+		go exposer.run(ctx)
+	}
+
 	// Start server
-	log.Println("Starting Kubiya MCP Server...")
+	mcpLog.Info("starting Kubiya MCP server")
 	return server.ServeStdio(mcpServer)
 }
 
@@ -71,12 +92,12 @@ func (s *Server) Start() error {
 func (s *Server) addTools() error {
 	// If whitelist is configured, only add whitelisted tools
 	if len(s.serverConfig.WhitelistedTools) > 0 {
-		log.Printf("Using whitelist mode - only adding %d whitelisted tools", len(s.serverConfig.WhitelistedTools))
+		mcpLog.Info("using whitelist mode", "tools", len(s.serverConfig.WhitelistedTools))
 		return s.addWhitelistedToolsOnly()
 	}
 
 	// Default behavior: add all tools based on configuration
-	log.Println("Using default mode - adding all configured tools")
+	mcpLog.Info("using default mode, adding all configured tools")
 
 	// Core tool execution
 	if err := s.addExecuteTool(); err != nil {
@@ -124,30 +145,10 @@ func (s *Server) addResources() error {
 // addWhitelistedToolsOnly registers only the whitelisted tools as individual MCP tools
 func (s *Server) addWhitelistedToolsOnly() error {
 	for _, tool := range s.serverConfig.WhitelistedTools {
-		log.Printf("Adding whitelisted tool: %s (%s)", tool.Name, tool.Description)
-
-		// Convert WhitelistedTool to Kubiya Tool format
-		kubiyaTool := kubiya.Tool{
-			Name:        tool.Name,
-			Source:      kubiya.ToolSource{ID: tool.Source.ID, URL: tool.Source.URL},
-			Description: tool.Description,
-			Args:        convertToolArgs(tool.Args),
-			Env:         tool.Env,
-			Content:     tool.Content,
-			FileName:    tool.FileName,
-			Secrets:     tool.Secrets,
-			IconURL:     tool.IconURL,
-			Type:        tool.Type,
-			Alias:       tool.Alias,
-			WithFiles:   tool.WithFiles,
-			WithVolumes: tool.WithVolumes,
-			LongRunning: tool.LongRunning,
-			Metadata:    tool.Metadata,
-			Mermaid:     tool.Mermaid,
-		}
+		mcpLog.Info("adding whitelisted tool", "name", tool.Name, "description", tool.Description)
 
 		// Create the tool handler that executes the whitelisted tool
-		handler := NewWhitelistedToolHandler(s.client, tool, kubiyaTool)
+		handler := NewWhitelistedToolHandler(s.client, tool, whitelistedKubiyaTool(tool))
 
 		// Register the tool as an individual MCP tool
 		if err := handler.Register(s.server); err != nil {
@@ -158,6 +159,28 @@ func (s *Server) addWhitelistedToolsOnly() error {
 	return nil
 }
 
+// whitelistedKubiyaTool converts a WhitelistedTool to Kubiya Tool format
+func whitelistedKubiyaTool(tool WhitelistedTool) kubiya.Tool {
+	return kubiya.Tool{
+		Name:        tool.Name,
+		Source:      kubiya.ToolSource{ID: tool.Source.ID, URL: tool.Source.URL},
+		Description: tool.Description,
+		Args:        convertToolArgs(tool.Args),
+		Env:         tool.Env,
+		Content:     tool.Content,
+		FileName:    tool.FileName,
+		Secrets:     tool.Secrets,
+		IconURL:     tool.IconURL,
+		Type:        tool.Type,
+		Alias:       tool.Alias,
+		WithFiles:   tool.WithFiles,
+		WithVolumes: tool.WithVolumes,
+		LongRunning: tool.LongRunning,
+		Metadata:    tool.Metadata,
+		Mermaid:     tool.Mermaid,
+	}
+}
+
 // convertToolArgs converts MCP ToolArg to Kubiya ToolArg
 func convertToolArgs(mcpArgs []ToolArg) []kubiya.ToolArg {
 	args := make([]kubiya.ToolArg, len(mcpArgs))
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/getsentry/sentry-go"
+	"github.com/kubiyabot/cli/internal/httpclient"
+	"github.com/kubiyabot/cli/internal/integrations"
 	"github.com/kubiyabot/cli/internal/kubiya"
+	klog "github.com/kubiyabot/cli/internal/log"
 	"github.com/kubiyabot/cli/internal/mcp/filter"
 	"github.com/kubiyabot/cli/internal/mcp/hooks"
 	"github.com/kubiyabot/cli/internal/mcp/middleware"
@@ -20,6 +23,7 @@ import (
 	sentryutil "github.com/kubiyabot/cli/internal/sentry"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/spf13/afero"
 )
 
 // ProductionServer is a production-ready MCP server with all features
@@ -34,10 +38,21 @@ type ProductionServer struct {
 	config          *Config
 }
 
+// longRunningToolTimeouts are the default timeouts of tools known to run
+// longer than the default, overridden by tool_timeouts of the config
+var longRunningToolTimeouts = map[string]time.Duration{
+	"execute_tool":             30 * time.Minute, // Tool execution can be very long
+	"execute_workflow":         45 * time.Minute, // Workflows can be complex
+	"create_on_demand_tool":    25 * time.Minute, // Dynamic tool creation + execution
+	"execute_whitelisted_tool": 30 * time.Minute, // Whitelisted tools may be complex
+	"workflow_dsl_wasm":        15 * time.Minute, // WASM execution can be slow
+	"chat_with_agent":          10 * time.Minute, // Agent conversations can be lengthy
+}
+
 // NewProductionServer creates a new production MCP server
 func NewProductionServer(kubiyaClient *kubiya.Client, config *Config) (*ProductionServer, error) {
-	// Initialize logger
-	logger := log.New(os.Stdout, "[MCP] ", log.LstdFlags|log.Lshortfile)
+	// Log to the mcp subsystem, stdout carries the protocol
+	logger := log.New(mcpLog.Writer(klog.LevelInfo), "", 0)
 
 	// Initialize session manager
 	sessionTimeout := 30 * time.Minute
@@ -91,16 +106,7 @@ func NewProductionServer(kubiyaClient *kubiya.Client, config *Config) (*Producti
 	timeoutMW := middleware.NewTimeoutMiddleware(defaultTimeout)
 
 	// Set extended timeouts for known long-running tools
-	longRunningTools := map[string]time.Duration{
-		"execute_tool":             30 * time.Minute, // Tool execution can be very long
-		"execute_workflow":         45 * time.Minute, // Workflows can be complex
-		"create_on_demand_tool":    25 * time.Minute, // Dynamic tool creation + execution
-		"execute_whitelisted_tool": 30 * time.Minute, // Whitelisted tools may be complex
-		"workflow_dsl_wasm":        15 * time.Minute, // WASM execution can be slow
-		"chat_with_agent":          10 * time.Minute, // Agent conversations can be lengthy
-	}
-
-	for tool, timeout := range longRunningTools {
+	for tool, timeout := range longRunningToolTimeouts {
 		timeoutMW.SetToolTimeout(tool, timeout)
 	}
 
@@ -130,6 +136,15 @@ func NewProductionServer(kubiyaClient *kubiya.Client, config *Config) (*Producti
 	permMW := NewPermissionMiddleware(config.ToolPermissions)
 	middlewares = append(middlewares, permMW.Apply)
 
+	// Context enrichment (innermost, so it sees the raw tool result)
+	if len(config.EnrichmentRules) > 0 {
+		enrichMW, err := middleware.NewEnrichmentMiddleware(config.EnrichmentRules, knowledgeSearch(kubiyaClient), logger)
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, enrichMW.Apply)
+	}
+
 	// Chain all middleware
 	chainedMiddleware := middleware.Chain(middlewares...)
 
@@ -260,7 +275,7 @@ func (ps *ProductionServer) handleExecuteTool(ctx context.Context, req mcp.CallT
 		ps.logger.Printf("Loading tool from URL: %s", toolURL)
 
 		// Fetch the tool definition from URL
-		resp, err := http.Get(toolURL)
+		resp, err := httpclient.Get(toolURL)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to fetch tool from URL: %v", err)), nil
 		}
@@ -354,139 +369,10 @@ func (ps *ProductionServer) handleExecuteTool(ctx context.Context, req mcp.CallT
 	}
 
 	// Apply integrations if specified
-	if integrations, ok := req.Params.Arguments["integrations"].([]interface{}); ok {
-		ps.logger.Printf("Applying %d integrations to tool", len(integrations))
-
-		// Import the cli package for integration support
-		// We need to create a lightweight integration manager for the MCP server
-		for _, integration := range integrations {
-			integrationName, ok := integration.(string)
-			if !ok {
-				continue
-			}
-
-			ps.logger.Printf("Applying integration: %s", integrationName)
-
-			// Apply integration based on name
-			// For now, we'll apply the most common integrations inline
-			// In a production setup, this would use the same integration system
-			switch integrationName {
-			case "kubernetes/incluster":
-				// Apply Kubernetes in-cluster integration
-				if toolDef["image"] == nil || toolDef["image"] == "" {
-					toolDef["image"] = "bitnami/kubectl:latest"
-				}
-
-				// Add file mappings
-				withFiles, _ := toolDef["with_files"].([]interface{})
-				withFiles = append(withFiles,
-					map[string]interface{}{
-						"source":      "/var/run/secrets/kubernetes.io/serviceaccount/token",
-						"destination": "/var/run/secrets/kubernetes.io/serviceaccount/token",
-					},
-					map[string]interface{}{
-						"source":      "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
-						"destination": "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
-					},
-				)
-				toolDef["with_files"] = withFiles
-
-				// Add environment variables
-				env, _ := toolDef["env"].([]interface{})
-				env = append(env,
-					"KUBERNETES_SERVICE_HOST=kubernetes.default.svc",
-					"KUBERNETES_SERVICE_PORT=443",
-				)
-				toolDef["env"] = env
-
-				// Wrap content with setup script
-				content, _ := toolDef["content"].(string)
-				toolDef["content"] = `#!/bin/bash
-set -e
-
-# Setup Kubernetes in-cluster authentication
-if [ -f /var/run/secrets/kubernetes.io/serviceaccount/token ]; then
-    export KUBE_TOKEN=$(cat /var/run/secrets/kubernetes.io/serviceaccount/token)
-    kubectl config set-cluster in-cluster \
-        --server=https://kubernetes.default.svc \
-        --certificate-authority=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt > /dev/null 2>&1
-    kubectl config set-credentials in-cluster --token=$KUBE_TOKEN > /dev/null 2>&1
-    kubectl config set-context in-cluster --cluster=in-cluster --user=in-cluster > /dev/null 2>&1
-    kubectl config use-context in-cluster > /dev/null 2>&1
-    echo "✓ Kubernetes in-cluster authentication configured"
-fi
-
-# User script
-` + content
-
-			case "aws/cli":
-				// Apply AWS CLI integration
-				if toolDef["image"] == nil || toolDef["image"] == "" {
-					toolDef["image"] = "amazon/aws-cli:latest"
-				}
-
-				// Add file mappings
-				withFiles, _ := toolDef["with_files"].([]interface{})
-				withFiles = append(withFiles,
-					map[string]interface{}{
-						"source":      "~/.aws/credentials",
-						"destination": "/root/.aws/credentials",
-					},
-					map[string]interface{}{
-						"source":      "~/.aws/config",
-						"destination": "/root/.aws/config",
-					},
-				)
-				toolDef["with_files"] = withFiles
-
-				// Add environment variables
-				env, _ := toolDef["env"].([]interface{})
-				env = append(env,
-					"AWS_PROFILE=${AWS_PROFILE:-default}",
-					"AWS_DEFAULT_REGION=${AWS_DEFAULT_REGION:-us-east-1}",
-				)
-				toolDef["env"] = env
-
-			case "database/postgres":
-				// Apply PostgreSQL integration
-				if toolDef["image"] == nil || toolDef["image"] == "" {
-					toolDef["image"] = "postgres:15-alpine"
-				}
-
-				// Add services
-				withServices, _ := toolDef["with_services"].([]interface{})
-				withServices = append(withServices, "postgres:15")
-				toolDef["with_services"] = withServices
-
-				// Add environment variables
-				env, _ := toolDef["env"].([]interface{})
-				env = append(env,
-					"PGHOST=${PGHOST:-postgres}",
-					"PGPORT=${PGPORT:-5432}",
-					"PGDATABASE=${PGDATABASE:-postgres}",
-					"PGUSER=${PGUSER:-postgres}",
-					"PGPASSWORD=${PGPASSWORD:-postgres}",
-				)
-				toolDef["env"] = env
-
-				// Wrap content with wait script
-				content, _ := toolDef["content"].(string)
-				toolDef["content"] = `#!/bin/bash
-set -e
-
-# Wait for PostgreSQL to be ready
-for i in {1..30}; do
-    if pg_isready -h $PGHOST -p $PGPORT -U $PGUSER; then
-        echo "✓ PostgreSQL is ready"
-        break
-    fi
-    echo "Waiting for PostgreSQL... ($i/30)"
-    sleep 1
-done
-
-# User script
-` + content
-			}
+	if names, ok := req.Params.Arguments["integrations"].([]interface{}); ok {
+		ps.logger.Printf("Applying %d integrations to tool", len(names))
+		if err := ps.applyIntegrations(toolDef, names); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 	}
 	var argVals map[string]any
@@ -534,8 +420,12 @@ done
 
 	// Collect output
 	var output strings.Builder
+	streams := toolStreams{progress: newProgressReporter(ctx, req)}
 	var hasError bool
 	for event := range events {
+		if streams.add(event, &output) {
+			continue
+		}
 		switch event.Type {
 		case "data":
 			// Try to parse as JSON
@@ -544,10 +434,6 @@ done
 				// Handle different event types
 				if eventType, ok := data["type"].(string); ok {
 					switch eventType {
-					case "tool-output":
-						if content, ok := data["content"].(string); ok {
-							output.WriteString(content)
-						}
 					case "log":
 						// Optional: Include logs in output
 						if content, ok := data["content"].(string); ok {
@@ -570,12 +456,14 @@ done
 			}
 		case "error":
 			hasError = true
+			streams.progress.flush()
 			return mcp.NewToolResultError(fmt.Sprintf("Tool execution error: %s", event.Data)), nil
 		}
 	}
 
+	streams.appendTo(&output)
 	if hasError {
-		return mcp.NewToolResultError("Tool execution failed"), nil
+		return mcp.NewToolResultError("Tool execution failed\n" + output.String()), nil
 	}
 
 	return mcp.NewToolResultText(output.String()), nil
@@ -611,278 +499,16 @@ func (ps *ProductionServer) handleListRunners(ctx context.Context, req mcp.CallT
 }
 
 func (ps *ProductionServer) handleListSources(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	args := req.Params.Arguments
-
-	// Parse pagination parameters
-	page := 1
-	if pageStr, ok := args["page"].(string); ok {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	} else if pageFloat, ok := args["page"].(float64); ok && pageFloat > 0 {
-		page = int(pageFloat)
-	}
-
-	// Set default page size with fallback
-	pageSize := ps.config.DefaultPageSize
-	if pageSize <= 0 {
-		pageSize = 20 // Default fallback
-	}
-	maxResponseSize := ps.config.MaxResponseSize
-	if maxResponseSize <= 0 {
-		maxResponseSize = 51200 // 50KB default
-	}
-	maxToolsInResponse := ps.config.MaxToolsInResponse
-	if maxToolsInResponse <= 0 {
-		maxToolsInResponse = 50 // Default max tools
-	}
-
-	if pageSizeStr, ok := args["page_size"].(string); ok {
-		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
-			pageSize = ps
-		}
-	} else if pageSizeFloat, ok := args["page_size"].(float64); ok && pageSizeFloat > 0 {
-		pageSize = int(pageSizeFloat)
-	}
-
-	// Limit page size to maximum configured
-	if pageSize > maxToolsInResponse {
-		pageSize = maxToolsInResponse
-	}
-
-	// Get sources from API
-	sources, err := ps.kubiyaClient.ListSources(ctx)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list sources: %v", err)), nil
-	}
-
-	// Convert to metadata-only format
-	var sourceMetadata []interface{}
-	for _, source := range sources {
-		// Get basic metadata for each source
-		metadata := map[string]interface{}{
-			"uuid":        source.UUID,
-			"name":        source.Name,
-			"description": source.Description,
-			"url":         source.URL,
-			"tool_count":  len(source.Tools) + len(source.InlineTools),
-			"created_at":  source.CreatedAt,
-			"updated_at":  source.UpdatedAt,
-			"status":      "active", // Default status
-		}
-
-		// Note: Git metadata would need to be fetched separately if needed
-
-		sourceMetadata = append(sourceMetadata, metadata)
-	}
-
-	// Apply pagination
-	paginatedItems, currentPage, totalPages, hasMore := paginateItems(sourceMetadata, page, pageSize)
-
-	// Create response with pagination info
-	response := map[string]interface{}{
-		"sources": paginatedItems,
-		"pagination": map[string]interface{}{
-			"page":        currentPage,
-			"page_size":   pageSize,
-			"total_pages": totalPages,
-			"total_items": len(sourceMetadata),
-			"has_more":    hasMore,
-		},
-	}
-
-	// Marshal and apply size limits
-	data, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal sources: %v", err)), nil
-	}
-
-	// Apply response size limit
-	if len(data) > maxResponseSize {
-		truncated := data[:maxResponseSize-100] // Reserve space for truncation message
-		truncated = append(truncated, []byte("\n\n... Response truncated due to size limit ...")...)
-		data = truncated
-	}
-
-	return mcp.NewToolResultText(string(data)), nil
+	return listSourcesPage(ctx, ps.kubiyaClient, req.Params.Arguments, ps.pageLimits())
 }
 
-func (ps *ProductionServer) handleSearchTools(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	args := req.Params.Arguments
-
-	// Get required query parameter
-	query, ok := args["query"].(string)
-	if !ok || query == "" {
-		return mcp.NewToolResultError("query parameter is required"), nil
-	}
-
-	// Parse pagination parameters
-	page := 1
-	if pageStr, ok := args["page"].(string); ok {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	} else if pageFloat, ok := args["page"].(float64); ok && pageFloat > 0 {
-		page = int(pageFloat)
-	}
-
-	// Set default page size with fallback
-	pageSize := ps.config.DefaultPageSize
-	if pageSize <= 0 {
-		pageSize = 20 // Default fallback
-	}
-	maxResponseSize := ps.config.MaxResponseSize
-	if maxResponseSize <= 0 {
-		maxResponseSize = 51200 // 50KB default
-	}
-	maxToolsInResponse := ps.config.MaxToolsInResponse
-	if maxToolsInResponse <= 0 {
-		maxToolsInResponse = 50 // Default max tools
-	}
-
-	if pageSizeStr, ok := args["page_size"].(string); ok {
-		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
-			pageSize = ps
-		}
-	} else if pageSizeFloat, ok := args["page_size"].(float64); ok && pageSizeFloat > 0 {
-		pageSize = int(pageSizeFloat)
-	}
-
-	// Limit page size to maximum configured
-	if pageSize > maxToolsInResponse {
-		pageSize = maxToolsInResponse
-	}
-
-	// Get optional filter parameters
-	sourceUUID, _ := args["source_uuid"].(string)
-	toolType, _ := args["tool_type"].(string)
-	longRunningOnly, _ := args["long_running_only"].(bool)
-
-	// Get all sources
-	sources, err := ps.kubiyaClient.ListSources(ctx)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list sources: %v", err)), nil
-	}
-
-	// Filter sources if sourceUUID is provided
-	if sourceUUID != "" {
-		var filteredSources []kubiya.Source
-		for _, source := range sources {
-			if source.UUID == sourceUUID {
-				filteredSources = append(filteredSources, source)
-				break
-			}
-		}
-		sources = filteredSources
-	}
-
-	// Search through all tools
-	var allMatches []map[string]interface{}
-	queryLower := strings.ToLower(query)
-
-	for _, source := range sources {
-		// Get source metadata to access tools
-		metadata, err := ps.kubiyaClient.GetSourceMetadata(ctx, source.UUID)
-		if err != nil {
-			continue // Skip sources that can't be accessed
-		}
-
-		// Search through regular tools
-		for _, tool := range metadata.Tools {
-			if matchesToolCriteria(tool, queryLower, toolType, longRunningOnly) {
-				toolMeta := map[string]interface{}{
-					"name":         tool.Name,
-					"description":  tool.Description,
-					"source_uuid":  source.UUID,
-					"source_name":  source.Name,
-					"type":         tool.Type,
-					"arg_count":    len(tool.Args),
-					"long_running": tool.LongRunning,
-				}
-				allMatches = append(allMatches, toolMeta)
-			}
-		}
-
-		// Search through inline tools
-		for _, tool := range metadata.InlineTools {
-			if matchesToolCriteria(tool, queryLower, toolType, longRunningOnly) {
-				toolMeta := map[string]interface{}{
-					"name":         tool.Name,
-					"description":  tool.Description,
-					"source_uuid":  source.UUID,
-					"source_name":  source.Name,
-					"type":         tool.Type,
-					"arg_count":    len(tool.Args),
-					"long_running": tool.LongRunning,
-				}
-				allMatches = append(allMatches, toolMeta)
-			}
-		}
-	}
-
-	// Convert to interface{} slice for pagination
-	var items []interface{}
-	for _, match := range allMatches {
-		items = append(items, match)
-	}
-
-	// Apply pagination
-	paginatedItems, currentPage, totalPages, hasMore := paginateItems(items, page, pageSize)
-
-	// Create response
-	response := map[string]interface{}{
-		"tools": paginatedItems,
-		"pagination": map[string]interface{}{
-			"page":        currentPage,
-			"page_size":   pageSize,
-			"total_pages": totalPages,
-			"total_items": len(allMatches),
-			"has_more":    hasMore,
-		},
-		"query": query,
-		"filters": map[string]interface{}{
-			"source_uuid":       sourceUUID,
-			"tool_type":         toolType,
-			"long_running_only": longRunningOnly,
-		},
-	}
-
-	// Marshal and apply size limits
-	data, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal search results: %v", err)), nil
-	}
-
-	// Apply response size limit
-	if len(data) > maxResponseSize {
-		truncated := data[:maxResponseSize-100] // Reserve space for truncation message
-		truncated = append(truncated, []byte("\n\n... Response truncated due to size limit ...")...)
-		data = truncated
-	}
-
-	return mcp.NewToolResultText(string(data)), nil
+// pageLimits returns the page limits of the server configuration
+func (ps *ProductionServer) pageLimits() pageLimits {
+	return newPageLimits(ps.config.DefaultPageSize, ps.config.MaxToolsInResponse, ps.config.MaxResponseSize)
 }
 
-// matchesToolCriteria checks if a tool matches the search criteria
-func matchesToolCriteria(tool kubiya.Tool, queryLower, toolType string, longRunningOnly bool) bool {
-	// Check query match (name or description)
-	toolNameLower := strings.ToLower(tool.Name)
-	toolDescLower := strings.ToLower(tool.Description)
-	if !strings.Contains(toolNameLower, queryLower) && !strings.Contains(toolDescLower, queryLower) {
-		return false
-	}
-
-	// Check tool type filter
-	if toolType != "" && strings.ToLower(tool.Type) != strings.ToLower(toolType) {
-		return false
-	}
-
-	// Check long running filter
-	if longRunningOnly && !tool.LongRunning {
-		return false
-	}
-
-	return true
+func (ps *ProductionServer) handleSearchTools(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return searchToolsPage(ctx, ps.kubiyaClient, req.Params.Arguments, ps.pageLimits())
 }
 
 func (ps *ProductionServer) handleExecuteToolFromSource(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -1015,13 +641,17 @@ func (ps *ProductionServer) handleExecuteToolFromSource(ctx context.Context, req
 	output.WriteString(fmt.Sprintf("📍 Runner: %s\n", runner))
 	output.WriteString("=" + strings.Repeat("=", 50) + "\n\n")
 
+	streams := toolStreams{progress: newProgressReporter(ctx, req)}
 	for event := range events {
 		// Check size limit before adding more content
-		if output.Len() > maxResponseSize-1000 {
+		if output.Len()+streams.stderr.Len() > maxResponseSize-1000 {
 			output.WriteString("\n... Output truncated due to size limit ...\n")
 			break
 		}
 
+		if streams.add(event, &output) {
+			continue
+		}
 		switch event.Type {
 		case "data":
 			// Try to parse as JSON for structured events
@@ -1029,10 +659,6 @@ func (ps *ProductionServer) handleExecuteToolFromSource(ctx context.Context, req
 			if err := json.Unmarshal([]byte(event.Data), &data); err == nil {
 				if eventType, ok := data["type"].(string); ok {
 					switch eventType {
-					case "tool-output":
-						if content, ok := data["content"].(string); ok {
-							output.WriteString(content)
-						}
 					case "log":
 						if content, ok := data["content"].(string); ok {
 							output.WriteString(fmt.Sprintf("📝 %s\n", content))
@@ -1051,17 +677,15 @@ func (ps *ProductionServer) handleExecuteToolFromSource(ctx context.Context, req
 			}
 		case "error":
 			output.WriteString(fmt.Sprintf("❌ Error: %s\n", event.Data))
+			streams.appendTo(&output)
 			return mcp.NewToolResultError(output.String()), nil
-		case "stdout":
-			output.WriteString(event.Data)
-		case "stderr":
-			output.WriteString(fmt.Sprintf("⚠️ %s", event.Data))
 		case "done":
 			output.WriteString("\n✅ Tool execution completed\n")
 		default:
 			output.WriteString(fmt.Sprintf("📝 %s: %s\n", event.Type, event.Data))
 		}
 	}
+	streams.appendTo(&output)
 
 	return mcp.NewToolResultText(output.String()), nil
 }
@@ -1110,6 +734,26 @@ func (ps *ProductionServer) handleSearchKB(ctx context.Context, req mcp.CallTool
 	return mcp.NewToolResultText(output.String()), nil
 }
 
+// applyIntegrations applies the named integration templates to toolDef,
+// with the same templates as 'kubiya tool exec --integration'
+func (ps *ProductionServer) applyIntegrations(toolDef map[string]interface{}, names []interface{}) error {
+	registry, err := integrations.Load(afero.NewOsFs())
+	if err != nil {
+		return fmt.Errorf("failed to load integrations: %w", err)
+	}
+	for _, name := range names {
+		integrationName, ok := name.(string)
+		if !ok {
+			continue
+		}
+		ps.logger.Printf("Applying integration: %s", integrationName)
+		if err := registry.Apply(toolDef, integrationName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // createWhitelistedToolHandler creates a handler for whitelisted tools
 func (ps *ProductionServer) createWhitelistedToolHandler(wt WhitelistedTool) middleware.ToolHandler {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -1120,124 +764,10 @@ func (ps *ProductionServer) createWhitelistedToolHandler(wt WhitelistedTool) mid
 		}
 
 		// Apply integrations from whitelisted tool config
-		if integrations, ok := wt.DefaultConfig["integrations"].([]interface{}); ok {
-			// Apply integrations using the same logic as handleExecuteTool
-			ps.logger.Printf("Applying %d integrations from whitelisted tool config", len(integrations))
-
-			for _, integration := range integrations {
-				integrationName, ok := integration.(string)
-				if !ok {
-					continue
-				}
-
-				ps.logger.Printf("Applying integration: %s", integrationName)
-
-				switch integrationName {
-				case "kubernetes/incluster":
-					if toolDef["image"] == nil || toolDef["image"] == "" {
-						toolDef["image"] = "bitnami/kubectl:latest"
-					}
-
-					withFiles, _ := toolDef["with_files"].([]interface{})
-					withFiles = append(withFiles,
-						map[string]interface{}{
-							"source":      "/var/run/secrets/kubernetes.io/serviceaccount/token",
-							"destination": "/var/run/secrets/kubernetes.io/serviceaccount/token",
-						},
-						map[string]interface{}{
-							"source":      "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
-							"destination": "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
-						},
-					)
-					toolDef["with_files"] = withFiles
-
-					env, _ := toolDef["env"].([]interface{})
-					env = append(env,
-						"KUBERNETES_SERVICE_HOST=kubernetes.default.svc",
-						"KUBERNETES_SERVICE_PORT=443",
-					)
-					toolDef["env"] = env
-
-					content, _ := toolDef["content"].(string)
-					toolDef["content"] = `#!/bin/bash
-set -e
-
-# Setup Kubernetes in-cluster authentication
-if [ -f /var/run/secrets/kubernetes.io/serviceaccount/token ]; then
-    export KUBE_TOKEN=$(cat /var/run/secrets/kubernetes.io/serviceaccount/token)
-    kubectl config set-cluster in-cluster \
-        --server=https://kubernetes.default.svc \
-        --certificate-authority=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt > /dev/null 2>&1
-    kubectl config set-credentials in-cluster --token=$KUBE_TOKEN > /dev/null 2>&1
-    kubectl config set-context in-cluster --cluster=in-cluster --user=in-cluster > /dev/null 2>&1
-    kubectl config use-context in-cluster > /dev/null 2>&1
-    echo "✓ Kubernetes in-cluster authentication configured"
-fi
-
-# User script
-` + content
-
-				case "aws/cli":
-					if toolDef["image"] == nil || toolDef["image"] == "" {
-						toolDef["image"] = "amazon/aws-cli:latest"
-					}
-
-					withFiles, _ := toolDef["with_files"].([]interface{})
-					withFiles = append(withFiles,
-						map[string]interface{}{
-							"source":      "~/.aws/credentials",
-							"destination": "/root/.aws/credentials",
-						},
-						map[string]interface{}{
-							"source":      "~/.aws/config",
-							"destination": "/root/.aws/config",
-						},
-					)
-					toolDef["with_files"] = withFiles
-
-					env, _ := toolDef["env"].([]interface{})
-					env = append(env,
-						"AWS_PROFILE=${AWS_PROFILE:-default}",
-						"AWS_DEFAULT_REGION=${AWS_DEFAULT_REGION:-us-east-1}",
-					)
-					toolDef["env"] = env
-
-				case "database/postgres":
-					if toolDef["image"] == nil || toolDef["image"] == "" {
-						toolDef["image"] = "postgres:15-alpine"
-					}
-
-					withServices, _ := toolDef["with_services"].([]interface{})
-					withServices = append(withServices, "postgres:15")
-					toolDef["with_services"] = withServices
-
-					env, _ := toolDef["env"].([]interface{})
-					env = append(env,
-						"PGHOST=${PGHOST:-postgres}",
-						"PGPORT=${PGPORT:-5432}",
-						"PGDATABASE=${PGDATABASE:-postgres}",
-						"PGUSER=${PGUSER:-postgres}",
-						"PGPASSWORD=${PGPASSWORD:-postgres}",
-					)
-					toolDef["env"] = env
-
-					content, _ := toolDef["content"].(string)
-					toolDef["content"] = `#!/bin/bash
-set -e
-
-# Wait for PostgreSQL to be ready
-for i in {1..30}; do
-    if pg_isready -h $PGHOST -p $PGPORT -U $PGUSER; then
-        echo "✓ PostgreSQL is ready"
-        break
-    fi
-    echo "Waiting for PostgreSQL... ($i/30)"
-    sleep 1
-done
-
-# User script
-` + content
-				}
+		if names, ok := wt.DefaultConfig["integrations"].([]interface{}); ok {
+			ps.logger.Printf("Applying %d integrations from whitelisted tool config", len(names))
+			if err := ps.applyIntegrations(toolDef, names); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 		}
 
@@ -1269,18 +799,22 @@ done
 
 		// Collect output
 		var output strings.Builder
+		progress := newProgressReporter(ctx, req)
 		for event := range events {
 			if event.Type == "data" {
 				var data map[string]interface{}
 				if err := json.Unmarshal([]byte(event.Data), &data); err == nil {
 					if outputStr, ok := data["output"].(string); ok {
 						output.WriteString(outputStr)
+						progress.write(outputStr)
 					}
 				}
 			} else if event.Type == "error" {
+				progress.flush()
 				return mcp.NewToolResultError(fmt.Sprintf("Execution error: %s", event.Data)), nil
 			}
 		}
+		progress.flush()
 
 		return mcp.NewToolResultText(output.String()), nil
 	}
@@ -1972,7 +1506,7 @@ func (ps *ProductionServer) handleWorkflowExamplesPrompt(ctx context.Context, re
 		prompt.WriteString("**Step 1: Tool Discovery and Dependency Analysis**\n")
 		prompt.WriteString("```python\n")
 		prompt.WriteString("# Use MCP tools to discover available capabilities\n")
-		prompt.WriteString("tools_result = search_tools(query='kubectl', page=1, page_size=10)\n")
+		prompt.WriteString("tools_result = search_tools(query='kubectl', page_size=10)\n")
 		prompt.WriteString("# Result: Found kubectl tools in source uuid 'abc-123-def'\n")
 		prompt.WriteString("\n")
 		prompt.WriteString("# CRITICAL: Examine tool dependencies from search results\n")
@@ -2295,13 +1829,13 @@ func (ps *ProductionServer) getAllTools() []mcp.Tool {
 		),
 		mcp.NewTool("list_sources",
 			mcp.WithDescription("List all available sources with metadata and pagination"),
-			mcp.WithNumber("page", mcp.Description("Page number (default: 1)")),
+			mcp.WithString("cursor", mcp.Description("Cursor of the page to get, from pagination.next_cursor of the previous page")),
 			mcp.WithNumber("page_size", mcp.Description("Items per page (default: 20, max: 50)")),
 		),
 		mcp.NewTool("search_tools",
 			mcp.WithDescription("Search for tools across all sources with pagination and filtering"),
 			mcp.WithString("query", mcp.Required(), mcp.Description("Search query for tool names and descriptions")),
-			mcp.WithNumber("page", mcp.Description("Page number (default: 1)")),
+			mcp.WithString("cursor", mcp.Description("Cursor of the page to get, from pagination.next_cursor of the previous page")),
 			mcp.WithNumber("page_size", mcp.Description("Items per page (default: 20, max: 50)")),
 			mcp.WithString("source_uuid", mcp.Description("Filter by specific source UUID (optional)")),
 			mcp.WithString("tool_type", mcp.Description("Filter by tool type (docker, python, bash, etc.)")),
@@ -2411,6 +1945,18 @@ func (ps *ProductionServer) Start(ctx context.Context) error {
 	// Call start hook
 	ps.hooks.OnServerStart(ctx)
 
+	// Expose the tools of an agent, kept in sync with its sources
+	if ps.config.ExposeAgentTools != "" {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		exposer := newAgentToolExposer(ps.kubiyaClient, ps.config.ExposeAgentTools, ps.mcpServer,
+			time.Duration(ps.config.AgentToolsRefresh)*time.Second, ps.logger, ps.agentServerTool)
+		if err := exposer.refresh(ctx); err != nil {
+			return fmt.Errorf("failed to expose agent tools: %w", err)
+		}
+		go exposer.run(ctx)
+	}
+
 	// Start the server
 	ps.logger.Printf("Starting MCP server %s v%s", ps.config.ServerName, ps.config.ServerVersion)
 
@@ -2418,6 +1964,24 @@ func (ps *ProductionServer) Start(ctx context.Context) error {
 	return server.ServeStdio(ps.mcpServer)
 }
 
+// agentServerTool wraps the handler of an exposed agent tool with the
+// middleware of the other tools
+func (ps *ProductionServer) agentServerTool(wt WhitelistedTool) server.ServerTool {
+	st := NewWhitelistedToolHandler(ps.kubiyaClient, wt, whitelistedKubiyaTool(wt)).ServerTool()
+	handler := ps.middlewareChain(middleware.ToolHandler(st.Handler))
+	st.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID := ps.getSessionIDFromContext(ctx)
+		if sess, exists := ps.sessionManager.GetSession(sessionID); exists {
+			ctx = session.ContextWithSession(ctx, sess)
+		}
+		start := time.Now()
+		result, err := handler(ctx, req)
+		ps.hooks.OnToolCall(ctx, sessionID, req.Params.Name, time.Since(start), err)
+		return result, err
+	}
+	return st
+}
+
 // Shutdown gracefully shuts down the server
 func (ps *ProductionServer) Shutdown(ctx context.Context) error {
 	ps.logger.Println("Shutting down MCP server...")
@@ -2431,6 +1995,25 @@ func (ps *ProductionServer) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// knowledgeSearch adapts the knowledge search API for enrichment rules
+func knowledgeSearch(client *kubiya.Client) middleware.KnowledgeSearchFunc {
+	return func(ctx context.Context, query string, limit int) ([]string, error) {
+		items, err := client.SearchKnowledge(ctx, url.QueryEscape(query), limit)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, 0, len(items))
+		for _, item := range items {
+			line := item.Name
+			if item.Description != "" {
+				line += ": " + item.Description
+			}
+			lines = append(lines, line)
+		}
+		return lines, nil
+	}
+}
+
 // NewPermissionMiddleware creates a new permission middleware
 func NewPermissionMiddleware(toolPermissions map[string][]string) *PermissionMiddleware {
 	// Convert map[string][]string to map[string]string for middleware compatibility
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// toolStreams keeps the stderr of a tool execution apart from its stdout, so
+// the stdout returned to MCP clients stays parseable when tools log to stderr
+type toolStreams struct {
+	stderr strings.Builder
+	// progress streams both outputs to the client as they arrive
+	progress *progressReporter
+}
+
+// add writes stdout chunks of event to output and keeps stderr chunks aside.
+// It reports whether the event carried tool output.
+func (s *toolStreams) add(event kubiya.WorkflowSSEEvent, output *strings.Builder) bool {
+	chunk, ok := event.OutputChunk()
+	if !ok {
+		return false
+	}
+	s.progress.write(chunk.Content)
+	if chunk.Stream == kubiya.StreamStderr {
+		s.stderr.WriteString(chunk.Content)
+	} else {
+		output.WriteString(chunk.Content)
+	}
+	return true
+}
+
+// appendTo writes the collected stderr to output as a separate section,
+// once the execution is over. Partial output not sent yet is sent first.
+func (s *toolStreams) appendTo(output *strings.Builder) {
+	s.progress.flush()
+	if s.stderr.Len() == 0 {
+		return
+	}
+	output.WriteString("\n--- stderr ---\n")
+	output.WriteString(s.stderr.String())
+	if !strings.HasSuffix(s.stderr.String(), "\n") {
+		output.WriteString("\n")
+	}
+	s.stderr.Reset()
+}
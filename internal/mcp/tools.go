@@ -91,7 +91,7 @@ func (s *Server) addCorePlatformTools() error {
 			handler:     s.listSourcesHandler,
 			params: []func(string, ...mcp.PropertyOption) mcp.ToolOption{
 				func(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
-					return mcp.WithNumber("page", append(opts, mcp.Description("Page number (default: 1)"))...)
+					return mcp.WithString("cursor", append(opts, mcp.Description("Cursor of the page to get, from pagination.next_cursor of the previous page"))...)
 				},
 				func(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
 					return mcp.WithNumber("page_size", append(opts, mcp.Description("Items per page (default: 20, max: 50)"))...)
@@ -132,7 +132,7 @@ func (s *Server) addCorePlatformTools() error {
 					return mcp.WithString("query", append(opts, mcp.Required(), mcp.Description("Search query for tool names and descriptions"))...)
 				},
 				func(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
-					return mcp.WithNumber("page", append(opts, mcp.Description("Page number (default: 1)"))...)
+					return mcp.WithString("cursor", append(opts, mcp.Description("Cursor of the page to get, from pagination.next_cursor of the previous page"))...)
 				},
 				func(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
 					return mcp.WithNumber("page_size", append(opts, mcp.Description("Items per page (default: 20, max: 50)"))...)
@@ -421,8 +421,12 @@ func (s *Server) executeToolHandler(ctx context.Context, request mcp.CallToolReq
 		eventCount := 0
 		errorCount := 0
 
+		streams := toolStreams{progress: newProgressReporter(ctx, request)}
 		for event := range eventChan {
 			eventCount++
+			if streams.add(event, &output) {
+				continue
+			}
 			switch event.Type {
 			case "error":
 				errorCount++
@@ -435,11 +439,8 @@ func (s *Server) executeToolHandler(ctx context.Context, request mcp.CallToolReq
 					"event_count": eventCount,
 				})
 
+				streams.appendTo(&output)
 				return mcp.NewToolResultText(output.String()), nil
-			case "stdout":
-				output.WriteString(event.Data)
-			case "stderr":
-				output.WriteString(fmt.Sprintf("⚠️ %s", event.Data))
 			case "done":
 				output.WriteString("\n✅ Tool execution completed\n")
 
@@ -453,6 +454,7 @@ func (s *Server) executeToolHandler(ctx context.Context, request mcp.CallToolReq
 				output.WriteString(fmt.Sprintf("📝 %s: %s\n", event.Type, event.Data))
 			}
 		}
+		streams.appendTo(&output)
 
 		return mcp.NewToolResultText(output.String()), nil
 	})
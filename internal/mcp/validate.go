@@ -0,0 +1,418 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubiyabot/cli/internal/docload"
+	"github.com/kubiyabot/cli/internal/mcp/filter"
+	"github.com/kubiyabot/cli/internal/suggest"
+)
+
+// ConfigIssue is a problem found while validating the MCP server config
+type ConfigIssue struct {
+	File    string
+	Line    int
+	Path    string
+	Message string
+	// Warning marks issues that do not stop the server but likely do not do
+	// what was intended
+	Warning bool
+}
+
+func (i ConfigIssue) String() string {
+	msg := i.Message
+	if i.Path != "" {
+		msg = i.Path + ": " + msg
+	}
+	if i.Warning {
+		msg = "warning: " + msg
+	}
+	loc := i.File
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, i.Line)
+	}
+	if loc == "" {
+		return msg
+	}
+	return loc + ": " + msg
+}
+
+// toolArgTypes are the argument types whitelisted tools may declare
+var toolArgTypes = []string{"string", "number", "int", "integer", "float", "boolean", "bool", "object", "dict", "array", "list"}
+
+// toolRoles are the roles of the default tool permissions
+var toolRoles = []string{"admin", "operator", "user"}
+
+// ConfigPath returns the config file the server reads: configFile, or
+// ~/.kubiya/mcp-server.json when it is empty
+func ConfigPath(configFile string) string {
+	if configFile != "" {
+		return configFile
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".kubiya", "mcp-server.json")
+}
+
+// ValidateConfigFile checks the config file at path, see ValidateConfig. A
+// missing file is an error wrapping os.ErrNotExist.
+func ValidateConfigFile(fs afero.Fs, path string, production bool) ([]ConfigIssue, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return ValidateConfig(data, path, production), nil
+}
+
+// ValidateConfig strictly checks a config file for syntax errors, unknown
+// keys, values of the wrong type, invalid tool permissions, malformed
+// whitelisted tools and tool timeouts of unknown tools. Keys only read by
+// the production server are reported as warnings unless production is set.
+func ValidateConfig(data []byte, file string, production bool) []ConfigIssue {
+	v := &configValidator{file: file, production: production}
+
+	// Configs that look like JSON get the precise JSON syntax errors; the
+	// others are YAML
+	if docload.LooksLikeJSON(data) {
+		var syntaxErr *json.SyntaxError
+		if err := json.Unmarshal(data, new(interface{})); errors.As(err, &syntaxErr) {
+			return []ConfigIssue{{File: file, Line: lineAt(data, syntaxErr.Offset), Message: "invalid JSON: " + syntaxErr.Error()}}
+		} else if err != nil {
+			return []ConfigIssue{{File: file, Message: "invalid JSON: " + err.Error()}}
+		}
+	}
+
+	// JSON is YAML, whose nodes know their line
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		issue := ConfigIssue{File: file, Message: "invalid YAML: " + strings.TrimPrefix(err.Error(), "yaml: ")}
+		fmt.Sscanf(issue.Message, "invalid YAML: line %d:", &issue.Line)
+		return []ConfigIssue{issue}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.errorf(root, "", "the config must be an object")
+		return v.issues
+	}
+
+	v.checkType(root, reflect.TypeOf(Config{}), "")
+	v.checkPermissions(mappingValue(root, "tool_permissions"))
+	v.checkWhitelistedTools(mappingValue(root, "whitelisted_tools"))
+	v.checkTimeouts(root)
+	return v.issues
+}
+
+type configValidator struct {
+	file       string
+	production bool
+	issues     []ConfigIssue
+}
+
+func (v *configValidator) errorf(node *yaml.Node, path, format string, args ...interface{}) {
+	v.issues = append(v.issues, ConfigIssue{File: v.file, Line: node.Line, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *configValidator) warnf(node *yaml.Node, path, format string, args ...interface{}) {
+	v.issues = append(v.issues, ConfigIssue{File: v.file, Line: node.Line, Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// checkType reports keys unknown to t and values of the wrong type
+func (v *configValidator) checkType(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.errorf(node, path, "expected an object, got %s", describeNode(node))
+			return
+		}
+		v.checkFields(node, t, path)
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.errorf(node, path, "expected a list, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.checkType(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.errorf(node, path, "expected an object, got %s", describeNode(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.checkType(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	case reflect.Bool:
+		if node.Tag != "!!bool" {
+			v.errorf(node, path, "expected true or false, got %s", describeNode(node))
+		}
+	case reflect.Int, reflect.Int64:
+		if node.Tag != "!!int" {
+			msg := fmt.Sprintf("expected a whole number, got %s", describeNode(node))
+			if _, err := time.ParseDuration(node.Value); err == nil && node.Tag == "!!str" {
+				msg += " (timeouts are numbers of seconds)"
+			}
+			v.errorf(node, path, "%s", msg)
+		}
+	case reflect.Float64:
+		if node.Tag != "!!int" && node.Tag != "!!float" {
+			v.errorf(node, path, "expected a number, got %s", describeNode(node))
+		}
+	case reflect.String:
+		if node.Tag != "!!str" {
+			v.errorf(node, path, "expected a string, got %s", describeNode(node))
+		}
+	}
+}
+
+// checkFields reports keys that do not match a json tag of t
+func (v *configValidator) checkFields(node *yaml.Node, t reflect.Type, path string) {
+	fields := jsonFields(t)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Keys of the production config the simple server ignores
+	var simple map[string]reflect.Type
+	if t == reflect.TypeOf(Config{}) && !v.production {
+		simple = jsonFields(reflect.TypeOf(Configuration{}))
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		childPath := joinPath(path, key.Value)
+		field, ok := fields[key.Value]
+		if !ok {
+			msg := "unknown key"
+			if s := suggest.Closest(key.Value, names); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			v.errorf(key, childPath, "%s", msg)
+			continue
+		}
+		if simple != nil {
+			if _, ok := simple[key.Value]; !ok && key.Value != "org_id" {
+				v.warnf(key, childPath, "only used with --production")
+			}
+		}
+		v.checkType(value, field, childPath)
+	}
+}
+
+// checkPermissions checks the roles allowed to call each tool
+func (v *configValidator) checkPermissions(node *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		tool, roles := node.Content[i], node.Content[i+1]
+		path := joinPath("tool_permissions", tool.Value)
+		if roles.Kind != yaml.SequenceNode {
+			continue
+		}
+		if len(roles.Content) == 0 {
+			v.errorf(roles, path, "no roles, nobody can call %s", tool.Value)
+		}
+		for j, role := range roles.Content {
+			rolePath := fmt.Sprintf("%s[%d]", path, j)
+			switch {
+			case strings.TrimSpace(role.Value) == "":
+				v.errorf(role, rolePath, "empty role")
+			case !contains(toolRoles, role.Value):
+				msg := fmt.Sprintf("unknown role %q (known roles: %s)", role.Value, strings.Join(toolRoles, ", "))
+				if s := suggest.Closest(role.Value, toolRoles); s != "" {
+					msg = fmt.Sprintf("unknown role %q (did you mean %q?)", role.Value, s)
+				}
+				v.warnf(role, rolePath, "%s", msg)
+			}
+		}
+	}
+}
+
+// checkWhitelistedTools checks that tools and their arguments are named and
+// that arguments have a known type
+func (v *configValidator) checkWhitelistedTools(node *yaml.Node) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+	seen := make(map[string]int)
+	for i, tool := range node.Content {
+		path := fmt.Sprintf("whitelisted_tools[%d]", i)
+		if tool.Kind != yaml.MappingNode {
+			continue
+		}
+		name := mappingValue(tool, "name")
+		switch {
+		case name == nil || strings.TrimSpace(name.Value) == "":
+			v.errorf(tool, path, "tool has no name")
+		case seen[name.Value] > 0:
+			v.errorf(name, path+".name", "tool %q is already defined on line %d", name.Value, seen[name.Value])
+		default:
+			seen[name.Value] = name.Line
+		}
+
+		args := mappingValue(tool, "args")
+		if args == nil || args.Kind != yaml.SequenceNode {
+			continue
+		}
+		for j, arg := range args.Content {
+			argPath := fmt.Sprintf("%s.args[%d]", path, j)
+			if arg.Kind != yaml.MappingNode {
+				continue
+			}
+			argName := mappingValue(arg, "name")
+			if argName == nil || strings.TrimSpace(argName.Value) == "" {
+				v.errorf(arg, argPath, "argument has no name")
+			}
+			argType := mappingValue(arg, "type")
+			switch {
+			case argType == nil || argType.Value == "":
+				v.errorf(arg, argPath, "argument has no type (one of %s)", strings.Join(toolArgTypes, ", "))
+			case !contains(toolArgTypes, argType.Value):
+				msg := fmt.Sprintf("unknown type %q (one of %s)", argType.Value, strings.Join(toolArgTypes, ", "))
+				if s := suggest.Closest(argType.Value, toolArgTypes); s != "" {
+					msg = fmt.Sprintf("unknown type %q (did you mean %q?)", argType.Value, s)
+				}
+				v.errorf(argType, argPath+".type", "%s", msg)
+			}
+		}
+	}
+}
+
+// checkTimeouts checks that timeouts are positive and that tool timeouts
+// name a known tool, catching typos that would leave the default in place
+func (v *configValidator) checkTimeouts(root *yaml.Node) {
+	for _, key := range []string{"session_timeout", "agent_tools_refresh"} {
+		if node := mappingValue(root, key); node != nil && node.Tag == "!!int" {
+			if n, _ := strconv.Atoi(node.Value); n < 0 {
+				v.errorf(node, key, "must not be negative")
+			}
+		}
+	}
+
+	node := mappingValue(root, "tool_timeouts")
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	known := make([]string, 0, len(longRunningToolTimeouts))
+	for name := range longRunningToolTimeouts {
+		known = append(known, name)
+	}
+	for name := range filter.DefaultToolPermissions() {
+		known = append(known, name)
+	}
+	if tools := mappingValue(root, "whitelisted_tools"); tools != nil {
+		for _, tool := range tools.Content {
+			if name := mappingValue(tool, "name"); name != nil {
+				known = append(known, name.Value)
+			}
+		}
+	}
+	sort.Strings(known)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		tool, timeout := node.Content[i], node.Content[i+1]
+		path := joinPath("tool_timeouts", tool.Value)
+		if timeout.Tag == "!!int" {
+			if n, _ := strconv.Atoi(timeout.Value); n <= 0 {
+				v.errorf(timeout, path, "timeout must be a positive number of seconds")
+			}
+		}
+		if !contains(known, tool.Value) {
+			if s := suggest.Closest(tool.Value, known); s != "" {
+				v.warnf(tool, path, "unknown tool %q (did you mean %q?)", tool.Value, s)
+			}
+		}
+	}
+}
+
+// jsonFields maps the json key of each field of t to its type
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func describeNode(node *yaml.Node) string {
+	switch {
+	case node.Kind == yaml.MappingNode:
+		return "an object"
+	case node.Kind == yaml.SequenceNode:
+		return "a list"
+	case node.Tag == "!!str":
+		return fmt.Sprintf("string %q", node.Value)
+	default:
+		return node.Value
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lineAt returns the line of the byte offset in data
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return strings.Count(string(data[:offset]), "\n") + 1
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
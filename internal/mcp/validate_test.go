@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestValidateConfig(t *testing.T) {
+	data := `{
+  "server_name": "ops",
+  "sesion_timeout": 600,
+  "tool_timeouts": {
+    "execute_workflw": 3600,
+    "kubectl": "30s"
+  },
+  "tool_permissions": {
+    "delete_user": [],
+    "scale_service": ["admn", ""]
+  },
+  "whitelisted_tools": [
+    {
+      "name": "kubectl",
+      "args": [
+        {"name": "command", "type": "string"},
+        {"name": "namespace"},
+        {"type": "strng"}
+      ]
+    },
+    {"name": "kubectl"},
+    {"description": "no name"}
+  ],
+  "rate_limit": {"requests_per_second": "fast"}
+}`
+	issues := ValidateConfig([]byte(data), "mcp.json", true)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`mcp.json:3: sesion_timeout: unknown key (did you mean "session_timeout"?)`,
+		`mcp.json:6: tool_timeouts.kubectl: expected a whole number, got string "30s" (timeouts are numbers of seconds)`,
+		`mcp.json:24: rate_limit.requests_per_second: expected a number, got string "fast"`,
+		`mcp.json:9: tool_permissions.delete_user: no roles, nobody can call delete_user`,
+		`mcp.json:10: warning: tool_permissions.scale_service[0]: unknown role "admn" (did you mean "admin"?)`,
+		`mcp.json:10: tool_permissions.scale_service[1]: empty role`,
+		`mcp.json:17: whitelisted_tools[0].args[1]: argument has no type (one of string, number, int, integer, float, boolean, bool, object, dict, array, list)`,
+		`mcp.json:18: whitelisted_tools[0].args[2]: argument has no name`,
+		`mcp.json:18: whitelisted_tools[0].args[2].type: unknown type "strng" (did you mean "string"?)`,
+		`mcp.json:21: whitelisted_tools[1].name: tool "kubectl" is already defined on line 14`,
+		`mcp.json:22: whitelisted_tools[2]: tool has no name`,
+		`mcp.json:5: warning: tool_timeouts.execute_workflw: unknown tool "execute_workflw" (did you mean "execute_workflow"?)`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected issues:\n%s\n\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateConfigProductionKeys(t *testing.T) {
+	issues := ValidateConfig([]byte(`{"enable_runners": true, "require_auth": true}`), "mcp.json", false)
+	if len(issues) != 1 || !issues[0].Warning || issues[0].Path != "require_auth" {
+		t.Fatalf("expected a warning for require_auth, got %v", issues)
+	}
+
+	if issues := ValidateConfig([]byte(`{"enable_runners": true, "require_auth": true}`), "mcp.json", true); len(issues) != 0 {
+		t.Errorf("expected no issues in production mode, got %v", issues)
+	}
+}
+
+func TestValidateConfigSyntaxError(t *testing.T) {
+	issues := ValidateConfig([]byte("{\n  \"enable_runners\": true,\n}\n"), "mcp.json", false)
+	if len(issues) != 1 || issues[0].Line != 3 || !strings.Contains(issues[0].Message, "invalid JSON") {
+		t.Fatalf("expected a syntax error on line 3, got %v", issues)
+	}
+}
+
+func TestValidateConfigYAML(t *testing.T) {
+	data := "# ops server\nenable_runners: true\nwhitelisted_tools:\n  - name: kubectl\nsesion_timeout: 600\n"
+	issues := ValidateConfig([]byte(data), "mcp.yaml", false)
+	if len(issues) != 1 || issues[0].Line != 5 || issues[0].Path != "sesion_timeout" {
+		t.Fatalf("expected the unknown key on line 5, got %v", issues)
+	}
+
+	issues = ValidateConfig([]byte("server_name: ops\n  enable_runners: true\n"), "mcp.yaml", false)
+	if len(issues) != 1 || issues[0].Line != 2 || !strings.Contains(issues[0].Message, "invalid YAML") {
+		t.Fatalf("expected a YAML syntax error on line 2, got %v", issues)
+	}
+}
+
+func TestLoadConfigurationYAML(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	data := "enable_runners: false\nwhitelisted_tools:\n  - name: kubectl\n    image: bitnami/kubectl\n"
+	if err := afero.WriteFile(fs, "mcp.json", []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfiguration(fs, "mcp.json", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.EnableRunners || len(config.WhitelistedTools) != 1 {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if tool := config.WhitelistedTools[0]; tool.Name != "kubectl" || tool.Image != "bitnami/kubectl" {
+		t.Errorf("unexpected tool %+v", tool)
+	}
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// NewWhitelistedTool converts a full Kubiya tool definition into a
+// whitelist entry with the embedded tool schema the MCP server expects
+func NewWhitelistedTool(tool kubiya.Tool, source *kubiya.Source) WhitelistedTool {
+	wt := WhitelistedTool{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Env:         tool.Env,
+		Content:     tool.Content,
+		FileName:    tool.FileName,
+		Secrets:     tool.Secrets,
+		IconURL:     tool.IconURL,
+		Type:        tool.Type,
+		Alias:       tool.Alias,
+		WithFiles:   tool.WithFiles,
+		WithVolumes: tool.WithVolumes,
+		LongRunning: tool.LongRunning,
+		Metadata:    tool.Metadata,
+		Mermaid:     tool.Mermaid,
+		Image:       tool.Image,
+		Source: ToolSource{
+			ID:  tool.Source.ID,
+			URL: tool.Source.URL,
+		},
+	}
+
+	if source != nil {
+		if wt.Source.ID == "" {
+			wt.Source.ID = source.UUID
+		}
+		if wt.Source.URL == "" {
+			wt.Source.URL = source.URL
+		}
+	}
+
+	for _, arg := range tool.Args {
+		wt.Args = append(wt.Args, ToolArg{
+			Name:        arg.Name,
+			Type:        arg.Type,
+			Description: arg.Description,
+			Required:    arg.Required,
+			Default:     arg.Default,
+			Options:     arg.Options,
+		})
+	}
+
+	return wt
+}
+
+// WhitelistFromSource builds whitelist entries for the named tools of a source.
+// When names is empty every tool in the source is included. Requested names
+// that do not exist in the source are reported as an error.
+func WhitelistFromSource(source *kubiya.Source, names []string) ([]WhitelistedTool, error) {
+	// Copy before appending, not to write into the backing array of the
+	// caller's source
+	all := make([]kubiya.Tool, 0, len(source.Tools)+len(source.InlineTools))
+	all = append(append(all, source.Tools...), source.InlineTools...)
+	available := make(map[string]kubiya.Tool)
+	for _, tool := range all {
+		if _, exists := available[tool.Name]; !exists {
+			available[tool.Name] = tool
+		}
+	}
+
+	if len(names) == 0 {
+		for name := range available {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var (
+		tools   []WhitelistedTool
+		missing []string
+	)
+	for _, name := range names {
+		tool, ok := available[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		tools = append(tools, NewWhitelistedTool(tool, source))
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("tools not found in source %s: %v", source.UUID, missing)
+	}
+
+	return tools, nil
+}
+
+// MergeWhitelistedTools replaces entries with the same name and appends new
+// ones. Entries are the generic whitelisted_tools of a configuration file, so
+// that fields this version does not know survive: other entries are kept as
+// they are, and the fields of a replaced entry are overwritten one by one.
+func MergeWhitelistedTools(existing []interface{}, updates []WhitelistedTool) ([]interface{}, error) {
+	index := make(map[string]int, len(existing))
+	for i, entry := range existing {
+		if m, ok := entry.(map[string]interface{}); ok {
+			if name, ok := m["name"].(string); ok {
+				index[name] = i
+			}
+		}
+	}
+
+	merged := append([]interface{}{}, existing...)
+	for _, wt := range updates {
+		data, err := json.Marshal(wt)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+
+		i, ok := index[wt.Name]
+		if !ok {
+			index[wt.Name] = len(merged)
+			merged = append(merged, fields)
+			continue
+		}
+		entry := make(map[string]interface{}, len(fields))
+		for k, v := range merged[i].(map[string]interface{}) {
+			entry[k] = v
+		}
+		for k, v := range fields {
+			entry[k] = v
+		}
+		merged[i] = entry
+	}
+	return merged, nil
+}
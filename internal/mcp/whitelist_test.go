@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestWhitelistFromSource(t *testing.T) {
+	source := &kubiya.Source{
+		UUID: "source-uuid",
+		URL:  "https://github.com/kubiyabot/community-tools/tree/main/kubernetes",
+		Tools: []kubiya.Tool{
+			{
+				Name:        "kubectl",
+				Description: "Kubernetes command-line tool",
+				Type:        "docker",
+				Image:       "kubiya/kubectl-light:latest",
+				Env:         []string{"KUBECONFIG"},
+				Args: []kubiya.ToolArg{
+					{Name: "command", Type: "string", Description: "kubectl command", Required: true},
+				},
+			},
+			{Name: "helm", Description: "Helm package manager", Image: "alpine/helm"},
+		},
+	}
+
+	t.Run("selected tools", func(t *testing.T) {
+		tools, err := WhitelistFromSource(source, []string{"kubectl"})
+		if err != nil {
+			t.Fatalf("WhitelistFromSource() error = %v", err)
+		}
+		if len(tools) != 1 {
+			t.Fatalf("expected 1 tool, got %d", len(tools))
+		}
+
+		wt := tools[0]
+		if wt.Image != "kubiya/kubectl-light:latest" || wt.Type != "docker" {
+			t.Errorf("tool definition not embedded: %+v", wt)
+		}
+		if wt.Source.ID != "source-uuid" || wt.Source.URL != source.URL {
+			t.Errorf("expected source to default to the parent source, got %+v", wt.Source)
+		}
+		if len(wt.Args) != 1 || !wt.Args[0].Required {
+			t.Errorf("expected required arg to be preserved, got %+v", wt.Args)
+		}
+	})
+
+	t.Run("all tools", func(t *testing.T) {
+		tools, err := WhitelistFromSource(source, nil)
+		if err != nil {
+			t.Fatalf("WhitelistFromSource() error = %v", err)
+		}
+		if len(tools) != 2 || tools[0].Name != "helm" || tools[1].Name != "kubectl" {
+			t.Errorf("expected all tools sorted by name, got %+v", tools)
+		}
+	})
+
+	t.Run("missing tool", func(t *testing.T) {
+		if _, err := WhitelistFromSource(source, []string{"terraform"}); err == nil {
+			t.Error("expected error for missing tool")
+		}
+	})
+}
+
+func TestWhitelistFromSourceKeepsSourceTools(t *testing.T) {
+	tools := make([]kubiya.Tool, 1, 2)
+	tools[0] = kubiya.Tool{Name: "kubectl"}
+	source := &kubiya.Source{UUID: "source-uuid", Tools: tools, InlineTools: []kubiya.Tool{{Name: "helm"}}}
+
+	if _, err := WhitelistFromSource(source, nil); err != nil {
+		t.Fatalf("WhitelistFromSource() error = %v", err)
+	}
+	if extra := tools[:2][1]; extra.Name != "" {
+		t.Errorf("expected the tools of the source to be left alone, got %q appended", extra.Name)
+	}
+}
+
+func TestMergeWhitelistedTools(t *testing.T) {
+	existing := []interface{}{
+		map[string]interface{}{"name": "kubectl", "description": "old", "x-team": "sre"},
+		map[string]interface{}{"name": "aws-cli", "description": "aws", "x-team": "cloud"},
+	}
+	updates := []WhitelistedTool{
+		{Name: "kubectl", Description: "new"},
+		{Name: "helm", Description: "helm"},
+	}
+
+	merged, err := MergeWhitelistedTools(existing, updates)
+	if err != nil {
+		t.Fatalf("MergeWhitelistedTools() error = %v", err)
+	}
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 tools, got %d", len(merged))
+	}
+	kubectl := merged[0].(map[string]interface{})
+	if kubectl["description"] != "new" {
+		t.Errorf("expected kubectl to be replaced, got %q", kubectl["description"])
+	}
+	if kubectl["x-team"] != "sre" {
+		t.Errorf("expected unknown fields of kubectl to be kept, got %v", kubectl)
+	}
+	if aws := merged[1].(map[string]interface{}); aws["x-team"] != "cloud" {
+		t.Errorf("expected aws-cli to be kept as is, got %v", aws)
+	}
+	if helm := merged[2].(map[string]interface{}); helm["name"] != "helm" {
+		t.Errorf("expected helm to be appended, got %v", helm)
+	}
+	if existing[0].(map[string]interface{})["description"] != "old" {
+		t.Error("expected the existing entries to be left alone")
+	}
+}
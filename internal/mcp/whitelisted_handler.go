@@ -90,21 +90,23 @@ func (s *Server) executeWhitelistedToolHandler(ctx context.Context, request mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute whitelisted tool: %v", err)), nil
 	}
 
+	streams := toolStreams{progress: newProgressReporter(ctx, request)}
 	for event := range eventChan {
+		if streams.add(event, &output) {
+			continue
+		}
 		switch event.Type {
 		case "error":
 			output.WriteString(fmt.Sprintf("❌ Error: %s\n", event.Data))
+			streams.appendTo(&output)
 			return mcp.NewToolResultText(output.String()), nil
-		case "stdout":
-			output.WriteString(event.Data)
-		case "stderr":
-			output.WriteString(fmt.Sprintf("⚠️ %s", event.Data))
 		case "done":
 			output.WriteString("\n✅ Whitelisted tool execution completed\n")
 		default:
 			output.WriteString(fmt.Sprintf("📝 %s: %s\n", event.Type, event.Data))
 		}
 	}
+	streams.appendTo(&output)
 
 	return mcp.NewToolResultText(output.String()), nil
 }
@@ -127,57 +129,48 @@ func NewWhitelistedToolHandler(client *kubiya.Client, tool WhitelistedTool, kubi
 
 // Register registers the whitelisted tool as an individual MCP tool
 func (h *WhitelistedToolHandler) Register(server *server.MCPServer) error {
-	// Build MCP tool options
-	var opts []mcp.ToolOption
-	opts = append(opts, mcp.WithDescription(h.tool.Description))
+	server.AddTools(h.ServerTool())
+	return nil
+}
+
+// ServerTool returns the MCP tool with its handler
+func (h *WhitelistedToolHandler) ServerTool() server.ServerTool {
+	return server.ServerTool{Tool: h.Tool(), Handler: h.handleToolCall}
+}
+
+// Tool translates the arguments of the tool into the input schema of an
+// MCP tool. Options of string arguments become an enum.
+func (h *WhitelistedToolHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{mcp.WithDescription(h.tool.Description)}
 
-	// Add arguments to the MCP tool
 	for _, arg := range h.tool.Args {
+		props := []mcp.PropertyOption{mcp.Description(arg.Description)}
+		if arg.Required {
+			props = append(props, mcp.Required())
+		}
+
 		switch arg.Type {
-		case "string":
-			if arg.Required {
-				opts = append(opts, mcp.WithString(arg.Name, mcp.Required(), mcp.Description(arg.Description)))
-			} else {
-				opts = append(opts, mcp.WithString(arg.Name, mcp.Description(arg.Description)))
-			}
-		case "number", "int", "integer":
-			if arg.Required {
-				opts = append(opts, mcp.WithNumber(arg.Name, mcp.Required(), mcp.Description(arg.Description)))
-			} else {
-				opts = append(opts, mcp.WithNumber(arg.Name, mcp.Description(arg.Description)))
-			}
+		case "number", "int", "integer", "float":
+			opts = append(opts, mcp.WithNumber(arg.Name, props...))
 		case "boolean", "bool":
-			if arg.Required {
-				opts = append(opts, mcp.WithBoolean(arg.Name, mcp.Required(), mcp.Description(arg.Description)))
-			} else {
-				opts = append(opts, mcp.WithBoolean(arg.Name, mcp.Description(arg.Description)))
-			}
-		case "object":
-			if arg.Required {
-				opts = append(opts, mcp.WithObject(arg.Name, mcp.Required(), mcp.Description(arg.Description)))
-			} else {
-				opts = append(opts, mcp.WithObject(arg.Name, mcp.Description(arg.Description)))
-			}
-		case "array":
-			if arg.Required {
-				opts = append(opts, mcp.WithArray(arg.Name, mcp.Required(), mcp.Description(arg.Description)))
-			} else {
-				opts = append(opts, mcp.WithArray(arg.Name, mcp.Description(arg.Description)))
-			}
+			opts = append(opts, mcp.WithBoolean(arg.Name, props...))
+		case "object", "dict":
+			opts = append(opts, mcp.WithObject(arg.Name, props...))
+		case "array", "list":
+			opts = append(opts, mcp.WithArray(arg.Name, props...))
 		default:
 			// Default to string type
-			if arg.Required {
-				opts = append(opts, mcp.WithString(arg.Name, mcp.Required(), mcp.Description(arg.Description)))
-			} else {
-				opts = append(opts, mcp.WithString(arg.Name, mcp.Description(arg.Description)))
+			if len(arg.Options) > 0 {
+				props = append(props, mcp.Enum(arg.Options...))
 			}
+			if arg.Default != "" {
+				props = append(props, mcp.DefaultString(arg.Default))
+			}
+			opts = append(opts, mcp.WithString(arg.Name, props...))
 		}
 	}
 
-	// Register the tool with the handler
-	server.AddTool(mcp.NewTool(h.tool.Name, opts...), h.handleToolCall)
-
-	return nil
+	return mcp.NewTool(h.tool.Name, opts...)
 }
 
 // handleToolCall handles the actual execution of the whitelisted tool
@@ -231,7 +224,11 @@ func (h *WhitelistedToolHandler) handleToolCall(ctx context.Context, request mcp
 	output.WriteString(fmt.Sprintf("🔧 Executed tool: %s\n\n", h.tool.Name))
 
 	// Process streaming events
+	streams := toolStreams{progress: newProgressReporter(ctx, request)}
 	for event := range result {
+		if streams.add(event, &output) {
+			continue
+		}
 		switch event.Type {
 		case "data":
 			// Parse JSON data from SSE event
@@ -239,14 +236,6 @@ func (h *WhitelistedToolHandler) handleToolCall(ctx context.Context, request mcp
 			if err := json.Unmarshal([]byte(event.Data), &data); err == nil {
 				if eventType, ok := data["type"].(string); ok {
 					switch eventType {
-					case "stdout":
-						if stdout, ok := data["stdout"].(string); ok {
-							output.WriteString(stdout)
-						}
-					case "stderr":
-						if stderr, ok := data["stderr"].(string); ok {
-							output.WriteString(fmt.Sprintf("❌ Error: %s\n", stderr))
-						}
 					case "exit":
 						if exitCode, ok := data["exit_code"].(float64); ok {
 							if exitCode != 0 {
@@ -271,6 +260,7 @@ func (h *WhitelistedToolHandler) handleToolCall(ctx context.Context, request mcp
 			output.WriteString(fmt.Sprintf("📝 %s: %s\n", event.Type, event.Data))
 		}
 	}
+	streams.appendTo(&output)
 
 	return mcp.NewToolResultText(output.String()), nil
 }
@@ -0,0 +1,108 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Object is a fixture resource as returned by the API
+type Object = map[string]interface{}
+
+// ToolCall is a tool call a mocked chat reply makes before answering
+type ToolCall struct {
+	Name   string                 `json:"name"`
+	Args   map[string]interface{} `json:"args,omitempty"`
+	Output string                 `json:"output"`
+}
+
+// Reply is a canned chat answer. The first reply whose Match is contained in
+// the message (case-insensitive) is used; an empty Match matches anything.
+type Reply struct {
+	Match     string     `json:"match"`
+	Reply     string     `json:"reply"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Fixtures seed the mock API
+type Fixtures struct {
+	Agents  []Object `json:"agents"`
+	Sources []Object `json:"sources"`
+	Runners []Object `json:"runners"`
+	Replies []Reply  `json:"replies"`
+}
+
+// mockTimestamp is the creation time of the default fixtures
+const mockTimestamp = "2024-01-01T00:00:00Z"
+
+// fixtureFiles maps the files read by LoadFixtures to the fixtures they seed
+var fixtureFiles = []struct {
+	name string
+	dest func(f *Fixtures) interface{}
+}{
+	{"agents.json", func(f *Fixtures) interface{} { return &f.Agents }},
+	{"sources.json", func(f *Fixtures) interface{} { return &f.Sources }},
+	{"runners.json", func(f *Fixtures) interface{} { return &f.Runners }},
+	{"chat.json", func(f *Fixtures) interface{} { return &f.Replies }},
+}
+
+// DefaultFixtures returns a small organization with one agent, one source
+// and one runner, enough for the common CLI flows
+func DefaultFixtures() Fixtures {
+	return Fixtures{
+		Agents: []Object{{
+			"uuid":            "mock-agent-1",
+			"id":              "mock-agent-1",
+			"name":            "mock-agent",
+			"description":     "Agent served by the Kubiya mock API",
+			"llm_model":       "mock-model",
+			"ai_instructions": "You are a mock agent.",
+			"sources":         []interface{}{"mock-source-1"},
+			"runners":         []interface{}{"mock-runner"},
+			"tools":           []interface{}{},
+			"status":          "active",
+			"created_at":      mockTimestamp,
+			"updated_at":      mockTimestamp,
+		}},
+		Sources: []Object{{
+			"uuid":       "mock-source-1",
+			"name":       "mock-tools",
+			"url":        "https://github.com/kubiyabot/community-tools/tree/main/mock",
+			"type":       "git",
+			"created_at": mockTimestamp,
+			"tools": []interface{}{
+				Object{"name": "echo", "description": "Echo the input", "type": "docker", "image": "alpine", "content": "echo $message",
+					"args": []interface{}{Object{"name": "message", "type": "string", "description": "Text to echo", "required": true}}},
+			},
+		}},
+		Runners: []Object{{
+			"name":        "mock-runner",
+			"runner_type": "local",
+			"version":     "mock",
+		}},
+		Replies: []Reply{{Reply: "This is a mock response."}},
+	}
+}
+
+// LoadFixtures reads agents.json, sources.json, runners.json and chat.json
+// from dir. Missing files keep the defaults, so a directory only needs the
+// fixtures a test cares about.
+func LoadFixtures(dir string) (Fixtures, error) {
+	fixtures := DefaultFixtures()
+	for _, file := range fixtureFiles {
+		path := filepath.Join(dir, file.name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Fixtures{}, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		if err := json.Unmarshal(data, file.dest(&fixtures)); err != nil {
+			return Fixtures{}, fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+	}
+	return fixtures, nil
+}
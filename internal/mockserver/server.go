@@ -0,0 +1,252 @@
+// Package mockserver implements an in-memory mock of the Kubiya API for
+// development and CI. It serves agents, sources, runners and streaming chat
+// from fixtures, without credentials or network access.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is the mock Kubiya API
+type Server struct {
+	mu       sync.Mutex
+	fixtures Fixtures
+	nextID   int
+}
+
+// New returns a mock API serving fixtures. Changes made through the API
+// (creating, updating or deleting agents) are kept in memory.
+func New(fixtures Fixtures) *Server {
+	return &Server{fixtures: fixtures}
+}
+
+// Start serves the mock API on addr (e.g. "127.0.0.1:0") in the background
+// and returns its base URL and a function that stops it
+func (s *Server) Start(addr string) (string, func() error, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start mock server: %w", err)
+	}
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(listener)
+	return "http://" + listener.Addr().String(), srv.Close, nil
+}
+
+// ServeHTTP routes V1 API paths. The /api/v1 and /api/v3 prefixes are
+// optional so that clients configured with either base URL work.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+	path = strings.TrimPrefix(path, "/api/v3")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case parts[0] == "healthz":
+		writeJSON(w, http.StatusOK, Object{"status": "ok"})
+	case parts[0] == "agents":
+		s.serveAgents(w, r, parts[1:])
+	case parts[0] == "sources":
+		s.serveSources(w, r, parts[1:])
+	case parts[0] == "runners":
+		s.serveRunners(w, r, parts[1:])
+	case path == "/hb/v4/stream" && r.Method == http.MethodPost:
+		s.serveChat(w, r)
+	default:
+		notFound(w, r)
+	}
+}
+
+func (s *Server) serveAgents(w http.ResponseWriter, r *http.Request, rest []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.fixtures.Agents)
+		case http.MethodPost:
+			agent, ok := decodeObject(w, r)
+			if !ok {
+				return
+			}
+			s.nextID++
+			id := fmt.Sprintf("mock-agent-new-%d", s.nextID)
+			agent["uuid"], agent["id"] = id, id
+			s.fixtures.Agents = append(s.fixtures.Agents, agent)
+			writeJSON(w, http.StatusOK, agent)
+		default:
+			notFound(w, r)
+		}
+		return
+	}
+
+	i := findObject(s.fixtures.Agents, rest[0])
+	if i < 0 || len(rest) > 1 {
+		notFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.fixtures.Agents[i])
+	case http.MethodPut, http.MethodPatch:
+		update, ok := decodeObject(w, r)
+		if !ok {
+			return
+		}
+		for k, v := range update {
+			s.fixtures.Agents[i][k] = v
+		}
+		writeJSON(w, http.StatusOK, s.fixtures.Agents[i])
+	case http.MethodDelete:
+		s.fixtures.Agents = append(s.fixtures.Agents[:i], s.fixtures.Agents[i+1:]...)
+		writeJSON(w, http.StatusOK, Object{"deleted": true})
+	default:
+		notFound(w, r)
+	}
+}
+
+func (s *Server) serveSources(w http.ResponseWriter, r *http.Request, rest []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		notFound(w, r)
+		return
+	}
+	if len(rest) == 0 {
+		writeJSON(w, http.StatusOK, s.fixtures.Sources)
+		return
+	}
+
+	i := findObject(s.fixtures.Sources, rest[0])
+	if i < 0 {
+		notFound(w, r)
+		return
+	}
+	source := s.fixtures.Sources[i]
+	switch {
+	case len(rest) == 1:
+		writeJSON(w, http.StatusOK, source)
+	case len(rest) == 2 && rest[1] == "metadata":
+		writeJSON(w, http.StatusOK, Object{
+			"uuid":        source["uuid"],
+			"source_uuid": source["uuid"],
+			"tools":       source["tools"],
+		})
+	default:
+		notFound(w, r)
+	}
+}
+
+func (s *Server) serveRunners(w http.ResponseWriter, r *http.Request, rest []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method != http.MethodGet:
+		notFound(w, r)
+	case len(rest) == 0:
+		writeJSON(w, http.StatusOK, s.fixtures.Runners)
+	case len(rest) == 2 && rest[1] == "health" && findObject(s.fixtures.Runners, rest[0]) >= 0:
+		writeJSON(w, http.StatusOK, Object{
+			"status":  "ok",
+			"health":  "healthy",
+			"version": "mock",
+			"checks":  []Object{{"name": "tool-manager", "status": "ok", "version": "mock"}},
+		})
+	default:
+		notFound(w, r)
+	}
+}
+
+// serveChat answers a chat message with the matching canned reply using the
+// line based data stream protocol of /hb/v4/stream
+func (s *Server) serveChat(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Message   string `json:"message"`
+		AgentUUID string `json:"agent_uuid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Object{"error": "invalid chat request: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	reply := s.reply(req.Message)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("x-vercel-ai-data-stream", "v1")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	emit := func(typ byte, v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "%c:%s\n", typ, data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for i, call := range reply.ToolCalls {
+		id := fmt.Sprintf("mock-call-%d", i+1)
+		args := call.Args
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		emit('9', Object{"toolCallId": id, "toolName": call.Name, "args": args})
+		emit('a', Object{"toolCallId": id, "result": call.Output})
+	}
+	// Stream the reply word by word like a real model would
+	for _, word := range strings.SplitAfter(reply.Reply, " ") {
+		if word != "" {
+			emit('0', word)
+		}
+	}
+	emit('d', Object{"finishReason": "stop"})
+}
+
+func (s *Server) reply(message string) Reply {
+	lower := strings.ToLower(message)
+	for _, r := range s.fixtures.Replies {
+		if strings.Contains(lower, strings.ToLower(r.Match)) {
+			return r
+		}
+	}
+	return Reply{Reply: "Mock response to: " + message}
+}
+
+// findObject returns the index of the object whose uuid, id or name is key
+func findObject(objects []Object, key string) int {
+	for i, o := range objects {
+		for _, field := range []string{"uuid", "id", "name"} {
+			if v, ok := o[field].(string); ok && v == key {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func decodeObject(w http.ResponseWriter, r *http.Request) (Object, bool) {
+	obj := Object{}
+	if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+		writeJSON(w, http.StatusBadRequest, Object{"error": "invalid request body: " + err.Error()})
+		return nil, false
+	}
+	return obj, true
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusNotFound, Object{"error": fmt.Sprintf("mock server has no fixture for %s %s", r.Method, r.URL.Path)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,112 @@
+package mockserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func startTestServer(t *testing.T, fixtures Fixtures) *kubiya.Client {
+	url, stop, err := New(fixtures).Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { stop() })
+	return kubiya.NewClient(&config.Config{APIKey: "mock", BaseURL: url + "/api/v1"})
+}
+
+func TestMockServerResources(t *testing.T) {
+	client := startTestServer(t, DefaultFixtures())
+	ctx := context.Background()
+
+	agents, err := client.GetAgents(ctx)
+	if err != nil || len(agents) != 1 || agents[0].Name != "mock-agent" {
+		t.Fatalf("GetAgents() = %+v, %v", agents, err)
+	}
+
+	created, err := client.CreateAgent(ctx, kubiya.Agent{Name: "new-agent"})
+	if err != nil || created.UUID == "" {
+		t.Fatalf("CreateAgent() = %+v, %v", created, err)
+	}
+	if agent, err := client.GetAgent(ctx, created.UUID); err != nil || agent.Name != "new-agent" {
+		t.Errorf("GetAgent() = %+v, %v", agent, err)
+	}
+
+	source, err := client.GetSourceMetadata(ctx, "mock-source-1")
+	if err != nil || len(source.Tools) != 1 || source.Tools[0].Name != "echo" {
+		t.Errorf("GetSourceMetadata() = %+v, %v", source, err)
+	}
+
+	runners, err := client.ListRunners(ctx)
+	if err != nil || len(runners) != 1 || runners[0].RunnerHealth.Health != "healthy" {
+		t.Errorf("ListRunners() = %+v, %v", runners, err)
+	}
+
+	if _, err := client.GetAgent(ctx, "missing"); err == nil {
+		t.Error("GetAgent(missing) expected an error")
+	}
+}
+
+func TestMockServerChat(t *testing.T) {
+	fixtures := DefaultFixtures()
+	fixtures.Replies = []Reply{
+		{Match: "pods", Reply: "All pods are running", ToolCalls: []ToolCall{{Name: "kubectl", Output: "pod-a Running"}}},
+		{Reply: "fallback"},
+	}
+	client := startTestServer(t, fixtures)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	msgs, err := client.SendMessage(ctx, "mock-agent-1", "Are my PODS ok?", "session-1")
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	var final kubiya.ChatMessage
+	var sawTool bool
+	for msg := range msgs {
+		if strings.Contains(msg.Content, "kubectl") || strings.Contains(msg.Content, "pod-a Running") {
+			sawTool = true
+		}
+		if msg.Final {
+			final = msg
+		}
+	}
+	if final.Content != "All pods are running" {
+		t.Errorf("final message = %q", final.Content)
+	}
+	if !sawTool {
+		t.Error("expected the kubectl tool call in the stream")
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agents.json"), []byte(`[{"uuid":"a1","name":"from-file"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	if len(fixtures.Agents) != 1 || fixtures.Agents[0]["name"] != "from-file" {
+		t.Errorf("agents = %+v", fixtures.Agents)
+	}
+	if len(fixtures.Runners) != 1 {
+		t.Errorf("missing runners.json should keep the default runner, got %+v", fixtures.Runners)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "chat.json"), []byte(`{`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixtures(dir); err == nil {
+		t.Error("expected an error for an invalid fixture")
+	}
+}
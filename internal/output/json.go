@@ -0,0 +1,183 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SortKeys makes JSON output deterministic: object keys are sorted and so
+// are arrays that are sets, such as the tools or secrets of an agent, so
+// that the output of two runs can be diffed. It is on unless
+// KUBIYA_SORT_KEYS is false, and set by the global --sort-keys flag.
+var SortKeys = sortKeysFromEnv()
+
+func sortKeysFromEnv() bool {
+	v := strings.ToLower(os.Getenv("KUBIYA_SORT_KEYS"))
+	return v != "false" && v != "0"
+}
+
+// setFields are the fields whose arrays have no meaningful order. Runners,
+// integrations and sources are not among them: their order is a preference,
+// e.g. the first runner is the default one.
+var setFields = map[string]bool{
+	"agents":         true,
+	"allowed_groups": true,
+	"allowed_users":  true,
+	"groups":         true,
+	"inline_tools":   true,
+	"owners":         true,
+	"secrets":        true,
+	"tags":           true,
+	"tools":          true,
+}
+
+// identityKeys are the fields that array elements are sorted by, in order of
+// preference
+var identityKeys = []string{"name", "key", "uuid", "id"}
+
+// EncodeJSON writes v as indented JSON. With SortKeys, keys are sorted at
+// every level and so are the arrays of set fields.
+func EncodeJSON(w io.Writer, v interface{}) error {
+	return encodeJSON(w, v, false)
+}
+
+// EncodeJSONList is EncodeJSON for a list of resources, such as agents or
+// tools. With SortKeys, the list is sorted by name as well.
+func EncodeJSONList(w io.Writer, v interface{}) error {
+	return encodeJSON(w, v, true)
+}
+
+// EncodeJSONLine is EncodeJSON on a single line, for the records of NDJSON
+// streams
+func EncodeJSONLine(w io.Writer, v interface{}) error {
+	if SortKeys {
+		canonical, err := canonicalize(v, false)
+		if err != nil {
+			return err
+		}
+		v = canonical
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+func encodeJSON(w io.Writer, v interface{}, list bool) error {
+	if SortKeys {
+		canonical, err := canonicalize(v, list)
+		if err != nil {
+			return err
+		}
+		v = canonical
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// canonicalize converts v into maps, whose keys encoding/json sorts, and
+// slices, with the arrays of set fields sorted
+func canonicalize(v interface{}, list bool) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to normalize JSON output: %w", err)
+	}
+	sortSets(generic)
+	if items, ok := generic.([]interface{}); ok && list {
+		sortArray(items)
+	}
+	return generic, nil
+}
+
+func sortSets(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			sortSets(value)
+			if items, ok := value.([]interface{}); ok && setFields[key] {
+				sortArray(items)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			sortSets(item)
+		}
+	}
+}
+
+// sortArray sorts strings, and objects by their identity key. Arrays of
+// other values, or of objects without a common identity, keep their order.
+func sortArray(items []interface{}) {
+	if len(items) < 2 {
+		return
+	}
+	if keys, ok := stringKeys(items); ok {
+		sortByKeys(items, keys)
+		return
+	}
+	for _, field := range identityKeys {
+		if keys, ok := objectKeys(items, field); ok {
+			sortByKeys(items, keys)
+			return
+		}
+	}
+}
+
+func stringKeys(items []interface{}) ([]string, bool) {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		keys[i] = s
+	}
+	return keys, true
+}
+
+func objectKeys(items []interface{}, field string) ([]string, bool) {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		s, ok := obj[field].(string)
+		if !ok {
+			return nil, false
+		}
+		keys[i] = s
+	}
+	return keys, true
+}
+
+func sortByKeys(items []interface{}, keys []string) {
+	sort.Stable(byKey{items: items, keys: keys})
+}
+
+type byKey struct {
+	items []interface{}
+	keys  []string
+}
+
+func (b byKey) Len() int { return len(b.items) }
+func (b byKey) Less(i, j int) bool {
+	ki, kj := strings.ToLower(b.keys[i]), strings.ToLower(b.keys[j])
+	if ki != kj {
+		return ki < kj
+	}
+	return b.keys[i] < b.keys[j]
+}
+func (b byKey) Swap(i, j int) {
+	b.items[i], b.items[j] = b.items[j], b.items[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAgent struct {
+	UUID        string            `json:"uuid"`
+	Name        string            `json:"name"`
+	Tools       []string          `json:"tools"`
+	Environment map[string]string `json:"environment_variables"`
+	Steps       []string          `json:"steps"`
+	MaxTokens   int64             `json:"max_tokens"`
+}
+
+func encode(t *testing.T, list bool, v interface{}) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if list {
+		require.NoError(t, EncodeJSONList(&buf, v))
+	} else {
+		require.NoError(t, EncodeJSON(&buf, v))
+	}
+	return buf.String()
+}
+
+func TestEncodeJSONSortsKeysAndSets(t *testing.T) {
+	SortKeys = true
+
+	agent := testAgent{
+		UUID:        "b",
+		Name:        "devbot",
+		Tools:       []string{"kubectl", "aws", "Helm"},
+		Environment: map[string]string{"Z": "1", "A": "2"},
+		Steps:       []string{"build", "deploy"},
+		MaxTokens:   9007199254740993,
+	}
+
+	assert.Equal(t, `{
+  "environment_variables": {
+    "A": "2",
+    "Z": "1"
+  },
+  "max_tokens": 9007199254740993,
+  "name": "devbot",
+  "steps": [
+    "build",
+    "deploy"
+  ],
+  "tools": [
+    "aws",
+    "Helm",
+    "kubectl"
+  ],
+  "uuid": "b"
+}
+`, encode(t, false, agent))
+}
+
+func TestEncodeJSONKeepsOrderedFields(t *testing.T) {
+	SortKeys = true
+
+	agent := map[string][]string{
+		"runners":      {"prod", "kubiya-hosted"},
+		"integrations": {"slack", "github"},
+		"sources":      {"src-2", "src-1"},
+	}
+
+	assert.Equal(t, `{
+  "integrations": [
+    "slack",
+    "github"
+  ],
+  "runners": [
+    "prod",
+    "kubiya-hosted"
+  ],
+  "sources": [
+    "src-2",
+    "src-1"
+  ]
+}
+`, encode(t, false, agent))
+}
+
+func TestEncodeJSONLine(t *testing.T) {
+	SortKeys = true
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJSONLine(&buf, testAgent{UUID: "b", Name: "devbot", Tools: []string{"kubectl", "aws"}}))
+	assert.Equal(t, `{"environment_variables":null,"max_tokens":0,"name":"devbot","steps":null,"tools":["aws","kubectl"],"uuid":"b"}`+"\n", buf.String())
+}
+
+func TestEncodeJSONListSortsByName(t *testing.T) {
+	SortKeys = true
+
+	agents := []testAgent{{UUID: "1", Name: "zeta"}, {UUID: "2", Name: "alpha"}}
+	out := encode(t, true, agents)
+	assert.Less(t, bytes.Index([]byte(out), []byte("alpha")), bytes.Index([]byte(out), []byte("zeta")))
+
+	// Without the list helper, the order of the top-level array is kept
+	out = encode(t, false, agents)
+	assert.Greater(t, bytes.Index([]byte(out), []byte("alpha")), bytes.Index([]byte(out), []byte("zeta")))
+}
+
+func TestEncodeJSONWithoutSortKeys(t *testing.T) {
+	SortKeys = false
+	defer func() { SortKeys = true }()
+
+	out := encode(t, true, []testAgent{{UUID: "1", Name: "zeta", Tools: []string{"b", "a"}}, {UUID: "2", Name: "alpha"}})
+	assert.Greater(t, bytes.Index([]byte(out), []byte("alpha")), bytes.Index([]byte(out), []byte("zeta")))
+	assert.Contains(t, out, "\"uuid\": \"1\",\n    \"name\": \"zeta\"")
+	assert.Contains(t, out, "\"b\",\n      \"a\"")
+}
+
+func TestSortArrayKeepsMixedArrays(t *testing.T) {
+	items := []interface{}{map[string]interface{}{"name": "b"}, map[string]interface{}{"other": "a"}}
+	sortArray(items)
+	assert.Equal(t, "b", items[0].(map[string]interface{})["name"])
+
+	items = []interface{}{map[string]interface{}{"key": "B"}, map[string]interface{}{"key": "A"}}
+	sortArray(items)
+	assert.Equal(t, "A", items[0].(map[string]interface{})["key"])
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Pager sends stdout through a pager program once the output gets taller
+// than the terminal, like git does. Shorter output is written as is.
+type Pager struct {
+	stdout *os.File
+	w      *os.File
+	done   chan struct{}
+}
+
+// PagerCommand returns the pager program from KUBIYA_PAGER or PAGER,
+// defaulting to less. It returns nil when paging is disabled with an empty
+// value or cat.
+func PagerCommand() []string {
+	cmd, ok := os.LookupEnv("KUBIYA_PAGER")
+	if !ok {
+		cmd, ok = os.LookupEnv("PAGER")
+	}
+	if !ok {
+		cmd = "less"
+	}
+
+	args := strings.Fields(cmd)
+	if len(args) == 0 || args[0] == "cat" {
+		return nil
+	}
+	return args
+}
+
+// StartPager redirects os.Stdout through the pager until Close is called.
+// It returns nil when stdout is not a terminal or no pager is available;
+// Close is safe to call on a nil Pager.
+func StartPager() *Pager {
+	if IsCI() || !IsTTY() {
+		return nil
+	}
+	args := PagerCommand()
+	if args == nil {
+		return nil
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil
+	}
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || height <= 0 {
+		return nil
+	}
+
+	p, err := newPager(os.Stdout, args, height)
+	if err != nil {
+		return nil
+	}
+	os.Stdout = p.w
+	return p
+}
+
+// newPager starts reading the output written to p.w, paging it to stdout
+// with args when it has more than height lines
+func newPager(stdout *os.File, args []string, height int) (*Pager, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	p := &Pager{stdout: stdout, w: w, done: make(chan struct{})}
+	go p.run(r, args, height)
+	return p, nil
+}
+
+func (p *Pager) run(r *os.File, args []string, height int) {
+	defer close(p.done)
+	defer r.Close()
+
+	var buf bytes.Buffer
+	lines := 0
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(chunk)
+		buf.Write(chunk[:n])
+		lines += bytes.Count(chunk[:n], []byte("\n"))
+		if lines >= height {
+			p.page(io.MultiReader(&buf, r), args)
+			return
+		}
+		if err != nil {
+			_, _ = p.stdout.Write(buf.Bytes())
+			return
+		}
+	}
+}
+
+// page runs the pager on in, falling back to plain output when it can't be
+// started
+func (p *Pager) page(in io.Reader, args []string) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = in
+	cmd.Stdout = p.stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// Keep colors and leave the output on screen
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+
+	if err := cmd.Start(); err != nil {
+		_, _ = io.Copy(p.stdout, in)
+		return
+	}
+	_ = cmd.Wait()
+
+	// Keep draining so writers don't block after the user quit the pager
+	_, _ = io.Copy(io.Discard, in)
+}
+
+// Close restores os.Stdout and waits for the pager to exit
+func (p *Pager) Close() {
+	if p == nil {
+		return
+	}
+	if os.Stdout == p.w {
+		os.Stdout = p.stdout
+	}
+	_ = p.w.Close()
+	<-p.done
+}
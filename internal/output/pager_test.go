@@ -0,0 +1,62 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("KUBIYA_PAGER", "less -S")
+	assert.Equal(t, []string{"less", "-S"}, PagerCommand())
+
+	t.Setenv("KUBIYA_PAGER", "cat")
+	assert.Nil(t, PagerCommand())
+
+	t.Setenv("KUBIYA_PAGER", "")
+	assert.Nil(t, PagerCommand())
+
+	os.Unsetenv("KUBIYA_PAGER")
+	t.Setenv("PAGER", "more")
+	assert.Equal(t, []string{"more"}, PagerCommand())
+}
+
+func TestPagerOnlyPagesTallOutput(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not available")
+	}
+
+	tests := []struct {
+		name  string
+		lines int
+		want  string
+	}{
+		{"short output is written as is", 3, "line"},
+		{"tall output is paged", 10, "LINE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+			require.NoError(t, err)
+			defer out.Close()
+
+			// tr stands in for the pager so paged output is recognizable
+			p, err := newPager(out, []string{"tr", "a-z", "A-Z"}, 5)
+			require.NoError(t, err)
+			for i := 0; i < tt.lines; i++ {
+				fmt.Fprintf(p.w, "line %d\n", i)
+			}
+			p.Close()
+
+			data, err := os.ReadFile(out.Name())
+			require.NoError(t, err)
+			assert.Equal(t, tt.lines, strings.Count(string(data), tt.want))
+		})
+	}
+}
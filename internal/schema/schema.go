@@ -0,0 +1,193 @@
+// Package schema generates JSON Schemas from the Go structs the CLI decodes
+// its files into, so that editors can validate and complete them. Schemas
+// are built from the structs at run time and therefore always match the
+// binary.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the schema of the JSON encoding of values of type t.
+// Named structs are defined once under $defs and referenced.
+func Generate(t reflect.Type) *Schema {
+	g := &generator{defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+	root := g.schemaOf(t)
+	root.Schema = Draft
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+// OneOrMany returns the schema of files holding either a single value of
+// type t or a list of them
+func OneOrMany(t reflect.Type) *Schema {
+	root := Generate(t)
+	single := &Schema{Ref: root.Ref}
+	if single.Ref == "" {
+		single = &Schema{Type: root.Type, Properties: root.Properties, AdditionalProperties: root.AdditionalProperties}
+	}
+	return &Schema{
+		Schema: root.Schema,
+		AnyOf:  []*Schema{single, {Type: "array", Items: single}},
+		Defs:   root.Defs,
+	}
+}
+
+type generator struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// The encoding is up to the type
+		return &Schema{}
+	case t.Implements(textType) || reflect.PointerTo(t).Implements(textType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"} // base64
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + g.define(t)}
+	default:
+		// Interfaces hold anything
+		return &Schema{}
+	}
+}
+
+// define adds the schema of the named struct t to $defs, once, and returns
+// its name there
+func (g *generator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.defs[name]; taken {
+		// Same name in another package
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + name
+	}
+	g.names[t] = name
+	g.defs[name] = &Schema{} // placeholder for recursive types
+	*g.defs[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the properties of the fields of struct t to s, with
+// embedded structs flattened as encoding/json does: their fields lose to
+// the fields of t of the same name
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schemaOf(field.Type)
+		if hasOption(opts, "string") {
+			prop = &Schema{Type: "string"}
+		}
+		s.Properties[name] = prop
+	}
+
+	for _, et := range embedded {
+		inner := &Schema{Properties: make(map[string]*Schema)}
+		g.addFields(inner, et)
+		for name, prop := range inner.Properties {
+			if _, exists := s.Properties[name]; !exists {
+				s.Properties[name] = prop
+			}
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type base struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type node struct {
+	base
+	Name     string            `json:"title"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Quoted   int               `json:"quoted,string"`
+	Enabled  *bool             `json:"enabled"`
+	Labels   map[string]string `json:"labels"`
+	Children []*node           `json:"children"`
+	Data     []byte            `json:"data"`
+	Extra    interface{}       `json:"extra"`
+	At       time.Time         `json:"at"`
+	Inline   struct {
+		Key string `json:"key"`
+	} `json:"inline"`
+	Skipped string `json:"-"`
+	NoTag   string
+	private string
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(reflect.TypeOf(node{}))
+	assert.Equal(t, Draft, s.Schema)
+	assert.Equal(t, "#/$defs/node", s.Ref)
+	require.Contains(t, s.Defs, "node")
+
+	props := s.Defs["node"].Properties
+	assert.Equal(t, &Schema{Type: "string"}, props["id"], "embedded fields are flattened")
+	assert.Equal(t, &Schema{Type: "string"}, props["name"])
+	assert.Equal(t, &Schema{Type: "string"}, props["title"])
+	assert.Equal(t, &Schema{Type: "integer"}, props["count"])
+	assert.Equal(t, &Schema{Type: "number"}, props["ratio"])
+	assert.Equal(t, &Schema{Type: "string"}, props["quoted"])
+	assert.Equal(t, &Schema{Type: "boolean"}, props["enabled"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, props["labels"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/$defs/node"}}, props["children"], "recursive types are referenced")
+	assert.Equal(t, &Schema{Type: "string", Format: "byte"}, props["data"])
+	assert.Equal(t, &Schema{}, props["extra"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, props["at"])
+	assert.Equal(t, "string", props["inline"].Properties["key"].Type)
+	assert.Contains(t, props, "NoTag")
+	assert.NotContains(t, props, "Skipped")
+	assert.NotContains(t, props, "private")
+}
+
+func TestEmbeddedFieldsLoseToOuterFields(t *testing.T) {
+	type outer struct {
+		base
+		Name int `json:"name"`
+	}
+	s := Generate(reflect.TypeOf(outer{}))
+	assert.Equal(t, "integer", s.Defs["outer"].Properties["name"].Type)
+}
+
+func TestOneOrMany(t *testing.T) {
+	s := OneOrMany(reflect.TypeOf(base{}))
+	require.Len(t, s.AnyOf, 2)
+	assert.Equal(t, "#/$defs/base", s.AnyOf[0].Ref)
+	assert.Equal(t, "array", s.AnyOf[1].Type)
+	assert.Equal(t, "#/$defs/base", s.AnyOf[1].Items.Ref)
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"$schema":"`+Draft+`"`)
+	assert.Contains(t, string(data), `"$defs":{"base":`)
+}
@@ -2,6 +2,7 @@ package sentry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -111,6 +112,27 @@ func CaptureError(err error, tags map[string]string, extras map[string]interface
 		for k, v := range extras {
 			scope.SetExtra(k, v)
 		}
+		captureException(err)
+	})
+}
+
+// captureException captures err with the tags and extras of the SentryErrors
+// it wraps, which are set where the context of the failure is known
+func captureException(err error) {
+	sentry.WithScope(func(scope *sentry.Scope) {
+		for e := err; e != nil; {
+			var serr *SentryError
+			if !errors.As(e, &serr) {
+				break
+			}
+			for k, v := range serr.Tags {
+				scope.SetTag(k, v)
+			}
+			for k, v := range serr.Extras {
+				scope.SetExtra(k, v)
+			}
+			e = serr.Err
+		}
 		sentry.CaptureException(err)
 	})
 }
@@ -211,7 +233,7 @@ func WithTransaction(ctx context.Context, name string, fn func(context.Context)
 
 	if err != nil {
 		span.Status = sentry.SpanStatusInternalError
-		sentry.CaptureException(err)
+		captureException(err)
 	} else {
 		span.Status = sentry.SpanStatusOK
 	}
@@ -271,7 +293,7 @@ func WithMCPTransaction(ctx context.Context, operation string, toolName string,
 			})
 		}
 		
-		sentry.CaptureException(err)
+		captureException(err)
 	} else {
 		span.Status = sentry.SpanStatusOK
 	}
@@ -300,7 +322,7 @@ func WithCLICommand(ctx context.Context, commandName string, args []string, fn f
 	if err != nil {
 		span.Status = sentry.SpanStatusInternalError
 		span.SetTag("error.command", commandName)
-		sentry.CaptureException(err)
+		captureException(err)
 	} else {
 		span.Status = sentry.SpanStatusOK
 	}
@@ -327,7 +349,7 @@ func WithKubiyaChat(ctx context.Context, chatType string, messageCount int, fn f
 	if err != nil {
 		span.Status = sentry.SpanStatusInternalError
 		span.SetTag("error.chat_type", chatType)
-		sentry.CaptureException(err)
+		captureException(err)
 	} else {
 		span.Status = sentry.SpanStatusOK
 	}
@@ -354,7 +376,7 @@ func WithWorkflowExecution(ctx context.Context, workflowName string, workflowID
 	if err != nil {
 		span.Status = sentry.SpanStatusInternalError
 		span.SetTag("error.workflow", workflowName)
-		sentry.CaptureException(err)
+		captureException(err)
 	} else {
 		span.Status = sentry.SpanStatusOK
 	}
@@ -381,7 +403,7 @@ func WithToolExecution(ctx context.Context, toolName string, toolSource string,
 	if err != nil {
 		span.Status = sentry.SpanStatusInternalError
 		span.SetTag("error.tool_name", toolName)
-		sentry.CaptureException(err)
+		captureException(err)
 	} else {
 		span.Status = sentry.SpanStatusOK
 	}
@@ -0,0 +1,222 @@
+package style
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// emojiDisabled is set by DisableEmoji
+var emojiDisabled bool
+
+// EmojiDisabled reports whether output is stripped of emoji
+func EmojiDisabled() bool {
+	return emojiDisabled
+}
+
+// asciiGlyphs are the decorative glyphs that have a plain replacement
+var asciiGlyphs = map[rune]string{
+	'•': "*", '●': "*", '◦': "*", '▪': "*", '‣': "*",
+	'→': "->", '⇒': "=>", '➜': "->", '➔': "->", '←': "<-",
+	'…': "...", '—': "-", '–': "-",
+	'─': "-", '━': "-", '═': "=", '┄': "-", '┈': "-",
+	'│': "|", '┃': "|", '║': "|", '┆': "|", '┊': "|",
+	'█': "#", '▓': "#", '▒': "#", '░': "-",
+}
+
+// plainGlyph returns the ASCII replacement of a decorative glyph, or ""
+// when it has none
+func plainGlyph(r rune) string {
+	if s, ok := asciiGlyphs[r]; ok {
+		return s
+	}
+	switch {
+	case r >= 0x2500 && r <= 0x257F: // box corners and joints
+		return "+"
+	case r >= 0x2580 && r <= 0x259F: // blocks of progress bars
+		return "#"
+	}
+	return ""
+}
+
+// isEmoji reports whether r is an emoji or a decorative glyph without a
+// plain replacement
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, flags
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // arrows
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // ⏱ ⏳ ⌛
+		return true
+	case r >= 0x2500 && r <= 0x27BF: // box drawing, shapes, symbols, dingbats
+		return true
+	case r >= 0x2800 && r <= 0x28FF: // braille spinner frames
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // ⭐ ⬆
+		return true
+	case r == 0x2139 || r == 0x203C || r == 0x2049: // ℹ ‼ ⁉
+		return true
+	}
+	return false
+}
+
+// isEmojiModifier reports whether r only changes how the emoji before it
+// looks: variation selectors, joiners and keycaps
+func isEmojiModifier(r rune) bool {
+	return r == 0xFE0E || r == 0xFE0F || r == 0x200D || r == 0x20E3 ||
+		(r >= 0xE0020 && r <= 0xE007F)
+}
+
+// StripEmoji removes emoji from s and replaces decorative glyphs such as
+// bullets, arrows and box drawing with ASCII. The space after a removed
+// emoji that starts a word is removed too, so "✅ Done" becomes "Done".
+func StripEmoji(s string) string {
+	var b strings.Builder
+	e := &emojiStripper{w: &b, atSpace: true}
+	_, _ = e.Write([]byte(s))
+	e.flush()
+	return b.String()
+}
+
+// emojiStripper strips emoji from the text written to it. Runes split
+// between writes are kept until they are complete.
+type emojiStripper struct {
+	w       io.Writer
+	pending []byte
+	// atSpace is true at the start of a line and after whitespace
+	atSpace bool
+	// dropSpace removes the space that separated a removed emoji from the
+	// text after it
+	dropSpace bool
+	// escape tracks ANSI escape sequences, which are copied as they are
+	escape int
+}
+
+func (e *emojiStripper) Write(p []byte) (int, error) {
+	buf := append(e.pending, p...)
+	e.pending = nil
+
+	out := make([]byte, 0, len(buf))
+	for i := 0; i < len(buf); {
+		c := buf[i]
+		// ESC [ ... final byte; colors must not reset the word state
+		if e.escape > 0 || c == 0x1b {
+			out = append(out, c)
+			i++
+			switch {
+			case c == 0x1b:
+				e.escape = 1
+			case e.escape == 1 && c == '[':
+				e.escape = 2
+			case e.escape == 1 || (c >= 0x40 && c <= 0x7e):
+				e.escape = 0
+			}
+			continue
+		}
+
+		if !utf8.FullRune(buf[i:]) {
+			e.pending = append([]byte(nil), buf[i:]...)
+			break
+		}
+		r, size := utf8.DecodeRune(buf[i:])
+		i += size
+
+		switch {
+		case isEmojiModifier(r):
+		case plainGlyph(r) != "":
+			out = append(out, plainGlyph(r)...)
+			e.atSpace, e.dropSpace = false, false
+		case isEmoji(r):
+			e.dropSpace = e.atSpace
+		case r == ' ' && e.dropSpace:
+			e.dropSpace = false
+		default:
+			out = append(out, buf[i-size:i]...)
+			e.atSpace = r == ' ' || r == '\t' || r == '\n' || r == '\r'
+			e.dropSpace = false
+		}
+	}
+
+	if _, err := e.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// flush writes an incomplete rune left at the end of the output
+func (e *emojiStripper) flush() {
+	if len(e.pending) > 0 {
+		_, _ = e.w.Write(e.pending)
+		e.pending = nil
+	}
+}
+
+// emojiFilter passes everything written to w through an emojiStripper to
+// out
+type emojiFilter struct {
+	out  *os.File
+	w    *os.File
+	done chan struct{}
+}
+
+func newEmojiFilter(out *os.File) (*emojiFilter, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	f := &emojiFilter{out: out, w: w, done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer r.Close()
+		e := &emojiStripper{w: out, atSpace: true}
+		_, _ = io.Copy(e, r)
+		e.flush()
+	}()
+	return f, nil
+}
+
+func (f *emojiFilter) close() {
+	_ = f.w.Close()
+	<-f.done
+}
+
+// DisableEmoji strips emoji and decorative glyphs from everything written
+// to stderr and, unless it carries machine-readable output that must be
+// written as is, to stdout. It is meant for terminals and log viewers that
+// render emoji poorly, such as Jenkins logs and serial consoles. The
+// returned function restores the files once all output has been written.
+func DisableEmoji(stdout bool) (restore func()) {
+	emojiDisabled = true
+
+	files := []**os.File{&os.Stderr}
+	if stdout {
+		files = append(files, &os.Stdout)
+	}
+	var filters []*emojiFilter
+	for _, file := range files {
+		f, err := newEmojiFilter(*file)
+		if err != nil {
+			continue
+		}
+		*file = f.w
+		filters = append(filters, f)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, f := range filters {
+				if os.Stdout == f.w {
+					os.Stdout = f.out
+				}
+				if os.Stderr == f.w {
+					os.Stderr = f.out
+				}
+				f.close()
+			}
+		})
+	}
+}
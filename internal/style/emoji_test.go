@@ -0,0 +1,42 @@
+package style
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripEmoji(t *testing.T) {
+	tests := map[string]string{
+		"✅ Agent created":                  "Agent created",
+		"⚠️ config: unknown key":           "config: unknown key",
+		"Status: ❌ failed":                 "Status: failed",
+		"  📌 Pinned abc to 4f2c1ab":        "  Pinned abc to 4f2c1ab",
+		"Done ✓":                           "Done ",
+		"👩‍💻 ZWJ sequence":                 "ZWJ sequence",
+		"  • first → second…":              "  * first -> second...",
+		"╭──╮\n│ok│\n╰──╯":                 "+--+\n|ok|\n+--+",
+		"[████░░]":                         "[####--]",
+		"\x1b[1;32m✅\x1b[0m Done":          "\x1b[1;32m\x1b[0mDone",
+		"Naïve café – ünïcode stays":       "Naïve café - ünïcode stays",
+		"plain ASCII is left alone: 2 > 1": "plain ASCII is left alone: 2 > 1",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, StripEmoji(in), in)
+	}
+}
+
+func TestEmojiStripperSplitWrites(t *testing.T) {
+	var out bytes.Buffer
+	e := &emojiStripper{w: &out, atSpace: true}
+	in := []byte("🚀 Deploying\n✅ Done\n")
+	// Write byte by byte so that every multi-byte rune is split
+	for i := range in {
+		n, err := e.Write(in[i : i+1])
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	e.flush()
+	assert.Equal(t, "Deploying\nDone\n", out.String())
+}
@@ -197,6 +197,10 @@ var (
 	OutputStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("147"))
 
+	// StderrStyle marks output a tool wrote to stderr
+	StderrStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("209"))
+
 	SectionStyle = lipgloss.NewStyle().
 			Bold(true).
 			Foreground(lipgloss.Color("#60A5FA")).
@@ -250,6 +254,7 @@ func DisableColors() {
 	noStyle := lipgloss.NewStyle()
 
 	// Reset all styles to no-op
+	HeaderStyle = noStyle
 	TitleStyle = noStyle
 	SubtitleStyle = noStyle
 	HighlightStyle = noStyle
@@ -297,6 +302,7 @@ func DisableColors() {
 	ChatStyle = noStyle
 	InfoStyle = noStyle
 	OutputStyle = noStyle
+	StderrStyle = noStyle
 	SectionStyle = noStyle
 	ToolExecutingStyle = noStyle
 	ToolRunningStyle = noStyle
@@ -305,6 +311,8 @@ func DisableColors() {
 	ToolBoxStyle = noStyle
 	ProgressDotStyle = noStyle
 	LiveStatusStyle = noStyle
+	AnimationStyle = noStyle
+	CompletionStyle = noStyle
 }
 
 // Add this function to check if colors should be enabled
@@ -0,0 +1,40 @@
+// Package suggest finds the closest of a set of names to a misspelled one,
+// for "did you mean" hints
+package suggest
+
+import "strings"
+
+// Closest returns the candidate closest to s by edit distance, ignoring
+// case, or "" when none is close enough to be a plausible typo. Ties go to
+// the candidate listed first.
+func Closest(s string, candidates []string) string {
+	s = strings.ToLower(s)
+	best, bestDist := "", len(s)/2+1
+	for _, c := range candidates {
+		if d := Distance(s, strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// Distance is the Levenshtein distance between a and b
+func Distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
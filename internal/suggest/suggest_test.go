@@ -0,0 +1,38 @@
+package suggest
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"verbose", "output", "Debug"}
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"verbos", "verbose"},
+		{"OUPUT", "output"},
+		{"debgu", "Debug"},
+		{"timeout", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Closest(tt.s, candidates); got != tt.want {
+			t.Errorf("Closest(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"same", "same", 0},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package tfexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// Resource types of the Kubiya Terraform provider
+const (
+	AgentType   = "kubiya_agent"
+	SourceType  = "kubiya_source"
+	WebhookType = "kubiya_webhook"
+)
+
+// providerSource is the registry address of the Kubiya Terraform provider
+const providerSource = "kubiya-terraform/kubiya"
+
+// Export is a set of resources to write as Terraform configuration
+type Export struct {
+	Sources  []*Resource
+	Agents   []*Resource
+	Webhooks []*Resource
+}
+
+// Build maps agents, sources and webhooks to resources. Agents refer to the
+// exported sources and webhooks to the exported agents, so Terraform knows
+// the order to manage them in.
+func Build(agents []kubiya.Agent, sources []kubiya.Source, webhooks []kubiya.Webhook) (*Export, error) {
+	names := namer{}
+	e := &Export{}
+
+	sourceRefs := map[string]Expr{}
+	for _, s := range sortedSources(sources) {
+		r, err := sourceResource(names, s)
+		if err != nil {
+			return nil, err
+		}
+		sourceRefs[s.UUID] = r.Ref("id")
+		e.Sources = append(e.Sources, r)
+	}
+
+	agentRefs := map[string]Expr{}
+	for _, a := range sortedAgents(agents) {
+		r := agentResource(names, a, sourceRefs)
+		agentRefs[agentID(a)] = r.Ref("name")
+		e.Agents = append(e.Agents, r)
+	}
+
+	for _, w := range sortedWebhooks(webhooks) {
+		e.Webhooks = append(e.Webhooks, webhookResource(names, w, agentRefs))
+	}
+	return e, nil
+}
+
+// Resources returns all resources of the export
+func (e *Export) Resources() []*Resource {
+	all := append([]*Resource{}, e.Sources...)
+	all = append(all, e.Agents...)
+	return append(all, e.Webhooks...)
+}
+
+// Files returns the configuration by file name: versions.tf, one file per
+// resource type and imports.tf
+func (e *Export) Files() (map[string][]byte, error) {
+	files := map[string][]byte{
+		"versions.tf": []byte(fmt.Sprintf(`terraform {
+  required_providers {
+    kubiya = {
+      source = %q
+    }
+  }
+}
+
+provider "kubiya" {}
+`, providerSource)),
+	}
+
+	for name, resources := range map[string][]*Resource{
+		"sources.tf":  e.Sources,
+		"agents.tf":   e.Agents,
+		"webhooks.tf": e.Webhooks,
+	} {
+		if len(resources) == 0 {
+			continue
+		}
+		var buf bytes.Buffer
+		for i, r := range resources {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			if err := r.WriteHCL(&buf); err != nil {
+				return nil, err
+			}
+		}
+		files[name] = buf.Bytes()
+	}
+
+	var imports bytes.Buffer
+	imports.WriteString("# Import blocks adopt the existing resources on the first apply (Terraform 1.5+ or OpenTofu).\n")
+	imports.WriteString("# They can be removed once the resources are in the state.\n")
+	for _, r := range e.Resources() {
+		imports.WriteString("\n")
+		if err := r.WriteImport(&imports); err != nil {
+			return nil, err
+		}
+	}
+	files["imports.tf"] = imports.Bytes()
+	return files, nil
+}
+
+// WriteDir writes the configuration files to dir. Existing files are only
+// replaced with overwrite.
+func (e *Export) WriteDir(dir string, overwrite bool) ([]string, error) {
+	files, err := e.Files()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !overwrite {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return nil, fmt.Errorf("%s already exists (use --force to overwrite)", filepath.Join(dir, name))
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var written []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+func sourceResource(names namer, s kubiya.Source) (*Resource, error) {
+	name := s.Name
+	if name == "" {
+		name = s.UUID
+	}
+	r := &Resource{Type: SourceType, Name: names.name(SourceType, name), ID: s.UUID}
+	r.Attrs = []Attr{
+		{"name", s.Name},
+		{"url", s.URL},
+		{"runner", s.Runner},
+	}
+
+	if len(s.DynamicConfig) > 0 {
+		config, err := json.Marshal(s.DynamicConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dynamic config of source %s: %w", s.UUID, err)
+		}
+		r.Attrs = append(r.Attrs, Attr{"dynamic_config", jsonExpr(config)})
+	}
+	if s.URL == "" && len(s.InlineTools) > 0 {
+		tools, err := json.MarshalIndent(s.InlineTools, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("invalid tools of source %s: %w", s.UUID, err)
+		}
+		r.Attrs = append(r.Attrs, Attr{"tools", jsonExpr(tools)})
+	}
+	return r, nil
+}
+
+func agentResource(names namer, a kubiya.Agent, sourceRefs map[string]Expr) *Resource {
+	r := &Resource{Type: AgentType, Name: names.name(AgentType, a.Name), ID: agentID(a)}
+
+	var sources []Expr
+	var unknownSources []string
+	for _, uuid := range a.Sources {
+		if ref, ok := sourceRefs[uuid]; ok {
+			sources = append(sources, ref)
+		} else {
+			unknownSources = append(unknownSources, uuid)
+		}
+	}
+	for _, uuid := range unknownSources {
+		sources = append(sources, Expr(quote(uuid)))
+	}
+	if len(unknownSources) > 0 {
+		r.Comment = "Some sources of this agent were not exported and are referenced by ID"
+	}
+
+	runner := ""
+	if len(a.Runners) > 0 {
+		runner = a.Runners[0]
+	}
+	r.Attrs = []Attr{
+		{"name", a.Name},
+		{"description", a.Description},
+		{"instructions", a.AIInstructions},
+		{"model", a.LLMModel},
+		{"runner", runner},
+		{"image", a.Image},
+		{"sources", sources},
+		{"secrets", a.Secrets},
+		{"integrations", a.Integrations},
+		{"users", a.AllowedUsers},
+		{"groups", a.AllowedGroups},
+		{"links", a.Links},
+		{"environment_variables", a.Environment},
+		{"is_debug_mode", a.IsDebugMode},
+	}
+	return r
+}
+
+func webhookResource(names namer, w kubiya.Webhook, agentRefs map[string]Expr) *Resource {
+	r := &Resource{Type: WebhookType, Name: names.name(WebhookType, w.Name), ID: w.ID}
+
+	agent, ok := agentRefs[w.AgentID]
+	if !ok && w.AgentID != "" {
+		agent = Expr(quote(w.AgentID))
+		r.Comment = "The agent of this webhook was not exported and is referenced by ID"
+	}
+	r.Attrs = []Attr{
+		{"name", w.Name},
+		{"source", w.Source},
+		{"agent", agent},
+		{"prompt", w.Prompt},
+		{"filter", w.Filter},
+		{"method", w.Communication.Method},
+		{"destination", w.Communication.Destination},
+		{"runner", w.Runner},
+	}
+	return r
+}
+
+func agentID(a kubiya.Agent) string {
+	if a.UUID != "" {
+		return a.UUID
+	}
+	return a.ID
+}
+
+// jsonExpr returns a JSON document as a jsonencode() expression. JSON is
+// valid HCL once template sequences in its strings are escaped.
+func jsonExpr(data []byte) Expr {
+	doc := strings.NewReplacer("${", "$${", "%{", "%%{", "\n", "\n  ").Replace(string(data))
+	return Expr("jsonencode(" + doc + ")")
+}
+
+// The sorted helpers give stable output, and stable names when resources
+// share a name
+
+func sortedSources(sources []kubiya.Source) []kubiya.Source {
+	sorted := append([]kubiya.Source{}, sources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].UUID < sorted[j].UUID
+	})
+	return sorted
+}
+
+func sortedAgents(agents []kubiya.Agent) []kubiya.Agent {
+	sorted := append([]kubiya.Agent{}, agents...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return agentID(sorted[i]) < agentID(sorted[j])
+	})
+	return sorted
+}
+
+func sortedWebhooks(webhooks []kubiya.Webhook) []kubiya.Webhook {
+	sorted := append([]kubiya.Webhook{}, webhooks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
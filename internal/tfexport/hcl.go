@@ -0,0 +1,191 @@
+// Package tfexport turns Kubiya resources into HCL for the Kubiya Terraform
+// provider, with import blocks so existing resources can be brought under
+// Terraform (or OpenTofu) without being recreated.
+package tfexport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Expr is a raw HCL expression, such as a reference to another resource
+type Expr string
+
+// Attr is an attribute of a resource. Value is a string, bool, Expr,
+// []string, []Expr or map[string]string; empty values are not written.
+type Attr struct {
+	Name  string
+	Value interface{}
+}
+
+// Resource is a Terraform resource block and the ID it is imported from
+type Resource struct {
+	Type  string
+	Name  string
+	ID    string
+	Attrs []Attr
+	// Comment is written above the block
+	Comment string
+}
+
+// Address returns the resource address, e.g. kubiya_agent.ops
+func (r *Resource) Address() string {
+	return r.Type + "." + r.Name
+}
+
+// Ref returns a reference to an attribute of the resource
+func (r *Resource) Ref(attr string) Expr {
+	return Expr(r.Address() + "." + attr)
+}
+
+// WriteHCL writes the resource block
+func (r *Resource) WriteHCL(w io.Writer) error {
+	var b strings.Builder
+	if r.Comment != "" {
+		for _, line := range strings.Split(r.Comment, "\n") {
+			fmt.Fprintf(&b, "# %s\n", line)
+		}
+	}
+	fmt.Fprintf(&b, "resource %q %q {\n", r.Type, r.Name)
+
+	width := 0
+	for _, a := range r.Attrs {
+		if !isEmpty(a.Value) && len(a.Name) > width {
+			width = len(a.Name)
+		}
+	}
+	for _, a := range r.Attrs {
+		if isEmpty(a.Value) {
+			continue
+		}
+		fmt.Fprintf(&b, "  %-*s = %s\n", width, a.Name, formatValue(a.Value, "  "))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteImport writes the import block of the resource
+func (r *Resource) WriteImport(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "import {\n  to = %s\n  id = %s\n}\n", r.Address(), quote(r.ID))
+	return err
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case Expr:
+		return v == ""
+	case bool:
+		return !v
+	case []string:
+		return len(v) == 0
+	case []Expr:
+		return len(v) == 0
+	case map[string]string:
+		return len(v) == 0
+	}
+	return false
+}
+
+func formatValue(v interface{}, indent string) string {
+	switch v := v.(type) {
+	case string:
+		return formatString(v)
+	case Expr:
+		return string(v)
+	case bool:
+		return fmt.Sprint(v)
+	case []string:
+		items := make([]string, len(v))
+		for i, s := range v {
+			items[i] = quote(s)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []Expr:
+		items := make([]string, len(v))
+		for i, e := range v {
+			items[i] = string(e)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		width := 0
+		for k := range v {
+			keys = append(keys, k)
+			if len(quote(k)) > width {
+				width = len(quote(k))
+			}
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s  %-*s = %s\n", indent, width, quote(k), quote(v[k]))
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+	}
+	return quote(fmt.Sprint(v))
+}
+
+// quote returns s as an HCL string literal. Template sequences are escaped
+// so values are written literally.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			b.WriteString(`\"`)
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case (c == '$' || c == '%') && i+1 < len(s) && s[i+1] == '{':
+			b.WriteByte(c)
+			b.WriteByte(c)
+		case c < 0x20:
+			fmt.Fprintf(&b, `\u%04x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// formatString writes multi-line strings as heredocs, which end with a
+// newline: strings that do not are wrapped in chomp() so they round-trip
+func formatString(s string) string {
+	body := strings.TrimSuffix(s, "\n")
+	if !strings.Contains(body, "\n") || strings.HasSuffix(body, "\n") ||
+		strings.Contains(s, "\r") || containsLine(s, "EOT") {
+		return quote(s)
+	}
+	doc := "<<EOT\n" + strings.NewReplacer("${", "$${", "%{", "%%{").Replace(body) + "\nEOT"
+	if body == s {
+		return "chomp(" + doc + "\n)"
+	}
+	return doc
+}
+
+func containsLine(s, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package tfexport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SanitizeName turns a resource name into a Terraform identifier: lower
+// case letters, digits, underscores and dashes, starting with a letter or an
+// underscore
+func SanitizeName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+			underscore = false
+		case !underscore:
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+
+	id := strings.Trim(b.String(), "_-")
+	if id == "" {
+		return "unnamed"
+	}
+	if id[0] >= '0' && id[0] <= '9' || id[0] == '-' {
+		id = "_" + id
+	}
+	return id
+}
+
+// namer hands out unique identifiers per resource type
+type namer map[string]map[string]bool
+
+func (n namer) name(resourceType, name string) string {
+	if n[resourceType] == nil {
+		n[resourceType] = map[string]bool{}
+	}
+	base := SanitizeName(name)
+	id := base
+	for i := 2; n[resourceType][id]; i++ {
+		id = fmt.Sprintf("%s_%d", base, i)
+	}
+	n[resourceType][id] = true
+	return id
+}
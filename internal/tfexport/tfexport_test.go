@@ -0,0 +1,138 @@
+package tfexport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+func TestSanitizeName(t *testing.T) {
+	for name, want := range map[string]string{
+		"DevOps Bot":      "devops_bot",
+		"k8s-tools":       "k8s-tools",
+		"  Jira / Triage": "jira_triage",
+		"123 agent":       "_123_agent",
+		"🚀":               "unnamed",
+	} {
+		if got := SanitizeName(name); got != want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	n := namer{}
+	if a, b := n.name(AgentType, "ops"), n.name(AgentType, "Ops"); a != "ops" || b != "ops_2" {
+		t.Errorf("duplicate names = %q, %q, want ops, ops_2", a, b)
+	}
+	if s := n.name(SourceType, "ops"); s != "ops" {
+		t.Errorf("names are unique per type, got %q", s)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for s, want := range map[string]string{
+		`say "hi"`:       `"say \"hi\""`,
+		"${var.x} %{if}": `"$${var.x} %%{if}"`,
+		`C:\tmp`:         `"C:\\tmp"`,
+		"$HOME":          `"$HOME"`,
+	} {
+		if got := quote(s); got != want {
+			t.Errorf("quote(%q) = %s, want %s", s, got, want)
+		}
+	}
+
+	if got := formatString("line one\nline ${two}\n"); got != "<<EOT\nline one\nline $${two}\nEOT" {
+		t.Errorf("heredoc = %q", got)
+	}
+	if got := formatString("a\nb"); got != "chomp(<<EOT\na\nb\nEOT\n)" {
+		t.Errorf("heredoc without trailing newline = %q", got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	sources := []kubiya.Source{
+		{UUID: "src-1", Name: "K8s Tools", URL: "https://github.com/org/tools", DynamicConfig: map[string]interface{}{"cluster": "${prod}"}},
+	}
+	agents := []kubiya.Agent{{
+		UUID:           "agent-1",
+		Name:           "DevOps Bot",
+		LLMModel:       "azure/gpt-4",
+		AIInstructions: "Be careful.\nAsk before deleting.",
+		Sources:        []string{"src-1", "src-unknown"},
+		Secrets:        []string{"GH_TOKEN"},
+		Environment:    map[string]string{"LOG_LEVEL": "debug"},
+	}}
+	webhooks := []kubiya.Webhook{
+		{ID: "wh-1", Name: "alerts", Source: "datadog", AgentID: "agent-1", Prompt: "Triage {{.event.title}}",
+			Communication: kubiya.Communication{Method: "Slack", Destination: "#ops"}},
+		{ID: "wh-2", Name: "orphan", AgentID: "agent-gone"},
+	}
+
+	export, err := Build(agents, sources, webhooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := export.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for file, wants := range map[string][]string{
+		"sources.tf": {
+			`resource "kubiya_source" "k8s_tools" {`,
+			`url            = "https://github.com/org/tools"`,
+			`dynamic_config = jsonencode({"cluster":"$${prod}"})`,
+		},
+		"agents.tf": {
+			`resource "kubiya_agent" "devops_bot" {`,
+			"instructions          = chomp(<<EOT\nBe careful.\nAsk before deleting.\nEOT\n)",
+			`sources               = [kubiya_source.k8s_tools.id, "src-unknown"]`,
+			`"LOG_LEVEL" = "debug"`,
+			"# Some sources of this agent were not exported",
+		},
+		"webhooks.tf": {
+			`agent       = kubiya_agent.devops_bot.name`,
+			`agent = "agent-gone"`,
+			`destination = "#ops"`,
+		},
+		"imports.tf": {
+			"import {\n  to = kubiya_agent.devops_bot\n  id = \"agent-1\"\n}",
+			"to = kubiya_webhook.orphan",
+		},
+		"versions.tf": {`source = "kubiya-terraform/kubiya"`},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(string(files[file]), want) {
+				t.Errorf("%s does not contain %q:\n%s", file, want, files[file])
+			}
+		}
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tf")
+	export, err := Build([]kubiya.Agent{{UUID: "a", Name: "ops"}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := export.WriteDir(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 3 {
+		t.Errorf("wrote %v, want agents.tf, imports.tf and versions.tf", written)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "webhooks.tf")); err == nil {
+		t.Error("webhooks.tf written without webhooks")
+	}
+
+	if _, err := export.WriteDir(dir, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected existing files to be kept, got %v", err)
+	}
+	if _, err := export.WriteDir(dir, true); err != nil {
+		t.Errorf("overwrite failed: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package toolparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoRecords is returned when output holds no records in a parser's format
+var ErrNoRecords = errors.New("no records found")
+
+// Parser turns tool output into a table
+type Parser interface {
+	Parse(output string) (*Table, error)
+}
+
+// ParserFunc adapts a function to the Parser interface
+type ParserFunc func(output string) (*Table, error)
+
+// Parse calls f
+func (f ParserFunc) Parse(output string) (*Table, error) {
+	return f(output)
+}
+
+// Text returns the text carried by tool output. Tool results arrive JSON
+// encoded, so a JSON string is decoded, as is an object whose only text is in
+// an output or stdout field.
+func Text(output string) string {
+	output = strings.TrimSpace(output)
+	var s string
+	if err := json.Unmarshal([]byte(output), &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	var result struct {
+		Output string `json:"output"`
+		Stdout string `json:"stdout"`
+	}
+	if strings.HasPrefix(output, "{") && json.Unmarshal([]byte(output), &result) == nil {
+		if result.Output != "" {
+			return strings.TrimSpace(result.Output)
+		}
+		if result.Stdout != "" {
+			return strings.TrimSpace(result.Stdout)
+		}
+	}
+	return output
+}
+
+// parseJSON reads an array of objects, an object with an items array (as
+// kubectl -o json prints) or a single object
+func parseJSON(output string) (*Table, error) {
+	data := []byte(Text(output))
+	var probe interface{}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var raws []json.RawMessage
+	switch v := probe.(type) {
+	case []interface{}:
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return nil, err
+		}
+	case map[string]interface{}:
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if _, ok := v["items"].([]interface{}); ok && json.Unmarshal(data, &list) == nil {
+			raws = list.Items
+		} else {
+			raws = []json.RawMessage{data}
+		}
+	default:
+		return nil, ErrNoRecords
+	}
+	return tableFromJSON(raws)
+}
+
+// parseNDJSON reads one JSON object per line
+func parseNDJSON(output string) (*Table, error) {
+	var raws []json.RawMessage
+	for _, line := range strings.Split(Text(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "{") || !json.Valid([]byte(line)) {
+			return nil, fmt.Errorf("line is not a JSON object: %.40s", line)
+		}
+		raws = append(raws, json.RawMessage(line))
+	}
+	return tableFromJSON(raws)
+}
+
+func tableFromJSON(raws []json.RawMessage) (*Table, error) {
+	t := &Table{}
+	for _, raw := range raws {
+		var row map[string]interface{}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return nil, ErrNoRecords
+		}
+		t.addRow(row, jsonKeys(raw))
+	}
+	if len(t.Rows) == 0 {
+		return nil, ErrNoRecords
+	}
+	return t, nil
+}
+
+// jsonKeys returns the keys of a JSON object in document order
+func jsonKeys(raw json.RawMessage) []string {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			break
+		}
+	}
+	return keys
+}
+
+// parseYAML reads a list of mappings or a mapping with an items list. Plain
+// text is valid YAML, so anything else is rejected.
+func parseYAML(output string) (*Table, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(Text(output)), &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	list := doc.Content[0]
+	if list.Kind == yaml.MappingNode {
+		list = yamlValue(list, "items")
+	}
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil, ErrNoRecords
+	}
+
+	t := &Table{}
+	for _, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, ErrNoRecords
+		}
+		var row map[string]interface{}
+		if err := item.Decode(&row); err != nil {
+			return nil, err
+		}
+		var keys []string
+		for i := 0; i < len(item.Content); i += 2 {
+			keys = append(keys, item.Content[i].Value)
+		}
+		t.addRow(row, keys)
+	}
+	if len(t.Rows) == 0 {
+		return nil, ErrNoRecords
+	}
+	return t, nil
+}
+
+func yamlValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// parseRecords reads JSON or newline delimited JSON, as written by command
+// parsers
+func parseRecords(r io.Reader) (*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if t, err := parseJSON(string(data)); err == nil {
+		return t, nil
+	}
+	return parseNDJSON(string(data))
+}
@@ -0,0 +1,233 @@
+package toolparse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Auto selects the parser from the tool name and the output
+const Auto = "auto"
+
+// commandTimeout bounds how long a command parser may run
+const commandTimeout = 10 * time.Second
+
+// Registry holds the parsers available to chat
+type Registry struct {
+	parsers map[string]Parser
+	// auto is the order parsers are tried in by Auto
+	auto []string
+	// tools maps tool name patterns to the parser preferred for them
+	tools []toolParser
+}
+
+type toolParser struct {
+	pattern *regexp.Regexp
+	parser  string
+}
+
+// NewRegistry returns a registry with the built-in json, ndjson and yaml
+// parsers
+func NewRegistry() *Registry {
+	r := &Registry{parsers: make(map[string]Parser)}
+	r.Register("json", ParserFunc(parseJSON))
+	r.Register("ndjson", ParserFunc(parseNDJSON))
+	r.Register("yaml", ParserFunc(parseYAML))
+	r.auto = []string{"json", "ndjson", "yaml"}
+	return r
+}
+
+// Register adds or replaces a parser. With tool patterns, Auto tries the
+// parser first for the tools whose name matches one of them.
+func (r *Registry) Register(name string, p Parser, tools ...*regexp.Regexp) {
+	r.parsers[name] = p
+	for _, re := range tools {
+		r.tools = append(r.tools, toolParser{pattern: re, parser: name})
+	}
+}
+
+// Get returns the parser registered as name
+func (r *Registry) Get(name string) (Parser, bool) {
+	p, ok := r.parsers[name]
+	return p, ok
+}
+
+// Names returns the names of the registered parsers, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.parsers))
+	for name := range r.parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse parses the output of tool with the named parser, or with Auto the
+// first one that finds records. It returns the name of the parser used.
+func (r *Registry) Parse(name, tool, output string) (*Table, string, error) {
+	if name != Auto {
+		p, ok := r.parsers[name]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown output parser %q (available: %s)", name, strings.Join(r.Names(), ", "))
+		}
+		t, err := p.Parse(output)
+		return t, name, err
+	}
+
+	var candidates []string
+	for _, tp := range r.tools {
+		if tp.pattern.MatchString(tool) && !containsString(candidates, tp.parser) {
+			candidates = append(candidates, tp.parser)
+		}
+	}
+	candidates = append(candidates, r.auto...)
+	for _, candidate := range candidates {
+		if t, err := r.parsers[candidate].Parse(output); err == nil && len(t.Rows) > 0 {
+			return t, candidate, nil
+		}
+	}
+	return nil, "", ErrNoRecords
+}
+
+// Definition is a custom parser read from the parsers directory
+type Definition struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Tools are patterns of the tool names Auto uses the parser for
+	Tools []string `yaml:"tools,omitempty"`
+	// Pattern is a regular expression with named groups matched against
+	// each line; the groups become the columns
+	Pattern string `yaml:"pattern,omitempty"`
+	// Skip is the number of leading lines (e.g. a header) to ignore
+	Skip int `yaml:"skip,omitempty"`
+	// Command receives the output on stdin and writes JSON or newline
+	// delimited JSON objects to stdout
+	Command []string `yaml:"command,omitempty"`
+}
+
+// LoadDir registers the parsers defined by the *.yaml files of dir. A
+// missing directory is not an error.
+func (r *Registry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	more, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	files = append(files, more...)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read parser %s: %w", file, err)
+		}
+		var def Definition
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("invalid parser %s: %w", file, err)
+		}
+		if def.Name == "" {
+			def.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		if err := r.RegisterDefinition(def); err != nil {
+			return fmt.Errorf("invalid parser %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// RegisterDefinition registers a custom parser
+func (r *Registry) RegisterDefinition(def Definition) error {
+	if (def.Pattern == "") == (len(def.Command) == 0) {
+		return fmt.Errorf("parser %q needs either a pattern or a command", def.Name)
+	}
+
+	var tools []*regexp.Regexp
+	for _, t := range def.Tools {
+		re, err := regexp.Compile(t)
+		if err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", t, err)
+		}
+		tools = append(tools, re)
+	}
+
+	if len(def.Command) > 0 {
+		r.Register(def.Name, commandParser(def.Command), tools...)
+		return nil
+	}
+	re, err := regexp.Compile(def.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	columns := groupNames(re)
+	if len(columns) == 0 {
+		return fmt.Errorf("pattern %q has no named groups", def.Pattern)
+	}
+	r.Register(def.Name, patternParser(re, columns, def.Skip), tools...)
+	return nil
+}
+
+func groupNames(re *regexp.Regexp) []string {
+	var names []string
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// patternParser makes a row of every line matching re
+func patternParser(re *regexp.Regexp, columns []string, skip int) Parser {
+	return ParserFunc(func(output string) (*Table, error) {
+		lines := strings.Split(Text(output), "\n")
+		if skip < len(lines) {
+			lines = lines[skip:]
+		} else {
+			lines = nil
+		}
+
+		t := &Table{}
+		for _, line := range lines {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			row := make(map[string]interface{}, len(columns))
+			for i, name := range re.SubexpNames() {
+				if name != "" {
+					row[name] = m[i]
+				}
+			}
+			t.addRow(row, columns)
+		}
+		if len(t.Rows) == 0 {
+			return nil, ErrNoRecords
+		}
+		return t, nil
+	})
+}
+
+// commandParser pipes the output through an external command
+func commandParser(command []string) Parser {
+	return ParserFunc(func(output string) (*Table, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(Text(output))
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("parser command %s failed: %w: %s", command[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return parseRecords(&stdout)
+	})
+}
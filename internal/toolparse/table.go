@@ -0,0 +1,108 @@
+// Package toolparse turns the output of tools (JSON, YAML, newline delimited
+// JSON or text matched by custom parsers) into tables, so chat can render it
+// as such and export it as structured objects.
+package toolparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxCellWidth bounds the width of a rendered cell
+const maxCellWidth = 60
+
+// Table is parsed tool output: one row per record
+type Table struct {
+	// Columns in the order they first appeared in the output
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+// addRow appends a record and the columns it introduces
+func (t *Table) addRow(row map[string]interface{}, keys []string) {
+	for _, k := range keys {
+		if !containsString(t.Columns, k) {
+			t.Columns = append(t.Columns, k)
+		}
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// Select returns a table with only the given columns. A column may be a
+// dotted path into nested objects, e.g. metadata.name.
+func (t *Table) Select(columns []string) *Table {
+	selected := &Table{Columns: columns}
+	for _, row := range t.Rows {
+		out := make(map[string]interface{}, len(columns))
+		for _, c := range columns {
+			out[c] = lookup(row, c)
+		}
+		selected.Rows = append(selected.Rows, out)
+	}
+	return selected
+}
+
+// Render writes the table with a header of upper-cased column names
+func (t *Table) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range t.Rows {
+		cells := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			cells[i] = formatCell(row[c])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func lookup(row map[string]interface{}, path string) interface{} {
+	if v, ok := row[path]; ok {
+		return v
+	}
+	var current interface{} = row
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+func formatCell(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		s = v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		s = string(data)
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxCellWidth {
+		s = s[:maxCellWidth-3] + "..."
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
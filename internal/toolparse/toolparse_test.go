@@ -0,0 +1,82 @@
+package toolparse
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuiltins(t *testing.T) {
+	r := NewRegistry()
+
+	table, parser, err := r.Parse(Auto, "kubectl", `"{\"items\":[{\"name\":\"web\",\"ready\":true},{\"name\":\"db\",\"ready\":false,\"restarts\":3}]}"`)
+	require.NoError(t, err)
+	assert.Equal(t, "json", parser)
+	assert.Equal(t, []string{"name", "ready", "restarts"}, table.Columns)
+	assert.Len(t, table.Rows, 2)
+
+	table, parser, err = r.Parse(Auto, "logs", "{\"level\":\"info\",\"msg\":\"a\"}\n{\"level\":\"warn\",\"msg\":\"b\"}\n")
+	require.NoError(t, err)
+	assert.Equal(t, "ndjson", parser)
+	assert.Equal(t, []string{"level", "msg"}, table.Columns)
+
+	table, parser, err = r.Parse(Auto, "helm", "- name: ingress\n  chart: nginx\n- name: redis\n  chart: redis\n")
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", parser)
+	assert.Equal(t, "redis", table.Rows[1]["name"])
+
+	_, _, err = r.Parse(Auto, "echo", `"all pods are running"`)
+	assert.ErrorIs(t, err, ErrNoRecords)
+
+	_, _, err = r.Parse("csv", "echo", "a,b")
+	assert.ErrorContains(t, err, "unknown output parser")
+}
+
+func TestTableSelectAndRender(t *testing.T) {
+	table, err := parseJSON(`[{"metadata":{"name":"web"},"status":{"phase":"Running"},"note":"a   very long\nnote"}]`)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, table.Select([]string{"metadata.name", "status.phase", "missing", "note"}).Render(&out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"METADATA.NAME", "STATUS.PHASE", "MISSING", "NOTE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"web", "Running", "-", "a", "very", "long", "note"}, strings.Fields(lines[1]))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "df.yaml"), []byte(`
+tools: ["^df$"]
+pattern: '^(?P<filesystem>\S+)\s+(?P<size>\S+)\s+(?P<used>\S+)'
+skip: 1
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "words.yaml"), []byte(`
+name: words
+command: ["sh", "-c", "tr ' ' '\n' | sed 's/.*/{\"word\":\"&\"}/'"]
+`), 0644))
+
+	r := NewRegistry()
+	require.NoError(t, r.LoadDir(dir))
+	assert.Equal(t, []string{"df", "json", "ndjson", "words", "yaml"}, r.Names())
+
+	table, parser, err := r.Parse(Auto, "df", "Filesystem Size Used\n/dev/sda1 50G 20G\ntmpfs 1G 0\n")
+	require.NoError(t, err)
+	assert.Equal(t, "df", parser)
+	assert.Equal(t, []string{"filesystem", "size", "used"}, table.Columns)
+	assert.Equal(t, "/dev/sda1", table.Rows[0]["filesystem"])
+
+	table, _, err = r.Parse("words", "echo", "hello world")
+	require.NoError(t, err)
+	assert.Len(t, table.Rows, 2)
+	assert.Equal(t, "world", table.Rows[1]["word"])
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("name: bad\npattern: '(no groups)'\n"), 0644))
+	assert.Error(t, NewRegistry().LoadDir(dir))
+	assert.NoError(t, NewRegistry().LoadDir(filepath.Join(dir, "missing")))
+}
@@ -0,0 +1,102 @@
+// Package tracing exports spans of the CLI to an OpenTelemetry collector
+// over OTLP/HTTP, so that chats, tool calls and API calls show up in the
+// tracing stack of the organization next to those of its other services.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceName is the service.name of the spans of the CLI
+	ServiceName = "kubiya-cli"
+	// TracerName is the name of the tracer of the CLI
+	TracerName = "github.com/kubiyabot/cli"
+
+	// tracesPath is where OTLP/HTTP collectors receive spans
+	tracesPath = "/v1/traces"
+)
+
+// EndpointURL returns the URL spans are sent to for an endpoint given as
+// a collector URL, e.g. http://localhost:4318, or as host:port for HTTPS.
+// The traces path is added when the URL has no path.
+func EndpointURL(endpoint string) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid OTLP endpoint %q: expected a URL such as http://localhost:4318", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = tracesPath
+	}
+	return u.String(), nil
+}
+
+// NewProvider returns a provider exporting spans in batches to the OTLP/HTTP
+// endpoint. Headers, e.g. for authentication, are read from the standard
+// OTEL_EXPORTER_OTLP_HEADERS environment variable. Shutting the provider
+// down sends the spans left. Export errors are passed to onError rather
+// than logged.
+func NewProvider(ctx context.Context, endpoint, version string, onError func(error)) (*sdktrace.TracerProvider, error) {
+	endpointURL, err := EndpointURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(onError))
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpointURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", ServiceName),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the service: %w", err)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// Transport wraps an API transport so that every request is a client span
+// of the provider. Requests whose context has no span become children of
+// parent, which keeps the API calls of a command in its trace. The trace
+// context is propagated to the API in the traceparent header.
+func Transport(transport http.RoundTripper, provider trace.TracerProvider, parent trace.Span) http.RoundTripper {
+	traced := otelhttp.NewTransport(transport,
+		otelhttp.WithTracerProvider(provider),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+	)
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if !trace.SpanContextFromContext(req.Context()).IsValid() {
+			req = req.WithContext(trace.ContextWithSpan(req.Context(), parent))
+		}
+		return traced.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
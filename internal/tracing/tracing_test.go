@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{endpoint: "http://localhost:4318", want: "http://localhost:4318/v1/traces"},
+		{endpoint: "http://localhost:4318/", want: "http://localhost:4318/v1/traces"},
+		{endpoint: "otel.corp.com", want: "https://otel.corp.com/v1/traces"},
+		{endpoint: "https://otel.corp.com/otlp/v1/traces", want: "https://otel.corp.com/otlp/v1/traces"},
+		{endpoint: "grpc://otel.corp.com:4317", wantErr: true},
+		{endpoint: "http://", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := EndpointURL(tt.endpoint)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("EndpointURL(%q) = %q, want an error", tt.endpoint, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("EndpointURL(%q) = %q, %v, want %q", tt.endpoint, got, err, tt.want)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, parent := provider.Tracer(TracerName).Start(context.Background(), "kubiya chat")
+	client := &http.Client{Transport: Transport(http.DefaultTransport, provider, parent)}
+
+	resp, err := client.Get(server.URL + "/api/v1/agents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/agents" {
+		t.Errorf("span name = %q", span.Name())
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("the request span is not a child of the parent span")
+	}
+	want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+}
@@ -3,11 +3,13 @@ package tui
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 func (s *SourceBrowser) executeTool() tea.Cmd {
@@ -46,7 +48,7 @@ func (s *SourceBrowser) executeTool() tea.Cmd {
 		}
 
 		// Make HTTP request with proper error handling
-		httpClient := &http.Client{Timeout: 30 * time.Second}
+		httpClient := httpclient.New(30 * time.Second)
 		resp, err := httpClient.Post("http://localhost:5001/tool/execute",
 			"application/json", bytes.NewBuffer(jsonData))
 		if err != nil {
@@ -67,7 +69,7 @@ func (s *SourceBrowser) executeTool() tea.Cmd {
 		}
 
 		if execResp.Error != "" {
-			return toolExecutedMsg{err: fmt.Errorf(execResp.Error)}
+			return toolExecutedMsg{err: errors.New(execResp.Error)}
 		}
 
 		// Handle execution status
@@ -92,7 +94,7 @@ func (s *SourceBrowser) followAsyncExecution(execID string) {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := httpclient.New(5 * time.Second)
 
 	for range ticker.C {
 		resp, err := client.Get(fmt.Sprintf("http://localhost:5001/tool/status/%s", execID))
@@ -121,7 +123,7 @@ func (s *SourceBrowser) followAsyncExecution(execID string) {
 		if status.Status == "completed" || status.Status == "failed" {
 			s.execution.executing = false
 			if status.Error != "" {
-				s.execution.error = fmt.Errorf(status.Error)
+				s.execution.error = errors.New(status.Error)
 			}
 			return
 		}
@@ -133,7 +133,6 @@ func NewSourceBrowser(cfg *config.Config) *SourceBrowser {
 		cfg:    cfg,
 		client: kubiya.NewClient(cfg),
 		state:  stateSourceList,
-		debug:  cfg.Debug,
 		execution: executionState{
 			args:        make(map[string]string),
 			envVars:     make(map[string]*kubiya.EnvVarStatus),
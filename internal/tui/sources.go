@@ -6,8 +6,13 @@ import (
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+
+	klog "github.com/kubiyabot/cli/internal/log"
 )
 
+// tuiLog logs away from the terminal the user interface is drawn on
+var tuiLog = klog.For(klog.TUI)
+
 type errMsg error
 
 // fetchSources retrieves the list of sources
@@ -47,21 +52,15 @@ func (s *SourceBrowser) fetchTools() tea.Cmd {
 			return toolsLoadedMsg{err: fmt.Errorf("No source selected")}
 		}
 
-		if s.debug {
-			fmt.Printf("Fetching tools for source: %s (%s)\n", s.currentSource.Name, s.currentSource.URL)
-		}
+		tuiLog.Debug("fetching tools", "source", s.currentSource.Name, "url", s.currentSource.URL)
 
 		tools, err := s.client.ListTools(context.Background(), s.currentSource.URL)
 		if err != nil {
-			if s.debug {
-				fmt.Printf("Error fetching tools: %v\n", err)
-			}
+			tuiLog.Debug("failed to fetch tools", "source", s.currentSource.Name, "error", err)
 			return toolsLoadedMsg{err: err}
 		}
 
-		if s.debug {
-			fmt.Printf("Found %d tools\n", len(tools))
-		}
+		tuiLog.Debug("fetched tools", "source", s.currentSource.Name, "count", len(tools))
 
 		items := make([]list.Item, len(tools))
 		for i, tool := range tools {
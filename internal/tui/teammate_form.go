@@ -35,6 +35,7 @@ const (
 	SecretsPage
 	EnvVarsPage
 	IntegrationsPage
+	StartersPage
 	ConfirmPage
 )
 
@@ -61,6 +62,7 @@ type AgentForm struct {
 	envKeyInput          textinput.Model
 	envValueInput        textinput.Model
 	integrationInput     textinput.Model
+	starterInputs        []textinput.Model
 
 	// Data
 	sources          []kubiya.Source
@@ -81,6 +83,7 @@ type AgentForm struct {
 	selectedSecrets       []string
 	envVars               map[string]string
 	integrations          []string
+	starters              []kubiya.Starter
 	addingEnvVar          bool
 	currentEnvKey         string
 	sourceInputType       SourceInputType
@@ -182,6 +185,26 @@ func NewAgentForm(cfg *config.Config) *AgentForm {
 	integrationInput.Width = 30
 	integrationInput.Prompt = "Integration: "
 
+	// Initialize starter inputs: name, command and display text
+	starterInputs := make([]textinput.Model, 3)
+	starterInputs[0] = textinput.New()
+	starterInputs[0].Placeholder = "failing-pods"
+	starterInputs[0].CharLimit = 50
+	starterInputs[0].Width = 30
+	starterInputs[0].Prompt = "Name: "
+
+	starterInputs[1] = textinput.New()
+	starterInputs[1].Placeholder = "List the pods failing in production"
+	starterInputs[1].CharLimit = 500
+	starterInputs[1].Width = 60
+	starterInputs[1].Prompt = "Command: "
+
+	starterInputs[2] = textinput.New()
+	starterInputs[2].Placeholder = "🔍 Failing pods"
+	starterInputs[2].CharLimit = 100
+	starterInputs[2].Width = 40
+	starterInputs[2].Prompt = "Display Text: "
+
 	// Initialize sources list
 	sourcesList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	sourcesList.Title = "Available Sources"
@@ -208,6 +231,7 @@ func NewAgentForm(cfg *config.Config) *AgentForm {
 		envKeyInput:          envKeyInput,
 		envValueInput:        envValueInput,
 		integrationInput:     integrationInput,
+		starterInputs:        starterInputs,
 		sourcesList:          sourcesList,
 		secretsList:          secretsList,
 		focus:                0,
@@ -216,6 +240,7 @@ func NewAgentForm(cfg *config.Config) *AgentForm {
 		selectedSecrets:      []string{},
 		envVars:              make(map[string]string),
 		integrations:         []string{},
+		starters:             []kubiya.Starter{},
 		addingEnvVar:         false,
 		sourceInputType:      ExistingSourceType,
 	}
@@ -228,11 +253,14 @@ func (f *AgentForm) SetDefaults(agent *kubiya.Agent) {
 	f.inputs[2].SetValue(agent.LLMModel)
 	f.inputs[3].SetValue(agent.InstructionType)
 
-	// Set sources, secrets, env vars, and integrations
+	// Set sources, secrets, env vars, integrations and starters
 	f.selectedSources = agent.Sources
 	f.selectedSecrets = agent.Secrets
 	f.envVars = agent.Environment
 	f.integrations = agent.Integrations
+	if starters, err := agent.GetStarters(); err == nil {
+		f.starters = starters
+	}
 
 	// Reset source input states
 	f.sourceInputType = ExistingSourceType
@@ -275,7 +303,7 @@ func (f *AgentForm) Run() (*kubiya.Agent, error) {
 		}
 	}
 
-	return &kubiya.Agent{
+	agent := &kubiya.Agent{
 		Name:            f.inputs[0].Value(),
 		Description:     f.inputs[1].Value(),
 		LLMModel:        f.inputs[2].Value(),
@@ -284,7 +312,9 @@ func (f *AgentForm) Run() (*kubiya.Agent, error) {
 		Secrets:         f.selectedSecrets,
 		Environment:     f.envVars,
 		Integrations:    f.integrations,
-	}, nil
+	}
+	agent.SetStarters(f.starters)
+	return agent, nil
 }
 
 func (f *AgentForm) fetchSources() {
@@ -404,6 +434,8 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if f.page == SourcesPage && !f.inlineSourceInputMode {
 				// Cycle through source input types
 				f.sourceInputType = (f.sourceInputType + 1) % 4
+			} else if f.page == StartersPage {
+				f.focus = (f.focus + 1) % len(f.starterInputs)
 			}
 
 		case "shift+tab":
@@ -429,6 +461,11 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if f.sourceInputType < 0 {
 					f.sourceInputType = 3
 				}
+			} else if f.page == StartersPage {
+				f.focus--
+				if f.focus < 0 {
+					f.focus = len(f.starterInputs) - 1
+				}
 			}
 
 		case "enter":
@@ -597,8 +634,44 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					f.integrations = append(f.integrations, integration)
 					f.integrationInput.SetValue("")
 				} else {
-					// If input is empty, go to confirmation page
+					// If input is empty, go to starters page
+					f.page = StartersPage
+					f.focus = 0
+				}
+
+			case StartersPage:
+				if f.starterInputs[0].Value() == "" {
+					// If name is empty, go to confirmation page
 					f.page = ConfirmPage
+				} else if f.focus < len(f.starterInputs)-1 {
+					f.focus++
+				} else {
+					starter := kubiya.Starter{
+						Name:        f.starterInputs[0].Value(),
+						Command:     f.starterInputs[1].Value(),
+						DisplayText: f.starterInputs[2].Value(),
+					}
+					if err := starter.Validate(); err != nil {
+						f.err = err
+						f.focus = 1
+						return f, nil
+					}
+					// Replace a starter with the same name
+					replaced := false
+					for i, existing := range f.starters {
+						if existing.Name == starter.Name {
+							f.starters[i] = starter
+							replaced = true
+							break
+						}
+					}
+					if !replaced {
+						f.starters = append(f.starters, starter)
+					}
+					for i := range f.starterInputs {
+						f.starterInputs[i].SetValue("")
+					}
+					f.focus = 0
 				}
 
 			case ConfirmPage:
@@ -668,6 +741,8 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				f.selectedSecrets = f.selectedSecrets[:len(f.selectedSecrets)-1]
 			} else if f.page == IntegrationsPage && msg.Type == tea.KeyBackspace && len(f.integrations) > 0 && f.integrationInput.Value() == "" {
 				f.integrations = f.integrations[:len(f.integrations)-1]
+			} else if f.page == StartersPage && msg.Type == tea.KeyBackspace && len(f.starters) > 0 && f.focus == 0 && f.starterInputs[0].Value() == "" {
+				f.starters = f.starters[:len(f.starters)-1]
 			}
 
 		case "right", "l":
@@ -687,6 +762,9 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				f.page = IntegrationsPage
 				f.integrationInput.Focus()
 			case IntegrationsPage:
+				f.page = StartersPage
+				f.focus = 0
+			case StartersPage:
 				f.page = ConfirmPage
 			}
 
@@ -715,9 +793,12 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case IntegrationsPage:
 				f.page = EnvVarsPage
 				f.addingEnvVar = false
-			case ConfirmPage:
+			case StartersPage:
 				f.page = IntegrationsPage
 				f.integrationInput.Focus()
+			case ConfirmPage:
+				f.page = StartersPage
+				f.focus = 0
 			}
 		}
 
@@ -826,6 +907,18 @@ func (f *AgentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		f.integrationInput, cmd = f.integrationInput.Update(msg)
 		cmds = append(cmds, cmd)
+
+	case StartersPage:
+		for i := range f.starterInputs {
+			if i == f.focus {
+				f.starterInputs[i].Focus()
+			} else {
+				f.starterInputs[i].Blur()
+			}
+			var cmd tea.Cmd
+			f.starterInputs[i], cmd = f.starterInputs[i].Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	}
 
 	return f, tea.Batch(cmds...)
@@ -1038,7 +1131,7 @@ func (f *AgentForm) View() string {
 	s += "🤖 Kubiya Agent Configuration\n\n"
 
 	// Navigation
-	pages := []string{"Basic Info", "Sources", "Secrets", "Env Vars", "Integrations", "Confirm"}
+	pages := []string{"Basic Info", "Sources", "Secrets", "Env Vars", "Integrations", "Starters", "Confirm"}
 	nav := "Pages: "
 	for i, page := range pages {
 		if int(f.page) == i {
@@ -1250,6 +1343,25 @@ func (f *AgentForm) View() string {
 		s += "Press Backspace on empty field to remove last integration\n"
 		s += "Common integrations: github, aws, kubernetes, slack, jira, etc.\n"
 
+	case StartersPage:
+		s += "🚀 Conversation Starters\n\n"
+		for i := range f.starterInputs {
+			s += f.starterInputs[i].View() + "\n"
+		}
+
+		s += "\nCurrent Starters:\n"
+		if len(f.starters) == 0 {
+			s += "  (none)\n"
+		} else {
+			for _, starter := range f.starters {
+				s += fmt.Sprintf("  • %s: %s\n", starter.Name, starter.Command)
+			}
+		}
+
+		s += "\nPress Tab to move between fields, Enter on the last field to add the starter\n"
+		s += "Press Enter with an empty name to continue\n"
+		s += "Press Backspace on an empty name to remove the last starter\n"
+
 	case ConfirmPage:
 		s += "✅ Confirmation\n\n"
 
@@ -1320,6 +1432,15 @@ func (f *AgentForm) View() string {
 			s += "\n"
 		}
 
+		s += fmt.Sprintf("Starters: %d\n", len(f.starters))
+		if len(f.starters) > 0 {
+			s += "  Starters:\n"
+			for _, starter := range f.starters {
+				s += fmt.Sprintf("  • %s: %s\n", starter.Name, starter.Command)
+			}
+			s += "\n"
+		}
+
 		s += "Press Enter to confirm and create the agent, or Left to go back\n"
 	}
 
@@ -102,7 +102,6 @@ type SourceBrowser struct {
 	execution     executionState
 	contexts      []string
 	agents     []kubiya.Agent
-	debug         bool
 	portForward   struct {
 		forwarder *portforward.PortForwarder
 		ready     bool
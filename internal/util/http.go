@@ -9,9 +9,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
+	klog "github.com/kubiyabot/cli/internal/log"
 )
 
 // HTTPClient provides a wrapper around the standard HTTP client with common utilities
@@ -60,9 +62,7 @@ func WithHTTPClient(client *http.Client) Option {
 func NewHTTPClient(baseURL string, opts ...Option) *HTTPClient {
 	// Default configuration
 	c := &HTTPClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  httpclient.New(30 * time.Second),
 		baseURL: baseURL,
 		timeout: 30 * time.Second,
 		debug:   false,
@@ -178,7 +178,7 @@ func (h *HTTPClient) Do(req *http.Request) (*http.Response, error) {
 	resp, err := h.client.Do(req)
 	if err != nil {
 		if h.debug {
-			fmt.Printf("[DEBUG] Request failed: %v\n", err)
+			httpLog.Debug("request failed", "url", req.URL.String(), "error", err)
 		}
 		LogAPICall(req.Method, req.URL.String(), h.headerMapFromRequest(req), nil, 0, []byte(fmt.Sprintf("Request failed: %v", err)))
 		return nil, err
@@ -389,7 +389,7 @@ func (h *HTTPClient) StreamSSE(resp *http.Response, timeout time.Duration) (<-ch
 				for range ticker.C {
 					if time.Since(lastEventTime) > inactivityTimeout {
 						if h.debug {
-							fmt.Printf("[DEBUG] SSE stream timeout after %v of inactivity\n", time.Since(lastEventTime))
+							klog.For(klog.SSE).Debug("stream timed out", "inactive", time.Since(lastEventTime))
 						}
 						resp.Body.Close()
 						return
@@ -455,19 +455,18 @@ func (h *HTTPClient) StreamSSE(resp *http.Response, timeout time.Duration) (<-ch
 // Helper methods for logging
 
 func (h *HTTPClient) logRequest(req *http.Request) {
-	fmt.Printf("[DEBUG] %s %s\n", req.Method, req.URL.String())
+	var body []byte
 	if req.Body != nil {
-		if body, err := io.ReadAll(req.Body); err == nil {
-			req.Body = io.NopCloser(bytes.NewBuffer(body))
-			if len(body) > 0 {
-				fmt.Printf("[DEBUG] Request Body: %s\n", string(body))
-			}
+		if b, err := io.ReadAll(req.Body); err == nil {
+			req.Body = io.NopCloser(bytes.NewBuffer(b))
+			body = b
 		}
 	}
+	httpLog.Debug("request", "method", req.Method, "url", req.URL.String(), "body", string(body))
 }
 
 func (h *HTTPClient) logResponse(resp *http.Response) {
-	fmt.Printf("[DEBUG] Response Status: %d\n", resp.StatusCode)
+	httpLog.Debug("response", "url", resp.Request.URL.String(), "status", resp.StatusCode)
 }
 
 func (h *HTTPClient) headerMapFromRequest(req *http.Request) map[string]string {
@@ -482,34 +481,16 @@ func (h *HTTPClient) headerMapFromRequest(req *http.Request) map[string]string {
 	return headers
 }
 
-// LogAPICall logs all API calls to /tmp/klog.txt (extracted from kubiya client)
-func LogAPICall(method, url string, headers map[string]string, body []byte, responseStatus int, responseBody []byte) {
-	if os.Getenv("KUBIYA_DEBUG") != "true" {
-		return
-	}
+var httpLog = klog.For(klog.HTTP)
 
-	f, err := os.OpenFile("/tmp/klog.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-
-	logEntry := fmt.Sprintf("\n=== API Call %s ===\n", timestamp)
-	logEntry += fmt.Sprintf("Method: %s\n", method)
-	logEntry += fmt.Sprintf("URL: %s\n", url)
-	logEntry += fmt.Sprintf("Headers: %v\n", headers)
-	if body != nil {
-		logEntry += fmt.Sprintf("Request Body: %s\n", string(body))
-	}
-	logEntry += fmt.Sprintf("Response Status: %d\n", responseStatus)
-	if responseBody != nil {
-		logEntry += fmt.Sprintf("Response Body: %s\n", string(responseBody))
+// LogAPICall logs an API call to the http subsystem, with its headers and
+// bodies at the trace level
+func LogAPICall(method, url string, headers map[string]string, body []byte, responseStatus int, responseBody []byte) {
+	httpLog.Debug("api call", "method", method, "url", url, "status", responseStatus)
+	if httpLog.Enabled(klog.LevelTrace) {
+		httpLog.Trace("api call details", "method", method, "url", url, "headers", headers,
+			"request", string(body), "response", string(responseBody))
 	}
-	logEntry += "=======================================\n"
-
-	f.WriteString(logEntry)
 }
 
 // Response reading utilities
@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // IsRemoteSource checks if the given source is a remote URL or git repository
@@ -73,9 +75,7 @@ func FetchHTTP(ctx context.Context, urlStr string) ([]byte, error) {
 	}
 
 	// Perform request
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		if urlErr, ok := err.(*url.Error); ok && urlErr.Timeout() {
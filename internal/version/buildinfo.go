@@ -0,0 +1,177 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// keyDependencies are the modules shown by 'kubiya version --verbose'
+var keyDependencies = []string{
+	"github.com/docker/docker",
+	"github.com/extism/go-sdk",
+	"github.com/getsentry/sentry-go",
+	"github.com/go-resty/resty/v2",
+	"github.com/golang-jwt/jwt/v4",
+	"github.com/mark3labs/mcp-go",
+	"github.com/spf13/cobra",
+	"golang.org/x/net",
+	"gopkg.in/yaml.v3",
+	"k8s.io/client-go",
+}
+
+// BuildInfo is the provenance of the running binary. The fields after
+// BuiltBy are only filled by GetBuildInfo with verbose set.
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+	BuiltBy string `json:"built_by"`
+
+	GoVersion string `json:"go_version,omitempty"`
+	Platform  string `json:"platform,omitempty"`
+	// Module is the main module the binary was built from
+	Module *Module `json:"module,omitempty"`
+	VCS    *VCS    `json:"vcs,omitempty"`
+	// Settings are the build settings, such as CGO_ENABLED and -trimpath
+	Settings map[string]string `json:"settings,omitempty"`
+	// Dependencies are all the modules compiled into the binary
+	Dependencies []Module `json:"dependencies,omitempty"`
+}
+
+// Module is a Go module compiled into the binary
+type Module struct {
+	Path    string  `json:"path"`
+	Version string  `json:"version"`
+	Sum     string  `json:"sum,omitempty"`
+	Replace *Module `json:"replace,omitempty"`
+}
+
+// VCS describes the source tree the binary was built from
+type VCS struct {
+	System   string `json:"system,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	Time     string `json:"time,omitempty"`
+	// Modified is set when the tree had uncommitted changes
+	Modified bool `json:"modified"`
+}
+
+// GetBuildInfo returns the provenance of the running binary, from the
+// release metadata and the build information Go embeds in it
+func GetBuildInfo(verbose bool) BuildInfo {
+	bi, _ := debug.ReadBuildInfo()
+	return buildInfoFrom(bi, verbose)
+}
+
+func buildInfoFrom(bi *debug.BuildInfo, verbose bool) BuildInfo {
+	info := BuildInfo{Version: Version, Commit: commit, Date: date, BuiltBy: builtBy}
+	if bi == nil {
+		if verbose {
+			info.GoVersion = runtime.Version()
+			info.Platform = runtime.GOOS + "/" + runtime.GOARCH
+		}
+		return info
+	}
+
+	var vcs *VCS
+	settings := map[string]string{}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs":
+			vcs = ensureVCS(vcs)
+			vcs.System = s.Value
+		case "vcs.revision":
+			vcs = ensureVCS(vcs)
+			vcs.Revision = s.Value
+		case "vcs.time":
+			vcs = ensureVCS(vcs)
+			vcs.Time = s.Value
+		case "vcs.modified":
+			vcs = ensureVCS(vcs)
+			vcs.Modified = s.Value == "true"
+		case "-ldflags":
+			// Holds the values set above, and is often long
+		default:
+			settings[s.Key] = s.Value
+		}
+	}
+	if treeState != "" {
+		vcs = ensureVCS(vcs)
+		vcs.Modified = vcs.Modified || treeState == "dirty"
+	}
+
+	// Builds without goreleaser still know their commit from Go
+	if info.Commit == "unknown" && vcs != nil && vcs.Revision != "" {
+		info.Commit = vcs.Revision
+	}
+	if info.Date == "unknown" && vcs != nil && vcs.Time != "" {
+		info.Date = vcs.Time
+	}
+	if !verbose {
+		return info
+	}
+
+	info.GoVersion = bi.GoVersion
+	info.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	if goos, goarch := settings["GOOS"], settings["GOARCH"]; goos != "" && goarch != "" {
+		info.Platform = goos + "/" + goarch
+	}
+	info.Module = &Module{Path: bi.Main.Path, Version: bi.Main.Version, Sum: bi.Main.Sum}
+	info.VCS = vcs
+	if len(settings) > 0 {
+		info.Settings = settings
+	}
+	for _, dep := range bi.Deps {
+		info.Dependencies = append(info.Dependencies, moduleFrom(dep))
+	}
+	sort.Slice(info.Dependencies, func(i, j int) bool {
+		return info.Dependencies[i].Path < info.Dependencies[j].Path
+	})
+	return info
+}
+
+// Dirty reports whether the binary was built from a tree with uncommitted
+// changes
+func (b BuildInfo) Dirty() bool {
+	return b.VCS != nil && b.VCS.Modified
+}
+
+// KeyDependencies returns the dependencies that matter most to security
+// reviews: those handling credentials, the network, containers and clusters
+func (b BuildInfo) KeyDependencies() []Module {
+	var deps []Module
+	for _, dep := range b.Dependencies {
+		for _, path := range keyDependencies {
+			if dep.Path == path {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// String is the module as path@version, with its replacement if any
+func (m Module) String() string {
+	s := m.Path + "@" + m.Version
+	if m.Replace != nil {
+		s += " => " + strings.TrimSuffix(m.Replace.Path+"@"+m.Replace.Version, "@")
+	}
+	return s
+}
+
+func moduleFrom(m *debug.Module) Module {
+	mod := Module{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := moduleFrom(m.Replace)
+		mod.Replace = &replace
+	}
+	return mod
+}
+
+func ensureVCS(vcs *VCS) *VCS {
+	if vcs == nil {
+		return &VCS{}
+	}
+	return vcs
+}
@@ -0,0 +1,85 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func testBuildInfo() *debug.BuildInfo {
+	return &debug.BuildInfo{
+		GoVersion: "go1.24.2",
+		Main:      debug.Module{Path: "github.com/kubiyabot/cli", Version: "v2.5.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.9.1", Sum: "h1:cobra"},
+			{Path: "github.com/fatih/color", Version: "v1.18.0", Sum: "h1:color"},
+			{Path: "k8s.io/client-go", Version: "v0.32.3", Replace: &debug.Module{Path: "../client-go"}},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "-ldflags", Value: "-s -w -X github.com/kubiyabot/cli/internal/version.Version=v2.5.0"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "GOARCH", Value: "arm64"},
+			{Key: "GOOS", Value: "darwin"},
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "0123abc"},
+			{Key: "vcs.time", Value: "2025-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	}
+}
+
+func TestBuildInfoFrom(t *testing.T) {
+	info := buildInfoFrom(testBuildInfo(), true)
+
+	if info.Commit != "0123abc" || info.Date != "2025-01-02T03:04:05Z" {
+		t.Errorf("commit and date should fall back to the VCS information, got %q and %q", info.Commit, info.Date)
+	}
+	if info.Platform != "darwin/arm64" || info.GoVersion != "go1.24.2" {
+		t.Errorf("unexpected platform %q or Go version %q", info.Platform, info.GoVersion)
+	}
+	if info.Dirty() {
+		t.Error("a clean tree is reported dirty")
+	}
+	if _, ok := info.Settings["-ldflags"]; ok {
+		t.Error("-ldflags should not be listed in the settings")
+	}
+	if info.Settings["CGO_ENABLED"] != "0" {
+		t.Errorf("CGO_ENABLED = %q, want 0", info.Settings["CGO_ENABLED"])
+	}
+
+	if len(info.Dependencies) != 3 || info.Dependencies[0].Path != "github.com/fatih/color" {
+		t.Fatalf("dependencies should be sorted by path, got %v", info.Dependencies)
+	}
+	key := info.KeyDependencies()
+	if len(key) != 2 {
+		t.Fatalf("expected cobra and client-go as key dependencies, got %v", key)
+	}
+	if got := key[1].String(); got != "k8s.io/client-go@v0.32.3 => ../client-go" {
+		t.Errorf("replaced module = %q", got)
+	}
+}
+
+func TestBuildInfoFromNotVerbose(t *testing.T) {
+	info := buildInfoFrom(testBuildInfo(), false)
+	if info.GoVersion != "" || info.VCS != nil || len(info.Dependencies) != 0 {
+		t.Errorf("only the release metadata is expected without verbose, got %+v", info)
+	}
+	if info.Commit != "0123abc" {
+		t.Errorf("commit = %q, want the VCS revision", info.Commit)
+	}
+}
+
+func TestBuildInfoTreeState(t *testing.T) {
+	defer func(s string) { treeState = s }(treeState)
+	treeState = "dirty"
+
+	if info := buildInfoFrom(testBuildInfo(), true); !info.Dirty() {
+		t.Error("a release built from a dirty tree should be reported dirty")
+	}
+
+	// go build without VCS information, e.g. of main.go alone
+	bi := testBuildInfo()
+	bi.Settings = nil
+	if info := buildInfoFrom(bi, true); !info.Dirty() {
+		t.Error("the goreleaser tree state applies without VCS information")
+	}
+}
@@ -0,0 +1,85 @@
+package version
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkCache keeps the results of the version checks in
+// ~/.kubiya/cache/version.json, so that each is made at most once per
+// checkInterval across invocations of the CLI
+type checkCache struct {
+	LatestVersion string                    `json:"latest_version,omitempty"`
+	CheckedAt     time.Time                 `json:"checked_at,omitempty"`
+	Policies      map[string]PlatformPolicy `json:"policies,omitempty"` // by control plane URL
+}
+
+// PlatformPolicy is the range of CLI versions a platform supports
+type PlatformPolicy struct {
+	Minimum     string    `json:"minimum_version"`
+	Recommended string    `json:"recommended_version"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// cachePath returns the path of the check cache, empty when it can't be
+// stored
+var cachePath = func() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".kubiya", "cache", "version.json")
+}
+
+func loadCheckCache() checkCache {
+	var cache checkCache
+	if path := cachePath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, &cache)
+		}
+	}
+	return cache
+}
+
+func saveCheckCache(cache checkCache) {
+	path := cachePath()
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
+
+// CheckPlatformPolicy returns the CLI versions supported by the platform at
+// baseURL. Like CheckForUpdate, it uses the result of the last check when it
+// is less than a day old, unless refresh is set; fetch is only called
+// otherwise.
+func CheckPlatformPolicy(baseURL string, refresh bool, fetch func() (minimum, recommended string, err error)) (PlatformPolicy, error) {
+	checkMutex.Lock()
+	defer checkMutex.Unlock()
+
+	cache := loadCheckCache()
+	if policy, ok := cache.Policies[baseURL]; ok && !refresh && time.Since(policy.CheckedAt) < checkInterval {
+		return policy, nil
+	}
+
+	minimum, recommended, err := fetch()
+	if err != nil {
+		return PlatformPolicy{}, err
+	}
+	policy := PlatformPolicy{Minimum: minimum, Recommended: recommended, CheckedAt: time.Now()}
+	if cache.Policies == nil {
+		cache.Policies = map[string]PlatformPolicy{}
+	}
+	cache.Policies[baseURL] = policy
+	saveCheckCache(cache)
+	return policy, nil
+}
@@ -0,0 +1,67 @@
+package version
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckPlatformPolicyCaches(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old func() string) { cachePath = old }(cachePath)
+	cachePath = func() string { return filepath.Join(dir, "version.json") }
+
+	calls := 0
+	fetch := func() (string, string, error) {
+		calls++
+		return "v1.0.0", "v1.2.0", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		policy, err := CheckPlatformPolicy("https://cp", false, fetch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if policy.Minimum != "v1.0.0" || policy.Recommended != "v1.2.0" {
+			t.Errorf("unexpected policy %+v", policy)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the policy to be fetched once, got %d", calls)
+	}
+
+	// Other control planes and refreshes are fetched
+	_, _ = CheckPlatformPolicy("https://other-cp", false, fetch)
+	_, _ = CheckPlatformPolicy("https://cp", true, fetch)
+	if calls != 3 {
+		t.Errorf("expected 3 fetches, got %d", calls)
+	}
+
+	// Stale policies are fetched again
+	cache := loadCheckCache()
+	policy := cache.Policies["https://cp"]
+	policy.CheckedAt = time.Now().Add(-checkInterval - time.Minute)
+	cache.Policies["https://cp"] = policy
+	saveCheckCache(cache)
+	_, _ = CheckPlatformPolicy("https://cp", false, fetch)
+	if calls != 4 {
+		t.Errorf("expected a stale policy to be fetched again, got %d fetches", calls)
+	}
+}
+
+func TestCheckPlatformPolicyFailure(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old func() string) { cachePath = old }(cachePath)
+	cachePath = func() string { return filepath.Join(dir, "version.json") }
+
+	_, err := CheckPlatformPolicy("https://cp", false, func() (string, string, error) {
+		return "", "", errors.New("connection refused")
+	})
+	if err == nil {
+		t.Fatal("expected the lookup error")
+	}
+	if _, ok := loadCheckCache().Policies["https://cp"]; ok {
+		t.Error("expected failures not to be cached")
+	}
+}
@@ -0,0 +1,77 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Compatibility describes how the running CLI relates to the versions
+// supported by the platform API
+type Compatibility int
+
+const (
+	// CompatibilityOK means the CLI is at or above the recommended version
+	CompatibilityOK Compatibility = iota
+	// CompatibilityOutdated means the CLI is supported but older than recommended
+	CompatibilityOutdated
+	// CompatibilityUnsupported means the CLI is older than the minimum supported version
+	CompatibilityUnsupported
+	// CompatibilityUnknown means the versions could not be compared (e.g. dev builds)
+	CompatibilityUnknown
+)
+
+// CheckCompatibility compares the current version against the platform's
+// minimum and recommended CLI versions. Empty bounds are ignored.
+func CheckCompatibility(currentVersion, minimum, recommended string) (Compatibility, error) {
+	if currentVersion == "dev" {
+		return CompatibilityUnknown, nil
+	}
+
+	current, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return CompatibilityUnknown, fmt.Errorf("failed to parse current version %s: %w", currentVersion, err)
+	}
+
+	if minimum != "" {
+		min, err := semver.NewVersion(minimum)
+		if err != nil {
+			return CompatibilityUnknown, fmt.Errorf("failed to parse minimum version %s: %w", minimum, err)
+		}
+		if current.LessThan(min) {
+			return CompatibilityUnsupported, nil
+		}
+	}
+
+	if recommended != "" {
+		rec, err := semver.NewVersion(recommended)
+		if err != nil {
+			return CompatibilityUnknown, fmt.Errorf("failed to parse recommended version %s: %w", recommended, err)
+		}
+		if current.LessThan(rec) {
+			return CompatibilityOutdated, nil
+		}
+	}
+
+	return CompatibilityOK, nil
+}
+
+// GetCompatibilityMessage returns a formatted warning for outdated or
+// unsupported CLI versions, or an empty string when no action is needed
+func GetCompatibilityMessage(status Compatibility, minimum, recommended string) string {
+	var sb strings.Builder
+
+	switch status {
+	case CompatibilityUnsupported:
+		sb.WriteString(fmt.Sprintf("\n⛔ Kubiya CLI %s is no longer supported by the platform (minimum: %s).\n", Version, minimum))
+		sb.WriteString("   Some commands may fail in unexpected ways until you upgrade.\n")
+	case CompatibilityOutdated:
+		sb.WriteString(fmt.Sprintf("\n⚠️  Kubiya CLI %s is older than the recommended version (%s).\n", Version, recommended))
+	default:
+		return ""
+	}
+
+	sb.WriteString("   Run 'kubiya update' to upgrade.\n\n")
+	return sb.String()
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 var (
@@ -20,6 +22,8 @@ var (
 	commit  = "unknown"
 	date    = "unknown"
 	builtBy = "unknown"
+	// treeState is "clean" or "dirty", the state of the git tree
+	treeState = ""
 
 	// Cache check results
 	lastCheck     time.Time
@@ -41,8 +45,9 @@ type githubRelease struct {
 
 // GetVersion returns the full version string
 func GetVersion() string {
+	info := GetBuildInfo(false)
 	return fmt.Sprintf("%s (commit: %s, built: %s, by: %s)",
-		Version, commit, date, builtBy)
+		info.Version, info.Commit, info.Date, info.BuiltBy)
 }
 
 // GetCommit returns the git commit hash
@@ -61,13 +66,19 @@ func CheckForUpdate() (string, bool, error) {
 	checkMutex.Lock()
 	defer checkMutex.Unlock()
 
-	// Use cached result if recent enough
+	// Use cached result if recent enough, from this or a previous invocation
+	if latestVersion == "" {
+		if cache := loadCheckCache(); cache.LatestVersion != "" {
+			lastCheck, latestVersion = cache.CheckedAt, cache.LatestVersion
+		}
+	}
 	if time.Since(lastCheck) < checkInterval && latestVersion != "" {
-		return latestVersion, latestVersion > Version, nil
+		hasUpdate, err := compareVersions(Version, latestVersion)
+		return latestVersion, hasUpdate, err
 	}
 
 	// Check GitHub API for latest release
-	resp, err := http.Get("https://api.github.com/repos/kubiyabot/cli/releases/latest")
+	resp, err := httpclient.Get("https://api.github.com/repos/kubiyabot/cli/releases/latest")
 	if err != nil {
 		return "", false, err
 	}
@@ -85,6 +96,9 @@ func CheckForUpdate() (string, bool, error) {
 	// Update cache
 	lastCheck = time.Now()
 	latestVersion = release.TagName
+	cache := loadCheckCache()
+	cache.LatestVersion, cache.CheckedAt = latestVersion, lastCheck
+	saveCheckCache(cache)
 
 	// Compare versions using semver
 	hasUpdate, err := compareVersions(Version, latestVersion)
@@ -114,3 +114,32 @@ func TestGetUpdateMessage(t *testing.T) {
 
 	t.Logf("✓ GetUpdateMessage works correctly for dev version")
 }
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		minimum     string
+		recommended string
+		expected    Compatibility
+	}{
+		{name: "Up to date", current: "v2.7.0", minimum: "v2.0.0", recommended: "v2.7.0", expected: CompatibilityOK},
+		{name: "Older than recommended", current: "v2.6.0", minimum: "v2.0.0", recommended: "v2.7.0", expected: CompatibilityOutdated},
+		{name: "Older than minimum", current: "v1.9.0", minimum: "v2.0.0", recommended: "v2.7.0", expected: CompatibilityUnsupported},
+		{name: "No bounds", current: "v1.0.0", expected: CompatibilityOK},
+		{name: "Dev version", current: "dev", minimum: "v2.0.0", expected: CompatibilityUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckCompatibility(tt.current, tt.minimum, tt.recommended)
+			if err != nil {
+				t.Fatalf("CheckCompatibility() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("CheckCompatibility(%s, %s, %s) = %v, want %v",
+					tt.current, tt.minimum, tt.recommended, got, tt.expected)
+			}
+		})
+	}
+}
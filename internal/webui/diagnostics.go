@@ -12,6 +12,8 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 const (
@@ -333,9 +335,7 @@ func (d *DiagnosticsRunner) checkControlPlaneConnectivity(ctx context.Context) D
 	}
 
 	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := httpclient.New(10 * time.Second)
 
 	// Check health endpoint
 	healthURL := strings.TrimSuffix(d.controlPlaneURL, "/") + "/api/health"
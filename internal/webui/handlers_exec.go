@@ -11,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // ExecutionSession represents an active execution
@@ -520,7 +522,7 @@ func (s *Server) fetchAgentsFromControlPlane() ([]map[string]interface{}, error)
 		return nil, nil
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.New(10 * time.Second)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/v1/agents", nil)
 	if err != nil {
 		return nil, err
@@ -562,7 +564,7 @@ func (s *Server) fetchTeamsFromControlPlane() ([]map[string]interface{}, error)
 		return nil, nil
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.New(10 * time.Second)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/v1/teams", nil)
 	if err != nil {
 		return nil, err
@@ -603,7 +605,7 @@ func (s *Server) fetchEnvironmentsFromControlPlane() ([]map[string]interface{},
 		return nil, nil
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.New(10 * time.Second)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/v1/environments", nil)
 	if err != nil {
 		return nil, err
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // LLM insights cache
@@ -151,7 +153,7 @@ func (s *Server) fetchModelsFromControlPlane() ([]ModelInfo, error) {
 		return nil, nil
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.New(10 * time.Second)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/v1/models", nil)
 	if err != nil {
 		return nil, err
@@ -216,7 +218,7 @@ func (s *Server) fetchProvidersFromControlPlane() ([]ProviderStatus, error) {
 		return nil, nil
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.New(10 * time.Second)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/v1/models/providers", nil)
 	if err != nil {
 		return nil, err
@@ -256,7 +258,7 @@ func (s *Server) fetchDefaultModel() (*ModelInfo, error) {
 		return nil, nil
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.New(10 * time.Second)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/v1/models/default", nil)
 	if err != nil {
 		return nil, err
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // StreamEvent represents a normalized streaming event for the WebUI
@@ -56,7 +58,7 @@ func (c *cpClient) executeAgent(agentID string, req *CPExecuteRequest) (*CPExecu
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := httpclient.New(30 * time.Second)
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
@@ -103,7 +105,7 @@ func (c *cpClient) streamExecution(ctx context.Context, executionID string) (<-c
 		req = req.WithContext(ctx)
 
 		// Use a client with no timeout for SSE streaming
-		streamClient := &http.Client{Timeout: 0}
+		streamClient := httpclient.New(0)
 		resp, err := streamClient.Do(req)
 		if err != nil {
 			errChan <- fmt.Errorf("failed to connect to stream: %w", err)
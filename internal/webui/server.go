@@ -11,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/kubiyabot/cli/internal/httpclient"
 )
 
 // ServerConfig contains configuration for the WebUI server
@@ -536,7 +538,7 @@ func (s *Server) checkControlPlane() {
 	}
 
 	// Try the API health endpoint with authentication
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := httpclient.New(5 * time.Second)
 
 	// Try /api/health first (the actual control plane endpoint)
 	req, err := http.NewRequest("GET", s.config.ControlPlaneURL+"/api/health", nil)
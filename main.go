@@ -9,16 +9,21 @@ import (
 
 	"github.com/kubiyabot/cli/internal/cli"
 	"github.com/kubiyabot/cli/internal/config"
+	"github.com/kubiyabot/cli/internal/correlation"
 	"github.com/kubiyabot/cli/internal/errors"
 	"github.com/kubiyabot/cli/internal/sentry"
+	"github.com/kubiyabot/cli/internal/style"
 	"github.com/kubiyabot/cli/internal/version"
 )
 
 func main() {
-	// Initialize Sentry early for comprehensive tracing
-	if err := sentry.Initialize(version.GetVersion()); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize Sentry: %v\n", err)
-		// Continue execution even if Sentry fails
+	// Initialize Sentry early for comprehensive tracing, unless telemetry is
+	// disabled with KUBIYA_TELEMETRY=false or `kubiya config set telemetry.enabled false`
+	if config.TelemetryEnabled() {
+		if err := sentry.Initialize(version.GetVersion()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to initialize Sentry: %v\n", err)
+			// Continue execution even if Sentry fails
+		}
 	}
 
 	// Ensure Sentry events are flushed on exit
@@ -78,7 +83,8 @@ func main() {
 
 	if err != nil {
 		sentry.CaptureError(err, map[string]string{
-			"error.type": "cli_execution",
+			"error.type":     "cli_execution",
+			"correlation_id": correlation.ID(),
 		}, map[string]interface{}{
 			"command": os.Args,
 		})
@@ -92,16 +98,37 @@ func handleError(err error) int {
 	if err == nil {
 		return errors.ExitCodeSuccess
 	}
+	defer printCorrelationID()
 
 	// Check if it's a CLIError with type information
 	cliErr, ok := err.(*errors.CLIError)
 	if !ok {
 		// Not a typed error - treat as runtime error
-		fmt.Fprintf(os.Stderr, "%s\n", errors.FormatSimple(err))
+		fmt.Fprintf(os.Stderr, "%s\n", plainError(errors.FormatSimple(err)))
 		return errors.ExitCodeRuntime
 	}
 
 	// Format and display the typed error
-	fmt.Fprintf(os.Stderr, "%s\n", errors.FormatError(cliErr))
+	fmt.Fprintf(os.Stderr, "%s\n", plainError(errors.FormatError(cliErr)))
 	return errors.ExitCode(cliErr.Type)
 }
+
+// plainError strips the emoji of an error message with --no-emoji; the
+// output filters are gone by the time errors are printed
+func plainError(msg string) string {
+	if style.EmojiDisabled() {
+		return style.StripEmoji(msg)
+	}
+	return msg
+}
+
+// printCorrelationID tells which ID to look the failure up with, when the
+// API was called
+func printCorrelationID() {
+	if !correlation.Used() {
+		return
+	}
+	id := correlation.ID()
+	fmt.Fprintf(os.Stderr, "\nCorrelation ID: %s (%s)\nRun 'kubiya trace %s' or share it with support.\n",
+		id, time.Now().UTC().Format(time.RFC3339), id)
+}
@@ -0,0 +1,44 @@
+package kubiya
+
+import "context"
+
+// ListAgents lists the agents of the organization
+func (c *Client) ListAgents(ctx context.Context) ([]Agent, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	agents, err := c.api.GetAgents(ctx)
+	return agentsFromAPI(agents), wrapError("list agents", rec, err)
+}
+
+// GetAgent returns the agent with the given UUID
+func (c *Client) GetAgent(ctx context.Context, uuid string) (*Agent, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	agent, err := c.api.GetAgent(ctx, uuid)
+	return agentFromAPI(agent), wrapError("get agent", rec, err)
+}
+
+// CreateAgent creates an agent and returns it as created
+func (c *Client) CreateAgent(ctx context.Context, agent Agent) (*Agent, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	created, err := c.api.CreateAgent(ctx, agentToAPI(agent))
+	return agentFromAPI(created), wrapError("create agent", rec, err)
+}
+
+// UpdateAgent replaces the agent with the given UUID
+func (c *Client) UpdateAgent(ctx context.Context, uuid string, agent Agent) (*Agent, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	updated, err := c.api.UpdateAgent(ctx, uuid, agentToAPI(agent))
+	return agentFromAPI(updated), wrapError("update agent", rec, err)
+}
+
+// DeleteAgent deletes the agent with the given UUID
+func (c *Client) DeleteAgent(ctx context.Context, uuid string) error {
+	ctx, rec := withStatusRecorder(ctx)
+	return wrapError("delete agent", rec, c.api.DeleteAgent(ctx, uuid))
+}
+
+// ListRunners lists the runners agents and tools can run on
+func (c *Client) ListRunners(ctx context.Context) ([]Runner, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	runners, err := c.api.ListRunners(ctx)
+	return runnersFromAPI(runners), wrapError("list runners", rec, err)
+}
@@ -0,0 +1,39 @@
+package kubiya
+
+import "context"
+
+// ChatOption configures a message sent with Chat
+type ChatOption func(*chatOptions)
+
+type chatOptions struct {
+	sessionID string
+	files     map[string]string
+}
+
+// InSession sends the message in an existing session, so the agent knows the
+// conversation so far
+func InSession(sessionID string) ChatOption {
+	return func(o *chatOptions) {
+		o.sessionID = sessionID
+	}
+}
+
+// WithContextFiles attaches files to the message, by file name
+func WithContextFiles(files map[string]string) ChatOption {
+	return func(o *chatOptions) {
+		o.files = files
+	}
+}
+
+// Chat sends message to the agent with the given UUID and streams its reply.
+// The channel is closed when the agent is done or ctx is done; failures of
+// the agent arrive as a message of type "error".
+func (c *Client) Chat(ctx context.Context, agentUUID, message string, opts ...ChatOption) (<-chan ChatMessage, error) {
+	var o chatOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, rec := withStatusRecorder(ctx)
+	messages, err := c.api.SendMessageWithContext(ctx, agentUUID, message, o.sessionID, o.files)
+	return chatMessagesFromAPI(ctx, messages), wrapError("chat", rec, err)
+}
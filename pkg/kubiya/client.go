@@ -0,0 +1,107 @@
+package kubiya
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kubiyabot/cli/internal/config"
+	kubiyacontext "github.com/kubiyabot/cli/internal/context"
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// DefaultBaseURL is the Kubiya API used unless WithBaseURL is given
+const DefaultBaseURL = "https://api.kubiya.ai/api/v1"
+
+// Client calls the Kubiya API. It is safe for concurrent use.
+type Client struct {
+	api *kubiya.Client
+}
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	baseURL   string
+	rateLimit float64
+	rateBurst int
+	cache     bool
+}
+
+// WithBaseURL sets the URL of the Kubiya API, e.g. for a self-hosted
+// installation
+func WithBaseURL(url string) Option {
+	return func(o *options) {
+		o.baseURL = url
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond, allowing bursts of
+// burst requests. Clients are not rate limited by default.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = requestsPerSecond
+		o.rateBurst = burst
+	}
+}
+
+// WithCache revalidates listings with their ETag, keeping the responses in
+// ~/.kubiya/cache like the CLI does. It is off by default.
+func WithCache() Option {
+	return func(o *options) {
+		o.cache = true
+	}
+}
+
+// New creates a client authenticating with apiKey
+func New(apiKey string, opts ...Option) (*Client, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("kubiya: API key is required")
+	}
+	disabled := false
+	cfg := &config.Config{
+		APIKey:      apiKey,
+		BaseURL:     DefaultBaseURL,
+		Preferences: kubiyacontext.Preferences{Cache: &kubiyacontext.CacheConfig{Enabled: &disabled}},
+	}
+	return newClient(cfg, opts)
+}
+
+// Load creates a client with the configuration of the CLI: the current
+// context of ~/.kubiya, or the KUBIYA_API_KEY and KUBIYA_BASE_URL
+// environment variables
+func Load(opts ...Option) (*Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("kubiya: failed to load configuration: %w", err)
+	}
+	if err := cfg.ValidateAPIKey(); err != nil {
+		return nil, fmt.Errorf("kubiya: %w", err)
+	}
+	return newClient(cfg, opts)
+}
+
+func newClient(cfg *config.Config, opts []Option) (*Client, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.baseURL != "" {
+		cfg.BaseURL = o.baseURL
+	}
+	if o.rateLimit > 0 {
+		cfg.RateLimit, cfg.RateBurst = o.rateLimit, o.rateBurst
+	}
+	if o.cache {
+		enabled := true
+		cfg.Preferences.Cache = &kubiyacontext.CacheConfig{Enabled: &enabled}
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("kubiya: base URL is required")
+	}
+
+	api := kubiya.NewClient(cfg)
+	api.WrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		return &statusTransport{next: next}
+	})
+	return &Client{api: api}, nil
+}
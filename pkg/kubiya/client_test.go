@@ -0,0 +1,117 @@
+package kubiya
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := New("test-key", WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client
+}
+
+func TestNewRequiresAPIKey(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Error("New() without API key should fail")
+	}
+}
+
+func TestGetAgent(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agents/abc-123" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "test-key") {
+			t.Errorf("request is not authenticated: %q", r.Header.Get("Authorization"))
+		}
+		json.NewEncoder(w).Encode(Agent{UUID: "abc-123", Name: "devops"})
+	})
+
+	agent, err := client.GetAgent(context.Background(), "abc-123")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if agent.Name != "devops" {
+		t.Errorf("GetAgent() name = %q, want devops", agent.Name)
+	}
+}
+
+func TestGetWebhookFlattensCommunication(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"wh-1","name":"alerts","communication":{"method":"Slack","destination":"#alerts"}}`))
+	})
+
+	webhook, err := client.GetWebhook(context.Background(), "wh-1")
+	if err != nil {
+		t.Fatalf("GetWebhook() error = %v", err)
+	}
+	if webhook.Method != "Slack" || webhook.Destination != "#alerts" {
+		t.Errorf("GetWebhook() method, destination = %q, %q, want Slack, #alerts", webhook.Method, webhook.Destination)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusBadGateway, ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+
+			// A deadline shorter than the backoff stops retries of 429s
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			_, err := client.GetAgent(ctx, "abc-123")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetAgent() error = %v, want %v", err, tt.want)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.Op != "get agent" {
+				t.Errorf("GetAgent() error = %#v, want status %d of get agent", err, tt.status)
+			}
+			if errors.Is(err, ErrConflict) {
+				t.Error("error should only match its own status")
+			}
+		})
+	}
+}
+
+func TestNetworkErrorHasNoStatus(t *testing.T) {
+	client, err := New("test-key", WithBaseURL("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = client.DeleteWebhook(ctx, "wh-1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 0 {
+		t.Fatalf("DeleteWebhook() error = %#v, want an *Error without status", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DeleteWebhook() error = %v, want it to wrap context.Canceled", err)
+	}
+}
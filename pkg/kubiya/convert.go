@@ -0,0 +1,366 @@
+package kubiya
+
+import (
+	"context"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// The types of this package are converted from and to those of the internal
+// client here, so that the internal client can change without breaking
+// programs using this package.
+
+func agentFromAPI(a *kubiya.Agent) *Agent {
+	if a == nil {
+		return nil
+	}
+	agent := &Agent{
+		UUID:            a.UUID,
+		Name:            a.Name,
+		Description:     a.Description,
+		InstructionType: a.InstructionType,
+		LLMModel:        a.LLMModel,
+		Sources:         a.Sources,
+		Environment:     a.Environment,
+		Secrets:         a.Secrets,
+		AllowedGroups:   a.AllowedGroups,
+		AllowedUsers:    a.AllowedUsers,
+		Owners:          a.Owners,
+		Runners:         a.Runners,
+		IsDebugMode:     a.IsDebugMode,
+		AIInstructions:  a.AIInstructions,
+		Image:           a.Image,
+		ManagedBy:       a.ManagedBy,
+		Integrations:    a.Integrations,
+		Links:           a.Links,
+		Tools:           a.Tools,
+		Tasks:           a.Tasks,
+		Starters:        a.Starters,
+		Tags:            a.Tags,
+		CreatedAt:       a.Metadata.CreatedAt,
+		LastUpdated:     a.Metadata.LastUpdated,
+	}
+	if agent.UUID == "" {
+		agent.UUID = a.ID
+	}
+	if agent.Description == "" {
+		agent.Description = a.Desc
+	}
+	return agent
+}
+
+func agentToAPI(a Agent) kubiya.Agent {
+	agent := kubiya.Agent{
+		UUID:            a.UUID,
+		Name:            a.Name,
+		Description:     a.Description,
+		InstructionType: a.InstructionType,
+		LLMModel:        a.LLMModel,
+		Sources:         a.Sources,
+		Environment:     a.Environment,
+		Secrets:         a.Secrets,
+		AllowedGroups:   a.AllowedGroups,
+		AllowedUsers:    a.AllowedUsers,
+		Owners:          a.Owners,
+		Runners:         a.Runners,
+		IsDebugMode:     a.IsDebugMode,
+		AIInstructions:  a.AIInstructions,
+		Image:           a.Image,
+		ManagedBy:       a.ManagedBy,
+		Integrations:    a.Integrations,
+		Links:           a.Links,
+		Tools:           a.Tools,
+		Tasks:           a.Tasks,
+		Starters:        a.Starters,
+		Tags:            a.Tags,
+	}
+	agent.Metadata.CreatedAt = a.CreatedAt
+	agent.Metadata.LastUpdated = a.LastUpdated
+	return agent
+}
+
+func agentsFromAPI(agents []kubiya.Agent) []Agent {
+	if agents == nil {
+		return nil
+	}
+	out := make([]Agent, len(agents))
+	for i := range agents {
+		out[i] = *agentFromAPI(&agents[i])
+	}
+	return out
+}
+
+func sourceFromAPI(s *kubiya.Source) *Source {
+	if s == nil {
+		return nil
+	}
+	return &Source{
+		UUID:                    s.UUID,
+		URL:                     s.URL,
+		Name:                    s.Name,
+		Description:             s.Description,
+		Type:                    s.Type,
+		ManagedBy:               s.ManagedBy,
+		ConnectedAgentsCount:    s.ConnectedAgentsCount,
+		ConnectedToolsCount:     s.ConnectedToolsCount,
+		ConnectedWorkflowsCount: s.ConnectedWorkflowsCount,
+		ErrorsCount:             s.ErrorsCount,
+		Tools:                   toolsFromAPI(s.Tools),
+		InlineTools:             toolsFromAPI(s.InlineTools),
+		DynamicConfig:           s.DynamicConfig,
+		Runner:                  s.Runner,
+		PinnedRef:               s.PinnedRef,
+		CreatedBy:               s.KubiyaMetadata.UserCreated,
+		LastUpdatedBy:           s.KubiyaMetadata.UserLastUpdated,
+		CreatedAt:               s.CreatedAt,
+		UpdatedAt:               s.UpdatedAt,
+	}
+}
+
+func sourcesFromAPI(sources []kubiya.Source) []Source {
+	if sources == nil {
+		return nil
+	}
+	out := make([]Source, len(sources))
+	for i := range sources {
+		out[i] = *sourceFromAPI(&sources[i])
+	}
+	return out
+}
+
+func toolFromAPI(t kubiya.Tool) Tool {
+	tool := Tool{
+		Name:         t.Name,
+		Description:  t.Description,
+		Alias:        t.Alias,
+		Type:         t.Type,
+		Image:        t.Image,
+		Content:      t.Content,
+		FileName:     t.FileName,
+		Env:          t.Env,
+		Secrets:      t.Secrets,
+		IconURL:      t.IconURL,
+		WithFiles:    t.WithFiles,
+		WithVolumes:  t.WithVolumes,
+		WithServices: t.WithServices,
+		LongRunning:  t.LongRunning,
+		Metadata:     t.Metadata,
+		Mermaid:      t.Mermaid,
+		SourceID:     t.Source.ID,
+		SourceURL:    t.Source.URL,
+		SourceRef:    t.Source.Ref,
+	}
+	if t.Args != nil {
+		tool.Args = make([]ToolArg, len(t.Args))
+		for i, a := range t.Args {
+			tool.Args[i] = ToolArg{
+				Name:        a.Name,
+				Type:        a.Type,
+				Description: a.Description,
+				Required:    a.Required,
+				Default:     a.Default,
+				Options:     a.Options,
+			}
+			if a.OptionsFrom != nil {
+				tool.Args[i].OptionsFromImage = a.OptionsFrom.Image
+				tool.Args[i].OptionsFromScript = a.OptionsFrom.Script
+			}
+		}
+	}
+	for _, test := range t.Tests {
+		tool.Tests = append(tool.Tests, ToolTest(test))
+	}
+	return tool
+}
+
+func toolToAPI(t Tool) kubiya.Tool {
+	tool := kubiya.Tool{
+		Name:         t.Name,
+		Description:  t.Description,
+		Alias:        t.Alias,
+		Type:         t.Type,
+		Image:        t.Image,
+		Content:      t.Content,
+		FileName:     t.FileName,
+		Env:          t.Env,
+		Secrets:      t.Secrets,
+		IconURL:      t.IconURL,
+		WithFiles:    t.WithFiles,
+		WithVolumes:  t.WithVolumes,
+		WithServices: t.WithServices,
+		LongRunning:  t.LongRunning,
+		Metadata:     t.Metadata,
+		Mermaid:      t.Mermaid,
+		Source:       kubiya.ToolSource{ID: t.SourceID, URL: t.SourceURL, Ref: t.SourceRef},
+	}
+	if t.Args != nil {
+		tool.Args = make([]kubiya.ToolArg, len(t.Args))
+		for i, a := range t.Args {
+			tool.Args[i] = kubiya.ToolArg{
+				Name:        a.Name,
+				Type:        a.Type,
+				Description: a.Description,
+				Required:    a.Required,
+				Default:     a.Default,
+				Options:     a.Options,
+			}
+			if a.OptionsFromImage != "" || a.OptionsFromScript != "" {
+				tool.Args[i].OptionsFrom = &kubiya.OptionsFrom{Image: a.OptionsFromImage, Script: a.OptionsFromScript}
+			}
+		}
+	}
+	for _, test := range t.Tests {
+		tool.Tests = append(tool.Tests, kubiya.ToolTest(test))
+	}
+	return tool
+}
+
+func toolsFromAPI(tools []kubiya.Tool) []Tool {
+	if tools == nil {
+		return nil
+	}
+	out := make([]Tool, len(tools))
+	for i, t := range tools {
+		out[i] = toolFromAPI(t)
+	}
+	return out
+}
+
+func toolsToAPI(tools []Tool) []kubiya.Tool {
+	if tools == nil {
+		return nil
+	}
+	out := make([]kubiya.Tool, len(tools))
+	for i, t := range tools {
+		out[i] = toolToAPI(t)
+	}
+	return out
+}
+
+func webhookFromAPI(w *kubiya.Webhook) *Webhook {
+	if w == nil {
+		return nil
+	}
+	return &Webhook{
+		ID:                 w.ID,
+		Name:               w.Name,
+		Source:             w.Source,
+		AgentID:            w.AgentID,
+		Workflow:           w.Workflow,
+		Runner:             w.Runner,
+		Method:             w.Communication.Method,
+		Destination:        w.Communication.Destination,
+		Filter:             w.Filter,
+		Prompt:             w.Prompt,
+		ManagedBy:          w.ManagedBy,
+		Org:                w.Org,
+		TaskID:             w.TaskID,
+		WebhookURL:         w.WebhookURL,
+		HideWebhookHeaders: w.HideWebhookHeaders,
+		Paused:             w.Paused,
+		CreatedAt:          w.CreatedAt,
+		CreatedBy:          w.CreatedBy,
+		UpdatedAt:          w.UpdatedAt,
+	}
+}
+
+func webhookToAPI(w Webhook) kubiya.Webhook {
+	return kubiya.Webhook{
+		ID:                 w.ID,
+		Name:               w.Name,
+		Source:             w.Source,
+		AgentID:            w.AgentID,
+		Workflow:           w.Workflow,
+		Runner:             w.Runner,
+		Communication:      kubiya.Communication{Method: w.Method, Destination: w.Destination},
+		Filter:             w.Filter,
+		Prompt:             w.Prompt,
+		ManagedBy:          w.ManagedBy,
+		Org:                w.Org,
+		TaskID:             w.TaskID,
+		WebhookURL:         w.WebhookURL,
+		HideWebhookHeaders: w.HideWebhookHeaders,
+		Paused:             w.Paused,
+		CreatedAt:          w.CreatedAt,
+		CreatedBy:          w.CreatedBy,
+		UpdatedAt:          w.UpdatedAt,
+	}
+}
+
+func webhooksFromAPI(webhooks []kubiya.Webhook) []Webhook {
+	if webhooks == nil {
+		return nil
+	}
+	out := make([]Webhook, len(webhooks))
+	for i := range webhooks {
+		out[i] = *webhookFromAPI(&webhooks[i])
+	}
+	return out
+}
+
+func runnersFromAPI(runners []kubiya.Runner) []Runner {
+	if runners == nil {
+		return nil
+	}
+	out := make([]Runner, len(runners))
+	for i, r := range runners {
+		out[i] = Runner{
+			Name:                r.Name,
+			Description:         r.Description,
+			RunnerType:          r.RunnerType,
+			Version:             r.Version,
+			ManagedBy:           r.ManagedBy,
+			Namespace:           r.Namespace,
+			KubernetesNamespace: r.KubernetesNamespace,
+			Labels:              r.Labels,
+			RunnerHealth:        RunnerHealth(r.RunnerHealth),
+			ToolManagerHealth:   RunnerHealth(r.ToolManagerHealth),
+			AgentManagerHealth:  RunnerHealth(r.AgentManagerHealth),
+		}
+		if r.Capacity != nil {
+			capacity := RunnerCapacity(*r.Capacity)
+			out[i].Capacity = &capacity
+		}
+	}
+	return out
+}
+
+// chatMessagesFromAPI forwards the messages of a chat stream until it ends
+// or ctx is done
+func chatMessagesFromAPI(ctx context.Context, in <-chan kubiya.ChatMessage) <-chan ChatMessage {
+	if in == nil {
+		return nil
+	}
+	out := make(chan ChatMessage)
+	go func() {
+		defer close(out)
+		for msg := range in {
+			select {
+			case out <- ChatMessage(msg):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// toolEventsFromAPI forwards the events of a tool execution stream until it
+// ends or ctx is done
+func toolEventsFromAPI(ctx context.Context, in <-chan kubiya.WorkflowSSEEvent) <-chan ToolEvent {
+	if in == nil {
+		return nil
+	}
+	out := make(chan ToolEvent)
+	go func() {
+		defer close(out)
+		for event := range in {
+			select {
+			case out <- ToolEvent(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,32 @@
+// Package kubiya is the Go client of the Kubiya platform, the same client the
+// kubiya CLI uses, for programs that manage agents, sources, tools and
+// webhooks or chat with agents without going through the CLI.
+//
+//	client, err := kubiya.New(os.Getenv("KUBIYA_API_KEY"))
+//	if err != nil {
+//		return err
+//	}
+//	agent, err := client.GetAgent(ctx, "abc-123")
+//	if errors.Is(err, kubiya.ErrNotFound) {
+//		...
+//	}
+//
+//	messages, err := client.Chat(ctx, agent.UUID, "Are the pods healthy?")
+//	if err != nil {
+//		return err
+//	}
+//	for msg := range messages {
+//		fmt.Println(msg.Content)
+//	}
+//
+// Load creates a client from the configuration of the CLI instead: the
+// current context of ~/.kubiya or the KUBIYA_* environment variables.
+//
+// Every method takes a context, which cancels the request or, for streams,
+// ends the stream. Failed requests return an *Error, which tells the status
+// of the API response and matches ErrNotFound, ErrUnauthorized and the other
+// sentinel errors with errors.Is.
+//
+// The functions, methods and types of this package follow semantic
+// versioning with the CLI; other packages of the module are internal.
+package kubiya
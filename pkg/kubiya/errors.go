@@ -0,0 +1,93 @@
+package kubiya
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Sentinel errors matched by *Error with errors.Is, after the status of the
+// API response
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// Error is a failed call of the Kubiya API
+type Error struct {
+	// Op is the failed operation, e.g. "get agent"
+	Op string
+	// StatusCode is the HTTP status of the API response, 0 when the request
+	// failed before a response, as on network errors
+	StatusCode int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("kubiya: %s: %v", e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the status of the response matches target, one of the
+// sentinel errors of the package
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// statusRecorder keeps the status of the last failed response of a call
+type statusRecorder struct {
+	status atomic.Int32
+}
+
+type statusRecorderKey struct{}
+
+// withStatusRecorder returns a context whose failed responses are recorded
+func withStatusRecorder(ctx context.Context) (context.Context, *statusRecorder) {
+	rec := &statusRecorder{}
+	return context.WithValue(ctx, statusRecorderKey{}, rec), rec
+}
+
+// statusTransport records failed responses in the statusRecorder of the
+// request context
+type statusTransport struct {
+	next http.RoundTripper
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if rec, ok := req.Context().Value(statusRecorderKey{}).(*statusRecorder); ok && resp != nil && resp.StatusCode >= 400 {
+		rec.status.Store(int32(resp.StatusCode))
+	}
+	return resp, err
+}
+
+// wrapError returns err as an *Error of op
+func wrapError(op string, rec *statusRecorder, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, StatusCode: int(rec.status.Load()), Err: err}
+}
@@ -0,0 +1,92 @@
+package kubiya
+
+import (
+	"context"
+
+	"github.com/kubiyabot/cli/internal/kubiya"
+)
+
+// SourceOption configures a source created with CreateSource
+type SourceOption func(*Source)
+
+// SourceName names the source instead of deriving the name from its URL
+func SourceName(name string) SourceOption {
+	return func(s *Source) {
+		s.Name = name
+	}
+}
+
+// SourceRunner loads the source on the given runner
+func SourceRunner(runner string) SourceOption {
+	return func(s *Source) {
+		s.Runner = runner
+	}
+}
+
+// SourceInlineTools defines the tools of the source instead of loading them
+// from its URL
+func SourceInlineTools(tools []Tool) SourceOption {
+	return func(s *Source) {
+		s.InlineTools = tools
+	}
+}
+
+// SourceDynamicConfig sets the dynamic configuration of the source
+func SourceDynamicConfig(config map[string]interface{}) SourceOption {
+	return func(s *Source) {
+		s.DynamicConfig = config
+	}
+}
+
+// ListSources lists the sources of the organization
+func (c *Client) ListSources(ctx context.Context) ([]Source, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	sources, err := c.api.ListSources(ctx)
+	return sourcesFromAPI(sources), wrapError("list sources", rec, err)
+}
+
+// GetSource returns the source with the given UUID
+func (c *Client) GetSource(ctx context.Context, uuid string) (*Source, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	source, err := c.api.GetSource(ctx, uuid)
+	return sourceFromAPI(source), wrapError("get source", rec, err)
+}
+
+// CreateSource creates a source of the tools found at url
+func (c *Client) CreateSource(ctx context.Context, url string, opts ...SourceOption) (*Source, error) {
+	var s Source
+	for _, opt := range opts {
+		opt(&s)
+	}
+	var apiOpts []kubiya.SourceOption
+	if s.Name != "" {
+		apiOpts = append(apiOpts, kubiya.WithName(s.Name))
+	}
+	if s.Runner != "" {
+		apiOpts = append(apiOpts, kubiya.WithRunner(s.Runner))
+	}
+	if s.InlineTools != nil {
+		apiOpts = append(apiOpts, kubiya.WithInlineTools(toolsToAPI(s.InlineTools)))
+	}
+	if s.DynamicConfig != nil {
+		apiOpts = append(apiOpts, kubiya.WithDynamicConfig(s.DynamicConfig))
+	}
+
+	ctx, rec := withStatusRecorder(ctx)
+	source, err := c.api.CreateSource(ctx, url, apiOpts...)
+	return sourceFromAPI(source), wrapError("create source", rec, err)
+}
+
+// SyncSource reloads the tools of the source with the given UUID from its
+// URL, on runner or the default runner when empty
+func (c *Client) SyncSource(ctx context.Context, uuid string, opts SyncOptions, runner string) (*Source, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	source, err := c.api.SyncSource(ctx, uuid, kubiya.SyncOptions(opts), runner)
+	return sourceFromAPI(source), wrapError("sync source", rec, err)
+}
+
+// DeleteSource deletes the source with the given UUID
+func (c *Client) DeleteSource(ctx context.Context, uuid string) error {
+	ctx, rec := withStatusRecorder(ctx)
+	return wrapError("delete source", rec, c.api.DeleteSource(ctx, uuid, ""))
+}
@@ -0,0 +1,19 @@
+package kubiya
+
+import "context"
+
+// ListTools lists the tools of the source at sourceURL
+func (c *Client) ListTools(ctx context.Context, sourceURL string) ([]Tool, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	tools, err := c.api.ListTools(ctx, sourceURL)
+	return toolsFromAPI(tools), wrapError("list tools", rec, err)
+}
+
+// ExecuteTool runs a tool on runner, or on the default runner when empty,
+// and streams its events until it ends or ctx is done. definition is the
+// tool definition for tools that are not part of a source, nil otherwise.
+func (c *Client) ExecuteTool(ctx context.Context, name string, definition map[string]interface{}, runner string, args map[string]any) (<-chan ToolEvent, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	events, err := c.api.ExecuteToolWithTimeout(ctx, name, definition, runner, 0, args)
+	return toolEventsFromAPI(ctx, events), wrapError("execute tool", rec, err)
+}
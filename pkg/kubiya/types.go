@@ -0,0 +1,194 @@
+package kubiya
+
+import "time"
+
+// Agent is an agent of the organization
+type Agent struct {
+	UUID            string            `json:"uuid,omitempty"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	InstructionType string            `json:"instruction_type"`
+	LLMModel        string            `json:"llm_model"`
+	Sources         []string          `json:"sources"` // UUIDs of the sources of its tools
+	Environment     map[string]string `json:"environment_variables"`
+	Secrets         []string          `json:"secrets"`
+	AllowedGroups   []string          `json:"allowed_groups"`
+	AllowedUsers    []string          `json:"allowed_users"`
+	Owners          []string          `json:"owners"`
+	Runners         []string          `json:"runners"`
+	IsDebugMode     bool              `json:"is_debug_mode"`
+	AIInstructions  string            `json:"ai_instructions"`
+	Image           string            `json:"image"`
+	ManagedBy       string            `json:"managed_by"`
+	Integrations    []string          `json:"integrations"`
+	Links           []string          `json:"links"`
+	Tools           []string          `json:"tools"`
+	Tasks           []string          `json:"tasks"`
+	Starters        []interface{}     `json:"starters,omitempty"`
+	Tags            []string          `json:"tags,omitempty"`
+	CreatedAt       string            `json:"created_at,omitempty"`
+	LastUpdated     string            `json:"last_updated,omitempty"`
+}
+
+// Source is a git repository, or a set of inline tools, providing tools to
+// agents
+type Source struct {
+	UUID                    string                 `json:"uuid"`
+	URL                     string                 `json:"url"`
+	Name                    string                 `json:"name"`
+	Description             string                 `json:"description"`
+	Type                    string                 `json:"type,omitempty"` // "git" or "inline"
+	ManagedBy               string                 `json:"managed_by"`
+	ConnectedAgentsCount    int                    `json:"connected_agents_count"`
+	ConnectedToolsCount     int                    `json:"connected_tools_count"`
+	ConnectedWorkflowsCount int                    `json:"connected_workflows_count"`
+	ErrorsCount             int                    `json:"errors_count"`
+	Tools                   []Tool                 `json:"tools"`
+	InlineTools             []Tool                 `json:"inline_tools,omitempty"`
+	DynamicConfig           map[string]interface{} `json:"dynamic_config,omitempty"`
+	Runner                  string                 `json:"runner,omitempty"`
+	PinnedRef               string                 `json:"pinned_ref"` // Commit the tools are loaded from, empty to follow the branch
+	CreatedBy               string                 `json:"created_by,omitempty"`
+	LastUpdatedBy           string                 `json:"last_updated_by,omitempty"`
+	CreatedAt               time.Time              `json:"created_at"`
+	UpdatedAt               time.Time              `json:"updated_at"`
+}
+
+// Tool is a tool agents can run
+type Tool struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Alias        string      `json:"alias,omitempty"`
+	Type         string      `json:"type,omitempty"`
+	Image        string      `json:"image,omitempty"`
+	Content      string      `json:"content"`
+	FileName     string      `json:"file_name"`
+	Args         []ToolArg   `json:"args"`
+	Env          []string    `json:"env"`
+	Secrets      []string    `json:"secrets,omitempty"`
+	IconURL      string      `json:"icon_url,omitempty"`
+	WithFiles    interface{} `json:"with_files,omitempty"`   // []string or map[string]interface{}
+	WithVolumes  interface{} `json:"with_volumes,omitempty"` // []string or map[string]interface{}
+	WithServices []string    `json:"with_services,omitempty"`
+	LongRunning  bool        `json:"long_running,omitempty"`
+	Metadata     interface{} `json:"metadata,omitempty"`
+	Mermaid      string      `json:"mermaid,omitempty"`
+	SourceID     string      `json:"source_id,omitempty"`
+	SourceURL    string      `json:"source_url,omitempty"`
+	SourceRef    string      `json:"source_ref,omitempty"` // Pinned commit of the source
+	Tests        []ToolTest  `json:"tests,omitempty"`
+}
+
+// ToolArg is an argument of a tool
+type ToolArg struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	// OptionsFromImage and OptionsFromScript compute the options by running
+	// the script in the image
+	OptionsFromImage  string `json:"options_from_image,omitempty"`
+	OptionsFromScript string `json:"options_from_script,omitempty"`
+}
+
+// ToolTest is a test case of a tool
+type ToolTest struct {
+	Name        string                 `json:"name,omitempty"`
+	Args        map[string]interface{} `json:"args,omitempty"`
+	ExitCode    int                    `json:"exit_code"`
+	OutputRegex string                 `json:"output_regex,omitempty"`
+}
+
+// Webhook runs an agent, or a workflow, on the events sent to its URL
+type Webhook struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Source             string `json:"source"`
+	AgentID            string `json:"agent_id"`
+	Workflow           string `json:"workflow,omitempty"`
+	Runner             string `json:"runner,omitempty"`
+	Method             string `json:"method"`      // How the result is sent, e.g. "Slack"
+	Destination        string `json:"destination"` // Where the result is sent, e.g. "#alerts"
+	Filter             string `json:"filter"`
+	Prompt             string `json:"prompt"`
+	ManagedBy          string `json:"managed_by,omitempty"`
+	Org                string `json:"org,omitempty"`
+	TaskID             string `json:"task_id,omitempty"`
+	WebhookURL         string `json:"webhook_url,omitempty"`
+	HideWebhookHeaders bool   `json:"hide_webhook_headers,omitempty"`
+	Paused             bool   `json:"paused,omitempty"`
+	CreatedAt          string `json:"created_at,omitempty"`
+	CreatedBy          string `json:"created_by,omitempty"`
+	UpdatedAt          string `json:"updated_at,omitempty"`
+}
+
+// Runner is where agents and tools run
+type Runner struct {
+	Name                string            `json:"name"`
+	Description         string            `json:"description"`
+	RunnerType          string            `json:"runner_type"`
+	Version             string            `json:"version"`
+	ManagedBy           string            `json:"managed_by"`
+	Namespace           string            `json:"namespace"`
+	KubernetesNamespace string            `json:"kubernetes_namespace"`
+	Labels              map[string]string `json:"labels,omitempty"`
+	RunnerHealth        RunnerHealth      `json:"runner_health"`
+	ToolManagerHealth   RunnerHealth      `json:"tool_manager_health"`
+	AgentManagerHealth  RunnerHealth      `json:"agent_manager_health"`
+	// Capacity is the load of the runner, nil when the platform does not
+	// report it
+	Capacity *RunnerCapacity `json:"capacity,omitempty"`
+}
+
+// RunnerHealth is the health of a component of a runner
+type RunnerHealth struct {
+	Status  string `json:"status"`
+	Health  string `json:"health"`
+	Error   string `json:"error"`
+	Version string `json:"version"`
+}
+
+// RunnerCapacity is the load of a runner
+type RunnerCapacity struct {
+	QueueDepth     int `json:"queue_depth"` // Executions waiting for a slot
+	Running        int `json:"running"`
+	MaxConcurrency int `json:"max_concurrency"`
+}
+
+// SyncOptions configures SyncSource
+type SyncOptions struct {
+	Mode       string `json:"mode,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	Force      bool   `json:"force,omitempty"`
+	AutoCommit bool   `json:"auto_commit,omitempty"`
+	NoDiff     bool   `json:"no_diff,omitempty"`
+	Ref        string `json:"ref,omitempty"` // Commit to load, for pinned sources
+}
+
+// ChatMessage is a message of a chat stream. Messages are sent again, whole,
+// every time they grow: the latest one of a MessageID replaces the former.
+// Type is "tool" for tool calls, "tool_output" for their output and "error"
+// for a failure of the agent, which ends the stream.
+type ChatMessage struct {
+	Content      string `json:"content"`
+	Type         string `json:"type"`
+	MessageID    string `json:"message_id"`
+	Timestamp    string `json:"timestamp"`
+	SenderName   string `json:"sender_name"`
+	Final        bool   `json:"final"`
+	SessionID    string `json:"session_id"`
+	Error        string `json:"error"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	Stream       string `json:"stream,omitempty"` // "stdout" or "stderr" on tool output
+}
+
+// ToolEvent is an event of a tool execution stream
+type ToolEvent struct {
+	Type     string `json:"type"`
+	Data     string `json:"data"`
+	Step     string `json:"step,omitempty"`
+	Progress int    `json:"progress,omitempty"`
+	Stream   string `json:"stream,omitempty"` // "stdout" or "stderr" on tool output
+}
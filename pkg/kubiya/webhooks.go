@@ -0,0 +1,37 @@
+package kubiya
+
+import "context"
+
+// ListWebhooks lists the webhooks of the organization
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	webhooks, err := c.api.ListWebhooks(ctx)
+	return webhooksFromAPI(webhooks), wrapError("list webhooks", rec, err)
+}
+
+// GetWebhook returns the webhook with the given ID
+func (c *Client) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	webhook, err := c.api.GetWebhook(ctx, id)
+	return webhookFromAPI(webhook), wrapError("get webhook", rec, err)
+}
+
+// CreateWebhook creates a webhook and returns it as created, with its URL
+func (c *Client) CreateWebhook(ctx context.Context, webhook Webhook) (*Webhook, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	created, err := c.api.CreateWebhook(ctx, webhookToAPI(webhook))
+	return webhookFromAPI(created), wrapError("create webhook", rec, err)
+}
+
+// UpdateWebhook replaces the webhook with the given ID
+func (c *Client) UpdateWebhook(ctx context.Context, id string, webhook Webhook) (*Webhook, error) {
+	ctx, rec := withStatusRecorder(ctx)
+	updated, err := c.api.UpdateWebhook(ctx, id, webhookToAPI(webhook))
+	return webhookFromAPI(updated), wrapError("update webhook", rec, err)
+}
+
+// DeleteWebhook deletes the webhook with the given ID
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, rec := withStatusRecorder(ctx)
+	return wrapError("delete webhook", rec, c.api.DeleteWebhook(ctx, id))
+}